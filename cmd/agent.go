@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -18,10 +20,13 @@ import (
 	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/daemon"
 	"github.com/zhubert/erg/internal/daemonstate"
+	pexec "github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/ghapp"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
 	"github.com/zhubert/erg/internal/logger"
 	"github.com/zhubert/erg/internal/manifest"
+	"github.com/zhubert/erg/internal/paths"
 	"github.com/zhubert/erg/internal/session"
 	"github.com/zhubert/erg/internal/workflow"
 )
@@ -34,6 +39,8 @@ var (
 	agentWorkflowFile  string // optional explicit workflow config file path
 	agentConfigFile    string // optional config file for multi-repo mode
 	agentDashboardAddr string // optional embedded dashboard address
+	agentWebhookAddr   string // optional webhook listener address
+	agentLeaseDir      string // optional shared directory for the leadership lease
 )
 
 // osExecutable is the function used to resolve the current binary path.
@@ -48,11 +55,15 @@ func init() {
 	rootCmd.Flags().StringVar(&agentWorkflowFile, "workflow", "", "Path to workflow config file (default: <repo>/.erg/workflow.yaml)")
 	rootCmd.Flags().StringVar(&agentConfigFile, "config", "", "Path to config file for multi-repo mode")
 	rootCmd.Flags().StringVar(&agentDashboardAddr, "dashboard-addr", "", "Start an embedded dashboard server at this address (e.g. localhost:21122)")
+	rootCmd.Flags().StringVar(&agentWebhookAddr, "webhook-addr", "", "Listen for issue-tracker webhooks at this address (e.g. :8787)")
+	rootCmd.Flags().StringVar(&agentLeaseDir, "lease-dir", "", "Share leadership with standby instances through a lease in this directory")
 	rootCmd.Flags().MarkHidden("_daemon")        //nolint:errcheck
 	rootCmd.Flags().MarkHidden("once")           //nolint:errcheck
 	rootCmd.Flags().MarkHidden("repo")           //nolint:errcheck
 	rootCmd.Flags().MarkHidden("config")         //nolint:errcheck
 	rootCmd.Flags().MarkHidden("dashboard-addr") //nolint:errcheck
+	rootCmd.Flags().MarkHidden("webhook-addr")   //nolint:errcheck
+	rootCmd.Flags().MarkHidden("lease-dir")      //nolint:errcheck
 }
 
 func runAgent(cmd *cobra.Command, args []string) error {
@@ -127,7 +138,7 @@ func daemonize(cmd *cobra.Command, args []string) error {
 	}()
 
 	// Build args for re-exec
-	childArgs := buildDaemonArgs(agentRepo, agentOnce, agentWorkflowFile, agentConfigFile, agentDashboardAddr)
+	childArgs := buildDaemonArgs(agentRepo, agentOnce, agentWorkflowFile, agentConfigFile, agentDashboardAddr, agentWebhookAddr, agentLeaseDir)
 
 	// Re-exec self with --_daemon
 	self, err := osExecutable()
@@ -183,7 +194,7 @@ func daemonize(cmd *cobra.Command, args []string) error {
 }
 
 // buildDaemonArgs constructs the args slice for the re-exec'd child process.
-func buildDaemonArgs(repo string, once bool, workflowFile, configFile, dashboardAddr string) []string {
+func buildDaemonArgs(repo string, once bool, workflowFile, configFile, dashboardAddr, webhookAddr, leaseDir string) []string {
 	args := []string{"--_daemon"}
 	if configFile != "" {
 		args = append(args, "--config", configFile)
@@ -199,6 +210,12 @@ func buildDaemonArgs(repo string, once bool, workflowFile, configFile, dashboard
 	if dashboardAddr != "" {
 		args = append(args, "--dashboard-addr", dashboardAddr)
 	}
+	if webhookAddr != "" {
+		args = append(args, "--webhook-addr", webhookAddr)
+	}
+	if leaseDir != "" {
+		args = append(args, "--lease-dir", leaseDir)
+	}
 	return args
 }
 
@@ -374,20 +391,25 @@ func runMultiRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqui
 		return fmt.Errorf("error loading manifest: %w", err)
 	}
 
-	gitSvc := git.NewGitService()
-
 	// Build per-repo workflow file mapping and ensure container images
 	repoWorkflowFiles := make(map[string]string)
 	repoContainerImages := make(map[string]string)
+	repoMaxConcurrent := make(map[string]int)
+	limits := []workflow.LimitsConfig{m.Limits}
 	for _, entry := range m.Repos {
 		repoWorkflowFiles[entry.Path] = entry.Workflow
+		if entry.MaxConcurrent > 0 {
+			repoMaxConcurrent[entry.Path] = entry.MaxConcurrent
+		}
 
 		wfCfg, err := ensureRepoImage(ctx, entry.Path, entry.Workflow, daemonLogger)
 		if err != nil {
 			return err
 		}
 		repoContainerImages[entry.Path] = wfCfg.Settings.ContainerImage
+		limits = append(limits, wfCfg.ConcurrencyLimits())
 	}
+	gitSvc := newGitService(limits...)
 
 	// Build AgentConfig with all repos
 	var cfgOpts []agentconfig.AgentConfigOption
@@ -398,24 +420,23 @@ func runMultiRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqui
 	cfg := agentconfig.NewAgentConfig(cfgOpts...)
 
 	// Sync issue provider settings from each repo's workflow config
+	httpProvider := issues.NewGenericHTTPProvider()
 	for _, entry := range m.Repos {
 		wfCfg, _ := workflow.LoadAndMergeWithFile(entry.Path, entry.Workflow)
 		if wfCfg == nil {
 			continue
 		}
-		if wfCfg.Source.Provider == "asana" && wfCfg.Source.Filter.Project != "" {
-			cfg.SetAsanaProject(entry.Path, wfCfg.Source.Filter.Project)
-		}
-		if wfCfg.Source.Provider == "linear" && wfCfg.Source.Filter.Team != "" {
-			cfg.SetLinearTeam(entry.Path, wfCfg.Source.Filter.Team)
-		}
+		syncProviderSettings(cfg, httpProvider, entry.Path, wfCfg)
 	}
 
 	// Initialize issue providers
 	githubProvider := issues.NewGitHubProvider(gitSvc)
 	asanaProvider := issues.NewAsanaProvider(cfg)
 	linearProvider := issues.NewLinearProvider(cfg)
-	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider)
+	gitlabProvider := issues.NewGitLabProvider(cfg)
+	clickupProvider := issues.NewClickUpProvider(cfg)
+	fileProvider := issues.NewFileProvider()
+	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider, gitlabProvider, clickupProvider, httpProvider, fileProvider)
 
 	// Build daemon options
 	var opts []daemon.Option
@@ -425,12 +446,37 @@ func runMultiRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqui
 	opts = append(opts, daemon.WithDaemonID(m.DaemonID()))
 	opts = append(opts, daemon.WithRepoWorkflowFiles(repoWorkflowFiles))
 	opts = append(opts, daemon.WithRepoContainerImages(repoContainerImages))
+	opts = append(opts, daemon.WithScopedImageBuilder(scopedImageBuilder(daemonLogger)))
+	if container.IsRemote() {
+		opts = append(opts, daemon.WithWorkspaceSyncer(container.RemoteWorkspace{}))
+	}
+	if container.Offline() {
+		opts = append(opts, daemon.WithOffline(true))
+	}
+	opts = append(opts, daemon.WithRepoMaxConcurrent(repoMaxConcurrent))
+	if m.Budget != nil {
+		opts = append(opts, daemon.WithGlobalBudget(m.Budget))
+	}
+	if len(m.Limits) > 0 {
+		opts = append(opts, daemon.WithGlobalLimits(m.Limits))
+	}
 	if len(preacquiredLock) > 0 && preacquiredLock[0] != nil {
 		opts = append(opts, daemon.WithPreacquiredLock(preacquiredLock[0]))
 	}
 	if agentDashboardAddr != "" {
 		opts = append(opts, daemon.WithDashboard(agentDashboardAddr))
 	}
+	if agentWebhookAddr != "" {
+		opts = append(opts, daemon.WithWebhook(agentWebhookAddr))
+	}
+	if agentLeaseDir != "" {
+		opts = append(opts, daemon.WithLease(agentLeaseDir, daemon.DefaultLeaseTTL))
+	}
+	if minterOpt, err := githubAppMinterOption(); err != nil {
+		return err
+	} else if minterOpt != nil {
+		opts = append(opts, minterOpt)
+	}
 
 	sessSvc := session.NewSessionService()
 	d := daemon.New(cfg, gitSvc, sessSvc, issueRegistry, daemonLogger, opts...)
@@ -441,15 +487,55 @@ func runMultiRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqui
 	return nil
 }
 
+// newGitService returns the daemon's git service. Its gh calls, which
+// carry both GitHub issue polling and PR operations, are bounded by the
+// tightest github_api_concurrency among limits. Its commit message and PR
+// text generations are cached for git.DefaultResponseCacheTTL.
+func newGitService(limits ...workflow.LimitsConfig) *git.GitService {
+	n := 0
+	for _, l := range limits {
+		if c := l.APIConcurrency("github"); c > 0 && (n == 0 || c < n) {
+			n = c
+		}
+	}
+	svc := git.NewGitService()
+	if n > 0 {
+		svc = git.NewGitServiceWithExecutor(pexec.NewLimitedExecutor(pexec.NewRealExecutor(), "gh", n))
+	}
+	if dir, err := paths.ResponseCacheDir(); err == nil {
+		cache := git.NewResponseCache(dir, git.DefaultResponseCacheTTL)
+		if _, err := cache.Prune(); err != nil {
+			logger.Get().Warn("failed to prune response cache", "error", err)
+		}
+		svc.SetResponseCache(cache)
+	}
+	return svc
+}
+
+// githubAppMinterOption returns a daemon option that enables per-session,
+// repo-scoped GitHub tokens when ERG_GITHUB_APP_* env vars are set.
+// Returns nil when GitHub App auth is not configured.
+func githubAppMinterOption() (daemon.Option, error) {
+	appCfg, ok, err := ghapp.ConfigFromEnv()
+	if err != nil || !ok {
+		return nil, err
+	}
+	minter, err := ghapp.NewMinter(appCfg)
+	if err != nil {
+		return nil, err
+	}
+	return daemon.WithRepoTokenMinter(minter), nil
+}
+
 // runSingleRepoDaemon starts a daemon that watches a single repo (original behavior).
 func runSingleRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacquiredLock ...*daemonstate.DaemonLock) error {
-	gitSvc := git.NewGitService()
 	sessSvc := session.NewSessionService()
 
 	wfCfg, err := ensureRepoImage(ctx, agentRepo, agentWorkflowFile, daemonLogger)
 	if err != nil {
 		return err
 	}
+	gitSvc := newGitService(wfCfg.ConcurrencyLimits())
 
 	// Build AgentConfig from workflow settings + defaults
 	var cfgOpts []agentconfig.AgentConfigOption
@@ -478,18 +564,17 @@ func runSingleRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqu
 		}
 	}
 	cfg := agentconfig.NewAgentConfig(cfgOpts...)
-	if wfCfg.Source.Provider == "asana" && wfCfg.Source.Filter.Project != "" {
-		cfg.SetAsanaProject(agentRepo, wfCfg.Source.Filter.Project)
-	}
-	if wfCfg.Source.Provider == "linear" && wfCfg.Source.Filter.Team != "" {
-		cfg.SetLinearTeam(agentRepo, wfCfg.Source.Filter.Team)
-	}
+	httpProvider := issues.NewGenericHTTPProvider()
+	syncProviderSettings(cfg, httpProvider, agentRepo, wfCfg)
 
 	// Initialize issue providers
 	githubProvider := issues.NewGitHubProvider(gitSvc)
 	asanaProvider := issues.NewAsanaProvider(cfg)
 	linearProvider := issues.NewLinearProvider(cfg)
-	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider)
+	gitlabProvider := issues.NewGitLabProvider(cfg)
+	clickupProvider := issues.NewClickUpProvider(cfg)
+	fileProvider := issues.NewFileProvider()
+	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider, gitlabProvider, clickupProvider, httpProvider, fileProvider)
 
 	// Build daemon options
 	var opts []daemon.Option
@@ -497,6 +582,13 @@ func runSingleRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqu
 		opts = append(opts, daemon.WithOnce(true))
 	}
 	opts = append(opts, daemon.WithRepoFilter(agentRepo))
+	opts = append(opts, daemon.WithScopedImageBuilder(scopedImageBuilder(daemonLogger)))
+	if container.IsRemote() {
+		opts = append(opts, daemon.WithWorkspaceSyncer(container.RemoteWorkspace{}))
+	}
+	if container.Offline() {
+		opts = append(opts, daemon.WithOffline(true))
+	}
 	if wfCfg.Settings != nil && wfCfg.Settings.AutoMerge != nil {
 		opts = append(opts, daemon.WithAutoMerge(*wfCfg.Settings.AutoMerge))
 	}
@@ -509,6 +601,17 @@ func runSingleRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqu
 	if agentDashboardAddr != "" {
 		opts = append(opts, daemon.WithDashboard(agentDashboardAddr))
 	}
+	if agentWebhookAddr != "" {
+		opts = append(opts, daemon.WithWebhook(agentWebhookAddr))
+	}
+	if agentLeaseDir != "" {
+		opts = append(opts, daemon.WithLease(agentLeaseDir, daemon.DefaultLeaseTTL))
+	}
+	if minterOpt, err := githubAppMinterOption(); err != nil {
+		return err
+	} else if minterOpt != nil {
+		opts = append(opts, minterOpt)
+	}
 
 	d := daemon.New(cfg, gitSvc, sessSvc, issueRegistry, daemonLogger, opts...)
 
@@ -519,47 +622,87 @@ func runSingleRepoDaemon(ctx context.Context, daemonLogger *slog.Logger, preacqu
 }
 
 // validatePrereqs checks that all required tools and the container runtime are available.
+// configureHTTPSource maps repoPath to the endpoint described by its workflow
+// config when the repo uses the http issue provider.
+// syncProviderSettings copies the tracker scope of each of a repo's sources
+// (primary and source.additional) into the config and HTTP provider, where
+// the providers look it up by repo path.
+func syncProviderSettings(cfg *agentconfig.AgentConfig, httpProvider *issues.GenericHTTPProvider, repoPath string, wfCfg *workflow.Config) {
+	for _, src := range wfCfg.Source.Sources() {
+		switch {
+		case src.Provider == "asana" && src.Filter.Project != "":
+			cfg.SetAsanaProject(repoPath, src.Filter.Project)
+		case src.Provider == "linear" && src.Filter.Team != "":
+			cfg.SetLinearTeam(repoPath, src.Filter.Team)
+		case src.Provider == "gitlab" && src.Filter.Project != "":
+			cfg.SetGitLabProject(repoPath, src.Filter.Project)
+		case src.Provider == "clickup" && src.Filter.List != "":
+			cfg.SetClickUpList(repoPath, src.Filter.List)
+		}
+		configureHTTPSource(httpProvider, repoPath, wfCfg.ForSource(src))
+	}
+}
+
+func configureHTTPSource(p *issues.GenericHTTPProvider, repoPath string, wfCfg *workflow.Config) {
+	src := wfCfg.Source.Filter.HTTP
+	if wfCfg.Source.Provider != "http" || src == nil {
+		return
+	}
+	p.SetMapping(repoPath, issues.HTTPMapping{
+		URL:            src.URL,
+		Items:          src.Items,
+		IDField:        src.Fields.ID,
+		TitleField:     src.Fields.Title,
+		BodyField:      src.Fields.Body,
+		URLField:       src.Fields.URL,
+		LabelsField:    src.Fields.Labels,
+		PriorityField:  src.Fields.Priority,
+		CreatedAtField: src.Fields.CreatedAt,
+		CommentURL:     src.CommentURL,
+		RemoveLabelURL: src.RemoveLabelURL,
+		TokenEnv:       src.TokenEnv,
+	})
+}
+
 func validatePrereqs() error {
 	prereqs := cli.DefaultPrerequisites()
 	if err := cli.ValidateRequired(prereqs); err != nil {
 		return fmt.Errorf("%w\n\nInstall required tools and try again", err)
 	}
 	if !hasContainerRuntime() {
-		return fmt.Errorf("a container runtime is required for agent mode.\nInstall OrbStack: https://orbstack.dev\nInstall Docker:   https://docs.docker.com/get-docker/\nInstall Colima:   https://github.com/abiosoft/colima")
+		return fmt.Errorf("a container runtime is required for agent mode.\nInstall OrbStack: https://orbstack.dev\nInstall Docker:   https://docs.docker.com/get-docker/\nInstall Colima:   https://github.com/abiosoft/colima\nInstall Podman:   https://podman.io")
 	}
 	return checkDockerDaemon()
 }
 
 // refreshContainerAuth ensures the container auth token is fresh and prints status.
 func refreshContainerAuth() {
-	authSource := claude.ContainerAuthSource()
-	if strings.Contains(authSource, "keychain") || (authSource == "" && claude.KeychainNeedsRefresh()) {
-		if out, err := exec.Command("claude", "-v").Output(); err == nil {
-			fmt.Printf("Claude: %s\n", strings.TrimSpace(string(out)))
-		}
-		authSource = claude.ContainerAuthSource()
-	}
-	if authSource != "" {
-		fmt.Printf("Auth: %s\n", authSource)
-	} else {
+	st, err := claude.RefreshAuth(context.Background())
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: %v\n", err)
+	case st.Source == "":
 		fmt.Println("Warning: no container auth credentials found")
+	case st.ExpiresAt.IsZero() || st.SelfRefreshing:
+		fmt.Printf("Auth: %s\n", st.Source)
+	default:
+		fmt.Printf("Auth: %s (expires in %s)\n", st.Source, formatDuration(time.Until(st.ExpiresAt)))
 	}
 }
 
-// ensureRepoImage loads the workflow config for a repo and auto-builds a container image
-// if none is configured. Returns the loaded workflow config.
+// ensureRepoImage loads and lints the workflow config for a repo, refusing to
+// continue if it has any problems, and auto-builds a container image if none
+// is configured. Returns the loaded workflow config.
 func ensureRepoImage(ctx context.Context, repoPath, workflowFile string, buildLogger *slog.Logger) (*workflow.Config, error) {
-	wfCfg, err := workflow.LoadAndMergeWithFile(repoPath, workflowFile)
+	wfCfg, err := lintWorkflowFile(repoPath, workflowFile, claude.IsValidModel)
 	if err != nil {
-		return nil, fmt.Errorf("error loading workflow config for %s: %w", repoPath, err)
+		return nil, fmt.Errorf("repo %s: %w", repoPath, err)
 	}
 	if wfCfg == nil {
 		return nil, fmt.Errorf("no workflow config found for %s — run `erg workflow init` to create .erg/workflow.yaml", repoPath)
 	}
 	if wfCfg.Settings == nil || wfCfg.Settings.ContainerImage == "" {
-		detected := container.Detect(ctx, repoPath)
-		buildLogger.Info("auto-detected languages", "languages", detected, "repo", repoPath)
-		image, _, err := container.EnsureImage(ctx, detected, version, buildLogger)
+		image, err := autoBuildImage(ctx, repoPath, wfCfg.Container, wfCfg.ImageRegistry(), buildLogger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to auto-build container image for %s: %w\nto skip auto-build, set `settings.container_image` in .erg/workflow.yaml to a pre-built image", repoPath, err)
 		}
@@ -567,18 +710,106 @@ func ensureRepoImage(ctx context.Context, repoPath, workflowFile string, buildLo
 			wfCfg.Settings = &workflow.SettingsConfig{}
 		}
 		wfCfg.Settings.ContainerImage = image
-	}
-	if err := validateWorkflowConfig(wfCfg, claude.IsValidModel); err != nil {
-		return nil, fmt.Errorf("repo %s: %w", repoPath, err)
+	} else if container.Offline() {
+		if err := container.RequireCachedImage(ctx, wfCfg.Settings.ContainerImage); err != nil {
+			return nil, fmt.Errorf("repo %s: %w", repoPath, err)
+		}
 	}
 	return wfCfg, nil
 }
 
+// autoBuildImage returns the session container image for a repo that doesn't
+// set settings.container_image: the image or Dockerfile its workflow's
+// container section names, else one built from its devcontainer.json so
+// sessions run in the environment developers use, else one built for the
+// detected languages, pulled prebuilt from reg when it has one.
+func autoBuildImage(ctx context.Context, repoPath string, custom *workflow.ContainerConfig, reg *workflow.ImageRegistryConfig, buildLogger *slog.Logger) (string, error) {
+	if custom != nil && custom.Image != "" {
+		if container.Offline() {
+			if err := container.RequireCachedImage(ctx, custom.ImageRef()); err != nil {
+				return "", err
+			}
+		}
+		return custom.ImageRef(), nil
+	}
+	if custom != nil && custom.Dockerfile != "" {
+		b := container.DockerfileBuild{Dockerfile: filepath.Join(repoPath, custom.Dockerfile), Args: custom.BuildArgs}
+		if custom.Context != "" {
+			b.Context = filepath.Join(repoPath, custom.Context)
+		}
+		image, _, err := container.EnsureDockerfileImage(ctx, b, version, buildLogger)
+		return image, err
+	}
+
+	dc, err := container.LoadDevContainer(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if dc != nil {
+		buildLogger.Info("building container image from dev container config", "config", dc.Path(), "repo", repoPath)
+		image, _, err := container.EnsureDevContainerImage(ctx, repoPath, dc, version, buildLogger)
+		return image, err
+	}
+	detected := container.Detect(ctx, repoPath)
+	buildLogger.Info("auto-detected languages", "languages", detected, "repo", repoPath)
+	if custom.GPUDevices() != "" {
+		detected = append(detected, container.DetectedLang{Lang: container.LangCUDA, Version: custom.CUDA})
+	} else if slices.ContainsFunc(detected, func(l container.DetectedLang) bool { return l.Lang == container.LangML }) {
+		buildLogger.Info("ML project detected; set container.gpus in the workflow to give sessions GPUs", "repo", repoPath)
+	}
+	image, _, err := container.EnsureImageFrom(ctx, detected, version, containerRegistry(reg), buildLogger)
+	return image, err
+}
+
+// containerRegistry converts a workflow's image_registry setting for the
+// container package.
+func containerRegistry(reg *workflow.ImageRegistryConfig) *container.Registry {
+	if reg == nil {
+		return nil
+	}
+	return &container.Registry{Repository: reg.Repository, Push: reg.Push}
+}
+
+// scopedImageBuilder builds the session image for just some components of
+// a monorepo from their detected languages. Repos with a devcontainer.json
+// keep their full image, since it defines the environment developers use.
+func scopedImageBuilder(buildLogger *slog.Logger) daemon.ScopedImageBuilder {
+	return func(ctx context.Context, repoPath string, dirs []string, reg *workflow.ImageRegistryConfig) (string, error) {
+		if dc, err := container.LoadDevContainer(repoPath); err != nil || dc != nil {
+			return "", err
+		}
+		detected := container.DetectScoped(repoPath, dirs)
+		if len(detected) == 0 {
+			return "", nil
+		}
+		buildLogger.Info("auto-detected languages for components", "languages", detected, "components", dirs, "repo", repoPath)
+		image, _, err := container.EnsureImageFrom(ctx, detected, version, containerRegistry(reg), buildLogger)
+		return image, err
+	}
+}
+
 // validateWorkflowConfig returns an error if the workflow config has validation problems.
 // isValidModel is called for each non-empty model string; pass claude.IsValidModel
 // in production and a custom func in tests.
 func validateWorkflowConfig(cfg *workflow.Config, isValidModel func(string) bool) error {
 	errs := workflow.Validate(cfg)
+	errs = append(errs, modelValidationErrors(cfg, isValidModel)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("workflow configuration errors:\n")
+	for _, e := range errs {
+		sb.WriteString(fmt.Sprintf("  - %s: %s\n", e.Field, e.Message))
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+// modelValidationErrors reports settings- and state-level model names that
+// isValidModel rejects.
+func modelValidationErrors(cfg *workflow.Config, isValidModel func(string) bool) []workflow.ValidationError {
+	var errs []workflow.ValidationError
 
 	// Validate settings-level model
 	if cfg.Settings != nil && cfg.Settings.Model != "" {
@@ -600,15 +831,7 @@ func validateWorkflowConfig(cfg *workflow.Config, isValidModel func(string) bool
 		}
 	}
 
-	if len(errs) == 0 {
-		return nil
-	}
-	var sb strings.Builder
-	sb.WriteString("workflow configuration errors:\n")
-	for _, e := range errs {
-		sb.WriteString(fmt.Sprintf("  - %s: %s\n", e.Field, e.Message))
-	}
-	return fmt.Errorf("%s", sb.String())
+	return errs
 }
 
 // cwdGitRootGetter abstracts the GetCurrentDirGitRoot call for testability.
@@ -683,7 +906,8 @@ func displaySummary(repo string) error {
 		queuedCount := 0
 		completedCount := 0
 		failedCount := 0
-		for _, item := range state.WorkItems {
+		items := state.GetAllWorkItems()
+		for _, item := range items {
 			switch item.State {
 			case daemonstate.WorkItemActive:
 				activeCount++
@@ -710,6 +934,9 @@ func displaySummary(repo string) error {
 		}
 		fmt.Printf("Active: %d  |  Queued: %d  |  Completed: %d  |  Failed: %d\n",
 			activeCount, queuedCount, completedCount, failedCount)
+		if counts := formatWorkflowCounts(items); counts != "" {
+			fmt.Printf("Workflows: %s\n", counts)
+		}
 
 		costUSD, outputTokens, inputTokens := state.GetSpend()
 		totalTokens := outputTokens + inputTokens
@@ -721,6 +948,20 @@ func displaySummary(repo string) error {
 				formatTokenCount(outputTokens),
 			)
 		}
+		if since := state.GetTrackerOfflineSince(); !since.IsZero() {
+			fmt.Printf("Tracker: unreachable since %s  |  Buffered updates: %d\n",
+				since.Local().Format("Jan 2 15:04"), len(state.GetPendingOps()))
+		}
+		if mode, since := state.GetRunMode(); mode != daemonstate.ModeRunning {
+			fmt.Printf("Intake: %s since %s  |  %s\n",
+				mode, since.Local().Format("Jan 2 15:04"), mode.Description())
+		}
+		if tier, since := state.GetDegradation(); tier != daemonstate.TierNormal {
+			fmt.Printf("Mode:   degraded since %s  |  %s\n",
+				since.Local().Format("Jan 2 15:04"), tier.Description())
+		}
+		printPendingConfirmations(os.Stdout, pendingConfirmations(state))
+		printStalledItems(os.Stdout, stalledItems(state))
 	}
 
 	logPath, _ := logger.DefaultLogPath()
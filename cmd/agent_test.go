@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"os"
 	"os/exec"
@@ -154,6 +156,16 @@ func TestHasContainerRuntime(t *testing.T) {
 			available: map[string]bool{"docker": true, "colima": true},
 			want:      true,
 		},
+		{
+			name:      "podman only",
+			available: map[string]bool{"podman": true},
+			want:      true,
+		},
+		{
+			name:      "lima nerdctl only",
+			available: map[string]bool{"nerdctl.lima": true},
+			want:      true,
+		},
 		{
 			name:      "neither",
 			available: map[string]bool{},
@@ -225,7 +237,7 @@ func TestRuntimeStartHint_ColimaNotInstalled(t *testing.T) {
 // ---- buildDaemonArgs ----
 
 func TestBuildDaemonArgs_Basic(t *testing.T) {
-	args := buildDaemonArgs("owner/repo", false, "", "", "")
+	args := buildDaemonArgs("owner/repo", false, "", "", "", "", "")
 	if len(args) != 3 {
 		t.Fatalf("expected 3 args, got %d: %v", len(args), args)
 	}
@@ -238,7 +250,7 @@ func TestBuildDaemonArgs_Basic(t *testing.T) {
 }
 
 func TestBuildDaemonArgs_WithOnce(t *testing.T) {
-	args := buildDaemonArgs("owner/repo", true, "", "", "")
+	args := buildDaemonArgs("owner/repo", true, "", "", "", "", "")
 	if len(args) != 4 {
 		t.Fatalf("expected 4 args, got %d: %v", len(args), args)
 	}
@@ -250,14 +262,14 @@ func TestBuildDaemonArgs_WithOnce(t *testing.T) {
 
 func TestBuildDaemonArgs_HiddenFlagAppended(t *testing.T) {
 	// Verify --_daemon is always the first arg
-	args := buildDaemonArgs("/path/to/repo", false, "", "", "")
+	args := buildDaemonArgs("/path/to/repo", false, "", "", "", "", "")
 	if args[0] != "--_daemon" {
 		t.Errorf("expected '--_daemon' as first arg, got %q", args[0])
 	}
 }
 
 func TestBuildDaemonArgs_WithWorkflowFile(t *testing.T) {
-	args := buildDaemonArgs("owner/repo", false, "/custom/workflow.yaml", "", "")
+	args := buildDaemonArgs("owner/repo", false, "/custom/workflow.yaml", "", "", "", "")
 	if !slices.Contains(args, "--workflow") {
 		t.Errorf("expected '--workflow' in args: %v", args)
 	}
@@ -272,14 +284,14 @@ func TestBuildDaemonArgs_WithWorkflowFile(t *testing.T) {
 
 func TestBuildDaemonArgs_NoWorkflowFile(t *testing.T) {
 	// When workflowFile is empty, --workflow should not appear in args.
-	args := buildDaemonArgs("owner/repo", false, "", "", "")
+	args := buildDaemonArgs("owner/repo", false, "", "", "", "", "")
 	if slices.Contains(args, "--workflow") {
 		t.Errorf("expected no '--workflow' in args when empty: %v", args)
 	}
 }
 
 func TestBuildDaemonArgs_WithConfigFile(t *testing.T) {
-	args := buildDaemonArgs("", false, "", "/path/to/config.yaml", "")
+	args := buildDaemonArgs("", false, "", "/path/to/config.yaml", "", "", "")
 	if slices.Contains(args, "--repo") {
 		t.Errorf("expected no '--repo' when config file is set: %v", args)
 	}
@@ -296,7 +308,7 @@ func TestBuildDaemonArgs_WithConfigFile(t *testing.T) {
 }
 
 func TestBuildDaemonArgs_WithDashboardAddr(t *testing.T) {
-	args := buildDaemonArgs("owner/repo", false, "", "", defaultDashboardAddr)
+	args := buildDaemonArgs("owner/repo", false, "", "", defaultDashboardAddr, "", "")
 	if !slices.Contains(args, "--dashboard-addr") {
 		t.Errorf("expected '--dashboard-addr' in args: %v", args)
 	}
@@ -310,12 +322,34 @@ func TestBuildDaemonArgs_WithDashboardAddr(t *testing.T) {
 }
 
 func TestBuildDaemonArgs_NoDashboardAddr(t *testing.T) {
-	args := buildDaemonArgs("owner/repo", false, "", "", "")
+	args := buildDaemonArgs("owner/repo", false, "", "", "", "", "")
 	if slices.Contains(args, "--dashboard-addr") {
 		t.Errorf("expected no '--dashboard-addr' in args when empty: %v", args)
 	}
 }
 
+func TestBuildDaemonArgs_WithWebhookAddr(t *testing.T) {
+	args := buildDaemonArgs("owner/repo", false, "", "", "", ":8787", "")
+	idx := slices.Index(args, "--webhook-addr")
+	if idx < 0 || idx+1 >= len(args) || args[idx+1] != ":8787" {
+		t.Errorf("expected '--webhook-addr :8787' in args: %v", args)
+	}
+	if args := buildDaemonArgs("owner/repo", false, "", "", "", "", ""); slices.Contains(args, "--webhook-addr") {
+		t.Errorf("expected no '--webhook-addr' in args when empty: %v", args)
+	}
+}
+
+func TestBuildDaemonArgs_WithLeaseDir(t *testing.T) {
+	args := buildDaemonArgs("owner/repo", false, "", "", "", "", "/mnt/erg")
+	idx := slices.Index(args, "--lease-dir")
+	if idx < 0 || idx+1 >= len(args) || args[idx+1] != "/mnt/erg" {
+		t.Errorf("expected '--lease-dir /mnt/erg' in args: %v", args)
+	}
+	if args := buildDaemonArgs("owner/repo", false, "", "", "", "", ""); slices.Contains(args, "--lease-dir") {
+		t.Errorf("expected no '--lease-dir' in args when empty: %v", args)
+	}
+}
+
 // ---- runAgent flag logic ----
 
 func TestDaemonFlagIsHidden(t *testing.T) {
@@ -480,12 +514,21 @@ func TestValidateWorkflowConfig_ModelValidation(t *testing.T) {
 	}
 }
 
-func TestWorkflowCommandRemoved(t *testing.T) {
+func TestWorkflowCommandSubcommands(t *testing.T) {
 	for _, cmd := range rootCmd.Commands() {
-		if cmd.Use == "workflow" {
-			t.Error("'erg workflow' command should have been removed")
+		if cmd.Use != "workflow" {
+			continue
+		}
+		var subs []string
+		for _, sub := range cmd.Commands() {
+			subs = append(subs, sub.Use)
 		}
+		if strings.Join(subs, ",") != "graph,migrate,validate" {
+			t.Errorf("'erg workflow' should have graph, migrate, and validate subcommands, got %v", subs)
+		}
+		return
 	}
+	t.Error("'erg workflow' command not registered")
 }
 
 func containsAny(s string, substrs ...string) bool {
@@ -599,3 +642,28 @@ func TestEnsureDockerHost_NoSocket(t *testing.T) {
 		t.Errorf("DOCKER_HOST should remain empty when no socket found, got %q", got)
 	}
 }
+
+func TestAutoBuildImage_CustomImagePinned(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	custom := &workflow.ContainerConfig{Image: "ghcr.io/acme/tools:1", Digest: digest}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	image, err := autoBuildImage(context.Background(), t.TempDir(), custom, nil, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image != "ghcr.io/acme/tools:1@"+digest {
+		t.Errorf("expected the pinned custom image, got %q", image)
+	}
+}
+
+func TestScopedImageBuilder_NoLanguagesKeepsFullImage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	image, err := scopedImageBuilder(logger)(context.Background(), t.TempDir(), []string{"docs"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image != "" {
+		t.Errorf("expected no scoped image, got %q", image)
+	}
+}
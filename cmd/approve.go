@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/session"
+)
+
+// phaseAwaitingConfirmation mirrors the daemon's phase for work items parked
+// before a destructive action.
+const phaseAwaitingConfirmation = "awaiting_confirmation"
+
+var (
+	approveRepo   string
+	approveReject bool
+)
+
+var approveCmd = &cobra.Command{
+	Use:     "approve <issue>",
+	Short:   "Confirm a destructive action awaiting approval",
+	GroupID: "daemon",
+	Long: `Confirms (or rejects) a destructive action — force-push, closing the issue —
+that the orchestrator is holding for approval because the workflow lists it
+in settings.confirm_actions. Pending actions are shown by 'erg status'.
+
+The issue is identified by its tracker ID (e.g. 42 or ENG-123) or by the full
+work item ID. Confirmation can also be given by commenting "/erg confirm" (or
+"/erg reject") on the issue.
+
+Examples:
+  erg approve 42                  # Let the pending action on issue #42 run
+  erg approve 42 --reject         # Refuse it; the step fails instead
+  erg approve ENG-123 --repo /path/to/repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApprove,
+}
+
+func init() {
+	approveCmd.Flags().StringVar(&approveRepo, "repo", "", "Repo whose orchestrator holds the action (owner/repo or filesystem path)")
+	approveCmd.Flags().BoolVar(&approveReject, "reject", false, "Reject the pending action instead of confirming it")
+	rootCmd.AddCommand(approveCmd)
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	repo := approveRepo
+	if repo == "" {
+		resolved, err := resolveAgentRepo(context.Background(), "", session.NewSessionService())
+		if err != nil {
+			repo, err = findSingleRunningDaemon()
+			if err != nil {
+				return err
+			}
+		} else {
+			repo = resolved
+		}
+	}
+
+	state, err := daemonstate.LoadDaemonState(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+	item, err := findPendingConfirmation(state, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := daemonstate.WriteConfirmation(repo, daemonstate.Confirmation{
+		WorkItemID: item.ID,
+		Approved:   !approveReject,
+		At:         time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	verb := "Confirmed"
+	if approveReject {
+		verb = "Rejected"
+	}
+	action, _ := item.StepData["_pending_action"].(string)
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s for %s; the orchestrator applies it on its next tick.\n",
+		verb, action, issueLabel(item.IssueRef, item.ID, 60))
+	return nil
+}
+
+// pendingConfirmations returns the work items awaiting confirmation, sorted by ID.
+func pendingConfirmations(state *daemonstate.DaemonState) []daemonstate.WorkItem {
+	var pending []daemonstate.WorkItem
+	for _, item := range state.GetActiveWorkItems() {
+		if item.Phase == phaseAwaitingConfirmation {
+			pending = append(pending, item)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	return pending
+}
+
+// findPendingConfirmation finds the work item awaiting confirmation that
+// matches ref, either by work item ID or by issue ID.
+func findPendingConfirmation(state *daemonstate.DaemonState, ref string) (daemonstate.WorkItem, error) {
+	pending := pendingConfirmations(state)
+	matches := matchWorkItems(pending, ref)
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		if len(pending) == 0 {
+			return daemonstate.WorkItem{}, fmt.Errorf("no actions are awaiting confirmation")
+		}
+		return daemonstate.WorkItem{}, fmt.Errorf("no action awaiting confirmation for %q (see 'erg status')", ref)
+	default:
+		return daemonstate.WorkItem{}, ambiguousRefError(ref, matches)
+	}
+}
+
+// matchWorkItems returns the items that ref names, either by work item ID or
+// by issue ID (with or without a leading "#").
+func matchWorkItems(items []daemonstate.WorkItem, ref string) []daemonstate.WorkItem {
+	var matches []daemonstate.WorkItem
+	for _, item := range items {
+		if item.ID == ref || strings.EqualFold(item.IssueRef.ID, strings.TrimPrefix(ref, "#")) {
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// ambiguousRefError reports that ref names several work items.
+func ambiguousRefError(ref string, matches []daemonstate.WorkItem) error {
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	return fmt.Errorf("%q matches several work items, use the full ID: %s", ref, strings.Join(ids, ", "))
+}
+
+// printPendingConfirmations lists the destructive actions held for approval,
+// with the command that confirms each one.
+func printPendingConfirmations(w io.Writer, pending []daemonstate.WorkItem) {
+	if len(pending) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Awaiting confirmation:")
+	for _, item := range pending {
+		action, _ := item.StepData["_pending_action"].(string)
+		ref := item.IssueRef.ID
+		if ref == "" {
+			ref = item.ID
+		}
+		fmt.Fprintf(w, "  %s — %s at %s (erg approve %s)\n", issueLabel(item.IssueRef, item.ID, 40), action, item.CurrentStep, ref)
+	}
+}
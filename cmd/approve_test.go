@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+func approveTestState() *daemonstate.DaemonState {
+	state := daemonstate.NewDaemonState("/test/repo")
+	for _, it := range []struct {
+		id, issue, phase string
+	}{
+		{"/test/repo-42", "42", phaseAwaitingConfirmation},
+		{"/test/repo-43", "43", "idle"},
+		{"/other/repo-42", "42", phaseAwaitingConfirmation},
+		{"/test/repo-ENG-7", "ENG-7", phaseAwaitingConfirmation},
+	} {
+		state.AddWorkItem(&daemonstate.WorkItem{
+			ID:          it.id,
+			IssueRef:    config.IssueRef{Source: "github", ID: it.issue, Title: "Issue " + it.issue},
+			CurrentStep: "rebase",
+			StepData:    map[string]any{"_pending_action": "git.rebase"},
+		})
+		state.UpdateWorkItem(it.id, func(w *daemonstate.WorkItem) {
+			w.State = daemonstate.WorkItemActive
+			w.Phase = it.phase
+		})
+	}
+	return state
+}
+
+func TestFindPendingConfirmation(t *testing.T) {
+	state := approveTestState()
+
+	item, err := findPendingConfirmation(state, "eng-7")
+	if err != nil || item.ID != "/test/repo-ENG-7" {
+		t.Errorf("by issue ID: got %q, %v", item.ID, err)
+	}
+	item, err = findPendingConfirmation(state, "/test/repo-42")
+	if err != nil || item.ID != "/test/repo-42" {
+		t.Errorf("by work item ID: got %q, %v", item.ID, err)
+	}
+	if _, err := findPendingConfirmation(state, "#42"); err == nil || !strings.Contains(err.Error(), "several work items") {
+		t.Errorf("expected ambiguity error, got %v", err)
+	}
+	if _, err := findPendingConfirmation(state, "43"); err == nil || !strings.Contains(err.Error(), "no action awaiting confirmation") {
+		t.Errorf("expected not-found error for idle item, got %v", err)
+	}
+	if _, err := findPendingConfirmation(daemonstate.NewDaemonState("/test/repo"), "42"); err == nil || !strings.Contains(err.Error(), "no actions are awaiting") {
+		t.Errorf("expected empty error, got %v", err)
+	}
+}
+
+func TestPrintPendingConfirmations(t *testing.T) {
+	var buf bytes.Buffer
+	printPendingConfirmations(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("expected no output without pending items, got %q", buf.String())
+	}
+
+	printPendingConfirmations(&buf, pendingConfirmations(approveTestState()))
+	out := buf.String()
+	if !strings.HasPrefix(out, "Awaiting confirmation:\n") {
+		t.Errorf("missing header: %q", out)
+	}
+	if strings.Count(out, "git.rebase at rebase") != 3 {
+		t.Errorf("expected three pending actions, got %q", out)
+	}
+	if !strings.Contains(out, "(erg approve ENG-7)") {
+		t.Errorf("expected approve hint, got %q", out)
+	}
+}
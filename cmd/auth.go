@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+var (
+	authSetExec  string
+	authSetStore string
+)
+
+var authCmd = &cobra.Command{
+	Use:     "auth",
+	Short:   "Manage issue tracker credentials",
+	GroupID: "setup",
+	Long: `Stores and inspects the API tokens erg uses for Asana, Linear, GitLab, and
+ClickUp. GitHub uses the gh CLI's own authentication.
+
+Each token is resolved in order from:
+  1. its environment variable (ASANA_PAT, LINEAR_API_KEY, GITLAB_TOKEN, CLICKUP_API_TOKEN)
+  2. the OS keychain (macOS Keychain, or the Secret Service via secret-tool on Linux)
+  3. the encrypted credentials file, ~/.erg/credentials.enc
+
+Any of these may hold an exec: reference instead of the token itself, e.g.
+"exec:op read op://Private/Linear/credential". erg runs the command and uses
+its output, caching the result for a few minutes.`,
+}
+
+var authSetCmd = &cobra.Command{
+	Use:   "set <provider>",
+	Short: "Store a token for an issue tracker",
+	Long: `Stores a token for asana, linear, gitlab, or clickup. The token is read
+from stdin without echo, or use --exec to store a command that prints it.
+
+By default the token goes into the OS keychain when one is available and
+the encrypted credentials file otherwise. The credentials file is encrypted
+with a key kept beside it in ~/.erg/credentials.key, or with a key derived
+from ERG_CREDENTIALS_PASSPHRASE when that is set.
+
+Examples:
+  erg auth set linear
+  erg auth set asana --store file
+  erg auth set linear --exec "op read op://Private/Linear/credential"`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: authProviders(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthSet(os.Stdin, cmd.OutOrStdout(), args[0], authSetExec, authSetStore)
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where each token is found and whether Claude's is valid",
+	Long: `Shows where each issue tracker token resolves from, then the Claude
+credentials session containers are given: their source, and when an OAuth
+access token expires. An expired or soon-to-expire token is refreshed before
+each session starts.
+
+Also lists the erg-auth-* files holding credentials for running containers.
+Each is rewritten when its container starts; the daemon removes any older
+than 6 hours.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printAuthStatus(cmd.OutOrStdout())
+		files, err := claude.ListAuthFiles()
+		if err != nil {
+			return fmt.Errorf("failed to list auth files: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+		formatClaudeAuth(cmd.OutOrStdout(), claude.ClaudeAuthStatus(), files, time.Now())
+		return nil
+	},
+}
+
+var authDeleteCmd = &cobra.Command{
+	Use:       "delete <provider>",
+	Short:     "Remove a stored token from the keychain and credentials file",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: authProviders(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthDelete(cmd.OutOrStdout(), args[0])
+	},
+}
+
+func init() {
+	authSetCmd.Flags().StringVar(&authSetExec, "exec", "", "Store a command whose output is the token instead of the token itself")
+	authSetCmd.Flags().StringVar(&authSetStore, "store", "", "Where to store the token: keychain or file (default: keychain if available)")
+	authCmd.AddCommand(authSetCmd, authStatusCmd, authDeleteCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+func authProviders() []string {
+	names := make([]string, len(secrets.Credentials))
+	for i, c := range secrets.Credentials {
+		names[i] = c.Provider
+	}
+	return names
+}
+
+func lookupAuthProvider(provider string) (secrets.Credential, error) {
+	c, ok := secrets.CredentialFor(strings.ToLower(provider))
+	if !ok {
+		return secrets.Credential{}, fmt.Errorf("unknown provider %q (expected one of: %s)", provider, strings.Join(authProviders(), ", "))
+	}
+	return c, nil
+}
+
+func runAuthSet(input io.Reader, output io.Writer, provider, execCmd, store string) error {
+	c, err := lookupAuthProvider(provider)
+	if err != nil {
+		return err
+	}
+	switch store {
+	case "":
+		store = "file"
+		if secrets.IsKeychainAvailable() {
+			store = "keychain"
+		}
+	case "keychain":
+		if !secrets.IsKeychainAvailable() {
+			return fmt.Errorf("no OS keychain is available on this system; use --store file")
+		}
+	case "file":
+	default:
+		return fmt.Errorf("invalid --store %q: must be keychain or file", store)
+	}
+
+	value := secrets.ExecPrefix + strings.TrimSpace(execCmd)
+	if execCmd == "" {
+		value = promptSecret(bufio.NewScanner(input), input, output, "Paste your "+c.EnvVar)
+		if value == "" {
+			return fmt.Errorf("no token provided")
+		}
+	} else if _, err := secrets.Expand(value); err != nil {
+		return fmt.Errorf("--exec command did not produce a token: %w", err)
+	}
+
+	if store == "keychain" {
+		err = secrets.Set(c.Service, value)
+	} else {
+		err = secrets.FileSet(c.Service, value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", c.EnvVar, err)
+	}
+
+	where := secrets.KeychainName()
+	if store == "file" {
+		where, _ = secrets.CredentialsPath()
+	}
+	fmt.Fprintf(output, "Saved %s to %s.\n", c.EnvVar, where)
+	if os.Getenv(c.EnvVar) != "" {
+		fmt.Fprintf(output, "Note: %s is set in the environment and takes priority.\n", c.EnvVar)
+	}
+	return nil
+}
+
+func runAuthDelete(output io.Writer, provider string) error {
+	c, err := lookupAuthProvider(provider)
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets.Get(c.Service); ok {
+		if err := secrets.Delete(c.Service); err != nil {
+			return fmt.Errorf("failed to remove %s from %s: %w", c.EnvVar, secrets.KeychainName(), err)
+		}
+	}
+	if err := secrets.FileDelete(c.Service); err != nil {
+		return fmt.Errorf("failed to remove %s from credentials file: %w", c.EnvVar, err)
+	}
+	fmt.Fprintf(output, "Removed stored %s.\n", c.EnvVar)
+	return nil
+}
+
+// printAuthStatus lists where each token resolves from. exec: references
+// are reported but not run.
+func printAuthStatus(output io.Writer) {
+	tw := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tENV VAR\tSOURCE")
+	for _, c := range secrets.Credentials {
+		value, source := secrets.Locate(c.EnvVar, c.Service)
+		switch {
+		case source == "":
+			source = "not set"
+		case strings.HasPrefix(value, secrets.ExecPrefix):
+			source += " (exec)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Provider, c.EnvVar, source)
+	}
+	tw.Flush()
+}
+
+// formatClaudeAuth describes the Claude credentials containers get and the
+// erg-auth-* files on disk, as of now.
+func formatClaudeAuth(output io.Writer, st claude.AuthStatus, files []claude.AuthFile, now time.Time) {
+	if st.Source == "" {
+		fmt.Fprintln(output, "Claude: no credentials found (set ANTHROPIC_API_KEY or CLAUDE_CODE_OAUTH_TOKEN, or run 'claude login')")
+	} else {
+		fmt.Fprintf(output, "Claude: %s\n", st.Source)
+		switch {
+		case st.ExpiresAt.IsZero():
+			fmt.Fprintln(output, "Token:  does not expire")
+		case !st.ExpiresAt.After(now) && st.SelfRefreshing:
+			fmt.Fprintf(output, "Token:  expired %s ago (refreshed by the CLI on next use)\n", formatAgeAt(st.ExpiresAt, now))
+		case !st.ExpiresAt.After(now):
+			fmt.Fprintf(output, "Token:  expired %s ago (refreshed before the next session starts)\n", formatAgeAt(st.ExpiresAt, now))
+		default:
+			fmt.Fprintf(output, "Token:  valid, expires in %s\n", formatDuration(st.ExpiresAt.Sub(now)))
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Fprintln(output, "Auth files: none")
+		return
+	}
+	fmt.Fprintln(output, "Auth files:")
+	tw := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  SESSION\tVARS\tAGE\tSTATUS")
+	for _, f := range files {
+		status := "current"
+		if now.Sub(f.ModTime) >= claude.AuthFileTTL {
+			status = "stale"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", f.SessionID, strings.Join(f.Vars, ","), formatAgeAt(f.ModTime, now), status)
+	}
+	tw.Flush()
+}
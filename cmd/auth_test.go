@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+func isolateAuthHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(secrets.PassphraseEnvVar, "")
+	t.Setenv("LINEAR_API_KEY", "")
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+}
+
+func TestRunAuthSet_FileStore(t *testing.T) {
+	isolateAuthHome(t)
+
+	var out bytes.Buffer
+	if err := runAuthSet(strings.NewReader("lin_abc\n"), &out, "Linear", "", "file"); err != nil {
+		t.Fatalf("runAuthSet: %v", err)
+	}
+	if v, ok := secrets.FileGet(secrets.LinearAPIKeyService); !ok || v != "lin_abc" {
+		t.Errorf("stored value = %q, %v", v, ok)
+	}
+	if !strings.Contains(out.String(), "Saved LINEAR_API_KEY") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	printAuthStatus(&out)
+	if !strings.Contains(out.String(), "linear") || !strings.Contains(out.String(), "LINEAR_API_KEY") {
+		t.Errorf("status missing linear row:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runAuthDelete(&out, "linear"); err != nil {
+		t.Fatalf("runAuthDelete: %v", err)
+	}
+	if _, ok := secrets.FileGet(secrets.LinearAPIKeyService); ok {
+		t.Error("expected credential to be removed")
+	}
+}
+
+func TestRunAuthSet_ExecReference(t *testing.T) {
+	isolateAuthHome(t)
+
+	var out bytes.Buffer
+	if err := runAuthSet(strings.NewReader(""), &out, "asana", "printf tok", "file"); err != nil {
+		t.Fatalf("runAuthSet: %v", err)
+	}
+	if v, _ := secrets.FileGet(secrets.AsanaPATService); v != "exec:printf tok" {
+		t.Errorf("stored value = %q, want the exec reference", v)
+	}
+
+	if err := runAuthSet(strings.NewReader(""), &out, "asana", "false", "file"); err == nil {
+		t.Error("expected error for a command that fails")
+	}
+}
+
+func TestRunAuthSet_Errors(t *testing.T) {
+	isolateAuthHome(t)
+
+	tests := []struct {
+		name     string
+		provider string
+		input    string
+		store    string
+		wantErr  string
+	}{
+		{"unknown provider", "jira", "x\n", "file", "unknown provider"},
+		{"bad store", "linear", "x\n", "vault", "invalid --store"},
+		{"empty token", "linear", "\n", "file", "no token provided"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runAuthSet(strings.NewReader(tt.input), &bytes.Buffer{}, tt.provider, "", tt.store)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatClaudeAuth(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	files := []claude.AuthFile{
+		{SessionID: "sess-1", ModTime: now.Add(-5 * time.Minute), Vars: []string{"CLAUDE_CODE_OAUTH_TOKEN", "GH_TOKEN"}},
+		{SessionID: "sess-2", ModTime: now.Add(-claude.AuthFileTTL - time.Hour)},
+	}
+
+	var out bytes.Buffer
+	formatClaudeAuth(&out, claude.AuthStatus{Source: "macOS keychain (Claude Code-credentials)", ExpiresAt: now.Add(90 * time.Minute)}, files, now)
+	for _, want := range []string{"Claude: macOS keychain", "valid, expires in 1h 30m", "sess-1", "CLAUDE_CODE_OAUTH_TOKEN,GH_TOKEN", "5m", "current", "stale"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	formatClaudeAuth(&out, claude.AuthStatus{Source: "keychain", ExpiresAt: now.Add(-2 * time.Hour)}, nil, now)
+	if !strings.Contains(out.String(), "expired 2h ago") || !strings.Contains(out.String(), "Auth files: none") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	formatClaudeAuth(&out, claude.AuthStatus{}, nil, now)
+	if !strings.Contains(out.String(), "no credentials found") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	backfillRepo         string
+	backfillBranchPrefix string
+	backfillLimit        int
+	backfillDryRun       bool
+)
+
+var backfillCmd = &cobra.Command{
+	Use:     "backfill",
+	Short:   "Import past erg PRs into the orchestrator state",
+	GroupID: "daemon",
+	Long: `Scans the repository's pull requests for branches created by erg and
+reconstructs a completed or failed work item for each one, so that
+'erg stats' reflects history from before the orchestrator kept state.
+
+erg branches are identified by the workflow's settings.branch_prefix followed
+by an erg-generated name (issue-<number> for GitHub, linear-<id> for Linear).
+Merged PRs become completed work items; PRs closed without merging become
+failed work items. Open PRs are skipped — the orchestrator picks those up
+itself. Issues that already have a work item in the state are left untouched.
+
+Backfilled items are kept when the orchestrator prunes old terminal items.
+The orchestrator must be stopped while backfilling.
+
+Examples:
+  erg backfill                         # Import from the current repo
+  erg backfill --repo /path/to/repo    # Import from a specific repo
+  erg backfill --dry-run               # Show what would be imported
+  erg backfill --limit 1000            # Scan more PRs`,
+	RunE: runBackfill,
+}
+
+func init() {
+	backfillCmd.Flags().StringVar(&backfillRepo, "repo", "", "Repo to backfill (filesystem path)")
+	backfillCmd.Flags().StringVar(&backfillBranchPrefix, "branch-prefix", "", "Branch prefix of erg branches (default: settings.branch_prefix from the workflow)")
+	backfillCmd.Flags().IntVar(&backfillLimit, "limit", 500, "Maximum number of recent PRs to scan")
+	backfillCmd.Flags().BoolVar(&backfillDryRun, "dry-run", false, "Show what would be imported without writing state")
+	rootCmd.AddCommand(backfillCmd)
+}
+
+func runBackfill(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	repo, err := resolveAgentRepo(ctx, backfillRepo, session.NewSessionService())
+	if err != nil {
+		return err
+	}
+	if info, statErr := os.Stat(repo); statErr != nil || !info.IsDir() {
+		return fmt.Errorf("backfill requires a local repository path, got %q", repo)
+	}
+	if _, running := daemonstate.ReadLockStatus(repo); running {
+		return fmt.Errorf("orchestrator is running for %s — stop it with 'erg stop' before backfilling", repo)
+	}
+
+	prefix := backfillBranchPrefix
+	if prefix == "" {
+		if wfCfg, loadErr := workflow.LoadAndMerge(repo); loadErr == nil && wfCfg.Settings != nil {
+			prefix = wfCfg.Settings.BranchPrefix
+		}
+	}
+
+	prs, err := git.NewGitService().ListPRsByHeadPrefix(ctx, repo, prefix, backfillLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	state, err := daemonstate.LoadDaemonState(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+
+	items := buildBackfillItems(repo, prefix, prs, func(id string) bool {
+		_, ok := state.GetWorkItem(id)
+		return ok
+	})
+
+	out := cmd.OutOrStdout()
+	if len(items) == 0 {
+		fmt.Fprintf(out, "No new erg PRs found among %d scanned.\n", len(prs))
+		return nil
+	}
+	printBackfillItems(out, items)
+
+	if backfillDryRun {
+		fmt.Fprintf(out, "\nDry run: %d work items would be imported.\n", len(items))
+		return nil
+	}
+
+	for _, item := range items {
+		state.AddRebuiltWorkItem(item)
+	}
+	if err := state.Save(); err != nil {
+		return fmt.Errorf("failed to save orchestrator state: %w", err)
+	}
+	fmt.Fprintf(out, "\nImported %d work items.\n", len(items))
+	return nil
+}
+
+var (
+	backfillGitHubBranch = regexp.MustCompile(`^issue-(\d+)$`)
+	backfillLinearBranch = regexp.MustCompile(`^linear-([a-z0-9]+-\d+)$`)
+)
+
+// backfillIssueRef derives the issue a PR was opened for from its head
+// branch. Returns false for branches that don't follow erg's naming scheme.
+func backfillIssueRef(branch, prefix string) (config.IssueRef, bool) {
+	name, ok := strings.CutPrefix(branch, prefix)
+	if !ok {
+		return config.IssueRef{}, false
+	}
+	if m := backfillGitHubBranch.FindStringSubmatch(name); m != nil {
+		return config.IssueRef{Source: string(issues.SourceGitHub), ID: m[1]}, true
+	}
+	if m := backfillLinearBranch.FindStringSubmatch(name); m != nil {
+		return config.IssueRef{Source: string(issues.SourceLinear), ID: strings.ToUpper(m[1])}, true
+	}
+	return config.IssueRef{}, false
+}
+
+// buildBackfillItems converts erg PRs into terminal work items. PRs are
+// expected newest first; only the most recent PR per issue is used. Open PRs
+// and issues for which exists reports true are skipped.
+func buildBackfillItems(repoPath, prefix string, prs []git.PRSummary, exists func(id string) bool) []*daemonstate.WorkItem {
+	var items []*daemonstate.WorkItem
+	seen := make(map[string]bool)
+	for _, pr := range prs {
+		ref, ok := backfillIssueRef(pr.HeadRefName, prefix)
+		if !ok {
+			continue
+		}
+		id := fmt.Sprintf("%s-%s", repoPath, ref.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if exists(id) {
+			continue
+		}
+
+		ref.Title = pr.Title
+		item := &daemonstate.WorkItem{
+			ID:         id,
+			IssueRef:   ref,
+			Phase:      "idle",
+			Branch:     pr.HeadRefName,
+			PRURL:      pr.URL,
+			CreatedAt:  pr.CreatedAt,
+			Backfilled: true,
+			StepData: map[string]any{
+				"_repo_path": repoPath,
+			},
+		}
+		switch pr.State {
+		case git.PRStateMerged:
+			item.State = daemonstate.WorkItemCompleted
+			item.CurrentStep = "done"
+			item.CompletedAt = pr.MergedAt
+		case git.PRStateClosed:
+			item.State = daemonstate.WorkItemFailed
+			item.CurrentStep = "failed"
+			item.CompletedAt = pr.ClosedAt
+			item.ErrorMessage = "PR closed without merging"
+		default:
+			continue
+		}
+		if item.CompletedAt != nil {
+			item.StepEnteredAt = *item.CompletedAt
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// printBackfillItems writes a table of the work items to be imported.
+func printBackfillItems(w io.Writer, items []*daemonstate.WorkItem) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ISSUE\tOUTCOME\tPR")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", issueLabel(item.IssueRef, item.ID, 50), item.State, item.PRURL)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/git"
+)
+
+func TestBackfillIssueRef(t *testing.T) {
+	tests := []struct {
+		branch     string
+		prefix     string
+		wantOK     bool
+		wantSource string
+		wantID     string
+	}{
+		{"issue-42", "", true, "github", "42"},
+		{"erg/issue-42", "erg/", true, "github", "42"},
+		{"issue-42", "erg/", false, "", ""},
+		{"erg/linear-eng-123", "erg/", true, "linear", "ENG-123"},
+		{"erg/feature-x", "erg/", false, "", ""},
+		{"erg/issue-42-extra", "erg/", false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			ref, ok := backfillIssueRef(tt.branch, tt.prefix)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ref.Source != tt.wantSource || ref.ID != tt.wantID {
+				t.Errorf("ref = %+v, want source=%s id=%s", ref, tt.wantSource, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestBuildBackfillItems(t *testing.T) {
+	created := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	merged := created.Add(2 * time.Hour)
+	closed := created.Add(24 * time.Hour)
+
+	prs := []git.PRSummary{
+		{Number: 5, Title: "Retry", URL: "u5", HeadRefName: "issue-1", State: git.PRStateMerged, CreatedAt: created, MergedAt: &merged},
+		{Number: 4, Title: "Open", URL: "u4", HeadRefName: "issue-2", State: git.PRStateOpen, CreatedAt: created},
+		{Number: 3, Title: "Abandoned", URL: "u3", HeadRefName: "issue-3", State: git.PRStateClosed, CreatedAt: created, ClosedAt: &closed},
+		{Number: 2, Title: "Known", URL: "u2", HeadRefName: "issue-4", State: git.PRStateMerged, CreatedAt: created, MergedAt: &merged},
+		{Number: 1, Title: "Older attempt", URL: "u1", HeadRefName: "issue-1", State: git.PRStateClosed, CreatedAt: created, ClosedAt: &closed},
+		{Number: 0, Title: "Human", URL: "u0", HeadRefName: "feature", State: git.PRStateMerged, CreatedAt: created, MergedAt: &merged},
+	}
+
+	items := buildBackfillItems("/repo", "", prs, func(id string) bool { return id == "/repo-4" })
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	done := items[0]
+	if done.ID != "/repo-1" || done.State != daemonstate.WorkItemCompleted || done.CurrentStep != "done" {
+		t.Errorf("unexpected merged item: %+v", done)
+	}
+	if done.PRURL != "u5" {
+		t.Errorf("expected most recent PR for issue, got %s", done.PRURL)
+	}
+	if done.CompletedAt == nil || !done.CompletedAt.Equal(merged) || !done.CreatedAt.Equal(created) {
+		t.Errorf("unexpected timestamps: created=%v completed=%v", done.CreatedAt, done.CompletedAt)
+	}
+	if !done.Backfilled {
+		t.Error("expected item to be marked backfilled")
+	}
+
+	failed := items[1]
+	if failed.ID != "/repo-3" || failed.State != daemonstate.WorkItemFailed || failed.ErrorMessage == "" {
+		t.Errorf("unexpected closed item: %+v", failed)
+	}
+
+	var buf bytes.Buffer
+	printBackfillItems(&buf, items)
+	if !strings.Contains(buf.String(), "u3") {
+		t.Errorf("expected PR URL in output, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/cli"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemon"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/logger"
+	"github.com/zhubert/erg/internal/session"
+)
+
+var (
+	batchLabel        string
+	batchMax          int
+	batchRate         string
+	batchRepo         string
+	batchWorkflowFile string
+)
+
+var batchCmd = &cobra.Command{
+	Use:     "batch",
+	Short:   "Work through a labeled backlog of issues, throttled",
+	GroupID: "daemon",
+	Long: `Processes a set of issues carrying a label one at a time, in the foreground,
+and prints a summary report when done. Meant for one-off backlog burn-downs
+(dependency bumps, lint cleanups, small refactors) rather than steady-state
+operation.
+
+Issues are fetched once up front and taken most urgent first, up to --max.
+Each runs through the workflow like 'erg run', up to opening its PR. The
+container image, provider clients, and orchestrator state are set up once
+and shared by every issue in the batch.
+
+--rate spaces out the start of each issue, e.g. 5/hour starts one every 12
+minutes. The period may be second, minute, hour, day, or a Go duration
+(10/30m).
+
+Batch state is kept per repo and label, so re-running the same batch after
+an interruption skips issues it already processed.`,
+	Example: `  erg batch --label cleanup
+  erg batch --label cleanup --max 50 --rate 5/hour
+  erg batch --label deps --repo /path/to/repo --workflow .erg/deps.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runBatch,
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchLabel, "label", "", "Label (tag in Asana and ClickUp) selecting the issues to process (required)")
+	batchCmd.Flags().IntVar(&batchMax, "max", 50, "Maximum number of issues to process")
+	batchCmd.Flags().StringVar(&batchRate, "rate", "", "Maximum rate of issue starts, e.g. 5/hour (default: no throttling)")
+	batchCmd.Flags().StringVar(&batchRepo, "repo", "", "Repo path (default: current git root)")
+	batchCmd.Flags().StringVar(&batchWorkflowFile, "workflow", "", "Path to workflow config file")
+	_ = batchCmd.MarkFlagRequired("label")
+	rootCmd.AddCommand(batchCmd)
+}
+
+func runBatch(cmd *cobra.Command, _ []string) error {
+	if batchMax <= 0 {
+		return fmt.Errorf("--max must be positive")
+	}
+	interval, err := parseRate(batchRate)
+	if err != nil {
+		return err
+	}
+
+	prereqs := cli.DefaultPrerequisites()
+	if err := cli.ValidateRequired(prereqs); err != nil {
+		return fmt.Errorf("%w\n\nInstall required tools and try again", err)
+	}
+	if !hasContainerRuntime() {
+		return fmt.Errorf("a container runtime is required for agent mode.\nInstall OrbStack: https://orbstack.dev\nInstall Docker:   https://docs.docker.com/get-docker/\nInstall Colima:   https://github.com/abiosoft/colima\nInstall Podman:   https://podman.io")
+	}
+	if err := checkDockerDaemon(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	sessSvc := session.NewSessionService()
+	repoPath, err := resolveAgentRepo(ctx, batchRepo, sessSvc)
+	if err != nil {
+		return err
+	}
+
+	// Use a per-label lock/state key so a batch neither conflicts with a
+	// running daemon on the same repo nor with a batch for another label.
+	batchKey := fmt.Sprintf("batch-%s-%s", repoPath, batchLabel)
+	if _, running := daemonstate.ReadLockStatus(batchKey); running {
+		return fmt.Errorf("a batch for label %q is already running on %s", batchLabel, repoPath)
+	}
+
+	logger.SetDebug(true)
+	defer logger.Close()
+	runLogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	env, err := newRunEnv(ctx, repoPath, batchWorkflowFile, runLogger)
+	if err != nil {
+		return err
+	}
+	provider := issues.Source(env.wfCfg.Source.Provider)
+	if provider == "" {
+		provider = issues.SourceGitHub
+	}
+
+	runLogger.Info("fetching batch issues", "provider", provider, "label", batchLabel)
+	fetched, err := fetchBatchIssues(ctx, env, repoPath, provider)
+	if err != nil {
+		return fmt.Errorf("failed to fetch issues labeled %q: %w", batchLabel, err)
+	}
+
+	state, err := daemonstate.LoadDaemonState(batchKey)
+	if err != nil {
+		state = daemonstate.NewDaemonState(batchKey)
+	}
+	ordering := issues.Ordering{
+		Policy:         env.wfCfg.Source.Order,
+		LabelWeights:   env.wfCfg.Source.LabelWeights,
+		DeadlineWindow: env.wfCfg.DeadlineWindow(),
+	}
+	selected, skipped := selectBatchIssues(fetched, state, provider, ordering, batchMax)
+	if skipped > 0 {
+		runLogger.Info("skipping issues processed by an earlier batch", "count", skipped)
+	}
+	runLogger.Info("starting batch", "issues", len(selected), "interval", interval)
+
+	throttle := &batchThrottle{interval: interval}
+	var results []batchResult
+	for _, issue := range selected {
+		if err := throttle.wait(ctx); err != nil {
+			break
+		}
+
+		runLogger.Info("processing batch issue", "id", issue.ID, "title", issue.Title,
+			"n", len(results)+1, "of", len(selected))
+		start := time.Now()
+		opts := []daemon.Option{
+			daemon.WithOnce(true),
+			daemon.WithRepoFilter(repoPath),
+			daemon.WithPreseededIssue(issue),
+			daemon.WithDaemonID(batchKey),
+		}
+		if env.wfCfg.Settings != nil && env.wfCfg.Settings.AutoMerge != nil {
+			opts = append(opts, daemon.WithAutoMerge(*env.wfCfg.Settings.AutoMerge))
+		}
+		if batchWorkflowFile != "" {
+			opts = append(opts, daemon.WithWorkflowFile(batchWorkflowFile))
+		}
+		if env.minterOpt != nil {
+			opts = append(opts, env.minterOpt)
+		}
+
+		d := daemon.New(env.cfg, env.gitSvc, sessSvc, env.registry, runLogger, opts...)
+		runErr := d.Run(ctx)
+
+		if state, err = daemonstate.LoadDaemonState(batchKey); err != nil {
+			runLogger.Warn("failed to reload batch state", "error", err)
+			state = daemonstate.NewDaemonState(batchKey)
+		}
+		result := newBatchResult(state, repoPath, issue, time.Since(start))
+		if runErr != nil && ctx.Err() == nil {
+			result.Outcome = "error: " + runErr.Error()
+		}
+		results = append(results, result)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	printBatchSummary(cmd.OutOrStdout(), batchLabel, results, len(selected))
+	return nil
+}
+
+// fetchBatchIssues lists the open issues carrying batchLabel. GitHub is
+// queried by label through the gh CLI, as the daemon does; the other
+// providers filter by label themselves.
+func fetchBatchIssues(ctx context.Context, env *runEnv, repoPath string, provider issues.Source) ([]issues.Issue, error) {
+	if provider == issues.SourceGitHub {
+		ghIssues, err := env.gitSvc.FetchGitHubIssuesWithLabel(ctx, repoPath, batchLabel, env.wfCfg.Source.Filter.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]issues.Issue, 0, len(ghIssues))
+		for _, gh := range ghIssues {
+			result = append(result, issues.FromGitHubIssue(gh))
+		}
+		return result, nil
+	}
+
+	p := env.registry.GetProvider(provider)
+	if p == nil {
+		return nil, fmt.Errorf("provider %q not registered", provider)
+	}
+	filter := env.wfCfg.Source.Filter
+	return p.FetchIssues(ctx, repoPath, issues.FilterConfig{
+		Label:    batchLabel,
+		Project:  filter.Project,
+		Team:     filter.Team,
+		Section:  filter.Section,
+		List:     filter.List,
+		Assignee: filter.Assignee,
+		MaxItems: filter.MaxItems,
+	})
+}
+
+// selectBatchIssues orders fetched issues by the repo's queue ordering and
+// returns up to max of them that the batch state has no work item for, along
+// with the number skipped because an earlier run already processed them.
+func selectBatchIssues(fetched []issues.Issue, state *daemonstate.DaemonState, provider issues.Source, ordering issues.Ordering, max int) ([]issues.Issue, int) {
+	sorted := slices.Clone(fetched)
+	slices.SortStableFunc(sorted, ordering.Compare)
+
+	var selected []issues.Issue
+	skipped := 0
+	for _, issue := range sorted {
+		if state.HasWorkItemForIssue(string(provider), issue.ID) {
+			skipped++
+			continue
+		}
+		if len(selected) < max {
+			selected = append(selected, issue)
+		}
+	}
+	return selected, skipped
+}
+
+// parseRate parses a --rate value such as "5/hour" or "10/30m" into the
+// interval between issue starts. An empty rate means no throttling.
+func parseRate(rate string) (time.Duration, error) {
+	if rate == "" {
+		return 0, nil
+	}
+	countStr, periodStr, ok := strings.Cut(rate, "/")
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if !ok || err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid --rate %q: want <count>/<period>, e.g. 5/hour", rate)
+	}
+
+	var period time.Duration
+	switch strings.ToLower(strings.TrimSpace(periodStr)) {
+	case "s", "sec", "second":
+		period = time.Second
+	case "m", "min", "minute":
+		period = time.Minute
+	case "h", "hr", "hour":
+		period = time.Hour
+	case "d", "day":
+		period = 24 * time.Hour
+	default:
+		period, err = time.ParseDuration(strings.TrimSpace(periodStr))
+		if err != nil || period <= 0 {
+			return 0, fmt.Errorf("invalid --rate %q: period must be second, minute, hour, day, or a duration", rate)
+		}
+	}
+	return period / time.Duration(count), nil
+}
+
+// batchThrottle spaces issue starts at least interval apart.
+type batchThrottle struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next start is allowed, returning ctx's error if it
+// is cancelled first.
+func (t *batchThrottle) wait(ctx context.Context) error {
+	if d := time.Until(t.next); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	t.next = time.Now().Add(t.interval)
+	return nil
+}
+
+// batchResult records how one issue in a batch turned out.
+type batchResult struct {
+	Issue    issues.Issue
+	Outcome  string
+	PRURL    string
+	CostUSD  float64
+	Duration time.Duration
+}
+
+// newBatchResult reads an issue's outcome from the batch state after its run.
+func newBatchResult(state *daemonstate.DaemonState, repoPath string, issue issues.Issue, elapsed time.Duration) batchResult {
+	result := batchResult{Issue: issue, Duration: elapsed}
+	item, ok := state.GetWorkItem(fmt.Sprintf("%s-%s", repoPath, issue.ID))
+	if !ok {
+		// Not queued: claimed by another daemon or already addressed by a PR.
+		result.Outcome = "skipped"
+		return result
+	}
+	result.PRURL = item.PRURL
+	result.CostUSD = item.CostUSD
+	switch {
+	case item.State == daemonstate.WorkItemFailed:
+		result.Outcome = "failed"
+		if item.ErrorMessage != "" {
+			result.Outcome += ": " + item.ErrorMessage
+		}
+	case item.State == daemonstate.WorkItemCompleted:
+		result.Outcome = "completed"
+	case item.PRURL != "":
+		result.Outcome = "PR opened"
+	default:
+		result.Outcome = "stopped at " + item.CurrentStep
+	}
+	return result
+}
+
+// printBatchSummary writes the per-issue report and totals for a batch.
+func printBatchSummary(w io.Writer, label string, results []batchResult, selected int) {
+	fmt.Fprintf(w, "\nBatch summary (label %q)\n\n", label)
+	if len(results) == 0 {
+		fmt.Fprintln(w, "  No issues processed.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ISSUE\tOUTCOME\tCOST\tTIME\tPR")
+	var cost float64
+	var elapsed time.Duration
+	var prs, failed int
+	for _, r := range results {
+		ref := config.IssueRef{Source: string(r.Issue.Source), ID: r.Issue.ID, Title: r.Issue.Title}
+		fmt.Fprintf(tw, "  %s\t%s\t$%.2f\t%s\t%s\n",
+			issueLabel(ref, r.Issue.ID, 40), r.Outcome, r.CostUSD, formatDuration(r.Duration), r.PRURL)
+		cost += r.CostUSD
+		elapsed += r.Duration
+		if r.PRURL != "" {
+			prs++
+		}
+		if strings.HasPrefix(r.Outcome, "failed") || strings.HasPrefix(r.Outcome, "error") {
+			failed++
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nProcessed %d of %d issues: %d PRs, %d failed, $%.2f spent in %s\n",
+		len(results), selected, prs, failed, cost, formatDuration(elapsed))
+}
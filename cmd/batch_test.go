@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+)
+
+func TestBatchCmd_FlagRegistration(t *testing.T) {
+	for _, name := range []string{"label", "max", "rate", "repo", "workflow"} {
+		if batchCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be registered", name)
+		}
+	}
+	if batchCmd.GroupID != "daemon" {
+		t.Errorf("expected GroupID 'daemon', got %q", batchCmd.GroupID)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"5/hour", 12 * time.Minute, false},
+		{"1/day", 24 * time.Hour, false},
+		{"2/m", 30 * time.Second, false},
+		{"10/30m", 3 * time.Minute, false},
+		{"5", 0, true},
+		{"0/hour", 0, true},
+		{"x/hour", 0, true},
+		{"5/fortnight", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRate(tt.rate)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRate(%q) error = %v, wantErr %v", tt.rate, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRate(%q) = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestSelectBatchIssues(t *testing.T) {
+	state := daemonstate.NewDaemonState("batch-test")
+	state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "/repo-2",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-2"},
+	})
+
+	fetched := []issues.Issue{
+		{ID: "ENG-1"},
+		{ID: "ENG-2", Priority: 1},
+		{ID: "ENG-3", Priority: 3},
+		{ID: "ENG-4", Priority: 2},
+	}
+	selected, skipped := selectBatchIssues(fetched, state, issues.SourceLinear, issues.Ordering{}, 2)
+
+	if skipped != 1 {
+		t.Errorf("expected 1 issue skipped as already processed, got %d", skipped)
+	}
+	if len(selected) != 2 || selected[0].ID != "ENG-4" || selected[1].ID != "ENG-3" {
+		t.Errorf("expected [ENG-4 ENG-3], got %+v", selected)
+	}
+}
+
+func TestNewBatchResult(t *testing.T) {
+	state := daemonstate.NewDaemonState("batch-test")
+	state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "/repo-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "1"},
+		PRURL:    "https://github.com/o/r/pull/9",
+		CostUSD:  0.5,
+	})
+	state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "/repo-2",
+		IssueRef: config.IssueRef{Source: "github", ID: "2"},
+	})
+	state.UpdateWorkItem("/repo-2", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemFailed
+		it.ErrorMessage = "max turns"
+	})
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"1", "PR opened"},
+		{"2", "failed: max turns"},
+		{"3", "skipped"},
+	}
+	for _, tt := range tests {
+		r := newBatchResult(state, "/repo", issues.Issue{ID: tt.id}, time.Minute)
+		if r.Outcome != tt.want {
+			t.Errorf("issue %s: outcome = %q, want %q", tt.id, r.Outcome, tt.want)
+		}
+	}
+}
+
+func TestPrintBatchSummary(t *testing.T) {
+	var buf bytes.Buffer
+	printBatchSummary(&buf, "cleanup", []batchResult{
+		{Issue: issues.Issue{ID: "1", Title: "Bump deps", Source: issues.SourceGitHub}, Outcome: "PR opened", PRURL: "https://github.com/o/r/pull/9", CostUSD: 1.25, Duration: 10 * time.Minute},
+		{Issue: issues.Issue{ID: "2", Title: "Fix lint", Source: issues.SourceGitHub}, Outcome: "failed: max turns", CostUSD: 0.75, Duration: 5 * time.Minute},
+	}, 3)
+
+	out := buf.String()
+	for _, want := range []string{`label "cleanup"`, "#1 Bump deps", "failed: max turns", "Processed 2 of 3 issues: 1 PRs, 1 failed, $2.00 spent in 15m"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestBatchThrottle(t *testing.T) {
+	throttle := &batchThrottle{interval: time.Hour}
+	if err := throttle.wait(context.Background()); err != nil {
+		t.Fatalf("first start should not wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := throttle.wait(ctx); err == nil {
+		t.Error("expected second start to wait for the interval and give up on cancellation")
+	}
+}
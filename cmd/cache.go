@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/container"
+)
+
+var (
+	cacheRepo             string
+	cachePruneSkipConfirm bool
+)
+
+// Overridden in tests.
+var (
+	listCacheVolumesFunc  = container.ListCacheVolumes
+	removeCacheVolumeFunc = container.RemoveCacheVolume
+	cacheRuntimeCheckFunc = checkDockerDaemon
+)
+
+var cacheCmd = &cobra.Command{
+	Use:     "cache",
+	Short:   "Inspect and prune dependency cache volumes",
+	GroupID: "setup",
+	Long: `Session containers mount a named volume per repo for each package manager's
+download cache (Go modules and build cache, npm, pip, cargo and Gradle), so
+repeated sessions don't download the same dependencies again. The volumes
+grow as dependencies change; prune them to reclaim the space.
+
+  list   Show the cache volumes and their sizes.
+  prune  Remove them; sessions start with empty caches again.
+
+Both act on every repo's caches unless --repo names one.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dependency cache volumes and their sizes",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		vols, err := loadCacheVolumes(c.Context())
+		if err != nil {
+			return err
+		}
+		formatCacheVolumes(c.OutOrStdout(), vols)
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove dependency cache volumes",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		return runCachePrune(c.Context(), c.OutOrStdout(), os.Stdin)
+	},
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheRepo, "repo", "", "Only the caches of this repo (filesystem path)")
+	cachePruneCmd.Flags().BoolVarP(&cachePruneSkipConfirm, "yes", "y", false, "Skip confirmation prompt")
+	cacheCmd.AddCommand(cacheListCmd, cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// loadCacheVolumes returns the cache volumes selected by --repo.
+func loadCacheVolumes(ctx context.Context) ([]container.CacheVolume, error) {
+	if err := cacheRuntimeCheckFunc(); err != nil {
+		return nil, err
+	}
+	prefix := container.CacheVolumePrefix
+	if cacheRepo != "" {
+		abs, err := filepath.Abs(cacheRepo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repo path %q: %w", cacheRepo, err)
+		}
+		prefix = container.CacheVolumeRepoPrefix(abs)
+	}
+	return listCacheVolumesFunc(ctx, prefix)
+}
+
+// runCachePrune removes the selected cache volumes after confirmation and
+// reports the space reclaimed.
+func runCachePrune(ctx context.Context, w io.Writer, input io.Reader) error {
+	vols, err := loadCacheVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(vols) == 0 {
+		fmt.Fprintln(w, "No dependency cache volumes.")
+		return nil
+	}
+	formatCacheVolumes(w, vols)
+	if !cachePruneSkipConfirm && !confirm(input, "Remove these volumes?") {
+		fmt.Fprintln(w, "Aborted.")
+		return nil
+	}
+
+	var removed int
+	var reclaimed int64
+	var failed []string
+	for _, v := range vols {
+		if err := removeCacheVolumeFunc(ctx, v.Name); err != nil {
+			failed = append(failed, v.Name)
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		removed++
+		if v.Size > 0 {
+			reclaimed += v.Size
+		}
+	}
+	fmt.Fprintf(w, "Removed %d volume(s), reclaiming %s.\n", removed, formatBytes(reclaimed))
+	if len(failed) > 0 {
+		return fmt.Errorf("could not remove %d volume(s), likely in use by running sessions: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// formatCacheVolumes writes the cache volumes and their total size as a
+// table.
+func formatCacheVolumes(w io.Writer, vols []container.CacheVolume) {
+	if len(vols) == 0 {
+		fmt.Fprintln(w, "No dependency cache volumes.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VOLUME\tSIZE")
+	var total int64
+	unknown := false
+	for _, v := range vols {
+		size := "?"
+		if v.Size >= 0 {
+			size = formatBytes(v.Size)
+			total += v.Size
+		} else {
+			unknown = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", v.Name, size)
+	}
+	tw.Flush()
+	suffix := ""
+	if unknown {
+		suffix = " (the runtime did not report every volume's size)"
+	}
+	fmt.Fprintf(w, "Total: %s in %d volume(s)%s\n", formatBytes(total), len(vols), suffix)
+}
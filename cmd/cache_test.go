@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/container"
+)
+
+func stubCacheVolumes(t *testing.T, vols []container.CacheVolume, removeErr map[string]error) *[]string {
+	t.Helper()
+	origList, origRemove, origCheck := listCacheVolumesFunc, removeCacheVolumeFunc, cacheRuntimeCheckFunc
+	origRepo, origYes := cacheRepo, cachePruneSkipConfirm
+	t.Cleanup(func() {
+		listCacheVolumesFunc, removeCacheVolumeFunc, cacheRuntimeCheckFunc = origList, origRemove, origCheck
+		cacheRepo, cachePruneSkipConfirm = origRepo, origYes
+	})
+
+	var removed []string
+	cacheRuntimeCheckFunc = func() error { return nil }
+	listCacheVolumesFunc = func(_ context.Context, prefix string) ([]container.CacheVolume, error) {
+		var out []container.CacheVolume
+		for _, v := range vols {
+			if strings.HasPrefix(v.Name, prefix) {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	}
+	removeCacheVolumeFunc = func(_ context.Context, name string) error {
+		if err := removeErr[name]; err != nil {
+			return err
+		}
+		removed = append(removed, name)
+		return nil
+	}
+	return &removed
+}
+
+func TestRunCachePrune(t *testing.T) {
+	app := container.CacheVolumeRepoPrefix("/src/app")
+	web := container.CacheVolumeRepoPrefix("/src/web")
+	removed := stubCacheVolumes(t, []container.CacheVolume{
+		{Name: app + "go", Size: 3 << 30},
+		{Name: app + "npm", Size: -1},
+		{Name: web + "npm", Size: 1 << 20},
+	}, nil)
+	cacheRepo = "/src/app"
+	cachePruneSkipConfirm = true
+
+	var out bytes.Buffer
+	if err := runCachePrune(context.Background(), &out, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{app + "go", app + "npm"}; !slices.Equal(*removed, want) {
+		t.Errorf("removed %v, want %v", *removed, want)
+	}
+	for _, want := range []string{"3.0GiB", "?", "Total: 3.0GiB in 2 volume(s) (the runtime did not report", "Removed 2 volume(s), reclaiming 3.0GiB."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunCachePrune_DeclinedAndInUse(t *testing.T) {
+	app := container.CacheVolumeRepoPrefix("/src/app")
+	removed := stubCacheVolumes(t, []container.CacheVolume{{Name: app + "go", Size: 10}, {Name: app + "pip", Size: 5}},
+		map[string]error{app + "go": errors.New("volume is in use")})
+
+	var out bytes.Buffer
+	if err := runCachePrune(context.Background(), &out, strings.NewReader("n\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*removed) != 0 || !strings.Contains(out.String(), "Aborted.") {
+		t.Errorf("expected nothing removed when declined, removed %v:\n%s", *removed, out.String())
+	}
+
+	out.Reset()
+	err := runCachePrune(context.Background(), &out, strings.NewReader("y\n"))
+	if err == nil || !strings.Contains(err.Error(), app+"go") {
+		t.Errorf("expected the in-use volume reported, got %v", err)
+	}
+	if !slices.Equal(*removed, []string{app + "pip"}) || !strings.Contains(out.String(), "reclaiming 5B") {
+		t.Errorf("expected the free volume removed, removed %v:\n%s", *removed, out.String())
+	}
+}
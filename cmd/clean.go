@@ -91,7 +91,7 @@ func runAgentCleanWithReader(input io.Reader) error {
 	// Print summary
 	fmt.Println("This will clean:")
 	if stateExists {
-		fmt.Println("  - Orchestrator state file (daemon-state.json)")
+		fmt.Println("  - Orchestrator state file (daemon-state)")
 	}
 	if len(lockFiles) > 0 {
 		fmt.Printf("  - %d orchestrator lock file(s)\n", len(lockFiles))
@@ -263,7 +263,7 @@ func buildProviderSetupText(provider string) string {
 		b.WriteString("  3. Give it a description (e.g., \"erg\") and copy the token\n\n")
 		if secrets.IsKeychainAvailable() {
 			b.WriteString("You can either:\n")
-			b.WriteString("  a) Store it in the macOS Keychain (recommended for brew services) —\n")
+			b.WriteString("  a) Store it in the " + secrets.KeychainName() + " (recommended for background services) —\n")
 			b.WriteString("     you'll be prompted in the next step\n")
 			b.WriteString("  b) Add it to your shell profile (~/.zshrc or ~/.bashrc):\n")
 			b.WriteString("       export ASANA_PAT=\"your-token-here\"\n\n")
@@ -281,7 +281,7 @@ func buildProviderSetupText(provider string) string {
 		b.WriteString("  3. Click \"New API key\", give it a name (e.g., \"erg\"), and copy the key\n\n")
 		if secrets.IsKeychainAvailable() {
 			b.WriteString("You can either:\n")
-			b.WriteString("  a) Store it in the macOS Keychain (recommended for brew services) —\n")
+			b.WriteString("  a) Store it in the " + secrets.KeychainName() + " (recommended for background services) —\n")
 			b.WriteString("     you'll be prompted in the next step\n")
 			b.WriteString("  b) Add it to your shell profile (~/.zshrc or ~/.bashrc):\n")
 			b.WriteString("       export LINEAR_API_KEY=\"your-key-here\"\n\n")
@@ -398,22 +398,22 @@ func promptSelect(scanner *bufio.Scanner, output io.Writer, prompt string, optio
 	return ""
 }
 
-// promptKeychainStore offers to store a secret in the macOS Keychain.
-// No-op on non-macOS platforms.
+// promptKeychainStore offers to store a secret in the OS keychain.
+// No-op when no keychain is available.
 func promptKeychainStore(scanner *bufio.Scanner, input io.Reader, output io.Writer, displayName, envVar, service string) {
 	if !secrets.IsKeychainAvailable() {
 		return
 	}
-	fmt.Fprintf(output, "You can store your %s in the macOS Keychain\n", displayName)
-	fmt.Fprintln(output, "so erg works via brew services without shell env vars.")
+	fmt.Fprintf(output, "You can store your %s in the %s\n", displayName, secrets.KeychainName())
+	fmt.Fprintln(output, "so erg works as a background service without shell env vars.")
 	fmt.Fprintln(output)
 	if promptYN(scanner, output, "Store "+envVar+" in Keychain?", true) {
 		val := promptSecret(scanner, input, output, "Paste your "+displayName)
 		if val != "" {
 			if err := secrets.Set(service, val); err != nil {
-				fmt.Fprintf(output, "Warning: failed to store in Keychain: %v\n", err)
+				fmt.Fprintf(output, "Warning: failed to store in %s: %v\n", secrets.KeychainName(), err)
 			} else {
-				fmt.Fprintln(output, "Saved to macOS Keychain.")
+				fmt.Fprintf(output, "Saved to %s.\n", secrets.KeychainName())
 			}
 		}
 	}
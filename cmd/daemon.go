@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/session"
+)
+
+var (
+	daemonRepo      string
+	daemonListAll   bool
+	daemonQueueRepo string
+	daemonLogLines  int
+)
+
+var daemonCmd = &cobra.Command{
+	Use:     "daemon",
+	Short:   "Control the running orchestrator",
+	GroupID: "daemon",
+	Long: `Control a running orchestrator through its control socket, for maintenance
+without killing active sessions.
+
+  pause    Stop picking up new issues. In-flight items keep going through
+           their workflow. A paused orchestrator stays paused across restarts.
+  drain    Pause, and exit once no sessions are running. Items waiting on CI
+           or review are saved and continue on the next start.
+  resume   Start picking up new issues again.
+
+It also answers for the work it holds in memory, which can be ahead of what
+'erg status' reads from the saved state:
+
+  list     Show in-flight work items.
+  queue    Queue an issue now, without waiting for its label or the next poll.
+  cancel   Stop a work item's session and fail it for good.
+  retry    Queue a failed, completed, or quarantined work item again.
+  logs     Show the end of a work item's session log.
+  spend    Show what sessions spent today, this week, and in total.
+
+Work items are named by their ID or by their issue's number.
+
+The control socket speaks newline-terminated JSON, one request per
+connection, so other tools can drive the orchestrator the same way.`,
+}
+
+var daemonPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Stop picking up new work; in-flight items continue",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonControl(control.CommandPause),
+}
+
+var daemonDrainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Finish running sessions, then exit",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonControl(control.CommandDrain),
+}
+
+var daemonResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Pick up new work again after pause or drain",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonControl(control.CommandResume),
+}
+
+var daemonListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the orchestrator's work items",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		resp, err := sendDaemonRequest(daemonRepo, control.Request{Command: control.CommandList})
+		if err != nil {
+			return err
+		}
+		formatDaemonItems(c.OutOrStdout(), resp.Items, daemonListAll)
+		return nil
+	},
+}
+
+var daemonQueueCmd = &cobra.Command{
+	Use:   "queue <issue>",
+	Short: "Queue an issue now, skipping the label and poll",
+	Long: `Queues an issue on the orchestrator's next tick, as 'erg run' would, without
+waiting for its label or the next poll. It still counts against the
+concurrency limit. Use --into to choose the repo when the orchestrator
+manages several.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return runDaemonItemRequest(c, control.Request{Command: control.CommandQueue, Issue: args[0], Repo: daemonQueueRepo})
+	},
+}
+
+var daemonCancelCmd = &cobra.Command{
+	Use:   "cancel <item>",
+	Short: "Stop a work item's session and fail it",
+	Long: `Stops the work item's session and marks it failed. The issue is unqueued so
+it is not picked up again until it is relabeled.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return runDaemonItemRequest(c, control.Request{Command: control.CommandCancel, Item: args[0]})
+	},
+}
+
+var daemonRetryCmd = &cobra.Command{
+	Use:   "retry <item>",
+	Short: "Queue a failed, completed, or quarantined work item again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return runDaemonItemRequest(c, control.Request{Command: control.CommandRetry, Item: args[0]})
+	},
+}
+
+var daemonLogsCmd = &cobra.Command{
+	Use:   "logs <item>",
+	Short: "Show the end of a work item's session log",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		resp, err := sendDaemonRequest(daemonRepo, control.Request{Command: control.CommandLogs, Item: args[0], Lines: daemonLogLines})
+		if err != nil {
+			return err
+		}
+		for _, line := range resp.Log {
+			fmt.Fprintln(c.OutOrStdout(), line)
+		}
+		return nil
+	},
+}
+
+var daemonSpendCmd = &cobra.Command{
+	Use:   "spend",
+	Short: "Show what the orchestrator's sessions spent",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		resp, err := sendDaemonRequest(daemonRepo, control.Request{Command: control.CommandSpend})
+		if err != nil {
+			return err
+		}
+		formatDaemonSpend(c.OutOrStdout(), resp.Spend)
+		return nil
+	},
+}
+
+func init() {
+	daemonCmd.PersistentFlags().StringVar(&daemonRepo, "repo", "", "Repo whose orchestrator to control (owner/repo or filesystem path)")
+	daemonListCmd.Flags().BoolVar(&daemonListAll, "all", false, "Include completed and failed work items")
+	daemonQueueCmd.Flags().StringVar(&daemonQueueRepo, "into", "", "Repo to queue the issue in (owner/repo or filesystem path)")
+	daemonLogsCmd.Flags().IntVarP(&daemonLogLines, "lines", "n", 50, "Number of log lines to show")
+	daemonCmd.AddCommand(daemonPauseCmd, daemonDrainCmd, daemonResumeCmd,
+		daemonListCmd, daemonQueueCmd, daemonCancelCmd, daemonRetryCmd, daemonLogsCmd, daemonSpendCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// sendDaemonRequest sends req to the control socket of the orchestrator
+// for repo (see resolveDaemonRepo) and returns its answer.
+func sendDaemonRequest(repo string, req control.Request) (control.Response, error) {
+	repo, err := resolveDaemonRepo(repo)
+	if err != nil {
+		return control.Response{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	resp, err := control.Send(ctx, control.SocketPath(repo), req)
+	if err != nil {
+		return control.Response{}, fmt.Errorf("%w\n\nIs the orchestrator for %s running? Check with 'erg status'", err, repo)
+	}
+	return resp, nil
+}
+
+// resolveDaemonRepo returns the repo named by a --repo flag or, when it is
+// empty, the repo of the current directory or the single running
+// orchestrator.
+func resolveDaemonRepo(repo string) (string, error) {
+	if repo != "" {
+		return repo, nil
+	}
+	if resolved, err := resolveAgentRepo(context.Background(), "", session.NewSessionService()); err == nil {
+		return resolved, nil
+	}
+	return findSingleRunningDaemon()
+}
+
+// runDaemonControl returns a command that sends cmd to the orchestrator's
+// control socket and reports its answer.
+func runDaemonControl(cmd control.Command) func(*cobra.Command, []string) error {
+	return func(c *cobra.Command, args []string) error {
+		resp, err := sendDaemonRequest(daemonRepo, control.Request{Command: cmd})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.OutOrStdout(), describeControlResponse(cmd, resp))
+		return nil
+	}
+}
+
+// runDaemonItemRequest sends a request about one work item or issue and
+// prints what the orchestrator did.
+func runDaemonItemRequest(c *cobra.Command, req control.Request) error {
+	resp, err := sendDaemonRequest(daemonRepo, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.OutOrStdout(), resp.Message)
+	return nil
+}
+
+// formatDaemonItems writes work items as a table. Finished items are left
+// out unless all is set.
+func formatDaemonItems(w io.Writer, items []control.Item, all bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ITEM\tISSUE\tSTATE\tSTEP\tCOST\tTITLE")
+	shown := 0
+	for _, it := range items {
+		if !all && (it.State == "completed" || it.State == "failed") {
+			continue
+		}
+		state := it.State
+		if it.Phase != "" && it.Phase != "idle" {
+			state += "/" + it.Phase
+		}
+		fmt.Fprintf(tw, "%s\t#%s\t%s\t%s\t$%.2f\t%s\n", it.ID, it.Issue, state, it.Step, it.CostUSD, it.Title)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Fprintln(w, "No work items.")
+		return
+	}
+	tw.Flush()
+}
+
+// formatDaemonSpend writes the orchestrator's spend totals.
+func formatDaemonSpend(w io.Writer, s *control.Spend) {
+	if s == nil {
+		s = &control.Spend{}
+	}
+	fmt.Fprintf(w, "Today:        $%.2f\n", s.TodayUSD)
+	fmt.Fprintf(w, "This week:    $%.2f\n", s.WeekUSD)
+	fmt.Fprintf(w, "Total:        $%.2f (%d input, %d output tokens)\n", s.TotalUSD, s.InputTokens, s.OutputTokens)
+}
+
+// describeControlResponse explains what the orchestrator will do now.
+func describeControlResponse(cmd control.Command, resp control.Response) string {
+	switch cmd {
+	case control.CommandPause:
+		return fmt.Sprintf("Orchestrator paused: no new work will be picked up. %d session(s) still running, %d item(s) queued.",
+			resp.Sessions, resp.Queued)
+	case control.CommandDrain:
+		if resp.Sessions == 0 {
+			return "Orchestrator draining: no sessions are running, so it exits on its next tick."
+		}
+		return fmt.Sprintf("Orchestrator draining: it exits once its %d running session(s) finish.", resp.Sessions)
+	default:
+		return fmt.Sprintf("Orchestrator %s: picking up new work again.", resp.Mode)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/control"
+)
+
+func TestDaemonCmdSubcommands(t *testing.T) {
+	var names []string
+	for _, sub := range daemonCmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	if got := strings.Join(names, ","); got != "cancel,drain,list,logs,pause,queue,resume,retry,spend" {
+		t.Errorf("subcommands = %s", got)
+	}
+	if daemonCmd.PersistentFlags().Lookup("repo") == nil {
+		t.Error("expected --repo flag on daemon command")
+	}
+}
+
+func TestRunDaemonControl_NoDaemon(t *testing.T) {
+	orig := daemonRepo
+	defer func() { daemonRepo = orig }()
+	daemonRepo = "/nonexistent/repo/with/no/daemon"
+
+	err := runDaemonControl(control.CommandPause)(daemonPauseCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "erg status") {
+		t.Errorf("expected a hint to check status, got %v", err)
+	}
+}
+
+func TestDescribeControlResponse(t *testing.T) {
+	tests := []struct {
+		cmd  control.Command
+		resp control.Response
+		want string
+	}{
+		{control.CommandPause, control.Response{Mode: "paused", Sessions: 2, Queued: 3}, "2 session(s) still running, 3 item(s) queued"},
+		{control.CommandDrain, control.Response{Mode: "draining", Sessions: 1}, "once its 1 running session(s) finish"},
+		{control.CommandDrain, control.Response{Mode: "draining"}, "exits on its next tick"},
+		{control.CommandResume, control.Response{Mode: "running"}, "Orchestrator running"},
+	}
+	for _, tt := range tests {
+		if got := describeControlResponse(tt.cmd, tt.resp); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: got %q, want it to contain %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDaemonItems(t *testing.T) {
+	items := []control.Item{
+		{ID: "item-1", Issue: "12", Title: "Fix login", State: "active", Phase: "async_pending", Step: "await_ci", CostUSD: 1.25},
+		{ID: "item-2", Issue: "7", Title: "Old work", State: "completed", Step: "done"},
+	}
+
+	var buf bytes.Buffer
+	formatDaemonItems(&buf, items, false)
+	out := buf.String()
+	for _, want := range []string{"ITEM", "item-1", "#12", "active/async_pending", "await_ci", "$1.25", "Fix login"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "item-2") {
+		t.Errorf("finished items should be hidden without --all:\n%s", out)
+	}
+
+	buf.Reset()
+	formatDaemonItems(&buf, items, true)
+	if !strings.Contains(buf.String(), "item-2") {
+		t.Errorf("expected finished items with --all:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	formatDaemonItems(&buf, items[1:], false)
+	if !strings.Contains(buf.String(), "No work items") {
+		t.Errorf("empty output:\n%s", buf.String())
+	}
+}
+
+func TestFormatDaemonSpend(t *testing.T) {
+	var buf bytes.Buffer
+	formatDaemonSpend(&buf, &control.Spend{TotalUSD: 9.5, InputTokens: 300, OutputTokens: 100, TodayUSD: 1.5, WeekUSD: 4})
+	out := buf.String()
+	for _, want := range []string{"$1.50", "$4.00", "$9.50 (300 input, 100 output tokens)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -3,15 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/zhubert/erg/internal/dashboard"
 )
 
-var dashboardPort int
+var (
+	dashboardPort int
+	dashboardHost string
+)
 
 var dashboardCmd = &cobra.Command{
 	Use:     "dashboard",
@@ -22,19 +27,29 @@ showing all running erg daemons, their work items, and session logs.
 
 Uses Server-Sent Events for live updates.
 
+Set ERG_DASHBOARD_ADMIN_TOKEN and/or ERG_DASHBOARD_OBSERVER_TOKEN to require
+authentication. Observers can view status, logs, and spend but cannot stop,
+retry, or message work items. Binding to a non-loopback host requires a token.
+
 Examples:
-  erg dashboard              # Start on default port 21122
-  erg dashboard --port 8080  # Start on custom port`,
+  erg dashboard                    # Start on default port 21122
+  erg dashboard --port 8080        # Start on custom port
+  erg dashboard --host 0.0.0.0     # Share with teammates (requires a token)`,
 	RunE: runDashboard,
 }
 
 func init() {
 	dashboardCmd.Flags().IntVar(&dashboardPort, "port", 21122, "Port for the dashboard server")
+	dashboardCmd.Flags().StringVar(&dashboardHost, "host", "localhost", "Host interface for the dashboard server")
 	rootCmd.AddCommand(dashboardCmd)
 }
 
 func runDashboard(cmd *cobra.Command, args []string) error {
-	addr := fmt.Sprintf("localhost:%d", dashboardPort)
+	addr := net.JoinHostPort(dashboardHost, strconv.Itoa(dashboardPort))
+	if !isLoopbackHost(dashboardHost) && os.Getenv(dashboard.AdminTokenEnvVar) == "" && os.Getenv(dashboard.ObserverTokenEnvVar) == "" {
+		return fmt.Errorf("--host %s exposes the dashboard to the network: set %s or %s to require authentication",
+			dashboardHost, dashboard.ObserverTokenEnvVar, dashboard.AdminTokenEnvVar)
+	}
 	fmt.Printf("erg dashboard → http://%s\n", addr)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -47,6 +62,15 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
-	srv := dashboard.New(addr)
+	srv := dashboard.New(addr, dashboard.AccessTokensFromEnv())
 	return srv.Run(ctx)
 }
+
+// isLoopbackHost reports whether host names the local machine only.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+var dlqRepo string
+
+// dlqErrorWidth is how much of the last error 'erg dlq list' shows.
+const dlqErrorWidth = 60
+
+var dlqCmd = &cobra.Command{
+	Use:     "dlq",
+	Short:   "Inspect and release issues that keep failing",
+	GroupID: "daemon",
+	Long: `When work on an issue fails settings.dead_letter_after times (3 by default),
+the orchestrator stops retrying it, moves it to the dead-letter queue, and
+comments on the issue explaining why. It stays there until you decide:
+
+  list     Show the dead-letter queue, from the saved state.
+  retry    Release an issue with a fresh set of attempts and queue it again.
+  discard  Drop an issue from the queue without retrying it.
+
+Issues are named by their number or by their work item's ID. retry and
+discard go through the running orchestrator's control socket.`,
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dead-lettered issues",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		repo, err := resolveDaemonRepo(dlqRepo)
+		if err != nil {
+			return err
+		}
+		state, err := daemonstate.LoadDaemonState(repo)
+		if err != nil {
+			return fmt.Errorf("failed to load orchestrator state: %w", err)
+		}
+		formatDeadLetters(c.OutOrStdout(), state.GetDeadLetters(), time.Now())
+		return nil
+	},
+}
+
+var dlqRetryCmd = &cobra.Command{
+	Use:   "retry <issue>",
+	Short: "Release a dead-lettered issue and queue it again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return runDLQRequest(c, control.Request{Command: control.CommandDLQRetry, Item: args[0]})
+	},
+}
+
+var dlqDiscardCmd = &cobra.Command{
+	Use:   "discard <issue>",
+	Short: "Drop a dead-lettered issue without retrying it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return runDLQRequest(c, control.Request{Command: control.CommandDLQDiscard, Item: args[0]})
+	},
+}
+
+func init() {
+	dlqCmd.PersistentFlags().StringVar(&dlqRepo, "repo", "", "Repo whose dead-letter queue to use (owner/repo or filesystem path)")
+	dlqCmd.AddCommand(dlqListCmd, dlqRetryCmd, dlqDiscardCmd)
+	rootCmd.AddCommand(dlqCmd)
+}
+
+// runDLQRequest sends a dead-letter request to the orchestrator and prints
+// what it did.
+func runDLQRequest(c *cobra.Command, req control.Request) error {
+	resp, err := sendDaemonRequest(dlqRepo, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.OutOrStdout(), resp.Message)
+	return nil
+}
+
+// formatDeadLetters writes the dead-letter queue as a table.
+func formatDeadLetters(w io.Writer, dls []daemonstate.DeadLetter, now time.Time) {
+	if len(dls) == 0 {
+		fmt.Fprintln(w, "The dead-letter queue is empty.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ISSUE\tITEM\tFAILURES\tSTEP\tAGE\tERROR")
+	for _, dl := range dls {
+		step := dl.Step
+		if step == "" {
+			step = "—"
+		}
+		errMsg := []rune(dl.Error)
+		if len(errMsg) > dlqErrorWidth {
+			errMsg = append(errMsg[:dlqErrorWidth-1], '…')
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			dl.IssueRef.ID, dl.WorkItemID, dl.Failures, step, formatAgeAt(dl.At, now), string(errMsg))
+	}
+	tw.Flush()
+}
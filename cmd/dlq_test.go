@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+func TestDLQCmdSubcommands(t *testing.T) {
+	var names []string
+	for _, sub := range dlqCmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	if got := strings.Join(names, ","); got != "discard,list,retry" {
+		t.Errorf("subcommands = %s", got)
+	}
+}
+
+func TestFormatDeadLetters(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+	formatDeadLetters(&buf, nil, now)
+	if !strings.Contains(buf.String(), "empty") {
+		t.Errorf("empty output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	formatDeadLetters(&buf, []daemonstate.DeadLetter{{
+		WorkItemID: "item-12",
+		IssueRef:   config.IssueRef{Source: "github", ID: "12"},
+		Failures:   3,
+		Step:       "await_ci",
+		Error:      strings.Repeat("x", 100),
+		At:         now.Add(-2 * time.Hour),
+	}}, now)
+	out := buf.String()
+	for _, want := range []string{"ISSUE", "12", "item-12", "3", "await_ci", "2h", "…"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, strings.Repeat("x", 61)) {
+		t.Errorf("long error not truncated:\n%s", out)
+	}
+}
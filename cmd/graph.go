@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// graphFormatSVG renders the diagram to SVG with an external tool.
+const graphFormatSVG = "svg"
+
+var (
+	graphRepo         string
+	graphWorkflowFile string
+	graphFormat       string
+	graphLive         bool
+	graphWorkItem     string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the workflow as a state diagram",
+	Long: `Prints the repo's workflow, after templates are expanded, as a state
+diagram. Parallel states are drawn as forks and join states as joins.
+
+Formats:
+  mermaid    Mermaid stateDiagram-v2 (default); renders on GitHub and mermaid.live
+  dot        Graphviz DOT
+  plantuml   PlantUML state diagram
+  svg        SVG image, rendered with Graphviz (dot) if installed,
+             otherwise with mermaid-cli (mmdc)
+
+With --live, each state is labeled with the number of active work items at
+it and states with items are highlighted. --work-item does the same and also
+highlights the state the given work item is at. Both read the orchestrator's
+state for the repo and need --format mermaid.
+
+Examples:
+  erg workflow graph                                   # Mermaid for current repo
+  erg workflow graph --format dot | dot -Tpng -o wf.png
+  erg workflow graph --format svg > workflow.svg
+  erg workflow graph --workflow .erg/release.yaml      # Specific workflow file
+  erg workflow graph --live                            # Where active items are
+  erg workflow graph --work-item 42                    # Where issue #42 is`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphRepo, "repo", "", "Repo path (default: current git root)")
+	graphCmd.Flags().StringVar(&graphWorkflowFile, "workflow", "", "Path to workflow config file")
+	graphCmd.Flags().StringVar(&graphFormat, "format", workflow.GraphFormatMermaid, "Output format: mermaid, dot, plantuml, or svg")
+	graphCmd.Flags().BoolVar(&graphLive, "live", false, "Annotate states with the number of active work items")
+	graphCmd.Flags().StringVar(&graphWorkItem, "work-item", "", "Highlight the state of this work item or issue ID (implies --live)")
+	workflowCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	repoPath, err := resolveAgentRepo(ctx, graphRepo, session.NewSessionService())
+	if err != nil {
+		return err
+	}
+	var overlay *workflow.GraphOverlay
+	if graphLive || graphWorkItem != "" {
+		if graphFormat != workflow.GraphFormatMermaid {
+			return fmt.Errorf("--live and --work-item need --format mermaid")
+		}
+		state, err := daemonstate.LoadDaemonState(repoPath)
+		if err != nil {
+			return fmt.Errorf("failed to load orchestrator state: %w", err)
+		}
+		if overlay, err = workItemOverlay(state, graphWorkItem); err != nil {
+			return err
+		}
+	}
+	return printWorkflowGraph(ctx, os.Stdout, repoPath, graphWorkflowFile, graphFormat, overlay)
+}
+
+// workItemOverlay counts the active work items at each step and, when ref is
+// set, marks the step of the work item it names by work item or issue ID.
+func workItemOverlay(state *daemonstate.DaemonState, ref string) (*workflow.GraphOverlay, error) {
+	overlay := &workflow.GraphOverlay{Counts: make(map[string]int)}
+	for _, item := range state.GetActiveWorkItems() {
+		if item.CurrentStep != "" {
+			overlay.Counts[item.CurrentStep]++
+		}
+	}
+	if ref == "" {
+		return overlay, nil
+	}
+
+	var matches []daemonstate.WorkItem
+	for _, item := range state.GetAllWorkItems() {
+		if item.ID == ref || strings.EqualFold(item.IssueRef.ID, strings.TrimPrefix(ref, "#")) {
+			matches = append(matches, item)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no work item found for %q (see 'erg status')", ref)
+	case 1:
+		overlay.Current = matches[0].CurrentStep
+		return overlay, nil
+	default:
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID
+		}
+		sort.Strings(ids)
+		return nil, fmt.Errorf("%q matches several work items, use the full ID: %s", ref, strings.Join(ids, ", "))
+	}
+}
+
+// printWorkflowGraph loads and validates the workflow for repoPath and
+// writes it to w as a diagram in the given format. A non-nil overlay is drawn
+// on Mermaid output.
+func printWorkflowGraph(ctx context.Context, w io.Writer, repoPath, workflowFile, format string, overlay *workflow.GraphOverlay) error {
+	switch format {
+	case workflow.GraphFormatMermaid, workflow.GraphFormatDOT, workflow.GraphFormatPlantUML, graphFormatSVG:
+	default:
+		return fmt.Errorf("unknown --format %q (must be mermaid, dot, plantuml, or svg)", format)
+	}
+
+	wfCfg, err := workflow.LoadAndMergeWithFile(repoPath, workflowFile)
+	if err != nil {
+		return fmt.Errorf("error loading workflow config: %w", err)
+	}
+	if wfCfg == nil {
+		return fmt.Errorf("no workflow config found in %s", repoPath)
+	}
+	if err := validateWorkflowConfig(wfCfg, claude.IsValidModel); err != nil {
+		return err
+	}
+
+	if format == graphFormatSVG {
+		svg, err := renderSVG(ctx, wfCfg)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(svg)
+		return err
+	}
+
+	if format == workflow.GraphFormatMermaid {
+		_, err = fmt.Fprint(w, workflow.GenerateMermaidOverlay(wfCfg, overlay))
+		return err
+	}
+	out, err := workflow.Graph(wfCfg, format)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, out)
+	return err
+}
+
+// renderSVG renders the workflow to SVG with Graphviz when dot is on PATH,
+// falling back to mermaid-cli (mmdc).
+func renderSVG(ctx context.Context, cfg *workflow.Config) ([]byte, error) {
+	if dot, err := exec.LookPath("dot"); err == nil {
+		cmd := exec.CommandContext(ctx, dot, "-Tsvg")
+		cmd.Stdin = bytes.NewBufferString(workflow.GenerateDOT(cfg))
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("dot failed: %w: %s", err, stderr.String())
+		}
+		return out, nil
+	}
+
+	mmdc, err := exec.LookPath("mmdc")
+	if err != nil {
+		return nil, fmt.Errorf("svg output needs Graphviz (dot) or mermaid-cli (mmdc) on PATH")
+	}
+	dir, err := os.MkdirTemp("", "erg-graph-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "workflow.mmd")
+	out := filepath.Join(dir, "workflow.svg")
+	if err := os.WriteFile(in, []byte(workflow.GenerateMermaid(cfg)), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write diagram: %w", err)
+	}
+	if output, err := exec.CommandContext(ctx, mmdc, "-i", in, "-o", out).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("mmdc failed: %w: %s", err, output)
+	}
+	return os.ReadFile(out)
+}
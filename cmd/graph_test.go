@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// graphTestRepo creates a repo whose workflow runs two parallel checks.
+func graphTestRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".erg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := `source:
+  provider: github
+  filter:
+    label: queued
+start: checks
+states:
+  checks:
+    type: parallel
+    branches: [lint, test]
+    next: gather
+  lint:
+    type: task
+    action: exec.run
+    params:
+      command: make lint
+    next: gather
+  test:
+    type: task
+    action: exec.run
+    params:
+      command: make test
+    next: gather
+  gather:
+    type: join
+    next: done
+    error: failed
+`
+	if err := os.WriteFile(filepath.Join(repo, ".erg", "workflow.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestPrintWorkflowGraph(t *testing.T) {
+	repo := graphTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := printWorkflowGraph(context.Background(), &buf, repo, "", workflow.GraphFormatMermaid, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"stateDiagram-v2", "state checks <<fork>>", "state gather <<join>>", "checks --> lint", "gather --> done"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintWorkflowGraph_Invalid(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".erg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	yaml := `source:
+  provider: github
+  filter:
+    label: queued
+start: checks
+states:
+  checks:
+    type: parallel
+    branches: [lint]
+    next: done
+  lint:
+    type: task
+    action: exec.run
+    params:
+      command: make lint
+    next: done
+`
+	if err := os.WriteFile(filepath.Join(repo, ".erg", "workflow.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := printWorkflowGraph(context.Background(), &bytes.Buffer{}, repo, "", workflow.GraphFormatMermaid, nil)
+	if err == nil || !strings.Contains(err.Error(), "states.checks") {
+		t.Fatalf("expected validation error for parallel state, got: %v", err)
+	}
+}
+
+func TestPrintWorkflowGraph_NoConfig(t *testing.T) {
+	if err := printWorkflowGraph(context.Background(), &bytes.Buffer{}, t.TempDir(), "", workflow.GraphFormatMermaid, nil); err == nil {
+		t.Fatal("expected error when no workflow config exists")
+	}
+}
+
+func TestPrintWorkflowGraph_DOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printWorkflowGraph(context.Background(), &buf, graphTestRepo(t), "", workflow.GraphFormatDOT, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "digraph workflow {") || !strings.Contains(out, "checks -> lint;") {
+		t.Errorf("unexpected DOT output:\n%s", out)
+	}
+}
+
+func TestPrintWorkflowGraph_UnknownFormat(t *testing.T) {
+	err := printWorkflowGraph(context.Background(), &bytes.Buffer{}, graphTestRepo(t), "", "png", nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown --format "png"`) {
+		t.Fatalf("expected unknown format error, got: %v", err)
+	}
+}
+
+func TestPrintWorkflowGraph_SVGWithDot(t *testing.T) {
+	bin := t.TempDir()
+	// A stand-in for Graphviz that wraps its input so the test can check it.
+	script := "#!/bin/sh\nprintf '<svg>'\ncat\nprintf '</svg>'\n"
+	if err := os.WriteFile(filepath.Join(bin, "dot"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var buf bytes.Buffer
+	if err := printWorkflowGraph(context.Background(), &buf, graphTestRepo(t), "", "svg", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "<svg>digraph workflow {") || !strings.HasSuffix(out, "</svg>") {
+		t.Errorf("expected DOT piped through dot, got:\n%s", out)
+	}
+}
+
+func TestPrintWorkflowGraph_SVGWithoutRenderer(t *testing.T) {
+	repo := graphTestRepo(t)
+	t.Setenv("PATH", t.TempDir())
+
+	err := printWorkflowGraph(context.Background(), &bytes.Buffer{}, repo, "", "svg", nil)
+	if err == nil || !strings.Contains(err.Error(), "Graphviz (dot) or mermaid-cli (mmdc)") {
+		t.Fatalf("expected missing renderer error, got: %v", err)
+	}
+}
+
+func TestWorkItemOverlay(t *testing.T) {
+	state := daemonstate.NewDaemonState("/test/repo")
+	for _, it := range []struct {
+		id, issue, step string
+		state           daemonstate.WorkItemState
+	}{
+		{"/test/repo-1", "1", "lint", daemonstate.WorkItemActive},
+		{"/test/repo-2", "2", "lint", daemonstate.WorkItemActive},
+		{"/test/repo-3", "3", "gather", daemonstate.WorkItemActive},
+		{"/test/repo-4", "4", "done", daemonstate.WorkItemCompleted},
+	} {
+		state.AddWorkItem(&daemonstate.WorkItem{
+			ID:          it.id,
+			IssueRef:    config.IssueRef{Source: "github", ID: it.issue},
+			CurrentStep: it.step,
+		})
+		state.UpdateWorkItem(it.id, func(w *daemonstate.WorkItem) { w.State = it.state })
+	}
+
+	overlay, err := workItemOverlay(state, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if overlay.Counts["lint"] != 2 || overlay.Counts["gather"] != 1 || overlay.Counts["done"] != 0 {
+		t.Errorf("unexpected counts: %v", overlay.Counts)
+	}
+	if overlay.Current != "" {
+		t.Errorf("expected no current state, got %q", overlay.Current)
+	}
+
+	overlay, err = workItemOverlay(state, "#3")
+	if err != nil || overlay.Current != "gather" {
+		t.Errorf("by issue ID: got %+v, %v", overlay, err)
+	}
+	overlay, err = workItemOverlay(state, "/test/repo-4")
+	if err != nil || overlay.Current != "done" {
+		t.Errorf("completed item by work item ID: got %+v, %v", overlay, err)
+	}
+	if _, err := workItemOverlay(state, "99"); err == nil || !strings.Contains(err.Error(), "no work item found") {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+}
+
+func TestPrintWorkflowGraph_Overlay(t *testing.T) {
+	overlay := &workflow.GraphOverlay{Counts: map[string]int{"lint": 2}, Current: "lint"}
+
+	var buf bytes.Buffer
+	if err := printWorkflowGraph(context.Background(), &buf, graphTestRepo(t), "", workflow.GraphFormatMermaid, overlay); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`state "lint (exec.run) [2]" as lint`, "class lint current"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+var (
+	historyRepo string
+	historyKind string
+	historyJSON bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:     "history <work-item>",
+	Short:   "Show a work item's audit trail",
+	GroupID: "daemon",
+	Long: `Shows everything the orchestrator recorded doing for a work item: step
+transitions, shell commands its sessions ran, files they wrote, PRs opened
+and merged, comments posted, spend per response, and operator actions.
+
+The trail is append-only and kept in the orchestrator's state database after
+the work item itself is pruned. Name the work item by its ID, or by its
+issue's number to see the trails of all of the issue's work items.
+
+Examples:
+  erg history 42                  # Trail of issue #42's work items
+  erg history 42 --kind command   # Only the commands its sessions ran
+  erg history 42 --json > 42.json # Export for review`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyRepo, "repo", "", "Repo whose orchestrator recorded the work item (owner/repo or filesystem path)")
+	historyCmd.Flags().StringVar(&historyKind, "kind", "", "Only show entries of one kind: transition, command, file, pr, comment, spend, operator, reaper")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output the entries as a JSON array")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	repo, err := resolveDaemonRepo(historyRepo)
+	if err != nil {
+		return err
+	}
+	state, err := daemonstate.LoadDaemonState(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+
+	ref := args[0]
+	entries, err := state.AuditLog(ref, false)
+	if err == nil && len(entries) == 0 {
+		entries, err = state.AuditLog(strings.TrimPrefix(ref, "#"), true)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no history recorded for %q", ref)
+	}
+	if historyKind != "" {
+		entries = filterAuditKind(entries, daemonstate.AuditKind(historyKind))
+	}
+
+	if historyJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	formatHistory(cmd.OutOrStdout(), entries)
+	return nil
+}
+
+// filterAuditKind returns the entries of the given kind.
+func filterAuditKind(entries []daemonstate.AuditEntry, kind daemonstate.AuditKind) []daemonstate.AuditEntry {
+	out := []daemonstate.AuditEntry{}
+	for _, e := range entries {
+		if e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// formatHistory writes audit entries as a table, with a work item column
+// when they span several items.
+func formatHistory(w io.Writer, entries []daemonstate.AuditEntry) {
+	items := make(map[string]bool)
+	for _, e := range entries {
+		items[e.WorkItemID] = true
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(items) > 1 {
+		fmt.Fprintln(tw, "TIME\tITEM\tKIND\tSUMMARY")
+	} else {
+		fmt.Fprintln(tw, "TIME\tKIND\tSUMMARY")
+	}
+	for _, e := range entries {
+		at := e.At.Local().Format("2006-01-02 15:04:05")
+		if len(items) > 1 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", at, e.WorkItemID, e.Kind, e.Summary)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", at, e.Kind, e.Summary)
+		}
+	}
+	tw.Flush()
+}
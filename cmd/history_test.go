@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+func TestFormatHistory(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	entries := []daemonstate.AuditEntry{
+		{WorkItemID: "item-1", At: at, Kind: daemonstate.AuditTransition, Summary: "started at coding"},
+		{WorkItemID: "item-1", At: at, Kind: daemonstate.AuditCommand, Summary: "Bash: go test ./..."},
+	}
+
+	var buf bytes.Buffer
+	formatHistory(&buf, entries)
+	out := buf.String()
+	if strings.Contains(out, "ITEM") || !strings.Contains(out, "2026-03-01 12:00:00") || !strings.Contains(out, "Bash: go test ./...") {
+		t.Errorf("single item output:\n%s", out)
+	}
+
+	buf.Reset()
+	entries = append(entries, daemonstate.AuditEntry{WorkItemID: "item-2", At: at, Kind: daemonstate.AuditPR, Summary: "opened https://example.com/pr/2"})
+	formatHistory(&buf, entries)
+	if out := buf.String(); !strings.Contains(out, "ITEM") || !strings.Contains(out, "item-2") {
+		t.Errorf("multi item output:\n%s", out)
+	}
+
+	if got := filterAuditKind(entries, daemonstate.AuditPR); len(got) != 1 || got[0].WorkItemID != "item-2" {
+		t.Errorf("filterAuditKind = %+v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/repoindex"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	indexRepo    string
+	indexResults int
+)
+
+var indexCmd = &cobra.Command{
+	Use:     "index",
+	Short:   "Build the code index sessions retrieve related code from",
+	GroupID: "setup",
+	Long: `The code index splits a repo's files into chunks and stores a vector for
+each, locally in erg's data directory. With settings.repo_index on, the
+chunks most related to an issue are added to the prompt of its planning and
+coding sessions, so agents on large repos start from the right files.
+
+Vectors come from the embedding model in settings.repo_index_model, which
+matches code to an issue by meaning. Without one they are computed offline
+from the words each chunk contains, so only code sharing terms with the
+issue is found. Changing the model rebuilds the index on the next update.
+
+Files git ignores, lockfiles, binaries, and files over 256KB are left out.
+
+  build   Index the repo from scratch.
+  update  Re-index only files added or changed since the last build, and
+          drop deleted ones. Builds the index if there is none.
+  search  Show what the index finds for some text, such as an issue title.
+
+The orchestrator does not refresh the index itself; run 'erg index update'
+after large changes, or on a schedule.`,
+}
+
+var indexBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Index the repo from scratch",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexUpdate(cmd, true)
+	},
+}
+
+var indexUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Re-index files changed since the last build",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexUpdate(cmd, false)
+	},
+}
+
+var indexSearchCmd = &cobra.Command{
+	Use:   "search <text>",
+	Short: "Show the code the index finds for some text",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoPath, err := indexRepoPath(cmd.Context())
+		if err != nil {
+			return err
+		}
+		ix, err := repoindex.Load(repoPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no index for %s; build one with 'erg index build'", repoPath)
+		}
+		if err != nil {
+			return err
+		}
+		embedder, err := indexEmbedder(repoPath)
+		if err != nil {
+			return err
+		}
+		hits, err := ix.Search(embedder, strings.Join(args, " "), indexResults)
+		if err != nil {
+			return err
+		}
+		formatIndexHits(cmd.OutOrStdout(), hits)
+		return nil
+	},
+}
+
+func init() {
+	indexCmd.PersistentFlags().StringVar(&indexRepo, "repo", "", "Repo path (default: current git root)")
+	indexSearchCmd.Flags().IntVarP(&indexResults, "results", "n", 8, "Number of chunks to show")
+	indexCmd.AddCommand(indexBuildCmd, indexUpdateCmd, indexSearchCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+// indexRepoPath returns the local checkout the --repo flag or the current
+// directory names. The index reads files, so owner/repo names are refused.
+func indexRepoPath(ctx context.Context) (string, error) {
+	repo, err := resolveAgentRepo(ctx, indexRepo, session.NewSessionService())
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a local checkout; pass its path with --repo", repo)
+	}
+	return abs, nil
+}
+
+// indexEmbedder returns the embedder the repo's workflow config names in
+// settings.repo_index_model, or the offline lexical one.
+func indexEmbedder(repoPath string) (repoindex.Embedder, error) {
+	wfCfg, err := workflow.LoadAndMerge(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow config: %w", err)
+	}
+	m := wfCfg.RepoIndexModel()
+	return repoindex.NewEmbedder(m.URL, m.Model, m.APIKeyEnv)
+}
+
+// runIndexUpdate brings the repo's index up to date, from scratch when
+// rebuild is set, and saves it.
+func runIndexUpdate(cmd *cobra.Command, rebuild bool) error {
+	repoPath, err := indexRepoPath(cmd.Context())
+	if err != nil {
+		return err
+	}
+	var prev *repoindex.Index
+	if !rebuild {
+		prev, err = repoindex.Load(repoPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	embedder, err := indexEmbedder(repoPath)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	ix, stats, err := repoindex.Update(cmd.Context(), repoPath, prev, embedder)
+	if err != nil {
+		return err
+	}
+	if err := ix.Save(); err != nil {
+		return err
+	}
+	dir, _ := repoindex.Dir(repoPath)
+	formatIndexStats(cmd.OutOrStdout(), stats, time.Since(start), dir)
+	return nil
+}
+
+// formatIndexStats reports what a build or update did.
+func formatIndexStats(w io.Writer, stats repoindex.Stats, took time.Duration, dir string) {
+	fmt.Fprintf(w, "Indexed %d files (%d chunks) in %s.\n", stats.Files, stats.Chunks, took.Round(time.Millisecond))
+	fmt.Fprintf(w, "  embedded: %d new or changed\n", stats.Embedded)
+	if stats.Removed > 0 {
+		fmt.Fprintf(w, "  removed:  %d deleted\n", stats.Removed)
+	}
+	fmt.Fprintf(w, "Stored in %s\n", dir)
+}
+
+// formatIndexHits lists search results, best first.
+func formatIndexHits(w io.Writer, hits []repoindex.Hit) {
+	if len(hits) == 0 {
+		fmt.Fprintln(w, "Nothing related found.")
+		return
+	}
+	for _, h := range hits {
+		fmt.Fprintf(w, "%.3f  %s:%d-%d\n", h.Score, h.Path, h.StartLine, h.EndLine)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/repoindex"
+)
+
+func TestIndexCommands(t *testing.T) {
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	os.MkdirAll(filepath.Join(repo, "config"), 0o755)
+	os.WriteFile(filepath.Join(repo, "config", "parse.go"), []byte("package config\n\nfunc parseConfig(data []byte) {}\n"), 0o644)
+	os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	t.Cleanup(func() {
+		if dir, err := repoindex.Dir(repo); err == nil {
+			os.RemoveAll(dir)
+		}
+	})
+
+	orig := indexRepo
+	t.Cleanup(func() { indexRepo = orig })
+	indexRepo = repo
+	var out bytes.Buffer
+	indexCmd.SetOut(&out)
+	t.Cleanup(func() { indexCmd.SetOut(nil) })
+	for _, c := range []*cobra.Command{indexBuildCmd, indexUpdateCmd, indexSearchCmd} {
+		c.SetContext(context.Background())
+	}
+
+	if err := indexSearchCmd.RunE(indexSearchCmd, []string{"config"}); err == nil || !strings.Contains(err.Error(), "erg index build") {
+		t.Errorf("search without an index: %v", err)
+	}
+
+	if err := runIndexUpdate(indexBuildCmd, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Indexed 2 files") || !strings.Contains(out.String(), "embedded: 2 new or changed") {
+		t.Errorf("build output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runIndexUpdate(indexUpdateCmd, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "embedded: 0 new or changed") {
+		t.Errorf("update of an unchanged repo should embed nothing:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := indexSearchCmd.RunE(indexSearchCmd, []string{"parse", "the", "config"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.SplitN(out.String(), "\n", 2)[0], "config/parse.go:1-3") {
+		t.Errorf("search output:\n%s", out.String())
+	}
+}
+
+func TestIndexRepoPath_RejectsRemoteNames(t *testing.T) {
+	orig := indexRepo
+	t.Cleanup(func() { indexRepo = orig })
+	indexRepo = "owner/repo-that-is-not-here"
+	if _, err := indexRepoPath(context.Background()); err == nil || !strings.Contains(err.Error(), "not a local checkout") {
+		t.Errorf("expected a local checkout to be required, got %v", err)
+	}
+}
+
+func TestFormatIndexStats(t *testing.T) {
+	var buf bytes.Buffer
+	formatIndexStats(&buf, repoindex.Stats{Files: 10, Chunks: 25, Embedded: 3, Removed: 1}, 1500*time.Millisecond, "/data/index/app-abc")
+	for _, want := range []string{"Indexed 10 files (25 chunks) in 1.5s.", "embedded: 3", "removed:  1", "/data/index/app-abc"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
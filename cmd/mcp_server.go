@@ -159,6 +159,8 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 	var commentIssueRespChan chan mcp.CommentIssueResponse
 	var submitReviewChan chan mcp.SubmitReviewRequest
 	var submitReviewRespChan chan mcp.SubmitReviewResponse
+	var submitResultChan chan mcp.SubmitResultRequest
+	var submitResultRespChan chan mcp.SubmitResultResponse
 
 	if mcpHostTools {
 		createPRChan = make(chan mcp.CreatePRRequest)
@@ -171,6 +173,8 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 		commentIssueRespChan = make(chan mcp.CommentIssueResponse, 1)
 		submitReviewChan = make(chan mcp.SubmitReviewRequest)
 		submitReviewRespChan = make(chan mcp.SubmitReviewResponse, 1)
+		submitResultChan = make(chan mcp.SubmitResultRequest)
+		submitResultRespChan = make(chan mcp.SubmitResultResponse, 1)
 
 		mcp.ForwardRequests(&wg, createPRChan, createPRRespChan,
 			client.SendCreatePRRequest,
@@ -202,12 +206,19 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 				return mcp.SubmitReviewResponse{ID: req.ID, Success: false, Error: "Communication error with TUI"}
 			})
 
+		mcp.ForwardRequests(&wg, submitResultChan, submitResultRespChan,
+			client.SendSubmitResultRequest,
+			func(req mcp.SubmitResultRequest) mcp.SubmitResultResponse {
+				return mcp.SubmitResultResponse{ID: req.ID, Success: false, Error: "Communication error with TUI"}
+			})
+
 		serverOpts = append(serverOpts, mcp.WithHostTools(
 			createPRChan, createPRRespChan,
 			pushBranchChan, pushBranchRespChan,
 			getReviewCommentsChan, getReviewCommentsRespChan,
 			commentIssueChan, commentIssueRespChan,
 			submitReviewChan, submitReviewRespChan,
+			submitResultChan, submitResultRespChan,
 		))
 	}
 
@@ -239,6 +250,7 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 		close(getReviewCommentsChan)
 		close(commentIssueChan)
 		close(submitReviewChan)
+		close(submitResultChan)
 	}
 	wg.Wait()
 	close(respChan)
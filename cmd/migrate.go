@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemon"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	migrateRepo         string
+	migrateWorkflowFile string
+	migrateMaps         []string
+	migratePolicy       string
+	migrateDryRun       bool
+)
+
+var workflowMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move in-flight work items onto the current workflow version",
+	Long: `Every work item records the version of the workflow config it started on.
+When .erg/workflow.yaml changes, the running orchestrator reloads it and
+carries in-flight items over according to settings.migration.policy:
+
+  remap          move items to the same state in the new config, or to the
+                 state a settings.migration.remap rule names (default)
+  finish-on-old  leave items on the version they started on until they finish
+  fail           fail every item started on an earlier version
+
+Items whose state no longer exists and has no remap rule fail. Items with a
+running session are moved once it finishes.
+
+This command moves items still on an earlier version, typically those held
+back by finish-on-old, onto the current one. Rules given with --map are
+applied on top of settings.migration.remap. The running orchestrator applies
+the migration on its next tick.
+
+Examples:
+  erg workflow migrate --dry-run                 # Show what would happen
+  erg workflow migrate                           # Move items, keeping their state
+  erg workflow migrate --map review=code_review  # Move items at review to code_review
+  erg workflow migrate --policy fail             # Fail items on old versions`,
+	Args: cobra.NoArgs,
+	RunE: runWorkflowMigrate,
+}
+
+func init() {
+	workflowMigrateCmd.Flags().StringVar(&migrateRepo, "repo", "", "Repo path (default: current git root)")
+	workflowMigrateCmd.Flags().StringVar(&migrateWorkflowFile, "workflow", "", "Path to workflow config file")
+	workflowMigrateCmd.Flags().StringArrayVar(&migrateMaps, "map", nil, "Remap rule old=new (repeatable)")
+	workflowMigrateCmd.Flags().StringVar(&migratePolicy, "policy", workflow.MigrationRemap, "Migration policy: remap or fail")
+	workflowMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migration plan without applying it")
+	workflowCmd.AddCommand(workflowMigrateCmd)
+}
+
+func runWorkflowMigrate(cmd *cobra.Command, args []string) error {
+	repoPath, err := resolveAgentRepo(context.Background(), migrateRepo, session.NewSessionService())
+	if err != nil {
+		return err
+	}
+	req, err := parseMigrationRequest(migratePolicy, migrateMaps)
+	if err != nil {
+		return err
+	}
+	return migrateWorkflow(cmd.OutOrStdout(), repoPath, migrateWorkflowFile, req, migrateDryRun)
+}
+
+// parseMigrationRequest builds a migration request from the --policy and
+// --map flags.
+func parseMigrationRequest(policy string, rules []string) (daemonstate.MigrationRequest, error) {
+	req := daemonstate.MigrationRequest{Policy: policy, At: time.Now()}
+	if policy != workflow.MigrationRemap && policy != workflow.MigrationFail {
+		return req, fmt.Errorf("unknown --policy %q (must be remap or fail)", policy)
+	}
+	for _, m := range rules {
+		from, to, ok := strings.Cut(m, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return req, fmt.Errorf("invalid --map %q (want old=new)", m)
+		}
+		if req.Remap == nil {
+			req.Remap = make(map[string]string)
+		}
+		req.Remap[from] = to
+	}
+	return req, nil
+}
+
+// migrationRow is one work item in a migration plan.
+type migrationRow struct {
+	item daemonstate.WorkItem
+	plan daemon.MigrationPlan
+}
+
+// migrateWorkflow prints the plan for moving the repo's in-flight items
+// onto the current workflow version and, unless dryRun is set, hands the
+// request to the running orchestrator.
+func migrateWorkflow(w io.Writer, repoPath, workflowFile string, req daemonstate.MigrationRequest, dryRun bool) error {
+	wfCfg, err := workflow.LoadAndMergeWithFile(repoPath, workflowFile)
+	if err != nil {
+		return fmt.Errorf("error loading workflow config: %w", err)
+	}
+	if wfCfg == nil {
+		return fmt.Errorf("no workflow config found in %s", repoPath)
+	}
+	if errs := workflow.Validate(wfCfg); len(errs) > 0 {
+		return fmt.Errorf("%s", formatLintErrors(workflow.ConfigPath(repoPath, workflowFile), errs))
+	}
+	for _, from := range slices.Sorted(maps.Keys(req.Remap)) {
+		if _, ok := wfCfg.States[req.Remap[from]]; !ok {
+			return fmt.Errorf("--map %s=%s: state %q does not exist in the current workflow", from, req.Remap[from], req.Remap[from])
+		}
+	}
+
+	state, err := daemonstate.LoadDaemonState(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+	rows := planWorkflowMigration(state, wfCfg, &req)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "All in-flight work items are on the current workflow version.")
+		return nil
+	}
+	printMigrationPlan(w, rows)
+
+	if dryRun {
+		fmt.Fprintf(w, "\nDry run: %d work items would be migrated.\n", len(rows))
+		return nil
+	}
+	if _, running := daemonstate.ReadLockStatus(repoPath); !running {
+		return fmt.Errorf("orchestrator is not running for %s — work items are rebuilt on the current workflow when it starts", repoPath)
+	}
+	if err := daemonstate.WriteMigrationRequest(repoPath, req); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nMigration requested for %d work items; the orchestrator applies it on its next tick.\n", len(rows))
+	return nil
+}
+
+// planWorkflowMigration returns what a migration request would do to each
+// non-terminal item started on a version other than cfg's, ordered by ID.
+func planWorkflowMigration(state *daemonstate.DaemonState, cfg *workflow.Config, req *daemonstate.MigrationRequest) []migrationRow {
+	version := workflow.HashConfig(cfg)
+	policy, rules := daemon.MigrationRules(cfg, req)
+	var rows []migrationRow
+	for _, item := range state.GetAllWorkItems() {
+		if item.IsTerminal() || item.WorkflowVersion == "" || item.WorkflowVersion == version {
+			continue
+		}
+		rows = append(rows, migrationRow{item: item, plan: daemon.PlanMigration(item, cfg, policy, rules)})
+	}
+	slices.SortFunc(rows, func(a, b migrationRow) int { return strings.Compare(a.item.ID, b.item.ID) })
+	return rows
+}
+
+func printMigrationPlan(w io.Writer, rows []migrationRow) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORK ITEM\tSTEP\tACTION\tTO")
+	for _, r := range rows {
+		to := r.plan.Step
+		switch r.plan.Action {
+		case daemon.MigrateWait:
+			to = "(after the running session)"
+		case daemon.MigrateFail:
+			to = r.plan.Reason
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.item.ID, cmp.Or(r.item.CurrentStep, "-"), r.plan.Action, to)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestParseMigrationRequest(t *testing.T) {
+	req, err := parseMigrationRequest("remap", []string{"review=check", " old = new "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Policy != "remap" || req.Remap["review"] != "check" || req.Remap["old"] != "new" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	for _, bad := range []string{"review", "=check", "review="} {
+		if _, err := parseMigrationRequest("remap", []string{bad}); err == nil {
+			t.Errorf("expected error for --map %q", bad)
+		}
+	}
+	if _, err := parseMigrationRequest("finish-on-old", nil); err == nil {
+		t.Error("expected error for --policy finish-on-old")
+	}
+}
+
+// migrateTestState saves orchestrator state for repo holding items on an
+// earlier workflow version and one on the current version of cfg.
+func migrateTestState(t *testing.T, repo string, cfg *workflow.Config) {
+	t.Helper()
+	state := daemonstate.NewDaemonState(repo)
+	add := func(id, step, phase, version string) {
+		state.AddWorkItem(&daemonstate.WorkItem{ID: id, IssueRef: config.IssueRef{Source: "github", ID: id}, CurrentStep: step})
+		state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+			it.State = daemonstate.WorkItemActive
+			it.Phase = phase
+			it.WorkflowVersion = version
+		})
+	}
+	add("current", "lint", "idle", workflow.HashConfig(cfg))
+	add("kept", "lint", "idle", "old")
+	add("renamed", "review", "idle", "old")
+	add("busy", "review", "async_pending", "old")
+	add("removed", "deploy", "idle", "old")
+	add("legacy", "lint", "idle", "")
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateWorkflow_DryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	repo := graphTestRepo(t)
+	cfg, err := workflow.LoadAndMergeWithFile(repo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateTestState(t, repo, cfg)
+
+	req, _ := parseMigrationRequest("remap", []string{"review=test"})
+	var buf bytes.Buffer
+	if err := migrateWorkflow(&buf, repo, "", req, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := [][]string{
+		{"WORK ITEM", "STEP", "ACTION", "TO"},
+		{"busy", "review", "wait"},
+		{"kept", "lint", "move", "lint"},
+		{"removed", "deploy", "fail", `step "deploy" no longer exists`},
+		{"renamed", "review", "move", "test"},
+	}
+	for i, fields := range want {
+		for _, f := range fields {
+			if !strings.Contains(lines[i], f) {
+				t.Errorf("line %d: missing %q in %q", i, f, lines[i])
+			}
+		}
+	}
+	if strings.Contains(out, "current") || strings.Contains(out, "legacy") {
+		t.Errorf("items on the current or no version should not be listed:\n%s", out)
+	}
+	if !strings.Contains(out, "Dry run: 4 work items would be migrated.") {
+		t.Errorf("missing dry run summary:\n%s", out)
+	}
+	if got := daemonstate.TakeMigrationRequest(repo); got != nil {
+		t.Errorf("dry run should not write a request, got %+v", got)
+	}
+}
+
+func TestMigrateWorkflow_NotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	repo := graphTestRepo(t)
+	cfg, err := workflow.LoadAndMergeWithFile(repo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrateTestState(t, repo, cfg)
+
+	req, _ := parseMigrationRequest("remap", nil)
+	err = migrateWorkflow(&bytes.Buffer{}, repo, "", req, false)
+	if err == nil || !strings.Contains(err.Error(), "orchestrator is not running") {
+		t.Errorf("expected not running error, got %v", err)
+	}
+	if got := daemonstate.TakeMigrationRequest(repo); got != nil {
+		t.Errorf("no request should be written, got %+v", got)
+	}
+}
+
+func TestMigrateWorkflow_UnknownMapTarget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	repo := graphTestRepo(t)
+	req, _ := parseMigrationRequest("remap", []string{"review=code_review"})
+	err := migrateWorkflow(&bytes.Buffer{}, repo, "", req, true)
+	if err == nil || !strings.Contains(err.Error(), `state "code_review" does not exist`) {
+		t.Errorf("expected unknown state error, got %v", err)
+	}
+}
+
+func TestMigrateWorkflow_NothingToMigrate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	repo := graphTestRepo(t)
+	req, _ := parseMigrationRequest("remap", nil)
+	var buf bytes.Buffer
+	if err := migrateWorkflow(&buf, repo, "", req, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "on the current workflow version") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/agentconfig"
+	"github.com/zhubert/erg/internal/daemon"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/logger"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	recoverRepo   string
+	recoverDryRun bool
+)
+
+var recoverCmd = &cobra.Command{
+	Use:     "recover",
+	Short:   "Reconcile saved work items with worktrees, sessions, and PRs",
+	GroupID: "daemon",
+	Long: `Compares the work items saved when the orchestrator last stopped with
+what is actually there, and repairs the difference:
+
+  merged-pr          the item's PR merged while the orchestrator was down:
+                     the item is completed (and its worktree removed when
+                     merged sessions are cleaned up)
+  dead-session       a session was running when the orchestrator stopped:
+                     a leftover container is removed, and a coding session
+                     whose worktree survived has its uncommitted work
+                     committed and resumes there; other steps are rebuilt
+                     from the tracker
+  orphaned-worktree  a worktree no work item owns: removed with its branch
+  orphaned-branch    a merged item's branch left by interrupted cleanup:
+                     deleted
+
+The orchestrator does this every time it starts. While it is stopped, run
+this command with --dry-run to see what the next start will do, or without
+it to repair the saved state now.
+
+Examples:
+  erg recover --dry-run   # Show what would be done
+  erg recover             # Do it now`,
+	Args: cobra.NoArgs,
+	RunE: runRecover,
+}
+
+func init() {
+	recoverCmd.Flags().StringVar(&recoverRepo, "repo", "", "Repo path (default: current git root)")
+	recoverCmd.Flags().BoolVar(&recoverDryRun, "dry-run", false, "Report what would be done without changing anything")
+	rootCmd.AddCommand(recoverCmd)
+}
+
+func runRecover(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	sessSvc := session.NewSessionService()
+	repoPath, err := resolveAgentRepo(ctx, recoverRepo, sessSvc)
+	if err != nil {
+		return err
+	}
+	// A running orchestrator's sessions are live, not dead, and it already
+	// recovered when it started.
+	if _, running := daemonstate.ReadLockStatus(repoPath); running {
+		return fmt.Errorf("orchestrator is running for %s — it recovered when it started", repoPath)
+	}
+
+	cfgOpts := []agentconfig.AgentConfigOption{agentconfig.WithRepos([]string{repoPath})}
+	if wfCfg, _ := workflow.LoadAndMergeWithFile(repoPath, ""); wfCfg != nil && wfCfg.Settings != nil && wfCfg.Settings.CleanupMerged != nil {
+		cfgOpts = append(cfgOpts, agentconfig.WithCleanupMerged(*wfCfg.Settings.CleanupMerged))
+	}
+	cfg := agentconfig.NewAgentConfig(cfgOpts...)
+	d := daemon.New(cfg, git.NewGitService(), sessSvc, issues.NewProviderRegistry(), logger.Get(),
+		daemon.WithRepoFilter(repoPath))
+	report, err := d.Recover(ctx, recoverDryRun)
+	if err != nil {
+		return err
+	}
+	printRecoveryReport(cmd.OutOrStdout(), report)
+	return nil
+}
+
+func printRecoveryReport(w io.Writer, report daemon.RecoveryReport) {
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(w, "Nothing to recover: saved work items match their worktrees, sessions, and PRs.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FINDING\tWORK ITEM\tBRANCH\tACTION")
+	for _, f := range report.Findings {
+		item := cmp.Or(f.WorkItem, f.Path)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Kind, item, cmp.Or(f.Branch, "-"), f.Action)
+	}
+	tw.Flush()
+	if report.DryRun {
+		fmt.Fprintf(w, "\nDry run: nothing was changed. %d finding(s) would be acted on.\n", len(report.Findings))
+	} else {
+		fmt.Fprintf(w, "\nRecovered %d finding(s).\n", len(report.Findings))
+	}
+}
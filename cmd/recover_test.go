@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemon"
+)
+
+func TestPrintRecoveryReport(t *testing.T) {
+	var buf bytes.Buffer
+	printRecoveryReport(&buf, daemon.RecoveryReport{})
+	if !strings.Contains(buf.String(), "Nothing to recover") {
+		t.Errorf("empty report output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printRecoveryReport(&buf, daemon.RecoveryReport{
+		DryRun: true,
+		Findings: []daemon.RecoveryFinding{
+			{Kind: daemon.RecoveryMergedPR, WorkItem: "/repo-42", Branch: "issue-42", Action: "mark completed"},
+			{Kind: daemon.RecoveryOrphanedWorktree, Path: "/wt/abc", Action: "remove worktree"},
+		},
+	})
+	out := buf.String()
+	for _, want := range []string{"merged-pr", "/repo-42", "issue-42", "mark completed", "orphaned-worktree", "/wt/abc", "Dry run: nothing was changed. 2 finding(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
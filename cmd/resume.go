@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var resumeRepo string
+
+var resumeCmd = &cobra.Command{
+	Use:     "resume <issue>",
+	Short:   "Restart a work item stalled on a timed-out wait",
+	GroupID: "daemon",
+	Long: `Restarts a work item the orchestrator parked because a wait state with
+"on_timeout: stall" timed out (for example a PR nobody reviewed). The item
+waits at the same step again, with its timeout and escalation starting over.
+Stalled items are shown by 'erg status'.
+
+The issue is identified by its tracker ID (e.g. 42 or ENG-123) or by the full
+work item ID.
+
+Examples:
+  erg resume 42
+  erg resume ENG-123 --repo /path/to/repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runResume,
+}
+
+func init() {
+	resumeCmd.Flags().StringVar(&resumeRepo, "repo", "", "Repo whose orchestrator holds the item (owner/repo or filesystem path)")
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	repo := resumeRepo
+	if repo == "" {
+		resolved, err := resolveAgentRepo(context.Background(), "", session.NewSessionService())
+		if err != nil {
+			repo, err = findSingleRunningDaemon()
+			if err != nil {
+				return err
+			}
+		} else {
+			repo = resolved
+		}
+	}
+
+	state, err := daemonstate.LoadDaemonState(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+	item, err := findStalledItem(state, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := daemonstate.WriteResumeRequest(repo, daemonstate.ResumeRequest{
+		WorkItemID: item.ID,
+		At:         time.Now(),
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Resumed %s at %s; the orchestrator picks it up on its next tick.\n",
+		issueLabel(item.IssueRef, item.ID, 60), item.CurrentStep)
+	return nil
+}
+
+// stalledItems returns the work items parked after a wait timeout, sorted by ID.
+func stalledItems(state *daemonstate.DaemonState) []daemonstate.WorkItem {
+	var stalled []daemonstate.WorkItem
+	for _, item := range state.GetActiveWorkItems() {
+		if item.Phase == workflow.PhaseStalled {
+			stalled = append(stalled, item)
+		}
+	}
+	sort.Slice(stalled, func(i, j int) bool { return stalled[i].ID < stalled[j].ID })
+	return stalled
+}
+
+// findStalledItem finds the stalled work item that matches ref, either by
+// work item ID or by issue ID.
+func findStalledItem(state *daemonstate.DaemonState, ref string) (daemonstate.WorkItem, error) {
+	stalled := stalledItems(state)
+	matches := matchWorkItems(stalled, ref)
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		if len(stalled) == 0 {
+			return daemonstate.WorkItem{}, fmt.Errorf("no work items are stalled")
+		}
+		return daemonstate.WorkItem{}, fmt.Errorf("no stalled work item for %q (see 'erg status')", ref)
+	default:
+		return daemonstate.WorkItem{}, ambiguousRefError(ref, matches)
+	}
+}
+
+// printStalledItems lists the items parked after a wait timeout, with the
+// command that resumes each one.
+func printStalledItems(w io.Writer, stalled []daemonstate.WorkItem) {
+	if len(stalled) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Stalled:")
+	for _, item := range stalled {
+		ref := item.IssueRef.ID
+		if ref == "" {
+			ref = item.ID
+		}
+		fmt.Fprintf(w, "  %s — timed out at %s (erg resume %s)\n", issueLabel(item.IssueRef, item.ID, 40), item.CurrentStep, ref)
+	}
+}
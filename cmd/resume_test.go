@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func resumeTestState() *daemonstate.DaemonState {
+	state := daemonstate.NewDaemonState("/test/repo")
+	for _, it := range []struct {
+		id, issue, phase string
+	}{
+		{"/test/repo-42", "42", workflow.PhaseStalled},
+		{"/test/repo-43", "43", "idle"},
+		{"/test/repo-44", "44", phaseAwaitingConfirmation},
+	} {
+		state.AddWorkItem(&daemonstate.WorkItem{
+			ID:          it.id,
+			IssueRef:    config.IssueRef{Source: "github", ID: it.issue, Title: "Issue " + it.issue},
+			CurrentStep: "await_review",
+		})
+		state.UpdateWorkItem(it.id, func(w *daemonstate.WorkItem) {
+			w.State = daemonstate.WorkItemActive
+			w.Phase = it.phase
+		})
+	}
+	return state
+}
+
+func TestFindStalledItem(t *testing.T) {
+	state := resumeTestState()
+
+	item, err := findStalledItem(state, "#42")
+	if err != nil || item.ID != "/test/repo-42" {
+		t.Errorf("by issue ID: got %q, %v", item.ID, err)
+	}
+	if _, err := findStalledItem(state, "44"); err == nil || !strings.Contains(err.Error(), "no stalled work item") {
+		t.Errorf("expected not-found error for item awaiting confirmation, got %v", err)
+	}
+	if _, err := findStalledItem(daemonstate.NewDaemonState("/test/repo"), "42"); err == nil || !strings.Contains(err.Error(), "no work items are stalled") {
+		t.Errorf("expected empty error, got %v", err)
+	}
+}
+
+func TestPrintStalledItems(t *testing.T) {
+	var buf bytes.Buffer
+	printStalledItems(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("expected no output without stalled items, got %q", buf.String())
+	}
+
+	printStalledItems(&buf, stalledItems(resumeTestState()))
+	out := buf.String()
+	if !strings.HasPrefix(out, "Stalled:\n") || strings.Count(out, "timed out at await_review") != 1 {
+		t.Errorf("expected one stalled item, got %q", out)
+	}
+	if !strings.Contains(out, "(erg resume 42)") {
+		t.Errorf("expected resume hint, got %q", out)
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/zhubert/erg/internal/agentconfig"
 	"github.com/zhubert/erg/internal/claude"
 	"github.com/zhubert/erg/internal/cli"
-	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/daemon"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
@@ -40,7 +39,11 @@ CI/CD integration.
 The --issue flag accepts the native ID format for the configured provider:
   GitHub:  integer issue number (e.g. --issue 42)
   Asana:   task GID (e.g. --issue 1234567890123)
-  Linear:  issue identifier (e.g. --issue ENG-123)`,
+  Linear:  issue identifier (e.g. --issue ENG-123)
+  GitLab:  project issue number (e.g. --issue 42)
+  ClickUp: task ID (e.g. --issue 86b0xyz12)
+  HTTP:    issue ID as returned by the endpoint (e.g. --issue TRK-7)
+  File:    backlog item ID (e.g. --issue add-dark-mode)`,
 	Example: `  erg run --issue 42
   erg run --issue 42 --repo /path/to/repo
   erg run --issue ENG-123 --workflow .erg/linear-workflow.yaml`,
@@ -63,7 +66,7 @@ func runIssue(cmd *cobra.Command, args []string) error {
 	}
 
 	if !hasContainerRuntime() {
-		return fmt.Errorf("a container runtime is required for agent mode.\nInstall OrbStack: https://orbstack.dev\nInstall Docker:   https://docs.docker.com/get-docker/\nInstall Colima:   https://github.com/abiosoft/colima")
+		return fmt.Errorf("a container runtime is required for agent mode.\nInstall OrbStack: https://orbstack.dev\nInstall Docker:   https://docs.docker.com/get-docker/\nInstall Colima:   https://github.com/abiosoft/colima\nInstall Podman:   https://podman.io")
 	}
 	if err := checkDockerDaemon(); err != nil {
 		return err
@@ -92,22 +95,93 @@ func runIssue(cmd *cobra.Command, args []string) error {
 	defer logger.Close()
 	runLogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 
+	env, err := newRunEnv(ctx, repoPath, runWorkflowFile, runLogger)
+	if err != nil {
+		return err
+	}
+	wfCfg := env.wfCfg
+
+	providerSource := issues.Source(wfCfg.Source.Provider)
+	if providerSource == "" {
+		providerSource = issues.SourceGitHub
+	}
+
+	p := env.registry.GetProvider(providerSource)
+	if p == nil {
+		return fmt.Errorf("provider %q not registered", wfCfg.Source.Provider)
+	}
+
+	getter, ok := p.(issues.IssueGetter)
+	if !ok {
+		return fmt.Errorf("provider %q does not support single-issue lookup", wfCfg.Source.Provider)
+	}
+
+	runLogger.Info("fetching issue", "provider", wfCfg.Source.Provider, "id", runIssueID)
+	issue, err := getter.GetIssue(ctx, repoPath, runIssueID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch issue %q: %w", runIssueID, err)
+	}
+	runLogger.Info("found issue", "title", issue.Title, "url", issue.URL)
+
+	// Use a distinct lock/state key to avoid conflicting with a running daemon on the same repo
+	runKey := fmt.Sprintf("run-%s-%s", repoPath, runIssueID)
+
+	// Build daemon options
+	opts := []daemon.Option{
+		daemon.WithOnce(true),
+		daemon.WithRepoFilter(repoPath),
+		daemon.WithPreseededIssue(*issue),
+		daemon.WithDaemonID(runKey),
+	}
+	if wfCfg.Settings != nil && wfCfg.Settings.AutoMerge != nil {
+		opts = append(opts, daemon.WithAutoMerge(*wfCfg.Settings.AutoMerge))
+	}
+	if runWorkflowFile != "" {
+		opts = append(opts, daemon.WithWorkflowFile(runWorkflowFile))
+	}
+	if env.minterOpt != nil {
+		opts = append(opts, env.minterOpt)
+	}
+
+	d := daemon.New(env.cfg, env.gitSvc, sessSvc, env.registry, runLogger, opts...)
+	if err := d.Run(ctx); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// runEnv is the configuration and provider setup shared by the foreground
+// commands that drive the daemon for specific issues (erg run, erg batch).
+type runEnv struct {
+	cfg      *agentconfig.AgentConfig
+	wfCfg    *workflow.Config
+	gitSvc   *git.GitService
+	registry *issues.ProviderRegistry
+
+	// minterOpt enables repo-scoped GitHub tokens; nil when GitHub App auth
+	// is not configured.
+	minterOpt daemon.Option
+}
+
+// newRunEnv loads and lints the workflow for repoPath, builds the
+// container image if the workflow doesn't name one, sets up the issue
+// providers and, when GitHub App auth is configured, the scoped token
+// minter.
+func newRunEnv(ctx context.Context, repoPath, workflowFile string, runLogger *slog.Logger) (*runEnv, error) {
 	// Load workflow config
-	wfCfg, err := workflow.LoadAndMergeWithFile(repoPath, runWorkflowFile)
+	wfCfg, err := lintWorkflowFile(repoPath, workflowFile, claude.IsValidModel)
 	if err != nil {
-		return fmt.Errorf("error loading workflow config: %w", err)
+		return nil, err
 	}
 	if wfCfg == nil {
-		return fmt.Errorf("no workflow config found — run `erg workflow init` to create .erg/workflow.yaml")
+		return nil, fmt.Errorf("no workflow config found — run `erg workflow init` to create .erg/workflow.yaml")
 	}
 
 	// Ensure container image
 	if wfCfg.Settings == nil || wfCfg.Settings.ContainerImage == "" {
-		detected := container.Detect(ctx, repoPath)
-		runLogger.Info("auto-detected languages", "languages", detected)
-		image, _, err := container.EnsureImage(ctx, detected, version, runLogger)
+		image, err := autoBuildImage(ctx, repoPath, wfCfg.Container, wfCfg.ImageRegistry(), runLogger)
 		if err != nil {
-			return fmt.Errorf("failed to auto-build container image: %w\n\n"+
+			return nil, fmt.Errorf("failed to auto-build container image: %w\n\n"+
 				"You can skip auto-detection by setting container_image in .erg/workflow.yaml", err)
 		}
 		if wfCfg.Settings == nil {
@@ -116,10 +190,6 @@ func runIssue(cmd *cobra.Command, args []string) error {
 		wfCfg.Settings.ContainerImage = image
 	}
 
-	if err := validateWorkflowConfig(wfCfg, claude.IsValidModel); err != nil {
-		return err
-	}
-
 	// Build AgentConfig
 	var cfgOpts []agentconfig.AgentConfigOption
 	cfgOpts = append(cfgOpts, agentconfig.WithRepos([]string{repoPath}))
@@ -150,56 +220,29 @@ func runIssue(cmd *cobra.Command, args []string) error {
 	if wfCfg.Source.Provider == "linear" && wfCfg.Source.Filter.Team != "" {
 		cfg.SetLinearTeam(repoPath, wfCfg.Source.Filter.Team)
 	}
+	if wfCfg.Source.Provider == "gitlab" && wfCfg.Source.Filter.Project != "" {
+		cfg.SetGitLabProject(repoPath, wfCfg.Source.Filter.Project)
+	}
+	if wfCfg.Source.Provider == "clickup" && wfCfg.Source.Filter.List != "" {
+		cfg.SetClickUpList(repoPath, wfCfg.Source.Filter.List)
+	}
+	httpProvider := issues.NewGenericHTTPProvider()
+	configureHTTPSource(httpProvider, repoPath, wfCfg)
 
-	// Build provider registry and fetch the specific issue
+	// Build provider registry
 	gitSvc := git.NewGitService()
 	githubProvider := issues.NewGitHubProvider(gitSvc)
 	asanaProvider := issues.NewAsanaProvider(cfg)
 	linearProvider := issues.NewLinearProvider(cfg)
-	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider)
-
-	providerSource := issues.Source(wfCfg.Source.Provider)
-	if providerSource == "" {
-		providerSource = issues.SourceGitHub
-	}
-
-	p := issueRegistry.GetProvider(providerSource)
-	if p == nil {
-		return fmt.Errorf("provider %q not registered", wfCfg.Source.Provider)
-	}
-
-	getter, ok := p.(issues.IssueGetter)
-	if !ok {
-		return fmt.Errorf("provider %q does not support single-issue lookup", wfCfg.Source.Provider)
-	}
+	gitlabProvider := issues.NewGitLabProvider(cfg)
+	clickupProvider := issues.NewClickUpProvider(cfg)
+	fileProvider := issues.NewFileProvider()
+	issueRegistry := issues.NewProviderRegistry(githubProvider, asanaProvider, linearProvider, gitlabProvider, clickupProvider, httpProvider, fileProvider)
 
-	runLogger.Info("fetching issue", "provider", wfCfg.Source.Provider, "id", runIssueID)
-	issue, err := getter.GetIssue(ctx, repoPath, runIssueID)
+	minterOpt, err := githubAppMinterOption()
 	if err != nil {
-		return fmt.Errorf("failed to fetch issue %q: %w", runIssueID, err)
+		return nil, err
 	}
-	runLogger.Info("found issue", "title", issue.Title, "url", issue.URL)
-
-	// Use a distinct lock/state key to avoid conflicting with a running daemon on the same repo
-	runKey := fmt.Sprintf("run-%s-%s", repoPath, runIssueID)
 
-	// Build daemon options
-	opts := []daemon.Option{
-		daemon.WithOnce(true),
-		daemon.WithRepoFilter(repoPath),
-		daemon.WithPreseededIssue(*issue),
-		daemon.WithDaemonID(runKey),
-	}
-	if wfCfg.Settings != nil && wfCfg.Settings.AutoMerge != nil {
-		opts = append(opts, daemon.WithAutoMerge(*wfCfg.Settings.AutoMerge))
-	}
-	if runWorkflowFile != "" {
-		opts = append(opts, daemon.WithWorkflowFile(runWorkflowFile))
-	}
-
-	d := daemon.New(cfg, gitSvc, sessSvc, issueRegistry, runLogger, opts...)
-	if err := d.Run(ctx); err != nil && ctx.Err() == nil {
-		return err
-	}
-	return nil
+	return &runEnv{cfg: cfg, wfCfg: wfCfg, gitSvc: gitSvc, registry: issueRegistry, minterOpt: minterOpt}, nil
 }
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	spendRepo string
+	spendDays int
+	spendBy   string
+)
+
+var spendCmd = &cobra.Command{
+	Use:     "spend",
+	Short:   "Report session spend by repo, issue, model, or day",
+	GroupID: "daemon",
+	Long: `Reports what sessions spent, from the ledger the orchestrator keeps per day,
+repo, work item, and model. Unlike 'erg stats', spend on pruned work items is
+still counted.
+
+When the repo's workflow sets settings.budget, today's and this week's
+spend are shown against its limits. Days are calendar days in local time
+and weeks start on Monday.
+
+Examples:
+  erg spend                    # Spend per day over the last 7 days
+  erg spend --by issue         # Which issues cost the most
+  erg spend --by model         # Spend per model, fallbacks included
+  erg spend --by repo --days 30`,
+	Args: cobra.NoArgs,
+	RunE: runSpend,
+}
+
+func init() {
+	spendCmd.Flags().StringVar(&spendRepo, "repo", "", "Repo to report on (owner/repo or filesystem path)")
+	spendCmd.Flags().IntVar(&spendDays, "days", 7, "Number of days to report, including today")
+	spendCmd.Flags().StringVar(&spendBy, "by", "day", "Group spend by: day, repo, issue, or model")
+	rootCmd.AddCommand(spendCmd)
+}
+
+func runSpend(cmd *cobra.Command, args []string) error {
+	if spendDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	if !slices.Contains([]string{"day", "repo", "issue", "model"}, spendBy) {
+		return fmt.Errorf("--by must be one of day, repo, issue, model (got %q)", spendBy)
+	}
+	repo := spendRepo
+	if repo == "" {
+		resolved, err := resolveAgentRepo(context.Background(), "", session.NewSessionService())
+		if err != nil {
+			repo, err = findSingleRunningDaemon()
+			if err != nil {
+				return err
+			}
+		} else {
+			repo = resolved
+		}
+	}
+
+	state, err := daemonstate.LoadDaemonState(repo)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+	now := time.Now()
+	since := now.AddDate(0, 0, -(spendDays - 1)).Format(daemonstate.SpendDayFormat)
+	entries, err := state.SpendLedger(since)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	_, repoLabels := state.GetRepoLabels()
+	formatSpend(w, groupSpend(entries, spendBy, repoLabels), spendBy)
+	if wfCfg, _ := workflow.LoadAndMergeWithFile(repo, ""); wfCfg.SpendBudget() != nil {
+		fmt.Fprintln(w)
+		formatBudgetUsage(w, wfCfg.SpendBudget(), state, now)
+	}
+	return nil
+}
+
+// spendRow is the spend of one group of ledger entries.
+type spendRow struct {
+	Key     string
+	CostUSD float64
+	Tokens  int
+}
+
+// groupSpend sums ledger entries by day, repo, issue, or model, most
+// expensive first except for days, which stay in date order. Repos are shown
+// by their owner/repo label when one is known, and spend on the Claude CLI's
+// default model, or from before models were recorded, as "default".
+func groupSpend(entries []daemonstate.SpendEntry, by string, repoLabels map[string]string) []spendRow {
+	var rows []spendRow
+	index := make(map[string]int)
+	for _, e := range entries {
+		repo := cmp.Or(repoLabels[e.RepoPath], e.RepoPath)
+		var key string
+		switch by {
+		case "repo":
+			key = repo
+		case "issue":
+			key = repo + "#" + cmp.Or(e.IssueID, e.WorkItemID)
+		case "model":
+			key = cmp.Or(e.Model, "default")
+		default:
+			key = e.Day
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, spendRow{Key: key})
+		}
+		rows[i].CostUSD += e.CostUSD
+		rows[i].Tokens += e.Tokens()
+	}
+	if by == "day" {
+		slices.SortFunc(rows, func(a, b spendRow) int { return cmp.Compare(a.Key, b.Key) })
+	} else {
+		slices.SortStableFunc(rows, func(a, b spendRow) int { return cmp.Compare(b.CostUSD, a.CostUSD) })
+	}
+	return rows
+}
+
+// formatSpend writes grouped spend as a table with a total.
+func formatSpend(w io.Writer, rows []spendRow, by string) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No spend recorded in this period.")
+		return
+	}
+	var total spendRow
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCOST\tTOKENS\n", map[string]string{"day": "DAY", "repo": "REPO", "issue": "ISSUE", "model": "MODEL"}[by])
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t$%.2f\t%d\n", r.Key, r.CostUSD, r.Tokens)
+		total.CostUSD += r.CostUSD
+		total.Tokens += r.Tokens
+	}
+	fmt.Fprintf(tw, "TOTAL\t$%.2f\t%d\n", total.CostUSD, total.Tokens)
+	tw.Flush()
+}
+
+// formatBudgetUsage writes a single repo's spend today and this week against
+// each limit its budget sets.
+func formatBudgetUsage(w io.Writer, b *workflow.BudgetConfig, state *daemonstate.DaemonState, now time.Time) {
+	dayCost, dayTokens := state.SpendSince("", daemonstate.SpendDay(now))
+	weekCost, weekTokens := state.SpendSince("", daemonstate.SpendWeekStart(now))
+	fmt.Fprintf(w, "Budget (on exceeded: %s):\n", b.Behavior())
+	usage := func(label string, spent, limit float64, format string) {
+		if limit <= 0 {
+			return
+		}
+		status := ""
+		if spent >= limit {
+			status = "  EXCEEDED"
+		}
+		fmt.Fprintf(w, "  %-16s "+format+" of "+format+" (%.0f%%)%s\n", label, spent, limit, spent/limit*100, status)
+	}
+	usage("Today:", dayCost, b.DailyUSD, "$%.2f")
+	usage("This week:", weekCost, b.WeeklyUSD, "$%.2f")
+	usage("Today tokens:", float64(dayTokens), float64(b.DailyTokens), "%.0f")
+	usage("Week tokens:", float64(weekTokens), float64(b.WeeklyTokens), "%.0f")
+}
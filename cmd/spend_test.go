@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestGroupSpend(t *testing.T) {
+	entries := []daemonstate.SpendEntry{
+		{Day: "2026-03-09", RepoPath: "/a", WorkItemID: "/a-1", IssueID: "1", CostUSD: 1, InputTokens: 10},
+		{Day: "2026-03-10", RepoPath: "/a", WorkItemID: "/a-1", IssueID: "1", Model: "claude-sonnet-4-5", CostUSD: 2, OutputTokens: 5},
+		{Day: "2026-03-10", RepoPath: "/b", WorkItemID: "/b-7", IssueID: "7", Model: "claude-opus-4-1", CostUSD: 4},
+	}
+	labels := map[string]string{"/a": "owner/a"}
+
+	byDay := groupSpend(entries, "day", labels)
+	if len(byDay) != 2 || byDay[0].Key != "2026-03-09" || byDay[1].CostUSD != 6 {
+		t.Errorf("by day = %+v", byDay)
+	}
+	byRepo := groupSpend(entries, "repo", labels)
+	if len(byRepo) != 2 || byRepo[0].Key != "/b" || byRepo[1].Key != "owner/a" || byRepo[1].Tokens != 15 {
+		t.Errorf("by repo = %+v, want most expensive first with labels", byRepo)
+	}
+	byIssue := groupSpend(entries, "issue", labels)
+	if len(byIssue) != 2 || byIssue[1].Key != "owner/a#1" || byIssue[1].CostUSD != 3 {
+		t.Errorf("by issue = %+v", byIssue)
+	}
+	byModel := groupSpend(entries, "model", labels)
+	if len(byModel) != 3 || byModel[0].Key != "claude-opus-4-1" || byModel[2].Key != "default" {
+		t.Errorf("by model = %+v", byModel)
+	}
+}
+
+func TestFormatSpend(t *testing.T) {
+	var buf bytes.Buffer
+	formatSpend(&buf, nil, "day")
+	if !strings.Contains(buf.String(), "No spend recorded") {
+		t.Errorf("empty output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	formatSpend(&buf, []spendRow{{Key: "owner/a#1", CostUSD: 3, Tokens: 15}, {Key: "owner/b#7", CostUSD: 1.5}}, "issue")
+	out := buf.String()
+	for _, want := range []string{"ISSUE", "owner/a#1", "$3.00", "TOTAL", "$4.50"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatBudgetUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	now := time.Now()
+	state := daemonstate.NewDaemonState("/test/repo")
+	state.RecordSpendEntry(daemonstate.SpendEntry{Day: daemonstate.SpendDay(now), RepoPath: "/test/repo", WorkItemID: "1", CostUSD: 12})
+
+	var buf bytes.Buffer
+	formatBudgetUsage(&buf, &workflow.BudgetConfig{DailyUSD: 10, WeeklyUSD: 50, OnExceeded: "pause"}, state, now)
+	out := buf.String()
+	for _, want := range []string{"on exceeded: pause", "$12.00 of $10.00 (120%)  EXCEEDED", "$12.00 of $50.00 (24%)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "tokens") {
+		t.Errorf("unset limits should not be shown:\n%s", out)
+	}
+}
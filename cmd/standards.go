@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	standardsRepo   string
+	standardsGlobal bool
+)
+
+// runEditorFunc opens path in the user's editor. Overridden in tests.
+var runEditorFunc = runEditor
+
+// standardsTemplate seeds a new standards document.
+const standardsTemplate = `<!--
+Coding standards: every erg session gets this document in its system prompt.
+List the rules changes must follow, for example:
+
+## Style
+- Wrap errors with fmt.Errorf("...: %w", err).
+
+## Architecture
+- Handlers never talk to the database directly; go through a store.
+
+## Forbidden
+- No new dependencies without an issue approving them.
+
+Comments like this one are left out of the prompt.
+-->
+`
+
+var standardsCmd = &cobra.Command{
+	Use:     "standards",
+	Short:   "Manage the coding standards injected into every session",
+	GroupID: "setup",
+	Long: `Coding standards are Markdown documents (style guide, architecture
+constraints, forbidden patterns) appended to the system prompt of every
+session erg starts, so agents follow them without each workflow repeating
+them.
+
+There are two, and sessions get both:
+  global  standards.md in erg's config directory, beside daemon.yaml
+  repo    .erg/standards.md in the repo, versioned with its workflow
+
+HTML comments (<!-- ... -->) are left out of the prompt.
+
+  edit  Open a standards document in $EDITOR, creating it if needed.
+  show  Print the standards sessions on the repo get.`,
+}
+
+var standardsEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the repo's (or with --global, the global) standards",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := standardsPath(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return editStandards(cmd.OutOrStdout(), path)
+	},
+}
+
+var standardsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the standards sessions on the repo get",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		globalPath, err := paths.StandardsPath()
+		if err != nil {
+			return err
+		}
+		repoPath := ""
+		if !standardsGlobal {
+			if repoPath, err = resolveAgentRepo(cmd.Context(), standardsRepo, session.NewSessionService()); err != nil {
+				return err
+			}
+		}
+		s, err := workflow.LoadStandards(globalPath, repoPath)
+		if err != nil {
+			return err
+		}
+		formatStandards(cmd.OutOrStdout(), s, globalPath, repoPath)
+		return nil
+	},
+}
+
+func init() {
+	standardsCmd.PersistentFlags().StringVar(&standardsRepo, "repo", "", "Repo path (default: current git root)")
+	standardsCmd.PersistentFlags().BoolVar(&standardsGlobal, "global", false, "Use the global standards only")
+	standardsCmd.AddCommand(standardsEditCmd, standardsShowCmd)
+	rootCmd.AddCommand(standardsCmd)
+}
+
+// standardsPath returns the standards document the flags select.
+func standardsPath(ctx context.Context) (string, error) {
+	if standardsGlobal {
+		return paths.StandardsPath()
+	}
+	repoPath, err := resolveAgentRepo(ctx, standardsRepo, session.NewSessionService())
+	if err != nil {
+		return "", err
+	}
+	return workflow.RepoStandardsPath(repoPath), nil
+}
+
+// editStandards opens the standards document at path in the editor,
+// seeding it from standardsTemplate when it does not exist yet.
+func editStandards(w io.Writer, path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(standardsTemplate), 0o644); err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+	if err := runEditorFunc(path); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	fmt.Fprintf(w, "Saved %s. New sessions pick it up; running sessions keep what they started with.\n", path)
+	if !standardsGlobal {
+		fmt.Fprintln(w, "Commit it to version it with the repo's workflow.")
+	}
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi. The
+// variable may carry arguments, such as "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	c := osexec.Command("sh", "-c", editor+` "$1"`, "--", path)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
+
+// formatStandards prints the standards documents sessions get.
+func formatStandards(w io.Writer, s workflow.Standards, globalPath, repoPath string) {
+	if s.Empty() {
+		fmt.Fprintln(w, "No coding standards are set. Run `erg standards edit` to add some.")
+		return
+	}
+	if s.Global != "" {
+		fmt.Fprintf(w, "== %s ==\n%s\n", globalPath, s.Global)
+	}
+	if s.Repo != "" {
+		if s.Global != "" {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "== %s ==\n%s\n", workflow.RepoStandardsPath(repoPath), s.Repo)
+	}
+}
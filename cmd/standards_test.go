@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestEditStandards_SeedsAndOpens(t *testing.T) {
+	repo := t.TempDir()
+	path := workflow.RepoStandardsPath(repo)
+	var opened string
+	orig := runEditorFunc
+	runEditorFunc = func(p string) error {
+		opened = p
+		return os.WriteFile(p, []byte("- Prefer table tests."), 0o644)
+	}
+	t.Cleanup(func() { runEditorFunc = orig })
+
+	var out bytes.Buffer
+	if err := editStandards(&out, path); err != nil {
+		t.Fatal(err)
+	}
+	if opened != path {
+		t.Errorf("expected the editor opened on %s, got %q", path, opened)
+	}
+	if !strings.Contains(out.String(), "Saved "+path) || !strings.Contains(out.String(), "Commit it") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	s, err := workflow.LoadStandards("", repo)
+	if err != nil || s.Repo != "- Prefer table tests." {
+		t.Errorf("expected the edited standards loaded, got %+v, %v", s, err)
+	}
+}
+
+func TestEditStandards_TemplateInjectsNothing(t *testing.T) {
+	repo := t.TempDir()
+	orig := runEditorFunc
+	runEditorFunc = func(string) error { return nil }
+	t.Cleanup(func() { runEditorFunc = orig })
+
+	if err := editStandards(&bytes.Buffer{}, workflow.RepoStandardsPath(repo)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".erg", "standards.md")); err != nil {
+		t.Fatal("expected the document created")
+	}
+	if s, _ := workflow.LoadStandards("", repo); !s.Empty() {
+		t.Errorf("expected an untouched template to add nothing to prompts, got %q", s.Repo)
+	}
+}
+
+func TestFormatStandards(t *testing.T) {
+	var out bytes.Buffer
+	formatStandards(&out, workflow.Standards{}, "/cfg/standards.md", "/repo")
+	if !strings.Contains(out.String(), "No coding standards") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	formatStandards(&out, workflow.Standards{Global: "- A", Repo: "- B"}, "/cfg/standards.md", "/repo")
+	if !strings.Contains(out.String(), "== /cfg/standards.md ==\n- A") || !strings.Contains(out.String(), "== /repo/.erg/standards.md ==\n- B") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
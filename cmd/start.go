@@ -18,6 +18,8 @@ var (
 	startConfigFile    string
 	startDashboardAddr string
 	startDashboard     bool
+	startWebhookAddr   string
+	startLeaseDir      string
 )
 
 var startCmd = &cobra.Command{
@@ -43,7 +45,9 @@ Examples:
   erg start -f --repo owner/repo      # Foreground with live status display
   erg start --once --repo owner/repo  # Run one tick, then exit
   erg start --config config.yaml       # Watch multiple repos
-  erg start --dashboard               # Start orchestrator with embedded web dashboard`,
+  erg start --dashboard               # Start orchestrator with embedded web dashboard
+  erg start --webhook-addr :8787      # Also accept issue-tracker webhooks (see 'erg webhook setup')
+  erg start --lease-dir /mnt/erg      # Run as primary or warm standby, sharing a lease on shared storage`,
 	RunE: runStart,
 }
 
@@ -55,6 +59,8 @@ func init() {
 	startCmd.Flags().StringVar(&startConfigFile, "config", "", "Path to config file for multi-repo mode")
 	startCmd.Flags().StringVar(&startDashboardAddr, "dashboard-addr", "", "Start an embedded dashboard server at this address (e.g. localhost:21122)")
 	startCmd.Flags().BoolVar(&startDashboard, "dashboard", false, "Start an embedded dashboard at localhost:21122")
+	startCmd.Flags().StringVar(&startWebhookAddr, "webhook-addr", "", "Listen for issue-tracker webhooks at this address (e.g. :8787); polling continues as a fallback")
+	startCmd.Flags().StringVar(&startLeaseDir, "lease-dir", "", "Share leadership with standby instances through a lease in this directory on shared storage; only the leader polls and works")
 	rootCmd.AddCommand(startCmd)
 }
 
@@ -80,6 +86,8 @@ func runStart(cmd *cobra.Command, args []string) error {
 	agentWorkflowFile = startWorkflowFile
 	agentConfigFile = startConfigFile
 	agentDashboardAddr = resolveDashboardAddr(startDashboard, startDashboardAddr)
+	agentWebhookAddr = startWebhookAddr
+	agentLeaseDir = startLeaseDir
 
 	// --once implies foreground
 	if agentOnce {
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+var (
+	stateExportRepo   string
+	stateExportOutput string
+	stateImportForce  bool
+)
+
+var stateCmd = &cobra.Command{
+	Use:     "state",
+	Short:   "Export or import orchestrator state for host migration",
+	GroupID: "daemon",
+	Long: `Moves orchestrator state between hosts. 'erg state export' snapshots the
+state files (work items, spend totals, buffered tracker updates, and issue
+caches) into a portable archive; 'erg state import' installs them on the new
+host.
+
+Imported state keeps the exporting host's claim identity, so issues claimed
+before the move are still recognized as this daemon's own. State is keyed by
+repo path, so repos must be cloned to the same paths on the new host.`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write orchestrator state to a portable archive",
+	Long: `Writes the orchestrator state for every daemon on this host (or only the
+one given with --repo) to a gzip-compressed archive.
+
+Examples:
+  erg state export                          # All state to erg-state-<date>.json.gz
+  erg state export --repo /path/to/repo     # One repo's state
+  erg state export -o state.json.gz         # Choose the archive path`,
+	Args: cobra.NoArgs,
+	RunE: runStateExport,
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Install orchestrator state from an archive",
+	Long: `Installs the orchestrator state from an archive written by 'erg state export'.
+The orchestrator must be stopped for every imported repo. Existing state with
+work items is left untouched unless --force is given.
+
+Examples:
+  erg state import erg-state-2026-01-02.json.gz
+  erg state import state.json.gz --force    # Replace existing state`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStateImport,
+}
+
+func init() {
+	stateExportCmd.Flags().StringVar(&stateExportRepo, "repo", "", "Only export state for this repo path or daemon ID")
+	stateExportCmd.Flags().StringVarP(&stateExportOutput, "output", "o", "", "Archive path (default: erg-state-<date>.json.gz)")
+	stateImportCmd.Flags().BoolVar(&stateImportForce, "force", false, "Replace existing state that has work items")
+	stateCmd.AddCommand(stateExportCmd, stateImportCmd)
+	rootCmd.AddCommand(stateCmd)
+}
+
+func runStateExport(cmd *cobra.Command, _ []string) error {
+	keys := []string{stateExportRepo}
+	if stateExportRepo != "" {
+		if !daemonstate.HasState(stateExportRepo) {
+			return fmt.Errorf("no orchestrator state found for %s", stateExportRepo)
+		}
+	} else {
+		var err error
+		if keys, err = daemonstate.StateKeys(); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("no orchestrator state found on this host")
+		}
+	}
+
+	archive, err := daemonstate.NewArchive(keys)
+	if err != nil {
+		return fmt.Errorf("failed to load orchestrator state: %w", err)
+	}
+
+	output := stateExportOutput
+	if output == "" {
+		output = fmt.Sprintf("erg-state-%s.json.gz", time.Now().Format("2006-01-02"))
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := archive.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	printArchiveStates(out, archive)
+	fmt.Fprintf(out, "\nExported %d state file(s) to %s\n", len(archive.States), output)
+	return nil
+}
+
+func runStateImport(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	archive, err := daemonstate.ReadArchive(f)
+	if err != nil {
+		return err
+	}
+
+	// Check every key before writing any, so a refusal leaves nothing half-imported.
+	for _, state := range archive.States {
+		if _, running := daemonstate.ReadLockStatus(state.RepoPath); running {
+			return fmt.Errorf("orchestrator is running for %s — stop it with 'erg stop' before importing", state.RepoPath)
+		}
+		if err := daemonstate.CheckImport(state, stateImportForce); err != nil {
+			return err
+		}
+	}
+
+	for _, state := range archive.States {
+		if err := daemonstate.ImportState(state, archive.Hostname); err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	printArchiveStates(out, archive)
+	fmt.Fprintf(out, "\nImported %d state file(s) exported from %s at %s\n",
+		len(archive.States), archive.Hostname, archive.ExportedAt.Local().Format(time.RFC822))
+	return nil
+}
+
+// printArchiveStates lists each state in the archive with its work item count and spend.
+func printArchiveStates(w io.Writer, archive *daemonstate.Archive) {
+	for _, state := range archive.States {
+		key := state.RepoPath
+		if key == "" {
+			key = "(legacy)"
+		}
+		items := state.GetAllWorkItems()
+		var cost float64
+		for _, item := range items {
+			cost += item.CostUSD
+		}
+		fmt.Fprintf(w, "  %s: %d work items, $%.2f spent\n", key, len(items), cost)
+	}
+}
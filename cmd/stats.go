@@ -23,10 +23,12 @@ var statsCmd = &cobra.Command{
 	Short:   "Show aggregate session performance analytics",
 	GroupID: "daemon",
 	Long: `Displays aggregate performance analytics from the orchestrator's persisted state,
-including session history, cost tracking, failure analysis, and feedback stats.
+including session history, cost tracking, container resource usage, failure
+analysis, and feedback stats.
 
 Note: stats reflect only items still in the state file. Terminal items older
-than the configured max age (default 7 days) are pruned and not shown.
+than the configured max age (default 7 days) are pruned and not shown, except
+items imported with 'erg backfill'.
 
 Examples:
   erg stats                     # Show stats for current repo
@@ -79,6 +81,9 @@ type SessionStats struct {
 	// Per-item cost data (sorted by CostUSD descending, for display)
 	CostItems []WorkItemCostSummary
 
+	// Per-item container resource usage (sorted by CPU-seconds descending)
+	ResourceItems []WorkItemResourceSummary
+
 	// Failure analysis
 	FailedItems []daemonstate.WorkItem
 
@@ -98,6 +103,16 @@ type WorkItemCostSummary struct {
 	OutputTokens int
 }
 
+// WorkItemResourceSummary holds container resource usage for a single work item.
+type WorkItemResourceSummary struct {
+	Label           string
+	CPUSeconds      float64
+	PeakMemoryBytes int64
+	DiskReadBytes   int64
+	DiskWriteBytes  int64
+	CostUSD         float64
+}
+
 // computeSessionStats aggregates analytics from a slice of work items.
 func computeSessionStats(items []daemonstate.WorkItem) SessionStats {
 	var stats SessionStats
@@ -131,6 +146,17 @@ func computeSessionStats(items []daemonstate.WorkItem) SessionStats {
 			})
 		}
 
+		if item.ContainerCPUSeconds > 0 || item.PeakMemoryBytes > 0 {
+			stats.ResourceItems = append(stats.ResourceItems, WorkItemResourceSummary{
+				Label:           formatWorkItemLabel(item),
+				CPUSeconds:      item.ContainerCPUSeconds,
+				PeakMemoryBytes: item.PeakMemoryBytes,
+				DiskReadBytes:   item.DiskReadBytes,
+				DiskWriteBytes:  item.DiskWriteBytes,
+				CostUSD:         item.CostUSD,
+			})
+		}
+
 		if item.FeedbackRounds > 0 {
 			stats.FeedbackItems = append(stats.FeedbackItems, item)
 		}
@@ -140,6 +166,9 @@ func computeSessionStats(items []daemonstate.WorkItem) SessionStats {
 	sort.Slice(stats.CostItems, func(i, j int) bool {
 		return stats.CostItems[i].CostUSD > stats.CostItems[j].CostUSD
 	})
+	sort.Slice(stats.ResourceItems, func(i, j int) bool {
+		return stats.ResourceItems[i].CPUSeconds > stats.ResourceItems[j].CPUSeconds
+	})
 
 	return stats
 }
@@ -154,6 +183,7 @@ func formatStats(w io.Writer, stats SessionStats) {
 	printOverview(w, stats)
 	printTimeToMerge(w, stats)
 	printTokenSpend(w, stats)
+	printResourceUsage(w, stats)
 	printFailureAnalysis(w, stats)
 	printFeedbackRounds(w, stats)
 }
@@ -238,6 +268,37 @@ func printTokenSpend(w io.Writer, stats SessionStats) {
 	fmt.Fprintln(w)
 }
 
+func printResourceUsage(w io.Writer, stats SessionStats) {
+	if len(stats.ResourceItems) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "Resource Usage (top sessions by container CPU)")
+	fmt.Fprintln(w, "──────────────────────────────────────────────")
+
+	var totalCPU float64
+	var maxMem int64
+	for _, ri := range stats.ResourceItems {
+		totalCPU += ri.CPUSeconds
+		maxMem = max(maxMem, ri.PeakMemoryBytes)
+	}
+	fmt.Fprintf(w, "  Avg CPU:   %.0f CPU-s per session (%d sampled)  (%.0f CPU-s total)\n",
+		totalCPU/float64(len(stats.ResourceItems)), len(stats.ResourceItems), totalCPU)
+	fmt.Fprintf(w, "  Max peak:  %s memory\n", formatBytes(maxMem))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ISSUE\tCPU\tPEAK MEM\tDISK READ\tDISK WRITE\tCOST")
+
+	limit := min(len(stats.ResourceItems), 10)
+	for _, ri := range stats.ResourceItems[:limit] {
+		fmt.Fprintf(tw, "  %s\t%.0fs\t%s\t%s\t%s\t$%.4f\n",
+			ri.Label, ri.CPUSeconds, formatBytes(ri.PeakMemoryBytes),
+			formatBytes(ri.DiskReadBytes), formatBytes(ri.DiskWriteBytes), ri.CostUSD)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
 func printFailureAnalysis(w io.Writer, stats SessionStats) {
 	if len(stats.FailedItems) == 0 {
 		return
@@ -324,6 +385,20 @@ func printFeedbackRounds(w io.Writer, stats SessionStats) {
 	fmt.Fprintln(w)
 }
 
+// formatBytes formats a byte count with a binary unit suffix (e.g. "1.5GiB").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // formatDuration formats a duration as a human-readable string (e.g. "1h 23m").
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
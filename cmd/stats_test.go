@@ -288,3 +288,41 @@ func TestFormatWorkItemLabel_NoSource(t *testing.T) {
 		t.Errorf("expected 'item-xyz', got %q", got)
 	}
 }
+
+func TestComputeSessionStats_ResourceItemsSortedByCPU(t *testing.T) {
+	items := []daemonstate.WorkItem{
+		{ID: "a", ContainerCPUSeconds: 30, PeakMemoryBytes: 1 << 30},
+		{ID: "b", ContainerCPUSeconds: 120, PeakMemoryBytes: 512 << 20, DiskWriteBytes: 2048},
+		{ID: "c", CostUSD: 0.5}, // never sampled
+	}
+	stats := computeSessionStats(items)
+	if len(stats.ResourceItems) != 2 {
+		t.Fatalf("expected 2 resource items, got %d", len(stats.ResourceItems))
+	}
+	if stats.ResourceItems[0].CPUSeconds != 120 {
+		t.Errorf("expected highest CPU first, got %v", stats.ResourceItems[0].CPUSeconds)
+	}
+}
+
+func TestFormatStats_ResourceSection(t *testing.T) {
+	stats := computeSessionStats([]daemonstate.WorkItem{
+		{ID: "a", State: daemonstate.WorkItemCompleted, ContainerCPUSeconds: 90, PeakMemoryBytes: 1536 << 20, DiskReadBytes: 4096, CostUSD: 0.25},
+	})
+	var buf bytes.Buffer
+	formatStats(&buf, stats)
+	out := buf.String()
+	for _, want := range []string{"Resource Usage", "90 CPU-s total", "1.5GiB", "4.0KiB", "$0.2500"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{0: "0B", 1023: "1023B", 1024: "1.0KiB", 5 << 20: "5.0MiB", 3 << 30: "3.0GiB"}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -197,17 +200,19 @@ func formatCellInfo(item *daemonstate.WorkItem) string {
 // printTableView renders work items as an aligned table.
 func printTableView(w io.Writer, items []*daemonstate.WorkItem) {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tw, "ISSUE\tSTEP\tPHASE\tAGE\tPR")
+	fmt.Fprintln(tw, "ISSUE\tWORKFLOW\tSTEP\tPHASE\tAGE\tDUE\tPR")
 	for _, item := range items {
 		issue := formatIssue(item)
+		wf := cmp.Or(item.Workflow, workflow.DefaultWorkflowName)
 		step := formatStep(item)
 		phase := workflow.PhaseLabel(item.Phase)
 		age := formatAge(item.StepEnteredAt)
+		due := formatDue(item)
 		pr := item.PRURL
 		if pr == "" {
 			pr = "—"
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", issue, step, phase, age, pr)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", issue, wf, step, phase, age, due, pr)
 	}
 	tw.Flush()
 }
@@ -274,8 +279,9 @@ func printMapView(w io.Writer, items []*daemonstate.WorkItem, cfg *workflow.Conf
 }
 
 // primaryWorkflowPath walks the workflow graph from cfg.Start following the
-// happy path: for task/wait/pass states use Next, for choice states use the
-// first choice's Next (falling back to Default). Stops at terminal states.
+// happy path: for task/wait/pass/parallel/join states use Next, for choice
+// states use the first choice's Next (falling back to Default). Stops at
+// terminal states.
 func primaryWorkflowPath(cfg *workflow.Config) []string {
 	if cfg == nil || cfg.Start == "" {
 		return nil
@@ -295,7 +301,8 @@ func primaryWorkflowPath(cfg *workflow.Config) []string {
 		}
 
 		switch state.Type {
-		case workflow.StateTypeTask, workflow.StateTypeWait, workflow.StateTypePass:
+		case workflow.StateTypeTask, workflow.StateTypeWait, workflow.StateTypePass,
+			workflow.StateTypeParallel, workflow.StateTypeJoin:
 			current = state.Next
 		case workflow.StateTypeChoice:
 			// Pick the first choice that leads forward through the graph,
@@ -373,6 +380,19 @@ func formatIssue(item *daemonstate.WorkItem) string {
 	return issueLabel(item.IssueRef, item.ID, 30)
 }
 
+// formatDue returns the item's due date, marked when the daemon judged it
+// unlikely to finish in time, or "—" when the issue has no due date.
+func formatDue(item *daemonstate.WorkItem) string {
+	if item.IssueRef.DueAt.IsZero() {
+		return "—"
+	}
+	due := item.IssueRef.DueAt.Format("Jan 2")
+	if item.DeadlineAtRisk {
+		due += " (at risk)"
+	}
+	return due
+}
+
 // formatStep returns the display string for the item's current step.
 func formatStep(item *daemonstate.WorkItem) string {
 	switch item.State {
@@ -388,6 +408,30 @@ func formatStep(item *daemonstate.WorkItem) string {
 	}
 }
 
+// formatWorkflowCounts summarizes how many of the given non-terminal items
+// run on each workflow, e.g. "default 3  |  docs 1". It returns "" when
+// every item is on the repo's main workflow.
+func formatWorkflowCounts(items []daemonstate.WorkItem) string {
+	counts := make(map[string]int)
+	named := false
+	for _, item := range items {
+		if item.IsTerminal() {
+			continue
+		}
+		counts[cmp.Or(item.Workflow, workflow.DefaultWorkflowName)]++
+		named = named || item.Workflow != ""
+	}
+	if !named {
+		return ""
+	}
+	names := slices.Sorted(maps.Keys(counts))
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, counts[name])
+	}
+	return strings.Join(parts, "  |  ")
+}
+
 // formatTokenCount formats a token count with K/M suffix for readability.
 func formatTokenCount(n int) string {
 	switch {
@@ -154,6 +154,25 @@ func TestFormatIssue_EmDash_Truncation(t *testing.T) {
 	}
 }
 
+// ---- formatDue ----
+
+func TestFormatDue(t *testing.T) {
+	due := time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		item *daemonstate.WorkItem
+		want string
+	}{
+		{&daemonstate.WorkItem{}, "—"},
+		{&daemonstate.WorkItem{IssueRef: config.IssueRef{DueAt: due}}, "Apr 15"},
+		{&daemonstate.WorkItem{IssueRef: config.IssueRef{DueAt: due}, DeadlineAtRisk: true}, "Apr 15 (at risk)"},
+	}
+	for _, tt := range tests {
+		if got := formatDue(tt.item); got != tt.want {
+			t.Errorf("formatDue = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 // ---- formatStep ----
 
 func TestFormatStep_Active(t *testing.T) {
@@ -357,6 +376,40 @@ func TestPrintTableView_EmptyPhase(t *testing.T) {
 	}
 }
 
+func TestPrintTableView_Workflow(t *testing.T) {
+	items := []*daemonstate.WorkItem{
+		{IssueRef: config.IssueRef{Source: "github", ID: "1"}, State: daemonstate.WorkItemActive, CurrentStep: "coding", Workflow: "docs"},
+		{IssueRef: config.IssueRef{Source: "github", ID: "2"}, State: daemonstate.WorkItemActive, CurrentStep: "coding"},
+	}
+
+	var buf bytes.Buffer
+	printTableView(&buf, items)
+	out := buf.String()
+
+	if !strings.Contains(out, "WORKFLOW") || !strings.Contains(out, "docs") || !strings.Contains(out, "default") {
+		t.Errorf("expected workflow column in output: %q", out)
+	}
+}
+
+// ---- formatWorkflowCounts ----
+
+func TestFormatWorkflowCounts(t *testing.T) {
+	items := []daemonstate.WorkItem{
+		{State: daemonstate.WorkItemActive},
+		{State: daemonstate.WorkItemQueued},
+		{State: daemonstate.WorkItemActive, Workflow: "hotfix"},
+		{State: daemonstate.WorkItemQueued, Workflow: "docs"},
+		{State: daemonstate.WorkItemCompleted, Workflow: "docs"},
+	}
+	if got, want := formatWorkflowCounts(items), "default 2  |  docs 1  |  hotfix 1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := formatWorkflowCounts(items[:2]); got != "" {
+		t.Errorf("expected no summary when only the main workflow is used, got %q", got)
+	}
+}
+
 // ---- primaryWorkflowPath ----
 
 func TestPrimaryWorkflowPath_Default(t *testing.T) {
@@ -25,7 +25,11 @@ var stopCmd = &cobra.Command{
 	GroupID: "daemon",
 	Long: `Send SIGTERM to the running orchestrator to trigger a graceful shutdown.
 
-The orchestrator will finish in-flight work before exiting.
+The orchestrator stops its sessions before exiting. Coding sessions are
+checkpointed first: their uncommitted work is committed as an [erg-wip]
+commit and pushed, and their conversation is saved, so the next start
+resumes each item at its step instead of restarting it. Use
+'erg daemon drain' to let sessions finish instead.
 
 Examples:
   erg stop                       # Stop orchestrator for current repo
@@ -216,6 +216,9 @@ func renderTailView(w io.Writer, items []*daemonstate.WorkItem, termRows, termCo
 		phaseLabel := workflow.PhaseLabel(item.Phase)
 		cols[i].header = header
 		cols[i].subheader = fmt.Sprintf("%s / %s", stepLabel, phaseLabel)
+		if item.Workflow != "" {
+			cols[i].subheader = item.Workflow + ": " + cols[i].subheader
+		}
 
 		logLines, err := readStreamLogLines(item.SessionID)
 		if err != nil {
@@ -326,9 +329,9 @@ func drawTailFrame(repo string) error {
 
 	// Collect active items (non-terminal, any state) sorted by creation time
 	var items []*daemonstate.WorkItem
-	for _, item := range state.WorkItems {
+	for _, item := range state.GetAllWorkItems() {
 		if !item.IsTerminal() {
-			items = append(items, item)
+			items = append(items, &item)
 		}
 	}
 	sort.Slice(items, func(i, j int) bool {
@@ -3,7 +3,6 @@ package cmd
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +14,7 @@ import (
 	"time"
 
 	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/paths"
 )
@@ -23,16 +23,11 @@ import (
 // Overridden in tests to control behavior.
 var lookPathFunc = exec.LookPath
 
-// hasContainerRuntime checks whether a container runtime binary (docker or colima)
-// is available on PATH. Returns true if either is found.
+// hasContainerRuntime checks whether a container runtime binary (docker,
+// colima, podman or nerdctl) is available on PATH.
 func hasContainerRuntime() bool {
-	if _, err := lookPathFunc("docker"); err == nil {
-		return true
-	}
-	if _, err := lookPathFunc("colima"); err == nil {
-		return true
-	}
-	return false
+	_, err := container.SelectRuntime("", lookPathFunc)
+	return err == nil
 }
 
 // dockerSocketPathsFunc returns well-known Docker socket paths to probe when
@@ -76,21 +71,45 @@ func ensureDockerHost() {
 	}
 }
 
-// checkDockerDaemon verifies a container runtime daemon is reachable, not just
-// that the binary exists. This catches the case where a Docker-compatible container
-// runtime is installed but not running, which would otherwise cause silent per-session failures.
-// Works with OrbStack, Docker Desktop, and Colima since all expose a Docker-compatible API.
+// checkDockerDaemon selects the container runtime ($ERG_CONTAINER_RUNTIME, or
+// the first one installed) and verifies its daemon is reachable, not just
+// that the binary exists. This catches the case where a container runtime is
+// installed but not running, which would otherwise cause silent per-session
+// failures. Docker covers OrbStack, Docker Desktop, and Colima since all
+// expose a Docker-compatible API; Podman and nerdctl are driven directly.
 func checkDockerDaemon() error {
+	rt, err := container.SelectRuntime(os.Getenv(container.RuntimeEnvVar), lookPathFunc)
+	if err != nil {
+		return err
+	}
+	container.SetRuntime(rt)
+
+	if rt.Name() != "docker" {
+		return checkRuntimeReachable(rt)
+	}
 	ensureDockerHost()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := exec.CommandContext(ctx, "docker", "info").Run(); err != nil {
+	if err := rt.Command(ctx, "info").Run(); err != nil {
 		if errors.Is(err, exec.ErrNotFound) {
 			hint := runtimeStartHint()
 			return fmt.Errorf("docker CLI not found on PATH — install a container runtime that provides it%s", hint)
 		}
 		hint := runtimeStartHint()
-		return fmt.Errorf("container runtime is not reachable (is OrbStack, Docker Desktop, or Colima running?)%s", hint)
+		return fmt.Errorf("container runtime is not reachable (%s)%s", rt.StartHint(), hint)
+	}
+	return nil
+}
+
+// checkRuntimeReachable verifies a non-Docker runtime's engine answers.
+func checkRuntimeReachable(rt container.Runtime) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rt.Command(ctx, "info").Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s CLI not found on PATH — install it or unset %s to auto-detect a runtime", rt.Name(), container.RuntimeEnvVar)
+		}
+		return fmt.Errorf("container runtime %s is not reachable (%s)", rt.Name(), rt.StartHint())
 	}
 	return nil
 }
@@ -143,8 +162,9 @@ func findSingleRunningDaemon() (string, error) {
 var readLockFileKey = readLockFileKeyDefault
 
 func readLockFileKeyDefault(lockPath string) (string, error) {
-	// Lock files are named daemon-<hash>.lock. State files are daemon-state-<hash>.json.
-	// Extract the hash from the lock file name and find the matching state file.
+	// Lock files are named daemon-<hash>.lock. State files are daemon-state-<hash>.db
+	// (or .json, left by older versions). Extract the hash from the lock file name
+	// and find the matching state file.
 	// The state file contains the repo_path.
 	base := strings.TrimSuffix(filepath.Base(lockPath), ".lock")
 	hash := strings.TrimPrefix(base, "daemon-")
@@ -159,32 +179,25 @@ func readLockFileKeyDefault(lockPath string) (string, error) {
 		stateDir = dir
 	}
 
-	statePath := filepath.Join(stateDir, fmt.Sprintf("daemon-state-%s.json", hash))
-	state, err := loadStateRepoPath(statePath)
-	if err != nil {
-		// If no state file, the hash itself is the best we have
-		return hash, nil
+	for _, name := range []string{"daemon-state-%s.db", "daemon-state-%s.json"} {
+		if repo, err := loadStateRepoPath(filepath.Join(stateDir, fmt.Sprintf(name, hash))); err == nil {
+			return repo, nil
+		}
 	}
-	return state, nil
+	// If no state file, the hash itself is the best we have
+	return hash, nil
 }
 
 // loadStateRepoPath reads just the repo_path from a daemon state file.
 func loadStateRepoPath(path string) (string, error) {
-	data, err := os.ReadFile(path)
+	repo, err := daemonstate.ReadStateRepoPath(path)
 	if err != nil {
 		return "", err
 	}
-	// Quick extract of repo_path without full JSON unmarshal
-	var partial struct {
-		RepoPath string `json:"repo_path"`
-	}
-	if err := json.Unmarshal(data, &partial); err != nil {
-		return "", err
-	}
-	if partial.RepoPath == "" {
+	if repo == "" {
 		return "", fmt.Errorf("no repo_path in state file")
 	}
-	return partial.RepoPath, nil
+	return repo, nil
 }
 
 // issueLabel formats an issue reference into a display label, truncated to maxWidth runes.
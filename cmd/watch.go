@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/control"
+)
+
+var (
+	watchRepo  string
+	watchLines int
+)
+
+var watchCmd = &cobra.Command{
+	Use:     "watch <item>",
+	Short:   "Follow a work item's session live",
+	GroupID: "daemon",
+	Long: `Stream what the agent working on a work item is doing as it does it: the
+text it writes and the tools it calls, starting with the last few lines of
+its session. Step changes are shown as they happen, and a step that starts
+a new session is followed into it. The watch ends when the work item
+finishes, or on Ctrl-C; the work item itself is left alone.
+
+The item is named by its ID or its issue number. The transcript comes from
+the running orchestrator's control socket; 'erg daemon logs' shows the same
+log once, without following it.`,
+	Example: `  erg watch 42
+  erg watch 42 -n 100
+  erg watch owner-repo-42 --repo owner/repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&watchRepo, "repo", "", "Repo whose orchestrator to watch (owner/repo or filesystem path)")
+	watchCmd.Flags().IntVarP(&watchLines, "lines", "n", 20, "Number of already-written lines to start with")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(c *cobra.Command, args []string) error {
+	repo, err := resolveDaemonRepo(watchRepo)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := c.OutOrStdout()
+	req := control.Request{Command: control.CommandWatch, Item: args[0], Lines: watchLines}
+	err = control.Watch(ctx, control.SocketPath(repo), req, func(ev control.Event) error {
+		return printWatchEvent(out, ev)
+	})
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil
+	case err != nil:
+		return fmt.Errorf("%w\n\nIs the orchestrator for %s running? Check with 'erg status'", err, repo)
+	}
+	return nil
+}
+
+// printWatchEvent writes one event of a watch stream. Events it does not
+// know are skipped, so newer orchestrators can stream more.
+func printWatchEvent(w io.Writer, ev control.Event) error {
+	switch ev.Name {
+	case control.EventLine:
+		var ln control.Line
+		if err := json.Unmarshal(ev.Data, &ln); err != nil {
+			return fmt.Errorf("malformed transcript line: %w", err)
+		}
+		fmt.Fprintln(w, formatWatchLine(ln))
+	case control.EventStatus:
+		var item control.Item
+		if err := json.Unmarshal(ev.Data, &item); err != nil {
+			return fmt.Errorf("malformed status: %w", err)
+		}
+		fmt.Fprintf(w, "── %s ──\n", describeWatchedItem(item))
+	case control.EventEnd:
+		var item control.Item
+		if err := json.Unmarshal(ev.Data, &item); err != nil {
+			return fmt.Errorf("malformed status: %w", err)
+		}
+		fmt.Fprintf(w, "Work item %s %s.\n", item.ID, item.State)
+	}
+	return nil
+}
+
+// formatWatchLine formats a transcript line as 'erg daemon logs' shows it.
+func formatWatchLine(ln control.Line) string {
+	switch {
+	case ln.Type != "tool":
+		return ln.Text
+	case ln.Text != "":
+		return fmt.Sprintf("[%s: %s]", claude.FormatToolIcon(ln.Name), ln.Text)
+	default:
+		return fmt.Sprintf("[%s]", claude.FormatToolIcon(ln.Name))
+	}
+}
+
+// describeWatchedItem says where a watched work item is: its issue, step,
+// and state.
+func describeWatchedItem(item control.Item) string {
+	state := item.State
+	if item.Phase != "" && item.Phase != "idle" {
+		state += "/" + item.Phase
+	}
+	desc := fmt.Sprintf("#%s %s (%s)", item.Issue, item.Step, state)
+	if item.Step == "" {
+		desc = fmt.Sprintf("#%s (%s)", item.Issue, state)
+	}
+	if item.Session == "" {
+		desc += ", no session yet"
+	}
+	return desc
+}
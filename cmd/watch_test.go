@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/control"
+)
+
+func TestRunWatch_StreamsUntilEnd(t *testing.T) {
+	repo := t.TempDir()
+	srv, err := control.Listen(control.SocketPath(repo), func(control.Request) (control.Response, error) {
+		return control.Response{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got control.Request
+	srv.HandleStreams(func(req control.Request) (control.Response, control.StreamFunc, error) {
+		got = req
+		if req.Item != "42" {
+			return control.Response{}, nil, errors.New(`no work item for "` + req.Item + `"`)
+		}
+		return control.Response{}, func(ctx context.Context, emit control.Emitter) error {
+			emit(control.EventStatus, control.Item{ID: "item-42", Issue: "42", Step: "coding", State: "active", Session: "s1"})
+			emit(control.EventLine, control.Line{Type: "text", Text: "Reading the handler"})
+			emit(control.EventLine, control.Line{Type: "tool", Name: "Bash", Text: "go test ./..."})
+			emit("progress", map[string]int{"pct": 50})
+			return emit(control.EventEnd, control.Item{ID: "item-42", State: "completed"})
+		}, nil
+	})
+	go srv.Serve(t.Context())
+
+	origRepo, origLines := watchRepo, watchLines
+	t.Cleanup(func() { watchRepo, watchLines = origRepo, origLines })
+	watchRepo, watchLines = repo, 5
+
+	var out bytes.Buffer
+	watchCmd.SetOut(&out)
+	t.Cleanup(func() { watchCmd.SetOut(nil) })
+	if err := runWatch(watchCmd, []string{"42"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Command != control.CommandWatch || got.Lines != 5 {
+		t.Errorf("request = %+v", got)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || lines[0] != "── #42 coding (active) ──" || lines[1] != "Reading the handler" {
+		t.Fatalf("output:\n%s", out.String())
+	}
+	for _, wantLine := range []string{": go test ./...]", "Work item item-42 completed."} {
+		if !strings.Contains(out.String(), wantLine) {
+			t.Errorf("output missing %q:\n%s", wantLine, out.String())
+		}
+	}
+
+	if err := runWatch(watchCmd, []string{"7"}); err == nil || !strings.Contains(err.Error(), "no work item") {
+		t.Errorf("expected the orchestrator's error, got %v", err)
+	}
+}
+
+func TestRunWatch_NoDaemon(t *testing.T) {
+	orig := watchRepo
+	t.Cleanup(func() { watchRepo = orig })
+	watchRepo = "/nonexistent/repo/with/no/daemon"
+
+	if err := runWatch(watchCmd, []string{"42"}); err == nil || !strings.Contains(err.Error(), "erg status") {
+		t.Errorf("expected a hint to check status, got %v", err)
+	}
+}
+
+func TestDescribeWatchedItem(t *testing.T) {
+	tests := []struct {
+		item control.Item
+		want string
+	}{
+		{control.Item{Issue: "42", Step: "coding", State: "active", Session: "s1"}, "#42 coding (active)"},
+		{control.Item{Issue: "42", Step: "await_ci", State: "active", Phase: "async_pending", Session: "s1"}, "#42 await_ci (active/async_pending)"},
+		{control.Item{Issue: "42", State: "queued"}, "#42 (queued), no session yet"},
+	}
+	for _, tt := range tests {
+		if got := describeWatchedItem(tt.item); got != tt.want {
+			t.Errorf("describeWatchedItem(%+v) = %q, want %q", tt.item, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/manifest"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/webhook"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	webhookURL        string
+	webhookRepo       string
+	webhookConfigFile string
+)
+
+var webhookCmd = &cobra.Command{
+	Use:     "webhook",
+	Short:   "Manage issue-tracker webhooks",
+	GroupID: "setup",
+}
+
+var webhookSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Register issue-tracker webhooks that wake the orchestrator",
+	Long: `Registers a webhook with each repo's issue tracker so the orchestrator
+picks up newly labeled issues immediately instead of on its next poll.
+
+--url is the public base URL of the orchestrator's webhook listener, started
+with 'erg start --webhook-addr'. Deliveries are sent to:
+
+  GitHub  <url>/webhooks/github          (issue opened, reopened, labeled)
+  Linear  <url>/webhooks/linear          (issue created or updated in the team)
+  Asana   <url>/webhooks/asana/<project> (task added or changed in the project)
+
+GitHub and Linear deliveries are signed with a shared secret that is
+generated on first run and stored in ~/.erg/webhooks.json (override it with
+ERG_WEBHOOK_SECRET). Asana supplies its own secret during a handshake with
+the listener, so the orchestrator must be running and reachable at --url
+when an Asana webhook is registered.
+
+Webhooks only trigger an immediate poll; regular polling keeps running, so
+a missed delivery only delays pickup. Other providers are skipped.
+
+Examples:
+  erg webhook setup --url https://erg.example.com
+  erg webhook setup --url https://erg.example.com --repo /path/to/repo
+  erg webhook setup --url https://erg.example.com --config ~/.erg/daemon.yaml`,
+	RunE: runWebhookSetup,
+}
+
+func init() {
+	webhookSetupCmd.Flags().StringVar(&webhookURL, "url", "", "Public base URL of the webhook listener (required)")
+	webhookSetupCmd.Flags().StringVar(&webhookRepo, "repo", "", "Repo to register webhooks for (filesystem path)")
+	webhookSetupCmd.Flags().StringVar(&webhookConfigFile, "config", "", "Path to config file for multi-repo mode")
+	webhookSetupCmd.MarkFlagRequired("url") //nolint:errcheck
+	webhookCmd.AddCommand(webhookSetupCmd)
+	rootCmd.AddCommand(webhookCmd)
+}
+
+// webhookTarget is one repo's tracker and the scope to register a webhook for.
+type webhookTarget struct {
+	repo     string
+	provider string
+	team     string // Linear team ID
+	project  string // Asana project GID
+}
+
+// webhookRegistrar creates webhooks with each provider. Overridden in tests.
+type webhookRegistrar struct {
+	github func(ctx context.Context, repo, url, secret string) error
+	linear func(ctx context.Context, team, url, secret string) error
+	asana  func(ctx context.Context, project, url string) error
+}
+
+func defaultWebhookRegistrar() webhookRegistrar {
+	return webhookRegistrar{
+		github: git.NewGitService().CreateRepoWebhook,
+		linear: issues.RegisterLinearWebhook,
+		asana:  issues.RegisterAsanaWebhook,
+	}
+}
+
+func runWebhookSetup(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if webhookConfigFile != "" && webhookRepo != "" {
+		return fmt.Errorf("--config cannot be used with --repo")
+	}
+
+	targets, err := resolveWebhookTargets(ctx)
+	if err != nil {
+		return err
+	}
+	secretsPath, err := webhook.SecretsPath()
+	if err != nil {
+		return err
+	}
+	return setupWebhooks(ctx, cmd.OutOrStdout(), targets, webhookURL, secretsPath, defaultWebhookRegistrar())
+}
+
+// resolveWebhookTargets reads the tracker settings of each repo from the
+// manifest (--config) or of the single --repo / current repo.
+func resolveWebhookTargets(ctx context.Context) ([]webhookTarget, error) {
+	type repoWorkflow struct{ path, workflow string }
+	var repos []repoWorkflow
+	if webhookConfigFile != "" {
+		m, err := manifest.LoadFile(webhookConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading manifest: %w", err)
+		}
+		for _, entry := range m.Repos {
+			repos = append(repos, repoWorkflow{entry.Path, entry.Workflow})
+		}
+	} else {
+		repo, err := resolveAgentRepo(ctx, webhookRepo, session.NewSessionService())
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repoWorkflow{path: repo})
+	}
+
+	targets := make([]webhookTarget, 0, len(repos))
+	for _, r := range repos {
+		wfCfg, err := workflow.LoadAndMergeWithFile(r.path, r.workflow)
+		if err != nil {
+			return nil, fmt.Errorf("error loading workflow for %s: %w", r.path, err)
+		}
+		targets = append(targets, webhookTarget{
+			repo:     r.path,
+			provider: wfCfg.Source.Provider,
+			team:     wfCfg.Source.Filter.Team,
+			project:  wfCfg.Source.Filter.Project,
+		})
+	}
+	return targets, nil
+}
+
+// setupWebhooks registers a webhook for each target, continuing past
+// failures so one misconfigured repo doesn't block the rest. The signing
+// secret is saved before any registration so the listener can verify the
+// first delivery (GitHub sends a ping immediately).
+func setupWebhooks(ctx context.Context, out io.Writer, targets []webhookTarget, baseURL, secretsPath string, reg webhookRegistrar) error {
+	baseURL = strings.TrimRight(baseURL, "/")
+	secrets, err := webhook.LoadSecrets(secretsPath)
+	if err != nil {
+		return err
+	}
+	secret, err := secrets.EnsureSecret()
+	if err != nil {
+		return err
+	}
+	if err := secrets.Save(secretsPath); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, t := range targets {
+		var url string
+		var regErr error
+		switch t.provider {
+		case string(issues.SourceGitHub):
+			url = baseURL + "/webhooks/github"
+			regErr = reg.github(ctx, t.repo, url, secret)
+		case string(issues.SourceLinear):
+			if t.team == "" {
+				regErr = fmt.Errorf("source.filter.team is required for Linear webhooks")
+				break
+			}
+			url = baseURL + "/webhooks/linear"
+			regErr = reg.linear(ctx, t.team, url, secret)
+		case string(issues.SourceAsana):
+			if t.project == "" {
+				regErr = fmt.Errorf("source.filter.project is required for Asana webhooks")
+				break
+			}
+			// Let the listener accept this project's handshake.
+			secrets.ExpectAsanaHandshake(t.project)
+			if regErr = secrets.Save(secretsPath); regErr != nil {
+				break
+			}
+			url = baseURL + "/webhooks/asana/" + t.project
+			regErr = reg.asana(ctx, t.project, url)
+		default:
+			fmt.Fprintf(out, "- %s: %s does not support webhooks, polling only\n", t.repo, t.provider)
+			continue
+		}
+		if regErr != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", t.repo, regErr)
+			errs = append(errs, fmt.Errorf("%s: %w", t.repo, regErr))
+			continue
+		}
+		fmt.Fprintf(out, "✓ %s: %s webhook → %s\n", t.repo, t.provider, url)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to register %d webhook(s): %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
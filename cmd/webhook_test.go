@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/webhook"
+)
+
+func TestSetupWebhooks(t *testing.T) {
+	t.Setenv(webhook.SecretEnvVar, "")
+	secretsPath := filepath.Join(t.TempDir(), "webhooks.json")
+
+	var calls []string
+	reg := webhookRegistrar{
+		github: func(_ context.Context, repo, url, secret string) error {
+			if secret == "" {
+				t.Error("expected a signing secret for GitHub")
+			}
+			calls = append(calls, "github "+repo+" "+url)
+			return nil
+		},
+		linear: func(_ context.Context, team, url, secret string) error {
+			calls = append(calls, "linear "+team+" "+url)
+			return errors.New("forbidden")
+		},
+		asana: func(_ context.Context, project, url string) error {
+			// The handshake must be expected before Asana calls back.
+			stored, err := webhook.LoadSecrets(secretsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, pending := stored.Asana[project]; !pending {
+				t.Errorf("expected project %s to await its handshake", project)
+			}
+			calls = append(calls, "asana "+project+" "+url)
+			return nil
+		},
+	}
+	targets := []webhookTarget{
+		{repo: "/repo/gh", provider: "github"},
+		{repo: "/repo/lin", provider: "linear", team: "team-1"},
+		{repo: "/repo/asana", provider: "asana", project: "proj-1"},
+		{repo: "/repo/asana-noproj", provider: "asana"},
+		{repo: "/repo/jira", provider: "jira"},
+	}
+
+	var out bytes.Buffer
+	err := setupWebhooks(context.Background(), &out, targets, "https://erg.example.com/", secretsPath, reg)
+	if err == nil || !strings.Contains(err.Error(), "failed to register 2 webhook(s)") {
+		t.Fatalf("expected two failures, got %v", err)
+	}
+
+	want := []string{
+		"github /repo/gh https://erg.example.com/webhooks/github",
+		"linear team-1 https://erg.example.com/webhooks/linear",
+		"asana proj-1 https://erg.example.com/webhooks/asana/proj-1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+	for _, line := range []string{
+		"✓ /repo/gh: github webhook",
+		"✗ /repo/lin: forbidden",
+		"✗ /repo/asana-noproj: source.filter.project is required",
+		"- /repo/jira: jira does not support webhooks",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in output:\n%s", line, out.String())
+		}
+	}
+
+	stored, err := webhook.LoadSecrets(secretsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Secret == "" {
+		t.Error("expected the generated secret to be saved")
+	}
+}
+
+func TestWebhookSetupRequiresURL(t *testing.T) {
+	flag := webhookSetupCmd.Flags().Lookup("url")
+	if flag == nil {
+		t.Fatal("expected --url flag")
+	}
+	if ann := flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]; len(ann) == 0 {
+		t.Error("expected --url to be required")
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/session"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+var (
+	workflowValidateRepo string
+	workflowValidateFile string
+)
+
+var workflowCmd = &cobra.Command{
+	Use:     "workflow",
+	Short:   "Inspect the repo's workflow configuration",
+	GroupID: "setup",
+}
+
+var workflowValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check .erg/workflow.yaml for errors",
+	Long: `Loads the repo's workflow config and reports every problem found, each
+with the line of the file it comes from:
+
+  - YAML syntax errors and unknown keys
+  - transitions to states that do not exist
+  - states that cannot be reached from the start state or any trigger
+  - states from which no succeed or fail state can be reached
+  - invalid actions, params, models, and hooks
+
+The same checks run when erg starts, which refuses to start on an invalid
+config.
+
+Examples:
+  erg workflow validate
+  erg workflow validate --workflow .erg/release.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runWorkflowValidate,
+}
+
+func init() {
+	workflowValidateCmd.Flags().StringVar(&workflowValidateRepo, "repo", "", "Repo path (default: current git root)")
+	workflowValidateCmd.Flags().StringVar(&workflowValidateFile, "workflow", "", "Path to workflow config file")
+	workflowCmd.AddCommand(workflowValidateCmd)
+	rootCmd.AddCommand(workflowCmd)
+}
+
+func runWorkflowValidate(cmd *cobra.Command, args []string) error {
+	repoPath, err := resolveAgentRepo(context.Background(), workflowValidateRepo, session.NewSessionService())
+	if err != nil {
+		return err
+	}
+	return validateWorkflowFile(os.Stdout, repoPath, workflowValidateFile)
+}
+
+// validateWorkflowFile lints the workflow for repoPath and reports the result
+// to w. It returns an error if the config is missing or invalid.
+func validateWorkflowFile(w io.Writer, repoPath, workflowFile string) error {
+	wfCfg, err := lintWorkflowFile(repoPath, workflowFile, claude.IsValidModel)
+	if err != nil {
+		return err
+	}
+	if wfCfg == nil {
+		return fmt.Errorf("no workflow config found in %s — run `erg workflow init` to create .erg/workflow.yaml", repoPath)
+	}
+	fmt.Fprintf(w, "%s: ok (%d states)\n", workflow.ConfigPath(repoPath, workflowFile), len(wfCfg.States))
+	return nil
+}
+
+// lintWorkflowFile loads the workflow config with workflow.LintFile, adding
+// the model checks, and returns an error listing every problem by line.
+// Returns nil, nil if no workflow file exists.
+func lintWorkflowFile(repoPath, workflowFile string, isValidModel func(string) bool) (*workflow.Config, error) {
+	modelCheck := func(cfg *workflow.Config) []workflow.ValidationError {
+		return modelValidationErrors(cfg, isValidModel)
+	}
+	wfCfg, errs, err := workflow.LintFile(repoPath, workflowFile, modelCheck)
+	if err != nil {
+		return nil, fmt.Errorf("error loading workflow config: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", formatLintErrors(workflow.ConfigPath(repoPath, workflowFile), errs))
+	}
+	return wfCfg, nil
+}
+
+// formatLintErrors renders errs one per line as "path:line: field: message",
+// omitting the line or field when unknown.
+func formatLintErrors(path string, errs []workflow.ValidationError) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "workflow configuration errors (%d):\n", len(errs))
+	for _, e := range errs {
+		loc := path
+		if e.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", path, e.Line)
+		}
+		if e.Field != "" {
+			fmt.Fprintf(&sb, "  %s: %s: %s\n", loc, e.Field, e.Message)
+		} else {
+			fmt.Fprintf(&sb, "  %s: %s\n", loc, e.Message)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func writeRepoWorkflow(t *testing.T, content string) string {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".erg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".erg", "workflow.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestValidateWorkflowFile_Valid(t *testing.T) {
+	repo := writeRepoWorkflow(t, workflow.Template)
+
+	var buf bytes.Buffer
+	if err := validateWorkflowFile(&buf, repo, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "ok") {
+		t.Errorf("expected ok output, got %q", buf.String())
+	}
+}
+
+func TestValidateWorkflowFile_Missing(t *testing.T) {
+	var buf bytes.Buffer
+	err := validateWorkflowFile(&buf, t.TempDir(), "")
+	if err == nil || !strings.Contains(err.Error(), "no workflow config found") {
+		t.Errorf("expected missing config error, got %v", err)
+	}
+}
+
+func TestValidateWorkflowFile_ReportsLines(t *testing.T) {
+	repo := writeRepoWorkflow(t, `source:
+  provider: github
+  filter:
+    label: queued
+start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    model: gpt-2
+    next: nowhere
+    befor:
+      - run: make setup
+`)
+
+	var buf bytes.Buffer
+	err := validateWorkflowFile(&buf, repo, "")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	path := filepath.Join(repo, ".erg", "workflow.yaml")
+	for _, want := range []string{
+		path + ":10: states.coding.model: unknown model",
+		path + ":11: states.coding.next: references non-existent state",
+		path + `:12: states.coding.befor: unknown key "befor"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%s", want, err)
+		}
+	}
+}
+
+func TestFormatLintErrors(t *testing.T) {
+	got := formatLintErrors("wf.yaml", []workflow.ValidationError{
+		{Field: "start", Message: "start state is required"},
+		{Field: "states.a.next", Message: "bad", Line: 4},
+		{Message: "mapping values are not allowed", Line: 7},
+	})
+	for _, want := range []string{
+		"workflow configuration errors (3):",
+		"  wf.yaml: start: start state is required\n",
+		"  wf.yaml:4: states.a.next: bad\n",
+		"  wf.yaml:7: mapping values are not allowed\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
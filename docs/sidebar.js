@@ -61,6 +61,7 @@
         { href: "actions.html#actions-git", text: "git", type: "sub" },
         { href: "actions.html#actions-asana", text: "asana", type: "sub" },
         { href: "actions.html#actions-linear", text: "linear", type: "sub" },
+        { href: "actions.html#actions-issue", text: "issue", type: "sub" },
         { href: "actions.html#actions-slack", text: "slack", type: "sub" },
         { href: "actions.html#actions-webhook", text: "webhook", type: "sub" },
         { href: "actions.html#actions-workflow", text: "workflow", type: "sub" },
@@ -0,0 +1,195 @@
+// Package agentbackend runs agent sessions on model APIs other than the
+// Claude CLI.
+//
+// # Overview
+//
+// The session worker drives a session through the Backend interface. The
+// Claude CLI runner (claude.Runner) is one Backend; Runner is another,
+// which runs the agent loop itself against a Provider: it sends the
+// conversation to the model, carries out the tool calls the model makes in
+// the session's worktree, and streams the results back as
+// claude.ResponseChunk values, so the worker treats both the same way.
+//
+// # Providers
+//
+// Providers speak one model API each:
+//   - openai: the OpenAI chat completions API, which Ollama, vLLM, LiteLLM
+//     and most hosted model gateways also serve
+//   - gemini: Google's Gemini generateContent API
+//   - bedrock: the AWS Bedrock Converse API
+//   - local: a local inference server (Ollama, vLLM, a llama.cpp server)
+//     speaking the OpenAI API, streamed, with token counts estimated when
+//     the server doesn't report them
+//
+// # Tools
+//
+// Runner offers the model read_file, write_file, list_files and
+// run_command, which work on the session's worktree (run_command in a
+// throwaway container of the session image when containerized), plus the
+// host tools (create_pr, comment_issue, submit_result, ...) the worker
+// answers for Claude sessions.
+//
+// # Context
+//
+// Runner keeps the conversation inside the model's context window,
+// summarizing older turns as it nears the limit while pinning the session's
+// prompts, the latest failing command output and the latest diff verbatim.
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/mcp"
+)
+
+// Backend is an agent session the worker can drive: it takes prompts,
+// streams the agent's progress back, and raises host tool requests for the
+// worker to answer. claude.Runner and Runner implement it.
+type Backend interface {
+	claude.RunnerSession
+}
+
+var (
+	_ Backend                = (*claude.Runner)(nil)
+	_ claude.RunnerInterface = (*Runner)(nil)
+)
+
+// Provider names.
+const (
+	ProviderOpenAI  = "openai"
+	ProviderGemini  = "gemini"
+	ProviderBedrock = "bedrock"
+	ProviderLocal   = "local"
+)
+
+// DefaultAPIKeyEnv returns the environment variable holding provider's API
+// key by default, or "" when the provider needs none.
+func DefaultAPIKeyEnv(provider string) string {
+	switch provider {
+	case ProviderOpenAI:
+		return "OPENAI_API_KEY"
+	case ProviderGemini:
+		return "GEMINI_API_KEY"
+	case ProviderBedrock:
+		return "AWS_BEARER_TOKEN_BEDROCK"
+	}
+	return ""
+}
+
+// Config selects a provider and model.
+type Config struct {
+	Provider string
+	Model    string
+	// BaseURL overrides the provider's endpoint.
+	BaseURL string
+	// APIKey authenticates with the provider. For Bedrock, when empty,
+	// requests are signed with the AWS credentials in the environment.
+	APIKey string
+	// Region is the AWS region of a Bedrock backend.
+	Region string
+	// InputPrice and OutputPrice are USD per million tokens.
+	InputPrice  float64
+	OutputPrice float64
+	// ContextWindow is the model's context length in tokens; 0 assumes a
+	// typical window for the provider.
+	ContextWindow int
+}
+
+// Role of a Turn.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	// RoleTool turns carry the results of the previous assistant turn's
+	// tool calls.
+	RoleTool = "tool"
+)
+
+// Turn is one message of a conversation with a model.
+type Turn struct {
+	Role        string
+	Text        string
+	ToolCalls   []ToolCall
+	ToolResults []ToolResult
+	// Raw is the provider's own encoding of an assistant turn, when it must
+	// be sent back as it came.
+	Raw json.RawMessage
+}
+
+// ToolCall is the model asking for a tool to run.
+type ToolCall struct {
+	ID    string
+	Name  string
+	Input json.RawMessage
+}
+
+// ToolResult is the output of a tool call, returned to the model.
+type ToolResult struct {
+	CallID  string
+	Name    string
+	Content string
+	IsError bool
+}
+
+// Request is one call to a model: the whole conversation so far and the
+// tools it may call.
+type Request struct {
+	Model  string
+	System string
+	Turns  []Turn
+	Tools  []mcp.ToolDefinition
+}
+
+// Response is a model's reply: text, tool calls or both.
+type Response struct {
+	Text      string
+	ToolCalls []ToolCall
+	Usage     Usage
+	// Raw becomes the assistant turn's Raw.
+	Raw json.RawMessage
+}
+
+// Usage counts the tokens of one call.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider sends requests to one model API.
+type Provider interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// StreamingProvider is a Provider that streams a reply's text as the model
+// generates it.
+type StreamingProvider interface {
+	Provider
+	// CompleteStream is Complete, calling onText with each piece of the
+	// reply's text as it arrives.
+	CompleteStream(ctx context.Context, req Request, onText func(string)) (Response, error)
+}
+
+// NewProvider returns the provider cfg names, sending requests with client.
+func NewProvider(cfg Config, client *http.Client) (Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		return &openAIProvider{client: client, baseURL: cfg.BaseURL, apiKey: cfg.APIKey}, nil
+	case ProviderGemini:
+		return &geminiProvider{client: client, baseURL: cfg.BaseURL, apiKey: cfg.APIKey}, nil
+	case ProviderBedrock:
+		return &bedrockProvider{client: client, baseURL: cfg.BaseURL, apiKey: cfg.APIKey, region: cfg.Region, now: timeNow}, nil
+	case ProviderLocal:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = DefaultLocalURL
+		}
+		return &localProvider{&openAIProvider{client: client, baseURL: baseURL, apiKey: cfg.APIKey}}, nil
+	}
+	return nil, fmt.Errorf("unknown agent backend provider %q", cfg.Provider)
+}
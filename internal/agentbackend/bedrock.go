@@ -0,0 +1,243 @@
+package agentbackend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timeNow is the clock Bedrock requests are signed with.
+var timeNow = time.Now
+
+// bedrockProvider speaks the AWS Bedrock Converse API, authenticating with
+// a Bedrock API key or, without one, by signing requests with the AWS
+// credentials in the environment.
+type bedrockProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	region  string
+	now     func() time.Time
+}
+
+type bedrockContent struct {
+	Text       string             `json:"text,omitempty"`
+	ToolUse    *bedrockToolUse    `json:"toolUse,omitempty"`
+	ToolResult *bedrockToolResult `json:"toolResult,omitempty"`
+}
+
+type bedrockToolUse struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+type bedrockToolResult struct {
+	ToolUseID string           `json:"toolUseId"`
+	Content   []bedrockContent `json:"content"`
+	Status    string           `json:"status,omitempty"`
+}
+
+type bedrockMessage struct {
+	Role    string           `json:"role"`
+	Content []bedrockContent `json:"content"`
+}
+
+func (p *bedrockProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	var messages []bedrockMessage
+	for _, t := range req.Turns {
+		switch t.Role {
+		case RoleTool:
+			m := bedrockMessage{Role: "user"}
+			for _, r := range t.ToolResults {
+				status := "success"
+				if r.IsError {
+					status = "error"
+				}
+				m.Content = append(m.Content, bedrockContent{ToolResult: &bedrockToolResult{
+					ToolUseID: r.CallID, Content: []bedrockContent{{Text: r.Content}}, Status: status,
+				}})
+			}
+			messages = append(messages, m)
+		case RoleAssistant:
+			m := bedrockMessage{Role: "assistant"}
+			if t.Text != "" {
+				m.Content = append(m.Content, bedrockContent{Text: t.Text})
+			}
+			for _, c := range t.ToolCalls {
+				m.Content = append(m.Content, bedrockContent{ToolUse: &bedrockToolUse{ToolUseID: c.ID, Name: c.Name, Input: c.Input}})
+			}
+			messages = append(messages, m)
+		default:
+			messages = append(messages, bedrockMessage{Role: "user", Content: []bedrockContent{{Text: t.Text}}})
+		}
+	}
+
+	body := map[string]any{"messages": messages}
+	if req.System != "" {
+		body["system"] = []bedrockContent{{Text: req.System}}
+	}
+	if len(req.Tools) > 0 {
+		var tools []map[string]any
+		for _, d := range req.Tools {
+			tools = append(tools, map[string]any{"toolSpec": map[string]any{
+				"name": d.Name, "description": d.Description, "inputSchema": map[string]any{"json": d.InputSchema},
+			}})
+		}
+		body["toolConfig"] = map[string]any{"tools": tools}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	base := p.baseURL
+	if base == "" {
+		base = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", p.region)
+	}
+	// Model IDs such as amazon.nova-pro-v1:0 are escaped in the path.
+	path := "/model/" + awsURIEncode(req.Model) + "/converse"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	} else {
+		creds := awsCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return Response{}, fmt.Errorf("bedrock: no API key and no AWS credentials (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY) in the environment")
+		}
+		signAWSRequest(httpReq, data, creds, p.region, "bedrock", p.now())
+	}
+
+	var out struct {
+		Output struct {
+			Message bedrockMessage `json:"message"`
+		} `json:"output"`
+		Usage struct {
+			InputTokens  int `json:"inputTokens"`
+			OutputTokens int `json:"outputTokens"`
+		} `json:"usage"`
+	}
+	if err := doJSON(p.client, httpReq, &out); err != nil {
+		return Response{}, fmt.Errorf("bedrock: %w", err)
+	}
+	resp := Response{Usage: Usage{InputTokens: out.Usage.InputTokens, OutputTokens: out.Usage.OutputTokens}}
+	var text []string
+	for _, c := range out.Output.Message.Content {
+		if c.Text != "" {
+			text = append(text, c.Text)
+		}
+		if tu := c.ToolUse; tu != nil {
+			input := tu.Input
+			if len(input) == 0 {
+				input = json.RawMessage("{}")
+			}
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: tu.ToolUseID, Name: tu.Name, Input: input})
+		}
+	}
+	resp.Text = strings.Join(text, "")
+	return resp, nil
+}
+
+// awsCredentials are the AWS keys requests are signed with.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// signAWSRequest signs req, whose body is body, with AWS Signature Version
+// 4 for service in region at time t.
+func signAWSRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// Outside S3, each path segment is encoded again for signing.
+	segments := strings.Split(req.URL.EscapedPath(), "/")
+	for i, s := range segments {
+		segments[i] = awsURIEncode(s)
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		strings.Join(segments, "/"),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode percent-encodes every byte of s but the unreserved
+// characters, as AWS signing requires.
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
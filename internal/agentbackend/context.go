@@ -0,0 +1,271 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// defaultContextWindows are the context lengths, in tokens, assumed for
+// the providers' models when a backend doesn't set one.
+var defaultContextWindows = map[string]int{
+	ProviderOpenAI:  128_000,
+	ProviderGemini:  1_000_000,
+	ProviderBedrock: 200_000,
+	ProviderLocal:   32_768,
+}
+
+const (
+	// compactAt is the share of the context window a conversation may
+	// fill before its older turns are summarized.
+	compactAt = 0.75
+	// keepRecent is the share of the context window the latest turns keep
+	// verbatim when the conversation is compacted.
+	keepRecent = 0.25
+	// minSummarized is the share of the context window the turns to
+	// summarize must take for compacting to be worth a model call.
+	minSummarized = 0.1
+	// summaryResultChars caps each tool result in the transcript sent to
+	// be summarized.
+	summaryResultChars = 2000
+)
+
+// summarizePrompt asks the model to summarize the earlier part of a
+// session.
+const summarizePrompt = `You are summarizing the earlier part of a coding agent's session so it can continue with less context. Summarize what was done and learned: files read and changed, commands run and their outcomes, decisions made, and what remains to do. Keep file paths, identifiers and error messages exact. Reply with the summary only.`
+
+// contextWindow returns the context length of the configured model.
+func (c Config) contextWindow() int {
+	if c.ContextWindow > 0 {
+		return c.ContextWindow
+	}
+	if n, ok := defaultContextWindows[c.Provider]; ok {
+		return n
+	}
+	return defaultContextWindows[ProviderOpenAI]
+}
+
+// contextManager keeps a runner's conversation inside its model's context
+// window. It tracks the conversation's size in tokens and, as it nears the
+// window, replaces the older turns with a summary. What the summary would
+// lose is pinned verbatim: the prompts the session was given (the issue,
+// CI failures, review feedback and diffs they carry), the latest failing
+// command's output and the latest diff the model looked at.
+type contextManager struct {
+	window int
+
+	// tokens is the conversation's size as the model last reported it,
+	// through its first turns turns.
+	tokens int
+	turns  int
+
+	// compacted is set once the conversation's first turn is the one
+	// compaction made, of summary and the pins below.
+	compacted bool
+	summary   string
+	prompts   []string
+	failure   *pinnedOutput
+	diff      *pinnedOutput
+}
+
+// pinnedOutput is a command's output kept through compaction.
+type pinnedOutput struct {
+	command string
+	output  string
+}
+
+func newContextManager(window int) *contextManager {
+	return &contextManager{window: window}
+}
+
+// observe records the size the model reported for a conversation of turns
+// turns.
+func (m *contextManager) observe(turns int, usage Usage) {
+	m.tokens, m.turns = usage.InputTokens+usage.OutputTokens, turns
+}
+
+// size returns the tokens req takes: the size last reported, plus an
+// estimate of the turns added since.
+func (m *contextManager) size(req Request) int {
+	if m.turns == 0 || m.turns > len(req.Turns) {
+		return estimateRequestTokens(req)
+	}
+	return m.tokens + estimateRequestTokens(Request{Turns: req.Turns[m.turns:]})
+}
+
+// split returns how many of the leading turns of req to summarize, or 0
+// when the conversation fits. The turns kept start with a model turn, so
+// tool results stay with the calls they answer.
+func (m *contextManager) split(req Request) int {
+	if m.size(req) < int(compactAt*float64(m.window)) {
+		return 0
+	}
+	turns := req.Turns
+	keep := int(keepRecent * float64(m.window))
+	i := len(turns)
+	for i > 0 && estimateTurnTokens(turns[i-1]) <= keep {
+		keep -= estimateTurnTokens(turns[i-1])
+		i--
+	}
+	for i < len(turns) && i > 0 && turns[i].Role == RoleTool {
+		i--
+	}
+	if i < len(turns) && turns[i].Role == RoleUser {
+		i++
+	}
+
+	// Compacting again just to fold in a few turns isn't worth a call.
+	summarized := 0
+	for j, t := range turns[:i] {
+		if t.Role != RoleUser || (j == 0 && m.compacted) {
+			summarized += estimateTurnTokens(t)
+		}
+	}
+	if summarized < int(minSummarized*float64(m.window)) {
+		return 0
+	}
+	return i
+}
+
+// summaryRequest asks model to summarize head, the turns split chose.
+func (m *contextManager) summaryRequest(model string, head []Turn) Request {
+	var b strings.Builder
+	if m.compacted && len(head) > 0 {
+		fmt.Fprintf(&b, "Summary of the session before this:\n%s\n\n", m.summary)
+		head = head[1:]
+	}
+	for _, t := range head {
+		switch t.Role {
+		case RoleUser:
+			fmt.Fprintf(&b, "User:\n%s\n\n", t.Text)
+		case RoleAssistant:
+			fmt.Fprintf(&b, "Assistant:\n%s\n", t.Text)
+			for _, c := range t.ToolCalls {
+				fmt.Fprintf(&b, "[called %s %s]\n", c.Name, c.Input)
+			}
+			b.WriteString("\n")
+		case RoleTool:
+			for _, r := range t.ToolResults {
+				content := r.Content
+				if len(content) > summaryResultChars {
+					content = content[:summaryResultChars] + "\n[... truncated]"
+				}
+				status := "result"
+				if r.IsError {
+					status = "error"
+				}
+				fmt.Fprintf(&b, "[%s %s]:\n%s\n\n", r.Name, status, content)
+			}
+		}
+	}
+	return Request{
+		Model:  model,
+		System: summarizePrompt,
+		Turns:  []Turn{{Role: RoleUser, Text: strings.TrimSpace(b.String())}},
+	}
+}
+
+// compact pins what head holds that the summary would lose and returns the
+// turn that replaces head: summary and pins.
+func (m *contextManager) compact(head []Turn, summary string) Turn {
+	if m.compacted && len(head) > 0 {
+		head = head[1:]
+	}
+	calls := map[string]ToolCall{}
+	for _, t := range head {
+		switch t.Role {
+		case RoleUser:
+			m.prompts = append(m.prompts, t.Text)
+		case RoleAssistant:
+			for _, c := range t.ToolCalls {
+				calls[c.ID] = c
+			}
+		case RoleTool:
+			for _, r := range t.ToolResults {
+				m.pinResult(calls[r.CallID], r)
+			}
+		}
+	}
+	m.compacted = true
+	m.summary = summary
+	m.tokens, m.turns = 0, 0
+	return Turn{Role: RoleUser, Text: m.text()}
+}
+
+// pinResult keeps the output of a failing command until the command
+// passes, and the output of the latest diff.
+func (m *contextManager) pinResult(call ToolCall, r ToolResult) {
+	if call.Name != "run_command" {
+		return
+	}
+	var args struct {
+		Command string `json:"command"`
+	}
+	if json.Unmarshal(call.Input, &args) != nil || args.Command == "" {
+		return
+	}
+	switch {
+	case r.IsError:
+		m.failure = &pinnedOutput{command: args.Command, output: r.Content}
+	case m.failure != nil && m.failure.command == args.Command:
+		m.failure = nil
+	}
+	if !r.IsError && strings.HasPrefix(strings.TrimSpace(args.Command), "git diff") {
+		m.diff = &pinnedOutput{command: args.Command, output: r.Content}
+	}
+}
+
+// text renders the summary and pins as the conversation's first turn.
+func (m *contextManager) text() string {
+	var b strings.Builder
+	b.WriteString("The earlier part of this session was summarized to fit the model's context window.\n\n")
+	fmt.Fprintf(&b, "Summary of the work so far:\n%s", m.summary)
+	if len(m.prompts) > 0 {
+		b.WriteString("\n\nInstructions given earlier in the session, verbatim:")
+		for _, p := range m.prompts {
+			fmt.Fprintf(&b, "\n\n---\n%s", p)
+		}
+	}
+	if m.failure != nil {
+		fmt.Fprintf(&b, "\n\nLatest failing command, `%s`, output:\n%s", m.failure.command, m.failure.output)
+	}
+	if m.diff != nil {
+		fmt.Fprintf(&b, "\n\nLatest `%s` output:\n%s", m.diff.command, m.diff.output)
+	}
+	return b.String()
+}
+
+// nextRequest returns the request for the runner's next model call,
+// first compacting the conversation if it nears the model's context
+// window. The summary's tokens are added to usage.
+func (r *Runner) nextRequest(ctx context.Context, usage *Usage) (Request, error) {
+	r.mu.Lock()
+	req := r.request()
+	split := r.contextMgr.split(req)
+	var summaryReq Request
+	if split > 0 {
+		summaryReq = r.contextMgr.summaryRequest(r.cfg.Model, req.Turns[:split])
+	}
+	r.mu.Unlock()
+	if split == 0 {
+		return req, nil
+	}
+
+	resp, err := r.provider.Complete(ctx, summaryReq)
+	if err != nil {
+		return Request{}, fmt.Errorf("summarizing the conversation: %w", err)
+	}
+	usage.InputTokens += resp.Usage.InputTokens
+	usage.OutputTokens += resp.Usage.OutputTokens
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.turns = append([]Turn{r.contextMgr.compact(req.Turns[:split], resp.Text)}, r.turns[split:]...)
+	return r.request(), nil
+}
+
+// estimateTurnTokens estimates the tokens of one turn.
+func estimateTurnTokens(t Turn) int {
+	return estimateRequestTokens(Request{Turns: []Turn{t}})
+}
@@ -0,0 +1,103 @@
+package agentbackend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/container"
+)
+
+func TestRunner_CompactsNearContextWindow(t *testing.T) {
+	r, p := testRunner(t,
+		Response{Text: strings.Repeat("a", 200), ToolCalls: []ToolCall{call("1", "run_command", `{"command":"go test ./..."}`)}, Usage: Usage{InputTokens: 50, OutputTokens: 50}},
+		Response{Text: strings.Repeat("b", 600), ToolCalls: []ToolCall{call("2", "read_file", `{"path":"missing.go"}`)}, Usage: Usage{InputTokens: 300, OutputTokens: 10}},
+		Response{Text: "ran the tests; they fail in TestLogin", Usage: Usage{InputTokens: 20, OutputTokens: 10}},
+		Response{Text: "done", Usage: Usage{InputTokens: 100}},
+	)
+	r.contextMgr = newContextManager(400)
+	r.SetContainerized(true, "erg:abc")
+	r.execFunc = func(context.Context, container.Exec) (string, int, error) {
+		return "--- FAIL: TestLogin", 1, nil
+	}
+
+	var stats *claude.StreamStats
+	for _, c := range drain(r.Send(context.Background(), "Fix issue #1: login fails")) {
+		if c.Error != nil {
+			t.Fatalf("unexpected error: %v", c.Error)
+		}
+		if c.Type == claude.ChunkTypeStreamStats {
+			stats = c.Stats
+		}
+	}
+
+	if len(p.requests) != 4 {
+		t.Fatalf("expected 4 model calls, got %d", len(p.requests))
+	}
+	summary := p.requests[2]
+	if summary.System != summarizePrompt || len(summary.Tools) != 0 || !strings.Contains(summary.Turns[0].Text, strings.Repeat("a", 200)) {
+		t.Errorf("unexpected summary request: %+v", summary)
+	}
+	turns := p.requests[3].Turns
+	if len(turns) != 3 || turns[1].Text != strings.Repeat("b", 600) || turns[2].Role != RoleTool {
+		t.Fatalf("expected the summary followed by the latest exchange, got %+v", turns)
+	}
+	first := turns[0].Text
+	for _, want := range []string{"ran the tests; they fail in TestLogin", "Fix issue #1: login fails", "`go test ./...`", "--- FAIL: TestLogin"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("compacted turn is missing %q:\n%s", want, first)
+		}
+	}
+	if strings.Contains(first, strings.Repeat("a", 200)) {
+		t.Error("expected the summarized turns dropped")
+	}
+	if stats == nil || stats.InputTokens != 470 || stats.OutputTokens != 70 {
+		t.Errorf("expected the summary's tokens counted, got %+v", stats)
+	}
+}
+
+func TestContextManager_FitsWithoutCompacting(t *testing.T) {
+	m := newContextManager(defaultContextWindows[ProviderOpenAI])
+	req := Request{Turns: []Turn{{Role: RoleUser, Text: "hi"}, {Role: RoleAssistant, Text: strings.Repeat("x", 4000)}}}
+	m.observe(2, Usage{InputTokens: 90_000, OutputTokens: 1000})
+	if got := m.split(req); got != 0 {
+		t.Errorf("split = %d, want nothing summarized under the threshold", got)
+	}
+	m.observe(2, Usage{InputTokens: 97_000})
+	if got := m.split(req); got != 0 {
+		t.Errorf("split = %d, want nothing summarized when too little would go", got)
+	}
+}
+
+func TestContextManager_PinsUntilCommandPasses(t *testing.T) {
+	m := newContextManager(1000)
+	m.compact([]Turn{
+		{Role: RoleAssistant, ToolCalls: []ToolCall{call("1", "run_command", `{"command":"make test"}`), call("2", "run_command", `{"command":"git diff"}`)}},
+		{Role: RoleTool, ToolResults: []ToolResult{{CallID: "1", Content: "FAIL", IsError: true}, {CallID: "2", Content: "+fix"}}},
+	}, "s1")
+	if m.failure == nil || m.diff == nil || m.diff.output != "+fix" {
+		t.Fatalf("expected the failure and diff pinned, got %+v %+v", m.failure, m.diff)
+	}
+
+	text := m.compact([]Turn{
+		{Role: RoleUser, Text: "(compacted)"},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{call("3", "run_command", `{"command":"make test"}`)}},
+		{Role: RoleTool, ToolResults: []ToolResult{{CallID: "3", Content: "ok"}}},
+	}, "s2").Text
+	if m.failure != nil || strings.Contains(text, "FAIL") {
+		t.Errorf("expected the failure unpinned once the command passed:\n%s", text)
+	}
+	if !strings.Contains(text, "s2") || strings.Contains(text, "s1") || !strings.Contains(text, "+fix") {
+		t.Errorf("unexpected compacted turn:\n%s", text)
+	}
+}
+
+func TestConfig_ContextWindow(t *testing.T) {
+	if got := (Config{Provider: ProviderLocal}).contextWindow(); got != 32_768 {
+		t.Errorf("local default = %d", got)
+	}
+	if got := (Config{Provider: ProviderGemini, ContextWindow: 50_000}).contextWindow(); got != 50_000 {
+		t.Errorf("configured window = %d", got)
+	}
+}
@@ -0,0 +1,163 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultGeminiURL is the Gemini API.
+const defaultGeminiURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// geminiLocalIDPrefix marks the IDs given to function calls that came
+// without one, which are not sent back.
+const geminiLocalIDPrefix = "erg-"
+
+// geminiProvider speaks the Gemini generateContent API.
+type geminiProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+func (p *geminiProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	var contents []json.RawMessage
+	add := func(c geminiContent) {
+		data, _ := json.Marshal(c)
+		contents = append(contents, data)
+	}
+	for _, t := range req.Turns {
+		switch t.Role {
+		case RoleTool:
+			c := geminiContent{Role: "user"}
+			for _, r := range t.ToolResults {
+				key := "output"
+				if r.IsError {
+					key = "error"
+				}
+				id := r.CallID
+				if strings.HasPrefix(id, geminiLocalIDPrefix) {
+					id = ""
+				}
+				c.Parts = append(c.Parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+					ID: id, Name: r.Name, Response: map[string]any{key: r.Content},
+				}})
+			}
+			add(c)
+		case RoleAssistant:
+			// The model's own content, sent back as it came, keeps the
+			// thought signatures Gemini requires with function calls.
+			if t.Raw != nil {
+				contents = append(contents, t.Raw)
+				continue
+			}
+			c := geminiContent{Role: "model"}
+			if t.Text != "" {
+				c.Parts = append(c.Parts, geminiPart{Text: t.Text})
+			}
+			for _, call := range t.ToolCalls {
+				c.Parts = append(c.Parts, geminiPart{FunctionCall: &geminiFunctionCall{ID: call.ID, Name: call.Name, Args: call.Input}})
+			}
+			add(c)
+		default:
+			add(geminiContent{Role: "user", Parts: []geminiPart{{Text: t.Text}}})
+		}
+	}
+
+	body := map[string]any{"contents": contents}
+	if req.System != "" {
+		body["systemInstruction"] = geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+	if len(req.Tools) > 0 {
+		var decls []map[string]any
+		for _, d := range req.Tools {
+			decls = append(decls, map[string]any{"name": d.Name, "description": d.Description, "parametersJsonSchema": d.InputSchema})
+		}
+		body["tools"] = []map[string]any{{"functionDeclarations": decls}}
+	}
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("x-goog-api-key", p.apiKey)
+	}
+	base := p.baseURL
+	if base == "" {
+		base = defaultGeminiURL
+	}
+	endpoint := strings.TrimSuffix(base, "/") + "/models/" + url.PathEscape(req.Model) + ":generateContent"
+
+	var out struct {
+		Candidates []struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := postJSON(ctx, p.client, endpoint, header, body, &out); err != nil {
+		return Response{}, fmt.Errorf("gemini: %w", err)
+	}
+	if len(out.Candidates) == 0 || out.Candidates[0].Content == nil {
+		return Response{}, fmt.Errorf("gemini: response has no candidates")
+	}
+	var content geminiContent
+	if err := json.Unmarshal(out.Candidates[0].Content, &content); err != nil {
+		return Response{}, fmt.Errorf("gemini: %w", err)
+	}
+
+	resp := Response{
+		Raw: out.Candidates[0].Content,
+		Usage: Usage{
+			InputTokens:  out.UsageMetadata.PromptTokenCount,
+			OutputTokens: out.UsageMetadata.CandidatesTokenCount + out.UsageMetadata.ThoughtsTokenCount,
+		},
+	}
+	var text []string
+	for i, part := range content.Parts {
+		if part.Text != "" {
+			text = append(text, part.Text)
+		}
+		if fc := part.FunctionCall; fc != nil {
+			id := fc.ID
+			if id == "" {
+				// Older models don't number their calls; results are
+				// matched to them by name and order.
+				id = fmt.Sprintf("%s%s-%d", geminiLocalIDPrefix, fc.Name, i)
+			}
+			args := fc.Args
+			if len(args) == 0 {
+				args = json.RawMessage("{}")
+			}
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: id, Name: fc.Name, Input: args})
+		}
+	}
+	resp.Text = strings.Join(text, "")
+	return resp, nil
+}
@@ -0,0 +1,182 @@
+package agentbackend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultLocalURL is Ollama's OpenAI-compatible API on this machine, where
+// local backends send requests by default.
+const DefaultLocalURL = "http://localhost:11434/v1"
+
+// maxStreamEvent caps the size of one server-sent event of a streamed
+// reply.
+const maxStreamEvent = 16 << 20
+
+// localProvider speaks the OpenAI chat completions API to a local inference
+// server, streaming replies. Local servers often leave out token counts, so
+// it estimates those it isn't sent.
+type localProvider struct {
+	*openAIProvider
+}
+
+func (p *localProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	return p.CompleteStream(ctx, req, nil)
+}
+
+// openAIStreamChunk is one event of a streamed chat completion.
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *localProvider) CompleteStream(ctx context.Context, req Request, onText func(string)) (Response, error) {
+	url, header := p.endpoint()
+	body := openAIBody(req)
+	body["stream"] = true
+	body["stream_options"] = map[string]any{"include_usage": true}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header = header
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("local: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxErrorBody))
+		return Response{}, fmt.Errorf("local: %s: %s", httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var text strings.Builder
+	calls := map[int]*openAIToolCall{}
+	var usage Usage
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamEvent)
+	for scanner.Scan() {
+		event, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		event = strings.TrimSpace(event)
+		if event == "[DONE]" {
+			break
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(event), &chunk); err != nil {
+			return Response{}, fmt.Errorf("local: decoding stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return Response{}, fmt.Errorf("local: %s", chunk.Error.Message)
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" {
+				text.WriteString(c.Delta.Content)
+				if onText != nil {
+					onText(c.Delta.Content)
+				}
+			}
+			for _, d := range c.Delta.ToolCalls {
+				tc := calls[d.Index]
+				if tc == nil {
+					tc = &openAIToolCall{Type: "function"}
+					calls[d.Index] = tc
+				}
+				if d.ID != "" {
+					tc.ID = d.ID
+				}
+				if d.Function.Name != "" {
+					tc.Function.Name = d.Function.Name
+				}
+				tc.Function.Arguments += d.Function.Arguments
+			}
+		}
+		if chunk.Usage != nil {
+			usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Response{}, fmt.Errorf("local: reading stream: %w", err)
+	}
+
+	var toolCalls []openAIToolCall
+	for _, i := range slices.Sorted(maps.Keys(calls)) {
+		tc := *calls[i]
+		if tc.ID == "" {
+			tc.ID = fmt.Sprintf("erg-%d", i)
+		}
+		toolCalls = append(toolCalls, tc)
+	}
+	resp := Response{Text: text.String(), ToolCalls: openAIToolCalls(toolCalls), Usage: usage}
+	if resp.Usage.InputTokens == 0 {
+		resp.Usage.InputTokens = estimateRequestTokens(req)
+	}
+	if resp.Usage.OutputTokens == 0 {
+		resp.Usage.OutputTokens = estimateTokens(resp.Text)
+		for _, c := range resp.ToolCalls {
+			resp.Usage.OutputTokens += estimateTokens(c.Name) + estimateTokens(string(c.Input))
+		}
+	}
+	return resp, nil
+}
+
+// estimateTokens estimates the tokens of text at four characters a token,
+// close enough for the spend and context budgets of models whose servers
+// don't count.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// estimateRequestTokens estimates the prompt tokens of req.
+func estimateRequestTokens(req Request) int {
+	n := estimateTokens(req.System)
+	for _, t := range req.Turns {
+		n += estimateTokens(t.Text)
+		for _, c := range t.ToolCalls {
+			n += estimateTokens(c.Name) + estimateTokens(string(c.Input))
+		}
+		for _, r := range t.ToolResults {
+			n += estimateTokens(r.Content)
+		}
+	}
+	for _, d := range req.Tools {
+		schema, _ := json.Marshal(d.InputSchema)
+		n += estimateTokens(d.Name) + estimateTokens(d.Description) + estimateTokens(string(schema))
+	}
+	return n
+}
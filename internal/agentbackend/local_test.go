@@ -0,0 +1,120 @@
+package agentbackend
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+)
+
+// streamReply is a streamed chat completion whose tool call arrives in
+// pieces, without token counts.
+const streamReply = `data: {"choices":[{"delta":{"role":"assistant","content":"Let me "}}]}
+
+data: {"choices":[{"delta":{"content":"look."}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"read_file","arguments":"{\"pa"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"th\":\"a.go\"}"}}]}}]}
+
+data: [DONE]
+
+`
+
+func TestLocalProvider_Stream(t *testing.T) {
+	srv, got, body := providerServer(t, streamReply)
+	p, _ := NewProvider(Config{Provider: ProviderLocal, BaseURL: srv.URL + "/v1"}, nil)
+
+	var pieces []string
+	resp, err := p.(StreamingProvider).CompleteStream(context.Background(), providerRequest, func(s string) {
+		pieces = append(pieces, s)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/v1/chat/completions" || got.Header.Get("Authorization") != "" {
+		t.Errorf("unexpected request: %s %v", got.URL.Path, got.Header)
+	}
+	if (*body)["stream"] != true {
+		t.Errorf("expected a streamed request: %v", *body)
+	}
+	if !slices.Equal(pieces, []string{"Let me ", "look."}) || resp.Text != "Let me look." {
+		t.Errorf("unexpected text %q from %q", resp.Text, pieces)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "call_1" || string(resp.ToolCalls[0].Input) != `{"path":"a.go"}` {
+		t.Errorf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+	if resp.Usage.InputTokens != estimateRequestTokens(providerRequest) || resp.Usage.OutputTokens == 0 {
+		t.Errorf("expected token counts estimated, got %+v", resp.Usage)
+	}
+}
+
+func TestLocalProvider_ReportedUsage(t *testing.T) {
+	srv, _, _ := providerServer(t, `data: {"choices":[{"delta":{"content":"ok"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":1234,"completion_tokens":5}}
+
+data: [DONE]
+`)
+	p, _ := NewProvider(Config{Provider: ProviderLocal, BaseURL: srv.URL, APIKey: "vllm-key"}, nil)
+	resp, err := p.Complete(context.Background(), Request{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "ok" || resp.Usage != (Usage{InputTokens: 1234, OutputTokens: 5}) {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestLocalProvider_StreamError(t *testing.T) {
+	srv, _, _ := providerServer(t, `data: {"error":{"message":"model \"qwen\" not found, try pulling it first"}}
+`)
+	p, _ := NewProvider(Config{Provider: ProviderLocal, BaseURL: srv.URL}, nil)
+	if _, err := p.Complete(context.Background(), Request{Model: "qwen"}); err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+}
+
+func TestNewProvider_LocalDefaultsToOllama(t *testing.T) {
+	p, err := NewProvider(Config{Provider: ProviderLocal, Model: "m"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url, _ := p.(*localProvider).endpoint(); url != DefaultLocalURL+"/chat/completions" {
+		t.Errorf("unexpected endpoint %s", url)
+	}
+}
+
+func TestRunner_StreamsText(t *testing.T) {
+	srv, _, _ := providerServer(t, `data: {"choices":[{"delta":{"content":"one "}}]}
+
+data: {"choices":[{"delta":{"content":"two"}}]}
+
+data: [DONE]
+`)
+	r, err := NewRunner(Config{Provider: ProviderLocal, Model: "m", BaseURL: srv.URL}, "s", t.TempDir(), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text []string
+	var stats *claude.StreamStats
+	for c := range r.Send(context.Background(), "hi") {
+		switch c.Type {
+		case claude.ChunkTypeText:
+			text = append(text, c.Content)
+		case claude.ChunkTypeStreamStats:
+			stats = c.Stats
+		}
+	}
+	if !slices.Equal(text, []string{"one ", "two"}) {
+		t.Errorf("expected the text streamed as it came, got %q", text)
+	}
+	if stats == nil || stats.InputTokens == 0 || stats.TotalCostUSD != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if msgs := r.GetMessages(); len(msgs) != 2 || msgs[1].Content != "one two" {
+		t.Errorf("unexpected messages: %+v", msgs)
+	}
+}
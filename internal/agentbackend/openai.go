@@ -0,0 +1,181 @@
+package agentbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of a failed response's body an error quotes.
+const maxErrorBody = 512
+
+// postJSON sends body as JSON to url with header and decodes a successful
+// response into out. A non-2xx response is an error quoting the body.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(client, req, out)
+}
+
+// doJSON sends req and decodes a successful response into out.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// defaultOpenAIURL is the OpenAI API.
+const defaultOpenAIURL = "https://api.openai.com/v1"
+
+// openAIProvider speaks the OpenAI chat completions API.
+type openAIProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Parameters  any    `json:"parameters"`
+	} `json:"function"`
+}
+
+// openAIBody encodes req as a chat completions request body.
+func openAIBody(req Request) map[string]any {
+	var messages []openAIMessage
+	if req.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: &req.System})
+	}
+	for _, t := range req.Turns {
+		switch t.Role {
+		case RoleTool:
+			for _, r := range t.ToolResults {
+				messages = append(messages, openAIMessage{Role: "tool", Content: &r.Content, ToolCallID: r.CallID})
+			}
+		case RoleAssistant:
+			m := openAIMessage{Role: "assistant"}
+			if t.Text != "" {
+				m.Content = &t.Text
+			}
+			for _, c := range t.ToolCalls {
+				var tc openAIToolCall
+				tc.ID, tc.Type = c.ID, "function"
+				tc.Function.Name, tc.Function.Arguments = c.Name, string(c.Input)
+				m.ToolCalls = append(m.ToolCalls, tc)
+			}
+			messages = append(messages, m)
+		default:
+			messages = append(messages, openAIMessage{Role: "user", Content: &t.Text})
+		}
+	}
+	var tools []openAITool
+	for _, d := range req.Tools {
+		var tool openAITool
+		tool.Type = "function"
+		tool.Function.Name, tool.Function.Description, tool.Function.Parameters = d.Name, d.Description, d.InputSchema
+		tools = append(tools, tool)
+	}
+
+	body := map[string]any{"model": req.Model, "messages": messages}
+	if len(tools) > 0 {
+		body["tools"] = tools
+	}
+	return body
+}
+
+// endpoint returns the chat completions URL and the headers to send.
+func (p *openAIProvider) endpoint() (string, http.Header) {
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	base := p.baseURL
+	if base == "" {
+		base = defaultOpenAIURL
+	}
+	return strings.TrimSuffix(base, "/") + "/chat/completions", header
+}
+
+// openAIToolCalls decodes the tool calls of a chat completions message.
+func openAIToolCalls(calls []openAIToolCall) []ToolCall {
+	var out []ToolCall
+	for _, tc := range calls {
+		args := tc.Function.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		out = append(out, ToolCall{ID: tc.ID, Name: tc.Function.Name, Input: json.RawMessage(args)})
+	}
+	return out
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	url, header := p.endpoint()
+
+	var out struct {
+		Choices []struct {
+			Message struct {
+				Content   string           `json:"content"`
+				ToolCalls []openAIToolCall `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := postJSON(ctx, p.client, url, header, openAIBody(req), &out); err != nil {
+		return Response{}, fmt.Errorf("openai: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return Response{}, fmt.Errorf("openai: response has no choices")
+	}
+	msg := out.Choices[0].Message
+	resp := Response{
+		Text:      msg.Content,
+		ToolCalls: openAIToolCalls(msg.ToolCalls),
+		Usage:     Usage{InputTokens: out.Usage.PromptTokens, OutputTokens: out.Usage.CompletionTokens},
+	}
+	return resp, nil
+}
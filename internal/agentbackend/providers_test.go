@@ -0,0 +1,193 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/mcp"
+)
+
+// providerServer serves reply to every request, recording the last
+// request and its body.
+func providerServer(t *testing.T, reply string) (*httptest.Server, *http.Request, *map[string]any) {
+	t.Helper()
+	var got http.Request
+	body := map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got, &body
+}
+
+// providerRequest is a conversation mid tool call.
+var providerRequest = Request{
+	Model:  "m:1",
+	System: "be brief",
+	Turns: []Turn{
+		{Role: RoleUser, Text: "fix it"},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "c1", Name: "read_file", Input: json.RawMessage(`{"path":"a.go"}`)}}},
+		{Role: RoleTool, ToolResults: []ToolResult{{CallID: "c1", Name: "read_file", Content: "package a"}}},
+	},
+	Tools: []mcp.ToolDefinition{workspaceTools[0].def},
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	srv, got, body := providerServer(t, `{
+		"choices": [{"message": {"content": "reading", "tool_calls": [{"id": "c2", "type": "function", "function": {"name": "run_command", "arguments": "{\"command\":\"go test\"}"}}]}}],
+		"usage": {"prompt_tokens": 100, "completion_tokens": 20}
+	}`)
+	p, _ := NewProvider(Config{Provider: ProviderOpenAI, BaseURL: srv.URL + "/v1/", APIKey: "sk-test"}, nil)
+
+	resp, err := p.Complete(context.Background(), providerRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/v1/chat/completions" || got.Header.Get("Authorization") != "Bearer sk-test" {
+		t.Errorf("unexpected request: %s %v", got.URL.Path, got.Header)
+	}
+	messages := (*body)["messages"].([]any)
+	if len(messages) != 4 || messages[0].(map[string]any)["role"] != "system" || messages[3].(map[string]any)["tool_call_id"] != "c1" {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+	if tools := (*body)["tools"].([]any); len(tools) != 1 {
+		t.Errorf("unexpected tools: %v", tools)
+	}
+	if resp.Text != "reading" || len(resp.ToolCalls) != 1 || string(resp.ToolCalls[0].Input) != `{"command":"go test"}` || resp.Usage.InputTokens != 100 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestOpenAIProvider_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "model not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+	p, _ := NewProvider(Config{Provider: ProviderOpenAI, BaseURL: srv.URL}, nil)
+	_, err := p.Complete(context.Background(), Request{Model: "m"})
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("expected the API's error, got %v", err)
+	}
+}
+
+func TestGeminiProvider(t *testing.T) {
+	srv, got, body := providerServer(t, `{
+		"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}, {"functionCall": {"name": "list_files", "args": {}}, "thoughtSignature": "sig"}]}}],
+		"usageMetadata": {"promptTokenCount": 50, "candidatesTokenCount": 5, "thoughtsTokenCount": 10}
+	}`)
+	p, _ := NewProvider(Config{Provider: ProviderGemini, BaseURL: srv.URL, APIKey: "g-key"}, nil)
+
+	resp, err := p.Complete(context.Background(), providerRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/models/m:1:generateContent" || got.Header.Get("x-goog-api-key") != "g-key" {
+		t.Errorf("unexpected request: %s %v", got.URL.Path, got.Header)
+	}
+	contents := (*body)["contents"].([]any)
+	if len(contents) != 3 || contents[1].(map[string]any)["role"] != "model" {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+	if (*body)["systemInstruction"] == nil || (*body)["tools"] == nil {
+		t.Errorf("expected the system prompt and tools sent: %v", *body)
+	}
+	if resp.Text != "ok" || len(resp.ToolCalls) != 1 || resp.Usage.OutputTokens != 15 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if !strings.Contains(string(resp.Raw), "thoughtSignature") {
+		t.Errorf("expected the model's content kept to send back, got %s", resp.Raw)
+	}
+
+	// The synthesized call ID is not sent back with the result.
+	req := Request{Model: "m", Turns: []Turn{
+		{Role: RoleAssistant, Raw: resp.Raw},
+		{Role: RoleTool, ToolResults: []ToolResult{{CallID: resp.ToolCalls[0].ID, Name: "list_files", Content: "a.go"}}},
+	}}
+	if _, err := p.Complete(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	contents = (*body)["contents"].([]any)
+	if !strings.Contains(toJSON(contents[0]), "sig") {
+		t.Errorf("expected the raw content sent back: %v", contents[0])
+	}
+	if s := toJSON(contents[1]); strings.Contains(s, `"id"`) || !strings.Contains(s, `"output":"a.go"`) {
+		t.Errorf("unexpected function response: %s", s)
+	}
+}
+
+func TestBedrockProvider(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	srv, got, body := providerServer(t, `{
+		"output": {"message": {"role": "assistant", "content": [{"toolUse": {"toolUseId": "t1", "name": "run_command", "input": {"command": "make"}}}]}},
+		"usage": {"inputTokens": 30, "outputTokens": 3}
+	}`)
+	p, _ := NewProvider(Config{Provider: ProviderBedrock, BaseURL: srv.URL, Region: "us-east-1"}, nil)
+	p.(*bedrockProvider).now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	resp, err := p.Complete(context.Background(), providerRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.EscapedPath() != "/model/m%3A1/converse" {
+		t.Errorf("unexpected path %s", got.URL.EscapedPath())
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/us-east-1/bedrock/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("unexpected Authorization %q", auth)
+	}
+	if got.Header.Get("X-Amz-Date") != "20260102T030405Z" {
+		t.Errorf("unexpected X-Amz-Date %q", got.Header.Get("X-Amz-Date"))
+	}
+	if s := toJSON((*body)["messages"]); !strings.Contains(s, `"toolUseId":"c1"`) || !strings.Contains(s, `"toolResult"`) {
+		t.Errorf("unexpected messages: %s", s)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "t1" || resp.Usage.InputTokens != 30 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// A Bedrock API key is sent as a bearer token instead.
+	p, _ = NewProvider(Config{Provider: ProviderBedrock, BaseURL: srv.URL, Region: "us-east-1", APIKey: "br-key"}, nil)
+	if _, err := p.Complete(context.Background(), providerRequest); err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Get("Authorization") != "Bearer br-key" {
+		t.Errorf("unexpected Authorization %q", got.Header.Get("Authorization"))
+	}
+}
+
+func TestSignAWSRequest_Deterministic(t *testing.T) {
+	sign := func(secret string) string {
+		req, _ := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-west-2.amazonaws.com/model/a%3A0/converse", nil)
+		signAWSRequest(req, []byte("{}"), awsCredentials{AccessKeyID: "AK", SecretAccessKey: secret, SessionToken: "tok"}, "us-west-2", "bedrock", time.Unix(0, 0))
+		return req.Header.Get("Authorization")
+	}
+	a := sign("s1")
+	if a != sign("s1") || a == sign("s2") {
+		t.Error("expected the signature to depend only on the request and secret")
+	}
+	if !strings.Contains(a, "x-amz-security-token") {
+		t.Errorf("expected the session token signed: %s", a)
+	}
+}
+
+func TestNewProvider_Unknown(t *testing.T) {
+	if _, err := NewProvider(Config{Provider: "anthropic"}, nil); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func toJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
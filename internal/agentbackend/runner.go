@@ -0,0 +1,542 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/logger"
+	"github.com/zhubert/erg/internal/mcp"
+)
+
+// maxModelCalls caps the model calls one Send makes before giving up on
+// the model finishing.
+const maxModelCalls = 200
+
+// baseSystemPrompt tells the model how it works, ahead of the session's
+// own system prompt.
+const baseSystemPrompt = `You are an autonomous software engineering agent working in a git repository. Use the tools to read, list and write files and to run commands such as builds and tests at the repository root. Work until the task is done, then reply with a short summary of what you did.`
+
+// Runner is a Backend that runs the agent loop itself against a Provider.
+// It implements claude.RunnerInterface, so the daemon configures it like
+// a Claude runner; settings with no counterpart outside the Claude CLI,
+// such as MCP servers and session forking, are ignored.
+type Runner struct {
+	mu sync.Mutex
+
+	sessionID string
+	workDir   string
+	repoPath  string
+	cfg       Config
+	provider  Provider
+	log       *slog.Logger
+
+	system          string
+	allowedTools    []string
+	disallowedTools []string
+	hostTools       bool
+	containerized   bool
+	image           string
+	env             []string
+	network         string
+
+	turns      []Turn
+	contextMgr *contextManager
+	messages   []claude.Message
+	streaming  bool
+	cancel     context.CancelFunc
+	stopped    bool
+
+	createPR          *mcp.ChannelPair[mcp.CreatePRRequest, mcp.CreatePRResponse]
+	pushBranch        *mcp.ChannelPair[mcp.PushBranchRequest, mcp.PushBranchResponse]
+	getReviewComments *mcp.ChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse]
+	commentIssue      *mcp.ChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse]
+	submitReview      *mcp.ChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse]
+	submitResult      *mcp.ChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse]
+
+	// execFunc runs containerized commands; tests replace it.
+	execFunc func(ctx context.Context, e container.Exec) (string, int, error)
+}
+
+// NewRunner returns a Runner for a session working in workDir, a worktree
+// of repoPath, on the model cfg names. initialMessages, the session's
+// conversation so far, are carried over as context.
+func NewRunner(cfg Config, sessionID, workDir, repoPath string, initialMessages []claude.Message) (*Runner, error) {
+	provider, err := NewProvider(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &Runner{
+		sessionID:         sessionID,
+		workDir:           workDir,
+		repoPath:          repoPath,
+		cfg:               cfg,
+		provider:          provider,
+		log:               logger.WithSession(sessionID),
+		contextMgr:        newContextManager(cfg.contextWindow()),
+		messages:          slices.Clone(initialMessages),
+		createPR:          mcp.NewChannelPair[mcp.CreatePRRequest, mcp.CreatePRResponse](1),
+		pushBranch:        mcp.NewChannelPair[mcp.PushBranchRequest, mcp.PushBranchResponse](1),
+		getReviewComments: mcp.NewChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse](1),
+		commentIssue:      mcp.NewChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse](1),
+		submitReview:      mcp.NewChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse](1),
+		submitResult:      mcp.NewChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse](1),
+		execFunc:          container.RunExec,
+	}
+	for _, m := range initialMessages {
+		role := RoleUser
+		if m.Role == "assistant" {
+			role = RoleAssistant
+		}
+		r.turns = append(r.turns, Turn{Role: role, Text: m.Content})
+	}
+	return r, nil
+}
+
+// Provider returns the name of the provider the runner's model is on.
+func (r *Runner) Provider() string { return r.cfg.Provider }
+
+// RunnerConfig
+
+func (r *Runner) SetAllowedTools(tools []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowedTools = slices.Clone(tools)
+}
+
+func (r *Runner) AddAllowedTool(tool string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.allowedTools, tool) {
+		r.allowedTools = append(r.allowedTools, tool)
+	}
+}
+
+func (r *Runner) SetDisallowedTools(tools []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disallowedTools = slices.Clone(tools)
+}
+
+// SetMCPServers is ignored: MCP servers are a Claude CLI feature.
+func (r *Runner) SetMCPServers([]claude.MCPServer) {}
+
+// SetForkFromSession is ignored: the conversation is carried over by
+// NewRunner instead.
+func (r *Runner) SetForkFromSession(string) {}
+
+func (r *Runner) SetContainerized(containerized bool, image string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containerized, r.image = containerized, image
+}
+
+// SetOnContainerReady calls callback right away: commands start their own
+// containers, so there is none to wait for.
+func (r *Runner) SetOnContainerReady(callback func()) {
+	if callback != nil {
+		callback()
+	}
+}
+
+func (r *Runner) SetSystemPrompt(prompt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.system = prompt
+}
+
+func (r *Runner) SetHostTools(hostTools bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hostTools = hostTools
+}
+
+// SetModel is ignored: the backend's configured model always applies, as
+// Claude model names mean nothing to other providers.
+func (r *Runner) SetModel(string) {}
+
+// GetModel returns the backend's model.
+func (r *Runner) GetModel() string { return r.cfg.Model }
+
+func (r *Runner) SetContainerEnv(env []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.env = slices.Clone(env)
+}
+
+func (r *Runner) SetContainerNetwork(network string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.network = network
+}
+
+// SetContainerResources is ignored: command containers are short-lived.
+func (r *Runner) SetContainerResources(claude.ContainerResources) {}
+
+// RunnerSession
+
+func (r *Runner) SessionStarted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.turns) > 0
+}
+
+func (r *Runner) IsStreaming() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.streaming
+}
+
+func (r *Runner) GetMessages() []claude.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.messages)
+}
+
+func (r *Runner) Send(ctx context.Context, prompt string) <-chan claude.ResponseChunk {
+	return r.SendContent(ctx, []claude.ContentBlock{{Type: claude.ContentTypeText, Text: prompt}})
+}
+
+// SendContent runs the agent loop on content until the model replies
+// without calling a tool. Images are not sent; the model is told of them.
+func (r *Runner) SendContent(ctx context.Context, content []claude.ContentBlock) <-chan claude.ResponseChunk {
+	var parts []string
+	for _, b := range content {
+		switch b.Type {
+		case claude.ContentTypeText:
+			parts = append(parts, b.Text)
+		case claude.ContentTypeImage:
+			parts = append(parts, "[an image was attached but this model backend cannot see images]")
+		}
+	}
+	prompt := strings.Join(parts, "\n\n")
+
+	ch := make(chan claude.ResponseChunk, 64)
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.cancel = cancel
+	r.streaming = true
+	r.turns = append(r.turns, Turn{Role: RoleUser, Text: prompt})
+	r.messages = append(r.messages, claude.Message{Role: "user", Content: prompt})
+	r.mu.Unlock()
+
+	go func() {
+		defer close(ch)
+		defer cancel()
+		reply, err := r.run(ctx, ch)
+		r.mu.Lock()
+		r.streaming = false
+		if reply != "" {
+			r.messages = append(r.messages, claude.Message{Role: "assistant", Content: reply})
+		}
+		r.mu.Unlock()
+		if err != nil {
+			emit(ctx, ch, claude.ResponseChunk{Error: err, Done: true})
+			return
+		}
+		emit(ctx, ch, claude.ResponseChunk{Done: true})
+	}()
+	return ch
+}
+
+// run calls the model, and the tools it asks for, until it stops calling
+// tools, streaming its text and tool calls to ch and ending with the
+// spend. It returns the model's text.
+func (r *Runner) run(ctx context.Context, ch chan<- claude.ResponseChunk) (string, error) {
+	start := time.Now()
+	var usage Usage
+	defer func() {
+		emit(ctx, ch, claude.ResponseChunk{Type: claude.ChunkTypeStreamStats, Stats: &claude.StreamStats{
+			InputTokens:  usage.InputTokens,
+			OutputTokens: usage.OutputTokens,
+			TotalCostUSD: (float64(usage.InputTokens)*r.cfg.InputPrice + float64(usage.OutputTokens)*r.cfg.OutputPrice) / 1e6,
+			DurationMs:   max(1, int(time.Since(start).Milliseconds())),
+		}})
+	}()
+
+	var reply []string
+	for range maxModelCalls {
+		req, err := r.nextRequest(ctx, &usage)
+		if err != nil {
+			return strings.Join(reply, "\n\n"), err
+		}
+
+		var resp Response
+		sp, streaming := r.provider.(StreamingProvider)
+		if streaming {
+			resp, err = sp.CompleteStream(ctx, req, func(text string) {
+				emit(ctx, ch, claude.ResponseChunk{Type: claude.ChunkTypeText, Content: text})
+			})
+		} else {
+			resp, err = r.provider.Complete(ctx, req)
+		}
+		if err != nil {
+			return strings.Join(reply, "\n\n"), err
+		}
+		usage.InputTokens += resp.Usage.InputTokens
+		usage.OutputTokens += resp.Usage.OutputTokens
+		if resp.Text != "" {
+			reply = append(reply, resp.Text)
+			if !streaming {
+				emit(ctx, ch, claude.ResponseChunk{Type: claude.ChunkTypeText, Content: resp.Text})
+			}
+		}
+		r.mu.Lock()
+		r.turns = append(r.turns, Turn{Role: RoleAssistant, Text: resp.Text, ToolCalls: resp.ToolCalls, Raw: resp.Raw})
+		r.contextMgr.observe(len(r.turns), resp.Usage)
+		r.mu.Unlock()
+		if len(resp.ToolCalls) == 0 {
+			return strings.Join(reply, "\n\n"), nil
+		}
+
+		results := Turn{Role: RoleTool}
+		for _, call := range resp.ToolCalls {
+			emit(ctx, ch, claude.ResponseChunk{Type: claude.ChunkTypeToolUse, ToolName: call.Name, ToolInput: toolInputSummary(call.Input), ToolUseID: call.ID})
+			if err := r.commandRefusal(call); err != nil {
+				r.log.Warn("refused command", "tool", call.Name, "reason", err)
+				emit(ctx, ch, claude.ResponseChunk{Type: claude.ChunkTypeText, Content: fmt.Sprintf("\n[Permission denied: %s]\n", err)})
+				results.ToolResults = append(results.ToolResults, ToolResult{CallID: call.ID, Name: call.Name, Content: err.Error(), IsError: true})
+				continue
+			}
+			content, isError := r.callTool(ctx, call)
+			if ctx.Err() != nil {
+				return strings.Join(reply, "\n\n"), ctx.Err()
+			}
+			results.ToolResults = append(results.ToolResults, ToolResult{CallID: call.ID, Name: call.Name, Content: content, IsError: isError})
+		}
+		r.mu.Lock()
+		r.turns = append(r.turns, results)
+		r.mu.Unlock()
+	}
+	return strings.Join(reply, "\n\n"), fmt.Errorf("model made %d calls without finishing", maxModelCalls)
+}
+
+// request returns the request for a model call on the conversation so
+// far. r.mu must be held.
+func (r *Runner) request() Request {
+	req := Request{
+		Model:  r.cfg.Model,
+		System: baseSystemPrompt,
+		Turns:  slices.Clone(r.turns),
+		Tools:  offeredTools(r.allowedTools, r.disallowedTools),
+	}
+	if r.system != "" {
+		req.System += "\n\n" + r.system
+	}
+	if r.hostTools {
+		req.Tools = append(req.Tools, mcp.HostToolDefinitions()...)
+	}
+	return req
+}
+
+// emit sends c on ch unless ctx is done first.
+func emit(ctx context.Context, ch chan<- claude.ResponseChunk, c claude.ResponseChunk) {
+	select {
+	case ch <- c:
+	case <-ctx.Done():
+	}
+}
+
+// callTool carries out a tool call and returns its result for the model.
+func (r *Runner) callTool(ctx context.Context, call ToolCall) (string, bool) {
+	r.mu.Lock()
+	offered := slices.ContainsFunc(offeredTools(r.allowedTools, r.disallowedTools), func(d mcp.ToolDefinition) bool { return d.Name == call.Name })
+	hostTools := r.hostTools
+	r.mu.Unlock()
+
+	if offered {
+		if result, isError, ok := r.runWorkspaceTool(ctx, call.Name, call.Input); ok {
+			return result, isError
+		}
+	}
+	if hostTools {
+		if result, isError, ok := r.runHostTool(ctx, call); ok {
+			return result, isError
+		}
+	}
+	return fmt.Sprintf("unknown tool %q", call.Name), true
+}
+
+// runHostTool raises a host tool call as a request for the worker and
+// returns its response. ok is false when call is not a host tool.
+func (r *Runner) runHostTool(ctx context.Context, call ToolCall) (result string, isError, ok bool) {
+	var resp any
+	var success bool
+	var err error
+	switch call.Name {
+	case "create_pr":
+		var req mcp.CreatePRRequest
+		if err = json.Unmarshal(call.Input, &req); err == nil {
+			req.ID = call.ID
+			var res mcp.CreatePRResponse
+			res, err = hostCall(ctx, r.createPR, req)
+			resp, success = res, res.Success
+		}
+	case "push_branch":
+		var req mcp.PushBranchRequest
+		if err = json.Unmarshal(call.Input, &req); err == nil {
+			req.ID = call.ID
+			var res mcp.PushBranchResponse
+			res, err = hostCall(ctx, r.pushBranch, req)
+			resp, success = res, res.Success
+		}
+	case "get_review_comments":
+		var res mcp.GetReviewCommentsResponse
+		res, err = hostCall(ctx, r.getReviewComments, mcp.GetReviewCommentsRequest{ID: call.ID})
+		resp, success = res, res.Success
+	case "comment_issue":
+		var req mcp.CommentIssueRequest
+		if err = json.Unmarshal(call.Input, &req); err == nil {
+			req.ID = call.ID
+			var res mcp.CommentIssueResponse
+			res, err = hostCall(ctx, r.commentIssue, req)
+			resp, success = res, res.Success
+		}
+	case "submit_review":
+		var req mcp.SubmitReviewRequest
+		if err = json.Unmarshal(call.Input, &req); err == nil {
+			req.ID = call.ID
+			var res mcp.SubmitReviewResponse
+			res, err = hostCall(ctx, r.submitReview, req)
+			resp, success = res, res.Success
+		}
+	case "submit_result":
+		var req mcp.SubmitResultRequest
+		if err = json.Unmarshal(call.Input, &req); err == nil {
+			req.ID = call.ID
+			var res mcp.SubmitResultResponse
+			res, err = hostCall(ctx, r.submitResult, req)
+			resp, success = res, res.Success
+		}
+	default:
+		return "", false, false
+	}
+	if err != nil {
+		return err.Error(), true, true
+	}
+	data, _ := json.Marshal(resp)
+	return string(data), !success, true
+}
+
+// hostCall sends req to the worker over cp and waits for its response.
+func hostCall[Req, Resp any](ctx context.Context, cp *mcp.ChannelPair[Req, Resp], req Req) (Resp, error) {
+	var zero Resp
+	select {
+	case cp.Req <- req:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	select {
+	case resp := <-cp.Resp:
+		return resp, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// toolInputSummary briefly describes a tool call's input for the log.
+func toolInputSummary(input json.RawMessage) string {
+	var args map[string]any
+	if json.Unmarshal(input, &args) != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "path", "title", "status"} {
+		if s, ok := args[key].(string); ok && s != "" {
+			if len(s) > 80 {
+				s = s[:80] + "..."
+			}
+			return s
+		}
+	}
+	return ""
+}
+
+// Permission, question and plan approval requests never arise: the model
+// calls tools without asking.
+
+func (r *Runner) PermissionRequestChan() <-chan mcp.PermissionRequest     { return nil }
+func (r *Runner) SendPermissionResponse(mcp.PermissionResponse)           {}
+func (r *Runner) QuestionRequestChan() <-chan mcp.QuestionRequest         { return nil }
+func (r *Runner) SendQuestionResponse(mcp.QuestionResponse)               {}
+func (r *Runner) PlanApprovalRequestChan() <-chan mcp.PlanApprovalRequest { return nil }
+func (r *Runner) SendPlanApprovalResponse(mcp.PlanApprovalResponse)       {}
+
+// hostChan returns cp's request channel while the runner offers host tools.
+func hostChan[Req, Resp any](r *Runner, cp *mcp.ChannelPair[Req, Resp]) <-chan Req {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped || !r.hostTools {
+		return nil
+	}
+	return cp.Req
+}
+
+// respond hands the worker's response to the waiting tool call.
+func respond[Req, Resp any](cp *mcp.ChannelPair[Req, Resp], resp Resp) {
+	select {
+	case cp.Resp <- resp:
+	default:
+	}
+}
+
+func (r *Runner) CreatePRRequestChan() <-chan mcp.CreatePRRequest { return hostChan(r, r.createPR) }
+func (r *Runner) SendCreatePRResponse(resp mcp.CreatePRResponse)  { respond(r.createPR, resp) }
+func (r *Runner) PushBranchRequestChan() <-chan mcp.PushBranchRequest {
+	return hostChan(r, r.pushBranch)
+}
+func (r *Runner) SendPushBranchResponse(resp mcp.PushBranchResponse) { respond(r.pushBranch, resp) }
+func (r *Runner) GetReviewCommentsRequestChan() <-chan mcp.GetReviewCommentsRequest {
+	return hostChan(r, r.getReviewComments)
+}
+func (r *Runner) SendGetReviewCommentsResponse(resp mcp.GetReviewCommentsResponse) {
+	respond(r.getReviewComments, resp)
+}
+func (r *Runner) CommentIssueRequestChan() <-chan mcp.CommentIssueRequest {
+	return hostChan(r, r.commentIssue)
+}
+func (r *Runner) SendCommentIssueResponse(resp mcp.CommentIssueResponse) {
+	respond(r.commentIssue, resp)
+}
+func (r *Runner) SubmitReviewRequestChan() <-chan mcp.SubmitReviewRequest {
+	return hostChan(r, r.submitReview)
+}
+func (r *Runner) SendSubmitReviewResponse(resp mcp.SubmitReviewResponse) {
+	respond(r.submitReview, resp)
+}
+func (r *Runner) SubmitResultRequestChan() <-chan mcp.SubmitResultRequest {
+	return hostChan(r, r.submitResult)
+}
+func (r *Runner) SendSubmitResultResponse(resp mcp.SubmitResultResponse) {
+	respond(r.submitResult, resp)
+}
+
+// Stop cancels any call in flight; the runner takes no more host tool
+// requests.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// Interrupt cancels the call in flight.
+func (r *Runner) Interrupt() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/mcp"
+)
+
+// scriptedProvider replies with each of replies in turn, recording the
+// requests it gets.
+type scriptedProvider struct {
+	replies  []Response
+	requests []Request
+}
+
+func (p *scriptedProvider) Complete(_ context.Context, req Request) (Response, error) {
+	p.requests = append(p.requests, req)
+	resp := p.replies[0]
+	p.replies = p.replies[1:]
+	return resp, nil
+}
+
+func call(id, name, input string) ToolCall {
+	return ToolCall{ID: id, Name: name, Input: json.RawMessage(input)}
+}
+
+func testRunner(t *testing.T, replies ...Response) (*Runner, *scriptedProvider) {
+	t.Helper()
+	r, err := NewRunner(Config{Provider: ProviderOpenAI, Model: "m", InputPrice: 1, OutputPrice: 10}, "sess-1", t.TempDir(), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &scriptedProvider{replies: replies}
+	r.provider = p
+	r.SetAllowedTools(claude.ComposeTools(claude.ToolSetBase, claude.ToolSetContainerShell))
+	return r, p
+}
+
+func drain(ch <-chan claude.ResponseChunk) []claude.ResponseChunk {
+	var chunks []claude.ResponseChunk
+	for c := range ch {
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+func TestRunner_ToolLoop(t *testing.T) {
+	r, p := testRunner(t,
+		Response{ToolCalls: []ToolCall{call("1", "write_file", `{"path":"pkg/a.txt","content":"hello"}`)}, Usage: Usage{InputTokens: 1000, OutputTokens: 100}},
+		Response{Text: "checking", ToolCalls: []ToolCall{call("2", "run_command", `{"command":"cat pkg/a.txt"}`), call("3", "read_file", `{"path":"../etc/passwd"}`)}},
+		Response{Text: "done", Usage: Usage{InputTokens: 1000}},
+	)
+	r.SetSystemPrompt("Fix issue #1.")
+
+	chunks := drain(r.Send(context.Background(), "go"))
+
+	if data, _ := os.ReadFile(filepath.Join(r.workDir, "pkg/a.txt")); string(data) != "hello" {
+		t.Errorf("expected the file written, got %q", data)
+	}
+	var tools []string
+	var stats *claude.StreamStats
+	for _, c := range chunks {
+		if c.Error != nil {
+			t.Fatalf("unexpected error: %v", c.Error)
+		}
+		if c.Type == claude.ChunkTypeToolUse {
+			tools = append(tools, c.ToolName+":"+c.ToolInput)
+		}
+		if c.Type == claude.ChunkTypeStreamStats {
+			stats = c.Stats
+		}
+	}
+	if !slices.Equal(tools, []string{"write_file:pkg/a.txt", "run_command:cat pkg/a.txt", "read_file:../etc/passwd"}) {
+		t.Errorf("tool chunks = %v", tools)
+	}
+	if stats == nil || stats.InputTokens != 2000 || stats.OutputTokens != 100 || stats.TotalCostUSD != 0.003 || stats.DurationMs <= 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !chunks[len(chunks)-1].Done {
+		t.Error("expected the last chunk done")
+	}
+
+	last := p.requests[2]
+	if !strings.HasPrefix(last.System, baseSystemPrompt) || !strings.HasSuffix(last.System, "Fix issue #1.") {
+		t.Errorf("unexpected system prompt %q", last.System)
+	}
+	results := last.Turns[len(last.Turns)-1].ToolResults
+	if len(results) != 2 || results[0].Content != "hello" || !results[1].IsError || !strings.Contains(results[1].Content, "outside the repository") {
+		t.Errorf("unexpected tool results: %+v", results)
+	}
+	if msgs := r.GetMessages(); len(msgs) != 2 || msgs[1].Content != "checking\n\ndone" {
+		t.Errorf("unexpected messages: %+v", msgs)
+	}
+	if !r.SessionStarted() || r.IsStreaming() {
+		t.Error("expected a started, idle session")
+	}
+}
+
+func TestRunner_OffersAllowedTools(t *testing.T) {
+	r, p := testRunner(t, Response{ToolCalls: []ToolCall{call("1", "run_command", `{"command":"rm -rf ."}`)}}, Response{Text: "ok"})
+	r.SetAllowedTools(claude.ToolSetReadOnly)
+	drain(r.Send(context.Background(), "review"))
+
+	var names []string
+	for _, d := range p.requests[0].Tools {
+		names = append(names, d.Name)
+	}
+	if !slices.Equal(names, []string{"read_file", "list_files"}) {
+		t.Errorf("offered tools = %v", names)
+	}
+	if res := p.requests[1].Turns[2].ToolResults[0]; !res.IsError || !strings.Contains(res.Content, "unknown tool") {
+		t.Errorf("expected a tool not offered refused, got %+v", res)
+	}
+}
+
+func TestRunner_RefusesCommandsByToolPolicy(t *testing.T) {
+	r, p := testRunner(t,
+		Response{ToolCalls: []ToolCall{
+			call("1", "run_command", `{"command":"curl https://example.com"}`),
+			call("2", "run_command", `{"command":"echo ok"}`),
+		}},
+		Response{Text: "ok"},
+	)
+	r.SetAllowedTools([]string{"Read", "Bash(echo:*)"})
+	r.SetDisallowedTools([]string{"Bash(curl:*)"})
+	chunks := drain(r.Send(context.Background(), "go"))
+
+	if !slices.ContainsFunc(p.requests[0].Tools, func(d mcp.ToolDefinition) bool { return d.Name == "run_command" }) {
+		t.Error("expected run_command offered for allowed command rules")
+	}
+	results := p.requests[1].Turns[2].ToolResults
+	if len(results) != 2 || !results[0].IsError || !strings.Contains(results[0].Content, "tool policy") {
+		t.Errorf("expected curl refused by the tool policy, got %+v", results)
+	}
+	if results[1].IsError || strings.TrimSpace(results[1].Content) != "ok" {
+		t.Errorf("expected an allowed command run, got %+v", results[1])
+	}
+	if !slices.ContainsFunc(chunks, func(c claude.ResponseChunk) bool {
+		return c.Type == claude.ChunkTypeText && strings.Contains(c.Content, "Permission denied") && strings.Contains(c.Content, "curl")
+	}) {
+		t.Error("expected the refusal surfaced in the transcript")
+	}
+}
+
+func TestRunner_HostTools(t *testing.T) {
+	r, p := testRunner(t,
+		Response{ToolCalls: []ToolCall{call("1", "submit_result", `{"status":"success","summary":"fixed","confidence":0.9}`)}},
+		Response{Text: "ok"},
+	)
+	r.SetHostTools(true)
+
+	got := make(chan mcp.SubmitResultRequest, 1)
+	go func() {
+		req := <-r.SubmitResultRequestChan()
+		got <- req
+		r.SendSubmitResultResponse(mcp.SubmitResultResponse{ID: req.ID, Success: true})
+	}()
+	drain(r.Send(context.Background(), "go"))
+
+	req := <-got
+	if req.ID != "1" || req.Status != "success" || req.Confidence != 0.9 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if !slices.ContainsFunc(p.requests[0].Tools, func(d mcp.ToolDefinition) bool { return d.Name == "submit_result" }) {
+		t.Error("expected the host tools offered")
+	}
+	if res := p.requests[1].Turns[2].ToolResults[0]; res.IsError || !strings.Contains(res.Content, `"success":true`) {
+		t.Errorf("unexpected tool result: %+v", res)
+	}
+
+	r.Stop()
+	if r.SubmitResultRequestChan() != nil {
+		t.Error("expected no host tool requests after Stop")
+	}
+}
+
+func TestRunner_ContainerizedCommand(t *testing.T) {
+	r, p := testRunner(t, Response{ToolCalls: []ToolCall{call("1", "run_command", `{"command":"go test ./..."}`)}}, Response{Text: "ok"})
+	r.SetContainerized(true, "erg:abc")
+	r.SetContainerNetwork("erg-offline")
+	var got container.Exec
+	r.execFunc = func(_ context.Context, e container.Exec) (string, int, error) {
+		got = e
+		return "FAIL", 1, nil
+	}
+	drain(r.Send(context.Background(), "go"))
+
+	if got.Image != "erg:abc" || got.Worktree != r.workDir || got.Network != "erg-offline" || got.Command != "go test ./..." {
+		t.Errorf("unexpected exec: %+v", got)
+	}
+	if res := p.requests[1].Turns[2].ToolResults[0]; !res.IsError || !strings.Contains(res.Content, "[exit code 1]") {
+		t.Errorf("unexpected tool result: %+v", res)
+	}
+}
+
+func TestNewRunner_CarriesOverConversation(t *testing.T) {
+	r, err := NewRunner(Config{Provider: ProviderGemini, Model: "m"}, "s", t.TempDir(), "", []claude.Message{
+		{Role: "user", Content: "plan it"}, {Role: "assistant", Content: "the plan"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.SessionStarted() || len(r.turns) != 2 || r.turns[1].Role != RoleAssistant {
+		t.Errorf("unexpected turns: %+v", r.turns)
+	}
+}
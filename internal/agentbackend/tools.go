@@ -0,0 +1,281 @@
+package agentbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/mcp"
+	"github.com/zhubert/erg/internal/toolpolicy"
+)
+
+// Limits on what a workspace tool returns to the model.
+const (
+	maxReadBytes    = 256 * 1024
+	maxCommandBytes = 64 * 1024
+	maxListedFiles  = 1000
+	commandTimeout  = 10 * time.Minute
+)
+
+// workspaceTool is a tool working on the session's worktree, offered when
+// any of the Claude tools it stands in for is allowed.
+type workspaceTool struct {
+	def    mcp.ToolDefinition
+	claude []string
+}
+
+var workspaceTools = []workspaceTool{
+	{
+		def: mcp.ToolDefinition{
+			Name:        "read_file",
+			Description: "Read a file of the repository.",
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]mcp.Property{"path": {Type: "string", Description: "Path relative to the repository root."}},
+				Required:   []string{"path"},
+			},
+		},
+		claude: []string{"Read"},
+	},
+	{
+		def: mcp.ToolDefinition{
+			Name:        "list_files",
+			Description: "List the files under a directory of the repository, recursively.",
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]mcp.Property{"path": {Type: "string", Description: "Directory relative to the repository root. Defaults to the root."}},
+			},
+		},
+		claude: []string{"Glob", "Grep", "Read"},
+	},
+	{
+		def: mcp.ToolDefinition{
+			Name:        "write_file",
+			Description: "Create or overwrite a file of the repository with the given content.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"path":    {Type: "string", Description: "Path relative to the repository root."},
+					"content": {Type: "string", Description: "The file's complete new content."},
+				},
+				Required: []string{"path", "content"},
+			},
+		},
+		claude: []string{"Write", "Edit"},
+	},
+	{
+		def: mcp.ToolDefinition{
+			Name:        "run_command",
+			Description: "Run a shell command at the repository root and return its combined output and exit code, e.g. to build or test.",
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]mcp.Property{"command": {Type: "string", Description: "The command, run with sh -c."}},
+				Required:   []string{"command"},
+			},
+		},
+		claude: []string{"Bash"},
+	},
+}
+
+// offeredTools returns the workspace tools allowed by the Claude tool
+// lists allowed and disallowed. A rule such as "Bash(go test:*)" allows
+// its tool; which commands it allows is checked when one runs.
+func offeredTools(allowed, disallowed []string) []mcp.ToolDefinition {
+	var defs []mcp.ToolDefinition
+	for _, t := range workspaceTools {
+		ok := false
+		for _, name := range t.claude {
+			if allowsTool(allowed, name) && !slices.Contains(disallowed, name) {
+				ok = true
+			}
+		}
+		if ok {
+			defs = append(defs, t.def)
+		}
+	}
+	return defs
+}
+
+// allowsTool reports whether the Claude tool rules allow the tool name,
+// whole or through a pattern.
+func allowsTool(rules []string, name string) bool {
+	return slices.ContainsFunc(rules, func(rule string) bool {
+		return rule == name || strings.HasPrefix(rule, name+"(")
+	})
+}
+
+// commandRefusal returns why the tool policy refuses call, a run_command
+// call whose command the session's tool rules don't allow, or nil.
+func (r *Runner) commandRefusal(call ToolCall) error {
+	if call.Name != "run_command" {
+		return nil
+	}
+	var args struct {
+		Command string `json:"command"`
+	}
+	if json.Unmarshal(call.Input, &args) != nil || strings.TrimSpace(args.Command) == "" {
+		return nil
+	}
+	r.mu.Lock()
+	allowed, disallowed := slices.Clone(r.allowedTools), slices.Clone(r.disallowedTools)
+	r.mu.Unlock()
+	if !allowsTool(allowed, "Bash") || slices.Contains(disallowed, "Bash") {
+		return nil // not offered; callTool refuses it as unknown
+	}
+	return toolpolicy.CheckCommand(args.Command, allowed, disallowed)
+}
+
+// resolvePath returns the absolute path of rel within root, refusing paths
+// that leave it.
+func resolvePath(root, rel string) (string, error) {
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	r, err := filepath.Rel(root, p)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside the repository", rel)
+	}
+	return p, nil
+}
+
+// runWorkspaceTool carries out a workspace tool call. ok is false when
+// name is not a workspace tool.
+func (r *Runner) runWorkspaceTool(ctx context.Context, name string, input json.RawMessage) (result string, isError, ok bool) {
+	var args struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "invalid arguments: " + err.Error(), true, true
+	}
+	var err error
+	switch name {
+	case "read_file":
+		result, err = r.readFile(args.Path)
+	case "list_files":
+		result, err = r.listFiles(args.Path)
+	case "write_file":
+		result, err = r.writeFile(args.Path, args.Content)
+	case "run_command":
+		result, err = r.runCommand(ctx, args.Command)
+	default:
+		return "", false, false
+	}
+	if err != nil {
+		return err.Error(), true, true
+	}
+	return result, false, true
+}
+
+func (r *Runner) readFile(rel string) (string, error) {
+	p, err := resolvePath(r.workDir, rel)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxReadBytes {
+		return string(data[:maxReadBytes]) + fmt.Sprintf("\n[truncated: file is %d bytes]", len(data)), nil
+	}
+	return string(data), nil
+}
+
+func (r *Runner) writeFile(rel, content string) (string, error) {
+	p, err := resolvePath(r.workDir, rel)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("wrote %d bytes to %s", len(content), rel), nil
+}
+
+func (r *Runner) listFiles(rel string) (string, error) {
+	dir, err := resolvePath(r.workDir, rel)
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(files) == maxListedFiles {
+			return fs.SkipAll
+		}
+		rel, _ := filepath.Rel(r.workDir, p)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	out := strings.Join(files, "\n")
+	if len(files) == maxListedFiles {
+		out += fmt.Sprintf("\n[truncated at %d files]", maxListedFiles)
+	}
+	return out, nil
+}
+
+// runCommand runs command at the worktree root: in a throwaway container
+// of the session image when containerized, otherwise on the host.
+func (r *Runner) runCommand(ctx context.Context, command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("command is empty")
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	r.mu.Lock()
+	e := container.Exec{
+		Image: r.image, Worktree: r.workDir, RepoPath: r.repoPath,
+		Network: r.network, Env: slices.Clone(r.env), Command: command,
+	}
+	containerized := r.containerized
+	r.mu.Unlock()
+
+	var out string
+	var code int
+	if containerized {
+		var err error
+		if out, code, err = r.execFunc(ctx, e); err != nil {
+			return "", err
+		}
+	} else {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = r.workDir
+		data, err := cmd.CombinedOutput()
+		out = string(data)
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			return "", err
+		}
+	}
+	if len(out) > maxCommandBytes {
+		out = "[output truncated]\n" + out[len(out)-maxCommandBytes:]
+	}
+	if code != 0 {
+		return "", fmt.Errorf("%s\n[exit code %d]", out, code)
+	}
+	return out, nil
+}
@@ -32,8 +32,10 @@ type AgentConfig struct {
 	maxConcurrent  int
 	mergeMethod    string
 
-	asanaProjects map[string]string // repo path → Asana project GID
-	linearTeams   map[string]string // repo path → Linear team ID
+	asanaProjects  map[string]string // repo path → Asana project GID
+	linearTeams    map[string]string // repo path → Linear team ID
+	gitlabProjects map[string]string // repo path → GitLab project ID or path
+	clickupLists   map[string]string // repo path → ClickUp list ID
 }
 
 // Compile-time interface satisfaction check.
@@ -296,3 +298,55 @@ func (c *AgentConfig) SetLinearTeam(repoPath, teamID string) {
 		c.linearTeams[repoPath] = teamID
 	}
 }
+
+// HasGitLabProject returns true if a GitLab project is configured for the given repo.
+func (c *AgentConfig) HasGitLabProject(repoPath string) bool {
+	return c.GetGitLabProject(repoPath) != ""
+}
+
+// GetGitLabProject returns the GitLab project ID or path for the given repo path.
+func (c *AgentConfig) GetGitLabProject(repoPath string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gitlabProjects[repoPath]
+}
+
+// SetGitLabProject stores the GitLab project ID or path for the given repo path.
+func (c *AgentConfig) SetGitLabProject(repoPath, project string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gitlabProjects == nil {
+		c.gitlabProjects = make(map[string]string)
+	}
+	if project == "" {
+		delete(c.gitlabProjects, repoPath)
+	} else {
+		c.gitlabProjects[repoPath] = project
+	}
+}
+
+// HasClickUpList returns true if a ClickUp list is configured for the given repo.
+func (c *AgentConfig) HasClickUpList(repoPath string) bool {
+	return c.GetClickUpList(repoPath) != ""
+}
+
+// GetClickUpList returns the ClickUp list ID for the given repo path.
+func (c *AgentConfig) GetClickUpList(repoPath string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clickupLists[repoPath]
+}
+
+// SetClickUpList stores the ClickUp list ID for the given repo path.
+func (c *AgentConfig) SetClickUpList(repoPath, listID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clickupLists == nil {
+		c.clickupLists = make(map[string]string)
+	}
+	if listID == "" {
+		delete(c.clickupLists, repoPath)
+	} else {
+		c.clickupLists[repoPath] = listID
+	}
+}
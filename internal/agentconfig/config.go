@@ -39,6 +39,8 @@ type Config interface {
 	// Issue providers
 	SetAsanaProject(repoPath, projectGID string)
 	SetLinearTeam(repoPath, teamID string)
+	SetGitLabProject(repoPath, project string)
+	SetClickUpList(repoPath, listID string)
 
 	// Persistence
 	Save() error
@@ -0,0 +1,208 @@
+package claude
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// AuthRefreshWindow is how long before an OAuth access token expires that it
+// is refreshed, so a session started just before expiry doesn't lose its
+// credentials mid-run.
+const AuthRefreshWindow = 15 * time.Minute
+
+// AuthFileTTL is the age past which an erg-auth-* file is stale. Sessions
+// rewrite their file each time their container starts, so one this old was
+// left behind by a session that never cleaned up.
+const AuthFileTTL = 6 * time.Hour
+
+// authRefreshTimeout bounds the Claude CLI call that refreshes a token, so
+// a hung CLI or network can't stall a session's start.
+const authRefreshTimeout = 60 * time.Second
+
+// refreshAuthCommand makes a minimal authenticated request with the Claude
+// CLI on the host: sending it makes the CLI refresh an expired OAuth access
+// token and store the new one where it found the old. Merely starting the
+// CLI (claude -v) does not. Replaceable for testing.
+var refreshAuthCommand = func(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "claude", "--print", "--max-turns", "1", "--output-format", "json", "Reply with OK.")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("claude --print: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// AuthStatus describes the Claude credentials containers would be given.
+type AuthStatus struct {
+	Source string // Where the credentials come from, as ContainerAuthSource; empty if none
+
+	// ExpiresAt is when the OAuth access token expires. Zero for API keys
+	// and long-lived tokens, which don't.
+	ExpiresAt time.Time
+
+	// SelfRefreshing is set for .credentials.json, whose refresh token the
+	// CLI inside the container uses to renew an expired access token.
+	SelfRefreshing bool
+}
+
+// Valid reports whether there are credentials and their access token, if
+// it expires, hasn't yet.
+func (s AuthStatus) Valid() bool {
+	return s.Source != "" && (s.ExpiresAt.IsZero() || time.Now().Before(s.ExpiresAt))
+}
+
+// ExpiresWithin reports whether the access token expires within d.
+func (s AuthStatus) ExpiresWithin(d time.Duration) bool {
+	return !s.ExpiresAt.IsZero() && time.Until(s.ExpiresAt) < d
+}
+
+// ClaudeAuthStatus returns the credentials containers would be given, in the
+// order writeContainerAuthFile picks them. Unlike ContainerAuthSource, it
+// reports a keychain OAuth entry whose token has expired.
+func ClaudeAuthStatus() AuthStatus {
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		return AuthStatus{Source: "ANTHROPIC_API_KEY env var"}
+	}
+	if os.Getenv("CLAUDE_CODE_OAUTH_TOKEN") != "" {
+		return AuthStatus{Source: "CLAUDE_CODE_OAUTH_TOKEN env var"}
+	}
+	if readKeychainPassword("anthropic_api_key") != "" {
+		return AuthStatus{Source: "macOS keychain (anthropic_api_key)"}
+	}
+	if readKeychainPassword("Claude Code") != "" {
+		return AuthStatus{Source: "macOS keychain (Claude Code)"}
+	}
+	if raw := readKeychainPassword("Claude Code-credentials"); raw != "" {
+		var creds keychainOAuthCredentials
+		if json.Unmarshal([]byte(raw), &creds) == nil && creds.ClaudeAiOauth.AccessToken != "" {
+			return AuthStatus{Source: "macOS keychain (Claude Code-credentials)", ExpiresAt: oauthExpiry(creds)}
+		}
+	}
+	if credentialsFileExists() {
+		st := AuthStatus{Source: "$CLAUDE_CONFIG_DIR/.credentials.json (OAuth via claude login)", SelfRefreshing: true}
+		if claudeDir, err := paths.ClaudeConfigDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(claudeDir, ".credentials.json")); err == nil {
+				var creds keychainOAuthCredentials
+				if json.Unmarshal(data, &creds) == nil {
+					st.ExpiresAt = oauthExpiry(creds)
+				}
+			}
+		}
+		return st
+	}
+	return AuthStatus{}
+}
+
+// oauthExpiry returns when parsed OAuth credentials' access token expires,
+// or zero if they don't say.
+func oauthExpiry(creds keychainOAuthCredentials) time.Time {
+	if creds.ClaudeAiOauth.ExpiresAt <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(creds.ClaudeAiOauth.ExpiresAt)
+}
+
+// RefreshAuth refreshes an OAuth access token that has expired or expires
+// within AuthRefreshWindow by making a request with the Claude CLI on the
+// host, taking at most authRefreshTimeout, and returns the credentials'
+// status afterwards. Credentials that don't expire, and a .credentials.json
+// the container's CLI refreshes itself, are left alone. It fails only when
+// the token is still expired after the refresh.
+func RefreshAuth(ctx context.Context) (AuthStatus, error) {
+	st := ClaudeAuthStatus()
+	if st.SelfRefreshing || !st.ExpiresWithin(AuthRefreshWindow) {
+		return st, nil
+	}
+	refreshCtx, cancel := context.WithTimeout(ctx, authRefreshTimeout)
+	refreshErr := refreshAuthCommand(refreshCtx)
+	cancel()
+	st = ClaudeAuthStatus()
+	if !st.Valid() {
+		if refreshErr != nil {
+			return st, fmt.Errorf("claude credentials from %s expired and could not be refreshed: %w", st.Source, refreshErr)
+		}
+		return st, fmt.Errorf("claude credentials from %s expired at %s; run 'claude login' or set CLAUDE_CODE_OAUTH_TOKEN", st.Source, st.ExpiresAt.Format(time.RFC3339))
+	}
+	return st, nil
+}
+
+// AuthFile describes an erg-auth-* file.
+type AuthFile struct {
+	Path      string
+	SessionID string
+	ModTime   time.Time
+	Vars      []string // Names of the environment variables it sets
+}
+
+// ListAuthFiles returns the erg-auth-* files FindAuthFiles finds, with the
+// names (not the values) of the variables each sets.
+func ListAuthFiles() ([]AuthFile, error) {
+	found, err := FindAuthFiles()
+	if err != nil {
+		return nil, err
+	}
+	var files []AuthFile
+	for _, p := range found {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		files = append(files, AuthFile{
+			Path:      p,
+			SessionID: strings.TrimPrefix(filepath.Base(p), "erg-auth-"),
+			ModTime:   info.ModTime(),
+			Vars:      authFileVars(p),
+		})
+	}
+	return files, nil
+}
+
+// authFileVars returns the names of the variables an env file sets.
+func authFileVars(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var vars []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key, _, ok := strings.Cut(scanner.Text(), "="); ok && key != "" {
+			vars = append(vars, key)
+		}
+	}
+	return vars
+}
+
+// RotateAuthFiles removes erg-auth-* files last written more than ttl ago.
+// A container reads its file only as it starts, and each start writes a
+// fresh one, so removing a stale file never affects a running session but
+// keeps old tokens from lingering on disk. Returns the number removed.
+func RotateAuthFiles(ttl time.Duration) (int, error) {
+	files, err := ListAuthFiles()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, f := range files {
+		if time.Since(f.ModTime) < ttl {
+			continue
+		}
+		if err := os.Remove(f.Path); err == nil {
+			count++
+		} else if !os.IsNotExist(err) {
+			return count, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,139 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClaudeAuthStatus(t *testing.T) {
+	setupAuthTest(t)
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+
+	if st := ClaudeAuthStatus(); st.Source != "" || st.Valid() {
+		t.Errorf("expected no credentials, got %+v", st)
+	}
+
+	expires := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	creds := fmt.Sprintf(`{"claudeAiOauth":{"accessToken":"tok","expiresAt":%d}}`, expires.UnixMilli())
+	if err := os.WriteFile(filepath.Join(claudeDir, ".credentials.json"), []byte(creds), 0600); err != nil {
+		t.Fatal(err)
+	}
+	st := ClaudeAuthStatus()
+	if !st.SelfRefreshing || !st.ExpiresAt.Equal(expires) || st.Valid() || !st.ExpiresWithin(AuthRefreshWindow) {
+		t.Errorf("expected an expired, self-refreshing token, got %+v", st)
+	}
+
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "long-lived")
+	if st := ClaudeAuthStatus(); st.Source != "CLAUDE_CODE_OAUTH_TOKEN env var" || !st.ExpiresAt.IsZero() || !st.Valid() {
+		t.Errorf("expected the env token preferred, got %+v", st)
+	}
+}
+
+func TestRefreshAuth_LeavesTokensThatNeedNoRefresh(t *testing.T) {
+	setupAuthTest(t)
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+	ran := false
+	orig := refreshAuthCommand
+	refreshAuthCommand = func(context.Context) error { ran = true; return nil }
+	t.Cleanup(func() { refreshAuthCommand = orig })
+
+	os.WriteFile(filepath.Join(claudeDir, ".credentials.json"), []byte(`{"claudeAiOauth":{"accessToken":"tok","expiresAt":1}}`), 0600)
+	if _, err := RefreshAuth(context.Background()); err != nil || ran {
+		t.Errorf("expected .credentials.json left to the CLI, got err %v, ran %v", err, ran)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+	if st, err := RefreshAuth(context.Background()); err != nil || ran || st.Source != "ANTHROPIC_API_KEY env var" {
+		t.Errorf("expected an API key left alone, got %+v, err %v, ran %v", st, err, ran)
+	}
+}
+
+func TestRefreshAuth_RenewsExpiringKeychainToken(t *testing.T) {
+	setupAuthTest(t)
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+
+	keychainCreds := func(expires time.Time) string {
+		return fmt.Sprintf(`{"claudeAiOauth":{"accessToken":"tok","expiresAt":%d}}`, expires.UnixMilli())
+	}
+	before := time.Now().Add(5 * time.Minute).Truncate(time.Millisecond)
+	after := time.Now().Add(8 * time.Hour).Truncate(time.Millisecond)
+	entry := keychainCreds(before)
+	origKeychain := readKeychainPassword
+	readKeychainPassword = func(service string) string {
+		if service == "Claude Code-credentials" {
+			return entry
+		}
+		return ""
+	}
+	t.Cleanup(func() { readKeychainPassword = origKeychain })
+
+	// The CLI stores a renewed token as it makes its request.
+	var hadDeadline bool
+	origRefresh := refreshAuthCommand
+	refreshAuthCommand = func(ctx context.Context) error {
+		_, hadDeadline = ctx.Deadline()
+		entry = keychainCreds(after)
+		return nil
+	}
+	t.Cleanup(func() { refreshAuthCommand = origRefresh })
+
+	st, err := RefreshAuth(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshAuth: %v", err)
+	}
+	if !st.ExpiresAt.Equal(after) || !st.ExpiresAt.After(before) {
+		t.Errorf("ExpiresAt = %v, want it moved forward from %v to %v", st.ExpiresAt, before, after)
+	}
+	if !hadDeadline {
+		t.Error("expected the refresh command to run with a timeout")
+	}
+}
+
+func TestListAuthFiles_ReportsVarNames(t *testing.T) {
+	stateDir := setupAuthTest(t)
+	os.WriteFile(filepath.Join(stateDir, "erg-auth-sess-1"), []byte("ANTHROPIC_API_KEY=sk-secret\nGH_TOKEN=ghp"), 0600)
+
+	files, err := ListAuthFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].SessionID != "sess-1" || !slices.Equal(files[0].Vars, []string{"ANTHROPIC_API_KEY", "GH_TOKEN"}) {
+		t.Errorf("unexpected auth files: %+v", files)
+	}
+}
+
+func TestRotateAuthFiles_RemovesOnlyStale(t *testing.T) {
+	stateDir := setupAuthTest(t)
+	fresh := filepath.Join(stateDir, "erg-auth-fresh")
+	stale := filepath.Join(stateDir, "erg-auth-stale")
+	os.WriteFile(fresh, []byte("ANTHROPIC_API_KEY=a"), 0600)
+	os.WriteFile(stale, []byte("ANTHROPIC_API_KEY=b"), 0600)
+	old := time.Now().Add(-AuthFileTTL - time.Minute)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := RotateAuthFiles(AuthFileTTL)
+	if err != nil || n != 1 {
+		t.Fatalf("RotateAuthFiles = %d, %v; want 1", n, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("expected the stale file removed")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("expected the fresh file kept")
+	}
+}
@@ -242,3 +242,31 @@ func (r *Runner) SendSubmitReviewResponse(resp mcp.SubmitReviewResponse) {
 		r.log.Debug("SendSubmitReviewResponse channel full, ignoring")
 	}
 }
+
+// SubmitResultRequestChan returns the channel for receiving submit result requests.
+func (r *Runner) SubmitResultRequestChan() <-chan mcp.SubmitResultRequest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.stopped || r.mcp == nil || r.mcp.SubmitResult == nil {
+		return nil
+	}
+	return r.mcp.SubmitResult.Req
+}
+
+// SendSubmitResultResponse sends a response to a submit result request.
+func (r *Runner) SendSubmitResultResponse(resp mcp.SubmitResultResponse) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.stopped || r.mcp == nil || r.mcp.SubmitResult == nil {
+		return
+	}
+
+	ch := r.mcp.SubmitResult.Resp
+	select {
+	case ch <- resp:
+		// Success
+	default:
+		r.log.Debug("SendSubmitResultResponse channel full, ignoring")
+	}
+}
@@ -9,6 +9,7 @@
 //   - mcp_config.go: MCP server configuration and socket management
 //   - process_manager.go: Process lifecycle and auto-recovery
 //   - container_auth.go: Container authentication and Docker argument building
+//   - conversation.go: Checkpointing containerized sessions' transcripts for resume
 //   - runner_interface.go: Interfaces for testing
 //   - mock_runner.go: Mock runner for testing
 //   - todo.go: TodoWrite tool parsing
@@ -26,6 +27,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/logger"
 	"github.com/zhubert/erg/internal/mcp"
 )
@@ -203,6 +205,19 @@ type Runner struct {
 	// Model: when non-empty, passed to Claude CLI via --model (resolved canonical ID)
 	model string
 
+	// Extra KEY=VALUE env vars written to the container env-file (e.g. scoped GH_TOKEN)
+	containerEnv []string
+
+	// Docker network for the container (empty = Docker default network)
+	containerNetwork string
+
+	// CPU, memory and process limits for the container (zero = unlimited)
+	containerResources ContainerResources
+
+	// Host directory the container's transcript persists in, checkpointed
+	// after each turn (empty when the conversation isn't kept)
+	conversationDir string
+
 	// Container ready callback: invoked when containerized session receives init message
 	onContainerReady func()
 
@@ -338,6 +353,44 @@ func (r *Runner) SetModel(model string) {
 	r.model = model
 }
 
+// GetModel returns the model set with SetModel; "" is the CLI default.
+func (r *Runner) GetModel() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.model
+}
+
+// SetContainerEnv sets extra KEY=VALUE environment variables passed to the
+// container via its env-file. Values are treated as secrets and redacted from
+// transcripts. Only used in containerized mode.
+func (r *Runner) SetContainerEnv(env []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containerEnv = append([]string(nil), env...)
+	for _, e := range env {
+		if _, val, ok := strings.Cut(e, "="); ok {
+			r.redactor.Add(val)
+		}
+	}
+}
+
+// SetContainerNetwork sets the Docker network the container joins when it
+// starts. An empty string uses Docker's default network. Only used in
+// containerized mode.
+func (r *Runner) SetContainerNetwork(network string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containerNetwork = network
+}
+
+// SetContainerResources sets the CPU, memory and process limits of the
+// container. Only used in containerized mode.
+func (r *Runner) SetContainerResources(resources ContainerResources) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containerResources = resources
+}
+
 // SetHostTools enables or disables host tools mode for this runner.
 // When enabled, host tool channels are initialized and the MCP config
 // will include the --host-tools flag.
@@ -440,6 +493,17 @@ func (r *Runner) ensureProcessRunning() error {
 		containerMCPPort = mcp.ContainerMCPPort
 	}
 
+	// Keep a local container's transcript on the host so an interrupted
+	// session resumes its conversation. A remote host can't mount it.
+	r.conversationDir = ""
+	if r.containerized && !container.IsRemote() {
+		if dir, err := ConversationDir(r.sessionID); err == nil {
+			r.conversationDir = dir
+		} else {
+			r.log.Warn("failed to determine conversation dir", "error", err)
+		}
+	}
+
 	config := ProcessConfig{
 		SessionID:         r.sessionID,
 		WorkingDir:        r.workingDir,
@@ -454,7 +518,11 @@ func (r *Runner) ensureProcessRunning() error {
 		ContainerMCPPort:  containerMCPPort,
 		SystemPrompt:      r.systemPrompt,
 		Model:             r.model,
+		ContainerEnv:      append([]string(nil), r.containerEnv...),
+		ContainerNetwork:  r.containerNetwork,
+		ConversationDir:   r.conversationDir,
 	}
+	config.ContainerResources = r.containerResources
 	copy(config.AllowedTools, r.allowedTools)
 	copy(config.DisallowedTools, r.disallowedTools)
 
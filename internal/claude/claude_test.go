@@ -1092,6 +1092,40 @@ func TestRunner_SessionStartedOnInitMessage_WithProcessManager(t *testing.T) {
 	}
 }
 
+func TestRunner_CheckpointsConversationOnResult(t *testing.T) {
+	runner := New("session-ckpt", "/tmp", "", false, nil)
+	dir := t.TempDir()
+	runner.conversationDir = dir
+	if err := os.WriteFile(transcriptPath(dir, "session-ckpt"), []byte("turn 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner.handleProcessLine(`{"type":"result","subtype":"success","result":"done"}`)
+
+	if data, err := os.ReadFile(checkpointPath(dir, "session-ckpt")); err != nil || string(data) != "turn 1\n" {
+		t.Errorf("checkpoint = %q, %v; want the transcript checkpointed at the end of the turn", data, err)
+	}
+}
+
+func TestRunner_PermissionDenialsInTranscript(t *testing.T) {
+	runner := New("session-denied", "/tmp", "", false, nil)
+	ch := make(chan ResponseChunk, 10)
+	runner.mu.Lock()
+	runner.responseChan.Setup(ch)
+	runner.mu.Unlock()
+
+	runner.handleProcessLine(`{"type":"result","subtype":"success","result":"done","permission_denials":[{"tool":"Bash","description":"curl example.com","reason":"refused by the repo's tool policy"}]}`)
+
+	want := "[Permission denied: Bash `curl example.com` (refused by the repo's tool policy)]"
+	msgs := runner.GetMessages()
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Content, want) {
+		t.Errorf("messages = %+v, want the denial %q in the transcript", msgs, want)
+	}
+	if chunk := <-ch; chunk.Type != ChunkTypeText || !strings.Contains(chunk.Content, want) {
+		t.Errorf("chunk = %+v, want the denial streamed", chunk)
+	}
+}
+
 func TestRunner_SessionStartedOnInitMessage_ContainerNoDeadlock(t *testing.T) {
 	// Regression test: MarkSessionStarted calls OnContainerReady which calls
 	// handleContainerReady which acquires r.mu.RLock(). If handleProcessLine
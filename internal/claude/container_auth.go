@@ -1,21 +1,30 @@
 package claude
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/paths"
 )
 
+// runtimeCommand returns a command running the container runtime's CLI
+// (docker, podman or nerdctl) with args.
+func runtimeCommand(args ...string) *exec.Cmd {
+	return container.CurrentRuntime().Command(context.Background(), args...)
+}
+
 // containerRunResult holds the result of building container run arguments.
 type containerRunResult struct {
-	Args       []string // Arguments for `docker run`
+	Args       []string // Arguments for the runtime's `run` (e.g. `docker run`)
 	AuthSource string   // Credential source used (empty if none)
 }
 
@@ -33,13 +42,25 @@ func buildContainerRunArgs(config ProcessConfig, claudeArgs []string) (container
 		return containerRunResult{}, fmt.Errorf("failed to determine Claude config dir: %w", err)
 	}
 
-	args := []string{
-		"run", "-i", "--rm",
-		"--name", containerName,
-		"-v", config.WorkingDir + ":/workspace",
-		"-v", claudeDir + ":/home/claude/.claude-host:ro",
-		"-w", "/workspace",
+	// A remote host can't mount local paths: the session's worktree is
+	// copied into a volume there, and the host's Claude config and main
+	// repository stay behind.
+	remote := container.IsRemote()
+	args := []string{"run", "-i", "--rm", "--name", containerName}
+	if remote {
+		args = append(args, "-v", container.WorkspaceVolume(config.SessionID)+":/workspace")
+	} else {
+		args = append(args,
+			"-v", config.WorkingDir+":/workspace",
+			"-v", claudeDir+":/home/claude/.claude-host:ro",
+		)
+		// Keep the session's transcript on the host so the next container
+		// can resume the conversation.
+		if config.ConversationDir != "" {
+			args = append(args, "-v", config.ConversationDir+":"+containerProjectDir)
+		}
 	}
+	args = append(args, "-w", "/workspace")
 
 	// Publish the container MCP port so the host can dial in.
 	// -p 0:<port> maps an ephemeral host port to the fixed container port.
@@ -48,6 +69,30 @@ func buildContainerRunArgs(config ProcessConfig, claudeArgs []string) (container
 		args = append(args, "-p", fmt.Sprintf("0:%d", config.ContainerMCPPort))
 	}
 
+	// Join the network for this state's network profile. Restricted networks
+	// are provisioned by the operator and must still allow the Claude API.
+	if config.ContainerNetwork != "" {
+		args = append(args, "--network", config.ContainerNetwork)
+	}
+
+	// Apply the repo's resource limits. Swap is capped at the memory limit
+	// so a session over it is OOM-killed rather than slowed to a crawl.
+	// GPUs need the NVIDIA Container Toolkit on the host.
+	if r := config.ContainerResources; r != (ContainerResources{}) {
+		if r.CPUs != "" {
+			args = append(args, "--cpus", r.CPUs)
+		}
+		if r.Memory != "" {
+			args = append(args, "--memory", r.Memory, "--memory-swap", r.Memory)
+		}
+		if r.PidsLimit > 0 {
+			args = append(args, "--pids-limit", strconv.Itoa(r.PidsLimit))
+		}
+		if r.GPUs != "" {
+			args = append(args, "--gpus", r.GPUs)
+		}
+	}
+
 	// Pass ERG_SKIP_UPDATE through to the container if set on the host.
 	// This allows developers to skip the entrypoint auto-update when testing
 	// with a locally-built container image.
@@ -61,10 +106,11 @@ func buildContainerRunArgs(config ProcessConfig, claudeArgs []string) (container
 	// (0600 permissions) and pass it via --env-file, which sets the env var
 	// directly in the container process. This is safer than -e which would
 	// expose the key in `ps` output on the host.
-	auth := writeContainerAuthFile(config.SessionID)
+	auth := writeContainerAuthFile(config.SessionID, config.ContainerEnv...)
 	if auth.Path != "" {
 		args = append(args, "--env-file", auth.Path)
-	} else if credentialsFileExists() {
+	}
+	if auth.Source == "" && !remote && credentialsFileExists() {
 		// No env var or keychain credentials, but .credentials.json exists on the host.
 		// The entrypoint copies it into the container's ~/.claude/, so Claude CLI
 		// will find it and handle token refresh natively. No --env-file needed.
@@ -74,7 +120,8 @@ func buildContainerRunArgs(config ProcessConfig, claudeArgs []string) (container
 	// Mount MCP config for AskUserQuestion/ExitPlanMode support.
 	// The MCP subprocess inside the container listens on a port and the host
 	// dials in (reverse TCP direction to avoid macOS firewall issues).
-	if config.MCPConfigPath != "" {
+	// Remote containers get the config inline instead; see BuildCommandArgs.
+	if config.MCPConfigPath != "" && !remote {
 		args = append(args, "-v", config.MCPConfigPath+":"+containerMCPConfigPath+":ro")
 	}
 
@@ -103,10 +150,17 @@ func buildContainerRunArgs(config ProcessConfig, claudeArgs []string) (container
 	// Git worktrees have a .git file pointing to /path/to/repo/.git/worktrees/<id>.
 	// We mount the repo at its original absolute path so these references work transparently.
 	// Note: Must be read-write because git needs to update .git/worktrees/<id>/ when committing.
-	if config.RepoPath != "" {
+	// A remote workspace is a clone of its own and needs no main repository.
+	if config.RepoPath != "" && !remote {
 		args = append(args, "-v", config.RepoPath+":"+config.RepoPath)
 	}
 
+	// Mount the repo's dependency cache volumes so sessions reuse the
+	// modules and packages earlier sessions downloaded.
+	if config.RepoPath != "" {
+		args = append(args, container.CacheRunArgs(config.RepoPath)...)
+	}
+
 	args = append(args, image)
 	args = append(args, claudeArgs...)
 	return containerRunResult{Args: args, AuthSource: auth.Source}, nil
@@ -227,8 +281,8 @@ func readKeychainOAuthToken() string {
 }
 
 // KeychainNeedsRefresh reports whether the macOS keychain has an OAuth entry
-// whose access token is missing or expired. When true, running `claude -v` on
-// the host will force the CLI to refresh the token before we read it.
+// whose access token is missing or expired. When true, RefreshAuth has the
+// CLI on the host refresh the token before we read it.
 func KeychainNeedsRefresh() bool {
 	if runtime.GOOS != "darwin" {
 		return false
@@ -278,8 +332,12 @@ type containerAuthResult struct {
 // are short-lived and will expire inside the container. For long-running container
 // sessions, use "claude setup-token" to generate a long-lived CLAUDE_CODE_OAUTH_TOKEN.
 //
+// extraEnv holds additional KEY=VALUE lines (such as a repo-scoped GH_TOKEN)
+// appended to the same file. Entries that are malformed or contain newlines
+// are dropped.
+//
 // Returns empty path if no credentials are available.
-func writeContainerAuthFile(sessionID string) containerAuthResult {
+func writeContainerAuthFile(sessionID string, extraEnv ...string) containerAuthResult {
 	var content string
 	var source string
 
@@ -295,14 +353,25 @@ func writeContainerAuthFile(sessionID string) containerAuthResult {
 		source = cred.Source
 	}
 
-	if content == "" {
-		return containerAuthResult{}
-	}
-
 	// Validate credential value has no newlines that would break Docker env-file format
 	// (Docker env-file doesn't support multiline values)
 	parts := strings.SplitN(content, "=", 2)
 	if len(parts) == 2 && strings.ContainsAny(parts[1], "\n\r") {
+		content, source = "", ""
+	}
+
+	lines := []string{}
+	if content != "" {
+		lines = append(lines, content)
+	}
+	for _, e := range extraEnv {
+		key, _, ok := strings.Cut(e, "=")
+		if !ok || key == "" || strings.ContainsAny(e, "\n\r") {
+			continue
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) == 0 {
 		return containerAuthResult{}
 	}
 
@@ -310,7 +379,7 @@ func writeContainerAuthFile(sessionID string) containerAuthResult {
 	if path == "" {
 		return containerAuthResult{}
 	}
-	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
 		return containerAuthResult{}
 	}
 	return containerAuthResult{Path: path, Source: source}
@@ -451,7 +520,8 @@ func ClearAuthFiles() (int, error) {
 
 // readKeychainPassword reads a password from the macOS keychain.
 // Returns empty string if not found, on error, or on non-macOS platforms.
-func readKeychainPassword(service string) string {
+// Replaceable for testing.
+var readKeychainPassword = func(service string) string {
 	if runtime.GOOS != "darwin" {
 		return ""
 	}
@@ -0,0 +1,112 @@
+package claude
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// containerProjectDir is where the Claude CLI in a session container keeps
+// the transcripts of conversations run in /workspace. A container run with
+// --rm loses them, so a host directory is mounted there to keep them.
+const containerProjectDir = "/home/claude/.claude/projects/-workspace"
+
+// ConversationDir returns the host directory a containerized session's
+// Claude CLI transcript persists in between containers.
+func ConversationDir(sessionID string) (string, error) {
+	dir, err := paths.ConversationsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionID), nil
+}
+
+// transcriptPath is the Claude CLI's live transcript of a session.
+func transcriptPath(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".jsonl")
+}
+
+// checkpointPath is the copy of a session's transcript taken when its last
+// turn completed.
+func checkpointPath(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".checkpoint.jsonl")
+}
+
+// checkpointConversation copies a session's transcript at the end of a
+// turn, the point a conversation can safely be resumed from.
+func checkpointConversation(dir, sessionID string) error {
+	return copyFileAtomic(transcriptPath(dir, sessionID), checkpointPath(dir, sessionID))
+}
+
+// prepareConversation readies a containerized session's transcript before
+// its container starts. Resuming rolls the transcript back to its last
+// checkpoint, dropping a turn the session was interrupted in, and reports
+// whether there was one; otherwise, or without a checkpoint, the transcript
+// is cleared for a new conversation.
+func prepareConversation(dir, sessionID string, resume bool) (bool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return false, err
+	}
+	if resume {
+		err := copyFileAtomic(checkpointPath(dir, sessionID), transcriptPath(dir, sessionID))
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	for _, path := range []string{transcriptPath(dir, sessionID), checkpointPath(dir, sessionID)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// HasConversationCheckpoint reports whether a containerized session has a
+// checkpointed conversation its next container resumes.
+func HasConversationCheckpoint(sessionID string) bool {
+	dir, err := ConversationDir(sessionID)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(checkpointPath(dir, sessionID))
+	return err == nil
+}
+
+// DeleteConversation removes a session's persisted transcript.
+func DeleteConversation(sessionID string) error {
+	dir, err := ConversationDir(sessionID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// copyFileAtomic copies src to dst through a temporary file, so dst is
+// never left half written.
+func copyFileAtomic(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".conversation-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,62 @@
+package claude
+
+import (
+	"os"
+	"testing"
+)
+
+func TestConversationCheckpoint_RollsBackInterruptedTurn(t *testing.T) {
+	dir := t.TempDir()
+	live := transcriptPath(dir, "sess")
+	if err := os.WriteFile(live, []byte("turn 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkpointConversation(dir, "sess"); err != nil {
+		t.Fatal(err)
+	}
+	// The container dies partway through the next turn.
+	if err := os.WriteFile(live, []byte("turn 1\nturn 2 (partial)\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := prepareConversation(dir, "sess", true)
+	if err != nil || !resumed {
+		t.Fatalf("prepareConversation = %v, %v; want the checkpoint restored", resumed, err)
+	}
+	if data, _ := os.ReadFile(live); string(data) != "turn 1\n" {
+		t.Errorf("transcript = %q, want it rolled back to the checkpoint", data)
+	}
+}
+
+func TestPrepareConversation_NewConversation(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{transcriptPath(dir, "sess"), checkpointPath(dir, "sess")} {
+		if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if resumed, err := prepareConversation(dir, "sess", false); err != nil || resumed {
+		t.Fatalf("prepareConversation = %v, %v; want a new conversation", resumed, err)
+	}
+	for _, path := range []string{transcriptPath(dir, "sess"), checkpointPath(dir, "sess")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s cleared, got %v", path, err)
+		}
+	}
+}
+
+func TestPrepareConversation_NoCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	// A first turn interrupted before it completed leaves no checkpoint.
+	if err := os.WriteFile(transcriptPath(dir, "sess"), []byte("partial\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if resumed, err := prepareConversation(dir, "sess", true); err != nil || resumed {
+		t.Fatalf("prepareConversation = %v, %v; want nothing to resume", resumed, err)
+	}
+	if _, err := os.Stat(transcriptPath(dir, "sess")); !os.IsNotExist(err) {
+		t.Errorf("expected the partial transcript cleared, got %v", err)
+	}
+}
@@ -48,6 +48,7 @@ func (r *Runner) ensureServerRunning() error {
 			r.mcp.GetReviewComments.Req, r.mcp.GetReviewComments.Resp,
 			r.mcp.CommentIssue.Req, r.mcp.CommentIssue.Resp,
 			r.mcp.SubmitReview.Req, r.mcp.SubmitReview.Resp,
+			r.mcp.SubmitResult.Req, r.mcp.SubmitResult.Resp,
 		))
 	}
 
@@ -41,6 +41,7 @@ type MockRunner struct {
 	getReviewComments *mcp.ChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse]
 	commentIssue      *mcp.ChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse]
 	submitReview      *mcp.ChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse]
+	submitResult      *mcp.ChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse]
 
 	// Callbacks for test assertions
 	OnSend             func(content []ContentBlock)
@@ -57,6 +58,9 @@ type MockRunner struct {
 	stopped      bool
 	systemPrompt string
 	model        string
+	containerEnv []string
+	network      string
+	resources    ContainerResources
 }
 
 // NewMockRunner creates a mock runner for testing.
@@ -198,6 +202,18 @@ func (m *MockRunner) SimulateSubmitReviewRequest(req mcp.SubmitReviewRequest) {
 	ch.Req <- req
 }
 
+// SimulateSubmitResultRequest triggers a submit result request that the UI will receive.
+func (m *MockRunner) SimulateSubmitResultRequest(req mcp.SubmitResultRequest) {
+	m.mu.RLock()
+	stopped := m.stopped
+	ch := m.submitResult
+	m.mu.RUnlock()
+	if stopped || ch == nil {
+		return
+	}
+	ch.Req <- req
+}
+
 // SessionStarted implements RunnerSession.
 func (m *MockRunner) SessionStarted() bool {
 	m.mu.RLock()
@@ -491,6 +507,48 @@ func (m *MockRunner) SendPlanApprovalResponse(resp mcp.PlanApprovalResponse) {
 	}
 }
 
+// SetContainerEnv implements RunnerConfig.
+func (m *MockRunner) SetContainerEnv(env []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containerEnv = append([]string(nil), env...)
+}
+
+// GetContainerEnv returns the container env vars (for test assertions).
+func (m *MockRunner) GetContainerEnv() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.containerEnv...)
+}
+
+// SetContainerNetwork implements RunnerConfig.
+func (m *MockRunner) SetContainerNetwork(network string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.network = network
+}
+
+// GetContainerNetwork returns the container network (for test assertions).
+func (m *MockRunner) GetContainerNetwork() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.network
+}
+
+// SetContainerResources implements RunnerConfig.
+func (m *MockRunner) SetContainerResources(resources ContainerResources) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resources = resources
+}
+
+// GetContainerResources returns the container limits (for test assertions).
+func (m *MockRunner) GetContainerResources() ContainerResources {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.resources
+}
+
 // SetModel implements RunnerConfig.
 func (m *MockRunner) SetModel(model string) {
 	m.mu.Lock()
@@ -498,7 +556,7 @@ func (m *MockRunner) SetModel(model string) {
 	m.model = model
 }
 
-// GetModel returns the current model (for test assertions).
+// GetModel implements RunnerConfig.
 func (m *MockRunner) GetModel() string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -515,6 +573,7 @@ func (m *MockRunner) SetHostTools(hostTools bool) {
 		m.getReviewComments = mcp.NewChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse](1)
 		m.commentIssue = mcp.NewChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse](1)
 		m.submitReview = mcp.NewChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse](1)
+		m.submitResult = mcp.NewChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse](1)
 	}
 }
 
@@ -633,6 +692,29 @@ func (m *MockRunner) SendSubmitReviewResponse(resp mcp.SubmitReviewResponse) {
 	}
 }
 
+// SubmitResultRequestChan implements RunnerSession.
+func (m *MockRunner) SubmitResultRequestChan() <-chan mcp.SubmitResultRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.stopped || m.submitResult == nil {
+		return nil
+	}
+	return m.submitResult.Req
+}
+
+// SendSubmitResultResponse implements RunnerSession.
+func (m *MockRunner) SendSubmitResultResponse(resp mcp.SubmitResultResponse) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.stopped || m.submitResult == nil {
+		return
+	}
+	select {
+	case m.submitResult.Resp <- resp:
+	default:
+	}
+}
+
 // Stop implements RunnerSession.
 func (m *MockRunner) Stop() {
 	m.mu.Lock()
@@ -652,6 +734,7 @@ func (m *MockRunner) Stop() {
 	m.getReviewComments.Close()
 	m.commentIssue.Close()
 	m.submitReview.Close()
+	m.submitResult.Close()
 	if m.responseChan != nil {
 		// Only close if we control it
 		select {
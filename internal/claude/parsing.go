@@ -14,6 +14,19 @@ type PermissionDenial struct {
 	Reason      string `json:"reason"`      // Why it was denied (optional)
 }
 
+// formatPermissionDenial renders a denial for the session transcript, e.g.
+// "Bash `curl example.com` (refused by the repo's tool policy)".
+func formatPermissionDenial(d PermissionDenial) string {
+	s := d.Tool
+	if d.Description != "" {
+		s += " `" + d.Description + "`"
+	}
+	if d.Reason != "" {
+		s += " (" + d.Reason + ")"
+	}
+	return s
+}
+
 // streamMessage represents a JSON message from Claude's stream-json output
 type streamMessage struct {
 	Type            string `json:"type"`               // "system", "assistant", "user", "result"
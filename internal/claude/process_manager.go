@@ -3,6 +3,7 @@ package claude
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -13,6 +14,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/secrets"
 )
 
@@ -77,8 +79,29 @@ type ProcessConfig struct {
 	SystemPrompt            string        // When set, passed to Claude CLI via --append-system-prompt
 	ContainerStartupTimeout time.Duration // Override container startup watchdog timeout (0 = use default)
 	Model                   string        // When set, passed to Claude CLI via --model (canonical model ID)
+	ContainerEnv            []string      // Extra KEY=VALUE vars written to the container env-file
+	ContainerNetwork        string        // Docker network to join (empty = Docker default)
+	ContainerResources      ContainerResources
+	ConversationDir         string // Host directory a container's Claude CLI transcript persists in; when set, containerized sessions resume
 }
 
+// ContainerResources limits a session container, and gives it GPUs. Empty
+// fields are unlimited, or no GPUs.
+type ContainerResources struct {
+	CPUs      string // e.g. "2" (--cpus)
+	Memory    string // e.g. "4g" (--memory, with no extra swap)
+	PidsLimit int    // max processes (--pids-limit)
+	GPUs      string // e.g. "all" or "device=0" (--gpus)
+}
+
+// ErrContainerOOM reports a session container killed for exceeding its
+// memory limit.
+var ErrContainerOOM = errors.New("session container ran out of memory")
+
+// oomExitCode is the exit status of a container whose main process was
+// SIGKILLed, as the kernel's OOM killer does.
+const oomExitCode = 137
+
 // ProcessCallbacks defines callbacks that the ProcessManager invokes during operation.
 // This allows the Runner to respond to process events without tight coupling.
 //
@@ -214,14 +237,28 @@ func (pm *ProcessManager) containerStartupTimeoutDuration() time.Duration {
 	return ContainerStartupTimeout
 }
 
+// containerMCPConfig returns the --mcp-config value for a containerized
+// session: the path the config is mounted at, or, on a remote host that
+// can't mount it, the config itself.
+func containerMCPConfig(hostPath string) string {
+	if !container.IsRemote() {
+		return containerMCPConfigPath
+	}
+	data, err := os.ReadFile(hostPath)
+	if err != nil {
+		return containerMCPConfigPath
+	}
+	return string(data)
+}
+
 // BuildCommandArgs builds the command line arguments for the Claude CLI based on the config.
 // This is exported for testing purposes to verify correct argument construction.
 func BuildCommandArgs(config ProcessConfig) []string {
 	var args []string
-	if config.SessionStarted && !config.Containerized {
+	if config.SessionStarted && (!config.Containerized || config.ConversationDir != "") {
 		// Session already started - resume our own session
-		// (Skip resume in container mode: each container run is a fresh environment
-		// with no prior session data, so --resume would fail with "No conversation found")
+		// (A container only has the prior session data when its transcript persists in a
+		// mounted ConversationDir; otherwise --resume would fail with "No conversation found")
 		args = []string{
 			"--print",
 			"--output-format", "stream-json",
@@ -263,7 +300,7 @@ func BuildCommandArgs(config ProcessConfig) []string {
 		// so we use one or the other — never both.
 		if config.MCPConfigPath != "" {
 			args = append(args,
-				"--mcp-config", containerMCPConfigPath,
+				"--mcp-config", containerMCPConfig(config.MCPConfigPath),
 				"--permission-prompt-tool", "mcp__erg__permission",
 			)
 		} else {
@@ -335,11 +372,33 @@ func (pm *ProcessManager) Start() error {
 	pm.log.Info("starting process")
 	startTime := time.Now()
 
+	// Refresh an OAuth token about to expire, so the container isn't
+	// handed one that dies mid-run.
+	if pm.config.Containerized {
+		if _, err := RefreshAuth(context.Background()); err != nil {
+			pm.log.Warn("failed to refresh container auth", "error", err)
+		}
+	}
+
 	// Container mode requires credentials (short-lived OAuth tokens rotate and would become invalid)
 	if pm.config.Containerized && !ContainerAuthAvailable() {
 		return fmt.Errorf("container mode requires authentication: set ANTHROPIC_API_KEY, CLAUDE_CODE_OAUTH_TOKEN, run 'claude login', or add 'anthropic_api_key' to macOS keychain")
 	}
 
+	// Roll a containerized session's transcript back to its last completed
+	// turn. Without a checkpoint there is no conversation to resume, and the
+	// session starts a new one.
+	if pm.config.Containerized && pm.config.ConversationDir != "" {
+		resumed, err := prepareConversation(pm.config.ConversationDir, pm.config.SessionID, pm.config.SessionStarted)
+		if err != nil {
+			pm.log.Warn("failed to restore conversation checkpoint", "error", err)
+		}
+		if resumed {
+			pm.log.Info("resuming conversation from checkpoint")
+		}
+		pm.config.SessionStarted = resumed
+	}
+
 	// Build command arguments
 	args := BuildCommandArgs(pm.config)
 
@@ -354,7 +413,7 @@ func (pm *ProcessManager) Start() error {
 		// docker run --rm only cleans up on clean exit, so a crashed container
 		// may still be lingering and block the new docker run.
 		containerName := "erg-" + pm.config.SessionID
-		rmCmd := exec.Command("docker", "rm", "-f", containerName)
+		rmCmd := runtimeCommand("rm", "-f", containerName)
 		if rmOut, rmErr := rmCmd.CombinedOutput(); rmErr != nil {
 			pm.log.Debug("pre-start container cleanup (may not exist)", "name", containerName, "output", strings.TrimSpace(string(rmOut)))
 		} else {
@@ -370,8 +429,8 @@ func (pm *ProcessManager) Start() error {
 		} else {
 			pm.log.Warn("no auth credentials found for container")
 		}
-		pm.log.Debug("starting containerized process", "command", "docker "+strings.Join(result.Args, " "))
-		cmd = exec.Command("docker", result.Args...)
+		pm.log.Debug("starting containerized process", "command", container.CurrentRuntime().Name()+" "+strings.Join(result.Args, " "))
+		cmd = runtimeCommand(result.Args...)
 		// Don't set cmd.Dir — the container's -w flag handles the working directory
 	} else {
 		pm.log.Debug("starting process", "command", "claude "+strings.Join(args, " "))
@@ -414,7 +473,7 @@ func (pm *ProcessManager) Start() error {
 		stderr.Close()
 		pm.log.Error("failed to start process", "error", err)
 		if pm.config.Containerized {
-			return fmt.Errorf("failed to start container: %w (is Docker running?)", err)
+			return fmt.Errorf("failed to start container: %w (is %s running?)", err, container.CurrentRuntime().Name())
 		}
 		return fmt.Errorf("failed to start process: %w", err)
 	}
@@ -536,14 +595,14 @@ func (pm *ProcessManager) Stop() {
 		containerNeverStarted := ready != nil && !isChannelClosed(ready)
 		if containerNeverStarted {
 			pm.log.Warn("container session was stopped before startup completed - capturing docker logs for diagnostics")
-			logCmd := exec.Command("docker", "logs", "--tail", "100", containerName)
+			logCmd := runtimeCommand("logs", "--tail", "100", containerName)
 			if logOutput, logErr := logCmd.CombinedOutput(); logErr == nil && len(logOutput) > 0 {
 				pm.log.Warn("container logs on shutdown", "logs", strings.TrimSpace(string(logOutput)))
 			}
 		}
 
 		pm.log.Debug("removing container", "name", containerName)
-		rmCmd := exec.Command("docker", "rm", "-f", containerName)
+		rmCmd := runtimeCommand("rm", "-f", containerName)
 		if err := rmCmd.Run(); err != nil {
 			pm.log.Debug("container rm failed (may already be removed)", "error", err)
 		}
@@ -928,6 +987,24 @@ func (pm *ProcessManager) handleExit(err error) {
 		return
 	}
 
+	// A container over its memory limit is killed by the OOM killer. It is
+	// reported at once rather than restarted into the same limit. Without a
+	// limit, a SIGKILL has too many other causes to tell.
+	var oomErr *exec.ExitError
+	if pm.config.Containerized && pm.config.ContainerResources.Memory != "" &&
+		errors.As(err, &oomErr) && oomErr.ExitCode() == oomExitCode {
+		pm.log.Error("container killed for exceeding its memory limit", "memory", pm.config.ContainerResources.Memory)
+		if authFile := containerAuthFilePath(pm.config.SessionID); authFile != "" {
+			if removeErr := os.Remove(authFile); removeErr == nil {
+				pm.log.Debug("cleaned up auth file on OOM kill", "path", authFile)
+			}
+		}
+		if pm.callbacks.OnFatalError != nil {
+			pm.callbacks.OnFatalError(fmt.Errorf("%w (limit %s)", ErrContainerOOM, pm.config.ContainerResources.Memory))
+		}
+		return
+	}
+
 	// Check with callback if we should restart
 	shouldRestart := true
 	if pm.callbacks.OnProcessExit != nil {
@@ -1096,7 +1173,7 @@ func (pm *ProcessManager) containerStartupWatchdog() {
 
 	// Capture docker logs before killing the process for diagnostics
 	containerName := "erg-" + pm.config.SessionID
-	logCmd := exec.Command("docker", "logs", "--tail", "50", containerName)
+	logCmd := runtimeCommand("logs", "--tail", "50", containerName)
 	logOutput, logErr := logCmd.CombinedOutput()
 	var logs string
 	if logErr == nil && len(logOutput) > 0 {
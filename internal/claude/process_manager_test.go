@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -17,6 +18,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/secrets"
 )
 
@@ -1247,8 +1249,8 @@ func TestBuildCommandArgs_Containerized_ResumedSession(t *testing.T) {
 
 	args := BuildCommandArgs(config)
 
-	// Containerized sessions always use --session-id (never --resume) because
-	// each container run is a fresh environment with no prior session data
+	// Without a persisted conversation, containerized sessions use --session-id
+	// (never --resume) because each container run is a fresh environment
 	if containsArg(args, "--resume") {
 		t.Error("Containerized session must not use --resume (no session data persists across container runs)")
 	}
@@ -1268,6 +1270,25 @@ func TestBuildCommandArgs_Containerized_ResumedSession(t *testing.T) {
 	}
 }
 
+func TestBuildCommandArgs_Containerized_ResumesPersistedConversation(t *testing.T) {
+	config := ProcessConfig{
+		SessionID:       "container-session-uuid",
+		WorkingDir:      "/tmp/worktree",
+		SessionStarted:  true,
+		Containerized:   true,
+		ConversationDir: "/data/conversations/container-session-uuid",
+	}
+
+	args := BuildCommandArgs(config)
+
+	if got := getArgValue(args, "--resume"); got != "container-session-uuid" {
+		t.Errorf("--resume = %q, want the session's persisted conversation resumed", got)
+	}
+	if containsArg(args, "--session-id") {
+		t.Error("resumed session should not pass --session-id")
+	}
+}
+
 func TestBuildCommandArgs_Containerized_ForkedSession(t *testing.T) {
 	config := ProcessConfig{
 		SessionID:         "child-session-uuid",
@@ -1431,6 +1452,44 @@ func TestBuildContainerRunArgs_DefaultImage(t *testing.T) {
 	}
 }
 
+func TestBuildContainerRunArgs_Remote(t *testing.T) {
+	orig := container.CurrentRuntime()
+	defer container.SetRuntime(orig)
+	container.SetRuntime(container.Remote{Host: "ssh://erg@builder"})
+
+	config := ProcessConfig{
+		SessionID:     "test-session",
+		WorkingDir:    "/path/to/worktree",
+		RepoPath:      "/path/to/repo",
+		MCPConfigPath: "/tmp/erg-mcp.json",
+	}
+	result, err := buildContainerRunArgs(config, []string{"--print"})
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	if !slices.Contains(result.Args, "erg-ws-test-session:/workspace") {
+		t.Errorf("expected the session's workspace volume mounted, got %v", result.Args)
+	}
+	for _, arg := range result.Args {
+		if strings.HasPrefix(arg, "/") && strings.Contains(arg, ":") {
+			t.Errorf("remote container mounts local path %q", arg)
+		}
+	}
+}
+
+func TestBuildCommandArgs_RemoteInlinesMCPConfig(t *testing.T) {
+	orig := container.CurrentRuntime()
+	defer container.SetRuntime(orig)
+	container.SetRuntime(container.Remote{Host: "ssh://erg@builder"})
+
+	mcpPath := filepath.Join(t.TempDir(), "mcp.json")
+	os.WriteFile(mcpPath, []byte(`{"mcpServers":{}}`), 0o600)
+	args := BuildCommandArgs(ProcessConfig{SessionID: "s", Containerized: true, MCPConfigPath: mcpPath})
+	if got := getArgValue(args, "--mcp-config"); got != `{"mcpServers":{}}` {
+		t.Errorf("--mcp-config = %q, want the config inline", got)
+	}
+}
+
 func TestBuildContainerRunArgs_ReportsAuthSource(t *testing.T) {
 
 	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
@@ -1631,6 +1690,28 @@ func TestWriteContainerAuthFile_AllowsSingleQuotes(t *testing.T) {
 	}
 }
 
+func TestWriteContainerAuthFile_ExtraEnv(t *testing.T) {
+	sessionID := "test-extra-env"
+	defer os.Remove(containerAuthFilePath(sessionID))
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-key")
+
+	result := writeContainerAuthFile(sessionID, "GH_TOKEN=ghs_scoped", "BAD=line\nINJECTED=1", "NOEQUALS")
+	if result.Path == "" {
+		t.Fatal("writeContainerAuthFile should write a file")
+	}
+
+	content, err := os.ReadFile(result.Path)
+	if err != nil {
+		t.Fatalf("failed to read auth file: %v", err)
+	}
+
+	expected := "ANTHROPIC_API_KEY=sk-ant-key\nGH_TOKEN=ghs_scoped"
+	if string(content) != expected {
+		t.Errorf("auth file content = %q, want %q", string(content), expected)
+	}
+}
+
 func TestContainerAuthDir_ReturnsUserPrivateDir(t *testing.T) {
 	dir := containerAuthDir()
 	if dir == "" {
@@ -1996,6 +2077,23 @@ func TestBuildContainerRunArgs_MountsMCPConfig(t *testing.T) {
 	}
 }
 
+func TestBuildContainerRunArgs_MountsConversationDir(t *testing.T) {
+	config := ProcessConfig{
+		SessionID:       "test-conversation",
+		WorkingDir:      "/path/to/worktree",
+		ContainerImage:  "erg",
+		ConversationDir: "/data/conversations/test-conversation",
+	}
+
+	result, err := buildContainerRunArgs(config, nil)
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	if want := "/data/conversations/test-conversation:" + containerProjectDir; !slices.Contains(result.Args, want) {
+		t.Errorf("expected the conversation dir mounted as %q, got %v", want, result.Args)
+	}
+}
+
 func TestBuildContainerRunArgs_NoMountsWithoutPaths(t *testing.T) {
 	config := ProcessConfig{
 		SessionID:      "test-no-mount",
@@ -2062,6 +2160,35 @@ func TestBuildContainerRunArgs_NoPortWithoutContainerMCPPort(t *testing.T) {
 	}
 }
 
+func TestBuildContainerRunArgs_Network(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		want    string
+	}{
+		{name: "default network", network: "", want: ""},
+		{name: "restricted network", network: "erg-offline", want: "erg-offline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ProcessConfig{
+				SessionID:        "test-network",
+				WorkingDir:       "/path/to/worktree",
+				ContainerImage:   "erg",
+				ContainerNetwork: tt.network,
+			}
+
+			result, err := buildContainerRunArgs(config, []string{"--print"})
+			if err != nil {
+				t.Fatalf("buildContainerRunArgs failed: %v", err)
+			}
+			if got := getArgValue(result.Args, "--network"); got != tt.want {
+				t.Errorf("--network = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProcessManager_MonitorExit_SingleWait(t *testing.T) {
 	// Regression test for issue #126: cmd.Wait() must only be called once.
 	// monitorExit owns the cmd.Wait() call; Stop() coordinates via waitDone channel.
@@ -2498,6 +2625,76 @@ func TestBuildContainerRunArgs_WithRepoPath(t *testing.T) {
 	}
 }
 
+func TestBuildContainerRunArgs_DependencyCaches(t *testing.T) {
+	config := ProcessConfig{
+		SessionID:      "test-session",
+		WorkingDir:     "/tmp/worktree",
+		RepoPath:       "/tmp/repo",
+		Containerized:  true,
+		ContainerImage: "test-image",
+	}
+
+	result, err := buildContainerRunArgs(config, []string{"--arg"})
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	joined := strings.Join(result.Args, " ")
+	prefix := container.CacheVolumeRepoPrefix("/tmp/repo")
+	for _, want := range []string{
+		"-v " + prefix + "go:/erg-cache/go",
+		"-e GOMODCACHE=/erg-cache/go/mod",
+		"-v " + prefix + "cargo-registry:/root/.cargo/registry",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in run args: %v", want, result.Args)
+		}
+	}
+	if idx := slices.Index(result.Args, "test-image"); idx < slices.Index(result.Args, "GOMODCACHE=/erg-cache/go/mod") {
+		t.Error("expected cache args before the image")
+	}
+
+	config.RepoPath = ""
+	result, err = buildContainerRunArgs(config, []string{"--arg"})
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	if strings.Contains(strings.Join(result.Args, " "), container.CacheVolumePrefix) {
+		t.Errorf("expected no cache volumes without a repo: %v", result.Args)
+	}
+}
+
+func TestBuildContainerRunArgs_Resources(t *testing.T) {
+	config := ProcessConfig{
+		SessionID:          "test-session",
+		WorkingDir:         "/tmp/worktree",
+		Containerized:      true,
+		ContainerImage:     "test-image",
+		ContainerResources: ContainerResources{CPUs: "2", Memory: "4g", PidsLimit: 512, GPUs: "all"},
+	}
+
+	result, err := buildContainerRunArgs(config, []string{"--arg"})
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	joined := strings.Join(result.Args, " ")
+	for _, want := range []string{"--cpus 2", "--memory 4g", "--memory-swap 4g", "--pids-limit 512", "--gpus all"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in run args: %v", want, result.Args)
+		}
+	}
+
+	config.ContainerResources = ContainerResources{}
+	result, err = buildContainerRunArgs(config, []string{"--arg"})
+	if err != nil {
+		t.Fatalf("buildContainerRunArgs failed: %v", err)
+	}
+	for _, flag := range []string{"--cpus", "--memory", "--pids-limit", "--gpus"} {
+		if slices.Contains(result.Args, flag) {
+			t.Errorf("expected no %s without limits: %v", flag, result.Args)
+		}
+	}
+}
+
 func TestBuildContainerRunArgs_WithoutRepoPath(t *testing.T) {
 	config := ProcessConfig{
 		SessionID:      "test-session",
@@ -3297,6 +3494,56 @@ func TestContainerStartupWatchdog_TimeoutLogsRedactedAndNotInFatalError(t *testi
 	pm.cancel()
 }
 
+func TestHandleExit_ContainerOOM(t *testing.T) {
+	// A real exit status 137, as a container killed by the OOM killer exits.
+	exitErr := exec.Command("sh", "-c", "exit 137").Run()
+	if exitErr == nil {
+		t.Fatal("expected exit status 137")
+	}
+
+	tests := []struct {
+		name    string
+		memory  string
+		wantOOM bool
+	}{
+		{name: "memory limit set", memory: "1g", wantOOM: true},
+		{name: "no memory limit", memory: "", wantOOM: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fatalErr error
+			var restarted bool
+			pm := NewProcessManager(ProcessConfig{
+				SessionID:          "test-oom",
+				WorkingDir:         t.TempDir(),
+				Containerized:      true,
+				ContainerResources: ContainerResources{Memory: tt.memory},
+			}, ProcessCallbacks{
+				OnFatalError: func(err error) { fatalErr = err },
+				OnProcessExit: func(error, string) bool {
+					restarted = true
+					return false
+				},
+			}, pmCaptureLogger(&strings.Builder{}))
+
+			pm.mu.Lock()
+			pm.running = true
+			pm.ctx, pm.cancel = context.WithCancel(context.Background())
+			pm.mu.Unlock()
+			defer pm.cancel()
+
+			pm.handleExit(exitErr)
+
+			if got := errors.Is(fatalErr, ErrContainerOOM); got != tt.wantOOM {
+				t.Errorf("OOM reported = %v, want %v (err: %v)", got, tt.wantOOM, fatalErr)
+			}
+			if restarted == tt.wantOOM {
+				t.Errorf("restart considered = %v, want %v", restarted, !tt.wantOOM)
+			}
+		})
+	}
+}
+
 func TestIsChannelClosed(t *testing.T) {
 	ch := make(chan struct{})
 
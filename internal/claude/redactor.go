@@ -34,3 +34,11 @@ func (r *Redactor) Redact(text string) string {
 	}
 	return text
 }
+
+// Add registers an additional secret value to scrub. Empty values are ignored.
+func (r *Redactor) Add(value string) {
+	if value == "" {
+		return
+	}
+	r.secretValues = append(r.secretValues, value)
+}
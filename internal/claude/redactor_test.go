@@ -121,3 +121,16 @@ func TestNewRedactor_AllKnownVarsRecognised(t *testing.T) {
 		})
 	}
 }
+
+func TestRedactor_Add(t *testing.T) {
+	r := &Redactor{}
+	r.Add("")
+	r.Add("ghs_minted")
+
+	if len(r.secretValues) != 1 {
+		t.Fatalf("expected 1 secret, got %d", len(r.secretValues))
+	}
+	if got := r.Redact("token ghs_minted here"); got != "token [REDACTED] here" {
+		t.Errorf("Redact() = %q", got)
+	}
+}
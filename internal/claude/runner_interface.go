@@ -20,6 +20,10 @@ type RunnerConfig interface {
 	SetSystemPrompt(prompt string)
 	SetHostTools(hostTools bool)
 	SetModel(model string)
+	GetModel() string
+	SetContainerEnv(env []string)
+	SetContainerNetwork(network string)
+	SetContainerResources(resources ContainerResources)
 }
 
 // RunnerSession is the interface for interacting with an active Claude session.
@@ -54,6 +58,8 @@ type RunnerSession interface {
 	SendCommentIssueResponse(resp mcp.CommentIssueResponse)
 	SubmitReviewRequestChan() <-chan mcp.SubmitReviewRequest
 	SendSubmitReviewResponse(resp mcp.SubmitReviewResponse)
+	SubmitResultRequestChan() <-chan mcp.SubmitResultRequest
+	SendSubmitResultResponse(resp mcp.SubmitResultResponse)
 
 	// Lifecycle
 	Stop()
@@ -23,6 +23,7 @@ type MCPChannels struct {
 	GetReviewComments *mcp.ChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse]
 	CommentIssue      *mcp.ChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse]
 	SubmitReview      *mcp.ChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse]
+	SubmitResult      *mcp.ChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse]
 }
 
 // NewMCPChannels creates a new MCPChannels with buffered channels.
@@ -42,6 +43,7 @@ func (m *MCPChannels) InitHostToolChannels() {
 	m.GetReviewComments = mcp.NewChannelPair[mcp.GetReviewCommentsRequest, mcp.GetReviewCommentsResponse](PermissionChannelBuffer)
 	m.CommentIssue = mcp.NewChannelPair[mcp.CommentIssueRequest, mcp.CommentIssueResponse](PermissionChannelBuffer)
 	m.SubmitReview = mcp.NewChannelPair[mcp.SubmitReviewRequest, mcp.SubmitReviewResponse](PermissionChannelBuffer)
+	m.SubmitResult = mcp.NewChannelPair[mcp.SubmitResultRequest, mcp.SubmitResultResponse](PermissionChannelBuffer)
 }
 
 // Close closes all channels. Safe to call multiple times.
@@ -54,6 +56,7 @@ func (m *MCPChannels) Close() {
 	m.GetReviewComments.Close()
 	m.CommentIssue.Close()
 	m.SubmitReview.Close()
+	m.SubmitResult.Close()
 }
 
 // StreamingState tracks state during response streaming.
@@ -4,10 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/mcp"
 )
 
@@ -156,11 +156,17 @@ func (r *Runner) handleProcessLine(line string) {
 			r.sessionStarted = true
 			r.streaming.Complete = true
 			pm := r.processManager
+			conversationDir := r.conversationDir
 			r.mu.Unlock()
 			if pm != nil {
 				pm.MarkSessionStarted()
 				pm.ResetRestartAttempts()
 			}
+			if conversationDir != "" {
+				if err := checkpointConversation(conversationDir, r.sessionID); err != nil {
+					r.log.Warn("failed to checkpoint conversation", "error", err)
+				}
+			}
 			r.mu.Lock()
 
 			// Determine error message
@@ -186,11 +192,21 @@ func (r *Runner) handleProcessLine(line string) {
 				}
 			}
 
-			// Log permission denials if any
+			// Log permission denials if any, and note them in the transcript
+			// so a refused command (e.g. by the repo's tool policy) is visible
+			// alongside the work it interrupted.
 			if len(msg.PermissionDenials) > 0 {
 				r.log.Warn("permission denials in result", "count", len(msg.PermissionDenials))
 				for _, d := range msg.PermissionDenials {
 					r.log.Warn("permission denied", "tool", d.Tool, "description", d.Description, "reason", d.Reason)
+					if ch != nil && !r.responseChan.Closed {
+						denialMsg := fmt.Sprintf("\n[Permission denied: %s]\n", formatPermissionDenial(d))
+						r.streaming.Response.WriteString(denialMsg)
+						select {
+						case ch <- ResponseChunk{Type: ChunkTypeText, Content: denialMsg}:
+						default:
+						}
+					}
 				}
 			}
 
@@ -389,7 +405,7 @@ func (r *Runner) connectToContainerMCP() {
 			return
 		}
 
-		out, err := exec.Command("docker", "port", containerName, portSpec).Output()
+		out, err := runtimeCommand("port", containerName, portSpec).Output()
 		if err == nil {
 			line := strings.TrimSpace(string(out))
 			if idx := strings.Index(line, "\n"); idx >= 0 {
@@ -416,7 +432,7 @@ func (r *Runner) connectToContainerMCP() {
 	}
 
 	// Step 2: Connect and handle messages
-	addr := "localhost:" + hostPort
+	addr := container.PublishedPortHost() + ":" + hostPort
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		r.mu.RLock()
 		stopped := r.stopped
@@ -21,6 +21,8 @@ type Config struct {
 	RepoSquashOnMerge map[string]bool        `json:"repo_squash_on_merge,omitempty"` // Per-repo squash-on-merge setting
 	RepoAsanaProject  map[string]string      `json:"repo_asana_project,omitempty"`   // Per-repo Asana project GID mapping
 	RepoLinearTeam    map[string]string      `json:"repo_linear_team,omitempty"`     // Per-repo Linear team ID mapping
+	RepoGitLabProject map[string]string      `json:"repo_gitlab_project,omitempty"`  // Per-repo GitLab project ID/path mapping
+	RepoClickUpList   map[string]string      `json:"repo_clickup_list,omitempty"`    // Per-repo ClickUp list ID mapping
 	ContainerImage    string                 `json:"container_image,omitempty"`      // Container image for containerized sessions
 
 	WelcomeShown         bool   `json:"welcome_shown,omitempty"`         // Whether welcome modal has been shown
@@ -124,6 +126,12 @@ func (c *Config) ensureInitialized() {
 	if c.RepoLinearTeam == nil {
 		c.RepoLinearTeam = make(map[string]string)
 	}
+	if c.RepoGitLabProject == nil {
+		c.RepoGitLabProject = make(map[string]string)
+	}
+	if c.RepoClickUpList == nil {
+		c.RepoClickUpList = make(map[string]string)
+	}
 }
 
 // Validate checks that the config is internally consistent.
@@ -456,6 +464,68 @@ func (c *Config) HasLinearTeam(repoPath string) bool {
 	return c.GetLinearTeam(repoPath) != ""
 }
 
+// GetGitLabProject returns the GitLab project ID or path for a repo, or empty string if not configured
+func (c *Config) GetGitLabProject(repoPath string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.RepoGitLabProject == nil {
+		return ""
+	}
+	resolved := resolveRepoPath(c.Repos, repoPath)
+	return c.RepoGitLabProject[resolved]
+}
+
+// SetGitLabProject sets the GitLab project ID or path for a repo
+func (c *Config) SetGitLabProject(repoPath, project string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.RepoGitLabProject == nil {
+		c.RepoGitLabProject = make(map[string]string)
+	}
+	resolved := resolveRepoPath(c.Repos, repoPath)
+	if project == "" {
+		delete(c.RepoGitLabProject, resolved)
+	} else {
+		c.RepoGitLabProject[resolved] = project
+	}
+}
+
+// HasGitLabProject returns true if the repo has a GitLab project configured
+func (c *Config) HasGitLabProject(repoPath string) bool {
+	return c.GetGitLabProject(repoPath) != ""
+}
+
+// GetClickUpList returns the ClickUp list ID for a repo, or empty string if not configured
+func (c *Config) GetClickUpList(repoPath string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.RepoClickUpList == nil {
+		return ""
+	}
+	resolved := resolveRepoPath(c.Repos, repoPath)
+	return c.RepoClickUpList[resolved]
+}
+
+// SetClickUpList sets the ClickUp list ID for a repo
+func (c *Config) SetClickUpList(repoPath, listID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.RepoClickUpList == nil {
+		c.RepoClickUpList = make(map[string]string)
+	}
+	resolved := resolveRepoPath(c.Repos, repoPath)
+	if listID == "" {
+		delete(c.RepoClickUpList, resolved)
+	} else {
+		c.RepoClickUpList[resolved] = listID
+	}
+}
+
+// HasClickUpList returns true if the repo has a ClickUp list configured
+func (c *Config) HasClickUpList(repoPath string) bool {
+	return c.GetClickUpList(repoPath) != ""
+}
+
 // GetContainerImage returns the container image name, defaulting to "ghcr.io/zhubert/erg"
 func (c *Config) GetContainerImage() string {
 	c.mu.RLock()
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -34,12 +35,18 @@ func isValidRustVersion(v string) bool {
 
 // defaultVersions are used when a version cannot be parsed from the repo.
 var defaultVersions = map[Language]string{
-	LangGo:     "1.23",
-	LangNode:   "20",
-	LangPython: "3.12",
-	LangRuby:   "3.3",
-	LangRust:   "1.77",
-	LangJava:   "21",
+	LangGo:        "1.23",
+	LangNode:      "20",
+	LangPython:    "3.12",
+	LangRuby:      "3.3",
+	LangRust:      "1.77",
+	LangJava:      "21",
+	LangDotNet:    "8.0",
+	LangElixir:    "1.17",
+	LangSwift:     "5.10",
+	LangZig:       "0.13.0",
+	LangTerraform: "1.9.8",
+	LangCUDA:      DefaultCUDAVersion,
 }
 
 // goArch returns the Go/Docker architecture string for the current platform.
@@ -52,6 +59,16 @@ func goArch() string {
 	}
 }
 
+// zigArch returns the Zig release architecture for the current platform.
+func zigArch() string {
+	switch runtime.GOARCH {
+	case "arm64":
+		return "aarch64"
+	default:
+		return "x86_64"
+	}
+}
+
 // releaseArch returns the goreleaser archive architecture suffix.
 func releaseArch() string {
 	switch runtime.GOARCH {
@@ -85,16 +102,56 @@ func GenerateDockerfile(langs []DetectedLang, version, devBinaryHash string) (st
 		return "", fmt.Errorf("invalid node version %q", nodeVersion)
 	}
 
-	// Base layer: node Alpine image + essential tools + Claude Code.
-	// Alpine is significantly smaller than Ubuntu (~5MB vs ~80MB base).
-	// Node.js is always required for Claude Code, so node:XX-alpine is the natural base.
-	fmt.Fprintf(&b, "FROM node:%s-alpine\n", nodeVersion)
-	b.WriteString("RUN apk add --no-cache git curl ca-certificates build-base gnupg bash\n")
+	// Swift's toolchain and ML frameworks' wheels need glibc, and GPU
+	// sessions need NVIDIA's CUDA libraries, so those repos build on a
+	// Debian-based image: the official Swift or CUDA (Ubuntu) image with
+	// Node copied in from the Debian node image, or that node image itself.
+	debian := false
+	var swiftVersion, cudaVersion string
+	for _, l := range langs {
+		v := l.Version
+		if v == "" {
+			v = defaultVersions[l.Lang]
+		}
+		switch l.Lang {
+		case LangSwift:
+			swiftVersion = v
+		case LangCUDA:
+			cudaVersion = v
+		case LangML:
+			debian = true
+			continue
+		default:
+			continue
+		}
+		if !isValidVersion(v) {
+			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+		}
+	}
+	switch {
+	case swiftVersion != "" && cudaVersion != "":
+		return "", fmt.Errorf("swift repos can't use GPU (CUDA) images")
+	case swiftVersion != "":
+		writeDebianBase(&b, "swift:"+swiftVersion, nodeVersion)
+		debian = true
+	case cudaVersion != "":
+		writeDebianBase(&b, "nvidia/cuda:"+cudaVersion+"-cudnn-runtime-ubuntu22.04", nodeVersion)
+		debian = true
+	case debian:
+		fmt.Fprintf(&b, "FROM node:%s-bookworm-slim\n", nodeVersion)
+		b.WriteString(debianToolsInstall)
+	default:
+		// Base layer: node Alpine image + essential tools + Claude Code.
+		// Alpine is significantly smaller than Ubuntu (~5MB vs ~80MB base).
+		// Node.js is always required for Claude Code, so node:XX-alpine is the natural base.
+		fmt.Fprintf(&b, "FROM node:%s-alpine\n", nodeVersion)
+		b.WriteString("RUN apk add --no-cache git curl ca-certificates build-base gnupg bash\n")
+	}
 	b.WriteString("RUN npm install -g @anthropic-ai/claude-code\n")
 
 	// Add language-specific install blocks
 	for _, l := range langs {
-		block, err := languageInstallBlock(l)
+		block, err := languageInstallBlock(l, debian)
 		if err != nil {
 			return "", err
 		}
@@ -113,6 +170,41 @@ func GenerateDockerfile(langs []DetectedLang, version, devBinaryHash string) (st
 	}
 
 	// Install the erg binary.
+	b.WriteString(ergInstallBlock(version, devBinaryHash))
+
+	// Entrypoint script: install latest Claude Code on boot (keeps cached image fresh),
+	// then exec into claude with all original arguments.
+	// Uses `npm install @latest` instead of `npm update` because npm update respects
+	// semver ranges and won't cross major version boundaries.
+	// Redirects both stdout and stderr to avoid polluting the JSON stream.
+	// Checks ERG_SKIP_UPDATE to allow developers to skip the update.
+	b.WriteString("RUN printf '#!/bin/sh\\nif [ -z \"$ERG_SKIP_UPDATE\" ]; then npm install -g @anthropic-ai/claude-code@latest >/dev/null 2>&1; fi\\nexec claude \"$@\"\\n' > /usr/local/bin/entrypoint.sh \\\n")
+	b.WriteString("    && chmod +x /usr/local/bin/entrypoint.sh\n")
+	b.WriteString("ENTRYPOINT [\"/usr/local/bin/entrypoint.sh\"]\n")
+
+	return b.String(), nil
+}
+
+// debianToolsInstall installs the base layer's essential tools on a
+// Debian-based image.
+const debianToolsInstall = "RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends git curl ca-certificates build-essential gnupg bash unzip xz-utils \\\n" +
+	"    && rm -rf /var/lib/apt/lists/*\n"
+
+// writeDebianBase writes a base layer built on the Debian-based image from,
+// with the essential tools and Node copied in from the Debian node image.
+func writeDebianBase(b *strings.Builder, from, nodeVersion string) {
+	fmt.Fprintf(b, "FROM %s\n", from)
+	b.WriteString(debianToolsInstall)
+	fmt.Fprintf(b, "COPY --from=node:%s-bookworm-slim /usr/local/bin/node /usr/local/bin/node\n", nodeVersion)
+	fmt.Fprintf(b, "COPY --from=node:%s-bookworm-slim /usr/local/lib/node_modules /usr/local/lib/node_modules\n", nodeVersion)
+	b.WriteString("RUN ln -s ../lib/node_modules/npm/bin/npm-cli.js /usr/local/bin/npm && ln -s ../lib/node_modules/npm/bin/npx-cli.js /usr/local/bin/npx\n")
+}
+
+// ergInstallBlock returns the Dockerfile instructions installing the erg
+// binary as /usr/local/bin/erg: COPYed from the build context for dev builds
+// (devBinaryHash set), otherwise downloaded from the GitHub release.
+func ergInstallBlock(version, devBinaryHash string) string {
+	var b strings.Builder
 	if devBinaryHash != "" {
 		// Dev mode: COPY the cross-compiled binary from the build context.
 		// The label makes the Dockerfile text unique per binary, busting the ImageTag cache.
@@ -129,22 +221,24 @@ func GenerateDockerfile(langs []DetectedLang, version, devBinaryHash string) (st
 			" | tar -xz -C /tmp && mv /tmp/erg /usr/local/bin/erg\n",
 			releaseArch())
 	}
-	// Entrypoint script: install latest Claude Code on boot (keeps cached image fresh),
-	// then exec into claude with all original arguments.
-	// Uses `npm install @latest` instead of `npm update` because npm update respects
-	// semver ranges and won't cross major version boundaries.
-	// Redirects both stdout and stderr to avoid polluting the JSON stream.
-	// Checks ERG_SKIP_UPDATE to allow developers to skip the update.
-	b.WriteString("RUN printf '#!/bin/sh\\nif [ -z \"$ERG_SKIP_UPDATE\" ]; then npm install -g @anthropic-ai/claude-code@latest >/dev/null 2>&1; fi\\nexec claude \"$@\"\\n' > /usr/local/bin/entrypoint.sh \\\n")
-	b.WriteString("    && chmod +x /usr/local/bin/entrypoint.sh\n")
-	b.WriteString("ENTRYPOINT [\"/usr/local/bin/entrypoint.sh\"]\n")
+	return b.String()
+}
 
-	return b.String(), nil
+// pkgInstall returns a RUN instruction installing packages with the base
+// image's package manager: apk on the Alpine base, apt-get on the Debian-based
+// one.
+func pkgInstall(debian bool, apkPkgs, aptPkgs string) string {
+	if debian {
+		return "RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends " + aptPkgs + " && rm -rf /var/lib/apt/lists/*\n"
+	}
+	return "RUN apk add --no-cache " + apkPkgs + "\n"
 }
 
-// languageInstallBlock returns the Dockerfile RUN instruction for a language.
-// Returns empty string if the language is handled in the base layer (Node) or unknown.
-func languageInstallBlock(l DetectedLang) (string, error) {
+// languageInstallBlock returns the Dockerfile RUN instruction for a language,
+// using apt-get instead of apk when debian is set (see GenerateDockerfile).
+// Returns empty string if the language is handled in the base layer (Node,
+// Swift) or unknown.
+func languageInstallBlock(l DetectedLang, debian bool) (string, error) {
 	v := l.Version
 	if v == "" {
 		v = defaultVersions[l.Lang]
@@ -164,13 +258,17 @@ func languageInstallBlock(l DetectedLang) (string, error) {
 			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
 		}
 		// Build dependencies only — mise handles the actual Ruby install.
-		return "RUN apk add --no-cache autoconf bison openssl-dev yaml-dev readline-dev zlib-dev libffi-dev linux-headers\n", nil
+		return pkgInstall(debian,
+			"autoconf bison openssl-dev yaml-dev readline-dev zlib-dev libffi-dev linux-headers",
+			"autoconf bison libssl-dev libyaml-dev libreadline-dev zlib1g-dev libffi-dev"), nil
 	case LangPython:
 		if !isValidVersion(v) {
 			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
 		}
 		// Build dependencies only — mise handles the actual Python install.
-		return "RUN apk add --no-cache libffi-dev openssl-dev bzip2-dev xz-dev readline-dev sqlite-dev\n", nil
+		return pkgInstall(debian,
+			"libffi-dev openssl-dev bzip2-dev xz-dev readline-dev sqlite-dev",
+			"libffi-dev libssl-dev libbz2-dev liblzma-dev libreadline-dev libsqlite3-dev"), nil
 	case LangRust:
 		if !isValidRustVersion(v) {
 			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
@@ -183,17 +281,56 @@ func languageInstallBlock(l DetectedLang) (string, error) {
 		if !isValidVersion(v) {
 			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
 		}
-		return fmt.Sprintf(""+
-			"RUN apk add --no-cache openjdk%s-jdk\n",
-			v), nil
+		return pkgInstall(debian, fmt.Sprintf("openjdk%s-jdk", v), fmt.Sprintf("openjdk-%s-jdk-headless", v)), nil
 	case LangPHP:
+		if debian {
+			return "" +
+				"RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends php-cli php-mbstring php-xml \\\n" +
+				"    && rm -rf /var/lib/apt/lists/* \\\n" +
+				"    && curl -fsSL https://getcomposer.org/installer | php -- --install-dir=/usr/local/bin --filename=composer\n", nil
+		}
 		// Alpine uses versioned PHP package names (e.g., php83, php83-cli).
 		// Default to PHP 8.3; php83-phar and php83-openssl are required by Composer.
 		return "" +
 			"RUN apk add --no-cache php83 php83-cli php83-mbstring php83-xml php83-phar php83-openssl \\\n" +
 			"    && ln -s /usr/bin/php83 /usr/bin/php \\\n" +
 			"    && curl -fsSL https://getcomposer.org/installer | php -- --install-dir=/usr/local/bin --filename=composer\n", nil
-	case LangNode:
+	case LangCpp:
+		// The compilers come with the base layer; add CMake and Ninja.
+		return pkgInstall(debian, "cmake samurai pkgconf", "cmake ninja-build pkg-config"), nil
+	case LangDotNet:
+		if !isValidVersion(v) {
+			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+		}
+		// dotnet-install picks the musl or glibc build for the base image.
+		return pkgInstall(debian, "icu-libs libgcc libstdc++ krb5-libs zlib", "libicu-dev zlib1g") + fmt.Sprintf(""+
+			"RUN curl -fsSL https://dot.net/v1/dotnet-install.sh | bash -s -- --channel %s --install-dir /usr/share/dotnet \\\n"+
+			"    && ln -s /usr/share/dotnet/dotnet /usr/local/bin/dotnet\n"+
+			"ENV DOTNET_ROOT=/usr/share/dotnet DOTNET_CLI_TELEMETRY_OPTOUT=1\n",
+			v), nil
+	case LangElixir:
+		if !isValidVersion(v) {
+			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+		}
+		// Erlang/OTP only — mise handles the actual Elixir install.
+		return pkgInstall(debian, "erlang erlang-dev", "erlang-nox erlang-dev"), nil
+	case LangZig:
+		if !isValidVersion(v) {
+			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+		}
+		return pkgInstall(debian, "xz", "xz-utils") + fmt.Sprintf(""+
+			"RUN mkdir -p /usr/local/zig && curl -fsSL https://ziglang.org/download/%s/%s.tar.xz | tar -xJ -C /usr/local/zig --strip-components=1\n"+
+			"ENV PATH=\"/usr/local/zig:${PATH}\"\n",
+			v, zigArchive(v)), nil
+	case LangTerraform:
+		if !isValidVersion(v) {
+			return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+		}
+		return pkgInstall(debian, "unzip", "unzip") + fmt.Sprintf(""+
+			"RUN curl -fsSL https://releases.hashicorp.com/terraform/%s/terraform_%s_linux_%s.zip -o /tmp/terraform.zip \\\n"+
+			"    && unzip -o /tmp/terraform.zip terraform -d /usr/local/bin && rm /tmp/terraform.zip\n",
+			v, v, goArch()), nil
+	case LangNode, LangSwift, LangML, LangCUDA:
 		// Handled in base layer
 		return "", nil
 	default:
@@ -201,9 +338,20 @@ func languageInstallBlock(l DetectedLang) (string, error) {
 	}
 }
 
-// miseInstallBlock generates Dockerfile instructions to install Ruby and/or Python
-// via mise (https://mise.jdx.dev). Returns empty string if no mise-managed languages
-// are present. Mise provides reproducible, version-pinned installs without the
+// zigArchive returns the name of the Zig release archive for version, which
+// changed from zig-linux-<arch>-<v> to zig-<arch>-linux-<v> in 0.14.1.
+func zigArchive(version string) string {
+	var major, minor, patch int
+	fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch)
+	if major > 0 || minor > 14 || (minor == 14 && patch >= 1) {
+		return fmt.Sprintf("zig-%s-linux-%s", zigArch(), version)
+	}
+	return fmt.Sprintf("zig-linux-%s-%s", zigArch(), version)
+}
+
+// miseInstallBlock generates Dockerfile instructions to install Ruby, Python and/or
+// Elixir via mise (https://mise.jdx.dev). Returns empty string if no mise-managed
+// languages are present. Mise provides reproducible, version-pinned installs without the
 // complexity of ruby-install/pyenv.
 func miseInstallBlock(langs []DetectedLang) (string, error) {
 	type miseEntry struct {
@@ -227,6 +375,11 @@ func miseInstallBlock(langs []DetectedLang) (string, error) {
 				return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
 			}
 			entries = append(entries, miseEntry{tool: "python", version: v})
+		case LangElixir:
+			if !isValidVersion(v) {
+				return "", fmt.Errorf("invalid version string %q for language %s", v, l.Lang)
+			}
+			entries = append(entries, miseEntry{tool: "elixir", version: v})
 		}
 	}
 	if len(entries) == 0 {
@@ -243,7 +396,7 @@ func miseInstallBlock(langs []DetectedLang) (string, error) {
 	s := b.String()
 	s = strings.TrimSuffix(s, " \\\n") + "\n"
 
-	// Add mise shims to PATH so Ruby/Python are available without eval
+	// Add mise shims to PATH so Ruby/Python/Elixir are available without eval
 	s += "ENV PATH=\"/root/.local/share/mise/shims:/root/.local/bin:${PATH}\"\n"
 	return s, nil
 }
@@ -305,11 +458,12 @@ func findModuleRoot(dir string) (string, error) {
 	}
 }
 
-// dockerCommandFunc is the function used to execute docker commands. Overridden in tests.
+// dockerCommandFunc is the function used to execute container runtime
+// commands. Overridden in tests.
 var dockerCommandFunc = dockerCommand
 
 func dockerCommand(ctx context.Context, stdin string, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd := CurrentRuntime().Command(ctx, args...)
 	if stdin != "" {
 		cmd.Stdin = strings.NewReader(stdin)
 	}
@@ -322,53 +476,53 @@ func dockerCommand(ctx context.Context, stdin string, args ...string) ([]byte, e
 	return stdout.Bytes(), nil
 }
 
+// dockerStreamFunc is dockerCommand for input and output too large to hold
+// in memory: the command reads stdin and writes stdout as it runs, and
+// either may be nil. Overridden in tests.
+var dockerStreamFunc = dockerStream
+
+func dockerStream(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	cmd := CurrentRuntime().Command(ctx, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return nil
+}
+
 // EnsureImage generates a Dockerfile for the detected languages, builds it if
 // not already cached, and returns the image tag plus whether a build was needed.
 // For dev builds, the local erg binary is cross-compiled for Linux and COPYed
 // into the image. For release builds, the binary is downloaded from GitHub.
 func EnsureImage(ctx context.Context, langs []DetectedLang, version string, logger *slog.Logger) (string, bool, error) {
-	var devBinaryHash string
-	var buildContextDir string
+	return EnsureImageFrom(ctx, langs, version, nil, logger)
+}
 
-	// For dev builds, try to cross-compile the local binary.
-	if version == "dev" {
-		tmpDir, err := os.MkdirTemp("", "erg-dev-build-*")
-		if err != nil {
-			logger.Warn("failed to create temp dir for dev build, falling back to release download", "error", err)
-		} else {
-			binaryPath := filepath.Join(tmpDir, "erg")
-			if err := crossCompileFunc(binaryPath); err != nil {
-				logger.Warn("cross-compilation failed, falling back to release download", "error", err)
-				os.RemoveAll(tmpDir)
-			} else {
-				data, err := os.ReadFile(binaryPath)
-				if err != nil {
-					logger.Warn("failed to read cross-compiled binary, falling back to release download", "error", err)
-					os.RemoveAll(tmpDir)
-				} else {
-					h := sha256.Sum256(data)
-					devBinaryHash = fmt.Sprintf("%x", h[:16])
-					buildContextDir = tmpDir
-					defer os.RemoveAll(tmpDir)
-					logger.Info("cross-compiled dev binary for container", "hash", devBinaryHash)
-				}
-			}
-		}
-	}
+// EnsureImageFrom is EnsureImage that first looks for a prebuilt image of
+// the same fingerprint in reg, when set, and pushes the image there after a
+// local build if reg allows. Dev builds carry a local erg binary no registry
+// has, so they always build locally. In offline mode the registry is not
+// asked.
+func EnsureImageFrom(ctx context.Context, langs []DetectedLang, version string, reg *Registry, logger *slog.Logger) (string, bool, error) {
+	devBinaryHash, buildContextDir, cleanup := prepareDevBinary(version, logger)
+	defer cleanup()
 
 	dockerfile, err := GenerateDockerfile(langs, version, devBinaryHash)
 	if err != nil {
 		return "", false, fmt.Errorf("invalid language version: %w", err)
 	}
-	tag := ImageTag(dockerfile)
 
-	// Check if image already exists (cached)
-	if _, err := dockerCommandFunc(ctx, "", "image", "inspect", tag); err == nil {
-		logger.Info("using cached container image", "image", tag)
-		return tag, false, nil
+	var ref string
+	if reg != nil && reg.Repository != "" && devBinaryHash == "" && !Offline() {
+		ref = reg.Ref(Fingerprint(langs, version))
+		if tag, ok := pullPrebuiltImage(ctx, ref, ImageTag(dockerfile), logger); ok {
+			return tag, false, nil
+		}
 	}
 
-	// Build the image
 	langNames := make([]string, len(langs))
 	for i, l := range langs {
 		if l.Version != "" {
@@ -377,7 +531,62 @@ func EnsureImage(ctx context.Context, langs []DetectedLang, version string, logg
 			langNames[i] = string(l.Lang)
 		}
 	}
-	logger.Info("building container image", "image", tag, "languages", strings.Join(langNames, ", "))
+	tag, built, err := buildImage(ctx, dockerfile, buildContextDir, logger, "languages", strings.Join(langNames, ", "))
+	if err == nil && built && ref != "" && reg.Push {
+		pushPrebuiltImage(ctx, tag, ref, logger)
+	}
+	return tag, built, err
+}
+
+// prepareDevBinary cross-compiles the local erg binary for dev builds and
+// returns its hash and the build context directory holding it, plus a func
+// removing that directory. For release builds, or when cross-compiling
+// fails, the hash and directory are empty and the image downloads erg.
+func prepareDevBinary(version string, logger *slog.Logger) (string, string, func()) {
+	if version != "dev" {
+		return "", "", func() {}
+	}
+	tmpDir, err := os.MkdirTemp("", "erg-dev-build-*")
+	if err != nil {
+		logger.Warn("failed to create temp dir for dev build, falling back to release download", "error", err)
+		return "", "", func() {}
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+	binaryPath := filepath.Join(tmpDir, "erg")
+	if err := crossCompileFunc(binaryPath); err != nil {
+		logger.Warn("cross-compilation failed, falling back to release download", "error", err)
+		cleanup()
+		return "", "", func() {}
+	}
+	data, err := os.ReadFile(binaryPath)
+	if err != nil {
+		logger.Warn("failed to read cross-compiled binary, falling back to release download", "error", err)
+		cleanup()
+		return "", "", func() {}
+	}
+	h := sha256.Sum256(data)
+	devBinaryHash := fmt.Sprintf("%x", h[:16])
+	logger.Info("cross-compiled dev binary for container", "hash", devBinaryHash)
+	return devBinaryHash, tmpDir, cleanup
+}
+
+// buildImage builds dockerfile unless its image is already cached and
+// returns the image tag plus whether a build was needed. logArgs describe
+// what the image is built from in the build log line. In offline mode, where
+// the build would download toolchains, an image not cached is an error.
+func buildImage(ctx context.Context, dockerfile, buildContextDir string, logger *slog.Logger, logArgs ...any) (string, bool, error) {
+	tag := ImageTag(dockerfile)
+
+	// Check if image already exists (cached)
+	if _, err := dockerCommandFunc(ctx, "", "image", "inspect", tag); err == nil {
+		logger.Info("using cached container image", "image", tag)
+		return tag, false, nil
+	}
+	if Offline() {
+		return "", false, fmt.Errorf("%w: container image %s is not cached locally; build it while online, or load it from an archive with docker load", ErrOffline, tag)
+	}
+
+	logger.Info("building container image", append([]any{"image", tag}, logArgs...)...)
 
 	// Ensure we have a build context directory. Dev builds already have one
 	// (with the cross-compiled binary). Non-dev builds don't COPY anything,
@@ -392,9 +601,8 @@ func EnsureImage(ctx context.Context, langs []DetectedLang, version string, logg
 		buildContextDir = emptyDir
 	}
 
-	_, err = dockerCommandFunc(ctx, dockerfile, "build", "-t", tag, "-f-", buildContextDir)
-	if err != nil {
-		return "", false, fmt.Errorf("docker build failed: %w", err)
+	if _, err := dockerCommandFunc(ctx, dockerfile, "build", "-t", tag, "-f-", buildContextDir); err != nil {
+		return "", false, fmt.Errorf("%s build failed: %w", CurrentRuntime().Name(), err)
 	}
 
 	logger.Info("container image built successfully", "image", tag)
@@ -841,7 +841,7 @@ func TestLanguageInstallBlock_RejectsInvalidVersion(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := languageInstallBlock(tt.lang)
+			_, err := languageInstallBlock(tt.lang, false)
 			if err == nil {
 				t.Errorf("expected error for invalid version %q, got nil", tt.lang.Version)
 			}
@@ -866,3 +866,109 @@ func TestMiseInstallBlock_RejectsInvalidVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateDockerfile_NewToolchains(t *testing.T) {
+	tests := []struct {
+		lang DetectedLang
+		want []string
+	}{
+		{DetectedLang{Lang: LangCpp}, []string{"apk add --no-cache cmake samurai pkgconf"}},
+		{DetectedLang{Lang: LangDotNet, Version: "9.0"}, []string{"dotnet-install.sh | bash -s -- --channel 9.0", "icu-libs", "DOTNET_ROOT=/usr/share/dotnet"}},
+		{DetectedLang{Lang: LangElixir, Version: "1.16"}, []string{"apk add --no-cache erlang erlang-dev", "mise use -g elixir@1.16"}},
+		{DetectedLang{Lang: LangZig, Version: "0.13.0"}, []string{"https://ziglang.org/download/0.13.0/zig-linux-" + zigArch() + "-0.13.0.tar.xz"}},
+		{DetectedLang{Lang: LangZig, Version: "0.14.1"}, []string{"https://ziglang.org/download/0.14.1/zig-" + zigArch() + "-linux-0.14.1.tar.xz"}},
+		{DetectedLang{Lang: LangTerraform}, []string{"releases.hashicorp.com/terraform/1.9.8/terraform_1.9.8_linux_" + goArch() + ".zip", "apk add --no-cache unzip"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.lang.Lang)+tt.lang.Version, func(t *testing.T) {
+			df, err := GenerateDockerfile([]DetectedLang{tt.lang}, "0.1.0", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(df, "FROM node:") || !strings.Contains(df, "-alpine") {
+				t.Errorf("expected the Alpine node base, got:\n%s", df)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(df, w) {
+					t.Errorf("expected %q in Dockerfile, got:\n%s", w, df)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDockerfile_SwiftUsesSwiftBase(t *testing.T) {
+	df, err := GenerateDockerfile([]DetectedLang{
+		{Lang: LangSwift, Version: "5.9"},
+		{Lang: LangPython, Version: "3.12"},
+	}, "0.1.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(df, "FROM swift:5.9\n") {
+		t.Errorf("expected the Swift base image, got:\n%s", df)
+	}
+	if strings.Contains(df, "apk ") {
+		t.Errorf("expected no apk on the Ubuntu-based Swift image, got:\n%s", df)
+	}
+	for _, w := range []string{
+		"COPY --from=node:20-bookworm-slim /usr/local/bin/node",
+		"npm install -g @anthropic-ai/claude-code",
+		"apt-get install -y --no-install-recommends libffi-dev libssl-dev",
+	} {
+		if !strings.Contains(df, w) {
+			t.Errorf("expected %q in Dockerfile, got:\n%s", w, df)
+		}
+	}
+}
+
+func TestGenerateDockerfile_MLUsesDebianBase(t *testing.T) {
+	df, err := GenerateDockerfile([]DetectedLang{{Lang: LangPython, Version: "3.11"}, {Lang: LangML}}, "0.1.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(df, "FROM node:20-bookworm-slim\n") {
+		t.Errorf("expected the Debian node base image, got:\n%s", df)
+	}
+	if strings.Contains(df, "apk ") || strings.Contains(df, "COPY --from=node") {
+		t.Errorf("expected apt-get on the node image itself, got:\n%s", df)
+	}
+	if !strings.Contains(df, "mise install python@3.11") {
+		t.Errorf("expected Python installed, got:\n%s", df)
+	}
+}
+
+func TestGenerateDockerfile_CUDAUsesNvidiaBase(t *testing.T) {
+	df, err := GenerateDockerfile([]DetectedLang{{Lang: LangPython}, {Lang: LangML}, {Lang: LangCUDA}}, "0.1.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(df, "FROM nvidia/cuda:"+DefaultCUDAVersion+"-cudnn-runtime-ubuntu22.04\n") {
+		t.Errorf("expected the CUDA base image, got:\n%s", df)
+	}
+	if !strings.Contains(df, "COPY --from=node:20-bookworm-slim /usr/local/bin/node") {
+		t.Errorf("expected Node copied in, got:\n%s", df)
+	}
+
+	if _, err := GenerateDockerfile([]DetectedLang{{Lang: LangCUDA, Version: "12.x"}}, "0.1.0", ""); err == nil {
+		t.Error("expected an invalid CUDA version rejected")
+	}
+	if _, err := GenerateDockerfile([]DetectedLang{{Lang: LangSwift}, {Lang: LangCUDA}}, "0.1.0", ""); err == nil {
+		t.Error("expected Swift with CUDA rejected")
+	}
+}
+
+func TestZigArchive(t *testing.T) {
+	arch := zigArch()
+	tests := map[string]string{
+		"0.11.0": "zig-linux-" + arch + "-0.11.0",
+		"0.14.0": "zig-linux-" + arch + "-0.14.0",
+		"0.14.1": "zig-" + arch + "-linux-0.14.1",
+		"0.15.2": "zig-" + arch + "-linux-0.15.2",
+	}
+	for v, want := range tests {
+		if got := zigArchive(v); got != want {
+			t.Errorf("zigArchive(%q) = %q, want %q", v, got, want)
+		}
+	}
+}
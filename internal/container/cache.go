@@ -0,0 +1,177 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CacheVolumePrefix starts the name of every dependency cache volume.
+const CacheVolumePrefix = "erg-cache-"
+
+// dependencyCache is a package manager's download cache kept in a named
+// volume per repo, so repeated sessions don't download dependencies again.
+// Tools whose cache has no fixed path under the (root) session user's home
+// are pointed at the volume through env.
+type dependencyCache struct {
+	name string
+	dir  string
+	env  []string
+}
+
+var dependencyCaches = []dependencyCache{
+	{"go", "/erg-cache/go", []string{"GOMODCACHE=/erg-cache/go/mod", "GOCACHE=/erg-cache/go/build"}},
+	{"npm", "/erg-cache/npm", []string{"npm_config_cache=/erg-cache/npm"}},
+	{"pip", "/erg-cache/pip", []string{"PIP_CACHE_DIR=/erg-cache/pip"}},
+	// Only cargo's download caches: ~/.cargo/bin holds the toolchain.
+	{"cargo-registry", "/root/.cargo/registry", nil},
+	{"cargo-git", "/root/.cargo/git", nil},
+	{"gradle", "/erg-cache/gradle", []string{"GRADLE_USER_HOME=/erg-cache/gradle"}},
+}
+
+// unsafeVolumeChars matches characters not allowed in volume names.
+var unsafeVolumeChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// CacheVolumeRepoPrefix returns the prefix of the dependency cache volumes
+// of the repo at repoPath: its directory name, for people reading volume
+// lists, and a hash of its path, to keep repos of the same name apart.
+func CacheVolumeRepoPrefix(repoPath string) string {
+	name := strings.Trim(unsafeVolumeChars.ReplaceAllString(filepath.Base(repoPath), "-"), "-.")
+	if name == "" {
+		name = "repo"
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(filepath.Clean(repoPath))))
+	return CacheVolumePrefix + strings.ToLower(name) + "-" + hash[:8] + "-"
+}
+
+// CacheRunArgs returns the run arguments mounting the repo's dependency
+// cache volumes into a session container and pointing tools at them, and
+// in offline mode keeping the tools from trying the network. The runtime
+// creates the volumes on first use.
+func CacheRunArgs(repoPath string) []string {
+	prefix := CacheVolumeRepoPrefix(repoPath)
+	var args []string
+	for _, c := range dependencyCaches {
+		args = append(args, "-v", prefix+c.name+":"+c.dir)
+		for _, e := range c.env {
+			args = append(args, "-e", e)
+		}
+	}
+	if Offline() {
+		for _, e := range offlineEnv {
+			args = append(args, "-e", e)
+		}
+	}
+	return args
+}
+
+// CacheVolume is a dependency cache volume and the space it uses.
+type CacheVolume struct {
+	Name string
+	// Size is the volume's size in bytes, or -1 when the runtime doesn't
+	// report it.
+	Size int64
+}
+
+// ListCacheVolumes returns the dependency cache volumes whose names start
+// with prefix (CacheVolumePrefix for all of them), sorted by name.
+func ListCacheVolumes(ctx context.Context, prefix string) ([]CacheVolume, error) {
+	out, err := dockerCommandFunc(ctx, "", "volume", "ls", "--filter", "name="+CacheVolumePrefix, "--format", "{{.Name}}")
+	if err != nil {
+		return nil, fmt.Errorf("%s volume ls failed: %w", CurrentRuntime().Name(), err)
+	}
+	var sizes map[string]int64
+	if df, err := dockerCommandFunc(ctx, "", "system", "df", "-v", "--format", "{{json .Volumes}}"); err == nil {
+		sizes = parseVolumeSizes(df)
+	}
+
+	var vols []CacheVolume
+	for name := range strings.FieldsSeq(string(out)) {
+		// The name filter matches anywhere in the name.
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		size, ok := sizes[name]
+		if !ok {
+			size = -1
+		}
+		vols = append(vols, CacheVolume{Name: name, Size: size})
+	}
+	sort.Slice(vols, func(i, j int) bool { return vols[i].Name < vols[j].Name })
+	return vols, nil
+}
+
+// RemoveCacheVolume removes a dependency cache volume. It fails while a
+// running session container uses it.
+func RemoveCacheVolume(ctx context.Context, name string) error {
+	if _, err := dockerCommandFunc(ctx, "", "volume", "rm", name); err != nil {
+		return fmt.Errorf("%s volume rm %s failed: %w", CurrentRuntime().Name(), name, err)
+	}
+	return nil
+}
+
+// parseVolumeSizes reads volume sizes from `system df -v` JSON output,
+// which reports each volume's size either as UsageData.Size in bytes or as
+// a human-readable Size such as "1.2GB".
+func parseVolumeSizes(out []byte) map[string]int64 {
+	var vols []struct {
+		Name      string
+		Size      any
+		UsageData *struct {
+			Size int64
+		}
+	}
+	if err := json.Unmarshal(out, &vols); err != nil {
+		return nil
+	}
+	sizes := make(map[string]int64, len(vols))
+	for _, v := range vols {
+		switch {
+		case v.UsageData != nil && v.UsageData.Size >= 0:
+			sizes[v.Name] = v.UsageData.Size
+		default:
+			switch s := v.Size.(type) {
+			case float64:
+				sizes[v.Name] = int64(s)
+			case string:
+				if n, ok := parseHumanSize(s); ok {
+					sizes[v.Name] = n
+				}
+			}
+		}
+	}
+	return sizes
+}
+
+var humanSizeRe = regexp.MustCompile(`^([0-9.]+)\s*([kKMGTP]?i?B)$`)
+
+// parseHumanSize parses sizes as the runtimes print them, e.g. "512B",
+// "1.5kB", "3GB" or "2GiB".
+func parseHumanSize(s string) (int64, bool) {
+	m := humanSizeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.ToUpper(m[2])
+	base := 1000.0
+	if strings.Contains(unit, "I") {
+		base = 1024
+	}
+	mult := 1.0
+	if unit != "B" {
+		for range strings.Index("KMGTP", unit[:1]) + 1 {
+			mult *= base
+		}
+	}
+	return int64(n * mult), true
+}
@@ -0,0 +1,95 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCacheVolumeRepoPrefix(t *testing.T) {
+	a := CacheVolumeRepoPrefix("/src/My Repo")
+	if !strings.HasPrefix(a, CacheVolumePrefix+"my-repo-") || !strings.HasSuffix(a, "-") {
+		t.Errorf("unexpected prefix %q", a)
+	}
+	if b := CacheVolumeRepoPrefix("/other/My Repo"); a == b {
+		t.Errorf("expected repos of the same name kept apart, both %q", a)
+	}
+	if c := CacheVolumeRepoPrefix("/src/My Repo/"); a != c {
+		t.Errorf("expected the same prefix for the same path, got %q and %q", a, c)
+	}
+}
+
+func TestCacheRunArgs(t *testing.T) {
+	args := strings.Join(CacheRunArgs("/src/app"), " ")
+	prefix := CacheVolumeRepoPrefix("/src/app")
+	for _, want := range []string{
+		"-v " + prefix + "go:/erg-cache/go -e GOMODCACHE=/erg-cache/go/mod -e GOCACHE=/erg-cache/go/build",
+		"-v " + prefix + "npm:/erg-cache/npm -e npm_config_cache=/erg-cache/npm",
+		"-v " + prefix + "pip:/erg-cache/pip -e PIP_CACHE_DIR=/erg-cache/pip",
+		"-v " + prefix + "cargo-registry:/root/.cargo/registry",
+		"-v " + prefix + "cargo-git:/root/.cargo/git",
+		"-v " + prefix + "gradle:/erg-cache/gradle -e GRADLE_USER_HOME=/erg-cache/gradle",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in %q", want, args)
+		}
+	}
+}
+
+func TestListCacheVolumes(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+
+	prefix := CacheVolumeRepoPrefix("/src/app")
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		switch args[0] {
+		case "volume":
+			return []byte(prefix + "npm\n" + prefix + "go\nother-erg-cache-x\n" + CacheVolumeRepoPrefix("/src/web") + "go\n"), nil
+		case "system":
+			return []byte(`[{"Name":"` + prefix + `go","Size":"1.5GB"},{"Name":"` + prefix + `npm","UsageData":{"Size":2048}}]`), nil
+		}
+		return nil, fmt.Errorf("unexpected call %v", args)
+	}
+
+	vols, err := ListCacheVolumes(context.Background(), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vols) != 2 {
+		t.Fatalf("expected the repo's 2 volumes, got %v", vols)
+	}
+	if vols[0].Name != prefix+"go" || vols[0].Size != 1_500_000_000 {
+		t.Errorf("unexpected %+v", vols[0])
+	}
+	if vols[1].Name != prefix+"npm" || vols[1].Size != 2048 {
+		t.Errorf("unexpected %+v", vols[1])
+	}
+
+	all, err := ListCacheVolumes(context.Background(), CacheVolumePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[2].Size != -1 {
+		t.Errorf("expected 3 volumes, the unreported one sized -1, got %v", all)
+	}
+}
+
+func TestParseHumanSize(t *testing.T) {
+	tests := map[string]int64{
+		"512B":   512,
+		"1.5kB":  1500,
+		"3MB":    3_000_000,
+		"2GiB":   2 << 30,
+		"0B":     0,
+		"10 KiB": 10 << 10,
+	}
+	for in, want := range tests {
+		if got, ok := parseHumanSize(in); !ok || got != want {
+			t.Errorf("parseHumanSize(%q) = %d, %v, want %d", in, got, ok, want)
+		}
+	}
+	if _, ok := parseHumanSize("N/A"); ok {
+		t.Error("expected N/A rejected")
+	}
+}
@@ -0,0 +1,191 @@
+package container
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Commands are a repo's canonical commands for building, testing and
+// linting it, each run with sh -c at the repo root. A field is empty when
+// none was detected.
+type Commands struct {
+	Build string `json:"build,omitempty"`
+	Test  string `json:"test,omitempty"`
+	Lint  string `json:"lint,omitempty"`
+}
+
+// Empty reports whether no command was detected.
+func (c Commands) Empty() bool {
+	return c == Commands{}
+}
+
+// commandTargets are the target, recipe, task or script names that mean
+// each command.
+var commandTargets = map[string][]string{
+	"build": {"build"},
+	"test":  {"test", "tests"},
+	"lint":  {"lint"},
+}
+
+// makeTargetRe matches a Makefile rule's target, but not a variable
+// assignment such as CC := gcc.
+var makeTargetRe = regexp.MustCompile(`(?m)^([A-Za-z0-9][A-Za-z0-9_.-]*)[ \t]*:(?:[^=]|$)`)
+
+// justRecipeRe matches a justfile recipe's name, with any parameters, but
+// not a setting or assignment such as set shell := ["bash"].
+var justRecipeRe = regexp.MustCompile(`(?m)^@?([A-Za-z0-9_-]+)(?:[ \t]+[^:\n]*)?:(?:[^=]|$)`)
+
+// toxEnvRe matches a tox.ini environment section.
+var toxEnvRe = regexp.MustCompile(`(?m)^\[(tox|testenv)(?::([^\]]+))?\]`)
+
+// packageLockfiles name the package manager of a repo with package.json by
+// its lockfile; npm is the default.
+var packageLockfiles = []struct{ file, manager string }{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+}
+
+// npmDefaultTest is the test script npm init writes, which only fails.
+const npmDefaultTest = `echo "Error: no test specified" && exit 1`
+
+// DetectCommands infers the canonical build, test and lint commands of the
+// repo at repoPath from its task runners and manifests, the first to define
+// each command winning: Makefile targets, justfile recipes, Taskfile tasks,
+// package.json scripts, tox.ini environments, then the toolchain's own
+// commands for Go and Rust.
+func DetectCommands(repoPath string) Commands {
+	found := []map[string]string{
+		runnerCommands("make", makeTargets(repoPath)),
+		runnerCommands("just", justRecipes(repoPath)),
+		runnerCommands("task", taskfileTasks(repoPath)),
+		packageScripts(repoPath),
+		toxCommands(repoPath),
+	}
+	if hasMarker(repoPath, "go.mod") {
+		found = append(found, map[string]string{"build": "go build ./...", "test": "go test ./...", "lint": "go vet ./..."})
+	}
+	if hasMarker(repoPath, "Cargo.toml") {
+		found = append(found, map[string]string{"build": "cargo build", "test": "cargo test", "lint": "cargo clippy"})
+	}
+
+	var c Commands
+	for _, cmds := range found {
+		if c.Build == "" {
+			c.Build = cmds["build"]
+		}
+		if c.Test == "" {
+			c.Test = cmds["test"]
+		}
+		if c.Lint == "" {
+			c.Lint = cmds["lint"]
+		}
+	}
+	return c
+}
+
+// runnerCommands maps each command to "runner name" for the first of its
+// target names in names.
+func runnerCommands(runner string, names map[string]bool) map[string]string {
+	cmds := make(map[string]string)
+	for kind, targets := range commandTargets {
+		for _, t := range targets {
+			if names[t] {
+				cmds[kind] = runner + " " + t
+				break
+			}
+		}
+	}
+	return cmds
+}
+
+// readFirst returns the contents of the first of names present in dir.
+func readFirst(dir string, names ...string) []byte {
+	for _, name := range names {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return data
+		}
+	}
+	return nil
+}
+
+// matchedNames returns the first submatch of each match of re in data.
+func matchedNames(re *regexp.Regexp, data []byte) map[string]bool {
+	names := make(map[string]bool)
+	for _, m := range re.FindAllSubmatch(data, -1) {
+		names[string(m[1])] = true
+	}
+	return names
+}
+
+// makeTargets returns the targets of the repo's Makefile.
+func makeTargets(repoPath string) map[string]bool {
+	return matchedNames(makeTargetRe, readFirst(repoPath, "GNUmakefile", "Makefile", "makefile"))
+}
+
+// justRecipes returns the recipes of the repo's justfile.
+func justRecipes(repoPath string) map[string]bool {
+	return matchedNames(justRecipeRe, readFirst(repoPath, "justfile", "Justfile", ".justfile"))
+}
+
+// taskfileTasks returns the tasks of the repo's Taskfile.
+func taskfileTasks(repoPath string) map[string]bool {
+	data := readFirst(repoPath, "Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml")
+	var tf struct {
+		Tasks map[string]any `yaml:"tasks"`
+	}
+	if data == nil || yaml.Unmarshal(data, &tf) != nil {
+		return nil
+	}
+	names := make(map[string]bool, len(tf.Tasks))
+	for name := range tf.Tasks {
+		names[name] = true
+	}
+	return names
+}
+
+// packageScripts returns the commands running the repo's package.json
+// scripts with the package manager its lockfile names.
+func packageScripts(repoPath string) map[string]string {
+	data := readFirst(repoPath, "package.json")
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if data == nil || json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	manager := "npm"
+	for _, l := range packageLockfiles {
+		if hasMarker(repoPath, l.file) {
+			manager = l.manager
+			break
+		}
+	}
+	names := make(map[string]bool, len(pkg.Scripts))
+	for name, script := range pkg.Scripts {
+		if name != "test" || script != npmDefaultTest {
+			names[name] = true
+		}
+	}
+	return runnerCommands(manager+" run", names)
+}
+
+// toxCommands returns the commands running the repo's tox environments:
+// all of them for test, and a lint environment for lint.
+func toxCommands(repoPath string) map[string]string {
+	data := readFirst(repoPath, "tox.ini")
+	cmds := make(map[string]string)
+	for _, m := range toxEnvRe.FindAllSubmatch(data, -1) {
+		if env := string(m[2]); env == "lint" {
+			cmds["lint"] = "tox -e lint"
+		} else if env == "" {
+			cmds["test"] = "tox"
+		}
+	}
+	return cmds
+}
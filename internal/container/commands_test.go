@@ -0,0 +1,73 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Commands
+	}{
+		{
+			name:  "makefile",
+			files: map[string]string{"Makefile": ".PHONY: build test\nCC := gcc\nbuild:\n\tgo build ./...\ntest: build\n\tgo test ./...\n"},
+			want:  Commands{Build: "make build", Test: "make test"},
+		},
+		{
+			name:  "justfile",
+			files: map[string]string{"justfile": "set shell := [\"bash\", \"-c\"]\nversion := \"1\"\n@lint:\n  ruff check\ntests *args: build\n  pytest {{args}}\nbuild:\n  echo\n"},
+			want:  Commands{Build: "just build", Test: "just tests", Lint: "just lint"},
+		},
+		{
+			name:  "taskfile",
+			files: map[string]string{"Taskfile.yml": "version: '3'\ntasks:\n  test:\n    cmds: [go test ./...]\n  lint:\n    cmds: [golangci-lint run]\n"},
+			want:  Commands{Test: "task test", Lint: "task lint"},
+		},
+		{
+			name: "package.json with pnpm",
+			files: map[string]string{
+				"package.json":   `{"scripts": {"build": "tsc", "test": "vitest", "lint": "eslint ."}}`,
+				"pnpm-lock.yaml": "",
+			},
+			want: Commands{Build: "pnpm run build", Test: "pnpm run test", Lint: "pnpm run lint"},
+		},
+		{
+			name:  "npm default test script",
+			files: map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`},
+		},
+		{
+			name:  "tox",
+			files: map[string]string{"tox.ini": "[tox]\nenvlist = py312\n\n[testenv]\ncommands = pytest\n\n[testenv:lint]\ncommands = ruff check\n"},
+			want:  Commands{Test: "tox", Lint: "tox -e lint"},
+		},
+		{
+			name:  "go toolchain",
+			files: map[string]string{"go.mod": "module x\n"},
+			want:  Commands{Build: "go build ./...", Test: "go test ./...", Lint: "go vet ./..."},
+		},
+		{
+			name: "task runner wins over the toolchain",
+			files: map[string]string{
+				"go.mod":   "module x\n",
+				"Makefile": "test:\n\tgo test -race ./...\n",
+			},
+			want: Commands{Build: "go build ./...", Test: "make test", Lint: "go vet ./..."},
+		},
+		{name: "nothing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+			}
+			if got := DetectCommands(dir); got != tt.want {
+				t.Errorf("DetectCommands() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
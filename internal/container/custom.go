@@ -0,0 +1,71 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DockerfileBuild is a repo's own Dockerfile to build session images from.
+type DockerfileBuild struct {
+	// Dockerfile is the Dockerfile's path.
+	Dockerfile string
+	// Context is the build context directory; empty means the Dockerfile's.
+	Context string
+	// Args are passed as --build-arg values.
+	Args map[string]string
+}
+
+// buildDockerfile builds the Dockerfile at path with the given context and
+// build args, tagging the image tag.
+func buildDockerfile(ctx context.Context, tag, path, contextDir string, buildArgs map[string]string) error {
+	args := []string{"build", "-t", tag, "-f", path}
+	names := make([]string, 0, len(buildArgs))
+	for k := range buildArgs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		args = append(args, "--build-arg", k+"="+buildArgs[k])
+	}
+	args = append(args, contextDir)
+	if _, err := dockerCommandFunc(ctx, "", args...); err != nil {
+		return fmt.Errorf("%s build of %s failed: %w", CurrentRuntime().Name(), path, err)
+	}
+	return nil
+}
+
+// EnsureDockerfileImage builds the session image for a repo that brings its
+// own Dockerfile: the Dockerfile itself, then what erg needs layered on top.
+// The repo's Dockerfile is rebuilt every time so changes to the files it
+// copies are picked up; the runtime's layer cache keeps unchanged builds
+// fast, and the session layers are only rebuilt when the base image changed.
+// It returns the image tag and whether the session layers were built.
+func EnsureDockerfileImage(ctx context.Context, b DockerfileBuild, version string, logger *slog.Logger) (string, bool, error) {
+	contextDir := b.Context
+	if contextDir == "" {
+		contextDir = filepath.Dir(b.Dockerfile)
+	}
+	h := sha256.Sum256([]byte(b.Dockerfile))
+	baseTag := fmt.Sprintf("erg-custom:%x", h[:6])
+	logger.Info("building custom container image", "image", baseTag, "dockerfile", b.Dockerfile)
+	if err := buildDockerfile(ctx, baseTag, b.Dockerfile, contextDir, b.Args); err != nil {
+		return "", false, err
+	}
+	out, err := dockerCommandFunc(ctx, "", "image", "inspect", "--format", "{{.Id}}", baseTag)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to inspect %s: %w", baseTag, err)
+	}
+
+	devBinaryHash, buildContextDir, cleanup := prepareDevBinary(version, logger)
+	defer cleanup()
+
+	dockerfile := generateLayeredDockerfile(baseTag, nil, "", version, devBinaryHash)
+	// The base image ID makes the session layers' tag change with the base.
+	dockerfile += fmt.Sprintf("LABEL erg.base.id=%s\n", strings.TrimSpace(string(out)))
+	return buildImage(ctx, dockerfile, buildContextDir, logger, "dockerfile", b.Dockerfile)
+}
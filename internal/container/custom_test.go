@@ -0,0 +1,95 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnsureDockerfileImage(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+
+	baseID := "sha256:1111"
+	cached := map[string]bool{}
+	var calls [][]string
+	var sessionDockerfile string
+	dockerCommandFunc = func(_ context.Context, stdin string, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		switch {
+		case args[0] == "image" && args[2] == "--format":
+			return []byte(baseID + "\n"), nil
+		case args[0] == "image":
+			if cached[args[2]] {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("not found")
+		case args[0] == "build" && stdin != "":
+			sessionDockerfile = stdin
+			cached[args[2]] = true
+		}
+		return nil, nil
+	}
+
+	b := DockerfileBuild{Dockerfile: "/repo/tools/Dockerfile", Context: "/repo", Args: map[string]string{"GO": "1.23"}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tag, built, err := EnsureDockerfileImage(context.Background(), b, "0.2.11", logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !built || !strings.HasPrefix(tag, "erg:") {
+		t.Errorf("expected the session layers built, got %q built=%v", tag, built)
+	}
+	base := calls[0]
+	if !slices.Equal(base[:1], []string{"build"}) || !slices.Equal(base[3:], []string{"-f", "/repo/tools/Dockerfile", "--build-arg", "GO=1.23", "/repo"}) {
+		t.Errorf("unexpected base build %v", base)
+	}
+	if !strings.HasPrefix(sessionDockerfile, "FROM "+base[2]+"\n") || !strings.HasSuffix(sessionDockerfile, "LABEL erg.base.id="+baseID+"\n") {
+		t.Errorf("expected session layers on the base, labeled with its ID:\n%s", sessionDockerfile)
+	}
+
+	// An unchanged base rebuilds the repo's Dockerfile (from the layer
+	// cache) but reuses the session layers.
+	calls = nil
+	again, built, err := EnsureDockerfileImage(context.Background(), b, "0.2.11", logger)
+	if err != nil || built || again != tag {
+		t.Errorf("expected cached session image %q, got %q built=%v err=%v", tag, again, built, err)
+	}
+	if len(calls) == 0 || calls[0][0] != "build" {
+		t.Errorf("expected the repo's Dockerfile rebuilt, got %v", calls)
+	}
+
+	// A changed base changes the session image.
+	baseID = "sha256:2222"
+	changed, built, err := EnsureDockerfileImage(context.Background(), b, "0.2.11", logger)
+	if err != nil || !built || changed == tag {
+		t.Errorf("expected a new session image for a new base, got %q built=%v err=%v", changed, built, err)
+	}
+}
+
+func TestEnsureDockerfileImage_DefaultContext(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+
+	var baseBuild []string
+	dockerCommandFunc = func(_ context.Context, stdin string, args ...string) ([]byte, error) {
+		if args[0] == "build" && stdin == "" {
+			baseBuild = args
+			return nil, fmt.Errorf("exit status 1: no such file")
+		}
+		return nil, nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	_, _, err := EnsureDockerfileImage(context.Background(), DockerfileBuild{Dockerfile: "/repo/tools/Dockerfile"}, "0.2.11", logger)
+	if err == nil || !strings.Contains(err.Error(), "/repo/tools/Dockerfile") {
+		t.Errorf("expected a build error naming the Dockerfile, got %v", err)
+	}
+	if baseBuild[len(baseBuild)-1] != "/repo/tools" {
+		t.Errorf("expected the Dockerfile's directory as context, got %v", baseBuild)
+	}
+}
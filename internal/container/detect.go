@@ -26,6 +26,20 @@ const (
 	LangRust   Language = "rust"
 	LangJava   Language = "java"
 	LangPHP    Language = "php"
+	// LangCpp covers C and C++ built with CMake (and vcpkg).
+	LangCpp       Language = "cpp"
+	LangDotNet    Language = "dotnet"
+	LangElixir    Language = "elixir"
+	LangSwift     Language = "swift"
+	LangZig       Language = "zig"
+	LangTerraform Language = "terraform"
+
+	// LangML marks a Python repo using an ML framework (see DetectML),
+	// whose wheels need a glibc image rather than the Alpine one.
+	LangML Language = "ml"
+	// LangCUDA builds the image on NVIDIA's CUDA image, for sessions given
+	// GPUs. It is never detected; the repo's workflow asks for it.
+	LangCUDA Language = "cuda"
 )
 
 // DetectedLang pairs a language with its parsed version (may be empty).
@@ -36,13 +50,21 @@ type DetectedLang struct {
 
 // languageOrder defines a deterministic sort order for languages.
 var languageOrder = map[Language]int{
-	LangGo:     0,
-	LangNode:   1,
-	LangRuby:   2,
-	LangPython: 3,
-	LangRust:   4,
-	LangJava:   5,
-	LangPHP:    6,
+	LangGo:        0,
+	LangNode:      1,
+	LangRuby:      2,
+	LangPython:    3,
+	LangRust:      4,
+	LangJava:      5,
+	LangPHP:       6,
+	LangCpp:       7,
+	LangDotNet:    8,
+	LangElixir:    9,
+	LangSwift:     10,
+	LangZig:       11,
+	LangTerraform: 12,
+	LangML:        13,
+	LangCUDA:      14,
 }
 
 // isLocalPath returns true if the repo string looks like a local filesystem path.
@@ -52,15 +74,20 @@ func isLocalPath(repo string) bool {
 
 // Detect detects languages used in the given repository.
 // For local paths (starting with / or .), it checks for marker files on disk.
-// For remote repos (owner/repo format), it uses the GitHub API.
+// For remote repos (owner/repo format), it uses the GitHub API, except in
+// offline mode, where it detects nothing for them.
 func Detect(ctx context.Context, repoPath string) []DetectedLang {
 	if isLocalPath(repoPath) {
 		return detectLocal(repoPath)
 	}
+	if Offline() {
+		return nil
+	}
 	return detectRemote(ctx, repoPath)
 }
 
-// markerFile maps a filename to the language it indicates.
+// markerFile maps a filename, or a glob such as "*.tf", to the language it
+// indicates.
 type markerFile struct {
 	file string
 	lang Language
@@ -74,11 +101,22 @@ var markers = []markerFile{
 	{"requirements.txt", LangPython},
 	{"pyproject.toml", LangPython},
 	{"setup.py", LangPython},
+	{"environment.yml", LangPython},
 	{"Cargo.toml", LangRust},
 	{"pom.xml", LangJava},
 	{"build.gradle", LangJava},
 	{"build.gradle.kts", LangJava},
 	{"composer.json", LangPHP},
+	{"CMakeLists.txt", LangCpp},
+	{"vcpkg.json", LangCpp},
+	{"global.json", LangDotNet},
+	{"*.sln", LangDotNet},
+	{"*.csproj", LangDotNet},
+	{"*.fsproj", LangDotNet},
+	{"mix.exs", LangElixir},
+	{"Package.swift", LangSwift},
+	{"build.zig", LangZig},
+	{"*.tf", LangTerraform},
 }
 
 // detectLocal checks for marker files on the local filesystem.
@@ -90,18 +128,26 @@ func detectLocal(repoPath string) []DetectedLang {
 		if seen[m.lang] {
 			continue
 		}
-		path := filepath.Join(repoPath, m.file)
-		if _, err := os.Stat(path); err == nil {
+		if hasMarker(repoPath, m.file) {
 			seen[m.lang] = true
 			version := parseVersion(repoPath, m.lang)
 			result = append(result, DetectedLang{Lang: m.lang, Version: version})
 		}
 	}
+	if seen[LangPython] && DetectML(repoPath) {
+		result = append(result, DetectedLang{Lang: LangML})
+	}
 
 	sortDetected(result)
 	return result
 }
 
+// hasMarker reports whether the repo root has a file matching pattern.
+func hasMarker(repoPath, pattern string) bool {
+	matches, err := filepath.Glob(filepath.Join(repoPath, pattern))
+	return err == nil && len(matches) > 0
+}
+
 // parseVersion attempts to parse the version for a language from repo files.
 func parseVersion(repoPath string, lang Language) string {
 	switch lang {
@@ -117,6 +163,16 @@ func parseVersion(repoPath string, lang Language) string {
 		return parseRustVersion(repoPath)
 	case LangJava:
 		return parseJavaVersion(repoPath)
+	case LangDotNet:
+		return parseDotNetVersion(repoPath)
+	case LangElixir:
+		return parseElixirVersion(repoPath)
+	case LangSwift:
+		return parseSwiftVersion(repoPath)
+	case LangZig:
+		return parseZigVersion(repoPath)
+	case LangTerraform:
+		return parseTerraformVersion(repoPath)
 	default:
 		return ""
 	}
@@ -227,6 +283,138 @@ func parseJavaVersion(repoPath string) string {
 	return ""
 }
 
+var (
+	dotnetTargetRe = regexp.MustCompile(`<TargetFrameworks?>\s*net(\d+\.\d+)`)
+	dotnetSDKRe    = regexp.MustCompile(`^(\d+\.\d+)`)
+)
+
+// parseDotNetVersion returns the .NET SDK channel (e.g. "8.0"): from
+// global.json's sdk.version, else the first project's target framework.
+func parseDotNetVersion(repoPath string) string {
+	if data, err := os.ReadFile(filepath.Join(repoPath, "global.json")); err == nil {
+		var g struct {
+			SDK struct {
+				Version string `json:"version"`
+			} `json:"sdk"`
+		}
+		if json.Unmarshal(data, &g) == nil {
+			if m := dotnetSDKRe.FindString(g.SDK.Version); m != "" {
+				return m
+			}
+		}
+	}
+	for _, pattern := range []string{"*.csproj", "*.fsproj"} {
+		projects, _ := filepath.Glob(filepath.Join(repoPath, pattern))
+		sort.Strings(projects)
+		for _, p := range projects {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				continue
+			}
+			if m := dotnetTargetRe.FindSubmatch(data); m != nil {
+				return string(m[1])
+			}
+		}
+	}
+	return ""
+}
+
+// toolVersion returns the version an asdf/mise .tool-versions file pins
+// for tool, or "".
+func toolVersion(repoPath, tool string) string {
+	data, err := os.ReadFile(filepath.Join(repoPath, ".tool-versions"))
+	if err != nil {
+		return ""
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == tool {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+var (
+	elixirMixRe     = regexp.MustCompile(`elixir:\s*"[~>=<\s]*(\d+\.\d+(?:\.\d+)?)`)
+	elixirVersionRe = regexp.MustCompile(`^(\d+\.\d+(?:\.\d+)?)`)
+)
+
+// parseElixirVersion reads .tool-versions, then mix.exs's elixir requirement.
+func parseElixirVersion(repoPath string) string {
+	// .tool-versions pins e.g. "1.15.7-otp-26"; the OTP suffix is dropped.
+	if v := elixirVersionRe.FindString(toolVersion(repoPath, "elixir")); v != "" {
+		return v
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, "mix.exs"))
+	if err != nil {
+		return ""
+	}
+	if m := elixirMixRe.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+var swiftToolsRe = regexp.MustCompile(`^//\s*swift-tools-version:\s*(\d+\.\d+)`)
+
+// parseSwiftVersion reads .swift-version, then Package.swift's
+// swift-tools-version header.
+func parseSwiftVersion(repoPath string) string {
+	if v := readTrimmedFile(filepath.Join(repoPath, ".swift-version")); v != "" {
+		return extractMajorMinorVersion(v)
+	}
+	if m := swiftToolsRe.FindStringSubmatch(readTrimmedFile(filepath.Join(repoPath, "Package.swift"))); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var zigMinimumRe = regexp.MustCompile(`\.minimum_zig_version\s*=\s*"(\d+\.\d+\.\d+)"`)
+
+// parseZigVersion reads .zigversion, then build.zig.zon's
+// minimum_zig_version.
+func parseZigVersion(repoPath string) string {
+	if v := readTrimmedFile(filepath.Join(repoPath, ".zigversion")); v != "" {
+		return v
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, "build.zig.zon"))
+	if err != nil {
+		return ""
+	}
+	if m := zigMinimumRe.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+var terraformRequiredRe = regexp.MustCompile(`required_version\s*=\s*"[~>=<\s]*(\d+\.\d+(?:\.\d+)?)`)
+
+// parseTerraformVersion reads .terraform-version, then the lowest version
+// allowed by a required_version constraint in the root module's *.tf
+// files. Versions are X.Y.Z, as Terraform releases are published.
+func parseTerraformVersion(repoPath string) string {
+	if v := readTrimmedFile(filepath.Join(repoPath, ".terraform-version")); v != "" {
+		return v
+	}
+	files, _ := filepath.Glob(filepath.Join(repoPath, "*.tf"))
+	sort.Strings(files)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if m := terraformRequiredRe.FindSubmatch(data); m != nil {
+			v := string(m[1])
+			if strings.Count(v, ".") == 1 {
+				v += ".0"
+			}
+			return v
+		}
+	}
+	return ""
+}
+
 // readTrimmedFile reads a file and returns its trimmed contents, or "" on error.
 func readTrimmedFile(path string) string {
 	data, err := os.ReadFile(path)
@@ -272,6 +460,15 @@ var ghLanguageMap = map[string]Language{
 	"Java":       LangJava,
 	"Kotlin":     LangJava,
 	"PHP":        LangPHP,
+	"C":          LangCpp,
+	"C++":        LangCpp,
+	"CMake":      LangCpp,
+	"C#":         LangDotNet,
+	"F#":         LangDotNet,
+	"Elixir":     LangElixir,
+	"Swift":      LangSwift,
+	"Zig":        LangZig,
+	"HCL":        LangTerraform,
 }
 
 // ghCommandFunc is the function used to execute gh commands. Overridden in tests.
@@ -318,6 +515,12 @@ var versionFiles = map[Language][]string{
 	LangPython: {".python-version", "pyproject.toml"},
 	LangRust:   {"rust-toolchain.toml", "rust-toolchain"},
 	LangJava:   {".java-version"},
+	LangDotNet: {"global.json"},
+	LangElixir: {".tool-versions", "mix.exs"},
+	LangSwift:  {".swift-version", "Package.swift"},
+	LangZig:    {".zigversion", "build.zig.zon"},
+	// Remote repos' *.tf files can't be listed; the common ones are fetched.
+	LangTerraform: {".terraform-version", "versions.tf", "main.tf"},
 }
 
 // parseRemoteVersion fetches version files from a remote repo via the GitHub API.
@@ -354,6 +354,71 @@ func TestParseJavaVersion(t *testing.T) {
 	}
 }
 
+func TestDetectLocal_NewToolchains(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Language
+	}{
+		{"CMakeLists.txt", map[string]string{"CMakeLists.txt": "project(foo)\n"}, LangCpp},
+		{"vcpkg.json", map[string]string{"vcpkg.json": "{}"}, LangCpp},
+		{"global.json", map[string]string{"global.json": "{}"}, LangDotNet},
+		{"solution", map[string]string{"App.sln": ""}, LangDotNet},
+		{"csproj", map[string]string{"App.csproj": "<Project/>"}, LangDotNet},
+		{"fsproj", map[string]string{"App.fsproj": "<Project/>"}, LangDotNet},
+		{"mix.exs", map[string]string{"mix.exs": "defmodule Foo.MixProject do\nend\n"}, LangElixir},
+		{"Package.swift", map[string]string{"Package.swift": "// swift-tools-version:5.9\n"}, LangSwift},
+		{"build.zig", map[string]string{"build.zig": "const std = @import(\"std\");\n"}, LangZig},
+		{"terraform", map[string]string{"main.tf": "terraform {}\n"}, LangTerraform},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, c := range tt.files {
+				writeFile(t, dir, f, c)
+			}
+			langs := Detect(context.Background(), dir)
+			if len(langs) != 1 || langs[0].Lang != tt.want {
+				t.Fatalf("expected only %s, got %v", tt.want, langs)
+			}
+		})
+	}
+}
+
+func TestParseNewToolchainVersions(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  Language
+		files map[string]string
+		want  string
+	}{
+		{"dotnet global.json", LangDotNet, map[string]string{"global.json": `{"sdk":{"version":"8.0.100"}}`}, "8.0"},
+		{"dotnet target framework", LangDotNet, map[string]string{"App.csproj": "<Project><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>"}, "9.0"},
+		{"dotnet target frameworks", LangDotNet, map[string]string{"Lib.fsproj": "<TargetFrameworks>net8.0;net9.0</TargetFrameworks>"}, "8.0"},
+		{"dotnet none", LangDotNet, map[string]string{"App.sln": ""}, ""},
+		{"elixir tool-versions", LangElixir, map[string]string{".tool-versions": "erlang 26.2\nelixir 1.15.7-otp-26\n", "mix.exs": `elixir: "~> 1.14"`}, "1.15.7"},
+		{"elixir mix.exs", LangElixir, map[string]string{"mix.exs": "def project do\n  [app: :foo, elixir: \"~> 1.16\"]\nend\n"}, "1.16"},
+		{"swift-version", LangSwift, map[string]string{".swift-version": "5.10.1\n", "Package.swift": "// swift-tools-version:5.9\n"}, "5.10"},
+		{"swift tools version", LangSwift, map[string]string{"Package.swift": "// swift-tools-version: 6.0\nimport PackageDescription\n"}, "6.0"},
+		{"zigversion", LangZig, map[string]string{".zigversion": "0.14.1\n"}, "0.14.1"},
+		{"zig zon", LangZig, map[string]string{"build.zig.zon": ".{\n    .minimum_zig_version = \"0.13.0\",\n}\n"}, "0.13.0"},
+		{"terraform-version", LangTerraform, map[string]string{".terraform-version": "1.8.5\n"}, "1.8.5"},
+		{"terraform required_version", LangTerraform, map[string]string{"versions.tf": "terraform {\n  required_version = \">= 1.5\"\n}\n"}, "1.5.0"},
+		{"terraform none", LangTerraform, map[string]string{"main.tf": "resource \"null_resource\" \"x\" {}\n"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, c := range tt.files {
+				writeFile(t, dir, f, c)
+			}
+			if got := parseVersion(dir, tt.lang); got != tt.want {
+				t.Errorf("parseVersion(%s) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDetectRemote(t *testing.T) {
 	orig := ghCommandFunc
 	defer func() { ghCommandFunc = orig }()
@@ -414,6 +479,15 @@ func TestGitHubLanguageMapping(t *testing.T) {
 		{"Java", LangJava, true},
 		{"Kotlin", LangJava, true},
 		{"PHP", LangPHP, true},
+		{"C", LangCpp, true},
+		{"C++", LangCpp, true},
+		{"CMake", LangCpp, true},
+		{"C#", LangDotNet, true},
+		{"F#", LangDotNet, true},
+		{"Elixir", LangElixir, true},
+		{"Swift", LangSwift, true},
+		{"Zig", LangZig, true},
+		{"HCL", LangTerraform, true},
 		{"Haskell", "", false},
 		{"Shell", "", false},
 	}
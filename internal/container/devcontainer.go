@@ -0,0 +1,350 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// devContainerPaths are where a repo's dev container config may live, in
+// the order the dev container spec looks for them.
+var devContainerPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// DevContainer is the subset of a devcontainer.json that erg builds session
+// containers from. See https://containers.dev/implementors/json_reference/.
+type DevContainer struct {
+	// Image is the base image, when the config doesn't build one.
+	Image string `json:"image"`
+	// Build builds the base image from a Dockerfile instead.
+	Build *DevContainerBuild `json:"build"`
+	// Features are dev container features layered onto the base image.
+	Features map[string]any `json:"features"`
+	// PostCreateCommand runs in the workspace once the container starts: a
+	// shell string, a command array, or an object of named commands.
+	PostCreateCommand any `json:"postCreateCommand"`
+	// ContainerEnv is set in the container.
+	ContainerEnv map[string]string `json:"containerEnv"`
+
+	// path is the devcontainer.json file, dir the directory it's in.
+	path string
+	dir  string
+	// raw is the file's content, for cache keys.
+	raw []byte
+}
+
+// DevContainerBuild is a devcontainer.json "build" section. Paths are
+// relative to the devcontainer.json.
+type DevContainerBuild struct {
+	Dockerfile string            `json:"dockerfile"`
+	Context    string            `json:"context"`
+	Args       map[string]string `json:"args"`
+}
+
+// Path returns the devcontainer.json the config was loaded from.
+func (dc *DevContainer) Path() string { return dc.path }
+
+// LoadDevContainer reads the repo's devcontainer.json, returning nil when
+// the repo has none. Only local repos are checked.
+func LoadDevContainer(repoPath string) (*DevContainer, error) {
+	if !isLocalPath(repoPath) {
+		return nil, nil
+	}
+	for _, rel := range devContainerPaths {
+		path := filepath.Join(repoPath, rel)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		var dc DevContainer
+		if err := json.Unmarshal(stripJSONC(data), &dc); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", rel, err)
+		}
+		if dc.Image == "" && (dc.Build == nil || dc.Build.Dockerfile == "") {
+			return nil, fmt.Errorf("%s: only image- and Dockerfile-based dev containers are supported (docker compose is not)", rel)
+		}
+		dc.path = path
+		dc.dir = filepath.Dir(path)
+		dc.raw = data
+		return &dc, nil
+	}
+	return nil, nil
+}
+
+// stripJSONC turns devcontainer.json's JSON-with-comments into JSON by
+// dropping // and /* */ comments and trailing commas outside strings.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && (data[i] != '*' || data[i+1] != '/') {
+				i++
+			}
+			i++
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket.
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// postCreateScript returns a shell script running the config's
+// postCreateCommand, or "" when there is none. Named commands in object
+// form run one after another, in name order.
+func (dc *DevContainer) postCreateScript() (string, error) {
+	cmds, err := shellCommands(dc.PostCreateCommand)
+	if err != nil {
+		return "", fmt.Errorf("postCreateCommand: %w", err)
+	}
+	if len(cmds) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, c := range cmds {
+		b.WriteString(c + "\n")
+	}
+	return b.String(), nil
+}
+
+// shellCommands converts a devcontainer lifecycle command into shell lines.
+func shellCommands(v any) ([]string, error) {
+	switch cmd := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(cmd) == "" {
+			return nil, nil
+		}
+		return []string{cmd}, nil
+	case []any:
+		if len(cmd) == 0 {
+			return nil, nil
+		}
+		args := make([]string, len(cmd))
+		for i, a := range cmd {
+			s, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("command array must contain only strings")
+			}
+			args[i] = shellQuote(s)
+		}
+		return []string{strings.Join(args, " ")}, nil
+	case map[string]any:
+		names := make([]string, 0, len(cmd))
+		for name := range cmd {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var lines []string
+		for _, name := range names {
+			if _, nested := cmd[name].(map[string]any); nested {
+				return nil, fmt.Errorf("%s: named commands cannot be nested", name)
+			}
+			sub, err := shellCommands(cmd[name])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			lines = append(lines, sub...)
+		}
+		return lines, nil
+	default:
+		return nil, fmt.Errorf("must be a string, an array, or an object")
+	}
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// writeFileStep returns a Dockerfile RUN instruction writing content to an
+// executable file at path. The content is base64-encoded so it survives
+// Dockerfile and shell quoting untouched.
+func writeFileStep(path, content string) string {
+	enc := base64.StdEncoding.EncodeToString([]byte(content))
+	return fmt.Sprintf("RUN echo %s | base64 -d > %s && chmod +x %s\n", enc, path, path)
+}
+
+// devContainerEntrypoint refreshes Claude Code like the generated image's
+// entrypoint, then runs the post-create script (if any) in the workspace,
+// with its output on stderr so it never mixes into Claude's JSON stream.
+const devContainerEntrypoint = `#!/bin/sh
+if [ -z "$ERG_SKIP_UPDATE" ]; then npm install -g @anthropic-ai/claude-code@latest >/dev/null 2>&1; fi
+if [ -x /usr/local/bin/erg-post-create.sh ]; then
+  /usr/local/bin/erg-post-create.sh 1>&2 || echo "erg: postCreateCommand failed with status $?" >&2
+fi
+exec claude "$@"
+`
+
+// installPrereqsStep installs what erg needs in a session container on top
+// of an arbitrary base image: Node.js (for Claude Code), git, curl, and
+// certificates, using whichever package manager the image has.
+const installPrereqsStep = `RUN if command -v apk >/dev/null 2>&1; then apk add --no-cache nodejs npm git curl ca-certificates bash; \
+    elif command -v apt-get >/dev/null 2>&1; then apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends git curl ca-certificates \
+      && (command -v node >/dev/null 2>&1 || DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends nodejs npm) && rm -rf /var/lib/apt/lists/*; \
+    elif command -v dnf >/dev/null 2>&1; then dnf install -y git curl ca-certificates $(command -v node >/dev/null 2>&1 || echo nodejs npm) && dnf clean all; \
+    elif command -v yum >/dev/null 2>&1; then yum install -y git curl ca-certificates $(command -v node >/dev/null 2>&1 || echo nodejs npm) && yum clean all; \
+    fi
+`
+
+// GenerateDevContainerDockerfile produces a Dockerfile that layers what erg
+// needs (Claude Code, the erg binary, the entrypoint) onto the dev
+// container's base image, so sessions run in the environment developers
+// use. version and devBinaryHash install erg as in GenerateDockerfile.
+func GenerateDevContainerDockerfile(dc *DevContainer, baseImage, version, devBinaryHash string) (string, error) {
+	postCreate, err := dc.postCreateScript()
+	if err != nil {
+		return "", err
+	}
+	return generateLayeredDockerfile(baseImage, dc.ContainerEnv, postCreate, version, devBinaryHash), nil
+}
+
+// generateLayeredDockerfile produces a Dockerfile layering what erg needs
+// onto an arbitrary base image: its prerequisites, Claude Code, env, the erg
+// binary, an optional post-create script, and the entrypoint.
+func generateLayeredDockerfile(baseImage string, env map[string]string, postCreate, version, devBinaryHash string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\n", baseImage)
+	// Custom images commonly switch to a non-root user; installing needs
+	// root, and sessions run as root like the generated image's.
+	b.WriteString("USER root\n")
+	b.WriteString(installPrereqsStep)
+	b.WriteString("RUN npm install -g @anthropic-ai/claude-code\n")
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "ENV %s=%q\n", k, env[k])
+	}
+
+	b.WriteString(ergInstallBlock(version, devBinaryHash))
+	if postCreate != "" {
+		b.WriteString(writeFileStep("/usr/local/bin/erg-post-create.sh", postCreate))
+	}
+	b.WriteString(writeFileStep("/usr/local/bin/entrypoint.sh", devContainerEntrypoint))
+	b.WriteString("ENTRYPOINT [\"/usr/local/bin/entrypoint.sh\"]\n")
+	return b.String()
+}
+
+// devContainerCLIFunc reports the path of the devcontainer CLI, which can
+// apply dev container features. Overridden in tests.
+var devContainerCLIFunc = func() (string, error) { return exec.LookPath("devcontainer") }
+
+// ensureDevContainerBase builds or names the dev container's base image and
+// returns it. Features are applied through the devcontainer CLI when it is
+// installed; otherwise they are skipped with a warning.
+func ensureDevContainerBase(ctx context.Context, repoPath string, dc *DevContainer, logger *slog.Logger) (string, error) {
+	cli, cliErr := devContainerCLIFunc()
+	useCLI := len(dc.Features) > 0 && cliErr == nil
+	if len(dc.Features) > 0 && !useCLI {
+		logger.Warn("devcontainer CLI not found; building without dev container features", "config", dc.path)
+	}
+	if dc.Image != "" && !useCLI {
+		return dc.Image, nil
+	}
+
+	h := sha256.New()
+	h.Write(dc.raw)
+	if dc.Build != nil && dc.Build.Dockerfile != "" {
+		if data, err := os.ReadFile(filepath.Join(dc.dir, dc.Build.Dockerfile)); err == nil {
+			h.Write(data)
+		}
+	}
+	tag := fmt.Sprintf("erg-devcontainer:%x", h.Sum(nil)[:6])
+	if _, err := dockerCommandFunc(ctx, "", "image", "inspect", tag); err == nil {
+		return tag, nil
+	}
+
+	rt := CurrentRuntime()
+	if useCLI {
+		logger.Info("building dev container base image with features", "image", tag, "config", dc.path)
+		cmd := exec.CommandContext(ctx, cli, "build",
+			"--workspace-folder", repoPath,
+			"--config", dc.path,
+			"--docker-path", rt.Command(ctx).Path,
+			"--image-name", tag)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("devcontainer build failed: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return tag, nil
+	}
+
+	logger.Info("building dev container base image", "image", tag, "dockerfile", dc.Build.Dockerfile)
+	buildCtx := filepath.Join(dc.dir, dc.Build.Context)
+	if dc.Build.Context == "" {
+		buildCtx = dc.dir
+	}
+	if err := buildDockerfile(ctx, tag, filepath.Join(dc.dir, dc.Build.Dockerfile), buildCtx, dc.Build.Args); err != nil {
+		return "", err
+	}
+	return tag, nil
+}
+
+// EnsureDevContainerImage builds the session image for a repo with a
+// devcontainer.json: its base image (built, and with features applied, as
+// the config says) plus what erg needs. It returns the image tag and
+// whether a build was needed, like EnsureImage.
+func EnsureDevContainerImage(ctx context.Context, repoPath string, dc *DevContainer, version string, logger *slog.Logger) (string, bool, error) {
+	base, err := ensureDevContainerBase(ctx, repoPath, dc, logger)
+	if err != nil {
+		return "", false, err
+	}
+
+	devBinaryHash, buildContextDir, cleanup := prepareDevBinary(version, logger)
+	defer cleanup()
+
+	dockerfile, err := GenerateDevContainerDockerfile(dc, base, version, devBinaryHash)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s: %w", dc.path, err)
+	}
+	return buildImage(ctx, dockerfile, buildContextDir, logger, "devcontainer", dc.path)
+}
@@ -0,0 +1,239 @@
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeDevContainer(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDevContainer_JSONC(t *testing.T) {
+	repo := writeDevContainer(t, map[string]string{
+		".devcontainer/devcontainer.json": `{
+	// Base image developers use
+	"image": "mcr.microsoft.com/devcontainers/go:1.23", /* pinned */
+	"features": {
+		"ghcr.io/devcontainers/features/node:1": {"version": "20"},
+	},
+	"postCreateCommand": "go mod download // not a comment",
+	"containerEnv": {"GOFLAGS": "-mod=mod"},
+}`,
+	})
+
+	dc, err := LoadDevContainer(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dc == nil {
+		t.Fatal("expected a dev container config")
+	}
+	if dc.Image != "mcr.microsoft.com/devcontainers/go:1.23" {
+		t.Errorf("Image = %q", dc.Image)
+	}
+	if len(dc.Features) != 1 || dc.ContainerEnv["GOFLAGS"] != "-mod=mod" {
+		t.Errorf("unexpected features/env: %+v %+v", dc.Features, dc.ContainerEnv)
+	}
+	if dc.PostCreateCommand != "go mod download // not a comment" {
+		t.Errorf("PostCreateCommand = %q", dc.PostCreateCommand)
+	}
+}
+
+func TestLoadDevContainer_Absent(t *testing.T) {
+	dc, err := LoadDevContainer(t.TempDir())
+	if err != nil || dc != nil {
+		t.Errorf("expected no config, got %+v, %v", dc, err)
+	}
+}
+
+func TestLoadDevContainer_RejectsCompose(t *testing.T) {
+	repo := writeDevContainer(t, map[string]string{
+		".devcontainer.json": `{"dockerComposeFile": "compose.yml", "service": "app"}`,
+	})
+	if _, err := LoadDevContainer(repo); err == nil || !strings.Contains(err.Error(), "compose") {
+		t.Errorf("expected compose configs rejected, got %v", err)
+	}
+}
+
+func TestShellCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     any
+		want    []string
+		wantErr bool
+	}{
+		{name: "none", cmd: nil},
+		{name: "string", cmd: "npm ci && npm run build", want: []string{"npm ci && npm run build"}},
+		{name: "array", cmd: []any{"pip", "install", "-e", ".[dev]", "it's"}, want: []string{`'pip' 'install' '-e' '.[dev]' 'it'\''s'`}},
+		{name: "object", cmd: map[string]any{"b": "make deps", "a": []any{"go", "mod", "download"}}, want: []string{"'go' 'mod' 'download'", "make deps"}},
+		{name: "bad array", cmd: []any{"go", 1.0}, wantErr: true},
+		{name: "nested object", cmd: map[string]any{"a": map[string]any{"b": "x"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellCommands(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// decodeWrittenFile returns the content a writeFileStep for path writes.
+func decodeWrittenFile(t *testing.T, dockerfile, path string) string {
+	t.Helper()
+	m := regexp.MustCompile(`RUN echo (\S+) \| base64 -d > ` + regexp.QuoteMeta(path)).FindStringSubmatch(dockerfile)
+	if m == nil {
+		return ""
+	}
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGenerateDevContainerDockerfile(t *testing.T) {
+	dc := &DevContainer{
+		PostCreateCommand: "npm ci",
+		ContainerEnv:      map[string]string{"B": "2", "A": "1"},
+	}
+	df, err := GenerateDevContainerDockerfile(dc, "mcr.microsoft.com/devcontainers/typescript-node:20", "0.2.11", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(df, "FROM mcr.microsoft.com/devcontainers/typescript-node:20\nUSER root\n") {
+		t.Errorf("expected the dev container image as root base, got:\n%s", df)
+	}
+	for _, want := range []string{
+		"npm install -g @anthropic-ai/claude-code",
+		"releases/download/v0.2.11/",
+		"ENV A=\"1\"\nENV B=\"2\"\n",
+		`ENTRYPOINT ["/usr/local/bin/entrypoint.sh"]`,
+	} {
+		if !strings.Contains(df, want) {
+			t.Errorf("expected %q in Dockerfile:\n%s", want, df)
+		}
+	}
+	if got := decodeWrittenFile(t, df, "/usr/local/bin/erg-post-create.sh"); got != "#!/bin/sh\nnpm ci\n" {
+		t.Errorf("unexpected post-create script %q", got)
+	}
+	entry := decodeWrittenFile(t, df, "/usr/local/bin/entrypoint.sh")
+	if !strings.Contains(entry, "erg-post-create.sh 1>&2") || !strings.HasSuffix(entry, "exec claude \"$@\"\n") {
+		t.Errorf("unexpected entrypoint %q", entry)
+	}
+}
+
+func TestGenerateDevContainerDockerfile_NoPostCreate(t *testing.T) {
+	df, err := GenerateDevContainerDockerfile(&DevContainer{}, "ubuntu:24.04", "dev", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(df, "/usr/local/bin/erg-post-create.sh &&") {
+		t.Error("expected no post-create script without a postCreateCommand")
+	}
+	if !strings.Contains(df, "COPY erg /usr/local/bin/erg\nLABEL erg.dev.hash=abc123\n") {
+		t.Errorf("expected dev binary copied, got:\n%s", df)
+	}
+}
+
+func TestEnsureDevContainerImage_BuildsDockerfileBase(t *testing.T) {
+	repo := writeDevContainer(t, map[string]string{
+		".devcontainer/devcontainer.json": `{"build": {"dockerfile": "Dockerfile", "context": "..", "args": {"VARIANT": "bookworm"}}}`,
+		".devcontainer/Dockerfile":        "FROM debian:bookworm\n",
+	})
+	dc, err := LoadDevContainer(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origDocker, origCLI := dockerCommandFunc, devContainerCLIFunc
+	defer func() { dockerCommandFunc, devContainerCLIFunc = origDocker, origCLI }()
+	devContainerCLIFunc = func() (string, error) { return "", fmt.Errorf("not found") }
+	var builds [][]string
+	var sessionDockerfile string
+	dockerCommandFunc = func(_ context.Context, stdin string, args ...string) ([]byte, error) {
+		if args[0] == "image" {
+			return nil, fmt.Errorf("not found")
+		}
+		builds = append(builds, args)
+		if stdin != "" {
+			sessionDockerfile = stdin
+		}
+		return nil, nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tag, built, err := EnsureDevContainerImage(context.Background(), repo, dc, "0.2.11", logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !built || !strings.HasPrefix(tag, "erg:") {
+		t.Errorf("expected a built erg image, got %q built=%v", tag, built)
+	}
+	if len(builds) != 2 {
+		t.Fatalf("expected base and session builds, got %v", builds)
+	}
+	base := builds[0]
+	wantBase := []string{"build", "-t", base[2], "-f", filepath.Join(repo, ".devcontainer", "Dockerfile"), "--build-arg", "VARIANT=bookworm", repo}
+	if !slices.Equal(base, wantBase) || !strings.HasPrefix(base[2], "erg-devcontainer:") {
+		t.Errorf("base build args = %v, want %v", base, wantBase)
+	}
+	if !strings.HasPrefix(sessionDockerfile, "FROM "+base[2]+"\n") {
+		t.Errorf("expected session image built on the base, got:\n%s", sessionDockerfile)
+	}
+}
+
+func TestEnsureDevContainerImage_ImageWithoutFeaturesSkipsBaseBuild(t *testing.T) {
+	repo := writeDevContainer(t, map[string]string{
+		".devcontainer/devcontainer.json": `{"image": "python:3.12", "features": {"ghcr.io/devcontainers/features/go:1": {}}}`,
+	})
+	dc, err := LoadDevContainer(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origDocker, origCLI := dockerCommandFunc, devContainerCLIFunc
+	defer func() { dockerCommandFunc, devContainerCLIFunc = origDocker, origCLI }()
+	// Without the devcontainer CLI, features are skipped and the image used as is.
+	devContainerCLIFunc = func() (string, error) { return "", fmt.Errorf("not found") }
+	var sessionDockerfile string
+	dockerCommandFunc = func(_ context.Context, stdin string, args ...string) ([]byte, error) {
+		if args[0] == "image" {
+			return nil, fmt.Errorf("not found")
+		}
+		sessionDockerfile = stdin
+		return nil, nil
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	if _, _, err := EnsureDevContainerImage(context.Background(), repo, dc, "0.2.11", logger); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sessionDockerfile, "FROM python:3.12\n") {
+		t.Errorf("expected the configured image as base, got:\n%s", sessionDockerfile)
+	}
+}
@@ -0,0 +1,72 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Exec is a shell command run in a throwaway container set up like a
+// session's: Worktree mounted at /workspace and RepoPath, the main
+// repository the worktree's .git points into, at its own path.
+type Exec struct {
+	Image    string
+	Worktree string
+	RepoPath string
+	// Network, when set, is the Docker network the container joins.
+	Network string
+	// Env are KEY=VALUE pairs set in the container.
+	Env []string
+	// Command is run with sh -c in /workspace.
+	Command string
+}
+
+// execScript runs its argument with sh -c in /workspace, folding stderr
+// into stdout, and ends the output with a line carrying the exit code so a
+// failed command's output is not lost with the runtime's error.
+const execScript = `cd /workspace || exit 1
+sh -c "$1" 2>&1
+printf '\n` + execExitPrefix + `%s\n' "$?"
+`
+
+// execExitPrefix starts the script's final line, holding the exit code.
+const execExitPrefix = "erg exec exit: "
+
+// RunExec runs e.Command in a throwaway container of e.Image and returns
+// its combined output and exit code. The error is set only when the
+// container itself could not run.
+func RunExec(ctx context.Context, e Exec) (string, int, error) {
+	if IsRemote() {
+		return "", 0, fmt.Errorf("running commands in throwaway containers is not supported with the remote runtime")
+	}
+	args := []string{"run", "--rm", "--entrypoint", "sh", "-v", e.Worktree + ":/workspace"}
+	if e.RepoPath != "" {
+		args = append(args, "-v", e.RepoPath+":"+e.RepoPath)
+	}
+	if e.Network != "" {
+		args = append(args, "--network", e.Network)
+	}
+	for _, kv := range e.Env {
+		args = append(args, "-e", kv)
+	}
+	if e.RepoPath != "" {
+		args = append(args, CacheRunArgs(e.RepoPath)...)
+	}
+	args = append(args, e.Image, "-c", execScript, "sh", e.Command)
+
+	out, err := dockerCommandFunc(ctx, "", args...)
+	if err != nil {
+		return "", 0, err
+	}
+	output := strings.TrimSuffix(string(out), "\n")
+	i := strings.LastIndex(output, "\n"+execExitPrefix)
+	if i < 0 {
+		return output, 0, fmt.Errorf("command output is missing its exit code")
+	}
+	code, err := strconv.Atoi(output[i+1+len(execExitPrefix):])
+	if err != nil {
+		return output[:i], 0, fmt.Errorf("parsing exit code: %w", err)
+	}
+	return output[:i], code, nil
+}
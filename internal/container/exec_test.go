@@ -0,0 +1,36 @@
+package container
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRunExec(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+	var got []string
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		got = args
+		return []byte("--- FAIL: TestX\n\nerg exec exit: 1\n"), nil
+	}
+
+	out, code, err := RunExec(context.Background(), Exec{
+		Image: "erg:abc", Worktree: "/wt", RepoPath: "/repo", Network: "erg-offline",
+		Env: []string{"GH_TOKEN=t"}, Command: "go test ./...",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "--- FAIL: TestX\n" || code != 1 {
+		t.Errorf("RunExec() = %q, %d", out, code)
+	}
+	for _, want := range []string{"/wt:/workspace", "/repo:/repo", "erg-offline", "GH_TOKEN=t", "erg:abc"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("expected %q in run args: %v", want, got)
+		}
+	}
+	if got[len(got)-1] != "go test ./..." {
+		t.Errorf("expected the command passed last, got %v", got)
+	}
+}
@@ -0,0 +1,38 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// DefaultCUDAVersion is the CUDA version of GPU session images when the
+// workflow names none.
+const DefaultCUDAVersion = "12.4.1"
+
+// mlDependencyFiles are the Python dependency files searched for ML
+// frameworks.
+var mlDependencyFiles = []string{"requirements*.txt", "pyproject.toml", "setup.py", "setup.cfg", "Pipfile"}
+
+// mlFrameworkRe matches an ML framework named as a dependency, such as
+// torch>=2.2 in requirements.txt or "tensorflow" in pyproject.toml.
+var mlFrameworkRe = regexp.MustCompile(`(?im)(?:^|[\s"',\[])(torch|tensorflow(?:-gpu)?|jax(?:lib)?|keras)(?:[\s"'<>=~!\[;,]|$)`)
+
+// DetectML reports whether the repo at repoPath is an ML project: one with
+// a conda environment.yml, or a Python dependency on PyTorch, TensorFlow,
+// JAX or Keras.
+func DetectML(repoPath string) bool {
+	if hasMarker(repoPath, "environment.yml") {
+		return true
+	}
+	for _, pattern := range mlDependencyFiles {
+		files, _ := filepath.Glob(filepath.Join(repoPath, pattern))
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err == nil && mlFrameworkRe.Match(data) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectML(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{name: "requirements torch", files: map[string]string{"requirements.txt": "numpy\ntorch>=2.2\n"}, want: true},
+		{name: "dev requirements", files: map[string]string{"requirements-dev.txt": "tensorflow==2.16\n"}, want: true},
+		{name: "pyproject", files: map[string]string{"pyproject.toml": "[project]\ndependencies = [\"jax[cuda12]\", \"optax\"]\n"}, want: true},
+		{name: "conda environment", files: map[string]string{"environment.yml": "name: train\n"}, want: true},
+		{name: "plain python", files: map[string]string{"requirements.txt": "flask\ntorchvision-helpers-not-really\n"}},
+		{name: "no python", files: map[string]string{"go.mod": "module x\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+			}
+			if got := DetectML(dir); got != tt.want {
+				t.Errorf("DetectML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLocal_ML(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("torch\n"), 0o644)
+
+	got := detectLocal(dir)
+	if !slices.Equal(got, []DetectedLang{{Lang: LangPython}, {Lang: LangML}}) {
+		t.Errorf("detectLocal() = %v, want python and ml", got)
+	}
+}
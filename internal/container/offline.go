@@ -0,0 +1,44 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// OfflineEnvVar turns on offline mode when set to a true value such as 1,
+// for air-gapped machines or ones that may only reach approved hosts: repos
+// are detected from disk only, sessions run in images already cached
+// locally, and package managers install from the dependency caches.
+const OfflineEnvVar = "ERG_OFFLINE"
+
+// ErrOffline is wrapped by errors from steps that need the network, which
+// offline mode doesn't allow.
+var ErrOffline = errors.New("offline mode")
+
+// Offline reports whether offline mode is on.
+func Offline() bool {
+	on, _ := strconv.ParseBool(os.Getenv(OfflineEnvVar))
+	return on
+}
+
+// offlineEnv points package managers at their dependency caches instead of
+// the network in offline mode.
+var offlineEnv = []string{
+	"GOPROXY=off",
+	"npm_config_offline=true",
+	"YARN_ENABLE_OFFLINE_MODE=1",
+	"PIP_NO_INDEX=1",
+	"CARGO_NET_OFFLINE=true",
+}
+
+// RequireCachedImage returns an error wrapping ErrOffline unless image is
+// cached locally, for offline mode, where nothing can be pulled.
+func RequireCachedImage(ctx context.Context, image string) error {
+	if _, err := dockerCommandFunc(ctx, "", "image", "inspect", image); err != nil {
+		return fmt.Errorf("%w: container image %s is not cached locally; pull or build it while online, or load it from an archive with docker load", ErrOffline, image)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOffline(t *testing.T) {
+	for v, want := range map[string]bool{"": false, "0": false, "1": true, "true": true, "yes": false} {
+		t.Setenv(OfflineEnvVar, v)
+		if got := Offline(); got != want {
+			t.Errorf("Offline() with %s=%q = %v, want %v", OfflineEnvVar, v, got, want)
+		}
+	}
+}
+
+func TestDetect_OfflineSkipsGitHub(t *testing.T) {
+	t.Setenv(OfflineEnvVar, "1")
+	orig := ghCommandFunc
+	defer func() { ghCommandFunc = orig }()
+	ghCommandFunc = func(_ context.Context, args ...string) ([]byte, error) {
+		t.Errorf("unexpected gh call %v in offline mode", args)
+		return nil, errors.New("offline")
+	}
+
+	if got := Detect(context.Background(), "acme/app"); got != nil {
+		t.Errorf("expected nothing detected for a remote repo, got %v", got)
+	}
+}
+
+func TestCacheRunArgs_Offline(t *testing.T) {
+	if args := strings.Join(CacheRunArgs("/src/app"), " "); strings.Contains(args, "GOPROXY=off") {
+		t.Errorf("expected no offline settings online, got %q", args)
+	}
+	t.Setenv(OfflineEnvVar, "1")
+	args := strings.Join(CacheRunArgs("/src/app"), " ")
+	for _, want := range []string{"-e GOPROXY=off", "-e npm_config_offline=true", "-e PIP_NO_INDEX=1", "-e CARGO_NET_OFFLINE=true"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in %q", want, args)
+		}
+	}
+}
+
+func TestEnsureImageFrom_Offline(t *testing.T) {
+	t.Setenv(OfflineEnvVar, "1")
+	langs := []DetectedLang{{Lang: LangGo, Version: "1.23"}}
+	reg := &Registry{Repository: "ghcr.io/acme/erg-base", Push: true}
+	calls := registryDocker(t, reg.Ref(Fingerprint(langs, "0.2.11")))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	_, _, err := EnsureImageFrom(context.Background(), langs, "0.2.11", reg, logger)
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected an offline error for an image not cached, got %v", err)
+	}
+	if want := []string{"image"}; !slices.Equal(*calls, want) {
+		t.Errorf("commands = %v, want only the cache lookup", *calls)
+	}
+}
+
+func TestRequireCachedImage(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		if args[2] == "ghcr.io/acme/tools:1" {
+			return []byte("[]"), nil
+		}
+		return nil, fmt.Errorf("no such image")
+	}
+
+	if err := RequireCachedImage(context.Background(), "ghcr.io/acme/tools:1"); err != nil {
+		t.Errorf("unexpected error for a cached image: %v", err)
+	}
+	err := RequireCachedImage(context.Background(), "ghcr.io/acme/tools:2")
+	if !errors.Is(err, ErrOffline) || !strings.Contains(err.Error(), "ghcr.io/acme/tools:2") {
+		t.Errorf("expected an offline error naming the image, got %v", err)
+	}
+}
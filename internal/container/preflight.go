@@ -0,0 +1,124 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Preflight describes the checks run in a session's container before the
+// agent starts in it.
+type Preflight struct {
+	SessionID string
+	Image     string
+	// Worktree is mounted at /workspace, and RepoPath, the main repository
+	// the worktree's .git points into, at its own path, as for the session.
+	// On a remote host the session's workspace volume is mounted instead.
+	Worktree string
+	RepoPath string
+	// Network, when set, must exist, and the checks run on it.
+	Network string
+	// Toolchains are languages, as Detect names them, whose toolchains must
+	// run in the image.
+	Toolchains []string
+	// Commands are the repo's own checks, run with sh -c in /workspace.
+	Commands []string
+}
+
+// PreflightError reports a failed preflight check, with the output of the
+// checks up to and including it.
+type PreflightError struct {
+	Check string
+	Log   string
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("preflight check %q failed", e.Check)
+}
+
+// toolchainChecks are the commands proving a language's toolchain runs.
+var toolchainChecks = map[Language]string{
+	LangGo:        "go version",
+	LangNode:      "node --version",
+	LangPython:    "python3 --version",
+	LangRuby:      "ruby --version",
+	LangRust:      "cargo --version",
+	LangJava:      "java -version",
+	LangPHP:       "php --version",
+	LangCpp:       "cmake --version",
+	LangDotNet:    "dotnet --version",
+	LangElixir:    "elixir --version",
+	LangSwift:     "swift --version",
+	LangZig:       "zig version",
+	LangTerraform: "terraform version",
+}
+
+// preflightScript runs each argument with sh -c in /workspace, stopping at
+// the first that fails. All output goes to stderr, which a failed run's
+// error carries.
+const preflightScript = `exec 1>&2
+cd /workspace || exit 1
+for c in "$@"; do
+  printf '$ %s\n' "$c"
+  sh -c "$c" || { printf 'erg preflight: %s failed (exit %s)\n' "$c" "$?"; exit 1; }
+done
+`
+
+// preflightFailPrefix starts the script's line naming the failed check.
+const preflightFailPrefix = "erg preflight: "
+
+// RunPreflight checks a session's container environment: that p.Network
+// exists, then, in a throwaway container of p.Image set up like the
+// session's, that git works in the worktree, that p.Toolchains run, and
+// that p.Commands succeed. It returns a *PreflightError naming the first
+// failed check.
+func RunPreflight(ctx context.Context, p Preflight) error {
+	if p.Network != "" {
+		if _, err := dockerCommandFunc(ctx, "", "network", "inspect", p.Network); err != nil {
+			return &PreflightError{
+				Check: "network " + p.Network,
+				Log:   fmt.Sprintf("network %s does not exist: %v", p.Network, err),
+			}
+		}
+	}
+
+	checks := []string{"git status --porcelain >/dev/null"}
+	for _, t := range p.Toolchains {
+		if c, ok := toolchainChecks[Language(t)]; ok {
+			checks = append(checks, c)
+		}
+	}
+	checks = append(checks, p.Commands...)
+
+	args := []string{"run", "--rm", "--entrypoint", "sh"}
+	if IsRemote() {
+		args = append(args, "-v", WorkspaceVolume(p.SessionID)+":/workspace")
+	} else {
+		args = append(args, "-v", p.Worktree+":/workspace")
+		if p.RepoPath != "" {
+			args = append(args, "-v", p.RepoPath+":"+p.RepoPath)
+		}
+	}
+	if p.Network != "" {
+		args = append(args, "--network", p.Network)
+	}
+	if p.RepoPath != "" {
+		args = append(args, CacheRunArgs(p.RepoPath)...)
+	}
+	args = append(args, p.Image, "-c", preflightScript, "sh")
+	args = append(args, checks...)
+
+	if _, err := dockerCommandFunc(ctx, "", args...); err != nil {
+		log := err.Error()
+		check := "preflight container"
+		if i := strings.LastIndex(log, preflightFailPrefix); i >= 0 {
+			line, _, _ := strings.Cut(log[i+len(preflightFailPrefix):], "\n")
+			if j := strings.LastIndex(line, " failed (exit "); j >= 0 {
+				line = line[:j]
+			}
+			check = line
+		}
+		return &PreflightError{Check: check, Log: log}
+	}
+	return nil
+}
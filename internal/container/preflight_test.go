@@ -0,0 +1,78 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunPreflight(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+	var got [][]string
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		got = append(got, args)
+		return nil, nil
+	}
+
+	err := RunPreflight(context.Background(), Preflight{
+		SessionID: "sess-1", Image: "erg:abc", Worktree: "/wt", RepoPath: "/repo", Network: "erg-offline",
+		Toolchains: []string{"go", "ml"}, Commands: []string{"go build ./..."},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || strings.Join(got[0], " ") != "network inspect erg-offline" {
+		t.Fatalf("expected the network checked, then a run, got %v", got)
+	}
+	run := got[1]
+	for _, want := range []string{"/wt:/workspace", "/repo:/repo", "erg-offline", "erg:abc"} {
+		if !slices.Contains(run, want) {
+			t.Errorf("expected %q in run args: %v", want, run)
+		}
+	}
+	if checks := run[len(run)-3:]; !slices.Equal(checks, []string{"git status --porcelain >/dev/null", "go version", "go build ./..."}) {
+		t.Errorf("checks = %v", checks)
+	}
+}
+
+func TestRunPreflight_Failures(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		return nil, fmt.Errorf("exit status 1: no such network")
+	}
+	var pfErr *PreflightError
+	err := RunPreflight(context.Background(), Preflight{Image: "x", Worktree: "/wt", Network: "erg-offline"})
+	if !errors.As(err, &pfErr) || pfErr.Check != "network erg-offline" {
+		t.Errorf("expected a network failure, got %v", err)
+	}
+
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		return nil, fmt.Errorf("exit status 1: $ git status --porcelain >/dev/null\n$ npm ci\nnpm ERR! missing lockfile\nerg preflight: npm ci failed (exit 1)\n")
+	}
+	err = RunPreflight(context.Background(), Preflight{Image: "x", Worktree: "/wt", Commands: []string{"npm ci"}})
+	if !errors.As(err, &pfErr) || pfErr.Check != "npm ci" || !strings.Contains(pfErr.Log, "missing lockfile") {
+		t.Errorf("expected the npm ci failure with its log, got %v", err)
+	}
+}
+
+func TestPreflightScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	script := strings.ReplaceAll(preflightScript, "/workspace", t.TempDir())
+	out, err := exec.Command("sh", "-c", script, "sh", "true", "echo ran; exit 3", "echo never").CombinedOutput()
+	if err == nil {
+		t.Fatal("expected the script to fail")
+	}
+	log := string(out)
+	if !strings.Contains(log, "ran") || strings.Contains(log, "never") || !strings.Contains(log, "erg preflight: echo ran; exit 3 failed (exit 3)") {
+		t.Errorf("unexpected output:\n%s", log)
+	}
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Registry is a repository of prebuilt session images, tagged by the
+// fingerprint of what they were built for, that sessions pull instead of
+// building locally.
+type Registry struct {
+	// Repository is the image repository without a tag, e.g.
+	// ghcr.io/acme/erg-base.
+	Repository string
+	// Push publishes images built locally on a miss, so the next machine
+	// pulls them.
+	Push bool
+}
+
+// Ref returns the reference of the prebuilt image with the given
+// fingerprint.
+func (r *Registry) Ref(fingerprint string) string {
+	return r.Repository + ":" + fingerprint
+}
+
+// Fingerprint identifies the image EnsureImage builds for langs: the
+// languages and their versions, with defaults filled in, the erg version,
+// which fixes the rest of the Dockerfile, and the architecture. Machines
+// detecting the same toolchains share it.
+func Fingerprint(langs []DetectedLang, version string) string {
+	parts := make([]string, 0, len(langs)+2)
+	for _, l := range langs {
+		v := l.Version
+		if v == "" {
+			v = defaultVersions[l.Lang]
+		}
+		parts = append(parts, string(l.Lang)+"@"+v)
+	}
+	sort.Strings(parts)
+	parts = append(parts, "erg@"+version, "arch@"+goArch())
+	h := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return fmt.Sprintf("%x", h[:8])
+}
+
+// pullPrebuiltImage makes the image at ref available locally as tag,
+// pulling it unless tag is already cached. It reports false when the
+// registry doesn't have it, or can't be reached, so the caller builds.
+func pullPrebuiltImage(ctx context.Context, ref, tag string, logger *slog.Logger) (string, bool) {
+	if _, err := dockerCommandFunc(ctx, "", "image", "inspect", tag); err == nil {
+		logger.Info("using cached container image", "image", tag)
+		return tag, true
+	}
+	if _, err := dockerCommandFunc(ctx, "", "pull", ref); err != nil {
+		logger.Info("no prebuilt container image, building locally", "image", ref, "error", strings.TrimSpace(err.Error()))
+		return "", false
+	}
+	if _, err := dockerCommandFunc(ctx, "", "tag", ref, tag); err != nil {
+		logger.Warn("failed to tag prebuilt container image, building locally", "image", ref, "error", err)
+		return "", false
+	}
+	logger.Info("using prebuilt container image", "image", ref, "tag", tag)
+	return tag, true
+}
+
+// pushPrebuiltImage publishes a locally built image to ref. A failed push
+// is logged only; the local image is still used.
+func pushPrebuiltImage(ctx context.Context, tag, ref string, logger *slog.Logger) {
+	if _, err := dockerCommandFunc(ctx, "", "tag", tag, ref); err != nil {
+		logger.Warn("failed to tag container image for push", "image", ref, "error", err)
+		return
+	}
+	if _, err := dockerCommandFunc(ctx, "", "push", ref); err != nil {
+		logger.Warn("failed to push container image to registry", "image", ref, "error", err)
+		return
+	}
+	logger.Info("pushed container image to registry", "image", ref)
+}
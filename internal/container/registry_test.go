@@ -0,0 +1,123 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint([]DetectedLang{{Lang: LangGo, Version: "1.23"}, {Lang: LangNode}}, "0.2.11")
+
+	if got := Fingerprint([]DetectedLang{{Lang: LangNode, Version: "20"}, {Lang: LangGo, Version: "1.23"}}, "0.2.11"); got != base {
+		t.Errorf("expected order and explicit defaults not to matter, got %s vs %s", got, base)
+	}
+	for name, fp := range map[string]string{
+		"language version": Fingerprint([]DetectedLang{{Lang: LangGo, Version: "1.24"}, {Lang: LangNode}}, "0.2.11"),
+		"languages":        Fingerprint([]DetectedLang{{Lang: LangGo, Version: "1.23"}}, "0.2.11"),
+		"erg version":      Fingerprint([]DetectedLang{{Lang: LangGo, Version: "1.23"}, {Lang: LangNode}}, "0.2.12"),
+	} {
+		if fp == base {
+			t.Errorf("expected a different %s to change the fingerprint", name)
+		}
+	}
+	if len(base) != 16 {
+		t.Errorf("expected a 16-char fingerprint, got %q", base)
+	}
+}
+
+// registryDocker fakes the container runtime for prebuilt image tests:
+// nothing is cached locally, pulls of the refs in remote succeed and builds
+// succeed. It records every command.
+func registryDocker(t *testing.T, remote ...string) *[]string {
+	t.Helper()
+	orig := dockerCommandFunc
+	t.Cleanup(func() { dockerCommandFunc = orig })
+	var calls []string
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		calls = append(calls, args[0])
+		switch args[0] {
+		case "image":
+			return nil, fmt.Errorf("not found")
+		case "pull":
+			if slices.Contains(remote, args[1]) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("manifest unknown")
+		case "tag", "build", "push":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected call: %v", args)
+	}
+	return &calls
+}
+
+func TestEnsureImageFrom_PullsPrebuilt(t *testing.T) {
+	langs := []DetectedLang{{Lang: LangGo, Version: "1.23"}}
+	reg := &Registry{Repository: "ghcr.io/acme/erg-base", Push: true}
+	calls := registryDocker(t, reg.Ref(Fingerprint(langs, "0.2.11")))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tag, built, err := EnsureImageFrom(context.Background(), langs, "0.2.11", reg, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if built || !strings.HasPrefix(tag, "erg:") {
+		t.Errorf("expected the pulled image tagged locally, got %q built=%v", tag, built)
+	}
+	if want := []string{"image", "pull", "tag"}; !slices.Equal(*calls, want) {
+		t.Errorf("commands = %v, want %v", *calls, want)
+	}
+}
+
+func TestEnsureImageFrom_BuildsAndPushesOnMiss(t *testing.T) {
+	langs := []DetectedLang{{Lang: LangGo, Version: "1.23"}}
+	tests := []struct {
+		name string
+		push bool
+		want []string
+	}{
+		{name: "push", push: true, want: []string{"image", "pull", "image", "build", "tag", "push"}},
+		{name: "no push", push: false, want: []string{"image", "pull", "image", "build"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := registryDocker(t)
+			reg := &Registry{Repository: "ghcr.io/acme/erg-base", Push: tt.push}
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			_, built, err := EnsureImageFrom(context.Background(), langs, "0.2.11", reg, logger)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !built {
+				t.Error("expected a local build on a registry miss")
+			}
+			if !slices.Equal(*calls, tt.want) {
+				t.Errorf("commands = %v, want %v", *calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureImageFrom_DevBuildsSkipRegistry(t *testing.T) {
+	origCross := crossCompileFunc
+	defer func() { crossCompileFunc = origCross }()
+	crossCompileFunc = func(outputPath string) error {
+		return os.WriteFile(outputPath, []byte("fake-binary"), 0o755)
+	}
+	calls := registryDocker(t)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	reg := &Registry{Repository: "ghcr.io/acme/erg-base", Push: true}
+	if _, _, err := EnsureImageFrom(context.Background(), nil, "dev", reg, logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(*calls, "pull") || slices.Contains(*calls, "push") {
+		t.Errorf("expected dev builds not to use the registry, got %v", *calls)
+	}
+}
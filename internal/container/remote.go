@@ -0,0 +1,347 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RemoteHostEnvVar names the Docker host the remote runtime runs session
+// containers on, in any form docker --host takes: ssh://user@host or
+// tcp://host:2376.
+const RemoteHostEnvVar = "ERG_REMOTE_DOCKER_HOST"
+
+// Remote drives a Docker engine on another machine, so parallel sessions
+// don't load the local one. Images are built and cached on that host. Its
+// containers can't mount local paths, so each session's worktree is copied
+// into a volume there before the session runs and its changes are copied
+// back after; see PushWorkspace and PullWorkspace.
+type Remote struct {
+	// Host is passed to docker --host.
+	Host string
+}
+
+func (Remote) Name() string { return "remote" }
+
+func (r Remote) Command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "docker", append([]string{"--host", r.Host}, args...)...)
+}
+
+func (r Remote) StartHint() string {
+	return "is the Docker host at " + r.Host + " reachable? Check with: docker --host " + r.Host + " info"
+}
+
+// IsRemote reports whether session containers run on a remote host.
+func IsRemote() bool {
+	_, ok := CurrentRuntime().(Remote)
+	return ok
+}
+
+// PublishedPortHost returns the host to dial for a port a session container
+// publishes: the remote Docker host's name, or localhost.
+func PublishedPortHost() string {
+	r, ok := CurrentRuntime().(Remote)
+	if !ok {
+		return "localhost"
+	}
+	if u, err := url.Parse(r.Host); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+// WorkspaceVolume returns the volume holding a remote session's copy of its
+// worktree, which its container mounts at /workspace.
+func WorkspaceVolume(sessionID string) string {
+	return "erg-ws-" + sessionID
+}
+
+// remoteBaseRef marks the commit a remote workspace was copied at, so
+// PullWorkspace sends back only the session's own commits.
+const remoteBaseRef = "refs/erg/base"
+
+// pushWorkspaceScript replaces /workspace with a clone of the bundled
+// branch, then lays the worktree's files over it and removes the files
+// deleted in it, leaving the same uncommitted changes as the worktree.
+const pushWorkspaceScript = `set -e
+in=$(mktemp -d)
+tar -x -C "$in"
+find /workspace -mindepth 1 -delete
+git clone -q -b "$1" "$in/repo.bundle" /workspace
+cd /workspace
+git remote remove origin
+git update-ref ` + remoteBaseRef + ` HEAD
+if [ -d "$in/files" ]; then cp -a "$in/files/." /workspace/; fi
+if [ -s "$in/deleted" ]; then xargs -0 rm -f < "$in/deleted"; fi
+`
+
+// pullWorkspaceScript writes a tar of the session's commits since the copy,
+// as a bundle, and its uncommitted changes, as a patch, to stdout.
+const pullWorkspaceScript = `set -e
+out=$(mktemp -d)
+cd /workspace
+git add -A
+git diff --cached --binary HEAD > "$out/changes.patch"
+git reset -q
+if [ "$(git rev-list --count ` + remoteBaseRef + `..HEAD)" != 0 ]; then
+  git bundle create "$out/commits.bundle" ` + remoteBaseRef + `..HEAD >&2
+fi
+tar -c -C "$out" .
+`
+
+// gitCommand runs git in dir and returns its stdout.
+func gitCommand(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// PushWorkspace copies a worktree into its session's workspace volume on
+// the remote host: its branch's history, as a git bundle, and its working
+// files, tracked and untracked but not ignored. image runs the copy and
+// needs git, tar and find, as erg's images have.
+func PushWorkspace(ctx context.Context, sessionID, worktree, image string) error {
+	branch, err := gitCommand(ctx, worktree, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("remote sessions need a branch checked out: %w", err)
+	}
+	tmp, err := os.MkdirTemp("", "erg-push-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	bundle := filepath.Join(tmp, "repo.bundle")
+	if _, err := gitCommand(ctx, worktree, "bundle", "create", bundle, "HEAD", "refs/heads/"+strings.TrimSpace(string(branch))); err != nil {
+		return err
+	}
+	files, err := gitCommand(ctx, worktree, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return err
+	}
+	deleted, err := gitCommand(ctx, worktree, "ls-files", "-z", "--deleted")
+	if err != nil {
+		return err
+	}
+
+	// The tar is written into the command's stdin as it reads it, so a large
+	// worktree is never held in memory.
+	pr, pw := io.Pipe()
+	tarErr := make(chan error, 1)
+	go func() {
+		err := writeWorkspaceTar(pw, worktree, bundle, files, deleted)
+		pw.CloseWithError(err)
+		tarErr <- err
+	}()
+	err = dockerStreamFunc(ctx, pr, nil, "run", "--rm", "-i",
+		"-v", WorkspaceVolume(sessionID)+":/workspace", "--entrypoint", "sh", image,
+		"-c", pushWorkspaceScript, "sh", strings.TrimSpace(string(branch)))
+	pr.Close() // unblocks the writer if the command exited early
+	if werr := <-tarErr; werr != nil && err == nil {
+		err = werr
+	}
+	if err != nil {
+		return fmt.Errorf("copying worktree to the remote host failed: %w", err)
+	}
+	return nil
+}
+
+// writeWorkspaceTar writes the tar PushWorkspace sends: the bundle at
+// bundle, the worktree's files listed in files, and the list of deleted
+// ones, both NUL-separated as git ls-files -z prints them.
+func writeWorkspaceTar(w io.Writer, worktree, bundle string, files, deleted []byte) error {
+	tw := tar.NewWriter(w)
+	if err := addTarFile(tw, "repo.bundle", bundle); err != nil {
+		return err
+	}
+	for name := range strings.SplitSeq(string(files), "\x00") {
+		if name == "" {
+			continue
+		}
+		if err := addTarFile(tw, "files/"+name, filepath.Join(worktree, name)); err != nil {
+			if os.IsNotExist(err) {
+				continue // deleted in the worktree; listed in deleted
+			}
+			return err
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "deleted", Mode: 0o644, Size: int64(len(deleted))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(deleted); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// addTarFile adds the regular file or symlink at path to tw as name,
+// skipping anything else.
+func addTarFile(tw *tar.Writer, name, path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	} else if !info.Mode().IsRegular() {
+		return nil // e.g. a submodule directory
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if link != "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// PullWorkspace brings a remote session's work back into its worktree: the
+// commits it made, fast-forwarded onto the branch, and its uncommitted
+// changes. The worktree is first reset to its last commit, since the
+// remote copy, which started from it, is the session's only writer.
+func PullWorkspace(ctx context.Context, sessionID, worktree, image string) error {
+	tmp, err := os.MkdirTemp("", "erg-pull-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// The tar is extracted as the command writes it, so a large workspace
+	// is never held in memory.
+	pr, pw := io.Pipe()
+	extractErr := make(chan error, 1)
+	go func() {
+		err := extractFlatTar(pr, tmp)
+		if err == nil {
+			_, err = io.Copy(io.Discard, pr) // the tar's trailing padding
+		}
+		pr.CloseWithError(err)
+		extractErr <- err
+	}()
+	err = dockerStreamFunc(ctx, nil, pw, "run", "--rm",
+		"-v", WorkspaceVolume(sessionID)+":/workspace", "--entrypoint", "sh", image,
+		"-c", pullWorkspaceScript)
+	pw.CloseWithError(err)
+	if xerr := <-extractErr; xerr != nil && err == nil {
+		err = xerr
+	}
+	if err != nil {
+		return fmt.Errorf("reading the remote workspace failed: %w", err)
+	}
+
+	if _, err := gitCommand(ctx, worktree, "reset", "-q", "--hard"); err != nil {
+		return err
+	}
+	if _, err := gitCommand(ctx, worktree, "clean", "-q", "-fd"); err != nil {
+		return err
+	}
+	if bundle := filepath.Join(tmp, "commits.bundle"); fileExists(bundle) {
+		if _, err := gitCommand(ctx, worktree, "fetch", "-q", bundle, "HEAD"); err != nil {
+			return err
+		}
+		if _, err := gitCommand(ctx, worktree, "merge", "-q", "--ff-only", "FETCH_HEAD"); err != nil {
+			return err
+		}
+	}
+	if patch := filepath.Join(tmp, "changes.patch"); fileSize(patch) > 0 {
+		if _, err := gitCommand(ctx, worktree, "apply", "--binary", patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveWorkspace removes a session's workspace volume from the remote
+// host. It is safe to call when there is none.
+func RemoveWorkspace(ctx context.Context, sessionID string) error {
+	vol := WorkspaceVolume(sessionID)
+	if _, err := dockerCommandFunc(ctx, "", "volume", "inspect", vol); err != nil {
+		return nil
+	}
+	if _, err := dockerCommandFunc(ctx, "", "volume", "rm", "-f", vol); err != nil {
+		return fmt.Errorf("%s volume rm %s failed: %w", CurrentRuntime().Name(), vol, err)
+	}
+	return nil
+}
+
+// extractFlatTar writes the regular files of a tar with no subdirectories
+// into dir.
+func extractFlatTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(filepath.Clean(hdr.Name))
+		if hdr.Typeflag != tar.TypeReg || name == "." || name == ".." {
+			continue
+		}
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// RemoteWorkspace syncs session worktrees with the remote host through
+// PushWorkspace, PullWorkspace and RemoveWorkspace.
+type RemoteWorkspace struct{}
+
+func (RemoteWorkspace) Push(ctx context.Context, sessionID, worktree, image string) error {
+	return PushWorkspace(ctx, sessionID, worktree, image)
+}
+
+func (RemoteWorkspace) Pull(ctx context.Context, sessionID, worktree, image string) error {
+	return PullWorkspace(ctx, sessionID, worktree, image)
+}
+
+func (RemoteWorkspace) Remove(ctx context.Context, sessionID string) error {
+	return RemoveWorkspace(ctx, sessionID)
+}
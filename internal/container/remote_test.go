@@ -0,0 +1,185 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSelectRuntime_Remote(t *testing.T) {
+	t.Setenv(RemoteHostEnvVar, "")
+	if _, err := SelectRuntime("remote", lookPathIn("docker")); err == nil {
+		t.Fatal("expected an error without a remote host")
+	}
+
+	t.Setenv(RemoteHostEnvVar, "ssh://erg@builder")
+	rt, err := SelectRuntime("remote", lookPathIn("docker"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd := rt.Command(context.Background(), "info")
+	if !slices.Equal(cmd.Args, []string{"docker", "--host", "ssh://erg@builder", "info"}) {
+		t.Errorf("unexpected args %v", cmd.Args)
+	}
+}
+
+func TestPublishedPortHost(t *testing.T) {
+	orig := CurrentRuntime()
+	defer SetRuntime(orig)
+
+	for host, want := range map[string]string{
+		"ssh://erg@builder":       "builder",
+		"tcp://10.0.0.5:2376":     "10.0.0.5",
+		"unix:///var/docker.sock": "localhost",
+	} {
+		SetRuntime(Remote{Host: host})
+		if got := PublishedPortHost(); got != want {
+			t.Errorf("PublishedPortHost() for %s = %q, want %q", host, got, want)
+		}
+	}
+	SetRuntime(Docker{})
+	if got := PublishedPortHost(); got != "localhost" {
+		t.Errorf("PublishedPortHost() locally = %q, want localhost", got)
+	}
+}
+
+// runGit runs git in dir, failing the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// localWorkspaceDocker fakes the remote host by running workspace scripts
+// locally, against remote instead of the volume at /workspace.
+func localWorkspaceDocker(t *testing.T, remote string) {
+	t.Helper()
+	orig := dockerStreamFunc
+	t.Cleanup(func() { dockerStreamFunc = orig })
+	dockerStreamFunc = func(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+		i := slices.Index(args, "-c")
+		script := strings.ReplaceAll(args[i+1], "/workspace", remote)
+		cmd := exec.CommandContext(ctx, "sh", append([]string{"-c", script}, args[i+2:]...)...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		return cmd.Run()
+	}
+}
+
+func TestPushPullWorkspace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	worktree := t.TempDir()
+	remote := t.TempDir()
+	localWorkspaceDocker(t, remote)
+
+	runGit(t, worktree, "init", "-q", "-b", "issue-1")
+	os.WriteFile(filepath.Join(worktree, "main.go"), []byte("package main\n"), 0o644)
+	os.WriteFile(filepath.Join(worktree, "old.txt"), []byte("old\n"), 0o644)
+	runGit(t, worktree, "add", ".")
+	runGit(t, worktree, "commit", "-q", "-m", "initial")
+	// Uncommitted changes travel too.
+	os.WriteFile(filepath.Join(worktree, "notes.txt"), []byte("untracked\n"), 0o644)
+	os.Remove(filepath.Join(worktree, "old.txt"))
+
+	ctx := context.Background()
+	if err := PushWorkspace(ctx, "sess-1", worktree, "img"); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(remote, "notes.txt")); string(b) != "untracked\n" {
+		t.Errorf("untracked file not copied, got %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(remote, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("deleted file still in the remote copy")
+	}
+	if got := runGit(t, remote, "rev-parse", "--abbrev-ref", "HEAD"); got != "issue-1" {
+		t.Errorf("remote branch = %q, want issue-1", got)
+	}
+
+	// The session commits one change and leaves another uncommitted.
+	os.WriteFile(filepath.Join(remote, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	runGit(t, remote, "commit", "-q", "-am", "add main")
+	os.WriteFile(filepath.Join(remote, "wip.txt"), []byte("wip\n"), 0o644)
+
+	if err := PullWorkspace(ctx, "sess-1", worktree, "img"); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if got := runGit(t, worktree, "log", "-1", "--format=%s"); got != "add main" {
+		t.Errorf("last commit = %q, want the session's", got)
+	}
+	if b, _ := os.ReadFile(filepath.Join(worktree, "wip.txt")); string(b) != "wip\n" {
+		t.Errorf("uncommitted change not copied back, got %q", b)
+	}
+	if got := runGit(t, worktree, "status", "--porcelain"); !strings.Contains(got, "notes.txt") || !strings.Contains(got, "wip.txt") {
+		t.Errorf("unexpected worktree status:\n%s", got)
+	}
+}
+
+func TestPushWorkspace_CommandFailsEarly(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	worktree := t.TempDir()
+	runGit(t, worktree, "init", "-q", "-b", "issue-1")
+	os.WriteFile(filepath.Join(worktree, "big.bin"), make([]byte, 4<<20), 0o644)
+	runGit(t, worktree, "add", ".")
+	runGit(t, worktree, "commit", "-q", "-m", "initial")
+
+	orig := dockerStreamFunc
+	defer func() { dockerStreamFunc = orig }()
+	// The command reads a little of the tar and exits, as docker does when
+	// the host goes away mid-copy.
+	dockerStreamFunc = func(_ context.Context, stdin io.Reader, _ io.Writer, _ ...string) error {
+		io.ReadFull(stdin, make([]byte, 1024))
+		return errors.New("connection lost")
+	}
+
+	err := PushWorkspace(context.Background(), "sess-1", worktree, "img")
+	if err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("err = %v, want the command's error", err)
+	}
+}
+
+func TestPullWorkspace_BadOutput(t *testing.T) {
+	orig := dockerStreamFunc
+	defer func() { dockerStreamFunc = orig }()
+	dockerStreamFunc = func(_ context.Context, _ io.Reader, stdout io.Writer, _ ...string) error {
+		_, err := stdout.Write([]byte("not a tar"))
+		return err
+	}
+
+	err := PullWorkspace(context.Background(), "sess-1", t.TempDir(), "img")
+	if err == nil || !strings.Contains(err.Error(), "reading the remote workspace failed") {
+		t.Fatalf("err = %v, want a read failure", err)
+	}
+}
+
+func TestRemoveWorkspace(t *testing.T) {
+	orig := dockerCommandFunc
+	defer func() { dockerCommandFunc = orig }()
+	var calls []string
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}
+
+	if err := RemoveWorkspace(context.Background(), "sess-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(calls, "volume rm -f erg-ws-sess-1") {
+		t.Errorf("expected the volume removed, got %v", calls)
+	}
+}
@@ -0,0 +1,157 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// RuntimeEnvVar selects the container runtime: docker, podman, nerdctl,
+// remote (a Docker host named by RemoteHostEnvVar), or auto (the default)
+// for the first one installed.
+const RuntimeEnvVar = "ERG_CONTAINER_RUNTIME"
+
+// Runtime is a container engine erg drives through its Docker-compatible
+// CLI: building images, running session containers and inspecting them.
+type Runtime interface {
+	// Name is the runtime's name as selected in config.
+	Name() string
+	// Command returns a command running the runtime's CLI with args.
+	Command(ctx context.Context, args ...string) *exec.Cmd
+	// StartHint tells the user how to get the runtime's engine running when
+	// its CLI is installed but the engine is not reachable.
+	StartHint() string
+}
+
+// Docker drives Docker, including Docker-compatible engines that install
+// the docker CLI: OrbStack, Docker Desktop and Colima.
+type Docker struct{}
+
+func (Docker) Name() string { return "docker" }
+
+func (Docker) Command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "docker", args...)
+}
+
+func (Docker) StartHint() string {
+	return "is OrbStack, Docker Desktop, or Colima running?"
+}
+
+// Podman drives Podman, rootful or rootless. Containers run by rootless
+// Podman map root to the invoking user, so files a session writes to the
+// mounted worktree stay owned by that user.
+type Podman struct{}
+
+func (Podman) Name() string { return "podman" }
+
+func (Podman) Command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "podman", args...)
+}
+
+func (Podman) StartHint() string {
+	return "on macOS, start it with: podman machine start"
+}
+
+// Nerdctl drives containerd through nerdctl. On macOS, Lima installs it as
+// nerdctl.lima, which forwards to the nerdctl inside its VM.
+type Nerdctl struct {
+	// Binary is the nerdctl executable; empty means "nerdctl".
+	Binary string
+}
+
+func (Nerdctl) Name() string { return "nerdctl" }
+
+func (n Nerdctl) Command(ctx context.Context, args ...string) *exec.Cmd {
+	bin := n.Binary
+	if bin == "" {
+		bin = "nerdctl"
+	}
+	return exec.CommandContext(ctx, bin, args...)
+}
+
+func (Nerdctl) StartHint() string {
+	return "are containerd and buildkitd running? With Lima: limactl start; with Colima: colima start --runtime containerd"
+}
+
+// RuntimeNames lists the runtimes that can be selected by name.
+var RuntimeNames = []string{"docker", "podman", "nerdctl", "remote"}
+
+// SelectRuntime returns the runtime named name, or for "" or "auto" the
+// first one installed, checked in the order docker (or Colima, which
+// provides it), podman, nerdctl, nerdctl.lima. lookPath finds executables
+// on PATH, as exec.LookPath does.
+func SelectRuntime(name string, lookPath func(string) (string, error)) (Runtime, error) {
+	installed := func(bin string) bool {
+		_, err := lookPath(bin)
+		return err == nil
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		switch {
+		case installed("docker"), installed("colima"):
+			return Docker{}, nil
+		case installed("podman"):
+			return Podman{}, nil
+		case installed("nerdctl"):
+			return Nerdctl{}, nil
+		case installed("nerdctl.lima"):
+			return Nerdctl{Binary: "nerdctl.lima"}, nil
+		}
+		return nil, fmt.Errorf("no container runtime found on PATH (looked for docker, colima, podman, nerdctl)")
+	case "docker":
+		return Docker{}, nil
+	case "podman":
+		return Podman{}, nil
+	case "nerdctl":
+		if !installed("nerdctl") && installed("nerdctl.lima") {
+			return Nerdctl{Binary: "nerdctl.lima"}, nil
+		}
+		return Nerdctl{}, nil
+	case "remote":
+		host := strings.TrimSpace(os.Getenv(RemoteHostEnvVar))
+		if host == "" {
+			return nil, fmt.Errorf("%s=remote needs %s set to the Docker host, e.g. ssh://erg@builder", RuntimeEnvVar, RemoteHostEnvVar)
+		}
+		return Remote{Host: host}, nil
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q in %s (want auto, %s)", name, RuntimeEnvVar, strings.Join(RuntimeNames, ", "))
+	}
+}
+
+var (
+	runtimeMu      sync.RWMutex
+	currentRuntime Runtime
+)
+
+// SetRuntime makes rt the runtime used for all container operations.
+func SetRuntime(rt Runtime) {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	currentRuntime = rt
+}
+
+// CurrentRuntime returns the runtime used for container operations. Unless
+// SetRuntime was called, it is selected from RuntimeEnvVar on first use,
+// falling back to Docker.
+func CurrentRuntime() Runtime {
+	runtimeMu.RLock()
+	rt := currentRuntime
+	runtimeMu.RUnlock()
+	if rt != nil {
+		return rt
+	}
+
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	if currentRuntime == nil {
+		selected, err := SelectRuntime(os.Getenv(RuntimeEnvVar), exec.LookPath)
+		if err != nil {
+			selected = Docker{}
+		}
+		currentRuntime = selected
+	}
+	return currentRuntime
+}
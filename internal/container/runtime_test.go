@@ -0,0 +1,69 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func lookPathIn(installed ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/local/bin/" + name, nil
+		}
+		return "", fmt.Errorf("%s: not found", name)
+	}
+}
+
+func TestSelectRuntime(t *testing.T) {
+	tests := []struct {
+		name      string
+		selected  string
+		installed []string
+		want      string // binary the runtime invokes
+		wantErr   bool
+	}{
+		{name: "auto prefers docker", installed: []string{"podman", "docker"}, want: "docker"},
+		{name: "auto colima provides docker", installed: []string{"colima"}, want: "docker"},
+		{name: "auto podman", selected: "auto", installed: []string{"podman", "nerdctl"}, want: "podman"},
+		{name: "auto nerdctl", installed: []string{"nerdctl"}, want: "nerdctl"},
+		{name: "auto lima nerdctl", installed: []string{"nerdctl.lima"}, want: "nerdctl.lima"},
+		{name: "auto none installed", wantErr: true},
+		{name: "explicit podman over docker", selected: "Podman", installed: []string{"docker", "podman"}, want: "podman"},
+		{name: "explicit runtime not installed", selected: "podman", want: "podman"},
+		{name: "explicit nerdctl via lima", selected: "nerdctl", installed: []string{"nerdctl.lima"}, want: "nerdctl.lima"},
+		{name: "unknown runtime", selected: "rkt", installed: []string{"docker"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, err := SelectRuntime(tt.selected, lookPathIn(tt.installed...))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got runtime %s", rt.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cmd := rt.Command(context.Background(), "info")
+			if cmd.Args[0] != tt.want {
+				t.Errorf("runtime invokes %q, want %q", cmd.Args[0], tt.want)
+			}
+			if !slices.Equal(cmd.Args[1:], []string{"info"}) {
+				t.Errorf("unexpected args %v", cmd.Args)
+			}
+		})
+	}
+}
+
+func TestCurrentRuntime_SetRuntime(t *testing.T) {
+	orig := CurrentRuntime()
+	defer SetRuntime(orig)
+
+	SetRuntime(Podman{})
+	if got := CurrentRuntime().Name(); got != "podman" {
+		t.Errorf("CurrentRuntime() = %s, want podman", got)
+	}
+}
@@ -0,0 +1,44 @@
+package container
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DetectScoped detects the languages of the given directories of a local
+// repo, relative to its root: the components of a monorepo a session works
+// on. Languages are merged across directories; when two pin different
+// versions of one, the first directory's wins.
+func DetectScoped(repoPath string, dirs []string) []DetectedLang {
+	seen := make(map[Language]bool)
+	var result []DetectedLang
+	for _, dir := range dirs {
+		for _, l := range detectLocal(filepath.Join(repoPath, dir)) {
+			if !seen[l.Lang] {
+				seen[l.Lang] = true
+				result = append(result, l)
+			}
+		}
+	}
+	sortDetected(result)
+	return result
+}
+
+// ComponentDir returns the directory of the monorepo component holding the
+// repo-relative path rel: the nearest directory at or above it, below the
+// repo root, with a language marker. rel need not exist yet, so a file an
+// issue asks to create still finds its component.
+func ComponentDir(repoPath, rel string) (string, bool) {
+	rel = filepath.Clean(rel)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	for dir := rel; dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		for _, m := range markers {
+			if hasMarker(filepath.Join(repoPath, dir), m.file) {
+				return filepath.ToSlash(dir), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package container
+
+import (
+	"testing"
+)
+
+func TestDetectScoped(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module root\n\ngo 1.22\n")
+	writeFile(t, dir, "web/package.json", `{"engines":{"node":"22"}}`)
+	writeFile(t, dir, "services/api/go.mod", "module api\n\ngo 1.23\n")
+	writeFile(t, dir, "services/worker/go.mod", "module worker\n\ngo 1.21\n")
+	writeFile(t, dir, "services/worker/requirements.txt", "requests\n")
+
+	langs := DetectScoped(dir, []string{"web"})
+	if len(langs) != 1 || langs[0].Lang != LangNode {
+		t.Fatalf("expected only Node for web/, got %v", langs)
+	}
+
+	langs = DetectScoped(dir, []string{"services/api", "services/worker"})
+	if len(langs) != 2 {
+		t.Fatalf("expected Go and Python, got %v", langs)
+	}
+	if langs[0].Lang != LangGo || langs[0].Version != "1.23" {
+		t.Errorf("expected the first directory's Go 1.23, got %v", langs[0])
+	}
+	if langs[1].Lang != LangPython {
+		t.Errorf("expected Python second, got %v", langs[1])
+	}
+}
+
+func TestComponentDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module root\n")
+	writeFile(t, dir, "web/package.json", `{}`)
+	writeFile(t, dir, "services/api/go.mod", "module api\n")
+	writeFile(t, dir, "services/api/internal/handler.go", "package internal\n")
+	writeFile(t, dir, "docs/index.md", "# Docs\n")
+
+	tests := []struct {
+		rel  string
+		want string
+		ok   bool
+	}{
+		{"web", "web", true},
+		{"web/src/New.tsx", "web", true},
+		{"services/api/internal/handler.go", "services/api", true},
+		{"services/api/", "services/api", true},
+		{"docs/index.md", "", false},
+		{"main.go", "", false},
+		{"../elsewhere/go.mod", "", false},
+		{"/etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ComponentDir(dir, tt.rel)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ComponentDir(%q) = %q, %v, want %q, %v", tt.rel, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// serviceSessionLabel labels a sidecar service container with the session
+// it belongs to, so StopServices finds them without any saved state.
+const serviceSessionLabel = "erg.session"
+
+// Service is a sidecar container started for a session, reachable from the
+// session container at its name.
+type Service struct {
+	Name  string
+	Image string
+	// Env holds KEY=VALUE pairs.
+	Env []string
+	// HealthCmd, when set, is run with sh -c in the service container until
+	// it succeeds, every HealthInterval for up to HealthTimeout.
+	HealthCmd      string
+	HealthInterval time.Duration
+	HealthTimeout  time.Duration
+}
+
+// ServiceNetwork returns the name of the network a session's services and
+// session container share.
+func ServiceNetwork(sessionID string) string {
+	return "erg-svc-" + sessionID
+}
+
+// serviceContainer returns the container name of a session's service.
+func serviceContainer(sessionID, name string) string {
+	return ServiceNetwork(sessionID) + "-" + name
+}
+
+// EnsureServices starts the session's services that aren't already running
+// on its service network, creating the network if needed, and waits for
+// each started one to pass its healthcheck. It returns the network for the
+// session container to join. On failure, everything started is removed.
+func EnsureServices(ctx context.Context, sessionID string, services []Service) (string, error) {
+	network := ServiceNetwork(sessionID)
+	runtime := CurrentRuntime().Name()
+	if _, err := dockerCommandFunc(ctx, "", "network", "inspect", network); err != nil {
+		if _, err := dockerCommandFunc(ctx, "", "network", "create", "--label", serviceSessionLabel+"="+sessionID, network); err != nil {
+			return "", fmt.Errorf("%s network create %s failed: %w", runtime, network, err)
+		}
+	}
+
+	for _, svc := range services {
+		name := serviceContainer(sessionID, svc.Name)
+		if out, err := dockerCommandFunc(ctx, "", "container", "inspect", "--format", "{{.State.Running}}", name); err == nil {
+			if strings.TrimSpace(string(out)) == "true" {
+				continue
+			}
+			// Left behind stopped, e.g. by a crash: start it afresh.
+			_, _ = dockerCommandFunc(ctx, "", "rm", "-f", name)
+		}
+
+		args := []string{"run", "-d", "--name", name,
+			"--label", serviceSessionLabel + "=" + sessionID,
+			"--network", network, "--network-alias", svc.Name}
+		for _, e := range svc.Env {
+			args = append(args, "-e", e)
+		}
+		args = append(args, svc.Image)
+		if _, err := dockerCommandFunc(ctx, "", args...); err != nil {
+			_ = StopServices(ctx, sessionID)
+			return "", fmt.Errorf("starting service %s (%s) failed: %w", svc.Name, svc.Image, err)
+		}
+		if err := waitHealthy(ctx, name, svc); err != nil {
+			_ = StopServices(ctx, sessionID)
+			return "", fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+	}
+	return network, nil
+}
+
+// waitHealthy runs a service's healthcheck until it passes or times out.
+func waitHealthy(ctx context.Context, name string, svc Service) error {
+	if svc.HealthCmd == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, svc.HealthTimeout)
+	defer cancel()
+	for {
+		_, err := dockerCommandFunc(ctx, "", "exec", name, "sh", "-c", svc.HealthCmd)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not healthy after %s: %w", svc.HealthTimeout, err)
+		case <-time.After(svc.HealthInterval):
+		}
+	}
+}
+
+// StopServices removes a session's service containers and network. It is
+// safe to call for sessions without services.
+func StopServices(ctx context.Context, sessionID string) error {
+	runtime := CurrentRuntime().Name()
+	out, err := dockerCommandFunc(ctx, "", "ps", "-aq", "--filter", "label="+serviceSessionLabel+"="+sessionID)
+	if err != nil {
+		return fmt.Errorf("%s ps failed: %w", runtime, err)
+	}
+	if ids := strings.Fields(string(out)); len(ids) > 0 {
+		if _, err := dockerCommandFunc(ctx, "", append([]string{"rm", "-f"}, ids...)...); err != nil {
+			return fmt.Errorf("%s rm of session services failed: %w", runtime, err)
+		}
+	}
+	network := ServiceNetwork(sessionID)
+	if _, err := dockerCommandFunc(ctx, "", "network", "inspect", network); err != nil {
+		return nil
+	}
+	if _, err := dockerCommandFunc(ctx, "", "network", "rm", network); err != nil {
+		return fmt.Errorf("%s network rm %s failed: %w", runtime, network, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// servicesDocker fakes the container runtime for service tests. Nothing
+// exists yet; healthchecks fail failHealth times before passing. It records
+// every command.
+func servicesDocker(t *testing.T, failHealth int) *[]string {
+	t.Helper()
+	orig := dockerCommandFunc
+	t.Cleanup(func() { dockerCommandFunc = orig })
+	var calls []string
+	dockerCommandFunc = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch {
+		case args[0] == "network" && args[1] == "inspect", args[0] == "container":
+			return nil, fmt.Errorf("not found")
+		case args[0] == "exec":
+			if failHealth > 0 {
+				failHealth--
+				return nil, fmt.Errorf("not ready")
+			}
+			return nil, nil
+		case args[0] == "ps":
+			return []byte("abc123\n"), nil
+		}
+		return nil, nil
+	}
+	return &calls
+}
+
+func TestEnsureServices(t *testing.T) {
+	calls := servicesDocker(t, 2)
+	services := []Service{
+		{Name: "postgres", Image: "postgres:16", Env: []string{"POSTGRES_PASSWORD=test"},
+			HealthCmd: "pg_isready", HealthInterval: time.Millisecond, HealthTimeout: time.Second},
+		{Name: "redis", Image: "redis:7"},
+	}
+
+	network, err := EnsureServices(context.Background(), "sess-1", services)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if network != "erg-svc-sess-1" {
+		t.Errorf("network = %q", network)
+	}
+	for _, want := range []string{
+		"network create --label erg.session=sess-1 erg-svc-sess-1",
+		"run -d --name erg-svc-sess-1-postgres --label erg.session=sess-1 --network erg-svc-sess-1 --network-alias postgres -e POSTGRES_PASSWORD=test postgres:16",
+		"run -d --name erg-svc-sess-1-redis --label erg.session=sess-1 --network erg-svc-sess-1 --network-alias redis redis:7",
+	} {
+		if !slices.Contains(*calls, want) {
+			t.Errorf("expected %q in commands: %v", want, *calls)
+		}
+	}
+	var execs int
+	for _, c := range *calls {
+		if strings.HasPrefix(c, "exec ") {
+			execs++
+		}
+	}
+	if execs != 3 {
+		t.Errorf("expected the healthcheck to run until it passed (3 times), ran %d", execs)
+	}
+}
+
+func TestEnsureServices_UnhealthyTearsDown(t *testing.T) {
+	calls := servicesDocker(t, 1000)
+	services := []Service{{Name: "postgres", Image: "postgres:16",
+		HealthCmd: "pg_isready", HealthInterval: time.Millisecond, HealthTimeout: 20 * time.Millisecond}}
+
+	_, err := EnsureServices(context.Background(), "sess-1", services)
+	if err == nil || !strings.Contains(err.Error(), "not healthy") {
+		t.Fatalf("expected a healthcheck error, got %v", err)
+	}
+	if !slices.Contains(*calls, "rm -f abc123") {
+		t.Errorf("expected started services removed, got %v", *calls)
+	}
+}
+
+func TestStopServices_NoNetwork(t *testing.T) {
+	calls := servicesDocker(t, 0)
+	if err := StopServices(context.Background(), "sess-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(*calls, "network rm erg-svc-sess-1") {
+		t.Errorf("expected no network removal when there is none, got %v", *calls)
+	}
+}
@@ -0,0 +1,82 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Usage is the cumulative resource usage of a running container since it
+// started, read from its cgroup (v2) accounting files.
+type Usage struct {
+	CPUSeconds      float64
+	PeakMemoryBytes int64
+	DiskReadBytes   int64
+	DiskWriteBytes  int64
+}
+
+// usageScript prints cpu.stat, peak (or, on kernels without memory.peak,
+// current) memory, and io.stat, separated by "---" lines.
+const usageScript = `cat /sys/fs/cgroup/cpu.stat; echo ---; ` +
+	`cat /sys/fs/cgroup/memory.peak 2>/dev/null || cat /sys/fs/cgroup/memory.current; echo ---; ` +
+	`cat /sys/fs/cgroup/io.stat 2>/dev/null; true`
+
+// ReadUsage reads the cumulative resource usage of the named running container.
+func ReadUsage(ctx context.Context, name string) (Usage, error) {
+	rt := CurrentRuntime()
+	out, err := rt.Command(ctx, "exec", name, "sh", "-c", usageScript).Output()
+	if err != nil {
+		return Usage{}, fmt.Errorf("%s exec %s failed: %w", rt.Name(), name, err)
+	}
+	return ParseUsage(string(out))
+}
+
+// ParseUsage parses the output of usageScript.
+func ParseUsage(out string) (Usage, error) {
+	sections := strings.SplitN(out, "---\n", 3)
+	for len(sections) < 3 {
+		sections = append(sections, "")
+	}
+
+	var u Usage
+	var sawCPU bool
+	for line := range strings.SplitSeq(sections[0], "\n") {
+		if v, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			usec, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return Usage{}, fmt.Errorf("invalid cpu.stat usage_usec %q", v)
+			}
+			u.CPUSeconds = float64(usec) / 1e6
+			sawCPU = true
+		}
+	}
+	if !sawCPU {
+		return Usage{}, fmt.Errorf("cgroup v2 cpu.stat not available in container")
+	}
+
+	if mem := strings.TrimSpace(sections[1]); mem != "" {
+		u.PeakMemoryBytes, _ = strconv.ParseInt(mem, 10, 64)
+	}
+
+	// io.stat has one line per device: "8:0 rbytes=N wbytes=N rios=N ...".
+	for line := range strings.SplitSeq(sections[2], "\n") {
+		for _, field := range strings.Fields(line) {
+			key, val, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				u.DiskReadBytes += n
+			case "wbytes":
+				u.DiskWriteBytes += n
+			}
+		}
+	}
+	return u, nil
+}
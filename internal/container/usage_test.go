@@ -0,0 +1,35 @@
+package container
+
+import "testing"
+
+func TestParseUsage(t *testing.T) {
+	out := "usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n---\n" +
+		"104857600\n---\n" +
+		"8:0 rbytes=1000 wbytes=2000 rios=3 wios=4 dbytes=0 dios=0\n" +
+		"253:0 rbytes=500 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n"
+
+	u, err := ParseUsage(out)
+	if err != nil {
+		t.Fatalf("ParseUsage: %v", err)
+	}
+	want := Usage{CPUSeconds: 2.5, PeakMemoryBytes: 104857600, DiskReadBytes: 1500, DiskWriteBytes: 2000}
+	if u != want {
+		t.Errorf("ParseUsage = %+v, want %+v", u, want)
+	}
+}
+
+func TestParseUsage_NoIOStat(t *testing.T) {
+	u, err := ParseUsage("usage_usec 1000000\n---\n2048\n---\n")
+	if err != nil {
+		t.Fatalf("ParseUsage: %v", err)
+	}
+	if u.CPUSeconds != 1 || u.PeakMemoryBytes != 2048 || u.DiskReadBytes != 0 {
+		t.Errorf("ParseUsage = %+v", u)
+	}
+}
+
+func TestParseUsage_CgroupV1(t *testing.T) {
+	if _, err := ParseUsage("cat: /sys/fs/cgroup/cpu.stat: No such file or directory\n---\n---\n"); err == nil {
+		t.Error("expected error without cpu.stat usage_usec")
+	}
+}
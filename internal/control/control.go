@@ -0,0 +1,411 @@
+// Package control is the daemon's control socket: a local JSON API on a
+// Unix socket through which `erg daemon` commands (and future UIs) ask a
+// running daemon to change how it takes on work, queue, cancel, or retry
+// work items, and report its items, spend, and session logs, without
+// signalling the process or reading its state files.
+//
+// Each connection carries one newline-terminated JSON Request and gets one
+// JSON Response back. A streaming command such as CommandWatch follows a
+// successful Response with server-sent events until the stream ends or the
+// client hangs up. The socket is created mode 0600 in the user's private
+// sockets directory, so only the daemon's user can drive it.
+package control
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/logger"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// Command is an operation the control socket accepts.
+type Command string
+
+const (
+	// CommandPause stops the daemon picking up new work; in-flight items
+	// carry on.
+	CommandPause Command = "pause"
+	// CommandDrain pauses the daemon and makes it exit once no sessions are
+	// running.
+	CommandDrain Command = "drain"
+	// CommandResume returns a paused or draining daemon to normal.
+	CommandResume Command = "resume"
+	// CommandStatus reports the daemon's mode without changing it.
+	CommandStatus Command = "status"
+	// CommandList reports every work item the daemon tracks.
+	CommandList Command = "list"
+	// CommandQueue queues Request.Issue of Request.Repo as if it had been
+	// polled, skipping readiness checks.
+	CommandQueue Command = "queue"
+	// CommandCancel stops work on Request.Item for good: its session is
+	// stopped and the item fails.
+	CommandCancel Command = "cancel"
+	// CommandRetry queues a finished or quarantined Request.Item again.
+	CommandRetry Command = "retry"
+	// CommandSpend reports the daemon's spend.
+	CommandSpend Command = "spend"
+	// CommandLogs returns the last Request.Lines lines of Request.Item's
+	// session log.
+	CommandLogs Command = "logs"
+	// CommandDLQRetry releases Request.Item from the dead-letter queue and
+	// queues its work item again.
+	CommandDLQRetry Command = "dlq_retry"
+	// CommandDLQDiscard drops Request.Item from the dead-letter queue
+	// without retrying it.
+	CommandDLQDiscard Command = "dlq_discard"
+	// CommandWatch streams Request.Item's live session transcript and step
+	// changes as server-sent events until the item finishes. Request.Lines
+	// is how many already-written transcript lines to start with.
+	CommandWatch Command = "watch"
+)
+
+// streaming reports whether cmd answers with a stream of events after its
+// response.
+func (cmd Command) streaming() bool {
+	return cmd == CommandWatch
+}
+
+// ioTimeout bounds each read and write on a control connection.
+const ioTimeout = 10 * time.Second
+
+// maxSocketPathLen is the portable limit on a Unix socket path (macOS
+// allows 104 bytes, Linux 108).
+const maxSocketPathLen = 104
+
+// Request is what a client sends. Item names a work item by its ID or its
+// issue's tracker ID; Repo is a repo path or owner/repo label and may be
+// omitted when the daemon manages a single repo.
+type Request struct {
+	Command Command `json:"command"`
+	Item    string  `json:"item,omitempty"`
+	Issue   string  `json:"issue,omitempty"`
+	Repo    string  `json:"repo,omitempty"`
+	Lines   int     `json:"lines,omitempty"`
+}
+
+// Response is what the daemon answers: its mode after the command, how much
+// work is still in flight, and whatever the command asked for.
+type Response struct {
+	Mode     string `json:"mode"`
+	Sessions int    `json:"sessions"`
+	Queued   int    `json:"queued"`
+	Error    string `json:"error,omitempty"`
+
+	// Message describes what a queue, cancel, or retry did.
+	Message string   `json:"message,omitempty"`
+	Items   []Item   `json:"items,omitempty"`
+	Spend   *Spend   `json:"spend,omitempty"`
+	Log     []string `json:"log,omitempty"`
+}
+
+// Item is a work item as reported by CommandList.
+type Item struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	Issue     string    `json:"issue"`
+	Title     string    `json:"title,omitempty"`
+	Repo      string    `json:"repo,omitempty"`
+	State     string    `json:"state"`
+	Step      string    `json:"step,omitempty"`
+	Phase     string    `json:"phase,omitempty"`
+	PRURL     string    `json:"pr_url,omitempty"`
+	Session   string    `json:"session,omitempty"`
+	CostUSD   float64   `json:"cost_usd,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Events streamed by CommandWatch.
+const (
+	// EventStatus carries the watched Item whenever its state, step, phase,
+	// or session changes, starting with where it is when the watch begins.
+	EventStatus = "status"
+	// EventLine carries one Line of the session transcript.
+	EventLine = "line"
+	// EventEnd carries the watched Item once it has finished; the stream
+	// ends after it.
+	EventEnd = "end"
+)
+
+// Line is one line of a session transcript: a line of the agent's text, or
+// a tool call with a short description of its input.
+type Line struct {
+	Type string `json:"type"` // "text" or "tool"
+	Text string `json:"text"`
+	Name string `json:"name,omitempty"` // tool name (tool lines only)
+}
+
+// Spend is the daemon's spend as reported by CommandSpend: its running
+// totals and what was spent today and this week.
+type Spend struct {
+	TotalUSD     float64 `json:"total_usd"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TodayUSD     float64 `json:"today_usd"`
+	WeekUSD      float64 `json:"week_usd"`
+}
+
+// Handler carries out a request for the server.
+type Handler func(Request) (Response, error)
+
+// Event is one server-sent event of a streaming command: its name and its
+// JSON-encoded data.
+type Event struct {
+	Name string
+	Data json.RawMessage
+}
+
+// Emitter sends one event of a stream, encoding data as JSON.
+type Emitter func(name string, data any) error
+
+// StreamFunc writes a streaming command's events until it is done, emit
+// fails, or ctx is cancelled because the client hung up or the server is
+// stopping.
+type StreamFunc func(ctx context.Context, emit Emitter) error
+
+// StreamHandler checks a streaming request and returns the response to
+// send first and the stream to follow it. The stream is skipped when the
+// handler fails.
+type StreamHandler func(Request) (Response, StreamFunc, error)
+
+// SocketPath returns the control socket of the daemon whose state key is
+// key. Paths too long for a Unix socket fall back to the temp directory.
+func SocketPath(key string) string {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+	name := "daemon-" + hash[:12] + ".sock"
+	if dir, err := paths.SocketsDir(); err == nil {
+		if p := filepath.Join(dir, name); len(p) <= maxSocketPathLen {
+			return p
+		}
+	}
+	return filepath.Join(os.TempDir(), "erg-"+name)
+}
+
+// Server accepts control connections.
+type Server struct {
+	path    string
+	ln      net.Listener
+	handler Handler
+	stream  StreamHandler // nil = streaming commands are refused
+	log     *slog.Logger
+}
+
+// Listen creates the socket at path, replacing a stale one left by a daemon
+// that did not shut down cleanly. The caller holds the daemon lock, so no
+// live daemon can own it.
+func Listen(path string, handler Handler) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return &Server{path: path, ln: ln, handler: handler, log: logger.WithComponent("control")}, nil
+}
+
+// HandleStreams sets the handler for streaming commands.
+func (s *Server) HandleStreams(h StreamHandler) { s.stream = h }
+
+// Path returns the socket path.
+func (s *Server) Path() string { return s.path }
+
+// Serve handles connections until ctx is cancelled, then removes the socket.
+func (s *Server) Serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		s.ln.Close()
+	}()
+	defer os.Remove(s.path)
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				s.log.Warn("control socket stopped", "error", err)
+			}
+			return
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	var resp Response
+	var req Request
+	var stream StreamFunc
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+	switch {
+	case err != nil:
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	case req.Command.streaming() && s.stream == nil:
+		resp.Error = fmt.Sprintf("command %q is not supported", req.Command)
+	case req.Command.streaming():
+		if resp, stream, err = s.stream(req); err != nil {
+			resp.Error = err.Error()
+			stream = nil
+		}
+	default:
+		if resp, err = s.handler(req); err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		s.log.Debug("failed to write control response", "error", err)
+		return
+	}
+	if stream != nil {
+		s.serveStream(ctx, conn, req, stream)
+	}
+}
+
+// serveStream runs stream, writing its events to conn, until it ends or the
+// client hangs up. The client sends nothing more, so a read returning
+// means it has gone.
+func (s *Server) serveStream(ctx context.Context, conn net.Conn, req Request, stream StreamFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn.SetDeadline(time.Time{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		cancel()
+	}()
+
+	emit := func(name string, data any) error {
+		conn.SetWriteDeadline(time.Now().Add(ioTimeout))
+		return writeEvent(conn, name, data)
+	}
+	if err := stream(ctx, emit); err != nil && ctx.Err() == nil {
+		s.log.Debug("control stream ended", "command", req.Command, "error", err)
+	}
+}
+
+// writeEvent writes one server-sent event.
+func writeEvent(w io.Writer, name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
+
+// readEvent reads the next server-sent event. Comment lines and fields
+// other than event and data are ignored.
+func readEvent(r *bufio.Reader) (Event, error) {
+	var ev Event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if ev.Name != "" || ev.Data != nil {
+				return ev, nil
+			}
+		case strings.HasPrefix(line, "event:"):
+			ev.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			ev.Data = append(ev.Data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+		}
+	}
+}
+
+// Send delivers req to the daemon listening at path and returns its answer.
+// An error the daemon reports comes back as a Go error.
+func Send(ctx context.Context, path string, req Request) (Response, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return Response{}, fmt.Errorf("could not reach the orchestrator's control socket: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	data, _ := json.Marshal(req)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return Response{}, fmt.Errorf("failed to send %s: %w", req.Command, err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Watch sends a streaming request to the daemon listening at path and
+// calls fn with each event it streams back, until the daemon ends the
+// stream, fn fails, or ctx is cancelled. An error the daemon reports
+// before streaming comes back as a Go error.
+func Watch(ctx context.Context, path string, req Request, fn func(Event) error) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return fmt.Errorf("could not reach the orchestrator's control socket: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	conn.SetDeadline(time.Now().Add(ioTimeout))
+	data, _ := json.Marshal(req)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send %s: %w", req.Command, err)
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	conn.SetDeadline(time.Time{})
+	for {
+		ev, err := readEvent(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("stream interrupted: %w", err)
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServer_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.sock")
+	var mu sync.Mutex
+	var got []Request
+	srv, err := Listen(path, func(req Request) (Response, error) {
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+		switch req.Command {
+		case "explode":
+			return Response{}, errors.New("unknown command")
+		case CommandList:
+			return Response{Mode: "running", Items: []Item{{ID: "/repo-42", Issue: "42", State: "active"}}}, nil
+		}
+		return Response{Mode: "paused", Sessions: 2, Queued: 1}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("socket mode = %v, %v; want 0600", fi.Mode().Perm(), err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		srv.Serve(ctx)
+		close(done)
+	}()
+
+	resp, err := Send(t.Context(), path, Request{Command: CommandPause})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Mode != "paused" || resp.Sessions != 2 || resp.Queued != 1 {
+		t.Errorf("response = %+v", resp)
+	}
+	resp, err = Send(t.Context(), path, Request{Command: CommandList})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Issue != "42" {
+		t.Errorf("list response = %+v", resp)
+	}
+	if _, err := Send(t.Context(), path, Request{Command: "explode", Item: "42"}); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+	mu.Lock()
+	if len(got) != 3 || got[0].Command != CommandPause || got[2].Item != "42" {
+		t.Errorf("handled = %+v", got)
+	}
+	mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected socket removed on shutdown")
+	}
+	if _, err := Send(t.Context(), path, Request{Command: CommandStatus}); err == nil {
+		t.Error("expected an error with no daemon listening")
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	a, b := SocketPath("/repo/a"), SocketPath("/repo/b")
+	if a == b {
+		t.Error("expected a socket per daemon key")
+	}
+	if len(a) > maxSocketPathLen || !strings.HasSuffix(a, ".sock") {
+		t.Errorf("SocketPath = %q", a)
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.sock")
+	srv, err := Listen(path, func(req Request) (Response, error) {
+		return Response{}, errors.New("not a streaming command")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hungUp := make(chan struct{})
+	srv.HandleStreams(func(req Request) (Response, StreamFunc, error) {
+		switch req.Item {
+		case "missing":
+			return Response{}, nil, errors.New("no work item for \"missing\"")
+		case "forever":
+			return Response{}, func(ctx context.Context, emit Emitter) error {
+				if err := emit("line", map[string]string{"text": "working"}); err != nil {
+					return err
+				}
+				<-ctx.Done()
+				close(hungUp)
+				return ctx.Err()
+			}, nil
+		}
+		return Response{Mode: "running"}, func(ctx context.Context, emit Emitter) error {
+			for i := range req.Lines {
+				if err := emit("line", map[string]int{"n": i}); err != nil {
+					return err
+				}
+			}
+			return emit("end", map[string]string{"state": "completed"})
+		}, nil
+	})
+	go srv.Serve(t.Context())
+
+	var names []string
+	err = Watch(t.Context(), path, Request{Command: CommandWatch, Item: "42", Lines: 3}, func(ev Event) error {
+		names = append(names, ev.Name+" "+string(ev.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`line {"n":0}`, `line {"n":1}`, `line {"n":2}`, `end {"state":"completed"}`}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("events = %q, want %q", names, want)
+	}
+
+	err = Watch(t.Context(), path, Request{Command: CommandWatch, Item: "missing"}, func(Event) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "no work item") {
+		t.Errorf("expected the handler's error, got %v", err)
+	}
+
+	// A client that stops watching cancels the daemon's stream.
+	ctx, cancel := context.WithCancel(t.Context())
+	err = Watch(ctx, path, Request{Command: CommandWatch, Item: "forever"}, func(Event) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-hungUp:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream not cancelled after the client hung up")
+	}
+}
+
+func TestServer_WatchWithoutStreamHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.sock")
+	srv, err := Listen(path, func(req Request) (Response, error) { return Response{}, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(t.Context())
+
+	err = Watch(t.Context(), path, Request{Command: CommandWatch, Item: "42"}, func(Event) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected streaming to be refused, got %v", err)
+	}
+}
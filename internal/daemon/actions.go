@@ -9,8 +9,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	osexec "os/exec"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/zhubert/erg/internal/daemonstate"
@@ -155,6 +155,8 @@ func (a *codingAction) Execute(ctx context.Context, ac *workflow.ActionContext)
 		return workflow.ActionResult{Error: err}
 	}
 
+	d.postProgress(ctx, item, workflow.ProgressStarted)
+
 	return workflow.ActionResult{Success: true, Async: true}
 }
 
@@ -164,7 +166,9 @@ type createPRAction struct {
 }
 
 // Execute creates a PR. This is a synchronous action.
-// Supports an optional boolean param "draft" (default false) to create a draft PR.
+// Supports an optional boolean param "draft" (default false) to create a draft PR,
+// "review_map" (default false) to add a review map section to the PR body, and
+// "body", a template (or file: reference) the PR body is rendered from.
 func (a *createPRAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
 	d := a.daemon
 	item, ok := d.state.GetWorkItem(ac.WorkItemID)
@@ -173,7 +177,7 @@ func (a *createPRAction) Execute(ctx context.Context, ac *workflow.ActionContext
 	}
 
 	draft := ac.Params.Bool("draft", false)
-	prURL, err := d.createPR(ctx, item, draft)
+	prURL, err := d.createPR(ctx, item, draft, ac.Params.String("body", ""))
 	if err != nil {
 		if errors.Is(err, errNoChanges) {
 			// Coding session made no changes — comment and mark done,
@@ -190,6 +194,17 @@ func (a *createPRAction) Execute(ctx context.Context, ac *workflow.ActionContext
 		return workflow.ActionResult{Error: fmt.Errorf("PR creation failed: %w", err)}
 	}
 
+	if ac.Params.Bool("review_map", false) {
+		if sess, err := d.getSessionOrError(item.SessionID); err == nil {
+			if err := d.stampPRReviewMap(ctx, sess); err != nil {
+				d.logger.Warn("failed to add review map to PR body (non-fatal)", "workItem", item.ID, "error", err)
+			}
+		}
+	}
+
+	item.PRURL = prURL
+	d.postProgress(ctx, item, workflow.ProgressPROpened)
+
 	return workflow.ActionResult{
 		Success: true,
 		Data:    map[string]any{"pr_url": prURL},
@@ -834,6 +849,96 @@ func (a *validateDiffAction) Execute(ctx context.Context, ac *workflow.ActionCon
 	return workflow.ActionResult{Success: true}
 }
 
+// qualityGateAction implements the quality.gate action.
+type qualityGateAction struct {
+	daemon *Daemon
+}
+
+// Execute runs the configured quality checks against the branch. When any
+// check fails, the state fails with workflow.QualityGateFailed and the failing
+// checks' output is stored under workflow.QualityGateReportKey for the state
+// that addresses them.
+func (a *qualityGateAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
+	d := a.daemon
+	item, ok := d.state.GetWorkItem(ac.WorkItemID)
+	if !ok {
+		return workflow.ActionResult{Error: fmt.Errorf("work item not found: %s", ac.WorkItemID)}
+	}
+
+	results, err := d.runQualityGate(ctx, item, ac.Params)
+	if err != nil {
+		return workflow.ActionResult{Error: fmt.Errorf("quality.gate: %w", err)}
+	}
+
+	var failed []string
+	for _, r := range results {
+		if !r.passed {
+			failed = append(failed, r.name)
+		}
+		d.logger.Info("quality check", "workItem", item.ID, "check", r.name, "passed", r.passed, "detail", r.detail)
+	}
+	if len(failed) > 0 {
+		return workflow.ActionResult{
+			Error: errors.New(workflow.QualityGateFailed),
+			Data: map[string]any{
+				workflow.QualityGateReportKey: formatQualityReport(results),
+				"quality_gate_failed":         failed,
+			},
+		}
+	}
+
+	// Clear the report from an earlier failing round.
+	return workflow.ActionResult{
+		Success: true,
+		Data:    map[string]any{workflow.QualityGateReportKey: "", "quality_gate_failed": []string{}},
+	}
+}
+
+// addressFeedbackAction implements the ai.address_feedback action.
+type addressFeedbackAction struct {
+	daemon *Daemon
+}
+
+// Execute resumes the coding session with feedback to address, by default the
+// report of the last failed quality gate.
+func (a *addressFeedbackAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
+	d := a.daemon
+	item, ok := d.state.GetWorkItem(ac.WorkItemID)
+	if !ok {
+		return workflow.ActionResult{Error: fmt.Errorf("work item not found: %s", ac.WorkItemID)}
+	}
+
+	sess, err := d.getSessionOrError(item.SessionID)
+	if err != nil {
+		return workflow.ActionResult{Error: err}
+	}
+
+	maxRounds := ac.Params.Int("max_feedback_rounds", 3)
+	rounds := getFeedbackFixRounds(item.StepData)
+	if rounds >= maxRounds {
+		return workflow.ActionResult{Error: fmt.Errorf("max feedback rounds exceeded (%d/%d)", rounds, maxRounds)}
+	}
+
+	feedback := ac.Params.String("feedback", "")
+	if feedback == "" {
+		feedback, _ = item.StepData[workflow.QualityGateReportKey].(string)
+	}
+	if feedback == "" {
+		return workflow.ActionResult{Error: fmt.Errorf("no feedback to address")}
+	}
+
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.StepData["feedback_fix_rounds"] = rounds + 1
+		it.UpdatedAt = time.Now()
+	})
+
+	if err := d.startAddressFeedback(ctx, item, sess, rounds+1, feedback, ac.Params); err != nil {
+		return workflow.ActionResult{Error: err}
+	}
+
+	return workflow.ActionResult{Success: true, Async: true}
+}
+
 // formatAction implements the git.format action.
 type formatAction struct {
 	daemon *Daemon
@@ -1038,6 +1143,7 @@ func (a *slackNotifyAction) Execute(ctx context.Context, ac *workflow.ActionCont
 
 // slackTemplateData holds the variables available for slack.notify message templates.
 type slackTemplateData struct {
+	workflow.TemplateData
 	Title    string
 	IssueID  string
 	IssueURL string
@@ -1058,7 +1164,8 @@ type slackWebhookPayload struct {
 // Required params:
 //   - webhook_url: Slack incoming webhook URL. Supports $ENV_VAR syntax for secret injection.
 //   - message: Message text. Supports Go text/template syntax with .Title, .IssueID,
-//     .IssueURL, .PRURL, .Branch, and .Status variables.
+//     .IssueURL, .PRURL, .Branch, and .Status variables, plus the .Issue, .Step
+//     and .Spend fields every workflow template can use.
 //
 // Optional params:
 //   - username: display name shown in Slack (defaults to "erg")
@@ -1081,25 +1188,21 @@ func (d *Daemon) sendSlackNotification(ctx context.Context, item daemonstate.Wor
 		return fmt.Errorf("message parameter is required")
 	}
 
-	tmpl, err := template.New("slack").Parse(messageTemplate)
-	if err != nil {
-		return fmt.Errorf("invalid message template: %w", err)
-	}
-
 	data := slackTemplateData{
-		Title:    item.IssueRef.Title,
-		IssueID:  item.IssueRef.ID,
-		IssueURL: item.IssueRef.URL,
-		PRURL:    item.PRURL,
-		Branch:   item.Branch,
-		Status:   string(item.State),
+		TemplateData: d.templateData(ctx, item),
+		Title:        item.IssueRef.Title,
+		IssueID:      item.IssueRef.ID,
+		IssueURL:     item.IssueRef.URL,
+		PRURL:        item.PRURL,
+		Branch:       item.Branch,
+		Status:       string(item.State),
 	}
 
-	var msgBuf bytes.Buffer
-	if err := tmpl.Execute(&msgBuf, data); err != nil {
-		return fmt.Errorf("failed to render message template: %w", err)
+	rendered, err := workflow.RenderTemplate(messageTemplate, data)
+	if err != nil {
+		return fmt.Errorf("message: %w", err)
 	}
-	message := strings.TrimSpace(msgBuf.String())
+	message := strings.TrimSpace(rendered)
 	if message == "" {
 		return fmt.Errorf("rendered message is empty")
 	}
@@ -1111,6 +1214,16 @@ func (d *Daemon) sendSlackNotification(ctx context.Context, item daemonstate.Wor
 		Channel:   params.String("channel", ""),
 	}
 
+	if err := postSlackWebhook(ctx, webhookURL, payload); err != nil {
+		return err
+	}
+
+	d.logger.Info("slack notification sent", "workItem", item.ID, "channel", payload.Channel)
+	return nil
+}
+
+// postSlackWebhook posts payload to a Slack incoming webhook.
+func postSlackWebhook(ctx context.Context, webhookURL string, payload slackWebhookPayload) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Slack payload: %w", err)
@@ -1134,8 +1247,6 @@ func (d *Daemon) sendSlackNotification(ctx context.Context, item daemonstate.Wor
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("slack webhook returned non-200 status: %d", resp.StatusCode)
 	}
-
-	d.logger.Info("slack notification sent", "workItem", item.ID, "channel", payload.Channel)
 	return nil
 }
 
@@ -1145,6 +1256,7 @@ type writePRDescriptionAction struct {
 }
 
 // Execute generates a rich PR description from the diff and updates the open PR body.
+// Supports an optional boolean param "review_map" (default false) to append a review map section.
 func (a *writePRDescriptionAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
 	d := a.daemon
 	item, ok := d.state.GetWorkItem(ac.WorkItemID)
@@ -1157,7 +1269,7 @@ func (a *writePRDescriptionAction) Execute(ctx context.Context, ac *workflow.Act
 		return workflow.ActionResult{Error: err}
 	}
 
-	if err := d.writePRDescription(ctx, item, sess); err != nil {
+	if err := d.writePRDescription(ctx, item, sess, ac.Params.Bool("review_map", false)); err != nil {
 		return workflow.ActionResult{Error: fmt.Errorf("ai.write_pr_description failed: %w", err)}
 	}
 
@@ -1308,8 +1420,303 @@ func (a *webhookPostAction) Execute(ctx context.Context, ac *workflow.ActionCont
 	}
 }
 
-// webhookTemplateData holds work item fields available for webhook body templates.
+// execRunAction implements the exec.run action.
+type execRunAction struct {
+	daemon *Daemon
+}
+
+// Execute runs a user-supplied shell command for a custom workflow state.
+// A zero exit status follows the state's next transition; anything else
+// follows error.
+func (a *execRunAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
+	d := a.daemon
+	item, ok := d.state.GetWorkItem(ac.WorkItemID)
+	if !ok {
+		return workflow.ActionResult{Error: fmt.Errorf("work item not found: %s", ac.WorkItemID)}
+	}
+
+	exitCode, output, err := d.runExecCommand(ctx, item, ac.Params)
+	data := map[string]any{"exit_code": exitCode, "exec_output": output}
+	if err != nil {
+		return workflow.ActionResult{Error: fmt.Errorf("exec.run failed: %w", err), Data: data}
+	}
+
+	return workflow.ActionResult{Success: true, Data: data}
+}
+
+// maxExecOutput bounds how much command output exec.run keeps in step data.
+const maxExecOutput = 4000
+
+// runExecCommand runs the exec.run command with sh -c in the session's
+// worktree, falling back to the repo path once the session is gone.
+// Params:
+//   - command (required): shell command or script path
+//   - env (optional): extra environment variables, as a map
+//   - timeout (optional): command deadline, default 10m
+//
+// The command sees the same ERG_* variables as hooks, plus ERG_WORK_ITEM_ID
+// and ERG_STEP. It returns the exit code (-1 if the command never ran) and
+// the tail of its combined output.
+func (d *Daemon) runExecCommand(ctx context.Context, item daemonstate.WorkItem, params *workflow.ParamHelper) (int, string, error) {
+	command := params.String("command", "")
+	if command == "" {
+		return -1, "", fmt.Errorf("command parameter is required")
+	}
+	command, err := workflow.RenderCommand(command, d.templateData(ctx, item))
+	if err != nil {
+		return -1, "", fmt.Errorf("command: %w", err)
+	}
+	if strings.TrimSpace(command) == "" {
+		// e.g. {{.Commands.Lint}} in a repo with no lint command detected.
+		return -1, "", fmt.Errorf("command rendered empty")
+	}
+
+	hookCtx := workflow.HookContext{
+		Branch:     item.Branch,
+		SessionID:  item.SessionID,
+		IssueID:    item.IssueRef.ID,
+		IssueTitle: item.IssueRef.Title,
+		IssueURL:   item.IssueRef.URL,
+		PRURL:      item.PRURL,
+		Provider:   item.IssueRef.Source,
+	}
+	workDir := ""
+	if sess := d.config.GetSession(item.SessionID); item.SessionID != "" && sess != nil {
+		hookCtx.RepoPath = sess.RepoPath
+		hookCtx.WorkTree = sess.WorkTree
+		workDir = sess.GetWorkDir()
+	} else {
+		hookCtx.RepoPath = d.resolveRepoPath(ctx, item)
+		workDir = hookCtx.RepoPath
+	}
+	if workDir == "" {
+		return -1, "", fmt.Errorf("no working directory found for work item %s", item.ID)
+	}
+
+	env := append(hookCtx.Environ(),
+		"ERG_WORK_ITEM_ID="+item.ID,
+		"ERG_STEP="+item.CurrentStep,
+	)
+	if raw, ok := params.Raw("env").(map[string]any); ok {
+		for k, v := range raw {
+			if vs, ok := v.(string); ok {
+				env = append(env, k+"="+vs)
+			}
+		}
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, params.Duration("timeout", timeoutExecRun))
+	defer cancel()
+
+	cmd := osexec.CommandContext(execCtx, "sh", "-c", command)
+	cmd.Dir = workDir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	output := tailOutput(strings.TrimSpace(string(out)), maxExecOutput)
+	if err != nil {
+		var exitErr *osexec.ExitError
+		if errors.As(err, &exitErr) && execCtx.Err() == nil {
+			return exitErr.ExitCode(), output, fmt.Errorf("command exited with status %d: %s", exitErr.ExitCode(), output)
+		}
+		if execCtx.Err() == context.DeadlineExceeded {
+			return -1, output, fmt.Errorf("command timed out: %s", command)
+		}
+		return -1, output, fmt.Errorf("command failed: %w", err)
+	}
+
+	d.logger.Info("exec.run command completed", "workItem", item.ID, "step", item.CurrentStep, "command", command)
+	return 0, output, nil
+}
+
+// tailOutput keeps the last maxRunes runes of s, where a failing command
+// usually prints its error.
+func tailOutput(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return "... (truncated)\n" + string(runes[len(runes)-maxRunes:])
+}
+
+// createIssueAction implements the issue.create action.
+type createIssueAction struct {
+	daemon *Daemon
+}
+
+// Execute files a follow-up issue in the work item's tracker.
+func (a *createIssueAction) Execute(ctx context.Context, ac *workflow.ActionContext) workflow.ActionResult {
+	d := a.daemon
+	item, ok := d.state.GetWorkItem(ac.WorkItemID)
+	if !ok {
+		return workflow.ActionResult{Error: fmt.Errorf("work item not found: %s", ac.WorkItemID)}
+	}
+
+	if ac.Params.Bool("from_follow_ups", false) {
+		return d.createResultFollowUpIssues(ctx, item, ac.Params)
+	}
+
+	issue, err := d.createFollowUpIssue(ctx, item, ac.Params, "")
+	if err != nil {
+		return workflow.ActionResult{Error: fmt.Errorf("issue.create failed: %w", err)}
+	}
+
+	d.logger.Info("created follow-up issue", "workItem", item.ID, "source", issue.Source, "issue", issue.ID, "url", issue.URL)
+	return workflow.ActionResult{
+		Success: true,
+		Data: map[string]any{
+			"created_issue_id":  issue.ID,
+			"created_issue_url": issue.URL,
+		},
+	}
+}
+
+// createResultFollowUpIssues files one issue per follow-up the last
+// session listed in its result envelope. Issues already filed stay filed if
+// a later one fails; the error names how far it got.
+func (d *Daemon) createResultFollowUpIssues(ctx context.Context, item daemonstate.WorkItem, params *workflow.ParamHelper) workflow.ActionResult {
+	res, _ := workflow.ResultFromStepData(item.StepData)
+	ids := []string{}
+	urls := []string{}
+	for i, followUp := range res.FollowUps {
+		if strings.TrimSpace(followUp) == "" {
+			continue
+		}
+		issue, err := d.createFollowUpIssue(ctx, item, params, followUp)
+		if err != nil {
+			return workflow.ActionResult{Error: fmt.Errorf("issue.create failed on follow-up %d of %d: %w", i+1, len(res.FollowUps), err)}
+		}
+		d.logger.Info("created follow-up issue", "workItem", item.ID, "source", issue.Source, "issue", issue.ID, "url", issue.URL)
+		ids = append(ids, issue.ID)
+		urls = append(urls, issue.URL)
+	}
+	data := map[string]any{
+		"created_issue_ids":  ids,
+		"created_issue_urls": urls,
+	}
+	if len(ids) > 0 {
+		data["created_issue_id"] = ids[0]
+		data["created_issue_url"] = urls[0]
+	}
+	return workflow.ActionResult{Success: true, Data: data}
+}
+
+// issueTemplateData is what issue.create title and body templates can
+// reference: the webhook.post variables plus FollowUp, the result follow-up
+// the issue is filed for when from_follow_ups is set.
+type issueTemplateData struct {
+	webhookTemplateData
+	FollowUp string
+}
+
+// createFollowUpIssue files a new issue in the tracker the work item came from.
+// followUp is the result follow-up the issue is for, if any.
+// Params:
+//   - title (required unless from_follow_ups is set, when it defaults to
+//     the follow-up): issue title template (Go text/template syntax, same
+//     variables as webhook.post plus {{.FollowUp}})
+//   - body (optional): issue body template; a file: reference is read from the repo
+//   - labels (optional): labels to apply (YAML sequence or comma-separated string)
+func (d *Daemon) createFollowUpIssue(ctx context.Context, item daemonstate.WorkItem, params *workflow.ParamHelper, followUp string) (*issues.Issue, error) {
+	titleTemplate := params.String("title", "")
+	if titleTemplate == "" && followUp != "" {
+		titleTemplate = "{{.FollowUp}}"
+	}
+	if titleTemplate == "" {
+		return nil, fmt.Errorf("title parameter is required")
+	}
+
+	labels, err := parseIssueLabels(params)
+	if err != nil {
+		return nil, err
+	}
+
+	source := issues.Source(item.IssueRef.Source)
+	p := d.issueRegistry.GetProvider(source)
+	if p == nil {
+		return nil, fmt.Errorf("%s provider not registered", source)
+	}
+	writer, ok := p.(issues.ProviderWriter)
+	if !ok {
+		return nil, fmt.Errorf("%s provider does not support creating issues", source)
+	}
+
+	repoPath := d.resolveRepoPath(ctx, item)
+	if repoPath == "" {
+		return nil, fmt.Errorf("no repo path found for work item %s", item.ID)
+	}
+
+	data := issueTemplateData{
+		webhookTemplateData: webhookTemplateData{
+			TemplateData: d.templateData(ctx, item),
+			IssueID:      item.IssueRef.ID,
+			IssueTitle:   item.IssueRef.Title,
+			IssueURL:     item.IssueRef.URL,
+			IssueSource:  item.IssueRef.Source,
+			PRURL:        item.PRURL,
+			Branch:       item.Branch,
+			State:        string(item.State),
+			WorkItemID:   item.ID,
+		},
+		FollowUp: followUp,
+	}
+	title, err := workflow.RenderTemplate(titleTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("title: %w", err)
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("title is empty")
+	}
+
+	bodyTemplate, err := workflow.ResolveSystemPrompt(params.String("body", ""), repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve issue body: %w", err)
+	}
+	body, err := workflow.RenderTemplate(bodyTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	createCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer cancel()
+	return writer.CreateIssue(createCtx, repoPath, title, body, labels)
+}
+
+// parseIssueLabels extracts label names from the optional "labels" param.
+// Accepts a YAML sequence ([]any of strings) or a comma-separated string.
+func parseIssueLabels(params *workflow.ParamHelper) ([]string, error) {
+	var names []string
+	switch v := params.Raw("labels").(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("labels entries must be strings, got %T", item)
+			}
+			names = append(names, s)
+		}
+	case string:
+		names = strings.Split(v, ",")
+	default:
+		return nil, fmt.Errorf("labels parameter must be a list or string, got %T", v)
+	}
+
+	var labels []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			labels = append(labels, name)
+		}
+	}
+	return labels, nil
+}
+
+// webhookTemplateData holds work item fields available for webhook body
+// templates: everything in workflow.TemplateData plus the flat fields
+// earlier templates used.
 type webhookTemplateData struct {
+	workflow.TemplateData
 	IssueID     string
 	IssueTitle  string
 	IssueURL    string
@@ -1323,15 +1730,7 @@ type webhookTemplateData struct {
 // interpolateWebhookBody renders a body template string with work item data.
 // Templates use Go text/template syntax, e.g. {{.IssueID}}, {{.PRURL}}.
 func interpolateWebhookBody(bodyTemplate string, data webhookTemplateData) (string, error) {
-	t, err := template.New("webhook").Parse(bodyTemplate)
-	if err != nil {
-		return "", fmt.Errorf("invalid body template: %w", err)
-	}
-	var buf strings.Builder
-	if err := t.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("body template execution failed: %w", err)
-	}
-	return buf.String(), nil
+	return workflow.RenderTemplate(bodyTemplate, data)
 }
 
 // postWebhook POSTs to a webhook URL with an interpolated JSON body.
@@ -1356,14 +1755,15 @@ func (d *Daemon) postWebhook(ctx context.Context, item daemonstate.WorkItem, par
 	expectedStatus := params.Int("expected_status", 200)
 
 	data := webhookTemplateData{
-		IssueID:     item.IssueRef.ID,
-		IssueTitle:  item.IssueRef.Title,
-		IssueURL:    item.IssueRef.URL,
-		IssueSource: item.IssueRef.Source,
-		PRURL:       item.PRURL,
-		Branch:      item.Branch,
-		State:       string(item.State),
-		WorkItemID:  item.ID,
+		TemplateData: d.templateData(ctx, item),
+		IssueID:      item.IssueRef.ID,
+		IssueTitle:   item.IssueRef.Title,
+		IssueURL:     item.IssueRef.URL,
+		IssueSource:  item.IssueRef.Source,
+		PRURL:        item.PRURL,
+		Branch:       item.Branch,
+		State:        string(item.State),
+		WorkItemID:   item.ID,
 	}
 
 	body, err := interpolateWebhookBody(bodyTemplate, data)
@@ -20,6 +20,7 @@ import (
 	"github.com/zhubert/erg/internal/config"
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/ghapp"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
 	"github.com/zhubert/erg/internal/session"
@@ -445,6 +446,191 @@ func TestConfigureRunner_ContainerMode(t *testing.T) {
 	}
 }
 
+func TestApplyNetworkProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         string
+		containerized bool
+		want          string
+	}{
+		{name: "state override", state: "review", containerized: true, want: "erg-offline"},
+		{name: "settings default", state: "coding", containerized: true, want: "erg-registries"},
+		{name: "explicit full resets network", state: "open_pr", containerized: true, want: ""},
+		{name: "non-containerized ignored", state: "review", containerized: false, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDaemon(testConfig())
+			d.workflowConfigs["/test/repo"] = &workflow.Config{
+				States: map[string]*workflow.State{
+					"coding":  {Type: workflow.StateTypeTask, Action: "ai.code"},
+					"review":  {Type: workflow.StateTypeTask, Action: "ai.review", Network: workflow.NetworkOffline},
+					"open_pr": {Type: workflow.StateTypeTask, Action: "github.create_pr", Network: workflow.NetworkFull},
+				},
+				Settings: &workflow.SettingsConfig{
+					Network: workflow.NetworkRegistries,
+					NetworkProfiles: map[string]string{
+						workflow.NetworkRegistries: "erg-registries",
+						workflow.NetworkOffline:    "erg-offline",
+					},
+				},
+			}
+
+			runner := newTrackingRunner("test-session")
+			runner.SetContainerNetwork("stale")
+			sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: tt.containerized}
+
+			d.applyNetworkProfile(runner, sess, daemonstate.WorkItem{CurrentStep: tt.state})
+
+			want := tt.want
+			if !tt.containerized {
+				want = "stale"
+			}
+			if got := runner.GetContainerNetwork(); got != want {
+				t.Errorf("network = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyNetworkProfile_UsesItemWorkflow(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{
+		States: map[string]*workflow.State{"coding": {Type: workflow.StateTypeTask, Action: "ai.code"}},
+	}
+	addNamedWorkflow(t, d, "/test/repo", "locked", &workflow.Config{
+		States: map[string]*workflow.State{"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Network: workflow.NetworkOffline}},
+		Settings: &workflow.SettingsConfig{
+			NetworkProfiles: map[string]string{workflow.NetworkOffline: "erg-offline"},
+		},
+	})
+
+	runner := newTrackingRunner("test-session")
+	sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: true}
+	d.applyNetworkProfile(runner, sess, daemonstate.WorkItem{CurrentStep: "coding", Workflow: "locked"})
+
+	if got := runner.GetContainerNetwork(); got != "erg-offline" {
+		t.Errorf("network = %q, want the named workflow's erg-offline", got)
+	}
+}
+
+// fakeTokenMinter records the repos it was asked to mint tokens for. Its
+// tokens last ttl, an hour when unset.
+type fakeTokenMinter struct {
+	repos []string
+	err   error
+	ttl   time.Duration
+}
+
+func (f *fakeTokenMinter) MintRepoToken(_ context.Context, ownerRepo string) (*ghapp.Token, error) {
+	f.repos = append(f.repos, ownerRepo)
+	if f.err != nil {
+		return nil, f.err
+	}
+	ttl := f.ttl
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	return &ghapp.Token{Value: "ghs_scoped", ExpiresAt: time.Now().Add(ttl), Repository: ownerRepo}, nil
+}
+
+func TestApplyScopedToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerized bool
+		minter        *fakeTokenMinter
+		wantEnv       []string
+		wantRepos     []string
+	}{
+		{
+			name:          "mints token for containerized session",
+			containerized: true,
+			minter:        &fakeTokenMinter{},
+			wantEnv:       []string{"GH_TOKEN=ghs_scoped", "GITHUB_TOKEN=ghs_scoped"},
+			wantRepos:     []string{"owner/repo"},
+		},
+		{
+			name:          "skips non-containerized session",
+			containerized: false,
+			minter:        &fakeTokenMinter{},
+		},
+		{
+			name:          "mint failure leaves env unset",
+			containerized: true,
+			minter:        &fakeTokenMinter{err: errors.New("boom")},
+			wantRepos:     []string{"owner/repo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor(nil)
+			mockExec.AddPrefixMatch("git", []string{"remote", "get-url", "origin"}, exec.MockResponse{
+				Stdout: []byte("https://github.com/owner/repo.git\n"),
+			})
+			d := testDaemonWithExec(testConfig(), mockExec)
+			d.tokenMinter = tt.minter
+
+			runner := newTrackingRunner("test-session")
+			sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: tt.containerized}
+
+			d.applyScopedToken(context.Background(), runner, sess)
+
+			if got := runner.GetContainerEnv(); !slices.Equal(got, tt.wantEnv) {
+				t.Errorf("container env = %v, want %v", got, tt.wantEnv)
+			}
+			if !slices.Equal(tt.minter.repos, tt.wantRepos) {
+				t.Errorf("minted repos = %v, want %v", tt.minter.repos, tt.wantRepos)
+			}
+		})
+	}
+}
+
+func TestRefreshScopedTokens(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("git", []string{"remote", "get-url", "origin"}, exec.MockResponse{
+		Stdout: []byte("https://github.com/owner/repo.git\n"),
+	})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	minter := &fakeTokenMinter{ttl: time.Hour}
+	d.tokenMinter = minter
+
+	sess := &config.Session{ID: "sess-long", RepoPath: "/test/repo", Containerized: true}
+	runner := newTrackingRunner(sess.ID)
+	d.applyScopedToken(context.Background(), runner, sess)
+	d.workers["item-long"] = worker.NewSessionWorker(d, sess, nil, "")
+
+	// A fresh token is left alone.
+	d.refreshScopedTokens(context.Background())
+	if len(minter.repos) != 1 {
+		t.Fatalf("expected no refresh of a fresh token, minted %v", minter.repos)
+	}
+
+	// One about to expire is re-minted onto the session's runner.
+	d.scopedTokens[sess.ID] = scopedToken{runner: runner, ownerRepo: "owner/repo", expiresAt: time.Now().Add(time.Minute)}
+	runner.SetContainerEnv(nil)
+	d.refreshScopedTokens(context.Background())
+	if len(minter.repos) != 2 {
+		t.Fatalf("expected the expiring token re-minted, minted %v", minter.repos)
+	}
+	if got := runner.GetContainerEnv(); !slices.Equal(got, []string{"GH_TOKEN=ghs_scoped", "GITHUB_TOKEN=ghs_scoped"}) {
+		t.Errorf("container env = %v, want the refreshed token", got)
+	}
+	if time.Until(d.scopedTokens[sess.ID].expiresAt) < scopedTokenRefreshMargin {
+		t.Error("expected the refreshed token's expiry recorded")
+	}
+
+	// Once the session stops running its token is forgotten.
+	d.workers["item-long"] = worker.NewDoneWorker()
+	d.refreshScopedTokens(context.Background())
+	if _, ok := d.scopedTokens[sess.ID]; ok {
+		t.Error("expected the finished session's token forgotten")
+	}
+	if len(minter.repos) != 2 {
+		t.Errorf("expected no mint for a finished session, minted %v", minter.repos)
+	}
+}
+
 func TestConfigureRunner_ContainerMode_Disabled(t *testing.T) {
 	cfg := testConfig()
 	d := testDaemon(cfg)
@@ -580,7 +766,7 @@ func TestConfigureRunner_SystemPrompt(t *testing.T) {
 
 	d.configureRunner(runner, sess, "custom prompt", nil)
 
-	if runner.systemPrompt != "custom prompt" {
+	if runner.systemPrompt != "custom prompt"+resultContractDirective {
 		t.Errorf("expected system prompt 'custom prompt', got %q", runner.systemPrompt)
 	}
 }
@@ -599,6 +785,27 @@ func TestConfigureRunner_NoSystemPrompt(t *testing.T) {
 	}
 }
 
+func TestCreateWorkerWithPrompt_ResultRequired(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{States: map[string]*workflow.State{
+		"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Params: map[string]any{"result_required": []any{"plan", "risk_level"}}},
+	}}
+	d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, _ bool, _ []claude.Message) claude.RunnerInterface {
+		return claude.NewMockRunner(sessionID, false, nil)
+	})
+	sess := testSession("sess-rr")
+	d.config.AddSession(*sess)
+	item := daemonstate.WorkItem{ID: "item-rr", SessionID: sess.ID, CurrentStep: "coding"}
+	d.state.AddWorkItem(&item)
+
+	d.createWorkerWithPrompt(context.Background(), item, sess, "go", "Code it.")
+
+	prompt := d.sessionMgr.GetRunner(sess.ID).(*claude.MockRunner).GetSystemPrompt()
+	if !strings.Contains(prompt, "RESULT REQUIREMENTS") || !strings.Contains(prompt, "plan, risk_level") {
+		t.Errorf("expected the required result fields in the system prompt, got %q", prompt)
+	}
+}
+
 func TestStartCoding_SkipsCleanupWhenPRExists(t *testing.T) {
 	cfg := testConfig()
 	cfg.Repos = []string{"/test/repo"}
@@ -2296,6 +2503,145 @@ func TestRunFormatter_FallbackToRepoPath(t *testing.T) {
 	}
 }
 
+func TestExecRunAction_Execute_WorkItemNotFound(t *testing.T) {
+	d := testDaemon(testConfig())
+
+	action := &execRunAction{daemon: d}
+	result := action.Execute(context.Background(), &workflow.ActionContext{
+		WorkItemID: "nonexistent",
+		Params:     workflow.NewParamHelper(map[string]any{"command": "true"}),
+	})
+
+	if result.Success || result.Error == nil {
+		t.Error("expected failure for missing work item")
+	}
+}
+
+func TestExecRunAction_Execute_Success(t *testing.T) {
+	workDir := initTestGitRepo(t)
+
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.WorkTree = workDir
+	cfg.AddSession(*sess)
+
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-1",
+		IssueRef:    config.IssueRef{Source: "github", ID: "42"},
+		SessionID:   "sess-1",
+		Branch:      "feature-42",
+		CurrentStep: "smoke_test",
+	})
+
+	action := &execRunAction{daemon: d}
+	result := action.Execute(context.Background(), &workflow.ActionContext{
+		WorkItemID: "item-1",
+		Params: workflow.NewParamHelper(map[string]any{
+			"command": `echo "$ERG_ISSUE_ID $ERG_BRANCH $ERG_STEP $ERG_WORK_ITEM_ID $TARGET" > out.txt && echo ran`,
+			"env":     map[string]any{"TARGET": "staging"},
+		}),
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if result.Data["exit_code"] != 0 || result.Data["exec_output"] != "ran" {
+		t.Errorf("unexpected result data: %v", result.Data)
+	}
+	got, err := os.ReadFile(filepath.Join(workDir, "out.txt"))
+	if err != nil {
+		t.Fatalf("expected command to run in the worktree: %v", err)
+	}
+	if want := "42 feature-42 smoke_test item-1 staging\n"; string(got) != want {
+		t.Errorf("command env = %q, want %q", got, want)
+	}
+}
+
+func TestExecRunAction_Execute_NonZeroExit(t *testing.T) {
+	workDir := initTestGitRepo(t)
+
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.WorkTree = workDir
+	cfg.AddSession(*sess)
+
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "42"},
+		SessionID: "sess-1",
+	})
+
+	action := &execRunAction{daemon: d}
+	result := action.Execute(context.Background(), &workflow.ActionContext{
+		WorkItemID: "item-1",
+		Params:     workflow.NewParamHelper(map[string]any{"command": "echo 'smoke test failed' >&2; exit 3"}),
+	})
+
+	if result.Success || result.Error == nil {
+		t.Fatal("expected failure for non-zero exit")
+	}
+	if !strings.Contains(result.Error.Error(), "status 3") || !strings.Contains(result.Error.Error(), "smoke test failed") {
+		t.Errorf("expected exit status and output in error, got: %v", result.Error)
+	}
+	if result.Data["exit_code"] != 3 {
+		t.Errorf("expected exit_code 3, got %v", result.Data["exit_code"])
+	}
+}
+
+func TestRunExecCommand_Timeout(t *testing.T) {
+	workDir := initTestGitRepo(t)
+
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.WorkTree = workDir
+	cfg.AddSession(*sess)
+
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", SessionID: "sess-1"})
+
+	item, _ := d.state.GetWorkItem("item-1")
+	params := workflow.NewParamHelper(map[string]any{"command": "sleep 5", "timeout": "100ms"})
+	code, _, err := d.runExecCommand(context.Background(), item, params)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+	if code != -1 {
+		t.Errorf("expected exit code -1 on timeout, got %d", code)
+	}
+}
+
+func TestRunExecCommand_FallbackToRepoPath(t *testing.T) {
+	repoDir := initTestGitRepo(t)
+
+	d := testDaemon(testConfig())
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-1",
+		StepData: map[string]any{"_repo_path": repoDir},
+	})
+
+	item, _ := d.state.GetWorkItem("item-1")
+	_, output, err := d.runExecCommand(context.Background(), item, workflow.NewParamHelper(map[string]any{"command": `echo "$ERG_REPO_PATH"; pwd`}))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) != 2 || lines[0] != repoDir {
+		t.Errorf("expected ERG_REPO_PATH %q, got output %q", repoDir, output)
+	}
+}
+
+func TestTailOutput(t *testing.T) {
+	if got := tailOutput("short", 10); got != "short" {
+		t.Errorf("tailOutput(short) = %q", got)
+	}
+	got := tailOutput("0123456789", 4)
+	if !strings.HasSuffix(got, "6789") || !strings.HasPrefix(got, "... (truncated)") {
+		t.Errorf("tailOutput kept the wrong end: %q", got)
+	}
+}
+
 func TestTruncateLogs(t *testing.T) {
 	const maxLogLen = 50000
 	const truncSuffix = "\n\n... (truncated)"
@@ -2804,7 +3150,7 @@ func TestCreatePR_NoChanges_ReturnsError(t *testing.T) {
 	})
 
 	item, _ := d.state.GetWorkItem("item-no-changes")
-	_, err := d.createPR(context.Background(), item, false)
+	_, err := d.createPR(context.Background(), item, false, "")
 	if err == nil {
 		t.Fatal("expected error when creating PR with no changes")
 	}
@@ -3076,7 +3422,7 @@ func TestCreatePR_ExistingPR_ReturnsWithoutError(t *testing.T) {
 	})
 
 	item, _ := d.state.GetWorkItem("item-existing")
-	url, err := d.createPR(context.Background(), item, false)
+	url, err := d.createPR(context.Background(), item, false, "")
 	if err != nil {
 		t.Fatalf("expected no error for existing PR, got: %v", err)
 	}
@@ -4109,6 +4455,15 @@ func (m *mockCommentProvider) Comment(_ context.Context, repoPath, issueID, body
 	m.comments = append(m.comments, mockCommentCall{repoPath: repoPath, issueID: issueID, body: body})
 	return m.commentErr
 }
+func (m *mockCommentProvider) Claim(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockCommentProvider) Unclaim(_ context.Context, _, _ string) error {
+	return nil
+}
+func (m *mockCommentProvider) CloseIssue(_ context.Context, _, _ string) error {
+	return nil
+}
 
 // mockIdempotentCommentProvider is a test double that also implements
 // ProviderGateChecker and ProviderCommentUpdater to support idempotent comments.
@@ -4143,6 +4498,15 @@ func (m *mockIdempotentCommentProvider) Comment(_ context.Context, repoPath, iss
 	m.comments = append(m.comments, mockCommentCall{repoPath: repoPath, issueID: issueID, body: body})
 	return m.commentErr
 }
+func (m *mockIdempotentCommentProvider) Claim(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockIdempotentCommentProvider) Unclaim(_ context.Context, _, _ string) error {
+	return nil
+}
+func (m *mockIdempotentCommentProvider) CloseIssue(_ context.Context, _, _ string) error {
+	return nil
+}
 func (m *mockIdempotentCommentProvider) CheckIssueHasLabel(_ context.Context, _ string, _ string, _ string) (bool, error) {
 	return false, nil
 }
@@ -8796,7 +9160,7 @@ func TestStartPlanning_UsesCustomSystemPrompt(t *testing.T) {
 	if capturedRunner == nil {
 		t.Fatal("expected runner factory to be called")
 	}
-	if capturedRunner.systemPrompt != customPrompt {
+	if capturedRunner.systemPrompt != customPrompt+resultContractDirective {
 		t.Errorf("expected custom prompt %q, got %q", customPrompt, capturedRunner.systemPrompt)
 	}
 }
@@ -8841,7 +9205,7 @@ func TestStartPlanning_UsesDefaultPromptWhenNoCustom(t *testing.T) {
 	if capturedRunner == nil {
 		t.Fatal("expected runner factory to be called")
 	}
-	if capturedRunner.systemPrompt != DefaultPlanningSystemPrompt {
+	if capturedRunner.systemPrompt != DefaultPlanningSystemPrompt+resultContractDirective {
 		t.Errorf("expected DefaultPlanningSystemPrompt when no custom prompt, got %q", capturedRunner.systemPrompt)
 	}
 }
@@ -10313,7 +10677,7 @@ func TestStartDocumenting_UsesDefaultPrompt(t *testing.T) {
 	if capturedRunner == nil {
 		t.Fatal("expected runner factory to be called")
 	}
-	if capturedRunner.systemPrompt != DefaultDocumentingSystemPrompt {
+	if capturedRunner.systemPrompt != DefaultDocumentingSystemPrompt+resultContractDirective {
 		t.Errorf("expected DefaultDocumentingSystemPrompt, got %q", capturedRunner.systemPrompt)
 	}
 }
@@ -10346,7 +10710,7 @@ func TestStartDocumenting_UsesCustomSystemPrompt(t *testing.T) {
 	if capturedRunner == nil {
 		t.Fatal("expected runner factory to be called")
 	}
-	if capturedRunner.systemPrompt != customPrompt {
+	if capturedRunner.systemPrompt != customPrompt+resultContractDirective {
 		t.Errorf("expected custom prompt %q, got %q", customPrompt, capturedRunner.systemPrompt)
 	}
 }
@@ -10592,6 +10956,78 @@ func TestInjectScheduledIssue_SkippedWhenConfigSavePaused(t *testing.T) {
 	}
 }
 
+func TestInjectScheduledIssue_NamedTriggerWithPrompt(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	d.maxConcurrent = 5
+
+	trigger := workflow.TriggerConfig{Schedule: "0 2 * * 1", State: "coding", Name: "update-deps", Prompt: "Update all dependencies to their latest minor versions."}
+	d.injectScheduledIssue(context.Background(), "/test/repo", trigger)
+
+	queued := d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)
+	if len(queued) != 1 {
+		t.Fatalf("expected 1 queued item, got %d", len(queued))
+	}
+	item := queued[0]
+	if item.IssueRef.Title != "Scheduled: update-deps" {
+		t.Errorf("expected title from trigger name, got %q", item.IssueRef.Title)
+	}
+	if item.StepData["issue_body"] != trigger.Prompt {
+		t.Errorf("expected prompt as issue body, got %v", item.StepData["issue_body"])
+	}
+	if item.CurrentStep != "coding" {
+		t.Errorf("expected CurrentStep=coding, got %q", item.CurrentStep)
+	}
+
+	// A differently named trigger starting at the same state is not blocked.
+	d.injectScheduledIssue(context.Background(), "/test/repo", workflow.TriggerConfig{Schedule: "0 3 * * *", State: "coding", Name: "update"})
+	if n := len(d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)); n != 2 {
+		t.Errorf("expected 2 queued items for distinct triggers, got %d", n)
+	}
+	// The same trigger is.
+	d.injectScheduledIssue(context.Background(), "/test/repo", trigger)
+	if n := len(d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)); n != 2 {
+		t.Errorf("expected overlapping firing to be skipped, got %d queued", n)
+	}
+}
+
+func TestInjectScheduledIssue_OverlapAllow(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	d.maxConcurrent = 5
+
+	trigger := workflow.TriggerConfig{Schedule: "*/15 * * * *", State: "coding", Overlap: workflow.TriggerOverlapAllow}
+	d.injectScheduledIssue(context.Background(), "/test/repo", trigger)
+	d.injectScheduledIssue(context.Background(), "/test/repo", trigger)
+
+	if n := len(d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)); n != 2 {
+		t.Errorf("expected 2 queued items with overlap: allow, got %d", n)
+	}
+}
+
+func TestScheduledTriggerKey(t *testing.T) {
+	tests := []struct {
+		name string
+		item daemonstate.WorkItem
+		want string
+	}{
+		{"recorded key", daemonstate.WorkItem{IssueRef: config.IssueRef{ID: "scheduled-/test/repo-update-deps-1"}, StepData: map[string]any{"_trigger": "update-deps"}}, "update-deps"},
+		{"key from ID", daemonstate.WorkItem{IssueRef: config.IssueRef{ID: "scheduled-/test/repo-coding-12345"}}, "coding"},
+		{"other repo", daemonstate.WorkItem{IssueRef: config.IssueRef{ID: "scheduled-/other/repo-coding-12345"}, StepData: map[string]any{"_trigger": "coding"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduledTriggerKey("/test/repo", tt.item); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFetchIssueComments_SkipsSyntheticItems(t *testing.T) {
 	cfg := testConfig()
 	cfg.Repos = []string{"/test/repo"}
@@ -10733,3 +11169,170 @@ func TestStartScheduler_RegistersTriggers(t *testing.T) {
 		t.Error("expected at least one cron entry to be registered")
 	}
 }
+
+func TestTriggerSpec(t *testing.T) {
+	utc := &workflow.Config{}
+	ny := &workflow.Config{Settings: &workflow.SettingsConfig{Timezone: "America/New_York"}}
+
+	tests := []struct {
+		name  string
+		cfg   *workflow.Config
+		sched string
+		tz    string
+		want  string
+	}{
+		{"default UTC", utc, "0 9 * * 1-5", "", "CRON_TZ=UTC 0 9 * * 1-5"},
+		{"repo timezone", ny, "0 9 * * 1-5", "", "CRON_TZ=America/New_York 0 9 * * 1-5"},
+		{"trigger timezone", ny, "0 9 * * 1-5", "Europe/London", "CRON_TZ=Europe/London 0 9 * * 1-5"},
+		{"explicit CRON_TZ wins", ny, "CRON_TZ=Europe/Berlin 0 9 * * *", "", "CRON_TZ=Europe/Berlin 0 9 * * *"},
+		{"explicit TZ wins", ny, "TZ=Asia/Tokyo @daily", "", "TZ=Asia/Tokyo @daily"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := triggerSpec(tt.cfg, workflow.TriggerConfig{Schedule: tt.sched, Timezone: tt.tz})
+			if got != tt.want {
+				t.Errorf("triggerSpec = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateIssueAction_Execute(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+
+	fake := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(fake)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-7", Title: "Fix login"},
+		PRURL:    "https://github.com/owner/repo/pull/9",
+	})
+
+	action := &createIssueAction{daemon: d}
+	params := workflow.NewParamHelper(map[string]any{
+		"title":  "Follow-up to {{.IssueID}}: {{.IssueTitle}}",
+		"body":   "Found while working on {{.PRURL}}",
+		"labels": []any{"tech-debt", " ai-assisted "},
+	})
+	result := action.Execute(context.Background(), &workflow.ActionContext{WorkItemID: "item-1", Params: params})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if len(fake.CreateIssueCalls) != 1 {
+		t.Fatalf("expected 1 CreateIssue call, got %d", len(fake.CreateIssueCalls))
+	}
+	want := []string{"Follow-up to ENG-7: Fix login", "Found while working on https://github.com/owner/repo/pull/9", "tech-debt", "ai-assisted"}
+	if got := fake.CreateIssueCalls[0].Args; !slices.Equal(got, want) {
+		t.Errorf("CreateIssue args = %q, want %q", got, want)
+	}
+	if result.Data["created_issue_id"] != "new-1" {
+		t.Errorf("expected created_issue_id=new-1 in Data, got: %v", result.Data)
+	}
+}
+
+func TestCreateIssueAction_Execute_FromFollowUps(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+
+	fake := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(fake)
+	res := workflow.StateResult{Status: workflow.ResultStatusSuccess, Summary: "done", FollowUps: []string{"Add metrics", " ", "Document the flag"}}
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-7", Title: "Fix login"},
+		StepData: map[string]any{workflow.ResultStepDataKey: res.ToStepData()},
+	})
+
+	action := &createIssueAction{daemon: d}
+	params := workflow.NewParamHelper(map[string]any{
+		"from_follow_ups": true,
+		"body":            "Left over from {{.IssueID}}: {{.FollowUp}}",
+	})
+	result := action.Execute(context.Background(), &workflow.ActionContext{WorkItemID: "item-1", Params: params})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if len(fake.CreateIssueCalls) != 2 {
+		t.Fatalf("expected an issue per follow-up, got %d", len(fake.CreateIssueCalls))
+	}
+	if got := fake.CreateIssueCalls[1].Args; got[0] != "Document the flag" || got[1] != "Left over from ENG-7: Document the flag" {
+		t.Errorf("CreateIssue args = %q", got)
+	}
+	if ids, _ := result.Data["created_issue_ids"].([]string); len(ids) != 2 || result.Data["created_issue_id"] != ids[0] {
+		t.Errorf("unexpected Data: %v", result.Data)
+	}
+}
+
+func TestCreateIssueAction_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		params  map[string]any
+		wantErr string
+	}{
+		{"missing title", "linear", map[string]any{"body": "x"}, "title parameter is required"},
+		{"blank title", "linear", map[string]any{"title": "  "}, "title is empty"},
+		{"bad labels", "linear", map[string]any{"title": "t", "labels": 3}, "labels parameter must be a list or string"},
+		{"unregistered provider", "asana", map[string]any{"title": "t"}, "asana provider not registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Repos = []string{"/test/repo"}
+			d := testDaemon(cfg)
+			d.repoFilter = "/test/repo"
+			d.issueRegistry = issues.NewProviderRegistry(issues.NewFakeProvider(issues.SourceLinear))
+			d.state.AddWorkItem(&daemonstate.WorkItem{
+				ID:       "item-1",
+				IssueRef: config.IssueRef{Source: tt.source, ID: "1"},
+			})
+
+			action := &createIssueAction{daemon: d}
+			result := action.Execute(context.Background(), &workflow.ActionContext{
+				WorkItemID: "item-1",
+				Params:     workflow.NewParamHelper(tt.params),
+			})
+			if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got success=%v err=%v", tt.wantErr, result.Success, result.Error)
+			}
+		})
+	}
+}
+
+func TestCreateIssueAction_Execute_ProviderWithoutWriter(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	d.issueRegistry = issues.NewProviderRegistry(&mockCommentProvider{src: issues.SourceAsana})
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "asana", ID: "1"},
+	})
+
+	action := &createIssueAction{daemon: d}
+	result := action.Execute(context.Background(), &workflow.ActionContext{
+		WorkItemID: "item-1",
+		Params:     workflow.NewParamHelper(map[string]any{"title": "t"}),
+	})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "does not support creating issues") {
+		t.Errorf("expected unsupported-provider error, got: %v", result.Error)
+	}
+}
+
+func TestParseIssueLabels_CommaSeparated(t *testing.T) {
+	got, err := parseIssueLabels(workflow.NewParamHelper(map[string]any{"labels": "bug, tech-debt,,"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(got, []string{"bug", "tech-debt"}) {
+		t.Errorf("got %q, want [bug tech-debt]", got)
+	}
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// auditSummaryLen bounds the summary of an audit entry; auditBodyLen bounds
+// the comment body kept in its details.
+const (
+	auditSummaryLen = 80
+	auditBodyLen    = 2000
+)
+
+// audit records an action in a work item's audit trail (see `erg history`).
+func (d *Daemon) audit(itemID string, kind daemonstate.AuditKind, summary string, details map[string]any) {
+	if d.state == nil || itemID == "" {
+		return
+	}
+	d.state.RecordAudit(daemonstate.AuditEntry{WorkItemID: itemID, Kind: kind, Summary: summary, Details: details})
+}
+
+// auditComment records a comment posted on an item's issue.
+func (d *Daemon) auditComment(itemID, body string) {
+	d.audit(itemID, daemonstate.AuditComment, "commented: "+commentSummary(body),
+		map[string]any{"body": truncateRunes(body, auditBodyLen)})
+}
+
+// RecordToolUse records the shell commands a session runs and the files it
+// writes in its work item's audit trail. Other tools are not recorded.
+func (d *Daemon) RecordToolUse(sessionID, tool, input string) {
+	var kind daemonstate.AuditKind
+	switch tool {
+	case "Bash":
+		kind = daemonstate.AuditCommand
+	case "Edit", "MultiEdit", "Write", "NotebookEdit":
+		kind = daemonstate.AuditFile
+	default:
+		return
+	}
+	item, ok := d.state.GetWorkItemBySessionID(sessionID)
+	if !ok {
+		return
+	}
+	d.audit(item.ID, kind, fmt.Sprintf("%s: %s", tool, input),
+		map[string]any{"tool": tool, "input": input, "session": sessionID})
+}
+
+// commentSummary returns the first non-blank line of a comment, shortened
+// for an audit summary. Markers erg adds to its comments are skipped.
+func commentSummary(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		return truncateRunes(line, auditSummaryLen)
+	}
+	return ""
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with "…".
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
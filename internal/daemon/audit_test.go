@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+func TestRecordToolUse(t *testing.T) {
+	d := testDaemon(testConfig())
+	addCodingItem(d, "item-1", 0)
+
+	d.RecordToolUse("sess-item-1", "Bash", "go test ./...")
+	d.RecordToolUse("sess-item-1", "Edit", "internal/foo.go")
+	d.RecordToolUse("sess-item-1", "Read", "internal/bar.go")
+	d.RecordToolUse("sess-unknown", "Bash", "rm -rf /")
+
+	entries, err := d.state.AuditLog("item-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []daemonstate.AuditKind
+	for _, e := range entries {
+		if e.Kind != daemonstate.AuditTransition {
+			got = append(got, e.Kind)
+		}
+	}
+	if len(got) != 2 || got[0] != daemonstate.AuditCommand || got[1] != daemonstate.AuditFile {
+		t.Errorf("kinds = %v, want command then file", got)
+	}
+}
+
+func TestAuditComment(t *testing.T) {
+	d := testDaemon(testConfig())
+	addCodingItem(d, "item-1", 0)
+
+	d.auditComment("item-1", "\n\nPR opened: https://example.com/pr/1\n\nMore detail.")
+	entries, err := d.state.AuditLog("item-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := entries[len(entries)-1]
+	if last.Kind != daemonstate.AuditComment || last.Summary != "commented: PR opened: https://example.com/pr/1" {
+		t.Errorf("entry = %+v", last)
+	}
+}
@@ -0,0 +1,27 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+)
+
+// authRotationInterval is how often stale container auth files are looked for.
+const authRotationInterval = 10 * time.Minute
+
+// rotateAuthFiles removes erg-auth-* files older than claude.AuthFileTTL,
+// left behind by sessions that never cleaned up, so credentials don't
+// outlive the tokens in them. Live sessions are unaffected: a container
+// reads its file only as it starts, and each start writes a fresh one.
+func (d *Daemon) rotateAuthFiles() {
+	if time.Since(d.lastAuthRotationAt) < authRotationInterval {
+		return
+	}
+	d.lastAuthRotationAt = time.Now()
+
+	if n, err := claude.RotateAuthFiles(claude.AuthFileTTL); err != nil {
+		d.logger.Warn("failed to rotate stale auth files", "error", err)
+	} else if n > 0 {
+		d.logger.Info("removed stale auth files", "count", n, "ttl", claude.AuthFileTTL)
+	}
+}
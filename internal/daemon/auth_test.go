@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestRotateAuthFiles_RemovesStaleFilesPeriodically(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tmp, "state"))
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+	stateDir, err := paths.StateDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(stateDir, 0o700)
+	stale := filepath.Join(stateDir, "erg-auth-old")
+	writeStale := func() {
+		os.WriteFile(stale, []byte("ANTHROPIC_API_KEY=x"), 0600)
+		old := time.Now().Add(-claude.AuthFileTTL - time.Minute)
+		os.Chtimes(stale, old, old)
+	}
+	d := testDaemon(testConfig())
+
+	writeStale()
+	d.rotateAuthFiles()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatal("expected the stale auth file removed")
+	}
+
+	writeStale()
+	d.rotateAuthFiles()
+	if _, err := os.Stat(stale); err != nil {
+		t.Error("expected no second rotation within the interval")
+	}
+}
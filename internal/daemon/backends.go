@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zhubert/erg/internal/agentbackend"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// sessionRunner returns the session's runner for the agent backend the
+// item's current state runs on, in the workflow the item runs on. When the
+// session's runner is on another backend it is replaced, carrying the
+// conversation over, so a cheap model can triage and a strong one code in
+// the same session. On error the session's current runner is returned
+// with it.
+func (d *Daemon) sessionRunner(sess *config.Session, item daemonstate.WorkItem) (claude.RunnerInterface, error) {
+	stateName := item.CurrentStep
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	name := wfCfg.BackendName(stateName)
+
+	d.mu.Lock()
+	current := d.sessionBackends[sess.ID]
+	d.mu.Unlock()
+	if name == current {
+		return d.sessionMgr.GetOrCreateRunner(sess), nil
+	}
+
+	log := d.logger.With("sessionID", sess.ID, "state", stateName)
+
+	if name == "" {
+		d.sessionMgr.RemoveRunner(sess.ID)
+		runner := d.sessionMgr.GetOrCreateRunner(sess)
+		runner.SetModel(d.resolveStateModel(wfCfg, stateName))
+		d.setSessionBackend(sess.ID, "")
+		log.Info("switched session to the claude backend", "from", current)
+		return runner, nil
+	}
+
+	b := wfCfg.Backend(stateName)
+	if b == nil {
+		return d.sessionMgr.GetOrCreateRunner(sess), fmt.Errorf("agent backend %q is not defined", name)
+	}
+	if d.offline && !b.IsLocal() {
+		return d.sessionMgr.GetOrCreateRunner(sess), fmt.Errorf("%w: agent backend %q needs the network; use a local backend (provider: local) offline", container.ErrOffline, name)
+	}
+	if sess.Containerized && container.IsRemote() {
+		return d.sessionMgr.GetOrCreateRunner(sess), fmt.Errorf("agent backend %q cannot run sessions on a remote container host", name)
+	}
+	keyEnv := b.APIKeyEnv
+	if keyEnv == "" {
+		keyEnv = agentbackend.DefaultAPIKeyEnv(b.Provider)
+	}
+	cfg := agentbackend.Config{
+		Provider:      b.Provider,
+		Model:         b.Model,
+		BaseURL:       b.BaseURL,
+		APIKey:        os.Getenv(keyEnv),
+		Region:        b.Region,
+		InputPrice:    b.InputPrice,
+		OutputPrice:   b.OutputPrice,
+		ContextWindow: b.ContextWindow,
+	}
+
+	var history []claude.Message
+	if r := d.sessionMgr.GetRunner(sess.ID); r != nil {
+		history = r.GetMessages()
+	} else if saved, err := config.LoadSessionMessages(sess.ID); err == nil {
+		for _, m := range saved {
+			history = append(history, claude.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+
+	newRunner := d.newBackendRunner
+	if newRunner == nil {
+		newRunner = func(cfg agentbackend.Config, sessionID, workDir, repoPath string, history []claude.Message) (claude.RunnerInterface, error) {
+			return agentbackend.NewRunner(cfg, sessionID, workDir, repoPath, history)
+		}
+	}
+	runner, err := newRunner(cfg, sess.ID, sess.GetWorkDir(), sess.RepoPath, history)
+	if err != nil {
+		return d.sessionMgr.GetOrCreateRunner(sess), fmt.Errorf("agent backend %q: %w", name, err)
+	}
+	d.sessionMgr.RemoveRunner(sess.ID)
+	d.sessionMgr.SetRunner(sess.ID, runner)
+	d.setSessionBackend(sess.ID, name)
+	log.Info("switched session to agent backend", "backend", name, "provider", b.Provider, "model", b.Model, "from", current)
+	return runner, nil
+}
+
+// setSessionBackend records the agent backend a session's runner is on;
+// "" is the Claude CLI.
+func (d *Daemon) setSessionBackend(sessionID, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if name == "" {
+		delete(d.sessionBackends, sessionID)
+		return
+	}
+	if d.sessionBackends == nil {
+		d.sessionBackends = make(map[string]string)
+	}
+	d.sessionBackends[sessionID] = name
+}
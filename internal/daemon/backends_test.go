@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhubert/erg/internal/agentbackend"
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// backendTestDaemon returns a daemon whose /test/repo workflow triages on
+// a cheap OpenAI-compatible backend and codes on the Claude CLI, with
+// item-be in triage. Backend runners are mocks whose configs are recorded.
+func backendTestDaemon(t *testing.T) (*Daemon, *[]agentbackend.Config) {
+	t.Helper()
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:  "triage",
+		Source: workflow.SourceConfig{Provider: "github"},
+		Backends: map[string]*workflow.BackendConfig{
+			"cheap": {Provider: workflow.BackendOpenAI, Model: "gpt-4o-mini", APIKeyEnv: "CHEAP_KEY"},
+		},
+		States: map[string]*workflow.State{
+			"triage": {Type: workflow.StateTypeTask, Action: "ai.code", Backend: "cheap", Next: "coding"},
+			"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Model: "opus", Next: "done"},
+			"done":   {Type: workflow.StateTypeSucceed},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+	d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, _ bool, _ []claude.Message) claude.RunnerInterface {
+		return claude.NewMockRunner(sessionID, false, nil)
+	})
+	var created []agentbackend.Config
+	d.newBackendRunner = func(cfg agentbackend.Config, sessionID, _, _ string, history []claude.Message) (claude.RunnerInterface, error) {
+		created = append(created, cfg)
+		return claude.NewMockRunner(sessionID, len(history) > 0, history), nil
+	}
+
+	sess := testSession("sess-be")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-be",
+		IssueRef:    config.IssueRef{Source: "github", ID: "86"},
+		SessionID:   sess.ID,
+		CurrentStep: "triage",
+		StepData:    map[string]any{},
+	})
+	return d, &created
+}
+
+func TestSessionRunner_SwitchesBackends(t *testing.T) {
+	t.Setenv("CHEAP_KEY", "sk-cheap")
+	d, created := backendTestDaemon(t)
+	sess := d.config.GetSession("sess-be")
+
+	claudeRunner := d.sessionMgr.GetOrCreateRunner(sess)
+	claudeRunner.(*claude.MockRunner).AddAssistantMessage("earlier work")
+
+	triage, err := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "triage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*created) != 1 || (*created)[0].Model != "gpt-4o-mini" || (*created)[0].APIKey != "sk-cheap" {
+		t.Fatalf("unexpected backend runners: %+v", *created)
+	}
+	if triage == claudeRunner || d.sessionMgr.GetRunner(sess.ID) != triage {
+		t.Error("expected the session's runner replaced by the backend's")
+	}
+	if msgs := triage.GetMessages(); len(msgs) != 1 || msgs[0].Content != "earlier work" {
+		t.Errorf("expected the conversation carried over, got %+v", msgs)
+	}
+
+	// Staying on the backend keeps the runner.
+	if again, _ := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "triage"}); again != triage || len(*created) != 1 {
+		t.Error("expected the backend runner reused")
+	}
+
+	coding, err := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "coding"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coding == triage {
+		t.Fatal("expected a Claude runner for coding")
+	}
+	if got := coding.(*claude.MockRunner).GetModel(); got != claude.ResolveModel("opus") {
+		t.Errorf("expected the coding state's model set, got %q", got)
+	}
+	if triage.PermissionRequestChan() != nil {
+		t.Error("expected the backend runner stopped")
+	}
+}
+
+func TestCreateWorkerWithPrompt_BackendError(t *testing.T) {
+	d, _ := backendTestDaemon(t)
+	d.newBackendRunner = func(agentbackend.Config, string, string, string, []claude.Message) (claude.RunnerInterface, error) {
+		return nil, errors.New("unknown agent backend provider")
+	}
+	sess := d.config.GetSession("sess-be")
+	item, _ := d.state.GetWorkItem("item-be")
+
+	w := d.createWorkerWithPrompt(context.Background(), item, sess, "go", "")
+
+	if !w.Done() || w.ExitError() == nil {
+		t.Fatal("expected the session failed when its backend can't start")
+	}
+}
+
+func TestSessionRunner_OfflineNeedsLocalBackend(t *testing.T) {
+	d, created := backendTestDaemon(t)
+	d.offline = true
+	sess := d.config.GetSession("sess-be")
+
+	if _, err := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "triage"}); !errors.Is(err, container.ErrOffline) {
+		t.Fatalf("expected a hosted backend refused offline, got %v", err)
+	}
+
+	d.workflowConfigs["/test/repo"].Backends["cheap"] = &workflow.BackendConfig{Provider: workflow.BackendLocal, Model: "qwen2.5-coder:32b"}
+	if _, err := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "triage"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*created) != 1 || (*created)[0].Provider != agentbackend.ProviderLocal {
+		t.Errorf("unexpected backend runners: %+v", *created)
+	}
+}
+
+func TestSessionRunner_UsesItemWorkflow(t *testing.T) {
+	t.Setenv("CHEAP_KEY", "sk-cheap")
+	d, created := backendTestDaemon(t)
+	// The named workflow codes on the cheap backend; the main one does not.
+	addNamedWorkflow(t, d, "/test/repo", "cheap-coding", &workflow.Config{
+		Backends: map[string]*workflow.BackendConfig{
+			"cheap": {Provider: workflow.BackendOpenAI, Model: "gpt-4o-mini", APIKeyEnv: "CHEAP_KEY"},
+		},
+		States: map[string]*workflow.State{
+			"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Backend: "cheap", Next: "done"},
+			"done":   {Type: workflow.StateTypeSucceed},
+		},
+	})
+	sess := d.config.GetSession("sess-be")
+
+	if _, err := d.sessionRunner(sess, daemonstate.WorkItem{CurrentStep: "coding", Workflow: "cheap-coding"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*created) != 1 || (*created)[0].Model != "gpt-4o-mini" {
+		t.Errorf("expected the named workflow's backend, got %+v", *created)
+	}
+}
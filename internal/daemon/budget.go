@@ -0,0 +1,310 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// phaseBudgetHold marks a work item parked before an AI step because a
+// pausing spend budget is exceeded. It holds no slot and enters the step
+// once the budget allows it.
+const phaseBudgetHold = "budget_hold"
+
+// budgetWrapUpKey is the StepData key holding the step whose session was
+// told to wrap up because its work item neared its spend cap.
+const budgetWrapUpKey = "_budget_wrap_up"
+
+// errBudgetExceeded marks a session stopped short by its work item's spend
+// cap, so the item is routed to the budget_exceeded state.
+var errBudgetExceeded = errors.New("work item spend cap reached")
+
+// WithGlobalBudget caps what all the daemon's repos may spend together,
+// on top of each repo's settings.budget. Its item caps apply to repos
+// without their own.
+func WithGlobalBudget(b *workflow.BudgetConfig) Option {
+	return func(d *Daemon) {
+		if b.Enabled() || b.ItemLimited() {
+			d.globalBudget = b
+		}
+	}
+}
+
+// budgetBreach is an exceeded spend budget.
+type budgetBreach struct {
+	scope    string // the repo path, or "" for the global budget
+	limit    string // which limit was reached, e.g. "daily budget of $10.00"
+	behavior string // workflow.BudgetStop, BudgetPause or BudgetWarn
+}
+
+// budgetSeverity orders behaviors from most to least restrictive.
+var budgetSeverity = map[string]int{workflow.BudgetPause: 3, workflow.BudgetStop: 2, workflow.BudgetWarn: 1}
+
+// exceededLimit describes the first limit of b that spend in repoPath (or
+// in every repo when repoPath is empty) has reached, or "" when none has.
+func (d *Daemon) exceededLimit(b *workflow.BudgetConfig, repoPath string, now time.Time) string {
+	dayCost, dayTokens := d.state.SpendSince(repoPath, daemonstate.SpendDay(now))
+	weekCost, weekTokens := d.state.SpendSince(repoPath, daemonstate.SpendWeekStart(now))
+	switch {
+	case b.DailyUSD > 0 && dayCost >= b.DailyUSD:
+		return fmt.Sprintf("daily budget of $%.2f", b.DailyUSD)
+	case b.WeeklyUSD > 0 && weekCost >= b.WeeklyUSD:
+		return fmt.Sprintf("weekly budget of $%.2f", b.WeeklyUSD)
+	case b.DailyTokens > 0 && dayTokens >= b.DailyTokens:
+		return fmt.Sprintf("daily budget of %d tokens", b.DailyTokens)
+	case b.WeeklyTokens > 0 && weekTokens >= b.WeeklyTokens:
+		return fmt.Sprintf("weekly budget of %d tokens", b.WeeklyTokens)
+	}
+	return ""
+}
+
+// budgetBreaches returns the exceeded budgets that apply to repoPath: its
+// own and the global one.
+func (d *Daemon) budgetBreaches(repoPath string) []budgetBreach {
+	now := time.Now()
+	var breaches []budgetBreach
+	if wfCfg, ok := d.lookupWorkflowConfig(repoPath); ok && repoPath != "" {
+		if b := wfCfg.SpendBudget(); b != nil {
+			if limit := d.exceededLimit(b, repoPath, now); limit != "" {
+				breaches = append(breaches, budgetBreach{scope: repoPath, limit: limit, behavior: b.Behavior()})
+			}
+		}
+	}
+	if d.globalBudget != nil {
+		if limit := d.exceededLimit(d.globalBudget, "", now); limit != "" {
+			breaches = append(breaches, budgetBreach{limit: limit, behavior: d.globalBudget.Behavior()})
+		}
+	}
+	return breaches
+}
+
+// budgetBehavior returns the most restrictive behavior of the exceeded
+// budgets that apply to repoPath, or "" when none is exceeded.
+func (d *Daemon) budgetBehavior(repoPath string) string {
+	var behavior string
+	for _, b := range d.budgetBreaches(repoPath) {
+		if budgetSeverity[b.behavior] > budgetSeverity[behavior] {
+			behavior = b.behavior
+		}
+	}
+	return behavior
+}
+
+// budgetStopsNewWork reports whether an exceeded budget keeps repoPath from
+// starting queued items.
+func (d *Daemon) budgetStopsNewWork(repoPath string) bool {
+	behavior := d.budgetBehavior(repoPath)
+	return behavior == workflow.BudgetStop || behavior == workflow.BudgetPause
+}
+
+// budgetPaused reports whether an exceeded budget holds repoPath's in-flight
+// items before their next AI step.
+func (d *Daemon) budgetPaused(repoPath string) bool {
+	return d.budgetBehavior(repoPath) == workflow.BudgetPause
+}
+
+// checkBudgets logs each budget when it is first exceeded and when it
+// clears again, e.g. because a new day started.
+func (d *Daemon) checkBudgets() {
+	scopes := d.config.GetRepos()
+	if d.globalBudget != nil {
+		scopes = append([]string{""}, scopes...)
+	}
+	if d.budgetAlerts == nil {
+		d.budgetAlerts = make(map[string]string)
+	}
+	for _, scope := range scopes {
+		var breach budgetBreach
+		for _, b := range d.budgetBreaches(scope) {
+			if b.scope == scope {
+				breach = b
+			}
+		}
+		if breach.limit == d.budgetAlerts[scope] {
+			continue
+		}
+		name := scope
+		if name == "" {
+			name = "global"
+		}
+		if breach.limit == "" {
+			d.logger.Info("spend budget no longer exceeded", "event", "budget.cleared", "budget", name)
+			delete(d.budgetAlerts, scope)
+			continue
+		}
+		d.logger.Warn("spend budget exceeded", "event", "budget.exceeded",
+			"budget", name, "limit", breach.limit, "onExceeded", breach.behavior)
+		d.budgetAlerts[scope] = breach.limit
+	}
+}
+
+// holdForBudget reports whether the sync chain must stop because the item's
+// current step is an AI action and a pausing budget is exceeded. The item
+// is parked until processBudgetHoldItems finds the budget clear.
+func (d *Daemon) holdForBudget(ctx context.Context, item daemonstate.WorkItem, engine *workflow.Engine) bool {
+	state := engine.GetState(item.CurrentStep)
+	if state == nil || state.Type != workflow.StateTypeTask || !strings.HasPrefix(state.Action, "ai.") {
+		return false
+	}
+	if !d.budgetPaused(d.resolveRepoPath(ctx, item)) {
+		return false
+	}
+
+	now := time.Now()
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = phaseBudgetHold
+		it.UpdatedAt = now
+	})
+	d.logger.Info("spend budget exceeded, holding before AI step", "event", "budget.hold",
+		"workItem", item.ID, "step", item.CurrentStep, "action", state.Action)
+	return true
+}
+
+// processBudgetHoldItems resumes items parked by holdForBudget once their
+// budget is no longer exceeded, longest waiting first.
+func (d *Daemon) processBudgetHoldItems(ctx context.Context) {
+	var held []daemonstate.WorkItem
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase == phaseBudgetHold {
+			held = append(held, item)
+		}
+	}
+	slices.SortStableFunc(held, func(a, b daemonstate.WorkItem) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+
+	maxConcurrent := d.getMaxConcurrent()
+	for _, item := range held {
+		if d.activeSlotCount() >= maxConcurrent {
+			return
+		}
+		repoPath := d.resolveRepoPath(ctx, item)
+		if d.budgetPaused(repoPath) {
+			continue
+		}
+		engine := d.getItemEngine(repoPath, item)
+		if engine == nil {
+			continue
+		}
+		d.logger.Info("spend budget clear, resuming", "event", "budget.resumed",
+			"workItem", item.ID, "step", item.CurrentStep)
+		d.state.AdvanceWorkItem(item.ID, item.CurrentStep, "idle")
+		d.executeSyncChain(ctx, item.ID, engine)
+	}
+}
+
+// recordLedgerSpend adds spend to the ledger that budgets are checked
+// against, under the item's repo, the current day and model.
+func (d *Daemon) recordLedgerSpend(item daemonstate.WorkItem, model string, costUSD float64, outputTokens, inputTokens int) {
+	d.state.RecordSpendEntry(daemonstate.SpendEntry{
+		Day:          daemonstate.SpendDay(time.Now()),
+		RepoPath:     d.workItemRepoPath(item),
+		WorkItemID:   item.ID,
+		Model:        model,
+		IssueID:      item.IssueRef.ID,
+		CostUSD:      costUSD,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+}
+
+// itemBudget returns the budget whose item caps apply to work items in
+// repoPath: the repo's own, else the global one. Returns nil when items are
+// uncapped.
+func (d *Daemon) itemBudget(repoPath string) *workflow.BudgetConfig {
+	if wfCfg, ok := d.lookupWorkflowConfig(repoPath); ok {
+		if b := wfCfg.ItemBudget(); b != nil {
+			return b
+		}
+	}
+	if d.globalBudget.ItemLimited() {
+		return d.globalBudget
+	}
+	return nil
+}
+
+// checkItemBudget tells the item's session to wrap up once the item has
+// spent its budget's wrap_up_at share of its cap. The message is delivered
+// at the session's next turn boundary, and handleAsyncComplete then routes
+// the item to the budget_exceeded state.
+func (d *Daemon) checkItemBudget(itemID string) {
+	item, ok := d.state.GetWorkItem(itemID)
+	if !ok || item.SessionID == "" {
+		return
+	}
+	if _, warned := item.StepData[budgetWrapUpKey]; warned {
+		return
+	}
+	b := d.itemBudget(d.workItemRepoPath(item))
+	spent := b.ItemSpent(item.CostUSD, item.InputTokens+item.OutputTokens)
+	if b == nil || spent < b.WrapUpAtOrDefault() {
+		return
+	}
+
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData[budgetWrapUpKey] = it.CurrentStep
+	})
+	msg := budgetWrapUpMessage(b, item, spent)
+	if pending := d.GetPendingMessage(item.SessionID); pending != "" {
+		msg += "\n\n" + pending
+	}
+	d.SetPendingMessage(item.SessionID, msg)
+	d.logger.Warn("work item nearing its spend cap, asking the session to wrap up", "event", "budget.item_wrap_up",
+		"workItem", item.ID, "step", item.CurrentStep, "costUSD", item.CostUSD, "spent", spent)
+	d.audit(item.ID, daemonstate.AuditSpend, fmt.Sprintf("reached %.0f%% of its spend cap, wrapping up", spent*100),
+		map[string]any{"cost_usd": item.CostUSD, "tokens": item.InputTokens + item.OutputTokens, "spent": spent})
+}
+
+// budgetWrapUpMessage tells a session its work item is nearly out of budget.
+func budgetWrapUpMessage(b *workflow.BudgetConfig, item daemonstate.WorkItem, spent float64) string {
+	var used []string
+	if b.ItemUSD > 0 {
+		used = append(used, fmt.Sprintf("$%.2f of $%.2f", item.CostUSD, b.ItemUSD))
+	}
+	if b.ItemTokens > 0 {
+		used = append(used, fmt.Sprintf("%d of %d tokens", item.InputTokens+item.OutputTokens, b.ItemTokens))
+	}
+	return fmt.Sprintf("BUDGET: This work item has used %.0f%% of its spend cap (%s). Wrap up now: "+
+		"do not start new work, commit the changes that are complete and safe to keep, "+
+		"and call submit_result with status \"partial\", a summary of what is done, "+
+		"and follow_ups listing the work that remains.", spent*100, strings.Join(used, ", "))
+}
+
+// budgetWrappedUp reports whether item's current step was told to wrap up
+// for its spend cap, and clears the mark so the step can be entered again.
+func (d *Daemon) budgetWrappedUp(item daemonstate.WorkItem) bool {
+	step, ok := item.StepData[budgetWrapUpKey].(string)
+	if !ok || step != item.CurrentStep {
+		return false
+	}
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		delete(it.StepData, budgetWrapUpKey)
+	})
+	return true
+}
+
+// overItemBudget reports whether the item's current step is an AI action
+// and the item has already spent its cap, in which case the step fails
+// with workflow.ErrorBudgetExceeded instead of starting a session.
+func (d *Daemon) overItemBudget(ctx context.Context, item daemonstate.WorkItem, engine *workflow.Engine) bool {
+	state := engine.GetState(item.CurrentStep)
+	if state == nil || state.Type != workflow.StateTypeTask || !strings.HasPrefix(state.Action, "ai.") {
+		return false
+	}
+	b := d.itemBudget(d.resolveRepoPath(ctx, item))
+	if b.ItemSpent(item.CostUSD, item.InputTokens+item.OutputTokens) < 1 {
+		return false
+	}
+	d.logger.Warn("work item has spent its cap, not starting AI step", "event", "budget.item_exceeded",
+		"workItem", item.ID, "step", item.CurrentStep, "action", state.Action, "costUSD", item.CostUSD)
+	return true
+}
@@ -0,0 +1,209 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// budgetTestDaemon returns a daemon whose workflow runs an ai.summarize step
+// under budget and then succeeds. The action is replaced by a counter so
+// tests can see whether it ran.
+func budgetTestDaemon(t *testing.T, budget *workflow.BudgetConfig) (*Daemon, *countingAction) {
+	t.Helper()
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:    "summarize",
+		Source:   workflow.SourceConfig{Provider: "github"},
+		Settings: &workflow.SettingsConfig{Budget: budget},
+		States: map[string]*workflow.State{
+			"summarize": {Type: workflow.StateTypeTask, Action: "ai.summarize", Next: "done"},
+			"done":      {Type: workflow.StateTypeSucceed},
+		},
+	}
+	action := &countingAction{}
+	reg := d.buildActionRegistry()
+	reg.Register("ai.summarize", action)
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, reg, newEventChecker(d), d.logger)
+	return d, action
+}
+
+// addBudgetItem adds an idle work item at the summarize step of /test/repo.
+func addBudgetItem(d *Daemon, id string) {
+	sess := testSession("sess-" + id)
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          id,
+		IssueRef:    config.IssueRef{Source: "github", ID: id},
+		SessionID:   sess.ID,
+		CurrentStep: "summarize",
+		StepData:    map[string]any{},
+	})
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+		it.Phase = "idle"
+	})
+}
+
+func TestRecordItemSpend_RecordsLedgerEntry(t *testing.T) {
+	d, _ := budgetTestDaemon(t, nil)
+	addBudgetItem(d, "1")
+
+	d.RecordItemSpend("sess-1", 1.5, 200, 800)
+	d.RecordItemSpend("sess-1", 0.5, 0, 0)
+
+	today := daemonstate.SpendDay(time.Now())
+	if cost, tokens := d.state.SpendSince("/test/repo", today); cost != 2 || tokens != 1000 {
+		t.Errorf("repo spend = $%v, %d tokens; want $2, 1000", cost, tokens)
+	}
+	if cost, _ := d.state.SpendSince("/test/other", today); cost != 0 {
+		t.Errorf("other repo spend = $%v, want 0", cost)
+	}
+}
+
+func TestBudget_PauseHoldsAIStep(t *testing.T) {
+	budget := &workflow.BudgetConfig{DailyUSD: 1, OnExceeded: workflow.BudgetPause}
+	d, action := budgetTestDaemon(t, budget)
+	ctx := context.Background()
+	addBudgetItem(d, "spender")
+	addBudgetItem(d, "next")
+	d.RecordItemSpend("sess-spender", 1.25, 0, 0)
+
+	d.executeSyncChain(ctx, "next", d.engines["/test/repo"])
+
+	if action.runs != 0 {
+		t.Fatal("AI step ran over a pausing budget")
+	}
+	item, _ := d.state.GetWorkItem("next")
+	if item.Phase != phaseBudgetHold || item.ConsumesSlot() {
+		t.Fatalf("phase = %q, want held without a slot", item.Phase)
+	}
+
+	// Still over budget: nothing happens.
+	d.processBudgetHoldItems(ctx)
+	if action.runs != 0 {
+		t.Fatal("held item resumed while over budget")
+	}
+
+	budget.DailyUSD = 5
+	d.processBudgetHoldItems(ctx)
+	if action.runs != 1 {
+		t.Fatalf("AI step ran %d times once the budget cleared, want 1", action.runs)
+	}
+	if item, _ := d.state.GetWorkItem("next"); item.State != daemonstate.WorkItemCompleted {
+		t.Errorf("State = %q, want completed", item.State)
+	}
+}
+
+func TestBudget_StopOnlyBlocksNewWork(t *testing.T) {
+	d, action := budgetTestDaemon(t, &workflow.BudgetConfig{DailyTokens: 100})
+	addBudgetItem(d, "spender")
+	addBudgetItem(d, "next")
+	d.RecordItemSpend("sess-spender", 0, 60, 60)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "queued",
+		IssueRef: config.IssueRef{Source: "github", ID: "queued"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+
+	d.startQueuedItems(context.Background())
+	if item, _ := d.state.GetWorkItem("queued"); item.State != daemonstate.WorkItemQueued {
+		t.Errorf("queued item State = %q, want it left queued", item.State)
+	}
+
+	d.executeSyncChain(context.Background(), "next", d.engines["/test/repo"])
+	if action.runs != 1 {
+		t.Error("in-flight items should continue under a stopping budget")
+	}
+}
+
+func TestBudget_StrictestBehaviorWins(t *testing.T) {
+	d, _ := budgetTestDaemon(t, &workflow.BudgetConfig{WeeklyUSD: 1, OnExceeded: workflow.BudgetWarn})
+	addBudgetItem(d, "1")
+	d.RecordItemSpend("sess-1", 3, 0, 0)
+
+	if d.budgetStopsNewWork("/test/repo") {
+		t.Error("a warning budget should not stop new work")
+	}
+
+	WithGlobalBudget(&workflow.BudgetConfig{DailyUSD: 2, OnExceeded: workflow.BudgetPause})(d)
+	if !d.budgetPaused("/test/repo") {
+		t.Error("expected the exceeded global budget to pause the repo")
+	}
+	if !d.budgetPaused("/test/other") {
+		t.Error("expected the global budget to cover every repo")
+	}
+
+	d.config.(*config.Config).Repos = []string{"/test/repo"}
+	d.checkBudgets()
+	if d.budgetAlerts[""] != "daily budget of $2.00" || d.budgetAlerts["/test/repo"] != "weekly budget of $1.00" {
+		t.Errorf("budget alerts = %v", d.budgetAlerts)
+	}
+}
+
+func TestCheckItemBudget_AsksSessionToWrapUp(t *testing.T) {
+	d, _ := budgetTestDaemon(t, &workflow.BudgetConfig{ItemUSD: 10})
+	addBudgetItem(d, "1")
+
+	d.RecordItemSpend("sess-1", 7, 0, 0)
+	if msg := d.GetPendingMessage("sess-1"); msg != "" {
+		t.Fatalf("expected no wrap-up below 80%% of the cap, got %q", msg)
+	}
+
+	d.SetPendingMessage("sess-1", "Also rename the flag.")
+	d.RecordItemSpend("sess-1", 1.5, 0, 0)
+	msg := d.GetPendingMessage("sess-1")
+	if !strings.Contains(msg, "Wrap up now") || !strings.Contains(msg, "$8.50 of $10.00") || !strings.HasSuffix(msg, "Also rename the flag.") {
+		t.Errorf("unexpected wrap-up message: %q", msg)
+	}
+	if item, _ := d.state.GetWorkItem("1"); item.StepData[budgetWrapUpKey] != "summarize" {
+		t.Errorf("expected the step marked wrapped up, got %v", item.StepData)
+	}
+
+	d.RecordItemSpend("sess-1", 1, 0, 0)
+	if msg := d.GetPendingMessage("sess-1"); msg != "" {
+		t.Errorf("expected a single wrap-up, got %q", msg)
+	}
+}
+
+func TestHandleAsyncComplete_WrappedUpGoesToBudgetExceeded(t *testing.T) {
+	d, _ := budgetTestDaemon(t, &workflow.BudgetConfig{ItemUSD: 10})
+	d.workflowConfigs["/test/repo"].States[workflow.BudgetExceededState] = &workflow.State{Type: workflow.StateTypeFail}
+	addBudgetItem(d, "1")
+	d.RecordItemSpend("sess-1", 9, 0, 0)
+	item, _ := d.state.GetWorkItem("1")
+
+	d.handleAsyncComplete(context.Background(), item, nil)
+
+	item, _ = d.state.GetWorkItem("1")
+	if item.CurrentStep != workflow.BudgetExceededState {
+		t.Errorf("CurrentStep = %q, want budget_exceeded", item.CurrentStep)
+	}
+	if _, ok := item.StepData[budgetWrapUpKey]; ok {
+		t.Error("expected the wrap-up mark cleared")
+	}
+}
+
+func TestExecuteSyncChain_ItemOverCapSkipsAIStep(t *testing.T) {
+	d, action := budgetTestDaemon(t, &workflow.BudgetConfig{ItemTokens: 1000})
+	d.workflowConfigs["/test/repo"].States["summarize"].Error = "failed"
+	d.workflowConfigs["/test/repo"].States["failed"] = &workflow.State{Type: workflow.StateTypeFail}
+	addBudgetItem(d, "1")
+	d.state.RecordItemSpend("1", 0, 600, 400)
+
+	d.executeSyncChain(context.Background(), "1", d.engines["/test/repo"])
+
+	if action.runs != 0 {
+		t.Fatal("AI step ran for an item over its cap")
+	}
+	item, _ := d.state.GetWorkItem("1")
+	if item.CurrentStep != "failed" || item.State != daemonstate.WorkItemFailed {
+		t.Errorf("expected the item failed down its error edge, got %q (%s)", item.CurrentStep, item.State)
+	}
+}
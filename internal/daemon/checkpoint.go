@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// shutdownCheckpointMessage is the commit that saves a running coding
+// session's uncommitted work when the daemon shuts down.
+const shutdownCheckpointMessage = "[erg-wip] checkpoint before orchestrator shutdown"
+
+// shutdownResumeNote tells a session resumed after a shutdown that it is
+// picking up work an earlier session started.
+const shutdownResumeNote = "This task was paused when the orchestrator shut down and is now being resumed. " +
+	"Work already done is committed and pushed on the current branch (the last commit may be an [erg-wip] checkpoint): " +
+	"review it with git log and git diff, then continue from where it left off."
+
+// checkpointTranscriptChars bounds how much of the paused session's
+// conversation is replayed to the session that resumes it.
+const checkpointTranscriptChars = 4000
+
+// markShutdownCheckpoints marks the coding items among itemIDs, whose
+// workers are about to be stopped, to resume in their worktree on the next
+// start instead of restarting their step. It returns the marked items.
+func (d *Daemon) markShutdownCheckpoints(itemIDs []string) []string {
+	var marked []string
+	for _, id := range itemIDs {
+		item, ok := d.state.GetWorkItem(id)
+		if !ok || item.Phase != "async_pending" || !d.isCodingStep(d.workItemRepoPath(item), item) {
+			continue
+		}
+		d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+			it.Phase = phasePreempted
+			if it.StepData == nil {
+				it.StepData = make(map[string]any)
+			}
+			it.StepData["_shutdown_checkpoint"] = it.SessionID
+			it.UpdatedAt = time.Now()
+		})
+		marked = append(marked, id)
+	}
+	return marked
+}
+
+// checkpointForShutdown saves a stopped coding session's conversation and
+// commits and pushes its work as an [erg-wip] commit, so the item can resume
+// from its branch even if the worktree does not survive.
+func (d *Daemon) checkpointForShutdown(ctx context.Context, itemID string) {
+	item, ok := d.state.GetWorkItem(itemID)
+	if !ok {
+		return
+	}
+	log := d.logger.With("workItem", item.ID, "issue", item.IssueRef.ID, "step", item.CurrentStep)
+
+	if runner := d.sessionMgr.GetRunner(item.SessionID); runner != nil {
+		d.saveRunnerMessages(item.SessionID, runner)
+	}
+	sess := d.config.GetSession(item.SessionID)
+	if sess == nil || sess.WorkTree == "" || sess.Branch == "" {
+		log.Warn("no worktree to checkpoint, the item resumes from its last commit")
+		return
+	}
+
+	pushCtx, cancel := context.WithTimeout(ctx, timeoutGitPush)
+	defer cancel()
+	var pushErr error
+	for result := range d.gitService.PushUpdates(pushCtx, sess.RepoPath, sess.WorkTree, sess.Branch, shutdownCheckpointMessage) {
+		if result.Error != nil {
+			pushErr = result.Error
+		}
+	}
+	if pushErr != nil {
+		log.Warn("failed to push checkpoint, the work stays in the worktree", "branch", sess.Branch, "error", pushErr)
+		return
+	}
+	log.Info("checkpointed session for shutdown", "event", "shutdown.checkpoint", "branch", sess.Branch)
+}
+
+// resumesConversation reports whether sess's next Claude CLI process
+// resumes its conversation, context and all, rather than starting a new one
+// that needs the paused session's transcript replayed. A container's
+// conversation survives only when its last turn was checkpointed.
+func resumesConversation(sess *config.Session) bool {
+	if !sess.Started {
+		return false
+	}
+	return !sess.Containerized || claude.HasConversationCheckpoint(sess.ID)
+}
+
+// checkpointTranscript formats the end of a paused session's conversation
+// for the session that resumes it, or returns "" when nothing was saved.
+func checkpointTranscript(msgs []config.Message) string {
+	var parts []string
+	size := 0
+	for i := len(msgs) - 1; i >= 0 && size < checkpointTranscriptChars; i-- {
+		content := strings.TrimSpace(msgs[i].Content)
+		if content == "" {
+			continue
+		}
+		if room := checkpointTranscriptChars - size; len(content) > room {
+			content = "…" + content[len(content)-room:]
+		}
+		part := msgs[i].Role + ": " + content
+		parts = append([]string{part}, parts...)
+		size += len(content)
+	}
+	return strings.Join(parts, "\n\n")
+}
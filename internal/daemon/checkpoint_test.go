@@ -0,0 +1,175 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestShutdown_CheckpointsCodingSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("git", []string{"status", "--porcelain"}, exec.MockResponse{Stdout: []byte(" M main.go\n")})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	addCodingItem(d, "coder", 2)
+	addCodingItem(d, "waiter", 2)
+	d.state.UpdateWorkItem("waiter", func(it *daemonstate.WorkItem) { it.Phase = "addressing_feedback" })
+
+	d.shutdown()
+
+	coder, _ := d.state.GetWorkItem("coder")
+	if coder.Phase != phasePreempted || coder.StepData["_shutdown_checkpoint"] != "sess-coder" || coder.CurrentStep != "coding" {
+		t.Errorf("coder = phase %q step %q data %v, want preempted at coding with a checkpoint", coder.Phase, coder.CurrentStep, coder.StepData)
+	}
+	if waiter, _ := d.state.GetWorkItem("waiter"); waiter.Phase != "addressing_feedback" {
+		t.Errorf("waiter phase = %q, want it left for startup recovery", waiter.Phase)
+	}
+
+	var committed, pushed bool
+	for _, call := range mockExec.GetCalls() {
+		if call.Name != "git" {
+			continue
+		}
+		if slices.Equal(call.Args, []string{"commit", "-m", shutdownCheckpointMessage}) {
+			committed = call.Dir == "/test/worktree-sess-coder"
+		}
+		if len(call.Args) > 0 && call.Args[0] == "push" {
+			pushed = true
+		}
+	}
+	if !committed || !pushed {
+		t.Errorf("committed %v, pushed %v: want the coding session's work checkpointed and pushed", committed, pushed)
+	}
+}
+
+func TestResumePreempted_AfterShutdown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"api", "repos/:owner/:repo/issues/"}, exec.MockResponse{Stdout: []byte(`[]`)})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	addCodingItem(d, "coder", 2)
+	delete(d.workers, "coder")
+	d.state.UpdateWorkItem("coder", func(it *daemonstate.WorkItem) {
+		it.Phase = phasePreempted
+		it.StepData["_shutdown_checkpoint"] = "sess-coder"
+	})
+	if err := config.SaveSessionMessages("sess-coder", []config.Message{
+		{Role: "user", Content: "Fix the login bug"},
+		{Role: "assistant", Content: "Updated the session check; tests next."},
+	}, config.MaxSessionMessageLines); err != nil {
+		t.Fatal(err)
+	}
+
+	d.resumePreemptedItems(t.Context())
+
+	d.mu.Lock()
+	w := d.workers["coder"]
+	d.mu.Unlock()
+	if w == nil {
+		t.Fatal("expected a coding worker to be started")
+	}
+	msg := w.InitialMsg()
+	if !strings.HasPrefix(msg, shutdownResumeNote) || !strings.Contains(msg, "Updated the session check; tests next.") {
+		t.Errorf("initial message should carry the shutdown note and transcript, got:\n%s", msg)
+	}
+	if coder, _ := d.state.GetWorkItem("coder"); coder.StepData["_shutdown_checkpoint"] != nil {
+		t.Error("expected the checkpoint marker to be cleared on resume")
+	}
+}
+
+func TestResumePreempted_AfterShutdown_ResumesConversation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"api", "repos/:owner/:repo/issues/"}, exec.MockResponse{Stdout: []byte(`[]`)})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	addCodingItem(d, "coder", 2)
+	delete(d.workers, "coder")
+	d.state.UpdateWorkItem("coder", func(it *daemonstate.WorkItem) {
+		it.Phase = phasePreempted
+		it.StepData["_shutdown_checkpoint"] = "sess-coder"
+	})
+	if err := config.SaveSessionMessages("sess-coder", []config.Message{
+		{Role: "assistant", Content: "Updated the session check; tests next."},
+	}, config.MaxSessionMessageLines); err != nil {
+		t.Fatal(err)
+	}
+	writeConversationCheckpoint(t, "sess-coder")
+
+	d.resumePreemptedItems(t.Context())
+
+	d.mu.Lock()
+	w := d.workers["coder"]
+	d.mu.Unlock()
+	if w == nil {
+		t.Fatal("expected a coding worker to be started")
+	}
+	msg := w.InitialMsg()
+	if !strings.HasPrefix(msg, shutdownResumeNote) || strings.Contains(msg, "Updated the session check") {
+		t.Errorf("a resumed conversation should get the note without a replayed transcript, got:\n%s", msg)
+	}
+}
+
+func TestRefreshStaleSession_KeepsCheckpointedConversation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	d := testDaemon(testConfig())
+	addCodingItem(d, "coder", 2)
+	delete(d.workers, "coder")
+	writeConversationCheckpoint(t, "sess-coder")
+	item, _ := d.state.GetWorkItem("coder")
+
+	sess := d.refreshStaleSession(t.Context(), item, d.config.GetSession("sess-coder"))
+
+	if sess.ID != "sess-coder" {
+		t.Errorf("expected the session kept to resume its conversation, got %s", sess.ID)
+	}
+	if item, _ := d.state.GetWorkItem("coder"); item.SessionID != "sess-coder" {
+		t.Errorf("expected the item to keep its session, got %s", item.SessionID)
+	}
+}
+
+// writeConversationCheckpoint records a completed turn of a containerized
+// session's conversation.
+func writeConversationCheckpoint(t *testing.T, sessionID string) {
+	t.Helper()
+	dir, err := claude.ConversationDir(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".checkpoint.jsonl"), []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckpointTranscript(t *testing.T) {
+	if got := checkpointTranscript(nil); got != "" {
+		t.Errorf("empty transcript = %q", got)
+	}
+	long := strings.Repeat("a", checkpointTranscriptChars+100)
+	got := checkpointTranscript([]config.Message{{Role: "user", Content: "first"}, {Role: "assistant", Content: long}})
+	if strings.Contains(got, "first") || !strings.HasPrefix(got, "assistant: …") {
+		t.Errorf("transcript should keep only the end of the conversation, got %.40q", got)
+	}
+}
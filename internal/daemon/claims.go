@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/zhubert/erg/internal/issues"
@@ -35,10 +36,14 @@ const (
 //   - Step 1: if we can't read claims, skip (retry next poll).
 //   - Step 2: if we can't post a claim, skip (retry next poll).
 //   - Step 4: if we can't verify, delete our claim and skip.
+//
+// Once the claim is ours, the issue is also marked with the claim label (see
+// addClaimLabel). Providers without claim comments rely on the label alone.
 func (d *Daemon) tryClaim(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source) (bool, error) {
 	cm := d.getClaimManager(provider)
 	if cm == nil {
-		return true, nil // provider doesn't support claims — proceed
+		// No claim comments — the label is the only guard against double pickup.
+		return d.addClaimLabel(ctx, repoPath, issue, provider, false), nil
 	}
 
 	log := d.logger.With("component", "claim", "issue", issue.ID, "provider", string(provider))
@@ -56,11 +61,11 @@ func (d *Daemon) tryClaim(ctx context.Context, repoPath string, issue issues.Iss
 	daemonKey := d.claimIdentity()
 
 	for _, claim := range existingClaims {
-		if claim.DaemonID == daemonKey {
+		if d.isOwnClaim(claim.DaemonID) {
 			// Our own claim from a previous run — still valid
 			if now.Before(claim.Expires) {
 				log.Debug("found our own valid claim, proceeding")
-				return true, nil
+				return d.addClaimLabel(ctx, repoPath, issue, provider, true), nil
 			}
 			// Our own claim expired — delete it and re-claim
 			_ = cm.DeleteClaim(ctx, repoPath, issue.ID, claim.CommentID)
@@ -129,9 +134,9 @@ func (d *Daemon) tryClaim(ctx context.Context, repoPath string, issue issues.Iss
 		}
 	}
 
-	if earliest != nil && earliest.DaemonID == daemonKey {
+	if earliest != nil && d.isOwnClaim(earliest.DaemonID) {
 		log.Info("claimed issue successfully")
-		return true, nil
+		return d.addClaimLabel(ctx, repoPath, issue, provider, true), nil
 	}
 
 	// We lost (or no valid claims found, which shouldn't happen but handle safely)
@@ -144,6 +149,38 @@ func (d *Daemon) tryClaim(ctx context.Context, repoPath string, issue issues.Iss
 	return false, nil
 }
 
+// addClaimLabel marks the issue with the claim label so other daemons and
+// humans can see it is taken. leased reports whether this daemon holds a
+// valid claim comment: a label found next to one was left behind by a daemon
+// whose claim expired (typically one that crashed), so it is taken over.
+// Without a lease, a label already on the issue means another daemon has it.
+//
+// Labelling is best-effort: if the provider can't be reached the claim
+// comment (if any) still guards the issue, so errors are logged and ignored.
+func (d *Daemon) addClaimLabel(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, leased bool) bool {
+	pa := d.getProviderActions(provider)
+	if pa == nil {
+		return true
+	}
+
+	log := d.logger.With("component", "claim", "issue", issue.ID, "provider", string(provider))
+
+	added, err := pa.Claim(ctx, repoPath, issue.ID)
+	if err != nil {
+		log.Warn("failed to add claim label", "label", issues.ClaimLabel, "error", err)
+		return true
+	}
+	if added {
+		return true
+	}
+	if !leased {
+		log.Debug("issue already carries the claim label, skipping", "label", issues.ClaimLabel)
+		return false
+	}
+	log.Info("taking over claim label left by an expired claim", "label", issues.ClaimLabel)
+	return true
+}
+
 // isClaimedByOther checks whether an issue has a valid (non-expired) claim
 // from a different daemon. Used during recovery to skip issues that another
 // daemon instance is already working on. Does not post any claims.
@@ -159,38 +196,47 @@ func (d *Daemon) isClaimedByOther(ctx context.Context, repoPath string, issue is
 	}
 
 	now := time.Now()
-	daemonKey := d.claimIdentity()
-
 	for _, claim := range claims {
-		if claim.DaemonID != daemonKey && now.Before(claim.Expires) {
+		if !d.isOwnClaim(claim.DaemonID) && now.Before(claim.Expires) {
 			return true
 		}
 	}
 	return false
 }
 
-// deleteClaimForIssue removes this daemon's claim comment(s) from an issue.
+// deleteClaimForIssue removes this daemon's claim comment(s) from an issue,
+// along with the claim label unless another daemon holds a valid claim.
 // Used when work is cancelled, fails to start, or the issue is unqueued.
 // All errors are silently ignored — claim cleanup is best-effort.
 func (d *Daemon) deleteClaimForIssue(ctx context.Context, repoPath string, issueSource issues.Source, issueID string) {
-	cm := d.getClaimManager(issueSource)
-	if cm == nil {
-		return
-	}
+	claimedByOther := false
+	if cm := d.getClaimManager(issueSource); cm != nil {
+		claims, err := cm.GetClaims(ctx, repoPath, issueID)
+		if err != nil {
+			return
+		}
 
-	claims, err := cm.GetClaims(ctx, repoPath, issueID)
-	if err != nil {
-		return
+		now := time.Now()
+		for _, claim := range claims {
+			if d.isOwnClaim(claim.DaemonID) {
+				_ = cm.DeleteClaim(ctx, repoPath, issueID, claim.CommentID)
+			} else if now.Before(claim.Expires) {
+				claimedByOther = true
+			}
+		}
 	}
 
-	daemonKey := d.claimIdentity()
-	for _, claim := range claims {
-		if claim.DaemonID == daemonKey {
-			_ = cm.DeleteClaim(ctx, repoPath, issueID, claim.CommentID)
-		}
+	if pa := d.getProviderActions(issueSource); pa != nil && !claimedByOther {
+		_ = pa.Unclaim(ctx, repoPath, issueID)
 	}
 }
 
+// isOwnClaim reports whether a claim's daemon ID belongs to this daemon: its
+// current identity, or that of a host its state was imported from.
+func (d *Daemon) isOwnClaim(daemonID string) bool {
+	return daemonID == d.claimIdentity() || slices.Contains(d.state.GetClaimAliases(), daemonID)
+}
+
 // claimIsBefore returns true if claim a was created before claim b.
 // Prefers server-side timestamps (from the provider API's created_at)
 // over self-reported timestamps to avoid clock-skew between machines.
@@ -222,3 +268,13 @@ func (d *Daemon) getClaimManager(source issues.Source) issues.ProviderClaimManag
 	}
 	return cm
 }
+
+// getProviderActions returns the ProviderActions for the given source, or nil
+// if the provider is not registered or doesn't support write operations.
+func (d *Daemon) getProviderActions(source issues.Source) issues.ProviderActions {
+	if d.issueRegistry == nil {
+		return nil
+	}
+	pa, _ := d.issueRegistry.GetProvider(source).(issues.ProviderActions)
+	return pa
+}
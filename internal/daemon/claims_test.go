@@ -40,6 +40,9 @@ type mockClaimProvider struct {
 	postCalled            bool
 	deleteCalled          bool
 	deleteCalledIDs       []string
+	labeled               bool // issue carries issues.ClaimLabel
+	claimLabelErr         error
+	unclaimCalled         bool
 	// postHook is called after a successful PostClaim to allow tests to inject
 	// additional claims (simulating a race condition).
 	postHook func(m *mockClaimProvider)
@@ -88,6 +91,27 @@ func (m *mockClaimProvider) DeleteClaim(ctx context.Context, repoPath string, is
 	return nil
 }
 
+func (m *mockClaimProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	if m.claimLabelErr != nil {
+		return false, m.claimLabelErr
+	}
+	if m.labeled {
+		return false, nil
+	}
+	m.labeled = true
+	return true, nil
+}
+
+func (m *mockClaimProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	m.unclaimCalled = true
+	m.labeled = false
+	return nil
+}
+
+func (m *mockClaimProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	return nil
+}
+
 func newTestDaemonWithClaimProvider(mockProvider *mockClaimProvider) *Daemon {
 	cfg := testConfig()
 	registry := issues.NewProviderRegistry(mockProvider)
@@ -416,6 +440,34 @@ func TestIsClaimedByOther_OwnClaim(t *testing.T) {
 	}
 }
 
+func TestTryClaim_ImportedHostClaim_Wins(t *testing.T) {
+	mock := &mockClaimProvider{
+		claims: []issues.ClaimInfo{
+			{
+				CommentID: "old-host-claim",
+				DaemonID:  "test-daemon-1@old-host",
+				Hostname:  "old-host",
+				Timestamp: time.Now().Add(-10 * time.Minute),
+				Expires:   time.Now().Add(50 * time.Minute),
+			},
+		},
+	}
+	d := newTestDaemonWithClaimProvider(mock)
+	d.state.AddClaimAlias("test-daemon-1@old-host")
+
+	issue := issues.Issue{ID: "42", Source: issues.SourceGitHub}
+	won, err := d.tryClaim(context.Background(), "/test/repo", issue, issues.SourceGitHub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !won || mock.postCalled {
+		t.Errorf("expected claim from the host the state was imported from to count as ours (won=%v, posted=%v)", won, mock.postCalled)
+	}
+	if d.isClaimedByOther(context.Background(), "/test/repo", issue, issues.SourceGitHub) {
+		t.Error("expected imported host's claim not to count as another daemon's")
+	}
+}
+
 func TestIsClaimedByOther_ExpiredClaim(t *testing.T) {
 	mock := &mockClaimProvider{
 		claims: []issues.ClaimInfo{
@@ -480,6 +532,124 @@ func TestDeleteClaimForIssue_Cleanup(t *testing.T) {
 	}
 }
 
+func TestDeleteClaimForIssue_RemovesClaimLabel(t *testing.T) {
+	mock := &mockClaimProvider{
+		claims: []issues.ClaimInfo{
+			{
+				CommentID: "our-claim-1",
+				DaemonID:  testDaemonKey("test-daemon-1"),
+				Timestamp: time.Now(),
+				Expires:   time.Now().Add(1 * time.Hour),
+			},
+		},
+		labeled: true,
+	}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	d.deleteClaimForIssue(context.Background(), "/test/repo", "github", "42")
+
+	if !mock.unclaimCalled || mock.labeled {
+		t.Error("expected the claim label to be removed with our claim")
+	}
+}
+
+func TestDeleteClaimForIssue_KeepsClaimLabelForOtherDaemon(t *testing.T) {
+	mock := &mockClaimProvider{
+		claims: []issues.ClaimInfo{
+			{
+				CommentID: "other-claim",
+				DaemonID:  "other-daemon",
+				Timestamp: time.Now(),
+				Expires:   time.Now().Add(1 * time.Hour),
+			},
+		},
+		labeled: true,
+	}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	d.deleteClaimForIssue(context.Background(), "/test/repo", "github", "42")
+
+	if mock.unclaimCalled {
+		t.Error("expected the claim label to stay while another daemon holds a valid claim")
+	}
+}
+
+func TestTryClaim_AddsClaimLabel(t *testing.T) {
+	mock := &mockClaimProvider{nextCommentID: "comment-1"}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	issue := issues.Issue{ID: "42", Source: issues.SourceGitHub}
+	won, err := d.tryClaim(context.Background(), "/test/repo", issue, issues.SourceGitHub)
+
+	if err != nil || !won {
+		t.Fatalf("expected to win claim, got won=%v err=%v", won, err)
+	}
+	if !mock.labeled {
+		t.Errorf("expected %s label to be added", issues.ClaimLabel)
+	}
+}
+
+func TestTryClaim_TakesOverStaleClaimLabel(t *testing.T) {
+	// The label is left over from a daemon whose claim expired (e.g. it
+	// crashed). Winning the claim comment race takes the label over.
+	mock := &mockClaimProvider{
+		claims: []issues.ClaimInfo{
+			{
+				CommentID: "crashed-claim",
+				DaemonID:  "crashed-daemon",
+				Timestamp: time.Now().Add(-3 * time.Hour),
+				Expires:   time.Now().Add(-1 * time.Hour),
+			},
+		},
+		nextCommentID: "new-claim",
+		labeled:       true,
+	}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	issue := issues.Issue{ID: "42", Source: issues.SourceGitHub}
+	won, err := d.tryClaim(context.Background(), "/test/repo", issue, issues.SourceGitHub)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !won {
+		t.Error("expected to take over a claim label whose claim expired")
+	}
+}
+
+func TestTryClaim_ClaimLabelError_Proceeds(t *testing.T) {
+	mock := &mockClaimProvider{
+		nextCommentID: "comment-1",
+		claimLabelErr: fmt.Errorf("label API error"),
+	}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	issue := issues.Issue{ID: "42", Source: issues.SourceGitHub}
+	won, err := d.tryClaim(context.Background(), "/test/repo", issue, issues.SourceGitHub)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !won {
+		t.Error("expected the claim comment to stand when labelling fails")
+	}
+}
+
+func TestAddClaimLabel_UnleasedLabelledIssue_Skips(t *testing.T) {
+	// Without a claim comment to consult, an existing label means another
+	// daemon has the issue.
+	mock := &mockClaimProvider{labeled: true}
+	d := newTestDaemonWithClaimProvider(mock)
+
+	issue := issues.Issue{ID: "42", Source: issues.SourceGitHub}
+	if d.addClaimLabel(context.Background(), "/test/repo", issue, issues.SourceGitHub, false) {
+		t.Error("expected to skip an issue that already carries the claim label")
+	}
+	if !d.addClaimLabel(context.Background(), "/test/repo", issue, issues.SourceGitHub, true) {
+		t.Error("expected a leased claim to take the label over")
+	}
+}
+
 func TestPollForNewIssues_SkipsClaimedIssues(t *testing.T) {
 	cfg := testConfig()
 	cfg.Repos = []string{"/test/repo"}
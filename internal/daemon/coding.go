@@ -36,6 +36,31 @@ func maybeAppendSimplify(msg string, simplify bool) string {
 	return msg + simplifyDirective
 }
 
+// resultContractDirective is appended to every daemon session's system prompt.
+// It asks Claude to emit a structured result envelope via the submit_result MCP
+// tool so the workflow engine can branch on the outcome.
+const resultContractDirective = `
+
+RESULT CONTRACT:
+When you finish, call the submit_result MCP tool exactly once with:
+- status: "success" if the step is fully done, "partial" if some work remains,
+  "failed" if you could not complete it, or "blocked" if you need human input
+- summary: one or two sentences describing what you did
+- files_changed: paths of files you modified
+- follow_ups: anything left for later steps or humans
+- confidence: a number from 0 to 1
+- plan: the approach you took, as numbered steps
+- risk_level: "low", "medium", or "high", and risks: what your changes could break
+A "failed" or "blocked" status routes the workflow to its error path.`
+
+// resultRequiredDirective tells Claude which result fields its state's
+// result_required param makes mandatory. The worker rejects results without
+// them and asks again.
+func resultRequiredDirective(required []string) string {
+	return "\n\nRESULT REQUIREMENTS:\nThis step needs a submit_result call with these fields filled in: " +
+		strings.Join(required, ", ") + ". A result without them is rejected."
+}
+
 // fetchIssueComments retrieves comments for a work item's issue from the appropriate provider.
 // Synthetic work items (scheduled triggers) are skipped since they have no real issue.
 func (d *Daemon) fetchIssueComments(ctx context.Context, repoPath string, item daemonstate.WorkItem) ([]issues.IssueComment, error) {
@@ -87,7 +112,7 @@ func (d *Daemon) startPlanning(ctx context.Context, item daemonstate.WorkItem) e
 	}
 
 	// Configure from workflow params for the planning state
-	wfCfg := d.getWorkflowConfig(repoPath)
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
 	planningState := wfCfg.States["planning"]
 	params := workflow.NewParamHelper(nil)
 	if planningState != nil {
@@ -125,6 +150,9 @@ func (d *Daemon) startPlanning(ctx context.Context, item daemonstate.WorkItem) e
 	// Build initial message using provider-aware formatting
 	issueBody, _ := item.StepData["issue_body"].(string)
 	initialMsg := worker.FormatInitialMessage(item.IssueRef, issueBody)
+	initialMsg = d.withIssueContext(ctx, repoPath, item, initialMsg)
+	initialMsg = d.withRepoContext(repoPath, sess.GetWorkDir(), item, initialMsg)
+	initialMsg = d.withDependencyContext(ctx, repoPath, item, initialMsg)
 
 	// If this is a re-planning attempt triggered by user feedback, include
 	// the previous plan so Claude can revise it, plus all user feedback.
@@ -167,11 +195,11 @@ func (d *Daemon) startPlanning(ctx context.Context, item daemonstate.WorkItem) e
 		claude.ToolSetWeb,
 	)
 	// Set disallowed tools after createWorkerWithPrompt (which calls
-	// configureRunner and resets disallowed tools to nil). The runner is
-	// the same cached instance, so SetDisallowedTools applies correctly.
+	// configureRunner and resets disallowed tools to the repo policy's). The
+	// runner is the same cached instance, so setSessionTools applies correctly.
 	w := d.createWorkerWithPrompt(ctx, item, sess, initialMsg, planningPrompt, planningTools)
 	runner := d.sessionMgr.GetOrCreateRunner(sess)
-	runner.SetDisallowedTools(claude.ToolSetPlanningDeny)
+	d.setSessionTools(runner, sess, planningTools, claude.ToolSetPlanningDeny)
 	runner.SetModel(d.resolveStateModel(wfCfg, "planning"))
 	w.SetPlanningMode(true)
 	maxTurns := params.Int("max_turns", 0)
@@ -285,7 +313,7 @@ func (d *Daemon) startCoding(ctx context.Context, item daemonstate.WorkItem) err
 	}
 
 	// Configure session from workflow config params
-	wfCfg := d.getWorkflowConfig(repoPath)
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
 	codingState := wfCfg.States["coding"]
 	params := workflow.NewParamHelper(nil)
 	if codingState != nil {
@@ -310,19 +338,50 @@ func (d *Daemon) startCoding(ctx context.Context, item daemonstate.WorkItem) err
 	// than the previous order (config saved, work item updated in memory only,
 	// state saved at end of tick). Recovery will detect the orphaned branch on
 	// the next start and clean it up.
+	//
+	// A state entered afresh runs its pipeline, if any, from the plan.
 	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
 		it.SessionID = sess.ID
 		it.Branch = sess.Branch
 		it.State = daemonstate.WorkItemActive
 		it.UpdatedAt = time.Now()
+		delete(it.StepData, pipelinePhaseKey)
 	})
 
 	d.saveConfig("startCoding")
 	d.saveState()
+	if fresh, ok := d.state.GetWorkItem(item.ID); ok {
+		item = fresh
+	}
+
+	d.startCodingWorker(ctx, item, sess, wfCfg, params, "")
+
+	log.Info("started coding", "sessionID", sess.ID, "branch", sess.Branch)
+	return nil
+}
+
+// startCodingWorker starts the coding worker for item in sess. A non-empty
+// note goes ahead of the issue in the initial message, e.g. to tell a
+// resumed session where it left off.
+func (d *Daemon) startCodingWorker(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, wfCfg *workflow.Config, params *workflow.ParamHelper, note string) {
+	log := d.logger.With("workItem", item.ID, "issue", item.IssueRef.ID)
+	repoPath := sess.RepoPath
+
+	pipeline := params.Bool("pipeline", false)
+	if pipeline && pipelinePhase(item) == pipelineReview {
+		d.startPipelineReview(ctx, item, sess, wfCfg, params)
+		return
+	}
 
 	// Build initial message using provider-aware formatting
 	issueBody, _ := item.StepData["issue_body"].(string)
 	initialMsg := worker.FormatInitialMessage(item.IssueRef, issueBody)
+	initialMsg = d.withIssueContext(ctx, repoPath, item, initialMsg)
+	initialMsg = d.withRepoContext(repoPath, sess.GetWorkDir(), item, initialMsg)
+	initialMsg = d.withDependencyContext(ctx, repoPath, item, initialMsg)
+	if note != "" {
+		initialMsg = note + "\n\n---\n" + initialMsg
+	}
 
 	// If a planning phase produced an approved plan, fetch it from the issue
 	// comments and include it so the coding session knows what to implement.
@@ -335,6 +394,17 @@ func (d *Daemon) startCoding(ctx context.Context, item daemonstate.WorkItem) err
 		initialMsg += "\n\n---\nApproved implementation plan:\n" + sanitize.UntrustedContent("approved_plan", plan)
 	}
 
+	// Run as a pipeline, the state's planner goes first; the implementer
+	// then works from its plan.
+	if pipeline {
+		if pipelinePhase(item) != pipelineImplement {
+			d.startPipelinePlan(ctx, item, sess, wfCfg, params, initialMsg)
+			return
+		}
+		plan, _ := item.StepData[pipelinePlanKey].(string)
+		initialMsg += "\n\n---\nImplementation plan to carry out (a reviewer will check your changes against it):\n" + sanitize.UntrustedContent("implementation_plan", plan)
+	}
+
 	// Resolve coding system prompt from workflow config
 	systemPrompt := params.String("system_prompt", "")
 	codingPrompt, err := workflow.ResolveSystemPrompt(systemPrompt, repoPath)
@@ -376,10 +446,15 @@ func (d *Daemon) startCoding(ctx context.Context, item daemonstate.WorkItem) err
 	if maxTurns > 0 || maxDuration > 0 {
 		w.SetLimits(maxTurns, maxDuration)
 	}
+	if params.Bool("verify", false) {
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, worker.VerificationPassedKey)
+			delete(it.StepData, worker.VerificationSummaryKey)
+			delete(it.StepData, worker.VerificationRoundsKey)
+		})
+		w.SetVerification(params.Int("max_verify_rounds", worker.DefaultVerifyRounds))
+	}
 	w.Start(ctx)
-
-	log.Info("started coding", "sessionID", sess.ID, "branch", sess.Branch)
-	return nil
 }
 
 // startDocumenting creates a session and starts a Claude worker for a documentation work item.
@@ -479,7 +554,7 @@ func (d *Daemon) startDocumenting(ctx context.Context, item daemonstate.WorkItem
 	}
 
 	// Configure session from workflow config params — read from "documenting" state
-	wfCfg := d.getWorkflowConfig(repoPath)
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
 	documentingState := wfCfg.States["documenting"]
 	params := workflow.NewParamHelper(nil)
 	if documentingState != nil {
@@ -623,7 +698,7 @@ func (d *Daemon) addressFeedback(ctx context.Context, item daemonstate.WorkItem,
 	prompt := worker.FormatPRCommentsPrompt(reviewComments)
 
 	// Resolve review system prompt and format_command from workflow config.
-	wfCfg := d.getWorkflowConfig(sess.RepoPath)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
 	reviewState := wfCfg.States["await_review"]
 	systemPrompt := ""
 	formatCommand := ""
@@ -672,7 +747,9 @@ func (d *Daemon) addressFeedback(ctx context.Context, item daemonstate.WorkItem,
 // refreshStaleSession checks if the Claude conversation for this item is still
 // alive by looking for an active worker. If no worker is running, the container
 // and conversation are gone and we generate a new session ID so the Claude runner
-// starts a fresh conversation instead of trying to resume a dead one.
+// starts a fresh conversation instead of trying to resume a dead one — unless the
+// container's last completed turn was checkpointed, in which case the session
+// keeps its ID and its next container resumes the conversation.
 // If the session has no WorkTree (reconstructed after restart), a new worktree is
 // created for the existing branch so the container has a directory to mount.
 // Returns the (possibly new) session.
@@ -688,6 +765,14 @@ func (d *Daemon) refreshStaleSession(ctx context.Context, item daemonstate.WorkI
 
 	oldID := sess.ID
 	newID := uuid.New().String()
+	resume := sess.Containerized && claude.HasConversationCheckpoint(oldID)
+	if resume {
+		if sess.WorkTree != "" {
+			log.Info("resuming checkpointed conversation")
+			return sess
+		}
+		newID = oldID
+	}
 
 	// Clone session with new ID
 	newSess := *sess
@@ -716,7 +801,11 @@ func (d *Daemon) refreshStaleSession(ctx context.Context, item daemonstate.WorkI
 
 	d.saveConfig("refreshStaleSession")
 
-	log.Info("refreshed stale session with new ID", "newSessionID", newID)
+	if resume {
+		log.Info("resuming checkpointed conversation in a recreated worktree", "worktree", newSess.WorkTree)
+	} else {
+		log.Info("refreshed stale session with new ID", "newSessionID", newID)
+	}
 	return &newSess
 }
 
@@ -763,22 +852,20 @@ func (d *Daemon) recreateWorktree(ctx context.Context, repoPath, branch, session
 // The daemon makes all policy decisions here rather than relying on SessionManager.
 // If toolOverride is non-nil, it replaces the default tool set.
 func (d *Daemon) configureRunner(runner claude.RunnerConfig, sess *config.Session, customPrompt string, toolOverride []string) {
-	// Clear any previously set disallowed tools so that a runner reused from a
+	// Tools: use override if provided, otherwise compose the default container tool set.
+	// Disallowed tools are reset to the repo policy's so that a runner reused from a
 	// read-only session (e.g., ai.plan, ai.summarize) doesn't keep blocking
 	// mutation tools (Edit, Write, Bash) for subsequent coding actions.
-	runner.SetDisallowedTools(nil)
-
-	// Tools: use override if provided, otherwise compose the default container tool set
-	if toolOverride != nil {
-		runner.SetAllowedTools(toolOverride)
-	} else {
-		runner.SetAllowedTools(claude.ComposeTools(
+	tools := toolOverride
+	if tools == nil {
+		tools = claude.ComposeTools(
 			claude.ToolSetBase,
 			claude.ToolSetContainerShell,
 			claude.ToolSetWeb,
 			claude.ToolSetProductivity,
-		))
+		)
 	}
+	d.setSessionTools(runner, sess, tools, nil)
 
 	// Container mode
 	if sess.Containerized {
@@ -791,7 +878,7 @@ func (d *Daemon) configureRunner(runner claude.RunnerConfig, sess *config.Sessio
 
 	// System prompt
 	if customPrompt != "" {
-		runner.SetSystemPrompt(customPrompt)
+		runner.SetSystemPrompt(customPrompt + resultContractDirective)
 	}
 }
 
@@ -826,13 +913,60 @@ func (d *Daemon) containerImageForRepo(repoPath string) string {
 // but does not start it. The caller is responsible for calling w.Start(ctx).
 // ctx is used to cancel the notification goroutine on shutdown.
 func (d *Daemon) createWorkerWithPrompt(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, initialMsg, customPrompt string, toolOverride ...[]string) *worker.SessionWorker {
-	runner := d.sessionMgr.GetOrCreateRunner(sess)
+	runner, backendErr := d.sessionRunner(sess, item)
+	if model := fallbackModel(item); model != "" {
+		runner.SetModel(claude.ResolveModel(model))
+	}
 	var tools []string
 	if len(toolOverride) > 0 {
 		tools = toolOverride[0]
 	}
+	var required []string
+	if wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item); wfCfg != nil {
+		if state := wfCfg.States[item.CurrentStep]; state != nil {
+			required = workflow.RequiredResultFields(state.Params)
+		}
+	}
+	if customPrompt != "" {
+		customPrompt = d.renderPrompt(ctx, item, customPrompt)
+		customPrompt = d.withStandards(sess.RepoPath, customPrompt)
+		customPrompt = d.withRepoKnowledge(sess.RepoPath, customPrompt)
+		customPrompt = d.withProjectCommands(sess.GetWorkDir(), customPrompt)
+		if len(required) > 0 {
+			customPrompt += resultRequiredDirective(required)
+		}
+	}
 	d.configureRunner(runner, sess, customPrompt, tools)
-	w := worker.NewSessionWorker(d, sess, runner, initialMsg)
+	scopedImage := d.applyScopedImage(ctx, runner, sess, item)
+	d.applyContainerResources(runner, sess, item)
+	d.applyScopedToken(ctx, runner, sess)
+	d.applyNetworkProfile(runner, sess, item)
+	d.applyServices(ctx, runner, sess, item)
+
+	// Drop any result envelope left by a previous state so the engine only
+	// sees what this session reports.
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		delete(it.StepData, workflow.ResultStepDataKey)
+	})
+	// A session without its agent backend or worktree, or whose container
+	// fails preflight, has nothing to work with; fail it like any other
+	// session that couldn't start.
+	err := backendErr
+	if err == nil {
+		err = d.pushRemoteWorkspace(ctx, sess)
+	}
+	if err == nil {
+		err = d.runSessionPreflight(ctx, sess, item, scopedImage)
+	}
+	var w *worker.SessionWorker
+	if err != nil {
+		w = worker.NewDoneWorkerWithError(err)
+	} else {
+		w = worker.NewSessionWorker(d, sess, runner, initialMsg)
+		if len(required) > 0 {
+			w.SetResultContract(required)
+		}
+	}
 
 	d.mu.Lock()
 	d.workers[item.ID] = w
@@ -853,6 +987,116 @@ func (d *Daemon) createWorkerWithPrompt(ctx context.Context, item daemonstate.Wo
 	return w
 }
 
+// scopedTokenRefreshMargin is how long before a scoped GitHub token expires
+// that it is re-minted. GitHub App installation tokens last an hour.
+const scopedTokenRefreshMargin = 10 * time.Minute
+
+// scopedToken is the scoped GitHub token a session's runner holds.
+type scopedToken struct {
+	runner    claude.RunnerConfig
+	ownerRepo string
+	expiresAt time.Time
+}
+
+// applyScopedToken mints a GitHub token restricted to the session's repository
+// and passes it into the container as GH_TOKEN/GITHUB_TOKEN. A fresh token is
+// minted on every worker start, so a restarted session never inherits an
+// expired one. Failures are logged and the session proceeds without a token
+// rather than failing outright.
+func (d *Daemon) applyScopedToken(ctx context.Context, runner claude.RunnerConfig, sess *config.Session) {
+	if d.tokenMinter == nil || !sess.Containerized {
+		return
+	}
+	log := d.logger.With("sessionID", sess.ID, "repo", sess.RepoPath)
+
+	remoteURL, err := d.gitService.GetRemoteOriginURL(ctx, sess.RepoPath)
+	if err != nil {
+		log.Warn("failed to resolve remote for scoped token", "error", err)
+		return
+	}
+	ownerRepo := git.ExtractOwnerRepo(remoteURL)
+	if ownerRepo == "" {
+		log.Warn("could not determine owner/repo for scoped token", "remote", remoteURL)
+		return
+	}
+
+	if err := d.mintScopedToken(ctx, runner, sess.ID, ownerRepo); err != nil {
+		log.Warn("failed to mint scoped GitHub token", "error", err)
+	}
+}
+
+// mintScopedToken mints a token for ownerRepo, hands it to the session's
+// runner and records when it expires.
+func (d *Daemon) mintScopedToken(ctx context.Context, runner claude.RunnerConfig, sessionID, ownerRepo string) error {
+	tok, err := d.tokenMinter.MintRepoToken(ctx, ownerRepo)
+	if err != nil {
+		return err
+	}
+	runner.SetContainerEnv([]string{"GH_TOKEN=" + tok.Value, "GITHUB_TOKEN=" + tok.Value})
+
+	d.mu.Lock()
+	if d.scopedTokens == nil {
+		d.scopedTokens = make(map[string]scopedToken)
+	}
+	d.scopedTokens[sessionID] = scopedToken{runner: runner, ownerRepo: ownerRepo, expiresAt: tok.ExpiresAt}
+	d.mu.Unlock()
+
+	d.logger.Info("minted scoped GitHub token", "sessionID", sessionID, "ownerRepo", ownerRepo, "expiresAt", tok.ExpiresAt)
+	return nil
+}
+
+// refreshScopedTokens re-mints the scoped tokens of running sessions that
+// expire within scopedTokenRefreshMargin, so a session outliving its token
+// gets a valid one whenever its runner next starts the agent, such as after
+// an interrupt or for its next state. Tokens of sessions no longer running
+// are forgotten; their next worker mints its own.
+func (d *Daemon) refreshScopedTokens(ctx context.Context) {
+	if d.tokenMinter == nil {
+		return
+	}
+	d.mu.Lock()
+	running := make(map[string]bool, len(d.workers))
+	for _, w := range d.workers {
+		if !w.Done() {
+			running[w.SessionID()] = true
+		}
+	}
+	due := make(map[string]scopedToken)
+	for sessionID, tok := range d.scopedTokens {
+		switch {
+		case !running[sessionID]:
+			delete(d.scopedTokens, sessionID)
+		case time.Until(tok.expiresAt) < scopedTokenRefreshMargin:
+			due[sessionID] = tok
+		}
+	}
+	d.mu.Unlock()
+
+	for sessionID, tok := range due {
+		if err := d.mintScopedToken(ctx, tok.runner, sessionID, tok.ownerRepo); err != nil {
+			d.logger.Warn("failed to refresh scoped GitHub token", "sessionID", sessionID,
+				"ownerRepo", tok.ownerRepo, "expiresAt", tok.expiresAt, "error", err)
+		}
+	}
+}
+
+// applyNetworkProfile points a containerized session at the Docker network for
+// the network profile of the item's current state, in the workflow it runs
+// on. The network is always set (possibly to "") because runners are reused
+// across states with different profiles.
+func (d *Daemon) applyNetworkProfile(runner claude.RunnerConfig, sess *config.Session, item daemonstate.WorkItem) {
+	if !sess.Containerized {
+		return
+	}
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	network := wfCfg.ContainerNetwork(item.CurrentStep)
+	runner.SetContainerNetwork(network)
+	if network != "" {
+		d.logger.Debug("applied container network profile", "sessionID", sess.ID, "state", item.CurrentStep,
+			"profile", wfCfg.NetworkProfile(item.CurrentStep), "network", network)
+	}
+}
+
 // startWorkerWithPrompt creates and starts a session worker with an optional custom system prompt.
 func (d *Daemon) startWorkerWithPrompt(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, initialMsg, customPrompt string) {
 	w := d.createWorkerWithPrompt(ctx, item, sess, initialMsg, customPrompt)
@@ -869,6 +1113,9 @@ func (d *Daemon) cleanupSession(ctx context.Context, sessionID string) {
 	log := d.logger.With("sessionID", sessionID, "branch", sess.Branch)
 
 	d.sessionMgr.DeleteSession(sessionID)
+	d.setSessionBackend(sessionID, "")
+	d.stopSessionServices(ctx, sess)
+	d.removeRemoteWorkspace(ctx, sess)
 
 	if err := d.sessionService.Delete(ctx, sess); err != nil {
 		log.Warn("failed to delete worktree", "error", err)
@@ -877,6 +1124,7 @@ func (d *Daemon) cleanupSession(ctx context.Context, sessionID string) {
 	d.config.RemoveSession(sessionID)
 	d.config.ClearOrphanedParentIDs([]string{sessionID})
 	config.DeleteSessionMessages(sessionID)
+	claude.DeleteConversation(sessionID)
 
 	d.saveConfig("cleanupSession")
 
@@ -894,6 +1142,9 @@ func (d *Daemon) cleanupPlanningSession(ctx context.Context, sessionID string) {
 	log := d.logger.With("sessionID", sessionID, "branch", sess.Branch)
 
 	d.sessionMgr.DeleteSession(sessionID)
+	d.setSessionBackend(sessionID, "")
+	d.stopSessionServices(ctx, sess)
+	d.removeRemoteWorkspace(ctx, sess)
 
 	if err := d.sessionService.Delete(ctx, sess); err != nil {
 		log.Warn("failed to delete planning worktree", "error", err)
@@ -901,6 +1152,7 @@ func (d *Daemon) cleanupPlanningSession(ctx context.Context, sessionID string) {
 
 	d.config.RemoveSession(sessionID)
 	config.DeleteSessionMessages(sessionID)
+	claude.DeleteConversation(sessionID)
 
 	d.saveConfig("cleanupPlanningSession")
 
@@ -1065,7 +1317,7 @@ func (d *Daemon) startFixCI(ctx context.Context, item daemonstate.WorkItem, sess
 	prompt := formatCIFixPrompt(round, ciLogs)
 
 	// Resolve system prompt and format_command from workflow config's fix_ci state.
-	wfCfg := d.getWorkflowConfig(sess.RepoPath)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
 	fixState := wfCfg.States["fix_ci"]
 	systemPrompt := ""
 	formatCommand := ""
@@ -1205,7 +1457,7 @@ func (d *Daemon) startResolveConflicts(ctx context.Context, item *daemonstate.Wo
 	prompt := formatConflictResolutionPrompt(round, conflictedFiles)
 
 	// Resolve system prompt from workflow config
-	wfCfg := d.getWorkflowConfig(sess.RepoPath)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, *item)
 	resolveState := wfCfg.States["resolve_conflicts"]
 	systemPrompt := ""
 	if resolveState != nil {
@@ -1281,7 +1533,7 @@ func (d *Daemon) startAddressReview(ctx context.Context, item daemonstate.WorkIt
 	}
 
 	// Resolve system prompt and format_command from workflow config's address_review state.
-	wfCfg := d.getWorkflowConfig(sess.RepoPath)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
 	reviewState := wfCfg.States["address_review"]
 	systemPrompt := ""
 	formatCommand := ""
@@ -1391,9 +1643,86 @@ func getAddressReviewRounds(stepData map[string]any) int {
 	}
 }
 
+// startAddressFeedback resumes the coding session to address feedback such
+// as a failed quality gate's report. Params are those of the
+// ai.address_feedback state.
+func (d *Daemon) startAddressFeedback(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, round int, feedback string, params *workflow.ParamHelper) error {
+	sess = d.refreshStaleSession(ctx, item, sess)
+
+	prompt := maybeAppendSimplify(formatAddressFeedbackPrompt(round, feedback), params.Bool("simplify", false))
+
+	resolvedPrompt, err := workflow.ResolveSystemPrompt(params.String("system_prompt", ""), sess.RepoPath)
+	if err != nil {
+		d.logger.Warn("failed to resolve address_feedback system prompt", "error", err)
+	}
+	if resolvedPrompt == "" {
+		resolvedPrompt = DefaultCodingSystemPrompt
+	}
+
+	// Use the state's format_command, or keep the one the coding step stored.
+	formatCommand := params.String("format_command", "")
+	if formatCommand != "" {
+		formatMessage := params.String("format_message", "Apply auto-formatting")
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			it.StepData["_format_command"] = formatCommand
+			it.StepData["_format_message"] = formatMessage
+		})
+		d.saveState()
+	} else {
+		formatCommand, _ = item.StepData["_format_command"].(string)
+	}
+	if formatCommand != "" {
+		resolvedPrompt = resolvedPrompt + "\n\nFORMATTING: Before committing any changes, run the following formatter command:\n  " + formatCommand + "\nStage and include all formatting changes in your commit."
+	}
+
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	runner := d.sessionMgr.GetOrCreateRunner(sess)
+	runner.SetModel(d.resolveStateModel(wfCfg, item.CurrentStep))
+
+	d.startWorkerWithPrompt(ctx, item, sess, prompt, resolvedPrompt)
+	d.logger.Info("started address feedback session", "workItem", item.ID, "round", round)
+	return nil
+}
+
+// formatAddressFeedbackPrompt builds the prompt that asks Claude to fix the
+// problems described in feedback.
+func formatAddressFeedbackPrompt(round int, feedback string) string {
+	return fmt.Sprintf(`QUALITY FEEDBACK — FIX ROUND %d
+
+Automated checks on your changes found the problems below. Please fix them.
+
+INSTRUCTIONS:
+1. Read the failing checks and their output carefully
+2. Fix the code so each check passes (for size limits, reduce the change to what the task needs)
+3. Re-run the failing commands locally to verify your fix
+4. Commit your changes locally — the system re-runs the checks automatically
+
+DO NOT push or create PRs — the system handles this.
+
+FAILING CHECKS:
+%s`, round, feedback)
+}
+
+// getFeedbackFixRounds extracts the address feedback round counter from step data.
+func getFeedbackFixRounds(stepData map[string]any) int {
+	v, ok := stepData["feedback_fix_rounds"]
+	if !ok {
+		return 0
+	}
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
 // writePRDescription generates a rich PR description from the branch diff and updates the PR body.
 // It uses Claude with a tailored prompt focused on description quality, then edits the open PR body.
-func (d *Daemon) writePRDescription(ctx context.Context, item daemonstate.WorkItem, sess *config.Session) error {
+// When reviewMap is set, a review map section computed from the diff is appended.
+func (d *Daemon) writePRDescription(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, reviewMap bool) error {
 	// Refresh stale session so worktree path is valid.
 	sess = d.refreshStaleSession(ctx, item, sess)
 
@@ -1411,6 +1740,17 @@ func (d *Daemon) writePRDescription(ctx context.Context, item daemonstate.WorkIt
 	if err != nil {
 		return fmt.Errorf("failed to generate PR description: %w", err)
 	}
+	if reviewMap {
+		if section, err := d.generateReviewMap(ctx, sess); err != nil {
+			d.logger.Warn("failed to generate review map (non-fatal)", "workItem", item.ID, "error", err)
+		} else {
+			body = git.WithReviewMap(body, section)
+		}
+	}
+	body = withVerification(body, verificationSection(item))
+	if fp := d.configFingerprint(sess.RepoPath); fp.Workflow != "" {
+		body = workflow.WithFingerprintFooter(body, fp)
+	}
 
 	updateCtx, updateCancel := context.WithTimeout(ctx, timeoutStandardOp)
 	defer updateCancel()
@@ -1504,7 +1844,7 @@ func (d *Daemon) startSummarize(ctx context.Context, item daemonstate.WorkItem)
 	}
 
 	// Resolve system prompt from the workflow config for this state.
-	wfCfg := d.getWorkflowConfig(repoPath)
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
 	summarizeState := wfCfg.States[item.CurrentStep]
 	params := workflow.NewParamHelper(nil)
 	if summarizeState != nil {
@@ -1521,15 +1861,15 @@ func (d *Daemon) startSummarize(ctx context.Context, item daemonstate.WorkItem)
 
 	// Configure a new runner with read-only tools and planning mode.
 	// Set disallowed tools after createWorkerWithPrompt (which calls
-	// configureRunner and resets disallowed tools to nil).
+	// configureRunner and resets disallowed tools to the repo policy's).
 	summarizeTools := claude.ComposeTools(
 		claude.ToolSetReadOnly,
 		claude.ToolSetWeb,
 	)
 	w := d.createWorkerWithPrompt(ctx, item, sess, initialMsg, resolvedPrompt, summarizeTools)
 	runner := d.sessionMgr.GetOrCreateRunner(sess)
-	runner.SetDisallowedTools(claude.ToolSetPlanningDeny)
-	runner.SetModel(d.resolveStateModel(wfCfg, item.CurrentStep))
+	d.setSessionTools(runner, sess, summarizeTools, claude.ToolSetPlanningDeny)
+	runner.SetModel(d.stepModel(wfCfg, item))
 	w.SetPlanningMode(true)
 	maxTurns := params.Int("max_turns", 0)
 	maxDuration := params.Duration("max_duration", 0)
@@ -1585,7 +1925,7 @@ func (d *Daemon) startAIReview(ctx context.Context, item daemonstate.WorkItem, s
 
 	// Resolve system prompt from the workflow config state for this step.
 	// The state name is item.CurrentStep (e.g., "ai_review").
-	wfCfg := d.getWorkflowConfig(sess.RepoPath)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
 	reviewState := wfCfg.States[item.CurrentStep]
 	systemPrompt := ""
 	if reviewState != nil {
@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// commandsDirective is appended to session system prompts when the repo's
+// canonical commands were detected. The %s lists them.
+const commandsDirective = `
+
+PROJECT COMMANDS:
+These are this repository's canonical commands, detected from its task
+runners and manifests. Use them to build, test and lint your changes rather
+than guessing, and run them from the repository root.
+%s`
+
+// projectCommands returns the canonical commands detected in item's
+// worktree, or in repoPath before it has one, so a branch's own Makefile or
+// scripts are the ones used.
+func (d *Daemon) projectCommands(repoPath string, item daemonstate.WorkItem) container.Commands {
+	dir := repoPath
+	if sess := d.config.GetSession(item.SessionID); item.SessionID != "" && sess != nil && sess.GetWorkDir() != "" {
+		dir = sess.GetWorkDir()
+	}
+	return container.DetectCommands(dir)
+}
+
+// templateCommands converts detected commands for templates.
+func templateCommands(c container.Commands) workflow.TemplateCommands {
+	return workflow.TemplateCommands{Build: c.Build, Test: c.Test, Lint: c.Lint}
+}
+
+// withProjectCommands appends the canonical commands detected in dir to a
+// session system prompt. The prompt is returned unchanged when none were
+// detected.
+func (d *Daemon) withProjectCommands(dir, prompt string) string {
+	c := container.DetectCommands(dir)
+	if c.Empty() {
+		return prompt
+	}
+	var b strings.Builder
+	for _, cmd := range []struct{ name, command string }{{"build", c.Build}, {"test", c.Test}, {"lint", c.Lint}} {
+		if cmd.command != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", cmd.name, cmd.command)
+		}
+	}
+	return prompt + fmt.Sprintf(commandsDirective, strings.TrimSuffix(b.String(), "\n"))
+}
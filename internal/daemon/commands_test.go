@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestWithProjectCommands(t *testing.T) {
+	d := testDaemon(testConfig())
+	dir := t.TempDir()
+
+	if got := d.withProjectCommands(dir, "base prompt"); got != "base prompt" {
+		t.Errorf("expected the prompt unchanged without detected commands, got %q", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("test:\n\tgo test ./...\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644)
+	got := d.withProjectCommands(dir, "base prompt")
+	if !strings.HasPrefix(got, "base prompt\n\nPROJECT COMMANDS:") {
+		t.Errorf("expected the commands appended, got %q", got)
+	}
+	for _, want := range []string{"- build: go build ./...", "- test: make test", "- lint: go vet ./..."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in prompt:\n%s", want, got)
+		}
+	}
+}
+
+func TestTemplateData_CommandsFromWorktree(t *testing.T) {
+	workDir := initTestGitRepo(t)
+	os.WriteFile(filepath.Join(workDir, "justfile"), []byte("lint:\n  golangci-lint run\n"), 0o644)
+
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.WorkTree = workDir
+	cfg.AddSession(*sess)
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", SessionID: "sess-1"})
+	item, _ := d.state.GetWorkItem("item-1")
+
+	if got := d.templateData(context.Background(), item).Commands.Lint; got != "just lint" {
+		t.Errorf("Commands.Lint = %q, want the worktree's just lint", got)
+	}
+
+	// A command the repo doesn't have fails rather than running nothing.
+	_, _, err := d.runExecCommand(context.Background(), item, workflow.NewParamHelper(map[string]any{"command": "{{.Commands.Test}}"}))
+	if err == nil || !strings.Contains(err.Error(), "rendered empty") {
+		t.Errorf("expected an error for an empty command, got %v", err)
+	}
+	_, output, err := d.runExecCommand(context.Background(), item, workflow.NewParamHelper(map[string]any{"command": "echo {{.Commands.Lint}}"}))
+	if err != nil || output != "just lint" {
+		t.Errorf("expected the detected command rendered, got %q, %v", output, err)
+	}
+}
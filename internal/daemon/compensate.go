@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// compensateKey is the step data key listing the states a work item has
+// entered that declare on_failure compensations, in the order entered.
+const compensateKey = "_compensate"
+
+// recordCompensation notes that an item entered a state with on_failure
+// compensations, so they run if the item later fails. A state re-entered
+// (e.g. on a retry loop) is only recorded once.
+func (d *Daemon) recordCompensation(t daemonstate.Transition) {
+	repoPath := d.workItemRepoPath(t.Item)
+	if repoPath == "" {
+		repoPath = d.repoFilter
+	}
+	engine := d.getItemEngine(repoPath, t.Item)
+	if engine == nil {
+		return
+	}
+	if state := engine.GetState(t.To); state == nil || len(state.OnFailure) == 0 {
+		return
+	}
+	d.state.UpdateWorkItem(t.Item.ID, func(it *daemonstate.WorkItem) {
+		states := compensationStates(it.StepData)
+		if slices.Contains(states, t.To) {
+			return
+		}
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData[compensateKey] = append(states, t.To)
+	})
+}
+
+// compensationStates returns the states recorded under compensateKey. The
+// list is a []string in memory and a []any once reloaded from disk.
+func compensationStates(stepData map[string]any) []string {
+	switch v := stepData[compensateKey].(type) {
+	case []string:
+		return slices.Clone(v)
+	case []any:
+		states := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				states = append(states, s)
+			}
+		}
+		return states
+	}
+	return nil
+}
+
+// processCompensations runs the on_failure compensations of failed work
+// items, undoing what the states they passed through left behind. Each
+// item's list is cleared before running, so compensations run at most once
+// even if the daemon stops part-way.
+func (d *Daemon) processCompensations(ctx context.Context) {
+	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemFailed) {
+		states := compensationStates(item.StepData)
+		if len(states) == 0 {
+			continue
+		}
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, compensateKey)
+		})
+
+		repoPath := d.resolveRepoPath(ctx, item)
+		engine := d.getItemEngine(repoPath, item)
+		if engine == nil {
+			d.logger.Warn("no workflow engine for failed work item, skipping compensation", "workItem", item.ID)
+			continue
+		}
+
+		// Compensations can reference the failure as {{step.error}}.
+		errMsg := item.ErrorMessage
+		if errMsg == "" {
+			errMsg, _ = item.StepData["_last_error"].(string)
+		}
+		item.StepData = maps.Clone(item.StepData)
+		if item.StepData == nil {
+			item.StepData = make(map[string]any)
+		}
+		item.StepData["error"] = errMsg
+
+		d.logger.Info("running compensations for failed work item", "event", "compensate",
+			"workItem", item.ID, "states", states)
+		view := d.workItemView(item)
+		view.RepoPath = repoPath
+		sess := d.config.GetSession(item.SessionID)
+		if sess == nil {
+			sess = &config.Session{RepoPath: repoPath, Branch: item.Branch}
+		}
+		skipped := engine.Compensate(ctx, view, states, d.hookContext(ctx, item, sess))
+		if len(skipped) > 0 {
+			msg := fmt.Sprintf("erg did not run these compensations because they are listed in `confirm_actions` and a failed item cannot wait for confirmation: `%s`. Run them by hand if they are still needed.",
+				strings.Join(skipped, "`, `"))
+			if _, err := d.postMarkedComment(ctx, item, "compensate-skipped", msg); err != nil {
+				d.logger.Warn("failed to report skipped compensations", "workItem", item.ID, "error", err)
+			}
+		}
+	}
+}
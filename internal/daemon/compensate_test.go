@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestCompensation_RunsOnceAfterFailure(t *testing.T) {
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:  "claim",
+		Source: workflow.SourceConfig{Provider: "github"},
+		States: map[string]*workflow.State{
+			"claim": {
+				Type: workflow.StateTypeTask, Action: "github.add_label", Next: "work",
+				Params:    map[string]any{"label": "wip"},
+				OnFailure: []workflow.CompensationConfig{{Action: "github.remove_label", Params: map[string]any{"label": "wip"}}},
+			},
+			"work":   {Type: workflow.StateTypeTask, Action: "exec.run", Next: "failed"},
+			"failed": {Type: workflow.StateTypeFail},
+		},
+	}
+	addLabel, removeLabel, work := &countingAction{}, &countingAction{}, &countingAction{}
+	reg := d.buildActionRegistry()
+	reg.Register("github.add_label", addLabel)
+	reg.Register("github.remove_label", removeLabel)
+	reg.Register("exec.run", work)
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, reg, newEventChecker(d), d.logger)
+	d.watchTransitions()
+
+	sess := testSession("sess-1")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "42"},
+		SessionID: sess.ID,
+	})
+	d.state.AdvanceWorkItem("item-1", "claim", "idle")
+
+	// Compensations are only recorded while the item is running.
+	d.processCompensations(context.Background())
+	if removeLabel.runs != 0 {
+		t.Fatalf("expected no compensation before failure, got %d", removeLabel.runs)
+	}
+
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemFailed {
+		t.Fatalf("expected item to fail, got %s", item.State)
+	}
+	if got := compensationStates(item.StepData); len(got) != 1 || got[0] != "claim" {
+		t.Fatalf("expected claim recorded for compensation, got %v", got)
+	}
+
+	d.processCompensations(context.Background())
+	d.processCompensations(context.Background())
+	if removeLabel.runs != 1 {
+		t.Errorf("expected remove_label to run once, got %d", removeLabel.runs)
+	}
+	item, _ = d.state.GetWorkItem("item-1")
+	if _, ok := item.StepData[compensateKey]; ok {
+		t.Errorf("expected compensation list cleared, got %v", item.StepData)
+	}
+}
+
+func TestCompensationStates(t *testing.T) {
+	if got := compensationStates(map[string]any{compensateKey: []any{"a", "b"}}); len(got) != 2 || got[1] != "b" {
+		t.Errorf("reloaded list: got %v", got)
+	}
+	if got := compensationStates(nil); got != nil {
+		t.Errorf("missing list: got %v", got)
+	}
+}
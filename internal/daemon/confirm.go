@@ -0,0 +1,190 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// phaseAwaitingConfirmation marks a work item parked before a destructive
+// action listed in settings.confirm_actions. The action runs only after a
+// human confirms it with `erg approve` or a "/erg confirm" comment from an
+// authorized user.
+const phaseAwaitingConfirmation = "awaiting_confirmation"
+
+// confirmCommandRe matches "/erg confirm" and "/erg reject" tracker commands
+// at the start of a comment line.
+var confirmCommandRe = regexp.MustCompile(`(?im)^\s*/erg\s+(confirm|reject)\b`)
+
+// holdForConfirmation reports whether the sync chain must stop because the
+// item's current step runs a destructive action that has not been confirmed.
+// A confirmation is consumed when the action runs, so revisiting the step
+// later asks again.
+func (d *Daemon) holdForConfirmation(ctx context.Context, item daemonstate.WorkItem, engine *workflow.Engine) bool {
+	state := engine.GetState(item.CurrentStep)
+	if state == nil || state.Type != workflow.StateTypeTask {
+		return false
+	}
+	if wfCfg, _ := d.lookupWorkflowConfig(d.resolveRepoPath(ctx, item)); !wfCfg.RequiresConfirmation(state.Action) {
+		return false
+	}
+	if confirmed, _ := item.StepData["_confirmed_step"].(string); confirmed == item.CurrentStep {
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, "_confirmed_step")
+		})
+		return false
+	}
+	d.requestConfirmation(ctx, item, state.Action)
+	return true
+}
+
+// requestConfirmation parks the item and asks for confirmation on its issue.
+// The request comment is posted before the request time is recorded so it is
+// never mistaken for a reply.
+func (d *Daemon) requestConfirmation(ctx context.Context, item daemonstate.WorkItem, action string) {
+	log := d.logger.With("workItem", item.ID, "step", item.CurrentStep, "action", action)
+
+	msg := formatConfirmationRequest(item, action)
+	if ok, err := d.postMarkedComment(ctx, item, "confirm-"+item.CurrentStep, msg); err != nil {
+		log.Warn("failed to post confirmation request (non-fatal)", "error", err)
+	} else if !ok {
+		log.Debug("confirmation comments not supported for source", "source", item.IssueRef.Source)
+	}
+
+	now := time.Now()
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_pending_action"] = action
+		it.StepData["_confirm_requested_at"] = now.Format(time.RFC3339)
+		it.Phase = phaseAwaitingConfirmation
+		it.UpdatedAt = now
+	})
+	log.Info("destructive action awaiting confirmation", "event", "confirm.requested")
+}
+
+// formatConfirmationRequest renders the issue comment asking for confirmation.
+func formatConfirmationRequest(item daemonstate.WorkItem, action string) string {
+	return fmt.Sprintf("erg is about to %s (`%s` at step `%s`) and is waiting for confirmation.\n\n"+
+		"Reply `/erg confirm` to proceed or `/erg reject` to stop, or run `erg approve %s` (add `--reject` to stop).",
+		workflow.DestructiveActions[action], action, item.CurrentStep, item.IssueRef.ID)
+}
+
+// processConfirmationItems resolves items awaiting confirmation. Decisions
+// from `erg approve` are read on every tick; tracker comments are polled at
+// the review poll interval.
+func (d *Daemon) processConfirmationItems(ctx context.Context) {
+	decisions := make(map[string]bool)
+	for _, c := range daemonstate.TakeConfirmations(d.stateKey()) {
+		decisions[c.WorkItemID] = c.Approved
+	}
+	pollTracker := time.Since(d.lastReviewPollAt) >= d.reviewPollInterval
+
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase != phaseAwaitingConfirmation {
+			continue
+		}
+		approved, decided := decisions[item.ID]
+		via := "cli"
+		if !decided && pollTracker {
+			approved, decided = d.trackerConfirmation(ctx, item)
+			via = "tracker"
+		}
+		if !decided {
+			continue
+		}
+		d.resolveConfirmation(ctx, item, approved, via)
+	}
+}
+
+// trackerConfirmation looks for a "/erg confirm" or "/erg reject" comment
+// posted on the item's issue since confirmation was requested by someone
+// allowed to approve (see canConfirm). The latest such command wins.
+func (d *Daemon) trackerConfirmation(ctx context.Context, item daemonstate.WorkItem) (approved, decided bool) {
+	requestedAt, _ := item.StepData["_confirm_requested_at"].(string)
+	cutoff, _ := time.Parse(time.RFC3339, requestedAt)
+
+	repoPath := d.resolveRepoPath(ctx, item)
+	pollCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	defer cancel()
+	comments, err := newEventChecker(d).issueComments(pollCtx, repoPath, item.IssueRef.Source, item.IssueRef.ID)
+	if err != nil {
+		d.logger.Debug("failed to fetch comments for confirmation", "workItem", item.ID, "error", err)
+		return false, false
+	}
+	for _, comment := range comments {
+		if isErgSystemComment(comment) || comment.CreatedAt.Before(cutoff) {
+			continue
+		}
+		m := confirmCommandRe.FindStringSubmatch(comment.Body)
+		if m == nil {
+			continue
+		}
+		if !d.canConfirm(pollCtx, repoPath, item.IssueRef.Source, comment.Author) {
+			d.logger.Info("ignoring confirmation command from unauthorized user",
+				"workItem", item.ID, "author", comment.Author, "command", m[1])
+			continue
+		}
+		approved, decided = strings.EqualFold(m[1], "confirm"), true
+	}
+	return approved, decided
+}
+
+// canConfirm reports whether author may confirm or reject a destructive
+// action from the tracker. When settings.approvers is set only those users
+// may; otherwise GitHub users with write access to the repo may, and other
+// trackers' users may not, since erg cannot tell who has access there.
+func (d *Daemon) canConfirm(ctx context.Context, repoPath, source, author string) bool {
+	if wfCfg, _ := d.lookupWorkflowConfig(repoPath); wfCfg.HasApprovers() {
+		return wfCfg.IsApprover(author)
+	}
+	if source != "github" || author == "" {
+		return false
+	}
+	canWrite, err := d.gitService.CheckUserCanWrite(ctx, repoPath, author)
+	if err != nil {
+		d.logger.Warn("failed to check write access, ignoring confirmation command", "author", author, "error", err)
+		return false
+	}
+	return canWrite
+}
+
+// resolveConfirmation applies a human decision. An approved action runs
+// immediately; a rejected one follows the step's error edge, or fails the
+// item when there is none.
+func (d *Daemon) resolveConfirmation(ctx context.Context, item daemonstate.WorkItem, approved bool, via string) {
+	action, _ := item.StepData["_pending_action"].(string)
+	log := d.logger.With("workItem", item.ID, "step", item.CurrentStep, "action", action, "via", via)
+
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		delete(it.StepData, "_pending_action")
+		delete(it.StepData, "_confirm_requested_at")
+		if approved {
+			it.StepData["_confirmed_step"] = it.CurrentStep
+		}
+	})
+
+	engine := d.getItemEngine(d.resolveRepoPath(ctx, item), item)
+	if approved {
+		log.Info("destructive action confirmed", "event", "confirm.approved")
+		d.state.AdvanceWorkItem(item.ID, item.CurrentStep, "idle")
+		d.executeSyncChain(ctx, item.ID, engine)
+		return
+	}
+
+	log.Info("destructive action rejected", "event", "confirm.rejected")
+	if state := engine.GetState(item.CurrentStep); state != nil && state.Error != "" {
+		d.state.AdvanceWorkItem(item.ID, state.Error, "idle", stepDisplayName(engine, state.Error))
+		d.executeSyncChain(ctx, item.ID, engine)
+		return
+	}
+	d.state.SetErrorMessage(item.ID, fmt.Sprintf("%s rejected by human", action))
+	d.postTerminalMarker(ctx, item.ID, false)
+	d.state.MarkWorkItemTerminal(item.ID, false)
+}
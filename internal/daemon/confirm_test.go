@@ -0,0 +1,239 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// countingAction records how many times it ran.
+type countingAction struct {
+	runs int
+}
+
+func (a *countingAction) Execute(_ context.Context, _ *workflow.ActionContext) workflow.ActionResult {
+	a.runs++
+	return workflow.ActionResult{Success: true}
+}
+
+// confirmTestDaemon returns a daemon whose workflow closes the issue and then
+// succeeds, with github.close_issue gated behind confirmation and alice the
+// only approver. The close action is replaced by a counter so tests can see
+// whether it ran.
+func confirmTestDaemon(t *testing.T, withErrorEdge bool) (*Daemon, *issues.FakeProvider, *countingAction) {
+	t.Helper()
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	prov := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	closeState := &workflow.State{Type: workflow.StateTypeTask, Action: "github.close_issue", Next: "done"}
+	if withErrorEdge {
+		closeState.Error = "rejected"
+	}
+	wfCfg := &workflow.Config{
+		Start:  "close",
+		Source: workflow.SourceConfig{Provider: "linear"},
+		States: map[string]*workflow.State{
+			"close":    closeState,
+			"done":     {Type: workflow.StateTypeSucceed},
+			"rejected": {Type: workflow.StateTypeFail},
+		},
+		Settings: &workflow.SettingsConfig{
+			ConfirmActions: []string{"github.close_issue"},
+			Approvers:      []string{"Alice"},
+		},
+	}
+	action := &countingAction{}
+	reg := d.buildActionRegistry()
+	reg.Register("github.close_issue", action)
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, reg, newEventChecker(d), d.logger)
+
+	sess := testSession("sess-1")
+	cfg.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-1",
+		IssueRef:    config.IssueRef{Source: "linear", ID: "ENG-1"},
+		SessionID:   "sess-1",
+		CurrentStep: "close",
+		Phase:       "idle",
+		StepData:    map[string]any{},
+	})
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) { it.State = daemonstate.WorkItemActive })
+	return d, prov, action
+}
+
+func TestConfirmation_HoldsDestructiveAction(t *testing.T) {
+	d, prov, action := confirmTestDaemon(t, false)
+
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+	if action.runs != 0 {
+		t.Fatalf("destructive action ran %d times before confirmation", action.runs)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.Phase != phaseAwaitingConfirmation {
+		t.Errorf("Phase = %q, want %q", item.Phase, phaseAwaitingConfirmation)
+	}
+	if item.StepData["_pending_action"] != "github.close_issue" {
+		t.Errorf("_pending_action = %v", item.StepData["_pending_action"])
+	}
+	if len(prov.CommentCalls) != 1 || !strings.Contains(prov.CommentCalls[0].Args[0], "/erg confirm") {
+		t.Errorf("expected one confirmation request comment, got %+v", prov.CommentCalls)
+	}
+
+	// Without a decision the item stays parked.
+	d.processConfirmationItems(context.Background())
+	if action.runs != 0 {
+		t.Error("action ran without a decision")
+	}
+}
+
+func TestConfirmation_CLIApprovalRunsAction(t *testing.T) {
+	d, _, action := confirmTestDaemon(t, false)
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+	if err := daemonstate.WriteConfirmation(d.stateKey(), daemonstate.Confirmation{WorkItemID: "item-1", Approved: true, At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	d.processConfirmationItems(context.Background())
+
+	if action.runs != 1 {
+		t.Fatalf("action ran %d times, want 1", action.runs)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemCompleted {
+		t.Errorf("State = %q, want completed", item.State)
+	}
+	if _, ok := item.StepData["_confirmed_step"]; ok {
+		t.Error("expected confirmation to be consumed")
+	}
+	if got := daemonstate.TakeConfirmations(d.stateKey()); len(got) != 0 {
+		t.Errorf("expected confirmation file consumed, got %+v", got)
+	}
+}
+
+func TestConfirmation_TrackerReject(t *testing.T) {
+	tests := []struct {
+		name          string
+		withErrorEdge bool
+		wantStep      string
+	}{
+		{"follows error edge", true, "rejected"},
+		{"fails without error edge", false, "close"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, prov, action := confirmTestDaemon(t, tt.withErrorEdge)
+			d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+			prov.SetComments("ENG-1", []issues.IssueComment{
+				{Author: "old", Body: "/erg confirm", CreatedAt: time.Now().Add(-time.Hour)},
+				{Author: "alice", Body: "Not yet.\n/erg reject", CreatedAt: time.Now().Add(time.Minute)},
+			})
+			d.lastReviewPollAt = time.Time{}
+			d.processConfirmationItems(context.Background())
+
+			if action.runs != 0 {
+				t.Errorf("rejected action ran %d times", action.runs)
+			}
+			item, _ := d.state.GetWorkItem("item-1")
+			if item.State != daemonstate.WorkItemFailed {
+				t.Errorf("State = %q, want failed", item.State)
+			}
+			if item.CurrentStep != tt.wantStep {
+				t.Errorf("CurrentStep = %q, want %q", item.CurrentStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestConfirmation_TrackerConfirm(t *testing.T) {
+	d, prov, action := confirmTestDaemon(t, false)
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+	prov.SetComments("ENG-1", []issues.IssueComment{
+		{Author: "alice", Body: "/ERG Confirm", CreatedAt: time.Now().Add(time.Minute)},
+	})
+	d.lastReviewPollAt = time.Time{}
+	d.processConfirmationItems(context.Background())
+
+	if action.runs != 1 {
+		t.Errorf("action ran %d times, want 1", action.runs)
+	}
+}
+
+func TestConfirmation_TrackerIgnoresOutsider(t *testing.T) {
+	tests := []struct {
+		name      string
+		approvers []string
+		author    string
+	}{
+		{"not an approver", []string{"alice"}, "mallory"},
+		{"no approvers on a non-GitHub tracker", nil, "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, prov, action := confirmTestDaemon(t, false)
+			d.workflowConfigs["/test/repo"].Settings.Approvers = tt.approvers
+			d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+			prov.SetComments("ENG-1", []issues.IssueComment{
+				{Author: tt.author, Body: "/erg confirm", CreatedAt: time.Now().Add(time.Minute)},
+			})
+			d.lastReviewPollAt = time.Time{}
+			d.processConfirmationItems(context.Background())
+
+			if action.runs != 0 {
+				t.Errorf("action ran %d times on an unauthorized confirmation", action.runs)
+			}
+			item, _ := d.state.GetWorkItem("item-1")
+			if item.Phase != phaseAwaitingConfirmation {
+				t.Errorf("Phase = %q, want %q", item.Phase, phaseAwaitingConfirmation)
+			}
+		})
+	}
+}
+
+func TestConfirmation_GitHubRequiresWriteAccess(t *testing.T) {
+	tests := []struct {
+		permission string
+		wantAllow  bool
+	}{
+		{"write", true},
+		{"read", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor(nil)
+			mockExec.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/collaborators/bob/permission", "--jq", ".permission"}, exec.MockResponse{
+				Stdout: []byte(tt.permission + "\n"),
+			})
+			d := testDaemonWithExec(testConfig(), mockExec)
+			d.workflowConfigs["/test/repo"] = &workflow.Config{}
+
+			if got := d.canConfirm(context.Background(), "/test/repo", "github", "bob"); got != tt.wantAllow {
+				t.Errorf("canConfirm = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestConfirmation_UngatedActionRuns(t *testing.T) {
+	d, _, action := confirmTestDaemon(t, false)
+	d.workflowConfigs["/test/repo"].Settings = nil
+
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+	if action.runs != 1 {
+		t.Errorf("action ran %d times, want 1", action.runs)
+	}
+}
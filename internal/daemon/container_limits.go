@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// applyContainerResources sets the container limits and GPUs of the
+// workflow the item runs on on a session's runner. An item already retried after an OOM kill keeps the
+// raised memory limit for the rest of its run.
+func (d *Daemon) applyContainerResources(runner claude.RunnerConfig, sess *config.Session, item daemonstate.WorkItem) {
+	if !sess.Containerized {
+		return
+	}
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	resources := claude.ContainerResources{GPUs: wfCfg.Container.GPUDevices()}
+	if r := wfCfg.ContainerResources(); r != nil {
+		resources.CPUs, resources.Memory, resources.PidsLimit = r.CPUs, r.Memory, r.Pids
+	}
+	if resources == (claude.ContainerResources{}) {
+		return
+	}
+	if mem, ok := item.StepData["_container_memory"].(string); ok && mem != "" {
+		resources.Memory = mem
+	}
+	runner.SetContainerResources(resources)
+}
+
+// retryAfterOOM puts an item whose session container was OOM-killed back
+// into retry_pending with its workflow's oom_retry_memory limit, once. It
+// reports false when the item has had its retry or the workflow allows none,
+// leaving the failure to the state's retry and catch rules.
+func (d *Daemon) retryAfterOOM(item daemonstate.WorkItem, repoPath string, err error) bool {
+	if !errors.Is(err, claude.ErrContainerOOM) {
+		return false
+	}
+	if retried, _ := item.StepData["_oom_retried"].(bool); retried {
+		return false
+	}
+	r := d.getItemWorkflowConfig(repoPath, item).ContainerResources()
+	if r == nil || r.OOMRetryMemory == "" {
+		return false
+	}
+
+	d.logger.Warn("session container ran out of memory, retrying with a higher limit",
+		"workItem", item.ID, "step", item.CurrentStep, "memory", r.Memory, "retryMemory", r.OOMRetryMemory)
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = "retry_pending"
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_container_memory"] = r.OOMRetryMemory
+		it.StepData["_oom_retried"] = true
+		it.UpdatedAt = time.Now()
+	})
+	d.state.SetErrorMessage(item.ID, fmt.Sprintf("%v; retrying with %s", err, r.OOMRetryMemory))
+	return true
+}
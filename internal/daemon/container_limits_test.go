@@ -0,0 +1,166 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// oomTestDaemon returns a daemon whose /test/repo limits session memory and
+// catches container_oom at coding, with one OOM-killed worker for item-oom.
+func oomTestDaemon(t *testing.T, resources *workflow.ResourcesConfig, stepData map[string]any) *Daemon {
+	t.Helper()
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:    "coding",
+		Source:   workflow.SourceConfig{Provider: "github"},
+		Settings: &workflow.SettingsConfig{Resources: resources},
+		States: map[string]*workflow.State{
+			"coding": {
+				Type: workflow.StateTypeTask, Action: "ai.code", Next: "done", Error: "failed",
+				Catch: []workflow.CatchConfig{{Errors: []string{workflow.ErrorContainerOOM}, Next: "too_big"}},
+			},
+			"done":    {Type: workflow.StateTypeSucceed},
+			"failed":  {Type: workflow.StateTypeFail},
+			"too_big": {Type: workflow.StateTypeFail},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+
+	sess := testSession("sess-oom")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-oom",
+		IssueRef:    config.IssueRef{Source: "github", ID: "80"},
+		SessionID:   sess.ID,
+		CurrentStep: "coding",
+		StepData:    stepData,
+	})
+	d.state.AdvanceWorkItem("item-oom", "coding", "async_pending")
+	d.state.UpdateWorkItem("item-oom", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	oomErr := fmt.Errorf("claude error: %w", fmt.Errorf("%w (limit 4g)", claude.ErrContainerOOM))
+	d.workers["item-oom"] = worker.NewDoneWorkerWithError(oomErr)
+	return d
+}
+
+func TestApplyContainerResources(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{Settings: &workflow.SettingsConfig{
+		Resources: &workflow.ResourcesConfig{CPUs: "2", Memory: "4g", Pids: 256, OOMRetryMemory: "8g"},
+	}}
+	sess := testSession("sess-limits")
+
+	runner := claude.NewMockRunner(sess.ID, false, nil)
+	d.applyContainerResources(runner, sess, daemonstate.WorkItem{ID: "item-1"})
+	want := claude.ContainerResources{CPUs: "2", Memory: "4g", PidsLimit: 256}
+	if got := runner.GetContainerResources(); got != want {
+		t.Errorf("resources = %+v, want %+v", got, want)
+	}
+
+	// A retried item keeps its raised limit.
+	runner = claude.NewMockRunner(sess.ID, false, nil)
+	d.applyContainerResources(runner, sess, daemonstate.WorkItem{ID: "item-1", StepData: map[string]any{"_container_memory": "8g"}})
+	if got := runner.GetContainerResources().Memory; got != "8g" {
+		t.Errorf("memory = %q, want 8g", got)
+	}
+
+	// GPUs alone still reach the runner.
+	d.workflowConfigs["/test/repo"] = &workflow.Config{Container: &workflow.ContainerConfig{GPUs: "all"}}
+	runner = claude.NewMockRunner(sess.ID, false, nil)
+	d.applyContainerResources(runner, sess, daemonstate.WorkItem{ID: "item-1"})
+	if got := runner.GetContainerResources(); got != (claude.ContainerResources{GPUs: "all"}) {
+		t.Errorf("resources = %+v, want GPUs only", got)
+	}
+
+	// Host sessions run without a container to limit.
+	sess.Containerized = false
+	runner = claude.NewMockRunner(sess.ID, false, nil)
+	d.applyContainerResources(runner, sess, daemonstate.WorkItem{ID: "item-1"})
+	if got := runner.GetContainerResources(); got != (claude.ContainerResources{}) {
+		t.Errorf("expected no limits on a host session, got %+v", got)
+	}
+}
+
+func TestContainerResources_UseItemWorkflow(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{Settings: &workflow.SettingsConfig{
+		Resources: &workflow.ResourcesConfig{Memory: "4g"},
+	}}
+	addNamedWorkflow(t, d, "/test/repo", "gpu", &workflow.Config{
+		Start:     "coding",
+		Container: &workflow.ContainerConfig{GPUs: "all"},
+		Settings: &workflow.SettingsConfig{
+			Resources: &workflow.ResourcesConfig{Memory: "16g", OOMRetryMemory: "32g"},
+		},
+		States: map[string]*workflow.State{"coding": {Type: workflow.StateTypeSucceed}},
+	})
+	sess := testSession("sess-gpu")
+	item := daemonstate.WorkItem{ID: "item-gpu", Workflow: "gpu", StepData: map[string]any{}}
+
+	runner := claude.NewMockRunner(sess.ID, false, nil)
+	d.applyContainerResources(runner, sess, item)
+	want := claude.ContainerResources{GPUs: "all", Memory: "16g"}
+	if got := runner.GetContainerResources(); got != want {
+		t.Errorf("resources = %+v, want %+v", got, want)
+	}
+
+	// The OOM retry reads the named workflow's limit, not the repo's.
+	d.state.AddWorkItem(&item)
+	if !d.retryAfterOOM(item, sess.RepoPath, claude.ErrContainerOOM) {
+		t.Fatal("expected the named workflow's oom_retry_memory to allow a retry")
+	}
+	got, _ := d.state.GetWorkItem(item.ID)
+	if got.StepData["_container_memory"] != "32g" {
+		t.Errorf("_container_memory = %v, want 32g", got.StepData["_container_memory"])
+	}
+}
+
+func TestCollectCompletedWorkers_ContainerOOMRetriesOnce(t *testing.T) {
+	d := oomTestDaemon(t, &workflow.ResourcesConfig{Memory: "4g", OOMRetryMemory: "8g"}, map[string]any{})
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-oom")
+	if item.Phase != "retry_pending" || item.CurrentStep != "coding" {
+		t.Fatalf("expected coding retry_pending, got %s/%s", item.CurrentStep, item.Phase)
+	}
+	if item.StepData["_container_memory"] != "8g" || item.StepData["_oom_retried"] != true {
+		t.Errorf("expected the raised limit recorded, got %v", item.StepData)
+	}
+	if item.ErrorMessage == "" {
+		t.Error("expected error message to be set")
+	}
+}
+
+func TestCollectCompletedWorkers_ContainerOOMFails(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *workflow.ResourcesConfig
+		stepData  map[string]any
+	}{
+		{name: "no retry allowed", resources: &workflow.ResourcesConfig{Memory: "4g"}, stepData: map[string]any{}},
+		{name: "retry used up", resources: &workflow.ResourcesConfig{Memory: "4g", OOMRetryMemory: "8g"},
+			stepData: map[string]any{"_oom_retried": true, "_container_memory": "8g"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := oomTestDaemon(t, tt.resources, tt.stepData)
+
+			d.collectCompletedWorkers(context.Background())
+
+			item, _ := d.state.GetWorkItem("item-oom")
+			if item.CurrentStep != "too_big" {
+				t.Errorf("expected the container_oom catch to too_big, got %s/%s", item.CurrentStep, item.Phase)
+			}
+		})
+	}
+}
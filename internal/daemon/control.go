@@ -0,0 +1,408 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/dashboard"
+	"github.com/zhubert/erg/internal/issues"
+)
+
+// startControlSocket listens for `erg daemon` requests on the
+// daemon's control socket until ctx is cancelled. The daemon runs without
+// one if the socket cannot be created.
+func (d *Daemon) startControlSocket(ctx context.Context) {
+	if d.once {
+		return
+	}
+	srv, err := control.Listen(control.SocketPath(d.stateKey()), d.handleControl)
+	if err != nil {
+		d.logger.Warn("failed to start control socket, pause and drain are unavailable", "error", err)
+		return
+	}
+	srv.HandleStreams(d.handleControlStream)
+	go srv.Serve(ctx)
+	d.logger.Info("control socket started", "path", srv.Path())
+}
+
+// handleControl carries out a control socket request. It runs on the
+// socket's goroutine, so requests that change what the daemon works on
+// (mode changes, queue, cancel) are only recorded here and carried out by
+// the main loop, which they wake.
+func (d *Daemon) handleControl(req control.Request) (control.Response, error) {
+	var mode daemonstate.RunMode
+	switch req.Command {
+	case control.CommandPause:
+		mode = daemonstate.ModePaused
+	case control.CommandDrain:
+		mode = daemonstate.ModeDraining
+	case control.CommandResume:
+		mode = daemonstate.ModeRunning
+	case control.CommandStatus:
+		return d.controlStatus(), nil
+	case control.CommandList:
+		resp := d.controlStatus()
+		resp.Items = d.controlItems()
+		return resp, nil
+	case control.CommandQueue:
+		return d.controlQueue(req)
+	case control.CommandCancel:
+		return d.controlCancel(req)
+	case control.CommandRetry:
+		return d.controlRetry(req)
+	case control.CommandSpend:
+		resp := d.controlStatus()
+		resp.Spend = d.controlSpend()
+		return resp, nil
+	case control.CommandLogs:
+		return d.controlLogs(req)
+	case control.CommandDLQRetry:
+		return d.controlDeadLetterRetry(req)
+	case control.CommandDLQDiscard:
+		return d.controlDeadLetterDiscard(req)
+	default:
+		return control.Response{}, fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	if prev, _ := d.state.GetRunMode(); prev != mode {
+		d.state.SetRunMode(mode)
+		d.logger.Info("run mode changed by operator", "event", "daemon."+string(req.Command),
+			"mode", mode.String(), "previous", prev.String())
+	}
+	d.wakeForControl()
+	return d.controlStatus(), nil
+}
+
+// wakeForControl wakes the main loop to act on a control request.
+func (d *Daemon) wakeForControl() {
+	select {
+	case d.controlEvents <- struct{}{}:
+	default:
+	}
+}
+
+// controlStatus reports the daemon's mode and outstanding work.
+func (d *Daemon) controlStatus() control.Response {
+	mode, _ := d.state.GetRunMode()
+	d.mu.Lock()
+	sessions := len(d.workers)
+	d.mu.Unlock()
+	return control.Response{
+		Mode:     mode.String(),
+		Sessions: sessions,
+		Queued:   len(d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)),
+	}
+}
+
+// acceptingWork reports whether the daemon may poll for and start new work.
+func (d *Daemon) acceptingWork() bool {
+	mode, _ := d.state.GetRunMode()
+	return mode == daemonstate.ModeRunning
+}
+
+// drained reports whether a draining daemon has finished: no sessions are
+// running and none are waiting to resume after preemption. Items waiting
+// on CI or review stay in the saved state for the next start.
+func (d *Daemon) drained() bool {
+	if mode, _ := d.state.GetRunMode(); mode != daemonstate.ModeDraining {
+		return false
+	}
+	d.mu.Lock()
+	running := len(d.workers)
+	d.mu.Unlock()
+	if running > 0 {
+		return false
+	}
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase == phasePreempted {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultControlLogLines is how many log lines CommandLogs returns when the
+// request does not say.
+const defaultControlLogLines = 50
+
+// controlItems reports every work item, ordered by ID.
+func (d *Daemon) controlItems() []control.Item {
+	all := d.state.GetAllWorkItems()
+	items := make([]control.Item, 0, len(all))
+	for _, it := range all {
+		items = append(items, d.controlItem(it))
+	}
+	slices.SortFunc(items, func(a, b control.Item) int { return strings.Compare(a.ID, b.ID) })
+	return items
+}
+
+// controlItem reports a work item as the control socket shows it.
+func (d *Daemon) controlItem(it daemonstate.WorkItem) control.Item {
+	return control.Item{
+		ID:        it.ID,
+		Source:    it.IssueRef.Source,
+		Issue:     it.IssueRef.ID,
+		Title:     it.IssueRef.Title,
+		Repo:      d.workItemRepoPath(it),
+		State:     string(it.State),
+		Step:      it.CurrentStep,
+		Phase:     it.Phase,
+		PRURL:     it.PRURL,
+		Session:   it.SessionID,
+		CostUSD:   it.CostUSD,
+		UpdatedAt: it.UpdatedAt,
+	}
+}
+
+// controlSpend reports the daemon's running spend totals and what its
+// sessions spent today and this week.
+func (d *Daemon) controlSpend() *control.Spend {
+	total, out, in := d.state.GetSpend()
+	now := time.Now()
+	today, _ := d.state.SpendSince("", daemonstate.SpendDay(now))
+	week, _ := d.state.SpendSince("", daemonstate.SpendWeekStart(now))
+	return &control.Spend{TotalUSD: total, InputTokens: in, OutputTokens: out, TodayUSD: today, WeekUSD: week}
+}
+
+// controlRepo resolves a request's repo, given as a path or owner/repo
+// label, to one of the daemon's repos. It may be omitted when the daemon
+// manages a single repo.
+func (d *Daemon) controlRepo(ref string) (string, error) {
+	repos := d.config.GetRepos()
+	if ref == "" {
+		if len(repos) == 1 {
+			return repos[0], nil
+		}
+		return "", fmt.Errorf("the orchestrator manages %d repos; say which one", len(repos))
+	}
+	_, labels := d.state.GetRepoLabels()
+	for _, repo := range repos {
+		if repo == ref || labels[repo] == ref {
+			return repo, nil
+		}
+	}
+	return "", fmt.Errorf("the orchestrator does not manage repo %q", ref)
+}
+
+// findControlItem resolves a work item named by its ID or by its issue's
+// tracker ID. An issue with several items resolves to its one in-flight
+// item, or else to the one updated last.
+func (d *Daemon) findControlItem(ref string) (daemonstate.WorkItem, error) {
+	if ref == "" {
+		return daemonstate.WorkItem{}, fmt.Errorf("no work item given")
+	}
+	if item, ok := d.state.GetWorkItem(ref); ok {
+		return item, nil
+	}
+	issueID := strings.TrimPrefix(ref, "#")
+	var inFlight, finished []daemonstate.WorkItem
+	for _, item := range d.state.GetAllWorkItems() {
+		if item.IssueRef.ID != issueID {
+			continue
+		}
+		if item.IsTerminal() {
+			finished = append(finished, item)
+		} else {
+			inFlight = append(inFlight, item)
+		}
+	}
+	switch {
+	case len(inFlight) == 1:
+		return inFlight[0], nil
+	case len(inFlight) > 1:
+		ids := make([]string, len(inFlight))
+		for i, item := range inFlight {
+			ids[i] = item.ID
+		}
+		slices.Sort(ids)
+		return daemonstate.WorkItem{}, fmt.Errorf("issue %s has several work items, name one of: %s", issueID, strings.Join(ids, ", "))
+	case len(finished) > 0:
+		return slices.MaxFunc(finished, func(a, b daemonstate.WorkItem) int { return a.UpdatedAt.Compare(b.UpdatedAt) }), nil
+	}
+	return daemonstate.WorkItem{}, fmt.Errorf("no work item for %q", ref)
+}
+
+// controlQueue checks a queue request and leaves it for the main loop,
+// which fetches the issue and queues it.
+func (d *Daemon) controlQueue(req control.Request) (control.Response, error) {
+	if req.Issue == "" {
+		return control.Response{}, fmt.Errorf("no issue given")
+	}
+	repo, err := d.controlRepo(req.Repo)
+	if err != nil {
+		return control.Response{}, err
+	}
+	source := issues.Source(d.getWorkflowConfig(repo).Source.Provider)
+	if _, ok := d.issueRegistry.GetProvider(source).(issues.IssueGetter); !ok {
+		return control.Response{}, fmt.Errorf("provider %q does not support queuing a single issue", source)
+	}
+	if d.state.HasWorkItemForIssue(string(source), req.Issue) {
+		return control.Response{}, fmt.Errorf("issue %s is already tracked; retry it instead", req.Issue)
+	}
+
+	d.mu.Lock()
+	d.controlRequests = append(d.controlRequests, control.Request{Command: control.CommandQueue, Issue: req.Issue, Repo: repo})
+	d.mu.Unlock()
+	d.wakeForControl()
+	resp := d.controlStatus()
+	resp.Message = fmt.Sprintf("issue %s of %s will be queued on the next tick", req.Issue, repo)
+	return resp, nil
+}
+
+// controlCancel checks a cancel request and leaves it for the main loop.
+func (d *Daemon) controlCancel(req control.Request) (control.Response, error) {
+	item, err := d.findControlItem(req.Item)
+	if err != nil {
+		return control.Response{}, err
+	}
+	if item.IsTerminal() {
+		return control.Response{}, fmt.Errorf("work item %s already %s", item.ID, item.State)
+	}
+
+	d.mu.Lock()
+	d.controlRequests = append(d.controlRequests, control.Request{Command: control.CommandCancel, Item: item.ID})
+	d.mu.Unlock()
+	d.wakeForControl()
+	resp := d.controlStatus()
+	resp.Message = fmt.Sprintf("work item %s will be cancelled on the next tick", item.ID)
+	return resp, nil
+}
+
+// controlRetry queues a finished or quarantined work item again.
+func (d *Daemon) controlRetry(req control.Request) (control.Response, error) {
+	item, err := d.findControlItem(req.Item)
+	if err != nil {
+		return control.Response{}, err
+	}
+	if err := d.RetryWorkItem(item.ID); err != nil {
+		return control.Response{}, err
+	}
+	d.wakeForControl()
+	resp := d.controlStatus()
+	resp.Message = fmt.Sprintf("work item %s queued again", item.ID)
+	return resp, nil
+}
+
+// controlLogs returns the end of a work item's session log, formatted as
+// `erg status --tail` shows it.
+func (d *Daemon) controlLogs(req control.Request) (control.Response, error) {
+	item, err := d.findControlItem(req.Item)
+	if err != nil {
+		return control.Response{}, err
+	}
+	if item.SessionID == "" {
+		return control.Response{}, fmt.Errorf("work item %s has no session", item.ID)
+	}
+	n := req.Lines
+	if n <= 0 {
+		n = defaultControlLogLines
+	}
+	lines, err := dashboard.ReadSessionLog(item.SessionID, n)
+	if err != nil {
+		return control.Response{}, fmt.Errorf("failed to read the session log of %s: %w", item.ID, err)
+	}
+	resp := d.controlStatus()
+	for _, ln := range lines {
+		switch {
+		case ln.Type != "tool":
+			resp.Log = append(resp.Log, ln.Text)
+		case ln.Text != "":
+			resp.Log = append(resp.Log, fmt.Sprintf("[%s: %s]", claude.FormatToolIcon(ln.Name), ln.Text))
+		default:
+			resp.Log = append(resp.Log, fmt.Sprintf("[%s]", claude.FormatToolIcon(ln.Name)))
+		}
+	}
+	return resp, nil
+}
+
+// processControlRequests carries out the queue and cancel requests received
+// on the control socket since the last tick.
+func (d *Daemon) processControlRequests(ctx context.Context) {
+	d.mu.Lock()
+	reqs := d.controlRequests
+	d.controlRequests = nil
+	d.mu.Unlock()
+
+	for _, req := range reqs {
+		switch req.Command {
+		case control.CommandQueue:
+			d.queueRequestedIssue(ctx, req.Repo, req.Issue)
+		case control.CommandCancel:
+			d.cancelWorkItem(ctx, req.Item)
+		}
+	}
+}
+
+// queueRequestedIssue fetches an issue an operator asked for and queues it
+// like a polled issue. Readiness checks are skipped, as for `erg run`.
+func (d *Daemon) queueRequestedIssue(ctx context.Context, repoPath, issueID string) {
+	log := d.logger.With("repo", repoPath, "issue", issueID)
+	source := issues.Source(d.getWorkflowConfig(repoPath).Source.Provider)
+	getter, ok := d.issueRegistry.GetProvider(source).(issues.IssueGetter)
+	if !ok {
+		return
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer cancel()
+	issue, err := getter.GetIssue(fetchCtx, repoPath, issueID)
+	if err != nil {
+		log.Warn("failed to fetch issue queued by operator", "error", err)
+		return
+	}
+	if !d.admitPolledIssue(fetchCtx, repoPath, polledIssue{issue: *issue, provider: source}, true) {
+		log.Warn("issue queued by operator was not admitted: already tracked, claimed elsewhere, or addressed by a PR")
+		return
+	}
+	log.Info("issue queued by operator", "event", "human.queue")
+}
+
+// cancelledErrorMessage is the error message of an item an operator
+// cancelled.
+const cancelledErrorMessage = "cancelled by operator"
+
+// cancelWorkItem stops work on an item for good at an operator's request.
+func (d *Daemon) cancelWorkItem(ctx context.Context, itemID string) {
+	item, ok := d.state.GetWorkItem(itemID)
+	if !ok || item.IsTerminal() {
+		return
+	}
+	d.abandonWorkItem(ctx, item, "Cancelled by an operator.", "cancelled", cancelledErrorMessage)
+	d.logger.Info("work item cancelled by human", "event", "human.cancel",
+		"workItem", itemID, "repo", d.workItemRepoPath(item))
+	d.audit(itemID, daemonstate.AuditOperator, "cancelled by an operator", nil)
+}
+
+// abandonWorkItem stops work on an item for good: its session is stopped,
+// the issue is told reason and marked with suffix so it is not picked up
+// again, and the item fails with errMsg.
+func (d *Daemon) abandonWorkItem(ctx context.Context, item daemonstate.WorkItem, reason, suffix, errMsg string) {
+	d.stopWorker(item.ID)
+	d.unqueueIssueWithSuffix(ctx, item, reason, suffix)
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_unqueued_posted"] = true
+	})
+	d.state.MarkWorkItemTerminal(item.ID, false)
+	d.state.SetErrorMessage(item.ID, errMsg)
+}
+
+// stopWorker cancels and forgets the item's running worker, if any.
+func (d *Daemon) stopWorker(itemID string) {
+	d.mu.Lock()
+	w, running := d.workers[itemID]
+	if running {
+		delete(d.workers, itemID)
+	}
+	d.mu.Unlock()
+	if running {
+		w.Cancel()
+	}
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+func TestHandleControl_PauseAndResume(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "1"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+
+	resp, err := d.handleControl(control.Request{Command: control.CommandPause})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Mode != "paused" || resp.Queued != 1 {
+		t.Errorf("response = %+v", resp)
+	}
+	select {
+	case <-d.controlEvents:
+	default:
+		t.Error("expected the main loop to be woken")
+	}
+
+	d.tick(t.Context())
+	if item, _ := d.state.GetWorkItem("item-1"); item.State != daemonstate.WorkItemQueued {
+		t.Errorf("paused daemon started queued work: state %q", item.State)
+	}
+
+	if resp, _ := d.handleControl(control.Request{Command: control.CommandResume}); resp.Mode != "running" {
+		t.Errorf("mode after resume = %q", resp.Mode)
+	}
+	if !d.acceptingWork() {
+		t.Error("expected resumed daemon to accept work")
+	}
+	if _, err := d.handleControl(control.Request{Command: "reboot"}); err == nil {
+		t.Error("expected unknown command to be rejected")
+	}
+}
+
+func TestDrained(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workers["item-1"] = worker.NewDoneWorker()
+	if d.drained() {
+		t.Error("a running daemon is never drained")
+	}
+
+	resp, _ := d.handleControl(control.Request{Command: control.CommandDrain})
+	if resp.Mode != "draining" || resp.Sessions != 1 {
+		t.Errorf("response = %+v", resp)
+	}
+	if d.drained() {
+		t.Error("expected drain to wait for the running session")
+	}
+
+	delete(d.workers, "item-1")
+	if !d.drained() {
+		t.Error("expected drain to finish with no sessions running")
+	}
+}
+
+func TestHandleControl_ListAndFindItems(t *testing.T) {
+	d, _, _ := confirmTestDaemon(t, false)
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-0", IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1"}})
+	d.state.MarkWorkItemTerminal("item-0", false)
+
+	resp, err := d.handleControl(control.Request{Command: control.CommandList})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].ID != "item-1" || resp.Items[1].Repo != "/test/repo" {
+		t.Errorf("items = %+v", resp.Items)
+	}
+
+	// An issue resolves to its in-flight item over a finished one.
+	for _, ref := range []string{"item-1", "ENG-1", "#ENG-1"} {
+		if item, err := d.findControlItem(ref); err != nil || item.ID != "item-1" {
+			t.Errorf("findControlItem(%q) = %q, %v", ref, item.ID, err)
+		}
+	}
+	if _, err := d.findControlItem("ENG-404"); err == nil {
+		t.Error("expected an unknown issue to be rejected")
+	}
+}
+
+func TestHandleControl_QueueIssue(t *testing.T) {
+	d, prov, _ := confirmTestDaemon(t, false)
+	d.config.(*config.Config).Repos = []string{"/test/repo"}
+	prov.AddIssue(issues.Issue{ID: "ENG-2", Title: "Add dark mode", Source: issues.SourceLinear})
+
+	if _, err := d.handleControl(control.Request{Command: control.CommandQueue, Issue: "ENG-1"}); err == nil {
+		t.Error("expected an already tracked issue to be rejected")
+	}
+	if _, err := d.handleControl(control.Request{Command: control.CommandQueue, Issue: "ENG-2", Repo: "/elsewhere"}); err == nil {
+		t.Error("expected an unmanaged repo to be rejected")
+	}
+
+	resp, err := d.handleControl(control.Request{Command: control.CommandQueue, Issue: "ENG-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Message, "ENG-2") {
+		t.Errorf("message = %q", resp.Message)
+	}
+	if d.state.HasWorkItemForIssue("linear", "ENG-2") {
+		t.Fatal("queue must be left to the main loop")
+	}
+
+	d.processControlRequests(context.Background())
+	if !d.state.HasWorkItemForIssue("linear", "ENG-2") {
+		t.Error("expected the issue to be queued")
+	}
+}
+
+func TestHandleControl_CancelAndRetry(t *testing.T) {
+	d, _, _ := confirmTestDaemon(t, false)
+	d.workers["item-1"] = worker.NewDoneWorker()
+
+	if _, err := d.handleControl(control.Request{Command: control.CommandRetry, Item: "item-1"}); err == nil {
+		t.Error("expected retrying an in-flight item to be rejected")
+	}
+	if _, err := d.handleControl(control.Request{Command: control.CommandCancel, Item: "ENG-1"}); err != nil {
+		t.Fatal(err)
+	}
+	d.processControlRequests(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemFailed || item.ErrorMessage != "cancelled by operator" {
+		t.Errorf("item = %q (%q), want failed by the operator", item.State, item.ErrorMessage)
+	}
+	if _, running := d.workers["item-1"]; running {
+		t.Error("expected the worker to be removed")
+	}
+	if _, err := d.handleControl(control.Request{Command: control.CommandCancel, Item: "item-1"}); err == nil {
+		t.Error("expected cancelling a finished item to be rejected")
+	}
+
+	if _, err := d.handleControl(control.Request{Command: control.CommandRetry, Item: "item-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := d.state.GetWorkItem("item-1"); item.State != daemonstate.WorkItemQueued {
+		t.Errorf("State after retry = %q, want queued", item.State)
+	}
+}
+
+func TestHandleControl_Spend(t *testing.T) {
+	d, _, _ := confirmTestDaemon(t, false)
+	d.state.AddSpend(2.5, 100, 300)
+	d.RecordItemSpend("sess-1", 2.5, 100, 300)
+
+	resp, err := d.handleControl(control.Request{Command: control.CommandSpend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resp.Spend; s == nil || s.TotalUSD != 2.5 || s.TodayUSD != 2.5 || s.WeekUSD != 2.5 || s.InputTokens != 300 {
+		t.Errorf("spend = %+v", resp.Spend)
+	}
+}
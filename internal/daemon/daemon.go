@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"github.com/zhubert/erg/internal/agentbackend"
 	"github.com/zhubert/erg/internal/agentconfig"
 	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/control"
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/dashboard"
+	"github.com/zhubert/erg/internal/eventbus"
+	"github.com/zhubert/erg/internal/ghapp"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
 	"github.com/zhubert/erg/internal/manager"
@@ -41,9 +47,26 @@ type Daemon struct {
 	workflowConfigs map[string]*workflow.Config // keyed by repo path
 	engines         map[string]*workflow.Engine // keyed by repo path
 	mu              sync.Mutex
-	workerDone      chan struct{} // buffered(1); workers signal when done to wake the main loop
+	workerDone      chan struct{}     // buffered(1); workers signal when done to wake the main loop
+	issueEvents     chan struct{}     // buffered(1); webhook deliveries wake the main loop to poll
+	controlEvents   chan struct{}     // buffered(1); control socket commands wake the main loop
+	controlRequests []control.Request // queue and cancel requests for the main loop; guarded by mu
+	events          *eventbus.Bus     // delivers state transition events to settings.events sinks
 	logger          *slog.Logger
 
+	// Workflow versioning: workflowVersions is the hash of each repo's loaded
+	// config, pinnedEngines the engines of earlier versions that in-flight
+	// items are still running on, and workflowReloadErrors the last reload
+	// failure logged per repo. namedWorkflows holds each repo's
+	// label-selected workflows, keyed by name. workflowMu guards these and
+	// the config and engine maps once the daemon is running, since edits are
+	// reloaded.
+	workflowMu           sync.RWMutex
+	workflowVersions     map[string]string
+	pinnedEngines        map[string]*workflow.Engine // keyed by workflow version
+	workflowReloadErrors map[string]string
+	namedWorkflows       map[string]map[string]namedWorkflow // keyed by repo path, then name
+
 	// Config save tracking
 	configSaveFailures int
 	configSavePaused   bool // true after 5+ consecutive failures; blocks new work
@@ -61,6 +84,7 @@ type Daemon struct {
 	reviewPollInterval    time.Duration
 	lastReviewPollAt      time.Time
 	lastReconcileAt       time.Time
+	lastAuthRotationAt    time.Time
 
 	// preseededIssue is an issue to inject on the first poll tick (for erg run).
 	preseededIssue *issues.Issue
@@ -69,24 +93,120 @@ type Daemon struct {
 	// server with itself as the SessionController so that control buttons work.
 	dashboardAddr string
 
+	// webhookAddr, when set, starts a listener for issue-tracker webhooks so
+	// new issues are polled for immediately (see webhook.go).
+	webhookAddr string
+
 	// Docker health tracking
 	dockerDown        bool
 	dockerDownLogged  bool
 	dockerHealthCheck func(context.Context) error // injectable for testing; nil means use default
 
+	// claudeDownUntil pauses coding sessions until it passes, after a
+	// session failed because the Claude API was unavailable.
+	claudeDownUntil time.Time
+
+	// readContainerUsage reads a running container's cumulative resource
+	// usage; injectable for testing, nil means container.ReadUsage.
+	readContainerUsage func(ctx context.Context, name string) (container.Usage, error)
+
+	// ensureServices and stopServices start and remove a session's sidecar
+	// services; injectable for testing, nil means container.EnsureServices
+	// and container.StopServices.
+	ensureServices func(ctx context.Context, sessionID string, services []container.Service) (string, error)
+	stopServices   func(ctx context.Context, sessionID string) error
+
+	// runPreflight checks a session's container before the agent starts;
+	// injectable for testing, nil means container.RunPreflight.
+	runPreflight func(ctx context.Context, p container.Preflight) error
+
+	// sessionBackends records the agent backend each session's runner is
+	// on, when not the Claude CLI, and newBackendRunner creates runners on
+	// them; injectable for testing, nil means agentbackend.NewRunner.
+	sessionBackends  map[string]string
+	newBackendRunner func(cfg agentbackend.Config, sessionID, workDir, repoPath string, history []claude.Message) (claude.RunnerInterface, error)
+
+	// leftoverContainer reports whether a container from before a restart
+	// exists, removing it when remove is set; injectable for testing, nil
+	// means docker is asked.
+	leftoverContainer func(ctx context.Context, name string, remove bool) (bool, error)
+
+	// globalBudget caps what all repos spend together (nil when unset), and
+	// budgetAlerts records the exceeded limit last logged per budget scope.
+	globalBudget *workflow.BudgetConfig
+	budgetAlerts map[string]string
+
+	// globalLimits caps sessions per toolchain across all repos, toolchains
+	// caches each repo's detected toolchains, and detectToolchains detects
+	// them (injectable for testing; nil means container.Detect).
+	globalLimits     workflow.LimitsConfig
+	toolchains       map[string][]string
+	detectToolchains func(ctx context.Context, repoPath string) []string
+
+	// epicSummaries records the last summary posted per epic, for throttling.
+	epicSummaries map[epicKey]epicSummaryRecord
+
+	// subtaskParentsReported records expanded parents whose completion has
+	// been reported, so the comment is posted once.
+	subtaskParentsReported map[epicKey]bool
+
+	// readinessNotified records the last not-ready comment posted per issue
+	// (keyed by source/ID), so unchanged comments are not re-posted each poll.
+	readinessNotified map[string]string
+
+	// stuckSummaryAt records when each repo's stuck items summary was last
+	// reported, so it goes out once a day.
+	stuckSummaryAt map[string]time.Time
+
+	// leaseDir, when set, holds the lease instances of this daemon share
+	// leadership through; lease is that lease once Run starts, leaseTTL
+	// how long it lasts past each renewal, and leasePeers the hosts of
+	// leaders this instance stood by for.
+	leaseDir   string
+	leaseTTL   time.Duration
+	lease      *daemonstate.LeaseFile
+	leasePeers []string
+
 	// Cron scheduler for schedule triggers
 	scheduler *cron.Cron
 
+	// tokenMinter, when set, mints a repo-scoped GitHub token for each
+	// containerized session so the container never sees broader credentials.
+	// scopedTokens records, per session, the token its runner holds so it
+	// can be re-minted before it expires.
+	tokenMinter  RepoTokenMinter
+	scopedTokens map[string]scopedToken
+
+	// scopedImageBuilder, when set, builds monorepo session images with only
+	// the toolchains of the components a work item touches.
+	scopedImageBuilder ScopedImageBuilder
+
+	// workspaceSyncer, when set, copies containerized sessions' worktrees to
+	// and from the remote host their containers run on.
+	workspaceSyncer WorkspaceSyncer
+
+	// offline, when set, fails steps that need the network as soon as they
+	// are invoked instead of trying them, for air-gapped machines.
+	offline bool
+
 	// Workflow
 	workflowFile        string            // optional explicit workflow config file path
 	repoWorkflowFiles   map[string]string // per-repo workflow file overrides (repo path → file path)
 	repoContainerImages map[string]string // per-repo auto-built container images (repo path → image tag)
+	repoMaxConcurrent   map[string]int    // per-repo concurrency limits from the manifest (repo path → limit)
+	pollRotation        int               // which repo gets the first turn at the next poll
 	daemonID            string            // stable ID for lock/state keying in multi-repo mode
 }
 
 // Option configures the daemon.
 type Option func(*Daemon)
 
+// RepoTokenMinter mints short-lived GitHub tokens restricted to one repository.
+// Implemented by *ghapp.Minter.
+type RepoTokenMinter interface {
+	MintRepoToken(ctx context.Context, ownerRepo string) (*ghapp.Token, error)
+}
+
 // WithOnce configures the daemon to run one tick and exit.
 func WithOnce(once bool) Option {
 	return func(d *Daemon) { d.once = once }
@@ -137,6 +257,13 @@ func WithRepoWorkflowFiles(files map[string]string) Option {
 	return func(d *Daemon) { d.repoWorkflowFiles = files }
 }
 
+// WithRepoMaxConcurrent sets per-repo concurrency limits for multi-repo
+// mode. Each repo runs at most its limit of sessions, within the global
+// max concurrent.
+func WithRepoMaxConcurrent(limits map[string]int) Option {
+	return func(d *Daemon) { d.repoMaxConcurrent = limits }
+}
+
 // WithDaemonID sets a stable identifier for lock and state files.
 // This is used in multi-repo mode where repoFilter may be empty.
 // WithRepoContainerImages sets per-repo container image overrides.
@@ -156,6 +283,12 @@ func WithDashboard(addr string) Option {
 	return func(d *Daemon) { d.dashboardAddr = addr }
 }
 
+// WithRepoTokenMinter enables per-session GitHub token minting. Each
+// containerized session receives GH_TOKEN/GITHUB_TOKEN scoped to its own repo.
+func WithRepoTokenMinter(m RepoTokenMinter) Option {
+	return func(d *Daemon) { d.tokenMinter = m }
+}
+
 // New creates a new daemon.
 func New(cfg agentconfig.Config, gitSvc *git.GitService, sessSvc *session.SessionService, registry *issues.ProviderRegistry, logger *slog.Logger, opts ...Option) *Daemon {
 	d := &Daemon{
@@ -166,6 +299,9 @@ func New(cfg agentconfig.Config, gitSvc *git.GitService, sessSvc *session.Sessio
 		issueRegistry:      registry,
 		workers:            make(map[string]*worker.SessionWorker),
 		workerDone:         make(chan struct{}, 1),
+		issueEvents:        make(chan struct{}, 1),
+		controlEvents:      make(chan struct{}, 1),
+		events:             eventbus.New(logger),
 		logger:             logger,
 		autoMerge:          true, // Auto-merge is default for daemon
 		pollInterval:       defaultPollInterval,
@@ -202,6 +338,19 @@ func (d *Daemon) Run(ctx context.Context) error {
 	}
 	defer d.releaseLock()
 
+	// With a lease, stand by until this instance leads, and stop if it
+	// stops leading.
+	if d.leaseDir != "" {
+		if err := d.awaitLeadership(ctx); err != nil {
+			return err
+		}
+		leaderCtx, stop := context.WithCancelCause(ctx)
+		defer stop(nil)
+		ctx = leaderCtx
+		go d.keepLeadership(ctx, stop)
+		defer d.releaseLease()
+	}
+
 	// Clean up stale files from previous runs that were killed ungracefully
 	if n, err := claude.ClearAuthFiles(); err != nil {
 		d.logger.Warn("failed to clean stale auth files", "error", err)
@@ -222,9 +371,16 @@ func (d *Daemon) Run(ctx context.Context) error {
 		state = daemonstate.NewDaemonState(key)
 	}
 	d.state = state
-
-	// Reset spend tracking so it reflects only the current daemon run.
-	d.state.ResetSpend()
+	d.adoptPeerClaims()
+	if mode, _ := d.state.GetRunMode(); mode == daemonstate.ModeDraining {
+		// The drain finished by exiting; starting again means running.
+		d.state.SetRunMode(daemonstate.ModeRunning)
+	} else if mode == daemonstate.ModePaused {
+		d.logger.Warn("daemon is paused, not picking up new work until `erg daemon resume`")
+	}
+	d.watchTransitions()
+	d.events.Start()
+	defer d.events.Close()
 
 	// Resolve human-readable owner/repo labels from git remote URLs and persist them
 	// so the dashboard can display "zhubert/erg" instead of raw filesystem paths or
@@ -232,12 +388,12 @@ func (d *Daemon) Run(ctx context.Context) error {
 	d.resolveAndSaveRepoLabels(ctx)
 
 	if err := d.state.Save(); err != nil {
-		d.logger.Warn("failed to save state after resetting spend", "error", err)
+		d.logger.Warn("failed to save state after resolving repo labels", "error", err)
 	}
 
 	// Start embedded dashboard server if configured.
 	if d.dashboardAddr != "" {
-		dashSrv := dashboard.New(d.dashboardAddr, dashboard.WithController(d))
+		dashSrv := dashboard.New(d.dashboardAddr, dashboard.WithController(d), dashboard.AccessTokensFromEnv())
 		dashErrCh := make(chan error, 1)
 		go func() {
 			if err := dashSrv.Run(ctx); err != nil {
@@ -260,13 +416,33 @@ func (d *Daemon) Run(ctx context.Context) error {
 		}
 	}
 
+	// Start the webhook listener if configured. Polling continues regardless.
+	d.startWebhookListener(ctx)
+
+	// Listen for pause, drain, and resume from `erg daemon`.
+	d.startControlSocket(ctx)
+
 	// Load workflow configs for all repos
 	d.loadWorkflowConfigs()
+	if d.offline {
+		d.warnOfflineWorkflows()
+	}
 
 	// Start cron scheduler for schedule triggers (no-op in --once mode).
 	d.startScheduler(ctx)
 	defer d.stopScheduler()
 
+	// Sample container CPU, memory, and disk I/O for running sessions.
+	go d.runResourceSampler(ctx)
+
+	// Reconcile the saved state with what survived the last run: merged
+	// PRs, dead sessions, and orphaned worktrees and branches.
+	if report, err := d.Recover(ctx, false); err != nil {
+		d.logger.Warn("startup recovery failed", "error", err)
+	} else {
+		d.logRecoveryReport(report)
+	}
+
 	// Rebuild state from the issue tracker. This scans for active issues,
 	// queries the tracker for their actual progress (PR state, CI, review),
 	// and places each work item at the correct workflow step.
@@ -292,11 +468,20 @@ func (d *Daemon) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			d.logger.Info("context cancelled, shutting down daemon")
 			d.shutdown()
-			return ctx.Err()
+			return context.Cause(ctx)
 		case <-ticker.C:
 			d.tick(ctx)
 		case <-d.workerDone:
 			d.tick(ctx)
+		case <-d.issueEvents:
+			d.tick(ctx)
+		case <-d.controlEvents:
+			d.tick(ctx)
+		}
+		if d.drained() {
+			d.logger.Info("drain complete, daemon exiting")
+			d.shutdown()
+			return nil
 		}
 	}
 }
@@ -314,14 +499,40 @@ func (d *Daemon) notifyWorkerDone() {
 func (d *Daemon) tick(ctx context.Context) {
 	d.collectCompletedWorkers(ctx) // Always: detect finished sessions
 	d.retryConfigSave()            // Always: attempt recovery if config saves are paused
-	dockerOK := d.checkDockerHealth(ctx)
-	if dockerOK {
-		d.processRetryItems(ctx)     // Re-execute items whose retry delay has elapsed
-		d.processIdleSyncItems(ctx)  // Execute items idle on sync task steps (e.g. after recovery)
-		d.processWorkItems(ctx)      // Process active items via engine
-		d.reconcileClosedIssues(ctx) // Cancel work items whose issues were closed externally
-		d.pollForNewIssues(ctx)      // Find new issues (if slots available)
-		d.startQueuedItems(ctx)      // Start coding on queued items
+	d.reloadWorkflowConfigs(ctx)   // Always: pick up workflow edits and migrate in-flight items
+	d.processOutbox(ctx)           // Always: replay tracker updates buffered while offline
+	d.checkBudgets()               // Always: log spend budgets exceeded or cleared
+	d.rotateAuthFiles()            // Always: remove container auth files past their TTL
+	d.refreshScopedTokens(ctx)     // Always: re-mint scoped GitHub tokens nearing expiry
+	d.processCompensations(ctx)    // Always: undo what failed items left behind
+	d.processDeadLetters(ctx)      // Always: park issues that keep failing
+	tier := d.updateDegradation(d.checkDockerHealth(ctx))
+	if tier != daemonstate.TierContainerDown {
+		d.processQuarantinedItems(ctx) // Release quarantined items whose cooldown has elapsed
+		if tier != daemonstate.TierClaudeDown {
+			d.processRetryItems(ctx) // Re-execute items whose retry delay has elapsed
+		}
+		d.processConfirmationItems(ctx) // Run or reject destructive actions humans have decided on
+		d.processResumeRequests()       // Restart stalled items humans have resumed
+		d.processControlRequests(ctx)   // Queue issues and cancel items operators asked for
+		d.processMutexWaitItems(ctx)    // Enter mutex states whose group has been released
+		d.processBudgetHoldItems(ctx)   // Enter AI steps held while a spend budget was exceeded
+		d.processIdleSyncItems(ctx)     // Execute items idle on sync task steps (e.g. after recovery)
+		d.processWorkItems(ctx)         // Process active items via engine (CI, reviews)
+		d.postEpicSummaries(ctx)        // Refresh progress summaries on epics
+		d.reportSubtaskParents(ctx)     // Comment on parents whose sub-tasks have all merged
+		d.reconcileClosedIssues(ctx)    // Cancel work items whose issues were closed externally
+		d.processStuckItems(ctx)        // Ping, rebase, requeue or abandon items stuck at a state
+	}
+	if d.acceptingWork() {
+		d.pollForNewIssues(ctx) // Find new issues (if slots available); queueing continues in every tier
+	}
+	if tier != daemonstate.TierContainerDown && tier != daemonstate.TierClaudeDown {
+		if d.acceptingWork() {
+			d.startQueuedItems(ctx) // Start coding on queued items
+		} else {
+			d.resumePreemptedItems(ctx) // Paused or draining: only finish in-flight work
+		}
 	}
 	d.saveState() // Always: persist
 }
@@ -380,12 +591,16 @@ func (d *Daemon) stateKey() string {
 // different machines targeting the same repo produce distinct identities,
 // preventing them from treating each other's claims as their own.
 func (d *Daemon) claimIdentity() string {
-	base := d.stateKey()
+	return d.stateKey() + "@" + claimHostname()
+}
+
+// claimHostname returns the hostname claims and leases are made under.
+func claimHostname() string {
 	hostname, err := os.Hostname()
 	if err != nil || hostname == "" {
-		hostname = "unknown"
+		return "unknown"
 	}
-	return base + "@" + hostname
+	return hostname
 }
 
 // resolveAndSaveRepoLabels resolves owner/repo display labels for all repos this daemon
@@ -416,6 +631,10 @@ func (d *Daemon) resolveAndSaveRepoLabels(ctx context.Context) {
 func (d *Daemon) loadWorkflowConfigs() {
 	d.workflowConfigs = make(map[string]*workflow.Config)
 	d.engines = make(map[string]*workflow.Engine)
+	d.workflowVersions = make(map[string]string)
+	d.pinnedEngines = make(map[string]*workflow.Engine)
+	d.workflowReloadErrors = make(map[string]string)
+	d.namedWorkflows = make(map[string]map[string]namedWorkflow)
 
 	for _, repoPath := range d.config.GetRepos() {
 		wfFile := d.getWorkflowFileForRepo(repoPath)
@@ -428,23 +647,49 @@ func (d *Daemon) loadWorkflowConfigs() {
 			d.logger.Warn("no .erg/workflow.yaml found — skipping repo (run `erg workflow init` to create one)", "repo", repoPath)
 			continue
 		}
-		d.workflowConfigs[repoPath] = cfg
-
-		// Sync Asana project GID from workflow config into the config store so
-		// MoveToSection and IsInSection (which read from config.GetAsanaProject)
-		// work without requiring a separate manual configuration step.
-		if cfg.Source.Provider == "asana" && cfg.Source.Filter.Project != "" {
-			d.config.SetAsanaProject(repoPath, cfg.Source.Filter.Project)
+		named, err := workflow.LoadNamedWorkflows(repoPath, wfFile, cfg)
+		if err != nil {
+			d.logger.Warn("failed to load label-selected workflows, using the main workflow for every issue", "repo", repoPath, "error", err)
+			named = nil
 		}
+		d.installWorkflowConfig(repoPath, cfg, named)
+
+		d.logger.Debug("loaded workflow config", "repo", repoPath, "provider", cfg.Source.Provider, "namedWorkflows", len(named))
+	}
+}
 
-		// Create engine with action registry and event checker
-		registry := d.buildActionRegistry()
-		checker := newEventChecker(d)
-		engine := workflow.NewEngine(cfg, registry, checker, d.logger)
-		d.engines[repoPath] = engine
+// installWorkflowConfig makes cfg the repo's current workflow config, and
+// named its label-selected workflows, and creates their engines.
+func (d *Daemon) installWorkflowConfig(repoPath string, cfg *workflow.Config, named map[string]*workflow.Config) {
+	// Sync Asana project GID from workflow config into the config store so
+	// MoveToSection and IsInSection (which read from config.GetAsanaProject)
+	// work without requiring a separate manual configuration step.
+	for _, src := range cfg.Source.Sources() {
+		if src.Provider == "asana" && src.Filter.Project != "" {
+			d.config.SetAsanaProject(repoPath, src.Filter.Project)
+		}
+	}
 
-		d.logger.Debug("loaded workflow config", "repo", repoPath, "provider", cfg.Source.Provider)
+	// Create engine with action registry and event checker
+	registry := d.buildActionRegistry()
+	checker := newEventChecker(d)
+	engine := workflow.NewEngine(cfg, registry, checker, d.logger)
+	namedEngines := make(map[string]namedWorkflow, len(named))
+	for name, namedCfg := range named {
+		namedEngines[name] = namedWorkflow{
+			engine:  workflow.NewEngine(namedCfg, registry, checker, d.logger),
+			version: workflow.HashConfig(namedCfg),
+		}
 	}
+
+	d.configureEventSinks(repoPath, cfg)
+
+	d.workflowMu.Lock()
+	defer d.workflowMu.Unlock()
+	d.workflowConfigs[repoPath] = cfg
+	d.engines[repoPath] = engine
+	d.workflowVersions[repoPath] = workflow.HashConfig(cfg)
+	d.namedWorkflows[repoPath] = namedEngines
 }
 
 // buildActionRegistry creates the action registry with all daemon actions.
@@ -467,10 +712,12 @@ func (d *Daemon) buildActionRegistry() *workflow.ActionRegistry {
 	registry.Register("ai.document", &documentingAction{daemon: d})
 	registry.Register("ai.fix_ci", &fixCIAction{daemon: d})
 	registry.Register("ai.address_review", &addressReviewAction{daemon: d})
+	registry.Register("ai.address_feedback", &addressFeedbackAction{daemon: d})
 	registry.Register("ai.write_pr_description", &writePRDescriptionAction{daemon: d})
 	registry.Register("git.format", &formatAction{daemon: d})
 	registry.Register("git.rebase", &rebaseAction{daemon: d})
 	registry.Register("git.validate_diff", &validateDiffAction{daemon: d})
+	registry.Register("quality.gate", &qualityGateAction{daemon: d})
 	registry.Register("git.squash", &squashAction{daemon: d})
 	registry.Register("git.cherry_pick", &cherryPickAction{daemon: d})
 	registry.Register("ai.resolve_conflicts", &resolveConflictsAction{daemon: d})
@@ -481,8 +728,13 @@ func (d *Daemon) buildActionRegistry() *workflow.ActionRegistry {
 	registry.Register("github.create_release", &createReleaseAction{daemon: d})
 	registry.Register("slack.notify", &slackNotifyAction{daemon: d})
 	registry.Register("webhook.post", &webhookPostAction{daemon: d})
+	registry.Register("exec.run", &execRunAction{daemon: d})
+	registry.Register("issue.create", &createIssueAction{daemon: d})
 	registry.Register("workflow.retry", workflow.NewRetryAction(registry))
 	registry.Register("workflow.wait", &waitAction{daemon: d})
+	if d.offline {
+		disableNetworkActions(registry)
+	}
 	return registry
 }
 
@@ -503,22 +755,32 @@ func (d *Daemon) startScheduler(ctx context.Context) {
 		for _, trigger := range wfCfg.Triggers {
 			repoPath := repoPath // capture loop variable
 			trigger := trigger   // capture loop variable
-			_, err := d.scheduler.AddFunc(trigger.Schedule, func() {
+			_, err := d.scheduler.AddFunc(triggerSpec(wfCfg, trigger), func() {
 				d.injectScheduledIssue(ctx, repoPath, trigger)
 			})
 			if err != nil {
 				d.logger.Warn("failed to register schedule trigger",
-					"repo", repoPath, "schedule", trigger.Schedule, "state", trigger.State, "error", err)
+					"repo", repoPath, "trigger", trigger.Key(), "schedule", trigger.Schedule, "state", trigger.State, "error", err)
 				continue
 			}
 			d.logger.Info("registered schedule trigger",
-				"repo", repoPath, "schedule", trigger.Schedule, "state", trigger.State)
+				"repo", repoPath, "trigger", trigger.Key(), "schedule", trigger.Schedule, "state", trigger.State,
+				"timezone", wfCfg.TriggerLocation(trigger).String())
 		}
 	}
 
 	d.scheduler.Start()
 }
 
+// triggerSpec returns the cron spec for a trigger, evaluated in its timezone
+// or the repo's settings.timezone unless the schedule names its own zone.
+func triggerSpec(wfCfg *workflow.Config, trigger workflow.TriggerConfig) string {
+	if strings.HasPrefix(trigger.Schedule, "CRON_TZ=") || strings.HasPrefix(trigger.Schedule, "TZ=") {
+		return trigger.Schedule
+	}
+	return "CRON_TZ=" + wfCfg.TriggerLocation(trigger).String() + " " + trigger.Schedule
+}
+
 // stopScheduler stops the cron scheduler if it was started, waiting up to
 // 5 seconds for any in-flight job to finish.
 func (d *Daemon) stopScheduler() {
@@ -547,11 +809,19 @@ func (d *Daemon) getWorkflowFileForRepo(repoPath string) string {
 	return d.workflowFile
 }
 
+// lookupWorkflowConfig returns the workflow config loaded for a repo, if any.
+func (d *Daemon) lookupWorkflowConfig(repoPath string) (*workflow.Config, bool) {
+	d.workflowMu.RLock()
+	defer d.workflowMu.RUnlock()
+	cfg, ok := d.workflowConfigs[repoPath]
+	return cfg, ok
+}
+
 // getWorkflowConfig returns the workflow config for a repo.
 // The repo must have a loaded config — if missing, this logs an error and
 // returns a minimal config to avoid panics, but the repo will not function.
 func (d *Daemon) getWorkflowConfig(repoPath string) *workflow.Config {
-	if cfg, ok := d.workflowConfigs[repoPath]; ok {
+	if cfg, ok := d.lookupWorkflowConfig(repoPath); ok {
 		return cfg
 	}
 	d.logger.Error("no workflow config loaded for repo — add .erg/workflow.yaml", "repo", repoPath)
@@ -567,7 +837,10 @@ func (d *Daemon) getWorkflowConfig(repoPath string) *workflow.Config {
 // The repo must have a loaded engine — if missing, this logs an error and
 // returns a minimal engine to avoid panics, but the repo will not function.
 func (d *Daemon) getEngine(repoPath string) *workflow.Engine {
-	if engine, ok := d.engines[repoPath]; ok {
+	d.workflowMu.RLock()
+	engine, ok := d.engines[repoPath]
+	d.workflowMu.RUnlock()
+	if ok {
 		return engine
 	}
 	d.logger.Error("no workflow engine loaded for repo — add .erg/workflow.yaml", "repo", repoPath)
@@ -582,12 +855,12 @@ func (d *Daemon) getEngine(repoPath string) *workflow.Engine {
 	return workflow.NewEngine(cfg, registry, checker, d.logger)
 }
 
-// getEffectiveMergeMethod returns the effective merge method.
-func (d *Daemon) getEffectiveMergeMethod(repoPath string) string {
+// getEffectiveMergeMethod returns the effective merge method for an item.
+func (d *Daemon) getEffectiveMergeMethod(repoPath string, item daemonstate.WorkItem) string {
 	if d.mergeMethod != "" {
 		return d.mergeMethod
 	}
-	wfCfg := d.getWorkflowConfig(repoPath)
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
 	mergeState := wfCfg.States["merge"]
 	if mergeState != nil {
 		p := workflow.NewParamHelper(mergeState.Params)
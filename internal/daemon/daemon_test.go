@@ -524,7 +524,7 @@ func TestDaemon_HasExistingSession(t *testing.T) {
 	cfg := testConfig()
 	d := testDaemon(cfg)
 
-	if d.hasExistingSession("/repo", "42") {
+	if d.hasExistingSession("/repo", issues.SourceGitHub, "42") {
 		t.Error("expected false for empty sessions")
 	}
 
@@ -534,13 +534,17 @@ func TestDaemon_HasExistingSession(t *testing.T) {
 		IssueRef: &config.IssueRef{ID: "42", Source: "github"},
 	})
 
-	if !d.hasExistingSession("/repo", "42") {
+	if !d.hasExistingSession("/repo", issues.SourceGitHub, "42") {
 		t.Error("expected true for existing session")
 	}
 
-	if d.hasExistingSession("/repo", "99") {
+	if d.hasExistingSession("/repo", issues.SourceGitHub, "99") {
 		t.Error("expected false for different issue")
 	}
+
+	if d.hasExistingSession("/repo", issues.SourceGitLab, "42") {
+		t.Error("expected false for the same number from another source")
+	}
 }
 
 func TestDaemon_GetMergeMethod(t *testing.T) {
@@ -818,13 +822,13 @@ func TestDaemon_GetEffectiveMergeMethod(t *testing.T) {
 	d.repoFilter = "/test/repo"
 
 	// Default
-	if got := d.getEffectiveMergeMethod("/test/repo"); got != "rebase" {
+	if got := d.getEffectiveMergeMethod("/test/repo", daemonstate.WorkItem{}); got != "rebase" {
 		t.Errorf("expected rebase, got %s", got)
 	}
 
 	// CLI override
 	d.mergeMethod = "squash"
-	if got := d.getEffectiveMergeMethod("/test/repo"); got != "squash" {
+	if got := d.getEffectiveMergeMethod("/test/repo", daemonstate.WorkItem{}); got != "squash" {
 		t.Errorf("expected squash, got %s", got)
 	}
 }
@@ -1081,8 +1085,9 @@ func TestDaemon_CollectCompletedWorkers_WorkerError(t *testing.T) {
 		it.State = daemonstate.WorkItemActive
 	})
 
-	// Create a done worker WITH an error (simulating API 500)
-	mock := worker.NewDoneWorkerWithError(fmt.Errorf("API error detected in response stream"))
+	// Create a done worker WITH an error. API outages are parked instead
+	// (see TestCollectCompletedWorkers_ClaudeOutageParksItem).
+	mock := worker.NewDoneWorkerWithError(fmt.Errorf("claude error: exit status 1"))
 	d.workers["item-err"] = mock
 
 	ctx := context.Background()
@@ -1506,6 +1511,53 @@ func TestDaemon_ProcessIdleSyncItems_ExecutesMerge(t *testing.T) {
 	}
 }
 
+func TestDaemon_ProcessIdleSyncItems_RunsParallelBranches(t *testing.T) {
+	workDir := initTestGitRepo(t)
+
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.WorkTree = workDir
+	cfg.AddSession(*sess)
+
+	d := testDaemon(cfg)
+	wfCfg := &workflow.Config{
+		Start: "checks",
+		States: map[string]*workflow.State{
+			"checks": {Type: workflow.StateTypeParallel, Branches: []string{"lint", "test"}, Next: "gather"},
+			"lint":   {Type: workflow.StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "touch lint.ok"}},
+			"test":   {Type: workflow.StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "touch test.ok"}},
+			"gather": {Type: workflow.StateTypeJoin, Next: "done", Error: "failed"},
+			"done":   {Type: workflow.StateTypeSucceed},
+			"failed": {Type: workflow.StateTypeFail},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "1"},
+		SessionID: "sess-1",
+		Branch:    "feature-sess-1",
+	})
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	d.state.AdvanceWorkItem("item-1", "checks", "idle")
+
+	d.processIdleSyncItems(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemCompleted || item.CurrentStep != "done" {
+		t.Errorf("expected completed at done, got state=%s step=%s phase=%s", item.State, item.CurrentStep, item.Phase)
+	}
+	for _, f := range []string{"lint.ok", "test.ok"} {
+		if _, err := os.Stat(filepath.Join(workDir, f)); err != nil {
+			t.Errorf("expected branch to create %s: %v", f, err)
+		}
+	}
+}
+
 func TestDaemon_ProcessIdleSyncItems_SkipsWaitStates(t *testing.T) {
 	cfg := testConfig()
 	d := testDaemon(cfg)
@@ -2417,6 +2469,79 @@ func TestHandleAsyncComplete_AIReview_ViaStepData(t *testing.T) {
 	}
 }
 
+func TestHandleAsyncComplete_StructuredResult(t *testing.T) {
+	// The submit_result envelope drives transitions: failed/blocked follow the
+	// error edge, and choice states can branch on result.status.
+	tests := []struct {
+		name     string
+		result   map[string]any
+		wantStep string
+	}{
+		{name: "no result", result: nil, wantStep: "done"},
+		{name: "success", result: workflow.StateResult{Status: "success"}.ToStepData(), wantStep: "done"},
+		{name: "partial routes via choice", result: workflow.StateResult{Status: "partial"}.ToStepData(), wantStep: "needs_followup"},
+		{name: "failed follows error edge", result: workflow.StateResult{Status: "failed", Summary: "tests broken"}.ToStepData(), wantStep: "failed"},
+		{name: "blocked follows error edge", result: workflow.StateResult{Status: "blocked"}.ToStepData(), wantStep: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			d := testDaemon(cfg)
+
+			sess := testSession("sess-result")
+			cfg.AddSession(*sess)
+
+			customCfg := &workflow.Config{
+				Start: "implement",
+				States: map[string]*workflow.State{
+					"implement": {
+						Type:   workflow.StateTypeTask,
+						Action: "ai.code",
+						Next:   "route",
+						Error:  "failed",
+					},
+					"route": {
+						Type: workflow.StateTypeChoice,
+						Choices: []workflow.ChoiceRule{
+							{Variable: "result.status", Equals: "partial", Next: "needs_followup"},
+						},
+						Default: "done",
+					},
+					"needs_followup": {Type: workflow.StateTypeSucceed},
+					"done":           {Type: workflow.StateTypeSucceed},
+					"failed":         {Type: workflow.StateTypeFail},
+				},
+			}
+			registry := d.buildActionRegistry()
+			engine := workflow.NewEngine(customCfg, registry, nil, d.logger)
+			d.engines = map[string]*workflow.Engine{sess.RepoPath: engine}
+
+			stepData := map[string]any{}
+			if tt.result != nil {
+				stepData[workflow.ResultStepDataKey] = tt.result
+			}
+			d.state.AddWorkItem(&daemonstate.WorkItem{
+				ID:          "item-result",
+				IssueRef:    config.IssueRef{Source: "github", ID: "301"},
+				SessionID:   "sess-result",
+				Branch:      "feature-sess-result",
+				CurrentStep: "implement",
+				StepData:    stepData,
+			})
+			d.state.AdvanceWorkItem("item-result", "implement", "async_pending")
+			d.workers["item-result"] = newMockDoneWorker()
+
+			d.collectCompletedWorkers(context.Background())
+
+			item, _ := d.state.GetWorkItem("item-result")
+			if item.CurrentStep != tt.wantStep {
+				t.Errorf("step = %q, want %q", item.CurrentStep, tt.wantStep)
+			}
+		})
+	}
+}
+
 func TestHandleAsyncComplete_AIReview_FileFallback(t *testing.T) {
 	// When StepData has no review_passed, fall back to reading .erg/ai_review.json.
 	cfg := testConfig()
@@ -3101,3 +3226,55 @@ func TestResolveAndSaveRepoLabels_FallsBackOnError(t *testing.T) {
 		t.Errorf("expected raw path fallback in pathLabels, got %q", pathLabels["/no/remote/here"])
 	}
 }
+
+func TestExecuteSyncChain_HookOutputMergedIntoStepData(t *testing.T) {
+	repoDir := t.TempDir()
+	cfg := testConfig()
+	d := testDaemon(cfg)
+
+	wfCfg := &workflow.Config{
+		Start: "test",
+		States: map[string]*workflow.State{
+			"test": {
+				Type:   workflow.StateTypeTask,
+				Action: "exec.run",
+				Params: map[string]any{"command": "echo {{step.packages}} > out.txt"},
+				Next:   "done",
+				Before: []workflow.HookConfig{{Run: `echo '{"packages": ["./cmd", "./internal"]}'`, Output: workflow.HookOutputJSON}},
+				After:  []workflow.HookConfig{{Run: `echo '{"tested": true}'`, Output: workflow.HookOutputJSON}},
+			},
+			"done": {Type: workflow.StateTypeSucceed},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+
+	sess := testSession("sess-1")
+	sess.RepoPath = repoDir
+	sess.WorkTree = repoDir
+	cfg.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-1",
+		IssueRef:    config.IssueRef{Source: "github", ID: "42"},
+		SessionID:   "sess-1",
+		CurrentStep: "test",
+		StepData:    map[string]any{},
+	})
+
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+
+	got, err := os.ReadFile(filepath.Join(repoDir, "out.txt"))
+	if err != nil {
+		t.Fatalf("expected exec.run to run: %v", err)
+	}
+	if string(got) != "./cmd ./internal\n" {
+		t.Errorf("command saw %q, want before-hook output expanded", got)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.StepData["tested"] != true {
+		t.Errorf("after-hook output not merged into step data: %v", item.StepData)
+	}
+	if item.State != daemonstate.WorkItemCompleted {
+		t.Errorf("State = %q, want completed", item.State)
+	}
+}
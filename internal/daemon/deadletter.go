@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// processDeadLetters moves issues whose work has failed
+// settings.dead_letter_after times to the dead-letter queue and explains why
+// on the issue. A dead-lettered issue is not retried until an operator
+// releases it with `erg dlq retry`.
+func (d *Daemon) processDeadLetters(ctx context.Context) {
+	var failed []daemonstate.WorkItem
+	for _, item := range d.state.GetAllWorkItems() {
+		if item.State == daemonstate.WorkItemFailed && item.IssueRef.ID != "" {
+			failed = append(failed, item)
+		}
+	}
+	// Newest first, so an issue with several failed items is parked with
+	// the one that failed last.
+	slices.SortFunc(failed, func(a, b daemonstate.WorkItem) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+
+	for _, item := range failed {
+		ref := item.IssueRef
+		if d.state.IsDeadLettered(ref.Source, ref.ID) {
+			continue
+		}
+		repoPath := d.workItemRepoPath(item)
+		failures := d.state.IssueFailureCount(ref.Source, ref.ID)
+		if failures < d.getWorkflowConfig(repoPath).DeadLetterAfter() {
+			continue
+		}
+
+		d.state.AddDeadLetter(daemonstate.DeadLetter{
+			WorkItemID: item.ID,
+			RepoPath:   repoPath,
+			IssueRef:   ref,
+			Failures:   failures,
+			Step:       item.CurrentStep,
+			Error:      item.ErrorMessage,
+		})
+		d.unqueueIssueWithSuffix(ctx, item, deadLetterReason(item, failures), "dead_letter")
+		d.logger.Warn("issue moved to the dead-letter queue", "event", "dlq.added",
+			"workItem", item.ID, "issue", ref.ID, "failures", failures, "step", item.CurrentStep, "repo", repoPath)
+	}
+}
+
+// deadLetterReason is the comment posted on an issue when it is moved to the
+// dead-letter queue.
+func deadLetterReason(item daemonstate.WorkItem, failures int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Work on this issue has failed %d times, so erg has stopped retrying it.", failures)
+	if item.CurrentStep != "" {
+		fmt.Fprintf(&b, " The last attempt failed at step `%s`", item.CurrentStep)
+		if item.ErrorMessage != "" {
+			fmt.Fprintf(&b, ": %s", item.ErrorMessage)
+		}
+		b.WriteString(".")
+	}
+	b.WriteString(" An operator can queue it again with `erg dlq retry` or drop it with `erg dlq discard`.")
+	return b.String()
+}
+
+// findDeadLetter returns the dead letter named by ref: a work item ID, or
+// an issue's tracker ID with or without a leading '#'.
+func (d *Daemon) findDeadLetter(ref string) (daemonstate.DeadLetter, error) {
+	if ref == "" {
+		return daemonstate.DeadLetter{}, fmt.Errorf("no issue given")
+	}
+	issueID := strings.TrimPrefix(ref, "#")
+	var matches []daemonstate.DeadLetter
+	for _, dl := range d.state.GetDeadLetters() {
+		if dl.WorkItemID == ref || dl.IssueRef.ID == issueID {
+			matches = append(matches, dl)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return daemonstate.DeadLetter{}, fmt.Errorf("%q is not in the dead-letter queue", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return daemonstate.DeadLetter{}, fmt.Errorf("%q matches several dead letters, name a work item instead", ref)
+}
+
+// controlDeadLetterRetry releases an issue from the dead-letter queue with a
+// fresh set of attempts and queues its work item again.
+func (d *Daemon) controlDeadLetterRetry(req control.Request) (control.Response, error) {
+	dl, err := d.findDeadLetter(req.Item)
+	if err != nil {
+		return control.Response{}, err
+	}
+	d.state.RemoveDeadLetter(dl.IssueRef.Source, dl.IssueRef.ID)
+	if err := d.RetryWorkItem(dl.WorkItemID); err != nil {
+		d.state.AddDeadLetter(dl)
+		return control.Response{}, err
+	}
+	d.logger.Info("dead letter retried by human", "event", "dlq.retried",
+		"workItem", dl.WorkItemID, "issue", dl.IssueRef.ID, "repo", dl.RepoPath)
+	d.wakeForControl()
+	resp := d.controlStatus()
+	resp.Message = fmt.Sprintf("issue %s released from the dead-letter queue; work item %s queued again", dl.IssueRef.ID, dl.WorkItemID)
+	return resp, nil
+}
+
+// controlDeadLetterDiscard drops an issue from the dead-letter queue without
+// retrying it. Its work item stays failed.
+func (d *Daemon) controlDeadLetterDiscard(req control.Request) (control.Response, error) {
+	dl, err := d.findDeadLetter(req.Item)
+	if err != nil {
+		return control.Response{}, err
+	}
+	d.state.RemoveDeadLetter(dl.IssueRef.Source, dl.IssueRef.ID)
+	d.saveState()
+	d.audit(dl.WorkItemID, daemonstate.AuditOperator, "discarded from the dead-letter queue by an operator", nil)
+	d.logger.Info("dead letter discarded by human", "event", "dlq.discarded",
+		"workItem", dl.WorkItemID, "issue", dl.IssueRef.ID, "repo", dl.RepoPath)
+	resp := d.controlStatus()
+	resp.Message = fmt.Sprintf("issue %s discarded from the dead-letter queue", dl.IssueRef.ID)
+	return resp, nil
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+)
+
+// deadLetterTestDaemon is confirmTestDaemon with items dead-lettered after
+// two failures.
+func deadLetterTestDaemon(t *testing.T) (*Daemon, *issues.FakeProvider) {
+	t.Helper()
+	d, prov, _ := confirmTestDaemon(t, false)
+	d.workflowConfigs["/test/repo"].Settings.DeadLetterAfter = 2
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) { it.StepData["_repo_path"] = "/test/repo" })
+	return d, prov
+}
+
+// retryAndFail retries item-1 and fails it again at the close step.
+func retryAndFail(t *testing.T, d *Daemon) {
+	t.Helper()
+	if err := d.RetryWorkItem("item-1"); err != nil {
+		t.Fatal(err)
+	}
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) { it.CurrentStep = "close" })
+	d.state.SetErrorMessage("item-1", "CI failed")
+	d.state.MarkWorkItemTerminal("item-1", false)
+}
+
+func TestProcessDeadLetters(t *testing.T) {
+	d, prov := deadLetterTestDaemon(t)
+	ctx := context.Background()
+
+	d.state.MarkWorkItemTerminal("item-1", false)
+	d.processDeadLetters(ctx)
+	if d.state.IsDeadLettered("linear", "ENG-1") {
+		t.Fatal("dead-lettered after one failure")
+	}
+
+	retryAndFail(t, d)
+	d.processDeadLetters(ctx)
+	d.processDeadLetters(ctx)
+
+	dls := d.state.GetDeadLetters()
+	if len(dls) != 1 || dls[0].WorkItemID != "item-1" || dls[0].Failures != 2 || dls[0].Step != "close" || dls[0].Error != "CI failed" {
+		t.Fatalf("dead letters = %+v", dls)
+	}
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("comments = %d, want 1", len(prov.CommentCalls))
+	}
+	body := prov.CommentCalls[0].Args[0]
+	if !strings.Contains(body, "dead_letter") || !strings.Contains(body, "failed 2 times") || !strings.Contains(body, "CI failed") {
+		t.Errorf("comment = %q", body)
+	}
+	if err := d.RetryWorkItem("item-1"); err == nil {
+		t.Error("expected retrying a dead-lettered item to be rejected")
+	}
+}
+
+func TestHandleControl_DeadLetterRetryAndDiscard(t *testing.T) {
+	d, _ := deadLetterTestDaemon(t)
+	ctx := context.Background()
+
+	if _, err := d.handleControl(control.Request{Command: control.CommandDLQRetry, Item: "ENG-1"}); err == nil {
+		t.Error("expected retrying an issue not in the dead-letter queue to be rejected")
+	}
+
+	d.state.MarkWorkItemTerminal("item-1", false)
+	retryAndFail(t, d)
+	d.processDeadLetters(ctx)
+	if _, err := d.handleControl(control.Request{Command: control.CommandDLQRetry, Item: "#ENG-1"}); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemQueued || d.state.IsDeadLettered("linear", "ENG-1") {
+		t.Fatalf("after retry: state %q, dead-lettered %v", item.State, d.state.IsDeadLettered("linear", "ENG-1"))
+	}
+	if n := d.state.IssueFailureCount("linear", "ENG-1"); n != 0 {
+		t.Errorf("failures after retry = %d, want 0", n)
+	}
+
+	d.state.MarkWorkItemTerminal("item-1", false)
+	retryAndFail(t, d)
+	d.processDeadLetters(ctx)
+	if _, err := d.handleControl(control.Request{Command: control.CommandDLQDiscard, Item: "item-1"}); err != nil {
+		t.Fatal(err)
+	}
+	item, _ = d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemFailed || d.state.IsDeadLettered("linear", "ENG-1") {
+		t.Errorf("after discard: state %q, dead-lettered %v", item.State, d.state.IsDeadLettered("linear", "ENG-1"))
+	}
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// minLeadTimeSamples is how many completed work items are needed before
+// their lead times are trusted to predict whether an issue meets its due date.
+const minLeadTimeSamples = 3
+
+// typicalLeadTime returns the median time completed work items took from
+// being queued to completing, or false when there is too little history.
+func (d *Daemon) typicalLeadTime() (time.Duration, bool) {
+	var leadTimes []time.Duration
+	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemCompleted) {
+		if item.CompletedAt != nil && !item.CreatedAt.IsZero() {
+			leadTimes = append(leadTimes, item.CompletedAt.Sub(item.CreatedAt))
+		}
+	}
+	if len(leadTimes) < minLeadTimeSamples {
+		return 0, false
+	}
+	slices.Sort(leadTimes)
+	return leadTimes[len(leadTimes)/2], true
+}
+
+// checkDeadlineRisk flags a newly queued item whose issue is due before it
+// would plausibly finish, given the typical lead time of past work.
+func (d *Daemon) checkDeadlineRisk(item *daemonstate.WorkItem) {
+	due := item.IssueRef.DueAt
+	if due.IsZero() {
+		return
+	}
+	leadTime, ok := d.typicalLeadTime()
+	if !ok {
+		return
+	}
+	eta := time.Now().Add(leadTime)
+	if !eta.After(due) {
+		return
+	}
+	item.DeadlineAtRisk = true
+	d.logger.Warn("issue unlikely to finish before its due date", "component", "issue-poller", "event", "deadline.at_risk",
+		"workItem", item.ID, "issue", item.IssueRef.ID, "due", due.Format(time.DateOnly),
+		"typicalLeadTime", leadTime.Round(time.Minute).String(), "estimatedDone", eta.Format(time.DateOnly))
+}
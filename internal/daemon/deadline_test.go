@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// addCompletedItem records a completed work item that took leadTime.
+func addCompletedItem(d *Daemon, id string, leadTime time.Duration) {
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: id})
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+		done := it.CreatedAt.Add(leadTime)
+		it.State = daemonstate.WorkItemCompleted
+		it.CompletedAt = &done
+	})
+}
+
+func TestTypicalLeadTime(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+
+	addCompletedItem(d, "a", 2*time.Hour)
+	addCompletedItem(d, "b", 48*time.Hour)
+	if _, ok := d.typicalLeadTime(); ok {
+		t.Error("expected no estimate with too little history")
+	}
+
+	addCompletedItem(d, "c", 24*time.Hour)
+	got, ok := d.typicalLeadTime()
+	if !ok || got != 24*time.Hour {
+		t.Errorf("typicalLeadTime = %v, %v; want median 24h", got, ok)
+	}
+}
+
+func TestCheckDeadlineRisk(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	for _, id := range []string{"a", "b", "c"} {
+		addCompletedItem(d, id, 48*time.Hour)
+	}
+
+	tests := []struct {
+		name string
+		due  time.Time
+		want bool
+	}{
+		{"no due date", time.Time{}, false},
+		{"due after typical lead time", time.Now().Add(7 * 24 * time.Hour), false},
+		{"due before typical lead time", time.Now().Add(24 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		item := &daemonstate.WorkItem{ID: "new", IssueRef: config.IssueRef{ID: "1", DueAt: tt.due}}
+		d.checkDeadlineRisk(item)
+		if item.DeadlineAtRisk != tt.want {
+			t.Errorf("%s: DeadlineAtRisk = %v, want %v", tt.name, item.DeadlineAtRisk, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// polledIssue is an issue fetched from one of a repo's sources.
+type polledIssue struct {
+	issue     issues.Issue
+	provider  issues.Source
+	fromCache bool
+}
+
+// issueURLPattern finds URLs in issue descriptions.
+var issueURLPattern = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+// linearIssuePath matches the identifying part of a Linear issue URL path;
+// the title slug that follows it changes when the issue is renamed.
+var linearIssuePath = regexp.MustCompile(`^/[^/]+/issue/[A-Za-z0-9]+-\d+`)
+
+// issueURLKey normalizes an issue URL for comparison: the scheme, query,
+// fragment, trailing slashes, and Linear title slugs are dropped and the
+// result lowercased. Returns "" for anything that isn't an absolute URL.
+func issueURLKey(raw string) string {
+	u, err := url.Parse(strings.TrimRight(raw, ".,;:!?"))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	path := strings.TrimRight(u.Path, "/")
+	if strings.EqualFold(u.Host, "linear.app") {
+		if m := linearIssuePath.FindString(path); m != "" {
+			path = m
+		}
+	}
+	return strings.ToLower(u.Host + path)
+}
+
+// issueLinks is an issue's own URL key and the keys of the URLs its
+// description mentions.
+type issueLinks struct {
+	key  string
+	refs map[string]bool
+}
+
+func linksOf(issueURL, body string) issueLinks {
+	l := issueLinks{key: issueURLKey(issueURL), refs: make(map[string]bool)}
+	for _, u := range issueURLPattern.FindAllString(body, -1) {
+		if k := issueURLKey(u); k != "" {
+			l.refs[k] = true
+		}
+	}
+	return l
+}
+
+// crossReferenced reports whether either issue links to the other.
+func (l issueLinks) crossReferenced(other issueLinks) bool {
+	return (l.key != "" && other.refs[l.key]) || (other.key != "" && l.refs[other.key])
+}
+
+// dedupAcrossSources drops polled issues that are the same work as an issue
+// from another of the repo's sources, so one task tracked in two places gets
+// one session. Issues are the same work when either links to the other's
+// URL. An issue linked to an existing work item (in any state) from another
+// source is dropped; among polled issues linked to each other, the one from
+// source.canonical is kept, or failing that the one from the earliest
+// source. Repos with a single source are returned unchanged.
+func (d *Daemon) dedupAcrossSources(repoPath string, wfCfg *workflow.Config, polled []polledIssue) []polledIssue {
+	if len(wfCfg.Source.Additional) == 0 || len(polled) == 0 {
+		return polled
+	}
+	log := d.logger.With("component", "issue-poller")
+
+	links := make([]issueLinks, len(polled))
+	for i, p := range polled {
+		links[i] = linksOf(p.issue.URL, p.issue.Body)
+	}
+	dropped := make([]bool, len(polled))
+
+	// Work already underway or finished in one tracker must not start again
+	// from another.
+	for _, item := range d.state.GetAllWorkItems() {
+		if d.workItemRepoPath(item) != repoPath {
+			continue
+		}
+		body, _ := item.StepData["issue_body"].(string)
+		itemLinks := linksOf(item.IssueRef.URL, body)
+		for i, p := range polled {
+			if dropped[i] || item.IssueRef.Source == string(p.provider) {
+				continue
+			}
+			if links[i].crossReferenced(itemLinks) {
+				dropped[i] = true
+				log.Info("skipping issue duplicated by existing work item",
+					"issue", p.issue.ID, "provider", p.provider, "workItem", item.ID, "workItemIssue", item.IssueRef.ID)
+			}
+		}
+	}
+
+	// Group the remaining issues that link to each other, across sources.
+	group := make([]int, len(polled))
+	for i := range group {
+		group[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if group[i] != i {
+			group[i] = find(group[i])
+		}
+		return group[i]
+	}
+	for i := range polled {
+		for j := i + 1; j < len(polled); j++ {
+			if dropped[i] || dropped[j] || polled[i].provider == polled[j].provider {
+				continue
+			}
+			if links[i].crossReferenced(links[j]) {
+				group[find(j)] = find(i)
+			}
+		}
+	}
+
+	// Keep one issue per group: the first from the canonical provider, else
+	// the first polled (sources are polled in configuration order).
+	canonical := issues.Source(wfCfg.Source.CanonicalProvider())
+	keep := make(map[int]int) // group root → kept index
+	for i, p := range polled {
+		if dropped[i] {
+			continue
+		}
+		root := find(i)
+		k, seen := keep[root]
+		if !seen || (p.provider == canonical && polled[k].provider != canonical) {
+			keep[root] = i
+		}
+	}
+
+	result := make([]polledIssue, 0, len(polled))
+	for i, p := range polled {
+		if dropped[i] {
+			continue
+		}
+		if k := keep[find(i)]; k != i {
+			log.Info("skipping issue duplicated in another tracker",
+				"issue", p.issue.ID, "provider", p.provider, "canonical", polled[k].issue.ID, "canonicalProvider", polled[k].provider)
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestIssueURLKey(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/Owner/Repo/issues/42", "github.com/owner/repo/issues/42"},
+		{"http://github.com/owner/repo/issues/42/", "github.com/owner/repo/issues/42"},
+		{"https://github.com/owner/repo/issues/42#issuecomment-1", "github.com/owner/repo/issues/42"},
+		{"https://github.com/owner/repo/issues/42.", "github.com/owner/repo/issues/42"},
+		{"https://linear.app/acme/issue/ENG-7/fix-the-login-page", "linear.app/acme/issue/eng-7"},
+		{"https://linear.app/acme/issue/ENG-7", "linear.app/acme/issue/eng-7"},
+		{"https://app.asana.com/0/111/222?focus=true", "app.asana.com/0/111/222"},
+		{"ENG-7", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := issueURLKey(tt.url); got != tt.want {
+			t.Errorf("issueURLKey(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// multiSourceTestDaemon returns a daemon whose repo polls Linear and,
+// additionally, Asana.
+func multiSourceTestDaemon(t *testing.T, canonical string) (*Daemon, *issues.FakeProvider, *issues.FakeProvider) {
+	t.Helper()
+	d, linear := offlineTestDaemon(t)
+	asana := issues.NewFakeProvider(issues.SourceAsana)
+	d.issueRegistry = issues.NewProviderRegistry(linear, asana)
+	d.workflowConfigs["/test/repo"].Source = workflow.SourceConfig{
+		Provider:   "linear",
+		Additional: []workflow.AdditionalSource{{Provider: "asana", Filter: workflow.FilterConfig{Project: "111"}}},
+		Canonical:  canonical,
+	}
+	linear.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Title: "Fix login", URL: "https://linear.app/acme/issue/ENG-1/fix-login", Body: "Synced from https://app.asana.com/0/111/222", Source: issues.SourceLinear},
+		{ID: "ENG-2", Title: "Unrelated", URL: "https://linear.app/acme/issue/ENG-2/unrelated", Source: issues.SourceLinear},
+	})
+	asana.SetIssues([]issues.Issue{
+		{ID: "222", Title: "Fix login", URL: "https://app.asana.com/0/111/222", Source: issues.SourceAsana},
+		{ID: "333", Title: "Asana only", URL: "https://app.asana.com/0/111/333", Source: issues.SourceAsana},
+	})
+	return d, linear, asana
+}
+
+func queuedIssueIDs(d *Daemon) map[string]bool {
+	ids := make(map[string]bool)
+	for _, item := range d.state.GetAllWorkItems() {
+		ids[item.IssueRef.Source+":"+item.IssueRef.ID] = true
+	}
+	return ids
+}
+
+func TestPollForNewIssues_DedupsAcrossSources(t *testing.T) {
+	d, _, asana := multiSourceTestDaemon(t, "")
+
+	d.pollForNewIssues(context.Background())
+
+	got := queuedIssueIDs(d)
+	for _, want := range []string{"linear:ENG-1", "linear:ENG-2", "asana:333"} {
+		if !got[want] {
+			t.Errorf("expected %s to be queued, got %v", want, got)
+		}
+	}
+	if got["asana:222"] {
+		t.Error("expected the Asana duplicate of ENG-1 to be skipped in favor of the primary provider")
+	}
+
+	// A new Asana task linking to ENG-1 (under a renamed slug) duplicates
+	// the existing work item and is skipped too.
+	asana.SetIssues([]issues.Issue{
+		{ID: "444", Title: "Login again", URL: "https://app.asana.com/0/111/444", Body: "See https://linear.app/acme/issue/ENG-1/login-renamed", Source: issues.SourceAsana},
+	})
+	d.pollForNewIssues(context.Background())
+	if queuedIssueIDs(d)["asana:444"] {
+		t.Error("expected issue duplicating an existing work item to be skipped")
+	}
+}
+
+func TestPollForNewIssues_DedupPrefersCanonicalProvider(t *testing.T) {
+	d, _, _ := multiSourceTestDaemon(t, "asana")
+
+	d.pollForNewIssues(context.Background())
+
+	got := queuedIssueIDs(d)
+	if !got["asana:222"] || got["linear:ENG-1"] {
+		t.Errorf("expected the canonical Asana task to win over ENG-1, got %v", got)
+	}
+	if !got["linear:ENG-2"] || !got["asana:333"] {
+		t.Errorf("expected unrelated issues from both sources to be queued, got %v", got)
+	}
+}
+
+func TestDedupAcrossSources_SingleSourceUnchanged(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	polled := []polledIssue{
+		{issue: issues.Issue{ID: "ENG-1", URL: "https://linear.app/acme/issue/ENG-1", Body: "dup of https://linear.app/acme/issue/ENG-2"}, provider: issues.SourceLinear},
+		{issue: issues.Issue{ID: "ENG-2", URL: "https://linear.app/acme/issue/ENG-2"}, provider: issues.SourceLinear},
+	}
+	if got := d.dedupAcrossSources("/test/repo", d.getWorkflowConfig("/test/repo"), polled); len(got) != 2 {
+		t.Errorf("expected single-source repos to be left alone, got %d issues", len(got))
+	}
+}
+
+func TestPollForNewIssues_SameNumberFromTwoSources(t *testing.T) {
+	d, linear := offlineTestDaemon(t)
+	gitlab := issues.NewFakeProvider(issues.SourceGitLab)
+	d.issueRegistry = issues.NewProviderRegistry(linear, gitlab)
+	d.workflowConfigs["/test/repo"].Source = workflow.SourceConfig{
+		Provider:   "linear",
+		Additional: []workflow.AdditionalSource{{Provider: "gitlab"}},
+	}
+	linear.SetIssues([]issues.Issue{{ID: "12", Title: "Linear twelve", URL: "https://linear.app/acme/issue/12", Source: issues.SourceLinear}})
+	gitlab.SetIssues([]issues.Issue{{ID: "12", Title: "GitLab twelve", URL: "https://gitlab.com/acme/app/-/issues/12", Source: issues.SourceGitLab}})
+	// A session for the Linear issue must not hide the GitLab one.
+	d.config.AddSession(config.Session{ID: "sess-1", RepoPath: "/test/repo", IssueRef: &config.IssueRef{Source: "linear", ID: "12"}})
+
+	d.pollForNewIssues(context.Background())
+
+	item, ok := d.state.GetWorkItem("/test/repo-gitlab-12")
+	if !ok || item.IssueRef.Title != "GitLab twelve" {
+		t.Fatalf("expected the GitLab issue queued under its own ID, got %+v (found %v)", item, ok)
+	}
+	if _, ok := d.state.GetWorkItem("/test/repo-12"); ok {
+		t.Error("expected the Linear issue skipped while it has a session")
+	}
+
+	// With the session gone, the Linear issue is queued without replacing
+	// the GitLab one.
+	d.config.RemoveSession("sess-1")
+	d.pollForNewIssues(context.Background())
+	if item, ok := d.state.GetWorkItem("/test/repo-12"); !ok || item.IssueRef.Source != "linear" {
+		t.Errorf("expected the Linear issue queued as /test/repo-12, got %+v (found %v)", item, ok)
+	}
+	if item, _ := d.state.GetWorkItem("/test/repo-gitlab-12"); item.IssueRef.Title != "GitLab twelve" {
+		t.Errorf("GitLab item was overwritten: %+v", item)
+	}
+}
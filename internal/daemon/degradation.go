@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// claudeOutageBackoff is how long coding stays paused after a session fails
+// because the Claude API is unavailable. The next session started after it
+// elapses acts as the probe: failing again extends the pause.
+const claudeOutageBackoff = 5 * time.Minute
+
+// claudeUnavailableHints are fragments of worker exit errors that mean the
+// Claude API itself failed (overload, 5xx, connection loss), as opposed to
+// the session doing something wrong.
+var claudeUnavailableHints = []string{
+	"api error detected in response stream",
+	"overloaded_error",
+	"api error: 500",
+	"api error: 502",
+	"api error: 503",
+	"api error: 529",
+	"connection error",
+}
+
+// isClaudeUnavailable reports whether a worker exit error means the Claude
+// API was unavailable. Sessions failing this way are parked and retried once
+// the outage backoff elapses instead of following the error edge.
+func isClaudeUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, hint := range claudeUnavailableHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// markClaudeUnavailable pauses coding for claudeOutageBackoff, logging the
+// outage once when it starts.
+func (d *Daemon) markClaudeUnavailable(err error) {
+	if d.claudeAvailable() {
+		d.logger.Warn("Claude API unavailable, pausing coding sessions",
+			"event", "claude.unavailable", "error", err, "retryIn", claudeOutageBackoff)
+	}
+	d.claudeDownUntil = time.Now().Add(claudeOutageBackoff)
+}
+
+// claudeAvailable reports whether coding sessions may start.
+func (d *Daemon) claudeAvailable() bool {
+	return !time.Now().Before(d.claudeDownUntil)
+}
+
+// parkForClaude puts an item whose session failed on a Claude outage back
+// into retry_pending, due once the outage backoff elapses. The retry count is
+// left alone so outages do not use up the step's retry budget.
+func (d *Daemon) parkForClaude(item daemonstate.WorkItem, err error) {
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = "retry_pending"
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_retry_after"] = d.claudeDownUntil.Format(time.RFC3339)
+		it.UpdatedAt = time.Now()
+	})
+	d.state.SetErrorMessage(item.ID, fmt.Sprintf("Claude unavailable: %v", err))
+}
+
+// updateDegradation works out the degradation tier from dependency health,
+// records it in state, and logs each change of tier once.
+func (d *Daemon) updateDegradation(dockerOK bool) daemonstate.DegradationTier {
+	tier := daemonstate.TierNormal
+	switch {
+	case !dockerOK:
+		tier = daemonstate.TierContainerDown
+	case !d.claudeAvailable():
+		tier = daemonstate.TierClaudeDown
+	case !d.state.GetTrackerOfflineSince().IsZero():
+		tier = daemonstate.TierTrackerDown
+	}
+
+	prev, since := d.state.GetDegradation()
+	if tier == prev {
+		return tier
+	}
+	if tier == daemonstate.TierNormal {
+		d.logger.Info("all dependencies healthy, leaving degraded mode",
+			"event", "degradation.cleared", "previous", string(prev), "duration", time.Since(since).Round(time.Second))
+	} else {
+		d.logger.Warn("entering degraded mode",
+			"event", "degradation.changed", "tier", string(tier), "previous", string(prev), "behavior", tier.Description())
+	}
+	d.state.SetDegradation(tier)
+	return tier
+}
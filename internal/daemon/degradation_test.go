@@ -0,0 +1,135 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+func TestIsClaudeUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("API error detected in response stream"), true},
+		{errors.New(`claude error: {"type":"error","error":{"type":"overloaded_error"}}`), true},
+		{errors.New("claude error: API Error: 529 Overloaded"), true},
+		{errors.New("claude error: API Error: 503 Service Unavailable"), true},
+		{errors.New("claude error: API Error: 400 invalid request"), false},
+		{errors.New("context cancelled"), false},
+		{errors.New("Cannot connect to the Docker daemon"), false},
+	}
+	for _, tt := range tests {
+		if got := isClaudeUnavailable(tt.err); got != tt.want {
+			t.Errorf("isClaudeUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateDegradation(t *testing.T) {
+	d := testDaemon(testConfig())
+
+	if tier := d.updateDegradation(true); tier != daemonstate.TierNormal {
+		t.Fatalf("healthy tier = %q, want normal", tier)
+	}
+
+	d.state.SetTrackerOffline(true)
+	if tier := d.updateDegradation(true); tier != daemonstate.TierTrackerDown {
+		t.Errorf("tracker offline tier = %q, want %q", tier, daemonstate.TierTrackerDown)
+	}
+
+	d.markClaudeUnavailable(errors.New("API Error: 529"))
+	if tier := d.updateDegradation(true); tier != daemonstate.TierClaudeDown {
+		t.Errorf("claude down tier = %q, want %q", tier, daemonstate.TierClaudeDown)
+	}
+
+	// The container runtime being down is the most restrictive tier.
+	if tier := d.updateDegradation(false); tier != daemonstate.TierContainerDown {
+		t.Errorf("docker down tier = %q, want %q", tier, daemonstate.TierContainerDown)
+	}
+	tier, since := d.state.GetDegradation()
+	if tier != daemonstate.TierContainerDown || since.IsZero() {
+		t.Errorf("state degradation = %q since %v, want %q with a time", tier, since, daemonstate.TierContainerDown)
+	}
+
+	d.state.SetTrackerOffline(false)
+	d.claudeDownUntil = time.Time{}
+	if tier := d.updateDegradation(true); tier != daemonstate.TierNormal {
+		t.Errorf("recovered tier = %q, want normal", tier)
+	}
+	if _, since := d.state.GetDegradation(); !since.IsZero() {
+		t.Errorf("degraded since = %v after recovery, want zero", since)
+	}
+}
+
+func TestTick_ClaudeDownHoldsQueuedItems(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	d.loadWorkflowConfigs()
+
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "item-claude-down",
+		IssueRef: config.IssueRef{Source: "github", ID: "42"},
+		StepData: map[string]any{},
+	})
+	d.markClaudeUnavailable(errors.New("API Error: 529"))
+
+	d.tick(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-claude-down")
+	if item.State != daemonstate.WorkItemQueued {
+		t.Errorf("expected item to stay queued while Claude is down, got %s", item.State)
+	}
+	if tier, _ := d.state.GetDegradation(); tier != daemonstate.TierClaudeDown {
+		t.Errorf("tier = %q, want %q", tier, daemonstate.TierClaudeDown)
+	}
+}
+
+func TestCollectCompletedWorkers_ClaudeOutageParksItem(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+
+	sess := testSession("sess-claude")
+	cfg.AddSession(*sess)
+
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-claude",
+		IssueRef:    config.IssueRef{Source: "github", ID: "71"},
+		SessionID:   "sess-claude",
+		Branch:      "feature-sess-claude",
+		CurrentStep: "coding",
+	})
+	d.state.AdvanceWorkItem("item-claude", "coding", "async_pending")
+	d.state.UpdateWorkItem("item-claude", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	d.workers["item-claude"] = worker.NewDoneWorkerWithError(fmt.Errorf("API error detected in response stream"))
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-claude")
+	if item.Phase != "retry_pending" {
+		t.Errorf("phase = %q, want retry_pending", item.Phase)
+	}
+	if item.IsTerminal() {
+		t.Error("a Claude outage should not fail the item")
+	}
+	if _, ok := item.StepData["_retry_after"].(string); !ok {
+		t.Error("expected _retry_after to hold the item until the outage backoff elapses")
+	}
+	if _, ok := item.StepData["_retry_count"]; ok {
+		t.Error("a Claude outage should not use up the retry budget")
+	}
+	if d.claudeAvailable() {
+		t.Error("expected coding to be paused after a Claude outage")
+	}
+}
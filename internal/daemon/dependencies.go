@@ -0,0 +1,256 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/sanitize"
+)
+
+// dependenciesKey is the step data key listing the blockers an issue
+// waited for, as dependencyRecord maps, so its session can be told what
+// they changed.
+const dependenciesKey = "_dependencies"
+
+// dependencyFilesShown caps the changed files listed per dependency.
+const dependencyFilesShown = 15
+
+// dependencyRecord is a blocker an issue waited for, as kept in its step
+// data.
+type dependencyRecord struct {
+	ID    string
+	Title string
+	PRURL string // the PR that resolved it, when erg did the work
+}
+
+// issueBlockers returns the issues blocking issue: those its body declares
+// ("Blocked by #12") and, when online, those the tracker records. Tracker
+// relations come with their state; blockers that are only declared have
+// closed set from the tracker when it can say.
+func (d *Daemon) issueBlockers(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, online bool) []issues.Blocker {
+	var blockers []issues.Blocker
+	seen := map[string]bool{issue.ID: true}
+	if online && d.issueRegistry != nil {
+		if f, ok := d.issueRegistry.GetProvider(provider).(issues.ProviderBlockerFetcher); ok {
+			fetched, err := f.FetchBlockers(ctx, repoPath, issue.ID)
+			if err != nil {
+				d.logger.Debug("failed to fetch blocking issues", "issue", issue.ID, "error", err)
+			}
+			for _, b := range fetched {
+				if !seen[b.ID] {
+					seen[b.ID] = true
+					blockers = append(blockers, b)
+				}
+			}
+		}
+	}
+	for _, id := range issues.ParseDependencies(issue.Body) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		b := issues.Blocker{ID: id}
+		if online && d.issueRegistry != nil {
+			if checker, ok := d.issueRegistry.GetProvider(provider).(issues.IssueStateChecker); ok {
+				closed, err := checker.IsIssueClosed(ctx, repoPath, id)
+				if err != nil {
+					d.logger.Debug("failed to check blocking issue", "issue", issue.ID, "blocker", id, "error", err)
+				}
+				b.Closed = closed
+			}
+		}
+		blockers = append(blockers, b)
+	}
+	return blockers
+}
+
+// maxDependencyWalk caps how many blockers' own blockers are looked up
+// when searching for a dependency cycle.
+const maxDependencyWalk = 25
+
+// unmetDependencies splits an issue's blockers into readiness messages for
+// those not done yet and the records of those that are. A blocker erg
+// works on is done once its work item completed, i.e. its PR merged;
+// other blockers are done once the tracker reports them closed. A blocker
+// erg gave up on, or one that leads back to the issue, is reported as
+// such, since waiting will not resolve it.
+func (d *Daemon) unmetDependencies(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, online bool) (missing []string, met []dependencyRecord) {
+	var waiting []issues.Blocker
+	for _, b := range d.issueBlockers(ctx, repoPath, issue, provider, online) {
+		msg, rec, done := d.blockerStatus(provider, b)
+		if done {
+			met = append(met, rec)
+			continue
+		}
+		missing = append(missing, msg)
+		waiting = append(waiting, b)
+	}
+	if online && len(waiting) > 0 {
+		if cycle := d.dependencyCycle(ctx, repoPath, issue, provider, waiting); cycle != nil {
+			labels := make([]string, len(cycle))
+			for i, id := range cycle {
+				labels[i] = epicIssueLabel(config.IssueRef{Source: string(provider), ID: id})
+			}
+			d.logger.Warn("dependency cycle holds issue", "issue", issue.ID, "cycle", strings.Join(cycle, " -> "))
+			missing = append(missing, fmt.Sprintf("Break the dependency cycle %s; these issues wait on each other", strings.Join(labels, " → ")))
+		}
+	}
+	return missing, met
+}
+
+// blockerStatus returns whether a blocker is done, with its record, or
+// else the readiness message saying what it is waiting for.
+func (d *Daemon) blockerStatus(provider issues.Source, b issues.Blocker) (msg string, rec dependencyRecord, done bool) {
+	label := epicIssueLabel(config.IssueRef{Source: string(provider), ID: b.ID})
+	if d.state.IsDeadLettered(string(provider), b.ID) {
+		return fmt.Sprintf("Blocker %s failed repeatedly and is in erg's dead-letter queue; fix and retry it, or remove the dependency", label), rec, false
+	}
+	if item, ok := d.issueWorkItem(string(provider), b.ID); ok {
+		switch item.State {
+		case daemonstate.WorkItemCompleted:
+			return "", dependencyRecord{ID: b.ID, Title: item.IssueRef.Title, PRURL: item.PRURL}, true
+		case daemonstate.WorkItemFailed:
+			if item.ErrorMessage == cancelledErrorMessage {
+				return fmt.Sprintf("Blocker %s was cancelled; finish it another way, or remove the dependency", label), rec, false
+			}
+			return fmt.Sprintf("Blocker %s failed; retry it, or remove the dependency", label), rec, false
+		}
+		return fmt.Sprintf("Wait for %s to be merged (erg is working on it)", label), rec, false
+	}
+	if !b.Closed {
+		return fmt.Sprintf("Wait for %s to be done", label), rec, false
+	}
+	return "", dependencyRecord{ID: b.ID, Title: b.Title}, true
+}
+
+// dependencyCycle returns the chain of blockers, starting and ending with
+// issue, by which issue ends up waiting on itself, or nil. Only blockers
+// not done yet are followed, and at most maxDependencyWalk of them are
+// looked up in the tracker.
+func (d *Daemon) dependencyCycle(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, blockers []issues.Blocker) []string {
+	if d.issueRegistry == nil {
+		return nil
+	}
+	getter, ok := d.issueRegistry.GetProvider(provider).(issues.IssueGetter)
+	if !ok {
+		return nil
+	}
+	visited := make(map[string]bool)
+	var walk func(path []string, blockers []issues.Blocker) []string
+	walk = func(path []string, blockers []issues.Blocker) []string {
+		for _, b := range blockers {
+			if b.ID == issue.ID {
+				return append(path, b.ID)
+			}
+			if visited[b.ID] || len(visited) >= maxDependencyWalk {
+				continue
+			}
+			visited[b.ID] = true
+			next, err := getter.GetIssue(ctx, repoPath, b.ID)
+			if err != nil || next == nil {
+				continue
+			}
+			var waiting []issues.Blocker
+			for _, nb := range d.issueBlockers(ctx, repoPath, *next, provider, true) {
+				if _, _, done := d.blockerStatus(provider, nb); !done {
+					waiting = append(waiting, nb)
+				}
+			}
+			if cycle := walk(append(path[:len(path):len(path)], b.ID), waiting); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return walk([]string{issue.ID}, blockers)
+}
+
+// issueWorkItem returns the latest work item for an issue, in any state.
+func (d *Daemon) issueWorkItem(source, issueID string) (daemonstate.WorkItem, bool) {
+	var latest daemonstate.WorkItem
+	found := false
+	for _, item := range d.state.GetAllWorkItems() {
+		if item.IssueRef.Source == source && item.IssueRef.ID == issueID {
+			if !found || item.CreatedAt.After(latest.CreatedAt) {
+				latest, found = item, true
+			}
+		}
+	}
+	return latest, found
+}
+
+// recordDependencies keeps the blockers a newly queued item waited for.
+func (d *Daemon) recordDependencies(itemID string, deps []dependencyRecord) {
+	if len(deps) == 0 {
+		return
+	}
+	records := make([]any, 0, len(deps))
+	for _, dep := range deps {
+		records = append(records, map[string]any{"id": dep.ID, "title": dep.Title, "pr_url": dep.PRURL})
+	}
+	d.state.UpdateWorkItem(itemID, func(it *daemonstate.WorkItem) {
+		it.StepData[dependenciesKey] = records
+	})
+}
+
+// itemDependencies returns the blockers recorded for a work item.
+func itemDependencies(item daemonstate.WorkItem) []dependencyRecord {
+	records, _ := item.StepData[dependenciesKey].([]any)
+	var deps []dependencyRecord
+	for _, r := range records {
+		m, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := m["id"].(string)
+		title, _ := m["title"].(string)
+		prURL, _ := m["pr_url"].(string)
+		deps = append(deps, dependencyRecord{ID: id, Title: title, PRURL: prURL})
+	}
+	return deps
+}
+
+// withDependencyContext appends what the item's blockers changed to a
+// session's initial message, so the session builds on that work instead
+// of rediscovering it. Blockers whose PR cannot be looked up are listed
+// without a diff summary.
+func (d *Daemon) withDependencyContext(ctx context.Context, repoPath string, item daemonstate.WorkItem, msg string) string {
+	deps := itemDependencies(item)
+	if len(deps) == 0 {
+		return msg
+	}
+	var sb strings.Builder
+	for _, dep := range deps {
+		label := epicIssueLabel(config.IssueRef{Source: item.IssueRef.Source, ID: dep.ID})
+		fmt.Fprintf(&sb, "- %s", label)
+		if dep.Title != "" {
+			fmt.Fprintf(&sb, ": %s", dep.Title)
+		}
+		if dep.PRURL == "" {
+			sb.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&sb, " — merged in %s\n", dep.PRURL)
+		prCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+		summary, err := d.gitService.GetPRDiffSummary(prCtx, repoPath, dep.PRURL)
+		cancel()
+		if err != nil {
+			d.logger.Debug("failed to summarize dependency PR", "workItem", item.ID, "pr", dep.PRURL, "error", err)
+			continue
+		}
+		fmt.Fprintf(&sb, "  %d files changed (+%d −%d):\n", len(summary.Files), summary.Additions, summary.Deletions)
+		for i, f := range summary.Files {
+			if i == dependencyFilesShown {
+				fmt.Fprintf(&sb, "  - … and %d more\n", len(summary.Files)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "  - %s (+%d −%d)\n", f.Path, f.Additions, f.Deletions)
+		}
+	}
+	return msg + "\n\n---\nThis issue depends on work that is already done. Build on it rather than redoing it:\n" +
+		sanitize.UntrustedContent("dependencies", strings.TrimRight(sb.String(), "\n"))
+}
@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestPollForNewIssues_HoldsIssuesUntilBlockersAreDone(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.workflowConfigs["/test/repo"].Source.Readiness = &workflow.ReadinessConfig{Dependencies: true, Comment: true}
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-2", Title: "Use the schema", Body: "Blocked by ENG-1", Source: issues.SourceLinear},
+	})
+	prov.SetBlockers("ENG-2", []issues.Blocker{{ID: "ENG-5", Title: "Pick a database", Closed: true}})
+
+	d.pollForNewIssues(context.Background())
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-2"); ok {
+		t.Fatal("expected blocked issue skipped")
+	}
+	if len(prov.CommentCalls) != 1 || !strings.Contains(prov.CommentCalls[0].Args[0], "Wait for ENG-1 to be done") {
+		t.Fatalf("expected a comment naming the open blocker, got %+v", prov.CommentCalls)
+	}
+
+	prov.SetIssueClosed("ENG-1", true)
+	d.pollForNewIssues(context.Background())
+
+	item, ok := d.state.GetWorkItem("/test/repo-ENG-2")
+	if !ok {
+		t.Fatal("expected issue queued once its blockers are done")
+	}
+	deps := itemDependencies(item)
+	if len(deps) != 2 || deps[0].ID != "ENG-5" || deps[0].Title != "Pick a database" || deps[1].ID != "ENG-1" {
+		t.Errorf("unexpected recorded dependencies: %+v", deps)
+	}
+}
+
+func TestUnmetDependencies_WaitsForErgWorkToMerge(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "/test/repo-ENG-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1", Title: "Add schema"},
+		PRURL:    "https://github.com/owner/repo/pull/12",
+	})
+	// Closed in the tracker, but erg's PR for it has not merged yet.
+	prov.SetIssueClosed("ENG-1", true)
+	issue := issues.Issue{ID: "ENG-2", Body: "Depends on: ENG-1", Source: issues.SourceLinear}
+
+	missing, met := d.unmetDependencies(context.Background(), "/test/repo", issue, issues.SourceLinear, true)
+	if len(missing) != 1 || !strings.Contains(missing[0], "erg is working on it") || len(met) != 0 {
+		t.Fatalf("expected blocker in progress, got missing %v met %v", missing, met)
+	}
+
+	if err := d.state.MarkWorkItemTerminal("/test/repo-ENG-1", true); err != nil {
+		t.Fatal(err)
+	}
+	missing, met = d.unmetDependencies(context.Background(), "/test/repo", issue, issues.SourceLinear, true)
+	if len(missing) != 0 || len(met) != 1 || met[0].PRURL != "https://github.com/owner/repo/pull/12" || met[0].Title != "Add schema" {
+		t.Errorf("expected merged blocker met, got missing %v met %+v", missing, met)
+	}
+}
+
+func TestUnmetDependencies_ReportsBlockersErgGaveUpOn(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	for _, id := range []string{"ENG-1", "ENG-2"} {
+		d.state.AddWorkItem(&daemonstate.WorkItem{
+			ID:       "/test/repo-" + id,
+			IssueRef: config.IssueRef{Source: "linear", ID: id},
+		})
+		if err := d.state.MarkWorkItemTerminal("/test/repo-"+id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.state.SetErrorMessage("/test/repo-ENG-2", cancelledErrorMessage)
+	d.state.AddDeadLetter(daemonstate.DeadLetter{IssueRef: config.IssueRef{Source: "linear", ID: "ENG-3"}})
+	issue := issues.Issue{ID: "ENG-4", Body: "Blocked by ENG-1, ENG-2, ENG-3", Source: issues.SourceLinear}
+
+	missing, met := d.unmetDependencies(context.Background(), "/test/repo", issue, issues.SourceLinear, true)
+	if len(missing) != 3 || len(met) != 0 {
+		t.Fatalf("expected 3 unmet blockers, got missing %v met %v", missing, met)
+	}
+	for i, want := range []string{"ENG-1 failed", "ENG-2 was cancelled", "ENG-3 failed repeatedly"} {
+		if !strings.Contains(missing[i], want) || strings.Contains(missing[i], "erg is working on it") {
+			t.Errorf("missing[%d] = %q, want it to say %q", i, missing[i], want)
+		}
+	}
+}
+
+func TestUnmetDependencies_DetectsCycles(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Body: "Blocked by ENG-2", Source: issues.SourceLinear},
+		{ID: "ENG-2", Body: "Blocked by ENG-3", Source: issues.SourceLinear},
+		{ID: "ENG-3", Body: "Blocked by ENG-1", Source: issues.SourceLinear},
+		{ID: "ENG-4", Body: "Blocked by ENG-1", Source: issues.SourceLinear},
+	})
+
+	missing, _ := d.unmetDependencies(context.Background(), "/test/repo", issues.Issue{ID: "ENG-1", Body: "Blocked by ENG-2", Source: issues.SourceLinear}, issues.SourceLinear, true)
+	if len(missing) != 2 || !strings.Contains(missing[1], "ENG-1 → ENG-2 → ENG-3 → ENG-1") {
+		t.Fatalf("expected the cycle reported, got %v", missing)
+	}
+
+	// An issue waiting on a cycle it is not part of just waits.
+	missing, _ = d.unmetDependencies(context.Background(), "/test/repo", issues.Issue{ID: "ENG-4", Body: "Blocked by ENG-1", Source: issues.SourceLinear}, issues.SourceLinear, true)
+	if len(missing) != 1 || strings.Contains(missing[0], "cycle") {
+		t.Errorf("expected only the blocker, got %v", missing)
+	}
+
+	// Once a blocker in the chain is done, there is no cycle.
+	prov.SetIssueClosed("ENG-3", true)
+	missing, _ = d.unmetDependencies(context.Background(), "/test/repo", issues.Issue{ID: "ENG-1", Body: "Blocked by ENG-2", Source: issues.SourceLinear}, issues.SourceLinear, true)
+	if len(missing) != 1 || strings.Contains(missing[0], "cycle") {
+		t.Errorf("expected no cycle once ENG-3 is closed, got %v", missing)
+	}
+}
+
+func TestWithDependencyContext(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("gh", []string{"pr", "view", "https://github.com/owner/repo/pull/12", "--json", "title,additions,deletions,files"}, exec.MockResponse{
+		Stdout: []byte(`{"title":"Add schema","additions":40,"deletions":5,"files":[{"path":"db/schema.sql","additions":30,"deletions":0},{"path":"db/migrate.go","additions":10,"deletions":5}]}`),
+	})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	item := daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "14"},
+		StepData: map[string]any{},
+	}
+
+	if got := d.withDependencyContext(context.Background(), "/test/repo", item, "msg"); got != "msg" {
+		t.Errorf("expected message unchanged without dependencies, got %q", got)
+	}
+
+	d.state.AddWorkItem(&item)
+	d.recordDependencies("item-1", []dependencyRecord{
+		{ID: "12", Title: "Add schema", PRURL: "https://github.com/owner/repo/pull/12"},
+		{ID: "9", Title: "Pick a database"},
+	})
+	item, _ = d.state.GetWorkItem("item-1")
+
+	got := d.withDependencyContext(context.Background(), "/test/repo", item, "msg")
+	for _, want := range []string{
+		"#12: Add schema — merged in https://github.com/owner/repo/pull/12",
+		"2 files changed (+40 −5)",
+		"db/migrate.go (+10 −5)",
+		"#9: Pick a database",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in message, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/sanitize"
+)
+
+// issueContextTruncSuffix marks issue context cut at the configured cap.
+const issueContextTruncSuffix = "\n... (issue context truncated)"
+
+// withIssueContext appends the context the issue's provider gathers around
+// it (linked issues, tracker fields, recent comments) to a session's initial
+// message. The message is returned unchanged when enrichment is disabled for
+// the repo, the provider cannot enrich, or the lookup fails — a session
+// without the extra context is better than no session.
+func (d *Daemon) withIssueContext(ctx context.Context, repoPath string, item daemonstate.WorkItem, msg string) string {
+	wfCfg := d.getWorkflowConfig(repoPath)
+	if !wfCfg.IssueContextEnabled() || item.StepData["_synthetic"] == "true" || d.issueRegistry == nil {
+		return msg
+	}
+	enricher, ok := d.issueRegistry.GetProvider(issues.Source(item.IssueRef.Source)).(issues.ProviderEnricher)
+	if !ok {
+		return msg
+	}
+
+	body, _ := item.StepData["issue_body"].(string)
+	issue := issues.Issue{
+		ID:     item.IssueRef.ID,
+		Title:  item.IssueRef.Title,
+		URL:    item.IssueRef.URL,
+		Body:   body,
+		Source: issues.Source(item.IssueRef.Source),
+	}
+	enrichCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+	extra, err := enricher.EnrichIssue(enrichCtx, repoPath, issue)
+	cancel()
+	if err != nil {
+		d.logger.Warn("failed to gather issue context", "workItem", item.ID, "issue", item.IssueRef.ID, "error", err)
+		return msg
+	}
+	if extra == "" {
+		return msg
+	}
+	extra = truncateDiff(extra, wfCfg.IssueContextMaxChars(), issueContextTruncSuffix)
+	return msg + "\n\n---\nLinked context from the issue tracker:\n" + sanitize.UntrustedContent("issue_context", extra)
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func enrichTestItem() daemonstate.WorkItem {
+	return daemonstate.WorkItem{
+		ID:       "/test/repo-ENG-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1", Title: "Fix login"},
+		StepData: map[string]any{"issue_body": "Users cannot log in"},
+	}
+}
+
+func TestWithIssueContext_DisabledByDefault(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	prov.SetEnrichment("ENG-1", "### Linked issues")
+
+	if got := d.withIssueContext(context.Background(), "/test/repo", enrichTestItem(), "msg"); got != "msg" {
+		t.Errorf("message changed with issue_context unset: %q", got)
+	}
+	if len(prov.EnrichIssueCalls) != 0 {
+		t.Errorf("expected no enrichment lookups, got %d", len(prov.EnrichIssueCalls))
+	}
+}
+
+func TestWithIssueContext_AppendsWrappedContext(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{IssueContext: &enabled}
+	prov.SetEnrichment("ENG-1", "### Recent comments\n\n**alice**: it fails on Safari")
+
+	got := d.withIssueContext(context.Background(), "/test/repo", enrichTestItem(), "msg")
+
+	if !strings.HasPrefix(got, "msg\n\n---\nLinked context from the issue tracker:\n") {
+		t.Errorf("unexpected message: %q", got)
+	}
+	if !strings.Contains(got, `<user-content type="issue_context">`) || !strings.Contains(got, "it fails on Safari") {
+		t.Errorf("expected context wrapped as untrusted content, got %q", got)
+	}
+}
+
+func TestWithIssueContext_TruncatesAtCap(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{IssueContext: &enabled, IssueContextMaxChars: 100}
+	prov.SetEnrichment("ENG-1", strings.Repeat("x", 500))
+
+	got := d.withIssueContext(context.Background(), "/test/repo", enrichTestItem(), "msg")
+
+	if strings.Count(got, "x") > 100 {
+		t.Errorf("context not capped: %d chars kept", strings.Count(got, "x"))
+	}
+	if !strings.Contains(got, "(issue context truncated)") {
+		t.Errorf("expected truncation note in %q", got)
+	}
+}
+
+func TestWithIssueContext_SkipsOnErrorAndSynthetic(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{IssueContext: &enabled}
+
+	synthetic := enrichTestItem()
+	synthetic.StepData["_synthetic"] = "true"
+	if got := d.withIssueContext(context.Background(), "/test/repo", synthetic, "msg"); got != "msg" {
+		t.Errorf("synthetic item enriched: %q", got)
+	}
+	if len(prov.EnrichIssueCalls) != 0 {
+		t.Errorf("expected no lookup for a synthetic item, got %d", len(prov.EnrichIssueCalls))
+	}
+
+	prov.SetEnrichError(errors.New("tracker down"))
+	if got := d.withIssueContext(context.Background(), "/test/repo", enrichTestItem(), "msg"); got != "msg" {
+		t.Errorf("message changed after enrichment failed: %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// epicSummaryStep is the comment marker step for epic summaries. Each epic
+// carries one summary comment that is updated in place.
+const epicSummaryStep = "epic-summary"
+
+// epicKey identifies an epic within a repo's issue tracker.
+type epicKey struct {
+	repoPath string
+	source   string
+	epic     string
+}
+
+// epicProgress aggregates the work items belonging to one epic.
+type epicProgress struct {
+	Done       int
+	InProgress int
+	Blocked    int
+	Queued     int
+	CostUSD    float64
+	// BlockedItems lists the failed or quarantined items, for the summary.
+	BlockedItems []daemonstate.WorkItem
+}
+
+// Total returns the number of work items in the epic.
+func (p *epicProgress) Total() int {
+	return p.Done + p.InProgress + p.Blocked + p.Queued
+}
+
+// epicSummaryRecord is the last summary posted for an epic.
+type epicSummaryRecord struct {
+	at   time.Time
+	body string
+}
+
+// collectEpicProgress groups work items by the epic their issue declared and
+// tallies each group's status and spend. Items without an epic are ignored.
+func (d *Daemon) collectEpicProgress(ctx context.Context) map[epicKey]*epicProgress {
+	groups := make(map[epicKey]*epicProgress)
+	for _, item := range d.state.GetAllWorkItems() {
+		if item.IssueRef.Epic == "" {
+			continue
+		}
+		key := epicKey{repoPath: d.resolveRepoPath(ctx, item), source: item.IssueRef.Source, epic: item.IssueRef.Epic}
+		p := groups[key]
+		if p == nil {
+			p = &epicProgress{}
+			groups[key] = p
+		}
+		p.CostUSD += item.CostUSD
+		switch {
+		case item.State == daemonstate.WorkItemCompleted:
+			p.Done++
+		case item.State == daemonstate.WorkItemFailed || item.Phase == phaseQuarantined:
+			p.Blocked++
+			p.BlockedItems = append(p.BlockedItems, item)
+		case item.State == daemonstate.WorkItemQueued:
+			p.Queued++
+		default:
+			p.InProgress++
+		}
+	}
+	return groups
+}
+
+// formatEpicSummary renders the summary comment for an epic.
+func formatEpicSummary(p *epicProgress) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Epic progress: %d of %d issues done.\n\n", p.Done, p.Total())
+	fmt.Fprintf(&sb, "- Done: %d\n", p.Done)
+	fmt.Fprintf(&sb, "- In progress: %d\n", p.InProgress)
+	fmt.Fprintf(&sb, "- Blocked: %d\n", p.Blocked)
+	fmt.Fprintf(&sb, "- Queued: %d\n", p.Queued)
+	fmt.Fprintf(&sb, "- Spend: $%.2f\n", p.CostUSD)
+
+	if len(p.BlockedItems) > 0 {
+		items := slices.Clone(p.BlockedItems)
+		slices.SortFunc(items, func(a, b daemonstate.WorkItem) int { return strings.Compare(a.IssueRef.ID, b.IssueRef.ID) })
+		sb.WriteString("\nBlocked:\n")
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- %s %s", epicIssueLabel(item.IssueRef), item.IssueRef.Title)
+			if item.ErrorMessage != "" {
+				fmt.Fprintf(&sb, ": %s", item.ErrorMessage)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// epicIssueLabel returns how an issue is referenced in its own tracker.
+func epicIssueLabel(ref config.IssueRef) string {
+	switch ref.Source {
+	case "github", "gitlab":
+		return "#" + ref.ID
+	default:
+		return ref.ID
+	}
+}
+
+// postEpicSummaries updates the progress summary comment on each epic whose
+// repo has settings.epic_summaries enabled. An epic's summary is refreshed at
+// most once per settings.epic_summary_interval, and only when it changed.
+//
+// This is best-effort: failures are logged but do not affect the workflow.
+func (d *Daemon) postEpicSummaries(ctx context.Context) {
+	groups := d.collectEpicProgress(ctx)
+	if len(groups) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for key, p := range groups {
+		wfCfg, _ := d.lookupWorkflowConfig(key.repoPath)
+		if !wfCfg.EpicSummariesEnabled() {
+			continue
+		}
+
+		body := formatEpicSummary(p)
+		last, seen := d.epicSummaries[key]
+		if seen && (last.body == body || now.Sub(last.at) < wfCfg.EpicSummaryInterval()) {
+			continue
+		}
+
+		epicItem := daemonstate.WorkItem{
+			ID:       fmt.Sprintf("%s-epic-%s", key.repoPath, key.epic),
+			IssueRef: config.IssueRef{Source: key.source, ID: key.epic},
+			StepData: map[string]any{"_repo_path": key.repoPath},
+		}
+		log := d.logger.With("epic", key.epic, "repo", key.repoPath)
+
+		// The timestamp is left out of the recorded body so an unchanged
+		// summary is still recognised as unchanged.
+		stamped := body + "\n\n_Updated " + wfCfg.FormatLocalTime(now) + "_"
+		ok, err := d.postMarkedComment(ctx, epicItem, epicSummaryStep, stamped)
+		if !ok {
+			log.Debug("epic summaries not supported for source", "source", key.source)
+			continue
+		}
+		if err != nil {
+			log.Warn("failed to post epic summary (non-fatal)", "error", err)
+			continue
+		}
+		log.Info("posted epic summary", "done", p.Done, "total", p.Total())
+
+		if d.epicSummaries == nil {
+			d.epicSummaries = make(map[epicKey]epicSummaryRecord)
+		}
+		d.epicSummaries[key] = epicSummaryRecord{at: now, body: body}
+	}
+}
@@ -0,0 +1,135 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// epicTestDaemon returns a daemon with epic summaries enabled, a fake GitHub
+// provider, and three work items under epic #100: one completed, one active,
+// and one failed.
+func epicTestDaemon(t *testing.T) (*Daemon, *issues.FakeProvider) {
+	t.Helper()
+	d := testDaemon(testConfig())
+	prov := issues.NewFakeProvider(issues.SourceGitHub)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{EpicSummaries: &enabled}
+
+	for _, tc := range []struct {
+		id    string
+		state daemonstate.WorkItemState
+		cost  float64
+	}{
+		{"1", daemonstate.WorkItemCompleted, 1.50},
+		{"2", daemonstate.WorkItemActive, 0.25},
+		{"3", daemonstate.WorkItemFailed, 0.75},
+	} {
+		d.state.AddWorkItem(&daemonstate.WorkItem{
+			ID:       "/test/repo-" + tc.id,
+			IssueRef: config.IssueRef{Source: "github", ID: tc.id, Title: "Issue " + tc.id, Epic: "100"},
+			StepData: map[string]any{"_repo_path": "/test/repo"},
+		})
+		d.state.UpdateWorkItem("/test/repo-"+tc.id, func(it *daemonstate.WorkItem) {
+			it.State = tc.state
+			it.CostUSD = tc.cost
+			if tc.state == daemonstate.WorkItemFailed {
+				it.ErrorMessage = "CI kept failing"
+			}
+		})
+	}
+	// An item outside any epic must not be counted.
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "/test/repo-4",
+		IssueRef: config.IssueRef{Source: "github", ID: "4"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+	return d, prov
+}
+
+func TestCollectEpicProgress(t *testing.T) {
+	d, _ := epicTestDaemon(t)
+
+	groups := d.collectEpicProgress(context.Background())
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 epic, got %d", len(groups))
+	}
+	p := groups[epicKey{repoPath: "/test/repo", source: "github", epic: "100"}]
+	if p == nil {
+		t.Fatal("expected progress for epic #100")
+	}
+	if p.Done != 1 || p.InProgress != 1 || p.Blocked != 1 || p.Queued != 0 {
+		t.Errorf("got done=%d in_progress=%d blocked=%d queued=%d", p.Done, p.InProgress, p.Blocked, p.Queued)
+	}
+	if p.CostUSD != 2.50 {
+		t.Errorf("CostUSD = %v, want 2.50", p.CostUSD)
+	}
+}
+
+func TestFormatEpicSummary(t *testing.T) {
+	p := &epicProgress{
+		Done: 2, InProgress: 1, Blocked: 1, CostUSD: 3.456,
+		BlockedItems: []daemonstate.WorkItem{{
+			IssueRef:     config.IssueRef{Source: "github", ID: "9", Title: "Flaky"},
+			ErrorMessage: "tests failed",
+		}},
+	}
+	got := formatEpicSummary(p)
+	for _, want := range []string{"2 of 4 issues done", "- In progress: 1", "- Spend: $3.46", "- #9 Flaky: tests failed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPostEpicSummaries(t *testing.T) {
+	d, prov := epicTestDaemon(t)
+	ctx := context.Background()
+
+	d.postEpicSummaries(ctx)
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(prov.CommentCalls))
+	}
+	if prov.CommentCalls[0].IssueID != "100" {
+		t.Errorf("commented on %q, want epic 100", prov.CommentCalls[0].IssueID)
+	}
+	if !strings.Contains(prov.CommentCalls[0].Args[0], "1 of 3 issues done") {
+		t.Errorf("unexpected body: %q", prov.CommentCalls[0].Args[0])
+	}
+
+	// Unchanged progress is not re-posted, even after the interval.
+	d.epicSummaries[epicKey{repoPath: "/test/repo", source: "github", epic: "100"}] = epicSummaryRecord{
+		at:   time.Now().Add(-2 * time.Hour),
+		body: d.epicSummaries[epicKey{repoPath: "/test/repo", source: "github", epic: "100"}].body,
+	}
+	d.postEpicSummaries(ctx)
+	if total := len(prov.CommentCalls) + len(prov.UpdateCommentCalls); total != 1 {
+		t.Errorf("expected no re-post of unchanged summary, got %d calls", total)
+	}
+
+	// Changed progress within the interval is throttled.
+	d.epicSummaries[epicKey{repoPath: "/test/repo", source: "github", epic: "100"}] = epicSummaryRecord{at: time.Now(), body: "stale"}
+	d.postEpicSummaries(ctx)
+	if total := len(prov.CommentCalls) + len(prov.UpdateCommentCalls); total != 1 {
+		t.Errorf("expected throttled summary, got %d calls", total)
+	}
+}
+
+func TestPostEpicSummaries_Disabled(t *testing.T) {
+	d, prov := epicTestDaemon(t)
+	d.workflowConfigs["/test/repo"].Settings.EpicSummaries = nil
+
+	d.postEpicSummaries(context.Background())
+
+	if len(prov.CommentCalls) != 0 {
+		t.Errorf("expected no comments when disabled, got %d", len(prov.CommentCalls))
+	}
+}
@@ -188,6 +188,17 @@ func (c *eventChecker) checkPRReviewed(ctx context.Context, params *workflow.Par
 				return false, nil, nil
 			}
 
+			// Addressing feedback is a coding session; hold it while the
+			// Claude API is unavailable.
+			if !d.claudeAvailable() {
+				log.Debug("Claude API unavailable, deferring feedback")
+				return false, nil, nil
+			}
+			if d.budgetPaused(sess.RepoPath) {
+				log.Debug("spend budget exceeded, deferring feedback")
+				return false, nil, nil
+			}
+
 			// Start addressing feedback (this is an internal sub-action of the wait state).
 			// Pass the batch CommentCount so addressFeedback sets CommentsAddressed
 			// using the same counting source used for detection here.
@@ -258,6 +269,9 @@ func (c *eventChecker) checkCIComplete(ctx context.Context, params *workflow.Par
 
 	switch ciStatus {
 	case git.CIStatusPassing, git.CIStatusNone:
+		if ciStatus == git.CIStatusPassing {
+			c.reportCIGreen(ctx, item.ID)
+		}
 		if !d.autoMerge {
 			log.Info("CI passed but auto-merge disabled")
 			return false, nil, nil
@@ -288,6 +302,13 @@ func (c *eventChecker) checkCIComplete(ctx context.Context, params *workflow.Par
 	return false, nil, nil
 }
 
+// reportCIGreen posts the CI-green progress milestone for a work item.
+func (c *eventChecker) reportCIGreen(ctx context.Context, itemID string) {
+	if wi, ok := c.daemon.state.GetWorkItem(itemID); ok {
+		c.daemon.postProgress(ctx, wi, workflow.ProgressCIGreen)
+	}
+}
+
 // checkPRMergeable checks if the PR is mergeable — approved review AND CI passing.
 // This is a convenience event that combines pr.reviewed (approval) and ci.complete in one check.
 func (c *eventChecker) checkPRMergeable(ctx context.Context, params *workflow.ParamHelper, item *workflow.WorkItemView) (bool, map[string]any, error) {
@@ -417,6 +438,8 @@ func (c *eventChecker) checkCIWaitForChecks(ctx context.Context, params *workflo
 	ciStatus := git.CIStatusPassing
 	if len(failedChecks) > 0 {
 		ciStatus = git.CIStatusFailing
+	} else {
+		c.reportCIGreen(ctx, item.ID)
 	}
 
 	log.Info("all CI checks complete", "ci_status", ciStatus, "passed", len(passedChecks), "failed", len(failedChecks))
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// Step data keys recording the fallback model an item's step moved on to,
+// and which step that was.
+const (
+	fallbackModelKey = "_fallback_model"
+	fallbackStepKey  = "_fallback_step"
+)
+
+// fallbackModel returns the fallback model item's current step moved on to
+// after the Claude API failed on its own, or "" when it hasn't.
+func fallbackModel(item daemonstate.WorkItem) string {
+	if step, _ := item.StepData[fallbackStepKey].(string); step != item.CurrentStep {
+		return ""
+	}
+	model, _ := item.StepData[fallbackModelKey].(string)
+	return model
+}
+
+// stepModel returns the resolved model a session for item's current step
+// runs on: its fallback model, if it moved on to one, else the state's.
+func (d *Daemon) stepModel(wfCfg *workflow.Config, item daemonstate.WorkItem) string {
+	if model := fallbackModel(item); model != "" {
+		return claude.ResolveModel(model)
+	}
+	return d.resolveStateModel(wfCfg, item.CurrentStep)
+}
+
+// retryOnFallbackModel puts an item whose session failed because the Claude
+// API was overloaded or failing back into retry_pending on the next model
+// of its step's chain (workflow.Config.ModelChain) in the workflow it runs
+// on, to start right away rather than waiting out the outage. It reports false when err is not an
+// outage, the step runs on another agent backend, or every model has been
+// tried; the step starts over on its own model after the outage then.
+func (d *Daemon) retryOnFallbackModel(item daemonstate.WorkItem, repoPath string, err error) bool {
+	if !isClaudeUnavailable(err) {
+		return false
+	}
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
+	if wfCfg.BackendName(item.CurrentStep) != "" {
+		return false
+	}
+	chain := wfCfg.ModelChain(item.CurrentStep)
+	current := 0
+	if model := fallbackModel(item); model != "" {
+		current = 1 + slices.Index(chain[1:], model)
+	}
+	next := ""
+	for i := current + 1; i < len(chain); i++ {
+		// Skip models already tried earlier in the chain.
+		if !slices.Contains(chain[:i], chain[i]) {
+			next = chain[i]
+			break
+		}
+	}
+
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if next == "" {
+			delete(it.StepData, fallbackModelKey)
+			delete(it.StepData, fallbackStepKey)
+			return
+		}
+		it.Phase = "retry_pending"
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData[fallbackModelKey] = next
+		it.StepData[fallbackStepKey] = it.CurrentStep
+		delete(it.StepData, "_retry_after")
+		it.UpdatedAt = time.Now()
+	})
+	if next == "" {
+		return false
+	}
+	d.logger.Warn("Claude API failed on the step's model, retrying on the next fallback model",
+		"event", "model.fallback", "workItem", item.ID, "step", item.CurrentStep,
+		"model", cmp.Or(chain[current], "default"), "fallback", next, "error", err)
+	d.state.SetErrorMessage(item.ID, fmt.Sprintf("%v; retrying on %s", err, next))
+	return true
+}
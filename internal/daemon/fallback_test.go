@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// fallbackTestDaemon returns a daemon whose /test/repo workflow codes on
+// opus falling back to sonnet then haiku, with item-fb's coding session
+// failed on a Claude overload.
+func fallbackTestDaemon(t *testing.T, stepData map[string]any) *Daemon {
+	t.Helper()
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:  "coding",
+		Source: workflow.SourceConfig{Provider: "github"},
+		States: map[string]*workflow.State{
+			"coding": {
+				Type: workflow.StateTypeTask, Action: "ai.code", Next: "done", Error: "failed",
+				Model: "opus", FallbackModels: []string{"sonnet", "haiku"},
+			},
+			"done":   {Type: workflow.StateTypeSucceed},
+			"failed": {Type: workflow.StateTypeFail},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+
+	sess := testSession("sess-fb")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-fb",
+		IssueRef:    config.IssueRef{Source: "github", ID: "88"},
+		SessionID:   sess.ID,
+		CurrentStep: "coding",
+		StepData:    stepData,
+	})
+	d.state.AdvanceWorkItem("item-fb", "coding", "async_pending")
+	d.state.UpdateWorkItem("item-fb", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	d.workers["item-fb"] = worker.NewDoneWorkerWithError(errors.New(`API Error: 529 {"type":"overloaded_error"}`))
+	return d
+}
+
+func TestCollectCompletedWorkers_ClaudeOverloadFallsBack(t *testing.T) {
+	d := fallbackTestDaemon(t, map[string]any{})
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-fb")
+	if item.Phase != "retry_pending" || fallbackModel(item) != "sonnet" {
+		t.Fatalf("expected a retry on sonnet, got %s with %v", item.Phase, item.StepData)
+	}
+	if _, ok := item.StepData["_retry_after"]; ok {
+		t.Error("expected the fallback to start right away")
+	}
+	if !d.claudeAvailable() {
+		t.Error("expected coding not paused while a fallback model is left")
+	}
+	if got := d.stepModel(d.workflowConfigs["/test/repo"], item); got != claude.ResolveModel("sonnet") {
+		t.Errorf("stepModel = %q, want sonnet's", got)
+	}
+}
+
+func TestCollectCompletedWorkers_FallbackChainExhausted(t *testing.T) {
+	d := fallbackTestDaemon(t, map[string]any{fallbackModelKey: "haiku", fallbackStepKey: "coding"})
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-fb")
+	if item.Phase != "retry_pending" || item.StepData["_retry_after"] == nil {
+		t.Fatalf("expected the item parked for the outage, got %s with %v", item.Phase, item.StepData)
+	}
+	if fallbackModel(item) != "" {
+		t.Error("expected the step to start over on its own model after the outage")
+	}
+	if d.claudeAvailable() {
+		t.Error("expected coding paused once every model failed")
+	}
+}
+
+func TestRetryOnFallbackModel_UsesItemWorkflow(t *testing.T) {
+	d := fallbackTestDaemon(t, map[string]any{})
+	addNamedWorkflow(t, d, "/test/repo", "fast", &workflow.Config{
+		States: map[string]*workflow.State{
+			"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Model: "sonnet", FallbackModels: []string{"haiku"}},
+		},
+	})
+	d.state.UpdateWorkItem("item-fb", func(it *daemonstate.WorkItem) { it.Workflow = "fast" })
+	item, _ := d.state.GetWorkItem("item-fb")
+
+	if !d.retryOnFallbackModel(item, "/test/repo", errors.New(`API Error: 529 {"type":"overloaded_error"}`)) {
+		t.Fatal("expected a retry on the named workflow's chain")
+	}
+	item, _ = d.state.GetWorkItem("item-fb")
+	if got := fallbackModel(item); got != "haiku" {
+		t.Errorf("fallback model = %q, want the named workflow's haiku", got)
+	}
+}
+
+func TestFallbackModel_OtherStep(t *testing.T) {
+	item := daemonstate.WorkItem{CurrentStep: "review", StepData: map[string]any{fallbackModelKey: "sonnet", fallbackStepKey: "coding"}}
+	if got := fallbackModel(item); got != "" {
+		t.Errorf("expected a fallback to apply to its own step only, got %q", got)
+	}
+}
+
+func TestCreateWorkerWithPrompt_UsesFallbackModel(t *testing.T) {
+	d := fallbackTestDaemon(t, map[string]any{fallbackModelKey: "sonnet", fallbackStepKey: "coding"})
+	d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, _ bool, _ []claude.Message) claude.RunnerInterface {
+		return claude.NewMockRunner(sessionID, false, nil)
+	})
+	sess := d.config.GetSession("sess-fb")
+	item, _ := d.state.GetWorkItem("item-fb")
+
+	d.createWorkerWithPrompt(context.Background(), item, sess, "go", "")
+
+	if got := d.sessionMgr.GetRunner(sess.ID).GetModel(); got != claude.ResolveModel("sonnet") {
+		t.Errorf("model = %q, want sonnet's", got)
+	}
+}
+
+func TestRecordItemSpend_ByModel(t *testing.T) {
+	d := fallbackTestDaemon(t, map[string]any{})
+	runner := claude.NewMockRunner("sess-fb", false, nil)
+	runner.SetModel("claude-sonnet-4-5")
+	d.sessionMgr.SetRunner("sess-fb", runner)
+
+	d.RecordItemSpend("sess-fb", 1.25, 10, 100)
+
+	ledger, err := d.state.SpendLedger(daemonstate.SpendDay(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != 1 || ledger[0].Model != "claude-sonnet-4-5" || ledger[0].CostUSD != 1.25 {
+		t.Errorf("ledger = %+v", ledger)
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// defaultSystemPrompts maps each AI action to the system prompt it runs with
+// when its state does not set params.system_prompt.
+var defaultSystemPrompts = map[string]string{
+	"ai.code":              DefaultCodingSystemPrompt,
+	"ai.plan":              DefaultPlanningSystemPrompt,
+	"ai.document":          DefaultDocumentingSystemPrompt,
+	"ai.fix_ci":            DefaultCodingSystemPrompt,
+	"ai.resolve_conflicts": DefaultCodingSystemPrompt,
+	"ai.address_review":    DefaultCodingSystemPrompt,
+	"ai.address_feedback":  DefaultCodingSystemPrompt,
+	"ai.summarize":         DefaultSummarizeSystemPrompt,
+	"ai.review":            DefaultReviewSystemPrompt,
+}
+
+// configFingerprint computes the fingerprint of the workflow config in effect
+// for a repo: the config hash, the model used for coding, and a hash of the
+// system prompt each AI state resolves to. Prompts are resolved the same way
+// the workers resolve them, falling back to the built-in default.
+func (d *Daemon) configFingerprint(repoPath string) workflow.Fingerprint {
+	wfCfg, _ := d.lookupWorkflowConfig(repoPath)
+	if wfCfg == nil {
+		return workflow.Fingerprint{}
+	}
+
+	fp := workflow.Fingerprint{
+		Workflow: workflow.HashConfig(wfCfg),
+		Prompts:  make(map[string]string),
+	}
+	codingState := ""
+	for _, name := range slices.Sorted(maps.Keys(wfCfg.States)) {
+		state := wfCfg.States[name]
+		if state == nil || state.Type != workflow.StateTypeTask {
+			continue
+		}
+		defaultPrompt, ok := defaultSystemPrompts[state.Action]
+		if !ok {
+			continue
+		}
+		if state.Action == "ai.code" && codingState == "" {
+			codingState = name
+		}
+		prompt, err := workflow.ResolveSystemPrompt(workflow.NewParamHelper(state.Params).String("system_prompt", ""), repoPath)
+		if err != nil || prompt == "" {
+			prompt = defaultPrompt
+		}
+		fp.Prompts[name] = workflow.HashPrompt(prompt)
+	}
+	fp.Model = d.resolveStateModel(wfCfg, codingState)
+	return fp
+}
+
+// stampPRFingerprint appends the config fingerprint as a hidden footer to the
+// body of the PR for the session's branch, replacing any earlier footer.
+func (d *Daemon) stampPRFingerprint(ctx context.Context, sess *config.Session, fp workflow.Fingerprint) error {
+	bodyCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	body, err := d.gitService.GetPRBody(bodyCtx, sess.RepoPath, sess.Branch)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	stamped := workflow.WithFingerprintFooter(body, fp)
+	if stamped == body {
+		return nil
+	}
+	updateCtx, updateCancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer updateCancel()
+	return d.gitService.UpdatePRBody(updateCtx, sess.RepoPath, sess.Branch, stamped)
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	d := testDaemon(testConfig())
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "review.md"), []byte("Review carefully."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d.workflowConfigs[repo] = &workflow.Config{
+		Start: "coding",
+		States: map[string]*workflow.State{
+			"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Model: "opus", Next: "review"},
+			"review": {Type: workflow.StateTypeTask, Action: "ai.review", Params: map[string]any{"system_prompt": "file:review.md"}, Next: "pr"},
+			"pr":     {Type: workflow.StateTypeTask, Action: "github.create_pr", Next: "done"},
+			"done":   {Type: workflow.StateTypeSucceed},
+		},
+	}
+
+	fp := d.configFingerprint(repo)
+	if fp.Workflow != workflow.HashConfig(d.workflowConfigs[repo]) {
+		t.Errorf("Workflow = %q, want config hash", fp.Workflow)
+	}
+	if fp.Model != d.resolveStateModel(d.workflowConfigs[repo], "coding") || fp.Model == "" {
+		t.Errorf("Model = %q, want the coding state's model", fp.Model)
+	}
+	if len(fp.Prompts) != 2 {
+		t.Fatalf("Prompts = %v, want coding and review only", fp.Prompts)
+	}
+	if fp.Prompts["coding"] != workflow.HashPrompt(DefaultCodingSystemPrompt) {
+		t.Errorf("coding prompt hash = %q, want hash of the default prompt", fp.Prompts["coding"])
+	}
+	if fp.Prompts["review"] != workflow.HashPrompt("Review carefully.") {
+		t.Errorf("review prompt hash = %q, want hash of review.md", fp.Prompts["review"])
+	}
+
+	if got := d.configFingerprint("/no/such/repo"); got.Workflow != "" {
+		t.Errorf("expected empty fingerprint for unknown repo, got %+v", got)
+	}
+}
+
+func TestStampPRFingerprint(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"pr", "view"}, exec.MockResponse{
+		Stdout: []byte(`{"body":"## Summary\nFixes the bug."}`),
+	})
+	mockExec.AddPrefixMatch("gh", []string{"pr", "edit"}, exec.MockResponse{})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	sess := testSession("sess-1")
+
+	fp := d.configFingerprint(sess.RepoPath)
+	if err := d.stampPRFingerprint(context.Background(), sess, fp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body string
+	for _, c := range mockExec.GetCalls() {
+		if c.Name == "gh" && len(c.Args) >= 5 && c.Args[1] == "edit" && c.Args[3] == "--body" {
+			body = c.Args[4]
+		}
+	}
+	if !strings.HasPrefix(body, "## Summary\nFixes the bug.") {
+		t.Errorf("expected original body kept, got %q", body)
+	}
+	if !strings.HasSuffix(body, fp.Footer()) {
+		t.Errorf("expected fingerprint footer, got %q", body)
+	}
+}
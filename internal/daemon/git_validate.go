@@ -55,26 +55,14 @@ var defaultTestPatterns = []string{
 // Returns a non-empty violations slice when checks fail, or an error if the
 // checks could not be executed at all (e.g. git command failure).
 func (d *Daemon) validateDiff(ctx context.Context, item daemonstate.WorkItem, params *workflow.ParamHelper) ([]string, error) {
-	sess, err := d.getSessionOrError(item.SessionID)
+	workDir, diffRef, err := d.branchDiff(ctx, item)
 	if err != nil {
 		return nil, err
 	}
 
-	workDir := sess.GetWorkDir()
-
-	baseBranch := sess.BaseBranch
-	if baseBranch == "" {
-		baseBranch = d.gitService.GetDefaultBranch(ctx, sess.RepoPath)
-	}
-
 	diffCtx, cancel := context.WithTimeout(ctx, timeoutGitPush)
 	defer cancel()
 
-	// Three-dot notation: diff from merge base of baseBranch and item.Branch
-	// to item.Branch. This captures only commits introduced by the feature
-	// branch, ignoring unrelated upstream changes.
-	diffRef := baseBranch + "..." + item.Branch
-
 	changedFiles, err := gitDiffNameOnly(diffCtx, workDir, diffRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
@@ -150,6 +138,25 @@ func (d *Daemon) validateDiff(ctx context.Context, item daemonstate.WorkItem, pa
 	return violations, nil
 }
 
+// branchDiff returns the work item's working directory and the diff ref
+// that selects the changes its branch introduces.
+func (d *Daemon) branchDiff(ctx context.Context, item daemonstate.WorkItem) (workDir, diffRef string, err error) {
+	sess, err := d.getSessionOrError(item.SessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	baseBranch := sess.BaseBranch
+	if baseBranch == "" {
+		baseBranch = d.gitService.GetDefaultBranch(ctx, sess.RepoPath)
+	}
+
+	// Three-dot notation: diff from merge base of baseBranch and item.Branch
+	// to item.Branch. This captures only commits introduced by the feature
+	// branch, ignoring unrelated upstream changes.
+	return sess.GetWorkDir(), baseBranch + "..." + item.Branch, nil
+}
+
 // gitDiffNameOnly returns the list of file paths changed between diffRef.
 // An empty diff returns a nil slice (not an error).
 func gitDiffNameOnly(ctx context.Context, workDir, diffRef string) ([]string, error) {
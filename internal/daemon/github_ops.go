@@ -16,8 +16,9 @@ import (
 )
 
 // createPR creates a pull request for a work item's session.
-// When draft is true the PR is created in draft state.
-func (d *Daemon) createPR(ctx context.Context, item daemonstate.WorkItem, draft bool) (string, error) {
+// When draft is true the PR is created in draft state. A non-empty
+// bodyTemplate is rendered into the new PR's body; see applyPRBodyTemplate.
+func (d *Daemon) createPR(ctx context.Context, item daemonstate.WorkItem, draft bool, bodyTemplate string) (string, error) {
 	sess, err := d.getSessionOrError(item.SessionID)
 	if err != nil {
 		return "", err
@@ -71,10 +72,65 @@ func (d *Daemon) createPR(ctx context.Context, item daemonstate.WorkItem, draft
 		return "", lastErr
 	}
 
-	log.Info("PR created", "event", "pr.created", "url", prURL, "repo", sess.RepoPath)
+	if bodyTemplate != "" {
+		item.PRURL = prURL
+		if err := d.applyPRBodyTemplate(ctx, item, sess, bodyTemplate); err != nil {
+			log.Warn("failed to apply PR body template, keeping the generated body (non-fatal)", "error", err)
+		}
+	}
+
+	if err := d.stampPRVerification(ctx, item, sess); err != nil {
+		log.Warn("failed to add verification verdict to PR body (non-fatal)", "error", err)
+	}
+
+	fp := d.configFingerprint(sess.RepoPath)
+	if fp.Workflow != "" {
+		if err := d.stampPRFingerprint(ctx, sess, fp); err != nil {
+			log.Warn("failed to add config fingerprint to PR body (non-fatal)", "error", err)
+		}
+	}
+
+	log.Info("PR created", "event", "pr.created", "url", prURL, "repo", sess.RepoPath,
+		"workflowVersion", fp.Workflow, "model", fp.Model, "promptHashes", fp.PromptList())
+	d.audit(item.ID, daemonstate.AuditPR, "opened "+prURL, map[string]any{"url": prURL, "branch": sess.Branch, "draft": draft})
 	return prURL, nil
 }
 
+// prBodyTemplateData is what github.create_pr body templates can reference:
+// the work item's template data plus Body, the generated PR description.
+type prBodyTemplateData struct {
+	workflow.TemplateData
+	Body string
+}
+
+// applyPRBodyTemplate replaces the body of the session's PR with tmpl (or
+// the repo file a file: reference names) rendered for item. {{.Body}} in the
+// template is the description the PR was created with.
+func (d *Daemon) applyPRBodyTemplate(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, tmpl string) error {
+	tmpl, err := workflow.ResolveSystemPrompt(tmpl, sess.RepoPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve body template: %w", err)
+	}
+
+	bodyCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	body, err := d.gitService.GetPRBody(bodyCtx, sess.RepoPath, sess.Branch)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	rendered, err := workflow.RenderTemplate(tmpl, prBodyTemplateData{TemplateData: d.templateData(ctx, item), Body: body})
+	if err != nil {
+		return err
+	}
+	if rendered == body {
+		return nil
+	}
+	updateCtx, updateCancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer updateCancel()
+	return d.gitService.UpdatePRBody(updateCtx, sess.RepoPath, sess.Branch, rendered)
+}
+
 // branchHasChanges returns true if the session's branch has new commits relative
 // to the base branch OR has uncommitted changes in the worktree. Returns false
 // when the coding session made no changes at all.
@@ -149,7 +205,7 @@ func (d *Daemon) mergePR(ctx context.Context, item daemonstate.WorkItem) error {
 		return nil
 	}
 
-	method := d.getEffectiveMergeMethod(sess.RepoPath)
+	method := d.getEffectiveMergeMethod(sess.RepoPath, item)
 
 	mergeCtx, cancel := context.WithTimeout(ctx, timeoutGitHubMerge)
 	defer cancel()
@@ -216,6 +272,9 @@ func (d *Daemon) mergePR(ctx context.Context, item daemonstate.WorkItem) error {
 	d.config.MarkSessionPRMerged(item.SessionID)
 	d.saveConfig("mergePR")
 	d.logger.Info("PR merged", "event", "pr.merged", "workItem", item.ID, "branch", item.Branch, "repo", sess.RepoPath)
+	d.audit(item.ID, daemonstate.AuditPR, "merged "+item.Branch, map[string]any{"branch": item.Branch, "method": method})
+	d.postProgress(ctx, item, workflow.ProgressMerged)
+	d.completeIssue(ctx, item, sess.RepoPath)
 
 	// Persist the repo path before cleanup so workItemView can find it
 	// after the session is removed from config.
@@ -231,6 +290,33 @@ func (d *Daemon) mergePR(ctx context.Context, item daemonstate.WorkItem) error {
 	return nil
 }
 
+// completeIssue runs the issue provider's post-merge hook, for providers that
+// track completion themselves (e.g. moving a backlog file to done/). Sources
+// whose PR link text cannot close the issue (Asana, for example) are closed
+// through ProviderActions.CloseIssue instead.
+// Failures are logged but do not affect the merge.
+func (d *Daemon) completeIssue(ctx context.Context, item daemonstate.WorkItem, repoPath string) {
+	if d.issueRegistry == nil {
+		return
+	}
+	p := d.issueRegistry.GetProvider(issues.Source(item.IssueRef.Source))
+	if p == nil {
+		return
+	}
+
+	var err error
+	if completer, ok := p.(issues.ProviderCompleter); ok {
+		err = completer.CompleteIssue(ctx, repoPath, item.IssueRef.ID)
+	} else if pa, ok := p.(issues.ProviderActions); ok && p.GetPRLinkText(issues.Issue{ID: item.IssueRef.ID, Source: p.Source()}) == "" {
+		closeCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+		defer cancel()
+		err = pa.CloseIssue(closeCtx, repoPath, item.IssueRef.ID)
+	}
+	if err != nil {
+		d.logger.Warn("failed to complete issue after merge (non-fatal)", "workItem", item.ID, "issue", item.IssueRef.ID, "error", err)
+	}
+}
+
 // ergGitHubMarker returns the idempotency HTML comment marker for GitHub comments.
 // It is invisible when rendered by GitHub's Markdown parser.
 func ergGitHubMarker(step string) string {
@@ -288,6 +374,22 @@ func (d *Daemon) commentOnIssue(ctx context.Context, item daemonstate.WorkItem,
 		return fmt.Errorf("comment body is empty")
 	}
 
+	err = d.postGitHubIssueComment(ctx, repoPath, issueNum, body, step)
+	return d.bufferIfUnreachable(err, daemonstate.PendingOp{
+		Kind:       daemonstate.PendingComment,
+		WorkItemID: item.ID,
+		RepoPath:   repoPath,
+		Source:     item.IssueRef.Source,
+		IssueID:    item.IssueRef.ID,
+		Body:       body,
+		Step:       step,
+	})
+}
+
+// postGitHubIssueComment posts body on a GitHub issue. When step is non-empty
+// the comment carries the step marker and an existing marked comment is
+// updated in place rather than duplicated.
+func (d *Daemon) postGitHubIssueComment(ctx context.Context, repoPath string, issueNum int, body, step string) error {
 	commentCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
 	defer cancel()
 
@@ -394,13 +496,33 @@ func (d *Daemon) commentViaProvider(ctx context.Context, item daemonstate.WorkIt
 		return fmt.Errorf("comment body is empty")
 	}
 
-	p := d.issueRegistry.GetProvider(expectedSource)
+	err = d.postProviderComment(ctx, expectedSource, repoPath, item.IssueRef.ID, body, step)
+	if err == nil {
+		d.auditComment(item.ID, body)
+	}
+	return d.bufferIfUnreachable(err, daemonstate.PendingOp{
+		Kind:       daemonstate.PendingComment,
+		WorkItemID: item.ID,
+		RepoPath:   repoPath,
+		Source:     string(expectedSource),
+		IssueID:    item.IssueRef.ID,
+		Body:       body,
+		Step:       step,
+	})
+}
+
+// postProviderComment posts body on an issue through the source's
+// ProviderActions. When step is non-empty the comment carries the step marker
+// and, if the provider supports it, an existing marked comment is updated in
+// place rather than duplicated.
+func (d *Daemon) postProviderComment(ctx context.Context, source issues.Source, repoPath, issueID, body, step string) error {
+	p := d.issueRegistry.GetProvider(source)
 	if p == nil {
-		return fmt.Errorf("%s provider not registered", expectedSource)
+		return fmt.Errorf("%s provider not registered", source)
 	}
 	pa, ok := p.(issues.ProviderActions)
 	if !ok {
-		return fmt.Errorf("%s provider does not support commenting", expectedSource)
+		return fmt.Errorf("%s provider does not support commenting", source)
 	}
 
 	commentCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
@@ -414,21 +536,21 @@ func (d *Daemon) commentViaProvider(ctx context.Context, item daemonstate.WorkIt
 		// Attempt idempotent upsert if the provider supports it.
 		if gc, ok := p.(issues.ProviderGateChecker); ok {
 			if cu, ok := p.(issues.ProviderCommentUpdater); ok {
-				existing, listErr := gc.GetIssueComments(commentCtx, repoPath, item.IssueRef.ID)
+				existing, listErr := gc.GetIssueComments(commentCtx, repoPath, issueID)
 				if listErr == nil {
 					for _, c := range existing {
 						if containsMarker(c, marker) || containsMarker(c, legacyMarker) {
-							return cu.UpdateComment(commentCtx, repoPath, item.IssueRef.ID, c.ID, markedBody)
+							return cu.UpdateComment(commentCtx, repoPath, issueID, c.ID, markedBody)
 						}
 					}
 				}
 			}
 		}
 		// No existing comment found (or provider doesn't support upsert) — create new.
-		return pa.Comment(commentCtx, repoPath, item.IssueRef.ID, markedBody)
+		return pa.Comment(commentCtx, repoPath, issueID, markedBody)
 	}
 
-	return pa.Comment(commentCtx, repoPath, item.IssueRef.ID, body)
+	return pa.Comment(commentCtx, repoPath, issueID, body)
 }
 
 // addLabel adds a label to the issue for a work item.
@@ -457,7 +579,15 @@ func (d *Daemon) addLabel(ctx context.Context, item daemonstate.WorkItem, params
 	labelCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
 	defer cancel()
 
-	return d.gitService.AddIssueLabel(labelCtx, repoPath, issueNum, label)
+	err = d.gitService.AddIssueLabel(labelCtx, repoPath, issueNum, label)
+	return d.bufferIfUnreachable(err, daemonstate.PendingOp{
+		Kind:       daemonstate.PendingAddLabel,
+		WorkItemID: item.ID,
+		RepoPath:   repoPath,
+		Source:     item.IssueRef.Source,
+		IssueID:    item.IssueRef.ID,
+		Label:      label,
+	})
 }
 
 // removeLabel removes a label from the issue for a work item.
@@ -486,7 +616,15 @@ func (d *Daemon) removeLabel(ctx context.Context, item daemonstate.WorkItem, par
 	labelCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
 	defer cancel()
 
-	return d.gitService.RemoveIssueLabel(labelCtx, repoPath, issueNum, label)
+	err = d.gitService.RemoveIssueLabel(labelCtx, repoPath, issueNum, label)
+	return d.bufferIfUnreachable(err, daemonstate.PendingOp{
+		Kind:       daemonstate.PendingRemoveLabel,
+		WorkItemID: item.ID,
+		RepoPath:   repoPath,
+		Source:     item.IssueRef.Source,
+		IssueID:    item.IssueRef.ID,
+		Label:      label,
+	})
 }
 
 // moveToSection moves an Asana task to a named section within its project.
@@ -617,6 +755,8 @@ func (d *Daemon) unqueueIssueWithSuffix(ctx context.Context, item daemonstate.Wo
 		body := issues.FormatUnqueuedCommentWithSuffix(src, reason, suffix)
 		if err := pa.Comment(opCtx, repoPath, item.IssueRef.ID, body); err != nil {
 			log.Debug("failed to comment during unqueue", "error", err)
+		} else {
+			d.auditComment(item.ID, body)
 		}
 	} else {
 		log.Debug("provider does not support ProviderActions, skipping comment")
@@ -652,6 +792,8 @@ func (d *Daemon) closeIssueGracefully(ctx context.Context, item daemonstate.Work
 				body := issues.FormatUnqueuedCommentWithSuffix(src, reason, "success")
 				if err := pa.Comment(opCtx, repoPath, item.IssueRef.ID, body); err != nil {
 					log.Debug("failed to comment during graceful close", "error", err)
+				} else {
+					d.auditComment(item.ID, body)
 				}
 			}
 		}
@@ -866,9 +1008,13 @@ func (d *Daemon) resolveRepoPath(ctx context.Context, item daemonstate.WorkItem)
 // issueFromWorkItem converts a WorkItem's issue ref to an issues.Issue.
 func issueFromWorkItem(item daemonstate.WorkItem) issues.Issue {
 	return issues.Issue{
-		ID:     item.IssueRef.ID,
-		Title:  item.IssueRef.Title,
-		URL:    item.IssueRef.URL,
-		Source: issues.Source(item.IssueRef.Source),
+		ID:        item.IssueRef.ID,
+		Title:     item.IssueRef.Title,
+		URL:       item.IssueRef.URL,
+		Source:    issues.Source(item.IssueRef.Source),
+		Priority:  item.IssueRef.Priority,
+		CreatedAt: item.IssueRef.CreatedAt,
+		Labels:    item.IssueRef.Labels,
+		DueAt:     item.IssueRef.DueAt,
 	}
 }
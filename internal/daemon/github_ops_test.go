@@ -3,11 +3,14 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/zhubert/erg/internal/config"
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/issues"
 	"github.com/zhubert/erg/internal/workflow"
 )
 
@@ -422,3 +425,71 @@ func TestRequestReview_NotYetRequested_AddsReviewer(t *testing.T) {
 		t.Fatalf("expected nil error, got: %v", err)
 	}
 }
+
+func TestMergePR_CompletesBacklogItem(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"pr", "merge"}, exec.MockResponse{Stdout: []byte("merged")})
+	cfg := testConfig()
+	d := testDaemonWithExec(cfg, mockExec)
+	d.issueRegistry = issues.NewProviderRegistry(issues.NewFileProvider())
+
+	repo := t.TempDir()
+	itemPath := filepath.Join(repo, issues.BacklogDir, "dark-mode.md")
+	if err := os.MkdirAll(filepath.Dir(itemPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(itemPath, []byte("# Add dark mode\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := testSession("sess-1")
+	sess.RepoPath = repo
+	cfg.AddSession(*sess)
+
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "wi-1",
+		IssueRef:  config.IssueRef{Source: "file", ID: "dark-mode"},
+		SessionID: "sess-1",
+		Branch:    sess.Branch,
+		StepData:  map[string]any{},
+	})
+
+	item, _ := d.state.GetWorkItem("wi-1")
+	if err := d.mergePR(context.Background(), item); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(itemPath); !os.IsNotExist(err) {
+		t.Errorf("expected backlog item moved out of backlog, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, issues.BacklogDoneDir, "dark-mode.md")); err != nil {
+		t.Errorf("expected backlog item in done/: %v", err)
+	}
+}
+
+func TestCompleteIssue_ClosesWhenPRCannotLink(t *testing.T) {
+	d := testDaemon(testConfig())
+	prov := issues.NewFakeProvider(issues.SourceAsana)
+	prov.SetNoPRLink(true)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	item := daemonstate.WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "asana", ID: "1234"}}
+	d.completeIssue(context.Background(), item, "/test/repo")
+
+	if len(prov.CloseIssueCalls) != 1 || prov.CloseIssueCalls[0].IssueID != "1234" {
+		t.Errorf("expected task 1234 to be closed, got %+v", prov.CloseIssueCalls)
+	}
+}
+
+func TestCompleteIssue_LeavesLinkedIssuesToPR(t *testing.T) {
+	d := testDaemon(testConfig())
+	prov := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	item := daemonstate.WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1"}}
+	d.completeIssue(context.Background(), item, "/test/repo")
+
+	if len(prov.CloseIssueCalls) != 0 {
+		t.Errorf("expected no close when the PR links the issue, got %+v", prov.CloseIssueCalls)
+	}
+}
@@ -64,14 +64,24 @@ func (d *Daemon) RecordSpend(costUSD float64, outputTokens, inputTokens int) {
 }
 
 // RecordItemSpend accumulates spend data on the work item associated with the
-// given session ID.
+// given session ID and records it in the ledger spend budgets are checked
+// against, under the model the session's runner is on.
 func (d *Daemon) RecordItemSpend(sessionID string, costUSD float64, outputTokens, inputTokens int) {
 	item, ok := d.state.GetWorkItemBySessionID(sessionID)
 	if !ok {
 		d.logger.Warn("RecordItemSpend: no work item found for session", "sessionID", sessionID)
 		return
 	}
+	model := ""
+	if runner := d.sessionMgr.GetRunner(sessionID); runner != nil {
+		model = runner.GetModel()
+	}
 	d.state.RecordItemSpend(item.ID, costUSD, outputTokens, inputTokens)
+	d.recordLedgerSpend(item, model, costUSD, outputTokens, inputTokens)
+	d.audit(item.ID, daemonstate.AuditSpend,
+		fmt.Sprintf("spent $%.4f (%d input, %d output tokens)", costUSD, inputTokens, outputTokens),
+		map[string]any{"cost_usd": costUSD, "input_tokens": inputTokens, "output_tokens": outputTokens, "session": sessionID, "model": model})
+	d.checkItemBudget(item.ID)
 }
 
 // SetWorkItemData stores a key-value pair in the work item's StepData
@@ -102,6 +112,17 @@ func (d *Daemon) CommentOnIssue(ctx context.Context, sessionID, body string) err
 // contains it, the comment is updated in place; otherwise a new comment is
 // created. When marker is empty, a new comment is always created.
 func (d *Daemon) UpsertIssueComment(ctx context.Context, sessionID, body, marker string) error {
+	if err := d.upsertIssueComment(ctx, sessionID, body, marker); err != nil {
+		return err
+	}
+	if item, ok := d.state.GetWorkItemBySessionID(sessionID); ok {
+		d.auditComment(item.ID, body)
+	}
+	return nil
+}
+
+// upsertIssueComment posts or updates the comment for UpsertIssueComment.
+func (d *Daemon) upsertIssueComment(ctx context.Context, sessionID, body, marker string) error {
 	sess, err := d.getSessionOrError(sessionID)
 	if err != nil {
 		return err
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/zhubert/erg/internal/exec"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/issuetest"
 	"github.com/zhubert/erg/internal/session"
 	"github.com/zhubert/erg/internal/worker"
 	"github.com/zhubert/erg/internal/workflow"
@@ -682,7 +684,7 @@ func TestIntegration_DockerRecovery_ResumesDockerPendingItems(t *testing.T) {
 	}
 }
 
-func TestIntegration_DockerDown_SkipsAllProcessing(t *testing.T) {
+func TestIntegration_DockerDown_QueuesOnly(t *testing.T) {
 	mockExec := exec.NewMockExecutor(nil)
 
 	addBaseGitMocks(t, mockExec, []git.GitHubIssue{
@@ -698,12 +700,18 @@ func TestIntegration_DockerDown_SkipsAllProcessing(t *testing.T) {
 		return errors.New("docker unavailable")
 	}
 
-	// Tick 1: Docker down → no polling, no starting
+	// Tick 1: Docker down → the issue is queued but not started
 	d.tick(ctx)
 
 	all := d.state.GetAllWorkItems()
-	if len(all) != 0 {
-		t.Errorf("expected 0 work items when Docker is down, got %d", len(all))
+	if len(all) != 1 {
+		t.Fatalf("expected 1 queued work item when Docker is down, got %d", len(all))
+	}
+	if all[0].State != daemonstate.WorkItemQueued {
+		t.Errorf("expected item to stay queued when Docker is down, got %s", all[0].State)
+	}
+	if tier, _ := d.state.GetDegradation(); tier != daemonstate.TierContainerDown {
+		t.Errorf("tier = %q, want %q", tier, daemonstate.TierContainerDown)
 	}
 }
 
@@ -1053,3 +1061,73 @@ func TestIntegration_DockerTransientBlip_ResumesDockerPendingItems(t *testing.T)
 		t.Error("item stuck in docker_pending — transient Docker blip not handled")
 	}
 }
+
+func TestIntegration_Scripted_LinearFakeServer(t *testing.T) {
+	// Drives the real Linear provider against a fake Linear API through a
+	// scripted sequence of tracker changes and daemon ticks.
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	linear := issuetest.NewLinear(t)
+
+	mockExec := exec.NewMockExecutor(nil)
+	addBaseGitMocks(t, mockExec, nil)
+	d, _ := newIntegrationDaemon(t, mockExec)
+	installMockRunnerFactory(t, d)
+	d.maxConcurrent = 5
+
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam("/test/repo", "team-1")
+	d.issueRegistry = issues.NewProviderRegistry(
+		issues.NewLinearProviderWithClient(linearCfg, linear.Client(), linear.URL()))
+	wfCfg := d.workflowConfigs["/test/repo"]
+	wfCfg.Source.Provider = string(issues.SourceLinear)
+	wfCfg.Source.Filter.Team = "team-1"
+	wfCfg.Source.Filter.Label = "ai-ready && !blocked"
+
+	ctx := context.Background()
+	issueIDs := func() []string {
+		var ids []string
+		for _, item := range d.state.GetAllWorkItems() {
+			ids = append(ids, item.IssueRef.ID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	issuetest.RunScript(t, func() { d.tick(ctx) },
+		issuetest.Step{
+			Name: "rate-limited poll picks up nothing",
+			Do: func() {
+				linear.AddIssue(issuetest.Issue{ID: "ENG-1", Title: "First", Labels: []string{"ai-ready"}})
+				linear.AddIssue(issuetest.Issue{ID: "ENG-2", Title: "Second", Labels: []string{"ai-ready"}})
+				linear.AddIssue(issuetest.Issue{ID: "ENG-3", Title: "Blocked", Labels: []string{"ai-ready", "blocked"}})
+				linear.AddIssue(issuetest.Issue{ID: "ENG-4", Title: "Untriaged"})
+				linear.SetPageSize(1)
+				linear.RateLimitNext(1, 30*time.Second)
+			},
+			Check: func(t testing.TB) {
+				if ids := issueIDs(); len(ids) != 0 {
+					t.Errorf("work items = %v, want none while rate limited", ids)
+				}
+			},
+		},
+		issuetest.Step{
+			Name: "paged issues matching the filter are picked up",
+			Check: func(t testing.TB) {
+				if ids := issueIDs(); !slices.Equal(ids, []string{"ENG-1", "ENG-2"}) {
+					t.Errorf("work items = %v, want [ENG-1 ENG-2]", ids)
+				}
+			},
+		},
+		issuetest.Step{
+			Name: "newly labeled issue is picked up",
+			Do: func() {
+				linear.AddIssue(issuetest.Issue{ID: "ENG-5", Title: "Late arrival", Labels: []string{"AI-Ready"}})
+			},
+			Check: func(t testing.TB) {
+				if ids := issueIDs(); !slices.Equal(ids, []string{"ENG-1", "ENG-2", "ENG-5"}) {
+					t.Errorf("work items = %v, want [ENG-1 ENG-2 ENG-5]", ids)
+				}
+			},
+		},
+	)
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestRepoKnowledge_RecordedAndInjected(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	d, _ := offlineTestDaemon(t)
+	item := daemonstate.WorkItem{
+		ID:       "/test/repo-ENG-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1"},
+		StepData: map[string]any{
+			workflow.ResultStepDataKey: workflow.StateResult{
+				Status:    workflow.ResultStatusSuccess,
+				Learnings: []string{"gotchas: integration tests need Docker", "conventions: wrap errors with %w"},
+			}.ToStepData(),
+		},
+	}
+
+	// Disabled by default: nothing is recorded or injected.
+	d.recordLearnings(item, "/test/repo")
+	if k, _ := daemonstate.LoadKnowledge("/test/repo"); !k.Empty() {
+		t.Fatal("expected no knowledge recorded while the knowledge base is disabled")
+	}
+	if got := d.withRepoKnowledge("/test/repo", "prompt"); got != "prompt" {
+		t.Errorf("expected prompt unchanged while disabled, got %q", got)
+	}
+
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{KnowledgeBase: &enabled}
+
+	if got := d.withRepoKnowledge("/test/repo", "prompt"); !strings.Contains(got, "No notes have been recorded yet") {
+		t.Errorf("expected empty knowledge base note, got %q", got)
+	}
+
+	d.recordLearnings(item, "/test/repo")
+	k, err := daemonstate.LoadKnowledge("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Sections[daemonstate.KnowledgeGotchas]) != 1 || len(k.Sections[daemonstate.KnowledgeConventions]) != 1 {
+		t.Fatalf("expected one gotcha and one convention, got %+v", k.Sections)
+	}
+
+	got := d.withRepoKnowledge("/test/repo", "prompt")
+	if !strings.HasPrefix(got, "prompt") || !strings.Contains(got, "integration tests need Docker") || !strings.Contains(got, "## Conventions") {
+		t.Errorf("expected recorded knowledge in prompt, got %q", got)
+	}
+}
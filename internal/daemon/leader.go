@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// DefaultLeaseTTL is how long a leader's lease lasts past each renewal,
+// and so about how long a standby waits to take over from a leader that
+// died.
+const DefaultLeaseTTL = 30 * time.Second
+
+// errLostLeadership stops a leader whose lease another instance took over,
+// or that could not renew it in time.
+var errLostLeadership = errors.New("lost leadership to a standby instance")
+
+// WithLease makes the daemon one of several instances, typically on
+// different hosts, that share leadership through a lease file in dir on
+// storage they all mount. Only the instance holding the lease polls and
+// works; the others stand by and take over, recovering in-flight work,
+// once it expires.
+func WithLease(dir string, ttl time.Duration) Option {
+	return func(d *Daemon) {
+		d.leaseDir = dir
+		d.leaseTTL = ttl
+	}
+}
+
+// awaitLeadership blocks until this instance holds the lease, standing by
+// while another instance leads. The hosts of the leaders it stood by for
+// are kept so their claims are honored as this daemon's own.
+func (d *Daemon) awaitLeadership(ctx context.Context) error {
+	host := claimHostname()
+	d.lease = daemonstate.NewLeaseFile(d.leaseDir, d.stateKey(), fmt.Sprintf("%s/%d", host, os.Getpid()), host, d.leaseTTL)
+
+	waitingOn := ""
+	if cur, err := d.lease.Current(); err == nil && cur.Host != "" && cur.Host != host {
+		waitingOn = cur.Host
+		d.leasePeers = append(d.leasePeers, cur.Host)
+	}
+	logged := false
+	for {
+		lease, held, err := d.lease.Acquire(time.Now())
+		switch {
+		case err != nil:
+			d.logger.Warn("failed to check leadership lease", "error", err)
+		case held:
+			if waitingOn != "" {
+				d.logger.Info("took over leadership", "event", "leader.takeover", "previousHost", waitingOn)
+			} else {
+				d.logger.Info("acquired leadership", "event", "leader.acquired")
+			}
+			return nil
+		case !logged || lease.Host != waitingOn:
+			logged = true
+			waitingOn = lease.Host
+			if lease.Host != host {
+				d.leasePeers = append(d.leasePeers, lease.Host)
+			}
+			d.logger.Info("standing by while another instance leads", "event", "leader.standby",
+				"leader", lease.Holder, "host", lease.Host, "expires", lease.Expires.Format(time.RFC3339))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.leaseTTL / 3):
+		}
+	}
+}
+
+// keepLeadership renews the lease until ctx ends. When another instance
+// has taken the lease, or it could not be renewed for two thirds of its
+// TTL (a standby may be about to take over), it stops the daemon through
+// stop so work is never done twice.
+func (d *Daemon) keepLeadership(ctx context.Context, stop context.CancelCauseFunc) {
+	ticker := time.NewTicker(d.leaseTTL / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		lease, held, err := d.lease.Acquire(now)
+		switch {
+		case err == nil && held:
+			renewed = now
+		case err == nil:
+			d.logger.Error("leadership taken over by another instance, stopping", "event", "leader.lost",
+				"leader", lease.Holder, "host", lease.Host)
+			stop(errLostLeadership)
+			return
+		case now.Sub(renewed) >= d.leaseTTL*2/3:
+			d.logger.Error("could not renew leadership lease in time, stopping", "event", "leader.lost", "error", err)
+			stop(fmt.Errorf("%w: %w", errLostLeadership, err))
+			return
+		default:
+			d.logger.Warn("failed to renew leadership lease", "error", err)
+		}
+	}
+}
+
+// adoptPeerClaims records the claim identities of the leaders this
+// instance took over from, so their claims on in-flight issues count as
+// this daemon's.
+func (d *Daemon) adoptPeerClaims() {
+	for _, host := range d.leasePeers {
+		d.state.AddClaimAlias(d.stateKey() + "@" + host)
+	}
+}
+
+// releaseLease gives up leadership on exit so a standby takes over at once.
+func (d *Daemon) releaseLease() {
+	if err := d.lease.Release(); err != nil {
+		d.logger.Warn("failed to release leadership lease", "error", err)
+	}
+}
@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+func leaseTestDaemon(t *testing.T) (*Daemon, string) {
+	t.Helper()
+	dir := t.TempDir()
+	d := testDaemon(testConfig())
+	d.repoFilter = "/test/repo"
+	WithLease(dir, 90*time.Millisecond)(d)
+	return d, dir
+}
+
+func TestAwaitLeadership_StandsByUntilLeaderStops(t *testing.T) {
+	d, dir := leaseTestDaemon(t)
+	leader := daemonstate.NewLeaseFile(dir, d.stateKey(), "host-a/1", "host-a", time.Minute)
+	if _, held, err := leader.Acquire(time.Now()); err != nil || !held {
+		t.Fatalf("leader failed to take the lease: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- d.awaitLeadership(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("expected to stand by while another instance leads")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	if err := leader.Release(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected to take over once the leader released the lease")
+	}
+
+	d.adoptPeerClaims()
+	if !d.isOwnClaim(d.stateKey() + "@host-a") {
+		t.Error("expected the former leader's claims adopted")
+	}
+}
+
+func TestKeepLeadership_StopsWhenLeaseTakenOver(t *testing.T) {
+	d, dir := leaseTestDaemon(t)
+	if err := d.awaitLeadership(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, stop := context.WithCancelCause(context.Background())
+	defer stop(nil)
+	go d.keepLeadership(ctx, stop)
+
+	// Another instance ends up holding the lease, e.g. after a partition.
+	os.Remove(daemonstate.LeaseFilePath(dir, d.stateKey()))
+	other := daemonstate.NewLeaseFile(dir, d.stateKey(), "host-b/2", "host-b", time.Minute)
+	if _, held, _ := other.Acquire(time.Now()); !held {
+		t.Fatal("other instance failed to take the lease")
+	}
+
+	select {
+	case <-ctx.Done():
+		if !errors.Is(context.Cause(ctx), errLostLeadership) {
+			t.Errorf("expected lost leadership, got %v", context.Cause(ctx))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the daemon stopped after losing its lease")
+	}
+}
+
+func TestKeepLeadership_RenewsLease(t *testing.T) {
+	d, dir := leaseTestDaemon(t)
+	if err := d.awaitLeadership(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancelCause(context.Background())
+	go d.keepLeadership(ctx, stop)
+
+	time.Sleep(200 * time.Millisecond)
+	other := daemonstate.NewLeaseFile(dir, d.stateKey(), "host-b/2", "host-b", time.Minute)
+	if _, held, _ := other.Acquire(time.Now()); held {
+		t.Error("expected the renewed lease kept from a standby")
+	}
+	stop(nil)
+	d.releaseLease()
+	if _, held, _ := other.Acquire(time.Now()); !held {
+		t.Error("expected the released lease free for a standby")
+	}
+}
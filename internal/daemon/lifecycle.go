@@ -21,15 +21,23 @@ func (d *Daemon) waitForActiveWorkers(ctx context.Context) {
 	}
 }
 
-// shutdown gracefully stops all workers and releases the lock.
+// shutdown gracefully stops all workers and releases the lock. Coding
+// sessions are checkpointed first (see checkpointForShutdown) so the next
+// start resumes them where they stopped.
 func (d *Daemon) shutdown() {
 	d.mu.Lock()
 	workers := make([]*worker.SessionWorker, 0, len(d.workers))
-	for _, w := range d.workers {
+	itemIDs := make([]string, 0, len(d.workers))
+	for id, w := range d.workers {
 		workers = append(workers, w)
+		itemIDs = append(itemIDs, id)
 	}
 	d.mu.Unlock()
 
+	// The caller's context is already cancelled; the checkpoints still need
+	// to reach the remote.
+	checkpoints := d.markShutdownCheckpoints(itemIDs)
+
 	d.logger.Info("shutting down workers", "count", len(workers))
 	for _, w := range workers {
 		w.Cancel()
@@ -50,6 +58,9 @@ func (d *Daemon) shutdown() {
 		d.logger.Warn("shutdown timed out")
 	}
 
+	for _, id := range checkpoints {
+		d.checkpointForShutdown(context.Background(), id)
+	}
 	d.saveState()
 	d.sessionMgr.Shutdown()
 }
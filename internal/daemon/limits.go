@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// WithGlobalLimits caps concurrent sessions per toolchain across all the
+// daemon's repos, on top of each repo's settings.limits.
+func WithGlobalLimits(l workflow.LimitsConfig) Option {
+	return func(d *Daemon) { d.globalLimits = l }
+}
+
+// repoToolchains returns the toolchains detected in repoPath, detecting
+// them on first use. Only called from the tick goroutine.
+func (d *Daemon) repoToolchains(ctx context.Context, repoPath string) []string {
+	if toolchains, ok := d.toolchains[repoPath]; ok {
+		return toolchains
+	}
+	var toolchains []string
+	if d.detectToolchains != nil {
+		toolchains = d.detectToolchains(ctx, repoPath)
+	} else {
+		for _, l := range container.Detect(ctx, repoPath) {
+			toolchains = append(toolchains, string(l.Lang))
+		}
+	}
+	if d.toolchains == nil {
+		d.toolchains = make(map[string][]string)
+	}
+	d.toolchains[repoPath] = toolchains
+	d.logger.Debug("detected toolchains", "repo", repoPath, "toolchains", toolchains)
+	return toolchains
+}
+
+// repoLimits returns repoPath's settings.limits, or nil when it has none.
+func (d *Daemon) repoLimits(repoPath string) workflow.LimitsConfig {
+	if wfCfg, ok := d.lookupWorkflowConfig(repoPath); ok {
+		return wfCfg.ConcurrencyLimits()
+	}
+	return nil
+}
+
+// toolchainLimit returns how many sessions needing toolchain may run at
+// once when started from repoPath: the tighter of the repo's and the
+// global limit, or 0 when neither limits it.
+func (d *Daemon) toolchainLimit(repoPath, toolchain string) int {
+	limit := d.repoLimits(repoPath).Toolchain(toolchain)
+	if g := d.globalLimits.Toolchain(toolchain); g > 0 && (limit <= 0 || g < limit) {
+		limit = g
+	}
+	return limit
+}
+
+// toolchainFull returns the first toolchain of repoPath whose limit its
+// running sessions, in any repo, already reach, or "" when a session may
+// start there.
+func (d *Daemon) toolchainFull(ctx context.Context, repoPath string) string {
+	if !d.repoLimits(repoPath).HasToolchainLimits() && !d.globalLimits.HasToolchainLimits() {
+		return ""
+	}
+	for _, toolchain := range d.repoToolchains(ctx, repoPath) {
+		limit := d.toolchainLimit(repoPath, toolchain)
+		if limit > 0 && d.toolchainSlotCount(ctx, toolchain) >= limit {
+			return toolchain
+		}
+	}
+	return ""
+}
+
+// toolchainSlotCount returns the number of work items consuming
+// concurrency slots in repos that use toolchain.
+func (d *Daemon) toolchainSlotCount(ctx context.Context, toolchain string) int {
+	count := 0
+	for _, item := range d.state.GetActiveWorkItems() {
+		if !item.ConsumesSlot() {
+			continue
+		}
+		for _, t := range d.repoToolchains(ctx, d.workItemRepoPath(item)) {
+			if t == toolchain {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestStartQueuedItems_SkipsToolchainAtLimit(t *testing.T) {
+	d, action := mutexTestDaemon(t, "")
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{Limits: workflow.LimitsConfig{"java": 1}}
+	d.workflowConfigs["/other/repo"] = d.workflowConfigs["/test/repo"]
+	d.workflowConfigs["/go/repo"] = d.workflowConfigs["/test/repo"]
+	d.engines["/other/repo"] = d.engines["/test/repo"]
+	d.engines["/go/repo"] = d.engines["/test/repo"]
+	detected := map[string][]string{"/test/repo": {"java"}, "/other/repo": {"java", "node"}, "/go/repo": {"go"}}
+	d.detectToolchains = func(_ context.Context, repoPath string) []string { return detected[repoPath] }
+
+	// A Java repo already runs a session.
+	addMutexItem(d, "running", "/test/repo", "async_pending")
+	d.state.UpdateWorkItem("running", func(it *daemonstate.WorkItem) { it.CurrentStep = "coding" })
+
+	for _, q := range []struct{ id, repo string }{{"a-java", "/other/repo"}, {"b-go", "/go/repo"}} {
+		d.state.AddWorkItem(&daemonstate.WorkItem{
+			ID:       q.id,
+			IssueRef: config.IssueRef{Source: "github", ID: q.id},
+			StepData: map[string]any{"_repo_path": q.repo},
+		})
+	}
+
+	d.startQueuedItems(context.Background())
+
+	if item, _ := d.state.GetWorkItem("a-java"); item.State != daemonstate.WorkItemQueued {
+		t.Errorf("expected the second Java item to stay queued, got %s", item.State)
+	}
+	if item, _ := d.state.GetWorkItem("b-go"); item.State == daemonstate.WorkItemQueued {
+		t.Error("expected the Go item to start")
+	}
+	if action.runs != 1 {
+		t.Errorf("expected one step run, got %d", action.runs)
+	}
+}
+
+func TestToolchainLimit(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{Settings: &workflow.SettingsConfig{
+		Limits: workflow.LimitsConfig{"java": 3, "default": 4},
+	}}
+	d.globalLimits = workflow.LimitsConfig{"java": 1, "rust": 2}
+
+	tests := []struct {
+		toolchain string
+		want      int
+	}{
+		{"java", 1}, // the global limit is tighter
+		{"rust", 2}, // tighter than the repo default
+		{"go", 4},   // repo default
+	}
+	for _, tt := range tests {
+		if got := d.toolchainLimit("/test/repo", tt.toolchain); got != tt.want {
+			t.Errorf("toolchainLimit(%s) = %d, want %d", tt.toolchain, got, tt.want)
+		}
+	}
+	if got := d.toolchainLimit("/unknown/repo", "go"); got != 0 {
+		t.Errorf("unlimited toolchain: got %d, want 0", got)
+	}
+}
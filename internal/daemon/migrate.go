@@ -0,0 +1,250 @@
+package daemon
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// MigrationAction is what happens to an in-flight work item when the
+// workflow config it started on is replaced.
+type MigrationAction string
+
+const (
+	// MigrateKeep leaves the item on its old workflow version.
+	MigrateKeep MigrationAction = "keep"
+	// MigrateWait defers the item until its running worker finishes.
+	MigrateWait MigrationAction = "wait"
+	// MigrateMove moves the item onto the current version.
+	MigrateMove MigrationAction = "move"
+	// MigrateFail fails the item.
+	MigrateFail MigrationAction = "fail"
+)
+
+// MigrationPlan is the outcome of migrating one work item.
+type MigrationPlan struct {
+	Action MigrationAction
+	Step   string // the step a moved item continues from
+	Reason string // why a failed item fails
+}
+
+// MigrationRules returns the policy and remap rules to migrate items onto
+// cfg with. A request from `erg workflow migrate` replaces the configured
+// policy (defaulting to remap, since it asks for items to be moved) and adds
+// its rules to the configured ones.
+func MigrationRules(cfg *workflow.Config, req *daemonstate.MigrationRequest) (string, map[string]string) {
+	policy := cfg.MigrationPolicy()
+	rules := maps.Clone(cfg.MigrationRemap())
+	if req != nil {
+		policy = cmp.Or(req.Policy, workflow.MigrationRemap)
+		if rules == nil {
+			rules = make(map[string]string)
+		}
+		maps.Copy(rules, req.Remap)
+	}
+	return policy, rules
+}
+
+// PlanMigration decides what happens to an item started on an earlier
+// workflow version when cfg becomes current. Items with a worker running
+// are left until it finishes, so a session is never pulled out from under
+// a step.
+func PlanMigration(item daemonstate.WorkItem, cfg *workflow.Config, policy string, rules map[string]string) MigrationPlan {
+	if policy == workflow.MigrationFinishOnOld {
+		return MigrationPlan{Action: MigrateKeep}
+	}
+	if item.Phase == "async_pending" || item.Phase == "addressing_feedback" || item.Phase == phasePreempted {
+		return MigrationPlan{Action: MigrateWait}
+	}
+	if policy == workflow.MigrationFail {
+		return MigrationPlan{Action: MigrateFail, Reason: "workflow config changed while the item was in flight (migration policy: fail)"}
+	}
+	step, ok := cfg.RemapStep(item.CurrentStep, rules)
+	if !ok {
+		return MigrationPlan{
+			Action: MigrateFail,
+			Step:   step,
+			Reason: fmt.Sprintf("workflow config changed and step %q no longer exists; add a settings.migration.remap rule for it", step),
+		}
+	}
+	return MigrationPlan{Action: MigrateMove, Step: step}
+}
+
+// workflowVersion returns the version of the repo's loaded workflow config.
+func (d *Daemon) workflowVersion(repoPath string) string {
+	d.workflowMu.RLock()
+	defer d.workflowMu.RUnlock()
+	return d.workflowVersions[repoPath]
+}
+
+// getItemEngine returns the engine for the workflow version an item is
+// running on: the engine of an earlier version while the item is still on
+// it, otherwise the current engine of its label-selected workflow, or of the
+// repo's main workflow.
+func (d *Daemon) getItemEngine(repoPath string, item daemonstate.WorkItem) *workflow.Engine {
+	d.workflowMu.RLock()
+	engine, ok := d.pinnedEngines[item.WorkflowVersion]
+	d.workflowMu.RUnlock()
+	if ok {
+		return engine
+	}
+	if nw, ok := d.lookupNamedWorkflow(repoPath, item.Workflow); ok {
+		return nw.engine
+	}
+	return d.getEngine(repoPath)
+}
+
+// reloadWorkflowConfigs picks up edits to each repo's workflow file and the
+// label-selected workflow files it names. A changed config that loads and
+// validates replaces the current one; the previous engines are kept for
+// items still on the old versions. Invalid edits
+// are logged once and ignored until fixed. In-flight items are then migrated
+// according to the migration policy, or to a pending `erg workflow migrate`
+// request.
+func (d *Daemon) reloadWorkflowConfigs(ctx context.Context) {
+	req := daemonstate.TakeMigrationRequest(d.stateKey())
+
+	for _, repoPath := range d.config.GetRepos() {
+		oldVersion := d.workflowVersion(repoPath)
+		if oldVersion == "" {
+			continue // not loaded from a workflow file
+		}
+		cfg, named, err := d.loadValidWorkflowConfig(repoPath)
+		if err != nil {
+			if d.workflowReloadErrors[repoPath] != err.Error() {
+				d.workflowReloadErrors[repoPath] = err.Error()
+				d.logger.Warn("ignoring changed workflow config until it is fixed", "repo", repoPath, "error", err)
+			}
+			continue
+		}
+		delete(d.workflowReloadErrors, repoPath)
+		oldNamed := d.namedWorkflowVersions(repoPath)
+		newNamed := make(map[string]string, len(named))
+		for name, namedCfg := range named {
+			newNamed[name] = workflow.HashConfig(namedCfg)
+		}
+		if workflow.HashConfig(cfg) == oldVersion && maps.Equal(oldNamed, newNamed) {
+			continue
+		}
+
+		oldEngine := d.getEngine(repoPath)
+		d.workflowMu.Lock()
+		d.pinnedEngines[oldVersion] = oldEngine
+		for _, nw := range d.namedWorkflows[repoPath] {
+			d.pinnedEngines[nw.version] = nw.engine
+		}
+		d.workflowMu.Unlock()
+		d.installWorkflowConfig(repoPath, cfg, named)
+		d.logger.Info("workflow config changed, reloaded",
+			"repo", repoPath, "from", oldVersion, "to", d.workflowVersion(repoPath),
+			"migrationPolicy", cfg.MigrationPolicy())
+	}
+
+	d.migrateWorkItems(ctx, req)
+}
+
+// loadValidWorkflowConfig loads the repo's workflow file and the
+// label-selected workflows it names, and validates them.
+func (d *Daemon) loadValidWorkflowConfig(repoPath string) (*workflow.Config, map[string]*workflow.Config, error) {
+	wfFile := d.getWorkflowFileForRepo(repoPath)
+	cfg, err := workflow.LoadAndMergeWithFile(repoPath, wfFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg == nil {
+		return nil, nil, errors.New("workflow file was removed")
+	}
+	if errs := workflow.Validate(cfg); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%s: %s", errs[0].Field, errs[0].Message)
+	}
+	named, err := workflow.LoadNamedWorkflows(repoPath, wfFile, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, namedCfg := range named {
+		if errs := workflow.Validate(namedCfg); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("workflow %q: %s: %s", name, errs[0].Field, errs[0].Message)
+		}
+	}
+	return cfg, named, nil
+}
+
+// namedWorkflowVersions returns the versions of the repo's loaded
+// label-selected workflows, keyed by name.
+func (d *Daemon) namedWorkflowVersions(repoPath string) map[string]string {
+	d.workflowMu.RLock()
+	defer d.workflowMu.RUnlock()
+	versions := make(map[string]string, len(d.namedWorkflows[repoPath]))
+	for name, nw := range d.namedWorkflows[repoPath] {
+		versions[name] = nw.version
+	}
+	return versions
+}
+
+// migrateWorkItems moves non-terminal items started on an earlier workflow
+// version onto the current version of their workflow, following
+// PlanMigration, and drops engines no item is running on any more. Items
+// whose label-selected workflow was removed move to the repo's main
+// workflow. Items from before versioning are stamped with the current
+// version.
+func (d *Daemon) migrateWorkItems(ctx context.Context, req *daemonstate.MigrationRequest) {
+	inUse := make(map[string]bool)
+	for _, item := range d.state.GetAllWorkItems() {
+		if item.IsTerminal() {
+			continue
+		}
+		repoPath := d.workItemRepoPath(item)
+		version := d.workflowVersion(repoPath)
+		cfg, ok := d.lookupWorkflowConfig(repoPath)
+		name := ""
+		if nw, found := d.lookupNamedWorkflow(repoPath, item.Workflow); found {
+			cfg, version, name = nw.engine.GetConfig(), nw.version, item.Workflow
+		}
+		if !ok || version == "" || (item.WorkflowVersion == version && item.Workflow == name) {
+			continue
+		}
+		if item.WorkflowVersion == "" {
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				it.WorkflowVersion = version
+				it.Workflow = name
+			})
+			continue
+		}
+
+		log := d.logger.With("workItem", item.ID, "step", item.CurrentStep, "from", item.WorkflowVersion, "to", version)
+		policy, rules := MigrationRules(cfg, req)
+		plan := PlanMigration(item, cfg, policy, rules)
+		switch plan.Action {
+		case MigrateKeep, MigrateWait:
+			inUse[item.WorkflowVersion] = true
+		case MigrateMove:
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				it.CurrentStep = plan.Step
+				if state := cfg.States[plan.Step]; state != nil {
+					it.StepDisplayName = state.DisplayName
+				}
+				it.WorkflowVersion = version
+				it.Workflow = name
+			})
+			log.Info("migrated work item to new workflow version", "newStep", plan.Step)
+		case MigrateFail:
+			log.Warn("failing work item after workflow change", "reason", plan.Reason)
+			d.state.SetErrorMessage(item.ID, plan.Reason)
+			d.postTerminalMarker(ctx, item.ID, false)
+			d.state.MarkWorkItemTerminal(item.ID, false)
+		}
+	}
+
+	d.workflowMu.Lock()
+	defer d.workflowMu.Unlock()
+	for version := range d.pinnedEngines {
+		if !inUse[version] {
+			delete(d.pinnedEngines, version)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+const migrateWorkflowV1 = `source:
+  provider: github
+  filter:
+    label: queued
+start: coding
+states:
+  coding:
+    type: task
+    action: exec.run
+    params:
+      command: make
+    next: lint
+  lint:
+    type: task
+    action: exec.run
+    params:
+      command: make lint
+    next: review
+  review:
+    type: pass
+    next: done
+`
+
+const migrateWorkflowV2 = `source:
+  provider: github
+  filter:
+    label: queued
+start: coding
+settings:
+  migration:
+    policy: %s
+    remap:
+      review: check
+states:
+  coding:
+    type: task
+    action: exec.run
+    params:
+      command: make
+    next: check
+  check:
+    type: pass
+    next: done
+`
+
+// migrateTestDaemon creates a daemon managing a repo whose workflow file is
+// at version 1, with configs loaded.
+func migrateTestDaemon(t *testing.T) (*Daemon, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+	repoDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoDir, ".erg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeMigrateWorkflow(t, repoDir, migrateWorkflowV1)
+
+	cfg := testConfig()
+	cfg.AddRepo(repoDir)
+	d := testDaemon(cfg)
+	d.repoFilter = repoDir
+	d.loadWorkflowConfigs()
+	return d, repoDir
+}
+
+func writeMigrateWorkflow(t *testing.T, repoDir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoDir, ".erg", "workflow.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addMigrateItem adds an active work item at step on the repo's current
+// workflow version.
+func addMigrateItem(d *Daemon, repoDir, id, step, phase string) {
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:              id,
+		IssueRef:        config.IssueRef{Source: "github", ID: id},
+		CurrentStep:     step,
+		WorkflowVersion: d.workflowVersion(repoDir),
+		StepData:        map[string]any{"_repo_path": repoDir},
+	})
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+		it.Phase = phase
+	})
+}
+
+func TestPlanMigration(t *testing.T) {
+	cfg := &workflow.Config{States: map[string]*workflow.State{
+		"coding": {Type: workflow.StateTypeTask},
+		"check":  {Type: workflow.StateTypePass},
+	}}
+	rules := map[string]string{"review": "check", "lint": "gone"}
+
+	tests := []struct {
+		name   string
+		step   string
+		phase  string
+		policy string
+		want   MigrationPlan
+	}{
+		{"finish on old", "review", "idle", workflow.MigrationFinishOnOld, MigrationPlan{Action: MigrateKeep}},
+		{"busy waits", "review", "async_pending", workflow.MigrationRemap, MigrationPlan{Action: MigrateWait}},
+		{"same step", "coding", "idle", workflow.MigrationRemap, MigrationPlan{Action: MigrateMove, Step: "coding"}},
+		{"remapped step", "review", "idle", workflow.MigrationRemap, MigrationPlan{Action: MigrateMove, Step: "check"}},
+		{"not started", "", "idle", workflow.MigrationRemap, MigrationPlan{Action: MigrateMove}},
+		{"missing step", "deploy", "idle", workflow.MigrationRemap, MigrationPlan{Action: MigrateFail, Step: "deploy"}},
+		{"missing rule target", "lint", "idle", workflow.MigrationRemap, MigrationPlan{Action: MigrateFail, Step: "gone"}},
+		{"fail policy", "coding", "idle", workflow.MigrationFail, MigrationPlan{Action: MigrateFail}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := daemonstate.WorkItem{CurrentStep: tt.step, Phase: tt.phase}
+			got := PlanMigration(item, cfg, tt.policy, rules)
+			if got.Action != tt.want.Action || got.Step != tt.want.Step {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.Action == MigrateFail && got.Reason == "" {
+				t.Error("failed plan should give a reason")
+			}
+		})
+	}
+}
+
+func TestMigrationRules(t *testing.T) {
+	cfg := &workflow.Config{Settings: &workflow.SettingsConfig{Migration: &workflow.MigrationConfig{
+		Policy: workflow.MigrationFinishOnOld,
+		Remap:  map[string]string{"a": "b"},
+	}}}
+
+	policy, rules := MigrationRules(cfg, nil)
+	if policy != workflow.MigrationFinishOnOld || rules["a"] != "b" {
+		t.Errorf("configured rules: got %q %v", policy, rules)
+	}
+
+	policy, rules = MigrationRules(cfg, &daemonstate.MigrationRequest{Remap: map[string]string{"c": "d"}})
+	if policy != workflow.MigrationRemap || rules["a"] != "b" || rules["c"] != "d" {
+		t.Errorf("request rules: got %q %v", policy, rules)
+	}
+	if _, ok := cfg.Settings.Migration.Remap["c"]; ok {
+		t.Error("request rules must not modify the config")
+	}
+
+	if policy, _ := MigrationRules(&workflow.Config{}, nil); policy != workflow.MigrationRemap {
+		t.Errorf("default policy: got %q", policy)
+	}
+}
+
+func TestReloadWorkflowConfigs_Remap(t *testing.T) {
+	d, repoDir := migrateTestDaemon(t)
+	oldVersion := d.workflowVersion(repoDir)
+
+	addMigrateItem(d, repoDir, "same", "coding", "idle")
+	addMigrateItem(d, repoDir, "renamed", "review", "idle")
+	addMigrateItem(d, repoDir, "removed", "lint", "idle")
+	addMigrateItem(d, repoDir, "busy", "lint", "async_pending")
+
+	writeMigrateWorkflow(t, repoDir, strings.Replace(migrateWorkflowV2, "%s", "remap", 1))
+	d.reloadWorkflowConfigs(context.Background())
+
+	newVersion := d.workflowVersion(repoDir)
+	if newVersion == oldVersion {
+		t.Fatal("expected workflow version to change")
+	}
+	if _, ok := d.getWorkflowConfig(repoDir).States["check"]; !ok {
+		t.Fatal("expected new config to be loaded")
+	}
+
+	same, _ := d.state.GetWorkItem("same")
+	if same.CurrentStep != "coding" || same.WorkflowVersion != newVersion {
+		t.Errorf("same: got step %q version %q", same.CurrentStep, same.WorkflowVersion)
+	}
+	renamed, _ := d.state.GetWorkItem("renamed")
+	if renamed.CurrentStep != "check" || renamed.WorkflowVersion != newVersion {
+		t.Errorf("renamed: got step %q version %q", renamed.CurrentStep, renamed.WorkflowVersion)
+	}
+	removed, _ := d.state.GetWorkItem("removed")
+	if removed.State != daemonstate.WorkItemFailed || !strings.Contains(removed.ErrorMessage, `"lint" no longer exists`) {
+		t.Errorf("removed: got state %q error %q", removed.State, removed.ErrorMessage)
+	}
+
+	// The busy item stays on the old engine until its worker finishes.
+	busy, _ := d.state.GetWorkItem("busy")
+	if busy.IsTerminal() || busy.WorkflowVersion != oldVersion {
+		t.Errorf("busy: got state %q version %q", busy.State, busy.WorkflowVersion)
+	}
+	if d.getItemEngine(repoDir, busy).GetState("lint") == nil {
+		t.Error("busy item should still run on the old engine")
+	}
+
+	d.state.UpdateWorkItem("busy", func(it *daemonstate.WorkItem) { it.Phase = "idle" })
+	d.reloadWorkflowConfigs(context.Background())
+	busy, _ = d.state.GetWorkItem("busy")
+	if busy.State != daemonstate.WorkItemFailed {
+		t.Errorf("busy item should fail once idle, got state %q", busy.State)
+	}
+	if len(d.pinnedEngines) != 0 {
+		t.Errorf("expected old engine dropped, got %d pinned", len(d.pinnedEngines))
+	}
+}
+
+func TestReloadWorkflowConfigs_FinishOnOldThenMigrate(t *testing.T) {
+	d, repoDir := migrateTestDaemon(t)
+	oldVersion := d.workflowVersion(repoDir)
+	addMigrateItem(d, repoDir, "item", "review", "idle")
+
+	writeMigrateWorkflow(t, repoDir, strings.Replace(migrateWorkflowV2, "%s", "finish-on-old", 1))
+	d.reloadWorkflowConfigs(context.Background())
+
+	item, _ := d.state.GetWorkItem("item")
+	if item.CurrentStep != "review" || item.WorkflowVersion != oldVersion {
+		t.Fatalf("item should stay on old version, got step %q version %q", item.CurrentStep, item.WorkflowVersion)
+	}
+	if d.getItemEngine(repoDir, item).GetState("review") == nil {
+		t.Error("item should run on the old engine")
+	}
+	if d.getEngine(repoDir).GetState("review") != nil {
+		t.Error("repo's current engine should be the new version")
+	}
+
+	// erg workflow migrate moves it with the configured remap rules.
+	if err := daemonstate.WriteMigrationRequest(d.stateKey(), daemonstate.MigrationRequest{At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	d.reloadWorkflowConfigs(context.Background())
+
+	item, _ = d.state.GetWorkItem("item")
+	if item.CurrentStep != "check" || item.WorkflowVersion != d.workflowVersion(repoDir) {
+		t.Errorf("item should be migrated, got step %q version %q", item.CurrentStep, item.WorkflowVersion)
+	}
+	if len(d.pinnedEngines) != 0 {
+		t.Errorf("expected old engine dropped, got %d pinned", len(d.pinnedEngines))
+	}
+}
+
+func TestReloadWorkflowConfigs_InvalidEditIgnored(t *testing.T) {
+	d, repoDir := migrateTestDaemon(t)
+	version := d.workflowVersion(repoDir)
+	addMigrateItem(d, repoDir, "item", "lint", "idle")
+
+	writeMigrateWorkflow(t, repoDir, strings.Replace(migrateWorkflowV1, "next: review", "next: nowhere", 1))
+	d.reloadWorkflowConfigs(context.Background())
+
+	if d.workflowVersion(repoDir) != version {
+		t.Error("invalid config should not be loaded")
+	}
+	if d.workflowReloadErrors[repoDir] == "" {
+		t.Error("expected reload error to be recorded")
+	}
+	if item, _ := d.state.GetWorkItem("item"); item.IsTerminal() || item.WorkflowVersion != version {
+		t.Errorf("item should be untouched, got %+v", item)
+	}
+}
+
+func TestMigrateWorkItems_StampsUnversionedItems(t *testing.T) {
+	d, repoDir := migrateTestDaemon(t)
+	addMigrateItem(d, repoDir, "legacy", "lint", "idle")
+	d.state.UpdateWorkItem("legacy", func(it *daemonstate.WorkItem) { it.WorkflowVersion = "" })
+
+	d.migrateWorkItems(context.Background(), nil)
+
+	if item, _ := d.state.GetWorkItem("legacy"); item.WorkflowVersion != d.workflowVersion(repoDir) || item.CurrentStep != "lint" {
+		t.Errorf("legacy item should be stamped in place, got step %q version %q", item.CurrentStep, item.WorkflowVersion)
+	}
+}
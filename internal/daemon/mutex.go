@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// phaseMutexWait marks a work item parked before a task state whose mutex
+// group another item in the same repo holds. It holds no slot and enters the
+// state once the group is free.
+const phaseMutexWait = "mutex_wait"
+
+// heldMutexes returns the mutex groups an active work item holds: its
+// workflow's group for as long as it runs, and its current state's group
+// unless it is still waiting to enter that state.
+func (d *Daemon) heldMutexes(repoPath string, item daemonstate.WorkItem) []string {
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
+	if wfCfg == nil {
+		return nil
+	}
+	var groups []string
+	if wfCfg.Mutex != "" {
+		groups = append(groups, wfCfg.Mutex)
+	}
+	if item.Phase != phaseMutexWait {
+		if state := wfCfg.States[item.CurrentStep]; state != nil && state.Mutex != "" {
+			groups = append(groups, state.Mutex)
+		}
+	}
+	return groups
+}
+
+// mutexHolder returns the ID of another active work item in repoPath that
+// holds group, or "" when the group is free.
+func (d *Daemon) mutexHolder(ctx context.Context, repoPath, selfID, group string) string {
+	if group == "" {
+		return ""
+	}
+	for _, other := range d.state.GetActiveWorkItems() {
+		if other.ID == selfID || d.resolveRepoPath(ctx, other) != repoPath {
+			continue
+		}
+		if slices.Contains(d.heldMutexes(repoPath, other), group) {
+			return other.ID
+		}
+	}
+	return ""
+}
+
+// workflowMutexHeld reports whether a queued item must stay queued because
+// another item in its repo holds its workflow's mutex group.
+func (d *Daemon) workflowMutexHeld(ctx context.Context, repoPath string, item daemonstate.WorkItem) bool {
+	wfCfg := d.getItemWorkflowConfig(repoPath, item)
+	if wfCfg == nil || wfCfg.Mutex == "" {
+		return false
+	}
+	holder := d.mutexHolder(ctx, repoPath, item.ID, wfCfg.Mutex)
+	if holder == "" {
+		return false
+	}
+	d.logger.Debug("workflow mutex held, leaving item queued",
+		"workItem", item.ID, "mutex", wfCfg.Mutex, "holder", holder)
+	return true
+}
+
+// holdForMutex reports whether the sync chain must stop because the item's
+// current step declares a mutex group another item in the repo holds. The
+// item is parked until processMutexWaitItems finds the group free.
+func (d *Daemon) holdForMutex(ctx context.Context, item daemonstate.WorkItem, engine *workflow.Engine) bool {
+	state := engine.GetState(item.CurrentStep)
+	if state == nil || state.Type != workflow.StateTypeTask || state.Mutex == "" {
+		return false
+	}
+	holder := d.mutexHolder(ctx, d.resolveRepoPath(ctx, item), item.ID, state.Mutex)
+	if holder == "" {
+		return false
+	}
+
+	now := time.Now()
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_mutex_holder"] = holder
+		it.Phase = phaseMutexWait
+		it.UpdatedAt = now
+	})
+	d.logger.Info("mutex held by another work item, waiting", "event", "mutex.wait",
+		"workItem", item.ID, "step", item.CurrentStep, "mutex", state.Mutex, "holder", holder)
+	return true
+}
+
+// processMutexWaitItems resumes items parked by holdForMutex whose group is
+// now free, longest waiting first. Resuming an item makes it the holder, so
+// at most one waiter per group enters its state each tick.
+func (d *Daemon) processMutexWaitItems(ctx context.Context) {
+	var waiting []daemonstate.WorkItem
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase == phaseMutexWait {
+			waiting = append(waiting, item)
+		}
+	}
+	slices.SortStableFunc(waiting, func(a, b daemonstate.WorkItem) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+
+	maxConcurrent := d.getMaxConcurrent()
+	for _, item := range waiting {
+		if d.activeSlotCount() >= maxConcurrent {
+			return
+		}
+		repoPath := d.resolveRepoPath(ctx, item)
+		engine := d.getItemEngine(repoPath, item)
+		if engine == nil {
+			continue
+		}
+		var group string
+		if state := engine.GetState(item.CurrentStep); state != nil {
+			group = state.Mutex
+		}
+		if d.mutexHolder(ctx, repoPath, item.ID, group) != "" {
+			continue
+		}
+
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, "_mutex_holder")
+		})
+		d.logger.Info("mutex acquired", "event", "mutex.acquired",
+			"workItem", item.ID, "step", item.CurrentStep, "mutex", group)
+		d.state.AdvanceWorkItem(item.ID, item.CurrentStep, "idle")
+		d.executeSyncChain(ctx, item.ID, engine)
+	}
+}
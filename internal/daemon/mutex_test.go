@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// mutexTestDaemon returns a daemon whose workflow runs a migrate step in the
+// db-migrations mutex group and then succeeds. The migrate action is replaced
+// by a counter so tests can see whether it ran.
+func mutexTestDaemon(t *testing.T, workflowMutex string) (*Daemon, *countingAction) {
+	t.Helper()
+	cfg := testConfig()
+	d := testDaemon(cfg)
+
+	wfCfg := &workflow.Config{
+		Start:  "migrate",
+		Mutex:  workflowMutex,
+		Source: workflow.SourceConfig{Provider: "github"},
+		States: map[string]*workflow.State{
+			"migrate": {Type: workflow.StateTypeTask, Action: "exec.run", Next: "done", Mutex: "db-migrations"},
+			"done":    {Type: workflow.StateTypeSucceed},
+		},
+	}
+	action := &countingAction{}
+	reg := d.buildActionRegistry()
+	reg.Register("exec.run", action)
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, reg, newEventChecker(d), d.logger)
+	return d, action
+}
+
+// addMutexItem adds an active work item at the migrate step of repoPath.
+func addMutexItem(d *Daemon, id, repoPath, phase string) {
+	sess := testSession("sess-" + id)
+	sess.RepoPath = repoPath
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          id,
+		IssueRef:    config.IssueRef{Source: "github", ID: id},
+		SessionID:   sess.ID,
+		CurrentStep: "migrate",
+		StepData:    map[string]any{},
+	})
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+		it.Phase = phase
+	})
+}
+
+func TestMutex_WaitsForHolder(t *testing.T) {
+	d, action := mutexTestDaemon(t, "")
+	ctx := context.Background()
+	addMutexItem(d, "holder", "/test/repo", "async_pending")
+	addMutexItem(d, "waiter", "/test/repo", "idle")
+
+	d.executeSyncChain(ctx, "waiter", d.engines["/test/repo"])
+
+	if action.runs != 0 {
+		t.Fatalf("mutex step ran %d times while the group was held", action.runs)
+	}
+	waiter, _ := d.state.GetWorkItem("waiter")
+	if waiter.Phase != phaseMutexWait || waiter.StepData["_mutex_holder"] != "holder" {
+		t.Fatalf("expected waiter parked behind holder, got phase %q data %v", waiter.Phase, waiter.StepData)
+	}
+	if waiter.ConsumesSlot() {
+		t.Error("a waiting item should not hold a slot")
+	}
+
+	// Still held: nothing happens.
+	d.processMutexWaitItems(ctx)
+	if action.runs != 0 {
+		t.Fatal("waiter entered the step while the group was held")
+	}
+
+	// The holder leaves the step, releasing the group.
+	d.state.MarkWorkItemTerminal("holder", true)
+	d.processMutexWaitItems(ctx)
+
+	if action.runs != 1 {
+		t.Fatalf("mutex step ran %d times after release, want 1", action.runs)
+	}
+	waiter, _ = d.state.GetWorkItem("waiter")
+	if waiter.State != daemonstate.WorkItemCompleted {
+		t.Errorf("State = %q, want completed", waiter.State)
+	}
+	if _, ok := waiter.StepData["_mutex_holder"]; ok {
+		t.Error("expected _mutex_holder to be cleared")
+	}
+}
+
+func TestMutex_WaitingItemsDoNotHoldGroup(t *testing.T) {
+	d, action := mutexTestDaemon(t, "")
+	addMutexItem(d, "a", "/test/repo", phaseMutexWait)
+	addMutexItem(d, "b", "/test/repo", "idle")
+
+	d.executeSyncChain(context.Background(), "b", d.engines["/test/repo"])
+
+	if action.runs != 1 {
+		t.Errorf("expected b to enter the step past a waiting item, ran %d times", action.runs)
+	}
+}
+
+func TestMutex_OtherRepoDoesNotConflict(t *testing.T) {
+	d, action := mutexTestDaemon(t, "")
+	addMutexItem(d, "holder", "/test/repo", "async_pending")
+	addMutexItem(d, "other", "/test/other", "idle")
+	d.workflowConfigs["/test/other"] = d.workflowConfigs["/test/repo"]
+
+	d.executeSyncChain(context.Background(), "other", d.engines["/test/repo"])
+
+	if action.runs != 1 {
+		t.Errorf("expected item in another repo to run, ran %d times", action.runs)
+	}
+}
+
+func TestMutex_WorkflowMutexKeepsItemQueued(t *testing.T) {
+	d, _ := mutexTestDaemon(t, "deploy")
+	ctx := context.Background()
+	addMutexItem(d, "running", "/test/repo", "async_pending")
+	d.state.UpdateWorkItem("running", func(it *daemonstate.WorkItem) { it.CurrentStep = "done" })
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "queued",
+		IssueRef: config.IssueRef{Source: "github", ID: "queued"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+
+	d.startQueuedItems(ctx)
+
+	queued, _ := d.state.GetWorkItem("queued")
+	if queued.State != daemonstate.WorkItemQueued {
+		t.Fatalf("expected item to stay queued while the workflow mutex is held, got %q", queued.State)
+	}
+
+	d.state.MarkWorkItemTerminal("running", true)
+	d.startQueuedItems(ctx)
+
+	queued, _ = d.state.GetWorkItem("queued")
+	if queued.State == daemonstate.WorkItemQueued {
+		t.Error("expected item to start once the workflow mutex was released")
+	}
+}
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// namedWorkflow is the engine and version of one of a repo's label-selected
+// workflows (the workflows: list in its main workflow file).
+type namedWorkflow struct {
+	engine  *workflow.Engine
+	version string
+}
+
+// lookupNamedWorkflow returns the repo's label-selected workflow with the
+// given name, if it is loaded.
+func (d *Daemon) lookupNamedWorkflow(repoPath, name string) (namedWorkflow, bool) {
+	if name == "" {
+		return namedWorkflow{}, false
+	}
+	d.workflowMu.RLock()
+	defer d.workflowMu.RUnlock()
+	nw, ok := d.namedWorkflows[repoPath][name]
+	return nw, ok
+}
+
+// selectWorkflow returns the name of the workflow an issue with the given
+// labels runs on, or "" for the repo's main workflow. A workflow whose file
+// failed to load is never selected.
+func (d *Daemon) selectWorkflow(repoPath string, labels []string) string {
+	cfg, ok := d.lookupWorkflowConfig(repoPath)
+	if !ok {
+		return ""
+	}
+	name := cfg.WorkflowForLabels(labels)
+	if _, ok := d.lookupNamedWorkflow(repoPath, name); !ok {
+		return ""
+	}
+	return name
+}
+
+// workflowVersionFor returns the version of the named workflow, or of the
+// repo's main workflow when name is "" or not loaded.
+func (d *Daemon) workflowVersionFor(repoPath, name string) string {
+	if nw, ok := d.lookupNamedWorkflow(repoPath, name); ok {
+		return nw.version
+	}
+	return d.workflowVersion(repoPath)
+}
+
+// getItemWorkflowConfig returns the config of the workflow an item is
+// running on. Step params for an item must be read from here rather than
+// from the repo's main config, which may not be the one it runs on.
+func (d *Daemon) getItemWorkflowConfig(repoPath string, item daemonstate.WorkItem) *workflow.Config {
+	d.workflowMu.RLock()
+	engine, ok := d.pinnedEngines[item.WorkflowVersion]
+	d.workflowMu.RUnlock()
+	if ok {
+		return engine.GetConfig()
+	}
+	if nw, ok := d.lookupNamedWorkflow(repoPath, item.Workflow); ok {
+		return nw.engine.GetConfig()
+	}
+	return d.getWorkflowConfig(repoPath)
+}
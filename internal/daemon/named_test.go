@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+const namedWorkflowMain = migrateWorkflowV1 + `workflows:
+  - name: docs
+    file: docs.yaml
+    labels: [docs]
+`
+
+const namedWorkflowDocs = `start: coding
+states:
+  coding:
+    type: task
+    action: exec.run
+    params:
+      command: %s
+    next: done
+`
+
+func writeDocsWorkflow(t *testing.T, repoDir, command string) {
+	t.Helper()
+	content := []byte(fmt.Sprintf(namedWorkflowDocs, command))
+	if err := os.WriteFile(filepath.Join(repoDir, ".erg", "docs.yaml"), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// namedTestDaemon creates a daemon whose repo routes docs-labelled issues to
+// a separate docs workflow.
+func namedTestDaemon(t *testing.T) (*Daemon, string) {
+	t.Helper()
+	d, repoDir := migrateTestDaemon(t)
+	writeMigrateWorkflow(t, repoDir, namedWorkflowMain)
+	writeDocsWorkflow(t, repoDir, "make docs")
+	d.loadWorkflowConfigs()
+	return d, repoDir
+}
+
+func TestQueueIssue_SelectsWorkflowByLabel(t *testing.T) {
+	d, repoDir := namedTestDaemon(t)
+
+	d.queueIssue(repoDir, issues.Issue{ID: "1", Title: "Fix typo", Labels: []string{"queued", "Docs"}}, issues.SourceGitHub, false)
+	d.queueIssue(repoDir, issues.Issue{ID: "2", Title: "Add feature", Labels: []string{"queued"}}, issues.SourceGitHub, false)
+
+	docs, _ := d.state.GetWorkItem(repoDir + "-1")
+	if docs.Workflow != "docs" {
+		t.Fatalf("expected docs workflow, got %q", docs.Workflow)
+	}
+	if docs.WorkflowVersion != d.workflowVersionFor(repoDir, "docs") || docs.WorkflowVersion == d.workflowVersion(repoDir) {
+		t.Errorf("expected docs workflow version, got %q", docs.WorkflowVersion)
+	}
+	if got := d.getItemEngine(repoDir, docs).GetState("coding").Params["command"]; got != "make docs" {
+		t.Errorf("expected docs engine, got command %v", got)
+	}
+	if got := d.getItemWorkflowConfig(repoDir, docs).States["coding"].Params["command"]; got != "make docs" {
+		t.Errorf("expected docs config, got command %v", got)
+	}
+	if d.getItemWorkflowConfig(repoDir, docs).Source.Provider != "github" {
+		t.Error("expected docs workflow to take the main source")
+	}
+
+	main, _ := d.state.GetWorkItem(repoDir + "-2")
+	if main.Workflow != "" || main.WorkflowVersion != d.workflowVersion(repoDir) {
+		t.Errorf("expected main workflow, got %q version %q", main.Workflow, main.WorkflowVersion)
+	}
+	if d.getItemEngine(repoDir, main).GetState("lint") == nil {
+		t.Error("expected main engine for unlabelled issue")
+	}
+}
+
+func TestSelectWorkflow_UnloadedWorkflowFallsBack(t *testing.T) {
+	d, repoDir := migrateTestDaemon(t)
+	writeMigrateWorkflow(t, repoDir, namedWorkflowMain) // docs.yaml is missing
+	d.loadWorkflowConfigs()
+
+	if got := d.selectWorkflow(repoDir, []string{"docs"}); got != "" {
+		t.Errorf("expected main workflow when docs.yaml fails to load, got %q", got)
+	}
+}
+
+func TestReloadWorkflowConfigs_NamedWorkflow(t *testing.T) {
+	d, repoDir := namedTestDaemon(t)
+	d.queueIssue(repoDir, issues.Issue{ID: "1", Labels: []string{"docs"}}, issues.SourceGitHub, false)
+	id := repoDir + "-1"
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) { it.CurrentStep = "coding" })
+	oldVersion := d.workflowVersionFor(repoDir, "docs")
+	mainVersion := d.workflowVersion(repoDir)
+
+	// Editing only the named workflow file is picked up and migrates its items.
+	writeDocsWorkflow(t, repoDir, "make site")
+	d.reloadWorkflowConfigs(context.Background())
+
+	newVersion := d.workflowVersionFor(repoDir, "docs")
+	if newVersion == oldVersion {
+		t.Fatal("expected docs workflow version to change")
+	}
+	if d.workflowVersion(repoDir) != mainVersion {
+		t.Error("main workflow version should not change")
+	}
+	item, _ := d.state.GetWorkItem(id)
+	if item.Workflow != "docs" || item.WorkflowVersion != newVersion {
+		t.Errorf("expected item on new docs version, got %q %q", item.Workflow, item.WorkflowVersion)
+	}
+
+	// Removing the named workflow moves its items to the main workflow.
+	writeMigrateWorkflow(t, repoDir, migrateWorkflowV1)
+	d.reloadWorkflowConfigs(context.Background())
+
+	item, _ = d.state.GetWorkItem(id)
+	if item.Workflow != "" || item.WorkflowVersion != d.workflowVersion(repoDir) || item.CurrentStep != "coding" {
+		t.Errorf("expected item on main workflow, got %q %q at %q", item.Workflow, item.WorkflowVersion, item.CurrentStep)
+	}
+	if len(d.pinnedEngines) != 0 {
+		t.Errorf("expected old engines dropped, got %d pinned", len(d.pinnedEngines))
+	}
+}
+
+// addNamedWorkflow loads cfg as the repo's label-selected workflow name.
+func addNamedWorkflow(t *testing.T, d *Daemon, repoPath, name string, cfg *workflow.Config) {
+	t.Helper()
+	if d.namedWorkflows == nil {
+		d.namedWorkflows = make(map[string]map[string]namedWorkflow)
+	}
+	if d.namedWorkflows[repoPath] == nil {
+		d.namedWorkflows[repoPath] = make(map[string]namedWorkflow)
+	}
+	d.namedWorkflows[repoPath][name] = namedWorkflow{
+		engine:  workflow.NewEngine(cfg, d.buildActionRegistry(), newEventChecker(d), d.logger),
+		version: name,
+	}
+}
@@ -0,0 +1,311 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	osexec "os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// unreachableErrorHints are fragments of error output (including gh CLI
+// stderr) that mean the tracker could not be reached at all, as opposed to
+// rejecting the request.
+var unreachableErrorHints = []string{
+	"error connecting to",
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"network is unreachable",
+	"i/o timeout",
+	"tls handshake timeout",
+	"could not resolve host",
+	"temporary failure in name resolution",
+}
+
+// isTrackerUnreachable reports whether err means the issue tracker could not
+// be reached. Only these failures are buffered or served from the cache;
+// errors the tracker returned (bad request, permissions) are not.
+func isTrackerUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		msg += " " + strings.ToLower(string(exitErr.Stderr))
+	}
+	for _, hint := range unreachableErrorHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// markTrackerOffline records that the tracker is unreachable, logging the
+// transition once.
+func (d *Daemon) markTrackerOffline(err error) {
+	if d.state.GetTrackerOfflineSince().IsZero() {
+		d.logger.Warn("issue tracker unreachable, continuing in offline mode", "event", "tracker.offline", "error", err)
+	}
+	d.state.SetTrackerOffline(true)
+}
+
+// markTrackerOnline clears the offline marker after a tracker call succeeds.
+func (d *Daemon) markTrackerOnline() {
+	since := d.state.GetTrackerOfflineSince()
+	if since.IsZero() {
+		return
+	}
+	d.state.SetTrackerOffline(false)
+	d.logger.Info("issue tracker reachable again", "event", "tracker.online",
+		"offlineFor", time.Since(since).Round(time.Second).String(),
+		"buffered", len(d.state.GetPendingOps()))
+}
+
+// bufferIfUnreachable returns err unchanged unless it means the tracker is
+// unreachable, in which case op is added to the outbox for replay and nil is
+// returned so the workflow can keep going.
+func (d *Daemon) bufferIfUnreachable(err error, op daemonstate.PendingOp) error {
+	if !isTrackerUnreachable(err) {
+		return err
+	}
+	op.ID = uuid.New().String()
+	d.state.EnqueuePendingOp(op)
+	d.markTrackerOffline(err)
+	d.logger.Warn("tracker unreachable, buffering update", "event", "tracker.buffered",
+		"workItem", op.WorkItemID, "kind", op.Kind, "issue", op.IssueID, "error", err)
+	return nil
+}
+
+// fetchIssuesOrCache fetches issues for a repo and caches the result. When the
+// tracker is unreachable the last cached fetch is served instead, with
+// fromCache set so callers can skip checks that need the tracker.
+func (d *Daemon) fetchIssuesOrCache(ctx context.Context, repoPath string, wfCfg *workflow.Config) (fetched []issues.Issue, fromCache bool, err error) {
+	cacheKey := d.issueCacheKey(repoPath, wfCfg)
+	fetched, err = d.fetchIssuesForProvider(ctx, repoPath, wfCfg)
+	if err == nil {
+		d.markTrackerOnline()
+		cached := make([]daemonstate.CachedIssue, 0, len(fetched))
+		for _, issue := range fetched {
+			cached = append(cached, daemonstate.CachedIssue{ID: issue.ID, Title: issue.Title, Body: issue.Body, URL: issue.URL, Parent: issue.Parent})
+		}
+		d.state.SetIssueCache(cacheKey, cached)
+		return fetched, false, nil
+	}
+	if !isTrackerUnreachable(err) {
+		return nil, false, err
+	}
+
+	d.markTrackerOffline(err)
+	cache, ok := d.state.GetIssueCache(cacheKey)
+	if !ok {
+		return nil, false, err
+	}
+	source := issues.Source(wfCfg.Source.Provider)
+	fetched = make([]issues.Issue, 0, len(cache.Issues))
+	for _, c := range cache.Issues {
+		fetched = append(fetched, issues.Issue{ID: c.ID, Title: c.Title, Body: c.Body, URL: c.URL, Source: source, Parent: c.Parent})
+	}
+	d.logger.Debug("serving issues from cache", "repo", repoPath, "fetchedAt", cache.FetchedAt, "count", len(fetched))
+	return fetched, true, nil
+}
+
+// issueCacheKey keys the offline issue cache. A repo's primary source is
+// cached under the repo path; additional sources under the path and provider.
+func (d *Daemon) issueCacheKey(repoPath string, wfCfg *workflow.Config) string {
+	if primary, ok := d.lookupWorkflowConfig(repoPath); ok && primary.Source.Provider != wfCfg.Source.Provider {
+		return repoPath + "#" + wfCfg.Source.Provider
+	}
+	return repoPath
+}
+
+// processOutbox replays tracker writes buffered while offline, oldest first,
+// stopping at the first one that still cannot reach the tracker. Writes the
+// tracker rejects are dropped. Afterwards, claims are settled for issues that
+// were queued from the cache.
+func (d *Daemon) processOutbox(ctx context.Context) {
+	log := d.logger.With("component", "outbox")
+
+	for _, op := range d.state.GetPendingOps() {
+		err := d.replayPendingOp(ctx, op)
+		if isTrackerUnreachable(err) {
+			d.markTrackerOffline(err)
+			return
+		}
+		d.state.RemovePendingOp(op.ID)
+		if err != nil {
+			log.Warn("dropping buffered tracker update", "event", "tracker.dropped",
+				"workItem", op.WorkItemID, "kind", op.Kind, "issue", op.IssueID, "error", err)
+			continue
+		}
+		log.Info("replayed buffered tracker update", "event", "tracker.replayed",
+			"workItem", op.WorkItemID, "kind", op.Kind, "issue", op.IssueID, "queuedAt", op.QueuedAt)
+		d.markTrackerOnline()
+	}
+
+	d.reconcileOfflineClaims(ctx)
+}
+
+// replayPendingOp performs a buffered tracker write.
+func (d *Daemon) replayPendingOp(ctx context.Context, op daemonstate.PendingOp) error {
+	source := issues.Source(op.Source)
+	if op.Kind == daemonstate.PendingComment && source != issues.SourceGitHub {
+		return d.postProviderComment(ctx, source, op.RepoPath, op.IssueID, op.Body, op.Step)
+	}
+
+	issueNum, err := strconv.Atoi(op.IssueID)
+	if err != nil {
+		return fmt.Errorf("invalid github issue number %q: %w", op.IssueID, err)
+	}
+	switch op.Kind {
+	case daemonstate.PendingComment:
+		return d.postGitHubIssueComment(ctx, op.RepoPath, issueNum, op.Body, op.Step)
+	case daemonstate.PendingAddLabel, daemonstate.PendingRemoveLabel:
+		labelCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+		defer cancel()
+		if op.Kind == daemonstate.PendingAddLabel {
+			return d.gitService.AddIssueLabel(labelCtx, op.RepoPath, issueNum, op.Label)
+		}
+		return d.gitService.RemoveIssueLabel(labelCtx, op.RepoPath, issueNum, op.Label)
+	default:
+		return fmt.Errorf("unknown buffered update kind %q", op.Kind)
+	}
+}
+
+// reconcileOfflineClaims claims issues that were queued from the cache while
+// the tracker was unreachable. An item that has not started is dropped if
+// another daemon claimed the issue in the meantime; work already underway
+// continues, with a warning.
+func (d *Daemon) reconcileOfflineClaims(ctx context.Context) {
+	log := d.logger.With("component", "outbox")
+
+	for _, item := range d.state.GetAllWorkItems() {
+		if offline, _ := item.StepData["_queued_offline"].(bool); !offline || item.IsTerminal() {
+			continue
+		}
+
+		claimCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+		won, err := d.tryClaim(claimCtx, d.resolveRepoPath(ctx, item), issueFromWorkItem(item), issues.Source(item.IssueRef.Source))
+		cancel()
+		if err != nil {
+			if isTrackerUnreachable(err) {
+				d.markTrackerOffline(err)
+				return
+			}
+			log.Debug("claim for offline-queued issue failed, will retry", "workItem", item.ID, "error", err)
+			continue
+		}
+		d.markTrackerOnline()
+
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, "_queued_offline")
+		})
+		if won {
+			continue
+		}
+		if item.State == daemonstate.WorkItemQueued {
+			log.Info("issue queued while offline was claimed by another daemon, dropping",
+				"event", "tracker.claim_lost", "workItem", item.ID, "issue", item.IssueRef.ID)
+			d.state.SetErrorMessage(item.ID, "claimed by another daemon while offline")
+			d.state.MarkWorkItemTerminal(item.ID, false)
+			continue
+		}
+		log.Warn("issue worked while offline is also claimed by another daemon",
+			"event", "tracker.claim_lost", "workItem", item.ID, "issue", item.IssueRef.ID)
+	}
+}
+
+// WithOffline runs the daemon in offline mode (container.Offline): issue
+// sources and actions that need the network fail as soon as they are used,
+// with an error saying so, instead of timing out against hosts the machine
+// can't reach.
+func WithOffline(offline bool) Option {
+	return func(d *Daemon) { d.offline = offline }
+}
+
+// networkActions are the actions that can't work without the network: they
+// call the issue tracker, GitHub or another service.
+var networkActions = []string{
+	"github.create_pr", "github.push", "github.merge", "github.comment_issue",
+	"github.comment_pr", "github.add_label", "github.remove_label",
+	"github.close_issue", "github.request_review", "github.assign_pr",
+	"github.create_release", "ai.fix_ci", "ai.address_review",
+	"asana.comment", "asana.move_to_section", "linear.comment",
+	"linear.move_to_state", "slack.notify", "webhook.post", "issue.create",
+}
+
+// offlineAction stands in for a network action in offline mode, failing the
+// state it runs in.
+type offlineAction struct {
+	name string
+}
+
+func (a *offlineAction) Execute(_ context.Context, _ *workflow.ActionContext) workflow.ActionResult {
+	return workflow.ActionResult{Error: fmt.Errorf("%w: %s needs the network", container.ErrOffline, a.name)}
+}
+
+// disableNetworkActions replaces the registry's network actions with ones
+// failing in offline mode.
+func disableNetworkActions(registry *workflow.ActionRegistry) {
+	for _, name := range networkActions {
+		if registry.Has(name) {
+			registry.Register(name, &offlineAction{name: name})
+		}
+	}
+}
+
+// offlineFetchError returns the error fetching issues from provider fails
+// with in offline mode, or nil when the provider works offline: only the
+// file backlog, kept in the repo, does.
+func (d *Daemon) offlineFetchError(provider issues.Source) error {
+	if !d.offline || provider == issues.SourceFile {
+		return nil
+	}
+	return fmt.Errorf("%w: fetching issues from %s needs the network; use the file backlog (source.provider: file) offline", container.ErrOffline, provider)
+}
+
+// warnOfflineWorkflows logs, once at startup, what in each repo's workflow
+// won't work in offline mode: issue sources that need the network, which
+// pick up nothing, and states whose actions or agent backends need it,
+// which will fail.
+func (d *Daemon) warnOfflineWorkflows() {
+	d.workflowMu.RLock()
+	defer d.workflowMu.RUnlock()
+	for repoPath, wfCfg := range d.workflowConfigs {
+		for _, src := range wfCfg.Source.Sources() {
+			if err := d.offlineFetchError(issues.Source(src.Provider)); err != nil {
+				d.logger.Warn("offline mode: issue source needs the network, no issues will be picked up from it",
+					"repo", repoPath, "provider", src.Provider)
+			}
+		}
+		for name, state := range wfCfg.States {
+			if slices.Contains(networkActions, state.Action) {
+				d.logger.Warn("offline mode: state's action needs the network and will fail",
+					"repo", repoPath, "state", name, "action", state.Action)
+			}
+			if b := wfCfg.Backend(name); b != nil && !b.IsLocal() {
+				d.logger.Warn("offline mode: state's agent backend needs the network and will fail",
+					"repo", repoPath, "state", name, "backend", wfCfg.BackendName(name))
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestIsTrackerUnreachable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", fmt.Errorf("fetch: %w", context.Canceled), false},
+		{"deadline", fmt.Errorf("fetch: %w", context.DeadlineExceeded), true},
+		{"net error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"gh stderr", errors.New("gh issue comment failed: exit status 1: error connecting to api.github.com"), true},
+		{"dns", errors.New(`Post "https://api.linear.app/graphql": dial tcp: lookup api.linear.app: no such host`), true},
+		{"rejected", errors.New("gh issue edit --add-label failed: exit status 1: could not add label: 'x' not found"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTrackerUnreachable(tt.err); got != tt.want {
+				t.Errorf("isTrackerUnreachable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// offlineTestDaemon returns a daemon polling /test/repo from a fake Linear
+// provider.
+func offlineTestDaemon(t *testing.T) (*Daemon, *issues.FakeProvider) {
+	t.Helper()
+	cfg := testConfig()
+	cfg.Repos = []string{"/test/repo"}
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	d.maxConcurrent = 10
+	prov := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+	d.workflowConfigs["/test/repo"].Source = workflow.SourceConfig{Provider: "linear"}
+	return d, prov
+}
+
+func TestPollForNewIssues_ServesCacheWhileTrackerUnreachable(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.state.SetIssueCache("/test/repo", []daemonstate.CachedIssue{{ID: "ENG-1", Title: "Cached issue", Body: "From the cache"}})
+	prov.SetFetchError(&net.OpError{Op: "dial", Err: errors.New("connection refused")})
+
+	d.pollForNewIssues(context.Background())
+
+	item, ok := d.state.GetWorkItem("/test/repo-ENG-1")
+	if !ok {
+		t.Fatal("expected cached issue to be queued")
+	}
+	if item.StepData["issue_body"] != "From the cache" {
+		t.Errorf("issue_body = %v", item.StepData["issue_body"])
+	}
+	if item.StepData["_queued_offline"] != true {
+		t.Error("expected item flagged as queued offline")
+	}
+	if len(prov.PostClaimCalls) != 0 {
+		t.Errorf("expected no claim while offline, got %d", len(prov.PostClaimCalls))
+	}
+	if d.state.GetTrackerOfflineSince().IsZero() {
+		t.Error("expected tracker marked offline")
+	}
+
+	// Connectivity returns: the claim is settled and the tracker is back online.
+	prov.SetFetchError(nil)
+	d.processOutbox(context.Background())
+
+	item, _ = d.state.GetWorkItem("/test/repo-ENG-1")
+	if _, ok := item.StepData["_queued_offline"]; ok {
+		t.Error("expected offline flag cleared after claiming")
+	}
+	if len(prov.PostClaimCalls) != 1 {
+		t.Errorf("expected claim posted on reconnect, got %d", len(prov.PostClaimCalls))
+	}
+	if !d.state.GetTrackerOfflineSince().IsZero() {
+		t.Error("expected tracker marked online")
+	}
+}
+
+func TestFetchIssuesOrCache_CachesSuccessfulFetch(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	prov.SetIssues([]issues.Issue{{ID: "ENG-2", Title: "Live issue", Source: issues.SourceLinear}})
+
+	fetched, fromCache, err := d.fetchIssuesOrCache(context.Background(), "/test/repo", d.workflowConfigs["/test/repo"])
+	if err != nil || fromCache || len(fetched) != 1 {
+		t.Fatalf("fetchIssuesOrCache = %v, %v, %v", fetched, fromCache, err)
+	}
+	if c, ok := d.state.GetIssueCache("/test/repo"); !ok || len(c.Issues) != 1 || c.Issues[0].ID != "ENG-2" {
+		t.Errorf("cache = %+v, want ENG-2", c)
+	}
+
+	// A rejection from the tracker is not served from the cache.
+	prov.SetFetchError(errors.New("invalid API key"))
+	if _, _, err := d.fetchIssuesOrCache(context.Background(), "/test/repo", d.workflowConfigs["/test/repo"]); err == nil {
+		t.Error("expected non-network error to be returned")
+	}
+}
+
+func TestCommentViaProvider_BuffersWhileUnreachable(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	item := daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "linear", ID: "ENG-1"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	}
+	params := workflow.NewParamHelper(map[string]any{"body": "Work started"})
+
+	prov.SetCommentError(&net.OpError{Op: "dial", Err: errors.New("connection refused")})
+	if err := d.commentViaProvider(context.Background(), item, params, issues.SourceLinear, "coding"); err != nil {
+		t.Fatalf("expected buffered comment to succeed, got %v", err)
+	}
+	ops := d.state.GetPendingOps()
+	if len(ops) != 1 || ops[0].Body != "Work started" || ops[0].Step != "coding" {
+		t.Fatalf("outbox = %+v, want the buffered comment", ops)
+	}
+
+	// Still offline: the comment stays buffered.
+	d.processOutbox(context.Background())
+	if len(d.state.GetPendingOps()) != 1 {
+		t.Fatal("expected comment to stay buffered while offline")
+	}
+
+	prov.SetCommentError(nil)
+	d.processOutbox(context.Background())
+	if len(d.state.GetPendingOps()) != 0 {
+		t.Error("expected outbox drained after reconnect")
+	}
+	if len(prov.CommentCalls) != 1 || prov.CommentCalls[0].Args[0] != "Work started\n"+ergProviderMarker("coding") {
+		t.Errorf("expected replayed marked comment, got %+v", prov.CommentCalls)
+	}
+	if !d.state.GetTrackerOfflineSince().IsZero() {
+		t.Error("expected tracker marked online after replay")
+	}
+}
+
+func TestProcessOutbox_DropsRejectedUpdates(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.state.EnqueuePendingOp(daemonstate.PendingOp{ID: "op-1", Kind: daemonstate.PendingAddLabel, Source: "github", IssueID: "not-a-number", Label: "wip"})
+	d.state.EnqueuePendingOp(daemonstate.PendingOp{ID: "op-2", Kind: daemonstate.PendingComment, Source: "linear", IssueID: "ENG-1", Body: "hello"})
+
+	d.processOutbox(context.Background())
+
+	if ops := d.state.GetPendingOps(); len(ops) != 0 {
+		t.Errorf("expected outbox drained, got %+v", ops)
+	}
+	if len(prov.CommentCalls) != 1 {
+		t.Errorf("expected the valid update replayed after the rejected one, got %d", len(prov.CommentCalls))
+	}
+}
+
+func TestOfflineMode_ProviderFetchFailsFast(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.offline = true
+	prov.SetIssues([]issues.Issue{{ID: "ENG-2", Title: "Needs the network", Source: issues.SourceLinear}})
+
+	wfCfg, _ := d.lookupWorkflowConfig("/test/repo")
+	if _, _, err := d.fetchIssuesOrCache(context.Background(), "/test/repo", wfCfg); !errors.Is(err, container.ErrOffline) {
+		t.Errorf("expected an offline error fetching from linear, got %v", err)
+	}
+	d.pollForNewIssues(context.Background())
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-2"); ok {
+		t.Error("expected no issue picked up from a network source offline")
+	}
+	if err := d.offlineFetchError(issues.SourceFile); err != nil {
+		t.Errorf("expected the file backlog to work offline, got %v", err)
+	}
+}
+
+func TestOfflineMode_NetworkActionsFail(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.offline = true
+	registry := d.buildActionRegistry()
+
+	for _, name := range []string{"github.push", "github.create_pr", "slack.notify"} {
+		result := registry.Get(name).Execute(context.Background(), &workflow.ActionContext{WorkItemID: "item-1"})
+		if result.Success || !errors.Is(result.Error, container.ErrOffline) {
+			t.Errorf("%s: expected an offline failure, got success=%v err=%v", name, result.Success, result.Error)
+		}
+	}
+	if _, ok := registry.Get("ai.code").(*offlineAction); ok {
+		t.Error("expected ai.code to stay available offline")
+	}
+
+	d.offline = false
+	if _, ok := d.buildActionRegistry().Get("github.push").(*offlineAction); ok {
+		t.Error("expected network actions online")
+	}
+}
+
+func TestNetworkActions_CoverRegisteredNetworkActions(t *testing.T) {
+	// localActions work from the session's worktree alone; every other
+	// registered action calls out and must fail fast in offline mode.
+	localActions := map[string]bool{
+		"ai.code": true, "ai.review": true, "ai.plan": true, "ai.summarize": true,
+		"ai.document": true, "ai.address_feedback": true, "ai.write_pr_description": true,
+		"ai.resolve_conflicts": true, "git.format": true, "git.rebase": true,
+		"git.validate_diff": true, "git.squash": true, "git.cherry_pick": true,
+		"quality.gate": true, "exec.run": true, "workflow.retry": true, "workflow.wait": true,
+	}
+	registry := testDaemon(testConfig()).buildActionRegistry()
+	for _, name := range registry.Names() {
+		if !localActions[name] && !slices.Contains(networkActions, name) {
+			t.Errorf("%s is neither a local action nor in networkActions", name)
+		}
+	}
+	for _, name := range networkActions {
+		if !registry.Has(name) {
+			t.Errorf("networkActions lists unregistered action %s", name)
+		}
+	}
+}
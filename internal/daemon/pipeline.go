@@ -0,0 +1,317 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/sanitize"
+	"github.com/zhubert/erg/internal/worker"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// Phases of an ai.code state run with pipeline: true. Each runs as its own
+// agent, in a fresh conversation on the state's worktree: a planner writes
+// an implementation plan, an implementer carries it out, and a reviewer
+// checks the diff against it before the workflow moves on to open the PR.
+const (
+	pipelinePlan      = "plan"
+	pipelineImplement = "implement"
+	pipelineReview    = "review"
+)
+
+// Step data keys of the pipeline. The plan and review are kept as the work
+// item's artifacts after the state completes.
+const (
+	pipelinePhaseKey        = "_pipeline_phase"
+	pipelinePlanKey         = "pipeline_plan"
+	pipelineReviewKey       = "pipeline_review"
+	pipelineReviewPassedKey = "pipeline_review_passed"
+)
+
+// DefaultPipelinePlanSystemPrompt is the system prompt of a pipeline's
+// planner when the state sets no plan_system_prompt.
+const DefaultPipelinePlanSystemPrompt = `You are the planning agent of a plan/implement/review pipeline. Another agent will implement your plan, and a third will review its changes against it.
+
+FOCUS: Analyze the issue and the codebase, then write a structured implementation plan.
+
+DO NOT:
+- Make any code changes or commits
+- Push branches or create pull requests
+
+The plan should include:
+- Summary of the approach
+- Files to modify or add, and what changes in each
+- Step-by-step implementation plan
+- Tests to add or update
+- Risks and edge cases the implementation must handle
+
+OUTPUT:
+End your final reply with the complete plan between <plan> and </plan> tags. Only the text between the tags is passed on.`
+
+// DefaultPipelineReviewSystemPrompt is the system prompt of a pipeline's
+// reviewer when the state sets no review_system_prompt.
+const DefaultPipelineReviewSystemPrompt = `You are the review agent of a plan/implement/review pipeline. Another agent implemented the plan you are given; review its diff before the pull request opens.
+
+FOCUS: Check that the diff carries out the plan, and review it for correctness, tests, security, and code quality. Submit your findings via the submit_review MCP tool.
+
+DO NOT:
+- Modify any source files — this is a read-only review
+- Push branches or create pull requests
+
+OUTPUT:
+Use the submit_review MCP tool to report your findings:
+- Set passed=false for BLOCKING issues: plan steps left undone or done wrongly without reason, critical bugs, security holes, missing required tests
+- Set passed=true if the diff carries out the plan, with at most WARNING-level issues
+- Include a brief summary of the review outcome, naming any plan steps not carried out`
+
+// pipelinePhase returns the pipeline phase an item's ai.code state is at, or
+// "" when it isn't run as a pipeline.
+func pipelinePhase(item daemonstate.WorkItem) string {
+	phase, _ := item.StepData[pipelinePhaseKey].(string)
+	return phase
+}
+
+// startPipelinePlan starts the pipeline's planner on the issue, with
+// read-only tools.
+func (d *Daemon) startPipelinePlan(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, wfCfg *workflow.Config, params *workflow.ParamHelper, initialMsg string) {
+	d.setPipelinePhase(item.ID, pipelinePlan)
+
+	prompt, err := workflow.ResolveSystemPrompt(params.String("plan_system_prompt", ""), sess.RepoPath)
+	if err != nil {
+		d.logger.Warn("failed to resolve pipeline plan system prompt", "error", err)
+	}
+	if prompt == "" {
+		prompt = DefaultPipelinePlanSystemPrompt
+	}
+
+	planTools := claude.ComposeTools(claude.ToolSetReadOnly, claude.ToolSetWeb)
+	d.sessionMgr.GetOrCreateRunner(sess).SetModel(d.resolveStateModel(wfCfg, item.CurrentStep))
+	w := d.createWorkerWithPrompt(ctx, item, sess, initialMsg, prompt, planTools)
+	d.setSessionTools(d.sessionMgr.GetOrCreateRunner(sess), sess, planTools, claude.ToolSetPlanningDeny)
+	d.startPipelineWorker(ctx, w, params)
+	d.logger.Info("started pipeline planner", "workItem", item.ID, "sessionID", sess.ID)
+}
+
+// startPipelineReview starts the pipeline's reviewer on the branch's diff
+// and the plan it carries out.
+func (d *Daemon) startPipelineReview(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, wfCfg *workflow.Config, params *workflow.ParamHelper) {
+	// The reviewer reports through submit_review; drop what an earlier
+	// review left so only its findings are read.
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.StepData[pipelinePhaseKey] = pipelineReview
+		delete(it.StepData, "review_passed")
+		delete(it.StepData, "ai_review_summary")
+	})
+	d.saveState()
+
+	prompt, err := workflow.ResolveSystemPrompt(params.String("review_system_prompt", ""), sess.RepoPath)
+	if err != nil {
+		d.logger.Warn("failed to resolve pipeline review system prompt", "error", err)
+	}
+	if prompt == "" {
+		prompt = DefaultPipelineReviewSystemPrompt
+	}
+
+	diff, err := getAIReviewDiff(ctx, sess.GetWorkDir(), sess.BaseBranch)
+	if err != nil {
+		diff = fmt.Sprintf("(the diff could not be computed: %v; inspect it with git diff)", err)
+	}
+	plan, _ := item.StepData[pipelinePlanKey].(string)
+	msg := formatPipelineReviewPrompt(plan, diff)
+
+	d.sessionMgr.GetOrCreateRunner(sess).SetModel(d.resolveStateModel(wfCfg, item.CurrentStep))
+	w := d.createWorkerWithPrompt(ctx, item, sess, msg, prompt)
+	d.startPipelineWorker(ctx, w, params)
+	d.logger.Info("started pipeline reviewer", "workItem", item.ID, "sessionID", sess.ID)
+}
+
+// startPipelineWorker starts a pipeline agent's worker with the state's
+// per-session limits.
+func (d *Daemon) startPipelineWorker(ctx context.Context, w *worker.SessionWorker, params *workflow.ParamHelper) {
+	maxTurns := params.Int("max_turns", 0)
+	maxDuration := params.Duration("max_duration", 0)
+	if maxTurns > 0 || maxDuration > 0 {
+		w.SetLimits(maxTurns, maxDuration)
+	}
+	w.Start(ctx)
+}
+
+// formatPipelineReviewPrompt builds the initial message of a pipeline's
+// reviewer.
+func formatPipelineReviewPrompt(plan, diff string) string {
+	return fmt.Sprintf(`PIPELINE REVIEW
+
+Review the following git diff against the implementation plan it was written to carry out.
+
+INSTRUCTIONS:
+1. Read the plan, then the diff
+2. Use bash tools to explore the full context of changed files if needed
+3. Check each plan step was carried out, and check correctness, tests, security, and code quality
+4. Use the submit_review MCP tool to report your findings
+
+DO NOT modify any source files — this is a review-only session.
+
+IMPLEMENTATION PLAN:
+%s
+
+GIT DIFF:
+%s`, sanitize.UntrustedContent("implementation_plan", plan), diff)
+}
+
+// advancePipeline moves an ai.code state run as a pipeline on from the
+// agent that just finished, exitErr being how it finished. It reports
+// whether it started the next agent, in which case the state is still
+// running; otherwise it returns the state's outcome: exitErr, or why the
+// planner produced no plan or the reviewer blocked the changes.
+func (d *Daemon) advancePipeline(ctx context.Context, item daemonstate.WorkItem, sess *config.Session, exitErr error) (bool, error) {
+	phase := pipelinePhase(item)
+	if phase == "" {
+		return false, exitErr
+	}
+	if exitErr != nil {
+		// A retry of the state starts the pipeline over.
+		d.setPipelinePhase(item.ID, "")
+		return false, exitErr
+	}
+	log := d.logger.With("workItem", item.ID, "phase", phase)
+
+	switch phase {
+	case pipelinePlan:
+		plan := extractPlan(d.lastAssistantMessage(sess.ID))
+		if plan == "" {
+			d.setPipelinePhase(item.ID, "")
+			return false, fmt.Errorf("pipeline planner produced no plan")
+		}
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			it.StepData[pipelinePlanKey] = plan
+			it.StepData[pipelinePhaseKey] = pipelineImplement
+		})
+		d.saveState()
+		log.Info("pipeline plan ready, starting implementer")
+
+	case pipelineImplement:
+		d.setPipelinePhase(item.ID, pipelineReview)
+		log.Info("pipeline implementation done, starting reviewer")
+
+	case pipelineReview:
+		// The reviewer reports through submit_review; keep its findings
+		// as the pipeline's so a later ai.review state starts clean.
+		var passed bool
+		var summary string
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			rp, ok := it.StepData["review_passed"].(bool)
+			passed = rp || !ok
+			summary, _ = it.StepData["ai_review_summary"].(string)
+			it.StepData[pipelineReviewPassedKey] = passed
+			it.StepData[pipelineReviewKey] = summary
+			delete(it.StepData, "review_passed")
+			delete(it.StepData, "ai_review_summary")
+			delete(it.StepData, pipelinePhaseKey)
+		})
+		d.saveState()
+		log.Info("pipeline review done", "passed", passed, "summary", summary)
+		if !passed {
+			return false, fmt.Errorf("pipeline review blocked: %s", summary)
+		}
+		return false, nil
+
+	default:
+		d.setPipelinePhase(item.ID, "")
+		return false, fmt.Errorf("unknown pipeline phase %q", phase)
+	}
+
+	// Each agent starts a conversation of its own.
+	sess = d.renewConversation(item, sess)
+	item, _ = d.state.GetWorkItem(item.ID)
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	params := workflow.NewParamHelper(nil)
+	if state := wfCfg.States[item.CurrentStep]; state != nil {
+		params = workflow.NewParamHelper(state.Params)
+	}
+	d.startCodingWorker(ctx, item, sess, wfCfg, params, "")
+	return true, nil
+}
+
+// setPipelinePhase records the pipeline phase of an item's state; ""
+// clears it.
+func (d *Daemon) setPipelinePhase(itemID, phase string) {
+	d.state.UpdateWorkItem(itemID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		if phase == "" {
+			delete(it.StepData, pipelinePhaseKey)
+		} else {
+			it.StepData[pipelinePhaseKey] = phase
+		}
+	})
+	d.saveState()
+}
+
+// renewConversation gives an item's session a new ID, keeping its branch
+// and worktree, so the next agent on it starts a fresh conversation.
+func (d *Daemon) renewConversation(item daemonstate.WorkItem, sess *config.Session) *config.Session {
+	oldID := sess.ID
+	d.saveRunnerMessagesFor(oldID)
+	d.sessionMgr.RemoveRunner(oldID)
+	d.setSessionBackend(oldID, "")
+
+	newSess := *sess
+	newSess.ID = uuid.New().String()
+	newSess.Started = false
+	d.config.RemoveSession(oldID)
+	d.config.AddSession(newSess)
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.SessionID = newSess.ID
+	})
+	d.saveConfig("renewConversation")
+	d.logger.Debug("renewed session conversation", "workItem", item.ID, "oldSessionID", oldID, "newSessionID", newSess.ID)
+	return &newSess
+}
+
+// saveRunnerMessagesFor persists the conversation of a session's runner,
+// if it has one.
+func (d *Daemon) saveRunnerMessagesFor(sessionID string) {
+	if r := d.sessionMgr.GetRunner(sessionID); r != nil {
+		d.saveRunnerMessages(sessionID, r)
+	}
+}
+
+// lastAssistantMessage returns the last reply of a session's agent, from
+// its runner or, failing that, its saved conversation.
+func (d *Daemon) lastAssistantMessage(sessionID string) string {
+	var msgs []claude.Message
+	if r := d.sessionMgr.GetRunner(sessionID); r != nil {
+		msgs = r.GetMessages()
+	} else if saved, err := config.LoadSessionMessages(sessionID); err == nil {
+		for _, m := range saved {
+			msgs = append(msgs, claude.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "assistant" && strings.TrimSpace(msgs[i].Content) != "" {
+			return msgs[i].Content
+		}
+	}
+	return ""
+}
+
+// extractPlan returns the plan a planner's reply ends with: the text of its
+// last <plan> block, or the whole reply when it has none.
+func extractPlan(reply string) string {
+	start := strings.LastIndex(reply, "<plan>")
+	if start < 0 {
+		return strings.TrimSpace(reply)
+	}
+	plan := reply[start+len("<plan>"):]
+	if end := strings.Index(plan, "</plan>"); end >= 0 {
+		plan = plan[:end]
+	}
+	return strings.TrimSpace(plan)
+}
@@ -0,0 +1,181 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// pipelineTestDaemon returns a daemon whose /test/repo workflow codes as a
+// pipeline, with item-p at coding in the given pipeline phase. Runners are
+// mocks, recorded by session ID.
+func pipelineTestDaemon(t *testing.T, phase string) (*Daemon, map[string]*claude.MockRunner) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"api", "repos/:owner/:repo/issues/"}, exec.MockResponse{Stdout: []byte(`[]`)})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	wfCfg := &workflow.Config{
+		Start:  "coding",
+		Source: workflow.SourceConfig{Provider: "github"},
+		States: map[string]*workflow.State{
+			"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Params: map[string]any{"pipeline": true}, Next: "done", Error: "failed"},
+			"done":   {Type: workflow.StateTypeSucceed},
+			"failed": {Type: workflow.StateTypeFail},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+
+	runners := map[string]*claude.MockRunner{}
+	d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, started bool, msgs []claude.Message) claude.RunnerInterface {
+		r := claude.NewMockRunner(sessionID, started, msgs)
+		runners[sessionID] = r
+		return r
+	})
+
+	sess := testSession("sess-p")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-p",
+		IssueRef:    config.IssueRef{Source: "github", ID: "7", Title: "Add retries"},
+		SessionID:   sess.ID,
+		CurrentStep: "coding",
+		Phase:       "async_pending",
+		State:       daemonstate.WorkItemActive,
+		StepData:    map[string]any{pipelinePhaseKey: phase, pipelinePlanKey: "1. Wrap the client in a retrier."},
+	})
+	return d, runners
+}
+
+func TestAdvancePipeline_PlanStartsImplementer(t *testing.T) {
+	d, runners := pipelineTestDaemon(t, pipelinePlan)
+	planner := d.sessionMgr.GetOrCreateRunner(d.config.GetSession("sess-p")).(*claude.MockRunner)
+	planner.AddAssistantMessage("[Read: client.go]\nHere is the plan.\n<plan>\n1. Add a backoff helper.\n2. Retry on 503.\n</plan>")
+	item, _ := d.state.GetWorkItem("item-p")
+
+	d.handleAsyncComplete(t.Context(), item, nil)
+
+	item, _ = d.state.GetWorkItem("item-p")
+	if item.CurrentStep != "coding" || pipelinePhase(item) != pipelineImplement {
+		t.Fatalf("expected the implementer to run at coding, got step %q phase %q", item.CurrentStep, pipelinePhase(item))
+	}
+	want := "1. Add a backoff helper.\n2. Retry on 503."
+	if got := item.StepData[pipelinePlanKey]; got != want {
+		t.Errorf("plan artifact = %q, want %q", got, want)
+	}
+	if item.SessionID == "sess-p" || d.config.GetSession(item.SessionID) == nil || d.config.GetSession("sess-p") != nil {
+		t.Errorf("expected the implementer on a fresh conversation, got session %q", item.SessionID)
+	}
+
+	d.mu.Lock()
+	w := d.workers["item-p"]
+	d.mu.Unlock()
+	if w == nil {
+		t.Fatal("expected the implementer's worker started")
+	}
+	if !strings.Contains(w.InitialMsg(), want) {
+		t.Errorf("expected the plan in the implementer's prompt, got:\n%s", w.InitialMsg())
+	}
+	if implementer := runners[item.SessionID]; implementer == nil || !strings.HasPrefix(implementer.GetSystemPrompt(), DefaultCodingSystemPrompt) {
+		t.Error("expected the implementer on the coding system prompt")
+	}
+}
+
+func TestAdvancePipeline_ImplementStartsReviewer(t *testing.T) {
+	d, runners := pipelineTestDaemon(t, pipelineImplement)
+	d.state.UpdateWorkItem("item-p", func(it *daemonstate.WorkItem) {
+		it.StepData["review_passed"] = false // left by an earlier review
+	})
+	item, _ := d.state.GetWorkItem("item-p")
+
+	d.handleAsyncComplete(t.Context(), item, nil)
+
+	item, _ = d.state.GetWorkItem("item-p")
+	if pipelinePhase(item) != pipelineReview {
+		t.Fatalf("expected the reviewer to run, got phase %q", pipelinePhase(item))
+	}
+	if _, ok := item.StepData["review_passed"]; ok {
+		t.Error("expected an earlier review's result dropped")
+	}
+	d.mu.Lock()
+	w := d.workers["item-p"]
+	d.mu.Unlock()
+	if w == nil || !strings.Contains(w.InitialMsg(), "1. Wrap the client in a retrier.") || !strings.Contains(w.InitialMsg(), "GIT DIFF:") {
+		t.Fatal("expected the reviewer started on the plan and diff")
+	}
+	if reviewer := runners[item.SessionID]; reviewer == nil || !strings.HasPrefix(reviewer.GetSystemPrompt(), DefaultPipelineReviewSystemPrompt) {
+		t.Error("expected the reviewer on the pipeline review system prompt")
+	}
+}
+
+func TestAdvancePipeline_ReviewOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		passed   bool
+		wantStep string
+	}{
+		{name: "passed", passed: true, wantStep: "done"},
+		{name: "blocked", passed: false, wantStep: "failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _ := pipelineTestDaemon(t, pipelineReview)
+			d.state.UpdateWorkItem("item-p", func(it *daemonstate.WorkItem) {
+				it.StepData["review_passed"] = tt.passed
+				it.StepData["ai_review_summary"] = "Step 1 is done."
+			})
+			item, _ := d.state.GetWorkItem("item-p")
+
+			d.handleAsyncComplete(context.Background(), item, nil)
+
+			item, _ = d.state.GetWorkItem("item-p")
+			if item.CurrentStep != tt.wantStep {
+				t.Errorf("expected the item at %q, got %q", tt.wantStep, item.CurrentStep)
+			}
+			if item.StepData[pipelineReviewKey] != "Step 1 is done." || item.StepData[pipelineReviewPassedKey] != tt.passed {
+				t.Errorf("unexpected review artifacts: %+v", item.StepData)
+			}
+			if _, ok := item.StepData["review_passed"]; ok || pipelinePhase(item) != "" {
+				t.Errorf("expected the pipeline's review state cleared: %+v", item.StepData)
+			}
+		})
+	}
+}
+
+func TestAdvancePipeline_PlannerWithoutPlanFails(t *testing.T) {
+	d, _ := pipelineTestDaemon(t, pipelinePlan)
+	item, _ := d.state.GetWorkItem("item-p")
+
+	d.handleAsyncComplete(t.Context(), item, nil)
+
+	item, _ = d.state.GetWorkItem("item-p")
+	if item.CurrentStep != "failed" || pipelinePhase(item) != "" {
+		t.Errorf("expected the state failed without a plan, got step %q phase %q", item.CurrentStep, pipelinePhase(item))
+	}
+}
+
+func TestExtractPlan(t *testing.T) {
+	tests := map[string]string{
+		"Plan:\n1. Do it.":                                "Plan:\n1. Do it.",
+		"Notes.\n<plan>\n1. Do it.\n</plan>\nDone.":       "1. Do it.",
+		"<plan>draft</plan> revised: <plan>final</plan>":  "final",
+		"Looked around.\n<plan>\n1. Unterminated plan.\n": "1. Unterminated plan.",
+		"  ": "",
+	}
+	for reply, want := range tests {
+		if got := extractPlan(reply); got != want {
+			t.Errorf("extractPlan(%q) = %q, want %q", reply, got, want)
+		}
+	}
+}
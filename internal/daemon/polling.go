@@ -1,8 +1,10 @@
 package daemon
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/labelexpr"
 	"github.com/zhubert/erg/internal/workflow"
 )
 
@@ -57,133 +60,266 @@ func (d *Daemon) pollForNewIssues(ctx context.Context) {
 	pollCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
 	defer cancel()
 
-	for _, repoPath := range pollingRepos {
-		remaining := maxConcurrent - activeSlots - queuedCount
-		if remaining <= 0 {
-			break
+	// Fetch every repo's candidates first, then admit them one repo at a
+	// time so a repo with a deep backlog cannot take all the free slots.
+	// The first repo to get a turn rotates from poll to poll.
+	pollingRepos = d.rotatePollOrder(pollingRepos)
+	candidates := make([][]polledIssue, len(pollingRepos))
+	budgets := make([]int, len(pollingRepos))
+	preseeded := make([]bool, len(pollingRepos))
+	for i, repoPath := range pollingRepos {
+		budgets[i] = d.repoQueueBudget(repoPath)
+		if budgets[i] <= 0 {
+			log.Debug("repo at its concurrency limit, skipping poll", "repo", repoPath)
+			continue
 		}
 
 		wfCfg := d.getWorkflowConfig(repoPath)
-		provider := issues.Source(wfCfg.Source.Provider)
 
-		var fetchedIssues []issues.Issue
+		var polled []polledIssue
 		if d.preseededIssue != nil {
-			fetchedIssues = []issues.Issue{*d.preseededIssue}
+			polled = []polledIssue{{issue: *d.preseededIssue, provider: issues.Source(wfCfg.Source.Provider)}}
+			preseeded[i] = true
 			d.preseededIssue = nil // consume — only inject once
 		} else {
-			var err error
-			fetchedIssues, err = d.fetchIssuesForProvider(pollCtx, repoPath, wfCfg)
-			if err != nil {
-				log.Debug("failed to fetch issues", "repo", repoPath, "provider", provider, "error", err)
-				continue
+			// Poll every source configured for the repo, then drop issues
+			// that are the same work tracked in another source.
+			for _, src := range wfCfg.Source.Sources() {
+				provider := issues.Source(src.Provider)
+				fetched, fromCache, err := d.fetchIssuesOrCache(pollCtx, repoPath, wfCfg.ForSource(src))
+				if err != nil {
+					log.Debug("failed to fetch issues", "repo", repoPath, "provider", provider, "error", err)
+					continue
+				}
+				for _, issue := range fetched {
+					polled = append(polled, polledIssue{issue: issue, provider: provider, fromCache: fromCache})
+				}
 			}
+			polled = d.dedupAcrossSources(repoPath, wfCfg, polled)
 		}
 
-		for _, issue := range fetchedIssues {
+		// Take the issues the repo's ordering policy favors first when
+		// slots are scarce.
+		order := issueOrdering(wfCfg)
+		slices.SortStableFunc(polled, func(a, b polledIssue) int { return order.Compare(a.issue, b.issue) })
+		candidates[i] = polled
+	}
+
+	remaining := maxConcurrent - activeSlots - queuedCount
+	for admitted := true; admitted && remaining > 0; {
+		// Each round, every repo with budget left queues at most one issue.
+		// A round that queues nothing means every repo is out of candidates
+		// or budget.
+		admitted = false
+		for i, repoPath := range pollingRepos {
 			if remaining <= 0 {
 				break
 			}
-
-			// Check if we already have a work item for this issue
-			if d.state.HasWorkItemForIssue(string(provider), issue.ID) {
-				continue
+			for budgets[i] > 0 && len(candidates[i]) > 0 {
+				p := candidates[i][0]
+				candidates[i] = candidates[i][1:]
+				if d.admitPolledIssue(pollCtx, repoPath, p, preseeded[i]) {
+					budgets[i]--
+					remaining--
+					admitted = true
+					break
+				}
 			}
+		}
+	}
+}
 
-			// Also check config sessions for deduplication
-			if d.hasExistingSession(repoPath, issue.ID) {
-				continue
-			}
+// admitPolledIssue queues a polled issue unless the daemon already tracks
+// it, it is not ready, or another daemon claimed it. It reports whether the
+// issue was queued.
+func (d *Daemon) admitPolledIssue(ctx context.Context, repoPath string, p polledIssue, preseeded bool) bool {
+	log := d.logger.With("component", "issue-poller")
+	issue, provider, fromCache := p.issue, p.provider, p.fromCache
 
-			// Check if this issue was previously unqueued by erg (comment marker).
-			// This survives terminal work item pruning so we don't re-comment.
-			if d.isUnqueued(pollCtx, repoPath, issue, provider) {
-				log.Debug("issue has unqueued marker, skipping", "issue", issue.ID)
-				continue
-			}
+	// Check if we already have a work item for this issue
+	if d.state.HasWorkItemForIssue(string(provider), issue.ID) {
+		return false
+	}
 
-			// Attempt to claim the issue (multi-daemon coordination).
-			// Must happen before pre-flight PR checks so that adopting an
-			// existing PR is also coordinated across daemons.
-			won, claimErr := d.tryClaim(pollCtx, repoPath, issue, provider)
-			if claimErr != nil {
-				log.Debug("claim attempt failed", "issue", issue.ID, "error", claimErr)
-				continue
-			}
-			if !won {
-				log.Debug("issue claimed by another daemon, skipping", "issue", issue.ID)
-				continue
-			}
+	// Also check config sessions for deduplication
+	if d.hasExistingSession(repoPath, provider, issue.ID) {
+		return false
+	}
 
-			// Pre-flight: for GitHub issues, check if an open/merged PR already
-			// addresses this issue. If so, unqueue it without spawning a session.
-			// This runs after claiming so the unqueue path can clean up our claim.
-			if provider == issues.SourceGitHub {
-				if skip := d.checkLinkedPRsAndUnqueue(pollCtx, repoPath, issue); skip {
-					continue
-				}
-			}
+	// Readiness checks apply to polled issues; an issue run explicitly
+	// with `erg run --issue` is taken as ready.
+	var deps []dependencyRecord
+	if !preseeded {
+		wfCfg := d.getWorkflowConfig(repoPath)
+		var missing []string
+		if missing, deps = d.checkReadiness(ctx, repoPath, issue, provider, wfCfg, !fromCache); len(missing) > 0 {
+			log.Debug("issue not ready, skipping", "issue", issue.ID, "missing", missing)
+			return false
+		}
+	}
 
-			item := &daemonstate.WorkItem{
-				ID: fmt.Sprintf("%s-%s", repoPath, issue.ID),
-				IssueRef: config.IssueRef{
-					Source: string(provider),
-					ID:     issue.ID,
-					Title:  issue.Title,
-					URL:    issue.URL,
-				},
-				StepData: map[string]any{
-					"_repo_path": repoPath,
-				},
-			}
-			if issue.Body != "" {
-				item.StepData["issue_body"] = issue.Body
-			}
+	// Issues served from the cache skip the checks below, which all
+	// need the tracker; the claim is settled once it is reachable.
+	if fromCache {
+		d.recordDependencies(d.queueIssue(repoPath, issue, provider, true), deps)
+		return true
+	}
 
-			d.state.AddWorkItem(item)
-			queuedCount++
-			remaining--
+	// Check if this issue was previously unqueued by erg (comment marker).
+	// This survives terminal work item pruning so we don't re-comment.
+	if d.isUnqueued(ctx, repoPath, issue, provider) {
+		log.Debug("issue has unqueued marker, skipping", "issue", issue.ID)
+		return false
+	}
+
+	// Attempt to claim the issue (multi-daemon coordination).
+	// Must happen before pre-flight PR checks so that adopting an
+	// existing PR is also coordinated across daemons.
+	won, claimErr := d.tryClaim(ctx, repoPath, issue, provider)
+	if claimErr != nil {
+		log.Debug("claim attempt failed", "issue", issue.ID, "error", claimErr)
+		return false
+	}
+	if !won {
+		log.Debug("issue claimed by another daemon, skipping", "issue", issue.ID)
+		return false
+	}
 
-			log.Info("queued new issue", "event", "session.created", "issue", issue.ID, "title", issue.Title, "provider", provider, "workItem", item.ID, "repo", repoPath)
+	// Pre-flight: for GitHub issues, check if an open/merged PR already
+	// addresses this issue. If so, unqueue it without spawning a session.
+	// This runs after claiming so the unqueue path can clean up our claim.
+	if provider == issues.SourceGitHub {
+		if skip := d.checkLinkedPRsAndUnqueue(ctx, repoPath, issue); skip {
+			return false
 		}
 	}
+
+	d.recordDependencies(d.queueIssue(repoPath, issue, provider, false), deps)
+	return true
+}
+
+// queueIssue adds a queued work item for a fetched issue and returns its
+// ID. offline marks an issue served from the cache whose claim still has
+// to be settled.
+func (d *Daemon) queueIssue(repoPath string, issue issues.Issue, provider issues.Source, offline bool) string {
+	item := &daemonstate.WorkItem{
+		ID: d.workItemID(repoPath, provider, issue.ID),
+		IssueRef: config.IssueRef{
+			Source:    string(provider),
+			ID:        issue.ID,
+			Title:     issue.Title,
+			URL:       issue.URL,
+			Epic:      cmp.Or(issue.Parent, issues.ParseEpicRef(issue.Body)),
+			Priority:  issue.Priority,
+			CreatedAt: issue.CreatedAt,
+			Labels:    issue.Labels,
+			DueAt:     issue.DueAt,
+		},
+		StepData: map[string]any{
+			"_repo_path": repoPath,
+		},
+	}
+	if issue.Body != "" {
+		item.StepData["issue_body"] = issue.Body
+	}
+	if issue.Parent != "" {
+		item.StepData[subtaskParentKey] = issue.Parent
+	}
+	if offline {
+		item.StepData["_queued_offline"] = true
+	}
+	d.checkDeadlineRisk(item)
+	item.Workflow = d.selectWorkflow(repoPath, issue.Labels)
+	item.WorkflowVersion = d.workflowVersionFor(repoPath, item.Workflow)
+
+	d.state.AddWorkItem(item)
+
+	d.logger.Info("queued new issue", "component", "issue-poller", "event", "session.created", "issue", issue.ID, "title", issue.Title,
+		"provider", provider, "workItem", item.ID, "repo", repoPath, "workflow", item.Workflow, "offline", offline)
+	return item.ID
+}
+
+// workItemID returns the ID of the work item for an issue. Issues from the
+// repo's primary source are "<repo>-<id>"; those from an additional source
+// also name the provider, so same-numbered issues from two trackers get
+// items of their own.
+func (d *Daemon) workItemID(repoPath string, provider issues.Source, issueID string) string {
+	if primary := d.getWorkflowConfig(repoPath).Source.Provider; primary == "" || issues.Source(primary) == provider {
+		return fmt.Sprintf("%s-%s", repoPath, issueID)
+	}
+	return fmt.Sprintf("%s-%s-%s", repoPath, provider, issueID)
 }
 
-// fetchIssuesForProvider fetches issues using the appropriate provider.
+// fetchIssuesForProvider fetches issues using the appropriate provider. With
+// source.filter.expand_subtasks set, parents with open sub-tasks are replaced
+// by their sub-tasks.
 func (d *Daemon) fetchIssuesForProvider(ctx context.Context, repoPath string, wfCfg *workflow.Config) ([]issues.Issue, error) {
+	fetched, err := d.fetchMatchingIssues(ctx, repoPath, wfCfg)
+	if err != nil || !wfCfg.Source.Filter.ExpandSubtasks {
+		return fetched, err
+	}
+	return d.expandSubtasks(ctx, repoPath, wfCfg, fetched), nil
+}
+
+// fetchMatchingIssues fetches the issues matching the source filter.
+// The filter label may be an expression such as "ai-ready && !blocked": the
+// provider is asked for a label every match must carry, if there is one, and
+// the expression is then evaluated against each issue's labels.
+func (d *Daemon) fetchMatchingIssues(ctx context.Context, repoPath string, wfCfg *workflow.Config) ([]issues.Issue, error) {
+	label := wfCfg.Source.Filter.Label
+	if label == "" {
+		if issues.Source(wfCfg.Source.Provider) == issues.SourceGitHub {
+			label = autonomousFilterLabel
+		}
+		return d.fetchProviderIssues(ctx, repoPath, wfCfg, label)
+	}
+
+	expr, err := labelexpr.Parse(label)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := d.fetchProviderIssues(ctx, repoPath, wfCfg, expr.Required())
+	if err != nil || expr.Simple() {
+		return fetched, err
+	}
+	return slices.DeleteFunc(fetched, func(issue issues.Issue) bool {
+		return !expr.Match(issue.Labels)
+	}), nil
+}
+
+// fetchProviderIssues fetches open issues from the configured provider,
+// narrowed server-side to a single label ("" for no label filter).
+func (d *Daemon) fetchProviderIssues(ctx context.Context, repoPath string, wfCfg *workflow.Config, label string) ([]issues.Issue, error) {
 	provider := issues.Source(wfCfg.Source.Provider)
+	if err := d.offlineFetchError(provider); err != nil {
+		return nil, err
+	}
 
 	switch provider {
 	case issues.SourceGitHub:
-		label := wfCfg.Source.Filter.Label
-		if label == "" {
-			label = autonomousFilterLabel
-		}
-		ghIssues, err := d.gitService.FetchGitHubIssuesWithLabel(ctx, repoPath, label)
+		ghIssues, err := d.gitService.FetchGitHubIssuesWithLabel(ctx, repoPath, label, wfCfg.Source.Filter.Assignee)
 		if err != nil {
 			return nil, err
 		}
 		result := make([]issues.Issue, 0, len(ghIssues))
 		for _, ghIssue := range ghIssues {
-			result = append(result, issues.Issue{
-				ID:     strconv.Itoa(ghIssue.Number),
-				Title:  ghIssue.Title,
-				Body:   ghIssue.Body,
-				URL:    ghIssue.URL,
-				Source: issues.SourceGitHub,
-			})
+			result = append(result, issues.FromGitHubIssue(ghIssue))
 		}
 		return result, nil
 
-	case issues.SourceAsana, issues.SourceLinear:
+	case issues.SourceAsana, issues.SourceLinear, issues.SourceGitLab, issues.SourceClickUp, issues.SourceHTTP, issues.SourceFile:
 		p := d.issueRegistry.GetProvider(provider)
 		if p == nil {
 			return nil, fmt.Errorf("provider %q not registered", provider)
 		}
 		return p.FetchIssues(ctx, repoPath, issues.FilterConfig{
-			Label:   wfCfg.Source.Filter.Label,
-			Project: wfCfg.Source.Filter.Project,
-			Team:    wfCfg.Source.Filter.Team,
-			Section: wfCfg.Source.Filter.Section,
+			Label:    label,
+			Project:  wfCfg.Source.Filter.Project,
+			Team:     wfCfg.Source.Filter.Team,
+			Section:  wfCfg.Source.Filter.Section,
+			List:     wfCfg.Source.Filter.List,
+			Assignee: wfCfg.Source.Filter.Assignee,
+			MaxItems: wfCfg.Source.Filter.MaxItems,
 		})
 
 	default:
@@ -194,7 +330,9 @@ func (d *Daemon) fetchIssuesForProvider(ctx context.Context, repoPath string, wf
 // startQueuedItems starts coding on queued work items that have available slots.
 // Before starting any new work, it first checks whether any set-aside await_review
 // workflows are ready to continue — finishing existing work takes priority over
-// starting new work.
+// starting new work. Hotfixes start first and, where the repo allows it, may
+// preempt a running coding session; sessions preempted earlier resume ahead
+// of the remaining queue.
 func (d *Daemon) startQueuedItems(ctx context.Context) {
 	if d.configSavePaused {
 		d.logger.Warn("config save failures exceed threshold, skipping start of queued items to prevent state drift")
@@ -205,8 +343,10 @@ func (d *Daemon) startQueuedItems(ctx context.Context) {
 	queued := d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)
 
 	if len(queued) == 0 {
+		d.resumePreemptedItems(ctx)
 		return
 	}
+	d.sortQueuedItems(queued)
 
 	// Give priority to set-aside workflows that are ready to continue.
 	// processWaitItems checks await_review items for fired events (review
@@ -215,26 +355,49 @@ func (d *Daemon) startQueuedItems(ctx context.Context) {
 	// workflows reduce the budget available for new queued items below.
 	d.processWaitItems(ctx)
 
+	resumed := false
 	for _, item := range queued {
-		if d.activeSlotCount() >= maxConcurrent {
+		hotfix := d.isHotfix(item)
+		if !hotfix && !resumed {
+			d.resumePreemptedItems(ctx)
+			resumed = true
+		}
+		globalFull := d.activeSlotCount() >= maxConcurrent
+		if globalFull && !hotfix {
 			break
 		}
 
-		sess := d.config.GetSession(item.SessionID)
-		repoPath := ""
-		if sess != nil {
-			repoPath = sess.RepoPath
-		} else if rp, ok := item.StepData["_repo_path"].(string); ok && rp != "" {
-			repoPath = rp
-		} else if d.repoFilter != "" {
+		repoPath := d.workItemRepoPath(item)
+		if repoPath == "" && d.repoFilter != "" {
 			repoPath = d.findRepoPath(ctx)
 		}
 
-		engine := d.getEngine(repoPath)
+		engine := d.getItemEngine(repoPath, item)
 		if engine == nil {
 			d.logger.Error("no engine for repo", "repo", repoPath, "workItem", item.ID)
 			continue
 		}
+		if d.workflowMutexHeld(ctx, repoPath, item) {
+			continue
+		}
+		if d.budgetStopsNewWork(repoPath) {
+			continue // over its spend budget; other repos may still start
+		}
+		if toolchain := d.toolchainFull(ctx, repoPath); toolchain != "" {
+			d.logger.Debug("toolchain at its concurrency limit, leaving item queued",
+				"workItem", item.ID, "repo", repoPath, "toolchain", toolchain)
+			continue // other toolchains may still start
+		}
+		limit := d.getRepoMaxConcurrent(repoPath)
+		repoFull := limit > 0 && d.repoSlotCount(repoPath) >= limit
+		if globalFull || repoFull {
+			if !d.preemptionEnabled(repoPath) || !d.preemptFor(item, repoPath, repoFull) {
+				if globalFull {
+					break
+				}
+				continue // the repo is at its own limit; other repos may still start
+			}
+		}
 
 		// Transition out of "queued" state before running the sync chain.
 		// This is critical: if the chain takes the existing-PR shortcut
@@ -258,6 +421,93 @@ func (d *Daemon) startQueuedItems(ctx context.Context) {
 		// which calls startCoding to create the session and spawn the worker.
 		d.executeSyncChain(ctx, item.ID, engine)
 	}
+	if !resumed {
+		d.resumePreemptedItems(ctx)
+	}
+}
+
+// sortQueuedItems orders queued work items for pickup. Hotfixes come first,
+// in any repo. Items are ranked within their repo by that repo's ordering
+// policy (ties go to the item queued first), and repos then take turns:
+// every repo's top item comes before any repo's second, with each round in
+// queue order.
+func (d *Daemon) sortQueuedItems(items []daemonstate.WorkItem) {
+	byRepo := make(map[string][]daemonstate.WorkItem)
+	var repos []string
+	for _, item := range items {
+		repoPath := d.workItemRepoPath(item)
+		if _, ok := byRepo[repoPath]; !ok {
+			repos = append(repos, repoPath)
+		}
+		byRepo[repoPath] = append(byRepo[repoPath], item)
+	}
+
+	hotfix := make(map[string]bool, len(items))
+	for _, item := range items {
+		hotfix[item.ID] = d.isHotfix(item)
+	}
+
+	rank := make(map[string]int, len(items))
+	for _, repoPath := range repos {
+		group := byRepo[repoPath]
+		var ordering issues.Ordering
+		if wfCfg, ok := d.lookupWorkflowConfig(repoPath); ok {
+			ordering = issueOrdering(wfCfg)
+		}
+		slices.SortStableFunc(group, func(a, b daemonstate.WorkItem) int {
+			if c := compareHotfix(hotfix[a.ID], hotfix[b.ID]); c != 0 {
+				return c
+			}
+			if c := ordering.Compare(issueFromWorkItem(a), issueFromWorkItem(b)); c != 0 {
+				return c
+			}
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+		for i, item := range group {
+			rank[item.ID] = i
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b daemonstate.WorkItem) int {
+		if c := compareHotfix(hotfix[a.ID], hotfix[b.ID]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(rank[a.ID], rank[b.ID]); c != 0 {
+			return c
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+// compareHotfix orders hotfixes before everything else.
+func compareHotfix(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// workItemRepoPath returns the repo a work item belongs to, from its
+// session or, before a session exists, the repo recorded when it was queued.
+func (d *Daemon) workItemRepoPath(item daemonstate.WorkItem) string {
+	if sess := d.config.GetSession(item.SessionID); sess != nil {
+		return sess.RepoPath
+	}
+	repoPath, _ := item.StepData["_repo_path"].(string)
+	return repoPath
+}
+
+// issueOrdering returns the queue ordering policy configured for a repo.
+func issueOrdering(wfCfg *workflow.Config) issues.Ordering {
+	return issues.Ordering{
+		Policy:         wfCfg.Source.Order,
+		LabelWeights:   wfCfg.Source.LabelWeights,
+		DeadlineWindow: wfCfg.DeadlineWindow(),
+	}
 }
 
 // matchesRepoFilter checks if a repo path matches the daemon's repo filter.
@@ -308,16 +558,20 @@ func (d *Daemon) checkLinkedPRsAndUnqueue(ctx context.Context, repoPath string,
 	log.Info("existing PR found for issue",
 		"pr", pr.Number, "state", pr.State, "branch", pr.HeadRefName)
 
+	workflowName := d.selectWorkflow(repoPath, issue.Labels)
 	item := &daemonstate.WorkItem{
-		ID: fmt.Sprintf("%s-%s", repoPath, issue.ID),
+		ID: d.workItemID(repoPath, issues.SourceGitHub, issue.ID),
 		IssueRef: config.IssueRef{
 			Source: string(issues.SourceGitHub),
 			ID:     issue.ID,
 			Title:  issue.Title,
 			URL:    issue.URL,
+			Epic:   issues.ParseEpicRef(issue.Body),
 		},
-		Branch: pr.HeadRefName,
-		PRURL:  pr.URL,
+		Branch:          pr.HeadRefName,
+		PRURL:           pr.URL,
+		Workflow:        workflowName,
+		WorkflowVersion: d.workflowVersionFor(repoPath, workflowName),
 		StepData: map[string]any{
 			"_repo_path": repoPath,
 		},
@@ -367,7 +621,7 @@ func (d *Daemon) checkLinkedPRsAndUnqueue(ctx context.Context, repoPath string,
 	// them (e.g. "await_ci" becomes "_t_ci_await_ci"). Search by event type
 	// in priority order: CI first, then review, then mergeable.
 	// Compute this before creating the session to avoid orphaned session entries on failure.
-	engine := d.getEngine(repoPath)
+	engine := d.getItemEngine(repoPath, *item)
 	recoveryStep := engine.FindFirstWaitStateByEvents([]string{
 		"ci.complete",
 		"ci.wait_for_checks",
@@ -536,10 +790,11 @@ func (d *Daemon) reconcileClosedIssues(ctx context.Context) {
 //
 // Each firing gets a unique ID based on the unix timestamp so cron expressions
 // like "*/15 * * * *" enqueue a new item every 15 minutes as expected.
-// HasWorkItemForIssue prevents double-enqueue only while a previous item from
-// the same trigger is still active or queued.
+// Unless the trigger sets overlap: allow, a firing is skipped while a previous
+// item from the same trigger is still active or queued.
 func (d *Daemon) injectScheduledIssue(ctx context.Context, repoPath string, trigger workflow.TriggerConfig) {
-	log := d.logger.With("component", "scheduler", "repo", repoPath, "state", trigger.State)
+	key := trigger.Key()
+	log := d.logger.With("component", "scheduler", "repo", repoPath, "trigger", key, "state", trigger.State)
 
 	if d.configSavePaused {
 		log.Warn("config save failures exceed threshold, skipping scheduled trigger")
@@ -559,20 +814,26 @@ func (d *Daemon) injectScheduledIssue(ctx context.Context, repoPath string, trig
 	// Unique ID per firing so each cron tick enqueues a fresh work item.
 	// Include repoPath to avoid collisions when multiple repos share the same state name.
 	ts := time.Now().UTC().UnixNano()
-	issueID := fmt.Sprintf("scheduled-%s-%s-%d", repoPath, trigger.State, ts)
+	issueID := fmt.Sprintf("scheduled-%s-%s-%d", repoPath, key, ts)
 
 	// Don't enqueue if a previous firing of the same trigger is still
 	// active or queued. Once the previous item completes (terminal), the
 	// next firing is allowed through.
-	if d.hasActiveScheduledItem(repoPath, trigger.State) {
+	if trigger.Overlap != workflow.TriggerOverlapAllow && d.hasActiveScheduledItem(repoPath, key) {
 		log.Debug("previous scheduled item still active, skipping")
 		return
 	}
 
+	prompt, err := workflow.ResolveSystemPrompt(trigger.Prompt, repoPath)
+	if err != nil {
+		log.Warn("failed to resolve scheduled trigger prompt, skipping", "error", err)
+		return
+	}
+
 	wfCfg := d.getWorkflowConfig(repoPath)
 	provider := issues.Source(wfCfg.Source.Provider)
 
-	title := fmt.Sprintf("Scheduled: %s", trigger.State)
+	title := fmt.Sprintf("Scheduled: %s", key)
 	item := &daemonstate.WorkItem{
 		ID: fmt.Sprintf("%s-%s", repoPath, issueID),
 		IssueRef: config.IssueRef{
@@ -580,13 +841,18 @@ func (d *Daemon) injectScheduledIssue(ctx context.Context, repoPath string, trig
 			ID:     issueID,
 			Title:  title,
 		},
-		CurrentStep: trigger.State,
+		CurrentStep:     trigger.State,
+		WorkflowVersion: d.workflowVersion(repoPath),
 		StepData: map[string]any{
 			"_repo_path":         repoPath,
 			"_synthetic":         "true",
 			"_scheduled_trigger": trigger.Schedule,
+			"_trigger":           key,
 		},
 	}
+	if prompt != "" {
+		item.StepData["issue_body"] = prompt
+	}
 
 	d.state.AddWorkItem(item)
 	log.Info("enqueued scheduled work item", "issueID", issueID, "title", title, "workItemID", item.ID)
@@ -627,29 +893,43 @@ func (d *Daemon) isIssueClosed(ctx context.Context, repoPath string, item daemon
 }
 
 // hasActiveScheduledItem returns true if a non-terminal synthetic work item
-// already exists for the given repo and trigger state. This prevents enqueuing
+// already exists for the given repo and trigger. This prevents enqueuing
 // a new firing while a previous one is still active or queued, without imposing
 // an artificial once-per-day limit. Scoped by repoPath so triggers in different
 // repos don't block each other.
-func (d *Daemon) hasActiveScheduledItem(repoPath, triggerState string) bool {
-	prefix := fmt.Sprintf("scheduled-%s-%s-", repoPath, triggerState)
-	for _, item := range d.state.GetActiveWorkItems() {
-		if item.StepData["_synthetic"] == "true" && strings.HasPrefix(item.IssueRef.ID, prefix) {
-			return true
-		}
-	}
-	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemQueued) {
-		if item.StepData["_synthetic"] == "true" && strings.HasPrefix(item.IssueRef.ID, prefix) {
+func (d *Daemon) hasActiveScheduledItem(repoPath, triggerKey string) bool {
+	items := append(d.state.GetActiveWorkItems(), d.state.GetWorkItemsByState(daemonstate.WorkItemQueued)...)
+	for _, item := range items {
+		if item.StepData["_synthetic"] == "true" && scheduledTriggerKey(repoPath, item) == triggerKey {
 			return true
 		}
 	}
 	return false
 }
 
-// hasExistingSession checks if a session already exists for the given issue.
-func (d *Daemon) hasExistingSession(repoPath, issueID string) bool {
+// scheduledTriggerKey returns the key of the trigger that created a synthetic
+// work item in repoPath, or "" if it belongs to another repo. Items queued
+// before the key was recorded carry it in their ID:
+// scheduled-<repo>-<key>-<timestamp>.
+func scheduledTriggerKey(repoPath string, item daemonstate.WorkItem) string {
+	rest, ok := strings.CutPrefix(item.IssueRef.ID, "scheduled-"+repoPath+"-")
+	if !ok {
+		return ""
+	}
+	if key, ok := item.StepData["_trigger"].(string); ok {
+		return key
+	}
+	if i := strings.LastIndex(rest, "-"); i >= 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+// hasExistingSession checks if a session already exists for the given issue
+// from provider.
+func (d *Daemon) hasExistingSession(repoPath string, provider issues.Source, issueID string) bool {
 	for _, sess := range d.config.GetSessions() {
-		if sess.RepoPath == repoPath && sess.IssueRef != nil && sess.IssueRef.ID == issueID {
+		if sess.RepoPath == repoPath && sess.IssueRef != nil && sess.IssueRef.ID == issueID && sess.IssueRef.Source == string(provider) {
 			return true
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -106,6 +107,87 @@ func TestFetchIssuesForProvider_GitHub_CustomLabel(t *testing.T) {
 	}
 }
 
+func TestFetchIssuesForProvider_GitHub_Assignee(t *testing.T) {
+	cfg := testConfig()
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"issue", "list"}, exec.MockResponse{Stdout: []byte("[]")})
+
+	d := testDaemonWithExec(cfg, mockExec)
+
+	wfCfg := workflow.DefaultWorkflowConfig()
+	wfCfg.Source.Provider = "github"
+	wfCfg.Source.Filter.Assignee = "erg-bot"
+
+	if _, err := d.fetchIssuesForProvider(context.Background(), "/test/repo", wfCfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := mockExec.GetCalls()
+	if len(calls) != 1 || !slices.Contains(calls[0].Args, "--assignee") || calls[0].Args[len(calls[0].Args)-1] != "erg-bot" {
+		t.Errorf("expected gh issue list --assignee erg-bot, got %+v", calls)
+	}
+}
+
+func TestFetchIssuesForProvider_LabelExpression(t *testing.T) {
+	issuesJSON := []byte(`[
+		{"number": 1, "title": "Ready", "labels": [{"name": "ai-ready"}, {"name": "bug"}]},
+		{"number": 2, "title": "Blocked", "labels": [{"name": "ai-ready"}, {"name": "Blocked"}]},
+		{"number": 3, "title": "Chore", "labels": [{"name": "chore"}]},
+		{"number": 4, "title": "Unlabeled"}
+	]`)
+
+	tests := []struct {
+		expr      string
+		wantLabel string // server-side --label, "" for none
+		wantIDs   []string
+	}{
+		{"ai-ready && !blocked", "ai-ready", []string{"1"}},
+		{"bug || chore", "", []string{"1", "3"}},
+		{"!blocked", "", []string{"1", "3", "4"}},
+	}
+	for _, tt := range tests {
+		mockExec := exec.NewMockExecutor(nil)
+		mockExec.AddPrefixMatch("gh", []string{"issue", "list"}, exec.MockResponse{Stdout: issuesJSON})
+		d := testDaemonWithExec(testConfig(), mockExec)
+
+		wfCfg := workflow.DefaultWorkflowConfig()
+		wfCfg.Source.Provider = "github"
+		wfCfg.Source.Filter.Label = tt.expr
+
+		fetched, err := d.fetchIssuesForProvider(context.Background(), "/test/repo", wfCfg)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.expr, err)
+		}
+		var ids []string
+		for _, issue := range fetched {
+			ids = append(ids, issue.ID)
+		}
+		if !slices.Equal(ids, tt.wantIDs) {
+			t.Errorf("%q: got issues %v, want %v", tt.expr, ids, tt.wantIDs)
+		}
+
+		args := mockExec.GetCalls()[0].Args
+		i := slices.Index(args, "--label")
+		switch {
+		case tt.wantLabel == "" && i >= 0:
+			t.Errorf("%q: expected no --label, got %v", tt.expr, args)
+		case tt.wantLabel != "" && (i < 0 || args[i+1] != tt.wantLabel):
+			t.Errorf("%q: expected --label %s, got %v", tt.expr, tt.wantLabel, args)
+		}
+	}
+}
+
+func TestFetchIssuesForProvider_InvalidLabelExpression(t *testing.T) {
+	d := testDaemon(testConfig())
+
+	wfCfg := workflow.DefaultWorkflowConfig()
+	wfCfg.Source.Provider = "github"
+	wfCfg.Source.Filter.Label = "bug &&"
+
+	if _, err := d.fetchIssuesForProvider(context.Background(), "/test/repo", wfCfg); err == nil {
+		t.Error("expected error for invalid label expression")
+	}
+}
+
 func TestFetchIssuesForProvider_UnknownProvider(t *testing.T) {
 	cfg := testConfig()
 	d := testDaemon(cfg)
@@ -1259,3 +1341,104 @@ func TestReconcileClosedIssues_APIErrorFailsOpen(t *testing.T) {
 		t.Error("expected work item to remain active when API fails (fail open)")
 	}
 }
+
+func TestPollForNewIssues_QueuesMostUrgentFirst(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.maxConcurrent = 2
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Title: "No priority", Source: issues.SourceLinear},
+		{ID: "ENG-2", Title: "Low", Source: issues.SourceLinear, Priority: 4},
+		{ID: "ENG-3", Title: "Urgent", Source: issues.SourceLinear, Priority: 1},
+	})
+
+	d.pollForNewIssues(context.Background())
+
+	for _, id := range []string{"ENG-3", "ENG-2"} {
+		if _, ok := d.state.GetWorkItem("/test/repo-" + id); !ok {
+			t.Errorf("expected %s queued", id)
+		}
+	}
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-1"); ok {
+		t.Error("expected unprioritized issue to wait for a free slot")
+	}
+	if item, _ := d.state.GetWorkItem("/test/repo-ENG-3"); item.IssueRef.Priority != 1 {
+		t.Errorf("expected priority recorded on work item, got %d", item.IssueRef.Priority)
+	}
+}
+
+func TestSortQueuedItems(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	now := time.Now()
+	items := []daemonstate.WorkItem{
+		{ID: "none", CreatedAt: now},
+		{ID: "high-late", IssueRef: config.IssueRef{Priority: 2}, CreatedAt: now.Add(time.Minute)},
+		{ID: "urgent", IssueRef: config.IssueRef{Priority: 1}, CreatedAt: now.Add(2 * time.Minute)},
+		{ID: "high-early", IssueRef: config.IssueRef{Priority: 2}, CreatedAt: now},
+	}
+
+	d.sortQueuedItems(items)
+
+	want := []string{"urgent", "high-early", "high-late", "none"}
+	for i, item := range items {
+		if item.ID != want[i] {
+			t.Fatalf("position %d: got %s, want %s", i, item.ID, want[i])
+		}
+	}
+}
+
+func TestSortQueuedItems_InterleavesReposByTheirOwnOrder(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	d.workflowConfigs["/other/repo"] = &workflow.Config{
+		Source: workflow.SourceConfig{Provider: "github", Order: issues.OrderNewestFirst},
+	}
+	now := time.Now()
+	inRepo := func(id, repo string, priority int, opened, queued time.Time) daemonstate.WorkItem {
+		return daemonstate.WorkItem{
+			ID:        id,
+			IssueRef:  config.IssueRef{Priority: priority, CreatedAt: opened},
+			StepData:  map[string]any{"_repo_path": repo},
+			CreatedAt: queued,
+		}
+	}
+	items := []daemonstate.WorkItem{
+		inRepo("test-low", "/test/repo", 4, now.Add(-time.Hour), now),
+		inRepo("other-old", "/other/repo", 1, now.Add(-48*time.Hour), now),
+		inRepo("test-urgent", "/test/repo", 1, now.Add(-time.Hour), now.Add(time.Minute)),
+		inRepo("other-new", "/other/repo", 4, now.Add(-time.Hour), now.Add(2*time.Minute)),
+	}
+
+	d.sortQueuedItems(items)
+
+	// /test/repo orders by priority and /other/repo newest-first; the repos
+	// then alternate, each round in queue order.
+	want := []string{"test-urgent", "other-new", "test-low", "other-old"}
+	for i, item := range items {
+		if item.ID != want[i] {
+			t.Fatalf("position %d: got %s, want %s", i, item.ID, want[i])
+		}
+	}
+}
+
+func TestPollForNewIssues_FollowsOrderPolicy(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.maxConcurrent = 1
+	d.workflowConfigs["/test/repo"].Source.Order = issues.OrderOldestFirst
+	now := time.Now()
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Source: issues.SourceLinear, Priority: 1, CreatedAt: now},
+		{ID: "ENG-2", Source: issues.SourceLinear, Priority: 4, CreatedAt: now.Add(-24 * time.Hour), Labels: []string{"queued"}},
+	})
+
+	d.pollForNewIssues(context.Background())
+
+	item, ok := d.state.GetWorkItem("/test/repo-ENG-2")
+	if !ok {
+		t.Fatal("expected oldest issue queued first")
+	}
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-1"); ok {
+		t.Error("expected newer issue to wait for a free slot")
+	}
+	if !item.IssueRef.CreatedAt.Equal(now.Add(-24*time.Hour)) || len(item.IssueRef.Labels) != 1 {
+		t.Errorf("expected creation time and labels recorded on work item, got %+v", item.IssueRef)
+	}
+}
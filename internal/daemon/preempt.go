@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/sanitize"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// phasePreempted marks a coding item whose session was paused to make room
+// for a hotfix, or interrupted by a restart (see Recover). It holds no slot;
+// once its worker has exited and its work is checkpointed,
+// resumePreemptedItems restarts it when a slot frees up.
+const phasePreempted = "preempted"
+
+// preemptionCheckpointMessage is the commit that saves a preempted
+// session's uncommitted work on its branch.
+const preemptionCheckpointMessage = "WIP: checkpoint before preemption"
+
+// preemptionResumeNote tells a resumed session that it is picking up work
+// another session started.
+const preemptionResumeNote = "This task was paused to make room for an urgent issue and is now being resumed. " +
+	"Work already done is committed on the current branch (the last commit may be a WIP checkpoint): " +
+	"review it with git log and git diff, then continue from where it left off."
+
+// isHotfix reports whether item came from an issue carrying one of its
+// repo's hotfix labels.
+func (d *Daemon) isHotfix(item daemonstate.WorkItem) bool {
+	repoPath := d.workItemRepoPath(item)
+	wfCfg, ok := d.lookupWorkflowConfig(repoPath)
+	if ok {
+		wfCfg = d.getItemWorkflowConfig(repoPath, item)
+	}
+	return wfCfg.IsHotfix(item.IssueRef.Labels)
+}
+
+// preemptionEnabled reports whether hotfixes in repoPath may pause running
+// sessions.
+func (d *Daemon) preemptionEnabled(repoPath string) bool {
+	wfCfg, ok := d.lookupWorkflowConfig(repoPath)
+	return ok && wfCfg.PreemptionEnabled()
+}
+
+// preemptFor pauses the lowest priority coding session to free a slot for
+// the hotfix item. With sameRepo set only sessions in repoPath are
+// candidates, since only those free a slot under the repo's own limit.
+// It returns false when no session can be paused.
+func (d *Daemon) preemptFor(item daemonstate.WorkItem, repoPath string, sameRepo bool) bool {
+	var victim *daemonstate.WorkItem
+	for _, candidate := range d.state.GetActiveWorkItems() {
+		if !candidate.ConsumesSlot() || candidate.Phase != "async_pending" || d.isHotfix(candidate) {
+			continue
+		}
+		candidateRepo := d.workItemRepoPath(candidate)
+		if sameRepo && candidateRepo != repoPath {
+			continue
+		}
+		if !d.isCodingStep(candidateRepo, candidate) {
+			continue
+		}
+		if victim == nil || comparePreemption(candidate, *victim) < 0 {
+			victim = &candidate
+		}
+	}
+	if victim == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	w, ok := d.workers[victim.ID]
+	d.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	d.state.UpdateWorkItem(victim.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = phasePreempted
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_preempted_by"] = item.ID
+		it.UpdatedAt = time.Now()
+	})
+	w.Cancel()
+
+	d.logger.Info("preempted coding session for hotfix", "event", "preempt",
+		"workItem", victim.ID, "issue", victim.IssueRef.ID, "hotfix", item.ID, "hotfixIssue", item.IssueRef.ID)
+	return true
+}
+
+// comparePreemption returns a negative number when a should be paused
+// before b: lower issue priority first, then the session that started its
+// step most recently, as it has the least work to lose.
+func comparePreemption(a, b daemonstate.WorkItem) int {
+	if c := issues.ComparePriority(b.IssueRef.Priority, a.IssueRef.Priority); c != 0 {
+		return c
+	}
+	return b.StepEnteredAt.Compare(a.StepEnteredAt)
+}
+
+// isCodingStep reports whether item is on an ai.code step, the only kind of
+// session that can be paused and resumed.
+func (d *Daemon) isCodingStep(repoPath string, item daemonstate.WorkItem) bool {
+	engine := d.getItemEngine(repoPath, item)
+	if engine == nil {
+		return false
+	}
+	state := engine.GetState(item.CurrentStep)
+	return state != nil && state.Action == "ai.code"
+}
+
+// checkpointPreempted commits whatever the preempted item's session left
+// uncommitted, so nothing is lost if its worktree goes away before it
+// resumes.
+func (d *Daemon) checkpointPreempted(ctx context.Context, item daemonstate.WorkItem) {
+	sess := d.config.GetSession(item.SessionID)
+	if sess == nil || sess.WorkTree == "" {
+		return
+	}
+	gitCtx, cancel := context.WithTimeout(ctx, timeoutGitRewrite)
+	defer cancel()
+
+	status, err := d.gitService.GetWorktreeStatus(gitCtx, sess.WorkTree)
+	if err != nil {
+		d.logger.Warn("failed to check preempted worktree", "workItem", item.ID, "error", err)
+		return
+	}
+	if !status.HasChanges {
+		return
+	}
+	if err := d.gitService.CommitAll(gitCtx, sess.WorkTree, preemptionCheckpointMessage); err != nil {
+		d.logger.Warn("failed to checkpoint preempted session", "workItem", item.ID, "error", err)
+		return
+	}
+	d.logger.Info("checkpointed preempted session", "workItem", item.ID, "files", len(status.Files))
+}
+
+// resumePreemptedItems restarts preempted sessions while slots are free,
+// most urgent first. Items whose worker is still shutting down wait for the
+// next tick.
+func (d *Daemon) resumePreemptedItems(ctx context.Context) {
+	var preempted []daemonstate.WorkItem
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase == phasePreempted {
+			preempted = append(preempted, item)
+		}
+	}
+	slices.SortFunc(preempted, func(a, b daemonstate.WorkItem) int {
+		if c := issues.ComparePriority(a.IssueRef.Priority, b.IssueRef.Priority); c != 0 {
+			return c
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	maxConcurrent := d.getMaxConcurrent()
+	for _, item := range preempted {
+		if d.activeSlotCount() >= maxConcurrent {
+			return
+		}
+		d.mu.Lock()
+		_, running := d.workers[item.ID]
+		d.mu.Unlock()
+		if running {
+			continue
+		}
+		repoPath := d.workItemRepoPath(item)
+		if limit := d.getRepoMaxConcurrent(repoPath); limit > 0 && d.repoSlotCount(repoPath) >= limit {
+			continue
+		}
+		if d.budgetPaused(repoPath) {
+			continue
+		}
+		d.resumePreempted(ctx, item)
+	}
+}
+
+// resumePreempted starts a new coding session on a preempted item's branch.
+func (d *Daemon) resumePreempted(ctx context.Context, item daemonstate.WorkItem) {
+	sess := d.config.GetSession(item.SessionID)
+	if sess == nil {
+		d.logger.Warn("preempted item has no session, queuing its step again", "workItem", item.ID)
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			it.State = daemonstate.WorkItemQueued
+			it.Phase = "idle"
+			it.UpdatedAt = time.Now()
+		})
+		return
+	}
+	sess = d.refreshStaleSession(ctx, item, sess)
+
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	params := workflow.NewParamHelper(nil)
+	if state := wfCfg.States[item.CurrentStep]; state != nil {
+		params = workflow.NewParamHelper(state.Params)
+	}
+
+	note := preemptionResumeNote
+	if recovered, _ := item.StepData["_recovered"].(bool); recovered {
+		note = recoveryResumeNote
+	}
+	if pausedSession, ok := item.StepData["_shutdown_checkpoint"].(string); ok {
+		note = shutdownResumeNote
+		// A session that resumes its own conversation needs no transcript.
+		if pausedSession != sess.ID || !resumesConversation(sess) {
+			if msgs, err := config.LoadSessionMessages(pausedSession); err == nil {
+				if transcript := checkpointTranscript(msgs); transcript != "" {
+					note += "\n\nThe paused session ended with:\n" + sanitize.UntrustedContent("paused_session", transcript)
+				}
+			}
+		}
+	}
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = "async_pending"
+		delete(it.StepData, "_preempted_by")
+		delete(it.StepData, "_recovered")
+		delete(it.StepData, "_shutdown_checkpoint")
+		it.UpdatedAt = time.Now()
+	})
+	item, _ = d.state.GetWorkItem(item.ID)
+	d.startCodingWorker(ctx, item, sess, wfCfg, params, note)
+
+	d.logger.Info("resumed preempted session", "event", "preempt.resume",
+		"workItem", item.ID, "issue", item.IssueRef.ID, "sessionID", sess.ID)
+}
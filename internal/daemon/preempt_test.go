@@ -0,0 +1,178 @@
+package daemon
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/worker"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// addCodingItem adds an item coding in sess with a worker registered.
+func addCodingItem(d *Daemon, id string, priority int, labels ...string) {
+	sess := testSession("sess-" + id)
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        id,
+		IssueRef:  config.IssueRef{Source: "github", ID: id, Priority: priority, Labels: labels},
+		SessionID: sess.ID,
+		StepData:  map[string]any{},
+	})
+	d.state.AdvanceWorkItem(id, "coding", "async_pending")
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) { it.State = daemonstate.WorkItemActive })
+	d.workers[id] = worker.NewDoneWorker()
+}
+
+func enablePreemption(d *Daemon) {
+	preempt := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{
+		Priority: &workflow.PriorityConfig{Preempt: &preempt},
+	}
+}
+
+func TestSortQueuedItems_HotfixFirst(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	now := time.Now()
+	items := []daemonstate.WorkItem{
+		{ID: "urgent", IssueRef: config.IssueRef{Priority: 1}, CreatedAt: now},
+		{ID: "hotfix", IssueRef: config.IssueRef{Priority: 4, Labels: []string{"HotFix"}}, CreatedAt: now.Add(time.Minute)},
+		{ID: "low", IssueRef: config.IssueRef{Priority: 4}, CreatedAt: now},
+	}
+
+	d.sortQueuedItems(items)
+
+	var got []string
+	for _, item := range items {
+		got = append(got, item.ID)
+	}
+	if want := []string{"hotfix", "urgent", "low"}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestPreemptFor_PausesLowestPriorityCodingSession(t *testing.T) {
+	d := testDaemon(testConfig())
+	addCodingItem(d, "urgent", 1)
+	addCodingItem(d, "low", 4)
+	addCodingItem(d, "other-hotfix", 4, "hotfix")
+	hotfix := daemonstate.WorkItem{ID: "fix", IssueRef: config.IssueRef{ID: "fix", Labels: []string{"hotfix"}}}
+
+	if !d.preemptFor(hotfix, "/test/repo", false) {
+		t.Fatal("expected a session to be preempted")
+	}
+
+	low, _ := d.state.GetWorkItem("low")
+	if low.Phase != phasePreempted || low.StepData["_preempted_by"] != "fix" {
+		t.Errorf("low = phase %q, step data %v; want preempted by fix", low.Phase, low.StepData)
+	}
+	if low.ConsumesSlot() {
+		t.Error("preempted item should not hold a slot")
+	}
+	for _, id := range []string{"urgent", "other-hotfix"} {
+		if item, _ := d.state.GetWorkItem(id); item.Phase != "async_pending" {
+			t.Errorf("%s phase = %q, want async_pending", id, item.Phase)
+		}
+	}
+}
+
+func TestPreemptFor_NothingToPreempt(t *testing.T) {
+	d := testDaemon(testConfig())
+	addCodingItem(d, "other-hotfix", 4, "hotfix")
+	hotfix := daemonstate.WorkItem{ID: "fix", IssueRef: config.IssueRef{Labels: []string{"hotfix"}}}
+
+	if d.preemptFor(hotfix, "/test/repo", false) {
+		t.Error("hotfixes must not preempt each other")
+	}
+	if d.preemptFor(hotfix, "/other/repo", true) {
+		t.Error("expected no candidates in another repo")
+	}
+}
+
+func TestStartQueuedItems_PreemptsOnlyWhenEnabled(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.maxConcurrent = 1
+	addCodingItem(d, "low", 4)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       "fix",
+		IssueRef: config.IssueRef{Source: "github", ID: "fix", Labels: []string{"hotfix"}},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+
+	d.startQueuedItems(t.Context())
+	if low, _ := d.state.GetWorkItem("low"); low.Phase != "async_pending" {
+		t.Fatalf("preempted with preemption disabled: phase %q", low.Phase)
+	}
+
+	enablePreemption(d)
+	d.startQueuedItems(t.Context())
+	if low, _ := d.state.GetWorkItem("low"); low.Phase != phasePreempted {
+		t.Errorf("low phase = %q, want %q", low.Phase, phasePreempted)
+	}
+	if fix, _ := d.state.GetWorkItem("fix"); fix.State == daemonstate.WorkItemQueued {
+		t.Error("expected hotfix to start in the freed slot")
+	}
+}
+
+func TestCollectCompletedWorkers_CheckpointsPreempted(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("git", []string{"status", "--porcelain"}, exec.MockResponse{Stdout: []byte(" M main.go\n")})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	addCodingItem(d, "low", 4)
+	d.state.UpdateWorkItem("low", func(it *daemonstate.WorkItem) { it.Phase = phasePreempted })
+
+	d.collectCompletedWorkers(t.Context())
+
+	var committed bool
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && slices.Equal(call.Args, []string{"commit", "-m", preemptionCheckpointMessage}) {
+			committed = call.Dir == "/test/worktree-sess-low"
+		}
+	}
+	if !committed {
+		t.Error("expected uncommitted work to be checkpointed in the session's worktree")
+	}
+	if low, _ := d.state.GetWorkItem("low"); low.Phase != phasePreempted {
+		t.Errorf("phase = %q, want it left preempted", low.Phase)
+	}
+}
+
+func TestResumePreemptedItems(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"api", "repos/:owner/:repo/issues/"}, exec.MockResponse{Stdout: []byte(`[]`)})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	d.maxConcurrent = 1
+	addCodingItem(d, "busy", 1)
+	addCodingItem(d, "low", 4)
+	d.state.UpdateWorkItem("low", func(it *daemonstate.WorkItem) { it.Phase = phasePreempted })
+	delete(d.workers, "low")
+
+	d.resumePreemptedItems(t.Context())
+	if low, _ := d.state.GetWorkItem("low"); low.Phase != phasePreempted {
+		t.Fatal("resumed without a free slot")
+	}
+
+	d.state.UpdateWorkItem("busy", func(it *daemonstate.WorkItem) { it.Phase = "idle" })
+	d.resumePreemptedItems(t.Context())
+
+	low, _ := d.state.GetWorkItem("low")
+	if low.Phase != "async_pending" {
+		t.Fatalf("phase = %q, want async_pending", low.Phase)
+	}
+	if low.SessionID == "sess-low" {
+		t.Error("expected a fresh session for the resumed conversation")
+	}
+	d.mu.Lock()
+	w := d.workers["low"]
+	d.mu.Unlock()
+	if w == nil {
+		t.Fatal("expected a coding worker to be started")
+	}
+	if !strings.HasPrefix(w.InitialMsg(), preemptionResumeNote) {
+		t.Errorf("initial message should start with the resume note, got:\n%s", w.InitialMsg())
+	}
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// errEnvSetupFailed marks a session whose container failed its preflight
+// checks, so the agent never started.
+var errEnvSetupFailed = errors.New("session environment setup failed")
+
+// preflightLogLimit caps the preflight output kept on a work item.
+const preflightLogLimit = 8 << 10
+
+// runSessionPreflight runs the preflight checks of the workflow the item
+// runs on for a containerized session about to start, in image when a scoped image replaced the repo's.
+// A failure's output is kept in the item's preflight_log step data, and the
+// returned error wraps errEnvSetupFailed so the item is routed to
+// env_setup_failed instead of being handed to the agent.
+func (d *Daemon) runSessionPreflight(ctx context.Context, sess *config.Session, item daemonstate.WorkItem, image string) error {
+	if !sess.Containerized {
+		return nil
+	}
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	if wfCfg.Preflight == nil {
+		return nil
+	}
+	p := container.Preflight{
+		SessionID: sess.ID,
+		Image:     image,
+		Worktree:  sess.WorkTree,
+		RepoPath:  sess.RepoPath,
+		Network:   wfCfg.ContainerNetwork(item.CurrentStep),
+		Commands:  wfCfg.Preflight.Commands,
+	}
+	// Only images erg builds for the repo's detected languages are sure to
+	// have their toolchains; scoped images have just the components'.
+	if c := wfCfg.Container; image == "" && (c == nil || (c.Image == "" && c.Dockerfile == "")) {
+		p.Toolchains = d.repoToolchains(ctx, sess.RepoPath)
+	}
+	if p.Image == "" {
+		p.Image = d.containerImageForRepo(sess.RepoPath)
+	}
+
+	run := d.runPreflight
+	if run == nil {
+		run = container.RunPreflight
+	}
+	ctx, cancel := context.WithTimeout(ctx, wfCfg.Preflight.TimeoutOrDefault())
+	defer cancel()
+	err := run(ctx, p)
+	if err == nil {
+		d.logger.Debug("session preflight passed", "sessionID", sess.ID, "workItem", item.ID)
+		return nil
+	}
+
+	output := err.Error()
+	var pfErr *container.PreflightError
+	if errors.As(err, &pfErr) {
+		output = pfErr.Log
+	}
+	if len(output) > preflightLogLimit {
+		output = "…" + output[len(output)-preflightLogLimit:]
+	}
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["preflight_log"] = output
+	})
+	d.logger.Warn("session preflight failed", "sessionID", sess.ID, "workItem", item.ID, "error", err)
+	return fmt.Errorf("%w: %w", errEnvSetupFailed, err)
+}
@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// preflightTestDaemon returns a daemon whose /test/repo workflow has the
+// given preflight and an env_setup_failed state, with item-pf in coding.
+func preflightTestDaemon(t *testing.T, preflight *workflow.PreflightConfig) *Daemon {
+	t.Helper()
+	d := testDaemon(testConfig())
+	wfCfg := &workflow.Config{
+		Start:     "coding",
+		Source:    workflow.SourceConfig{Provider: "github"},
+		Container: &workflow.ContainerConfig{Image: "ghcr.io/acme/tools:1"},
+		Preflight: preflight,
+		States: map[string]*workflow.State{
+			"coding":                     {Type: workflow.StateTypeTask, Action: "ai.code", Next: "done", Error: "failed"},
+			"done":                       {Type: workflow.StateTypeSucceed},
+			"failed":                     {Type: workflow.StateTypeFail},
+			workflow.EnvSetupFailedState: {Type: workflow.StateTypeFail},
+		},
+	}
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, d.buildActionRegistry(), newEventChecker(d), d.logger)
+	d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, _ bool, _ []claude.Message) claude.RunnerInterface {
+		return claude.NewMockRunner(sessionID, false, nil)
+	})
+
+	sess := testSession("sess-pf")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-pf",
+		IssueRef:    config.IssueRef{Source: "github", ID: "83"},
+		SessionID:   sess.ID,
+		CurrentStep: "coding",
+		StepData:    map[string]any{},
+	})
+	return d
+}
+
+func TestCreateWorkerWithPrompt_RunsPreflight(t *testing.T) {
+	d := preflightTestDaemon(t, &workflow.PreflightConfig{Commands: []string{"go build ./..."}})
+	var got container.Preflight
+	d.runPreflight = func(_ context.Context, p container.Preflight) error {
+		got = p
+		return nil
+	}
+	sess := d.config.GetSession("sess-pf")
+	item, _ := d.state.GetWorkItem("item-pf")
+
+	w := d.createWorkerWithPrompt(context.Background(), item, sess, "go", "")
+
+	if w.Done() {
+		t.Fatalf("expected a runnable worker after preflight passed, got err %v", w.ExitError())
+	}
+	if got.SessionID != "sess-pf" || got.RepoPath != "/test/repo" || !slices.Equal(got.Commands, []string{"go build ./..."}) {
+		t.Errorf("unexpected preflight %+v", got)
+	}
+	// The workflow's own image isn't known to have the detected toolchains.
+	if got.Toolchains != nil {
+		t.Errorf("expected no toolchain checks for a configured image, got %v", got.Toolchains)
+	}
+}
+
+func TestCreateWorkerWithPrompt_PreflightFailure(t *testing.T) {
+	d := preflightTestDaemon(t, &workflow.PreflightConfig{Commands: []string{"npm ci"}})
+	d.runPreflight = func(context.Context, container.Preflight) error {
+		return &container.PreflightError{Check: "npm ci", Log: "$ npm ci\nnpm ERR! missing lockfile\n"}
+	}
+	sess := d.config.GetSession("sess-pf")
+	item, _ := d.state.GetWorkItem("item-pf")
+
+	w := d.createWorkerWithPrompt(context.Background(), item, sess, "go", "")
+
+	if !w.Done() || !errors.Is(w.ExitError(), errEnvSetupFailed) {
+		t.Fatalf("expected the worker failed with env setup, got done=%v err=%v", w.Done(), w.ExitError())
+	}
+	item, _ = d.state.GetWorkItem("item-pf")
+	if log, _ := item.StepData["preflight_log"].(string); !strings.Contains(log, "missing lockfile") {
+		t.Errorf("expected the preflight log kept, got %q", log)
+	}
+}
+
+func TestCreateWorkerWithPrompt_NoPreflightConfigured(t *testing.T) {
+	d := preflightTestDaemon(t, nil)
+	d.runPreflight = func(context.Context, container.Preflight) error {
+		t.Error("preflight run without a preflight section")
+		return nil
+	}
+	sess := d.config.GetSession("sess-pf")
+	item, _ := d.state.GetWorkItem("item-pf")
+
+	if w := d.createWorkerWithPrompt(context.Background(), item, sess, "go", ""); w.Done() {
+		t.Errorf("expected a runnable worker, got err %v", w.ExitError())
+	}
+}
+
+func TestRunSessionPreflight_UsesItemWorkflow(t *testing.T) {
+	d := preflightTestDaemon(t, nil)
+	addNamedWorkflow(t, d, "/test/repo", "web", &workflow.Config{
+		Preflight: &workflow.PreflightConfig{Commands: []string{"npm ci"}},
+		States:    map[string]*workflow.State{"coding": {Type: workflow.StateTypeTask, Action: "ai.code", Network: workflow.NetworkOffline}},
+		Settings: &workflow.SettingsConfig{
+			NetworkProfiles: map[string]string{workflow.NetworkOffline: "erg-offline"},
+		},
+	})
+	var got container.Preflight
+	d.runPreflight = func(_ context.Context, p container.Preflight) error {
+		got = p
+		return nil
+	}
+	sess := d.config.GetSession("sess-pf")
+	item, _ := d.state.GetWorkItem("item-pf")
+	item.Workflow = "web"
+
+	if err := d.runSessionPreflight(context.Background(), sess, item, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Commands, []string{"npm ci"}) || got.Network != "erg-offline" {
+		t.Errorf("expected the named workflow's preflight and network, got %+v", got)
+	}
+}
+
+func TestCollectCompletedWorkers_RoutesPreflightFailure(t *testing.T) {
+	d := preflightTestDaemon(t, &workflow.PreflightConfig{})
+	d.state.AdvanceWorkItem("item-pf", "coding", "async_pending")
+	d.state.UpdateWorkItem("item-pf", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	pfErr := &container.PreflightError{Check: "go version"}
+	d.workers["item-pf"] = worker.NewDoneWorkerWithError(errors.Join(errEnvSetupFailed, pfErr))
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-pf")
+	if item.CurrentStep != workflow.EnvSetupFailedState {
+		t.Errorf("expected the item routed to %s, got %s/%s", workflow.EnvSetupFailedState, item.CurrentStep, item.Phase)
+	}
+}
@@ -2,15 +2,18 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
-	osexec "os/exec"
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/claude"
 	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
 	"github.com/zhubert/erg/internal/daemonstate"
 	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/worker"
 	"github.com/zhubert/erg/internal/workflow"
 )
 
@@ -56,6 +59,16 @@ func (d *Daemon) collectCompletedWorkers(ctx context.Context) {
 			}
 		}
 
+		if err := d.pullRemoteWorkspace(ctx, item.SessionID); err != nil && cw.exitErr == nil {
+			cw.exitErr = err
+		}
+
+		var panicErr *worker.PanicError
+		if errors.As(cw.exitErr, &panicErr) {
+			d.quarantineWorkItem(ctx, item, panicErr, repo)
+			continue
+		}
+
 		if cw.exitErr != nil {
 			d.logger.Warn("worker completed with error", "event", "session.failed", "workItem", cw.workItemID, "step", item.CurrentStep, "phase", item.Phase, "error", cw.exitErr, "repo", repo)
 		} else {
@@ -63,6 +76,11 @@ func (d *Daemon) collectCompletedWorkers(ctx context.Context) {
 		}
 
 		switch item.Phase {
+		case phasePreempted:
+			// Cancelled to make room for a hotfix: save its work and leave
+			// it for resumePreemptedItems.
+			d.checkpointPreempted(ctx, item)
+
 		case "async_pending":
 			// If the worker failed due to Docker being unavailable,
 			// mark for retry instead of permanent failure.
@@ -76,12 +94,32 @@ func (d *Daemon) collectCompletedWorkers(ctx context.Context) {
 				d.state.SetErrorMessage(cw.workItemID, fmt.Sprintf("Docker unavailable: %v", cw.exitErr))
 				continue
 			}
+			// A Claude API failure on the step's model moves on to its
+			// next fallback model straight away, if it has one.
+			if d.retryOnFallbackModel(item, repo, cw.exitErr) {
+				continue
+			}
+			// Likewise, a Claude API outage parks the item until coding
+			// resumes rather than following the error edge.
+			if isClaudeUnavailable(cw.exitErr) {
+				d.markClaudeUnavailable(cw.exitErr)
+				d.parkForClaude(item, cw.exitErr)
+				continue
+			}
+			// A session killed for running out of memory gets one retry
+			// with the repo's higher limit, if it allows one.
+			if d.retryAfterOOM(item, repo, cw.exitErr) {
+				continue
+			}
 			// Main async action completed (e.g., coding)
 			d.handleAsyncComplete(ctx, item, cw.exitErr)
 
 		case "addressing_feedback":
 			// Feedback addressing completed -- push changes (skip if worker failed)
 			if cw.exitErr != nil {
+				if isClaudeUnavailable(cw.exitErr) {
+					d.markClaudeUnavailable(cw.exitErr)
+				}
 				d.logger.Warn("skipping push after failed feedback session", "workItem", cw.workItemID, "error", cw.exitErr)
 				d.state.SetErrorMessage(item.ID, fmt.Sprintf("feedback session failed: %v", cw.exitErr))
 				d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
@@ -106,7 +144,7 @@ func (d *Daemon) handleAsyncComplete(ctx context.Context, item daemonstate.WorkI
 		repoPath = sess.RepoPath
 	}
 
-	engine := d.getEngine(repoPath)
+	engine := d.getItemEngine(repoPath, item)
 	if engine == nil {
 		log.Error("no engine for repo", "repo", repoPath)
 		return
@@ -214,6 +252,46 @@ func (d *Daemon) handleAsyncComplete(ctx context.Context, item daemonstate.WorkI
 		}
 	}
 
+	// If the session submitted a structured result declaring failure, follow
+	// the error edge even though the process itself exited cleanly.
+	if exitErr == nil {
+		if fresh, ok := d.state.GetWorkItem(item.ID); ok {
+			item = fresh
+		}
+		if res, ok := workflow.ResultFromStepData(item.StepData); ok && res.Failed() {
+			log.Info("session reported unsuccessful result", "status", res.Status, "summary", res.Summary)
+			exitErr = fmt.Errorf("session reported %s: %s", res.Status, res.Summary)
+		}
+	}
+
+	// A session told to wrap up for its work item's spend cap has done
+	// what it could; the item goes to budget_exceeded rather than on.
+	if fresh, ok := d.state.GetWorkItem(item.ID); ok {
+		item = fresh
+	}
+	if d.budgetWrappedUp(item) {
+		log.Info("session wrapped up at the work item's spend cap", "costUSD", item.CostUSD)
+		exitErr = errBudgetExceeded
+	}
+
+	// An ai.code state run as a pipeline moves on to its next agent, and
+	// only advances once its reviewer is done.
+	if sess != nil {
+		started, err := d.advancePipeline(ctx, item, sess, exitErr)
+		if started {
+			return
+		}
+		exitErr = err
+		if fresh, ok := d.state.GetWorkItem(item.ID); ok {
+			item = fresh
+		}
+	}
+
+	// Keep what the session learned about the repo for later sessions.
+	if exitErr == nil && sess != nil {
+		d.recordLearnings(item, sess.RepoPath)
+	}
+
 	// For ai.plan steps, store the repo path in StepData (so workItemView can
 	// resolve it after the planning session is cleaned up) and release the session.
 	if exitErr == nil && state != nil && state.Action == "ai.plan" && sess != nil {
@@ -248,8 +326,20 @@ func (d *Daemon) handleAsyncComplete(ctx context.Context, item daemonstate.WorkI
 
 	// Normal async completion -- advance via engine
 	view := d.workItemView(item)
-	success := exitErr == nil
-	result, err := engine.AdvanceAfterAsync(view, success)
+	var result *workflow.StepResult
+	var err error
+	if errors.Is(exitErr, claude.ErrContainerOOM) {
+		// Give retry and catch rules a reason to match on.
+		result, err = engine.AdvanceAfterAsyncFailure(view, workflow.ErrorContainerOOM)
+	} else if errors.Is(exitErr, errEnvSetupFailed) {
+		d.state.SetErrorMessage(item.ID, exitErr.Error())
+		result, err = engine.AdvanceAfterAsyncFailure(view, workflow.ErrorEnvSetupFailed)
+	} else if errors.Is(exitErr, errBudgetExceeded) {
+		d.state.SetErrorMessage(item.ID, exitErr.Error())
+		result, err = engine.AdvanceAfterAsyncFailure(view, workflow.ErrorBudgetExceeded)
+	} else {
+		result, err = engine.AdvanceAfterAsync(view, exitErr == nil)
+	}
 	if err != nil {
 		log.Error("failed to advance after async", "error", err)
 		d.state.SetErrorMessage(item.ID, err.Error())
@@ -291,6 +381,10 @@ func (d *Daemon) executeSyncChain(ctx context.Context, itemID string, engine *wo
 			return
 		}
 
+		if d.holdForMutex(ctx, item, engine) {
+			return
+		}
+
 		// Run before-hooks for the current step (blocking -- failure stops execution)
 		beforeHooks := engine.GetBeforeHooks(item.CurrentStep)
 		if len(beforeHooks) > 0 {
@@ -298,18 +392,9 @@ func (d *Daemon) executeSyncChain(ctx context.Context, itemID string, engine *wo
 			if sess == nil {
 				d.logger.Warn("session not found, skipping before-hooks", "workItem", item.ID, "step", item.CurrentStep, "session", item.SessionID)
 			} else {
-				hookCtx := workflow.HookContext{
-					RepoPath:   sess.RepoPath,
-					Branch:     item.Branch,
-					SessionID:  item.SessionID,
-					IssueID:    item.IssueRef.ID,
-					IssueTitle: item.IssueRef.Title,
-					IssueURL:   item.IssueRef.URL,
-					PRURL:      item.PRURL,
-					WorkTree:   sess.WorkTree,
-					Provider:   item.IssueRef.Source,
-				}
-				if err := workflow.RunBeforeHooks(ctx, beforeHooks, hookCtx, d.logger); err != nil {
+				hookData, err := workflow.RunBeforeHooks(ctx, beforeHooks, d.hookContext(ctx, item, sess), d.logger)
+				d.mergeHookData(item.ID, hookData)
+				if err != nil {
 					d.logger.Error("before hook failed", "workItem", item.ID, "step", item.CurrentStep, "error", err)
 					state := engine.GetState(item.CurrentStep)
 					if state != nil && state.Error != "" {
@@ -324,6 +409,34 @@ func (d *Daemon) executeSyncChain(ctx context.Context, itemID string, engine *wo
 			}
 		}
 
+		if d.holdForConfirmation(ctx, item, engine) {
+			return
+		}
+		if d.holdForBudget(ctx, item, engine) {
+			return
+		}
+		if d.overItemBudget(ctx, item, engine) {
+			d.state.SetErrorMessage(item.ID, errBudgetExceeded.Error())
+			result, err := engine.AdvanceAfterAsyncFailure(d.workItemView(item), workflow.ErrorBudgetExceeded)
+			if err != nil {
+				d.logger.Error("sync chain error", "workItem", item.ID, "step", item.CurrentStep, "error", err)
+				d.postTerminalMarker(ctx, item.ID, false)
+				d.state.MarkWorkItemTerminal(item.ID, false)
+				return
+			}
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				maps.Copy(it.StepData, result.Data)
+			})
+			d.state.AdvanceWorkItem(item.ID, result.NewStep, result.NewPhase, stepDisplayName(engine, result.NewStep))
+			continue // follow the budget_exceeded or error edge
+		}
+
+		// Re-fetch so the step sees data emitted by its before-hooks.
+		if len(beforeHooks) > 0 {
+			if item, ok = d.state.GetWorkItem(itemID); !ok {
+				return
+			}
+		}
 		view := d.workItemView(item)
 		result, err := engine.ProcessStep(ctx, view)
 		if err != nil {
@@ -416,7 +529,7 @@ func (d *Daemon) handleFeedbackComplete(ctx context.Context, item daemonstate.Wo
 	// Run review after-hooks
 	sess := d.config.GetSession(item.SessionID)
 	if sess != nil {
-		engine := d.getEngine(sess.RepoPath)
+		engine := d.getItemEngine(sess.RepoPath, item)
 		if engine != nil {
 			state := engine.GetState(item.CurrentStep)
 			if state != nil {
@@ -450,7 +563,7 @@ func (d *Daemon) processWorkItems(ctx context.Context) {
 // processWaitItems processes items in wait states for review events.
 func (d *Daemon) processWaitItems(ctx context.Context) {
 	for _, item := range d.state.GetActiveWorkItems() {
-		if item.IsTerminal() || item.Phase == "async_pending" || item.Phase == "addressing_feedback" {
+		if item.IsTerminal() || item.Phase == "async_pending" || item.Phase == "addressing_feedback" || item.Phase == phaseQuarantined || item.Phase == workflow.PhaseStalled {
 			continue
 		}
 
@@ -459,7 +572,7 @@ func (d *Daemon) processWaitItems(ctx context.Context) {
 		// the session has been cleaned up (e.g. post-planning states).
 		view := d.workItemView(item)
 
-		engine := d.getEngine(view.RepoPath)
+		engine := d.getItemEngine(view.RepoPath, item)
 		if engine == nil {
 			continue
 		}
@@ -505,10 +618,17 @@ func (d *Daemon) processWaitItems(ctx context.Context) {
 			if result.Terminal {
 				d.postTerminalMarker(ctx, item.ID, result.TerminalOK)
 				d.state.MarkWorkItemTerminal(item.ID, result.TerminalOK)
+			} else if result.NewPhase == workflow.PhaseStalled {
+				d.announceStall(ctx, item)
 			} else {
 				// Continue sync chain if next is a sync task
 				d.executeSyncChain(ctx, item.ID, engine)
 			}
+		} else if result.Data != nil {
+			// Still waiting, but escalation progress must be recorded.
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				maps.Copy(it.StepData, result.Data)
+			})
 		}
 	}
 }
@@ -516,13 +636,13 @@ func (d *Daemon) processWaitItems(ctx context.Context) {
 // processCIItems processes items waiting for CI events.
 func (d *Daemon) processCIItems(ctx context.Context) {
 	for _, item := range d.state.GetActiveWorkItems() {
-		if item.IsTerminal() || item.Phase == "async_pending" || item.Phase == "addressing_feedback" {
+		if item.IsTerminal() || item.Phase == "async_pending" || item.Phase == "addressing_feedback" || item.Phase == phaseQuarantined || item.Phase == workflow.PhaseStalled {
 			continue
 		}
 
 		view := d.workItemView(item)
 
-		engine := d.getEngine(view.RepoPath)
+		engine := d.getItemEngine(view.RepoPath, item)
 		if engine == nil {
 			continue
 		}
@@ -562,15 +682,21 @@ func (d *Daemon) processCIItems(ctx context.Context) {
 			if result.Terminal {
 				d.postTerminalMarker(ctx, item.ID, result.TerminalOK)
 				d.state.MarkWorkItemTerminal(item.ID, result.TerminalOK)
+			} else if result.NewPhase == workflow.PhaseStalled {
+				d.announceStall(ctx, item)
 			} else {
 				d.executeSyncChain(ctx, item.ID, engine)
 			}
+		} else if result.Data != nil {
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				maps.Copy(it.StepData, result.Data)
+			})
 		}
 	}
 }
 
 // processIdleSyncItems finds items in idle phase sitting on synchronous task steps
-// (e.g. "merge") and executes them. This catches items that were advanced to a sync
+// (e.g. "merge") or on parallel and join steps, and executes them. This catches items that were advanced to a sync
 // task step during recovery but never had executeSyncChain called.
 //
 // Async actions (ai.code, ai.fix_ci, etc.) are explicitly skipped — they require
@@ -587,13 +713,18 @@ func (d *Daemon) processIdleSyncItems(ctx context.Context) {
 			continue
 		}
 
-		engine := d.getEngine(sess.RepoPath)
+		engine := d.getItemEngine(sess.RepoPath, item)
 		if engine == nil {
 			continue
 		}
 
 		state := engine.GetState(item.CurrentStep)
-		if state == nil || state.Type != workflow.StateTypeTask {
+		if state == nil {
+			continue
+		}
+		switch state.Type {
+		case workflow.StateTypeTask, workflow.StateTypeParallel, workflow.StateTypeJoin:
+		default:
 			continue
 		}
 
@@ -631,7 +762,7 @@ func (d *Daemon) processRetryItems(ctx context.Context) {
 			continue
 		}
 
-		engine := d.getEngine(sess.RepoPath)
+		engine := d.getItemEngine(sess.RepoPath, item)
 		if engine == nil {
 			continue
 		}
@@ -718,31 +849,35 @@ func (d *Daemon) checkDockerHealth(ctx context.Context) bool {
 	return true
 }
 
-// defaultDockerHealthCheck runs "docker version" with a 5-second timeout.
+// defaultDockerHealthCheck runs the container runtime's "version" command
+// (e.g. "docker version") with a 5-second timeout.
 // Uses the parent context so the check is cancelled promptly on daemon shutdown.
 func defaultDockerHealthCheck(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, timeoutDockerHealth)
 	defer cancel()
-	return osexec.CommandContext(ctx, "docker", "version").Run()
+	return container.CurrentRuntime().Command(ctx, "version").Run()
 }
 
-// runHooks runs the after-hooks for a given workflow step.
+// runHooks runs the after-hooks for a given workflow step and merges any
+// step data they emit into the work item.
 func (d *Daemon) runHooks(ctx context.Context, hooks []workflow.HookConfig, item daemonstate.WorkItem, sess *config.Session) {
 	if len(hooks) == 0 {
 		return
 	}
 
-	hookCtx := workflow.HookContext{
-		RepoPath:   sess.RepoPath,
-		Branch:     item.Branch,
-		SessionID:  item.SessionID,
-		IssueID:    item.IssueRef.ID,
-		IssueTitle: item.IssueRef.Title,
-		IssueURL:   item.IssueRef.URL,
-		PRURL:      item.PRURL,
-		WorkTree:   sess.WorkTree,
-		Provider:   item.IssueRef.Source,
-	}
+	d.mergeHookData(item.ID, workflow.RunHooks(ctx, hooks, d.hookContext(ctx, item, sess), d.logger))
+}
 
-	workflow.RunHooks(ctx, hooks, hookCtx, d.logger)
+// mergeHookData merges step data emitted by hooks with output: json into the
+// work item, where later states and prompts reference it as {{step.key}}.
+func (d *Daemon) mergeHookData(itemID string, data map[string]any) {
+	if len(data) == 0 {
+		return
+	}
+	d.state.UpdateWorkItem(itemID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		maps.Copy(it.StepData, data)
+	})
 }
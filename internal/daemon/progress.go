@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// StepData keys used to track progress comments for a work item.
+const (
+	progressPostedKey = "_progress_posted" // milestones already reported
+	progressSeqKey    = "_progress_seq"    // number of progress comments created
+	progressAtKey     = "_progress_at"     // when the latest progress comment was created
+	progressBodyKey   = "_progress_body"   // body of the latest progress comment
+)
+
+// postProgress reports a milestone on the work item's source issue when
+// settings.progress_comments is enabled. Each milestone is reported at most
+// once. To avoid noise, milestones reached within settings.progress_interval
+// of the previous progress comment are appended to that comment in place
+// instead of creating a new one.
+//
+// This is best-effort: failures are logged but do not affect the workflow.
+func (d *Daemon) postProgress(ctx context.Context, item daemonstate.WorkItem, milestone string) {
+	repoPath := d.resolveRepoPath(ctx, item)
+	wfCfg, _ := d.lookupWorkflowConfig(repoPath)
+	if !wfCfg.ProgressCommentsEnabled() {
+		return
+	}
+
+	// Re-read so that milestones recorded by earlier calls are visible.
+	if fresh, ok := d.state.GetWorkItem(item.ID); ok {
+		if fresh.PRURL == "" {
+			fresh.PRURL = item.PRURL
+		}
+		item = fresh
+	}
+
+	posted := getProgressPosted(item.StepData)
+	if slices.Contains(posted, milestone) {
+		return
+	}
+	msg := workflow.ProgressMessage(milestone, item.PRURL)
+	if msg == "" {
+		return
+	}
+
+	log := d.logger.With("workItem", item.ID, "milestone", milestone)
+
+	seq := getProgressSeq(item.StepData)
+	now := time.Now()
+	msg = fmt.Sprintf("%s (%s)", msg, wfCfg.FormatLocalTime(now))
+	body := msg
+	lastAt, _ := item.StepData[progressAtKey].(string)
+	lastBody, _ := item.StepData[progressBodyKey].(string)
+	if t, err := time.Parse(time.RFC3339, lastAt); err == nil && seq > 0 && lastBody != "" && now.Sub(t) < wfCfg.ProgressInterval() {
+		// Within the throttle window: fold this milestone into the latest comment.
+		body = lastBody + "\n" + msg
+	} else {
+		seq++
+		lastAt = now.Format(time.RFC3339)
+	}
+
+	ok, err := d.postMarkedComment(ctx, item, fmt.Sprintf("progress-%d", seq), body)
+	if !ok {
+		log.Debug("progress comments not supported for source", "source", item.IssueRef.Source)
+		return
+	}
+	if err != nil {
+		log.Warn("failed to post progress comment (non-fatal)", "error", err)
+		return
+	}
+	log.Info("posted progress comment")
+
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData[progressPostedKey] = append(posted, milestone)
+		it.StepData[progressSeqKey] = seq
+		it.StepData[progressAtKey] = lastAt
+		it.StepData[progressBodyKey] = body
+	})
+}
+
+// getProgressPosted extracts the milestones already reported from step data.
+func getProgressPosted(stepData map[string]any) []string {
+	switch v := stepData[progressPostedKey].(type) {
+	case []string:
+		return slices.Clone(v)
+	case []any:
+		posted := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				posted = append(posted, s)
+			}
+		}
+		return posted
+	default:
+		return nil
+	}
+}
+
+// getProgressSeq extracts the progress comment counter from step data.
+func getProgressSeq(stepData map[string]any) int {
+	switch n := stepData[progressSeqKey].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// progressTestDaemon returns a daemon with progress comments enabled and a
+// GitHub work item backed by a fake provider.
+func progressTestDaemon(t *testing.T, interval int) (*Daemon, *issues.FakeProvider, daemonstate.WorkItem) {
+	t.Helper()
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	prov := issues.NewFakeProvider(issues.SourceGitHub)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	enabled := true
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{
+		ProgressComments: &enabled,
+		ProgressInterval: interval,
+	}
+
+	cfg.AddSession(config.Session{
+		ID:       "sess-progress",
+		RepoPath: "/test/repo",
+		Branch:   "feat-progress",
+		IssueRef: &config.IssueRef{Source: "github", ID: "7"},
+	})
+	item := &daemonstate.WorkItem{
+		ID:        "wi-progress",
+		SessionID: "sess-progress",
+		IssueRef:  config.IssueRef{Source: "github", ID: "7", Title: "Progress"},
+		StepData:  map[string]any{},
+	}
+	d.state.AddWorkItem(item)
+	got, _ := d.state.GetWorkItem(item.ID)
+	return d, prov, got
+}
+
+func TestPostProgress_Disabled(t *testing.T) {
+	d, prov, item := progressTestDaemon(t, 0)
+	d.workflowConfigs["/test/repo"].Settings.ProgressComments = nil
+
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+
+	if len(prov.CommentCalls) != 0 {
+		t.Errorf("expected no comments when disabled, got %d", len(prov.CommentCalls))
+	}
+}
+
+func TestPostProgress_EachMilestoneOnce(t *testing.T) {
+	d, prov, item := progressTestDaemon(t, 0)
+
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(prov.CommentCalls))
+	}
+	body := prov.CommentCalls[0].Args[0]
+	if !strings.Contains(body, "Work has started") {
+		t.Errorf("unexpected body: %q", body)
+	}
+	if !isErgSystemComment(issues.IssueComment{Body: body}) {
+		t.Error("progress comment must be recognised as an erg system comment")
+	}
+}
+
+func TestPostProgress_TimestampInRepoTimezone(t *testing.T) {
+	d, prov, item := progressTestDaemon(t, 0)
+	d.workflowConfigs["/test/repo"].Settings.Timezone = "Asia/Tokyo"
+
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(prov.CommentCalls))
+	}
+	if body := prov.CommentCalls[0].Args[0]; !strings.Contains(body, " JST)") {
+		t.Errorf("expected a JST timestamp in %q", body)
+	}
+}
+
+func TestPostProgress_ThrottleFoldsIntoPreviousComment(t *testing.T) {
+	d, prov, item := progressTestDaemon(t, 10)
+
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(prov.CommentCalls))
+	}
+	prov.SetComments("7", []issues.IssueComment{{ID: "c1", Body: prov.CommentCalls[0].Args[0]}})
+
+	item.PRURL = "https://github.com/owner/repo/pull/9"
+	d.postProgress(context.Background(), item, workflow.ProgressPROpened)
+
+	if len(prov.CommentCalls) != 1 {
+		t.Errorf("expected no new comment within throttle window, got %d", len(prov.CommentCalls))
+	}
+	if len(prov.UpdateCommentCalls) != 1 {
+		t.Fatalf("expected 1 comment update, got %d", len(prov.UpdateCommentCalls))
+	}
+	updated := prov.UpdateCommentCalls[0].Args[1]
+	if !strings.Contains(updated, "Work has started") || !strings.Contains(updated, "pull/9") {
+		t.Errorf("expected both milestones in updated comment, got %q", updated)
+	}
+}
+
+func TestPostProgress_NewCommentAfterInterval(t *testing.T) {
+	d, prov, item := progressTestDaemon(t, 10)
+
+	d.postProgress(context.Background(), item, workflow.ProgressStarted)
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.StepData[progressAtKey] = time.Now().Add(-time.Hour).Format(time.RFC3339)
+	})
+	d.postProgress(context.Background(), item, workflow.ProgressMerged)
+
+	if len(prov.CommentCalls) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(prov.CommentCalls))
+	}
+	if strings.Contains(prov.CommentCalls[1].Args[0], "Work has started") {
+		t.Errorf("new comment should only contain the new milestone, got %q", prov.CommentCalls[1].Args[0])
+	}
+}
@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// coveragePercentRe matches a percentage such as "83.4%" in coverage output.
+var coveragePercentRe = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+
+// qualityCheckResult is the outcome of one quality.gate check.
+type qualityCheckResult struct {
+	name   string
+	passed bool
+	detail string // one-line summary
+	output string // command output, kept for failed command checks
+}
+
+// runQualityGate runs every check listed in the quality.gate checks param in
+// the work item's worktree and returns their results in order. All checks run
+// even after one fails, so the feedback covers everything at once. An error
+// means the gate could not be evaluated (no session, git failure).
+//
+// Each check has a name and a type (default command):
+//
+//   - command: runs command; passes on exit status 0.
+//   - coverage: runs command; passes when the last percentage in its output
+//     is at least min.
+//   - diff_size: passes when the branch changes at most max lines.
+//   - file_count: passes when the branch changes at most max files.
+//
+// Command checks take an optional timeout (default 10m).
+func (d *Daemon) runQualityGate(ctx context.Context, item daemonstate.WorkItem, params *workflow.ParamHelper) ([]qualityCheckResult, error) {
+	workDir, diffRef, err := d.branchDiff(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := params.Raw("checks").([]any)
+	results := make([]qualityCheckResult, 0, len(raw))
+	for _, entry := range raw {
+		check, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		p := workflow.NewParamHelper(check)
+		result := qualityCheckResult{name: p.String("name", "")}
+
+		switch checkType := p.String("type", workflow.QualityCheckCommand); checkType {
+		case workflow.QualityCheckCommand, workflow.QualityCheckCoverage:
+			exitCode, output, err := d.runExecCommand(ctx, item, p)
+			result.output = output
+			switch {
+			case err != nil && exitCode >= 0:
+				result.detail = fmt.Sprintf("exited with status %d", exitCode)
+			case err != nil:
+				result.detail = firstLine(err.Error())
+			case checkType == workflow.QualityCheckCommand:
+				result.passed = true
+				result.detail = "passed"
+			default:
+				result.passed, result.detail = checkCoverage(output, p.Float64("min", 0))
+			}
+
+		case workflow.QualityCheckDiffSize:
+			diffCtx, cancel := context.WithTimeout(ctx, timeoutGitPush)
+			_, added, deleted, err := gitDiffNumstat(diffCtx, workDir, diffRef)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("check %q: failed to get diff line stats: %w", result.name, err)
+			}
+			limit := p.Int("max", 0)
+			result.passed = added+deleted <= limit
+			result.detail = fmt.Sprintf("%d lines changed (max %d)", added+deleted, limit)
+
+		case workflow.QualityCheckFileCount:
+			diffCtx, cancel := context.WithTimeout(ctx, timeoutGitPush)
+			files, err := gitDiffNameOnly(diffCtx, workDir, diffRef)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("check %q: failed to get changed files: %w", result.name, err)
+			}
+			limit := p.Int("max", 0)
+			result.passed = len(files) <= limit
+			result.detail = fmt.Sprintf("%d files changed (max %d)", len(files), limit)
+
+		default:
+			return nil, fmt.Errorf("check %q: unknown type %q", result.name, checkType)
+		}
+
+		if result.passed {
+			result.output = ""
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkCoverage reports whether the last percentage in a coverage command's
+// output reaches minPct.
+func checkCoverage(output string, minPct float64) (bool, string) {
+	matches := coveragePercentRe.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return false, "no coverage percentage found in output"
+	}
+	pct, _ := strconv.ParseFloat(matches[len(matches)-1][1], 64)
+	return pct >= minPct, fmt.Sprintf("coverage %.1f%% (min %.1f%%)", pct, minPct)
+}
+
+// formatQualityReport renders the failed checks for the issue log and for
+// the session that addresses them.
+func formatQualityReport(results []qualityCheckResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		if r.passed {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s: %s\n", r.name, r.detail)
+		if r.output != "" {
+			fmt.Fprintf(&b, "\n```\n%s\n```\n", r.output)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,145 @@
+package daemon
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// qualityGateTestDaemon returns a daemon with a work item whose branch adds
+// two files (four lines) on top of the base branch.
+func qualityGateTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	dir, baseBranch := initTestGitRepoWithBranch(t, "feature-gate")
+	writeTestFile(t, dir, "a.go", "package main\n\nfunc a() {}\n")
+	writeTestFile(t, dir, "b.go", "package main\n")
+	mustRunGit(t, dir, "add", ".")
+	mustRunGit(t, dir, "commit", "-m", "add files")
+
+	cfg := testConfig()
+	cfg.AddSession(config.Session{ID: "sess-1", RepoPath: dir, WorkTree: dir, Branch: "feature-gate", BaseBranch: baseBranch})
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "1"},
+		SessionID: "sess-1",
+		Branch:    "feature-gate",
+		StepData:  map[string]any{},
+	})
+	return d
+}
+
+func runQualityGateAction(d *Daemon, checks ...map[string]any) workflow.ActionResult {
+	raw := make([]any, len(checks))
+	for i, c := range checks {
+		raw[i] = c
+	}
+	ac := &workflow.ActionContext{WorkItemID: "item-1", Params: workflow.NewParamHelper(map[string]any{"checks": raw})}
+	return (&qualityGateAction{daemon: d}).Execute(context.Background(), ac)
+}
+
+func TestQualityGateAction_ReportsFailingChecks(t *testing.T) {
+	d := qualityGateTestDaemon(t)
+
+	result := runQualityGateAction(d,
+		map[string]any{"name": "tests", "command": "true"},
+		map[string]any{"name": "lint", "command": "echo 'a.go:3: unused'; exit 3"},
+		map[string]any{"name": "coverage", "type": "coverage", "command": "echo 'coverage: 72.5% of statements'", "min": 80},
+		map[string]any{"name": "diff size", "type": "diff_size", "max": 3},
+		map[string]any{"name": "files", "type": "file_count", "max": 5},
+	)
+
+	if result.Success || result.Error == nil || result.Error.Error() != workflow.QualityGateFailed {
+		t.Fatalf("expected %q error, got success=%v error=%v", workflow.QualityGateFailed, result.Success, result.Error)
+	}
+	failed, _ := result.Data["quality_gate_failed"].([]string)
+	if !slices.Equal(failed, []string{"lint", "coverage", "diff size"}) {
+		t.Errorf("unexpected failed checks: %v", failed)
+	}
+	report, _ := result.Data[workflow.QualityGateReportKey].(string)
+	for _, want := range []string{"## lint: exited with status 3", "a.go:3: unused", "coverage 72.5% (min 80.0%)", "4 lines changed (max 3)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "tests") || strings.Contains(report, "files") {
+		t.Errorf("report should only cover failing checks:\n%s", report)
+	}
+}
+
+func TestQualityGateAction_PassClearsReport(t *testing.T) {
+	d := qualityGateTestDaemon(t)
+
+	result := runQualityGateAction(d,
+		map[string]any{"name": "coverage", "type": "coverage", "command": "echo 'ok 91.0%'", "min": 80},
+		map[string]any{"name": "files", "type": "file_count", "max": 2},
+	)
+
+	if !result.Success {
+		t.Fatalf("expected gate to pass, got %v", result.Error)
+	}
+	if result.Data[workflow.QualityGateReportKey] != "" {
+		t.Errorf("expected report cleared, got %v", result.Data)
+	}
+}
+
+func TestQualityGateAction_NoSession(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", SessionID: "missing"})
+
+	result := runQualityGateAction(d, map[string]any{"name": "tests", "command": "true"})
+	if result.Success || result.Error == nil || result.Error.Error() == workflow.QualityGateFailed {
+		t.Errorf("expected an error distinct from a failed gate, got %v", result.Error)
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	tests := []struct {
+		output string
+		min    float64
+		want   bool
+	}{
+		{"ok pkg/a 10.0%\ntotal: (statements) 85.2%", 80, true},
+		{"total: 79.9%", 80, false},
+		{"no numbers here", 10, false},
+	}
+	for _, tt := range tests {
+		if got, detail := checkCoverage(tt.output, tt.min); got != tt.want {
+			t.Errorf("checkCoverage(%q, %v) = %v (%s), want %v", tt.output, tt.min, got, detail, tt.want)
+		}
+	}
+}
+
+func TestAddressFeedbackAction_Execute_Limits(t *testing.T) {
+	cfg := testConfig()
+	cfg.AddSession(*testSession("sess-1"))
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		SessionID: "sess-1",
+		StepData:  map[string]any{"feedback_fix_rounds": float64(2)},
+	})
+	action := &addressFeedbackAction{daemon: d}
+
+	ac := &workflow.ActionContext{WorkItemID: "item-1", Params: workflow.NewParamHelper(map[string]any{"max_feedback_rounds": 2})}
+	if result := action.Execute(context.Background(), ac); result.Error == nil || !strings.Contains(result.Error.Error(), "max feedback rounds exceeded") {
+		t.Errorf("expected max rounds error, got %v", result.Error)
+	}
+
+	ac = &workflow.ActionContext{WorkItemID: "item-1", Params: workflow.NewParamHelper(map[string]any{"max_feedback_rounds": 5})}
+	if result := action.Execute(context.Background(), ac); result.Error == nil || !strings.Contains(result.Error.Error(), "no feedback") {
+		t.Errorf("expected no feedback error, got %v", result.Error)
+	}
+}
+
+func TestFormatAddressFeedbackPrompt(t *testing.T) {
+	prompt := formatAddressFeedbackPrompt(2, "## lint: exited with status 1")
+	if !strings.Contains(prompt, "FIX ROUND 2") || !strings.HasSuffix(prompt, "## lint: exited with status 1") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+// phaseQuarantined marks a work item whose worker panicked. The item is parked
+// (no slot, no event processing) until quarantineCooldown elapses, then
+// released for another attempt.
+const phaseQuarantined = "quarantined"
+
+const (
+	// quarantineCooldown is how long a panicked item stays parked before retry.
+	quarantineCooldown = 30 * time.Minute
+	// maxQuarantines is how many worker panics an item may cause before it is
+	// failed outright instead of being retried again.
+	maxQuarantines = 3
+)
+
+// quarantineWorkItem parks a work item whose worker panicked. The panic is
+// logged with its full stack and a snapshot of the work item so the bug can be
+// reproduced. Items that keep panicking are failed after maxQuarantines, the
+// way any other failure is, so the issue is told and the failure counts
+// towards the dead-letter queue.
+func (d *Daemon) quarantineWorkItem(ctx context.Context, item daemonstate.WorkItem, pe *worker.PanicError, repo string) {
+	snapshot, _ := json.Marshal(item)
+	d.logger.Error("worker panicked, quarantining work item",
+		"event", "session.panic",
+		"workItem", item.ID,
+		"step", item.CurrentStep,
+		"phase", item.Phase,
+		"repo", repo,
+		"panic", fmt.Sprint(pe.Value),
+		"stack", string(pe.Stack),
+		"snapshot", string(snapshot),
+	)
+
+	count := getQuarantineCount(item.StepData) + 1
+	if count > maxQuarantines {
+		d.state.SetErrorMessage(item.ID, fmt.Sprintf("worker panicked %d times, giving up: %v", count, pe.Value))
+		d.postTerminalMarker(ctx, item.ID, false)
+		d.state.MarkWorkItemTerminal(item.ID, false)
+		return
+	}
+
+	until := time.Now().Add(quarantineCooldown)
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_quarantine_count"] = count
+		it.StepData["_quarantined_phase"] = it.Phase
+		it.StepData["_quarantine_until"] = until.Format(time.RFC3339)
+		it.Phase = phaseQuarantined
+		it.UpdatedAt = time.Now()
+	})
+	d.state.SetErrorMessage(item.ID, fmt.Sprintf("quarantined until %s: %v", until.Format(time.RFC3339), pe))
+}
+
+// processQuarantinedItems releases quarantined items whose cooldown has
+// elapsed. Items that panicked while addressing feedback return to idle at
+// their current step so review polling picks them up again; items that
+// panicked in their main session are re-queued to start a fresh one, and
+// the session they leave behind is cleaned up.
+func (d *Daemon) processQuarantinedItems(ctx context.Context) {
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase != phaseQuarantined {
+			continue
+		}
+		if until, ok := item.StepData["_quarantine_until"].(string); ok {
+			t, err := time.Parse(time.RFC3339, until)
+			if err == nil && time.Now().Before(t) {
+				continue
+			}
+		}
+
+		prevPhase, _ := item.StepData["_quarantined_phase"].(string)
+		d.logger.Info("releasing quarantined work item", "workItem", item.ID, "step", item.CurrentStep, "phase", prevPhase)
+		if prevPhase == "addressing_feedback" {
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				it.Phase = "idle"
+				it.UpdatedAt = time.Now()
+			})
+			d.state.SetErrorMessage(item.ID, "")
+			continue
+		}
+		d.requeueWorkItem(ctx, item)
+	}
+}
+
+// getQuarantineCount returns how many times the item has been quarantined.
+// Handles both int (in-memory) and float64 (after JSON round-trip).
+func getQuarantineCount(stepData map[string]any) int {
+	switch v := stepData["_quarantine_count"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
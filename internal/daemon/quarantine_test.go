@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+// addPanickedWorker sets up an active coding item whose worker exited with a panic.
+func addPanickedWorker(d *Daemon, cfg *config.Config, itemID, phase string) {
+	sess := testSession("sess-" + itemID)
+	cfg.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          itemID,
+		IssueRef:    config.IssueRef{Source: "github", ID: "80"},
+		SessionID:   sess.ID,
+		Branch:      sess.Branch,
+		CurrentStep: "coding",
+	})
+	d.state.AdvanceWorkItem(itemID, "coding", phase)
+	d.state.UpdateWorkItem(itemID, func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+	})
+	d.workers[itemID] = worker.NewDoneWorkerWithError(&worker.PanicError{Value: "boom", Stack: []byte("goroutine 1")})
+}
+
+func TestCollectCompletedWorkers_PanicQuarantinesItem(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	addPanickedWorker(d, cfg, "item-panic", "async_pending")
+
+	d.collectCompletedWorkers(context.Background())
+
+	if _, ok := d.workers["item-panic"]; ok {
+		t.Error("expected done worker to be removed")
+	}
+	item, _ := d.state.GetWorkItem("item-panic")
+	if item.Phase != phaseQuarantined {
+		t.Errorf("expected phase %q, got %q", phaseQuarantined, item.Phase)
+	}
+	if item.IsTerminal() {
+		t.Error("expected quarantined item to stay non-terminal")
+	}
+	if item.ConsumesSlot() {
+		t.Error("expected quarantined item not to consume a slot")
+	}
+	if got := getQuarantineCount(item.StepData); got != 1 {
+		t.Errorf("expected quarantine count 1, got %d", got)
+	}
+	if item.ErrorMessage == "" {
+		t.Error("expected error message to be set")
+	}
+}
+
+func TestCollectCompletedWorkers_RepeatedPanicFailsItem(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	addPanickedWorker(d, cfg, "item-panic", "async_pending")
+	d.state.UpdateWorkItem("item-panic", func(it *daemonstate.WorkItem) {
+		it.StepData["_quarantine_count"] = float64(maxQuarantines)
+	})
+
+	d.collectCompletedWorkers(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-panic")
+	if item.State != daemonstate.WorkItemFailed {
+		t.Errorf("expected failed after %d quarantines, got state %s", maxQuarantines, item.State)
+	}
+	if posted, _ := item.StepData["_unqueued_posted"].(bool); !posted {
+		t.Error("expected the failure to be reported on the issue like any other")
+	}
+}
+
+func TestProcessQuarantinedItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		prevPhase string
+		until     time.Time
+		wantState daemonstate.WorkItemState
+		wantPhase string
+	}{
+		{name: "cooldown pending", prevPhase: "async_pending", until: time.Now().Add(time.Hour), wantState: daemonstate.WorkItemActive, wantPhase: phaseQuarantined},
+		{name: "coding session requeued", prevPhase: "async_pending", until: time.Now().Add(-time.Minute), wantState: daemonstate.WorkItemQueued, wantPhase: ""},
+		{name: "feedback session back to idle", prevPhase: "addressing_feedback", until: time.Now().Add(-time.Minute), wantState: daemonstate.WorkItemActive, wantPhase: "idle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDaemon(testConfig())
+			addTestWorkItem(d, "item-1", "sess-1", daemonstate.WorkItemActive)
+			d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+				it.Phase = phaseQuarantined
+				it.StepData = map[string]any{
+					"_quarantined_phase": tt.prevPhase,
+					"_quarantine_until":  tt.until.Format(time.RFC3339),
+				}
+			})
+
+			d.processQuarantinedItems(context.Background())
+
+			item, _ := d.state.GetWorkItem("item-1")
+			if item.State != tt.wantState || item.Phase != tt.wantPhase {
+				t.Errorf("got state=%s phase=%q, want state=%s phase=%q", item.State, item.Phase, tt.wantState, tt.wantPhase)
+			}
+		})
+	}
+}
+
+func TestProcessQuarantinedItems_RequeueCleansUpSession(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	addPanickedWorker(d, cfg, "item-1", "async_pending")
+	delete(d.workers, "item-1")
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+		it.Phase = phaseQuarantined
+		it.StepData = map[string]any{
+			"_quarantined_phase": "async_pending",
+			"_quarantine_until":  time.Now().Add(-time.Minute).Format(time.RFC3339),
+		}
+	})
+	d.SetPendingMessage("sess-item-1", "please also fix the docs")
+
+	d.processQuarantinedItems(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemQueued || item.SessionID != "" {
+		t.Errorf("got state=%s session=%q, want a queued item without a session", item.State, item.SessionID)
+	}
+	if cfg.GetSession("sess-item-1") != nil {
+		t.Error("expected the old session to be removed from config")
+	}
+	if msg := d.sessionMgr.StateManager().GetPendingMessage("sess-item-1"); msg != "" {
+		t.Errorf("expected the old session's pending message dropped, got %q", msg)
+	}
+}
+
+func TestRetryWorkItem_QuarantinedAllowed(t *testing.T) {
+	d := testDaemon(testConfig())
+	addTestWorkItem(d, "item-1", "sess-1", daemonstate.WorkItemActive)
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+		it.Phase = phaseQuarantined
+	})
+
+	if err := d.RetryWorkItem("item-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.State != daemonstate.WorkItemQueued {
+		t.Errorf("expected state=queued, got %s", item.State)
+	}
+}
@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// readinessCommentStep is the marker step for readiness comments, so a later
+// poll updates the same comment instead of adding another.
+const readinessCommentStep = "readiness"
+
+// estimateLineRe matches an "Estimate: 3" line, allowing markdown emphasis
+// or a heading prefix around the key.
+var estimateLineRe = regexp.MustCompile(`(?im)^\s*(?:#+\s*)?\**estimate\**\s*:\**\s*\S`)
+
+// checkReadiness evaluates the workflow's readiness checks for an issue and
+// returns what is missing (empty when the issue is ready or no checks are
+// configured), and with readiness.dependencies the blockers it waited for.
+// Label checks need the tracker and run only when online; a label lookup
+// that fails does not hold the issue back. When configured, the issue is
+// commented on with what is missing.
+func (d *Daemon) checkReadiness(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, wfCfg *workflow.Config, online bool) ([]string, []dependencyRecord) {
+	r := wfCfg.Source.Readiness
+	if r == nil {
+		return nil, nil
+	}
+
+	missing := missingReadiness(r, issue.Body)
+	if online {
+		checker := newEventChecker(d)
+		for _, label := range r.BlockedLabels {
+			has, err := checker.issueHasLabel(ctx, repoPath, string(provider), issue.ID, label)
+			if err != nil {
+				d.logger.Debug("readiness label check failed", "issue", issue.ID, "label", label, "error", err)
+				continue
+			}
+			if has {
+				missing = append(missing, fmt.Sprintf("Remove the %q label", label))
+			}
+		}
+	}
+
+	var deps []dependencyRecord
+	if r.Dependencies {
+		var waiting []string
+		waiting, deps = d.unmetDependencies(ctx, repoPath, issue, provider, online)
+		missing = append(missing, waiting...)
+	}
+
+	if len(missing) > 0 && r.Comment && online {
+		d.commentNotReady(ctx, repoPath, issue, provider, missing)
+	}
+	return missing, deps
+}
+
+// missingReadiness returns the description-based readiness checks body fails.
+func missingReadiness(r *workflow.ReadinessConfig, body string) []string {
+	var missing []string
+	if n := utf8.RuneCountInString(strings.TrimSpace(body)); n < r.MinBodyLength {
+		missing = append(missing, fmt.Sprintf("Description is %d characters; at least %d required", n, r.MinBodyLength))
+	}
+	for _, section := range r.RequiredSections {
+		if !hasSection(body, section) {
+			missing = append(missing, fmt.Sprintf("Add a %q section", section))
+		}
+	}
+	if r.RequireEstimate && !hasSection(body, "Estimate") && !estimateLineRe.MatchString(body) {
+		missing = append(missing, `Add an estimate (e.g. "Estimate: 3")`)
+	}
+	return missing
+}
+
+// hasSection reports whether body has a line naming the section: a markdown
+// heading, a bold line, or a line ending in a colon.
+func hasSection(body, name string) bool {
+	for line := range strings.SplitSeq(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		line = strings.TrimSpace(strings.Trim(line, "*_"))
+		line = strings.TrimSpace(strings.TrimSuffix(line, ":"))
+		if strings.EqualFold(line, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentNotReady tells the issue author what is missing. The comment is
+// posted again only when the list of missing items changes.
+func (d *Daemon) commentNotReady(ctx context.Context, repoPath string, issue issues.Issue, provider issues.Source, missing []string) {
+	var sb strings.Builder
+	sb.WriteString("This issue isn't ready for erg to pick up yet:\n\n")
+	for _, m := range missing {
+		sb.WriteString("- " + m + "\n")
+	}
+	sb.WriteString("\nIt will be picked up automatically once these are resolved.")
+	msg := sb.String()
+
+	key := string(provider) + "/" + issue.ID
+	if d.readinessNotified[key] == msg {
+		return
+	}
+
+	item := daemonstate.WorkItem{
+		ID:       d.workItemID(repoPath, provider, issue.ID),
+		IssueRef: config.IssueRef{Source: string(provider), ID: issue.ID, Title: issue.Title, URL: issue.URL},
+		StepData: map[string]any{"_repo_path": repoPath},
+	}
+	if _, err := d.postMarkedComment(ctx, item, readinessCommentStep, msg); err != nil {
+		d.logger.Warn("failed to comment on unready issue", "issue", issue.ID, "error", err)
+		return
+	}
+	if d.readinessNotified == nil {
+		d.readinessNotified = make(map[string]string)
+	}
+	d.readinessNotified[key] = msg
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestMissingReadiness(t *testing.T) {
+	r := &workflow.ReadinessConfig{
+		MinBodyLength:    20,
+		RequiredSections: []string{"Acceptance Criteria"},
+		RequireEstimate:  true,
+	}
+
+	ready := "Export the report as CSV.\n\n## Acceptance criteria\n- downloads a file\n\n**Estimate:** 3"
+	if missing := missingReadiness(r, ready); len(missing) != 0 {
+		t.Errorf("expected ready issue, got missing %v", missing)
+	}
+
+	missing := missingReadiness(r, "Fix it")
+	if len(missing) != 3 {
+		t.Fatalf("expected length, section, and estimate missing, got %v", missing)
+	}
+	if !strings.Contains(missing[0], "at least 20") || !strings.Contains(missing[1], "Acceptance Criteria") || !strings.Contains(missing[2], "estimate") {
+		t.Errorf("unexpected missing items: %v", missing)
+	}
+}
+
+func TestHasSection(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{"## Acceptance Criteria\n- x", true},
+		{"**Acceptance criteria**\n- x", true},
+		{"Acceptance Criteria:\n- x", true},
+		{"We have no acceptance criteria yet", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hasSection(tt.body, "Acceptance Criteria"); got != tt.want {
+			t.Errorf("hasSection(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestPollForNewIssues_SkipsUnreadyIssues(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	d.workflowConfigs["/test/repo"].Source.Readiness = &workflow.ReadinessConfig{
+		MinBodyLength: 10,
+		BlockedLabels: []string{"needs-design"},
+		Comment:       true,
+	}
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Title: "Ready", Body: "A detailed enough description", Source: issues.SourceLinear},
+		{ID: "ENG-2", Title: "Too short", Body: "tbd", Source: issues.SourceLinear},
+		{ID: "ENG-3", Title: "Needs design", Body: "A detailed enough description", Source: issues.SourceLinear},
+	})
+	prov.AddLabel("ENG-3", "needs-design")
+
+	d.pollForNewIssues(context.Background())
+
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-1"); !ok {
+		t.Error("expected ready issue queued")
+	}
+	for _, id := range []string{"ENG-2", "ENG-3"} {
+		if _, ok := d.state.GetWorkItem("/test/repo-" + id); ok {
+			t.Errorf("expected unready issue %s skipped", id)
+		}
+	}
+	if len(prov.CommentCalls) != 2 {
+		t.Fatalf("expected a comment on each unready issue, got %+v", prov.CommentCalls)
+	}
+	if body := prov.CommentCalls[1].Args[0]; prov.CommentCalls[1].IssueID != "ENG-3" || !strings.Contains(body, `Remove the "needs-design" label`) {
+		t.Errorf("unexpected comment on ENG-3: %q", body)
+	}
+
+	// Unchanged issues are not commented on again.
+	d.pollForNewIssues(context.Background())
+	if len(prov.CommentCalls) != 2 {
+		t.Errorf("expected no repeat comments, got %d", len(prov.CommentCalls))
+	}
+}
@@ -0,0 +1,241 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// StepData keys tracking which reaper rules have run during an item's
+// current stay at its step.
+const (
+	reaperEnteredKey = "_reaper_entered" // StepEnteredAt the rules ran against
+	reaperLevelKey   = "_reaper_level"   // how many of the step's rules have run
+)
+
+// stuckSummaryInterval is how often each repo's stuck items are reported.
+const stuckSummaryInterval = 24 * time.Hour
+
+// errReaperBusy defers a reaper action while the item's session is running.
+var errReaperBusy = errors.New("session is running")
+
+// processStuckItems applies settings.reaper rules to items that have been
+// at their current step too long, and reports each repo's stuck items once
+// a day. Each rule runs once per stay at its step, in order of its
+// threshold, so re-entering the step starts over. A failed action is
+// logged and not retried, except one deferred while a session is running.
+func (d *Daemon) processStuckItems(ctx context.Context) {
+	stuck := make(map[string][]daemonstate.WorkItem)
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.StepEnteredAt.IsZero() {
+			continue
+		}
+		repoPath := d.workItemRepoPath(item)
+		rules := d.getWorkflowConfig(repoPath).ReaperRules(item.CurrentStep)
+		if len(rules) == 0 {
+			continue
+		}
+		elapsed := time.Since(item.StepEnteredAt)
+		if elapsed >= rules[0].After.Duration {
+			stuck[repoPath] = append(stuck[repoPath], item)
+		}
+
+		entered := item.StepEnteredAt.UTC().Format(time.RFC3339Nano)
+		level := 0
+		if item.StepData[reaperEnteredKey] == entered {
+			level = getReaperLevel(item.StepData)
+		}
+		ran := level
+		for ran < len(rules) && elapsed >= rules[ran].After.Duration {
+			err := d.reap(ctx, item, rules[ran], elapsed)
+			if errors.Is(err, errReaperBusy) {
+				break
+			}
+			ran++
+			if err != nil {
+				d.logger.Warn("reaper action failed", "workItem", item.ID, "step", item.CurrentStep,
+					"action", rules[ran-1].Action, "error", err)
+			}
+			if rules[ran-1].Action == workflow.ReaperRequeue || rules[ran-1].Action == workflow.ReaperAbandon {
+				// The item left its step; its stay is over.
+				ran = level
+				break
+			}
+		}
+		if ran > level {
+			d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+				it.StepData[reaperEnteredKey] = entered
+				it.StepData[reaperLevelKey] = ran
+			})
+		}
+	}
+	d.reportStuckItems(ctx, stuck)
+}
+
+// reap takes a reaper rule's action on an item that has been at its step
+// for elapsed.
+func (d *Daemon) reap(ctx context.Context, item daemonstate.WorkItem, rule workflow.ReaperRule, elapsed time.Duration) error {
+	log := d.logger.With("workItem", item.ID, "step", item.CurrentStep, "action", rule.Action)
+	stuckFor := fmt.Sprintf("at step `%s` for %s", item.CurrentStep, formatStuckFor(elapsed))
+
+	// A stuck item is not at the rebase step, so it cannot be held for
+	// confirmation there; ask a human instead.
+	if rule.Action == workflow.ReaperRebase {
+		if wfCfg, _ := d.lookupWorkflowConfig(d.workItemRepoPath(item)); wfCfg.RequiresConfirmation("git.rebase") {
+			log.Info("reaper rebase requires confirmation, pinging instead")
+			rule.Action = workflow.ReaperPing
+		}
+	}
+
+	switch rule.Action {
+	case workflow.ReaperPing:
+		msg := fmt.Sprintf("This work has been %s without moving on and may need a human to look at it.", stuckFor)
+		if item.PRURL != "" {
+			msg += fmt.Sprintf(" The PR is %s.", item.PRURL)
+		}
+		if _, err := d.postMarkedComment(ctx, item, "stuck-"+item.CurrentStep, msg); err != nil {
+			return err
+		}
+
+	case workflow.ReaperRebase:
+		if d.workerRunning(item.ID) {
+			return errReaperBusy
+		}
+		result := (&rebaseAction{daemon: d}).Execute(ctx, &workflow.ActionContext{
+			WorkItemID: item.ID,
+			SessionID:  item.SessionID,
+			RepoPath:   d.workItemRepoPath(item),
+			Branch:     item.Branch,
+			Step:       item.CurrentStep,
+			Params:     workflow.NewParamHelper(nil),
+			Logger:     d.logger,
+		})
+		if !result.Success {
+			return result.Error
+		}
+
+	case workflow.ReaperRequeue:
+		d.stopWorker(item.ID)
+		if item.PRURL != "" && item.Branch != "" {
+			closeCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+			err := d.gitService.ClosePR(closeCtx, d.workItemRepoPath(item), item.Branch,
+				fmt.Sprintf("erg is closing this PR because its work has been %s, and will start over.", stuckFor))
+			cancel()
+			if err != nil {
+				log.Warn("failed to close PR of stuck item (non-fatal)", "pr", item.PRURL, "error", err)
+			}
+		}
+		d.requeueWorkItem(ctx, item)
+
+	case workflow.ReaperAbandon:
+		d.abandonWorkItem(ctx, item,
+			fmt.Sprintf("erg has given up on this work after it was %s.", stuckFor), "abandoned",
+			"abandoned after being stuck at "+item.CurrentStep)
+	}
+
+	log.Info("reaper acted on stuck work item", "event", "reaper."+rule.Action, "elapsed", elapsed.Round(time.Minute).String())
+	d.audit(item.ID, daemonstate.AuditReaper, fmt.Sprintf("%s: %s", rule.Action, stuckFor), map[string]any{"step": item.CurrentStep, "after": rule.After.String()})
+	return nil
+}
+
+// workerRunning reports whether the item has a session that has not finished.
+func (d *Daemon) workerRunning(itemID string) bool {
+	d.mu.Lock()
+	w, ok := d.workers[itemID]
+	d.mu.Unlock()
+	return ok && !w.Done()
+}
+
+// reportStuckItems logs each repo's stuck items, and posts them to the
+// repo's settings.reaper.summary_webhook, at most once per
+// stuckSummaryInterval. Repos with nothing stuck are skipped.
+func (d *Daemon) reportStuckItems(ctx context.Context, stuck map[string][]daemonstate.WorkItem) {
+	now := time.Now()
+	for repoPath, items := range stuck {
+		if last, ok := d.stuckSummaryAt[repoPath]; ok && now.Sub(last) < stuckSummaryInterval {
+			continue
+		}
+		if d.stuckSummaryAt == nil {
+			d.stuckSummaryAt = make(map[string]time.Time)
+		}
+		d.stuckSummaryAt[repoPath] = now
+
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		d.logger.Warn("work items stuck", "event", "reaper.summary", "repo", repoPath, "count", len(items), "workItems", ids)
+
+		webhook := d.getWorkflowConfig(repoPath).Settings.Reaper.SummaryWebhook
+		if webhook == "" {
+			continue
+		}
+		url := os.ExpandEnv(webhook)
+		if url == "" {
+			d.logger.Warn("stuck items summary webhook resolved to empty string (env var not set?)", "repo", repoPath)
+			continue
+		}
+		if err := postSlackWebhook(ctx, url, slackWebhookPayload{
+			Text:      stuckSummary(repoPath, items, now),
+			Username:  "erg",
+			IconEmoji: ":robot_face:",
+		}); err != nil {
+			d.logger.Warn("failed to post stuck items summary", "repo", repoPath, "error", err)
+		}
+	}
+}
+
+// stuckSummary is the daily stuck items report for a repo, longest stuck
+// first.
+func stuckSummary(repoPath string, items []daemonstate.WorkItem, now time.Time) string {
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b daemonstate.WorkItem) int { return a.StepEnteredAt.Compare(b.StepEnteredAt) })
+
+	var sb strings.Builder
+	noun := "items"
+	if len(items) == 1 {
+		noun = "item"
+	}
+	fmt.Fprintf(&sb, "%d work %s stuck in %s:", len(items), noun, filepath.Base(repoPath))
+	for _, item := range items {
+		fmt.Fprintf(&sb, "\n• %s", epicIssueLabel(item.IssueRef))
+		if item.IssueRef.Title != "" {
+			fmt.Fprintf(&sb, " %s", item.IssueRef.Title)
+		}
+		fmt.Fprintf(&sb, " — at `%s` for %s", item.CurrentStep, formatStuckFor(now.Sub(item.StepEnteredAt)))
+		if item.PRURL != "" {
+			fmt.Fprintf(&sb, " (%s)", item.PRURL)
+		}
+	}
+	return sb.String()
+}
+
+// formatStuckFor renders how long an item has been stuck in whole days, or
+// in hours under two days.
+func formatStuckFor(d time.Duration) string {
+	if d < 48*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// getReaperLevel returns how many reaper rules have run for the item's
+// current stay at its step.
+func getReaperLevel(stepData map[string]any) int {
+	switch n := stepData[reaperLevelKey].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
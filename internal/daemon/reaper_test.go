@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// addStuckItem adds an active work item that entered step stuckFor ago.
+func addStuckItem(d *Daemon, id, step string, stuckFor time.Duration) {
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:       id,
+		IssueRef: config.IssueRef{Source: "linear", ID: strings.TrimPrefix(id, "/test/repo-"), Title: "Stuck work"},
+		StepData: map[string]any{"_repo_path": "/test/repo"},
+	})
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+		it.CurrentStep = step
+		it.StepEnteredAt = time.Now().Add(-stuckFor)
+	})
+}
+
+// setReaper installs a reaper config in the test repo's workflow.
+func setReaper(d *Daemon, r *workflow.ReaperConfig) {
+	d.workflowConfigs["/test/repo"].Settings = &workflow.SettingsConfig{Reaper: r}
+}
+
+func days(n int) workflow.Duration {
+	return workflow.Duration{Duration: time.Duration(n) * 24 * time.Hour}
+}
+
+func TestProcessStuckItems_RunsEachRuleOncePerStay(t *testing.T) {
+	d, prov := offlineTestDaemon(t)
+	setReaper(d, &workflow.ReaperConfig{Rules: []workflow.ReaperRule{
+		{State: "await_review", After: days(14), Action: workflow.ReaperAbandon},
+		{State: "await_review", After: days(7), Action: workflow.ReaperPing},
+	}})
+	addStuckItem(d, "/test/repo-ENG-1", "await_review", 8*24*time.Hour)
+	addStuckItem(d, "/test/repo-ENG-2", "await_ci", 30*24*time.Hour)
+
+	d.processStuckItems(context.Background())
+	d.processStuckItems(context.Background())
+
+	if len(prov.CommentCalls) != 1 || prov.CommentCalls[0].IssueID != "ENG-1" {
+		t.Fatalf("expected one ping on ENG-1, got %+v", prov.CommentCalls)
+	}
+	if body := prov.CommentCalls[0].Args[0]; !strings.Contains(body, "at step `await_review` for 8d") {
+		t.Errorf("unexpected ping: %q", body)
+	}
+	if item, _ := d.state.GetWorkItem("/test/repo-ENG-1"); item.State != daemonstate.WorkItemActive {
+		t.Errorf("expected pinged item still active, got %s", item.State)
+	}
+
+	// Past the second threshold, the item is abandoned.
+	d.state.UpdateWorkItem("/test/repo-ENG-1", func(it *daemonstate.WorkItem) {
+		it.StepEnteredAt = time.Now().Add(-15 * 24 * time.Hour)
+	})
+	d.processStuckItems(context.Background())
+
+	item, _ := d.state.GetWorkItem("/test/repo-ENG-1")
+	if item.State != daemonstate.WorkItemFailed || !strings.Contains(item.ErrorMessage, "stuck at await_review") {
+		t.Errorf("expected abandoned item failed, got %s %q", item.State, item.ErrorMessage)
+	}
+	if other, _ := d.state.GetWorkItem("/test/repo-ENG-2"); other.State != daemonstate.WorkItemActive {
+		t.Errorf("expected item at a state without rules left alone, got %s", other.State)
+	}
+}
+
+func TestProcessStuckItems_RequeueClosesPR(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"pr", "close", "erg/eng-1"}, exec.MockResponse{})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	setReaper(d, &workflow.ReaperConfig{Rules: []workflow.ReaperRule{
+		{State: "await_review", After: days(7), Action: workflow.ReaperRequeue},
+	}})
+	addStuckItem(d, "/test/repo-ENG-1", "await_review", 10*24*time.Hour)
+	d.state.UpdateWorkItem("/test/repo-ENG-1", func(it *daemonstate.WorkItem) {
+		it.Branch = "erg/eng-1"
+		it.PRURL = "https://github.com/owner/repo/pull/7"
+	})
+
+	d.processStuckItems(context.Background())
+
+	item, _ := d.state.GetWorkItem("/test/repo-ENG-1")
+	if item.State != daemonstate.WorkItemQueued || item.PRURL != "" || item.CurrentStep != "" {
+		t.Errorf("expected item queued from the start, got %s at %q with PR %q", item.State, item.CurrentStep, item.PRURL)
+	}
+	closed := slices.ContainsFunc(mockExec.GetCalls(), func(c exec.MockCall) bool {
+		return c.Name == "gh" && slices.Contains(c.Args, "close") && slices.Contains(c.Args, "--delete-branch")
+	})
+	if !closed {
+		t.Error("expected the stuck item's PR closed")
+	}
+	entries, err := d.state.AuditLog("/test/repo-ENG-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(entries, func(e daemonstate.AuditEntry) bool { return e.Kind == daemonstate.AuditReaper }) {
+		t.Errorf("expected a reaper audit entry, got %+v", entries)
+	}
+}
+
+func TestReportStuckItems_PostsSummaryDaily(t *testing.T) {
+	var payload slackWebhookPayload
+	srv := newSlackTestServerCapture(t, http.StatusOK, &payload)
+	defer srv.Close()
+
+	d, _ := offlineTestDaemon(t)
+	setReaper(d, &workflow.ReaperConfig{
+		Rules:          []workflow.ReaperRule{{State: "await_review", After: days(7), Action: workflow.ReaperPing}},
+		SummaryWebhook: srv.URL,
+	})
+	addStuckItem(d, "/test/repo-ENG-1", "await_review", 8*24*time.Hour)
+
+	d.processStuckItems(context.Background())
+	if !strings.Contains(payload.Text, "1 work item stuck in repo") || !strings.Contains(payload.Text, "ENG-1 Stuck work — at `await_review` for 8d") {
+		t.Fatalf("unexpected summary: %q", payload.Text)
+	}
+
+	payload = slackWebhookPayload{}
+	d.processStuckItems(context.Background())
+	if payload.Text != "" {
+		t.Errorf("expected one summary a day, got another: %q", payload.Text)
+	}
+}
+
+func TestFormatStuckFor(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{5 * time.Hour, "5h"},
+		{47 * time.Hour, "47h"},
+		{8*24*time.Hour + 3*time.Hour, "8d"},
+	}
+	for _, tt := range tests {
+		if got := formatStuckFor(tt.d); got != tt.want {
+			t.Errorf("formatStuckFor(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// recoveryCheckpointMessage is the commit that saves the uncommitted work of
+// a session that was running when the daemon stopped.
+const recoveryCheckpointMessage = "WIP: checkpoint after orchestrator restart"
+
+// recoveryResumeNote tells a session resumed after a restart that it is
+// picking up work an interrupted session started.
+const recoveryResumeNote = "This task was interrupted when the orchestrator restarted and is now being resumed. " +
+	"Work already done is committed on the current branch (the last commit may be a WIP checkpoint): " +
+	"review it with git log and git diff, then continue from where it left off."
+
+// RecoveryKind classifies what startup recovery found.
+type RecoveryKind string
+
+const (
+	// RecoveryMergedPR is an in-flight item whose PR merged while the daemon
+	// was down.
+	RecoveryMergedPR RecoveryKind = "merged-pr"
+	// RecoveryDeadSession is an item whose session was running when the
+	// daemon stopped; its container died or was left behind.
+	RecoveryDeadSession RecoveryKind = "dead-session"
+	// RecoveryOrphanedWorktree is a worktree no saved work item owns.
+	RecoveryOrphanedWorktree RecoveryKind = "orphaned-worktree"
+	// RecoveryOrphanedBranch is the branch of a merged item whose cleanup
+	// was interrupted, left with no session or worktree.
+	RecoveryOrphanedBranch RecoveryKind = "orphaned-branch"
+)
+
+// RecoveryFinding is one discrepancy between the saved state and reality,
+// with what recovery does about it.
+type RecoveryFinding struct {
+	Kind     RecoveryKind
+	WorkItem string // empty for worktrees no item owns
+	RepoPath string
+	Branch   string
+	Path     string // worktree path, when there is one
+	Action   string
+}
+
+// RecoveryReport lists what startup recovery found. In a dry run nothing
+// was changed and each Action is what would be done.
+type RecoveryReport struct {
+	DryRun   bool
+	Findings []RecoveryFinding
+}
+
+func (r *RecoveryReport) add(f RecoveryFinding) {
+	r.Findings = append(r.Findings, f)
+}
+
+// Recover reconciles the work items saved when the daemon last stopped
+// against reality: PRs that merged while it was down, sessions whose
+// container died mid-step, and worktrees and branches nothing owns any
+// more. Interrupted coding sessions with a worktree are checkpointed and
+// marked to resume in it; merged items are completed; leftovers are
+// removed. Run calls it before rebuilding state from the tracker. With
+// dryRun set nothing is changed and the report says what would be done.
+func (d *Daemon) Recover(ctx context.Context, dryRun bool) (RecoveryReport, error) {
+	report := RecoveryReport{DryRun: dryRun}
+	if d.state == nil {
+		// Called outside Run (erg recover): load what Run would have.
+		state, err := daemonstate.LoadDaemonState(d.stateKey())
+		if err != nil {
+			return report, fmt.Errorf("failed to load orchestrator state: %w", err)
+		}
+		d.state = state
+		d.loadWorkflowConfigs()
+	}
+
+	saved := d.state.GetAllWorkItems()
+	slices.SortFunc(saved, func(a, b daemonstate.WorkItem) int { return strings.Compare(a.ID, b.ID) })
+
+	liveBranches := make(map[string]bool)
+	merged := make(map[string]bool)
+	for i := range saved {
+		if saved[i].IsTerminal() {
+			continue
+		}
+		if d.recoverMergedPR(ctx, &saved[i], &report) {
+			merged[saved[i].ID] = true
+			continue
+		}
+		if saved[i].Branch != "" {
+			liveBranches[saved[i].Branch] = true
+		}
+		if saved[i].SessionID != "" && (saved[i].Phase == "async_pending" || saved[i].Phase == "addressing_feedback") {
+			d.recoverDeadSession(ctx, saved[i], &report)
+		}
+	}
+	for _, item := range saved {
+		if item.State == daemonstate.WorkItemCompleted && !merged[item.ID] && !liveBranches[item.Branch] {
+			d.recoverOrphanedBranch(ctx, item, &report)
+		}
+	}
+	d.recoverOrphanedWorktrees(ctx, saved, &report)
+
+	if !dryRun && len(report.Findings) > 0 {
+		if err := d.state.Save(); err != nil {
+			return report, fmt.Errorf("failed to save state: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// logRecoveryReport logs each finding of a startup recovery.
+func (d *Daemon) logRecoveryReport(report RecoveryReport) {
+	for _, f := range report.Findings {
+		d.logger.Info("recovered after restart", "event", "recovery."+string(f.Kind),
+			"workItem", f.WorkItem, "branch", f.Branch, "path", f.Path, "action", f.Action)
+	}
+	if len(report.Findings) > 0 {
+		d.logger.Info("startup recovery complete", "findings", len(report.Findings))
+	}
+}
+
+// recoverMergedPR completes item if the PR on its branch merged while the
+// daemon was down, removing its worktree and branch when merged sessions
+// are cleaned up. It reports whether the PR had merged.
+func (d *Daemon) recoverMergedPR(ctx context.Context, item *daemonstate.WorkItem, report *RecoveryReport) bool {
+	if item.Branch == "" {
+		return false
+	}
+	repoPath := d.repoPathForItem(ctx, *item)
+	if repoPath == "" {
+		return false
+	}
+	prCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	prState, err := d.gitService.GetPRState(prCtx, repoPath, item.Branch)
+	cancel()
+	if err != nil || prState != git.PRStateMerged {
+		return false
+	}
+
+	worktree := savedWorktreePath(item.SessionID)
+	cleanup := d.config.GetAutoCleanupMerged()
+	action := "mark completed"
+	if cleanup {
+		action += ", remove worktree and branch"
+	}
+	report.add(RecoveryFinding{
+		Kind: RecoveryMergedPR, WorkItem: item.ID, RepoPath: repoPath,
+		Branch: item.Branch, Path: worktree, Action: action,
+	})
+
+	// Completed from here on, so later checks treat it as finished.
+	item.State = daemonstate.WorkItemCompleted
+	if report.DryRun {
+		return true
+	}
+	if err := d.state.MarkWorkItemTerminal(item.ID, true); err != nil {
+		d.logger.Warn("failed to complete merged work item", "workItem", item.ID, "error", err)
+		return true
+	}
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.CurrentStep = "done"
+		it.Phase = "idle"
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_repo_path"] = repoPath
+	})
+	if cleanup {
+		d.removeRecoveredWorktree(ctx, item.SessionID, repoPath, worktree, item.Branch)
+	}
+	return true
+}
+
+// recoverDeadSession deals with an item whose session was running when the
+// daemon stopped. A container left running is removed. A coding session
+// whose worktree survived has its uncommitted work checkpointed and is
+// marked to resume in that worktree; any other step is rebuilt from the
+// tracker.
+func (d *Daemon) recoverDeadSession(ctx context.Context, item daemonstate.WorkItem, report *RecoveryReport) {
+	repoPath := d.repoPathForItem(ctx, item)
+	worktree := savedWorktreePath(item.SessionID)
+	hasWorktree := worktree != "" && dirExists(worktree)
+	resumable := hasWorktree && d.isCodingStep(repoPath, item)
+
+	var actions []string
+	containerName := "erg-" + item.SessionID
+	leftover := d.leftoverContainer
+	if leftover == nil {
+		leftover = defaultLeftoverContainer
+	}
+	if found, err := leftover(ctx, containerName, !report.DryRun); err != nil {
+		d.logger.Warn("failed to check for leftover container", "container", containerName, "error", err)
+	} else if found {
+		actions = append(actions, "remove leftover container "+containerName)
+	}
+
+	if resumable {
+		gitCtx, cancel := context.WithTimeout(ctx, timeoutGitRewrite)
+		status, err := d.gitService.GetWorktreeStatus(gitCtx, worktree)
+		if err == nil && status.HasChanges {
+			actions = append(actions, fmt.Sprintf("checkpoint %d uncommitted file(s)", len(status.Files)))
+			if !report.DryRun {
+				if err := d.gitService.CommitAll(gitCtx, worktree, recoveryCheckpointMessage); err != nil {
+					d.logger.Warn("failed to checkpoint interrupted session", "workItem", item.ID, "error", err)
+				}
+			}
+		}
+		cancel()
+		actions = append(actions, "resume "+item.CurrentStep+" in its worktree")
+	} else {
+		actions = append(actions, "rebuild from the tracker")
+	}
+
+	report.add(RecoveryFinding{
+		Kind: RecoveryDeadSession, WorkItem: item.ID, RepoPath: repoPath,
+		Branch: item.Branch, Path: worktree, Action: strings.Join(actions, ", "),
+	})
+	if report.DryRun || !resumable {
+		return
+	}
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		it.Phase = phasePreempted
+		if it.StepData == nil {
+			it.StepData = make(map[string]any)
+		}
+		it.StepData["_recovered"] = true
+		if _, ok := it.StepData["_repo_path"]; !ok && repoPath != "" {
+			it.StepData["_repo_path"] = repoPath
+		}
+		it.UpdatedAt = time.Now()
+	})
+}
+
+// recoverOrphanedBranch deletes the branch of a completed item whose
+// cleanup was interrupted after its worktree went away. Branches are only
+// removed where merged sessions are cleaned up.
+func (d *Daemon) recoverOrphanedBranch(ctx context.Context, item daemonstate.WorkItem, report *RecoveryReport) {
+	if item.Branch == "" || !d.config.GetAutoCleanupMerged() {
+		return
+	}
+	if worktree := savedWorktreePath(item.SessionID); worktree != "" && dirExists(worktree) {
+		return
+	}
+	repoPath := d.repoPathForItem(ctx, item)
+	if repoPath == "" || !d.sessionService.BranchExists(ctx, repoPath, item.Branch) {
+		return
+	}
+
+	report.add(RecoveryFinding{
+		Kind: RecoveryOrphanedBranch, WorkItem: item.ID, RepoPath: repoPath,
+		Branch: item.Branch, Action: "delete branch",
+	})
+	if report.DryRun {
+		return
+	}
+	if err := d.sessionService.Delete(ctx, &config.Session{ID: item.SessionID, RepoPath: repoPath, Branch: item.Branch}); err != nil {
+		d.logger.Warn("failed to delete orphaned branch", "branch", item.Branch, "error", err)
+	}
+}
+
+// recoverOrphanedWorktrees removes worktrees of the daemon's repos that no
+// saved work item or session owns, with their branches.
+func (d *Daemon) recoverOrphanedWorktrees(ctx context.Context, saved []daemonstate.WorkItem, report *RecoveryReport) {
+	var repos []string
+	for _, repoPath := range d.config.GetRepos() {
+		if d.matchesRepoFilter(ctx, repoPath) {
+			repos = append(repos, repoPath)
+		}
+	}
+	if len(repos) == 0 {
+		return
+	}
+
+	known := make(map[string]bool)
+	for _, item := range saved {
+		if item.SessionID != "" {
+			known[item.SessionID] = true
+		}
+	}
+	for _, sess := range d.config.GetSessions() {
+		known[sess.ID] = true
+	}
+
+	orphans, err := d.sessionService.FindOrphanedWorktrees(ctx, repos, known)
+	if err != nil {
+		d.logger.Warn("failed to look for orphaned worktrees", "error", err)
+		return
+	}
+	for _, orphan := range orphans {
+		action := "remove worktree"
+		if orphan.Branch != "" {
+			action += " and branch"
+		}
+		report.add(RecoveryFinding{
+			Kind: RecoveryOrphanedWorktree, RepoPath: orphan.RepoPath,
+			Branch: orphan.Branch, Path: orphan.Path, Action: action,
+		})
+		if !report.DryRun {
+			d.removeRecoveredWorktree(ctx, orphan.ID, orphan.RepoPath, orphan.Path, orphan.Branch)
+		}
+	}
+}
+
+// removeRecoveredWorktree removes a worktree left from before a restart,
+// with its branch and session messages.
+func (d *Daemon) removeRecoveredWorktree(ctx context.Context, sessionID, repoPath, worktree, branch string) {
+	if worktree != "" && !dirExists(worktree) {
+		worktree = ""
+	}
+	sess := &config.Session{ID: sessionID, RepoPath: repoPath, WorkTree: worktree, Branch: branch}
+	if err := d.sessionService.Delete(ctx, sess); err != nil {
+		d.logger.Warn("failed to remove worktree", "path", worktree, "error", err)
+		return
+	}
+	if sessionID != "" {
+		config.DeleteSessionMessages(sessionID)
+		claude.DeleteConversation(sessionID)
+	}
+}
+
+// savedWorktreePath returns where a daemon session's worktree lives, or ""
+// if there is no session or the worktrees directory is unknown.
+func savedWorktreePath(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	worktreesDir, err := paths.WorktreesDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(worktreesDir, sessionID)
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// defaultLeftoverContainer reports whether a container named name exists,
+// removing it when remove is set.
+func defaultLeftoverContainer(ctx context.Context, name string, remove bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	defer cancel()
+	rt := container.CurrentRuntime()
+	out, err := rt.Command(ctx, "ps", "-aq", "--filter", "name=^"+name+"$").Output()
+	if err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return false, nil
+	}
+	if remove {
+		if err := rt.Command(ctx, "rm", "-f", name).Run(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
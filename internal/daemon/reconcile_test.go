@@ -0,0 +1,266 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// recoveryTestDaemon returns a daemon with a private worktrees directory
+// and a container check that records what it was asked.
+func recoveryTestDaemon(t *testing.T, mockExec *exec.MockExecutor) (*Daemon, *[]string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	d := testDaemonWithExec(testConfig(), mockExec)
+	var containers []string
+	d.leftoverContainer = func(_ context.Context, name string, remove bool) (bool, error) {
+		if remove {
+			name = "rm " + name
+		}
+		containers = append(containers, name)
+		return true, nil
+	}
+	return d, &containers
+}
+
+// addSavedWorktree creates the worktree directory of sessionID.
+func addSavedWorktree(t *testing.T, sessionID string) string {
+	t.Helper()
+	dir := savedWorktreePath(sessionID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// addSavedItem adds an active item as it was saved when the daemon stopped.
+func addSavedItem(d *Daemon, id, step, phase, sessionID, branch string) {
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        id,
+		IssueRef:  config.IssueRef{Source: "github", ID: id},
+		SessionID: sessionID,
+		Branch:    branch,
+		StepData:  map[string]any{"_repo_path": "/test/repo"},
+	})
+	d.state.AdvanceWorkItem(id, step, phase)
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) { it.State = daemonstate.WorkItemActive })
+}
+
+func findingsOf(report RecoveryReport, kind RecoveryKind) []RecoveryFinding {
+	var found []RecoveryFinding
+	for _, f := range report.Findings {
+		if f.Kind == kind {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+func TestRecover_CompletesItemsWhosePRMerged(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("gh", []string{"pr", "view", "issue-1", "--json", "state"}, exec.MockResponse{
+		Stdout: []byte(`{"state":"MERGED"}`),
+	})
+	d, _ := recoveryTestDaemon(t, mockExec)
+	d.config.(*config.Config).SetAutoCleanupMerged(true)
+	addSavedItem(d, "1", "await_ci", "idle", "sess-1", "issue-1")
+	addSavedItem(d, "2", "await_ci", "idle", "sess-2", "issue-2")
+
+	report, err := d.Recover(t.Context(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := findingsOf(report, RecoveryMergedPR)
+	if len(merged) != 1 || merged[0].WorkItem != "1" || merged[0].Action != "mark completed, remove worktree and branch" {
+		t.Fatalf("merged findings = %+v", merged)
+	}
+	if item, _ := d.state.GetWorkItem("1"); item.IsTerminal() {
+		t.Fatal("dry run must not change state")
+	}
+
+	if _, err := d.Recover(t.Context(), false); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := d.state.GetWorkItem("1")
+	if item.State != daemonstate.WorkItemCompleted || item.CurrentStep != "done" {
+		t.Errorf("item = %s at %s, want completed at done", item.State, item.CurrentStep)
+	}
+	if other, _ := d.state.GetWorkItem("2"); other.IsTerminal() {
+		t.Error("item without a merged PR should stay in flight")
+	}
+	var deleted bool
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && slices.Equal(call.Args, []string{"branch", "-D", "issue-1"}) {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Error("expected the merged branch to be deleted")
+	}
+}
+
+func TestRecover_ResumesInterruptedCodingSession(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("git", []string{"status", "--porcelain"}, exec.MockResponse{Stdout: []byte(" M main.go\n")})
+	d, containers := recoveryTestDaemon(t, mockExec)
+	addSavedItem(d, "1", "coding", "async_pending", "sess-1", "issue-1")
+	worktree := addSavedWorktree(t, "sess-1")
+
+	report, err := d.Recover(t.Context(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := findingsOf(report, RecoveryDeadSession)
+	if len(dead) != 1 {
+		t.Fatalf("dead session findings = %+v", report.Findings)
+	}
+	want := "remove leftover container erg-sess-1, checkpoint 1 uncommitted file(s), resume coding in its worktree"
+	if dead[0].Action != want {
+		t.Errorf("action = %q, want %q", dead[0].Action, want)
+	}
+	if item, _ := d.state.GetWorkItem("1"); item.Phase != "async_pending" {
+		t.Fatal("dry run must not change state")
+	}
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && len(call.Args) > 0 && call.Args[0] == "commit" {
+			t.Fatal("dry run must not commit")
+		}
+	}
+
+	if _, err := d.Recover(t.Context(), false); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := d.state.GetWorkItem("1")
+	if item.Phase != phasePreempted || item.StepData["_recovered"] != true {
+		t.Errorf("phase = %q, step data %v; want preempted and marked recovered", item.Phase, item.StepData)
+	}
+	if !slices.Equal(*containers, []string{"erg-sess-1", "rm erg-sess-1"}) {
+		t.Errorf("container checks = %v", *containers)
+	}
+	var committed bool
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && slices.Equal(call.Args, []string{"commit", "-m", recoveryCheckpointMessage}) {
+			committed = call.Dir == worktree
+		}
+	}
+	if !committed {
+		t.Error("expected uncommitted work to be checkpointed in the worktree")
+	}
+}
+
+func TestRecover_DeadSessionWithoutWorktreeIsRebuilt(t *testing.T) {
+	d, _ := recoveryTestDaemon(t, exec.NewMockExecutor(nil))
+	d.leftoverContainer = func(context.Context, string, bool) (bool, error) { return false, nil }
+	addSavedItem(d, "1", "coding", "async_pending", "sess-1", "issue-1")
+
+	report, err := d.Recover(t.Context(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := findingsOf(report, RecoveryDeadSession)
+	if len(dead) != 1 || dead[0].Action != "rebuild from the tracker" {
+		t.Fatalf("dead session findings = %+v", dead)
+	}
+	if item, _ := d.state.GetWorkItem("1"); item.Phase != "async_pending" {
+		t.Errorf("phase = %q, want it left for the rebuild", item.Phase)
+	}
+}
+
+func TestRecover_RemovesOrphanedWorktrees(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddExactMatch("git", []string{"rev-parse", "--abbrev-ref", "HEAD"}, exec.MockResponse{Stdout: []byte("issue-9\n")})
+	d, _ := recoveryTestDaemon(t, mockExec)
+	repo := t.TempDir()
+	d.config.(*config.Config).Repos = []string{repo}
+	d.repoFilter = repo
+	addSavedItem(d, "1", "await_ci", "idle", "sess-1", "issue-1")
+
+	for _, id := range []string{"sess-1", "orphan"} {
+		dir := addSavedWorktree(t, id)
+		gitdir := "gitdir: " + filepath.Join(repo, ".git", "worktrees", id) + "\n"
+		if err := os.WriteFile(filepath.Join(dir, ".git"), []byte(gitdir), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := d.Recover(t.Context(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphans := findingsOf(report, RecoveryOrphanedWorktree)
+	if len(orphans) != 1 || filepath.Base(orphans[0].Path) != "orphan" || orphans[0].Branch != "issue-9" {
+		t.Fatalf("orphaned worktree findings = %+v", orphans)
+	}
+	var removed []string
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && len(call.Args) > 2 && call.Args[0] == "worktree" && call.Args[1] == "remove" {
+			removed = append(removed, filepath.Base(call.Args[2]))
+		}
+	}
+	if !slices.Equal(removed, []string{"orphan"}) {
+		t.Errorf("removed worktrees = %v, want only the orphan", removed)
+	}
+}
+
+func TestRecover_FindsBranchesLeftByInterruptedCleanup(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	d, _ := recoveryTestDaemon(t, mockExec)
+	d.config.(*config.Config).SetAutoCleanupMerged(true)
+	addSavedItem(d, "done", "done", "idle", "sess-done", "issue-1")
+	d.state.MarkWorkItemTerminal("done", true)
+	addSavedItem(d, "again", "coding", "idle", "", "issue-2")
+	addSavedItem(d, "done-again", "done", "idle", "sess-old", "issue-2")
+	d.state.MarkWorkItemTerminal("done-again", true)
+
+	report, err := d.Recover(t.Context(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches := findingsOf(report, RecoveryOrphanedBranch)
+	if len(branches) != 1 || branches[0].Branch != "issue-1" {
+		t.Fatalf("orphaned branch findings = %+v, want only issue-1", branches)
+	}
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "git" && len(call.Args) > 0 && call.Args[0] == "branch" {
+			t.Fatalf("dry run must not delete branches: %v", call.Args)
+		}
+	}
+}
+
+func TestRebuild_KeepsPreemptedItems(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("gh", []string{"issue", "list"}, exec.MockResponse{
+		Stdout: mockGitHubIssuesList([]git.GitHubIssue{{Number: 42, Title: "Fix bug"}}),
+	})
+	mockExec.AddPrefixMatch("gh", []string{"api", "graphql"}, exec.MockResponse{Stdout: mockGitHubGraphQL(nil)})
+
+	d, _ := setupRebuildDaemon(t, mockExec)
+	id := "/test/repo-42"
+	addSavedItem(d, id, "coding", phasePreempted, "sess-42", "issue-42")
+	d.state.UpdateWorkItem(id, func(it *daemonstate.WorkItem) { it.StepData["_recovered"] = true })
+
+	d.rebuildStateFromTracker(t.Context())
+
+	item, ok := d.state.GetWorkItem(id)
+	if !ok {
+		t.Fatal("expected the item to be rebuilt")
+	}
+	if item.Phase != phasePreempted || item.SessionID != "sess-42" || item.CurrentStep != "coding" {
+		t.Errorf("item = %s/%s in %s, want the saved preempted item", item.CurrentStep, item.Phase, item.SessionID)
+	}
+	if sess := d.config.GetSession("sess-42"); sess == nil || !strings.HasSuffix(sess.WorkTree, "sess-42") {
+		t.Errorf("expected the saved session to be reconstructed, got %+v", sess)
+	}
+}
@@ -2,7 +2,6 @@ package daemon
 
 import (
 	"context"
-	"fmt"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -23,7 +22,9 @@ import (
 //
 // Terminal items (completed/failed) are preserved from the old state for
 // dashboard display and history. All non-terminal items are discarded and
-// rebuilt from the tracker.
+// rebuilt from the tracker, except preempted coding sessions whose issue is
+// still active, which keep their session and worktree so they resume where
+// they stopped.
 func (d *Daemon) rebuildStateFromTracker(ctx context.Context) {
 	if d.state == nil {
 		return
@@ -32,6 +33,12 @@ func (d *Daemon) rebuildStateFromTracker(ctx context.Context) {
 	log := d.logger.With("component", "rebuild")
 
 	// 1. Clear all non-terminal items — we'll rebuild them from the tracker.
+	preempted := make(map[string]daemonstate.WorkItem)
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.Phase == phasePreempted && item.SessionID != "" {
+			preempted[item.ID] = item
+		}
+	}
 	d.state.ClearNonTerminalItems()
 
 	// 2. For each repo, fetch issues matching the workflow filter and rebuild.
@@ -80,7 +87,7 @@ func (d *Daemon) rebuildStateFromTracker(ctx context.Context) {
 			// Skip if an item already exists for this issue in this repo.
 			// Use the repo-scoped work item ID to avoid collisions when
 			// different repos have issues with the same number.
-			workItemID := fmt.Sprintf("%s-%s", repoPath, issue.ID)
+			workItemID := d.workItemID(repoPath, provider, issue.ID)
 			if _, exists := d.state.GetWorkItem(workItemID); exists {
 				continue
 			}
@@ -99,6 +106,10 @@ func (d *Daemon) rebuildStateFromTracker(ctx context.Context) {
 			}
 
 			item := d.rebuildWorkItem(rebuildCtx, repoPath, issue, engine, provider)
+			if saved, ok := preempted[workItemID]; ok && item != nil && !item.IsTerminal() {
+				d.dropRebuiltSession(item)
+				item = &saved
+			}
 			if item != nil {
 				d.state.AddRebuiltWorkItem(item)
 				log.Info("rebuilt work item",
@@ -115,7 +126,8 @@ func (d *Daemon) rebuildStateFromTracker(ctx context.Context) {
 
 // rebuildWorkItem determines the correct workflow position for a single issue
 // by querying the tracker for artifacts (PR, CI, review status) and walking
-// the workflow graph.
+// the workflow graph. Issues whose labels select a named workflow are walked
+// on that workflow's engine instead of engine.
 func (d *Daemon) rebuildWorkItem(
 	ctx context.Context,
 	repoPath string,
@@ -125,14 +137,21 @@ func (d *Daemon) rebuildWorkItem(
 ) *daemonstate.WorkItem {
 	log := d.logger.With("component", "rebuild", "issue", issue.ID)
 
+	workflowName := d.selectWorkflow(repoPath, issue.Labels)
+	if nw, ok := d.lookupNamedWorkflow(repoPath, workflowName); ok {
+		engine = nw.engine
+	}
+
 	item := &daemonstate.WorkItem{
-		ID: fmt.Sprintf("%s-%s", repoPath, issue.ID),
+		ID: d.workItemID(repoPath, provider, issue.ID),
 		IssueRef: config.IssueRef{
 			Source: string(provider),
 			ID:     issue.ID,
 			Title:  issue.Title,
 			URL:    issue.URL,
 		},
+		Workflow:        workflowName,
+		WorkflowVersion: d.workflowVersionFor(repoPath, workflowName),
 		StepData: map[string]any{
 			"_repo_path": repoPath,
 		},
@@ -425,6 +444,14 @@ func workflowStartsWithTask(engine *workflow.Engine) bool {
 	}
 }
 
+// dropRebuiltSession removes the synthetic session rebuildWorkItem created
+// for item, when the item is replaced by its saved copy.
+func (d *Daemon) dropRebuiltSession(item *daemonstate.WorkItem) {
+	if item.SessionID != "" {
+		d.config.RemoveSession(item.SessionID)
+	}
+}
+
 // reconstructSessions creates minimal config.Session objects for work items
 // whose sessions are missing from the in-memory config. On daemon restart,
 // the config starts empty (AgentConfig.Save() is a no-op), so sessions need
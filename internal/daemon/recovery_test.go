@@ -1043,6 +1043,9 @@ func (m *mockRebuildProvider) FetchIssues(_ context.Context, _ string, _ issues.
 }
 func (m *mockRebuildProvider) RemoveLabel(_ context.Context, _, _, _ string) error { return nil }
 func (m *mockRebuildProvider) Comment(_ context.Context, _, _, _ string) error     { return nil }
+func (m *mockRebuildProvider) Claim(_ context.Context, _, _ string) (bool, error)  { return true, nil }
+func (m *mockRebuildProvider) Unclaim(_ context.Context, _, _ string) error        { return nil }
+func (m *mockRebuildProvider) CloseIssue(_ context.Context, _, _ string) error     { return nil }
 func (m *mockRebuildProvider) CheckIssueHasLabel(_ context.Context, _, _, _ string) (bool, error) {
 	return false, nil
 }
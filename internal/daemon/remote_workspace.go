@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/zhubert/erg/internal/config"
+)
+
+// WorkspaceSyncer copies session worktrees to and from the remote host that
+// session containers run on, which can't mount them.
+type WorkspaceSyncer interface {
+	// Push replaces the session's remote copy with its worktree.
+	Push(ctx context.Context, sessionID, worktree, image string) error
+	// Pull brings the session's commits and changes back into its worktree.
+	Pull(ctx context.Context, sessionID, worktree, image string) error
+	// Remove deletes the session's remote copy.
+	Remove(ctx context.Context, sessionID string) error
+}
+
+// WithWorkspaceSyncer runs containerized sessions against remote copies of
+// their worktrees, for a container runtime on another machine.
+func WithWorkspaceSyncer(s WorkspaceSyncer) Option {
+	return func(d *Daemon) { d.workspaceSyncer = s }
+}
+
+// pushRemoteWorkspace copies a containerized session's worktree to the
+// remote host before its container starts.
+func (d *Daemon) pushRemoteWorkspace(ctx context.Context, sess *config.Session) error {
+	if d.workspaceSyncer == nil || !sess.Containerized {
+		return nil
+	}
+	if err := d.workspaceSyncer.Push(ctx, sess.ID, sess.WorkTree, d.containerImageForRepo(sess.RepoPath)); err != nil {
+		d.logger.Error("failed to copy worktree to remote host", "sessionID", sess.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// pullRemoteWorkspace brings a finished containerized session's work back
+// from the remote host, so the rest of the workflow (pushing the branch,
+// opening the PR) sees it in the worktree.
+func (d *Daemon) pullRemoteWorkspace(ctx context.Context, sessionID string) error {
+	if d.workspaceSyncer == nil {
+		return nil
+	}
+	sess := d.config.GetSession(sessionID)
+	if sess == nil || !sess.Containerized {
+		return nil
+	}
+	if err := d.workspaceSyncer.Pull(ctx, sess.ID, sess.WorkTree, d.containerImageForRepo(sess.RepoPath)); err != nil {
+		d.logger.Error("failed to copy session work back from remote host", "sessionID", sess.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// removeRemoteWorkspace deletes a session's copy on the remote host.
+func (d *Daemon) removeRemoteWorkspace(ctx context.Context, sess *config.Session) {
+	if d.workspaceSyncer == nil || !sess.Containerized {
+		return
+	}
+	if err := d.workspaceSyncer.Remove(ctx, sess.ID); err != nil {
+		d.logger.Warn("failed to remove remote workspace", "sessionID", sess.ID, "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+// fakeSyncer records workspace syncs as "op:sessionID".
+type fakeSyncer struct {
+	calls   []string
+	pushErr error
+	pullErr error
+}
+
+func (f *fakeSyncer) Push(_ context.Context, sessionID, _, _ string) error {
+	f.calls = append(f.calls, "push:"+sessionID)
+	return f.pushErr
+}
+
+func (f *fakeSyncer) Pull(_ context.Context, sessionID, _, _ string) error {
+	f.calls = append(f.calls, "pull:"+sessionID)
+	return f.pullErr
+}
+
+func (f *fakeSyncer) Remove(_ context.Context, sessionID string) error {
+	f.calls = append(f.calls, "remove:"+sessionID)
+	return nil
+}
+
+func TestCreateWorkerWithPrompt_PushesRemoteWorkspace(t *testing.T) {
+	for _, pushErr := range []error{nil, errors.New("host unreachable")} {
+		d := testDaemon(testConfig())
+		syncer := &fakeSyncer{pushErr: pushErr}
+		d.workspaceSyncer = syncer
+		d.sessionMgr.SetRunnerFactory(func(sessionID, _, _ string, _ bool, _ []claude.Message) claude.RunnerInterface {
+			return claude.NewMockRunner(sessionID, false, nil)
+		})
+		sess := testSession("sess-remote")
+		d.config.AddSession(*sess)
+		item := daemonstate.WorkItem{ID: "item-remote", SessionID: sess.ID, CurrentStep: "coding", StepData: map[string]any{}}
+		d.state.AddWorkItem(&item)
+
+		w := d.createWorkerWithPrompt(context.Background(), item, sess, "go", "")
+
+		if !slices.Equal(syncer.calls, []string{"push:sess-remote"}) {
+			t.Errorf("syncs = %v, want a push", syncer.calls)
+		}
+		if pushErr != nil && (!w.Done() || !errors.Is(w.ExitError(), pushErr)) {
+			t.Errorf("expected a failed push to fail the worker, got done=%v err=%v", w.Done(), w.ExitError())
+		}
+		if pushErr == nil && w.Done() {
+			t.Error("expected a runnable worker after a successful push")
+		}
+	}
+}
+
+func TestCollectCompletedWorkers_PullsRemoteWorkspace(t *testing.T) {
+	d := testDaemon(testConfig())
+	syncer := &fakeSyncer{pullErr: errors.New("volume gone")}
+	d.workspaceSyncer = syncer
+	sess := testSession("sess-remote")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID: "item-remote", IssueRef: config.IssueRef{Source: "github", ID: "81"},
+		SessionID: sess.ID, CurrentStep: "coding", StepData: map[string]any{},
+	})
+	d.state.AdvanceWorkItem("item-remote", "coding", "async_pending")
+	d.workers["item-remote"] = worker.NewDoneWorkerWithError(nil)
+
+	d.collectCompletedWorkers(context.Background())
+
+	if !slices.Equal(syncer.calls, []string{"pull:sess-remote"}) {
+		t.Errorf("syncs = %v, want a pull", syncer.calls)
+	}
+	// Work that can't be brought back fails the session.
+	item, _ := d.state.GetWorkItem("item-remote")
+	if item.CurrentStep != "failed" {
+		t.Errorf("expected the failed pull handled as a session failure, got %s/%s", item.CurrentStep, item.Phase)
+	}
+}
+
+func TestCleanupSession_RemovesRemoteWorkspace(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	syncer := &fakeSyncer{}
+	d.workspaceSyncer = syncer
+	cfg.AddSession(*testSession("sess-remote"))
+
+	d.cleanupSession(context.Background(), "sess-remote")
+
+	if !slices.Equal(syncer.calls, []string{"remove:sess-remote"}) {
+		t.Errorf("syncs = %v, want a remove", syncer.calls)
+	}
+}
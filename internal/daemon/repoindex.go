@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/repoindex"
+	"github.com/zhubert/erg/internal/sanitize"
+)
+
+// repoIndexHits is how many indexed chunks are looked up for an issue;
+// repo_index_max_chars decides how many of them fit in the prompt.
+const repoIndexHits = 8
+
+// withRepoContext appends the code the repo index finds most related to the
+// issue to a session's initial message, read from the session's worktree
+// at dir. The message is returned unchanged when retrieval is disabled for
+// the repo, no index has been built, or nothing related is found.
+func (d *Daemon) withRepoContext(repoPath, dir string, item daemonstate.WorkItem, msg string) string {
+	wfCfg := d.getWorkflowConfig(repoPath)
+	if !wfCfg.RepoIndexEnabled() || item.StepData["_synthetic"] == "true" {
+		return msg
+	}
+	ix, err := repoindex.Load(repoPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			d.logger.Debug("repo index enabled but not built; run 'erg index build'", "repo", repoPath)
+		} else {
+			d.logger.Warn("failed to load repo index", "repo", repoPath, "error", err)
+		}
+		return msg
+	}
+
+	m := wfCfg.RepoIndexModel()
+	embedder, err := repoindex.NewEmbedder(m.URL, m.Model, m.APIKeyEnv)
+	if err != nil {
+		d.logger.Warn("failed to set up repo index embedder", "repo", repoPath, "error", err)
+		return msg
+	}
+	body, _ := item.StepData["issue_body"].(string)
+	hits, err := ix.Search(embedder, item.IssueRef.Title+"\n"+body, repoIndexHits)
+	if err != nil {
+		d.logger.Warn("failed to search repo index", "repo", repoPath, "workItem", item.ID, "error", err)
+		return msg
+	}
+	snippets := repoindex.Render(dir, hits, wfCfg.RepoIndexMaxChars())
+	if snippets == "" {
+		return msg
+	}
+	return msg + "\n\n---\nCode that may be related, found in the repository index (it can be out of date or off the mark; read the files before relying on it):\n" +
+		sanitize.UntrustedContent("repo_context", snippets)
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/repoindex"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// indexedTestRepo creates a git repo with a config parser and a dashboard
+// handler and builds its index.
+func indexedTestRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	files := map[string]string{
+		"config/parse.go":  "package config\n\n// parseConfig decodes the YAML config file.\nfunc parseConfig(data []byte) (*Config, error) {\n\treturn decodeYAML(data)\n}\n",
+		"web/dashboard.go": "package web\n\nfunc renderDashboard(w http.ResponseWriter) {\n\tw.Write(indexHTML)\n}\n",
+	}
+	for rel, content := range files {
+		os.MkdirAll(filepath.Join(repo, filepath.Dir(rel)), 0o755)
+		if err := os.WriteFile(filepath.Join(repo, rel), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ix, _, err := repoindex.Update(t.Context(), repo, nil, repoindex.DefaultEmbedder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if dir, err := repoindex.Dir(repo); err == nil {
+			os.RemoveAll(dir)
+		}
+	})
+	return repo
+}
+
+func repoIndexTestItem() daemonstate.WorkItem {
+	return daemonstate.WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "1", Title: "Crash on an empty YAML config file"},
+		StepData: map[string]any{"issue_body": "parsing the config panics"},
+	}
+}
+
+func TestWithRepoContext_AppendsRelatedCode(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	repo := indexedTestRepo(t)
+	enabled := true
+	cfg := *d.workflowConfigs["/test/repo"]
+	cfg.Settings = &workflow.SettingsConfig{RepoIndex: &enabled}
+	d.workflowConfigs[repo] = &cfg
+
+	got := d.withRepoContext(repo, repo, repoIndexTestItem(), "msg")
+
+	if !strings.HasPrefix(got, "msg\n\n---\nCode that may be related") {
+		t.Errorf("unexpected message: %q", got)
+	}
+	if !strings.Contains(got, `<user-content type="repo_context">`) || !strings.Contains(got, "config/parse.go (lines 1-6)") {
+		t.Errorf("expected the config parser wrapped as untrusted content, got %q", got)
+	}
+
+	cfg.Settings.RepoIndexMaxChars = 40
+	if got := d.withRepoContext(repo, repo, repoIndexTestItem(), "msg"); got != "msg" {
+		t.Errorf("nothing fits in 40 chars, so the message should be unchanged: %q", got)
+	}
+}
+
+func TestWithRepoContext_SkipsWhenDisabledOrUnbuilt(t *testing.T) {
+	d, _ := offlineTestDaemon(t)
+	repo := indexedTestRepo(t)
+	cfg := *d.workflowConfigs["/test/repo"]
+	d.workflowConfigs[repo] = &cfg
+
+	if got := d.withRepoContext(repo, repo, repoIndexTestItem(), "msg"); got != "msg" {
+		t.Errorf("message changed with repo_index unset: %q", got)
+	}
+
+	enabled := true
+	cfg.Settings = &workflow.SettingsConfig{RepoIndex: &enabled}
+	unbuilt := t.TempDir()
+	d.workflowConfigs[unbuilt] = &cfg
+	if got := d.withRepoContext(unbuilt, unbuilt, repoIndexTestItem(), "msg"); got != "msg" {
+		t.Errorf("message changed without an index: %q", got)
+	}
+
+	synthetic := repoIndexTestItem()
+	synthetic.StepData["_synthetic"] = "true"
+	if got := d.withRepoContext(repo, repo, synthetic, "msg"); got != "msg" {
+		t.Errorf("message changed for a synthetic item: %q", got)
+	}
+}
@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+// resourceSampleInterval is how often running session containers are sampled
+// for CPU, memory, and disk I/O. Usage in the last interval before a container
+// exits is not captured.
+const resourceSampleInterval = 10 * time.Second
+
+// runResourceSampler samples container resource usage for running workers
+// until ctx is cancelled.
+func (d *Daemon) runResourceSampler(ctx context.Context) {
+	last := make(map[*worker.SessionWorker]container.Usage)
+	ticker := time.NewTicker(resourceSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.sampleResourceUsage(ctx, last)
+		}
+	}
+}
+
+// sampleResourceUsage reads the cumulative usage of each running
+// containerized session and records the growth since the previous sample on
+// its work item. last holds the previous sample per worker; each worker runs
+// its own container, so a worker seen for the first time starts from zero.
+func (d *Daemon) sampleResourceUsage(ctx context.Context, last map[*worker.SessionWorker]container.Usage) {
+	d.mu.Lock()
+	running := make(map[string]*worker.SessionWorker, len(d.workers))
+	for itemID, w := range d.workers {
+		if !w.Done() {
+			running[itemID] = w
+		}
+	}
+	d.mu.Unlock()
+
+	read := d.readContainerUsage
+	if read == nil {
+		read = container.ReadUsage
+	}
+
+	active := make(map[*worker.SessionWorker]bool, len(running))
+	for itemID, w := range running {
+		sess := d.config.GetSession(w.SessionID())
+		if sess == nil || !sess.Containerized {
+			continue
+		}
+		active[w] = true
+
+		readCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+		cur, err := read(readCtx, "erg-"+w.SessionID())
+		cancel()
+		if err != nil {
+			d.logger.Debug("failed to sample container usage", "workItem", itemID, "error", err)
+			continue
+		}
+
+		prev := last[w]
+		d.state.RecordItemResources(itemID,
+			max(cur.CPUSeconds-prev.CPUSeconds, 0),
+			cur.PeakMemoryBytes,
+			max(cur.DiskReadBytes-prev.DiskReadBytes, 0),
+			max(cur.DiskWriteBytes-prev.DiskWriteBytes, 0))
+		last[w] = cur
+	}
+
+	for w := range last {
+		if !active[w] {
+			delete(last, w)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+func TestSampleResourceUsage(t *testing.T) {
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	cfg.AddSession(*sess)
+	d := testDaemon(cfg)
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", SessionID: "sess-1"})
+
+	samples := []container.Usage{
+		{CPUSeconds: 4, PeakMemoryBytes: 100, DiskReadBytes: 10, DiskWriteBytes: 20},
+		{CPUSeconds: 10, PeakMemoryBytes: 300, DiskReadBytes: 15, DiskWriteBytes: 50},
+	}
+	var names []string
+	d.readContainerUsage = func(_ context.Context, name string) (container.Usage, error) {
+		names = append(names, name)
+		if len(samples) == 0 {
+			return container.Usage{}, errors.New("container gone")
+		}
+		u := samples[0]
+		samples = samples[1:]
+		return u, nil
+	}
+
+	w := worker.NewSessionWorker(d, sess, nil, "")
+	d.workers["item-1"] = w
+	last := make(map[*worker.SessionWorker]container.Usage)
+
+	d.sampleResourceUsage(context.Background(), last)
+	d.sampleResourceUsage(context.Background(), last)
+	d.sampleResourceUsage(context.Background(), last) // read fails: nothing recorded
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.ContainerCPUSeconds != 10 || item.PeakMemoryBytes != 300 || item.DiskReadBytes != 15 || item.DiskWriteBytes != 50 {
+		t.Errorf("recorded usage = cpu %v mem %d read %d write %d, want the latest cumulative sample",
+			item.ContainerCPUSeconds, item.PeakMemoryBytes, item.DiskReadBytes, item.DiskWriteBytes)
+	}
+	if len(names) != 3 || names[0] != "erg-sess-1" {
+		t.Errorf("sampled containers = %v", names)
+	}
+
+	// A new worker for the same item runs a new container: its counters
+	// start from zero rather than the previous worker's.
+	delete(d.workers, "item-1")
+	d.sampleResourceUsage(context.Background(), last)
+	if len(last) != 0 {
+		t.Errorf("expected finished worker's baseline dropped, got %d", len(last))
+	}
+	d.workers["item-1"] = worker.NewSessionWorker(d, sess, nil, "")
+	samples = []container.Usage{{CPUSeconds: 2}}
+	d.sampleResourceUsage(context.Background(), last)
+	if item, _ := d.state.GetWorkItem("item-1"); item.ContainerCPUSeconds != 12 {
+		t.Errorf("ContainerCPUSeconds = %v, want 12 after second container", item.ContainerCPUSeconds)
+	}
+}
+
+func TestSampleResourceUsage_SkipsHostSessions(t *testing.T) {
+	cfg := testConfig()
+	sess := testSession("sess-1")
+	sess.Containerized = false
+	cfg.AddSession(*sess)
+	d := testDaemon(cfg)
+	d.readContainerUsage = func(context.Context, string) (container.Usage, error) {
+		t.Error("unexpected container read for a host session")
+		return container.Usage{}, nil
+	}
+	d.workers["item-1"] = worker.NewSessionWorker(d, sess, nil, "")
+
+	d.sampleResourceUsage(context.Background(), make(map[*worker.SessionWorker]container.Usage))
+}
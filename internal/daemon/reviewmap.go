@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/git"
+)
+
+// generateReviewMap builds the review map section for the session's branch.
+func (d *Daemon) generateReviewMap(ctx context.Context, sess *config.Session) (string, error) {
+	baseBranch := sess.BaseBranch
+	if baseBranch == "" {
+		baseBranch = d.gitService.GetDefaultBranch(ctx, sess.RepoPath)
+	}
+	mapCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer cancel()
+	return d.gitService.GenerateReviewMap(mapCtx, sess.GetWorkDir(), sess.Branch, baseBranch)
+}
+
+// stampPRReviewMap adds (or refreshes) the review map section in the body of
+// the PR for the session's branch.
+func (d *Daemon) stampPRReviewMap(ctx context.Context, sess *config.Session) error {
+	reviewMap, err := d.generateReviewMap(ctx, sess)
+	if err != nil || reviewMap == "" {
+		return err
+	}
+
+	bodyCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	body, err := d.gitService.GetPRBody(bodyCtx, sess.RepoPath, sess.Branch)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	updated := git.WithReviewMap(body, reviewMap)
+	if updated == body {
+		return nil
+	}
+	updateCtx, updateCancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer updateCancel()
+	return d.gitService.UpdatePRBody(updateCtx, sess.RepoPath, sess.Branch, updated)
+}
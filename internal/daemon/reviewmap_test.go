@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/exec"
+)
+
+func TestStampPRReviewMap(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("git", []string{"diff"}, exec.MockResponse{
+		Stdout: []byte("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n+\tgo work()\n"),
+	})
+	mockExec.AddPrefixMatch("gh", []string{"pr", "view"}, exec.MockResponse{
+		Stdout: []byte(`{"body":"## Summary\nFixes the bug."}`),
+	})
+	mockExec.AddPrefixMatch("gh", []string{"pr", "edit"}, exec.MockResponse{})
+	d := testDaemonWithExec(testConfig(), mockExec)
+	sess := testSession("sess-1")
+	sess.BaseBranch = "main"
+
+	if err := d.stampPRReviewMap(context.Background(), sess); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body string
+	for _, c := range mockExec.GetCalls() {
+		if c.Name == "gh" && len(c.Args) >= 5 && c.Args[1] == "edit" && c.Args[3] == "--body" {
+			body = c.Args[4]
+		}
+	}
+	if !strings.HasPrefix(body, "## Summary\nFixes the bug.\n\n") {
+		t.Errorf("expected original body kept, got %q", body)
+	}
+	if !strings.Contains(body, "## Review map") || !strings.Contains(body, "`main.go`") || !strings.Contains(body, "starts goroutines") {
+		t.Errorf("expected review map for main.go, got %q", body)
+	}
+}
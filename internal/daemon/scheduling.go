@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"math"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+)
+
+// getRepoMaxConcurrent returns how many sessions repoPath may run at once,
+// or 0 when only the global limit applies. In multi-repo mode a repo's
+// manifest entry sets it, falling back to its workflow's
+// settings.max_concurrent; in single-repo mode that setting is the global
+// limit itself.
+func (d *Daemon) getRepoMaxConcurrent(repoPath string) int {
+	if n := d.repoMaxConcurrent[repoPath]; n > 0 {
+		return n
+	}
+	if len(d.repoWorkflowFiles) == 0 {
+		return 0
+	}
+	if wfCfg, ok := d.lookupWorkflowConfig(repoPath); ok && wfCfg.Settings != nil {
+		return wfCfg.Settings.MaxConcurrent
+	}
+	return 0
+}
+
+// repoSlotCount returns the number of repoPath's work items consuming
+// concurrency slots.
+func (d *Daemon) repoSlotCount(repoPath string) int {
+	count := 0
+	for _, item := range d.state.GetActiveWorkItems() {
+		if item.ConsumesSlot() && d.workItemRepoPath(item) == repoPath {
+			count++
+		}
+	}
+	return count
+}
+
+// repoQueueBudget returns how many more issues repoPath may queue before
+// its work would exceed its own concurrency limit. Repos without one are
+// only bounded by the global limit.
+func (d *Daemon) repoQueueBudget(repoPath string) int {
+	limit := d.getRepoMaxConcurrent(repoPath)
+	if limit <= 0 {
+		return math.MaxInt
+	}
+	queued := 0
+	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemQueued) {
+		if d.workItemRepoPath(item) == repoPath {
+			queued++
+		}
+	}
+	return limit - d.repoSlotCount(repoPath) - queued
+}
+
+// rotatePollOrder returns repos starting one further along each call, so
+// every repo regularly gets the first turn at free slots.
+func (d *Daemon) rotatePollOrder(repos []string) []string {
+	if len(repos) < 2 {
+		return repos
+	}
+	start := d.pollRotation % len(repos)
+	d.pollRotation++
+	return append(append([]string(nil), repos[start:]...), repos[:start]...)
+}
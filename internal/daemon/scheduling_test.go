@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// multiRepoTestDaemon returns a multi-repo daemon watching /repo/a (Linear)
+// and /repo/b (Asana), each with a backlog of three issues.
+func multiRepoTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	cfg := testConfig()
+	cfg.Repos = []string{"/repo/a", "/repo/b"}
+	d := testDaemon(cfg)
+	d.repoFilter = ""
+	d.repoWorkflowFiles = map[string]string{"/repo/a": "", "/repo/b": ""}
+	d.maxConcurrent = 10
+
+	linear := issues.NewFakeProvider(issues.SourceLinear)
+	asana := issues.NewFakeProvider(issues.SourceAsana)
+	linear.SetIssues([]issues.Issue{{ID: "A-1"}, {ID: "A-2"}, {ID: "A-3"}})
+	asana.SetIssues([]issues.Issue{{ID: "B-1"}, {ID: "B-2"}, {ID: "B-3"}})
+	// Pre-existing claims of our own skip the claim consistency delay.
+	own := issues.ClaimInfo{DaemonID: d.claimIdentity(), Expires: time.Now().Add(time.Hour)}
+	for _, id := range []string{"A-1", "A-2", "A-3"} {
+		linear.PostClaim(context.Background(), "", id, own)
+	}
+	for _, id := range []string{"B-1", "B-2", "B-3"} {
+		asana.PostClaim(context.Background(), "", id, own)
+	}
+	d.issueRegistry = issues.NewProviderRegistry(linear, asana)
+	d.workflowConfigs["/repo/a"] = &workflow.Config{Source: workflow.SourceConfig{Provider: "linear"}}
+	d.workflowConfigs["/repo/b"] = &workflow.Config{Source: workflow.SourceConfig{Provider: "asana"}}
+	return d
+}
+
+func queuedPerRepo(d *Daemon) map[string]int {
+	counts := make(map[string]int)
+	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemQueued) {
+		counts[d.workItemRepoPath(item)]++
+	}
+	return counts
+}
+
+func TestPollForNewIssues_RoundRobinsAcrossRepos(t *testing.T) {
+	d := multiRepoTestDaemon(t)
+	d.maxConcurrent = 4
+
+	d.pollForNewIssues(context.Background())
+
+	got := queuedPerRepo(d)
+	if got["/repo/a"] != 2 || got["/repo/b"] != 2 {
+		t.Errorf("expected the free slots split evenly, got %v", got)
+	}
+}
+
+func TestPollForNewIssues_RotatesFirstRepo(t *testing.T) {
+	d := multiRepoTestDaemon(t)
+	d.maxConcurrent = 1
+
+	d.pollForNewIssues(context.Background())
+	first := queuedPerRepo(d)
+	for _, item := range d.state.GetWorkItemsByState(daemonstate.WorkItemQueued) {
+		d.state.MarkWorkItemTerminal(item.ID, true)
+	}
+	d.pollForNewIssues(context.Background())
+	second := queuedPerRepo(d)
+
+	if first["/repo/a"]+second["/repo/a"] != 1 || first["/repo/b"]+second["/repo/b"] != 1 {
+		t.Errorf("expected each repo to get the single slot once, got %v then %v", first, second)
+	}
+}
+
+func TestPollForNewIssues_RespectsRepoLimit(t *testing.T) {
+	d := multiRepoTestDaemon(t)
+	d.repoMaxConcurrent = map[string]int{"/repo/a": 1}
+	d.workflowConfigs["/repo/b"].Settings = &workflow.SettingsConfig{MaxConcurrent: 2}
+
+	d.pollForNewIssues(context.Background())
+
+	got := queuedPerRepo(d)
+	if got["/repo/a"] != 1 || got["/repo/b"] != 2 {
+		t.Errorf("expected per-repo limits 1 and 2, got %v", got)
+	}
+}
+
+func TestGetRepoMaxConcurrent(t *testing.T) {
+	d := multiRepoTestDaemon(t)
+	d.repoMaxConcurrent = map[string]int{"/repo/a": 1}
+	d.workflowConfigs["/repo/a"].Settings = &workflow.SettingsConfig{MaxConcurrent: 5}
+	d.workflowConfigs["/repo/b"].Settings = &workflow.SettingsConfig{MaxConcurrent: 3}
+
+	if got := d.getRepoMaxConcurrent("/repo/a"); got != 1 {
+		t.Errorf("manifest limit: got %d, want 1", got)
+	}
+	if got := d.getRepoMaxConcurrent("/repo/b"); got != 3 {
+		t.Errorf("workflow fallback: got %d, want 3", got)
+	}
+
+	// In single-repo mode the workflow setting is the global limit.
+	d.repoWorkflowFiles = nil
+	if got := d.getRepoMaxConcurrent("/repo/b"); got != 0 {
+		t.Errorf("single-repo mode: got %d, want 0", got)
+	}
+}
+
+func TestStartQueuedItems_SkipsRepoAtLimit(t *testing.T) {
+	d, action := mutexTestDaemon(t, "")
+	d.repoWorkflowFiles = map[string]string{"/test/repo": "", "/other/repo": ""}
+	d.repoMaxConcurrent = map[string]int{"/test/repo": 1}
+	d.workflowConfigs["/other/repo"] = d.workflowConfigs["/test/repo"]
+	d.engines["/other/repo"] = d.engines["/test/repo"]
+
+	// /test/repo already runs a session.
+	addMutexItem(d, "running", "/test/repo", "async_pending")
+	d.state.UpdateWorkItem("running", func(it *daemonstate.WorkItem) { it.CurrentStep = "coding" })
+
+	for _, q := range []struct{ id, repo string }{{"a-queued", "/test/repo"}, {"b-queued", "/other/repo"}} {
+		d.state.AddWorkItem(&daemonstate.WorkItem{
+			ID:       q.id,
+			IssueRef: config.IssueRef{Source: "github", ID: q.id},
+			StepData: map[string]any{"_repo_path": q.repo},
+		})
+	}
+
+	d.startQueuedItems(context.Background())
+
+	if item, _ := d.state.GetWorkItem("a-queued"); item.State != daemonstate.WorkItemQueued {
+		t.Errorf("expected item in the full repo to stay queued, got %s", item.State)
+	}
+	if item, _ := d.state.GetWorkItem("b-queued"); item.State == daemonstate.WorkItemQueued {
+		t.Error("expected item in the other repo to start")
+	}
+	if action.runs != 1 {
+		t.Errorf("expected one step run, got %d", action.runs)
+	}
+}
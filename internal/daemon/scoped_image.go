@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// ScopedImageBuilder builds, or finds already built or prebuilt in reg, the
+// session image for just the given components of a monorepo (directories
+// relative to repoPath). An empty image means the repo's full image is used.
+type ScopedImageBuilder func(ctx context.Context, repoPath string, dirs []string, reg *workflow.ImageRegistryConfig) (string, error)
+
+// WithScopedImageBuilder enables monorepo-scoped session images for repos
+// whose workflow sets container.monorepo or container.components.
+func WithScopedImageBuilder(build ScopedImageBuilder) Option {
+	return func(d *Daemon) { d.scopedImageBuilder = build }
+}
+
+var (
+	// issueURLRe matches URLs, whose paths are not repo paths.
+	issueURLRe = regexp.MustCompile(`[a-z][a-z0-9+.-]*://\S+`)
+	// issuePathRe matches repo paths mentioned in an issue: anything with a
+	// slash, such as web/src/App.tsx or services/api/.
+	issuePathRe = regexp.MustCompile(`[A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)*/[A-Za-z0-9_.-]*`)
+)
+
+// applyScopedImage runs a containerized session of a monorepo in an image
+// with only the toolchains of the components its issue mentions, instead
+// of the repo's full image that configureRunner set. The full image stays
+// when the issue mentions no component or the scoped build fails. It returns
+// the scoped image, or "" when the full image stays.
+func (d *Daemon) applyScopedImage(ctx context.Context, runner claude.RunnerConfig, sess *config.Session, item daemonstate.WorkItem) string {
+	if !sess.Containerized || d.scopedImageBuilder == nil {
+		return ""
+	}
+	wfCfg, ok := d.lookupWorkflowConfig(sess.RepoPath)
+	// Scoped images are built for the detected languages only, without the
+	// CUDA base GPU sessions need.
+	if !ok || !wfCfg.Container.Scoped() || wfCfg.Container.GPUDevices() != "" {
+		return ""
+	}
+	body, _ := item.StepData["issue_body"].(string)
+	dirs := touchedComponents(sess.RepoPath, wfCfg.Container, item.IssueRef.Title+"\n"+body)
+	if len(dirs) == 0 {
+		return ""
+	}
+
+	log := d.logger.With("workItem", item.ID, "components", dirs)
+	image, err := d.scopedImageBuilder(ctx, sess.RepoPath, dirs, wfCfg.ImageRegistry())
+	if err != nil {
+		log.Warn("failed to build scoped container image, using the repo's full image", "error", err)
+		return ""
+	}
+	if image == "" {
+		return ""
+	}
+	runner.SetContainerized(true, image)
+	log.Info("using container image scoped to components", "image", image)
+	return image
+}
+
+// touchedComponents returns the sorted component directories of the repo
+// paths mentioned in text: the configured components they match, or with
+// none configured, the nearest directory above each with a language marker.
+func touchedComponents(repoPath string, c *workflow.ContainerConfig, text string) []string {
+	var dirs []string
+	for _, p := range issuePathRe.FindAllString(issueURLRe.ReplaceAllString(text, " "), -1) {
+		p = strings.TrimPrefix(path.Clean(strings.TrimPrefix(p, "./")), "/")
+		var dir string
+		var ok bool
+		if len(c.Components) > 0 {
+			dir, ok = c.ComponentFor(p)
+		} else {
+			dir, ok = container.ComponentDir(repoPath, p)
+		}
+		if ok && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
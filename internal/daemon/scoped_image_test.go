@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestTouchedComponents_Configured(t *testing.T) {
+	c := &workflow.ContainerConfig{Components: []workflow.ComponentConfig{
+		{Path: "web"},
+		{Path: "services/api", Match: []string{"services/api/**", "proto/**"}},
+	}}
+	text := "Fix the login form in `web/src/Login.tsx` and the `proto/auth.proto` field.\n" +
+		"See https://github.com/acme/web/issues/1 and docs/auth.md."
+	got := touchedComponents("/unused", c, text)
+	if want := []string{"services/api", "web"}; !slices.Equal(got, want) {
+		t.Errorf("touchedComponents() = %v, want %v", got, want)
+	}
+}
+
+func TestTouchedComponents_Detected(t *testing.T) {
+	repo := t.TempDir()
+	for _, f := range []string{"go.mod", "web/package.json", "services/api/go.mod"} {
+		path := filepath.Join(repo, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &workflow.ContainerConfig{Monorepo: true}
+
+	got := touchedComponents(repo, c, "Add a health endpoint under ./services/api/internal/health.go")
+	if want := []string{"services/api"}; !slices.Equal(got, want) {
+		t.Errorf("touchedComponents() = %v, want %v", got, want)
+	}
+	if got := touchedComponents(repo, c, "Bump the root module and/or tidy main.go"); len(got) != 0 {
+		t.Errorf("expected no components, got %v", got)
+	}
+}
+
+func TestApplyScopedImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		buildErr error
+		want     string
+		wantDirs []string
+	}{
+		{name: "component mentioned", body: "Style web/src/App.css", want: "erg:web", wantDirs: []string{"web"}},
+		{name: "nothing mentioned", body: "Make it faster", want: "erg:full"},
+		{name: "build failure keeps full image", body: "Style web/src/App.css", buildErr: errors.New("boom"), want: "erg:full", wantDirs: []string{"web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDaemon(testConfig())
+			d.workflowConfigs["/test/repo"] = &workflow.Config{
+				Container: &workflow.ContainerConfig{Components: []workflow.ComponentConfig{{Path: "web"}}},
+				Settings:  &workflow.SettingsConfig{ImageRegistry: &workflow.ImageRegistryConfig{Repository: "ghcr.io/acme/erg-base"}},
+			}
+			var gotDirs []string
+			var gotReg *workflow.ImageRegistryConfig
+			WithScopedImageBuilder(func(_ context.Context, repoPath string, dirs []string, reg *workflow.ImageRegistryConfig) (string, error) {
+				gotDirs = dirs
+				gotReg = reg
+				return "erg:" + dirs[0], tt.buildErr
+			})(d)
+
+			runner := newTrackingRunner("test-session")
+			runner.SetContainerized(true, "erg:full")
+			sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: true}
+			item := daemonstate.WorkItem{ID: "item-1", StepData: map[string]any{"issue_body": tt.body}}
+
+			d.applyScopedImage(context.Background(), runner, sess, item)
+
+			if runner.containerImage != tt.want {
+				t.Errorf("image = %q, want %q", runner.containerImage, tt.want)
+			}
+			if !slices.Equal(gotDirs, tt.wantDirs) {
+				t.Errorf("built for %v, want %v", gotDirs, tt.wantDirs)
+			}
+			if gotDirs != nil && (gotReg == nil || gotReg.Repository != "ghcr.io/acme/erg-base") {
+				t.Errorf("expected the repo's image registry passed to the builder, got %+v", gotReg)
+			}
+		})
+	}
+}
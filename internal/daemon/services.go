@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// applyServices starts the sidecar services of the workflow the item runs
+// on for a containerized session, if not already running, and puts the
+// session container on their network. States with a restricted network
+// profile stay on the profile's network and can't reach the services. A
+// failed start is logged and the session runs without them.
+func (d *Daemon) applyServices(ctx context.Context, runner claude.RunnerConfig, sess *config.Session, item daemonstate.WorkItem) {
+	if !sess.Containerized {
+		return
+	}
+	wfCfg := d.getItemWorkflowConfig(sess.RepoPath, item)
+	if len(wfCfg.Services) == 0 {
+		return
+	}
+	log := d.logger.With("sessionID", sess.ID, "state", item.CurrentStep)
+	if network := wfCfg.ContainerNetwork(item.CurrentStep); network != "" {
+		log.Debug("restricted network profile, session services unreachable", "network", network)
+		return
+	}
+
+	ensure := d.ensureServices
+	if ensure == nil {
+		ensure = container.EnsureServices
+	}
+	network, err := ensure(ctx, sess.ID, containerServices(wfCfg.Services))
+	if err != nil {
+		log.Warn("failed to start session services, continuing without them", "error", err)
+		return
+	}
+	runner.SetContainerNetwork(network)
+}
+
+// stopSessionServices removes a session's sidecar services, if the workflow
+// its item runs on has any.
+func (d *Daemon) stopSessionServices(ctx context.Context, sess *config.Session) {
+	if !sess.Containerized {
+		return
+	}
+	item, _ := d.state.GetWorkItemBySessionID(sess.ID)
+	if len(d.getItemWorkflowConfig(sess.RepoPath, item).Services) == 0 {
+		return
+	}
+	stop := d.stopServices
+	if stop == nil {
+		stop = container.StopServices
+	}
+	if err := stop(ctx, sess.ID); err != nil {
+		d.logger.Warn("failed to remove session services", "sessionID", sess.ID, "error", err)
+	}
+}
+
+// containerServices converts a workflow's services for the container
+// package.
+func containerServices(services []workflow.ServiceConfig) []container.Service {
+	out := make([]container.Service, 0, len(services))
+	for _, s := range services {
+		svc := container.Service{Name: s.Name, Image: s.Image}
+		for _, k := range slices.Sorted(maps.Keys(s.Env)) {
+			svc.Env = append(svc.Env, k+"="+s.Env[k])
+		}
+		if h := s.Healthcheck; h != nil {
+			svc.HealthCmd = h.Command
+			svc.HealthInterval = h.IntervalOrDefault()
+			svc.HealthTimeout = h.TimeoutOrDefault()
+		}
+		out = append(out, svc)
+	}
+	return out
+}
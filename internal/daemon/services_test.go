@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/container"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestApplyServices(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		ensureErr   error
+		wantStarted bool
+		wantNetwork string
+	}{
+		{name: "started", wantStarted: true, wantNetwork: "erg-svc-sess-1"},
+		{name: "start failure", ensureErr: errors.New("boom"), wantStarted: true},
+		{name: "restricted profile", profile: workflow.NetworkOffline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDaemon(testConfig())
+			wfCfg := workflow.DefaultWorkflowConfig()
+			wfCfg.Services = []workflow.ServiceConfig{{
+				Name: "postgres", Image: "postgres:16",
+				Env:         map[string]string{"POSTGRES_USER": "erg", "POSTGRES_PASSWORD": "test"},
+				Healthcheck: &workflow.HealthcheckConfig{Command: "pg_isready"},
+			}}
+			if tt.profile != "" {
+				if wfCfg.Settings == nil {
+					wfCfg.Settings = &workflow.SettingsConfig{}
+				}
+				wfCfg.Settings.Network = tt.profile
+				wfCfg.Settings.NetworkProfiles = map[string]string{tt.profile: "erg-offline"}
+			}
+			d.workflowConfigs["/test/repo"] = wfCfg
+
+			var got []container.Service
+			d.ensureServices = func(_ context.Context, sessionID string, services []container.Service) (string, error) {
+				got = services
+				return "erg-svc-" + sessionID, tt.ensureErr
+			}
+			sess := testSession("sess-1")
+			runner := claude.NewMockRunner(sess.ID, false, nil)
+
+			d.applyServices(context.Background(), runner, sess, daemonstate.WorkItem{CurrentStep: "coding"})
+
+			if (got != nil) != tt.wantStarted {
+				t.Fatalf("services started = %v, want %v", got != nil, tt.wantStarted)
+			}
+			if got != nil {
+				if !slices.Equal(got[0].Env, []string{"POSTGRES_PASSWORD=test", "POSTGRES_USER=erg"}) || got[0].HealthCmd != "pg_isready" ||
+					got[0].HealthTimeout != workflow.DefaultHealthcheckTimeout {
+					t.Errorf("unexpected service: %+v", got[0])
+				}
+			}
+			if n := runner.GetContainerNetwork(); n != tt.wantNetwork {
+				t.Errorf("network = %q, want %q", n, tt.wantNetwork)
+			}
+		})
+	}
+}
+
+func TestCleanupSession_StopsServices(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	d.workflowConfigs["/test/repo"].Services = []workflow.ServiceConfig{{Name: "redis", Image: "redis:7"}}
+	var stopped []string
+	d.stopServices = func(_ context.Context, sessionID string) error {
+		stopped = append(stopped, sessionID)
+		return nil
+	}
+	sess := testSession("sess-svc")
+	cfg.AddSession(*sess)
+
+	d.cleanupSession(context.Background(), "sess-svc")
+
+	if !slices.Equal(stopped, []string{"sess-svc"}) {
+		t.Errorf("stopped services of %v, want [sess-svc]", stopped)
+	}
+}
+
+func TestServices_UseItemWorkflow(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	// Only the named workflow runs services.
+	addNamedWorkflow(t, d, "/test/repo", "db", &workflow.Config{
+		States:   map[string]*workflow.State{"coding": {Type: workflow.StateTypeTask, Action: "ai.code"}},
+		Services: []workflow.ServiceConfig{{Name: "postgres", Image: "postgres:16"}},
+	})
+	var started, stopped []string
+	d.ensureServices = func(_ context.Context, sessionID string, _ []container.Service) (string, error) {
+		started = append(started, sessionID)
+		return "erg-svc-" + sessionID, nil
+	}
+	d.stopServices = func(_ context.Context, sessionID string) error {
+		stopped = append(stopped, sessionID)
+		return nil
+	}
+	sess := testSession("sess-db")
+	cfg.AddSession(*sess)
+	item := daemonstate.WorkItem{ID: "item-db", SessionID: sess.ID, CurrentStep: "coding", Workflow: "db"}
+	d.state.AddWorkItem(&item)
+
+	d.applyServices(context.Background(), claude.NewMockRunner(sess.ID, false, nil), sess, item)
+	d.cleanupSession(context.Background(), sess.ID)
+
+	if !slices.Equal(started, []string{"sess-db"}) || !slices.Equal(stopped, []string{"sess-db"}) {
+		t.Errorf("started %v and stopped %v, want the named workflow's services for sess-db", started, stopped)
+	}
+}
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -39,8 +40,8 @@ func (d *Daemon) StopSession(itemID string) error {
 	return nil
 }
 
-// RetryWorkItem resets a failed or completed work item back to queued state so
-// the daemon picks it up on the next polling tick.
+// RetryWorkItem resets a failed, completed, or quarantined work item back to
+// queued state so the daemon picks it up on the next polling tick.
 // Returns an error if the item is currently active (would cause duplicate workers).
 func (d *Daemon) RetryWorkItem(itemID string) error {
 	item, ok := d.state.GetWorkItem(itemID)
@@ -52,29 +53,17 @@ func (d *Daemon) RetryWorkItem(itemID string) error {
 		// Already queued — no-op.
 		return nil
 	case daemonstate.WorkItemActive:
-		return fmt.Errorf("work item is still active, stop it first: %s", itemID)
+		// Quarantined items have no running worker and may be retried early.
+		if item.Phase != phaseQuarantined {
+			return fmt.Errorf("work item is still active, stop it first: %s", itemID)
+		}
+	}
+	if d.state.IsDeadLettered(item.IssueRef.Source, item.IssueRef.ID) {
+		return fmt.Errorf("issue %s is in the dead-letter queue, release it with `erg dlq retry`", item.IssueRef.ID)
 	}
 
 	// Reset to queued so the daemon re-processes it on the next tick.
-	now := time.Now()
-	d.state.UpdateWorkItem(itemID, func(it *daemonstate.WorkItem) {
-		it.State = daemonstate.WorkItemQueued
-		it.CurrentStep = ""
-		it.Phase = ""
-		it.ErrorMessage = ""
-		it.CompletedAt = nil
-		it.UpdatedAt = now
-		// Clear session-related fields so the retried item starts a fresh session
-		// and does not show stale session IDs/logs while queued.
-		it.SessionID = ""
-		it.Branch = ""
-		it.PRURL = ""
-		it.StepEnteredAt = time.Time{}
-		// Reset per-session spend so costs don't accumulate across retries.
-		it.CostUSD = 0
-		it.InputTokens = 0
-		it.OutputTokens = 0
-	})
+	d.requeueWorkItem(context.Background(), item)
 	d.saveState()
 	repo := ""
 	if item.SessionID != "" {
@@ -88,6 +77,7 @@ func (d *Daemon) RetryWorkItem(itemID string) error {
 		}
 	}
 	d.logger.Info("work item retried by human", "event", "human.retry", "workItem", itemID, "repo", repo)
+	d.audit(itemID, daemonstate.AuditOperator, "retried by an operator", nil)
 	return nil
 }
 
@@ -118,3 +108,42 @@ func (d *Daemon) SendMessage(itemID, message string) error {
 	d.logger.Info("message sent to session", "event", "human.message", "workItem", itemID, "repo", repo)
 	return nil
 }
+
+// requeueWorkItem returns an item to the queue to start over with a fresh
+// session, first cleaning up the session it had: its worktree, container,
+// sidecar services and pending messages. The item's worker must already
+// have stopped.
+func (d *Daemon) requeueWorkItem(ctx context.Context, item daemonstate.WorkItem) {
+	if item.SessionID != "" {
+		d.cleanupSession(ctx, item.SessionID)
+	}
+	now := time.Now()
+	d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+		resetForRetry(it, now)
+	})
+}
+
+// resetForRetry returns a work item to the queue so it starts over with a
+// fresh session.
+func resetForRetry(it *daemonstate.WorkItem, now time.Time) {
+	it.State = daemonstate.WorkItemQueued
+	it.CurrentStep = ""
+	it.Phase = ""
+	it.ErrorMessage = ""
+	it.CompletedAt = nil
+	it.UpdatedAt = now
+	// Clear session-related fields so the retried item starts a fresh session
+	// and does not show stale session IDs/logs while queued.
+	it.SessionID = ""
+	it.Branch = ""
+	it.PRURL = ""
+	it.StepEnteredAt = time.Time{}
+	// Reset per-session spend so costs don't accumulate across retries.
+	it.CostUSD = 0
+	it.InputTokens = 0
+	it.OutputTokens = 0
+	it.ContainerCPUSeconds = 0
+	it.PeakMemoryBytes = 0
+	it.DiskReadBytes = 0
+	it.DiskWriteBytes = 0
+}
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// announceStall tells the issue that the item's wait state timed out and the
+// item is parked until a human resumes it.
+func (d *Daemon) announceStall(ctx context.Context, item daemonstate.WorkItem) {
+	log := d.logger.With("workItem", item.ID, "step", item.CurrentStep)
+	log.Info("wait state timed out, item stalled", "event", "wait.stalled")
+
+	msg := fmt.Sprintf("erg stopped waiting at step `%s` after its timeout and has parked this work.\n\n"+
+		"Run `erg resume %s` to wait again.", item.CurrentStep, item.IssueRef.ID)
+	if ok, err := d.postMarkedComment(ctx, item, "stalled-"+item.CurrentStep, msg); err != nil {
+		log.Warn("failed to post stall notice (non-fatal)", "error", err)
+	} else if !ok {
+		log.Debug("stall comments not supported for source", "source", item.IssueRef.Source)
+	}
+}
+
+// processResumeRequests restarts stalled items that `erg resume` asked for.
+// The item waits again at the same step with its timeout and escalation
+// starting over. Requests for items that are no longer stalled are dropped.
+func (d *Daemon) processResumeRequests() {
+	for _, r := range daemonstate.TakeResumeRequests(d.stateKey()) {
+		item, ok := d.state.GetWorkItem(r.WorkItemID)
+		if !ok || item.IsTerminal() || item.Phase != workflow.PhaseStalled {
+			d.logger.Debug("ignoring resume request for item that is not stalled", "workItem", r.WorkItemID)
+			continue
+		}
+		now := time.Now()
+		d.state.UpdateWorkItem(item.ID, func(it *daemonstate.WorkItem) {
+			delete(it.StepData, "_escalation_entered")
+			delete(it.StepData, "_escalation_level")
+			it.Phase = "idle"
+			it.StepEnteredAt = now
+			it.UpdatedAt = now
+		})
+		d.logger.Info("stalled work item resumed by human", "event", "human.resume",
+			"workItem", item.ID, "step", item.CurrentStep)
+	}
+}
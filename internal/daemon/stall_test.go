@@ -0,0 +1,130 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// stallTestDaemon returns a daemon with an item that entered a review wait
+// the given time ago. The wait pings after an hour and stalls after three.
+func stallTestDaemon(t *testing.T, waited time.Duration) (*Daemon, *issues.FakeProvider, *countingAction) {
+	t.Helper()
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	d.repoFilter = "/test/repo"
+	prov := issues.NewFakeProvider(issues.SourceLinear)
+	d.issueRegistry = issues.NewProviderRegistry(prov)
+
+	wfCfg := &workflow.Config{
+		Start:  "await_review",
+		Source: workflow.SourceConfig{Provider: "linear"},
+		States: map[string]*workflow.State{
+			"await_review": {
+				Type:       workflow.StateTypeWait,
+				Event:      "pr.reviewed",
+				Next:       "done",
+				Timeout:    &workflow.Duration{Duration: 3 * time.Hour},
+				OnTimeout:  workflow.TimeoutStall,
+				Escalation: []workflow.EscalationConfig{{After: workflow.Duration{Duration: time.Hour}, Action: "github.comment_pr"}},
+			},
+			"done": {Type: workflow.StateTypeSucceed},
+		},
+	}
+	ping := &countingAction{}
+	reg := d.buildActionRegistry()
+	reg.Register("github.comment_pr", ping)
+	d.workflowConfigs["/test/repo"] = wfCfg
+	d.engines["/test/repo"] = workflow.NewEngine(wfCfg, reg, &dataCapturingEventChecker{}, d.logger)
+
+	cfg.AddSession(*testSession("sess-1"))
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-1",
+		IssueRef:    config.IssueRef{Source: "linear", ID: "ENG-1"},
+		SessionID:   "sess-1",
+		CurrentStep: "await_review",
+		Phase:       "idle",
+		StepData:    map[string]any{},
+	})
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+		it.State = daemonstate.WorkItemActive
+		it.StepEnteredAt = time.Now().Add(-waited)
+	})
+	d.lastReviewPollAt = time.Time{}
+	return d, prov, ping
+}
+
+func TestProcessWaitItems_RecordsEscalation(t *testing.T) {
+	d, _, ping := stallTestDaemon(t, 90*time.Minute)
+
+	d.processWaitItems(context.Background())
+	d.lastReviewPollAt = time.Time{}
+	d.processWaitItems(context.Background())
+
+	if ping.runs != 1 {
+		t.Errorf("escalation ran %d times, want 1", ping.runs)
+	}
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.Phase != "idle" || item.StepData["_escalation_level"] != 1 {
+		t.Errorf("expected item still waiting with level 1 recorded, got phase %q data %v", item.Phase, item.StepData)
+	}
+}
+
+func TestProcessWaitItems_StallsAndResumes(t *testing.T) {
+	d, prov, _ := stallTestDaemon(t, 4*time.Hour)
+
+	d.processWaitItems(context.Background())
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if item.Phase != workflow.PhaseStalled || item.CurrentStep != "await_review" || item.IsTerminal() {
+		t.Fatalf("expected stalled at await_review, got %q/%q state %q", item.CurrentStep, item.Phase, item.State)
+	}
+	if item.ConsumesSlot() {
+		t.Error("a stalled item must not hold a slot")
+	}
+	if len(prov.CommentCalls) != 1 || !strings.Contains(prov.CommentCalls[0].Args[0], "erg resume ENG-1") {
+		t.Errorf("expected one stall notice, got %+v", prov.CommentCalls)
+	}
+
+	// Stalled items are left alone until resumed.
+	d.lastReviewPollAt = time.Time{}
+	d.processWaitItems(context.Background())
+	if len(prov.CommentCalls) != 1 {
+		t.Errorf("expected no repeat notice, got %d comments", len(prov.CommentCalls))
+	}
+
+	if err := daemonstate.WriteResumeRequest(d.stateKey(), daemonstate.ResumeRequest{WorkItemID: "item-1", At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	d.processResumeRequests()
+
+	item, _ = d.state.GetWorkItem("item-1")
+	if item.Phase != "idle" || time.Since(item.StepEnteredAt) > time.Minute {
+		t.Errorf("expected resumed item waiting afresh, got phase %q entered %v", item.Phase, item.StepEnteredAt)
+	}
+	if _, ok := item.StepData["_escalation_level"]; ok {
+		t.Errorf("expected escalation progress cleared, got %v", item.StepData)
+	}
+}
+
+func TestProcessResumeRequests_IgnoresItemsNotStalled(t *testing.T) {
+	d, _, _ := stallTestDaemon(t, time.Minute)
+	entered := time.Now().Add(-time.Minute)
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) { it.StepEnteredAt = entered })
+
+	if err := daemonstate.WriteResumeRequest(d.stateKey(), daemonstate.ResumeRequest{WorkItemID: "item-1", At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	d.processResumeRequests()
+
+	item, _ := d.state.GetWorkItem("item-1")
+	if !item.StepEnteredAt.Equal(entered) {
+		t.Error("resume request for a waiting item must not restart its wait")
+	}
+}
@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"strings"
+
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// standardsDirective introduces the coding standards appended to session
+// system prompts.
+const standardsDirective = `
+
+CODING STANDARDS:
+The standards below are required for every change to this repository. Follow
+them even where the issue or existing code does otherwise, and call out in your
+result any the task forced you to break.`
+
+// withStandards appends the global and repo coding standards to a session
+// system prompt. The repo's document comes last so its rules read as the
+// more specific. The prompt is returned unchanged when neither exists.
+func (d *Daemon) withStandards(repoPath, prompt string) string {
+	globalPath, err := paths.StandardsPath()
+	if err != nil {
+		globalPath = ""
+	}
+	s, err := workflow.LoadStandards(globalPath, repoPath)
+	if err != nil {
+		d.logger.Warn("failed to load coding standards", "repo", repoPath, "error", err)
+		return prompt
+	}
+	if s.Empty() {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString(standardsDirective)
+	if s.Global != "" {
+		b.WriteString("\n\nOrganization standards:\n")
+		b.WriteString(s.Global)
+	}
+	if s.Repo != "" {
+		b.WriteString("\n\nRepository standards:\n")
+		b.WriteString(s.Repo)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestWithStandards(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+	d := testDaemon(testConfig())
+	repo := t.TempDir()
+
+	if got := d.withStandards(repo, "prompt"); got != "prompt" {
+		t.Errorf("expected the prompt unchanged without standards, got %q", got)
+	}
+
+	globalPath, err := paths.StandardsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(globalPath), 0o755)
+	os.WriteFile(globalPath, []byte("- No panics in library code."), 0o644)
+	os.MkdirAll(filepath.Join(repo, ".erg"), 0o755)
+	os.WriteFile(workflow.RepoStandardsPath(repo), []byte("- Use the store package for SQL."), 0o644)
+
+	got := d.withStandards(repo, "prompt")
+	if !strings.HasPrefix(got, "prompt\n\nCODING STANDARDS:") {
+		t.Fatalf("expected the standards directive appended, got %q", got)
+	}
+	global := strings.Index(got, "Organization standards:\n- No panics in library code.")
+	local := strings.Index(got, "Repository standards:\n- Use the store package for SQL.")
+	if global < 0 || local < global {
+		t.Errorf("expected the global then the repo standards, got %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// subtaskParentKey is the StepData key holding the parent issue ID of a work
+// item created by expanding a parent into its sub-tasks.
+const subtaskParentKey = "_subtask_parent"
+
+// subtasksDoneStep is the comment marker step for the completion report on
+// an expanded parent.
+const subtasksDoneStep = "subtasks-done"
+
+// expandSubtasks replaces each fetched issue that has open sub-tasks with
+// those sub-tasks, so every sub-task gets its own session. Issues without
+// sub-tasks are kept. A parent whose sub-tasks cannot be listed is left out
+// of this poll rather than worked as a single item.
+func (d *Daemon) expandSubtasks(ctx context.Context, repoPath string, wfCfg *workflow.Config, fetched []issues.Issue) []issues.Issue {
+	fetcher, ok := d.issueRegistry.GetProvider(issues.Source(wfCfg.Source.Provider)).(issues.ProviderSubtaskFetcher)
+	if !ok {
+		return fetched
+	}
+
+	var expanded []issues.Issue
+	for _, issue := range fetched {
+		subtasks, err := fetcher.FetchSubtasks(ctx, repoPath, issue.ID)
+		if err != nil {
+			d.logger.Debug("failed to fetch sub-tasks, skipping parent", "issue", issue.ID, "error", err)
+			continue
+		}
+		if len(subtasks) == 0 {
+			expanded = append(expanded, issue)
+			continue
+		}
+		expanded = append(expanded, subtasks...)
+	}
+
+	// A sub-task matching the filter itself is also fetched on its own; keep
+	// only the copy that knows its parent.
+	subtaskIDs := make(map[string]bool)
+	for _, issue := range expanded {
+		if issue.Parent != "" {
+			subtaskIDs[issue.ID] = true
+		}
+	}
+	return slices.DeleteFunc(expanded, func(issue issues.Issue) bool {
+		return issue.Parent == "" && subtaskIDs[issue.ID]
+	})
+}
+
+// reportSubtaskParents comments on each expanded parent once every one of its
+// sub-tasks has a completed work item. The comment carries the unqueued
+// marker, so the finished parent is not picked up as an issue of its own.
+//
+// This is best-effort: failures are logged and retried on the next tick.
+func (d *Daemon) reportSubtaskParents(ctx context.Context) {
+	groups := make(map[epicKey][]daemonstate.WorkItem)
+	for _, item := range d.state.GetAllWorkItems() {
+		parent, _ := item.StepData[subtaskParentKey].(string)
+		if parent == "" {
+			continue
+		}
+		key := epicKey{repoPath: d.resolveRepoPath(ctx, item), source: item.IssueRef.Source, epic: parent}
+		groups[key] = append(groups[key], item)
+	}
+
+	for key, children := range groups {
+		if d.subtaskParentsReported[key] {
+			continue
+		}
+		if !d.subtasksAllMerged(ctx, key, children) {
+			continue
+		}
+
+		log := d.logger.With("parent", key.epic, "repo", key.repoPath)
+		parentItem := daemonstate.WorkItem{
+			ID:       fmt.Sprintf("%s-parent-%s", key.repoPath, key.epic),
+			IssueRef: config.IssueRef{Source: key.source, ID: key.epic},
+			StepData: map[string]any{"_repo_path": key.repoPath},
+		}
+		body := issues.FormatUnqueuedCommentWithSuffix(issues.Source(key.source), formatSubtasksDone(children), "success")
+		ok, err := d.postMarkedComment(ctx, parentItem, subtasksDoneStep, body)
+		if !ok {
+			log.Debug("sub-task reports not supported for source", "source", key.source)
+			continue
+		}
+		if err != nil {
+			log.Warn("failed to report sub-task completion (non-fatal)", "error", err)
+			continue
+		}
+		log.Info("reported sub-task completion on parent", "subtasks", len(children))
+
+		if d.subtaskParentsReported == nil {
+			d.subtaskParentsReported = make(map[epicKey]bool)
+		}
+		d.subtaskParentsReported[key] = true
+	}
+}
+
+// subtasksAllMerged reports whether every child work item has completed and
+// the tracker lists no open sub-task of the parent without a completed item
+// (sub-tasks added after the parent was expanded keep it open).
+func (d *Daemon) subtasksAllMerged(ctx context.Context, key epicKey, children []daemonstate.WorkItem) bool {
+	for _, child := range children {
+		if child.State != daemonstate.WorkItemCompleted {
+			return false
+		}
+	}
+
+	fetcher, ok := d.issueRegistry.GetProvider(issues.Source(key.source)).(issues.ProviderSubtaskFetcher)
+	if !ok {
+		return true
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer cancel()
+	open, err := fetcher.FetchSubtasks(fetchCtx, key.repoPath, key.epic)
+	if err != nil {
+		d.logger.Debug("failed to fetch sub-tasks for parent", "parent", key.epic, "error", err)
+		return false
+	}
+	for _, subtask := range open {
+		if !slices.ContainsFunc(children, func(c daemonstate.WorkItem) bool { return c.IssueRef.ID == subtask.ID }) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatSubtasksDone renders the completion report for an expanded parent.
+func formatSubtasksDone(children []daemonstate.WorkItem) string {
+	children = slices.Clone(children)
+	slices.SortFunc(children, func(a, b daemonstate.WorkItem) int { return strings.Compare(a.IssueRef.ID, b.IssueRef.ID) })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "All %d sub-tasks of this issue have merged.\n", len(children))
+	for _, child := range children {
+		fmt.Fprintf(&sb, "\n- %s %s", epicIssueLabel(child.IssueRef), child.IssueRef.Title)
+		if child.PRURL != "" {
+			fmt.Fprintf(&sb, " (%s)", child.PRURL)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/issues"
+)
+
+// subtaskTestDaemon returns a daemon polling a fake Linear team with
+// sub-task expansion on. ENG-1 is a parent of ENG-2 and ENG-3; ENG-4 has no
+// sub-tasks.
+func subtaskTestDaemon(t *testing.T) (*Daemon, *issues.FakeProvider) {
+	t.Helper()
+	d, prov := offlineTestDaemon(t)
+	d.workflowConfigs["/test/repo"].Source.Filter.ExpandSubtasks = true
+	prov.SetIssues([]issues.Issue{
+		{ID: "ENG-1", Title: "Parent", Source: issues.SourceLinear},
+		{ID: "ENG-4", Title: "Standalone", Source: issues.SourceLinear},
+	})
+	prov.SetSubtasks("ENG-1", []issues.Issue{
+		{ID: "ENG-2", Title: "First part", Source: issues.SourceLinear},
+		{ID: "ENG-3", Title: "Second part", Source: issues.SourceLinear},
+	})
+	return d, prov
+}
+
+func TestPollForNewIssues_ExpandsSubtasks(t *testing.T) {
+	d, _ := subtaskTestDaemon(t)
+
+	d.pollForNewIssues(context.Background())
+
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-1"); ok {
+		t.Error("parent with sub-tasks should not be queued itself")
+	}
+	for _, id := range []string{"ENG-2", "ENG-3"} {
+		item, ok := d.state.GetWorkItem("/test/repo-" + id)
+		if !ok {
+			t.Fatalf("expected sub-task %s queued", id)
+		}
+		if item.IssueRef.Epic != "ENG-1" || item.StepData[subtaskParentKey] != "ENG-1" {
+			t.Errorf("%s: epic=%q parent=%v, want ENG-1", id, item.IssueRef.Epic, item.StepData[subtaskParentKey])
+		}
+	}
+	if _, ok := d.state.GetWorkItem("/test/repo-ENG-4"); !ok {
+		t.Error("expected issue without sub-tasks queued as usual")
+	}
+}
+
+func TestExpandSubtasks_DropsTopLevelDuplicate(t *testing.T) {
+	d, _ := subtaskTestDaemon(t)
+	fetched := []issues.Issue{
+		{ID: "ENG-1", Source: issues.SourceLinear},
+		{ID: "ENG-2", Source: issues.SourceLinear},
+	}
+
+	got := d.expandSubtasks(context.Background(), "/test/repo", d.workflowConfigs["/test/repo"], fetched)
+
+	var ids []string
+	for _, issue := range got {
+		ids = append(ids, issue.ID+"<"+issue.Parent)
+	}
+	if strings.Join(ids, ",") != "ENG-2<ENG-1,ENG-3<ENG-1" {
+		t.Errorf("expanded = %v", ids)
+	}
+}
+
+// queueSubtasks queues ENG-1's sub-tasks as the poller would, without the
+// claim round trip.
+func queueSubtasks(t *testing.T, d *Daemon) {
+	t.Helper()
+	wfCfg := d.workflowConfigs["/test/repo"]
+	fetched, err := d.fetchIssuesForProvider(context.Background(), "/test/repo", wfCfg)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	for _, issue := range fetched {
+		if issue.Parent != "" {
+			d.queueIssue("/test/repo", issue, issues.SourceLinear, false)
+		}
+	}
+}
+
+func TestReportSubtaskParents(t *testing.T) {
+	d, prov := subtaskTestDaemon(t)
+	ctx := context.Background()
+	queueSubtasks(t, d)
+
+	complete := func(id string) {
+		d.state.UpdateWorkItem("/test/repo-"+id, func(it *daemonstate.WorkItem) {
+			it.State = daemonstate.WorkItemCompleted
+			it.PRURL = "https://github.com/owner/repo/pull/" + id
+		})
+	}
+
+	complete("ENG-2")
+	d.reportSubtaskParents(ctx)
+	if len(prov.CommentCalls) != 0 {
+		t.Fatalf("expected no report while ENG-3 is open, got %d comments", len(prov.CommentCalls))
+	}
+
+	complete("ENG-3")
+	d.reportSubtaskParents(ctx)
+	if len(prov.CommentCalls) != 1 {
+		t.Fatalf("expected 1 report, got %d comments", len(prov.CommentCalls))
+	}
+	call := prov.CommentCalls[0]
+	if call.IssueID != "ENG-1" {
+		t.Errorf("reported on %q, want parent ENG-1", call.IssueID)
+	}
+	body := call.Args[0]
+	if !strings.Contains(body, "All 2 sub-tasks") || !strings.Contains(body, "pull/ENG-3") {
+		t.Errorf("unexpected report: %q", body)
+	}
+	if !issues.HasUnqueuedMarker([]issues.IssueComment{{Body: body}}) {
+		t.Error("report must carry the unqueued marker so the parent is not picked up")
+	}
+
+	d.reportSubtaskParents(ctx)
+	if total := len(prov.CommentCalls) + len(prov.UpdateCommentCalls); total != 1 {
+		t.Errorf("expected the report posted once, got %d calls", total)
+	}
+}
+
+func TestReportSubtaskParents_WaitsForNewSubtasks(t *testing.T) {
+	d, prov := subtaskTestDaemon(t)
+	ctx := context.Background()
+	queueSubtasks(t, d)
+	for _, id := range []string{"ENG-2", "ENG-3"} {
+		d.state.UpdateWorkItem("/test/repo-"+id, func(it *daemonstate.WorkItem) {
+			it.State = daemonstate.WorkItemCompleted
+		})
+	}
+
+	// A sub-task added after expansion keeps the parent open.
+	prov.SetSubtasks("ENG-1", []issues.Issue{{ID: "ENG-9", Title: "Late addition"}})
+	d.reportSubtaskParents(ctx)
+
+	if len(prov.CommentCalls) != 0 {
+		t.Errorf("expected no report with an unworked sub-task, got %d comments", len(prov.CommentCalls))
+	}
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// templateData returns what prompt, hook command and PR body templates
+// rendered for item can reference.
+func (d *Daemon) templateData(ctx context.Context, item daemonstate.WorkItem) workflow.TemplateData {
+	repoPath := d.resolveRepoPath(ctx, item)
+	result, _ := workflow.ResultFromStepData(item.StepData)
+	return workflow.TemplateData{
+		Issue: workflow.TemplateIssue{
+			ID:     item.IssueRef.ID,
+			Title:  item.IssueRef.Title,
+			URL:    item.IssueRef.URL,
+			Source: item.IssueRef.Source,
+			Labels: item.IssueRef.Labels,
+		},
+		Repo:        repoPath,
+		Branch:      item.Branch,
+		PRURL:       item.PRURL,
+		WorkItemID:  item.ID,
+		CurrentStep: item.CurrentStep,
+		Step:        item.StepData,
+		Result:      result,
+		Spend: workflow.TemplateSpend{
+			CostUSD:      item.CostUSD,
+			InputTokens:  item.InputTokens,
+			OutputTokens: item.OutputTokens,
+		},
+		Commands: templateCommands(d.projectCommands(repoPath, item)),
+	}
+}
+
+// renderPrompt renders a system prompt template for item. A prompt that
+// fails to render is logged and used with only its {{step.key}}
+// placeholders expanded, so a template mistake never blocks a session.
+func (d *Daemon) renderPrompt(ctx context.Context, item daemonstate.WorkItem, prompt string) string {
+	rendered, err := workflow.RenderTemplate(prompt, d.templateData(ctx, item))
+	if err != nil {
+		d.logger.Warn("failed to render prompt template, using it as written", "workItem", item.ID, "step", item.CurrentStep, "error", err)
+		return workflow.ExpandStepData(prompt, item.StepData)
+	}
+	return rendered
+}
+
+// hookContext returns the environment and template data for hooks run for
+// item in sess.
+func (d *Daemon) hookContext(ctx context.Context, item daemonstate.WorkItem, sess *config.Session) workflow.HookContext {
+	data := d.templateData(ctx, item)
+	return workflow.HookContext{
+		RepoPath:   sess.RepoPath,
+		Branch:     item.Branch,
+		SessionID:  item.SessionID,
+		IssueID:    item.IssueRef.ID,
+		IssueTitle: item.IssueRef.Title,
+		IssueURL:   item.IssueRef.URL,
+		PRURL:      item.PRURL,
+		WorkTree:   sess.WorkTree,
+		Provider:   item.IssueRef.Source,
+		Template:   &data,
+	}
+}
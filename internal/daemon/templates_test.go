@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestTemplateData(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	cfg.AddSession(*testSession("sess-1"))
+
+	item := daemonstate.WorkItem{
+		ID:           "item-1",
+		IssueRef:     config.IssueRef{Source: "github", ID: "7", Title: "Add caching", Labels: []string{"perf"}},
+		SessionID:    "sess-1",
+		Branch:       "erg/issue-7",
+		PRURL:        "https://github.com/o/r/pull/3",
+		CurrentStep:  "coding",
+		StepData: map[string]any{"plan": "cache it", workflow.ResultStepDataKey: workflow.StateResult{
+			Status: workflow.ResultStatusSuccess, Summary: "Cached lookups", RiskLevel: "low", FollowUps: []string{"tune TTL"},
+		}.ToStepData()},
+		CostUSD:      0.5,
+		InputTokens:  10,
+		OutputTokens: 20,
+	}
+
+	data := d.templateData(context.Background(), item)
+	if data.Issue.ID != "7" || data.Issue.Title != "Add caching" || data.Issue.Labels[0] != "perf" {
+		t.Errorf("unexpected issue data: %+v", data.Issue)
+	}
+	if data.Repo != "/test/repo" || data.Branch != "erg/issue-7" || data.PRURL != item.PRURL {
+		t.Errorf("unexpected repo data: %+v", data)
+	}
+	if data.Step["plan"] != "cache it" || data.Spend.CostUSD != 0.5 || data.Spend.OutputTokens != 20 {
+		t.Errorf("unexpected step or spend data: %+v", data)
+	}
+	if data.Result.Summary != "Cached lookups" || data.Result.RiskLevel != "low" || len(data.Result.FollowUps) != 1 {
+		t.Errorf("unexpected result data: %+v", data.Result)
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	cfg := testConfig()
+	d := testDaemon(cfg)
+	cfg.AddSession(*testSession("sess-1"))
+
+	item := daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "7", Title: "Add caching"},
+		SessionID: "sess-1",
+		StepData:  map[string]any{"plan": "cache it"},
+	}
+
+	got := d.renderPrompt(context.Background(), item, `Implement #{{.Issue.ID}} ({{.Issue.Title}}): {{step "plan"}}`)
+	if got != "Implement #7 (Add caching): cache it" {
+		t.Errorf("got %q", got)
+	}
+
+	// A prompt that fails to render still gets its step placeholders expanded.
+	got = d.renderPrompt(context.Background(), item, "{{.Nope}} plan: {{step.plan}}")
+	if got != "{{.Nope}} plan: cache it" {
+		t.Errorf("fallback: got %q", got)
+	}
+}
+
+func TestApplyPRBodyTemplate(t *testing.T) {
+	cfg := testConfig()
+	mockExec := exec.NewMockExecutor(nil)
+	prViewJSON, _ := json.Marshal(struct {
+		Body string `json:"body"`
+	}{Body: "Generated summary"})
+	mockExec.AddPrefixMatch("gh", []string{"pr", "view", "feature-sess-1", "--json", "body"}, exec.MockResponse{Stdout: prViewJSON})
+	mockExec.AddPrefixMatch("gh", []string{"pr", "edit", "feature-sess-1", "--body"}, exec.MockResponse{})
+
+	d := testDaemonWithExec(cfg, mockExec)
+	d.gitService = git.NewGitServiceWithExecutor(mockExec)
+
+	sess := testSession("sess-1")
+	cfg.AddSession(*sess)
+
+	item := daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "7"},
+		SessionID: "sess-1",
+		Branch:    "feature-sess-1",
+		CostUSD:   1.5,
+	}
+
+	err := d.applyPRBodyTemplate(context.Background(), item, sess, "Fixes #{{.Issue.ID}}\n\n{{.Body}}\n\nSpent ${{printf \"%.2f\" .Spend.CostUSD}}")
+	if err != nil {
+		t.Fatalf("applyPRBodyTemplate: %v", err)
+	}
+
+	var body string
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "gh" && len(call.Args) >= 5 && call.Args[0] == "pr" && call.Args[1] == "edit" {
+			body = call.Args[4]
+		}
+	}
+	want := "Fixes #7\n\nGenerated summary\n\nSpent $1.50"
+	if body != want {
+		t.Errorf("PR body: got %q, want %q", body, want)
+	}
+}
@@ -30,6 +30,9 @@ const (
 	// (rebase, squash, cherry-pick, format, merge-base-into-branch).
 	timeoutGitRewrite = 5 * time.Minute
 
+	// timeoutExecRun is the default deadline for exec.run commands.
+	timeoutExecRun = 10 * time.Minute
+
 	// timeoutDockerHealth is for the Docker daemon health check.
 	timeoutDockerHealth = 5 * time.Second
 )
@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/toolpolicy"
+)
+
+// repoToolPolicy returns the tool policy of the repo at repoPath; the zero
+// policy restricts nothing.
+func (d *Daemon) repoToolPolicy(repoPath string) toolpolicy.Policy {
+	wfCfg, ok := d.lookupWorkflowConfig(repoPath)
+	if !ok {
+		return toolpolicy.Policy{}
+	}
+	p := wfCfg.ToolPolicy()
+	if p == nil {
+		return toolpolicy.Policy{}
+	}
+	return toolpolicy.Policy{AllowCommands: p.AllowCommands, DenyCommands: p.DenyCommands, DenyTools: p.DenyTools}
+}
+
+// setSessionTools sets the tools a session's runner may and may not use,
+// with its repo's tool policy in force: the Claude CLI enforces the
+// resulting rules itself, and agent backends check commands against them
+// before running any.
+func (d *Daemon) setSessionTools(runner claude.RunnerConfig, sess *config.Session, allowed, disallowed []string) {
+	policy := d.repoToolPolicy(sess.RepoPath)
+	if !policy.IsZero() {
+		allowed, disallowed = policy.Apply(allowed, disallowed)
+	}
+	runner.SetAllowedTools(allowed)
+	runner.SetDisallowedTools(disallowed)
+}
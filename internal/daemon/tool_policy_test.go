@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestConfigureRunner_AppliesToolPolicy(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.workflowConfigs["/test/repo"] = &workflow.Config{
+		Settings: &workflow.SettingsConfig{ToolPolicy: &workflow.ToolPolicyConfig{
+			AllowCommands: []string{"go test", "make"},
+			DenyCommands:  []string{"curl"},
+			DenyTools:     []string{"WebFetch"},
+		}},
+	}
+	runner := newTrackingRunner("test-session")
+	runner.SetDisallowedTools(claude.ToolSetPlanningDeny)
+	sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: true}
+
+	d.configureRunner(runner, sess, "", nil)
+
+	allowed := runner.GetAllowedTools()
+	for _, tool := range []string{"Bash", "WebFetch"} {
+		if slices.Contains(allowed, tool) {
+			t.Errorf("expected %q not allowed under the policy, got %v", tool, allowed)
+		}
+	}
+	for _, tool := range []string{"Read", "Edit", "Bash(go test:*)", "Bash(make:*)", "WebSearch"} {
+		if !slices.Contains(allowed, tool) {
+			t.Errorf("expected %q allowed, got %v", tool, allowed)
+		}
+	}
+	if got := runner.GetDisallowedTools(); !slices.Equal(got, []string{"WebFetch", "Bash(curl:*)"}) {
+		t.Errorf("disallowed tools = %v, want the policy's only", got)
+	}
+}
+
+func TestConfigureRunner_NoToolPolicy(t *testing.T) {
+	d := testDaemon(testConfig())
+	runner := newTrackingRunner("test-session")
+	sess := &config.Session{ID: "test-session", RepoPath: "/test/repo", Containerized: true}
+
+	d.configureRunner(runner, sess, "", []string{"Read", "Bash"})
+
+	if got := runner.GetAllowedTools(); !slices.Equal(got, []string{"Read", "Bash"}) {
+		t.Errorf("allowed tools = %v", got)
+	}
+	if got := runner.GetDisallowedTools(); len(got) != 0 {
+		t.Errorf("disallowed tools = %v, want none", got)
+	}
+}
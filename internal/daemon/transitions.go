@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/eventbus"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+// watchTransitions publishes an event for every state transition of the
+// daemon's work items and records the compensations of states they enter.
+// It must be called again whenever d.state is replaced.
+func (d *Daemon) watchTransitions() {
+	d.state.SetTransitionHook(func(t daemonstate.Transition) {
+		d.publishTransition(t)
+		d.recordCompensation(t)
+	})
+}
+
+// publishTransition turns a state transition into a bus event. The reason
+// is the kind of edge the previous state took to reach the new one.
+func (d *Daemon) publishTransition(t daemonstate.Transition) {
+	repoPath := d.workItemRepoPath(t.Item)
+	if repoPath == "" {
+		repoPath = d.repoFilter
+	}
+	var from *workflow.State
+	if t.From != "" {
+		if engine := d.getItemEngine(repoPath, t.Item); engine != nil {
+			from = engine.GetState(t.From)
+		}
+		if from == nil {
+			from = &workflow.State{} // unknown state: no edges lead anywhere
+		}
+	}
+	d.events.Publish(eventbus.Event{
+		Type:        eventbus.TypeTransition,
+		Time:        time.Now().UTC(),
+		Repo:        repoPath,
+		WorkItem:    t.Item.ID,
+		IssueSource: t.Item.IssueRef.Source,
+		IssueID:     t.Item.IssueRef.ID,
+		Workflow:    t.Item.Workflow,
+		From:        t.From,
+		To:          t.To,
+		Reason:      workflow.TransitionReason(from, t.To),
+		DurationMS:  t.Duration.Milliseconds(),
+	})
+}
+
+// configureEventSinks points the repo's transition events at the sinks in
+// its workflow settings.
+func (d *Daemon) configureEventSinks(repoPath string, cfg *workflow.Config) {
+	var sinks []eventbus.Sink
+	if cfg.Settings != nil {
+		for _, sc := range cfg.Settings.Events {
+			switch sc.Type {
+			case workflow.EventSinkJSONL:
+				path := sc.Path
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(repoPath, path)
+				}
+				sinks = append(sinks, eventbus.NewFileSink(path))
+			case workflow.EventSinkWebhook:
+				sinks = append(sinks, eventbus.NewWebhookSink(sc.URL, sc.Headers))
+			case workflow.EventSinkStdout:
+				sinks = append(sinks, eventbus.NewWriterSink(os.Stdout))
+			}
+		}
+	}
+	d.events.SetSinks(repoPath, sinks)
+}
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/eventbus"
+	"github.com/zhubert/erg/internal/workflow"
+)
+
+func TestPublishTransition_WritesEvents(t *testing.T) {
+	d, _ := mutexTestDaemon(t, "")
+	eventsPath := filepath.Join(t.TempDir(), "events.jsonl")
+	d.configureEventSinks("/test/repo", &workflow.Config{Settings: &workflow.SettingsConfig{Events: []workflow.EventSinkConfig{
+		{Type: workflow.EventSinkJSONL, Path: eventsPath},
+	}}})
+	d.watchTransitions()
+	d.events.Start()
+
+	sess := testSession("sess-1")
+	d.config.AddSession(*sess)
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:        "item-1",
+		IssueRef:  config.IssueRef{Source: "github", ID: "42"},
+		SessionID: sess.ID,
+	})
+	d.state.AdvanceWorkItem("item-1", "migrate", "idle")
+	d.executeSyncChain(context.Background(), "item-1", d.engines["/test/repo"])
+	d.events.Close()
+
+	data, err := os.ReadFile(eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []eventbus.Event
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var ev eventbus.Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	start, done := events[0], events[1]
+	if start.From != "" || start.To != "migrate" || start.Reason != "start" {
+		t.Errorf("unexpected start event %+v", start)
+	}
+	if done.From != "migrate" || done.To != "done" || done.Reason != "next" {
+		t.Errorf("unexpected done event %+v", done)
+	}
+	if done.Type != eventbus.TypeTransition || done.WorkItem != "item-1" || done.Repo != "/test/repo" || done.IssueID != "42" {
+		t.Errorf("unexpected event fields %+v", done)
+	}
+}
+
+func TestConfigureEventSinks_RelativePath(t *testing.T) {
+	d := testDaemon(testConfig())
+	repoDir := t.TempDir()
+	d.configureEventSinks(repoDir, &workflow.Config{Settings: &workflow.SettingsConfig{Events: []workflow.EventSinkConfig{
+		{Type: workflow.EventSinkJSONL, Path: ".erg/events.jsonl"},
+	}}})
+	d.events.Start()
+	d.events.Publish(eventbus.Event{Repo: repoDir, WorkItem: "1"})
+	d.events.Close()
+
+	if _, err := os.Stat(filepath.Join(repoDir, ".erg", "events.jsonl")); err != nil {
+		t.Errorf("expected events under the repo: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+// Markers delimiting the verification section of a PR body, so it can be
+// replaced when the PR description is rewritten.
+const (
+	verificationStart = "<!-- erg:verification -->"
+	verificationEnd   = "<!-- /erg:verification -->"
+)
+
+// verificationSection renders the verdict a coding session's verification
+// turn left in item's StepData as a PR body section. Returns "" if the
+// session didn't verify its work.
+func verificationSection(item daemonstate.WorkItem) string {
+	passed, ok := item.StepData[worker.VerificationPassedKey].(bool)
+	if !ok {
+		return ""
+	}
+	verdict := "⚠️ The agent could not confirm the acceptance criteria are met."
+	if passed {
+		verdict = "✅ The agent checked its changes against the issue and confirmed the acceptance criteria are met."
+	}
+	var b strings.Builder
+	b.WriteString(verificationStart + "\n## Verification\n\n" + verdict + "\n")
+	if summary, _ := item.StepData[worker.VerificationSummaryKey].(string); summary != "" {
+		b.WriteString("\n" + summary + "\n")
+	}
+	b.WriteString(verificationEnd)
+	return b.String()
+}
+
+// withVerification returns body with its verification section replaced or,
+// if absent, appended, kept ahead of a trailing erg fingerprint footer. An
+// empty section removes it.
+func withVerification(body, section string) string {
+	if start := strings.Index(body, verificationStart); start >= 0 {
+		if end := strings.Index(body[start:], verificationEnd); end >= 0 {
+			body = strings.TrimRight(body[:start], "\n") + body[start+end+len(verificationEnd):]
+		}
+	}
+	if section == "" {
+		return body
+	}
+	footer := ""
+	if i := strings.Index(body, "<!-- erg:fingerprint"); i >= 0 {
+		body, footer = body[:i], "\n\n"+body[i:]
+	}
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return section + footer
+	}
+	return body + "\n\n" + section + footer
+}
+
+// stampPRVerification adds item's verification verdict to the body of its
+// session's PR. It does nothing when the session didn't verify its work.
+func (d *Daemon) stampPRVerification(ctx context.Context, item daemonstate.WorkItem, sess *config.Session) error {
+	section := verificationSection(item)
+	if section == "" {
+		return nil
+	}
+	bodyCtx, cancel := context.WithTimeout(ctx, timeoutQuickAPI)
+	body, err := d.gitService.GetPRBody(bodyCtx, sess.RepoPath, sess.Branch)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to read PR body: %w", err)
+	}
+	updated := withVerification(body, section)
+	if updated == body {
+		return nil
+	}
+	updateCtx, updateCancel := context.WithTimeout(ctx, timeoutStandardOp)
+	defer updateCancel()
+	return d.gitService.UpdatePRBody(updateCtx, sess.RepoPath, sess.Branch, updated)
+}
@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/worker"
+)
+
+func TestVerificationSection(t *testing.T) {
+	if got := verificationSection(daemonstate.WorkItem{StepData: map[string]any{}}); got != "" {
+		t.Errorf("expected no section without a verdict, got %q", got)
+	}
+	got := verificationSection(daemonstate.WorkItem{StepData: map[string]any{
+		worker.VerificationPassedKey:  false,
+		worker.VerificationSummaryKey: "No test for the 503 path.",
+	}})
+	if !strings.Contains(got, "## Verification") || !strings.Contains(got, "could not confirm") || !strings.Contains(got, "No test for the 503 path.") {
+		t.Errorf("unexpected section: %q", got)
+	}
+}
+
+func TestWithVerification(t *testing.T) {
+	section := verificationStart + "\n## Verification\n\nok\n" + verificationEnd
+	footer := "<!-- erg:fingerprint workflow=abc -->"
+
+	got := withVerification("## Summary\nAdds retries.\n\n"+footer, section)
+	want := "## Summary\nAdds retries.\n\n" + section + "\n\n" + footer
+	if got != want {
+		t.Errorf("expected the section ahead of the footer, got %q", got)
+	}
+
+	replaced := withVerification(got, verificationStart+"\nnew\n"+verificationEnd)
+	if strings.Count(replaced, verificationStart) != 1 || !strings.Contains(replaced, "new") || strings.Contains(replaced, "ok\n") {
+		t.Errorf("expected the section replaced, got %q", replaced)
+	}
+	if removed := withVerification(got, ""); strings.Contains(removed, verificationStart) {
+		t.Errorf("expected the section removed, got %q", removed)
+	}
+}
@@ -22,20 +22,9 @@ func (d *Daemon) postWaitGuidance(ctx context.Context, item daemonstate.WorkItem
 
 	log := d.logger.With("workItem", item.ID, "step", stepName, "event", state.Event)
 
-	source := issues.Source(item.IssueRef.Source)
-	var postErr error
-
-	switch source {
-	case issues.SourceGitHub:
-		postErr = d.postGuidanceGitHub(ctx, item, stepName, msg)
-	case issues.SourceAsana:
-		params := workflow.NewParamHelper(map[string]any{"body": msg})
-		postErr = d.commentViaProvider(ctx, item, params, issues.SourceAsana, stepName)
-	case issues.SourceLinear:
-		params := workflow.NewParamHelper(map[string]any{"body": msg})
-		postErr = d.commentViaProvider(ctx, item, params, issues.SourceLinear, stepName)
-	default:
-		log.Debug("guidance posting not supported for source", "source", source)
+	posted, postErr := d.postMarkedComment(ctx, item, stepName, msg)
+	if !posted {
+		log.Debug("guidance posting not supported for source", "source", item.IssueRef.Source)
 		return
 	}
 
@@ -46,6 +35,22 @@ func (d *Daemon) postWaitGuidance(ctx context.Context, item daemonstate.WorkItem
 	}
 }
 
+// postMarkedComment posts msg on the work item's issue with an idempotency
+// marker derived from step, updating an existing comment carrying the same
+// marker in place. Returns false when the issue source does not support
+// comments.
+func (d *Daemon) postMarkedComment(ctx context.Context, item daemonstate.WorkItem, step, msg string) (bool, error) {
+	switch source := issues.Source(item.IssueRef.Source); source {
+	case issues.SourceGitHub:
+		return true, d.postGuidanceGitHub(ctx, item, step, msg)
+	case issues.SourceAsana, issues.SourceLinear, issues.SourceGitLab, issues.SourceClickUp, issues.SourceHTTP:
+		params := workflow.NewParamHelper(map[string]any{"body": msg})
+		return true, d.commentViaProvider(ctx, item, params, source, step)
+	default:
+		return false, nil
+	}
+}
+
 // postGuidanceGitHub posts a guidance comment on a GitHub issue.
 // It tries the provider registry first (if a GitHub provider is registered),
 // then falls back to the git service. The comment is idempotent via step marker.
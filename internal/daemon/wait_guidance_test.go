@@ -32,6 +32,15 @@ func (p *guidanceTestProvider) Comment(_ context.Context, _, _, body string) err
 	p.comments = append(p.comments, body)
 	return nil
 }
+func (p *guidanceTestProvider) Claim(_ context.Context, _, _ string) (bool, error) {
+	return true, nil
+}
+func (p *guidanceTestProvider) Unclaim(_ context.Context, _, _ string) error {
+	return nil
+}
+func (p *guidanceTestProvider) CloseIssue(_ context.Context, _, _ string) error {
+	return nil
+}
 
 func makeGuidanceItem(source, issueID string) daemonstate.WorkItem {
 	return daemonstate.WorkItem{
@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/dashboard"
+)
+
+// watchPollInterval is how often a watch stream checks its work item and
+// session log for changes.
+var watchPollInterval = 250 * time.Millisecond
+
+// defaultWatchBacklog is how many already-written transcript lines a watch
+// starts with when the request does not say.
+const defaultWatchBacklog = 20
+
+// handleControlStream checks a streaming control socket request and
+// returns the stream that carries it out.
+func (d *Daemon) handleControlStream(req control.Request) (control.Response, control.StreamFunc, error) {
+	switch req.Command {
+	case control.CommandWatch:
+		item, err := d.findControlItem(req.Item)
+		if err != nil {
+			return control.Response{}, nil, err
+		}
+		backlog := req.Lines
+		if backlog <= 0 {
+			backlog = defaultWatchBacklog
+		}
+		return d.controlStatus(), d.watchWorkItem(item.ID, backlog), nil
+	default:
+		return control.Response{}, nil, fmt.Errorf("unknown command %q", req.Command)
+	}
+}
+
+// watchWorkItem streams a work item's live session transcript. It starts
+// with the item's status and the last backlog lines its session wrote,
+// then follows the log as the session writes it. Steps that start a new
+// session are followed into it, and the stream ends once the item
+// finishes.
+func (d *Daemon) watchWorkItem(itemID string, backlog int) control.StreamFunc {
+	return func(ctx context.Context, emit control.Emitter) error {
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+
+		var follower *dashboard.LogFollower
+		var last control.Item
+		limit := backlog
+		for {
+			it, ok := d.state.GetWorkItem(itemID)
+			if !ok {
+				return emit(control.EventEnd, last)
+			}
+			item := d.controlItem(it)
+			if item.Session != "" && item.Session != last.Session {
+				// Finish the old session's transcript before switching.
+				if err := emitTranscript(follower, 0, emit); err != nil {
+					return err
+				}
+				f, err := dashboard.NewLogFollower(item.Session)
+				if err != nil {
+					return err
+				}
+				follower = f
+			}
+			if item.State != last.State || item.Step != last.Step || item.Phase != last.Phase || item.Session != last.Session {
+				if err := emit(control.EventStatus, item); err != nil {
+					return err
+				}
+				last = item
+			}
+			if err := emitTranscript(follower, limit, emit); err != nil {
+				return err
+			}
+			limit = 0
+			if it.IsTerminal() {
+				return emit(control.EventEnd, item)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// emitTranscript streams the lines f's session wrote since it was last
+// read, only the last limit of them when limit is positive.
+func emitTranscript(f *dashboard.LogFollower, limit int, emit control.Emitter) error {
+	if f == nil {
+		return nil
+	}
+	lines, err := f.Next()
+	if err != nil {
+		return fmt.Errorf("failed to follow the session log: %w", err)
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	for _, ln := range lines {
+		if err := emit(control.EventLine, control.Line{Type: ln.Type, Text: ln.Text, Name: ln.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/control"
+	"github.com/zhubert/erg/internal/daemonstate"
+	"github.com/zhubert/erg/internal/logger"
+)
+
+// appendStreamLog appends assistant text messages to a session's stream log.
+func appendStreamLog(t *testing.T, sessionID string, texts ...string) {
+	t.Helper()
+	path, err := logger.StreamLogPath(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(path) })
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, text := range texts {
+		fmt.Fprintf(f, `{"type":"assistant","message":{"content":[{"type":"text","text":%q}]}}`+"\n", text)
+	}
+}
+
+// watchedEvent is an event a watch stream emitted, with its data re-encoded.
+type watchedEvent struct {
+	name string
+	data string
+}
+
+func startWatch(t *testing.T, d *Daemon, req control.Request) <-chan watchedEvent {
+	t.Helper()
+	_, stream, err := d.handleControlStream(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan watchedEvent, 64)
+	go func() {
+		defer close(events)
+		stream(t.Context(), func(name string, data any) error {
+			b, _ := json.Marshal(data)
+			events <- watchedEvent{name, string(b)}
+			return nil
+		})
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan watchedEvent) watchedEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("watch stream ended early")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a watch event")
+	}
+	return watchedEvent{}
+}
+
+func TestControlWatch_FollowsSessionsUntilDone(t *testing.T) {
+	watchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { watchPollInterval = 250 * time.Millisecond })
+
+	d := testDaemon(testConfig())
+	d.state.AddWorkItem(&daemonstate.WorkItem{
+		ID:          "item-1",
+		IssueRef:    config.IssueRef{Source: "github", ID: "7"},
+		SessionID:   "watch-sess-1",
+		CurrentStep: "coding",
+		StepData:    map[string]any{"_repo_path": "/test/repo"},
+	})
+	appendStreamLog(t, "watch-sess-1", "old one", "old two", "old three")
+
+	events := startWatch(t, d, control.Request{Command: control.CommandWatch, Item: "#7", Lines: 2})
+	if ev := nextEvent(t, events); ev.name != control.EventStatus {
+		t.Fatalf("first event = %+v, want status", ev)
+	}
+	for _, want := range []string{"old two", "old three"} {
+		ev := nextEvent(t, events)
+		var ln control.Line
+		json.Unmarshal([]byte(ev.data), &ln)
+		if ev.name != control.EventLine || ln.Text != want {
+			t.Fatalf("backlog event = %+v, want line %q", ev, want)
+		}
+	}
+
+	appendStreamLog(t, "watch-sess-1", "live")
+	if ev := nextEvent(t, events); ev.name != control.EventLine || ev.data != `{"type":"text","text":"live"}` {
+		t.Fatalf("live event = %+v", ev)
+	}
+
+	// A step that starts a new session is followed into it, from its start.
+	appendStreamLog(t, "watch-sess-2", "feedback")
+	d.state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) {
+		it.SessionID = "watch-sess-2"
+		it.CurrentStep = "address_review"
+	})
+	ev := nextEvent(t, events)
+	var status control.Item
+	json.Unmarshal([]byte(ev.data), &status)
+	if ev.name != control.EventStatus || status.Session != "watch-sess-2" || status.Step != "address_review" {
+		t.Fatalf("event after session change = %+v", ev)
+	}
+	if ev := nextEvent(t, events); ev.name != control.EventLine || ev.data != `{"type":"text","text":"feedback"}` {
+		t.Fatalf("new session event = %+v", ev)
+	}
+
+	d.state.MarkWorkItemTerminal("item-1", true)
+	if ev := nextEvent(t, events); ev.name != control.EventStatus {
+		t.Fatalf("event after finishing = %+v, want status", ev)
+	}
+	if ev := nextEvent(t, events); ev.name != control.EventEnd {
+		t.Fatalf("final event = %+v, want end", ev)
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the stream to end after the end event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch stream did not end")
+	}
+}
+
+func TestControlWatch_StopsWhenClientLeaves(t *testing.T) {
+	watchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { watchPollInterval = 250 * time.Millisecond })
+
+	d := testDaemon(testConfig())
+	d.state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "github", ID: "7"}})
+	_, stream, err := d.handleControlStream(control.Request{Command: control.CommandWatch, Item: "item-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- stream(ctx, func(string, any) error { return nil }) }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("stream error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+}
+
+func TestControlWatch_UnknownItem(t *testing.T) {
+	d := testDaemon(testConfig())
+	if _, _, err := d.handleControlStream(control.Request{Command: control.CommandWatch, Item: "nope"}); err == nil {
+		t.Error("expected an unknown work item to be rejected")
+	}
+	if _, _, err := d.handleControlStream(control.Request{Command: control.CommandList}); err == nil {
+		t.Error("expected a non-streaming command to be rejected")
+	}
+}
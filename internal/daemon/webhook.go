@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhubert/erg/internal/webhook"
+)
+
+// WithWebhook starts a listener at addr for GitHub, Linear, and Asana
+// webhooks. A verified delivery wakes the main loop to poll immediately;
+// regular polling continues as the fallback. When addr is empty no listener
+// is started.
+func WithWebhook(addr string) Option {
+	return func(d *Daemon) { d.webhookAddr = addr }
+}
+
+// startWebhookListener runs the webhook server until ctx is cancelled. It is
+// a no-op when no address is configured or in --once mode.
+func (d *Daemon) startWebhookListener(ctx context.Context) {
+	if d.webhookAddr == "" || d.once {
+		return
+	}
+	secretsPath, err := webhook.SecretsPath()
+	if err != nil {
+		d.logger.Warn("failed to start webhook listener", "addr", d.webhookAddr, "error", err)
+		return
+	}
+	if secrets, err := webhook.LoadSecrets(secretsPath); err == nil && secrets.SigningSecret() == "" {
+		d.logger.Warn("no webhook secret configured, GitHub and Linear deliveries will be rejected until `erg webhook setup` runs",
+			"path", secretsPath)
+	}
+
+	srv := webhook.New(d.webhookAddr, secretsPath, d.onWebhookEvent)
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.Run(ctx); err != nil {
+			select {
+			case errCh <- err:
+			default:
+			}
+			d.logger.Warn("webhook listener stopped", "addr", d.webhookAddr, "error", err)
+		}
+	}()
+	// Wait briefly so that an immediate bind failure is captured before
+	// declaring the listener started.
+	select {
+	case err := <-errCh:
+		d.logger.Warn("failed to start webhook listener, relying on polling", "addr", d.webhookAddr, "error", err)
+	case <-time.After(500 * time.Millisecond):
+		d.logger.Info("webhook listener started", "addr", d.webhookAddr)
+	}
+}
+
+// onWebhookEvent wakes the main loop to poll for new issues. The event only
+// says that something changed; the poll decides what, if anything, to queue.
+// Uses a non-blocking send so bursts of deliveries coalesce into one poll.
+func (d *Daemon) onWebhookEvent(ev webhook.Event) {
+	d.logger.Debug("webhook event, polling for new issues", "source", ev.Source, "issue", ev.IssueID)
+	select {
+	case d.issueEvents <- struct{}{}:
+	default:
+	}
+}
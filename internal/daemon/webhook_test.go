@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/webhook"
+)
+
+func TestOnWebhookEvent_CoalescesWakeups(t *testing.T) {
+	d := testDaemon(testConfig())
+
+	d.onWebhookEvent(webhook.Event{Source: "github", IssueID: "1"})
+	d.onWebhookEvent(webhook.Event{Source: "github", IssueID: "2"}) // must not block
+
+	select {
+	case <-d.issueEvents:
+	default:
+		t.Fatal("expected a pending wakeup after a webhook event")
+	}
+	select {
+	case <-d.issueEvents:
+		t.Fatal("expected bursts to coalesce into a single wakeup")
+	default:
+	}
+}
+
+func TestStartWebhookListener_BindFailureFallsBackToPolling(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	d := testDaemon(testConfig())
+	d.webhookAddr = ln.Addr().String() // already in use
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.startWebhookListener(ctx) // logs and returns; must not panic or block
+}
+
+func TestStartWebhookListener_SkippedInOnceMode(t *testing.T) {
+	d := testDaemon(testConfig())
+	d.webhookAddr = "127.0.0.1:0"
+	d.once = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.startWebhookListener(ctx) // returns immediately without binding
+}
@@ -0,0 +1,156 @@
+package daemonstate
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+const archiveVersion = 1
+
+// Archive is a portable snapshot of one or more daemon state files — work
+// items, spend totals, outbox, and issue caches — used to move a daemon to a
+// new host without losing history.
+type Archive struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Hostname   string         `json:"hostname"` // host the states were exported from
+	States     []*DaemonState `json:"states"`
+}
+
+// StateKeys returns the keys (repo paths or multi-repo daemon IDs) of all
+// daemon state files on disk.
+func StateKeys() ([]string, error) {
+	dir, err := paths.DataDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".erg")
+	}
+	dbs, err := filepath.Glob(filepath.Join(dir, "daemon-state*.db"))
+	if err != nil {
+		return nil, err
+	}
+	jsons, err := filepath.Glob(filepath.Join(dir, "daemon-state*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, match := range append(dbs, jsons...) {
+		key, err := ReadStateRepoPath(match)
+		if err != nil {
+			return nil, err
+		}
+		// A legacy JSON file may still sit beside the database it was
+		// imported into.
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// NewArchive loads the state for each key into an archive stamped with the
+// current host name.
+func NewArchive(keys []string) (*Archive, error) {
+	hostname, _ := os.Hostname()
+	a := &Archive{
+		Version:    archiveVersion,
+		ExportedAt: time.Now(),
+		Hostname:   hostname,
+	}
+	for _, key := range keys {
+		state, err := LoadDaemonState(key)
+		if err != nil {
+			return nil, err
+		}
+		a.States = append(a.States, state)
+	}
+	return a, nil
+}
+
+// Write encodes the archive to w as gzip-compressed JSON.
+func (a *Archive) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(a); err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to encode state archive: %w", err)
+	}
+	return zw.Close()
+}
+
+// ReadArchive decodes an archive written by Archive.Write.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a state archive: %w", err)
+	}
+	defer zr.Close()
+
+	var a Archive
+	if err := json.NewDecoder(zr).Decode(&a); err != nil {
+		return nil, fmt.Errorf("failed to decode state archive: %w", err)
+	}
+	if a.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported state archive version %d", a.Version)
+	}
+	for _, state := range a.States {
+		if state.WorkItems == nil {
+			state.WorkItems = make(map[string]*WorkItem)
+		}
+	}
+	return &a, nil
+}
+
+// CheckImport returns an error if importing state would replace an existing
+// state with work items and overwrite is not set.
+func CheckImport(state *DaemonState, overwrite bool) error {
+	if overwrite {
+		return nil
+	}
+	existing, err := LoadDaemonState(state.RepoPath)
+	if err != nil {
+		return fmt.Errorf("failed to check existing state for %s: %w", state.RepoPath, err)
+	}
+	if n := len(existing.GetAllWorkItems()); n > 0 {
+		return fmt.Errorf("state for %s already has %d work items (use --force to replace it)", state.RepoPath, n)
+	}
+	return nil
+}
+
+// ImportState writes an archived state to this host's state database for
+// its key, replacing what was there. The exporting host's claim identity is recorded as an alias so the
+// daemon recognizes its earlier claims on issues as its own.
+func ImportState(state *DaemonState, fromHost string) error {
+	state.filePath = StateFilePath(state.RepoPath)
+	if fromHost != "" {
+		state.AddClaimAlias(state.RepoPath + "@" + fromHost)
+	}
+	return state.Save()
+}
+
+// GetClaimAliases returns the claim identities of hosts this state was
+// migrated from.
+func (s *DaemonState) GetClaimAliases() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.ClaimAliases)
+}
+
+// AddClaimAlias records a claim identity that belongs to this daemon.
+func (s *DaemonState) AddClaimAlias(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.ClaimAliases, id) {
+		s.ClaimAliases = append(s.ClaimAliases, id)
+	}
+}
@@ -0,0 +1,100 @@
+package daemonstate
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestArchive_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "github", ID: "42"}})
+	state.RecordItemSpend("item-1", 1.5, 100, 200)
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	keys, err := StateKeys()
+	if err != nil || !slices.Equal(keys, []string{"/test/repo"}) {
+		t.Fatalf("StateKeys = %v, %v", keys, err)
+	}
+
+	archive, err := NewArchive(keys)
+	if err != nil {
+		t.Fatalf("NewArchive: %v", err)
+	}
+	archive.Hostname = "old-host"
+	var buf bytes.Buffer
+	if err := archive.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Move to a "new host": wipe local state and import.
+	if err := ClearState(); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ReadArchive(&buf)
+	if err != nil {
+		t.Fatalf("ReadArchive: %v", err)
+	}
+	if len(restored.States) != 1 || restored.Hostname != "old-host" {
+		t.Fatalf("restored = %+v", restored)
+	}
+	if err := CheckImport(restored.States[0], false); err != nil {
+		t.Fatalf("CheckImport on empty host: %v", err)
+	}
+	if err := ImportState(restored.States[0], restored.Hostname); err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+
+	loaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatalf("LoadDaemonState: %v", err)
+	}
+	item, ok := loaded.GetWorkItem("item-1")
+	if !ok || item.CostUSD != 1.5 || item.IssueRef.ID != "42" {
+		t.Errorf("imported item = %+v, %v", item, ok)
+	}
+	if aliases := loaded.GetClaimAliases(); !slices.Equal(aliases, []string{"/test/repo@old-host"}) {
+		t.Errorf("claim aliases = %v", aliases)
+	}
+
+	// A second import refuses to replace the state unless forced.
+	err = CheckImport(restored.States[0], false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected refusal to replace existing state, got %v", err)
+	}
+	if err := CheckImport(restored.States[0], true); err != nil {
+		t.Errorf("expected forced import allowed, got %v", err)
+	}
+}
+
+func TestReadArchive_Errors(t *testing.T) {
+	if _, err := ReadArchive(strings.NewReader("{}")); err == nil {
+		t.Error("expected error for non-gzip input")
+	}
+
+	var buf bytes.Buffer
+	if err := (&Archive{Version: 99}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadArchive(&buf); err == nil || !strings.Contains(err.Error(), "version 99") {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestAddClaimAlias_Deduplicates(t *testing.T) {
+	state := NewDaemonState("/test/repo")
+	state.AddClaimAlias("a@host")
+	state.AddClaimAlias("a@host")
+	if got := state.GetClaimAliases(); len(got) != 1 {
+		t.Errorf("aliases = %v, want one", got)
+	}
+}
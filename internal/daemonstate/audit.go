@@ -0,0 +1,145 @@
+package daemonstate
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// AuditKind classifies an entry of a work item's audit trail.
+type AuditKind string
+
+const (
+	// AuditTransition is a step change, including reaching a terminal state.
+	AuditTransition AuditKind = "transition"
+	// AuditCommand is a shell command a session ran.
+	AuditCommand AuditKind = "command"
+	// AuditFile is a file a session wrote or edited.
+	AuditFile AuditKind = "file"
+	// AuditPR is a pull request opened or merged for the item.
+	AuditPR AuditKind = "pr"
+	// AuditComment is a comment posted on the item's issue.
+	AuditComment AuditKind = "comment"
+	// AuditSpend is what one session response cost.
+	AuditSpend AuditKind = "spend"
+	// AuditOperator is an action an operator took on the item.
+	AuditOperator AuditKind = "operator"
+	// AuditReaper is an action the reaper took on an item stuck at a state.
+	AuditReaper AuditKind = "reaper"
+)
+
+// AuditEntry is one action recorded in a work item's audit trail. Unlike
+// the step history, the trail is append-only: it is kept after its work
+// item is pruned.
+type AuditEntry struct {
+	WorkItemID string         `json:"work_item_id"`
+	IssueID    string         `json:"issue_id,omitempty"`
+	At         time.Time      `json:"at"`
+	Kind       AuditKind      `json:"kind"`
+	Summary    string         `json:"summary"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// RecordAudit appends e to the audit trail of its work item. It is written
+// with the next Save. The issue is filled in from the work item when unset.
+// Thread-safe; may be called concurrently from multiple worker goroutines.
+func (s *DaemonState) RecordAudit(e AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordAudit(e)
+}
+
+// recordAudit queues an audit entry for the next Save. The caller must hold
+// s.mu.
+func (s *DaemonState) recordAudit(e AuditEntry) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	if e.IssueID == "" {
+		if item, ok := s.WorkItems[e.WorkItemID]; ok {
+			e.IssueID = item.IssueRef.ID
+		}
+	}
+	s.audit = append(s.audit, e)
+}
+
+// AuditLog returns the audit trail of a work item, oldest first, including
+// entries not saved yet. With byIssue set, ref is an issue's tracker ID and
+// the trails of all its work items are returned.
+func (s *DaemonState) AuditLog(ref string, byIssue bool) ([]AuditEntry, error) {
+	column := "work_item_id"
+	if byIssue {
+		column = "issue_id"
+	}
+	var entries []AuditEntry
+	path := storePath(s.filePath)
+	if _, err := os.Stat(path); err == nil {
+		db, err := openStore(path)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		entries, err = readAudit(db, column, ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.audit {
+		if (byIssue && e.IssueID == ref) || (!byIssue && e.WorkItemID == ref) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// readAudit reads the saved audit entries whose column equals ref.
+func readAudit(db *sql.DB, column, ref string) ([]AuditEntry, error) {
+	rows, err := db.Query(`SELECT work_item_id, issue_id, at, kind, summary, details FROM audit_log WHERE `+column+` = ? ORDER BY seq`, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer rows.Close()
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var at, details string
+		if err := rows.Scan(&e.WorkItemID, &e.IssueID, &at, &e.Kind, &e.Summary, &details); err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		e.At, _ = time.Parse(time.RFC3339Nano, at)
+		if details != "" {
+			if err := json.Unmarshal([]byte(details), &e.Details); err != nil {
+				return nil, fmt.Errorf("failed to parse audit log details: %w", err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// writeAudit appends audit entries within a save transaction.
+func writeAudit(tx *sql.Tx, entries []AuditEntry) error {
+	for _, e := range entries {
+		details := ""
+		if len(e.Details) > 0 {
+			data, err := json.Marshal(e.Details)
+			if err != nil {
+				return fmt.Errorf("failed to marshal audit details of work item %s: %w", e.WorkItemID, err)
+			}
+			details = string(data)
+		}
+		if _, err := tx.Exec(`INSERT INTO audit_log (work_item_id, issue_id, at, kind, summary, details) VALUES (?, ?, ?, ?, ?, ?)`,
+			e.WorkItemID, e.IssueID, e.At.UTC().Format(time.RFC3339Nano), string(e.Kind), e.Summary, details); err != nil {
+			return fmt.Errorf("failed to write audit log of work item %s: %w", e.WorkItemID, err)
+		}
+	}
+	return nil
+}
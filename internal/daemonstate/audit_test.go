@@ -0,0 +1,70 @@
+package daemonstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+
+	s := NewDaemonState("/test/repo")
+	ref := config.IssueRef{Source: "github", ID: "7"}
+	s.AddWorkItem(&WorkItem{ID: "item-7", IssueRef: ref, CurrentStep: "coding"})
+	if err := s.AdvanceWorkItem("item-7", "open_pr", "idle"); err != nil {
+		t.Fatal(err)
+	}
+	s.RecordAudit(AuditEntry{WorkItemID: "item-7", Kind: AuditCommand, Summary: "go test ./...", Details: map[string]any{"tool": "Bash"}})
+
+	entries, err := s.AuditLog("item-7", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 2 || entries[len(entries)-1].IssueID != "7" {
+		t.Fatalf("unsaved entries = %+v", entries)
+	}
+
+	if err := s.MarkWorkItemTerminal("item-7", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.PruneTerminalItems(-time.Second); n != 1 {
+		t.Fatalf("pruned %d items, want 1", n)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = loaded.AuditLog("7", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []AuditKind
+	var command *AuditEntry
+	for i, e := range entries {
+		kinds = append(kinds, e.Kind)
+		if e.Kind == AuditCommand {
+			command = &entries[i]
+		}
+	}
+	if command == nil || command.Summary != "go test ./..." || command.Details["tool"] != "Bash" {
+		t.Fatalf("command entry missing after prune and reload: %+v", entries)
+	}
+	if kinds[0] != AuditTransition || kinds[len(kinds)-1] != AuditTransition {
+		t.Errorf("kinds = %v, want transitions around the command", kinds)
+	}
+	if other, _ := loaded.AuditLog("item-8", false); len(other) != 0 {
+		t.Errorf("unrelated item has %d entries", len(other))
+	}
+}
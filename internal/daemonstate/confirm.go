@@ -0,0 +1,84 @@
+package daemonstate
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// Confirmation is a human decision on a destructive action awaiting
+// confirmation, dropped by `erg approve` for the running daemon to pick up.
+// The daemon owns the state file, so decisions travel through their own
+// files rather than being written into the state directly.
+type Confirmation struct {
+	WorkItemID string    `json:"work_item_id"`
+	Approved   bool      `json:"approved"`
+	At         time.Time `json:"at"`
+}
+
+// ConfirmationsDir returns the directory holding pending confirmations for
+// the daemon managing the given repo.
+func ConfirmationsDir(repoPath string) string {
+	return requestsDir("confirmations", repoPath)
+}
+
+// requestsDir returns the per-repo directory in the state directory through
+// which CLI commands hand requests of the given kind to the daemon.
+func requestsDir(kind, repoPath string) string {
+	dir, err := paths.StateDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".erg")
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(repoPath)))
+	return filepath.Join(dir, fmt.Sprintf("%s-%s", kind, hash[:12]))
+}
+
+// WriteConfirmation records a decision for the daemon to consume. A later
+// decision for the same work item replaces an unconsumed earlier one.
+func WriteConfirmation(repoPath string, c Confirmation) error {
+	dir := ConfirmationsDir(repoPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create confirmations directory: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(c.WorkItemID)))
+	fp := filepath.Join(dir, hash[:12]+".json")
+	tmpFile := fp + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write confirmation: %w", err)
+	}
+	if err := os.Rename(tmpFile, fp); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write confirmation: %w", err)
+	}
+	return nil
+}
+
+// TakeConfirmations returns and removes all recorded decisions for the repo.
+// Unreadable files are removed and skipped.
+func TakeConfirmations(repoPath string) []Confirmation {
+	matches, _ := filepath.Glob(filepath.Join(ConfirmationsDir(repoPath), "*.json"))
+	var result []Confirmation
+	for _, fp := range matches {
+		data, err := os.ReadFile(fp)
+		os.Remove(fp)
+		if err != nil {
+			continue
+		}
+		var c Confirmation
+		if err := json.Unmarshal(data, &c); err != nil || c.WorkItemID == "" {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package daemonstate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfirmations_WriteAndTake(t *testing.T) {
+	repo := "/test/confirm-repo-" + t.Name()
+
+	if got := TakeConfirmations(repo); len(got) != 0 {
+		t.Fatalf("expected no confirmations, got %+v", got)
+	}
+
+	if err := WriteConfirmation(repo, Confirmation{WorkItemID: "/test/repo-1", Approved: true, At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	// A later decision for the same item replaces the earlier one.
+	if err := WriteConfirmation(repo, Confirmation{WorkItemID: "/test/repo-1", Approved: false, At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConfirmation(repo, Confirmation{WorkItemID: "/test/repo-2", Approved: true, At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := TakeConfirmations(repo)
+	decisions := make(map[string]bool)
+	for _, c := range got {
+		decisions[c.WorkItemID] = c.Approved
+	}
+	if len(got) != 2 || decisions["/test/repo-1"] || !decisions["/test/repo-2"] {
+		t.Errorf("unexpected confirmations: %+v", got)
+	}
+
+	if again := TakeConfirmations(repo); len(again) != 0 {
+		t.Errorf("expected confirmations consumed, got %+v", again)
+	}
+}
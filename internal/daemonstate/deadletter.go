@@ -0,0 +1,104 @@
+package daemonstate
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+)
+
+// DeadLetter is an issue whose work failed too many times to be retried
+// automatically. It stays out of the queue until an operator retries or
+// discards it with `erg dlq`.
+type DeadLetter struct {
+	WorkItemID string          `json:"work_item_id"`
+	RepoPath   string          `json:"repo_path"`
+	IssueRef   config.IssueRef `json:"issue_ref"`
+	Failures   int             `json:"failures"`
+	Step       string          `json:"step,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	At         time.Time       `json:"at"`
+}
+
+// issueKey identifies an issue across work items for failure counts and
+// dead letters.
+func issueKey(source, id string) string {
+	return source + ":" + id
+}
+
+// countFailure records the outcome of an issue's work item reaching a
+// terminal state: a failure adds one to the issue's failure count and a
+// success clears it. Caller must hold s.mu.
+func (s *DaemonState) countFailure(ref config.IssueRef, success bool) {
+	key := issueKey(ref.Source, ref.ID)
+	if success {
+		delete(s.IssueFailures, key)
+		return
+	}
+	if s.IssueFailures == nil {
+		s.IssueFailures = make(map[string]int)
+	}
+	s.IssueFailures[key]++
+}
+
+// IssueFailureCount returns how many times work on the issue has failed
+// since it last succeeded or was released from the dead-letter queue.
+func (s *DaemonState) IssueFailureCount(source, id string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.IssueFailures[issueKey(source, id)]
+}
+
+// AddDeadLetter moves an issue to the dead-letter queue, replacing any
+// earlier entry for it.
+func (s *DaemonState) AddDeadLetter(dl DeadLetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.DeadLetters == nil {
+		s.DeadLetters = make(map[string]DeadLetter)
+	}
+	if dl.At.IsZero() {
+		dl.At = time.Now()
+	}
+	s.DeadLetters[issueKey(dl.IssueRef.Source, dl.IssueRef.ID)] = dl
+}
+
+// IsDeadLettered reports whether the issue is in the dead-letter queue.
+func (s *DaemonState) IsDeadLettered(source, id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.DeadLetters[issueKey(source, id)]
+	return ok
+}
+
+// GetDeadLetters returns the dead-letter queue, oldest first.
+func (s *DaemonState) GetDeadLetters() []DeadLetter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]DeadLetter, 0, len(s.DeadLetters))
+	for _, dl := range s.DeadLetters {
+		out = append(out, dl)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].At.Equal(out[j].At) {
+			return out[i].At.Before(out[j].At)
+		}
+		return out[i].WorkItemID < out[j].WorkItemID
+	})
+	return out
+}
+
+// RemoveDeadLetter takes an issue out of the dead-letter queue and clears
+// its failure count, so a retried issue gets a fresh set of attempts.
+func (s *DaemonState) RemoveDeadLetter(source, id string) (DeadLetter, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := issueKey(source, id)
+	dl, ok := s.DeadLetters[key]
+	if !ok {
+		return DeadLetter{}, false
+	}
+	delete(s.DeadLetters, key)
+	delete(s.IssueFailures, key)
+	return dl, true
+}
@@ -0,0 +1,79 @@
+package daemonstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestIssueFailureCount(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	ref := config.IssueRef{Source: "github", ID: "7"}
+	for _, id := range []string{"a", "b"} {
+		s.AddWorkItem(&WorkItem{ID: id, IssueRef: ref})
+		if err := s.MarkWorkItemTerminal(id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Marking an already-failed item again is not another failure.
+	if err := s.MarkWorkItemTerminal("b", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.IssueFailureCount("github", "7"); got != 2 {
+		t.Fatalf("failures = %d, want 2", got)
+	}
+
+	s.AddWorkItem(&WorkItem{ID: "c", IssueRef: ref})
+	if err := s.MarkWorkItemTerminal("c", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.IssueFailureCount("github", "7"); got != 0 {
+		t.Errorf("failures after success = %d, want 0", got)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	s := NewDaemonState("/test/repo")
+	ref := config.IssueRef{Source: "github", ID: "7"}
+	s.AddWorkItem(&WorkItem{ID: "item-7", IssueRef: ref})
+	if err := s.MarkWorkItemTerminal("item-7", false); err != nil {
+		t.Fatal(err)
+	}
+	s.AddDeadLetter(DeadLetter{WorkItemID: "item-7", IssueRef: ref, Failures: 1, Error: "boom"})
+	s.AddDeadLetter(DeadLetter{WorkItemID: "item-8", IssueRef: config.IssueRef{Source: "github", ID: "8"}, At: time.Now().Add(-time.Hour)})
+
+	if !s.IsDeadLettered("github", "7") || s.IsDeadLettered("github", "9") {
+		t.Fatal("IsDeadLettered mismatch")
+	}
+	if n := s.PruneTerminalItems(-time.Second); n != 0 {
+		t.Errorf("pruned %d items, want dead-lettered item kept", n)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dls := loaded.GetDeadLetters()
+	if len(dls) != 2 || dls[0].WorkItemID != "item-8" || dls[1].Error != "boom" {
+		t.Fatalf("dead letters = %+v, want item-8 then item-7", dls)
+	}
+
+	dl, ok := loaded.RemoveDeadLetter("github", "7")
+	if !ok || dl.WorkItemID != "item-7" {
+		t.Fatalf("RemoveDeadLetter = %+v, %v", dl, ok)
+	}
+	if loaded.IsDeadLettered("github", "7") || loaded.IssueFailureCount("github", "7") != 0 {
+		t.Error("removed issue still dead-lettered or counting failures")
+	}
+	if _, ok := loaded.RemoveDeadLetter("github", "7"); ok {
+		t.Error("second RemoveDeadLetter succeeded")
+	}
+}
@@ -0,0 +1,66 @@
+package daemonstate
+
+import "time"
+
+// DegradationTier names how much of the pipeline the daemon is running while
+// a dependency is unhealthy. When several dependencies are down, the most
+// restrictive tier applies.
+type DegradationTier string
+
+// Degradation tiers, least to most restrictive.
+const (
+	// TierNormal runs the full pipeline.
+	TierNormal DegradationTier = ""
+	// TierTrackerDown keeps active items moving while the issue tracker is
+	// unreachable; tracker writes are buffered and issues come from the cache.
+	TierTrackerDown DegradationTier = "tracker_down"
+	// TierClaudeDown pauses starting and retrying coding sessions while the
+	// Claude API is failing, but keeps polling CI and reviews.
+	TierClaudeDown DegradationTier = "claude_down"
+	// TierContainerDown only polls and queues new issues while the container
+	// runtime is unavailable.
+	TierContainerDown DegradationTier = "container_down"
+)
+
+// Description returns a one-line explanation of what the daemon is doing in
+// this tier, for status output.
+func (t DegradationTier) Description() string {
+	switch t {
+	case TierTrackerDown:
+		return "issue tracker unreachable: continuing active items, buffering tracker updates"
+	case TierClaudeDown:
+		return "Claude API unavailable: coding paused, still polling CI and reviews"
+	case TierContainerDown:
+		return "container runtime unavailable: queueing new issues only"
+	default:
+		return "normal"
+	}
+}
+
+// SetDegradation records the current degradation tier. Changing tier stamps
+// the time; returning to TierNormal clears it.
+func (s *DaemonState) SetDegradation(tier DegradationTier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tier == s.Degradation {
+		return
+	}
+	s.Degradation = tier
+	if tier == TierNormal {
+		s.DegradedSince = nil
+		return
+	}
+	now := time.Now()
+	s.DegradedSince = &now
+}
+
+// GetDegradation returns the current degradation tier and when the daemon
+// entered it (the zero time under TierNormal).
+func (s *DaemonState) GetDegradation() (DegradationTier, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.DegradedSince == nil {
+		return s.Degradation, time.Time{}
+	}
+	return s.Degradation, *s.DegradedSince
+}
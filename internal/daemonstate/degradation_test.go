@@ -0,0 +1,33 @@
+package daemonstate
+
+import "testing"
+
+func TestDegradation(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	if tier, since := s.GetDegradation(); tier != TierNormal || !since.IsZero() {
+		t.Fatalf("initial degradation = %q since %v, want normal", tier, since)
+	}
+
+	s.SetDegradation(TierClaudeDown)
+	tier, since := s.GetDegradation()
+	if tier != TierClaudeDown || since.IsZero() {
+		t.Fatalf("degradation = %q since %v, want %q with a time", tier, since, TierClaudeDown)
+	}
+	s.SetDegradation(TierClaudeDown)
+	if _, again := s.GetDegradation(); !again.Equal(since) {
+		t.Error("expected staying in a tier to keep the original time")
+	}
+
+	s.SetDegradation(TierNormal)
+	if tier, since := s.GetDegradation(); tier != TierNormal || !since.IsZero() {
+		t.Errorf("after recovery = %q since %v, want normal", tier, since)
+	}
+}
+
+func TestDegradationTier_Description(t *testing.T) {
+	for _, tier := range []DegradationTier{TierTrackerDown, TierClaudeDown, TierContainerDown} {
+		if d := tier.Description(); d == "" || d == TierNormal.Description() {
+			t.Errorf("tier %q has no description of its own", tier)
+		}
+	}
+}
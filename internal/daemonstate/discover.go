@@ -1,7 +1,6 @@
 package daemonstate
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -56,19 +55,12 @@ func RepoKeyFromLock(lockPath string) (string, error) {
 		stateDir = filepath.Dir(lockPath)
 	}
 
-	statePath := filepath.Join(stateDir, fmt.Sprintf("daemon-state-%s.json", hash))
-	data, err := os.ReadFile(statePath)
-	if err != nil {
-		return hash, nil
-	}
-
-	var partial struct {
-		RepoPath string `json:"repo_path"`
-	}
-	if err := json.Unmarshal(data, &partial); err != nil || partial.RepoPath == "" {
-		return hash, nil
+	for _, name := range []string{"daemon-state-%s.db", "daemon-state-%s.json"} {
+		if key, err := ReadStateRepoPath(filepath.Join(stateDir, fmt.Sprintf(name, hash))); err == nil && key != "" {
+			return key, nil
+		}
 	}
-	return partial.RepoPath, nil
+	return hash, nil
 }
 
 // readLockFile reads a PID from a lock file path and checks if it's alive.
@@ -0,0 +1,177 @@
+package daemonstate
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// Knowledge base sections, in the order they are rendered.
+const (
+	KnowledgeArchitecture = "architecture"
+	KnowledgeModules      = "modules"
+	KnowledgeConventions  = "conventions"
+	KnowledgeGotchas      = "gotchas"
+)
+
+// KnowledgeSections lists the knowledge base sections in render order.
+var KnowledgeSections = []string{KnowledgeArchitecture, KnowledgeModules, KnowledgeConventions, KnowledgeGotchas}
+
+// knowledgeSectionTitles are the headings used when rendering each section.
+var knowledgeSectionTitles = map[string]string{
+	KnowledgeArchitecture: "Architecture",
+	KnowledgeModules:      "Module map",
+	KnowledgeConventions:  "Conventions",
+	KnowledgeGotchas:      "Gotchas",
+}
+
+// maxKnowledgeEntries caps each section; the oldest notes are dropped first.
+const maxKnowledgeEntries = 30
+
+// KnowledgeEntry is one note in a repo's knowledge base.
+type KnowledgeEntry struct {
+	Note    string    `json:"note"`
+	IssueID string    `json:"issue_id,omitempty"` // issue whose session recorded the note
+	AddedAt time.Time `json:"added_at"`
+}
+
+// RepoKnowledge is a repo's knowledge base: notes about its architecture,
+// modules, conventions, and gotchas recorded by sessions as they complete
+// work items, and shown to later sessions on the same repo. It is stored
+// per repo path, independently of daemon state, so erg run, erg batch, and
+// the daemon share it.
+type RepoKnowledge struct {
+	RepoPath  string                      `json:"repo_path"`
+	Sections  map[string][]KnowledgeEntry `json:"sections"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+
+	filePath string
+}
+
+// KnowledgeFilePath returns the knowledge base file for a repo.
+func KnowledgeFilePath(repoPath string) string {
+	dir, err := paths.DataDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".erg")
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(repoPath)))
+	return filepath.Join(dir, "knowledge", fmt.Sprintf("%s.json", hash[:12]))
+}
+
+// LoadKnowledge loads a repo's knowledge base, returning an empty one when
+// none has been recorded yet.
+func LoadKnowledge(repoPath string) (*RepoKnowledge, error) {
+	k := &RepoKnowledge{
+		RepoPath: repoPath,
+		Sections: make(map[string][]KnowledgeEntry),
+		filePath: KnowledgeFilePath(repoPath),
+	}
+	data, err := os.ReadFile(k.filePath)
+	if os.IsNotExist(err) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read knowledge base: %w", err)
+	}
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, fmt.Errorf("failed to parse knowledge base: %w", err)
+	}
+	if k.Sections == nil {
+		k.Sections = make(map[string][]KnowledgeEntry)
+	}
+	return k, nil
+}
+
+// ParseLearning splits a learning reported by a session into its section
+// and note. Learnings without a known section prefix are filed as gotchas.
+func ParseLearning(learning string) (section, note string) {
+	learning = strings.TrimSpace(learning)
+	if prefix, rest, ok := strings.Cut(learning, ":"); ok {
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+		if prefix == "module" || prefix == "module map" {
+			prefix = KnowledgeModules
+		}
+		if slices.Contains(KnowledgeSections, prefix) {
+			return prefix, strings.TrimSpace(rest)
+		}
+	}
+	return KnowledgeGotchas, learning
+}
+
+// Add records a note in a section, skipping duplicates (case-insensitive).
+// It reports whether the note was added.
+func (k *RepoKnowledge) Add(section, note, issueID string) bool {
+	note = strings.TrimSpace(note)
+	if note == "" || !slices.Contains(KnowledgeSections, section) {
+		return false
+	}
+	entries := k.Sections[section]
+	if slices.ContainsFunc(entries, func(e KnowledgeEntry) bool { return strings.EqualFold(e.Note, note) }) {
+		return false
+	}
+	entries = append(entries, KnowledgeEntry{Note: note, IssueID: issueID, AddedAt: time.Now()})
+	if len(entries) > maxKnowledgeEntries {
+		entries = entries[len(entries)-maxKnowledgeEntries:]
+	}
+	k.Sections[section] = entries
+	k.UpdatedAt = time.Now()
+	return true
+}
+
+// Empty reports whether the knowledge base has no notes.
+func (k *RepoKnowledge) Empty() bool {
+	for _, entries := range k.Sections {
+		if len(entries) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Render formats the knowledge base as markdown, one heading per non-empty
+// section.
+func (k *RepoKnowledge) Render() string {
+	var sb strings.Builder
+	for _, section := range KnowledgeSections {
+		entries := k.Sections[section]
+		if len(entries) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("## " + knowledgeSectionTitles[section] + "\n")
+		for _, e := range entries {
+			sb.WriteString("- " + e.Note + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// Save writes the knowledge base to disk atomically.
+func (k *RepoKnowledge) Save() error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal knowledge base: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create knowledge directory: %w", err)
+	}
+	tmpFile := k.filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write knowledge base: %w", err)
+	}
+	if err := os.Rename(tmpFile, k.filePath); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename knowledge base: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package daemonstate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestParseLearning(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantSection string
+		wantNote    string
+	}{
+		{"architecture: the daemon owns all state", KnowledgeArchitecture, "the daemon owns all state"},
+		{"Modules: internal/git wraps the gh CLI", KnowledgeModules, "internal/git wraps the gh CLI"},
+		{"module map: cmd/ holds Cobra commands", KnowledgeModules, "cmd/ holds Cobra commands"},
+		{" conventions : tests use t.TempDir", KnowledgeConventions, "tests use t.TempDir"},
+		{"run go generate before building", KnowledgeGotchas, "run go generate before building"},
+		{"note: unknown prefix", KnowledgeGotchas, "note: unknown prefix"},
+	}
+	for _, tt := range tests {
+		section, note := ParseLearning(tt.in)
+		if section != tt.wantSection || note != tt.wantNote {
+			t.Errorf("ParseLearning(%q) = (%q, %q), want (%q, %q)", tt.in, section, note, tt.wantSection, tt.wantNote)
+		}
+	}
+}
+
+func TestRepoKnowledge_AddAndRender(t *testing.T) {
+	k := &RepoKnowledge{Sections: make(map[string][]KnowledgeEntry)}
+	if !k.Empty() || k.Render() != "" {
+		t.Fatal("expected a new knowledge base to be empty")
+	}
+
+	if !k.Add(KnowledgeGotchas, "Flaky test in pkg/x", "1") {
+		t.Error("expected note to be added")
+	}
+	if k.Add(KnowledgeGotchas, "flaky TEST in pkg/x", "2") {
+		t.Error("expected duplicate note to be skipped")
+	}
+	if k.Add("opinions", "tabs are better", "3") {
+		t.Error("expected unknown section to be rejected")
+	}
+	k.Add(KnowledgeArchitecture, "Event-driven core", "4")
+
+	want := "## Architecture\n- Event-driven core\n\n## Gotchas\n- Flaky test in pkg/x\n"
+	if got := k.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	for i := range maxKnowledgeEntries + 5 {
+		k.Add(KnowledgeConventions, fmt.Sprintf("convention %d", i), "")
+	}
+	entries := k.Sections[KnowledgeConventions]
+	if len(entries) != maxKnowledgeEntries || !strings.HasSuffix(entries[len(entries)-1].Note, fmt.Sprint(maxKnowledgeEntries+4)) {
+		t.Errorf("expected section capped at %d newest notes, got %d", maxKnowledgeEntries, len(entries))
+	}
+}
+
+func TestRepoKnowledge_SaveLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	k, err := LoadKnowledge("/repo")
+	if err != nil {
+		t.Fatalf("LoadKnowledge on a missing file: %v", err)
+	}
+	k.Add(KnowledgeModules, "internal/api serves HTTP", "7")
+	if err := k.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadKnowledge("/repo")
+	if err != nil {
+		t.Fatalf("LoadKnowledge: %v", err)
+	}
+	entries := loaded.Sections[KnowledgeModules]
+	if len(entries) != 1 || entries[0].Note != "internal/api serves HTTP" || entries[0].IssueID != "7" {
+		t.Errorf("unexpected loaded entries: %+v", entries)
+	}
+
+	other, _ := LoadKnowledge("/other")
+	if !other.Empty() {
+		t.Error("expected knowledge bases to be kept per repo")
+	}
+}
@@ -0,0 +1,223 @@
+package daemonstate
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LeaseFilePath returns the path of the leadership lease for a daemon key
+// in dir.
+func LeaseFilePath(dir, key string) string {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+	return filepath.Join(dir, fmt.Sprintf("daemon-%s.lease", hash[:12]))
+}
+
+// Lease records which of several daemon instances for the same key leads.
+// The leader renews it well before it expires; a standby takes it over
+// once it has expired.
+type Lease struct {
+	Holder  string    `json:"holder"`
+	Host    string    `json:"host"`
+	Renewed time.Time `json:"renewed"`
+	Expires time.Time `json:"expires"`
+}
+
+// LeaseFile is a Lease kept in a file on storage every instance mounts,
+// such as an NFS share. Updates are serialized by a companion lock file
+// created exclusively, so two instances never both believe they hold it.
+//
+// A lease's expiry is written by one instance's clock and checked by
+// another's, so instances must keep their clocks in sync (e.g. with NTP) to
+// well within the TTL. The lock file depends on no clock: it is judged
+// abandoned by how long this instance has seen it unchanged, not by its
+// mtime, which NFS sets from the server's clock.
+type LeaseFile struct {
+	path   string
+	holder string
+	host   string
+	ttl    time.Duration
+
+	mu sync.Mutex
+	// seenToken is the token of a lock file held by someone else, and
+	// seenAt when this instance first saw it.
+	seenToken string
+	seenAt    time.Time
+}
+
+// NewLeaseFile returns the lease for key in dir, acquired as holder on
+// host and held for ttl past each renewal.
+func NewLeaseFile(dir, key, holder, host string, ttl time.Duration) *LeaseFile {
+	return &LeaseFile{path: LeaseFilePath(dir, key), holder: holder, host: host, ttl: ttl}
+}
+
+// Acquire takes the lease when it is free or has expired, or renews it
+// when it is already held, and returns the lease as it now stands and
+// whether this instance holds it.
+func (l *LeaseFile) Acquire(now time.Time) (Lease, bool, error) {
+	unlock, err := l.lock(now)
+	if err != nil {
+		return Lease{}, false, err
+	}
+	defer unlock()
+
+	cur, err := l.read()
+	if err != nil {
+		return Lease{}, false, err
+	}
+	if cur.Holder != "" && cur.Holder != l.holder && now.Before(cur.Expires) {
+		return cur, false, nil
+	}
+	next := Lease{Holder: l.holder, Host: l.host, Renewed: now, Expires: now.Add(l.ttl)}
+	if err := l.write(next); err != nil {
+		return Lease{}, false, err
+	}
+	return next, true, nil
+}
+
+// Current returns the lease on file, or a zero Lease when there is none.
+func (l *LeaseFile) Current() (Lease, error) {
+	return l.read()
+}
+
+// Release gives the lease up if this instance holds it, so a standby can
+// take over without waiting for it to expire.
+func (l *LeaseFile) Release() error {
+	unlock, err := l.lock(time.Now())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cur, err := l.read()
+	if err != nil || cur.Holder != l.holder {
+		return err
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lease: %w", err)
+	}
+	return nil
+}
+
+// lock creates the lease's lock file exclusively, holding a token unique
+// to this attempt, and returns a func that removes it. A lock that has held
+// the same token for the lease TTL, by this instance's clock, was left by
+// an instance that died mid-update and is broken.
+func (l *LeaseFile) lock(now time.Time) (func(), error) {
+	lockPath := l.path + ".lock"
+	token := l.holder + "/" + randomToken()
+	for attempt := range 2 {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, writeErr := f.WriteString(token)
+			if err := errors.Join(writeErr, f.Close()); err != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to lock lease: %w", err)
+			}
+			return func() { unlockLease(lockPath, token) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock lease: %w", err)
+		}
+		stale, abandoned := l.abandoned(lockPath, now)
+		if attempt > 0 || !abandoned || !breakLock(lockPath, stale) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("lease %s is being updated by another instance", l.path)
+}
+
+// abandoned returns the token of the lock at lockPath and whether this
+// instance has seen it unchanged for the lease TTL.
+func (l *LeaseFile) abandoned(lockPath string, now time.Time) (string, bool) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return "", false
+	}
+	token := string(data)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if token != l.seenToken || l.seenAt.IsZero() {
+		l.seenToken, l.seenAt = token, now
+		return token, false
+	}
+	return token, now.Sub(l.seenAt) >= l.ttl
+}
+
+// breakLock removes the abandoned lock at lockPath holding token. The lock
+// is first renamed aside under a unique name, which only one of several
+// instances breaking it at once can do, and removed only if it is still
+// the lock judged abandoned; a lock that replaced it in the meantime is put
+// back. Reports whether the lock was removed.
+func breakLock(lockPath, token string) bool {
+	aside := lockPath + ".broken-" + randomToken()
+	if err := os.Rename(lockPath, aside); err != nil {
+		return false
+	}
+	data, err := os.ReadFile(aside)
+	if err == nil && string(data) != token {
+		// Link rather than rename, so a lock created since isn't replaced.
+		os.Link(aside, lockPath)
+		os.Remove(aside)
+		return false
+	}
+	os.Remove(aside)
+	return true
+}
+
+// unlockLease removes the lock at lockPath if it still holds token, so an
+// instance whose lock was broken while it stalled can't remove the lock of
+// the instance that broke it.
+func unlockLease(lockPath, token string) {
+	if data, err := os.ReadFile(lockPath); err == nil && string(data) == token {
+		os.Remove(lockPath)
+	}
+}
+
+// randomToken returns a random hex string.
+func randomToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// read returns the lease on file, or a zero Lease when there is none.
+func (l *LeaseFile) read() (Lease, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Lease{}, nil
+	}
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to read lease: %w", err)
+	}
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		// A torn or corrupt lease is treated as free.
+		return Lease{}, nil
+	}
+	return lease, nil
+}
+
+// write replaces the lease on file atomically.
+func (l *LeaseFile) write(lease Lease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package daemonstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaseFile_AcquireRenewAndTakeOver(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	primary := NewLeaseFile(dir, "owner/repo", "host-a/1", "host-a", 30*time.Second)
+	standby := NewLeaseFile(dir, "owner/repo", "host-b/2", "host-b", 30*time.Second)
+
+	if _, held, err := primary.Acquire(now); err != nil || !held {
+		t.Fatalf("expected primary to take a free lease, held=%v err=%v", held, err)
+	}
+	lease, held, err := standby.Acquire(now.Add(10 * time.Second))
+	if err != nil || held {
+		t.Fatalf("expected standby refused a live lease, held=%v err=%v", held, err)
+	}
+	if lease.Host != "host-a" {
+		t.Errorf("expected the standby told who leads, got %+v", lease)
+	}
+
+	// Renewing pushes the expiry out.
+	if _, held, _ := primary.Acquire(now.Add(20 * time.Second)); !held {
+		t.Fatal("expected primary to renew its lease")
+	}
+	if _, held, _ := standby.Acquire(now.Add(40 * time.Second)); held {
+		t.Fatal("expected renewed lease still held by primary")
+	}
+
+	// Once the primary stops renewing, the standby takes over.
+	if _, held, _ := standby.Acquire(now.Add(51 * time.Second)); !held {
+		t.Fatal("expected standby to take over an expired lease")
+	}
+	if _, held, _ := primary.Acquire(now.Add(52 * time.Second)); held {
+		t.Fatal("expected former primary refused the lease it lost")
+	}
+}
+
+func TestLeaseFile_Release(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	primary := NewLeaseFile(dir, "owner/repo", "host-a/1", "host-a", time.Minute)
+	standby := NewLeaseFile(dir, "owner/repo", "host-b/2", "host-b", time.Minute)
+	primary.Acquire(now)
+
+	// Releasing a lease held by someone else leaves it alone.
+	if err := standby.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, held, _ := standby.Acquire(now); held {
+		t.Fatal("expected lease still held after a non-holder released it")
+	}
+
+	if err := primary.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, held, _ := standby.Acquire(now); !held {
+		t.Fatal("expected standby to take a released lease at once")
+	}
+}
+
+func TestLeaseFile_BreaksAbandonedLock(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLeaseFile(dir, "owner/repo", "host-a/1", "host-a", time.Minute)
+	lockPath := LeaseFilePath(dir, "owner/repo") + ".lock"
+	if err := os.WriteFile(lockPath, []byte("host-b/1/dead"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// The lock's mtime says nothing: NFS sets it from the server's clock.
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lockPath, old, old)
+
+	now := time.Now()
+	if _, _, err := l.Acquire(now); err == nil {
+		t.Fatal("expected a lock seen for the first time to block the update")
+	}
+	if _, _, err := l.Acquire(now.Add(30 * time.Second)); err == nil {
+		t.Fatal("expected a lock seen for less than the TTL to block the update")
+	}
+	if _, held, err := l.Acquire(now.Add(time.Minute)); err != nil || !held {
+		t.Fatalf("expected an abandoned lock broken, held=%v err=%v", held, err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected the lock file removed after the update")
+	}
+}
+
+func TestLeaseFile_BreakingIsExclusive(t *testing.T) {
+	dir := t.TempDir()
+	a := NewLeaseFile(dir, "owner/repo", "host-a/1", "host-a", time.Minute)
+	b := NewLeaseFile(dir, "owner/repo", "host-b/1", "host-b", time.Minute)
+	lockPath := LeaseFilePath(dir, "owner/repo") + ".lock"
+	os.WriteFile(lockPath, []byte("host-c/1/dead"), 0o600)
+
+	now := time.Now()
+	a.lock(now)
+	b.lock(now)
+
+	// a breaks the abandoned lock and takes its own.
+	unlockA, err := a.lock(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected a to break the abandoned lock: %v", err)
+	}
+	held, _ := os.ReadFile(lockPath)
+
+	// b judged the same lock abandoned, but it has been replaced.
+	if _, err := b.lock(now.Add(time.Minute)); err == nil {
+		t.Fatal("expected b blocked by a's fresh lock")
+	}
+	if breakLock(lockPath, "host-c/1/dead") {
+		t.Fatal("expected a late break of the old lock to leave the new one")
+	}
+	if got, _ := os.ReadFile(lockPath); string(got) != string(held) {
+		t.Fatalf("lock = %q, want a's %q kept", got, held)
+	}
+
+	// An instance whose lock was broken doesn't remove its successor's.
+	unlockLease(lockPath, "host-c/1/dead")
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatal("expected a's lock kept")
+	}
+	unlockA()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected a's lock removed when a unlocks")
+	}
+	if matches, _ := filepath.Glob(lockPath + ".broken-*"); len(matches) != 0 {
+		t.Errorf("broken locks left behind: %v", matches)
+	}
+}
@@ -0,0 +1,76 @@
+package daemonstate
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// MigrationRequest asks the running daemon to move work items still on an
+// earlier workflow version onto the current one, dropped by
+// `erg workflow migrate`. Like confirmations it travels through its own file
+// because the daemon owns the state file.
+type MigrationRequest struct {
+	// Policy overrides settings.migration.policy for this migration; empty
+	// means remap.
+	Policy string `json:"policy,omitempty"`
+	// Remap rules are applied on top of settings.migration.remap.
+	Remap map[string]string `json:"remap,omitempty"`
+	At    time.Time         `json:"at"`
+}
+
+// MigrationRequestPath returns the file holding a pending migration request
+// for the daemon managing the given repo.
+func MigrationRequestPath(repoPath string) string {
+	dir, err := paths.StateDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".erg")
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(repoPath)))
+	return filepath.Join(dir, fmt.Sprintf("migrate-%s.json", hash[:12]))
+}
+
+// WriteMigrationRequest records a migration request for the daemon to
+// consume, replacing any unconsumed earlier one.
+func WriteMigrationRequest(repoPath string, r MigrationRequest) error {
+	fp := MigrationRequestPath(repoPath)
+	if err := os.MkdirAll(filepath.Dir(fp), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	tmpFile := fp + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write migration request: %w", err)
+	}
+	if err := os.Rename(tmpFile, fp); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write migration request: %w", err)
+	}
+	return nil
+}
+
+// TakeMigrationRequest returns and removes the pending migration request for
+// the repo, or nil if there is none. An unreadable request is removed and
+// ignored.
+func TakeMigrationRequest(repoPath string) *MigrationRequest {
+	fp := MigrationRequestPath(repoPath)
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil
+	}
+	os.Remove(fp)
+	var r MigrationRequest
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil
+	}
+	return &r
+}
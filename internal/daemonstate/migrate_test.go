@@ -0,0 +1,35 @@
+package daemonstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestMigrationRequest_WriteAndTake(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+	repo := "/test/migrate-repo"
+
+	if got := TakeMigrationRequest(repo); got != nil {
+		t.Fatalf("expected no request, got %+v", got)
+	}
+
+	if err := WriteMigrationRequest(repo, MigrationRequest{Policy: "fail", At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	// A later request replaces the earlier one.
+	if err := WriteMigrationRequest(repo, MigrationRequest{Remap: map[string]string{"old": "new"}, At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := TakeMigrationRequest(repo)
+	if got == nil || got.Policy != "" || got.Remap["old"] != "new" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if again := TakeMigrationRequest(repo); again != nil {
+		t.Errorf("expected request consumed, got %+v", again)
+	}
+}
@@ -0,0 +1,115 @@
+package daemonstate
+
+import (
+	"slices"
+	"time"
+)
+
+// CachedIssue is an issue from the last successful tracker fetch.
+type CachedIssue struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	URL   string `json:"url,omitempty"`
+
+	// Parent is the parent issue ID when the issue is an expanded sub-task.
+	Parent string `json:"parent,omitempty"`
+}
+
+// IssueCache holds the result of the last successful issue fetch for a repo.
+// It is served in place of a live fetch while the tracker is unreachable.
+type IssueCache struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Issues    []CachedIssue `json:"issues"`
+}
+
+// PendingOp kinds.
+const (
+	PendingComment     = "comment"
+	PendingAddLabel    = "add_label"
+	PendingRemoveLabel = "remove_label"
+)
+
+// PendingOp is an outgoing tracker write (comment or label change) buffered
+// while the tracker was unreachable. It is replayed in order once
+// connectivity returns.
+type PendingOp struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	WorkItemID string    `json:"work_item_id"`
+	RepoPath   string    `json:"repo_path"`
+	Source     string    `json:"source"`
+	IssueID    string    `json:"issue_id"`
+	Body       string    `json:"body,omitempty"`
+	Step       string    `json:"step,omitempty"` // idempotency marker step for comments
+	Label      string    `json:"label,omitempty"`
+	QueuedAt   time.Time `json:"queued_at"`
+}
+
+// SetIssueCache records the issues from a successful fetch for a repo.
+func (s *DaemonState) SetIssueCache(repoPath string, issues []CachedIssue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.IssueCaches == nil {
+		s.IssueCaches = make(map[string]IssueCache)
+	}
+	s.IssueCaches[repoPath] = IssueCache{FetchedAt: time.Now(), Issues: slices.Clone(issues)}
+}
+
+// GetIssueCache returns the cached fetch for a repo, if any.
+func (s *DaemonState) GetIssueCache(repoPath string) (IssueCache, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.IssueCaches[repoPath]
+	c.Issues = slices.Clone(c.Issues)
+	return c, ok
+}
+
+// EnqueuePendingOp appends a buffered tracker write to the outbox.
+func (s *DaemonState) EnqueuePendingOp(op PendingOp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if op.QueuedAt.IsZero() {
+		op.QueuedAt = time.Now()
+	}
+	s.Outbox = append(s.Outbox, op)
+}
+
+// GetPendingOps returns a copy of the outbox in the order the writes were buffered.
+func (s *DaemonState) GetPendingOps() []PendingOp {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.Outbox)
+}
+
+// RemovePendingOp drops a buffered write from the outbox.
+func (s *DaemonState) RemovePendingOp(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Outbox = slices.DeleteFunc(s.Outbox, func(op PendingOp) bool { return op.ID == id })
+}
+
+// SetTrackerOffline records whether the issue tracker is unreachable. The
+// first transition to offline stamps the time; going online clears it.
+func (s *DaemonState) SetTrackerOffline(offline bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case !offline:
+		s.TrackerOfflineSince = nil
+	case s.TrackerOfflineSince == nil:
+		now := time.Now()
+		s.TrackerOfflineSince = &now
+	}
+}
+
+// GetTrackerOfflineSince returns when the tracker became unreachable, or the
+// zero time while it is reachable.
+func (s *DaemonState) GetTrackerOfflineSince() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.TrackerOfflineSince == nil {
+		return time.Time{}
+	}
+	return *s.TrackerOfflineSince
+}
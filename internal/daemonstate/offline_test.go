@@ -0,0 +1,89 @@
+package daemonstate
+
+import (
+	"testing"
+)
+
+func TestIssueCache(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	if _, ok := s.GetIssueCache("/test/repo"); ok {
+		t.Fatal("expected no cache before first fetch")
+	}
+
+	s.SetIssueCache("/test/repo", []CachedIssue{{ID: "1", Title: "First"}})
+	c, ok := s.GetIssueCache("/test/repo")
+	if !ok || len(c.Issues) != 1 || c.Issues[0].Title != "First" || c.FetchedAt.IsZero() {
+		t.Fatalf("GetIssueCache = %+v, %v", c, ok)
+	}
+
+	// The returned slice is a copy.
+	c.Issues[0].Title = "mutated"
+	if again, _ := s.GetIssueCache("/test/repo"); again.Issues[0].Title != "First" {
+		t.Error("expected cache to be isolated from callers")
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	s.EnqueuePendingOp(PendingOp{ID: "a", Kind: PendingComment, Body: "hello"})
+	s.EnqueuePendingOp(PendingOp{ID: "b", Kind: PendingAddLabel, Label: "wip"})
+
+	ops := s.GetPendingOps()
+	if len(ops) != 2 || ops[0].ID != "a" || ops[1].ID != "b" {
+		t.Fatalf("GetPendingOps = %+v, want a then b", ops)
+	}
+	if ops[0].QueuedAt.IsZero() {
+		t.Error("expected QueuedAt to be stamped")
+	}
+
+	s.RemovePendingOp("a")
+	if ops := s.GetPendingOps(); len(ops) != 1 || ops[0].ID != "b" {
+		t.Errorf("after remove = %+v, want only b", ops)
+	}
+}
+
+func TestTrackerOffline(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	if !s.GetTrackerOfflineSince().IsZero() {
+		t.Fatal("expected tracker online initially")
+	}
+
+	s.SetTrackerOffline(true)
+	since := s.GetTrackerOfflineSince()
+	if since.IsZero() {
+		t.Fatal("expected offline time to be recorded")
+	}
+	s.SetTrackerOffline(true)
+	if !s.GetTrackerOfflineSince().Equal(since) {
+		t.Error("expected repeated offline reports to keep the original time")
+	}
+
+	s.SetTrackerOffline(false)
+	if !s.GetTrackerOfflineSince().IsZero() {
+		t.Error("expected online to clear the offline time")
+	}
+}
+
+func TestOfflineState_SurvivesSaveAndLoad(t *testing.T) {
+	s := NewDaemonState("/test/offline-repo")
+	s.SetIssueCache("/test/offline-repo", []CachedIssue{{ID: "7", Title: "Cached"}})
+	s.EnqueuePendingOp(PendingOp{ID: "op-1", Kind: PendingRemoveLabel, IssueID: "7", Label: "queued"})
+	s.SetTrackerOffline(true)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadDaemonState("/test/offline-repo")
+	if err != nil {
+		t.Fatalf("LoadDaemonState: %v", err)
+	}
+	if c, ok := loaded.GetIssueCache("/test/offline-repo"); !ok || len(c.Issues) != 1 {
+		t.Errorf("cache not persisted: %+v", c)
+	}
+	if ops := loaded.GetPendingOps(); len(ops) != 1 || ops[0].Label != "queued" {
+		t.Errorf("outbox not persisted: %+v", ops)
+	}
+	if loaded.GetTrackerOfflineSince().IsZero() {
+		t.Error("offline time not persisted")
+	}
+}
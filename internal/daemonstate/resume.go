@@ -0,0 +1,66 @@
+package daemonstate
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResumeRequest asks the running daemon to restart a work item parked in the
+// stalled phase after its wait state timed out, dropped by `erg resume`.
+type ResumeRequest struct {
+	WorkItemID string    `json:"work_item_id"`
+	At         time.Time `json:"at"`
+}
+
+// ResumeRequestsDir returns the directory holding pending resume requests
+// for the daemon managing the given repo.
+func ResumeRequestsDir(repoPath string) string {
+	return requestsDir("resume", repoPath)
+}
+
+// WriteResumeRequest records a resume request for the daemon to consume.
+func WriteResumeRequest(repoPath string, r ResumeRequest) error {
+	dir := ResumeRequestsDir(repoPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create resume directory: %w", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(r.WorkItemID)))
+	fp := filepath.Join(dir, hash[:12]+".json")
+	tmpFile := fp + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write resume request: %w", err)
+	}
+	if err := os.Rename(tmpFile, fp); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write resume request: %w", err)
+	}
+	return nil
+}
+
+// TakeResumeRequests returns and removes all recorded resume requests for the
+// repo. Unreadable files are removed and skipped.
+func TakeResumeRequests(repoPath string) []ResumeRequest {
+	matches, _ := filepath.Glob(filepath.Join(ResumeRequestsDir(repoPath), "*.json"))
+	var result []ResumeRequest
+	for _, fp := range matches {
+		data, err := os.ReadFile(fp)
+		os.Remove(fp)
+		if err != nil {
+			continue
+		}
+		var r ResumeRequest
+		if err := json.Unmarshal(data, &r); err != nil || r.WorkItemID == "" {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package daemonstate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResumeRequests_WriteAndTake(t *testing.T) {
+	repo := "/test/resume-repo-" + t.Name()
+
+	if got := TakeResumeRequests(repo); len(got) != 0 {
+		t.Fatalf("expected no resume requests, got %+v", got)
+	}
+
+	for _, id := range []string{"/test/repo-1", "/test/repo-1", "/test/repo-2"} {
+		if err := WriteResumeRequest(repo, ResumeRequest{WorkItemID: id, At: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ConfirmationsDir(repo) == ResumeRequestsDir(repo) {
+		t.Error("resume requests must not share the confirmations directory")
+	}
+
+	got := TakeResumeRequests(repo)
+	if len(got) != 2 {
+		t.Errorf("expected one request per item, got %+v", got)
+	}
+	if again := TakeResumeRequests(repo); len(again) != 0 {
+		t.Errorf("expected resume requests consumed, got %+v", again)
+	}
+}
@@ -0,0 +1,67 @@
+package daemonstate
+
+import "time"
+
+// RunMode is whether the daemon is taking on new work, set by operators
+// through `erg daemon pause`, `drain`, and `resume`.
+type RunMode string
+
+const (
+	// ModeRunning polls for and starts new work.
+	ModeRunning RunMode = ""
+	// ModePaused stops polling and starting new work; in-flight items
+	// carry on. It survives a restart until resumed.
+	ModePaused RunMode = "paused"
+	// ModeDraining is paused, and the daemon exits once no sessions are
+	// running.
+	ModeDraining RunMode = "draining"
+)
+
+// String returns the mode's name, "running" for ModeRunning.
+func (m RunMode) String() string {
+	if m == ModeRunning {
+		return "running"
+	}
+	return string(m)
+}
+
+// Description returns a one-line explanation of what the daemon is doing in
+// this mode, for status output.
+func (m RunMode) Description() string {
+	switch m {
+	case ModePaused:
+		return "not picking up new work; in-flight items continue"
+	case ModeDraining:
+		return "finishing running sessions, then exiting"
+	default:
+		return "normal"
+	}
+}
+
+// SetRunMode records the daemon's run mode. Changing mode stamps the time;
+// returning to ModeRunning clears it.
+func (s *DaemonState) SetRunMode(mode RunMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mode == s.RunMode {
+		return
+	}
+	s.RunMode = mode
+	if mode == ModeRunning {
+		s.RunModeSince = nil
+		return
+	}
+	now := time.Now()
+	s.RunModeSince = &now
+}
+
+// GetRunMode returns the daemon's run mode and when it was set (the zero
+// time under ModeRunning).
+func (s *DaemonState) GetRunMode() (RunMode, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.RunModeSince == nil {
+		return s.RunMode, time.Time{}
+	}
+	return s.RunMode, *s.RunModeSince
+}
@@ -0,0 +1,34 @@
+package daemonstate
+
+import (
+	"testing"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestRunMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	s := NewDaemonState("/test/repo")
+	if mode, since := s.GetRunMode(); mode != ModeRunning || !since.IsZero() || mode.String() != "running" {
+		t.Fatalf("initial mode = %q since %v, want running", mode, since)
+	}
+
+	s.SetRunMode(ModePaused)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode, since := loaded.GetRunMode(); mode != ModePaused || since.IsZero() {
+		t.Errorf("reloaded mode = %q since %v, want paused with a time", mode, since)
+	}
+
+	loaded.SetRunMode(ModeRunning)
+	if mode, since := loaded.GetRunMode(); mode != ModeRunning || !since.IsZero() {
+		t.Errorf("after resume = %q since %v, want running", mode, since)
+	}
+}
@@ -0,0 +1,163 @@
+package daemonstate
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// SpendDayFormat is the layout of SpendEntry.Day.
+const SpendDayFormat = "2006-01-02"
+
+// spendWindow is how many days of spend the state keeps in memory for
+// budget checks; enough to cover the current week.
+const spendWindow = 8
+
+// SpendEntry is what one work item spent on one model on one day.
+type SpendEntry struct {
+	Day        string // local calendar day, in SpendDayFormat
+	RepoPath   string
+	WorkItemID string
+	// Model is the model the spend was on; "" is the Claude CLI's default,
+	// and spend recorded before models were tracked.
+	Model        string
+	IssueID      string
+	CostUSD      float64
+	InputTokens  int
+	OutputTokens int
+}
+
+// Tokens returns the input and output tokens of the entry together.
+func (e SpendEntry) Tokens() int {
+	return e.InputTokens + e.OutputTokens
+}
+
+// spendKey identifies a row of the spend table.
+type spendKey struct {
+	day, repo, item, model string
+}
+
+func (e SpendEntry) key() spendKey {
+	return spendKey{e.Day, e.RepoPath, e.WorkItemID, e.Model}
+}
+
+// add accumulates o's spend into e.
+func (e *SpendEntry) add(o SpendEntry) {
+	e.CostUSD += o.CostUSD
+	e.InputTokens += o.InputTokens
+	e.OutputTokens += o.OutputTokens
+	if o.IssueID != "" {
+		e.IssueID = o.IssueID
+	}
+}
+
+// SpendDay returns the spend day t falls on.
+func SpendDay(t time.Time) string {
+	return t.Local().Format(SpendDayFormat)
+}
+
+// SpendWeekStart returns the first day (Monday) of the spend week t falls on.
+func SpendWeekStart(t time.Time) string {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset).Format(SpendDayFormat)
+}
+
+// RecordSpendEntry adds e to the spend ledger. It is written with the next
+// Save and counts towards SpendSince right away.
+// Thread-safe; may be called concurrently from multiple worker goroutines.
+func (s *DaemonState) RecordSpendEntry(e SpendEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.spend == nil {
+		s.spend = make(map[spendKey]*SpendEntry)
+	}
+	if s.spendPending == nil {
+		s.spendPending = make(map[spendKey]*SpendEntry)
+	}
+	for _, m := range []map[spendKey]*SpendEntry{s.spend, s.spendPending} {
+		if cur, ok := m[e.key()]; ok {
+			cur.add(e)
+		} else {
+			c := e
+			m[e.key()] = &c
+		}
+	}
+	// Days before the window no longer count towards any budget.
+	cutoff := time.Now().AddDate(0, 0, -spendWindow).Format(SpendDayFormat)
+	for k := range s.spend {
+		if k.day < cutoff {
+			delete(s.spend, k)
+		}
+	}
+}
+
+// SpendSince returns the cost and tokens spent in repoPath, or in every repo
+// when repoPath is empty, from the day since (in SpendDayFormat) on. Only
+// the last week or so is kept in memory, so since must be recent.
+func (s *DaemonState) SpendSince(repoPath, since string) (costUSD float64, tokens int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, e := range s.spend {
+		if k.day >= since && (repoPath == "" || k.repo == repoPath) {
+			costUSD += e.CostUSD
+			tokens += e.Tokens()
+		}
+	}
+	return costUSD, tokens
+}
+
+// SpendLedger returns the spend recorded from the day since on, including
+// entries not saved yet, ordered by day, repo, work item and model.
+func (s *DaemonState) SpendLedger(since string) ([]SpendEntry, error) {
+	byKey := make(map[spendKey]*SpendEntry)
+	path := storePath(s.filePath)
+	if _, err := os.Stat(path); err == nil {
+		db, err := openStore(path)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		rows, err := db.Query(`SELECT day, repo, work_item_id, model, issue_id, cost_usd, input_tokens, output_tokens
+			FROM spend WHERE day >= ?`, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spend: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e SpendEntry
+			if err := rows.Scan(&e.Day, &e.RepoPath, &e.WorkItemID, &e.Model, &e.IssueID, &e.CostUSD, &e.InputTokens, &e.OutputTokens); err != nil {
+				return nil, fmt.Errorf("failed to read spend: %w", err)
+			}
+			byKey[e.key()] = &e
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read spend: %w", err)
+		}
+	}
+
+	s.mu.RLock()
+	for k, e := range s.spendPending {
+		if k.day < since {
+			continue
+		}
+		if cur, ok := byKey[k]; ok {
+			cur.add(*e)
+		} else {
+			c := *e
+			byKey[k] = &c
+		}
+	}
+	s.mu.RUnlock()
+
+	entries := make([]SpendEntry, 0, len(byKey))
+	for _, e := range byKey {
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b SpendEntry) int {
+		return cmp.Or(cmp.Compare(a.Day, b.Day), cmp.Compare(a.RepoPath, b.RepoPath), cmp.Compare(a.WorkItemID, b.WorkItemID), cmp.Compare(a.Model, b.Model))
+	})
+	return entries, nil
+}
@@ -4,9 +4,10 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -54,6 +55,31 @@ type WorkItem struct {
 	CostUSD      float64 `json:"cost_usd,omitempty"`
 	InputTokens  int     `json:"input_tokens,omitempty"`
 	OutputTokens int     `json:"output_tokens,omitempty"`
+
+	// Per-session container resource usage (sampled while workers run)
+	ContainerCPUSeconds float64 `json:"container_cpu_seconds,omitempty"`
+	PeakMemoryBytes     int64   `json:"peak_memory_bytes,omitempty"`
+	DiskReadBytes       int64   `json:"disk_read_bytes,omitempty"`
+	DiskWriteBytes      int64   `json:"disk_write_bytes,omitempty"`
+
+	// DeadlineAtRisk marks items queued too close to their due date to
+	// plausibly finish in time, given how long past work items took.
+	DeadlineAtRisk bool `json:"deadline_at_risk,omitempty"`
+
+	// WorkflowVersion is the hash of the workflow config the item is running
+	// on (see workflow.HashConfig). Items started before the config last
+	// changed keep the old version until they are migrated.
+	WorkflowVersion string `json:"workflow_version,omitempty"`
+
+	// Workflow is the name of the label-selected workflow the item runs on
+	// (see workflow.NamedWorkflow), chosen when the item is created. Empty
+	// for the repo's main workflow.
+	Workflow string `json:"workflow,omitempty"`
+
+	// Backfilled marks items reconstructed from past PRs by `erg backfill`
+	// rather than processed by the daemon. They are historical records only
+	// and are exempt from PruneTerminalItems.
+	Backfilled bool `json:"backfilled,omitempty"`
 }
 
 // ConsumesSlot returns true if the work item currently consumes a concurrency slot.
@@ -73,7 +99,52 @@ func (item *WorkItem) IsTerminal() bool {
 	return item.State == WorkItemCompleted || item.State == WorkItemFailed
 }
 
+// snapshot returns a deep copy of the item that shares no maps, slices or
+// pointers with it, so it stays valid after the state lock is released.
+func (item *WorkItem) snapshot() WorkItem {
+	c := *item
+	c.IssueRef.Labels = slices.Clone(item.IssueRef.Labels)
+	if item.StepData != nil {
+		c.StepData = deepCopyValue(item.StepData).(map[string]any)
+	}
+	if item.CompletedAt != nil {
+		t := *item.CompletedAt
+		c.CompletedAt = &t
+	}
+	return c
+}
+
+// deepCopyValue copies the maps and slices step data is built from.
+// Other values are immutable or copied by assignment.
+func deepCopyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for k, e := range v {
+			c[k] = deepCopyValue(e)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, e := range v {
+			c[i] = deepCopyValue(e)
+		}
+		return c
+	case []string:
+		return slices.Clone(v)
+	case map[string]string:
+		return maps.Clone(v)
+	default:
+		return v
+	}
+}
+
 // DaemonState holds the persistent state of the daemon.
+//
+// Work items are only read and changed under the state lock: accessors such
+// as GetWorkItem return deep copies, and changes go through UpdateWorkItem
+// and the other mutators. WorkItems is exported for serialization only and
+// must not be touched while other goroutines may use the state.
 type DaemonState struct {
 	Version    int                  `json:"version"`
 	RepoPath   string               `json:"repo_path"`
@@ -81,7 +152,7 @@ type DaemonState struct {
 	LastPollAt time.Time            `json:"last_poll_at"`
 	StartedAt  time.Time            `json:"started_at"`
 
-	// Spend tracking — accumulated since daemon last started (reset on restart)
+	// Spend tracking — accumulated across daemon runs
 	TotalCostUSD      float64 `json:"total_cost_usd"`
 	TotalOutputTokens int     `json:"total_output_tokens"`
 	TotalInputTokens  int     `json:"total_input_tokens"`
@@ -93,13 +164,75 @@ type DaemonState struct {
 	RepoLabels     []string          `json:"repo_labels,omitempty"`
 	RepoPathLabels map[string]string `json:"repo_path_labels,omitempty"`
 
-	mu       sync.RWMutex
-	filePath string
+	// Offline mode: the last successful issue fetch per repo, tracker writes
+	// buffered while the tracker was unreachable, and when it became
+	// unreachable (zero while online).
+	IssueCaches         map[string]IssueCache `json:"issue_caches,omitempty"`
+	Outbox              []PendingOp           `json:"outbox,omitempty"`
+	TrackerOfflineSince *time.Time            `json:"tracker_offline_since,omitempty"`
+
+	// Degradation is the tier the daemon is running in while a dependency
+	// is unhealthy, and DegradedSince when it entered that tier.
+	Degradation   DegradationTier `json:"degradation,omitempty"`
+	DegradedSince *time.Time      `json:"degraded_since,omitempty"`
+
+	// RunMode is whether an operator has paused or is draining the daemon,
+	// and RunModeSince when they did.
+	RunMode      RunMode    `json:"run_mode,omitempty"`
+	RunModeSince *time.Time `json:"run_mode_since,omitempty"`
+
+	// ClaimAliases are claim identities of hosts this state was imported
+	// from; issue claims carrying them belong to this daemon.
+	ClaimAliases []string `json:"claim_aliases,omitempty"`
+
+	// IssueFailures counts failed work items per issue ("source:id") since
+	// the issue last succeeded, and DeadLetters holds the issues that failed
+	// too often to retry automatically.
+	IssueFailures map[string]int        `json:"issue_failures,omitempty"`
+	DeadLetters   map[string]DeadLetter `json:"dead_letters,omitempty"`
+
+	mu           sync.RWMutex
+	filePath     string // legacy JSON state file; the database sits beside it
+	onTransition func(Transition)
+
+	// saveMu serializes Save. saved holds a hash of each work item as last
+	// written to the database (nil until the state has been loaded from or
+	// saved to it) and history the step changes not yet written.
+	saveMu  sync.Mutex
+	saved   map[string][32]byte
+	history map[string][]HistoryEntry
+
+	// spend is the spend ledger of the last spendWindow days, for budget
+	// checks, and spendPending the spend not yet written to the database.
+	spend        map[spendKey]*SpendEntry
+	spendPending map[spendKey]*SpendEntry
+
+	// audit holds the audit log entries not yet written to the database.
+	audit []AuditEntry
+}
+
+// Transition describes a work item moving from one workflow step to another.
+type Transition struct {
+	Item     WorkItem // the item after the move
+	From     string   // "" when the item is starting
+	To       string
+	Duration time.Duration // time spent in From
+}
+
+// SetTransitionHook registers fn to be called after every step change made
+// by AdvanceWorkItem. fn runs without the state lock held, so it may read
+// the state.
+func (s *DaemonState) SetTransitionHook(fn func(Transition)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onTransition = fn
 }
 
 const stateVersion = 2
 
-// StateFilePath returns the path to the daemon state file for a given repo.
+// StateFilePath returns the path to the legacy JSON daemon state file for a
+// given repo. State is now saved to a database beside it (see StateDBPath);
+// a JSON file left by an older erg is read when no database exists yet.
 // Each repo gets its own state file keyed by a hash of the repo path,
 // preventing multiple daemons for different repos from clobbering each other.
 func StateFilePath(repoPath string) string {
@@ -127,22 +260,29 @@ func NewDaemonState(repoPath string) *DaemonState {
 	}
 }
 
-// LoadDaemonState loads daemon state from disk.
-// Returns a new empty state if the file doesn't exist.
+// LoadDaemonState loads daemon state from disk, from the state database or,
+// when there is none yet, a legacy JSON state file.
+// Returns a new empty state if neither exists.
 func LoadDaemonState(repoPath string) (*DaemonState, error) {
 	fp := StateFilePath(repoPath)
 
-	data, err := os.ReadFile(fp)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return NewDaemonState(repoPath), nil
+	var state *DaemonState
+	if _, err := os.Stat(storePath(fp)); err == nil {
+		if state, err = loadStore(storePath(fp)); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := os.ReadFile(fp)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return NewDaemonState(repoPath), nil
+			}
+			return nil, fmt.Errorf("failed to read daemon state: %w", err)
+		}
+		state = &DaemonState{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse daemon state: %w", err)
 		}
-		return nil, fmt.Errorf("failed to read daemon state: %w", err)
-	}
-
-	var state DaemonState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse daemon state: %w", err)
 	}
 
 	state.filePath = fp
@@ -167,44 +307,7 @@ func LoadDaemonState(repoPath string) (*DaemonState, error) {
 		return nil, fmt.Errorf("daemon state repo mismatch: expected %s, got %s", repoPath, state.RepoPath)
 	}
 
-	return &state, nil
-}
-
-// Save persists the daemon state to disk atomically (write temp file, then rename).
-func (s *DaemonState) Save() error {
-	s.mu.RLock()
-	data, err := json.MarshalIndent(s, "", "  ")
-	s.mu.RUnlock()
-	if err != nil {
-		return fmt.Errorf("failed to marshal daemon state: %w", err)
-	}
-
-	dir := filepath.Dir(s.filePath)
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return fmt.Errorf("failed to create state directory: %w", err)
-	}
-	// Tighten permissions on existing directories (MkdirAll ignores mode if dir exists).
-	if runtime.GOOS != "windows" {
-		if err := os.Chmod(dir, 0o700); err != nil {
-			return fmt.Errorf("failed to set state directory permissions: %w", err)
-		}
-	}
-
-	// Atomic write: temp file + rename.
-	// Remove any stale tmp file first — WriteFile does not update permissions on existing files.
-	tmpFile := s.filePath + ".tmp"
-	if err := os.Remove(tmpFile); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove existing temp state file: %w", err)
-	}
-	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write temp state file: %w", err)
-	}
-	if err := os.Rename(tmpFile, s.filePath); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to rename state file: %w", err)
-	}
-
-	return nil
+	return state, nil
 }
 
 // AdvanceWorkItem moves a work item to a new step and phase.
@@ -213,16 +316,19 @@ func (s *DaemonState) Save() error {
 // When only the phase changes (step unchanged), StepDisplayName is preserved.
 func (s *DaemonState) AdvanceWorkItem(id, newStep, newPhase string, displayName ...string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	item, ok := s.WorkItems[id]
 	if !ok {
+		s.mu.Unlock()
 		return fmt.Errorf("work item not found: %s", id)
 	}
 
 	now := time.Now()
 	stepChanged := item.CurrentStep != newStep
+	var transition Transition
 	if stepChanged {
+		transition = Transition{From: item.CurrentStep, To: newStep, Duration: now.Sub(item.StepEnteredAt)}
+		s.recordHistory(id, HistoryEntry{From: item.CurrentStep, To: newStep, At: now})
 		item.StepEnteredAt = now
 	}
 	item.CurrentStep = newStep
@@ -236,6 +342,15 @@ func (s *DaemonState) AdvanceWorkItem(id, newStep, newPhase string, displayName
 	// When the step is unchanged (phase-only reset), preserve existing StepDisplayName.
 	item.UpdatedAt = now
 
+	hook := s.onTransition
+	if stepChanged {
+		transition.Item = item.snapshot()
+	}
+	s.mu.Unlock()
+
+	if stepChanged && hook != nil {
+		hook(transition)
+	}
 	return nil
 }
 
@@ -249,6 +364,9 @@ func (s *DaemonState) MarkWorkItemTerminal(id string, success bool) error {
 		return fmt.Errorf("work item not found: %s", id)
 	}
 
+	if success || item.State != WorkItemFailed {
+		s.countFailure(item.IssueRef, success)
+	}
 	if success {
 		item.State = WorkItemCompleted
 	} else {
@@ -258,11 +376,13 @@ func (s *DaemonState) MarkWorkItemTerminal(id string, success bool) error {
 	now := time.Now()
 	item.CompletedAt = &now
 	item.UpdatedAt = now
+	s.recordHistory(id, HistoryEntry{From: item.CurrentStep, To: string(item.State), At: now})
 
 	return nil
 }
 
-// AddWorkItem adds a new work item in the Queued state.
+// AddWorkItem adds a new work item in the Queued state. The state keeps
+// its own copy of item; the fields it sets are also set on item.
 func (s *DaemonState) AddWorkItem(item *WorkItem) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -276,7 +396,8 @@ func (s *DaemonState) AddWorkItem(item *WorkItem) {
 	if item.StepData == nil {
 		item.StepData = make(map[string]any)
 	}
-	s.WorkItems[item.ID] = item
+	stored := item.snapshot()
+	s.WorkItems[item.ID] = &stored
 }
 
 // GetWorkItem returns a copy of the work item by ID.
@@ -288,7 +409,7 @@ func (s *DaemonState) GetWorkItem(id string) (WorkItem, bool) {
 	if !ok {
 		return WorkItem{}, false
 	}
-	return *item, true
+	return item.snapshot(), true
 }
 
 // GetWorkItemBySessionID returns a copy of the work item associated with the
@@ -302,7 +423,7 @@ func (s *DaemonState) GetWorkItemBySessionID(sessionID string) (WorkItem, bool)
 	defer s.mu.RUnlock()
 	for _, item := range s.WorkItems {
 		if item.SessionID == sessionID {
-			return *item, true
+			return item.snapshot(), true
 		}
 	}
 	return WorkItem{}, false
@@ -316,7 +437,7 @@ func (s *DaemonState) GetWorkItemsByState(state WorkItemState) []WorkItem {
 	var items []WorkItem
 	for _, item := range s.WorkItems {
 		if item.State == state {
-			items = append(items, *item)
+			items = append(items, item.snapshot())
 		}
 	}
 	return items
@@ -330,7 +451,7 @@ func (s *DaemonState) GetActiveWorkItems() []WorkItem {
 	var items []WorkItem
 	for _, item := range s.WorkItems {
 		if !item.IsTerminal() && item.State != WorkItemQueued {
-			items = append(items, *item)
+			items = append(items, item.snapshot())
 		}
 	}
 	return items
@@ -344,7 +465,7 @@ func (s *DaemonState) GetAllWorkItems() []WorkItem {
 
 	items := make([]WorkItem, 0, len(s.WorkItems))
 	for _, item := range s.WorkItems {
-		items = append(items, *item)
+		items = append(items, item.snapshot())
 	}
 	return items
 }
@@ -384,6 +505,7 @@ func (s *DaemonState) HasWorkItemForIssue(issueSource, issueID string) bool {
 
 // UpdateWorkItem applies a mutation function to a work item under the state lock.
 // This is useful for recovery and other cases that need to modify multiple fields atomically.
+// fn must not keep the pointer, or anything reachable from it, after it returns.
 func (s *DaemonState) UpdateWorkItem(id string, fn func(*WorkItem)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -446,9 +568,21 @@ func (s *DaemonState) RecordItemSpend(id string, costUSD float64, outputTokens,
 	}
 }
 
+// RecordItemResources accumulates container CPU time and disk I/O on the named
+// work item and raises its peak memory if peakMemoryBytes is higher.
+// Thread-safe; called from the daemon's resource sampler.
+func (s *DaemonState) RecordItemResources(id string, cpuSeconds float64, peakMemoryBytes, diskReadBytes, diskWriteBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, ok := s.WorkItems[id]; ok {
+		item.ContainerCPUSeconds += cpuSeconds
+		item.PeakMemoryBytes = max(item.PeakMemoryBytes, peakMemoryBytes)
+		item.DiskReadBytes += diskReadBytes
+		item.DiskWriteBytes += diskWriteBytes
+	}
+}
+
 // ResetSpend zeroes the accumulated spend counters.
-// Called when the daemon starts to ensure cost tracking reflects only the
-// current daemon run, not previous runs.
 func (s *DaemonState) ResetSpend() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -519,14 +653,19 @@ func StateExists() bool {
 		home, _ := os.UserHomeDir()
 		dir = filepath.Join(home, ".erg")
 	}
-	// Check for both legacy and per-repo state files
-	matches, _ := filepath.Glob(filepath.Join(dir, "daemon-state*.json"))
-	return len(matches) > 0
+	// Check for state databases and legacy JSON state files
+	for _, pattern := range []string{"daemon-state*.db", "daemon-state*.json"} {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // PruneTerminalItems removes completed and failed work items that finished
 // more than maxAge ago. This prevents unbounded growth of the WorkItems map
-// in long-running daemons. Returns the number of items pruned.
+// in long-running daemons. Backfilled items and the items of dead-lettered
+// issues are kept. Returns the number of items pruned.
 func (s *DaemonState) PruneTerminalItems(maxAge time.Duration) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -534,9 +673,12 @@ func (s *DaemonState) PruneTerminalItems(maxAge time.Duration) int {
 	now := time.Now()
 	pruned := 0
 	for id, item := range s.WorkItems {
-		if !item.IsTerminal() {
+		if !item.IsTerminal() || item.Backfilled {
 			continue
 		}
+		if dl, ok := s.DeadLetters[issueKey(item.IssueRef.Source, item.IssueRef.ID)]; ok && dl.WorkItemID == id {
+			continue // kept so `erg dlq retry` can restart it
+		}
 		completedAt := item.CompletedAt
 		if completedAt == nil {
 			// Terminal item without CompletedAt — use UpdatedAt as fallback.
@@ -568,6 +710,7 @@ func (s *DaemonState) ClearNonTerminalItems() {
 // AddRebuiltWorkItem adds a work item that was rebuilt from the issue tracker.
 // Unlike AddWorkItem, this allows setting State and CurrentStep directly
 // (not forced to Queued), and does not overwrite fields already set by the caller.
+// Like AddWorkItem, the state keeps its own copy of item.
 func (s *DaemonState) AddRebuiltWorkItem(item *WorkItem) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -583,7 +726,8 @@ func (s *DaemonState) AddRebuiltWorkItem(item *WorkItem) {
 	if item.StepData == nil {
 		item.StepData = make(map[string]any)
 	}
-	s.WorkItems[item.ID] = item
+	stored := item.snapshot()
+	s.WorkItems[item.ID] = &stored
 }
 
 // ClearState removes all daemon state files from disk.
@@ -594,10 +738,12 @@ func ClearState() error {
 		home, _ := os.UserHomeDir()
 		dir = filepath.Join(home, ".erg")
 	}
-	matches, _ := filepath.Glob(filepath.Join(dir, "daemon-state*.json"))
-	for _, match := range matches {
-		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to remove daemon state %s: %w", match, err)
+	for _, pattern := range []string{"daemon-state*.db", "daemon-state*.db-wal", "daemon-state*.db-shm", "daemon-state*.json"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove daemon state %s: %w", match, err)
+			}
 		}
 	}
 	return nil
@@ -178,6 +178,39 @@ func TestDaemonState_AdvanceWorkItem_DisplayName(t *testing.T) {
 	}
 }
 
+func TestDaemonState_TransitionHook(t *testing.T) {
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "1"},
+	})
+	var got []Transition
+	state.SetTransitionHook(func(tr Transition) {
+		// The hook runs without the lock held, so reading state is safe.
+		if _, ok := state.GetWorkItem(tr.Item.ID); !ok {
+			t.Error("expected to read the item from the hook")
+		}
+		got = append(got, tr)
+	})
+
+	state.AdvanceWorkItem("item-1", "coding", "idle")
+	state.AdvanceWorkItem("item-1", "coding", "async_pending") // phase only
+	state.AdvanceWorkItem("item-1", "open_pr", "idle")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 transitions, got %+v", got)
+	}
+	if got[0].From != "" || got[0].To != "coding" {
+		t.Errorf("unexpected first transition %+v", got[0])
+	}
+	if got[1].From != "coding" || got[1].To != "open_pr" || got[1].Item.CurrentStep != "open_pr" {
+		t.Errorf("unexpected second transition %+v", got[1])
+	}
+	if got[1].Duration < 0 {
+		t.Errorf("expected non-negative duration, got %s", got[1].Duration)
+	}
+}
+
 func TestDaemonState_MarkWorkItemTerminal(t *testing.T) {
 	state := NewDaemonState("/test/repo")
 	state.AddWorkItem(&WorkItem{
@@ -540,20 +573,15 @@ func TestDaemonState_SaveAndLoad(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	// Verify file exists
-	if _, err := os.Stat(state.filePath); err != nil {
-		t.Fatalf("state file not created: %v", err)
+	// Verify database exists
+	if _, err := os.Stat(storePath(state.filePath)); err != nil {
+		t.Fatalf("state database not created: %v", err)
 	}
 
 	// Load back
-	data, err := os.ReadFile(state.filePath)
+	loaded, err := loadStore(storePath(state.filePath))
 	if err != nil {
-		t.Fatalf("failed to read state file: %v", err)
-	}
-
-	var loaded DaemonState
-	if err := json.Unmarshal(data, &loaded); err != nil {
-		t.Fatalf("failed to unmarshal: %v", err)
+		t.Fatalf("failed to load state: %v", err)
 	}
 
 	if loaded.Version != stateVersion {
@@ -672,8 +700,8 @@ func TestDaemonState_SavePermissions(t *testing.T) {
 		t.Errorf("state directory permissions = %04o, want 0700", perm)
 	}
 
-	// State file must be owner-only (0600)
-	fileInfo, err := os.Stat(fp)
+	// State database must be owner-only (0600)
+	fileInfo, err := os.Stat(storePath(fp))
 	if err != nil {
 		t.Fatalf("failed to stat state file: %v", err)
 	}
@@ -682,7 +710,7 @@ func TestDaemonState_SavePermissions(t *testing.T) {
 	}
 }
 
-func TestDaemonState_SaveAtomicity(t *testing.T) {
+func TestDaemonState_SaveTwice(t *testing.T) {
 	tmpDir := t.TempDir()
 	fp := filepath.Join(tmpDir, "daemon-state.json")
 
@@ -694,7 +722,7 @@ func TestDaemonState_SaveAtomicity(t *testing.T) {
 		filePath:  fp,
 	}
 
-	// Save twice to verify atomic rename works
+	// Save twice to verify the second save adds to the first
 	state.AddWorkItem(&WorkItem{
 		ID:       "item-1",
 		IssueRef: config.IssueRef{Source: "github", ID: "1"},
@@ -711,16 +739,11 @@ func TestDaemonState_SaveAtomicity(t *testing.T) {
 		t.Fatalf("second Save failed: %v", err)
 	}
 
-	// Verify temp file was cleaned up
-	tmpFile := fp + ".tmp"
-	if _, err := os.Stat(tmpFile); !os.IsNotExist(err) {
-		t.Error("expected temp file to be cleaned up after rename")
-	}
-
 	// Verify content has both items
-	data, _ := os.ReadFile(fp)
-	var loaded DaemonState
-	json.Unmarshal(data, &loaded)
+	loaded, err := loadStore(storePath(fp))
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
 	if len(loaded.WorkItems) != 2 {
 		t.Errorf("expected 2 work items, got %d", len(loaded.WorkItems))
 	}
@@ -779,13 +802,9 @@ func TestDaemonState_Spend(t *testing.T) {
 			t.Fatalf("Save failed: %v", err)
 		}
 
-		data, err := os.ReadFile(fp)
+		loaded, err := loadStore(storePath(fp))
 		if err != nil {
-			t.Fatalf("failed to read state file: %v", err)
-		}
-		var loaded DaemonState
-		if err := json.Unmarshal(data, &loaded); err != nil {
-			t.Fatalf("failed to unmarshal: %v", err)
+			t.Fatalf("failed to load state: %v", err)
 		}
 		if loaded.TotalCostUSD != 0.1234 {
 			t.Errorf("expected TotalCostUSD 0.1234, got %v", loaded.TotalCostUSD)
@@ -871,13 +890,9 @@ func TestDaemonState_RecordItemSpend(t *testing.T) {
 			t.Fatalf("Save failed: %v", err)
 		}
 
-		data, err := os.ReadFile(fp)
+		loaded, err := loadStore(storePath(fp))
 		if err != nil {
-			t.Fatalf("failed to read state file: %v", err)
-		}
-		var loaded DaemonState
-		if err := json.Unmarshal(data, &loaded); err != nil {
-			t.Fatalf("failed to unmarshal: %v", err)
+			t.Fatalf("failed to load state: %v", err)
 		}
 
 		loadedItem, ok := loaded.WorkItems["item-persist"]
@@ -896,6 +911,26 @@ func TestDaemonState_RecordItemSpend(t *testing.T) {
 	})
 }
 
+func TestDaemonState_RecordItemResources(t *testing.T) {
+	s := NewDaemonState("/test/repo")
+	s.AddWorkItem(&WorkItem{ID: "item-1"})
+
+	s.RecordItemResources("item-1", 1.5, 200, 10, 20)
+	s.RecordItemResources("item-1", 2.0, 100, 5, 0)
+	s.RecordItemResources("does-not-exist", 1, 1, 1, 1) // no-op
+
+	item, _ := s.GetWorkItem("item-1")
+	if item.ContainerCPUSeconds != 3.5 {
+		t.Errorf("ContainerCPUSeconds = %v, want 3.5", item.ContainerCPUSeconds)
+	}
+	if item.PeakMemoryBytes != 200 {
+		t.Errorf("PeakMemoryBytes = %d, want the highest sample (200)", item.PeakMemoryBytes)
+	}
+	if item.DiskReadBytes != 15 || item.DiskWriteBytes != 20 {
+		t.Errorf("disk I/O = %d/%d, want 15/20", item.DiskReadBytes, item.DiskWriteBytes)
+	}
+}
+
 func TestDaemonState_SetLastPollAt(t *testing.T) {
 	s := NewDaemonState("/test/repo")
 
@@ -963,15 +998,15 @@ func TestClearState(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	if _, err := os.Stat(fp); err != nil {
-		t.Fatalf("expected state file to exist: %v", err)
+	if _, err := os.Stat(storePath(fp)); err != nil {
+		t.Fatalf("expected state database to exist: %v", err)
 	}
 
-	if err := os.Remove(fp); err != nil {
-		t.Fatalf("failed to remove state file: %v", err)
+	if err := os.Remove(storePath(fp)); err != nil {
+		t.Fatalf("failed to remove state database: %v", err)
 	}
 
-	if _, err := os.Stat(fp); !os.IsNotExist(err) {
+	if _, err := os.Stat(storePath(fp)); !os.IsNotExist(err) {
 		t.Error("expected state file to be removed")
 	}
 }
@@ -1002,6 +1037,55 @@ func TestGetWorkItem_ReturnsCopy(t *testing.T) {
 	}
 }
 
+func TestGetWorkItem_DeepCopy(t *testing.T) {
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{
+		ID:       "item-1",
+		IssueRef: config.IssueRef{Source: "github", ID: "1", Labels: []string{"bug"}},
+		StepData: map[string]any{"nested": map[string]any{"k": "v"}, "list": []any{"a"}},
+	})
+	state.MarkWorkItemTerminal("item-1", true)
+
+	// Nothing reachable from a snapshot may alias the stored item.
+	snap, _ := state.GetWorkItem("item-1")
+	snap.IssueRef.Labels[0] = "mutated"
+	snap.StepData["added"] = true
+	snap.StepData["nested"].(map[string]any)["k"] = "mutated"
+	snap.StepData["list"].([]any)[0] = "mutated"
+	*snap.CompletedAt = time.Time{}
+
+	got, _ := state.GetWorkItem("item-1")
+	if got.IssueRef.Labels[0] != "bug" {
+		t.Error("labels slice aliased the stored item")
+	}
+	if _, ok := got.StepData["added"]; ok {
+		t.Error("step data map aliased the stored item")
+	}
+	if got.StepData["nested"].(map[string]any)["k"] != "v" || got.StepData["list"].([]any)[0] != "a" {
+		t.Error("nested step data aliased the stored item")
+	}
+	if got.CompletedAt.IsZero() {
+		t.Error("CompletedAt pointer aliased the stored item")
+	}
+}
+
+func TestAddWorkItem_KeepsOwnCopy(t *testing.T) {
+	state := NewDaemonState("/test/repo")
+	item := &WorkItem{ID: "item-1", StepData: map[string]any{"k": "v"}}
+	state.AddWorkItem(item)
+
+	if item.State != WorkItemQueued || item.CreatedAt.IsZero() {
+		t.Errorf("expected AddWorkItem to set fields on the caller's item, got %+v", item)
+	}
+	item.Phase = "mutated"
+	item.StepData["k"] = "mutated"
+
+	got, _ := state.GetWorkItem("item-1")
+	if got.Phase != "idle" || got.StepData["k"] != "v" {
+		t.Errorf("caller's pointer aliased the stored item: %+v", got)
+	}
+}
+
 func TestGetActiveWorkItems_ReturnsCopies(t *testing.T) {
 	state := NewDaemonState("/test/repo")
 	state.AddWorkItem(&WorkItem{
@@ -1187,6 +1271,22 @@ func TestPruneTerminalItems_NoCompletedAt(t *testing.T) {
 	}
 }
 
+func TestPruneTerminalItems_KeepsBackfilled(t *testing.T) {
+	state := NewDaemonState("/test/repo")
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	state.AddRebuiltWorkItem(&WorkItem{
+		ID:          "backfilled",
+		State:       WorkItemCompleted,
+		CompletedAt: &old,
+		Backfilled:  true,
+	})
+
+	if pruned := state.PruneTerminalItems(7 * 24 * time.Hour); pruned != 0 {
+		t.Errorf("expected backfilled item to be kept, got %d pruned", pruned)
+	}
+}
+
 func TestPruneTerminalItems_EmptyState(t *testing.T) {
 	state := NewDaemonState("/test/repo")
 	pruned := state.PruneTerminalItems(7 * 24 * time.Hour)
@@ -1221,7 +1321,7 @@ func TestSetRepoLabels(t *testing.T) {
 	}
 }
 
-func TestSetRepoLabels_JSONRoundTrip(t *testing.T) {
+func TestSetRepoLabels_RoundTrip(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	state := &DaemonState{
@@ -1243,14 +1343,9 @@ func TestSetRepoLabels_JSONRoundTrip(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	data, err := os.ReadFile(state.filePath)
+	loaded, err := loadStore(storePath(state.filePath))
 	if err != nil {
-		t.Fatalf("failed to read state file: %v", err)
-	}
-
-	var loaded DaemonState
-	if err := json.Unmarshal(data, &loaded); err != nil {
-		t.Fatalf("failed to unmarshal: %v", err)
+		t.Fatalf("failed to load state: %v", err)
 	}
 
 	if len(loaded.RepoLabels) != 2 {
@@ -0,0 +1,557 @@
+package daemonstate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// The daemon state lives in a SQLite database next to where the JSON state
+// file used to be. Work items are kept one row each so a save only writes
+// the items that changed, and every step change is appended to a per-item
+// history. Spend is kept per day, repo, work item and model in its own table, so
+// it outlives the items it was spent on, as does the append-only audit log.
+// The rest of the state (spend totals, outbox, issue caches, ...) is stored
+// as a single JSON document in the meta table.
+
+// storeMigrations are the schema changes of the state database, in order.
+// A database's PRAGMA user_version is the number of them applied; append to
+// the list to change the schema and never edit an entry once released.
+var storeMigrations = []string{
+	`CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	CREATE TABLE work_items (
+		id    TEXT PRIMARY KEY,
+		state TEXT NOT NULL,
+		data  TEXT NOT NULL
+	);
+	CREATE TABLE item_history (
+		seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+		work_item_id TEXT NOT NULL,
+		from_step    TEXT NOT NULL,
+		to_step      TEXT NOT NULL,
+		at           TEXT NOT NULL
+	);
+	CREATE INDEX item_history_work_item ON item_history (work_item_id, seq);`,
+	`CREATE TABLE spend (
+		day           TEXT NOT NULL,
+		repo          TEXT NOT NULL,
+		work_item_id  TEXT NOT NULL,
+		issue_id      TEXT NOT NULL,
+		cost_usd      REAL NOT NULL,
+		input_tokens  INTEGER NOT NULL,
+		output_tokens INTEGER NOT NULL,
+		PRIMARY KEY (day, repo, work_item_id)
+	);`,
+	`CREATE TABLE audit_log (
+		seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+		work_item_id TEXT NOT NULL,
+		issue_id     TEXT NOT NULL,
+		at           TEXT NOT NULL,
+		kind         TEXT NOT NULL,
+		summary      TEXT NOT NULL,
+		details      TEXT NOT NULL
+	);
+	CREATE INDEX audit_log_work_item ON audit_log (work_item_id, seq);
+	CREATE INDEX audit_log_issue ON audit_log (issue_id, seq);`,
+	`CREATE TABLE spend_by_model (
+		day           TEXT NOT NULL,
+		repo          TEXT NOT NULL,
+		work_item_id  TEXT NOT NULL,
+		model         TEXT NOT NULL,
+		issue_id      TEXT NOT NULL,
+		cost_usd      REAL NOT NULL,
+		input_tokens  INTEGER NOT NULL,
+		output_tokens INTEGER NOT NULL,
+		PRIMARY KEY (day, repo, work_item_id, model)
+	);
+	INSERT INTO spend_by_model
+		SELECT day, repo, work_item_id, '', issue_id, cost_usd, input_tokens, output_tokens FROM spend;
+	DROP TABLE spend;
+	ALTER TABLE spend_by_model RENAME TO spend;`,
+}
+
+// metaStateKey is the meta row holding the state's non-work-item fields.
+const metaStateKey = "state"
+
+// HistoryEntry is one step change in a work item's history. Items reaching
+// a terminal state get an entry whose To is "completed" or "failed".
+type HistoryEntry struct {
+	From string
+	To   string
+	At   time.Time
+}
+
+// StateDBPath returns the path of the state database for a given repo.
+func StateDBPath(repoPath string) string {
+	return storePath(StateFilePath(repoPath))
+}
+
+// storePath returns the database path that replaces the JSON state file at
+// filePath.
+func storePath(filePath string) string {
+	return strings.TrimSuffix(filePath, ".json") + ".db"
+}
+
+// HasState reports whether state has been saved for the given repo, in
+// either the database or a legacy JSON file.
+func HasState(repoPath string) bool {
+	for _, p := range []string{StateDBPath(repoPath), StateFilePath(repoPath)} {
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// openStore opens the state database at path, creating it owner-only if
+// needed, and brings its schema up to date.
+func openStore(path string) (*sql.DB, error) {
+	if err := ensureStateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	// Create the file ourselves so it is owner-only; SQLite gives its -wal
+	// and -shm files the same mode.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state database: %w", err)
+	}
+	f.Close()
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to set state database permissions: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrateStore applies the migrations db has not seen yet, each in its own
+// transaction together with the user_version bump.
+func migrateStore(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read state database version: %w", err)
+	}
+	if version > len(storeMigrations) {
+		return fmt.Errorf("state database schema version %d is newer than this erg supports (%d)", version, len(storeMigrations))
+	}
+	for i := version; i < len(storeMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to migrate state database: %w", err)
+		}
+		if _, err := tx.Exec(storeMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to migrate state database to version %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to migrate state database to version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to migrate state database to version %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ensureStateDir creates the state directory owner-only, tightening the
+// permissions of an existing one (MkdirAll ignores mode if dir exists).
+func ensureStateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to set state directory permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadStore reads the state saved in the database at path.
+func loadStore(path string) (*DaemonState, error) {
+	db, err := openStore(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var state DaemonState
+	var meta string
+	switch err := db.QueryRow(`SELECT value FROM meta WHERE key = ?`, metaStateKey).Scan(&meta); {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read daemon state: %w", err)
+	default:
+		if err := json.Unmarshal([]byte(meta), &state); err != nil {
+			return nil, fmt.Errorf("failed to parse daemon state: %w", err)
+		}
+	}
+
+	rows, err := db.Query(`SELECT id, data FROM work_items`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read work items: %w", err)
+	}
+	defer rows.Close()
+	state.WorkItems = make(map[string]*WorkItem)
+	state.saved = make(map[string][32]byte)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to read work items: %w", err)
+		}
+		var item WorkItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("failed to parse work item %s: %w", id, err)
+		}
+		state.WorkItems[id] = &item
+		state.saved[id] = sha256.Sum256(data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read work items: %w", err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -spendWindow).Format(SpendDayFormat)
+	spendRows, err := db.Query(`SELECT day, repo, work_item_id, model, issue_id, cost_usd, input_tokens, output_tokens
+		FROM spend WHERE day >= ?`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spend: %w", err)
+	}
+	defer spendRows.Close()
+	state.spend = make(map[spendKey]*SpendEntry)
+	for spendRows.Next() {
+		var e SpendEntry
+		if err := spendRows.Scan(&e.Day, &e.RepoPath, &e.WorkItemID, &e.Model, &e.IssueID, &e.CostUSD, &e.InputTokens, &e.OutputTokens); err != nil {
+			return nil, fmt.Errorf("failed to read spend: %w", err)
+		}
+		state.spend[e.key()] = &e
+	}
+	if err := spendRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spend: %w", err)
+	}
+	return &state, nil
+}
+
+// itemRow is a work item as written to the work_items table.
+type itemRow struct {
+	id, state string
+	data      []byte
+}
+
+// storeWriter is the function Save writes with. Overridden in tests.
+var storeWriter = writeStore
+
+// writeStore writes a save to the database at path in one transaction:
+// the meta document, the changed work items, the removal of deleted ones
+// (or of every stored item not in changed when replace is set) and new
+// history entries, spend and audit entries.
+func writeStore(path string, meta []byte, changed []itemRow, deleted []string, replace bool, history map[string][]HistoryEntry, spend map[spendKey]*SpendEntry, audit []AuditEntry) error {
+	db, err := openStore(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin state transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, metaStateKey, string(meta)); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	if replace {
+		if _, err := tx.Exec(`DELETE FROM work_items`); err != nil {
+			return fmt.Errorf("failed to clear work items: %w", err)
+		}
+	}
+	for _, row := range changed {
+		if _, err := tx.Exec(`INSERT INTO work_items (id, state, data) VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET state = excluded.state, data = excluded.data`,
+			row.id, row.state, string(row.data)); err != nil {
+			return fmt.Errorf("failed to write work item %s: %w", row.id, err)
+		}
+	}
+	for _, id := range deleted {
+		if _, err := tx.Exec(`DELETE FROM work_items WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete work item %s: %w", id, err)
+		}
+	}
+	for id, entries := range history {
+		for _, e := range entries {
+			if _, err := tx.Exec(`INSERT INTO item_history (work_item_id, from_step, to_step, at) VALUES (?, ?, ?, ?)`,
+				id, e.From, e.To, e.At.UTC().Format(time.RFC3339Nano)); err != nil {
+				return fmt.Errorf("failed to write history of work item %s: %w", id, err)
+			}
+		}
+	}
+	for _, e := range spend {
+		if _, err := tx.Exec(`INSERT INTO spend (day, repo, work_item_id, model, issue_id, cost_usd, input_tokens, output_tokens)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (day, repo, work_item_id, model) DO UPDATE SET
+				issue_id = excluded.issue_id,
+				cost_usd = cost_usd + excluded.cost_usd,
+				input_tokens = input_tokens + excluded.input_tokens,
+				output_tokens = output_tokens + excluded.output_tokens`,
+			e.Day, e.RepoPath, e.WorkItemID, e.Model, e.IssueID, e.CostUSD, e.InputTokens, e.OutputTokens); err != nil {
+			return fmt.Errorf("failed to write spend of work item %s: %w", e.WorkItemID, err)
+		}
+	}
+	if err := writeAudit(tx, audit); err != nil {
+		return err
+	}
+	// History goes with its item.
+	if replace || len(deleted) > 0 {
+		if _, err := tx.Exec(`DELETE FROM item_history WHERE work_item_id NOT IN (SELECT id FROM work_items)`); err != nil {
+			return fmt.Errorf("failed to prune work item history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit daemon state: %w", err)
+	}
+	return nil
+}
+
+// Save persists the daemon state to its database in a single transaction.
+// Only work items that changed since the state was loaded or last saved
+// are written. A legacy JSON state file is removed once its contents have
+// been saved to the database.
+func (s *DaemonState) Save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	items := s.WorkItems
+	s.WorkItems = nil // stored as rows, not in the meta document
+	meta, err := json.Marshal(s)
+	s.WorkItems = items
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to marshal daemon state: %w", err)
+	}
+	replace := s.saved == nil
+	sums := make(map[string][32]byte, len(items))
+	var changed []itemRow
+	for id, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to marshal work item %s: %w", id, err)
+		}
+		sum := sha256.Sum256(data)
+		sums[id] = sum
+		if prev, ok := s.saved[id]; !ok || prev != sum {
+			changed = append(changed, itemRow{id: id, state: string(item.State), data: data})
+		}
+	}
+	var deleted []string
+	for id := range s.saved {
+		if _, ok := items[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	history := s.history
+	s.history = nil
+	spend := s.spendPending
+	s.spendPending = nil
+	audit := s.audit
+	s.audit = nil
+	s.mu.Unlock()
+
+	if err := storeWriter(storePath(s.filePath), meta, changed, deleted, replace, history, spend, audit); err != nil {
+		// Keep the unsaved history, spend and audit entries for the next
+		// attempt, along with any recorded while the write ran.
+		s.mu.Lock()
+		s.audit = append(audit, s.audit...)
+		if history == nil {
+			history = make(map[string][]HistoryEntry)
+		}
+		for id, entries := range s.history {
+			history[id] = append(history[id], entries...)
+		}
+		s.history = history
+		for k, e := range s.spendPending {
+			if cur, ok := spend[k]; ok {
+				cur.add(*e)
+			} else {
+				if spend == nil {
+					spend = make(map[spendKey]*SpendEntry)
+				}
+				spend[k] = e
+			}
+		}
+		s.spendPending = spend
+		s.mu.Unlock()
+		return err
+	}
+	s.saved = sums
+
+	if err := os.Remove(s.filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove legacy state file: %w", err)
+	}
+	return nil
+}
+
+// recordHistory queues a history entry for the next Save. The caller must
+// hold s.mu.
+func (s *DaemonState) recordHistory(id string, e HistoryEntry) {
+	if s.history == nil {
+		s.history = make(map[string][]HistoryEntry)
+	}
+	s.history[id] = append(s.history[id], e)
+	s.recordAudit(AuditEntry{WorkItemID: id, At: e.At, Kind: AuditTransition, Summary: historySummary(e),
+		Details: map[string]any{"from": e.From, "to": e.To}})
+}
+
+// historySummary describes a step change for the audit log.
+func historySummary(e HistoryEntry) string {
+	if e.From == "" {
+		return "started at " + e.To
+	}
+	return e.From + " → " + e.To
+}
+
+// ItemHistory returns the step changes of a work item, oldest first,
+// including those not saved yet.
+func (s *DaemonState) ItemHistory(id string) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	path := storePath(s.filePath)
+	if _, err := os.Stat(path); err == nil {
+		db, err := openStore(path)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		rows, err := db.Query(`SELECT from_step, to_step, at FROM item_history WHERE work_item_id = ? ORDER BY seq`, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read work item history: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e HistoryEntry
+			var at string
+			if err := rows.Scan(&e.From, &e.To, &at); err != nil {
+				return nil, fmt.Errorf("failed to read work item history: %w", err)
+			}
+			e.At, _ = time.Parse(time.RFC3339Nano, at)
+			entries = append(entries, e)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read work item history: %w", err)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(entries, s.history[id]...), nil
+}
+
+// ItemHistoryEntry is a HistoryEntry together with its work item.
+type ItemHistoryEntry struct {
+	WorkItemID string
+	HistoryEntry
+}
+
+// RecentHistory returns the last n step changes of all work items, newest
+// first, including those not saved yet.
+func (s *DaemonState) RecentHistory(n int) ([]ItemHistoryEntry, error) {
+	var entries []ItemHistoryEntry
+	path := storePath(s.filePath)
+	if _, err := os.Stat(path); err == nil {
+		db, err := openStore(path)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		rows, err := db.Query(`SELECT work_item_id, from_step, to_step, at FROM item_history ORDER BY seq DESC LIMIT ?`, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read work item history: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e ItemHistoryEntry
+			var at string
+			if err := rows.Scan(&e.WorkItemID, &e.From, &e.To, &at); err != nil {
+				return nil, fmt.Errorf("failed to read work item history: %w", err)
+			}
+			e.At, _ = time.Parse(time.RFC3339Nano, at)
+			entries = append(entries, e)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read work item history: %w", err)
+		}
+	}
+
+	s.mu.RLock()
+	for id, pending := range s.history {
+		for _, e := range pending {
+			entries = append(entries, ItemHistoryEntry{WorkItemID: id, HistoryEntry: e})
+		}
+	}
+	s.mu.RUnlock()
+	slices.SortStableFunc(entries, func(a, b ItemHistoryEntry) int { return b.At.Compare(a.At) })
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
+
+// ReadStateRepoPath returns the repo key (repo path or multi-repo daemon
+// ID) recorded in a daemon state file, which may be a state database or a
+// legacy JSON file. The legacy single-repo state file has an empty key.
+func ReadStateRepoPath(path string) (string, error) {
+	var data []byte
+	if strings.HasSuffix(path, ".db") {
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		db, err := openStore(path)
+		if err != nil {
+			return "", err
+		}
+		defer db.Close()
+		var meta string
+		if err := db.QueryRow(`SELECT value FROM meta WHERE key = ?`, metaStateKey).Scan(&meta); err != nil {
+			return "", fmt.Errorf("failed to read daemon state %s: %w", path, err)
+		}
+		data = []byte(meta)
+	} else {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return "", err
+		}
+	}
+	var partial struct {
+		RepoPath string `json:"repo_path"`
+	}
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return "", fmt.Errorf("failed to parse daemon state %s: %w", path, err)
+	}
+	return partial.RepoPath, nil
+}
@@ -0,0 +1,399 @@
+package daemonstate
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/paths"
+)
+
+func TestLoadDaemonState_ImportsLegacyJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	legacy := NewDaemonState("/test/repo")
+	legacy.AddWorkItem(&WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "github", ID: "1"}, FeedbackRounds: 2})
+	legacy.AddSpend(1.25, 10, 20)
+	if err := os.MkdirAll(filepath.Dir(StateFilePath("/test/repo")), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(StateFilePath("/test/repo"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatalf("LoadDaemonState: %v", err)
+	}
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(StateFilePath("/test/repo")); !os.IsNotExist(err) {
+		t.Error("expected legacy JSON file to be removed once imported")
+	}
+
+	reloaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	item, ok := reloaded.GetWorkItem("item-1")
+	if !ok || item.FeedbackRounds != 2 {
+		t.Errorf("imported item = %+v, %v", item, ok)
+	}
+	if cost, out, in := reloaded.GetSpend(); cost != 1.25 || out != 10 || in != 20 {
+		t.Errorf("spend = %v/%d/%d, want 1.25/10/20", cost, out, in)
+	}
+}
+
+func TestDaemonState_SaveWritesChangesOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	state := NewDaemonState("/test/repo")
+	for _, id := range []string{"a", "b", "c"} {
+		state.AddWorkItem(&WorkItem{ID: id})
+	}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.UpdateWorkItem("a", func(it *WorkItem) { it.FeedbackRounds = 3 })
+	state.mu.Lock()
+	delete(state.WorkItems, "b")
+	state.mu.Unlock()
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, it := range reloaded.GetAllWorkItems() {
+		ids = append(ids, it.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"a", "c"}) {
+		t.Errorf("items = %v, want [a c]", ids)
+	}
+	if a, _ := reloaded.GetWorkItem("a"); a.FeedbackRounds != 3 {
+		t.Errorf("FeedbackRounds = %d, want 3", a.FeedbackRounds)
+	}
+}
+
+func TestDaemonState_NewStateReplacesSaved(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	old := NewDaemonState("/test/repo")
+	old.AddWorkItem(&WorkItem{ID: "old"})
+	if err := old.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := NewDaemonState("/test/repo")
+	fresh.AddWorkItem(&WorkItem{ID: "new"})
+	if err := fresh.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := loaded.GetAllWorkItems(); len(items) != 1 || items[0].ID != "new" {
+		t.Errorf("items = %+v, want only the new one", items)
+	}
+}
+
+func TestDaemonState_RecentHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{ID: "item-1"})
+	state.AddWorkItem(&WorkItem{ID: "item-2"})
+	state.AdvanceWorkItem("item-1", "coding", "async_pending")
+	state.AdvanceWorkItem("item-2", "coding", "async_pending")
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	state.AdvanceWorkItem("item-1", "open_pr", "idle")
+
+	recent, err := state.RecentHistory(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range recent {
+		got = append(got, e.WorkItemID+":"+e.From+">"+e.To)
+	}
+	want := []string{"item-1:coding>open_pr", "item-2:>coding"}
+	if !slices.Equal(got, want) {
+		t.Errorf("recent history = %v, want %v", got, want)
+	}
+}
+
+func TestDaemonState_ItemHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{ID: "item-1"})
+	state.AdvanceWorkItem("item-1", "coding", "async_pending")
+	state.AdvanceWorkItem("item-1", "coding", "idle") // phase only: no entry
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	state.AdvanceWorkItem("item-1", "open_pr", "idle")
+	state.MarkWorkItemTerminal("item-1", true)
+
+	history, err := state.ItemHistory("item-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range history {
+		got = append(got, e.From+">"+e.To)
+	}
+	want := []string{">coding", "coding>open_pr", "open_pr>completed"}
+	if !slices.Equal(got, want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := reloaded.ItemHistory("item-1"); len(history) != 3 || history[2].At.IsZero() {
+		t.Errorf("reloaded history = %+v", history)
+	}
+
+	// History goes with a pruned item.
+	reloaded.UpdateWorkItem("item-1", func(it *WorkItem) {
+		past := time.Now().Add(-48 * time.Hour)
+		it.CompletedAt = &past
+	})
+	reloaded.PruneTerminalItems(time.Hour)
+	if err := reloaded.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := reloaded.ItemHistory("item-1"); len(history) != 0 {
+		t.Errorf("history of pruned item = %+v", history)
+	}
+}
+
+func TestDaemonState_SpendLedger(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	today := SpendDay(time.Now())
+	old := time.Now().AddDate(0, 0, -30).Format(SpendDayFormat)
+	state := NewDaemonState("/test/repo")
+	state.RecordSpendEntry(SpendEntry{Day: old, RepoPath: "/a", WorkItemID: "1", IssueID: "1", CostUSD: 5})
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/a", WorkItemID: "1", IssueID: "1", CostUSD: 1, InputTokens: 100})
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// Spend recorded after a save adds to the saved row.
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/a", WorkItemID: "1", IssueID: "1", CostUSD: 2, OutputTokens: 50})
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/b", WorkItemID: "2", IssueID: "2", CostUSD: 4})
+
+	if cost, tokens := state.SpendSince("/a", today); cost != 3 || tokens != 150 {
+		t.Errorf("SpendSince(/a) = %v, %d; want 3, 150", cost, tokens)
+	}
+	if cost, _ := state.SpendSince("", today); cost != 7 {
+		t.Errorf("SpendSince(all) = %v, want 7", cost)
+	}
+	ledger, err := state.SpendLedger(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != 2 || ledger[0].CostUSD != 3 || ledger[0].Tokens() != 150 || ledger[1].RepoPath != "/b" {
+		t.Errorf("ledger = %+v", ledger)
+	}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadDaemonState("/test/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, _ := reloaded.SpendSince("", old); cost != 7 {
+		t.Errorf("reloaded recent spend = %v, want 7 (old days are not kept in memory)", cost)
+	}
+	all, err := reloaded.SpendLedger(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Day != old || all[1].CostUSD != 3 {
+		t.Errorf("reloaded ledger = %+v", all)
+	}
+}
+
+func TestDaemonState_SpendLedgerByModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	today := SpendDay(time.Now())
+	state := NewDaemonState("/test/repo")
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/a", WorkItemID: "1", Model: "claude-opus-4-1", CostUSD: 1})
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/a", WorkItemID: "1", Model: "claude-sonnet-4-5", CostUSD: 0.5})
+	state.RecordSpendEntry(SpendEntry{Day: today, RepoPath: "/a", WorkItemID: "1", Model: "claude-opus-4-1", CostUSD: 2})
+
+	ledger, err := state.SpendLedger(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != 2 || ledger[0].Model != "claude-opus-4-1" || ledger[0].CostUSD != 3 || ledger[1].CostUSD != 0.5 {
+		t.Errorf("ledger = %+v", ledger)
+	}
+	if cost, _ := state.SpendSince("/a", today); cost != 3.5 {
+		t.Errorf("SpendSince(/a) = %v, want every model's spend", cost)
+	}
+}
+
+func TestOpenStore_MigratesSpendToModels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range storeMigrations[:3] {
+		if _, err := db.Exec(m); err != nil {
+			t.Fatalf("migration %d: %v", i+1, err)
+		}
+	}
+	db.Exec(`PRAGMA user_version = 3`)
+	db.Exec(`INSERT INTO spend VALUES ('2026-01-02', '/a', '1', '7', 1.5, 10, 20)`)
+	db.Close()
+
+	db, err = openStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var model, issue string
+	var cost float64
+	if err := db.QueryRow(`SELECT model, issue_id, cost_usd FROM spend WHERE work_item_id = '1'`).Scan(&model, &issue, &cost); err != nil {
+		t.Fatal(err)
+	}
+	if model != "" || issue != "7" || cost != 1.5 {
+		t.Errorf("migrated row = %q, %q, %v", model, issue, cost)
+	}
+}
+
+func TestSpendWeekStart(t *testing.T) {
+	sunday := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	if got := SpendWeekStart(sunday); got != "2026-03-09" {
+		t.Errorf("SpendWeekStart(Sunday) = %s, want the Monday before", got)
+	}
+	if got := SpendWeekStart(sunday.AddDate(0, 0, 1)); got != "2026-03-16" {
+		t.Errorf("SpendWeekStart(Monday) = %s, want the same day", got)
+	}
+}
+
+func TestOpenStore_SchemaAndWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	db, err := openStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	var mode string
+	db.QueryRow(`PRAGMA user_version`).Scan(&version)
+	db.QueryRow(`PRAGMA journal_mode`).Scan(&mode)
+	if version != len(storeMigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(storeMigrations))
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+
+	// A database from a newer erg is refused rather than misread.
+	db.Exec(`PRAGMA user_version = 99`)
+	db.Close()
+	if _, err := openStore(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected newer-schema error, got %v", err)
+	}
+}
+
+func TestStateDiscovery_Database(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	if err := NewDaemonState("/test/repo").Save(); err != nil {
+		t.Fatal(err)
+	}
+	if !HasState("/test/repo") || !StateExists() {
+		t.Error("expected saved state to be found")
+	}
+	if key, err := RepoKeyFromLock(LockFilePath("/test/repo")); err != nil || key != "/test/repo" {
+		t.Errorf("RepoKeyFromLock = %q, %v", key, err)
+	}
+	if keys, err := StateKeys(); err != nil || !slices.Equal(keys, []string{"/test/repo"}) {
+		t.Errorf("StateKeys = %v, %v", keys, err)
+	}
+
+	if err := ClearState(); err != nil {
+		t.Fatal(err)
+	}
+	if HasState("/test/repo") {
+		t.Error("expected state to be cleared")
+	}
+}
+
+func TestDaemonState_SaveFailureKeepsConcurrentHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	paths.Reset()
+
+	state := NewDaemonState("/test/repo")
+	state.AddWorkItem(&WorkItem{ID: "item-1"})
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is pending when the failing write starts; a step change lands
+	// while it runs.
+	storeWriter = func(string, []byte, []itemRow, []string, bool, map[string][]HistoryEntry, map[spendKey]*SpendEntry, []AuditEntry) error {
+		state.AdvanceWorkItem("item-1", "coding", "idle")
+		return errors.New("disk full")
+	}
+	t.Cleanup(func() { storeWriter = writeStore })
+	if err := state.Save(); err == nil {
+		t.Fatal("expected the write error")
+	}
+
+	storeWriter = writeStore
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	history, err := state.ItemHistory("item-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].To != "coding" {
+		t.Errorf("history = %+v, want the step change recorded during the failed write", history)
+	}
+}
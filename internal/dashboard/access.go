@@ -0,0 +1,135 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Role is the access level granted to an authenticated dashboard client.
+type Role string
+
+const (
+	// RoleAdmin may view everything and use the control endpoints
+	// (stop, retry, send message).
+	RoleAdmin Role = "admin"
+	// RoleObserver may view state, logs, and spend but cannot change anything.
+	RoleObserver Role = "observer"
+)
+
+// Environment variables read by AccessTokensFromEnv.
+const (
+	AdminTokenEnvVar    = "ERG_DASHBOARD_ADMIN_TOKEN"
+	ObserverTokenEnvVar = "ERG_DASHBOARD_OBSERVER_TOKEN"
+)
+
+// tokenCookie holds the access token after a browser signs in with ?token=,
+// so the page's fetch and EventSource requests are authenticated too.
+const tokenCookie = "erg_dashboard_token"
+
+type roleContextKey struct{}
+
+// WithAccessTokens enables authentication. Clients must present one of the
+// tokens as "Authorization: Bearer <token>", a ?token= query parameter, or the
+// cookie set after a successful ?token= sign-in. The admin token grants
+// RoleAdmin; the observer token grants RoleObserver. An empty token disables
+// that role.
+func WithAccessTokens(adminToken, observerToken string) ServerOption {
+	return func(s *Server) {
+		s.adminToken = adminToken
+		s.observerToken = observerToken
+	}
+}
+
+// AccessTokensFromEnv returns a WithAccessTokens option built from
+// ERG_DASHBOARD_ADMIN_TOKEN and ERG_DASHBOARD_OBSERVER_TOKEN.
+func AccessTokensFromEnv() ServerOption {
+	return WithAccessTokens(os.Getenv(AdminTokenEnvVar), os.Getenv(ObserverTokenEnvVar))
+}
+
+// authEnabled reports whether any access token is configured. Without tokens
+// every client is treated as admin, relying on the loopback bind for safety.
+func (s *Server) authEnabled() bool {
+	return s.adminToken != "" || s.observerToken != ""
+}
+
+// roleForToken maps a presented token to its role. Comparisons are constant
+// time so the tokens cannot be recovered by timing responses.
+func (s *Server) roleForToken(token string) (Role, bool) {
+	if token == "" {
+		return "", false
+	}
+	if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1 {
+		return RoleAdmin, true
+	}
+	if s.observerToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.observerToken)) == 1 {
+		return RoleObserver, true
+	}
+	return "", false
+}
+
+// authMiddleware resolves the client's role and stores it in the request
+// context. Requests without a valid token receive 401 when auth is enabled.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authEnabled() {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleContextKey{}, RoleAdmin)))
+			return
+		}
+
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if role, ok := s.roleForToken(bearer); ok {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleContextKey{}, role)))
+				return
+			}
+		}
+		if query := r.URL.Query().Get("token"); query != "" {
+			if role, ok := s.roleForToken(query); ok {
+				http.SetCookie(w, &http.Cookie{
+					Name:     tokenCookie,
+					Value:    query,
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteStrictMode,
+				})
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleContextKey{}, role)))
+				return
+			}
+		}
+		if cookie, err := r.Cookie(tokenCookie); err == nil {
+			if role, ok := s.roleForToken(cookie.Value); ok {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleContextKey{}, role)))
+				return
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="erg"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// requestRole returns the role resolved by authMiddleware. Handlers invoked
+// without the middleware (e.g. directly in tests) are treated as admin when
+// auth is disabled and as observer otherwise.
+func (s *Server) requestRole(r *http.Request) Role {
+	if role, ok := r.Context().Value(roleContextKey{}).(Role); ok {
+		return role
+	}
+	if s.authEnabled() {
+		return RoleObserver
+	}
+	return RoleAdmin
+}
+
+// requireAdmin rejects requests from non-admin clients with 403.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.requestRole(r) != RoleAdmin {
+			http.Error(w, "admin role required", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,142 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newAccessTestHandler returns the server's routes wrapped in the auth
+// middleware, mirroring what Run serves.
+func newAccessTestHandler(srv *Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/capabilities", srv.handleCapabilities)
+	mux.HandleFunc("POST /api/workitems/{itemID}/stop", srv.requireAdmin(srv.handleStop))
+	mux.HandleFunc("POST /api/workitems/{itemID}/retry", srv.requireAdmin(srv.handleRetry))
+	return srv.authMiddleware(mux)
+}
+
+func TestAuthMiddleware_Roles(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(r *http.Request)
+		wantStatus int
+		wantRole   string
+	}{
+		{name: "no token", setup: func(r *http.Request) {}, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantStatus: http.StatusUnauthorized},
+		{name: "admin bearer", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer admin-secret") }, wantStatus: http.StatusOK, wantRole: "admin"},
+		{name: "observer bearer", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer observer-secret") }, wantStatus: http.StatusOK, wantRole: "observer"},
+		{name: "observer cookie", setup: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: tokenCookie, Value: "observer-secret"}) }, wantStatus: http.StatusOK, wantRole: "observer"},
+	}
+	srv := New("localhost:0", WithController(&mockController{}), WithAccessTokens("admin-secret", "observer-secret"))
+	h := newAccessTestHandler(srv)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/capabilities", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var caps map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&caps); err != nil {
+				t.Fatalf("failed to decode capabilities: %v", err)
+			}
+			if caps["role"] != tt.wantRole {
+				t.Errorf("role = %v, want %s", caps["role"], tt.wantRole)
+			}
+			if caps["control"] != (tt.wantRole == "admin") {
+				t.Errorf("control = %v for role %s", caps["control"], tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_QueryTokenSetsCookie(t *testing.T) {
+	srv := New("localhost:0", WithAccessTokens("admin-secret", "observer-secret"))
+	h := newAccessTestHandler(srv)
+
+	req := httptest.NewRequest("GET", "/api/capabilities?token=observer-secret", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != tokenCookie || cookies[0].Value != "observer-secret" || !cookies[0].HttpOnly {
+		t.Errorf("expected HttpOnly %s cookie, got %+v", tokenCookie, cookies)
+	}
+}
+
+func TestRequireAdmin_ObserverCannotControl(t *testing.T) {
+	ctrl := &mockController{}
+	srv := New("localhost:0", WithController(ctrl), WithAccessTokens("admin-secret", "observer-secret"))
+	h := newAccessTestHandler(srv)
+
+	for _, path := range []string{"/api/workitems/item-1/stop", "/api/workitems/item-1/retry"} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer observer-secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s as observer: status = %d, want 403", path, w.Code)
+		}
+	}
+	if len(ctrl.stopCalls) != 0 || len(ctrl.retryCalls) != 0 {
+		t.Errorf("observer reached controller: stop=%v retry=%v", ctrl.stopCalls, ctrl.retryCalls)
+	}
+
+	req := httptest.NewRequest("POST", "/api/workitems/item-1/stop", nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("admin stop: status = %d, want 200", w.Code)
+	}
+	if len(ctrl.stopCalls) != 1 {
+		t.Errorf("expected 1 stop call, got %v", ctrl.stopCalls)
+	}
+}
+
+func TestAuthMiddleware_DisabledTreatsAllAsAdmin(t *testing.T) {
+	ctrl := &mockController{}
+	srv := New("localhost:0", WithController(ctrl))
+	h := newAccessTestHandler(srv)
+
+	req := httptest.NewRequest("POST", "/api/workitems/item-1/stop", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 without auth configured", w.Code)
+	}
+}
+
+func TestAccessTokensFromEnv(t *testing.T) {
+	t.Setenv(AdminTokenEnvVar, "a")
+	t.Setenv(ObserverTokenEnvVar, "")
+	srv := New("localhost:0", AccessTokensFromEnv())
+	if srv.adminToken != "a" || srv.observerToken != "" {
+		t.Errorf("tokens = %q/%q", srv.adminToken, srv.observerToken)
+	}
+}
+
+func TestRun_AllowsNonLoopbackWithControllerAndTokens(t *testing.T) {
+	srv := New("0.0.0.0:0", WithController(&mockController{}), WithAccessTokens("admin-secret", ""))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := srv.Run(ctx)
+	if err != nil && strings.Contains(err.Error(), "loopback") {
+		t.Errorf("unexpected loopback error with access tokens: %v", err)
+	}
+}
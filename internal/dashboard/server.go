@@ -25,8 +25,8 @@ import (
 var indexHTML embed.FS
 
 // SessionController allows the dashboard to issue commands to running sessions.
-// No authentication is applied — ensure the server address is restricted to
-// loopback (e.g., "localhost:port") to prevent remote access.
+// Without access tokens (see WithAccessTokens) no authentication is applied and
+// the server refuses to bind to a non-loopback address.
 type SessionController interface {
 	// StopSession cancels the running worker for the given work item ID.
 	StopSession(itemID string) error
@@ -63,6 +63,9 @@ type Server struct {
 	controller SessionController // nil = read-only mode
 	authExec   iexec.CommandExecutor
 
+	adminToken    string // empty = no admin token
+	observerToken string // empty = no observer token
+
 	mu      sync.RWMutex
 	clients map[chan []byte]struct{}
 
@@ -87,11 +90,12 @@ func New(addr string, opts ...ServerOption) *Server {
 }
 
 // Run starts the HTTP server and background poller. Blocks until ctx is cancelled.
-// When a SessionController is attached, the address must resolve to a loopback
-// interface to prevent remote access to the unauthenticated control endpoints.
+// When a SessionController is attached and no access tokens are configured, the
+// address must resolve to a loopback interface to prevent remote access to the
+// unauthenticated control endpoints.
 func (s *Server) Run(ctx context.Context) error {
-	// Enforce loopback-only when control endpoints are enabled.
-	if s.controller != nil {
+	// Enforce loopback-only when control endpoints are enabled without auth.
+	if s.controller != nil && !s.authEnabled() {
 		if err := validateLoopback(s.addr); err != nil {
 			return fmt.Errorf("dashboard with control enabled: %w", err)
 		}
@@ -102,11 +106,12 @@ func (s *Server) Run(ctx context.Context) error {
 	mux.HandleFunc("GET /api/state", s.handleState)
 	mux.HandleFunc("GET /api/events", s.handleSSE)
 	mux.HandleFunc("GET /api/logs/{sessionID}", s.handleLogs)
+	mux.HandleFunc("GET /api/sessions/{sessionID}/stream", s.handleTranscript)
 	mux.HandleFunc("GET /api/capabilities", s.handleCapabilities)
 	mux.HandleFunc("GET /api/auth", s.handleAuth)
-	mux.HandleFunc("POST /api/workitems/{itemID}/stop", s.handleStop)
-	mux.HandleFunc("POST /api/workitems/{itemID}/retry", s.handleRetry)
-	mux.HandleFunc("POST /api/workitems/{itemID}/message", s.handleMessage)
+	mux.HandleFunc("POST /api/workitems/{itemID}/stop", s.requireAdmin(s.handleStop))
+	mux.HandleFunc("POST /api/workitems/{itemID}/retry", s.requireAdmin(s.handleRetry))
+	mux.HandleFunc("POST /api/workitems/{itemID}/message", s.requireAdmin(s.handleMessage))
 
 	// Start background poller
 	go s.poll(ctx)
@@ -127,7 +132,7 @@ func (s *Server) Run(ctx context.Context) error {
 	allowedOrigin := buildOrigin(net.JoinHostPort(configHost, resolvedPort))
 	srv := &http.Server{
 		Addr:    s.addr,
-		Handler: corsMiddleware(allowedOrigin)(mux),
+		Handler: corsMiddleware(allowedOrigin)(s.authMiddleware(mux)),
 	}
 
 	go func() {
@@ -232,8 +237,12 @@ func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	role := s.requestRole(r)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"control": s.controller != nil})
+	json.NewEncoder(w).Encode(map[string]any{
+		"control": s.controller != nil && role == RoleAdmin,
+		"role":    role,
+	})
 }
 
 // handleAuth returns cached auth info, fetching fresh data if the cache is
@@ -379,7 +388,7 @@ func corsMiddleware(allowedOrigin string) func(http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 			w.Header().Set("Vary", "Origin")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
 				return
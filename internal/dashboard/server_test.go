@@ -283,11 +283,11 @@ func TestHandleCapabilities_NoController(t *testing.T) {
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	var caps map[string]bool
+	var caps map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&caps); err != nil {
 		t.Fatalf("failed to decode capabilities: %v", err)
 	}
-	if caps["control"] {
+	if caps["control"] != false {
 		t.Error("expected control=false with no controller")
 	}
 }
@@ -303,11 +303,11 @@ func TestHandleCapabilities_WithController(t *testing.T) {
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	var caps map[string]bool
+	var caps map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&caps); err != nil {
 		t.Fatalf("failed to decode capabilities: %v", err)
 	}
-	if !caps["control"] {
+	if caps["control"] != true {
 		t.Error("expected control=true with controller set")
 	}
 }
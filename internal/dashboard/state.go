@@ -2,9 +2,14 @@ package dashboard
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,8 +37,35 @@ type DaemonInfo struct {
 	LastPollAt    time.Time      `json:"last_poll_at"`
 	WorkItems     []WorkItemInfo `json:"work_items"`
 	SlotCount     int            `json:"slot_count"`
+
+	// Spend today and this week, from the spend ledger.
+	TodayUSD float64 `json:"today_usd"`
+	WeekUSD  float64 `json:"week_usd"`
+
+	Workflows   []WorkflowInfo   `json:"workflows,omitempty"`
+	Transitions []TransitionInfo `json:"transitions,omitempty"`
+}
+
+// WorkflowInfo is a repo's main workflow laid out for drawing, with the
+// number of active work items at each state.
+type WorkflowInfo struct {
+	Repo   string               `json:"repo"`
+	States []workflow.GraphNode `json:"states"`
+	Counts map[string]int       `json:"counts"`
+}
+
+// TransitionInfo is a recent step change of a work item.
+type TransitionInfo struct {
+	WorkItemID string          `json:"work_item_id"`
+	IssueRef   config.IssueRef `json:"issue_ref"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	At         time.Time       `json:"at"`
 }
 
+// recentTransitions is how many step changes a snapshot includes per daemon.
+const recentTransitions = 25
+
 // WorkItemInfo holds the state of a single work item.
 type WorkItemInfo struct {
 	ID                string          `json:"id"`
@@ -42,6 +74,7 @@ type WorkItemInfo struct {
 	CurrentStep       string          `json:"current_step"`
 	Phase             string          `json:"phase"`
 	StepDisplayName   string          `json:"step_display_name,omitempty"`
+	Workflow          string          `json:"workflow,omitempty"`
 	PhaseLabel        string          `json:"phase_label"`
 	SessionID         string          `json:"session_id"`
 	Branch            string          `json:"branch"`
@@ -125,6 +158,7 @@ func CollectAll() (*Snapshot, error) {
 				CurrentStep:       item.CurrentStep,
 				Phase:             item.Phase,
 				StepDisplayName:   stepDisplayName,
+				Workflow:          item.Workflow,
 				PhaseLabel:        workflow.PhaseLabel(item.Phase),
 				SessionID:         item.SessionID,
 				Branch:            item.Branch,
@@ -144,12 +178,80 @@ func CollectAll() (*Snapshot, error) {
 			})
 		}
 
+		now := time.Now()
+		info.TodayUSD, _ = state.SpendSince("", daemonstate.SpendDay(now))
+		info.WeekUSD, _ = state.SpendSince("", daemonstate.SpendWeekStart(now))
+		info.Workflows = collectWorkflows(state, allItems)
+		info.Transitions = collectTransitions(state, allItems)
+
 		snap.Daemons = append(snap.Daemons, info)
 	}
 
 	return snap, nil
 }
 
+// collectWorkflows lays out the main workflow of each of the daemon's repos
+// and counts the active items at each of its states. Repos whose workflow
+// cannot be loaded are left out.
+func collectWorkflows(state *daemonstate.DaemonState, items []daemonstate.WorkItem) []WorkflowInfo {
+	_, pathLabels := state.GetRepoLabels()
+	repos := slices.Sorted(maps.Keys(pathLabels))
+	if len(repos) == 0 {
+		repos = []string{state.RepoPath}
+	}
+
+	var out []WorkflowInfo
+	for _, repo := range repos {
+		cfg, err := workflow.LoadAndMerge(repo)
+		if err != nil || cfg == nil {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, item := range items {
+			if item.State != daemonstate.WorkItemActive || item.Workflow != "" {
+				continue
+			}
+			itemRepo, _ := item.StepData["_repo_path"].(string)
+			if itemRepo == "" && len(repos) == 1 {
+				itemRepo = repo
+			}
+			if itemRepo == repo && item.CurrentStep != "" {
+				counts[item.CurrentStep]++
+			}
+		}
+		out = append(out, WorkflowInfo{
+			Repo:   cmp.Or(pathLabels[repo], repo),
+			States: workflow.GraphLayout(cfg),
+			Counts: counts,
+		})
+	}
+	return out
+}
+
+// collectTransitions returns the daemon's most recent step changes, newest
+// first, with the issue of each item that is still known.
+func collectTransitions(state *daemonstate.DaemonState, items []daemonstate.WorkItem) []TransitionInfo {
+	history, err := state.RecentHistory(recentTransitions)
+	if err != nil {
+		return nil
+	}
+	issues := make(map[string]config.IssueRef, len(items))
+	for _, item := range items {
+		issues[item.ID] = item.IssueRef
+	}
+	out := make([]TransitionInfo, 0, len(history))
+	for _, e := range history {
+		out = append(out, TransitionInfo{
+			WorkItemID: e.WorkItemID,
+			IssueRef:   issues[e.WorkItemID],
+			From:       e.From,
+			To:         e.To,
+			At:         e.At,
+		})
+	}
+	return out
+}
+
 // streamLogMsg is a minimal struct for parsing stream log JSON entries.
 type streamLogMsg struct {
 	Type    string `json:"type"`
@@ -171,9 +273,6 @@ type LogLine struct {
 }
 
 // ReadSessionLog reads and parses the stream log for a session.
-// The stream log contains pretty-printed (multi-line) JSON objects,
-// so we use json.Decoder to handle arbitrary formatting correctly.
-// Non-JSON lines (e.g. raw process output) are skipped gracefully.
 func ReadSessionLog(sessionID string, tailN int) ([]LogLine, error) {
 	if sessionID == "" {
 		return nil, fmt.Errorf("no session ID")
@@ -187,24 +286,45 @@ func ReadSessionLog(sessionID string, tailN int) ([]LogLine, error) {
 		return nil, err
 	}
 
-	var lines []LogLine
+	lines, _ := parseStreamLog(data)
+
+	if tailN > 0 && len(lines) > tailN {
+		lines = lines[len(lines)-tailN:]
+	}
+	return lines, nil
+}
 
-	for len(data) > 0 {
-		dec := json.NewDecoder(bytes.NewReader(data))
+// parseStreamLog turns stream log data into display lines and reports how
+// many bytes it consumed. The stream log contains pretty-printed
+// (multi-line) JSON objects, so we use json.Decoder to handle arbitrary
+// formatting correctly. Non-JSON lines (e.g. raw process output) are
+// skipped gracefully; an object cut off at the end of data is left
+// unconsumed so a follower can finish it once the rest is written.
+func parseStreamLog(data []byte) ([]LogLine, int) {
+	var lines []LogLine
+	offset := 0
+	for offset < len(data) {
+		rest := data[offset:]
+		dec := json.NewDecoder(bytes.NewReader(rest))
 		var msg streamLogMsg
 		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				if len(bytes.TrimSpace(rest)) == 0 {
+					offset = len(data)
+				}
+				break
+			}
 			// Skip non-JSON content: advance past the current line
 			// and retry from the next line.
-			idx := bytes.IndexByte(data, '\n')
+			idx := bytes.IndexByte(rest, '\n')
 			if idx < 0 {
 				break
 			}
-			data = data[idx+1:]
+			offset += idx + 1
 			continue
 		}
-		// Advance data past what the decoder consumed.
-		consumed := int(dec.InputOffset())
-		data = data[consumed:]
+		// Advance past what the decoder consumed.
+		offset += int(dec.InputOffset())
 
 		if msg.Type != "assistant" {
 			continue
@@ -224,11 +344,7 @@ func ReadSessionLog(sessionID string, tailN int) ([]LogLine, error) {
 			}
 		}
 	}
-
-	if tailN > 0 && len(lines) > tailN {
-		lines = lines[len(lines)-tailN:]
-	}
-	return lines, nil
+	return lines, offset
 }
 
 // toolDesc extracts a short description from tool input JSON.
@@ -623,3 +623,68 @@ func TestCollectAll_WorkItemDisplayNames(t *testing.T) {
 		t.Errorf("wi-3 PhaseLabel = %q, want %q", wi3.PhaseLabel, "In Progress")
 	}
 }
+
+func TestCollectAll_WorkflowAndTransitions(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	paths.Reset()
+
+	repo := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".erg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wf := `
+workflow: test-flow
+start: coding
+source:
+  provider: github
+  filter:
+    label: ready
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+    error: failed
+`
+	if err := os.WriteFile(filepath.Join(repo, ".erg", "workflow.yaml"), []byte(wf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state := daemonstate.NewDaemonState(repo)
+	state.StartedAt = time.Now()
+	state.AddWorkItem(&daemonstate.WorkItem{ID: "item-1", IssueRef: config.IssueRef{Source: "github", ID: "7"}})
+	state.AdvanceWorkItem("item-1", "coding", "async_pending")
+	state.UpdateWorkItem("item-1", func(it *daemonstate.WorkItem) { it.State = daemonstate.WorkItemActive })
+	state.AddWorkItem(&daemonstate.WorkItem{ID: "item-2", IssueRef: config.IssueRef{Source: "github", ID: "8"}})
+	state.RecordSpendEntry(daemonstate.SpendEntry{Day: daemonstate.SpendDay(time.Now()), RepoPath: repo, WorkItemID: "item-1", CostUSD: 1.5})
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(daemonstate.LockFilePath(repo), fmt.Appendf(nil, "%d", os.Getpid()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := CollectAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Daemons) != 1 {
+		t.Fatalf("expected 1 daemon, got %d", len(snap.Daemons))
+	}
+	d := snap.Daemons[0]
+	if d.TodayUSD != 1.5 || d.WeekUSD != 1.5 {
+		t.Errorf("spend today/week = %v/%v, want 1.5", d.TodayUSD, d.WeekUSD)
+	}
+	if len(d.Workflows) != 1 {
+		t.Fatalf("expected 1 workflow, got %+v", d.Workflows)
+	}
+	w := d.Workflows[0]
+	if len(w.States) != 3 || w.States[0].Name != "coding" || w.Counts["coding"] != 1 {
+		t.Errorf("workflow = %+v", w)
+	}
+	if len(d.Transitions) != 1 || d.Transitions[0].WorkItemID != "item-1" ||
+		d.Transitions[0].To != "coding" || d.Transitions[0].IssueRef.ID != "7" {
+		t.Errorf("transitions = %+v", d.Transitions)
+	}
+}
@@ -0,0 +1,152 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zhubert/erg/internal/logger"
+)
+
+// transcriptPollRate is how often a followed stream log is checked for new
+// output.
+const transcriptPollRate = 250 * time.Millisecond
+
+// defaultStreamBacklog is how many already-written lines a transcript stream
+// starts with when the request does not say.
+const defaultStreamBacklog = 20
+
+// LogFollower reads a session's stream log as the session writes it, so
+// its transcript can be tailed live rather than read back afterwards.
+type LogFollower struct {
+	path    string
+	offset  int64
+	pending []byte
+}
+
+// NewLogFollower returns a follower positioned at the start of the
+// session's stream log. The log need not exist yet.
+func NewLogFollower(sessionID string) (*LogFollower, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no session ID")
+	}
+	path, err := logger.StreamLogPath(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &LogFollower{path: path}, nil
+}
+
+// Next returns the lines written since the last call. It returns nothing,
+// without error, while the log does not exist. A log that shrank was
+// replaced, so it is read again from the start.
+func (f *LogFollower) Next() ([]LogLine, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < f.offset {
+		f.offset, f.pending = 0, nil
+	}
+	if info.Size() == f.offset {
+		return nil, nil
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	f.offset += int64(len(data))
+
+	data = append(f.pending, data...)
+	lines, consumed := parseStreamLog(data)
+	f.pending = append([]byte(nil), data[consumed:]...)
+	return lines, nil
+}
+
+// WriteEvent writes v as one server-sent event named event.
+func WriteEvent(w io.Writer, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// handleTranscript streams a session's transcript as server-sent "line"
+// events: the last ?tail= lines already written, then each line as the
+// session writes it, until the client goes away.
+func (s *Server) handleTranscript(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+	if !validateItemID(sessionID) {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	backlog := defaultStreamBacklog
+	if t := r.URL.Query().Get("tail"); t != "" {
+		if n, err := strconv.Atoi(t); err == nil && n >= 0 {
+			backlog = min(n, maxTailLines)
+		}
+	}
+	follower, err := NewLogFollower(sessionID)
+	if err != nil {
+		http.Error(w, "failed to open log", http.StatusInternalServerError)
+		return
+	}
+	lines, err := follower.Next()
+	if err != nil {
+		http.Error(w, "failed to read logs", http.StatusInternalServerError)
+		return
+	}
+	if len(lines) > backlog {
+		lines = lines[len(lines)-backlog:]
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(transcriptPollRate)
+	defer ticker.Stop()
+	for {
+		for _, ln := range lines {
+			if err := WriteEvent(w, "line", ln); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		if lines, err = follower.Next(); err != nil {
+			s.log.Warn("failed to follow session log", "session", sessionID, "error", err)
+			return
+		}
+	}
+}
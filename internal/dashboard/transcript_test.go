@@ -0,0 +1,129 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/logger"
+)
+
+// testStreamLog creates an empty stream log for sessionID and returns it
+// open for appending.
+func testStreamLog(t *testing.T, sessionID string) *os.File {
+	t.Helper()
+	path, err := logger.StreamLogPath(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		f.Close()
+		os.Remove(path)
+	})
+	return f
+}
+
+func TestLogFollower_Next(t *testing.T) {
+	f, err := NewLogFollower("follower-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines, err := f.Next(); err != nil || len(lines) != 0 {
+		t.Fatalf("missing log: lines %v, err %v", lines, err)
+	}
+
+	log := testStreamLog(t, "follower-test")
+	fmt.Fprint(log, `{"type":"system","subtype":"init"}`+"\nraw output\n"+`{"type":"assistant","message":{"content":[{"type":"text","text":"first"}]}}`+"\n")
+	lines, err := f.Next()
+	if err != nil || len(lines) != 1 || lines[0].Text != "first" {
+		t.Fatalf("first read: lines %v, err %v", lines, err)
+	}
+
+	// An object cut off mid-write waits for the rest.
+	msg := "{\n  \"type\": \"assistant\",\n  \"message\": {\"content\": [{\"type\": \"tool_use\", \"name\": \"Bash\", \"input\": {\"command\": \"go test\"}}]}\n}\n"
+	fmt.Fprint(log, msg[:20])
+	if lines, err := f.Next(); err != nil || len(lines) != 0 {
+		t.Fatalf("partial object: lines %v, err %v", lines, err)
+	}
+	fmt.Fprint(log, msg[20:])
+	lines, err = f.Next()
+	if err != nil || len(lines) != 1 || lines[0].Type != "tool" || lines[0].Text != "go test" {
+		t.Fatalf("completed object: lines %v, err %v", lines, err)
+	}
+	if lines, err := f.Next(); err != nil || len(lines) != 0 {
+		t.Fatalf("no new output: lines %v, err %v", lines, err)
+	}
+}
+
+func TestHandleTranscript(t *testing.T) {
+	log := testStreamLog(t, "transcript-test")
+	for i := range 5 {
+		fmt.Fprintf(log, `{"type":"assistant","message":{"content":[{"type":"text","text":"old %d"}]}}`+"\n", i)
+	}
+
+	srv := New("localhost:0")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/sessions/{sessionID}/stream", srv.handleTranscript)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/sessions/transcript-test/stream?tail=2", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	r := bufio.NewReader(resp.Body)
+	readData := func() string {
+		t.Helper()
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				return strings.TrimSpace(data)
+			}
+		}
+	}
+	for _, want := range []string{"old 3", "old 4"} {
+		if got := readData(); got != `{"type":"text","text":"`+want+`"}` {
+			t.Errorf("backlog data = %s, want %s", got, want)
+		}
+	}
+	fmt.Fprint(log, `{"type":"assistant","message":{"content":[{"type":"text","text":"live"}]}}`+"\n")
+	if got := readData(); got != `{"type":"text","text":"live"}` {
+		t.Errorf("live data = %s", got)
+	}
+}
+
+func TestHandleTranscript_InvalidSessionID(t *testing.T) {
+	srv := New("localhost:0")
+	req := httptest.NewRequest("GET", "/api/sessions/x/stream", nil)
+	req.SetPathValue("sessionID", "../etc")
+	w := httptest.NewRecorder()
+	srv.handleTranscript(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
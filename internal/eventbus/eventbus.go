@@ -0,0 +1,131 @@
+// Package eventbus delivers structured workflow events, such as a work item
+// moving from one state to the next, to pluggable sinks (a JSONL file, a
+// webhook, stdout) so external systems can follow the daemon without
+// scraping its logs.
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// TypeTransition is the Event.Type of a work item state transition.
+const TypeTransition = "transition"
+
+// Event is one structured event. It is written to sinks as a single JSON
+// object.
+type Event struct {
+	Type        string    `json:"type"`
+	Time        time.Time `json:"time"`
+	Repo        string    `json:"repo"`
+	WorkItem    string    `json:"work_item"`
+	IssueSource string    `json:"issue_source,omitempty"`
+	IssueID     string    `json:"issue_id,omitempty"`
+	Workflow    string    `json:"workflow,omitempty"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	// Reason is the kind of edge taken (next, error, catch, timeout,
+	// choice, default, branch, start), or "jump" when the move did not
+	// follow an edge of From.
+	Reason string `json:"reason"`
+	// DurationMS is how long the item spent in From, in milliseconds.
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Sink is a destination for events.
+type Sink interface {
+	Send(ctx context.Context, ev Event) error
+}
+
+// queueSize bounds how many events may wait for delivery before new ones
+// are dropped.
+const queueSize = 1024
+
+// Bus routes events to the sinks registered for their repo. Publish never
+// blocks the caller: events are queued and delivered in order on a
+// background goroutine started by Start.
+type Bus struct {
+	mu     sync.RWMutex
+	sinks  map[string][]Sink // keyed by repo path
+	queue  chan Event
+	closed bool
+	done   chan struct{}
+	logger *slog.Logger
+}
+
+// New creates a bus with no sinks.
+func New(logger *slog.Logger) *Bus {
+	return &Bus{
+		sinks:  make(map[string][]Sink),
+		queue:  make(chan Event, queueSize),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+}
+
+// SetSinks replaces the sinks events for repo are delivered to. An empty
+// list stops delivery for the repo.
+func (b *Bus) SetSinks(repo string, sinks []Sink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(sinks) == 0 {
+		delete(b.sinks, repo)
+		return
+	}
+	b.sinks[repo] = sinks
+}
+
+// Publish queues ev for delivery. Events for repos without sinks are
+// discarded, as are events published once the queue is full or the bus is
+// closed.
+func (b *Bus) Publish(ev Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed || len(b.sinks[ev.Repo]) == 0 {
+		return
+	}
+	select {
+	case b.queue <- ev:
+	default:
+		b.logger.Warn("event queue full, dropping event", "type", ev.Type, "workItem", ev.WorkItem)
+	}
+}
+
+// Start begins delivering queued events.
+func (b *Bus) Start() {
+	go func() {
+		defer close(b.done)
+		for ev := range b.queue {
+			b.deliver(ev)
+		}
+	}()
+}
+
+// Close stops accepting events and waits for queued ones to be delivered.
+// It must only be called after Start.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+// deliver sends ev to each of its repo's sinks. A failing sink is logged
+// and does not stop delivery to the others.
+func (b *Bus) deliver(ev Event) {
+	b.mu.RLock()
+	sinks := b.sinks[ev.Repo]
+	b.mu.RUnlock()
+	for _, sink := range sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := sink.Send(ctx, ev); err != nil {
+			b.logger.Warn("failed to deliver event", "type", ev.Type, "workItem", ev.WorkItem, "error", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,130 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingSink records the events it is sent.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Send(_ context.Context, ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestBus_RoutesByRepo(t *testing.T) {
+	bus := New(discardLogger())
+	a, b := &recordingSink{}, &recordingSink{}
+	bus.SetSinks("/repo/a", []Sink{a})
+	bus.SetSinks("/repo/b", []Sink{b})
+	bus.Start()
+
+	bus.Publish(Event{Repo: "/repo/a", WorkItem: "1", From: "coding", To: "open_pr"})
+	bus.Publish(Event{Repo: "/repo/a", WorkItem: "1", From: "open_pr", To: "await_ci"})
+	bus.Publish(Event{Repo: "/repo/c", WorkItem: "2"}) // no sinks
+	bus.Close()
+
+	if len(a.events) != 2 || a.events[0].To != "open_pr" || a.events[1].To != "await_ci" {
+		t.Errorf("expected both repo a events in order, got %+v", a.events)
+	}
+	if len(b.events) != 0 {
+		t.Errorf("expected no events for repo b, got %+v", b.events)
+	}
+
+	bus.Publish(Event{Repo: "/repo/a"}) // after Close: dropped, no panic
+}
+
+func TestBus_SetSinksEmptyStopsDelivery(t *testing.T) {
+	bus := New(discardLogger())
+	sink := &recordingSink{}
+	bus.SetSinks("/repo", []Sink{sink})
+	bus.SetSinks("/repo", nil)
+	bus.Start()
+	bus.Publish(Event{Repo: "/repo"})
+	bus.Close()
+
+	if len(sink.events) != 0 {
+		t.Errorf("expected no events after sinks were removed, got %+v", sink.events)
+	}
+}
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "transitions.jsonl")
+	sink := NewFileSink(path)
+	for _, to := range []string{"open_pr", "await_ci"} {
+		if err := sink.Send(context.Background(), Event{Type: TypeTransition, WorkItem: "1", To: to}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", data)
+	}
+	var ev Event
+	if err := json.Unmarshal([]byte(lines[1]), &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != TypeTransition || ev.To != "await_ci" {
+		t.Errorf("unexpected event %+v", ev)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriterSink(&buf).Send(context.Background(), Event{WorkItem: "1", Reason: "next"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"reason":"next"`) || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Event
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.WorkItem == "bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, map[string]string{"Authorization": "Bearer x"})
+	if err := sink.Send(context.Background(), Event{WorkItem: "1", From: "coding", To: "open_pr"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.To != "open_pr" || auth != "Bearer x" {
+		t.Errorf("unexpected delivery: %+v auth=%q", got, auth)
+	}
+
+	if err := sink.Send(context.Background(), Event{WorkItem: "bad"}); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
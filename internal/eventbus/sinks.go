@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sendTimeout bounds a single delivery to a sink.
+const sendTimeout = 10 * time.Second
+
+// FileSink appends each event as a line of JSON to a file, creating it and
+// its directory if needed.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink returns a sink that appends to the JSONL file at path.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Send appends ev to the file.
+func (s *FileSink) Send(_ context.Context, ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriterSink writes each event as a line of JSON to a writer, such as
+// os.Stdout.
+type WriterSink struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewWriterSink returns a sink that writes to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Send writes ev to the writer.
+func (s *WriterSink) Send(_ context.Context, ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// WebhookSink POSTs each event as a JSON body to a URL.
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink returns a sink that POSTs to url with the given extra
+// headers.
+func NewWebhookSink(url string, headers map[string]string) *WebhookSink {
+	return &WebhookSink{url: url, headers: headers, client: &http.Client{}}
+}
+
+// Send POSTs ev. Any non-2xx response is an error.
+func (s *WebhookSink) Send(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook POST failed: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
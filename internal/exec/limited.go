@@ -0,0 +1,103 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// LimitedExecutor bounds how many commands with one name run at once,
+// e.g. gh, so parallel sessions stay under an API's rate limits. Other
+// commands pass straight through to the base executor.
+type LimitedExecutor struct {
+	base  CommandExecutor
+	name  string
+	slots chan struct{}
+}
+
+// NewLimitedExecutor returns an executor running at most n commands named
+// name at once through base. Callers wait for a free slot or their
+// context.
+func NewLimitedExecutor(base CommandExecutor, name string, n int) *LimitedExecutor {
+	return &LimitedExecutor{base: base, name: name, slots: make(chan struct{}, max(n, 1))}
+}
+
+// acquire waits for a slot for a command named name. The returned release
+// is a no-op for other commands.
+func (e *LimitedExecutor) acquire(ctx context.Context, name string) (release func(), err error) {
+	if name != e.name {
+		return func() {}, nil
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return func() { <-e.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Run implements CommandExecutor.
+func (e *LimitedExecutor) Run(ctx context.Context, dir string, name string, args ...string) (stdout, stderr []byte, err error) {
+	release, err := e.acquire(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	return e.base.Run(ctx, dir, name, args...)
+}
+
+// Output implements CommandExecutor.
+func (e *LimitedExecutor) Output(ctx context.Context, dir string, name string, args ...string) ([]byte, error) {
+	release, err := e.acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return e.base.Output(ctx, dir, name, args...)
+}
+
+// CombinedOutput implements CommandExecutor.
+func (e *LimitedExecutor) CombinedOutput(ctx context.Context, dir string, name string, args ...string) ([]byte, error) {
+	release, err := e.acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return e.base.CombinedOutput(ctx, dir, name, args...)
+}
+
+// Start implements CommandExecutor. The slot is held until the command's
+// Wait returns.
+func (e *LimitedExecutor) Start(ctx context.Context, dir string, name string, args ...string) (CommandHandle, error) {
+	release, err := e.acquire(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := e.base.Start(ctx, dir, name, args...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &limitedCommandHandle{CommandHandle: h, release: sync.OnceFunc(release)}, nil
+}
+
+// limitedCommandHandle releases its executor slot once the command is done.
+type limitedCommandHandle struct {
+	CommandHandle
+	release func()
+}
+
+func (h *limitedCommandHandle) Wait() (stdout, stderr []byte, err error) {
+	defer h.release()
+	return h.CommandHandle.Wait()
+}
+
+func (h *limitedCommandHandle) StdoutPipe() *bytes.Buffer {
+	return h.CommandHandle.StdoutPipe()
+}
+
+func (h *limitedCommandHandle) StderrPipe() *bytes.Buffer {
+	return h.CommandHandle.StderrPipe()
+}
+
+var _ CommandExecutor = (*LimitedExecutor)(nil)
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingExecutor records the most commands it ran at once.
+type blockingExecutor struct {
+	MockExecutor
+	running, peak atomic.Int32
+}
+
+func (e *blockingExecutor) Output(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	n := e.running.Add(1)
+	for {
+		p := e.peak.Load()
+		if n <= p || e.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	e.running.Add(-1)
+	return nil, nil
+}
+
+func TestLimitedExecutor_BoundsNamedCommand(t *testing.T) {
+	base := &blockingExecutor{}
+	e := NewLimitedExecutor(base, "gh", 2)
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Output(context.Background(), "", "gh", "issue", "list")
+		}()
+	}
+	wg.Wait()
+	if peak := base.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+
+	base.peak.Store(0)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Output(context.Background(), "", "git", "status")
+		}()
+	}
+	wg.Wait()
+	if peak := base.peak.Load(); peak < 3 {
+		t.Errorf("other commands were limited: peak %d", peak)
+	}
+}
+
+func TestLimitedExecutor_WaitHonorsContext(t *testing.T) {
+	mock := NewMockExecutor(nil)
+	e := NewLimitedExecutor(mock, "gh", 1)
+
+	h, err := e.Start(context.Background(), "", "gh", "pr", "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.Output(ctx, "", "gh", "pr", "view"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected to time out waiting for a slot, got %v", err)
+	}
+
+	h.Wait()
+	if _, err := e.Output(context.Background(), "", "gh", "pr", "view"); err != nil {
+		t.Errorf("slot not released after Wait: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// Package ghapp mints short-lived GitHub App installation tokens.
+//
+// When erg is configured with GitHub App credentials, the daemon mints a fresh
+// installation token for every session it starts. Each token is scoped to the
+// single repository the work item targets, so a compromised session container
+// cannot read or write any other repository the App is installed on.
+package ghapp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/providerhttp"
+)
+
+const (
+	defaultAPIBase = "https://api.github.com"
+	httpTimeout    = 30 * time.Second
+
+	// jwtLifetime is how long the App JWT used to request installation tokens
+	// is valid. GitHub rejects JWTs that live longer than 10 minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockSkew backdates the JWT issue time to tolerate clock drift between
+	// this host and GitHub.
+	jwtClockSkew = 60 * time.Second
+)
+
+// Environment variables used by ConfigFromEnv.
+const (
+	AppIDEnvVar          = "ERG_GITHUB_APP_ID"
+	InstallationIDEnvVar = "ERG_GITHUB_APP_INSTALLATION_ID"
+	PrivateKeyEnvVar     = "ERG_GITHUB_APP_PRIVATE_KEY_PATH"
+)
+
+// Config holds the GitHub App credentials needed to mint installation tokens.
+type Config struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKeyPath string
+	// Permissions optionally narrows the token's permissions (e.g.
+	// {"contents": "write", "pull_requests": "write"}). When empty, the token
+	// inherits the installation's permissions, still limited to one repo.
+	Permissions map[string]string
+	// APIBase overrides the GitHub API base URL (GitHub Enterprise or tests).
+	APIBase string
+}
+
+// ConfigFromEnv builds a Config from ERG_GITHUB_APP_* environment variables.
+// Returns false when GitHub App auth is not configured (any variable unset).
+func ConfigFromEnv() (Config, bool, error) {
+	appID := os.Getenv(AppIDEnvVar)
+	installID := os.Getenv(InstallationIDEnvVar)
+	keyPath := os.Getenv(PrivateKeyEnvVar)
+	if appID == "" && installID == "" && keyPath == "" {
+		return Config{}, false, nil
+	}
+	if appID == "" || installID == "" || keyPath == "" {
+		return Config{}, false, fmt.Errorf("GitHub App auth requires %s, %s, and %s to all be set", AppIDEnvVar, InstallationIDEnvVar, PrivateKeyEnvVar)
+	}
+	id, err := strconv.ParseInt(appID, 10, 64)
+	if err != nil {
+		return Config{}, false, fmt.Errorf("invalid %s %q: %w", AppIDEnvVar, appID, err)
+	}
+	inst, err := strconv.ParseInt(installID, 10, 64)
+	if err != nil {
+		return Config{}, false, fmt.Errorf("invalid %s %q: %w", InstallationIDEnvVar, installID, err)
+	}
+	return Config{AppID: id, InstallationID: inst, PrivateKeyPath: keyPath}, true, nil
+}
+
+// Token is a minted installation access token.
+type Token struct {
+	Value     string
+	ExpiresAt time.Time
+	// Repository is the owner/repo the token is restricted to.
+	Repository string
+}
+
+// Minter mints repository-scoped installation tokens for a GitHub App.
+type Minter struct {
+	cfg        Config
+	key        *rsa.PrivateKey
+	httpClient *http.Client
+	now        func() time.Time // injectable for testing
+}
+
+// NewMinter creates a Minter, loading and parsing the App's private key.
+func NewMinter(cfg Config) (*Minter, error) {
+	data, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.APIBase == "" {
+		cfg.APIBase = defaultAPIBase
+	}
+	return &Minter{
+		cfg:        cfg,
+		key:        key,
+		httpClient: providerhttp.NewClient(httpTimeout, providerhttp.DefaultPolicy()),
+		now:        time.Now,
+	}, nil
+}
+
+// NewMinterWithClient creates a Minter with a custom HTTP client (for testing).
+func NewMinterWithClient(cfg Config, client *http.Client) (*Minter, error) {
+	m, err := NewMinter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.httpClient = client
+	return m, nil
+}
+
+// parsePrivateKey decodes a PEM-encoded RSA private key in either PKCS#1
+// ("RSA PRIVATE KEY", the format GitHub downloads) or PKCS#8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
+
+// appJWT builds the RS256-signed JWT that authenticates as the App itself.
+func (m *Minter) appJWT() (string, error) {
+	now := m.now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(m.cfg.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// MintRepoToken mints an installation token restricted to ownerRepo
+// (e.g. "zhubert/erg"). The token is valid for one hour.
+func (m *Minter) MintRepoToken(ctx context.Context, ownerRepo string) (*Token, error) {
+	_, repo, ok := strings.Cut(ownerRepo, "/")
+	if !ok || repo == "" {
+		return nil, fmt.Errorf("invalid repository %q: expected owner/repo", ownerRepo)
+	}
+
+	jwt, err := m.appJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := map[string]any{"repositories": []string{repo}}
+	if len(m.cfg.Permissions) > 0 {
+		reqBody["permissions"] = m.cfg.Permissions
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimRight(m.cfg.APIBase, "/"), m.cfg.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub App token request failed: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("GitHub App token request returned status %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App token response: %w", err)
+	}
+	if out.Token == "" {
+		return nil, fmt.Errorf("GitHub App token response contained no token")
+	}
+	return &Token{Value: out.Token, ExpiresAt: out.ExpiresAt, Repository: ownerRepo}, nil
+}
@@ -0,0 +1,160 @@
+package ghapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestKey generates an RSA key and writes it as a PKCS#1 PEM file.
+func writeTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return key, path
+}
+
+func TestMintRepoToken(t *testing.T) {
+	key, keyPath := writeTestKey(t)
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/installations/99/access_tokens" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Fatalf("malformed JWT %q", jwt)
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("JWT signature invalid: %v", err)
+		}
+		claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]any
+		json.Unmarshal(claimsJSON, &claims)
+		if claims["iss"] != "42" {
+			t.Errorf("iss = %v, want 42", claims["iss"])
+		}
+
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"token": "ghs_scoped", "expires_at": expires})
+	}))
+	defer srv.Close()
+
+	m, err := NewMinter(Config{
+		AppID:          42,
+		InstallationID: 99,
+		PrivateKeyPath: keyPath,
+		Permissions:    map[string]string{"contents": "write"},
+		APIBase:        srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewMinter: %v", err)
+	}
+
+	tok, err := m.MintRepoToken(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("MintRepoToken: %v", err)
+	}
+	if tok.Value != "ghs_scoped" {
+		t.Errorf("token = %q", tok.Value)
+	}
+	if !tok.ExpiresAt.Equal(expires) {
+		t.Errorf("expires = %v, want %v", tok.ExpiresAt, expires)
+	}
+	repos, _ := gotBody["repositories"].([]any)
+	if len(repos) != 1 || repos[0] != "repo" {
+		t.Errorf("repositories = %v, want [repo]", gotBody["repositories"])
+	}
+	perms, _ := gotBody["permissions"].(map[string]any)
+	if perms["contents"] != "write" {
+		t.Errorf("permissions = %v", gotBody["permissions"])
+	}
+}
+
+func TestMintRepoToken_Errors(t *testing.T) {
+	_, keyPath := writeTestKey(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	m, err := NewMinter(Config{AppID: 1, InstallationID: 2, PrivateKeyPath: keyPath, APIBase: srv.URL})
+	if err != nil {
+		t.Fatalf("NewMinter: %v", err)
+	}
+
+	if _, err := m.MintRepoToken(context.Background(), "no-slash"); err == nil {
+		t.Error("expected error for invalid owner/repo")
+	}
+	if _, err := m.MintRepoToken(context.Background(), "owner/repo"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected status 404 error, got %v", err)
+	}
+}
+
+func TestNewMinter_InvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.pem")
+	os.WriteFile(path, []byte("not a key"), 0o600)
+	if _, err := NewMinter(Config{PrivateKeyPath: path}); err == nil {
+		t.Error("expected error for non-PEM key")
+	}
+	if _, err := NewMinter(Config{PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for missing key file")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "unset", env: map[string]string{}, wantOK: false},
+		{name: "complete", env: map[string]string{AppIDEnvVar: "1", InstallationIDEnvVar: "2", PrivateKeyEnvVar: "/k.pem"}, wantOK: true},
+		{name: "partial", env: map[string]string{AppIDEnvVar: "1"}, wantErr: true},
+		{name: "non-numeric", env: map[string]string{AppIDEnvVar: "x", InstallationIDEnvVar: "2", PrivateKeyEnvVar: "/k.pem"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{AppIDEnvVar, InstallationIDEnvVar, PrivateKeyEnvVar} {
+				t.Setenv(k, tt.env[k])
+			}
+			cfg, ok, err := ConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (cfg.AppID != 1 || cfg.InstallationID != 2) {
+				t.Errorf("cfg = %+v", cfg)
+			}
+		})
+	}
+}
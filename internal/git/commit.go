@@ -153,13 +153,13 @@ Diff:
 %s`, strings.Join(status.Files, ", "), fullDiff)
 
 	// Call Claude CLI directly with --print for a simple response
-	output, err := s.executor.Output(ctx, worktreePath, "claude", "--print", "-p", prompt)
+	output, err := s.generateWithClaude(ctx, worktreePath, "commit_message", prompt)
 	if err != nil {
 		log.Error("Claude commit message generation failed", "error", err)
 		return "", fmt.Errorf("failed to generate commit message with Claude: %w", err)
 	}
 
-	commitMsg := strings.TrimSpace(string(output))
+	commitMsg := strings.TrimSpace(output)
 	if commitMsg == "" {
 		return "", fmt.Errorf("claude returned empty commit message")
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -129,6 +130,71 @@ func (s *GitService) GetBatchPRStates(ctx context.Context, repoPath string, bran
 	return result, nil
 }
 
+// PRSummary describes a pull request returned by ListPRsByHeadPrefix.
+type PRSummary struct {
+	Number      int
+	Title       string
+	URL         string
+	HeadRefName string
+	State       PRState
+	CreatedAt   time.Time
+	MergedAt    *time.Time
+	ClosedAt    *time.Time
+}
+
+// ListPRsByHeadPrefix returns up to limit of the most recent pull requests in
+// any state whose head branch starts with prefix, newest first.
+func (s *GitService) ListPRsByHeadPrefix(ctx context.Context, repoPath, prefix string, limit int) ([]PRSummary, error) {
+	output, err := s.executor.Output(ctx, repoPath, "gh", "pr", "list",
+		"--state", "all",
+		"--limit", strconv.Itoa(limit),
+		"--json", "number,title,url,headRefName,state,createdAt,mergedAt,closedAt",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gh pr list failed: %w", err)
+	}
+
+	var prs []struct {
+		Number      int        `json:"number"`
+		Title       string     `json:"title"`
+		URL         string     `json:"url"`
+		HeadRefName string     `json:"headRefName"`
+		State       string     `json:"state"`
+		CreatedAt   time.Time  `json:"createdAt"`
+		MergedAt    *time.Time `json:"mergedAt"`
+		ClosedAt    *time.Time `json:"closedAt"`
+	}
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+
+	var result []PRSummary
+	for _, pr := range prs {
+		if !strings.HasPrefix(pr.HeadRefName, prefix) {
+			continue
+		}
+		// gh reports a zero timestamp rather than null for PRs that were
+		// never merged or closed.
+		if pr.MergedAt != nil && pr.MergedAt.IsZero() {
+			pr.MergedAt = nil
+		}
+		if pr.ClosedAt != nil && pr.ClosedAt.IsZero() {
+			pr.ClosedAt = nil
+		}
+		result = append(result, PRSummary{
+			Number:      pr.Number,
+			Title:       pr.Title,
+			URL:         pr.URL,
+			HeadRefName: pr.HeadRefName,
+			State:       PRState(pr.State),
+			CreatedAt:   pr.CreatedAt,
+			MergedAt:    pr.MergedAt,
+			ClosedAt:    pr.ClosedAt,
+		})
+	}
+	return result, nil
+}
+
 // LinkedPR represents a pull request that references a GitHub issue.
 type LinkedPR struct {
 	Number      int
@@ -502,6 +568,25 @@ func (s *GitService) CheckUserIsCollaborator(ctx context.Context, repoPath, user
 	return err == nil, nil
 }
 
+// CheckUserCanWrite returns true if the given GitHub username has write,
+// maintain or admin permission on the repo at repoPath. Uses
+// `gh api repos/:owner/:repo/collaborators/{username}/permission`, which
+// reports "read" or "none" for users who may only comment.
+func (s *GitService) CheckUserCanWrite(ctx context.Context, repoPath, username string) (bool, error) {
+	out, err := s.executor.Output(ctx, repoPath, "gh", "api",
+		fmt.Sprintf("repos/:owner/:repo/collaborators/%s/permission", username),
+		"--jq", ".permission",
+	)
+	if err != nil {
+		return false, fmt.Errorf("gh api permission check failed: %w", err)
+	}
+	switch strings.TrimSpace(string(out)) {
+	case "admin", "maintain", "write":
+		return true, nil
+	}
+	return false, nil
+}
+
 // GetIssueComments fetches all comments on a GitHub issue using the REST API.
 // Uses `gh api` instead of `gh issue view --json comments` because the latter
 // does not include updatedAt in its response, which is needed by
@@ -574,17 +659,37 @@ func (s *GitService) GetIssueCommentsWithIDs(ctx context.Context, repoPath strin
 
 // GitHubIssue represents a GitHub issue fetched via the gh CLI
 type GitHubIssue struct {
-	Number int    `json:"number"`
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	URL    string `json:"url"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string    `json:"title"`
+		DueOn time.Time `json:"dueOn"`
+	} `json:"milestone"`
+}
+
+// githubIssueFields are the gh --json fields that populate a GitHubIssue.
+const githubIssueFields = "number,title,body,url,createdAt,labels,milestone"
+
+// LabelNames returns the names of the issue's labels.
+func (i GitHubIssue) LabelNames() []string {
+	names := make([]string, len(i.Labels))
+	for j, l := range i.Labels {
+		names[j] = l.Name
+	}
+	return names
 }
 
 // GetGitHubIssue fetches a single GitHub issue by number using the gh CLI.
 func (s *GitService) GetGitHubIssue(ctx context.Context, repoPath string, number int) (*GitHubIssue, error) {
 	output, err := s.executor.Output(ctx, repoPath, "gh", "issue", "view",
 		fmt.Sprintf("%d", number),
-		"--json", "number,title,body,url",
+		"--json", githubIssueFields,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("gh issue view failed: %w", err)
@@ -598,11 +703,81 @@ func (s *GitService) GetGitHubIssue(ctx context.Context, repoPath string, number
 	return &issue, nil
 }
 
+// ReferencedIssue is a GitHub issue or pull request looked up by number.
+type ReferencedIssue struct {
+	Number int
+	Title  string
+	Body   string
+	State  string // "open" or "closed"
+	URL    string
+	IsPR   bool
+}
+
+// GetIssueOrPR fetches an issue or pull request by number. It uses the REST
+// issues endpoint, which serves both, because `gh issue view` rejects pull
+// request numbers.
+func (s *GitService) GetIssueOrPR(ctx context.Context, repoPath string, number int) (*ReferencedIssue, error) {
+	output, err := s.executor.Output(ctx, repoPath, "gh", "api",
+		fmt.Sprintf("repos/:owner/:repo/issues/%d", number),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gh api issues/%d failed: %w", number, err)
+	}
+
+	var resp struct {
+		Number      int       `json:"number"`
+		Title       string    `json:"title"`
+		Body        string    `json:"body"`
+		State       string    `json:"state"`
+		HTMLURL     string    `json:"html_url"`
+		PullRequest *struct{} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+
+	return &ReferencedIssue{
+		Number: resp.Number,
+		Title:  resp.Title,
+		Body:   resp.Body,
+		State:  resp.State,
+		URL:    resp.HTMLURL,
+		IsPR:   resp.PullRequest != nil,
+	}, nil
+}
+
+// GetIssueBlockedBy returns the issues GitHub records as blocking the issue
+// with the given number (its "blocked by" dependencies).
+func (s *GitService) GetIssueBlockedBy(ctx context.Context, repoPath string, number int) ([]ReferencedIssue, error) {
+	output, err := s.executor.Output(ctx, repoPath, "gh", "api",
+		fmt.Sprintf("repos/:owner/:repo/issues/%d/dependencies/blocked_by", number),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gh api issues/%d/dependencies/blocked_by failed: %w", number, err)
+	}
+
+	var resp []struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse blocking issues: %w", err)
+	}
+
+	blockers := make([]ReferencedIssue, 0, len(resp))
+	for _, r := range resp {
+		blockers = append(blockers, ReferencedIssue{Number: r.Number, Title: r.Title, State: r.State, URL: r.HTMLURL})
+	}
+	return blockers, nil
+}
+
 // FetchGitHubIssues fetches open issues from a GitHub repository using the gh CLI.
 // The repoPath is used as the working directory to determine which repo to query.
 func (s *GitService) FetchGitHubIssues(ctx context.Context, repoPath string) ([]GitHubIssue, error) {
 	output, err := s.executor.Output(ctx, repoPath, "gh", "issue", "list",
-		"--json", "number,title,body,url",
+		"--json", githubIssueFields,
 		"--state", "open",
 	)
 	if err != nil {
@@ -618,14 +793,18 @@ func (s *GitService) FetchGitHubIssues(ctx context.Context, repoPath string) ([]
 }
 
 // FetchGitHubIssuesWithLabel fetches open issues with a specific label from a GitHub repository.
-func (s *GitService) FetchGitHubIssuesWithLabel(ctx context.Context, repoPath, label string) ([]GitHubIssue, error) {
+// A non-empty assignee narrows the list to issues assigned to that login.
+func (s *GitService) FetchGitHubIssuesWithLabel(ctx context.Context, repoPath, label, assignee string) ([]GitHubIssue, error) {
 	args := []string{"issue", "list",
-		"--json", "number,title,body,url",
+		"--json", githubIssueFields,
 		"--state", "open",
 	}
 	if label != "" {
 		args = append(args, "--label", label)
 	}
+	if assignee != "" {
+		args = append(args, "--assignee", assignee)
+	}
 	output, err := s.executor.Output(ctx, repoPath, "gh", args...)
 	if err != nil {
 		return nil, fmt.Errorf("gh issue list failed: %w", err)
@@ -685,6 +864,16 @@ func (s *GitService) CloseIssue(ctx context.Context, repoPath, issueID string) e
 	return nil
 }
 
+// ClosePR closes the PR for branch with a comment saying why, and deletes
+// the branch.
+func (s *GitService) ClosePR(ctx context.Context, repoPath, branch, comment string) error {
+	_, err := s.executor.CombinedOutput(ctx, repoPath, "gh", "pr", "close", branch, "--comment", comment, "--delete-branch")
+	if err != nil {
+		return fmt.Errorf("gh pr close failed: %w", err)
+	}
+	return nil
+}
+
 // RequestPRReview adds a reviewer to a PR using the gh CLI.
 func (s *GitService) RequestPRReview(ctx context.Context, repoPath, branch, reviewer string) error {
 	_, err := s.executor.CombinedOutput(ctx, repoPath, "gh", "pr", "edit", branch, "--add-reviewer", reviewer)
@@ -696,23 +885,46 @@ func (s *GitService) RequestPRReview(ctx context.Context, repoPath, branch, revi
 
 // AddIssueLabel adds a label to a GitHub issue using the gh CLI.
 func (s *GitService) AddIssueLabel(ctx context.Context, repoPath string, issueNumber int, label string) error {
-	_, _, err := s.executor.Run(ctx, repoPath, "gh", "issue", "edit",
+	_, stderr, err := s.executor.Run(ctx, repoPath, "gh", "issue", "edit",
 		fmt.Sprintf("%d", issueNumber),
 		"--add-label", label,
 	)
 	if err != nil {
+		if stderrStr := strings.TrimSpace(string(stderr)); stderrStr != "" {
+			return fmt.Errorf("gh issue edit --add-label failed: %w: %s", err, stderrStr)
+		}
 		return fmt.Errorf("gh issue edit --add-label failed: %w", err)
 	}
 	return nil
 }
 
+// CreateLabel creates a repository label using the gh CLI. An existing label
+// with the same name is updated rather than treated as an error.
+func (s *GitService) CreateLabel(ctx context.Context, repoPath, name, color, description string) error {
+	_, stderr, err := s.executor.Run(ctx, repoPath, "gh", "label", "create", name,
+		"--color", color,
+		"--description", description,
+		"--force",
+	)
+	if err != nil {
+		if stderrStr := strings.TrimSpace(string(stderr)); stderrStr != "" {
+			return fmt.Errorf("gh label create failed: %w: %s", err, stderrStr)
+		}
+		return fmt.Errorf("gh label create failed: %w", err)
+	}
+	return nil
+}
+
 // RemoveIssueLabel removes a label from a GitHub issue using the gh CLI.
 func (s *GitService) RemoveIssueLabel(ctx context.Context, repoPath string, issueNumber int, label string) error {
-	_, _, err := s.executor.Run(ctx, repoPath, "gh", "issue", "edit",
+	_, stderr, err := s.executor.Run(ctx, repoPath, "gh", "issue", "edit",
 		fmt.Sprintf("%d", issueNumber),
 		"--remove-label", label,
 	)
 	if err != nil {
+		if stderrStr := strings.TrimSpace(string(stderr)); stderrStr != "" {
+			return fmt.Errorf("gh issue edit --remove-label failed: %w: %s", err, stderrStr)
+		}
 		return fmt.Errorf("gh issue edit --remove-label failed: %w", err)
 	}
 	return nil
@@ -720,16 +932,43 @@ func (s *GitService) RemoveIssueLabel(ctx context.Context, repoPath string, issu
 
 // CommentOnIssue leaves a comment on a GitHub issue using the gh CLI.
 func (s *GitService) CommentOnIssue(ctx context.Context, repoPath string, issueNumber int, body string) error {
-	_, _, err := s.executor.Run(ctx, repoPath, "gh", "issue", "comment",
+	_, stderr, err := s.executor.Run(ctx, repoPath, "gh", "issue", "comment",
 		fmt.Sprintf("%d", issueNumber),
 		"--body", body,
 	)
 	if err != nil {
+		if stderrStr := strings.TrimSpace(string(stderr)); stderrStr != "" {
+			return fmt.Errorf("gh issue comment failed: %w: %s", err, stderrStr)
+		}
 		return fmt.Errorf("gh issue comment failed: %w", err)
 	}
 	return nil
 }
 
+// CreateIssue opens a GitHub issue using the gh CLI and returns its number and URL.
+// Labels must already exist in the repository.
+func (s *GitService) CreateIssue(ctx context.Context, repoPath, title, body string, labels []string) (int, string, error) {
+	args := []string{"issue", "create", "--title", title, "--body", body}
+	for _, label := range labels {
+		args = append(args, "--label", label)
+	}
+	stdout, stderr, err := s.executor.Run(ctx, repoPath, "gh", args...)
+	if err != nil {
+		if stderrStr := strings.TrimSpace(string(stderr)); stderrStr != "" {
+			return 0, "", fmt.Errorf("gh issue create failed: %w: %s", err, stderrStr)
+		}
+		return 0, "", fmt.Errorf("gh issue create failed: %w", err)
+	}
+
+	// gh prints the new issue's URL, e.g. https://github.com/owner/repo/issues/42
+	issueURL := strings.TrimSpace(string(stdout))
+	number, err := strconv.Atoi(issueURL[strings.LastIndex(issueURL, "/")+1:])
+	if err != nil {
+		return 0, "", fmt.Errorf("unexpected gh issue create output %q", issueURL)
+	}
+	return number, issueURL, nil
+}
+
 // GitHubCommentEntry represents a GitHub issue or PR comment with its database ID.
 type GitHubCommentEntry struct {
 	ID   int64
@@ -804,6 +1043,24 @@ func (s *GitService) DeleteIssueComment(ctx context.Context, repoPath string, co
 	return nil
 }
 
+// CreateRepoWebhook registers a repository webhook that delivers issue
+// events to url, signed with secret. Requires admin access to the repo.
+func (s *GitService) CreateRepoWebhook(ctx context.Context, repoPath, url, secret string) error {
+	_, _, err := s.executor.Run(ctx, repoPath, "gh", "api", "--method", "POST",
+		"repos/:owner/:repo/hooks",
+		"-f", "name=web",
+		"-f", fmt.Sprintf("config[url]=%s", url),
+		"-f", "config[content_type]=json",
+		"-f", fmt.Sprintf("config[secret]=%s", secret),
+		"-f", "events[]=issues",
+		"-F", "active=true",
+	)
+	if err != nil {
+		return fmt.Errorf("gh api create webhook failed: %w", err)
+	}
+	return nil
+}
+
 // GetPRNumber returns the PR number for the given branch name.
 func (s *GitService) GetPRNumber(ctx context.Context, repoPath, branch string) (int, error) {
 	output, err := s.executor.Output(ctx, repoPath, "gh", "pr", "view", branch, "--json", "number")
@@ -1390,13 +1647,13 @@ Diff:
 %s`, string(commitLog), fullDiff)
 
 	// Call Claude CLI
-	output, err := s.executor.Output(ctx, repoPath, "claude", "--print", "-p", prompt)
+	output, err := s.generateWithClaude(ctx, repoPath, "pr_title_body", prompt)
 	if err != nil {
 		log.Error("Claude PR generation failed", "error", err)
 		return "", "", fmt.Errorf("failed to generate PR with Claude: %w", err)
 	}
 
-	result := strings.TrimSpace(string(output))
+	result := strings.TrimSpace(output)
 
 	// Parse the output
 	titleMarker := "---TITLE---"
@@ -1510,6 +1767,52 @@ func (s *GitService) CreateRelease(ctx context.Context, repoPath, tag, title, no
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetPRBody returns the body of the pull request for the given branch using the gh CLI.
+func (s *GitService) GetPRBody(ctx context.Context, repoPath, branch string) (string, error) {
+	output, err := s.executor.Output(ctx, repoPath, "gh", "pr", "view", branch, "--json", "body")
+	if err != nil {
+		return "", fmt.Errorf("gh pr view failed: %w", err)
+	}
+
+	var result struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return "", fmt.Errorf("failed to parse PR body: %w", err)
+	}
+	return result.Body, nil
+}
+
+// PRFileChange is a file a pull request changes, with its line counts.
+type PRFileChange struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// PRDiffSummary describes what a pull request changed.
+type PRDiffSummary struct {
+	Title     string         `json:"title"`
+	Additions int            `json:"additions"`
+	Deletions int            `json:"deletions"`
+	Files     []PRFileChange `json:"files"`
+}
+
+// GetPRDiffSummary returns the title, line counts and changed files of a
+// pull request, given by number, URL or branch, using the gh CLI.
+func (s *GitService) GetPRDiffSummary(ctx context.Context, repoPath, pr string) (*PRDiffSummary, error) {
+	output, err := s.executor.Output(ctx, repoPath, "gh", "pr", "view", pr, "--json", "title,additions,deletions,files")
+	if err != nil {
+		return nil, fmt.Errorf("gh pr view failed: %w", err)
+	}
+
+	var summary PRDiffSummary
+	if err := json.Unmarshal(output, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse PR files: %w", err)
+	}
+	return &summary, nil
+}
+
 // UpdatePRBody updates the body of an existing pull request using the gh CLI.
 func (s *GitService) UpdatePRBody(ctx context.Context, repoPath, branch, body string) error {
 	_, _, err := s.executor.Run(ctx, repoPath, "gh", "pr", "edit", branch, "--body", body)
@@ -1591,12 +1894,12 @@ Commits in this branch:
 Diff:
 %s`, issueContext, strings.TrimSpace(string(commitLog)), fullDiff)
 
-	output, err := s.executor.Output(ctx, repoPath, "claude", "--print", "-p", prompt)
+	output, err := s.generateWithClaude(ctx, repoPath, "pr_description", prompt)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate PR description with Claude: %w", err)
 	}
 
-	body := strings.TrimSpace(string(output))
+	body := strings.TrimSpace(output)
 	if body == "" {
 		return "", fmt.Errorf("claude returned empty PR description")
 	}
@@ -1616,7 +1919,8 @@ Diff:
 // GetPRLinkText returns the appropriate text to add to a PR body based on the issue source.
 // For GitHub issues: returns "\n\nFixes #123"
 // For Linear issues: returns "\n\nFixes ENG-123" (Linear supports auto-close via identifier mentions)
-// For Asana tasks: returns "" (no auto-close support)
+// For GitLab issues: returns "\n\nCloses #123" (GitLab closes the issue when the MR merges)
+// For Asana tasks and ClickUp tasks: returns "" (no auto-close support)
 // For unknown sources: returns ""
 func GetPRLinkText(issueRef *config.IssueRef) string {
 	if issueRef == nil {
@@ -1628,6 +1932,8 @@ func GetPRLinkText(issueRef *config.IssueRef) string {
 		return fmt.Sprintf("\n\nFixes #%s", issueRef.ID)
 	case "linear":
 		return fmt.Sprintf("\n\nFixes %s", issueRef.ID)
+	case "gitlab":
+		return fmt.Sprintf("\n\nCloses #%s", issueRef.ID)
 	case "asana":
 		// Asana doesn't support auto-closing tasks via commit message keywords.
 		// Users can manually link PRs in Asana or use the Asana GitHub integration.
@@ -872,12 +872,12 @@ func TestGetBatchPRStatesWithComments_DraftTreatedAsOpen(t *testing.T) {
 
 func TestFetchGitHubIssuesWithLabel_WithLabel(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url", "--state", "open", "--label", "bug"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url,createdAt,labels,milestone", "--state", "open", "--label", "bug"}, pexec.MockResponse{
 		Stdout: []byte(`[{"number":1,"title":"Fix crash","body":"App crashes on startup","url":"https://github.com/repo/issues/1"}]`),
 	})
 
 	svc := NewGitServiceWithExecutor(mock)
-	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "bug")
+	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "bug", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -901,12 +901,12 @@ func TestFetchGitHubIssuesWithLabel_WithLabel(t *testing.T) {
 func TestFetchGitHubIssuesWithLabel_WithoutLabel(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
 	// When label is empty, no --label flag should be added
-	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url", "--state", "open"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url,createdAt,labels,milestone", "--state", "open"}, pexec.MockResponse{
 		Stdout: []byte(`[{"number":1,"title":"Issue 1","body":"","url":"https://github.com/repo/issues/1"},{"number":2,"title":"Issue 2","body":"","url":"https://github.com/repo/issues/2"}]`),
 	})
 
 	svc := NewGitServiceWithExecutor(mock)
-	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "")
+	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -915,14 +915,30 @@ func TestFetchGitHubIssuesWithLabel_WithoutLabel(t *testing.T) {
 	}
 }
 
+func TestFetchGitHubIssuesWithLabel_WithAssignee(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url,createdAt,labels,milestone", "--state", "open", "--label", "bug", "--assignee", "erg-bot"}, pexec.MockResponse{
+		Stdout: []byte(`[{"number":3,"title":"Assigned","body":"","url":"https://github.com/repo/issues/3"}]`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "bug", "erg-bot")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 3 {
+		t.Errorf("expected only the assigned issue, got %+v", issues)
+	}
+}
+
 func TestFetchGitHubIssuesWithLabel_CLIError(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url", "--state", "open", "--label", "bug"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "list", "--json", "number,title,body,url,createdAt,labels,milestone", "--state", "open", "--label", "bug"}, pexec.MockResponse{
 		Err: fmt.Errorf("not a git repository"),
 	})
 
 	svc := NewGitServiceWithExecutor(mock)
-	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "bug")
+	issues, err := svc.FetchGitHubIssuesWithLabel(context.Background(), "/repo", "bug", "")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -2768,6 +2784,23 @@ func TestCloseIssue(t *testing.T) {
 	})
 }
 
+func TestClosePR(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"pr", "close", "erg/issue-42", "--comment", "Stuck for a week.", "--delete-branch"}, pexec.MockResponse{})
+	svc := NewGitServiceWithExecutor(mock)
+	if err := svc.ClosePR(context.Background(), "/repo", "erg/issue-42", "Stuck for a week."); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock = pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"pr", "close", "erg/issue-42", "--comment", "Stuck for a week.", "--delete-branch"}, pexec.MockResponse{
+		Err: fmt.Errorf("no pull requests found"),
+	})
+	if err := NewGitServiceWithExecutor(mock).ClosePR(context.Background(), "/repo", "erg/issue-42", "Stuck for a week."); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
 func TestRequestPRReview(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mock := pexec.NewMockExecutor(nil)
@@ -2780,6 +2813,28 @@ func TestRequestPRReview(t *testing.T) {
 	})
 }
 
+func TestGetPRBody(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddPrefixMatch("gh", []string{"pr", "view", "feature-branch", "--json", "body"}, pexec.MockResponse{
+		Stdout: []byte(`{"body":"## Summary\nFixes the thing."}`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	body, err := svc.GetPRBody(context.Background(), "/repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body != "## Summary\nFixes the thing." {
+		t.Errorf("body = %q", body)
+	}
+
+	mock = pexec.NewMockExecutor(nil)
+	mock.AddPrefixMatch("gh", []string{"pr", "view"}, pexec.MockResponse{Err: fmt.Errorf("no pull requests found")})
+	if _, err := NewGitServiceWithExecutor(mock).GetPRBody(context.Background(), "/repo", "feature-branch"); err == nil {
+		t.Error("expected error when gh fails")
+	}
+}
+
 func TestUpdatePRBody_Success(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
 	mock.AddPrefixMatch("gh", []string{"pr", "edit", "feature-branch", "--body"}, pexec.MockResponse{})
@@ -3096,6 +3151,11 @@ func TestGetPRLinkText(t *testing.T) {
 			issueRef: &config.IssueRef{Source: "linear", ID: "ENG-123"},
 			expected: "\n\nFixes ENG-123",
 		},
+		{
+			name:     "gitlab issue",
+			issueRef: &config.IssueRef{Source: "gitlab", ID: "7"},
+			expected: "\n\nCloses #7",
+		},
 		{
 			name:     "asana task",
 			issueRef: &config.IssueRef{Source: "asana", ID: "1234567890"},
@@ -3295,7 +3355,7 @@ func TestCheckUserIsCollaborator_NotCollaborator(t *testing.T) {
 
 func TestGetGitHubIssue_Success(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "view", "42", "--json", "number,title,body,url"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "view", "42", "--json", "number,title,body,url,createdAt,labels,milestone"}, pexec.MockResponse{
 		Stdout: []byte(`{"number":42,"title":"Fix the bug","body":"This is the body","url":"https://github.com/owner/repo/issues/42"}`),
 	})
 
@@ -3318,9 +3378,58 @@ func TestGetGitHubIssue_Success(t *testing.T) {
 	}
 }
 
+func TestGetIssueOrPR_DetectsPullRequests(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/issues/12"}, pexec.MockResponse{
+		Stdout: []byte(`{"number":12,"title":"Add login","body":"Adds it","state":"closed","html_url":"https://github.com/owner/repo/pull/12","pull_request":{"url":"x"}}`),
+	})
+	mock.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/issues/13"}, pexec.MockResponse{
+		Stdout: []byte(`{"number":13,"title":"Login broken","state":"open","html_url":"https://github.com/owner/repo/issues/13"}`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	pr, err := svc.GetIssueOrPR(context.Background(), "/repo", 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !pr.IsPR || pr.State != "closed" || pr.Title != "Add login" || pr.URL != "https://github.com/owner/repo/pull/12" {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	issue, err := svc.GetIssueOrPR(context.Background(), "/repo", 13)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.IsPR || issue.State != "open" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+}
+
+func TestCreateRepoWebhook(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddPrefixMatch("gh", []string{"api", "--method", "POST", "repos/:owner/:repo/hooks"}, pexec.MockResponse{
+		Stdout: []byte(`{"id":1}`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	if err := svc.CreateRepoWebhook(context.Background(), "/repo", "https://erg.example.com/webhooks/github", "shh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := mock.GetCalls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	args := strings.Join(calls[0].Args, " ")
+	for _, want := range []string{"config[url]=https://erg.example.com/webhooks/github", "config[secret]=shh", "events[]=issues"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in args: %s", want, args)
+		}
+	}
+}
+
 func TestGetGitHubIssue_CLIError(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "view", "99", "--json", "number,title,body,url"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "view", "99", "--json", "number,title,body,url,createdAt,labels,milestone"}, pexec.MockResponse{
 		Err: fmt.Errorf("issue not found"),
 	})
 
@@ -3336,7 +3445,7 @@ func TestGetGitHubIssue_CLIError(t *testing.T) {
 
 func TestGetGitHubIssue_InvalidJSON(t *testing.T) {
 	mock := pexec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "view", "1", "--json", "number,title,body,url"}, pexec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "view", "1", "--json", "number,title,body,url,createdAt,labels,milestone"}, pexec.MockResponse{
 		Stdout: []byte(`not valid json`),
 	})
 
@@ -3346,3 +3455,140 @@ func TestGetGitHubIssue_InvalidJSON(t *testing.T) {
 		t.Fatal("expected error for invalid JSON, got nil")
 	}
 }
+
+func TestListPRsByHeadPrefix(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"pr", "list", "--state", "all", "--limit", "50", "--json", "number,title,url,headRefName,state,createdAt,mergedAt,closedAt"}, pexec.MockResponse{
+		Stdout: []byte(`[
+			{"number":3,"title":"Fix bug","url":"https://github.com/o/r/pull/3","headRefName":"erg/issue-12","state":"MERGED","createdAt":"2026-01-01T10:00:00Z","mergedAt":"2026-01-01T12:00:00Z","closedAt":"2026-01-01T12:00:00Z"},
+			{"number":2,"title":"Human PR","url":"https://github.com/o/r/pull/2","headRefName":"feature-x","state":"OPEN","createdAt":"2026-01-01T09:00:00Z","mergedAt":"0001-01-01T00:00:00Z","closedAt":"0001-01-01T00:00:00Z"},
+			{"number":1,"title":"Abandoned","url":"https://github.com/o/r/pull/1","headRefName":"erg/issue-9","state":"CLOSED","createdAt":"2026-01-01T08:00:00Z","mergedAt":null,"closedAt":"2026-01-02T08:00:00Z"}
+		]`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	prs, err := svc.ListPRsByHeadPrefix(context.Background(), "/repo", "erg/", 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs matching prefix, got %d", len(prs))
+	}
+	if prs[0].Number != 3 || prs[0].State != PRStateMerged || prs[0].MergedAt == nil {
+		t.Errorf("unexpected merged PR: %+v", prs[0])
+	}
+	if prs[1].State != PRStateClosed || prs[1].MergedAt != nil || prs[1].ClosedAt == nil {
+		t.Errorf("unexpected closed PR: %+v", prs[1])
+	}
+}
+
+// --- CreateIssue tests ---
+
+func TestCreateIssue_Success(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"issue", "create", "--title", "Follow up", "--body", "Details", "--label", "ai-assisted", "--label", "bug"}, pexec.MockResponse{
+		Stdout: []byte("https://github.com/owner/repo/issues/57\n"),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	number, url, err := svc.CreateIssue(context.Background(), "/repo", "Follow up", "Details", []string{"ai-assisted", "bug"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if number != 57 || url != "https://github.com/owner/repo/issues/57" {
+		t.Errorf("got (%d, %q), want (57, issue URL)", number, url)
+	}
+}
+
+func TestCreateIssue_Error(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddPrefixMatch("gh", []string{"issue", "create"}, pexec.MockResponse{
+		Stderr: []byte("could not add label: 'nope' not found"),
+		Err:    fmt.Errorf("exit status 1"),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	_, _, err := svc.CreateIssue(context.Background(), "/repo", "Follow up", "", []string{"nope"})
+	if err == nil || !strings.Contains(err.Error(), "gh issue create failed") || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateIssue_UnexpectedOutput(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddPrefixMatch("gh", []string{"issue", "create"}, pexec.MockResponse{Stdout: []byte("Creating issue...\n")})
+
+	svc := NewGitServiceWithExecutor(mock)
+	if _, _, err := svc.CreateIssue(context.Background(), "/repo", "Follow up", "", nil); err == nil {
+		t.Error("expected error for output without an issue URL")
+	}
+}
+
+func TestGetIssueBlockedBy(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/issues/20/dependencies/blocked_by"}, pexec.MockResponse{
+		Stdout: []byte(`[{"number":12,"title":"Add schema","state":"closed","html_url":"https://github.com/owner/repo/issues/12"},{"number":14,"title":"Add API","state":"open"}]`),
+	})
+
+	svc := NewGitServiceWithExecutor(mock)
+	blockers, err := svc.GetIssueBlockedBy(context.Background(), "/repo", 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(blockers) != 2 || blockers[0].Number != 12 || blockers[0].State != "closed" || blockers[1].State != "open" {
+		t.Errorf("unexpected blockers: %+v", blockers)
+	}
+
+	if _, err := svc.GetIssueBlockedBy(context.Background(), "/repo", 21); err == nil {
+		t.Error("expected error when gh fails")
+	}
+}
+
+func TestGetPRDiffSummary(t *testing.T) {
+	mock := pexec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"pr", "view", "https://github.com/owner/repo/pull/12", "--json", "title,additions,deletions,files"}, pexec.MockResponse{
+		Stdout: []byte(`{"title":"Add schema","additions":40,"deletions":5,"files":[{"path":"db/schema.sql","additions":30,"deletions":0},{"path":"db/migrate.go","additions":10,"deletions":5}]}`),
+	})
+
+	summary, err := NewGitServiceWithExecutor(mock).GetPRDiffSummary(context.Background(), "/repo", "https://github.com/owner/repo/pull/12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Title != "Add schema" || summary.Additions != 40 || len(summary.Files) != 2 || summary.Files[1].Path != "db/migrate.go" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestCheckUserCanWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission string
+		err        error
+		want       bool
+		wantErr    bool
+	}{
+		{"admin", "admin\n", nil, true, false},
+		{"write", "write\n", nil, true, false},
+		{"read", "read\n", nil, false, false},
+		{"none", "none\n", nil, false, false},
+		{"api error", "", fmt.Errorf("HTTP 404: Not Found"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := pexec.NewMockExecutor(nil)
+			mock.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/collaborators/bob/permission", "--jq", ".permission"}, pexec.MockResponse{
+				Stdout: []byte(tt.permission),
+				Err:    tt.err,
+			})
+
+			svc := NewGitServiceWithExecutor(mock)
+			got, err := svc.CheckUserCanWrite(context.Background(), "/repo", "bob")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CheckUserCanWrite = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
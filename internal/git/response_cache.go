@@ -0,0 +1,132 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zhubert/erg/internal/logger"
+)
+
+// DefaultResponseCacheTTL is how long a cached Claude response is reused.
+const DefaultResponseCacheTTL = 24 * time.Hour
+
+// ResponseCache keeps the output of deterministic Claude calls (commit
+// messages, PR titles and descriptions) on disk, keyed by a hash of the
+// call's kind and prompt. The prompt holds the whole input — the diff and
+// commit log — so an identical prompt may reuse the earlier answer, and a
+// retried step or restarted daemon doesn't pay for it twice.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// cachedResponse is a ResponseCache entry on disk.
+type cachedResponse struct {
+	Kind     string    `json:"kind"`
+	Output   string    `json:"output"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// NewResponseCache returns a cache keeping entries in dir for ttl.
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// responseCacheKey hashes a call's kind and prompt.
+func responseCacheKey(kind, prompt string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *ResponseCache) path(kind, prompt string) string {
+	return filepath.Join(c.dir, responseCacheKey(kind, prompt)+".json")
+}
+
+// Get returns the cached output for kind and prompt, if there is one not
+// older than the TTL.
+func (c *ResponseCache) Get(kind, prompt string) (string, bool) {
+	data, err := os.ReadFile(c.path(kind, prompt))
+	if err != nil {
+		return "", false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Kind != kind {
+		return "", false
+	}
+	if c.now().Sub(entry.CachedAt) >= c.ttl {
+		return "", false
+	}
+	return entry.Output, true
+}
+
+// Put caches output for kind and prompt.
+func (c *ResponseCache) Put(kind, prompt, output string) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedResponse{Kind: kind, Output: output, CachedAt: c.now()})
+	if err != nil {
+		return err
+	}
+	path := c.path(kind, prompt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Prune removes entries older than the TTL and returns how many it removed.
+func (c *ResponseCache) Prune() (int, error) {
+	matches, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || c.now().Sub(info.ModTime()) < c.ttl {
+			continue
+		}
+		if err := os.Remove(m); err == nil {
+			count++
+		} else if !os.IsNotExist(err) {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// SetResponseCache makes the service reuse cached answers to its Claude
+// calls. A nil cache, the default, calls Claude every time.
+func (s *GitService) SetResponseCache(c *ResponseCache) {
+	s.responseCache = c
+}
+
+// generateWithClaude runs prompt through `claude --print` in dir, answering
+// from the response cache when it holds one for kind and prompt. Only
+// non-empty answers are cached.
+func (s *GitService) generateWithClaude(ctx context.Context, dir, kind, prompt string) (string, error) {
+	if s.responseCache != nil {
+		if out, ok := s.responseCache.Get(kind, prompt); ok {
+			logger.WithComponent("git").Debug("using cached Claude response", "kind", kind)
+			return out, nil
+		}
+	}
+	output, err := s.executor.Output(ctx, dir, "claude", "--print", "-p", prompt)
+	if err != nil {
+		return "", err
+	}
+	if s.responseCache != nil && len(output) > 0 {
+		if err := s.responseCache.Put(kind, prompt, string(output)); err != nil {
+			logger.WithComponent("git").Warn("failed to cache Claude response", "kind", kind, "error", err)
+		}
+	}
+	return string(output), nil
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pexec "github.com/zhubert/erg/internal/exec"
+)
+
+func TestResponseCache_GetPutExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := NewResponseCache(t.TempDir(), time.Hour)
+	c.now = func() time.Time { return now }
+
+	if _, ok := c.Get("commit_message", "diff A"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	if err := c.Put("commit_message", "diff A", "Fix the thing"); err != nil {
+		t.Fatal(err)
+	}
+	if out, ok := c.Get("commit_message", "diff A"); !ok || out != "Fix the thing" {
+		t.Errorf("Get = %q, %v; want the cached output", out, ok)
+	}
+	if _, ok := c.Get("commit_message", "diff B"); ok {
+		t.Error("expected a different prompt to miss")
+	}
+	if _, ok := c.Get("pr_description", "diff A"); ok {
+		t.Error("expected a different kind to miss")
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := c.Get("commit_message", "diff A"); ok {
+		t.Error("expected an entry past its TTL to miss")
+	}
+}
+
+func TestResponseCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	c := NewResponseCache(dir, time.Hour)
+	c.Put("commit_message", "old", "a")
+	c.Put("commit_message", "new", "b")
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(c.path("commit_message", "old"), old, old)
+
+	if n, err := c.Prune(); err != nil || n != 1 {
+		t.Fatalf("Prune = %d, %v; want 1", n, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(matches) != 1 {
+		t.Errorf("expected one entry left, got %v", matches)
+	}
+}
+
+func TestGenerateRichPRDescription_UsesResponseCache(t *testing.T) {
+	mockExec := pexec.NewMockExecutor(nil)
+	mockExec.AddPrefixMatch("git", []string{"log"}, pexec.MockResponse{Stdout: []byte("abc123 Add retries\n")})
+	mockExec.AddPrefixMatch("git", []string{"diff"}, pexec.MockResponse{Stdout: []byte("+retry()\n")})
+	mockExec.AddPrefixMatch("claude", []string{"--print", "-p"}, pexec.MockResponse{Stdout: []byte("## Summary\nAdds retries.")})
+	svc := NewGitServiceWithExecutor(mockExec)
+	svc.SetResponseCache(NewResponseCache(t.TempDir(), time.Hour))
+
+	for range 2 {
+		body, err := svc.GenerateRichPRDescription(context.Background(), "/test/repo", "feature", "main", nil)
+		if err != nil || body != "## Summary\nAdds retries." {
+			t.Fatalf("GenerateRichPRDescription = %q, %v", body, err)
+		}
+	}
+
+	claudeCalls := 0
+	for _, call := range mockExec.GetCalls() {
+		if call.Name == "claude" {
+			claudeCalls++
+		}
+	}
+	if claudeCalls != 1 {
+		t.Errorf("expected Claude called once and the repeat answered from the cache, got %d calls", claudeCalls)
+	}
+}
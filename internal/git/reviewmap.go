@@ -0,0 +1,437 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Review map section markers. The section between them is replaced when the
+// map is regenerated.
+const (
+	reviewMapStart = "<!-- erg:review-map -->"
+	reviewMapEnd   = "<!-- /erg:review-map -->"
+
+	// reviewMapMaxRows caps the table so very large PRs stay readable.
+	reviewMapMaxRows = 40
+	// reviewMapLargeChange is the changed-line count above which a file is
+	// flagged as a large change.
+	reviewMapLargeChange = 300
+)
+
+// ReviewFile is one file from a branch diff, as used to build a review map.
+type ReviewFile struct {
+	Path      string
+	Status    string // A (added), M (modified), D (deleted), R (renamed)
+	Additions int
+	Deletions int
+	Added     []string // Added lines, without the leading "+"
+}
+
+// fileKind groups files for the suggested review order.
+type fileKind int
+
+const (
+	kindSchema fileKind = iota // migrations and API/schema definitions
+	kindSource
+	kindConfig // CI, build, and dependency manifests
+	kindTest
+	kindDocs
+	kindGenerated
+)
+
+// riskLevel is a coarse per-file risk rating.
+type riskLevel int
+
+const (
+	riskLow riskLevel = iota
+	riskMedium
+	riskHigh
+)
+
+func (r riskLevel) String() string {
+	switch r {
+	case riskHigh:
+		return "high"
+	case riskMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// riskPattern flags added lines matching re with note.
+type riskPattern struct {
+	re   *regexp.Regexp
+	note string
+	high bool
+}
+
+// language holds the review heuristics for one programming language.
+type language struct {
+	exts     []string
+	isTest   func(p string) bool
+	tests    func(p string) []string // candidate test files for a source file
+	patterns []riskPattern
+}
+
+var languages = []language{
+	{
+		exts:   []string{".go"},
+		isTest: func(p string) bool { return strings.HasSuffix(p, "_test.go") },
+		tests: func(p string) []string {
+			return []string{strings.TrimSuffix(p, ".go") + "_test.go"}
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\bgo (func\b|[\w.]+\()`), note: "starts goroutines — check for leaks and races"},
+			{re: regexp.MustCompile(`\bsync\.(Mutex|RWMutex|WaitGroup|Once)\b|\.R?Lock\(\)`), note: "changes locking"},
+			{re: regexp.MustCompile(`\bunsafe\.`), note: "uses unsafe", high: true},
+			{re: regexp.MustCompile(`\bexec\.Command(Context)?\(`), note: "runs external commands — check argument handling"},
+			{re: regexp.MustCompile(`\bpanic\(`), note: "adds panics"},
+		},
+	},
+	{
+		exts: []string{".py"},
+		isTest: func(p string) bool {
+			base := path.Base(p)
+			return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") || base == "conftest.py"
+		},
+		tests: func(p string) []string {
+			dir, base := path.Split(p)
+			name := strings.TrimSuffix(base, ".py")
+			candidates := []string{dir + "test_" + base, dir + name + "_test.py", "tests/test_" + base}
+			if rest, ok := strings.CutPrefix(dir, "src/"); ok {
+				candidates = append(candidates, "tests/"+rest+"test_"+base)
+			} else if dir != "" {
+				candidates = append(candidates, "tests/"+dir+"test_"+base)
+			}
+			return candidates
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\b(subprocess\.|os\.system\()`), note: "runs external commands — check argument handling"},
+			{re: regexp.MustCompile(`\b(eval|exec)\(`), note: "executes dynamic code", high: true},
+			{re: regexp.MustCompile(`\bpickle\.loads?\(`), note: "unpickles data — check the source is trusted", high: true},
+			{re: regexp.MustCompile(`\b(threading|asyncio|multiprocessing)\.`), note: "adds concurrency"},
+		},
+	},
+	{
+		exts: []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"},
+		isTest: func(p string) bool {
+			return strings.Contains(p, "__tests__/") || strings.Contains(path.Base(p), ".test.") || strings.Contains(path.Base(p), ".spec.")
+		},
+		tests: func(p string) []string {
+			dir, base := path.Split(p)
+			ext := path.Ext(base)
+			name := strings.TrimSuffix(base, ext)
+			return []string{
+				dir + name + ".test" + ext,
+				dir + name + ".spec" + ext,
+				dir + "__tests__/" + name + ".test" + ext,
+			}
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\beval\(|\bnew Function\(`), note: "executes dynamic code", high: true},
+			{re: regexp.MustCompile(`dangerouslySetInnerHTML|\.innerHTML\s*=`), note: "writes raw HTML — check for XSS", high: true},
+			{re: regexp.MustCompile(`\bchild_process\b`), note: "runs external commands — check argument handling"},
+			{re: regexp.MustCompile(`:\s*any\b|\bas any\b`), note: "loosens types with any"},
+		},
+	},
+	{
+		exts: []string{".rb"},
+		isTest: func(p string) bool {
+			return strings.HasSuffix(p, "_spec.rb") || strings.HasSuffix(p, "_test.rb")
+		},
+		tests: func(p string) []string {
+			rel := strings.TrimSuffix(p, ".rb")
+			for _, prefix := range []string{"app/", "lib/"} {
+				rel = strings.TrimPrefix(rel, prefix)
+			}
+			return []string{"spec/" + rel + "_spec.rb", "test/" + rel + "_test.rb"}
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\.html_safe\b|\braw\(`), note: "marks HTML as safe — check for XSS", high: true},
+			{re: regexp.MustCompile(`\b(eval|instance_eval|send)\(`), note: "executes dynamic code"},
+			{re: regexp.MustCompile("\\bsystem\\(|`[^`]+`"), note: "runs external commands — check argument handling"},
+		},
+	},
+	{
+		exts:   []string{".rs"},
+		isTest: func(p string) bool { return strings.HasPrefix(p, "tests/") || strings.Contains(p, "/tests/") },
+		tests: func(p string) []string {
+			return []string{"tests/" + strings.TrimSuffix(path.Base(p), ".rs") + ".rs"}
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\bunsafe\s*(\{|fn\b|impl\b)`), note: "uses unsafe", high: true},
+			{re: regexp.MustCompile(`\.unwrap\(\)|\.expect\(`), note: "adds unwrap/expect calls that can panic"},
+			{re: regexp.MustCompile(`\b(thread|tokio)::spawn\b`), note: "adds concurrency"},
+		},
+	},
+	{
+		exts:   []string{".java", ".kt"},
+		isTest: func(p string) bool { return strings.Contains(p, "src/test/") },
+		tests: func(p string) []string {
+			ext := path.Ext(p)
+			rel := strings.Replace(strings.TrimSuffix(p, ext), "src/main/", "src/test/", 1)
+			return []string{rel + "Test" + ext}
+		},
+		patterns: []riskPattern{
+			{re: regexp.MustCompile(`\bsynchronized\b|\bExecutorService\b|\bnew Thread\(`), note: "adds concurrency"},
+			{re: regexp.MustCompile(`Runtime\.getRuntime\(\)\.exec|\bProcessBuilder\b`), note: "runs external commands — check argument handling"},
+		},
+	},
+}
+
+// todoPattern flags leftover TODO/FIXME markers in any language.
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME|XXX)\b`)
+
+// sensitivePathRe matches paths in security-sensitive areas.
+var sensitivePathRe = regexp.MustCompile(`(?i)(^|[/_.-])(auth|oauth|crypto|secrets?|passwords?|permissions?|security)`)
+
+// languageFor returns the language for a file path, or nil when unknown.
+func languageFor(p string) *language {
+	ext := path.Ext(p)
+	for i := range languages {
+		if slices.Contains(languages[i].exts, ext) {
+			return &languages[i]
+		}
+	}
+	return nil
+}
+
+// classifyFile returns the review group for a file path.
+func classifyFile(p string) fileKind {
+	base := path.Base(p)
+	lower := strings.ToLower(p)
+	switch {
+	case base == "go.sum" || base == "package-lock.json" || base == "yarn.lock" || base == "pnpm-lock.yaml" ||
+		base == "Cargo.lock" || base == "Gemfile.lock" || base == "poetry.lock" ||
+		strings.HasSuffix(base, ".pb.go") || strings.HasPrefix(base, "zz_generated") ||
+		strings.Contains(lower, "generated") || strings.HasSuffix(base, ".min.js"):
+		return kindGenerated
+	case strings.Contains(lower, "migration") || path.Ext(p) == ".sql" || path.Ext(p) == ".proto" ||
+		strings.HasSuffix(base, ".graphql") || strings.Contains(lower, "openapi"):
+		return kindSchema
+	case strings.HasPrefix(p, ".github/") || base == ".gitlab-ci.yml" || base == "Jenkinsfile" ||
+		base == "Dockerfile" || base == "Makefile" || base == "go.mod" || base == "package.json" ||
+		base == "Cargo.toml" || base == "pyproject.toml" || base == "Gemfile" || base == "requirements.txt":
+		return kindConfig
+	case path.Ext(p) == ".md" || path.Ext(p) == ".rst" || path.Ext(p) == ".txt" || strings.HasPrefix(p, "docs/"):
+		return kindDocs
+	}
+	if lang := languageFor(p); lang != nil && lang.isTest(p) {
+		return kindTest
+	}
+	return kindSource
+}
+
+// reviewEntry is one row of the review map.
+type reviewEntry struct {
+	file  ReviewFile
+	kind  fileKind
+	risk  riskLevel
+	notes []string
+	tests []string
+}
+
+// assessFile computes risk notes and covering tests for one changed file.
+// changed holds the paths of all files in the diff; repo is the worktree.
+func assessFile(f ReviewFile, changed map[string]bool, repo fs.FS) reviewEntry {
+	e := reviewEntry{file: f, kind: classifyFile(f.Path)}
+	high := false
+
+	switch e.kind {
+	case kindSchema:
+		e.notes = append(e.notes, "schema or migration — check compatibility with existing data and clients")
+		high = true
+	case kindGenerated:
+		e.notes = append(e.notes, "generated or lock file — skim")
+	case kindConfig:
+		if strings.HasPrefix(f.Path, ".github/") || path.Base(f.Path) == ".gitlab-ci.yml" {
+			e.notes = append(e.notes, "CI pipeline change")
+		} else {
+			e.notes = append(e.notes, "build or dependency change")
+		}
+	}
+	if f.Status == "D" {
+		e.notes = append(e.notes, "file deleted — check for remaining references")
+	}
+	if e.kind == kindSource && sensitivePathRe.MatchString(f.Path) {
+		e.notes = append(e.notes, "security-sensitive area")
+		high = true
+	}
+	if n := f.Additions + f.Deletions; n >= reviewMapLargeChange {
+		e.notes = append(e.notes, fmt.Sprintf("large change (%d lines)", n))
+	}
+
+	lang := languageFor(f.Path)
+	if lang != nil && e.kind != kindGenerated {
+		for _, rp := range lang.patterns {
+			if slices.ContainsFunc(f.Added, rp.re.MatchString) {
+				e.notes = append(e.notes, rp.note)
+				high = high || rp.high
+			}
+		}
+	}
+	if e.kind == kindSource && slices.ContainsFunc(f.Added, todoPattern.MatchString) {
+		e.notes = append(e.notes, "leaves TODO/FIXME markers")
+	}
+
+	if lang != nil && e.kind == kindSource && f.Status != "D" {
+		for _, candidate := range lang.tests(f.Path) {
+			if changed[candidate] {
+				e.tests = append(e.tests, fmt.Sprintf("`%s` (updated)", candidate))
+			} else if _, err := fs.Stat(repo, candidate); err == nil {
+				e.tests = append(e.tests, fmt.Sprintf("`%s`", candidate))
+			}
+		}
+		if len(e.tests) == 0 {
+			e.notes = append(e.notes, "no covering tests found")
+		}
+	}
+
+	switch {
+	case high:
+		e.risk = riskHigh
+	case e.kind == kindSource && len(e.notes) > 0:
+		e.risk = riskMedium
+	}
+	return e
+}
+
+// BuildReviewMap renders the "Review map" PR body section for the changed
+// files: a suggested review order (schemas first, then source riskiest first,
+// then config, tests, docs, and generated files), per-file risk notes, and
+// the tests covering each source file. repo is the worktree, used to find
+// existing test files. Returns "" when files is empty.
+func BuildReviewMap(files []ReviewFile, repo fs.FS) string {
+	if len(files) == 0 {
+		return ""
+	}
+	changed := make(map[string]bool, len(files))
+	for _, f := range files {
+		changed[f.Path] = true
+	}
+
+	entries := make([]reviewEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, assessFile(f, changed, repo))
+	}
+	slices.SortStableFunc(entries, func(a, b reviewEntry) int {
+		if a.kind != b.kind {
+			return int(a.kind) - int(b.kind)
+		}
+		if a.risk != b.risk {
+			return int(b.risk) - int(a.risk)
+		}
+		return (b.file.Additions + b.file.Deletions) - (a.file.Additions + a.file.Deletions)
+	})
+
+	var sb strings.Builder
+	sb.WriteString(reviewMapStart + "\n## Review map\n\n")
+	sb.WriteString("Suggested review order, generated from the diff.\n\n")
+	sb.WriteString("| # | File | Change | Risk | Notes | Tests |\n|---|---|---|---|---|---|\n")
+	for i, e := range entries {
+		if i == reviewMapMaxRows {
+			fmt.Fprintf(&sb, "\n…and %d more files.\n", len(entries)-reviewMapMaxRows)
+			break
+		}
+		notes, tests := strings.Join(e.notes, "; "), strings.Join(e.tests, ", ")
+		if notes == "" {
+			notes = "—"
+		}
+		if tests == "" {
+			tests = "—"
+		}
+		fmt.Fprintf(&sb, "| %d | `%s` | +%d −%d | %s | %s | %s |\n",
+			i+1, e.file.Path, e.file.Additions, e.file.Deletions, e.risk, notes, tests)
+	}
+	sb.WriteString(reviewMapEnd)
+	return sb.String()
+}
+
+// ParseReviewDiff splits a unified diff (as produced by git diff) into
+// per-file change records.
+func ParseReviewDiff(diff string) []ReviewFile {
+	var files []ReviewFile
+	var cur *ReviewFile
+	inHunk := false
+	for line := range strings.SplitSeq(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, ReviewFile{Status: "M"})
+			cur = &files[len(files)-1]
+			inHunk = false
+			// "diff --git a/<old> b/<new>": take the new path.
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				cur.Path = line[i+3:]
+			}
+		case cur == nil:
+			continue
+		case !inHunk && strings.HasPrefix(line, "new file mode"):
+			cur.Status = "A"
+		case !inHunk && strings.HasPrefix(line, "deleted file mode"):
+			cur.Status = "D"
+		case !inHunk && strings.HasPrefix(line, "rename to "):
+			cur.Status = "R"
+			cur.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			cur.Additions++
+			cur.Added = append(cur.Added, line[1:])
+		case inHunk && strings.HasPrefix(line, "-"):
+			cur.Deletions++
+		}
+	}
+	return files
+}
+
+// WithReviewMap returns body with the review map section replaced or, if
+// absent, appended. The section is kept ahead of a trailing erg fingerprint
+// footer. An empty reviewMap removes the section.
+func WithReviewMap(body, reviewMap string) string {
+	if start := strings.Index(body, reviewMapStart); start >= 0 {
+		if end := strings.Index(body[start:], reviewMapEnd); end >= 0 {
+			body = strings.TrimRight(body[:start], "\n") + body[start+end+len(reviewMapEnd):]
+		}
+	}
+	if reviewMap == "" {
+		return body
+	}
+	footer := ""
+	if i := strings.Index(body, "<!-- erg:fingerprint"); i >= 0 {
+		body, footer = body[:i], "\n\n"+body[i:]
+	}
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return reviewMap + footer
+	}
+	return body + "\n\n" + reviewMap + footer
+}
+
+// GenerateReviewMap builds the review map for the changes on branch relative
+// to baseBranch, looking up test files in worktreePath.
+func (s *GitService) GenerateReviewMap(ctx context.Context, worktreePath, branch, baseBranch string) (string, error) {
+	if baseBranch == "" {
+		baseBranch = s.GetDefaultBranch(ctx, worktreePath)
+	}
+
+	// Compare against origin/<baseBranch> when it exists, as PR generation does.
+	comparisonRef := baseBranch
+	candidateRef := fmt.Sprintf("origin/%s", baseBranch)
+	if _, _, err := s.executor.Run(ctx, worktreePath, "git", "rev-parse", "--verify", candidateRef); err == nil {
+		comparisonRef = candidateRef
+	}
+
+	diffOutput, err := s.executor.Output(ctx, worktreePath, "git", "diff", "--no-ext-diff", "-M",
+		fmt.Sprintf("%s...%s", comparisonRef, branch))
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+	return BuildReviewMap(ParseReviewDiff(string(diffOutput)), os.DirFS(worktreePath)), nil
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const reviewMapTestDiff = `diff --git a/internal/worker/pool.go b/internal/worker/pool.go
+index 1111111..2222222 100644
+--- a/internal/worker/pool.go
++++ b/internal/worker/pool.go
+@@ -10,3 +10,5 @@ func (p *Pool) Start() {
+-	p.run()
++	go func() {
++		p.run()
++	}()
+ }
+diff --git a/internal/worker/pool_test.go b/internal/worker/pool_test.go
+index 3333333..4444444 100644
+--- a/internal/worker/pool_test.go
++++ b/internal/worker/pool_test.go
+@@ -1,1 +1,2 @@
++// new case
+diff --git a/db/migrations/002_add_index.sql b/db/migrations/002_add_index.sql
+new file mode 100644
+--- /dev/null
++++ b/db/migrations/002_add_index.sql
+@@ -0,0 +1 @@
++CREATE INDEX idx ON items(id);
+diff --git a/web/src/view.tsx b/web/src/view.tsx
+index 5555555..6666666 100644
+--- a/web/src/view.tsx
++++ b/web/src/view.tsx
+@@ -1 +1 @@
+-const x = 1
++const x: any = 1
+diff --git a/README.md b/README.md
+deleted file mode 100644
+--- a/README.md
++++ /dev/null
+@@ -1 +0,0 @@
+-# Old
+`
+
+func TestParseReviewDiff(t *testing.T) {
+	files := ParseReviewDiff(reviewMapTestDiff)
+	if len(files) != 5 {
+		t.Fatalf("expected 5 files, got %d: %+v", len(files), files)
+	}
+	pool := files[0]
+	if pool.Path != "internal/worker/pool.go" || pool.Status != "M" || pool.Additions != 3 || pool.Deletions != 1 {
+		t.Errorf("pool.go = %+v", pool)
+	}
+	if files[2].Status != "A" || files[4].Status != "D" || files[4].Deletions != 1 {
+		t.Errorf("statuses = %q, %q", files[2].Status, files[4].Status)
+	}
+
+	renamed := ParseReviewDiff("diff --git a/old.go b/new.go\nsimilarity index 90%\nrename from old.go\nrename to new.go\n")
+	if len(renamed) != 1 || renamed[0].Path != "new.go" || renamed[0].Status != "R" {
+		t.Errorf("rename = %+v", renamed)
+	}
+}
+
+func TestBuildReviewMap(t *testing.T) {
+	repo := fstest.MapFS{"web/src/view.test.tsx": &fstest.MapFile{}}
+	got := BuildReviewMap(ParseReviewDiff(reviewMapTestDiff), repo)
+
+	if !strings.HasPrefix(got, reviewMapStart) || !strings.HasSuffix(got, reviewMapEnd) {
+		t.Errorf("expected review map markers, got %q", got)
+	}
+
+	// Schema first, then source (riskiest/largest first), then tests, then docs.
+	order := []string{"db/migrations/002_add_index.sql", "internal/worker/pool.go", "web/src/view.tsx", "internal/worker/pool_test.go", "README.md"}
+	last := -1
+	for _, path := range order {
+		i := strings.Index(got, "| `"+path+"` |")
+		if i < 0 || i < last {
+			t.Fatalf("expected %s in review order %v, got:\n%s", path, order, got)
+		}
+		last = i
+	}
+
+	for _, want := range []string{
+		"| 1 | `db/migrations/002_add_index.sql` | +1 −0 | high | schema or migration",
+		"starts goroutines",
+		"`internal/worker/pool_test.go` (updated)",
+		"loosens types with any",
+		"`web/src/view.test.tsx`",
+		"file deleted",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in review map:\n%s", want, got)
+		}
+	}
+
+	if BuildReviewMap(nil, repo) != "" {
+		t.Error("expected empty review map for no changes")
+	}
+}
+
+func TestBuildReviewMap_FlagsUntestedSource(t *testing.T) {
+	got := BuildReviewMap([]ReviewFile{{Path: "pkg/auth/login.py", Status: "A", Additions: 2, Added: []string{"import subprocess", "subprocess.run(cmd)"}}}, fstest.MapFS{})
+	for _, want := range []string{"| high |", "security-sensitive area", "runs external commands", "no covering tests found"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in review map:\n%s", want, got)
+		}
+	}
+}
+
+func TestWithReviewMap(t *testing.T) {
+	section := reviewMapStart + "\n## Review map\nv1\n" + reviewMapEnd
+	footer := "<!-- erg:fingerprint workflow=abc model=default -->"
+
+	body := WithReviewMap("## Summary\nFix.\n\n"+footer, section)
+	if body != "## Summary\nFix.\n\n"+section+"\n\n"+footer {
+		t.Errorf("expected map ahead of fingerprint footer, got %q", body)
+	}
+
+	updated := WithReviewMap(body, strings.Replace(section, "v1", "v2", 1))
+	if strings.Contains(updated, "v1") || strings.Count(updated, reviewMapStart) != 1 || !strings.HasSuffix(updated, footer) {
+		t.Errorf("expected map replaced in place, got %q", updated)
+	}
+
+	if got := WithReviewMap("## Summary\nFix.", ""); got != "## Summary\nFix." {
+		t.Errorf("empty map changed body: %q", got)
+	}
+	if got := WithReviewMap("", section); got != section {
+		t.Errorf("empty body = %q", got)
+	}
+}
@@ -8,7 +8,8 @@ import (
 // Instead of using a package-level executor variable, each GitService instance
 // holds its own executor, enabling proper testing and avoiding global state.
 type GitService struct {
-	executor pexec.CommandExecutor
+	executor      pexec.CommandExecutor
+	responseCache *ResponseCache
 }
 
 // NewGitService creates a new GitService with the default real executor.
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/providerhttp"
 	"github.com/zhubert/erg/internal/secrets"
 )
 
@@ -16,8 +17,21 @@ const (
 	asanaAPIBase     = "https://app.asana.com/api/1.0"
 	asanaPATEnvVar   = "ASANA_PAT"
 	asanaHTTPTimeout = 30 * time.Second
+
+	// asanaPageSize is the largest page size the Asana API accepts.
+	asanaPageSize = 100
+	// asanaDefaultMaxTasks caps tasks fetched per poll when the filter sets no limit.
+	asanaDefaultMaxTasks = 1000
 )
 
+// asanaHTTPPolicy retries transient failures and paces requests under
+// Asana's 150 requests per minute limit for free workspaces.
+func asanaHTTPPolicy() providerhttp.Policy {
+	policy := providerhttp.DefaultPolicy()
+	policy.Limiter = providerhttp.NewRateLimiter(150, time.Minute, 50)
+	return policy
+}
+
 // AsanaProject represents an Asana project with its GID and name.
 type AsanaProject struct {
 	GID  string
@@ -34,16 +48,9 @@ type AsanaProvider struct {
 // NewAsanaProvider creates a new Asana task provider.
 func NewAsanaProvider(cfg AsanaConfigProvider) *AsanaProvider {
 	return &AsanaProvider{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: asanaHTTPTimeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
-		},
-		apiBase: asanaAPIBase,
+		config:     cfg,
+		httpClient: providerhttp.NewClient(asanaHTTPTimeout, asanaHTTPPolicy()),
+		apiBase:    asanaAPIBase,
 	}
 }
 
@@ -81,18 +88,61 @@ type asanaTask struct {
 	Notes     string     `json:"notes"`
 	Permalink string     `json:"permalink_url"`
 	Tags      []asanaTag `json:"tags"`
+	CreatedAt time.Time  `json:"created_at"`
+	DueAt     *time.Time `json:"due_at"` // set when the due date has a time
+	DueOn     string     `json:"due_on"` // YYYY-MM-DD, or empty
+	Completed bool       `json:"completed"`
+	Assignee  *struct {
+		GID   string `json:"gid"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"assignee"`
+}
+
+// assignedTo reports whether the task is assigned to the user identified by
+// name, email, or GID (case-insensitive).
+func (t asanaTask) assignedTo(user string) bool {
+	if t.Assignee == nil {
+		return false
+	}
+	return strings.EqualFold(t.Assignee.Name, user) || strings.EqualFold(t.Assignee.Email, user) || t.Assignee.GID == user
+}
+
+// toIssue converts the task. Asana has no built-in priority, so the priority
+// is derived from the task's tags.
+func (t asanaTask) toIssue() Issue {
+	issue := Issue{
+		ID:        t.GID,
+		Title:     t.Name,
+		Body:      t.Notes,
+		URL:       t.Permalink,
+		Source:    SourceAsana,
+		CreatedAt: t.CreatedAt,
+	}
+	for _, tag := range t.Tags {
+		issue.Labels = append(issue.Labels, tag.Name)
+	}
+	issue.Priority = PriorityFromLabels(issue.Labels)
+	if t.DueAt != nil {
+		issue.DueAt = *t.DueAt
+	} else if due, err := time.Parse(time.DateOnly, t.DueOn); err == nil {
+		issue.DueAt = due
+	}
+	return issue
 }
 
 // asanaTasksResponse represents the Asana API response for listing tasks.
 type asanaTasksResponse struct {
-	Data []asanaTask `json:"data"`
+	Data     []asanaTask    `json:"data"`
+	NextPage *asanaNextPage `json:"next_page"`
 }
 
 // FetchIssues retrieves incomplete tasks from the Asana project.
 // The filter.Project should be the Asana project GID.
 // If filter.Section is set, only tasks in that section are returned (section name
 // is matched case-insensitively). If filter.Label is also set, it is applied as
-// an additional tag filter on top of the section results.
+// an additional tag filter on top of the section results. If filter.Assignee is
+// set, only tasks assigned to that user (name, email, or GID) are returned.
 func (p *AsanaProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
 	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
 	if !ok {
@@ -123,26 +173,17 @@ func (p *AsanaProvider) FetchIssues(ctx context.Context, repoPath string, filter
 			return nil, fmt.Errorf("section %q not found in project %s", filter.Section, projectID)
 		}
 
-		url := fmt.Sprintf("%s/sections/%s/tasks?opt_fields=gid,name,notes,permalink_url,tags.name&completed_since=now", p.apiBase, sectionGID)
-		var tasksResp asanaTasksResponse
-		if err := apiRequest(ctx, p.httpClient, http.MethodGet, url, nil,
-			"Bearer "+pat, http.StatusOK,
-			"Asana API returned 403 Forbidden - check that your ASANA_PAT has access to this project",
-			"Asana", &tasksResp); err != nil {
+		baseURL := fmt.Sprintf("%s/sections/%s/tasks", p.apiBase, sectionGID)
+		if tasks, err = p.fetchTasks(ctx, pat, baseURL, filter.MaxItems); err != nil {
 			return nil, err
 		}
-		tasks = tasksResp.Data
 	} else {
 		// Fetch all incomplete tasks from the project.
-		url := fmt.Sprintf("%s/projects/%s/tasks?opt_fields=gid,name,notes,permalink_url,tags.name&completed_since=now", p.apiBase, projectID)
-		var tasksResp asanaTasksResponse
-		if err := apiRequest(ctx, p.httpClient, http.MethodGet, url, nil,
-			"Bearer "+pat, http.StatusOK,
-			"Asana API returned 403 Forbidden - check that your ASANA_PAT has access to this project",
-			"Asana", &tasksResp); err != nil {
+		baseURL := fmt.Sprintf("%s/projects/%s/tasks", p.apiBase, projectID)
+		var err error
+		if tasks, err = p.fetchTasks(ctx, pat, baseURL, filter.MaxItems); err != nil {
 			return nil, err
 		}
-		tasks = tasksResp.Data
 	}
 
 	// Optionally narrow by tag.
@@ -159,20 +200,59 @@ func (p *AsanaProvider) FetchIssues(ctx context.Context, repoPath string, filter
 		tasks = filtered
 	}
 
+	// Optionally narrow by assignee. The project and section task endpoints
+	// can't filter by assignee themselves.
+	if filter.Assignee != "" {
+		var filtered []asanaTask
+		for _, task := range tasks {
+			if task.assignedTo(filter.Assignee) {
+				filtered = append(filtered, task)
+			}
+		}
+		tasks = filtered
+	}
+
 	issues := make([]Issue, len(tasks))
 	for i, task := range tasks {
-		issues[i] = Issue{
-			ID:     task.GID,
-			Title:  task.Name,
-			Body:   task.Notes,
-			URL:    task.Permalink,
-			Source: SourceAsana,
-		}
+		issues[i] = task.toIssue()
 	}
 
 	return issues, nil
 }
 
+// fetchTasks retrieves incomplete tasks from a project or section tasks
+// endpoint, following next_page offsets until the last page or until maxTasks
+// tasks have been read (asanaDefaultMaxTasks when maxTasks is zero).
+func (p *AsanaProvider) fetchTasks(ctx context.Context, pat, baseURL string, maxTasks int) ([]asanaTask, error) {
+	if maxTasks <= 0 {
+		maxTasks = asanaDefaultMaxTasks
+	}
+	baseURL = fmt.Sprintf("%s?opt_fields=gid,name,notes,permalink_url,tags.name,created_at,due_on,due_at,assignee.name,assignee.email&completed_since=now&limit=%d", baseURL, asanaPageSize)
+	requestURL := baseURL
+
+	var allTasks []asanaTask
+	for {
+		var tasksResp asanaTasksResponse
+		if err := apiRequest(ctx, p.httpClient, http.MethodGet, requestURL, nil,
+			"Bearer "+pat, http.StatusOK,
+			"Asana API returned 403 Forbidden - check that your ASANA_PAT has access to this project",
+			"Asana", &tasksResp); err != nil {
+			return nil, err
+		}
+
+		allTasks = append(allTasks, tasksResp.Data...)
+		if len(allTasks) >= maxTasks {
+			return allTasks[:maxTasks], nil
+		}
+
+		if tasksResp.NextPage == nil || tasksResp.NextPage.Offset == "" {
+			return allTasks, nil
+		}
+
+		requestURL = baseURL + "&offset=" + tasksResp.NextPage.Offset
+	}
+}
+
 // GetIssue fetches a single Asana task by its GID.
 // Implements IssueGetter.
 func (p *AsanaProvider) GetIssue(ctx context.Context, repoPath string, id string) (*Issue, error) {
@@ -181,7 +261,7 @@ func (p *AsanaProvider) GetIssue(ctx context.Context, repoPath string, id string
 		return nil, secrets.TokenNotFoundError(asanaPATEnvVar)
 	}
 
-	url := fmt.Sprintf("%s/tasks/%s?opt_fields=gid,name,notes,permalink_url", p.apiBase, id)
+	url := fmt.Sprintf("%s/tasks/%s?opt_fields=gid,name,notes,permalink_url,tags.name,created_at,due_on,due_at", p.apiBase, id)
 
 	type singleTaskResponse struct {
 		Data asanaTask `json:"data"`
@@ -199,13 +279,8 @@ func (p *AsanaProvider) GetIssue(ctx context.Context, repoPath string, id string
 		return nil, fmt.Errorf("asana task %q not found", id)
 	}
 
-	return &Issue{
-		ID:     task.GID,
-		Title:  task.Name,
-		Body:   task.Notes,
-		URL:    task.Permalink,
-		Source: SourceAsana,
-	}, nil
+	issue := task.toIssue()
+	return &issue, nil
 }
 
 // IsConfigured returns true if Asana is configured for the given repo.
@@ -670,3 +745,18 @@ func (p *AsanaProvider) UpdateComment(ctx context.Context, repoPath string, issu
 	return apiRequest(ctx, p.httpClient, http.MethodPut, storyURL, strings.NewReader(reqBody),
 		"Bearer "+pat, http.StatusOK, "", "Asana", nil)
 }
+
+// CloseIssue marks an Asana task complete.
+// Implements ProviderActions.
+func (p *AsanaProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+
+	taskURL := fmt.Sprintf("%s/tasks/%s", p.apiBase, issueID)
+	reqBody := `{"data":{"completed":true}}`
+
+	return apiRequest(ctx, p.httpClient, http.MethodPut, taskURL, strings.NewReader(reqBody),
+		"Bearer "+pat, http.StatusOK, "", "Asana", nil)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/zhubert/erg/internal/secrets"
@@ -62,3 +63,101 @@ func (p *AsanaProvider) DeleteClaim(ctx context.Context, repoPath string, issueI
 	return apiRequest(ctx, p.httpClient, http.MethodDelete, storyURL, nil,
 		"Bearer "+pat, http.StatusOK, "", "Asana", nil)
 }
+
+// Claim adds ClaimLabel as a tag on an Asana task, creating the tag in the
+// task's workspace the first time it is needed.
+// Implements ProviderActions.
+func (p *AsanaProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return false, secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+
+	taskURL := fmt.Sprintf("%s/tasks/%s?opt_fields=tags.gid,tags.name,workspace.gid", p.apiBase, issueID)
+	var taskResp struct {
+		Data struct {
+			Tags      []asanaTagWithGID `json:"tags"`
+			Workspace struct {
+				GID string `json:"gid"`
+			} `json:"workspace"`
+		} `json:"data"`
+	}
+	if err := apiRequest(ctx, p.httpClient, http.MethodGet, taskURL, nil,
+		"Bearer "+pat, http.StatusOK, "", "Asana", &taskResp); err != nil {
+		return false, err
+	}
+	for _, tag := range taskResp.Data.Tags {
+		if strings.EqualFold(tag.Name, ClaimLabel) {
+			return false, nil
+		}
+	}
+
+	tagGID, err := p.workspaceTagGID(ctx, pat, taskResp.Data.Workspace.GID, ClaimLabel, claimLabelDescription)
+	if err != nil {
+		return false, err
+	}
+
+	if err := p.addTag(ctx, pat, issueID, tagGID); err != nil {
+		return false, fmt.Errorf("failed to add claim tag: %w", err)
+	}
+	return true, nil
+}
+
+// Unclaim removes the ClaimLabel tag from an Asana task.
+// Implements ProviderActions.
+func (p *AsanaProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	return p.RemoveLabel(ctx, repoPath, issueID, ClaimLabel)
+}
+
+// addTag adds the tag with the given GID to a task.
+func (p *AsanaProvider) addTag(ctx context.Context, pat, taskGID, tagGID string) error {
+	addURL := fmt.Sprintf("%s/tasks/%s/addTag", p.apiBase, taskGID)
+	tagJSON, err := json.Marshal(tagGID)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tag GID: %w", err)
+	}
+	addBody := fmt.Sprintf(`{"data":{"tag":%s}}`, tagJSON)
+	return apiRequest(ctx, p.httpClient, http.MethodPost, addURL, strings.NewReader(addBody),
+		"Bearer "+pat, http.StatusOK, "", "Asana", nil)
+}
+
+// workspaceTagGID returns the GID of the workspace tag with the given name
+// (matched case-insensitively), creating the tag with notes if the workspace
+// doesn't have one yet.
+func (p *AsanaProvider) workspaceTagGID(ctx context.Context, pat, workspaceGID, name, notes string) (string, error) {
+	if workspaceGID == "" {
+		return "", fmt.Errorf("asana task has no workspace")
+	}
+
+	searchURL := fmt.Sprintf("%s/workspaces/%s/typeahead?resource_type=tag&query=%s&opt_fields=gid,name",
+		p.apiBase, workspaceGID, url.QueryEscape(name))
+	var searchResp struct {
+		Data []asanaTagWithGID `json:"data"`
+	}
+	if err := apiRequest(ctx, p.httpClient, http.MethodGet, searchURL, nil,
+		"Bearer "+pat, http.StatusOK, "", "Asana", &searchResp); err != nil {
+		return "", fmt.Errorf("failed to look up tag %q: %w", name, err)
+	}
+	for _, tag := range searchResp.Data {
+		if strings.EqualFold(tag.Name, name) {
+			return tag.GID, nil
+		}
+	}
+
+	createBody, err := json.Marshal(map[string]any{"data": map[string]string{
+		"name":      name,
+		"workspace": workspaceGID,
+		"notes":     notes,
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tag: %w", err)
+	}
+	var createResp struct {
+		Data asanaTagWithGID `json:"data"`
+	}
+	if err := apiRequest(ctx, p.httpClient, http.MethodPost, p.apiBase+"/tags", strings.NewReader(string(createBody)),
+		"Bearer "+pat, http.StatusCreated, "", "Asana", &createResp); err != nil {
+		return "", fmt.Errorf("failed to create tag %q: %w", name, err)
+	}
+	return createResp.Data.GID, nil
+}
@@ -0,0 +1,69 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+// asanaCustomFieldsResponse is the response for a task's custom fields.
+type asanaCustomFieldsResponse struct {
+	Data struct {
+		CustomFields []struct {
+			Name         string `json:"name"`
+			DisplayValue string `json:"display_value"`
+		} `json:"custom_fields"`
+	} `json:"data"`
+}
+
+// asanaAttachmentsResponse is the response for a task's attachments.
+type asanaAttachmentsResponse struct {
+	Data []struct {
+		Name         string `json:"name"`
+		PermanentURL string `json:"permanent_url"`
+	} `json:"data"`
+}
+
+// EnrichIssue describes the Asana task's filled-in custom fields and its
+// attachments, followed by its recent comments.
+// Implements ProviderEnricher.
+func (p *AsanaProvider) EnrichIssue(ctx context.Context, repoPath string, issue Issue) (string, error) {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return "", secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+	const forbidden = "Asana API returned 403 Forbidden - check that your ASANA_PAT has access to this task"
+
+	var e enrichment
+	fieldsURL := fmt.Sprintf("%s/tasks/%s?opt_fields=custom_fields.name,custom_fields.display_value", p.apiBase, issue.ID)
+	var fields asanaCustomFieldsResponse
+	if err := apiRequest(ctx, p.httpClient, http.MethodGet, fieldsURL, nil,
+		"Bearer "+pat, http.StatusOK, forbidden, "Asana", &fields); err != nil {
+		return "", fmt.Errorf("failed to fetch custom fields: %w", err)
+	}
+	for _, f := range fields.Data.CustomFields {
+		if f.DisplayValue != "" {
+			e.Fields = append(e.Fields, [2]string{f.Name, f.DisplayValue})
+		}
+	}
+
+	attachmentsURL := fmt.Sprintf("%s/attachments?parent=%s&opt_fields=name,permanent_url", p.apiBase, url.QueryEscape(issue.ID))
+	var attachments asanaAttachmentsResponse
+	if err := apiRequest(ctx, p.httpClient, http.MethodGet, attachmentsURL, nil,
+		"Bearer "+pat, http.StatusOK, forbidden, "Asana", &attachments); err != nil {
+		return "", fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	for _, a := range attachments.Data {
+		e.Attachments = append(e.Attachments, attachment{Name: a.Name, URL: a.PermanentURL})
+	}
+
+	comments, err := p.GetIssueComments(ctx, repoPath, issue.ID)
+	if err != nil {
+		return "", err
+	}
+	e.Comments = recentHumanComments(comments)
+	return e.render(), nil
+}
@@ -0,0 +1,40 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+// FetchSubtasks returns the incomplete subtasks of an Asana task.
+// Implements ProviderSubtaskFetcher.
+func (p *AsanaProvider) FetchSubtasks(ctx context.Context, repoPath string, parentID string) ([]Issue, error) {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return nil, secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+
+	// The subtasks endpoint has no completed_since filter, so completion is
+	// requested and checked here.
+	subtasksURL := fmt.Sprintf("%s/tasks/%s/subtasks?opt_fields=gid,name,notes,permalink_url,tags.name,created_at,due_on,due_at,completed", p.apiBase, parentID)
+	var resp asanaTasksResponse
+	if err := apiRequest(ctx, p.httpClient, http.MethodGet, subtasksURL, nil,
+		"Bearer "+pat, http.StatusOK,
+		"Asana API returned 403 Forbidden - check that your ASANA_PAT has access to this task",
+		"Asana", &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch subtasks: %w", err)
+	}
+
+	subtasks := []Issue{}
+	for _, task := range resp.Data {
+		if task.Completed {
+			continue
+		}
+		issue := task.toIssue()
+		issue.Parent = parentID
+		subtasks = append(subtasks, issue)
+	}
+	return subtasks, nil
+}
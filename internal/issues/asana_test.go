@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zhubert/erg/internal/config"
 )
@@ -168,6 +170,75 @@ func TestAsanaProvider_FetchIssues_MockServer(t *testing.T) {
 	}
 }
 
+func TestAsanaProvider_FetchIssues_PriorityDatesAndTags(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("opt_fields"), "created_at") {
+			t.Errorf("expected created_at in opt_fields, got %q", r.URL.Query().Get("opt_fields"))
+		}
+		json.NewEncoder(w).Encode(asanaTasksResponse{
+			Data: []asanaTask{
+				{GID: "1", Name: "Task 1", Tags: []asanaTag{{Name: "queued"}, {Name: "Priority: High"}}, CreatedAt: created, DueOn: "2026-04-15"},
+				{GID: "2", Name: "Task 2", DueAt: &created},
+			},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv(asanaPATEnvVar, "test-pat")
+	p := NewAsanaProviderWithClient(&config.Config{}, server.Client(), server.URL)
+
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Project: "12345"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].Priority != 2 || !issues[0].CreatedAt.Equal(created) || len(issues[0].Labels) != 2 {
+		t.Errorf("expected priority 2, creation time, and tags as labels, got %+v", issues[0])
+	}
+	if !issues[0].DueAt.Equal(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected due_on parsed, got %v", issues[0].DueAt)
+	}
+	if !issues[1].DueAt.Equal(created) {
+		t.Errorf("expected due_at to take precedence, got %v", issues[1].DueAt)
+	}
+}
+
+func TestAsanaProvider_FetchIssues_AssigneeFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("opt_fields"), "assignee.email") {
+			t.Errorf("expected assignee fields in opt_fields, got %q", r.URL.Query().Get("opt_fields"))
+		}
+		io.WriteString(w, `{"data": [
+			{"gid": "1", "name": "Bot task", "assignee": {"gid": "77", "name": "erg-bot", "email": "bot@example.com"}},
+			{"gid": "2", "name": "Human task", "assignee": {"gid": "88", "name": "Dana", "email": "dana@example.com"}},
+			{"gid": "3", "name": "Unassigned"},
+			{"gid": "4", "name": "Bot by email", "assignee": {"gid": "77", "name": "Erg Bot", "email": "BOT@example.com"}}
+		]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv(asanaPATEnvVar, "test-pat")
+	p := NewAsanaProviderWithClient(&config.Config{}, server.Client(), server.URL)
+
+	for _, assignee := range []string{"erg-bot", "bot@example.com", "77"} {
+		issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Project: "12345", Assignee: assignee})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for _, issue := range issues {
+			ids = append(ids, issue.ID)
+		}
+		want := map[string][]string{"erg-bot": {"1"}, "bot@example.com": {"1", "4"}, "77": {"1", "4"}}[assignee]
+		if !slices.Equal(ids, want) {
+			t.Errorf("assignee %q: got %v, want %v", assignee, ids, want)
+		}
+	}
+}
+
 func TestAsanaProvider_FetchIssues_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -188,6 +259,80 @@ func TestAsanaProvider_FetchIssues_APIError(t *testing.T) {
 	}
 }
 
+func TestAsanaProvider_FetchIssues_Pagination(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.URL.Query().Get("limit"); got != "100" {
+			t.Errorf("expected limit=100, got %q", got)
+		}
+
+		var response asanaTasksResponse
+		switch r.URL.Query().Get("offset") {
+		case "":
+			response = asanaTasksResponse{
+				Data:     []asanaTask{{GID: "1", Name: "Task 1"}, {GID: "2", Name: "Task 2"}},
+				NextPage: &asanaNextPage{Offset: "page2token"},
+			}
+		case "page2token":
+			response = asanaTasksResponse{
+				Data: []asanaTask{{GID: "3", Name: "Task 3"}},
+			}
+		default:
+			http.Error(w, "unexpected offset", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	t.Setenv(asanaPATEnvVar, "test-pat")
+
+	p := NewAsanaProviderWithClient(&config.Config{}, server.Client(), server.URL)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Project: "12345"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues across pages, got %d", len(issues))
+	}
+	if issues[2].ID != "3" {
+		t.Errorf("expected last issue from second page, got %q", issues[2].ID)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestAsanaProvider_FetchIssues_MaxItems(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		response := asanaTasksResponse{
+			Data:     []asanaTask{{GID: "1", Name: "Task 1"}, {GID: "2", Name: "Task 2"}},
+			NextPage: &asanaNextPage{Offset: "more"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	t.Setenv(asanaPATEnvVar, "test-pat")
+
+	p := NewAsanaProviderWithClient(&config.Config{}, server.Client(), server.URL)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Project: "12345", MaxItems: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected fetch capped at 3 issues, got %d", len(issues))
+	}
+	if requests != 2 {
+		t.Errorf("expected paging to stop once the cap was reached, got %d requests", requests)
+	}
+}
+
 func TestAsanaProvider_FetchIssues_BySection(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,73 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+// CreateIssue creates a task in the Asana project mapped to the repository
+// and tags it with labels, creating any workspace tags that don't exist yet.
+// The body is markdown and is converted to Asana rich text.
+// Implements ProviderWriter.
+func (p *AsanaProvider) CreateIssue(ctx context.Context, repoPath string, title, body string, labels []string) (*Issue, error) {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return nil, secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+
+	projectGID := p.config.GetAsanaProject(repoPath)
+	if projectGID == "" {
+		return nil, fmt.Errorf("asana project GID not configured for this repository")
+	}
+
+	data := map[string]any{
+		"name":     title,
+		"projects": []string{projectGID},
+	}
+	if body != "" {
+		data["html_notes"] = markdownToAsanaHTML(body)
+	}
+	reqBody, err := json.Marshal(map[string]any{"data": data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	var resp struct {
+		Data struct {
+			asanaTask
+			Workspace struct {
+				GID string `json:"gid"`
+			} `json:"workspace"`
+		} `json:"data"`
+	}
+	createURL := p.apiBase + "/tasks?opt_fields=gid,name,notes,permalink_url,created_at,workspace.gid"
+	if err := apiRequest(ctx, p.httpClient, http.MethodPost, createURL, strings.NewReader(string(reqBody)),
+		"Bearer "+pat, http.StatusCreated,
+		"Asana API returned 403 Forbidden - check that your ASANA_PAT can create tasks in this project",
+		"Asana", &resp); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	task := resp.Data.asanaTask
+	if task.GID == "" {
+		return nil, fmt.Errorf("asana API returned no task GID creating %q", title)
+	}
+
+	for _, label := range labels {
+		tagGID, err := p.workspaceTagGID(ctx, pat, resp.Data.Workspace.GID, label, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.addTag(ctx, pat, task.GID, tagGID); err != nil {
+			return nil, fmt.Errorf("failed to tag task %s with %q: %w", task.GID, label, err)
+		}
+		task.Tags = append(task.Tags, asanaTag{Name: label})
+	}
+
+	issue := task.toIssue()
+	return &issue, nil
+}
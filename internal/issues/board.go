@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/providerhttp"
 	"github.com/zhubert/erg/internal/secrets"
 )
 
@@ -26,7 +27,7 @@ var (
 // newBoardHTTPClient returns a short-lived HTTP client for one-off board API calls
 // during configure.
 func newBoardHTTPClient() *http.Client {
-	return &http.Client{Timeout: 30 * time.Second}
+	return providerhttp.NewClient(30*time.Second, providerhttp.DefaultPolicy())
 }
 
 // ListAsanaSections returns all sections in an Asana project.
@@ -83,7 +84,7 @@ func ListLinearWorkflowStates(ctx context.Context, teamID string) ([]BoardSectio
 	nodes := statesResp.Data.Team.States.Nodes
 	states := make([]BoardSection, len(nodes))
 	for i, s := range nodes {
-		states[i] = BoardSection(s)
+		states[i] = BoardSection{ID: s.ID, Name: s.Name}
 	}
 	return states, nil
 }
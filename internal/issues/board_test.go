@@ -47,9 +47,9 @@ func TestListAsanaSections(t *testing.T) {
 
 func TestListAsanaSections_NoToken(t *testing.T) {
 	t.Setenv("ASANA_PAT", "")
-	origKeychainGet := keychainGet
-	keychainGet = func(string) (string, bool) { return "", false }
-	defer func() { keychainGet = origKeychainGet }()
+	origKeychainGet := storedGet
+	storedGet = func(string) (string, bool) { return "", false }
+	defer func() { storedGet = origKeychainGet }()
 
 	_, err := ListAsanaSections(context.Background(), "proj123")
 	if err == nil {
@@ -14,6 +14,13 @@ const claimMarkerGitHub = "<!-- erg-claim "
 // claimMarkerVisible is the plain-text prefix used in Asana/Linear claim comments.
 const claimMarkerVisible = "[erg-claim] "
 
+// claimLabelColor and claimLabelDescription are used when a tracker needs
+// ClaimLabel created before it can be applied.
+const (
+	claimLabelColor       = "fbca04"
+	claimLabelDescription = "An erg daemon is working on this issue"
+)
+
 // claimJSON is the JSON payload embedded in claim comments.
 type claimJSON struct {
 	DaemonID string `json:"daemon"`
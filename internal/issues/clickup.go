@@ -0,0 +1,399 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/providerhttp"
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+const (
+	clickupAPIBase     = "https://api.clickup.com/api/v2"
+	clickupTokenEnvVar = "CLICKUP_API_TOKEN"
+	clickupHTTPTimeout = 30 * time.Second
+	clickupMaxPages    = 20 // 100 tasks per page
+)
+
+// clickupHTTPPolicy retries transient failures and paces requests under
+// ClickUp's 100 requests per minute limit.
+func clickupHTTPPolicy() providerhttp.Policy {
+	policy := providerhttp.DefaultPolicy()
+	policy.Limiter = providerhttp.NewRateLimiter(100, time.Minute, 20)
+	return policy
+}
+
+// ClickUpProvider implements Provider for ClickUp tasks using the ClickUp REST API (v2).
+// Tasks are fetched from a list mapped to the repo and filtered by tag.
+type ClickUpProvider struct {
+	config     ClickUpConfigProvider
+	httpClient *http.Client
+	apiBase    string // Override for testing; defaults to clickupAPIBase
+}
+
+// NewClickUpProvider creates a new ClickUp task provider.
+func NewClickUpProvider(cfg ClickUpConfigProvider) *ClickUpProvider {
+	return &ClickUpProvider{
+		config:     cfg,
+		httpClient: providerhttp.NewClient(clickupHTTPTimeout, clickupHTTPPolicy()),
+		apiBase:    clickupAPIBase,
+	}
+}
+
+// NewClickUpProviderWithClient creates a new ClickUp task provider with a custom HTTP client and API base URL (for testing).
+func NewClickUpProviderWithClient(cfg ClickUpConfigProvider, client *http.Client, apiBase string) *ClickUpProvider {
+	if apiBase == "" {
+		apiBase = clickupAPIBase
+	}
+	return &ClickUpProvider{
+		config:     cfg,
+		httpClient: client,
+		apiBase:    apiBase,
+	}
+}
+
+// Name returns the human-readable name of this provider.
+func (p *ClickUpProvider) Name() string {
+	return "ClickUp Tasks"
+}
+
+// Source returns the source type for this provider.
+func (p *ClickUpProvider) Source() Source {
+	return SourceClickUp
+}
+
+// clickupID accepts IDs encoded as either JSON strings or numbers; ClickUp
+// returns comment IDs as strings when listing and as numbers when creating.
+type clickupID string
+
+func (id *clickupID) UnmarshalJSON(data []byte) error {
+	*id = clickupID(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// clickupStatus is a task status. Type is "open", "custom", "done", or "closed".
+type clickupStatus struct {
+	Status string `json:"status"`
+	Type   string `json:"type"`
+}
+
+// clickupTag is a tag on a ClickUp task.
+type clickupTag struct {
+	Name string `json:"name"`
+}
+
+// clickupTask represents a task from the ClickUp REST API response.
+type clickupTask struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	Status      clickupStatus `json:"status"`
+	Tags        []clickupTag  `json:"tags"`
+	Priority    *struct {
+		Priority string `json:"priority"` // urgent, high, normal, or low
+	} `json:"priority"`
+	DateCreated string `json:"date_created"` // Unix milliseconds
+	List        struct {
+		ID string `json:"id"`
+	} `json:"list"`
+}
+
+// clickupComment represents a task comment from the ClickUp REST API response.
+type clickupComment struct {
+	ID          clickupID `json:"id"`
+	CommentText string    `json:"comment_text"`
+	Date        string    `json:"date"` // Unix milliseconds
+	User        struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
+
+func (t clickupTask) toIssue() Issue {
+	issue := Issue{
+		ID:     t.ID,
+		Title:  t.Name,
+		Body:   t.Description,
+		URL:    t.URL,
+		Source: SourceClickUp,
+	}
+	for _, tag := range t.Tags {
+		issue.Labels = append(issue.Labels, tag.Name)
+	}
+	if t.Priority != nil {
+		issue.Priority = priorityFromName(t.Priority.Priority)
+	}
+	if ms, err := strconv.ParseInt(t.DateCreated, 10, 64); err == nil {
+		issue.CreatedAt = time.UnixMilli(ms)
+	}
+	return issue
+}
+
+// isClosed reports whether the task is in a done or closed status.
+func (t clickupTask) isClosed() bool {
+	return t.Status.Type == "closed" || t.Status.Type == "done"
+}
+
+// FetchIssues retrieves open tasks from the ClickUp list carrying the filter tag.
+// The filter.List should be the ClickUp list ID.
+func (p *ClickUpProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
+	list := filter.List
+	if list == "" {
+		list = p.config.GetClickUpList(repoPath)
+	}
+	if list == "" {
+		return nil, fmt.Errorf("clickup list not configured for this repository")
+	}
+
+	var issues []Issue
+	for page := 0; page < clickupMaxPages; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		if filter.Label != "" {
+			query.Add("tags[]", filter.Label)
+		}
+
+		var resp struct {
+			Tasks    []clickupTask `json:"tasks"`
+			LastPage bool          `json:"last_page"`
+		}
+		if err := p.clickupRequest(ctx, http.MethodGet, "/list/"+url.PathEscape(list)+"/task?"+query.Encode(), nil,
+			"ClickUp API returned 403 Forbidden - check that your CLICKUP_API_TOKEN has access to this list",
+			&resp); err != nil {
+			return nil, err
+		}
+		for _, task := range resp.Tasks {
+			if !task.isClosed() {
+				issues = append(issues, task.toIssue())
+			}
+		}
+		if resp.LastPage || len(resp.Tasks) == 0 {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// GetIssue fetches a single ClickUp task by its ID.
+// Implements IssueGetter.
+func (p *ClickUpProvider) GetIssue(ctx context.Context, repoPath string, id string) (*Issue, error) {
+	task, err := p.getTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := task.toIssue()
+	return &result, nil
+}
+
+// IsConfigured returns true if ClickUp is configured for the given repo.
+// Requires both CLICKUP_API_TOKEN (env var or macOS Keychain) and a list mapped to the repo.
+func (p *ClickUpProvider) IsConfigured(repoPath string) bool {
+	if _, ok := resolveToken(clickupTokenEnvVar, secrets.ClickUpTokenService); !ok {
+		return false
+	}
+	return p.config.HasClickUpList(repoPath)
+}
+
+// GenerateBranchName returns a branch name for the given ClickUp task.
+// Format: "clickup-{id}"
+func (p *ClickUpProvider) GenerateBranchName(issue Issue) string {
+	return fmt.Sprintf("clickup-%s", issue.ID)
+}
+
+// GetPRLinkText returns "" — ClickUp does not close tasks from PR keywords;
+// the task is moved to a closed status by CompleteIssue after the merge.
+func (p *ClickUpProvider) GetPRLinkText(issue Issue) string {
+	return ""
+}
+
+// clickupRequest executes a REST request against the ClickUp API.
+// If forbiddenMsg is non-empty, a 403 response produces that specific error.
+func (p *ClickUpProvider) clickupRequest(ctx context.Context, method, path string, body any, forbiddenMsg string, result any) error {
+	token, ok := resolveToken(clickupTokenEnvVar, secrets.ClickUpTokenService)
+	if !ok {
+		return secrets.TokenNotFoundError(clickupTokenEnvVar)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ClickUp request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	// ClickUp personal tokens are sent as-is, without a "Bearer" prefix.
+	return apiRequest(ctx, p.httpClient, method, p.apiBase+path, reader,
+		token, http.StatusOK, forbiddenMsg, "ClickUp", result)
+}
+
+// getTask fetches a single task by ID.
+func (p *ClickUpProvider) getTask(ctx context.Context, taskID string) (clickupTask, error) {
+	var task clickupTask
+	if taskID == "" {
+		return task, fmt.Errorf("clickup task ID is empty")
+	}
+	err := p.clickupRequest(ctx, http.MethodGet, "/task/"+url.PathEscape(taskID), nil, "", &task)
+	return task, err
+}
+
+// CheckIssueHasLabel returns true if the ClickUp task has a tag matching the given name.
+// Implements ProviderGateChecker.
+func (p *ClickUpProvider) CheckIssueHasLabel(ctx context.Context, repoPath string, issueID string, label string) (bool, error) {
+	task, err := p.getTask(ctx, issueID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch task tags: %w", err)
+	}
+	return slices.ContainsFunc(task.Tags, func(t clickupTag) bool {
+		return strings.EqualFold(t.Name, label)
+	}), nil
+}
+
+// GetIssueComments returns all comments on a ClickUp task, ordered oldest first.
+// Implements ProviderGateChecker.
+func (p *ClickUpProvider) GetIssueComments(ctx context.Context, repoPath string, issueID string) ([]IssueComment, error) {
+	if issueID == "" {
+		return nil, fmt.Errorf("clickup task ID is empty")
+	}
+	var resp struct {
+		Comments []clickupComment `json:"comments"`
+	}
+	if err := p.clickupRequest(ctx, http.MethodGet, "/task/"+url.PathEscape(issueID)+"/comment", nil, "", &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch task comments: %w", err)
+	}
+
+	comments := make([]IssueComment, 0, len(resp.Comments))
+	for _, c := range resp.Comments {
+		if c.CommentText == "" {
+			continue
+		}
+		var createdAt time.Time
+		if ms, err := strconv.ParseInt(c.Date, 10, 64); err == nil {
+			createdAt = time.UnixMilli(ms)
+		}
+		comments = append(comments, IssueComment{
+			ID:        string(c.ID),
+			Author:    c.User.Username,
+			Body:      c.CommentText,
+			CreatedAt: createdAt,
+		})
+	}
+	// ClickUp lists comments newest first.
+	slices.SortStableFunc(comments, func(a, b IssueComment) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return comments, nil
+}
+
+// IsIssueClosed returns true if the ClickUp task is in a done or closed status.
+// Implements IssueStateChecker.
+func (p *ClickUpProvider) IsIssueClosed(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	task, err := p.getTask(ctx, issueID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch task status: %w", err)
+	}
+	return task.isClosed(), nil
+}
+
+// RemoveLabel removes a tag from a ClickUp task.
+// Implements ProviderActions.
+func (p *ClickUpProvider) RemoveLabel(ctx context.Context, repoPath string, issueID string, label string) error {
+	if issueID == "" {
+		return fmt.Errorf("clickup task ID is empty")
+	}
+	if err := p.clickupRequest(ctx, http.MethodDelete,
+		"/task/"+url.PathEscape(issueID)+"/tag/"+url.PathEscape(label), nil, "", nil); err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+	return nil
+}
+
+// Comment posts a comment on a ClickUp task.
+// Implements ProviderActions.
+func (p *ClickUpProvider) Comment(ctx context.Context, repoPath string, issueID string, body string) error {
+	if _, err := p.createComment(ctx, issueID, body); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// UpdateComment replaces the text of an existing ClickUp task comment.
+// Implements ProviderCommentUpdater.
+func (p *ClickUpProvider) UpdateComment(ctx context.Context, repoPath string, issueID string, commentID string, body string) error {
+	if err := p.clickupRequest(ctx, http.MethodPut, "/comment/"+url.PathEscape(commentID),
+		map[string]string{"comment_text": body}, "", nil); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	return nil
+}
+
+// createComment posts a comment on a ClickUp task and returns its ID.
+func (p *ClickUpProvider) createComment(ctx context.Context, issueID, body string) (string, error) {
+	if issueID == "" {
+		return "", fmt.Errorf("clickup task ID is empty")
+	}
+	var created clickupComment
+	if err := p.clickupRequest(ctx, http.MethodPost, "/task/"+url.PathEscape(issueID)+"/comment",
+		map[string]any{"comment_text": body, "notify_all": false}, "", &created); err != nil {
+		return "", err
+	}
+	return string(created.ID), nil
+}
+
+// CloseIssue moves the task to its list's closed status; see CompleteIssue.
+// Implements ProviderActions.
+func (p *ClickUpProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	return p.CompleteIssue(ctx, repoPath, issueID)
+}
+
+// CompleteIssue moves a merged task to its list's closed status (falling back
+// to a done status when the list has no closed one). Tasks already closed are
+// left alone.
+// Implements ProviderCompleter.
+func (p *ClickUpProvider) CompleteIssue(ctx context.Context, repoPath string, issueID string) error {
+	task, err := p.getTask(ctx, issueID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch task: %w", err)
+	}
+	if task.isClosed() {
+		return nil
+	}
+
+	listID := task.List.ID
+	if listID == "" {
+		listID = p.config.GetClickUpList(repoPath)
+	}
+	var list struct {
+		Statuses []clickupStatus `json:"statuses"`
+	}
+	if err := p.clickupRequest(ctx, http.MethodGet, "/list/"+url.PathEscape(listID), nil, "", &list); err != nil {
+		return fmt.Errorf("failed to fetch list statuses: %w", err)
+	}
+
+	var status string
+	for _, want := range []string{"closed", "done"} {
+		if i := slices.IndexFunc(list.Statuses, func(s clickupStatus) bool { return s.Type == want }); i >= 0 {
+			status = list.Statuses[i].Status
+			break
+		}
+	}
+	if status == "" {
+		return fmt.Errorf("clickup list %s has no closed status", listID)
+	}
+
+	if err := p.clickupRequest(ctx, http.MethodPut, "/task/"+url.PathEscape(issueID),
+		map[string]string{"status": status}, "", nil); err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PostClaim posts a claim comment on a ClickUp task and returns the comment ID.
+// Implements ProviderClaimManager.
+func (p *ClickUpProvider) PostClaim(ctx context.Context, repoPath string, issueID string, claim ClaimInfo) (string, error) {
+	commentID, err := p.createComment(ctx, issueID, formatClaimBodyVisible(claim))
+	if err != nil {
+		return "", fmt.Errorf("failed to post claim comment: %w", err)
+	}
+	return commentID, nil
+}
+
+// GetClaims reads all claim comments from a ClickUp task.
+// Implements ProviderClaimManager.
+func (p *ClickUpProvider) GetClaims(ctx context.Context, repoPath string, issueID string) ([]ClaimInfo, error) {
+	comments, err := p.GetIssueComments(ctx, repoPath, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getClaimsFromComments(comments), nil
+}
+
+// DeleteClaim deletes a claim comment from a ClickUp task by its comment ID.
+// Implements ProviderClaimManager.
+func (p *ClickUpProvider) DeleteClaim(ctx context.Context, repoPath string, issueID string, commentID string) error {
+	if err := p.clickupRequest(ctx, http.MethodDelete, "/comment/"+url.PathEscape(commentID), nil, "", nil); err != nil {
+		return fmt.Errorf("failed to delete claim comment: %w", err)
+	}
+	return nil
+}
+
+// Claim adds ClaimLabel as a tag on a ClickUp task.
+// Implements ProviderActions.
+func (p *ClickUpProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	has, err := p.CheckIssueHasLabel(ctx, repoPath, issueID, ClaimLabel)
+	if err != nil {
+		return false, err
+	}
+	if has {
+		return false, nil
+	}
+	if err := p.clickupRequest(ctx, http.MethodPost,
+		"/task/"+url.PathEscape(issueID)+"/tag/"+url.PathEscape(ClaimLabel), nil, "", nil); err != nil {
+		return false, fmt.Errorf("failed to add claim tag: %w", err)
+	}
+	return true, nil
+}
+
+// Unclaim removes the ClaimLabel tag from a ClickUp task.
+// Implements ProviderActions.
+func (p *ClickUpProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	return p.RemoveLabel(ctx, repoPath, issueID, ClaimLabel)
+}
@@ -0,0 +1,268 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+)
+
+// Compile-time interface checks.
+var (
+	_ ProviderActions        = (*ClickUpProvider)(nil)
+	_ ProviderGateChecker    = (*ClickUpProvider)(nil)
+	_ ProviderCommentUpdater = (*ClickUpProvider)(nil)
+	_ ProviderClaimManager   = (*ClickUpProvider)(nil)
+	_ ProviderCompleter      = (*ClickUpProvider)(nil)
+	_ IssueGetter            = (*ClickUpProvider)(nil)
+	_ IssueStateChecker      = (*ClickUpProvider)(nil)
+)
+
+// newClickUpTestProvider returns a provider pointed at server with the repo
+// mapped to list "901" and CLICKUP_API_TOKEN set.
+func newClickUpTestProvider(t *testing.T, server *httptest.Server) *ClickUpProvider {
+	t.Helper()
+	t.Setenv(clickupTokenEnvVar, "pk_test")
+	cfg := &config.Config{}
+	cfg.SetClickUpList("/test/repo", "901")
+	return NewClickUpProviderWithClient(cfg, server.Client(), server.URL)
+}
+
+func TestClickUpProvider_Basics(t *testing.T) {
+	p := NewClickUpProvider(nil)
+	if p.Name() != "ClickUp Tasks" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+	if p.Source() != SourceClickUp {
+		t.Errorf("Source() = %q", p.Source())
+	}
+	if got := p.GenerateBranchName(Issue{ID: "86b0abc"}); got != "clickup-86b0abc" {
+		t.Errorf("GenerateBranchName = %q, want clickup-86b0abc", got)
+	}
+	if got := p.GetPRLinkText(Issue{ID: "86b0abc"}); got != "" {
+		t.Errorf("GetPRLinkText = %q, want empty", got)
+	}
+}
+
+func TestClickUpProvider_IsConfigured(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.SetClickUpList("/test/repo", "901")
+	p := NewClickUpProvider(cfg)
+
+	t.Setenv(clickupTokenEnvVar, "")
+	if p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=false without token")
+	}
+
+	t.Setenv(clickupTokenEnvVar, "pk_test")
+	if p.IsConfigured("/other/repo") {
+		t.Error("expected IsConfigured=false without list mapping")
+	}
+	if !p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=true with token and list mapping")
+	}
+}
+
+func TestClickUpProvider_FetchIssues(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "pk_test" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.URL.Path != "/list/901/task" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.URL.Query()["tags[]"]; len(got) != 1 || got[0] != "erg" {
+			t.Errorf("tags = %v", got)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if page == "0" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"tasks": []map[string]any{
+					{"id": "abc1", "name": "Add export", "description": "CSV export", "url": "https://app.clickup.com/t/abc1", "status": map[string]string{"status": "to do", "type": "open"},
+						"tags": []map[string]string{{"name": "erg"}}, "priority": map[string]string{"id": "2", "priority": "high"}, "date_created": "1772355600000"},
+					{"id": "abc2", "name": "Shipped", "status": map[string]string{"status": "complete", "type": "closed"}},
+				},
+				"last_page": false,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"tasks":     []map[string]any{{"id": "abc3", "name": "Fix sync", "status": map[string]string{"status": "in progress", "type": "custom"}}},
+			"last_page": true,
+		})
+	}))
+	defer server.Close()
+
+	p := newClickUpTestProvider(t, server)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Label: "erg", List: "901"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Errorf("expected 2 pages fetched, got %v", pages)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected closed task skipped, got %+v", issues)
+	}
+	want := Issue{ID: "abc1", Title: "Add export", Body: "CSV export", URL: "https://app.clickup.com/t/abc1", Source: SourceClickUp,
+		Priority: 2, CreatedAt: time.UnixMilli(1772355600000), Labels: []string{"erg"}}
+	if !reflect.DeepEqual(issues[0], want) {
+		t.Errorf("issue = %+v, want %+v", issues[0], want)
+	}
+	if issues[1].ID != "abc3" {
+		t.Errorf("second issue = %+v, want abc3", issues[1])
+	}
+}
+
+func TestClickUpProvider_FetchIssues_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	p := newClickUpTestProvider(t, server)
+
+	if _, err := p.FetchIssues(context.Background(), "/unmapped", FilterConfig{}); err == nil {
+		t.Error("expected error without list")
+	}
+	_, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{})
+	if err == nil || !strings.Contains(err.Error(), "CLICKUP_API_TOKEN") {
+		t.Errorf("expected forbidden error mentioning CLICKUP_API_TOKEN, got %v", err)
+	}
+
+	t.Setenv(clickupTokenEnvVar, "")
+	if _, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{}); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestClickUpProvider_IssueOperations(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.Path, string(body)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(gotPath, "/comment"):
+			// ClickUp returns comments newest first.
+			json.NewEncoder(w).Encode(map[string]any{"comments": []map[string]any{
+				{"id": "2", "comment_text": "Second", "date": "1767261600000", "user": map[string]string{"username": "bob"}},
+				{"id": "1", "comment_text": "First", "date": "1767258000000", "user": map[string]string{"username": "alice"}},
+			}})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "abc1", "name": "T", "url": "https://app.clickup.com/t/abc1",
+				"status": map[string]string{"status": "complete", "type": "closed"},
+				"tags":   []map[string]string{{"name": "approved"}},
+			})
+		case r.Method == http.MethodPost:
+			json.NewEncoder(w).Encode(map[string]any{"id": 55, "hist_id": "h1"})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	p := newClickUpTestProvider(t, server)
+	ctx := context.Background()
+
+	issue, err := p.GetIssue(ctx, "/test/repo", "abc1")
+	if err != nil || issue.ID != "abc1" || issue.Source != SourceClickUp {
+		t.Errorf("GetIssue = %+v, %v", issue, err)
+	}
+
+	if has, err := p.CheckIssueHasLabel(ctx, "/test/repo", "abc1", "Approved"); err != nil || !has {
+		t.Errorf("CheckIssueHasLabel = %v, %v; want true", has, err)
+	}
+
+	if closed, err := p.IsIssueClosed(ctx, "/test/repo", "abc1"); err != nil || !closed {
+		t.Errorf("IsIssueClosed = %v, %v; want true", closed, err)
+	}
+
+	comments, err := p.GetIssueComments(ctx, "/test/repo", "abc1")
+	if err != nil {
+		t.Fatalf("GetIssueComments: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "1" || comments[0].Author != "alice" || comments[0].CreatedAt.IsZero() {
+		t.Errorf("expected comments oldest first, got %+v", comments)
+	}
+
+	if err := p.Comment(ctx, "/test/repo", "abc1", "hello"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/task/abc1/comment" || !strings.Contains(gotBody, `"comment_text":"hello"`) {
+		t.Errorf("Comment sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	if err := p.UpdateComment(ctx, "/test/repo", "abc1", "2", "edited"); err != nil {
+		t.Fatalf("UpdateComment: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotPath != "/comment/2" || !strings.Contains(gotBody, `"edited"`) {
+		t.Errorf("UpdateComment sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	if err := p.RemoveLabel(ctx, "/test/repo", "abc1", "erg"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/task/abc1/tag/erg" {
+		t.Errorf("RemoveLabel sent %s %s", gotMethod, gotPath)
+	}
+
+	id, err := p.PostClaim(ctx, "/test/repo", "abc1", ClaimInfo{DaemonID: "d1"})
+	if err != nil || id != "55" {
+		t.Errorf("PostClaim = %q, %v; want 55", id, err)
+	}
+
+	if err := p.DeleteClaim(ctx, "/test/repo", "abc1", "55"); err != nil {
+		t.Fatalf("DeleteClaim: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/comment/55" {
+		t.Errorf("DeleteClaim sent %s %s", gotMethod, gotPath)
+	}
+
+	if _, err := p.GetIssue(ctx, "/test/repo", ""); err == nil {
+		t.Error("expected error for empty task ID")
+	}
+}
+
+func TestClickUpProvider_CompleteIssue(t *testing.T) {
+	var statusUpdate string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/task/abc1":
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "abc1", "status": map[string]string{"status": "in review", "type": "custom"},
+				"list": map[string]string{"id": "902"},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/list/902":
+			json.NewEncoder(w).Encode(map[string]any{"statuses": []map[string]string{
+				{"status": "to do", "type": "open"},
+				{"status": "shipped", "type": "done"},
+				{"status": "complete", "type": "closed"},
+			}})
+		case r.Method == http.MethodPut && r.URL.Path == "/task/abc1":
+			body, _ := io.ReadAll(r.Body)
+			statusUpdate = string(body)
+			json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := newClickUpTestProvider(t, server)
+	if err := p.CompleteIssue(context.Background(), "/test/repo", "abc1"); err != nil {
+		t.Fatalf("CompleteIssue: %v", err)
+	}
+	if !strings.Contains(statusUpdate, `"status":"complete"`) {
+		t.Errorf("expected task moved to the closed status, got %q", statusUpdate)
+	}
+}
@@ -4,8 +4,10 @@ import "github.com/zhubert/erg/internal/config"
 
 // Compile-time interface satisfaction checks.
 var (
-	_ AsanaConfigProvider  = (*config.Config)(nil)
-	_ LinearConfigProvider = (*config.Config)(nil)
+	_ AsanaConfigProvider   = (*config.Config)(nil)
+	_ LinearConfigProvider  = (*config.Config)(nil)
+	_ GitLabConfigProvider  = (*config.Config)(nil)
+	_ ClickUpConfigProvider = (*config.Config)(nil)
 )
 
 // AsanaConfigProvider defines the configuration interface required by AsanaProvider.
@@ -19,3 +21,15 @@ type LinearConfigProvider interface {
 	HasLinearTeam(repoPath string) bool
 	GetLinearTeam(repoPath string) string
 }
+
+// GitLabConfigProvider defines the configuration interface required by GitLabProvider.
+type GitLabConfigProvider interface {
+	HasGitLabProject(repoPath string) bool
+	GetGitLabProject(repoPath string) string
+}
+
+// ClickUpConfigProvider defines the configuration interface required by ClickUpProvider.
+type ClickUpConfigProvider interface {
+	HasClickUpList(repoPath string) bool
+	GetClickUpList(repoPath string) string
+}
@@ -0,0 +1,36 @@
+package issues
+
+import "regexp"
+
+// dependencyLinePattern matches a line declaring what an issue depends on,
+// e.g. "Blocked by #12", "Depends on: #12, #14" or "**Blocked by:** ENG-7".
+var dependencyLinePattern = regexp.MustCompile(`(?im)^\s*(?:[-*]\s+)?\**(?:blocked by|depends on)\**\s*:?\**\s*(.+)$`)
+
+// dependencyRefPattern matches one reference on a dependency line: "#12"
+// or a Linear-style identifier such as "ENG-7".
+var dependencyRefPattern = regexp.MustCompile(`#(\d+)\b|\b([A-Z][A-Z0-9]*-\d+)\b`)
+
+// ParseDependencies returns the IDs of the issues a body declares it is
+// blocked by, in order and without duplicates. IDs are in the issue's own
+// tracker: "#12" yields "12", "ENG-7" stays as is.
+func ParseDependencies(body string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, line := range dependencyLinePattern.FindAllStringSubmatch(body, -1) {
+		for _, m := range dependencyRefPattern.FindAllStringSubmatch(line[1], -1) {
+			id := m[1] + m[2]
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Blocker is an issue the tracker records as blocking another.
+type Blocker struct {
+	ID     string
+	Title  string
+	Closed bool // closed, completed or canceled in the tracker
+}
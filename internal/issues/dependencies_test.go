@@ -0,0 +1,83 @@
+package issues
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/git"
+)
+
+func TestParseDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "empty", body: "", want: nil},
+		{name: "blocked by", body: "Add the API.\n\nBlocked by #12", want: []string{"12"}},
+		{name: "depends on list", body: "Depends on: #12, #14 and #12", want: []string{"12", "14"}},
+		{name: "bold key and linear ids", body: "**Blocked by:** ENG-7, ENG-9", want: []string{"ENG-7", "ENG-9"}},
+		{name: "list item", body: "- depends on #3", want: []string{"3"}},
+		{name: "several lines", body: "Blocked by #1\nDepends on #2", want: []string{"1", "2"}},
+		{name: "mid-sentence mention ignored", body: "This is blocked by #7 upstream", want: nil},
+		{name: "no reference", body: "Blocked by: the design review", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDependencies(tt.body); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseDependencies(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubProvider_FetchBlockers(t *testing.T) {
+	mock := exec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"api", "repos/:owner/:repo/issues/20/dependencies/blocked_by"}, exec.MockResponse{
+		Stdout: []byte(`[{"number":12,"title":"Add schema","state":"closed"},{"number":14,"title":"Add API","state":"open"}]`),
+	})
+
+	p := NewGitHubProvider(git.NewGitServiceWithExecutor(mock))
+	blockers, err := p.FetchBlockers(context.Background(), "/repo", "20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Blocker{{ID: "12", Title: "Add schema", Closed: true}, {ID: "14", Title: "Add API"}}
+	if !slices.Equal(blockers, want) {
+		t.Errorf("blockers = %+v, want %+v", blockers, want)
+	}
+
+	if _, err := p.FetchBlockers(context.Background(), "/repo", "abc"); err == nil {
+		t.Error("expected error for a non-numeric issue ID")
+	}
+}
+
+func TestLinearProvider_FetchBlockers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"issue": {"inverseRelations": {"nodes": [
+			{"type": "blocks", "issue": {"identifier": "ENG-7", "title": "Schema", "state": {"type": "completed"}}},
+			{"type": "related", "issue": {"identifier": "ENG-8", "title": "Docs", "state": {"type": "started"}}},
+			{"type": "blocks", "issue": {"identifier": "ENG-9", "title": "API", "state": {"type": "started"}}}
+		]}}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv(linearAPIKeyEnvVar, "lin_api_test123")
+	p := NewLinearProviderWithClient(&config.Config{}, server.Client(), server.URL)
+
+	blockers, err := p.FetchBlockers(context.Background(), "/test/repo", "ENG-10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Blocker{{ID: "ENG-7", Title: "Schema", Closed: true}, {ID: "ENG-9", Title: "API"}}
+	if !slices.Equal(blockers, want) {
+		t.Errorf("blockers = %+v, want %+v", blockers, want)
+	}
+}
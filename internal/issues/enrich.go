@@ -0,0 +1,125 @@
+package issues
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// enrichLinkLimit caps how many linked issues and PRs are described.
+	enrichLinkLimit = 5
+	// enrichCommentLimit caps how many recent comments are included.
+	enrichCommentLimit = 5
+	// enrichExcerptRunes caps each linked description and comment, so one
+	// long thread cannot crowd out the rest.
+	enrichExcerptRunes = 1500
+)
+
+// linkedIssue is an issue or PR the enriched issue refers to.
+type linkedIssue struct {
+	Ref      string // "#12", "ENG-7"
+	Relation string // "PR", "issue", "blocks", "related", ...
+	Title    string
+	State    string
+	URL      string
+	Body     string
+}
+
+// attachment is a file attached to the enriched issue.
+type attachment struct {
+	Name string
+	URL  string
+}
+
+// enrichment is the context gathered for an issue before a session starts.
+type enrichment struct {
+	Links       []linkedIssue
+	Fields      [][2]string // name, value
+	Attachments []attachment
+	Comments    []IssueComment
+}
+
+// render formats the enrichment as markdown, or "" when nothing was found.
+func (e enrichment) render() string {
+	var sb strings.Builder
+	if len(e.Links) > 0 {
+		sb.WriteString("### Linked issues\n")
+		for _, l := range e.Links {
+			fmt.Fprintf(&sb, "\n- %s (%s, %s): %s", l.Ref, l.Relation, l.State, l.Title)
+			if l.URL != "" {
+				fmt.Fprintf(&sb, " — %s", l.URL)
+			}
+			if body := strings.TrimSpace(l.Body); body != "" {
+				sb.WriteString("\n\n" + indent(excerpt(body), "  > "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(e.Fields) > 0 {
+		sb.WriteString("\n### Fields\n\n")
+		for _, f := range e.Fields {
+			fmt.Fprintf(&sb, "- %s: %s\n", f[0], f[1])
+		}
+	}
+	if len(e.Attachments) > 0 {
+		sb.WriteString("\n### Attachments\n\n")
+		for _, a := range e.Attachments {
+			if a.URL != "" {
+				fmt.Fprintf(&sb, "- %s: %s\n", a.Name, a.URL)
+			} else {
+				fmt.Fprintf(&sb, "- %s\n", a.Name)
+			}
+		}
+	}
+	if len(e.Comments) > 0 {
+		sb.WriteString("\n### Recent comments\n")
+		for _, c := range e.Comments {
+			fmt.Fprintf(&sb, "\n**%s** (%s):\n%s\n", c.Author, c.CreatedAt.UTC().Format("2006-01-02"), excerpt(strings.TrimSpace(c.Body)))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// recentHumanComments returns the last enrichCommentLimit comments that erg
+// did not post itself. Claims, step markers, plans and unqueue notices are
+// bookkeeping, not discussion.
+func recentHumanComments(comments []IssueComment) []IssueComment {
+	var human []IssueComment
+	for _, c := range comments {
+		if isErgComment(c.Body) {
+			continue
+		}
+		human = append(human, c)
+	}
+	if len(human) > enrichCommentLimit {
+		human = human[len(human)-enrichCommentLimit:]
+	}
+	return human
+}
+
+// isErgComment reports whether a comment body carries one of erg's markers.
+func isErgComment(body string) bool {
+	for _, marker := range []string{
+		"erg:step=", "<!-- erg:plan", unqueuedMarkerPrefixGitHub, unqueuedMarkerPrefixVisible,
+		claimMarkerGitHub, claimMarkerVisible,
+	} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// excerpt truncates s to enrichExcerptRunes runes.
+func excerpt(s string) string {
+	runes := []rune(s)
+	if len(runes) <= enrichExcerptRunes {
+		return s
+	}
+	return string(runes[:enrichExcerptRunes]) + "…"
+}
+
+// indent prefixes every line of s.
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,76 @@
+package issues
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrichment_RenderEmpty(t *testing.T) {
+	if got := (enrichment{}).render(); got != "" {
+		t.Errorf("empty enrichment rendered %q", got)
+	}
+}
+
+func TestEnrichment_RenderSections(t *testing.T) {
+	e := enrichment{
+		Links:       []linkedIssue{{Ref: "#12", Relation: "PR", State: "closed", Title: "Add login", URL: "https://example.com/12", Body: "line one\nline two"}},
+		Fields:      [][2]string{{"Priority", "High"}},
+		Attachments: []attachment{{Name: "trace.log", URL: "https://example.com/trace.log"}},
+		Comments:    []IssueComment{{Author: "alice", Body: "Fails on Safari", CreatedAt: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}},
+	}
+	got := e.render()
+
+	for _, want := range []string{
+		"- #12 (PR, closed): Add login — https://example.com/12",
+		"  > line one\n  > line two",
+		"- Priority: High",
+		"- trace.log: https://example.com/trace.log",
+		"**alice** (2026-03-04):\nFails on Safari",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("render missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRecentHumanComments(t *testing.T) {
+	var comments []IssueComment
+	for i := range enrichCommentLimit + 2 {
+		comments = append(comments, IssueComment{Author: "alice", Body: strings.Repeat("x", i+1)})
+	}
+	comments = append(comments,
+		IssueComment{Body: "<!-- erg:step=plan -->\nplan posted"},
+		IssueComment{Body: FormatUnqueuedComment(SourceLinear, "done")},
+		IssueComment{Body: claimMarkerVisible + `{"daemon":"d1"}`},
+	)
+
+	got := recentHumanComments(comments)
+	if len(got) != enrichCommentLimit {
+		t.Fatalf("got %d comments, want %d", len(got), enrichCommentLimit)
+	}
+	if got[len(got)-1].Body != strings.Repeat("x", enrichCommentLimit+2) {
+		t.Errorf("expected the newest human comment last, got %q", got[len(got)-1].Body)
+	}
+}
+
+func TestExcerpt_TruncatesLongText(t *testing.T) {
+	long := strings.Repeat("é", enrichExcerptRunes+10)
+	if got := excerpt(long); len([]rune(got)) != enrichExcerptRunes+1 || !strings.HasSuffix(got, "…") {
+		t.Errorf("excerpt kept %d runes", len([]rune(got)))
+	}
+	if got := excerpt("short"); got != "short" {
+		t.Errorf("excerpt(short) = %q", got)
+	}
+}
+
+func TestGitHubRefPattern(t *testing.T) {
+	body := "Follow-up to #12 and (#34). See &#35; and page#56.\n#78 too"
+	var got []string
+	for _, m := range githubRefPattern.FindAllStringSubmatch(body, -1) {
+		got = append(got, m[1])
+	}
+	if strings.Join(got, ",") != "12,34,78" {
+		t.Errorf("refs = %v, want [12 34 78]", got)
+	}
+}
@@ -0,0 +1,21 @@
+package issues
+
+import (
+	"regexp"
+	"strings"
+)
+
+// epicRefPattern matches a line declaring an issue's parent epic, e.g.
+// "Epic: #12", "Part of #12", or "Parent: ENG-40".
+var epicRefPattern = regexp.MustCompile(`(?im)^\s*(?:epic|parent|part of)\s*:?\s*#?([A-Za-z0-9][A-Za-z0-9_-]*)\s*$`)
+
+// ParseEpicRef returns the ID of the epic an issue body declares itself part
+// of, or "" when there is none. The ID is in the issue's own tracker: an issue
+// number for GitHub and GitLab, an identifier for Linear, a task GID for Asana.
+func ParseEpicRef(body string) string {
+	m := epicRefPattern.FindStringSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[1])
+}
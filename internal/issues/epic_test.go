@@ -0,0 +1,26 @@
+package issues
+
+import "testing"
+
+func TestParseEpicRef(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "epic hash", body: "Fix the login.\n\nEpic: #12", want: "12"},
+		{name: "part of", body: "part of #7\nmore text", want: "7"},
+		{name: "linear parent", body: "Parent: ENG-40", want: "ENG-40"},
+		{name: "asana gid", body: "Epic: 1209876543210", want: "1209876543210"},
+		{name: "mid-sentence mention ignored", body: "This is part of #7 and #8", want: ""},
+		{name: "no reference", body: "Just a bug.", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseEpicRef(tt.body); got != tt.want {
+				t.Errorf("ParseEpicRef(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,10 @@ var (
 	_ IssueStateChecker      = (*FakeProvider)(nil)
 	_ ProviderSectionChecker = (*FakeProvider)(nil)
 	_ ProviderSectionMover   = (*FakeProvider)(nil)
+	_ ProviderWriter         = (*FakeProvider)(nil)
+	_ ProviderSubtaskFetcher = (*FakeProvider)(nil)
+	_ ProviderEnricher       = (*FakeProvider)(nil)
+	_ ProviderBlockerFetcher = (*FakeProvider)(nil)
 )
 
 // FakeProviderCall records a single method invocation on FakeProvider.
@@ -32,6 +36,7 @@ type FakeProvider struct {
 	configured bool
 	issues     []Issue
 	fetchErr   error
+	commentErr error
 
 	// Per-issue data
 	comments     map[string][]IssueComment  // issueID → comments
@@ -40,14 +45,26 @@ type FakeProvider struct {
 	claims       map[string][]ClaimInfo     // issueID → claims
 	sections     map[string]string          // issueID → section name
 	issuesByID   map[string]Issue           // issueID → issue
+	createErr    error
+	nextIssueNum int
+	noPRLink     bool
+	subtasks     map[string][]Issue // parentID → sub-tasks
+	enrichment   map[string]string  // issueID → EnrichIssue result
+	enrichErr    error
+	blockers     map[string][]Blocker // issueID → FetchBlockers result
 
 	// Call recording (for assertions)
 	CommentCalls       []FakeProviderCall
 	RemoveLabelCalls   []FakeProviderCall
+	ClaimCalls         []FakeProviderCall
+	UnclaimCalls       []FakeProviderCall
+	CloseIssueCalls    []FakeProviderCall
 	PostClaimCalls     []FakeProviderCall
 	DeleteClaimCalls   []FakeProviderCall
 	MoveToSectionCalls []FakeProviderCall
 	UpdateCommentCalls []FakeProviderCall
+	CreateIssueCalls   []FakeProviderCall // Args: title, body, labels...
+	EnrichIssueCalls   []FakeProviderCall
 }
 
 // NewFakeProvider creates a new FakeProvider with the given source.
@@ -84,6 +101,66 @@ func (f *FakeProvider) SetFetchError(err error) {
 	f.fetchErr = err
 }
 
+// SetCommentError makes Comment return an error without recording a comment.
+func (f *FakeProvider) SetCommentError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commentErr = err
+}
+
+// SetCreateError makes CreateIssue return an error without creating an issue.
+func (f *FakeProvider) SetCreateError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.createErr = err
+}
+
+// SetNoPRLink makes GetPRLinkText return "", like trackers that PR keywords
+// cannot close.
+func (f *FakeProvider) SetNoPRLink(noLink bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.noPRLink = noLink
+}
+
+// SetSubtasks sets the sub-tasks of a parent issue. FetchSubtasks returns
+// those not marked closed, with Parent set.
+func (f *FakeProvider) SetSubtasks(parentID string, subtasks []Issue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.subtasks == nil {
+		f.subtasks = make(map[string][]Issue)
+	}
+	f.subtasks[parentID] = subtasks
+}
+
+// SetEnrichment sets what EnrichIssue returns for the given issue.
+func (f *FakeProvider) SetEnrichment(issueID, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.enrichment == nil {
+		f.enrichment = make(map[string]string)
+	}
+	f.enrichment[issueID] = text
+}
+
+// SetEnrichError makes EnrichIssue fail with err.
+func (f *FakeProvider) SetEnrichError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enrichErr = err
+}
+
+// SetBlockers sets what FetchBlockers returns for the given issue.
+func (f *FakeProvider) SetBlockers(issueID string, blockers []Blocker) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.blockers == nil {
+		f.blockers = make(map[string][]Blocker)
+	}
+	f.blockers[issueID] = blockers
+}
+
 // SetComments sets what GetIssueComments returns for the given issue.
 func (f *FakeProvider) SetComments(issueID string, comments []IssueComment) {
 	f.mu.Lock()
@@ -161,6 +238,11 @@ func (f *FakeProvider) GenerateBranchName(issue Issue) string {
 }
 
 func (f *FakeProvider) GetPRLinkText(issue Issue) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.noPRLink {
+		return ""
+	}
 	return fmt.Sprintf("Fixes #%s", issue.ID)
 }
 
@@ -182,6 +264,9 @@ func (f *FakeProvider) RemoveLabel(_ context.Context, _ string, issueID string,
 func (f *FakeProvider) Comment(_ context.Context, _ string, issueID string, body string) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if f.commentErr != nil {
+		return f.commentErr
+	}
 	f.CommentCalls = append(f.CommentCalls, FakeProviderCall{
 		IssueID: issueID,
 		Args:    []string{body},
@@ -189,6 +274,36 @@ func (f *FakeProvider) Comment(_ context.Context, _ string, issueID string, body
 	return nil
 }
 
+func (f *FakeProvider) Claim(_ context.Context, _ string, issueID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ClaimCalls = append(f.ClaimCalls, FakeProviderCall{IssueID: issueID})
+	if f.labels[issueID][ClaimLabel] {
+		return false, nil
+	}
+	if f.labels[issueID] == nil {
+		f.labels[issueID] = make(map[string]bool)
+	}
+	f.labels[issueID][ClaimLabel] = true
+	return true, nil
+}
+
+func (f *FakeProvider) Unclaim(_ context.Context, _ string, issueID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.UnclaimCalls = append(f.UnclaimCalls, FakeProviderCall{IssueID: issueID})
+	delete(f.labels[issueID], ClaimLabel)
+	return nil
+}
+
+func (f *FakeProvider) CloseIssue(_ context.Context, _ string, issueID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.CloseIssueCalls = append(f.CloseIssueCalls, FakeProviderCall{IssueID: issueID})
+	f.closedIssues[issueID] = true
+	return nil
+}
+
 // --- ProviderCommentUpdater ---
 
 func (f *FakeProvider) UpdateComment(_ context.Context, _ string, issueID string, commentID string, body string) error {
@@ -287,3 +402,70 @@ func (f *FakeProvider) MoveToSection(_ context.Context, _ string, issueID string
 	f.sections[issueID] = section
 	return nil
 }
+
+// --- ProviderWriter ---
+
+// CreateIssue records the call and adds the issue to issuesByID (for GetIssue).
+// Created issues are numbered "new-1", "new-2", and so on.
+func (f *FakeProvider) CreateIssue(_ context.Context, _ string, title, body string, labels []string) (*Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.CreateIssueCalls = append(f.CreateIssueCalls, FakeProviderCall{
+		Args: append([]string{title, body}, labels...),
+	})
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.nextIssueNum++
+	issue := Issue{
+		ID:     fmt.Sprintf("new-%d", f.nextIssueNum),
+		Title:  title,
+		Body:   body,
+		Source: f.source,
+		Labels: append([]string(nil), labels...),
+	}
+	f.issuesByID[issue.ID] = issue
+	for _, label := range labels {
+		if f.labels[issue.ID] == nil {
+			f.labels[issue.ID] = make(map[string]bool)
+		}
+		f.labels[issue.ID][label] = true
+	}
+	return &issue, nil
+}
+
+// --- ProviderSubtaskFetcher ---
+
+func (f *FakeProvider) FetchSubtasks(_ context.Context, _ string, parentID string) ([]Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var open []Issue
+	for _, issue := range f.subtasks[parentID] {
+		if f.closedIssues[issue.ID] {
+			continue
+		}
+		issue.Parent = parentID
+		open = append(open, issue)
+	}
+	return open, nil
+}
+
+// --- ProviderEnricher ---
+
+func (f *FakeProvider) EnrichIssue(_ context.Context, _ string, issue Issue) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.EnrichIssueCalls = append(f.EnrichIssueCalls, FakeProviderCall{IssueID: issue.ID})
+	if f.enrichErr != nil {
+		return "", f.enrichErr
+	}
+	return f.enrichment[issue.ID], nil
+}
+
+// --- ProviderBlockerFetcher ---
+
+func (f *FakeProvider) FetchBlockers(_ context.Context, _ string, issueID string) ([]Blocker, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.blockers[issueID], nil
+}
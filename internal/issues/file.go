@@ -0,0 +1,371 @@
+package issues
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BacklogFile is the single-file backlog at the repo root. It holds any
+	// number of items, each introduced by its own front-matter block.
+	BacklogFile = "BACKLOG.md"
+	// BacklogDir holds one markdown file per backlog item.
+	BacklogDir = ".erg/backlog"
+	// BacklogDoneDir receives completed items.
+	BacklogDoneDir = ".erg/backlog/done"
+)
+
+// fileItemIDPattern restricts backlog IDs to characters safe in branch and file names.
+var fileItemIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// FileProvider implements Provider for a markdown backlog kept in the repo
+// itself, letting teams without an issue tracker drive erg from files in git.
+//
+// Items live either in .erg/backlog/*.md (one item per file, ID defaults to
+// the file name) or in BACKLOG.md (items separated by front-matter blocks).
+// Front-matter keys: id, title, labels, priority. The markdown after the
+// front-matter is the item description; a leading "# " heading is used as the
+// title when none is given.
+type FileProvider struct{}
+
+// NewFileProvider creates a new markdown backlog provider.
+func NewFileProvider() *FileProvider {
+	return &FileProvider{}
+}
+
+// Name returns the human-readable name of this provider.
+func (p *FileProvider) Name() string {
+	return "Markdown Backlog"
+}
+
+// Source returns the source type for this provider.
+func (p *FileProvider) Source() Source {
+	return SourceFile
+}
+
+// fileFrontMatter is the YAML front-matter of a backlog item.
+type fileFrontMatter struct {
+	ID       string   `yaml:"id"`
+	Title    string   `yaml:"title"`
+	Labels   []string `yaml:"labels"`
+	Priority int      `yaml:"priority"`
+}
+
+// fileItem is a parsed backlog item and where it came from.
+type fileItem struct {
+	fileFrontMatter
+	Body string
+	Path string // repo-relative path of the file holding the item
+}
+
+func (i fileItem) toIssue() Issue {
+	return Issue{
+		ID:       i.ID,
+		Title:    i.Title,
+		Body:     i.Body,
+		URL:      i.Path,
+		Source:   SourceFile,
+		Priority: i.Priority,
+		Labels:   i.Labels,
+	}
+}
+
+func (i fileItem) hasLabel(label string) bool {
+	return slices.ContainsFunc(i.Labels, func(l string) bool {
+		return strings.EqualFold(l, label)
+	})
+}
+
+// FetchIssues returns the open backlog items, highest priority first.
+// Priority 1 is the highest; items without a priority sort last. When
+// filter.Label is set, only items carrying that label are returned.
+func (p *FileProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
+	items, err := loadBacklog(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var result []Issue
+	for _, item := range items {
+		if filter.Label != "" && !item.hasLabel(filter.Label) {
+			continue
+		}
+		result = append(result, item.toIssue())
+	}
+	return result, nil
+}
+
+// GetIssue returns the open backlog item with the given ID.
+// Implements IssueGetter.
+func (p *FileProvider) GetIssue(ctx context.Context, repoPath string, id string) (*Issue, error) {
+	item, err := findBacklogItem(repoPath, id)
+	if err != nil {
+		return nil, err
+	}
+	issue := item.toIssue()
+	return &issue, nil
+}
+
+// IsIssueClosed reports whether the item is no longer in the open backlog,
+// i.e. it was completed or deleted. Implements IssueStateChecker.
+func (p *FileProvider) IsIssueClosed(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	_, err := findBacklogItem(repoPath, issueID)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	return false, err
+}
+
+// IsConfigured returns true if the repo has a BACKLOG.md or .erg/backlog directory.
+func (p *FileProvider) IsConfigured(repoPath string) bool {
+	if _, err := os.Stat(filepath.Join(repoPath, BacklogFile)); err == nil {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(repoPath, BacklogDir))
+	return err == nil && info.IsDir()
+}
+
+// GenerateBranchName returns a branch name for the given backlog item.
+// Format: "backlog-{id}"
+func (p *FileProvider) GenerateBranchName(issue Issue) string {
+	return fmt.Sprintf("backlog-%s", strings.ToLower(issue.ID))
+}
+
+// GetPRLinkText returns "" — backlog items are completed by CompleteIssue
+// after merge, not by keywords in the PR body.
+func (p *FileProvider) GetPRLinkText(issue Issue) string {
+	return ""
+}
+
+// CompleteIssue moves a merged item into .erg/backlog/done/<id>.md, removing
+// it from BACKLOG.md if that is where it lived. The move is made in the
+// repo's working tree; committing it is left to the team.
+// Implements ProviderCompleter.
+func (p *FileProvider) CompleteIssue(ctx context.Context, repoPath string, issueID string) error {
+	item, err := findBacklogItem(repoPath, issueID)
+	if err != nil {
+		return err
+	}
+
+	doneDir := filepath.Join(repoPath, BacklogDoneDir)
+	if err := os.MkdirAll(doneDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", BacklogDoneDir, err)
+	}
+	donePath := filepath.Join(doneDir, item.ID+".md")
+
+	if item.Path != BacklogFile {
+		if err := os.Rename(filepath.Join(repoPath, item.Path), donePath); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", item.Path, BacklogDoneDir, err)
+		}
+		return nil
+	}
+
+	// Cut the item out of BACKLOG.md into its own done file, leaving the
+	// rest of the file, including any text before the first item, as is.
+	path := filepath.Join(repoPath, BacklogFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range splitBacklogEntries(data) {
+		parsed, err := parseBacklogItem(entry.data, BacklogFile, "")
+		if err != nil || parsed.ID != item.ID {
+			continue
+		}
+		if err := os.WriteFile(donePath, entry.data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", donePath, err)
+		}
+		rest := append(data[:entry.start:entry.start], data[entry.end:]...)
+		return os.WriteFile(path, rest, 0o644)
+	}
+	return fmt.Errorf("backlog item %q: %w", item.ID, os.ErrNotExist)
+}
+
+// findBacklogItem returns the open item with the given ID, or an error
+// wrapping os.ErrNotExist when there is none.
+func findBacklogItem(repoPath, id string) (fileItem, error) {
+	items, err := loadBacklog(repoPath)
+	if err != nil {
+		return fileItem{}, err
+	}
+	for _, item := range items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return fileItem{}, fmt.Errorf("backlog item %q: %w", id, os.ErrNotExist)
+}
+
+// loadBacklog reads all open items from BACKLOG.md and .erg/backlog/*.md,
+// sorted by priority then ID. Duplicate or invalid IDs are errors.
+func loadBacklog(repoPath string) ([]fileItem, error) {
+	var items []fileItem
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, BacklogFile)); err == nil {
+		for _, entry := range splitBacklogEntries(data) {
+			item, err := parseBacklogItem(entry.data, BacklogFile, "")
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", BacklogFile, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(repoPath, BacklogDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backlog item: %w", err)
+		}
+		name := filepath.Base(path)
+		item, err := parseBacklogItem(data, filepath.Join(BacklogDir, name), strings.TrimSuffix(name, ".md"))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	seen := make(map[string]string, len(items))
+	for _, item := range items {
+		if prev, dup := seen[item.ID]; dup {
+			return nil, fmt.Errorf("duplicate backlog ID %q in %s and %s", item.ID, prev, item.Path)
+		}
+		seen[item.ID] = item.Path
+	}
+
+	slices.SortStableFunc(items, func(a, b fileItem) int {
+		if c := cmp.Compare(sortPriority(a.Priority), sortPriority(b.Priority)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return items, nil
+}
+
+// sortPriority maps an unset priority to the lowest rank.
+func sortPriority(p int) int {
+	if p <= 0 {
+		return math.MaxInt
+	}
+	return p
+}
+
+// frontMatterKeys are the keys a backlog item's front-matter may set.
+var frontMatterKeys = map[string]bool{"id": true, "title": true, "labels": true, "priority": true}
+
+// backlogEntry is one item of BACKLOG.md and its byte range in the file.
+type backlogEntry struct {
+	data       []byte
+	start, end int
+}
+
+// splitBacklogEntries splits BACKLOG.md into entries. Each entry starts with a
+// "---" front-matter block and runs to the next one; text before the first
+// block is ignored. A "---" line only opens a block when the lines up to the
+// next "---" are a YAML mapping setting a front-matter key, so horizontal
+// rules in an item's body stay part of it.
+func splitBacklogEntries(data []byte) []backlogEntry {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+
+	var starts []int
+	for i := 0; i < len(lines); i++ {
+		if !isDelimiter(lines[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && !isDelimiter(lines[j]) {
+			j++
+		}
+		if j == len(lines) || !isFrontMatter(data[offsets[i+1]:offsets[j]]) {
+			continue
+		}
+		starts = append(starts, offsets[i])
+		i = j
+	}
+
+	entries := make([]backlogEntry, len(starts))
+	for k, start := range starts {
+		end := len(data)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		entries[k] = backlogEntry{data: data[start:end], start: start, end: end}
+	}
+	return entries
+}
+
+func isDelimiter(line []byte) bool {
+	return string(bytes.TrimSpace(line)) == "---"
+}
+
+// isFrontMatter reports whether block is a YAML mapping setting at least
+// one front-matter key.
+func isFrontMatter(block []byte) bool {
+	var m map[string]any
+	if err := yaml.Unmarshal(block, &m); err != nil {
+		return false
+	}
+	for key := range m {
+		if frontMatterKeys[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBacklogItem parses one item: optional front-matter followed by a
+// markdown body. defaultID is used when the front-matter has no id.
+func parseBacklogItem(data []byte, path, defaultID string) (fileItem, error) {
+	item := fileItem{Path: path}
+	body := string(data)
+
+	if rest, ok := strings.CutPrefix(strings.TrimLeft(body, "\n"), "---\n"); ok {
+		fm, after, found := strings.Cut(rest, "\n---")
+		if !found {
+			return fileItem{}, fmt.Errorf("%s: unterminated front-matter", path)
+		}
+		if err := yaml.Unmarshal([]byte(fm), &item.fileFrontMatter); err != nil {
+			return fileItem{}, fmt.Errorf("%s: invalid front-matter: %w", path, err)
+		}
+		_, body, _ = strings.Cut(after, "\n")
+	}
+	body = strings.TrimSpace(body)
+
+	if item.ID == "" {
+		item.ID = defaultID
+	}
+	if !fileItemIDPattern.MatchString(item.ID) {
+		return fileItem{}, fmt.Errorf("%s: invalid or missing backlog id %q", path, item.ID)
+	}
+	if item.Title == "" {
+		first, rest, _ := strings.Cut(body, "\n")
+		if title, ok := strings.CutPrefix(first, "# "); ok {
+			item.Title = strings.TrimSpace(title)
+			body = strings.TrimSpace(rest)
+		}
+	}
+	if item.Title == "" {
+		item.Title = item.ID
+	}
+	item.Body = body
+	return item, nil
+}
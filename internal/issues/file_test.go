@@ -0,0 +1,328 @@
+package issues
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Compile-time interface checks.
+var (
+	_ IssueGetter       = (*FileProvider)(nil)
+	_ IssueStateChecker = (*FileProvider)(nil)
+	_ ProviderCompleter = (*FileProvider)(nil)
+)
+
+// writeBacklogFile writes content to the repo-relative path, creating parent
+// directories as needed.
+func writeBacklogFile(t *testing.T, repo, rel, content string) {
+	t.Helper()
+	path := filepath.Join(repo, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileProvider_Basics(t *testing.T) {
+	p := NewFileProvider()
+	if p.Name() != "Markdown Backlog" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+	if p.Source() != SourceFile {
+		t.Errorf("Source() = %q", p.Source())
+	}
+	if got := p.GenerateBranchName(Issue{ID: "Dark-Mode"}); got != "backlog-dark-mode" {
+		t.Errorf("GenerateBranchName = %q, want backlog-dark-mode", got)
+	}
+	if got := p.GetPRLinkText(Issue{ID: "dark-mode"}); got != "" {
+		t.Errorf("GetPRLinkText = %q, want empty", got)
+	}
+}
+
+func TestFileProvider_IsConfigured(t *testing.T) {
+	p := NewFileProvider()
+
+	repo := t.TempDir()
+	if p.IsConfigured(repo) {
+		t.Error("expected IsConfigured=false for repo without a backlog")
+	}
+
+	writeBacklogFile(t, repo, BacklogFile, "")
+	if !p.IsConfigured(repo) {
+		t.Error("expected IsConfigured=true with BACKLOG.md")
+	}
+
+	repo = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, BacklogDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsConfigured(repo) {
+		t.Error("expected IsConfigured=true with .erg/backlog")
+	}
+}
+
+func TestFileProvider_FetchIssues(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, filepath.Join(BacklogDir, "dark-mode.md"), `---
+labels: [ui]
+priority: 2
+---
+# Add dark mode
+
+Follow the system theme.
+`)
+	writeBacklogFile(t, repo, filepath.Join(BacklogDir, "no-front-matter.md"), "Just a description.\n")
+	writeBacklogFile(t, repo, filepath.Join(BacklogDoneDir, "shipped.md"), "# Already done\n")
+	writeBacklogFile(t, repo, BacklogFile, `# Team backlog
+
+Notes before the first entry are ignored.
+
+---
+id: fix-login
+title: Fix login redirect
+labels: [bug, UI]
+priority: 1
+---
+Users land on a blank page.
+
+---
+id: docs
+---
+# Write docs
+`)
+
+	p := NewFileProvider()
+	got, err := p.FetchIssues(context.Background(), repo, FilterConfig{})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+
+	want := []Issue{
+		{ID: "fix-login", Title: "Fix login redirect", Body: "Users land on a blank page.", URL: BacklogFile, Priority: 1, Labels: []string{"bug", "UI"}},
+		{ID: "dark-mode", Title: "Add dark mode", Body: "Follow the system theme.", URL: ".erg/backlog/dark-mode.md", Priority: 2, Labels: []string{"ui"}},
+		{ID: "docs", Title: "Write docs", Body: "", URL: BacklogFile},
+		{ID: "no-front-matter", Title: "no-front-matter", Body: "Just a description.", URL: ".erg/backlog/no-front-matter.md"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d issues, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		w.Source = SourceFile
+		if !reflect.DeepEqual(got[i], w) {
+			t.Errorf("issue %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+
+	filtered, err := p.FetchIssues(context.Background(), repo, FilterConfig{Label: "ui"})
+	if err != nil {
+		t.Fatalf("FetchIssues with label: %v", err)
+	}
+	if len(filtered) != 2 || filtered[0].ID != "fix-login" || filtered[1].ID != "dark-mode" {
+		t.Errorf("label filter = %+v, want fix-login and dark-mode", filtered)
+	}
+}
+
+func TestFileProvider_FetchIssues_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "duplicate id",
+			files: map[string]string{
+				filepath.Join(BacklogDir, "a.md"): "---\nid: same\n---\n",
+				filepath.Join(BacklogDir, "b.md"): "---\nid: same\n---\n",
+			},
+			wantErr: "duplicate backlog ID",
+		},
+		{
+			name:    "missing id in BACKLOG.md",
+			files:   map[string]string{BacklogFile: "---\ntitle: No id\n---\nbody\n"},
+			wantErr: "invalid or missing backlog id",
+		},
+		{
+			name:    "unsafe id",
+			files:   map[string]string{filepath.Join(BacklogDir, "x.md"): "---\nid: ../escape\n---\n"},
+			wantErr: "invalid or missing backlog id",
+		},
+		{
+			name:    "unterminated front-matter",
+			files:   map[string]string{filepath.Join(BacklogDir, "x.md"): "---\nid: x\n"},
+			wantErr: "unterminated front-matter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			for rel, content := range tt.files {
+				writeBacklogFile(t, repo, rel, content)
+			}
+			_, err := NewFileProvider().FetchIssues(context.Background(), repo, FilterConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileProvider_GetIssueAndIsIssueClosed(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, filepath.Join(BacklogDir, "dark-mode.md"), "# Add dark mode\n")
+	p := NewFileProvider()
+	ctx := context.Background()
+
+	issue, err := p.GetIssue(ctx, repo, "dark-mode")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if issue.Title != "Add dark mode" {
+		t.Errorf("Title = %q", issue.Title)
+	}
+	if _, err := p.GetIssue(ctx, repo, "missing"); err == nil {
+		t.Error("expected error for missing item")
+	}
+
+	closed, err := p.IsIssueClosed(ctx, repo, "dark-mode")
+	if err != nil || closed {
+		t.Errorf("IsIssueClosed(dark-mode) = %v, %v; want false, nil", closed, err)
+	}
+	closed, err = p.IsIssueClosed(ctx, repo, "missing")
+	if err != nil || !closed {
+		t.Errorf("IsIssueClosed(missing) = %v, %v; want true, nil", closed, err)
+	}
+}
+
+func TestFileProvider_CompleteIssue_MovesFile(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, filepath.Join(BacklogDir, "dark-mode.md"), "# Add dark mode\n")
+	p := NewFileProvider()
+
+	if err := p.CompleteIssue(context.Background(), repo, "dark-mode"); err != nil {
+		t.Fatalf("CompleteIssue: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, BacklogDir, "dark-mode.md")); !os.IsNotExist(err) {
+		t.Errorf("expected item removed from backlog, stat err = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(repo, BacklogDoneDir, "dark-mode.md"))
+	if err != nil {
+		t.Fatalf("expected item in done/: %v", err)
+	}
+	if string(data) != "# Add dark mode\n" {
+		t.Errorf("done file = %q", data)
+	}
+
+	closed, err := p.IsIssueClosed(context.Background(), repo, "dark-mode")
+	if err != nil || !closed {
+		t.Errorf("IsIssueClosed after complete = %v, %v; want true, nil", closed, err)
+	}
+}
+
+func TestFileProvider_CompleteIssue_SplitsBacklogFile(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, BacklogFile, `---
+id: first
+---
+First item.
+
+---
+id: second
+---
+Second item.
+`)
+	p := NewFileProvider()
+
+	if err := p.CompleteIssue(context.Background(), repo, "first"); err != nil {
+		t.Fatalf("CompleteIssue: %v", err)
+	}
+
+	done, err := os.ReadFile(filepath.Join(repo, BacklogDoneDir, "first.md"))
+	if err != nil {
+		t.Fatalf("expected item in done/: %v", err)
+	}
+	if !strings.Contains(string(done), "First item.") {
+		t.Errorf("done file = %q, want first item", done)
+	}
+
+	remaining, err := p.FetchIssues(context.Background(), repo, FilterConfig{})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "second" || remaining[0].Body != "Second item." {
+		t.Errorf("remaining = %+v, want only second", remaining)
+	}
+}
+
+func TestFileProvider_CompleteIssue_KeepsRestOfBacklogFile(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, BacklogFile, `# Team backlog
+
+Some notes.
+
+---
+id: a
+---
+Item a.
+
+---
+id: b
+---
+Item b.
+`)
+	if err := NewFileProvider().CompleteIssue(context.Background(), repo, "a"); err != nil {
+		t.Fatalf("CompleteIssue: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(repo, BacklogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Team backlog\n\nSome notes.\n\n---\nid: b\n---\nItem b.\n"
+	if string(got) != want {
+		t.Errorf("BACKLOG.md = %q, want %q", got, want)
+	}
+}
+
+func TestFileProvider_HorizontalRuleInBody(t *testing.T) {
+	repo := t.TempDir()
+	writeBacklogFile(t, repo, BacklogFile, `---
+id: a
+---
+Before the rule.
+
+---
+
+After the rule.
+
+---
+id: b
+---
+Item b.
+`)
+	p := NewFileProvider()
+	got, err := p.FetchIssues(context.Background(), repo, FilterConfig{})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[0].Body != "Before the rule.\n\n---\n\nAfter the rule." || got[1].ID != "b" {
+		t.Fatalf("issues = %+v, want a with the rule in its body, then b", got)
+	}
+
+	if err := p.CompleteIssue(context.Background(), repo, "a"); err != nil {
+		t.Fatalf("CompleteIssue: %v", err)
+	}
+	done, err := os.ReadFile(filepath.Join(repo, BacklogDoneDir, "a.md"))
+	if err != nil || !strings.Contains(string(done), "After the rule.") {
+		t.Errorf("done file = %q, %v; want all of item a", done, err)
+	}
+	remaining, err := p.FetchIssues(context.Background(), repo, FilterConfig{})
+	if err != nil || len(remaining) != 1 || remaining[0].ID != "b" {
+		t.Errorf("remaining = %+v, %v; want only b", remaining, err)
+	}
+}
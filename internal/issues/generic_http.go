@@ -0,0 +1,379 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhubert/erg/internal/providerhttp"
+)
+
+const genericHTTPTimeout = 30 * time.Second
+
+// HTTPMapping describes how to read issues from a user-supplied JSON endpoint
+// and, optionally, where to send comments and label removals.
+//
+// Field paths are dot-separated keys into each item ("fields.summary");
+// numeric segments index into arrays ("assignees.0.name"). URL templates may
+// reference {id} and {label}, which are substituted URL-escaped.
+type HTTPMapping struct {
+	URL            string // GET endpoint returning the issue list (required)
+	Items          string // Path to the issue array in the response; empty means the response is the array
+	IDField        string // Default "id"
+	TitleField     string // Default "title"
+	BodyField      string // Default "body"
+	URLField       string // Default "url"
+	LabelsField    string // Default "labels"; strings, objects with a "name" key, or a comma-separated string
+	PriorityField  string // Default "priority"; 1 (urgent) through 4 (low) or a name such as "high"
+	CreatedAtField string // Default "created_at"; an RFC 3339 timestamp or Unix seconds
+	CommentURL     string // Optional POST endpoint for comments; receives {"body": "..."}
+	RemoveLabelURL string // Optional POST endpoint for label removal; receives {"label": "..."}
+	TokenEnv       string // Optional env var holding a bearer token sent with every request
+}
+
+// httpField returns path, or def when path is empty.
+func httpField(path, def string) string {
+	if path == "" {
+		return def
+	}
+	return path
+}
+
+// GenericHTTPProvider implements Provider for any tracker that can serve its
+// issues as JSON, letting users plug in internal trackers without writing Go.
+// Each repo is mapped to an endpoint with SetMapping.
+type GenericHTTPProvider struct {
+	mu         sync.RWMutex
+	mappings   map[string]HTTPMapping // repo path → mapping
+	httpClient *http.Client
+}
+
+// NewGenericHTTPProvider creates a new generic HTTP issue provider.
+func NewGenericHTTPProvider() *GenericHTTPProvider {
+	return NewGenericHTTPProviderWithClient(providerhttp.NewClient(genericHTTPTimeout, providerhttp.DefaultPolicy()))
+}
+
+// NewGenericHTTPProviderWithClient creates a new generic HTTP issue provider with a custom HTTP client (for testing).
+func NewGenericHTTPProviderWithClient(client *http.Client) *GenericHTTPProvider {
+	return &GenericHTTPProvider{
+		mappings:   make(map[string]HTTPMapping),
+		httpClient: client,
+	}
+}
+
+// SetMapping stores the endpoint mapping for the given repo path.
+func (p *GenericHTTPProvider) SetMapping(repoPath string, m HTTPMapping) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mappings[repoPath] = m
+}
+
+// mapping returns the endpoint mapping for the given repo path.
+func (p *GenericHTTPProvider) mapping(repoPath string) (HTTPMapping, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	m, ok := p.mappings[repoPath]
+	return m, ok && m.URL != ""
+}
+
+// Name returns the human-readable name of this provider.
+func (p *GenericHTTPProvider) Name() string {
+	return "HTTP Issues"
+}
+
+// Source returns the source type for this provider.
+func (p *GenericHTTPProvider) Source() Source {
+	return SourceHTTP
+}
+
+// IsConfigured returns true if the repo has an endpoint mapping and, when the
+// mapping names a token env var, that variable is set.
+func (p *GenericHTTPProvider) IsConfigured(repoPath string) bool {
+	m, ok := p.mapping(repoPath)
+	if !ok {
+		return false
+	}
+	return m.TokenEnv == "" || os.Getenv(m.TokenEnv) != ""
+}
+
+// httpBranchUnsafe matches characters that are replaced in generated branch names.
+var httpBranchUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// GenerateBranchName returns a branch name for the given issue.
+// Format: "http-{id}" with the ID lowercased and made safe for git refs.
+func (p *GenericHTTPProvider) GenerateBranchName(issue Issue) string {
+	id := strings.Trim(httpBranchUnsafe.ReplaceAllString(strings.ToLower(issue.ID), "-"), "-.")
+	return fmt.Sprintf("http-%s", id)
+}
+
+// GetPRLinkText returns "" — a generic tracker cannot be closed from PR keywords.
+func (p *GenericHTTPProvider) GetPRLinkText(issue Issue) string {
+	return ""
+}
+
+// FetchIssues retrieves issues from the repo's endpoint. When filter.Label is
+// set, only issues carrying that label (case-insensitive) are returned.
+func (p *GenericHTTPProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
+	m, ok := p.mapping(repoPath)
+	if !ok {
+		return nil, fmt.Errorf("http issue endpoint not configured for this repository")
+	}
+
+	var resp any
+	if err := p.request(ctx, m, http.MethodGet, expandHTTPTemplate(m.URL, "", filter.Label), nil, &resp); err != nil {
+		return nil, err
+	}
+	raw, ok := lookupJSONPath(resp, m.Items)
+	if !ok {
+		return nil, fmt.Errorf("http issue response has no value at %q", m.Items)
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("http issue response value at %q is not an array", m.Items)
+	}
+
+	result := make([]Issue, 0, len(items))
+	for i, item := range items {
+		issue := Issue{
+			ID:     jsonString(item, httpField(m.IDField, "id")),
+			Title:  jsonString(item, httpField(m.TitleField, "title")),
+			Body:   jsonString(item, httpField(m.BodyField, "body")),
+			URL:    jsonString(item, httpField(m.URLField, "url")),
+			Source: SourceHTTP,
+		}
+		if issue.ID == "" {
+			return nil, fmt.Errorf("http issue %d has no ID at %q", i, httpField(m.IDField, "id"))
+		}
+		labels := jsonLabels(item, httpField(m.LabelsField, "labels"))
+		if filter.Label != "" && !slices.ContainsFunc(labels, func(l string) bool { return strings.EqualFold(l, filter.Label) }) {
+			continue
+		}
+		issue.Labels = labels
+		issue.Priority = jsonPriority(item, httpField(m.PriorityField, "priority"))
+		if issue.Priority == 0 {
+			issue.Priority = PriorityFromLabels(labels)
+		}
+		issue.CreatedAt = jsonTime(item, httpField(m.CreatedAtField, "created_at"))
+		result = append(result, issue)
+	}
+	return result, nil
+}
+
+// GetIssue fetches the repo's issue list and returns the issue with the given ID.
+// Implements IssueGetter.
+func (p *GenericHTTPProvider) GetIssue(ctx context.Context, repoPath string, id string) (*Issue, error) {
+	all, err := p.FetchIssues(ctx, repoPath, FilterConfig{})
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range all {
+		if issue.ID == id {
+			return &issue, nil
+		}
+	}
+	return nil, fmt.Errorf("http issue %q not found", id)
+}
+
+// Comment posts a comment to the repo's comment endpoint. It is a no-op when
+// no comment endpoint is configured.
+// Implements ProviderActions.
+func (p *GenericHTTPProvider) Comment(ctx context.Context, repoPath string, issueID string, body string) error {
+	m, ok := p.mapping(repoPath)
+	if !ok || m.CommentURL == "" {
+		return nil
+	}
+	if err := p.request(ctx, m, http.MethodPost, expandHTTPTemplate(m.CommentURL, issueID, ""),
+		map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// RemoveLabel posts to the repo's label-removal endpoint. It is a no-op when
+// no label-removal endpoint is configured.
+// Implements ProviderActions.
+func (p *GenericHTTPProvider) RemoveLabel(ctx context.Context, repoPath string, issueID string, label string) error {
+	m, ok := p.mapping(repoPath)
+	if !ok || m.RemoveLabelURL == "" {
+		return nil
+	}
+	if err := p.request(ctx, m, http.MethodPost, expandHTTPTemplate(m.RemoveLabelURL, issueID, label),
+		map[string]string{"label": label}, nil); err != nil {
+		return fmt.Errorf("failed to remove label: %w", err)
+	}
+	return nil
+}
+
+// Claim always succeeds without marking the issue: the endpoint mapping has
+// no way to add labels.
+// Implements ProviderActions.
+func (p *GenericHTTPProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	return true, nil
+}
+
+// Unclaim is a no-op; see Claim.
+// Implements ProviderActions.
+func (p *GenericHTTPProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	return nil
+}
+
+// CloseIssue is a no-op: the endpoint mapping has no way to close issues.
+// Implements ProviderActions.
+func (p *GenericHTTPProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	return nil
+}
+
+// request executes a JSON request against a user-supplied endpoint. Any 2xx
+// status is treated as success.
+func (p *GenericHTTPProvider) request(ctx context.Context, m HTTPMapping, method, rawURL string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if m.TokenEnv != "" {
+		token := os.Getenv(m.TokenEnv)
+		if token == "" {
+			return fmt.Errorf("%s environment variable not set", m.TokenEnv)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http issue request failed: %w", err)
+	}
+	defer func() {
+		// Drain remaining body so the underlying TCP connection can be reused.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("http issue endpoint %s returned status %d", req.URL.Redacted(), resp.StatusCode)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to parse http issue response: %w", err)
+		}
+	}
+	return nil
+}
+
+// expandHTTPTemplate substitutes {id} and {label} in a URL template.
+func expandHTTPTemplate(tmpl, id, label string) string {
+	return strings.NewReplacer("{id}", url.PathEscape(id), "{label}", url.QueryEscape(label)).Replace(tmpl)
+}
+
+// lookupJSONPath walks a dot-separated path through decoded JSON. An empty
+// path returns v itself.
+func lookupJSONPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// jsonString returns the scalar at path formatted as a string, or "" when
+// the path is missing or does not hold a scalar.
+func jsonString(v any, path string) string {
+	val, _ := lookupJSONPath(v, path)
+	switch s := val.(type) {
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	default:
+		return ""
+	}
+}
+
+// jsonLabels returns the label names at path. Labels may be an array of
+// strings, an array of objects with a "name" key, or a comma-separated string.
+func jsonLabels(v any, path string) []string {
+	val, _ := lookupJSONPath(v, path)
+	var labels []string
+	switch l := val.(type) {
+	case string:
+		for name := range strings.SplitSeq(l, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				labels = append(labels, name)
+			}
+		}
+	case []any:
+		for _, entry := range l {
+			if name := jsonString(entry, ""); name != "" {
+				labels = append(labels, name)
+			} else if name := jsonString(entry, "name"); name != "" {
+				labels = append(labels, name)
+			}
+		}
+	}
+	return labels
+}
+
+// jsonPriority returns the priority at path, given either as a number or as
+// a name such as "high", or 0 when absent or unrecognized.
+func jsonPriority(v any, path string) int {
+	s := jsonString(v, path)
+	if p, err := strconv.Atoi(s); err == nil && p >= 0 {
+		return p
+	}
+	return priorityFromName(s)
+}
+
+// jsonTime returns the time at path, given either as an RFC 3339 timestamp
+// or as Unix seconds, or the zero time when absent or unparsable.
+func jsonTime(v any, path string) time.Time {
+	s := jsonString(v, path)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0)
+	}
+	return time.Time{}
+}
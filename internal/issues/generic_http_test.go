@@ -0,0 +1,226 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Compile-time interface checks.
+var (
+	_ ProviderActions = (*GenericHTTPProvider)(nil)
+	_ IssueGetter     = (*GenericHTTPProvider)(nil)
+)
+
+func TestGenericHTTPProvider_Basics(t *testing.T) {
+	p := NewGenericHTTPProvider()
+	if p.Name() != "HTTP Issues" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+	if p.Source() != SourceHTTP {
+		t.Errorf("Source() = %q", p.Source())
+	}
+	if got := p.GenerateBranchName(Issue{ID: "TRK/42 Fix"}); got != "http-trk-42-fix" {
+		t.Errorf("GenerateBranchName = %q, want http-trk-42-fix", got)
+	}
+	if got := p.GetPRLinkText(Issue{ID: "42"}); got != "" {
+		t.Errorf("GetPRLinkText = %q, want empty", got)
+	}
+}
+
+func TestGenericHTTPProvider_IsConfigured(t *testing.T) {
+	p := NewGenericHTTPProvider()
+	if p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=false without mapping")
+	}
+
+	p.SetMapping("/test/repo", HTTPMapping{URL: "https://tracker.example/issues", TokenEnv: "TRACKER_TOKEN"})
+	t.Setenv("TRACKER_TOKEN", "")
+	if p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=false without token")
+	}
+	t.Setenv("TRACKER_TOKEN", "secret")
+	if !p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=true with mapping and token")
+	}
+}
+
+func TestGenericHTTPProvider_FetchIssues(t *testing.T) {
+	var gotTag string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		gotTag = r.URL.Query().Get("tag")
+		io.WriteString(w, `{"data": {"issues": [
+			{"key": 101, "fields": {"summary": "Add export", "description": "CSV", "labels": ["erg", "ui"], "rank": "high", "opened": "2026-03-01T09:00:00Z"}, "self": "https://tracker.example/101"},
+			{"key": "TRK-2", "fields": {"summary": "Unlabeled", "labels": [{"name": "backend"}]}},
+			{"key": "TRK-3", "fields": {"summary": "Comma labels", "labels": "backend, ERG"}}
+		]}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("TRACKER_TOKEN", "secret")
+	p := NewGenericHTTPProviderWithClient(server.Client())
+	p.SetMapping("/test/repo", HTTPMapping{
+		URL:            server.URL + "/issues?tag={label}",
+		Items:          "data.issues",
+		IDField:        "key",
+		TitleField:     "fields.summary",
+		BodyField:      "fields.description",
+		URLField:       "self",
+		LabelsField:    "fields.labels",
+		PriorityField:  "fields.rank",
+		CreatedAtField: "fields.opened",
+		TokenEnv:       "TRACKER_TOKEN",
+	})
+
+	got, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Label: "erg"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if gotTag != "erg" {
+		t.Errorf("tag query = %q, want erg", gotTag)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected unlabeled issue filtered out, got %+v", got)
+	}
+	want := Issue{ID: "101", Title: "Add export", Body: "CSV", URL: "https://tracker.example/101", Source: SourceHTTP,
+		Priority: 2, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Labels: []string{"erg", "ui"}}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("issue = %+v, want %+v", got[0], want)
+	}
+	if got[1].ID != "TRK-3" {
+		t.Errorf("second issue = %+v, want TRK-3", got[1])
+	}
+
+	issue, err := p.GetIssue(context.Background(), "/test/repo", "TRK-2")
+	if err != nil || issue.Title != "Unlabeled" {
+		t.Errorf("GetIssue = %+v, %v", issue, err)
+	}
+	if _, err := p.GetIssue(context.Background(), "/test/repo", "missing"); err == nil {
+		t.Error("expected error for unknown issue")
+	}
+}
+
+func TestGenericHTTPProvider_FetchIssues_DefaultFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"id": "a1", "title": "T", "body": "B", "url": "U", "labels": ["P1"], "priority": 3, "created_at": 1772355600}]`)
+	}))
+	defer server.Close()
+
+	p := NewGenericHTTPProviderWithClient(server.Client())
+	p.SetMapping("/test/repo", HTTPMapping{URL: server.URL})
+
+	got, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	want := Issue{ID: "a1", Title: "T", Body: "B", URL: "U", Source: SourceHTTP,
+		Priority: 3, CreatedAt: time.Unix(1772355600, 0), Labels: []string{"P1"}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("FetchIssues = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestGenericHTTPProvider_FetchIssues_Errors(t *testing.T) {
+	var response string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	defer server.Close()
+
+	p := NewGenericHTTPProviderWithClient(server.Client())
+	if _, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{}); err == nil {
+		t.Error("expected error without mapping")
+	}
+
+	p.SetMapping("/test/repo", HTTPMapping{URL: server.URL, Items: "issues"})
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  string
+	}{
+		{"status", http.StatusUnauthorized, `{}`, "status 401"},
+		{"missing items", http.StatusOK, `{"other": []}`, `no value at "issues"`},
+		{"not an array", http.StatusOK, `{"issues": {}}`, "not an array"},
+		{"missing id", http.StatusOK, `{"issues": [{"title": "x"}]}`, "has no ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response = tt.status, tt.response
+			_, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenericHTTPProvider_Actions(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	p := NewGenericHTTPProviderWithClient(server.Client())
+	ctx := context.Background()
+
+	// Without endpoints the actions are no-ops.
+	p.SetMapping("/test/repo", HTTPMapping{URL: server.URL})
+	if err := p.Comment(ctx, "/test/repo", "TRK-1", "hi"); err != nil || gotPath != "" {
+		t.Errorf("expected no-op comment, got %v (path %q)", err, gotPath)
+	}
+
+	p.SetMapping("/test/repo", HTTPMapping{
+		URL:            server.URL,
+		CommentURL:     server.URL + "/issues/{id}/comments",
+		RemoveLabelURL: server.URL + "/issues/{id}/labels/remove",
+	})
+	if err := p.Comment(ctx, "/test/repo", "TRK-1", "hello"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if gotPath != "/issues/TRK-1/comments" || gotBody["body"] != "hello" {
+		t.Errorf("Comment sent %s %v", gotPath, gotBody)
+	}
+
+	if err := p.RemoveLabel(ctx, "/test/repo", "TRK-1", "erg"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if gotPath != "/issues/TRK-1/labels/remove" || gotBody["label"] != "erg" {
+		t.Errorf("RemoveLabel sent %s %v", gotPath, gotBody)
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{"a": {"b": [{"c": "deep"}, 7]}}`), &doc)
+
+	if got := jsonString(doc, "a.b.0.c"); got != "deep" {
+		t.Errorf("a.b.0.c = %q, want deep", got)
+	}
+	if got := jsonString(doc, "a.b.1"); got != "7" {
+		t.Errorf("a.b.1 = %q, want 7", got)
+	}
+	for _, path := range []string{"a.x", "a.b.5", "a.b.c", "a.b.0.c.d"} {
+		if _, ok := lookupJSONPath(doc, path); ok {
+			t.Errorf("lookupJSONPath(%q) succeeded, want missing", path)
+		}
+	}
+}
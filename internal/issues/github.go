@@ -38,17 +38,32 @@ func (p *GitHubProvider) FetchIssues(ctx context.Context, repoPath string, filte
 
 	issues := make([]Issue, len(ghIssues))
 	for i, gh := range ghIssues {
-		issues[i] = Issue{
-			ID:     strconv.Itoa(gh.Number),
-			Title:  gh.Title,
-			Body:   gh.Body,
-			URL:    gh.URL,
-			Source: SourceGitHub,
-		}
+		issues[i] = FromGitHubIssue(gh)
 	}
 	return issues, nil
 }
 
+// FromGitHubIssue converts an issue fetched through the gh CLI. GitHub has no
+// priority or due date fields, so the priority is derived from the issue's
+// labels and the due date is taken from its milestone.
+func FromGitHubIssue(gh git.GitHubIssue) Issue {
+	labels := gh.LabelNames()
+	issue := Issue{
+		ID:        strconv.Itoa(gh.Number),
+		Title:     gh.Title,
+		Body:      gh.Body,
+		URL:       gh.URL,
+		Source:    SourceGitHub,
+		Priority:  PriorityFromLabels(labels),
+		CreatedAt: gh.CreatedAt,
+		Labels:    labels,
+	}
+	if gh.Milestone != nil {
+		issue.DueAt = gh.Milestone.DueOn
+	}
+	return issue
+}
+
 // IsConfigured returns true - GitHub is always available via gh CLI.
 // The gh CLI is checked as a prerequisite when the app starts.
 func (p *GitHubProvider) IsConfigured(repoPath string) bool {
@@ -87,6 +102,15 @@ func (p *GitHubProvider) Comment(ctx context.Context, repoPath string, issueID s
 	return p.gitService.CommentOnIssue(ctx, repoPath, issueNum, body)
 }
 
+// CloseIssue closes a GitHub issue.
+// Implements ProviderActions.
+func (p *GitHubProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	if _, err := strconv.Atoi(issueID); err != nil {
+		return fmt.Errorf("invalid GitHub issue ID %q: %w", issueID, err)
+	}
+	return p.gitService.CloseIssue(ctx, repoPath, issueID)
+}
+
 // CheckIssueHasLabel returns true if the GitHub issue has the given label.
 // Implements ProviderGateChecker.
 func (p *GitHubProvider) CheckIssueHasLabel(ctx context.Context, repoPath string, issueID string, label string) (bool, error) {
@@ -144,13 +168,8 @@ func (p *GitHubProvider) GetIssue(ctx context.Context, repoPath string, id strin
 	if err != nil {
 		return nil, err
 	}
-	return &Issue{
-		ID:     strconv.Itoa(gh.Number),
-		Title:  gh.Title,
-		Body:   gh.Body,
-		URL:    gh.URL,
-		Source: SourceGitHub,
-	}, nil
+	issue := FromGitHubIssue(*gh)
+	return &issue, nil
 }
 
 // IsIssueClosed returns true if the GitHub issue is in CLOSED state.
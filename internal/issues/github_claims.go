@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // PostClaim posts a claim comment on a GitHub issue and returns the comment ID.
@@ -43,3 +44,44 @@ func (p *GitHubProvider) DeleteClaim(ctx context.Context, repoPath string, issue
 	}
 	return p.gitService.DeleteIssueComment(ctx, repoPath, id)
 }
+
+// Claim adds ClaimLabel to a GitHub issue, creating the label in the
+// repository the first time it is needed.
+// Implements ProviderActions.
+func (p *GitHubProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	issueNum, err := strconv.Atoi(issueID)
+	if err != nil {
+		return false, fmt.Errorf("invalid GitHub issue ID %q: %w", issueID, err)
+	}
+
+	has, err := p.gitService.CheckIssueHasLabel(ctx, repoPath, issueNum, ClaimLabel)
+	if err != nil {
+		return false, err
+	}
+	if has {
+		return false, nil
+	}
+
+	err = p.gitService.AddIssueLabel(ctx, repoPath, issueNum, ClaimLabel)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		// gh refuses labels the repository doesn't define yet.
+		if err := p.gitService.CreateLabel(ctx, repoPath, ClaimLabel, claimLabelColor, claimLabelDescription); err != nil {
+			return false, err
+		}
+		err = p.gitService.AddIssueLabel(ctx, repoPath, issueNum, ClaimLabel)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unclaim removes ClaimLabel from a GitHub issue.
+// Implements ProviderActions.
+func (p *GitHubProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	err := p.RemoveLabel(ctx, repoPath, issueID, ClaimLabel)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return nil // label never created in this repository
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FetchBlockers returns the issues GitHub records as blocking the issue.
+// Implements ProviderBlockerFetcher.
+func (p *GitHubProvider) FetchBlockers(ctx context.Context, repoPath string, issueID string) ([]Blocker, error) {
+	number, err := strconv.Atoi(issueID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub issue ID %q: %w", issueID, err)
+	}
+	refs, err := p.gitService.GetIssueBlockedBy(ctx, repoPath, number)
+	if err != nil {
+		return nil, err
+	}
+	blockers := make([]Blocker, 0, len(refs))
+	for _, r := range refs {
+		blockers = append(blockers, Blocker{
+			ID:     strconv.Itoa(r.Number),
+			Title:  r.Title,
+			Closed: strings.EqualFold(r.State, "closed"),
+		})
+	}
+	return blockers, nil
+}
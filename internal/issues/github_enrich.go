@@ -0,0 +1,52 @@
+package issues
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+// githubRefPattern matches same-repository references such as "#12" or
+// "see #12," but not HTML entities ("&#12;") or URL fragments ("page#12").
+var githubRefPattern = regexp.MustCompile(`(?:^|[^\w&#/])#(\d+)\b`)
+
+// EnrichIssue describes the issues and pull requests the issue body
+// references, followed by its recent comments. References that cannot be
+// fetched (deleted issues, other repositories) are skipped.
+// Implements ProviderEnricher.
+func (p *GitHubProvider) EnrichIssue(ctx context.Context, repoPath string, issue Issue) (string, error) {
+	var e enrichment
+	seen := map[string]bool{issue.ID: true}
+	for _, m := range githubRefPattern.FindAllStringSubmatch(issue.Body, -1) {
+		if len(e.Links) == enrichLinkLimit {
+			break
+		}
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		number, _ := strconv.Atoi(m[1])
+		ref, err := p.gitService.GetIssueOrPR(ctx, repoPath, number)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			continue
+		}
+		relation := "issue"
+		if ref.IsPR {
+			relation = "PR"
+		}
+		e.Links = append(e.Links, linkedIssue{
+			Ref: "#" + m[1], Relation: relation, Title: ref.Title,
+			State: ref.State, URL: ref.URL, Body: ref.Body,
+		})
+	}
+
+	comments, err := p.GetIssueComments(ctx, repoPath, issue.ID)
+	if err != nil {
+		return "", err
+	}
+	e.Comments = recentHumanComments(comments)
+	return e.render(), nil
+}
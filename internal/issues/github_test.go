@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/zhubert/erg/internal/exec"
 	"github.com/zhubert/erg/internal/git"
@@ -254,7 +255,7 @@ func TestGitHubProvider_UpdateComment_InvalidCommentID(t *testing.T) {
 
 func TestGitHubProvider_GetIssue_Success(t *testing.T) {
 	mock := exec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "view", "42", "--json", "number,title,body,url"}, exec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "view", "42", "--json", "number,title,body,url,createdAt,labels,milestone"}, exec.MockResponse{
 		Stdout: []byte(`{"number":42,"title":"Fix the bug","body":"This is the body","url":"https://github.com/owner/repo/issues/42"}`),
 	})
 
@@ -276,6 +277,28 @@ func TestGitHubProvider_GetIssue_Success(t *testing.T) {
 	}
 }
 
+func TestGitHubProvider_GetIssue_LabelsAndMilestone(t *testing.T) {
+	mock := exec.NewMockExecutor(nil)
+	mock.AddExactMatch("gh", []string{"issue", "view", "7", "--json", "number,title,body,url,createdAt,labels,milestone"}, exec.MockResponse{
+		Stdout: []byte(`{"number":7,"title":"Ship it","createdAt":"2026-03-01T09:00:00Z","labels":[{"name":"queued"},{"name":"P1"}],"milestone":{"title":"v2","dueOn":"2026-04-15T00:00:00Z"}}`),
+	})
+
+	p := NewGitHubProvider(git.NewGitServiceWithExecutor(mock))
+	issue, err := p.GetIssue(context.Background(), "/repo", "7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Priority != 2 || len(issue.Labels) != 2 {
+		t.Errorf("expected labels and P1 priority, got %+v", issue)
+	}
+	if !issue.CreatedAt.Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected createdAt parsed, got %v", issue.CreatedAt)
+	}
+	if !issue.DueAt.Equal(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected milestone due date, got %v", issue.DueAt)
+	}
+}
+
 func TestGitHubProvider_GetIssue_InvalidID(t *testing.T) {
 	p := NewGitHubProvider(nil)
 
@@ -287,7 +310,7 @@ func TestGitHubProvider_GetIssue_InvalidID(t *testing.T) {
 
 func TestGitHubProvider_GetIssue_CLIError(t *testing.T) {
 	mock := exec.NewMockExecutor(nil)
-	mock.AddExactMatch("gh", []string{"issue", "view", "99", "--json", "number,title,body,url"}, exec.MockResponse{
+	mock.AddExactMatch("gh", []string{"issue", "view", "99", "--json", "number,title,body,url,createdAt,labels,milestone"}, exec.MockResponse{
 		Err: fmt.Errorf("not found"),
 	})
 
@@ -0,0 +1,26 @@
+package issues
+
+import (
+	"context"
+	"slices"
+	"strconv"
+)
+
+// CreateIssue opens a GitHub issue. The labels must already exist in the
+// repository.
+// Implements ProviderWriter.
+func (p *GitHubProvider) CreateIssue(ctx context.Context, repoPath string, title, body string, labels []string) (*Issue, error) {
+	number, url, err := p.gitService.CreateIssue(ctx, repoPath, title, body, labels)
+	if err != nil {
+		return nil, err
+	}
+	return &Issue{
+		ID:       strconv.Itoa(number),
+		Title:    title,
+		Body:     body,
+		URL:      url,
+		Source:   SourceGitHub,
+		Priority: PriorityFromLabels(labels),
+		Labels:   slices.Clone(labels),
+	}, nil
+}
@@ -0,0 +1,308 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/providerhttp"
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+const (
+	gitlabDefaultURL     = "https://gitlab.com"
+	gitlabURLEnvVar      = "GITLAB_URL"
+	gitlabTokenEnvVar    = "GITLAB_TOKEN"
+	gitlabHTTPTimeout    = 30 * time.Second
+	gitlabIssuesPageSize = 100
+)
+
+// GitLabProvider implements Provider for GitLab Issues using the GitLab REST API (v4).
+// It works with gitlab.com and self-hosted instances; set GITLAB_URL to point at
+// a self-hosted instance. Issue IDs are project-scoped IIDs (the "#N" users see).
+type GitLabProvider struct {
+	config     GitLabConfigProvider
+	httpClient *http.Client
+	apiBase    string // Override for testing; defaults to GITLAB_URL + "/api/v4"
+}
+
+// NewGitLabProvider creates a new GitLab issue provider.
+func NewGitLabProvider(cfg GitLabConfigProvider) *GitLabProvider {
+	baseURL := os.Getenv(gitlabURLEnvVar)
+	if baseURL == "" {
+		baseURL = gitlabDefaultURL
+	}
+	return &GitLabProvider{
+		config:     cfg,
+		httpClient: providerhttp.NewClient(gitlabHTTPTimeout, providerhttp.DefaultPolicy()),
+		apiBase:    strings.TrimRight(baseURL, "/") + "/api/v4",
+	}
+}
+
+// NewGitLabProviderWithClient creates a new GitLab issue provider with a custom HTTP client and API base URL (for testing).
+func NewGitLabProviderWithClient(cfg GitLabConfigProvider, client *http.Client, apiBase string) *GitLabProvider {
+	if apiBase == "" {
+		apiBase = gitlabDefaultURL + "/api/v4"
+	}
+	return &GitLabProvider{
+		config:     cfg,
+		httpClient: client,
+		apiBase:    apiBase,
+	}
+}
+
+// Name returns the human-readable name of this provider.
+func (p *GitLabProvider) Name() string {
+	return "GitLab Issues"
+}
+
+// Source returns the source type for this provider.
+func (p *GitLabProvider) Source() Source {
+	return SourceGitLab
+}
+
+// gitlabIssue represents an issue from the GitLab REST API response.
+type gitlabIssue struct {
+	IID         int       `json:"iid"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	WebURL      string    `json:"web_url"`
+	State       string    `json:"state"`
+	Labels      []string  `json:"labels"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// gitlabNote represents an issue note (comment) from the GitLab REST API response.
+type gitlabNote struct {
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	System    bool   `json:"system"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Author    struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+func (i gitlabIssue) toIssue() Issue {
+	return Issue{
+		ID:        strconv.Itoa(i.IID),
+		Title:     i.Title,
+		Body:      i.Description,
+		URL:       i.WebURL,
+		Source:    SourceGitLab,
+		Priority:  PriorityFromLabels(i.Labels),
+		CreatedAt: i.CreatedAt,
+		Labels:    i.Labels,
+	}
+}
+
+// FetchIssues retrieves open issues from the GitLab project.
+// The filter.Project should be the GitLab project ID or full path (e.g. "group/project").
+func (p *GitLabProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
+	project := filter.Project
+	if project == "" {
+		project = p.config.GetGitLabProject(repoPath)
+	}
+	if project == "" {
+		return nil, fmt.Errorf("gitlab project not configured for this repository")
+	}
+
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("per_page", strconv.Itoa(gitlabIssuesPageSize))
+	if filter.Label != "" {
+		query.Set("labels", filter.Label)
+	}
+	if filter.Assignee != "" {
+		query.Set("assignee_username", filter.Assignee)
+	}
+
+	var glIssues []gitlabIssue
+	if err := p.gitlabRequest(ctx, http.MethodGet, project, "/issues?"+query.Encode(), nil, http.StatusOK,
+		"GitLab API returned 403 Forbidden - check that your GITLAB_TOKEN has access to this project",
+		&glIssues); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, len(glIssues))
+	for i, issue := range glIssues {
+		issues[i] = issue.toIssue()
+	}
+	return issues, nil
+}
+
+// GetIssue fetches a single GitLab issue by its IID.
+// Implements IssueGetter.
+func (p *GitLabProvider) GetIssue(ctx context.Context, repoPath string, id string) (*Issue, error) {
+	var issue gitlabIssue
+	if err := p.issueRequest(ctx, repoPath, id, http.MethodGet, "", nil, http.StatusOK, &issue); err != nil {
+		return nil, err
+	}
+	result := issue.toIssue()
+	return &result, nil
+}
+
+// IsConfigured returns true if GitLab is configured for the given repo.
+// Requires both GITLAB_TOKEN (env var or macOS Keychain) and a project mapped to the repo.
+func (p *GitLabProvider) IsConfigured(repoPath string) bool {
+	if _, ok := resolveToken(gitlabTokenEnvVar, secrets.GitLabTokenService); !ok {
+		return false
+	}
+	return p.config.HasGitLabProject(repoPath)
+}
+
+// GenerateBranchName returns a branch name for the given GitLab issue.
+// Format: "gitlab-{iid}"
+func (p *GitLabProvider) GenerateBranchName(issue Issue) string {
+	return fmt.Sprintf("gitlab-%s", issue.ID)
+}
+
+// GetPRLinkText returns the text to add to the MR body to link/close the issue.
+// GitLab closes the issue when a merge request containing "Closes #N" is merged.
+func (p *GitLabProvider) GetPRLinkText(issue Issue) string {
+	return fmt.Sprintf("Closes #%s", issue.ID)
+}
+
+// gitlabRequest executes a REST request against a project-scoped GitLab API path.
+// If forbiddenMsg is non-empty, a 403 response produces that specific error.
+func (p *GitLabProvider) gitlabRequest(ctx context.Context, method, project, path string, body any, expectStatus int, forbiddenMsg string, result any) error {
+	token, ok := resolveToken(gitlabTokenEnvVar, secrets.GitLabTokenService)
+	if !ok {
+		return secrets.TokenNotFoundError(gitlabTokenEnvVar)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal GitLab request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	// Project paths ("group/project") must be URL-encoded as a single segment.
+	reqURL := fmt.Sprintf("%s/projects/%s%s", p.apiBase, url.PathEscape(project), path)
+	return apiRequest(ctx, p.httpClient, method, reqURL, reader,
+		"Bearer "+token, expectStatus, forbiddenMsg, "GitLab", result)
+}
+
+// issueRequest executes a request against a single issue of the repo's mapped project.
+func (p *GitLabProvider) issueRequest(ctx context.Context, repoPath, issueID, method, path string, body any, expectStatus int, result any) error {
+	project := p.config.GetGitLabProject(repoPath)
+	if project == "" {
+		return fmt.Errorf("gitlab project not configured for this repository")
+	}
+	if _, err := strconv.Atoi(issueID); err != nil {
+		return fmt.Errorf("invalid gitlab issue IID %q", issueID)
+	}
+	return p.gitlabRequest(ctx, method, project, "/issues/"+issueID+path, body, expectStatus, "", result)
+}
+
+// CheckIssueHasLabel returns true if the GitLab issue has a label matching the given name.
+// Implements ProviderGateChecker.
+func (p *GitLabProvider) CheckIssueHasLabel(ctx context.Context, repoPath string, issueID string, label string) (bool, error) {
+	var issue gitlabIssue
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodGet, "", nil, http.StatusOK, &issue); err != nil {
+		return false, fmt.Errorf("failed to fetch issue labels: %w", err)
+	}
+	return slices.ContainsFunc(issue.Labels, func(l string) bool {
+		return strings.EqualFold(l, label)
+	}), nil
+}
+
+// GetIssueComments returns all user comments on a GitLab issue, ordered oldest first.
+// System notes (label changes, cross-references, etc.) are excluded.
+// Implements ProviderGateChecker.
+func (p *GitLabProvider) GetIssueComments(ctx context.Context, repoPath string, issueID string) ([]IssueComment, error) {
+	var notes []gitlabNote
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodGet,
+		"/notes?sort=asc&order_by=created_at&per_page=100", nil, http.StatusOK, &notes); err != nil {
+		return nil, fmt.Errorf("failed to fetch issue comments: %w", err)
+	}
+
+	comments := make([]IssueComment, 0, len(notes))
+	for _, n := range notes {
+		if n.System || n.Body == "" {
+			continue
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, n.CreatedAt)
+		updatedAt, _ := time.Parse(time.RFC3339Nano, n.UpdatedAt)
+		comments = append(comments, IssueComment{
+			ID:        strconv.FormatInt(n.ID, 10),
+			Author:    n.Author.Username,
+			Body:      n.Body,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		})
+	}
+	return comments, nil
+}
+
+// IsIssueClosed returns true if the GitLab issue is closed.
+// Implements IssueStateChecker.
+func (p *GitLabProvider) IsIssueClosed(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	var issue gitlabIssue
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodGet, "", nil, http.StatusOK, &issue); err != nil {
+		return false, fmt.Errorf("failed to fetch issue state: %w", err)
+	}
+	return issue.State == "closed", nil
+}
+
+// RemoveLabel removes a label from a GitLab issue.
+// Implements ProviderActions.
+func (p *GitLabProvider) RemoveLabel(ctx context.Context, repoPath string, issueID string, label string) error {
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodPut, "",
+		map[string]string{"remove_labels": label}, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("failed to remove label: %w", err)
+	}
+	return nil
+}
+
+// CloseIssue closes a GitLab issue.
+// Implements ProviderActions.
+func (p *GitLabProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodPut, "",
+		map[string]string{"state_event": "close"}, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("failed to close issue: %w", err)
+	}
+	return nil
+}
+
+// Comment creates a note on a GitLab issue.
+// Implements ProviderActions.
+func (p *GitLabProvider) Comment(ctx context.Context, repoPath string, issueID string, body string) error {
+	if _, err := p.createNote(ctx, repoPath, issueID, body); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// UpdateComment replaces the body of an existing GitLab issue note.
+// Implements ProviderCommentUpdater.
+func (p *GitLabProvider) UpdateComment(ctx context.Context, repoPath string, issueID string, commentID string, body string) error {
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodPut, "/notes/"+url.PathEscape(commentID),
+		map[string]string{"body": body}, http.StatusOK, nil); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	return nil
+}
+
+// createNote posts a note on a GitLab issue and returns its ID.
+func (p *GitLabProvider) createNote(ctx context.Context, repoPath, issueID, body string) (string, error) {
+	var note gitlabNote
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodPost, "/notes",
+		map[string]string{"body": body}, http.StatusCreated, &note); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(note.ID, 10), nil
+}
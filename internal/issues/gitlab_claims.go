@@ -0,0 +1,63 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PostClaim posts a claim note on a GitLab issue and returns the note ID.
+// Implements ProviderClaimManager.
+func (p *GitLabProvider) PostClaim(ctx context.Context, repoPath string, issueID string, claim ClaimInfo) (string, error) {
+	noteID, err := p.createNote(ctx, repoPath, issueID, formatClaimBodyVisible(claim))
+	if err != nil {
+		return "", fmt.Errorf("failed to post claim comment: %w", err)
+	}
+	return noteID, nil
+}
+
+// GetClaims reads all claim notes from a GitLab issue.
+// Implements ProviderClaimManager.
+func (p *GitLabProvider) GetClaims(ctx context.Context, repoPath string, issueID string) ([]ClaimInfo, error) {
+	comments, err := p.GetIssueComments(ctx, repoPath, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getClaimsFromComments(comments), nil
+}
+
+// DeleteClaim deletes a claim note from a GitLab issue by its note ID.
+// Implements ProviderClaimManager.
+func (p *GitLabProvider) DeleteClaim(ctx context.Context, repoPath string, issueID string, commentID string) error {
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodDelete, "/notes/"+url.PathEscape(commentID),
+		nil, http.StatusNoContent, nil); err != nil {
+		return fmt.Errorf("failed to delete claim comment: %w", err)
+	}
+	return nil
+}
+
+// Claim adds ClaimLabel to a GitLab issue. GitLab creates the project label
+// on first use.
+// Implements ProviderActions.
+func (p *GitLabProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	has, err := p.CheckIssueHasLabel(ctx, repoPath, issueID, ClaimLabel)
+	if err != nil {
+		return false, err
+	}
+	if has {
+		return false, nil
+	}
+	if err := p.issueRequest(ctx, repoPath, issueID, http.MethodPut, "",
+		map[string]string{"add_labels": ClaimLabel}, http.StatusOK, nil); err != nil {
+		return false, fmt.Errorf("failed to add claim label: %w", err)
+	}
+	return true, nil
+}
+
+// Unclaim removes ClaimLabel from a GitLab issue.
+// Implements ProviderActions.
+func (p *GitLabProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	return p.RemoveLabel(ctx, repoPath, issueID, ClaimLabel)
+}
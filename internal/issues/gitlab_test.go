@@ -0,0 +1,241 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+)
+
+// Compile-time interface checks.
+var (
+	_ ProviderActions        = (*GitLabProvider)(nil)
+	_ ProviderGateChecker    = (*GitLabProvider)(nil)
+	_ ProviderCommentUpdater = (*GitLabProvider)(nil)
+	_ ProviderClaimManager   = (*GitLabProvider)(nil)
+	_ IssueGetter            = (*GitLabProvider)(nil)
+	_ IssueStateChecker      = (*GitLabProvider)(nil)
+)
+
+// newGitLabTestProvider returns a provider pointed at server with the repo
+// mapped to the "group/project" project and GITLAB_TOKEN set.
+func newGitLabTestProvider(t *testing.T, server *httptest.Server) *GitLabProvider {
+	t.Helper()
+	t.Setenv(gitlabTokenEnvVar, "glpat-test")
+	cfg := &config.Config{}
+	cfg.SetGitLabProject("/test/repo", "group/project")
+	return NewGitLabProviderWithClient(cfg, server.Client(), server.URL+"/api/v4")
+}
+
+func TestGitLabProvider_Basics(t *testing.T) {
+	p := NewGitLabProvider(nil)
+	if p.Name() != "GitLab Issues" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+	if p.Source() != SourceGitLab {
+		t.Errorf("Source() = %q", p.Source())
+	}
+	if got := p.GenerateBranchName(Issue{ID: "17"}); got != "gitlab-17" {
+		t.Errorf("GenerateBranchName = %q, want gitlab-17", got)
+	}
+	if got := p.GetPRLinkText(Issue{ID: "17"}); got != "Closes #17" {
+		t.Errorf("GetPRLinkText = %q, want 'Closes #17'", got)
+	}
+}
+
+func TestGitLabProvider_IsConfigured(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.SetGitLabProject("/test/repo", "group/project")
+	p := NewGitLabProvider(cfg)
+
+	t.Setenv(gitlabTokenEnvVar, "")
+	if p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=false without token")
+	}
+
+	t.Setenv(gitlabTokenEnvVar, "glpat-test")
+	if p.IsConfigured("/other/repo") {
+		t.Error("expected IsConfigured=false without project mapping")
+	}
+	if !p.IsConfigured("/test/repo") {
+		t.Error("expected IsConfigured=true with token and project mapping")
+	}
+}
+
+func TestGitLabProvider_NewGitLabProvider_SelfHosted(t *testing.T) {
+	t.Setenv(gitlabURLEnvVar, "https://gitlab.example.com/")
+	p := NewGitLabProvider(nil)
+	if p.apiBase != "https://gitlab.example.com/api/v4" {
+		t.Errorf("apiBase = %q", p.apiBase)
+	}
+}
+
+func TestGitLabProvider_FetchIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer glpat-test" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/issues" {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("state") != "opened" || r.URL.Query().Get("labels") != "erg" {
+			t.Errorf("query = %q", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]gitlabIssue{
+			{IID: 4, Title: "Fix login", Description: "Login fails", WebURL: "https://gitlab.com/group/project/-/issues/4",
+				Labels: []string{"erg", "priority::high"}, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		})
+	}))
+	defer server.Close()
+
+	p := newGitLabTestProvider(t, server)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Label: "erg", Project: "group/project"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(issues))
+	}
+	want := Issue{ID: "4", Title: "Fix login", Body: "Login fails", URL: "https://gitlab.com/group/project/-/issues/4", Source: SourceGitLab,
+		Priority: 2, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Labels: []string{"erg", "priority::high"}}
+	if !reflect.DeepEqual(issues[0], want) {
+		t.Errorf("issue = %+v, want %+v", issues[0], want)
+	}
+}
+
+func TestGitLabProvider_FetchIssues_Assignee(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("assignee_username"); got != "erg-bot" {
+			t.Errorf("assignee_username = %q, want erg-bot", got)
+		}
+		json.NewEncoder(w).Encode([]gitlabIssue{})
+	}))
+	defer server.Close()
+
+	p := newGitLabTestProvider(t, server)
+	if _, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Label: "erg", Project: "group/project", Assignee: "erg-bot"}); err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+}
+
+func TestGitLabProvider_FetchIssues_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	p := newGitLabTestProvider(t, server)
+
+	if _, err := p.FetchIssues(context.Background(), "/unmapped", FilterConfig{}); err == nil {
+		t.Error("expected error without project")
+	}
+	_, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{})
+	if err == nil || !strings.Contains(err.Error(), "GITLAB_TOKEN") {
+		t.Errorf("expected forbidden error mentioning GITLAB_TOKEN, got %v", err)
+	}
+
+	t.Setenv(gitlabTokenEnvVar, "")
+	if _, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{}); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestGitLabProvider_IssueOperations(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.EscapedPath(), string(body)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(gotPath, "/notes"):
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"id": 1, "body": "added ~erg label", "system": true, "created_at": "2026-01-01T10:00:00Z"},
+				{"id": 2, "body": "LGTM", "system": false, "created_at": "2026-01-01T11:00:00.123Z", "author": map[string]string{"username": "alice"}},
+			})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(gitlabIssue{IID: 9, Title: "T", State: "closed", Labels: []string{"Approved"}})
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{"id": 55})
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	p := newGitLabTestProvider(t, server)
+	ctx := context.Background()
+	const base = "/api/v4/projects/group%2Fproject/issues/9"
+
+	issue, err := p.GetIssue(ctx, "/test/repo", "9")
+	if err != nil || issue.ID != "9" || issue.Source != SourceGitLab {
+		t.Errorf("GetIssue = %+v, %v", issue, err)
+	}
+
+	if has, err := p.CheckIssueHasLabel(ctx, "/test/repo", "9", "approved"); err != nil || !has {
+		t.Errorf("CheckIssueHasLabel = %v, %v; want true", has, err)
+	}
+
+	if closed, err := p.IsIssueClosed(ctx, "/test/repo", "9"); err != nil || !closed {
+		t.Errorf("IsIssueClosed = %v, %v; want true", closed, err)
+	}
+
+	comments, err := p.GetIssueComments(ctx, "/test/repo", "9")
+	if err != nil {
+		t.Fatalf("GetIssueComments: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != "2" || comments[0].Author != "alice" || comments[0].CreatedAt.IsZero() {
+		t.Errorf("expected only the user note, got %+v", comments)
+	}
+
+	if err := p.Comment(ctx, "/test/repo", "9", "hello"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotPath != base+"/notes" || !strings.Contains(gotBody, `"hello"`) {
+		t.Errorf("Comment sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	if err := p.UpdateComment(ctx, "/test/repo", "9", "2", "edited"); err != nil {
+		t.Fatalf("UpdateComment: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotPath != base+"/notes/2" || !strings.Contains(gotBody, `"edited"`) {
+		t.Errorf("UpdateComment sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	if err := p.RemoveLabel(ctx, "/test/repo", "9", "erg"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotPath != base || !strings.Contains(gotBody, `"remove_labels":"erg"`) {
+		t.Errorf("RemoveLabel sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	if err := p.CloseIssue(ctx, "/test/repo", "9"); err != nil {
+		t.Fatalf("CloseIssue: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotPath != base || !strings.Contains(gotBody, `"state_event":"close"`) {
+		t.Errorf("CloseIssue sent %s %s %s", gotMethod, gotPath, gotBody)
+	}
+
+	id, err := p.PostClaim(ctx, "/test/repo", "9", ClaimInfo{DaemonID: "d1"})
+	if err != nil || id != "55" {
+		t.Errorf("PostClaim = %q, %v; want 55", id, err)
+	}
+
+	if err := p.DeleteClaim(ctx, "/test/repo", "9", "55"); err != nil {
+		t.Fatalf("DeleteClaim: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotPath != base+"/notes/55" {
+		t.Errorf("DeleteClaim sent %s %s", gotMethod, gotPath)
+	}
+
+	if _, err := p.GetIssue(ctx, "/test/repo", "not-a-number"); err == nil {
+		t.Error("expected error for non-numeric IID")
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/zhubert/erg/internal/providerhttp"
 	"github.com/zhubert/erg/internal/secrets"
 )
 
@@ -16,8 +18,21 @@ const (
 	linearAPIBase      = "https://api.linear.app"
 	linearAPIKeyEnvVar = "LINEAR_API_KEY"
 	linearHTTPTimeout  = 30 * time.Second
+
+	// linearPageSize is the number of issues requested per GraphQL page.
+	linearPageSize = 100
+	// linearDefaultMaxIssues caps issues fetched per poll when the filter sets no limit.
+	linearDefaultMaxIssues = 1000
 )
 
+// linearHTTPPolicy retries transient failures and paces requests under
+// Linear's 1,500 requests per hour limit for API keys.
+func linearHTTPPolicy() providerhttp.Policy {
+	policy := providerhttp.DefaultPolicy()
+	policy.Limiter = providerhttp.NewRateLimiter(1500, time.Hour, 100)
+	return policy
+}
+
 // LinearTeam represents a Linear team with its ID and name.
 type LinearTeam struct {
 	ID   string
@@ -34,16 +49,9 @@ type LinearProvider struct {
 // NewLinearProvider creates a new Linear issue provider.
 func NewLinearProvider(cfg LinearConfigProvider) *LinearProvider {
 	return &LinearProvider{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: linearHTTPTimeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
-		},
-		apiBase: linearAPIBase,
+		config:     cfg,
+		httpClient: providerhttp.NewClient(linearHTTPTimeout, linearHTTPPolicy()),
+		apiBase:    linearAPIBase,
 	}
 }
 
@@ -77,11 +85,44 @@ type linearGraphQLRequest struct {
 
 // linearIssue represents an issue from the Linear GraphQL API response.
 type linearIssue struct {
-	ID          string `json:"id"`
-	Identifier  string `json:"identifier"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	URL         string `json:"url"`
+	ID          string    `json:"id"`
+	Identifier  string    `json:"identifier"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	Priority    int       `json:"priority"` // 0 = none, 1 = urgent … 4 = low
+	CreatedAt   time.Time `json:"createdAt"`
+	DueDate     string    `json:"dueDate"` // YYYY-MM-DD, or empty
+	Labels      struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
+
+func (i linearIssue) toIssue() Issue {
+	issue := Issue{
+		ID:        i.Identifier,
+		Title:     i.Title,
+		Body:      i.Description,
+		URL:       i.URL,
+		Source:    SourceLinear,
+		Priority:  i.Priority,
+		CreatedAt: i.CreatedAt,
+	}
+	for _, l := range i.Labels.Nodes {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	if due, err := time.Parse(time.DateOnly, i.DueDate); err == nil {
+		issue.DueAt = due
+	}
+	return issue
+}
+
+// linearPageInfo is the cursor pagination info of a GraphQL connection.
+type linearPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
 }
 
 // linearTeamIssuesResponse represents the Linear GraphQL response for team issues.
@@ -89,7 +130,8 @@ type linearTeamIssuesResponse struct {
 	Data struct {
 		Team struct {
 			Issues struct {
-				Nodes []linearIssue `json:"nodes"`
+				Nodes    []linearIssue  `json:"nodes"`
+				PageInfo linearPageInfo `json:"pageInfo"`
 			} `json:"issues"`
 		} `json:"team"`
 	} `json:"data"`
@@ -110,7 +152,9 @@ type linearTeamsResponse struct {
 	} `json:"data"`
 }
 
-// FetchIssues retrieves active issues from the Linear team.
+// FetchIssues retrieves active issues from the Linear team, following the
+// issues connection's cursor until the last page or until filter.MaxItems
+// issues have been read (linearDefaultMaxIssues when unset).
 // The filter.Team should be the Linear team ID.
 func (p *LinearProvider) FetchIssues(ctx context.Context, repoPath string, filter FilterConfig) ([]Issue, error) {
 	projectID := filter.Team
@@ -118,17 +162,31 @@ func (p *LinearProvider) FetchIssues(ctx context.Context, repoPath string, filte
 		return nil, fmt.Errorf("linear team ID not configured for this repository")
 	}
 
-	var query string
+	maxIssues := filter.MaxItems
+	if maxIssues <= 0 {
+		maxIssues = linearDefaultMaxIssues
+	}
+
 	variables := map[string]any{
 		"teamId": projectID,
+		"first":  linearPageSize,
 	}
-
+	params := []string{"$teamId: String!", "$first: Int!", "$after: String"}
+	filters := []string{`state: { type: { nin: ["completed", "canceled"] } }`}
 	if filter.Label != "" {
-		query = `query($teamId: String!, $label: String!) {
+		params = append(params, "$label: String!")
+		filters = append(filters, `labels: { name: { eqIgnoreCase: $label } }`)
+		variables["label"] = filter.Label
+	}
+	if filter.Assignee != "" {
+		params = append(params, "$assignee: String!")
+		filters = append(filters, `assignee: { or: [{ displayName: { eqIgnoreCase: $assignee } }, { email: { eqIgnoreCase: $assignee } }] }`)
+		variables["assignee"] = filter.Assignee
+	}
+	query := fmt.Sprintf(`query(%s) {
   team(id: $teamId) {
-    issues(filter: {
-      state: { type: { nin: ["completed", "canceled"] } }
-      labels: { name: { eqIgnoreCase: $label } }
+    issues(first: $first, after: $after, filter: {
+      %s
     }) {
       nodes {
         id
@@ -136,47 +194,45 @@ func (p *LinearProvider) FetchIssues(ctx context.Context, repoPath string, filte
         title
         description
         url
+        priority
+        createdAt
+        dueDate
+        labels {
+          nodes {
+            name
+          }
+        }
       }
-    }
-  }
-}`
-		variables["label"] = filter.Label
-	} else {
-		query = `query($teamId: String!) {
-  team(id: $teamId) {
-    issues(filter: { state: { type: { nin: ["completed", "canceled"] } } }) {
-      nodes {
-        id
-        identifier
-        title
-        description
-        url
+      pageInfo {
+        hasNextPage
+        endCursor
       }
     }
   }
-}`
-	}
+}`, strings.Join(params, ", "), strings.Join(filters, "\n      "))
+
+	var issues []Issue
+	for {
+		var gqlResp linearTeamIssuesResponse
+		if err := p.linearGraphQL(ctx, query, variables,
+			"Linear API returned 403 Forbidden - check that your LINEAR_API_KEY has access to this team",
+			&gqlResp); err != nil {
+			return nil, err
+		}
 
-	var gqlResp linearTeamIssuesResponse
-	if err := p.linearGraphQL(ctx, query, variables,
-		"Linear API returned 403 Forbidden - check that your LINEAR_API_KEY has access to this team",
-		&gqlResp); err != nil {
-		return nil, err
-	}
+		conn := gqlResp.Data.Team.Issues
+		for _, issue := range conn.Nodes {
+			issues = append(issues, issue.toIssue())
+			if len(issues) >= maxIssues {
+				return issues, nil
+			}
+		}
 
-	nodes := gqlResp.Data.Team.Issues.Nodes
-	issues := make([]Issue, len(nodes))
-	for i, issue := range nodes {
-		issues[i] = Issue{
-			ID:     issue.Identifier,
-			Title:  issue.Title,
-			Body:   issue.Description,
-			URL:    issue.URL,
-			Source: SourceLinear,
+		if !conn.PageInfo.HasNextPage || conn.PageInfo.EndCursor == "" {
+			return issues, nil
 		}
+		variables["after"] = conn.PageInfo.EndCursor
 	}
-
-	return issues, nil
 }
 
 // linearSingleIssueResponse is the GraphQL response for a single issue lookup.
@@ -196,6 +252,14 @@ func (p *LinearProvider) GetIssue(ctx context.Context, repoPath string, id strin
     title
     description
     url
+    priority
+    createdAt
+    dueDate
+    labels {
+      nodes {
+        name
+      }
+    }
   }
 }`
 	var resp linearSingleIssueResponse
@@ -210,13 +274,8 @@ func (p *LinearProvider) GetIssue(ctx context.Context, repoPath string, id strin
 		return nil, fmt.Errorf("linear issue %q not found", id)
 	}
 
-	return &Issue{
-		ID:     issue.Identifier,
-		Title:  issue.Title,
-		Body:   issue.Description,
-		URL:    issue.URL,
-		Source: SourceLinear,
-	}, nil
+	result := issue.toIssue()
+	return &result, nil
 }
 
 // IsConfigured returns true if Linear is configured for the given repo.
@@ -263,6 +322,7 @@ const linearIssueLabelsQuery = `query($id: String!) {
       nodes {
         id
         name
+        type
       }
     }
   }
@@ -306,6 +366,11 @@ func (p *LinearProvider) linearGraphQL(ctx context.Context, query string, variab
 		return fmt.Errorf("failed to marshal GraphQL request: %w", err)
 	}
 
+	// Queries only read, so they are safe to retry after a server error;
+	// mutations are not.
+	if !strings.HasPrefix(strings.TrimSpace(query), "mutation") {
+		ctx = providerhttp.Idempotent(ctx)
+	}
 	url := fmt.Sprintf("%s/graphql", p.apiBase)
 	return apiRequest(ctx, p.httpClient, http.MethodPost, url, bytes.NewReader(body),
 		apiKey, http.StatusOK, forbiddenMsg, "Linear", result)
@@ -546,6 +611,7 @@ const linearTeamStatesQuery = `query($teamId: String!) {
 type linearWorkflowState struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	Type string `json:"type"` // "backlog", "unstarted", "started", "completed", or "canceled"
 }
 
 // linearTeamStatesResponse is the GraphQL response for a team's workflow states.
@@ -583,29 +649,51 @@ func (p *LinearProvider) IsInSection(ctx context.Context, repoPath string, issue
 // then updates the issue. The state name is matched case-insensitively.
 // Implements ProviderSectionMover.
 func (p *LinearProvider) MoveToSection(ctx context.Context, repoPath string, issueID string, section string) error {
+	states, err := p.teamStates(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+
+	// Find the target state by name (case-insensitive).
+	i := slices.IndexFunc(states, func(s linearWorkflowState) bool { return strings.EqualFold(s.Name, section) })
+	if i < 0 {
+		return fmt.Errorf("workflow state %q not found for team", section)
+	}
+	return p.setIssueState(ctx, issueID, states[i].ID)
+}
+
+// CloseIssue moves a Linear issue to the team's first completed workflow
+// state (usually "Done").
+// Implements ProviderActions.
+func (p *LinearProvider) CloseIssue(ctx context.Context, repoPath string, issueID string) error {
+	states, err := p.teamStates(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(states, func(s linearWorkflowState) bool { return s.Type == "completed" })
+	if i < 0 {
+		return fmt.Errorf("no completed workflow state found for team")
+	}
+	return p.setIssueState(ctx, issueID, states[i].ID)
+}
+
+// teamStates fetches the workflow states of the team mapped to repoPath.
+func (p *LinearProvider) teamStates(ctx context.Context, repoPath string) ([]linearWorkflowState, error) {
 	teamID := p.config.GetLinearTeam(repoPath)
 	if teamID == "" {
-		return fmt.Errorf("linear team ID not configured for this repository")
+		return nil, fmt.Errorf("linear team ID not configured for this repository")
 	}
 
-	// Fetch the team's workflow states.
 	var statesResp linearTeamStatesResponse
 	if err := p.linearGraphQL(ctx, linearTeamStatesQuery, map[string]any{"teamId": teamID}, "", &statesResp); err != nil {
-		return fmt.Errorf("failed to fetch team workflow states: %w", err)
-	}
-
-	// Find the target state by name (case-insensitive).
-	var targetStateID string
-	for _, s := range statesResp.Data.Team.States.Nodes {
-		if strings.EqualFold(s.Name, section) {
-			targetStateID = s.ID
-			break
-		}
-	}
-	if targetStateID == "" {
-		return fmt.Errorf("workflow state %q not found for team", section)
+		return nil, fmt.Errorf("failed to fetch team workflow states: %w", err)
 	}
+	return statesResp.Data.Team.States.Nodes, nil
+}
 
+// setIssueState moves the issue with the given identifier to a workflow state.
+func (p *LinearProvider) setIssueState(ctx context.Context, issueID, stateID string) error {
 	// Look up the issue UUID.
 	var issueResp struct {
 		Data struct {
@@ -633,7 +721,7 @@ func (p *LinearProvider) MoveToSection(ctx context.Context, repoPath string, iss
 	}
 	if err := p.linearGraphQL(ctx, linearIssueUpdateStateMutation, map[string]any{
 		"id":      issueUUID,
-		"stateId": targetStateID,
+		"stateId": stateID,
 	}, "", &updateResp); err != nil {
 		return fmt.Errorf("failed to update issue state: %w", err)
 	}
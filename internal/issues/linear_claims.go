@@ -3,6 +3,7 @@ package issues
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 // linearCommentCreateWithIDMutation creates a comment and returns its ID.
@@ -22,6 +23,27 @@ const linearCommentDeleteMutation = `mutation($id: String!) {
   }
 }`
 
+// linearTeamLabelQuery looks up a team's issue label by name.
+const linearTeamLabelQuery = `query($teamId: String!, $name: String!) {
+  team(id: $teamId) {
+    labels(filter: { name: { eqIgnoreCase: $name } }) {
+      nodes {
+        id
+      }
+    }
+  }
+}`
+
+// linearLabelCreateMutation creates a team issue label and returns its ID.
+const linearLabelCreateMutation = `mutation($teamId: String!, $name: String!, $color: String!, $description: String!) {
+  issueLabelCreate(input: { teamId: $teamId, name: $name, color: $color, description: $description }) {
+    success
+    issueLabel {
+      id
+    }
+  }
+}`
+
 // PostClaim posts a claim comment on a Linear issue and returns the comment ID.
 // Implements ProviderClaimManager.
 func (p *LinearProvider) PostClaim(ctx context.Context, repoPath string, issueID string, claim ClaimInfo) (string, error) {
@@ -98,3 +120,108 @@ func (p *LinearProvider) DeleteClaim(ctx context.Context, repoPath string, issue
 	}
 	return nil
 }
+
+// Claim adds ClaimLabel to a Linear issue, creating the team label the first
+// time it is needed.
+// Implements ProviderActions.
+func (p *LinearProvider) Claim(ctx context.Context, repoPath string, issueID string) (bool, error) {
+	var labelsResp linearIssueLabelsResponse
+	if err := p.linearGraphQL(ctx, linearIssueLabelsQuery, map[string]any{"id": issueID}, "", &labelsResp); err != nil {
+		return false, fmt.Errorf("failed to fetch issue labels: %w", err)
+	}
+	issueUUID := labelsResp.Data.Issue.ID
+	if issueUUID == "" {
+		return false, fmt.Errorf("issue %q not found in Linear", issueID)
+	}
+
+	labelIDs := []string{}
+	for _, l := range labelsResp.Data.Issue.Labels.Nodes {
+		if strings.EqualFold(l.Name, ClaimLabel) {
+			return false, nil
+		}
+		labelIDs = append(labelIDs, l.ID)
+	}
+
+	claimLabelID, err := p.teamLabelID(ctx, repoPath, ClaimLabel, "#"+claimLabelColor, claimLabelDescription)
+	if err != nil {
+		return false, err
+	}
+
+	var updateResp struct {
+		Data struct {
+			IssueUpdate struct {
+				Success bool `json:"success"`
+			} `json:"issueUpdate"`
+		} `json:"data"`
+	}
+	if err := p.linearGraphQL(ctx, linearIssueUpdateMutation, map[string]any{
+		"id":       issueUUID,
+		"labelIds": append(labelIDs, claimLabelID),
+	}, "", &updateResp); err != nil {
+		return false, fmt.Errorf("failed to add claim label: %w", err)
+	}
+	if !updateResp.Data.IssueUpdate.Success {
+		return false, fmt.Errorf("linear API returned success=false for claim label on issue %q", issueID)
+	}
+	return true, nil
+}
+
+// Unclaim removes ClaimLabel from a Linear issue.
+// Implements ProviderActions.
+func (p *LinearProvider) Unclaim(ctx context.Context, repoPath string, issueID string) error {
+	return p.RemoveLabel(ctx, repoPath, issueID, ClaimLabel)
+}
+
+// teamLabelID returns the ID of the team label with the given name (matched
+// case-insensitively), creating the label with color and description if the
+// team doesn't have one yet.
+func (p *LinearProvider) teamLabelID(ctx context.Context, repoPath, name, color, description string) (string, error) {
+	teamID := p.config.GetLinearTeam(repoPath)
+	if teamID == "" {
+		return "", fmt.Errorf("linear team ID not configured for this repository")
+	}
+
+	var lookupResp struct {
+		Data struct {
+			Team struct {
+				Labels struct {
+					Nodes []struct {
+						ID string `json:"id"`
+					} `json:"nodes"`
+				} `json:"labels"`
+			} `json:"team"`
+		} `json:"data"`
+	}
+	if err := p.linearGraphQL(ctx, linearTeamLabelQuery, map[string]any{
+		"teamId": teamID,
+		"name":   name,
+	}, "", &lookupResp); err != nil {
+		return "", fmt.Errorf("failed to look up label %q: %w", name, err)
+	}
+	if nodes := lookupResp.Data.Team.Labels.Nodes; len(nodes) > 0 {
+		return nodes[0].ID, nil
+	}
+
+	var createResp struct {
+		Data struct {
+			IssueLabelCreate struct {
+				Success    bool `json:"success"`
+				IssueLabel struct {
+					ID string `json:"id"`
+				} `json:"issueLabel"`
+			} `json:"issueLabelCreate"`
+		} `json:"data"`
+	}
+	if err := p.linearGraphQL(ctx, linearLabelCreateMutation, map[string]any{
+		"teamId":      teamID,
+		"name":        name,
+		"color":       color,
+		"description": description,
+	}, "", &createResp); err != nil {
+		return "", fmt.Errorf("failed to create label %q: %w", name, err)
+	}
+	if !createResp.Data.IssueLabelCreate.Success || createResp.Data.IssueLabelCreate.IssueLabel.ID == "" {
+		return "", fmt.Errorf("linear API returned success=false creating label %q", name)
+	}
+	return createResp.Data.IssueLabelCreate.IssueLabel.ID, nil
+}
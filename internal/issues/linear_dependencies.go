@@ -0,0 +1,70 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+)
+
+// linearBlockersQuery fetches the issues with a "blocks" relation to a
+// Linear issue.
+const linearBlockersQuery = `query($id: String!) {
+  issue(id: $id) {
+    inverseRelations {
+      nodes {
+        type
+        issue {
+          identifier
+          title
+          state {
+            type
+          }
+        }
+      }
+    }
+  }
+}`
+
+// linearBlockersResponse is the GraphQL response for linearBlockersQuery.
+type linearBlockersResponse struct {
+	Data struct {
+		Issue *struct {
+			InverseRelations struct {
+				Nodes []struct {
+					Type  string `json:"type"`
+					Issue struct {
+						Identifier string `json:"identifier"`
+						Title      string `json:"title"`
+						State      struct {
+							Type string `json:"type"`
+						} `json:"state"`
+					} `json:"issue"`
+				} `json:"nodes"`
+			} `json:"inverseRelations"`
+		} `json:"issue"`
+	} `json:"data"`
+}
+
+// FetchBlockers returns the issues with a "blocks" relation to the Linear
+// issue. Implements ProviderBlockerFetcher.
+func (p *LinearProvider) FetchBlockers(ctx context.Context, repoPath string, issueID string) ([]Blocker, error) {
+	var resp linearBlockersResponse
+	if err := p.linearGraphQL(ctx, linearBlockersQuery, map[string]any{"id": issueID}, "", &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch issue relations: %w", err)
+	}
+	if resp.Data.Issue == nil {
+		return nil, fmt.Errorf("linear issue %q not found", issueID)
+	}
+	var blockers []Blocker
+	for _, rel := range resp.Data.Issue.InverseRelations.Nodes {
+		if rel.Type != "blocks" {
+			continue
+		}
+		state := rel.Issue.State.Type
+		blockers = append(blockers, Blocker{
+			ID:     rel.Issue.Identifier,
+			Title:  rel.Issue.Title,
+			Closed: state == "completed" || state == "canceled",
+		})
+	}
+	return blockers, nil
+}
@@ -0,0 +1,119 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+)
+
+// linearRelationsQuery fetches the issues a Linear issue is related to, in
+// both directions.
+const linearRelationsQuery = `query($id: String!) {
+  issue(id: $id) {
+    relations {
+      nodes {
+        type
+        relatedIssue {
+          identifier
+          title
+          description
+          url
+          state {
+            name
+          }
+        }
+      }
+    }
+    inverseRelations {
+      nodes {
+        type
+        issue {
+          identifier
+          title
+          description
+          url
+          state {
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+// linearRelatedIssue is the far side of a Linear issue relation.
+type linearRelatedIssue struct {
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	State       struct {
+		Name string `json:"name"`
+	} `json:"state"`
+}
+
+// linearRelationsResponse is the GraphQL response for linearRelationsQuery.
+type linearRelationsResponse struct {
+	Data struct {
+		Issue *struct {
+			Relations struct {
+				Nodes []struct {
+					Type         string             `json:"type"`
+					RelatedIssue linearRelatedIssue `json:"relatedIssue"`
+				} `json:"nodes"`
+			} `json:"relations"`
+			InverseRelations struct {
+				Nodes []struct {
+					Type  string             `json:"type"`
+					Issue linearRelatedIssue `json:"issue"`
+				} `json:"nodes"`
+			} `json:"inverseRelations"`
+		} `json:"issue"`
+	} `json:"data"`
+}
+
+// linearInverseRelation names a relation seen from its target, so "ENG-1
+// blocks ENG-2" reads as "blocked by" on ENG-2.
+var linearInverseRelation = map[string]string{
+	"blocks":    "blocked by",
+	"duplicate": "duplicated by",
+}
+
+// EnrichIssue describes the Linear issue's related, blocking and duplicate
+// issues, followed by its recent comments.
+// Implements ProviderEnricher.
+func (p *LinearProvider) EnrichIssue(ctx context.Context, repoPath string, issue Issue) (string, error) {
+	var resp linearRelationsResponse
+	if err := p.linearGraphQL(ctx, linearRelationsQuery, map[string]any{"id": issue.ID}, "", &resp); err != nil {
+		return "", fmt.Errorf("failed to fetch issue relations: %w", err)
+	}
+	if resp.Data.Issue == nil {
+		return "", fmt.Errorf("linear issue %q not found", issue.ID)
+	}
+
+	var e enrichment
+	link := func(relation string, related linearRelatedIssue) {
+		if len(e.Links) < enrichLinkLimit {
+			e.Links = append(e.Links, linkedIssue{
+				Ref: related.Identifier, Relation: relation, Title: related.Title,
+				State: related.State.Name, URL: related.URL, Body: related.Description,
+			})
+		}
+	}
+	for _, rel := range resp.Data.Issue.Relations.Nodes {
+		link(rel.Type, rel.RelatedIssue)
+	}
+	for _, rel := range resp.Data.Issue.InverseRelations.Nodes {
+		relation, ok := linearInverseRelation[rel.Type]
+		if !ok {
+			relation = rel.Type
+		}
+		link(relation, rel.Issue)
+	}
+
+	comments, err := p.GetIssueComments(ctx, repoPath, issue.ID)
+	if err != nil {
+		return "", err
+	}
+	e.Comments = recentHumanComments(comments)
+	return e.render(), nil
+}
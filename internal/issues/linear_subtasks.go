@@ -0,0 +1,62 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+)
+
+// linearChildrenQuery fetches an issue's open sub-issues.
+const linearChildrenQuery = `query($id: String!) {
+  issue(id: $id) {
+    children(filter: { state: { type: { nin: ["completed", "canceled"] } } }) {
+      nodes {
+        id
+        identifier
+        title
+        description
+        url
+        priority
+        createdAt
+        dueDate
+        labels {
+          nodes {
+            name
+          }
+        }
+      }
+    }
+  }
+}`
+
+// linearChildrenResponse is the GraphQL response for linearChildrenQuery.
+type linearChildrenResponse struct {
+	Data struct {
+		Issue *struct {
+			Children struct {
+				Nodes []linearIssue `json:"nodes"`
+			} `json:"children"`
+		} `json:"issue"`
+	} `json:"data"`
+}
+
+// FetchSubtasks returns the open sub-issues of a Linear issue.
+// Implements ProviderSubtaskFetcher.
+func (p *LinearProvider) FetchSubtasks(ctx context.Context, repoPath string, parentID string) ([]Issue, error) {
+	var resp linearChildrenResponse
+	if err := p.linearGraphQL(ctx, linearChildrenQuery, map[string]any{"id": parentID},
+		"Linear API returned 403 Forbidden - check that your LINEAR_API_KEY is valid",
+		&resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch sub-issues: %w", err)
+	}
+	if resp.Data.Issue == nil {
+		return nil, fmt.Errorf("linear issue %q not found", parentID)
+	}
+
+	subtasks := []Issue{}
+	for _, child := range resp.Data.Issue.Children.Nodes {
+		issue := child.toIssue()
+		issue.Parent = parentID
+		subtasks = append(subtasks, issue)
+	}
+	return subtasks, nil
+}
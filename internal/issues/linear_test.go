@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zhubert/erg/internal/config"
 )
@@ -211,6 +213,112 @@ func TestLinearProvider_FetchIssues_MockServer(t *testing.T) {
 	}
 }
 
+func TestLinearProvider_FetchIssues_CreatedAtAndLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"team": {"issues": {"nodes": [{
+			"identifier": "ENG-1", "title": "Fix login", "priority": 2, "createdAt": "2026-03-01T09:00:00.000Z", "dueDate": "2026-04-15",
+			"labels": {"nodes": [{"name": "queued"}, {"name": "bug"}]}
+		}], "pageInfo": {"hasNextPage": false}}}}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv(linearAPIKeyEnvVar, "lin_api_test123")
+	p := NewLinearProviderWithClient(&config.Config{}, server.Client(), server.URL)
+
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Team: "team-123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(issues))
+	}
+	if !issues[0].CreatedAt.Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected createdAt parsed, got %v", issues[0].CreatedAt)
+	}
+	if !slices.Equal(issues[0].Labels, []string{"queued", "bug"}) {
+		t.Errorf("expected labels [queued bug], got %v", issues[0].Labels)
+	}
+	if !issues[0].DueAt.Equal(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected dueDate parsed, got %v", issues[0].DueAt)
+	}
+}
+
+func TestLinearProvider_FetchIssues_Pagination(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		var gqlReq linearGraphQLRequest
+		json.Unmarshal(body, &gqlReq)
+		if gqlReq.Variables["first"] != float64(linearPageSize) {
+			t.Errorf("expected first=%d, got %v", linearPageSize, gqlReq.Variables["first"])
+		}
+
+		response := linearTeamIssuesResponse{}
+		switch gqlReq.Variables["after"] {
+		case nil:
+			response.Data.Team.Issues.Nodes = []linearIssue{
+				{ID: "uuid-1", Identifier: "ENG-1", Title: "First", Priority: 3},
+			}
+			response.Data.Team.Issues.PageInfo = linearPageInfo{HasNextPage: true, EndCursor: "cursor-1"}
+		case "cursor-1":
+			response.Data.Team.Issues.Nodes = []linearIssue{
+				{ID: "uuid-2", Identifier: "ENG-2", Title: "Second", Priority: 1},
+			}
+		default:
+			t.Errorf("unexpected cursor %v", gqlReq.Variables["after"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	t.Setenv(linearAPIKeyEnvVar, "lin_api_test123")
+
+	p := NewLinearProviderWithClient(&config.Config{}, server.Client(), server.URL)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Team: "team-123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 || requests != 2 {
+		t.Fatalf("expected 2 issues over 2 requests, got %d issues over %d requests", len(issues), requests)
+	}
+	if issues[1].ID != "ENG-2" || issues[1].Priority != 1 {
+		t.Errorf("expected ENG-2 with priority 1, got %s with priority %d", issues[1].ID, issues[1].Priority)
+	}
+}
+
+func TestLinearProvider_FetchIssues_MaxItems(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		response := linearTeamIssuesResponse{}
+		response.Data.Team.Issues.Nodes = []linearIssue{
+			{ID: "uuid-1", Identifier: "ENG-1"},
+			{ID: "uuid-2", Identifier: "ENG-2"},
+		}
+		response.Data.Team.Issues.PageInfo = linearPageInfo{HasNextPage: true, EndCursor: "more"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	t.Setenv(linearAPIKeyEnvVar, "lin_api_test123")
+
+	p := NewLinearProviderWithClient(&config.Config{}, server.Client(), server.URL)
+	issues, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Team: "team-123", MaxItems: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected fetch capped at 3 issues, got %d", len(issues))
+	}
+	if requests != 2 {
+		t.Errorf("expected paging to stop once the cap was reached, got %d requests", requests)
+	}
+}
+
 func TestLinearProvider_FetchIssues_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -347,7 +455,7 @@ func TestLinearProvider_FetchIssues_LabelFilter(t *testing.T) {
 		bodyStr := string(body)
 
 		// Verify the GraphQL query contains a labels filter
-		if !strings.Contains(bodyStr, "labels") {
+		if !strings.Contains(bodyStr, "labels: {") {
 			t.Error("expected GraphQL query to contain 'labels' filter when Label is set")
 		}
 
@@ -396,7 +504,7 @@ func TestLinearProvider_FetchIssues_NoLabelOmitsFilter(t *testing.T) {
 		bodyStr := string(body)
 
 		// Verify the GraphQL query does NOT contain a labels filter
-		if strings.Contains(bodyStr, "labels") {
+		if strings.Contains(bodyStr, "labels: {") {
 			t.Error("expected GraphQL query to NOT contain 'labels' filter when Label is empty")
 		}
 
@@ -440,6 +548,31 @@ func TestLinearProvider_FetchIssues_NoLabelOmitsFilter(t *testing.T) {
 	}
 }
 
+func TestLinearProvider_FetchIssues_AssigneeFilter(t *testing.T) {
+	var gqlReq linearGraphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gqlReq)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(linearTeamIssuesResponse{})
+	}))
+	defer server.Close()
+
+	t.Setenv(linearAPIKeyEnvVar, "lin_api_test123")
+	p := NewLinearProviderWithClient(&config.Config{}, server.Client(), server.URL)
+
+	if _, err := p.FetchIssues(context.Background(), "/test/repo", FilterConfig{Team: "team-123", Label: "queued", Assignee: "erg-bot"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gqlReq.Variables["assignee"] != "erg-bot" {
+		t.Errorf("expected assignee variable 'erg-bot', got %v", gqlReq.Variables["assignee"])
+	}
+	for _, want := range []string{"$assignee: String!", "assignee: {", "labels: {"} {
+		if !strings.Contains(gqlReq.Query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, gqlReq.Query)
+		}
+	}
+}
+
 func TestLinearProvider_RemoveLabel(t *testing.T) {
 	requestCount := 0
 	var updateBody string
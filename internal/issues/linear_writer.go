@@ -0,0 +1,74 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+)
+
+// linearDefaultLabelColor is the color given to labels CreateIssue has to
+// create (Linear's default gray).
+const linearDefaultLabelColor = "#bec2c8"
+
+// linearIssueCreateMutation creates an issue and returns it.
+const linearIssueCreateMutation = `mutation($teamId: String!, $title: String!, $description: String!, $labelIds: [String!]!) {
+  issueCreate(input: { teamId: $teamId, title: $title, description: $description, labelIds: $labelIds }) {
+    success
+    issue {
+      id
+      identifier
+      title
+      description
+      url
+      priority
+      createdAt
+      dueDate
+      labels {
+        nodes {
+          name
+        }
+      }
+    }
+  }
+}`
+
+// CreateIssue creates an issue in the Linear team mapped to the repository,
+// creating any team labels that don't exist yet.
+// Implements ProviderWriter.
+func (p *LinearProvider) CreateIssue(ctx context.Context, repoPath string, title, body string, labels []string) (*Issue, error) {
+	teamID := p.config.GetLinearTeam(repoPath)
+	if teamID == "" {
+		return nil, fmt.Errorf("linear team ID not configured for this repository")
+	}
+
+	labelIDs := []string{}
+	for _, label := range labels {
+		id, err := p.teamLabelID(ctx, repoPath, label, linearDefaultLabelColor, "")
+		if err != nil {
+			return nil, err
+		}
+		labelIDs = append(labelIDs, id)
+	}
+
+	var resp struct {
+		Data struct {
+			IssueCreate struct {
+				Success bool        `json:"success"`
+				Issue   linearIssue `json:"issue"`
+			} `json:"issueCreate"`
+		} `json:"data"`
+	}
+	if err := p.linearGraphQL(ctx, linearIssueCreateMutation, map[string]any{
+		"teamId":      teamID,
+		"title":       title,
+		"description": body,
+		"labelIds":    labelIDs,
+	}, "Linear API returned 403 Forbidden - check that your LINEAR_API_KEY can create issues", &resp); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	if !resp.Data.IssueCreate.Success || resp.Data.IssueCreate.Issue.Identifier == "" {
+		return nil, fmt.Errorf("linear API returned success=false creating issue %q", title)
+	}
+
+	issue := resp.Data.IssueCreate.Issue.toIssue()
+	return &issue, nil
+}
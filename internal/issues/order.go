@@ -0,0 +1,140 @@
+package issues
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+// Queue ordering policies for Ordering.Policy.
+const (
+	OrderPriority      = "priority"
+	OrderOldestFirst   = "oldest-first"
+	OrderNewestFirst   = "newest-first"
+	OrderLabelWeighted = "label-weighted"
+)
+
+// Ordering decides which issues are picked up first.
+type Ordering struct {
+	Policy       string         // One of the Order* policies; empty means OrderPriority
+	LabelWeights map[string]int // OrderLabelWeighted: weight per label, matched case-insensitively
+
+	// DeadlineWindow escalates issues due within this long (or overdue)
+	// ahead of the policy, earliest due first. Zero disables escalation.
+	DeadlineWindow time.Duration
+}
+
+// Compare returns a negative number when a should be picked up before b.
+// Issues nearing their due date come first; the rest follow the policy,
+// with ties falling back to priority, then to age (oldest first). Issues
+// without a creation time sort after those with one.
+func (o Ordering) Compare(a, b Issue) int {
+	dueA, dueB := o.nearingDue(a), o.nearingDue(b)
+	switch {
+	case dueA && dueB:
+		if c := a.DueAt.Compare(b.DueAt); c != 0 {
+			return c
+		}
+	case dueA:
+		return -1
+	case dueB:
+		return 1
+	}
+
+	switch o.Policy {
+	case OrderOldestFirst:
+		if c := compareCreated(a.CreatedAt, b.CreatedAt, false); c != 0 {
+			return c
+		}
+	case OrderNewestFirst:
+		if c := compareCreated(a.CreatedAt, b.CreatedAt, true); c != 0 {
+			return c
+		}
+	case OrderLabelWeighted:
+		if c := cmp.Compare(o.labelWeight(b), o.labelWeight(a)); c != 0 {
+			return c
+		}
+	}
+	if c := ComparePriority(a.Priority, b.Priority); c != 0 {
+		return c
+	}
+	return compareCreated(a.CreatedAt, b.CreatedAt, false)
+}
+
+// nearingDue reports whether the issue is due within the deadline window.
+func (o Ordering) nearingDue(issue Issue) bool {
+	return o.DeadlineWindow > 0 && !issue.DueAt.IsZero() && time.Until(issue.DueAt) <= o.DeadlineWindow
+}
+
+// labelWeight sums the weights of the issue's labels.
+func (o Ordering) labelWeight(issue Issue) int {
+	total := 0
+	for _, label := range issue.Labels {
+		for name, weight := range o.LabelWeights {
+			if strings.EqualFold(name, label) {
+				total += weight
+			}
+		}
+	}
+	return total
+}
+
+// compareCreated orders creation times oldest first, or newest first when
+// newest is set. Unknown (zero) times sort last either way.
+func compareCreated(a, b time.Time, newest bool) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	case newest:
+		return b.Compare(a)
+	}
+	return a.Compare(b)
+}
+
+// priorityNames maps tracker priority names to Issue.Priority values.
+var priorityNames = map[string]int{
+	"urgent":   1,
+	"critical": 1,
+	"high":     2,
+	"medium":   3,
+	"normal":   3,
+	"low":      4,
+}
+
+// priorityFromName maps a priority name such as "urgent" or "low" to an
+// Issue.Priority value, or 0 when the name is not recognized.
+func priorityFromName(name string) int {
+	return priorityNames[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// PriorityFromLabels derives an Issue.Priority from priority labels, for
+// trackers without a priority field. It recognizes "P0" through "P4" and
+// "priority: high" style labels (separated by ":", "/", "-" or a space).
+// The most urgent match wins; it returns 0 when no label matches.
+func PriorityFromLabels(labels []string) int {
+	best := 0
+	for _, label := range labels {
+		p := labelPriority(strings.ToLower(strings.TrimSpace(label)))
+		if p > 0 && (best == 0 || p < best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// labelPriority returns the priority a single lowercased label denotes.
+func labelPriority(label string) int {
+	if len(label) == 2 && label[0] == 'p' && label[1] >= '0' && label[1] <= '4' {
+		// P0 is the most urgent; P3 and P4 both map to low.
+		return min(int(label[1]-'0')+1, 4)
+	}
+	rest, ok := strings.CutPrefix(label, "priority")
+	if !ok {
+		return 0
+	}
+	return priorityFromName(strings.TrimLeft(rest, ":/- "))
+}
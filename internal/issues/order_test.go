@@ -0,0 +1,94 @@
+package issues
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOrderingCompare(t *testing.T) {
+	now := time.Now()
+	all := []Issue{
+		{ID: "old-low", Priority: 4, CreatedAt: now.Add(-72 * time.Hour), Labels: []string{"chore"}},
+		{ID: "new-urgent", Priority: 1, CreatedAt: now, Labels: []string{"Bug"}},
+		{ID: "undated-high", Priority: 2, Labels: []string{"bug", "security"}},
+		{ID: "mid-none", CreatedAt: now.Add(-24 * time.Hour)},
+	}
+
+	tests := []struct {
+		ordering Ordering
+		want     []string
+	}{
+		{Ordering{}, []string{"new-urgent", "undated-high", "old-low", "mid-none"}},
+		{Ordering{Policy: OrderPriority}, []string{"new-urgent", "undated-high", "old-low", "mid-none"}},
+		{Ordering{Policy: OrderOldestFirst}, []string{"old-low", "mid-none", "new-urgent", "undated-high"}},
+		{Ordering{Policy: OrderNewestFirst}, []string{"new-urgent", "mid-none", "old-low", "undated-high"}},
+		{
+			Ordering{Policy: OrderLabelWeighted, LabelWeights: map[string]int{"bug": 5, "security": 10, "chore": -1}},
+			[]string{"undated-high", "new-urgent", "mid-none", "old-low"},
+		},
+	}
+	for _, tt := range tests {
+		sorted := slices.Clone(all)
+		slices.SortStableFunc(sorted, tt.ordering.Compare)
+		var got []string
+		for _, issue := range sorted {
+			got = append(got, issue.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("policy %q: got %v, want %v", tt.ordering.Policy, got, tt.want)
+		}
+	}
+}
+
+func TestOrderingCompare_DeadlineWindow(t *testing.T) {
+	now := time.Now()
+	all := []Issue{
+		{ID: "urgent", Priority: 1},
+		{ID: "due-later", Priority: 4, DueAt: now.Add(30 * 24 * time.Hour)},
+		{ID: "due-soon", Priority: 4, DueAt: now.Add(48 * time.Hour)},
+		{ID: "overdue", Priority: 3, DueAt: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		window time.Duration
+		want   []string
+	}{
+		{0, []string{"urgent", "overdue", "due-later", "due-soon"}},
+		{72 * time.Hour, []string{"overdue", "due-soon", "urgent", "due-later"}},
+	}
+	for _, tt := range tests {
+		sorted := slices.Clone(all)
+		slices.SortStableFunc(sorted, Ordering{DeadlineWindow: tt.window}.Compare)
+		var got []string
+		for _, issue := range sorted {
+			got = append(got, issue.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("window %v: got %v, want %v", tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestPriorityFromLabels(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   int
+	}{
+		{nil, 0},
+		{[]string{"bug", "queued"}, 0},
+		{[]string{"P0"}, 1},
+		{[]string{"p2"}, 3},
+		{[]string{"P4"}, 4},
+		{[]string{"priority: high"}, 2},
+		{[]string{"Priority/Low"}, 4},
+		{[]string{"priority-urgent"}, 1},
+		{[]string{"priority: someday"}, 0},
+		{[]string{"priority: low", "P1"}, 2}, // most urgent wins
+	}
+	for _, tt := range tests {
+		if got := PriorityFromLabels(tt.labels); got != tt.want {
+			t.Errorf("PriorityFromLabels(%v) = %d, want %d", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package issues
 
 import (
 	"context"
+	"math"
 	"time"
 )
 
@@ -11,9 +12,13 @@ import (
 type Source string
 
 const (
-	SourceGitHub Source = "github"
-	SourceAsana  Source = "asana"
-	SourceLinear Source = "linear"
+	SourceGitHub  Source = "github"
+	SourceAsana   Source = "asana"
+	SourceLinear  Source = "linear"
+	SourceGitLab  Source = "gitlab"
+	SourceClickUp Source = "clickup"
+	SourceFile    Source = "file"
+	SourceHTTP    Source = "http"
 )
 
 // Issue represents a generic issue/task from any supported source.
@@ -23,14 +28,54 @@ type Issue struct {
 	Body   string
 	URL    string
 	Source Source
+
+	// Priority is the tracker's urgency: 1 (urgent) through 4 (low), or 0
+	// when the issue has none. Linear and ClickUp report it natively and
+	// file backlogs set it in front matter; for the other trackers it is
+	// derived from priority labels (see PriorityFromLabels).
+	Priority int
+
+	// CreatedAt is when the issue was opened, or zero when the tracker
+	// doesn't report it.
+	CreatedAt time.Time
+
+	// Labels are the issue's labels (tags in Asana and ClickUp).
+	Labels []string
+
+	// DueAt is the issue's due date (a GitHub issue's milestone due date),
+	// or zero when it has none. Set by GitHub, Linear, and Asana.
+	DueAt time.Time
+
+	// Parent is the ID of the issue this one is a sub-task of. Only set on
+	// issues returned by ProviderSubtaskFetcher.FetchSubtasks.
+	Parent string
+}
+
+// ComparePriority orders two Issue.Priority values from most to least urgent,
+// with unprioritized (0) last. It returns a negative number when a is more
+// urgent than b.
+func ComparePriority(a, b int) int {
+	return priorityRank(a) - priorityRank(b)
+}
+
+// priorityRank maps a priority to a sort key where unprioritized sorts last.
+func priorityRank(p int) int {
+	if p <= 0 {
+		return math.MaxInt32
+	}
+	return p
 }
 
 // FilterConfig holds provider-specific filter parameters for fetching issues.
 type FilterConfig struct {
 	Label   string // Tag/label name to filter by (empty = no filtering)
-	Project string // Asana: project GID
+	Project string // Asana: project GID; GitLab: project ID or path
 	Team    string // Linear: team ID
 	Section string // Asana: section name to filter by (fetches tasks in that section only)
+	List    string // ClickUp: list ID
+
+	Assignee string // GitHub, GitLab: login; Linear: display name or email; Asana: name, email, or GID (empty = any assignee)
+	MaxItems int    // Asana, Linear: cap on issues fetched across pages (0 = default)
 }
 
 // Provider defines the interface for fetching issues from different sources.
@@ -67,6 +112,11 @@ type Provider interface {
 	GetPRLinkText(issue Issue) string
 }
 
+// ClaimLabel is the label (tag in Asana and ClickUp) a daemon adds to an issue
+// while it is working on it, so other daemons and humans can see the issue is
+// taken. See ProviderActions.Claim.
+const ClaimLabel = "erg-in-progress"
+
 // ProviderActions extends Provider with write operations for issue management.
 // Providers that support label removal and commenting should implement this interface.
 // Operations are expected to be best-effort; callers should log but not fail on errors.
@@ -76,6 +126,24 @@ type ProviderActions interface {
 
 	// Comment adds a comment/story to an issue/task.
 	Comment(ctx context.Context, repoPath string, issueID string, body string) error
+
+	// Claim marks the issue as taken by adding ClaimLabel. It returns false,
+	// leaving the issue untouched, when the issue already carries the label.
+	// Providers that cannot label issues report true without marking anything.
+	//
+	// The label carries no lease: the daemon pairs it with a claim comment
+	// (ProviderClaimManager) whose expiry decides whether a label left behind
+	// by a crashed daemon may be taken over.
+	Claim(ctx context.Context, repoPath string, issueID string) (bool, error)
+
+	// Unclaim removes ClaimLabel. Removing a label that is not there is not an error.
+	Unclaim(ctx context.Context, repoPath string, issueID string) error
+
+	// CloseIssue closes the issue/task: closes a GitHub or GitLab issue, marks
+	// an Asana task complete, or moves a Linear issue to the team's completed
+	// state. The daemon calls it after merge for sources whose PR link text
+	// cannot close the issue. Closing an already-closed issue is not an error.
+	CloseIssue(ctx context.Context, repoPath string, issueID string) error
 }
 
 // ProviderRegistry holds all available issue providers.
@@ -182,6 +250,49 @@ type IssueStateChecker interface {
 	IsIssueClosed(ctx context.Context, repoPath string, issueID string) (bool, error)
 }
 
+// ProviderCompleter extends Provider with a post-merge hook for providers that
+// track completion themselves rather than through PR link keywords.
+type ProviderCompleter interface {
+	// CompleteIssue marks the issue done after its PR has merged.
+	CompleteIssue(ctx context.Context, repoPath string, issueID string) error
+}
+
+// ProviderWriter extends Provider with the ability to file new issues, so
+// workflows can record follow-up work discovered during a session.
+type ProviderWriter interface {
+	// CreateIssue files an issue in the tracker mapped to repoPath and returns
+	// it as the provider would fetch it. Labels are created in the tracker
+	// where it requires that (GitHub labels must already exist).
+	CreateIssue(ctx context.Context, repoPath string, title, body string, labels []string) (*Issue, error)
+}
+
+// ProviderSubtaskFetcher extends Provider with sub-task lookup (Asana
+// subtasks, Linear sub-issues), so a parent issue can be expanded into one
+// work item per sub-task.
+type ProviderSubtaskFetcher interface {
+	// FetchSubtasks returns the parent's open sub-tasks, with Parent set to
+	// parentID. A parent without sub-tasks yields an empty slice.
+	FetchSubtasks(ctx context.Context, repoPath string, parentID string) ([]Issue, error)
+}
+
+// ProviderEnricher extends Provider with issue enrichment: gathering the
+// context around an issue (linked issues and PRs, tracker fields,
+// attachments, recent comments) so a session sees the whole discussion
+// rather than just the issue body.
+type ProviderEnricher interface {
+	// EnrichIssue returns the issue's context as markdown, or "" when there
+	// is nothing beyond the body. Callers cap the size of the result.
+	EnrichIssue(ctx context.Context, repoPath string, issue Issue) (string, error)
+}
+
+// ProviderBlockerFetcher extends Provider with the tracker's own "blocked
+// by" relations, so the daemon can hold an issue until its blockers are
+// done.
+type ProviderBlockerFetcher interface {
+	// FetchBlockers returns the issues blocking issueID, open or not.
+	FetchBlockers(ctx context.Context, repoPath string, issueID string) ([]Blocker, error)
+}
+
 // ClaimInfo represents a daemon's claim on an issue. Used by the claiming
 // protocol to coordinate work across multiple daemon instances.
 type ClaimInfo struct {
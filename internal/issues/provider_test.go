@@ -108,3 +108,23 @@ func (m *mockProvider) GenerateBranchName(issue Issue) string {
 func (m *mockProvider) GetPRLinkText(_ Issue) string {
 	return ""
 }
+
+func TestComparePriority(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want int // sign of the result
+	}{
+		{1, 2, -1},
+		{4, 1, 1},
+		{2, 2, 0},
+		{4, 0, -1}, // unprioritized sorts last
+		{0, 1, 1},
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		got := ComparePriority(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("ComparePriority(%d, %d) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -6,15 +6,21 @@ import (
 	"github.com/zhubert/erg/internal/secrets"
 )
 
-// keychainGet is the function used to look up secrets from the keychain.
-// It can be overridden in tests to control keychain behavior.
-var keychainGet = secrets.Get
+// storedGet is the function used to look up credentials saved with
+// `erg auth set` (OS keychain, then the encrypted credentials file).
+// It can be overridden in tests to control stored-credential behavior.
+var storedGet = secrets.Stored
 
 // resolveToken looks up an API token by checking the environment variable first,
-// then falling back to the macOS Keychain. Returns the token and true if found.
-func resolveToken(envVar, keychainService string) (string, bool) {
-	if v := os.Getenv(envVar); v != "" {
-		return v, true
+// then falling back to stored credentials. Either may be an exec: reference,
+// which is expanded by running its command. Returns the token and true if found.
+func resolveToken(envVar, service string) (string, bool) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		var ok bool
+		if raw, ok = storedGet(service); !ok {
+			return "", false
+		}
 	}
-	return keychainGet(keychainService)
+	return secrets.ExpandFor(envVar, raw)
 }
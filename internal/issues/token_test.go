@@ -11,7 +11,7 @@ func TestResolveToken_EnvVarTakesPriority(t *testing.T) {
 	defer os.Unsetenv(envVar)
 
 	// Even if keychain would return something, env var wins
-	keychainGet = func(string) (string, bool) { return "from-keychain", true }
+	storedGet = func(string) (string, bool) { return "from-keychain", true }
 
 	val, ok := resolveToken(envVar, "erg-test/nonexistent")
 	if !ok {
@@ -26,7 +26,7 @@ func TestResolveToken_FallsBackToKeychain(t *testing.T) {
 	const envVar = "ERG_TEST_TOKEN_RESOLVE_KC"
 	os.Unsetenv(envVar)
 
-	keychainGet = func(service string) (string, bool) {
+	storedGet = func(service string) (string, bool) {
 		if service == "erg-test/my-service" {
 			return "keychain-value", true
 		}
@@ -54,3 +54,17 @@ func TestResolveToken_NeitherSet(t *testing.T) {
 		t.Errorf("resolveToken = %q, want empty", val)
 	}
 }
+
+func TestResolveToken_ExpandsExecReference(t *testing.T) {
+	const envVar = "ERG_TEST_TOKEN_RESOLVE_EXEC"
+	t.Setenv(envVar, "")
+
+	orig := storedGet
+	defer func() { storedGet = orig }()
+	storedGet = func(string) (string, bool) { return "exec:printf stored-via-exec", true }
+
+	val, ok := resolveToken(envVar, "erg-test/exec-service")
+	if !ok || val != "stored-via-exec" {
+		t.Errorf("resolveToken = %q, %v; want exec output", val, ok)
+	}
+}
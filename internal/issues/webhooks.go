@@ -0,0 +1,77 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+// RegisterLinearWebhook creates a Linear webhook that delivers issue changes
+// for teamID to url, signed with secret.
+func RegisterLinearWebhook(ctx context.Context, teamID, url, secret string) error {
+	p := &LinearProvider{
+		httpClient: newBoardHTTPClient(),
+		apiBase:    boardLinearBase,
+	}
+
+	mutation := `mutation($teamId: String!, $url: String!, $secret: String!) {
+  webhookCreate(input: { teamId: $teamId, url: $url, secret: $secret, resourceTypes: ["Issue"], label: "erg" }) {
+    success
+  }
+}`
+
+	var resp struct {
+		Data struct {
+			WebhookCreate struct {
+				Success bool `json:"success"`
+			} `json:"webhookCreate"`
+		} `json:"data"`
+	}
+
+	if err := p.linearGraphQL(ctx, mutation, map[string]any{
+		"teamId": teamID,
+		"url":    url,
+		"secret": secret,
+	}, "Linear API key lacks admin permission to create webhooks", &resp); err != nil {
+		return err
+	}
+
+	if !resp.Data.WebhookCreate.Success {
+		return fmt.Errorf("linear API returned success=false creating webhook for team %q", teamID)
+	}
+	return nil
+}
+
+// RegisterAsanaWebhook creates an Asana webhook that delivers task changes
+// in projectGID to target. Asana confirms the webhook with a handshake
+// request to target before this call returns, so the erg webhook listener
+// must already be reachable there.
+func RegisterAsanaWebhook(ctx context.Context, projectGID, target string) error {
+	pat, ok := resolveToken(asanaPATEnvVar, secrets.AsanaPATService)
+	if !ok {
+		return secrets.TokenNotFoundError(asanaPATEnvVar)
+	}
+
+	payload := map[string]any{
+		"data": map[string]any{
+			"resource": projectGID,
+			"target":   target,
+			"filters": []map[string]string{
+				{"resource_type": "task", "action": "added"},
+				{"resource_type": "task", "action": "changed"},
+			},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/webhooks", boardAsanaBase)
+	return apiRequest(ctx, newBoardHTTPClient(), http.MethodPost, url, bytes.NewReader(body),
+		"Bearer "+pat, http.StatusCreated, "", "Asana", nil)
+}
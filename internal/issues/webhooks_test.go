@@ -0,0 +1,94 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterLinearWebhook(t *testing.T) {
+	var capturedQuery string
+	var capturedVars map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var gql struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&gql)
+		capturedQuery, capturedVars = gql.Query, gql.Variables
+		w.Write([]byte(`{"data":{"webhookCreate":{"success":true}}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Setenv("LINEAR_API_KEY", "test-key")
+	origBase := boardLinearBase
+	boardLinearBase = srv.URL
+	defer func() { boardLinearBase = origBase }()
+
+	if err := RegisterLinearWebhook(context.Background(), "team-1", "https://erg.example.com/webhooks/linear", "shh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(capturedQuery, "webhookCreate") || !strings.Contains(capturedQuery, `resourceTypes: ["Issue"]`) {
+		t.Errorf("unexpected mutation: %s", capturedQuery)
+	}
+	if capturedVars["teamId"] != "team-1" || capturedVars["url"] != "https://erg.example.com/webhooks/linear" || capturedVars["secret"] != "shh" {
+		t.Errorf("unexpected variables: %v", capturedVars)
+	}
+}
+
+func TestRegisterLinearWebhook_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"webhookCreate":{"success":false}}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("LINEAR_API_KEY", "test-key")
+	origBase := boardLinearBase
+	boardLinearBase = srv.URL
+	defer func() { boardLinearBase = origBase }()
+
+	if err := RegisterLinearWebhook(context.Background(), "team-1", "https://x", "shh"); err == nil {
+		t.Fatal("expected error when success=false")
+	}
+}
+
+func TestRegisterAsanaWebhook(t *testing.T) {
+	var captured struct {
+		Data struct {
+			Resource string              `json:"resource"`
+			Target   string              `json:"target"`
+			Filters  []map[string]string `json:"filters"`
+		} `json:"data"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&captured)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"gid":"wh-1"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Setenv("ASANA_PAT", "test-token")
+	origBase := boardAsanaBase
+	boardAsanaBase = srv.URL
+	defer func() { boardAsanaBase = origBase }()
+
+	if err := RegisterAsanaWebhook(context.Background(), "proj123", "https://erg.example.com/webhooks/asana/proj123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.Data.Resource != "proj123" || captured.Data.Target != "https://erg.example.com/webhooks/asana/proj123" {
+		t.Errorf("unexpected request: %+v", captured.Data)
+	}
+	if len(captured.Data.Filters) != 2 || captured.Data.Filters[0]["resource_type"] != "task" {
+		t.Errorf("unexpected filters: %v", captured.Data.Filters)
+	}
+}
@@ -0,0 +1,446 @@
+package issuetest
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// asanaDefaultPageSize mirrors the page size erg requests from Asana.
+const asanaDefaultPageSize = 100
+
+// AsanaProject is the GID of the project an AsanaServer serves. Configure it
+// as the repo's Asana project.
+const AsanaProject = "1200000000000001"
+
+// asanaWorkspace is the GID of the workspace AsanaProject belongs to.
+const asanaWorkspace = "1100000000000001"
+
+// AsanaServer is a fake Asana REST API serving a single project. Tasks live
+// in the project and, when Issue.Section is set, in that section; tags are
+// the issue's labels.
+type AsanaServer struct {
+	store
+	srv  *httptest.Server
+	tags []string // workspace tags created through the API
+}
+
+// NewAsana starts a fake Asana API, closed when the test ends. Requests must
+// carry a bearer token, as the real API requires.
+func NewAsana(t testing.TB) *AsanaServer {
+	t.Helper()
+	s := &AsanaServer{store: newStore(asanaDefaultPageSize, "erg")}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/"+AsanaProject+"/tasks", s.listTasks)
+	mux.HandleFunc("GET /projects/"+AsanaProject+"/sections", s.listSections)
+	mux.HandleFunc("GET /sections/{section}/tasks", s.listTasks)
+	mux.HandleFunc("POST /sections/{section}/addTask", s.addTaskToSection)
+	mux.HandleFunc("POST /tasks", s.createTask)
+	mux.HandleFunc("GET /tasks/{task}", s.getTask)
+	mux.HandleFunc("PUT /tasks/{task}", s.updateTask)
+	mux.HandleFunc("GET /tasks/{task}/subtasks", s.listSubtasks)
+	mux.HandleFunc("POST /tasks/{task}/addTag", s.changeTag)
+	mux.HandleFunc("POST /tasks/{task}/removeTag", s.changeTag)
+	mux.HandleFunc("GET /tasks/{task}/stories", s.listStories)
+	mux.HandleFunc("POST /tasks/{task}/stories", s.addStory)
+	mux.HandleFunc("GET /attachments", s.listAttachments)
+	mux.HandleFunc("GET /workspaces/"+asanaWorkspace+"/typeahead", s.searchTags)
+	mux.HandleFunc("POST /tags", s.createTag)
+
+	s.srv = httptest.NewServer(s.guard(mux))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL returns the API base URL to hand to the provider.
+func (s *AsanaServer) URL() string { return s.srv.URL }
+
+// Client returns an HTTP client for the server.
+func (s *AsanaServer) Client() *http.Client { return s.srv.Client() }
+
+// guard applies authentication and injected faults ahead of routing.
+func (s *AsanaServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := s.begin(); ok {
+			if f.retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(f.retryAfter/time.Second)))
+			}
+			asanaError(w, f.status, http.StatusText(f.status))
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			asanaError(w, http.StatusUnauthorized, "Not Authorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func asanaError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"errors": []map[string]string{{"message": msg}}})
+}
+
+// asanaSectionGID is the section GID the fake reports for a section name.
+func asanaSectionGID(name string) string { return "section-" + strings.ToLower(name) }
+
+// asanaTagGID is the tag GID the fake reports for a tag name.
+func asanaTagGID(name string) string { return "tag-" + strings.ToLower(name) }
+
+func (s *AsanaServer) listTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	section := r.PathValue("section")
+	openOnly := q.Get("completed_since") == "now"
+
+	s.mu.Lock()
+	tasks, next := s.page(func(i *Issue) bool {
+		if openOnly && i.Closed {
+			return false
+		}
+		return section == "" || asanaSectionGID(i.Section) == section
+	}, q.Get("offset"), limit)
+	data := make([]map[string]any, len(tasks))
+	for i, task := range tasks {
+		data[i] = s.task(task)
+	}
+	s.mu.Unlock()
+
+	resp := map[string]any{"data": data, "next_page": nil}
+	if next != "" {
+		resp["next_page"] = map[string]string{"offset": next}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *AsanaServer) listSections(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var names []string
+	for _, issue := range s.issues {
+		if issue.Section != "" && !slices.Contains(names, issue.Section) {
+			names = append(names, issue.Section)
+		}
+	}
+	s.mu.Unlock()
+
+	data := make([]map[string]string, len(names))
+	for i, name := range names {
+		data[i] = map[string]string{"gid": asanaSectionGID(name), "name": name}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (s *AsanaServer) addTaskToSection(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Task string `json:"task"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(body.Data.Task)
+	if issue == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	for _, other := range s.issues {
+		if asanaSectionGID(other.Section) == r.PathValue("section") {
+			issue.Section = other.Section
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{}})
+}
+
+func (s *AsanaServer) getTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(r.PathValue("task"))
+	if issue == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.task(issue)})
+}
+
+// listSubtasks serves a task's subtasks, complete or not, as Asana does.
+func (s *AsanaServer) listSubtasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.find(r.PathValue("task")) == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	tasks := []map[string]any{}
+	for _, child := range s.children(r.PathValue("task")) {
+		tasks = append(tasks, s.task(child))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": tasks, "next_page": nil})
+}
+
+// listAttachments serves the attachments of the task named by the parent
+// query parameter.
+func (s *AsanaServer) listAttachments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(r.URL.Query().Get("parent"))
+	if issue == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	attachments := []map[string]string{}
+	for i, name := range issue.Attachments {
+		attachments = append(attachments, map[string]string{
+			"gid": issue.ID + "-" + strconv.Itoa(i), "name": name,
+			"permanent_url": s.srv.URL + "/attachments/" + issue.ID + "/" + name,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": attachments})
+}
+
+// updateTask applies a task update. Only the completed field is honoured.
+func (s *AsanaServer) updateTask(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Completed *bool `json:"completed"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(r.PathValue("task"))
+	if issue == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if body.Data.Completed != nil {
+		issue.Closed = *body.Data.Completed
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.task(issue)})
+}
+
+// createTask creates a task in AsanaProject. Rich-text html_notes are kept
+// verbatim as the task's notes.
+func (s *AsanaServer) createTask(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Name      string   `json:"name"`
+			Notes     string   `json:"notes"`
+			HTMLNotes string   `json:"html_notes"`
+			Projects  []string `json:"projects"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !slices.Contains(body.Data.Projects, AsanaProject) {
+		asanaError(w, http.StatusBadRequest, "projects: task must belong to project "+AsanaProject)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	issue := &Issue{
+		ID:        strconv.Itoa(1300000000000000 + s.nextID),
+		Title:     body.Data.Name,
+		Body:      body.Data.Notes,
+		CreatedAt: time.Now(),
+	}
+	if body.Data.HTMLNotes != "" {
+		issue.Body = body.Data.HTMLNotes
+	}
+	s.issues = append(s.issues, issue)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": s.task(issue)})
+}
+
+// changeTag serves addTag and removeTag.
+func (s *AsanaServer) changeTag(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Tag string `json:"tag"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(r.PathValue("task"))
+	if issue == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/removeTag") {
+		issue.Labels = slices.DeleteFunc(issue.Labels, func(l string) bool { return asanaTagGID(l) == body.Data.Tag })
+	} else if i := slices.IndexFunc(s.workspaceTags(), func(n string) bool { return asanaTagGID(n) == body.Data.Tag }); i < 0 {
+		asanaError(w, http.StatusNotFound, "tag not found")
+		return
+	} else if name := s.workspaceTags()[i]; !issue.hasLabel(name) {
+		issue.Labels = append(issue.Labels, name)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{}})
+}
+
+// searchTags serves tag typeahead, matching tag names that contain the query.
+func (s *AsanaServer) searchTags(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	data := []map[string]string{}
+	s.mu.Lock()
+	for _, name := range s.workspaceTags() {
+		if strings.Contains(strings.ToLower(name), query) {
+			data = append(data, map[string]string{"gid": asanaTagGID(name), "name": name})
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (s *AsanaServer) createTag(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Name      string `json:"name"`
+			Workspace string `json:"workspace"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Data.Workspace != asanaWorkspace {
+		asanaError(w, http.StatusNotFound, "workspace not found")
+		return
+	}
+
+	s.mu.Lock()
+	s.tags = append(s.tags, body.Data.Name)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, map[string]any{"data": map[string]string{
+		"gid": asanaTagGID(body.Data.Name), "name": body.Data.Name,
+	}})
+}
+
+// workspaceTags lists the workspace's tag names: those created through the
+// API plus those on seeded tasks. The caller holds s.mu.
+func (s *AsanaServer) workspaceTags() []string {
+	names := slices.Clone(s.tags)
+	for _, issue := range s.issues {
+		for _, l := range issue.Labels {
+			if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, l) }) {
+				names = append(names, l)
+			}
+		}
+	}
+	return names
+}
+
+func (s *AsanaServer) listStories(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("task")
+	if s.find(id) == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	data := []map[string]any{}
+	for _, c := range s.comments[id] {
+		stamp := c.CreatedAt.UTC().Format(time.RFC3339)
+		data = append(data, map[string]any{
+			"gid": c.ID, "type": "comment", "text": c.Body,
+			"created_at": stamp, "modified_at": stamp,
+			"created_by": map[string]string{"name": c.Author},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (s *AsanaServer) addStory(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data struct {
+			Text     string `json:"text"`
+			HTMLText string `json:"html_text"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		asanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("task")
+	if s.find(id) == nil {
+		asanaError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	text := body.Data.Text
+	if text == "" {
+		text = body.Data.HTMLText
+	}
+	gid := s.addComment(id, text)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": map[string]string{"gid": gid}})
+}
+
+// task renders an issue as an Asana task. The caller holds s.mu.
+func (s *AsanaServer) task(issue *Issue) map[string]any {
+	tags := make([]map[string]string, len(issue.Labels))
+	for i, l := range issue.Labels {
+		tags[i] = map[string]string{"gid": asanaTagGID(l), "name": l}
+	}
+	fields := []map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(issue.Fields)) {
+		fields = append(fields, map[string]string{"name": name, "display_value": issue.Fields[name]})
+	}
+	task := map[string]any{
+		"gid":           issue.ID,
+		"name":          issue.Title,
+		"notes":         issue.Body,
+		"permalink_url": s.srv.URL + "/0/0/" + issue.ID,
+		"tags":          tags,
+		"created_at":    issue.CreatedAt.UTC().Format(time.RFC3339),
+		"completed":     issue.Closed,
+		"workspace":     map[string]string{"gid": asanaWorkspace},
+		"assignee":      nil,
+		"due_on":        nil,
+		"memberships":   []map[string]any{},
+		"custom_fields": fields,
+	}
+	if issue.Assignee != "" {
+		task["assignee"] = map[string]string{"gid": issue.Assignee, "name": issue.Assignee, "email": issue.Assignee}
+	}
+	if !issue.DueAt.IsZero() {
+		task["due_on"] = issue.DueAt.Format(time.DateOnly)
+	}
+	if issue.Section != "" {
+		task["memberships"] = []map[string]any{{
+			"project": map[string]string{"gid": AsanaProject},
+			"section": map[string]string{"gid": asanaSectionGID(issue.Section), "name": issue.Section},
+		}}
+	}
+	return task
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
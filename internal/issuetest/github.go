@@ -0,0 +1,375 @@
+package issuetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/exec"
+)
+
+// githubDefaultListLimit is how many issues `gh issue list` returns without
+// --limit.
+const githubDefaultListLimit = 30
+
+// GitHub emulates the gh CLI commands erg uses to work with GitHub issues:
+// `gh issue list|view|comment|edit|close|create` and the `gh api` issue and comment
+// endpoints. Issue IDs are issue numbers. Every other command, including
+// other gh commands such as `gh pr`, goes to the fallback executor, so a
+// GitHub can wrap the exec.MockExecutor a test already configures.
+//
+// gh pages through results itself, so list requests are bounded by --limit
+// (30 by default) rather than by SetPageSize. Injected failures make the
+// command exit non-zero with gh's "HTTP <status>" message on stderr.
+type GitHub struct {
+	store
+	fallback exec.CommandExecutor
+}
+
+// NewGitHub returns a gh emulator that delegates everything it does not
+// handle to fallback (which may be nil).
+func NewGitHub(fallback exec.CommandExecutor) *GitHub {
+	return &GitHub{store: newStore(githubDefaultListLimit, "erg-bot"), fallback: fallback}
+}
+
+var _ exec.CommandExecutor = (*GitHub)(nil)
+
+// ghResult is the outcome of an emulated gh command.
+type ghResult struct {
+	stdout, stderr []byte
+	err            error
+}
+
+// Run implements exec.CommandExecutor.
+func (g *GitHub) Run(ctx context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	if r, ok := g.handle(name, args); ok {
+		return r.stdout, r.stderr, r.err
+	}
+	if g.fallback != nil {
+		return g.fallback.Run(ctx, dir, name, args...)
+	}
+	return nil, nil, nil
+}
+
+// Output implements exec.CommandExecutor.
+func (g *GitHub) Output(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if r, ok := g.handle(name, args); ok {
+		return r.stdout, r.err
+	}
+	if g.fallback != nil {
+		return g.fallback.Output(ctx, dir, name, args...)
+	}
+	return nil, nil
+}
+
+// CombinedOutput implements exec.CommandExecutor.
+func (g *GitHub) CombinedOutput(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if r, ok := g.handle(name, args); ok {
+		return append(r.stdout, r.stderr...), r.err
+	}
+	if g.fallback != nil {
+		return g.fallback.CombinedOutput(ctx, dir, name, args...)
+	}
+	return nil, nil
+}
+
+// Start implements exec.CommandExecutor. erg never streams gh issue
+// commands, so Start always goes to the fallback.
+func (g *GitHub) Start(ctx context.Context, dir, name string, args ...string) (exec.CommandHandle, error) {
+	if g.fallback == nil {
+		return nil, fmt.Errorf("issuetest: no fallback executor for %s", name)
+	}
+	return g.fallback.Start(ctx, dir, name, args...)
+}
+
+// errGH is the exit error of a failed gh command.
+var errGH = errors.New("exit status 1")
+
+// ghFailure builds the result of a failed gh command, carrying stderr in
+// the error too since Output callers never see stderr.
+func ghFailure(format string, a ...any) (ghResult, bool) {
+	msg := fmt.Sprintf(format, a...)
+	return ghResult{stderr: []byte(msg + "\n"), err: fmt.Errorf("%w: %s", errGH, msg)}, true
+}
+
+// ghOutput is the result of a successful gh command.
+func ghOutput(stdout, stderr string) (ghResult, bool) {
+	return ghResult{stdout: []byte(stdout), stderr: []byte(stderr)}, true
+}
+
+// handle runs a gh issue command against the store. ok is false for
+// commands the emulator does not handle.
+func (g *GitHub) handle(name string, args []string) (ghResult, bool) {
+	if name != "gh" || len(args) < 2 || !g.handles(args) {
+		return ghResult{}, false
+	}
+	if f, failed := g.begin(); failed {
+		if f.status == http.StatusTooManyRequests {
+			return ghFailure("HTTP 429: API rate limit exceeded (https://api.github.com/graphql)")
+		}
+		return ghFailure("HTTP %d: %s (https://api.github.com/graphql)", f.status, http.StatusText(f.status))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if args[0] == "api" {
+		return g.api(args[1:])
+	}
+
+	sub, rest := args[1], args[2:]
+	switch sub {
+	case "list":
+		return g.list(rest)
+	case "create":
+		return g.create(rest)
+	}
+	if len(rest) == 0 {
+		return ghFailure("issue number required")
+	}
+	issue := g.find(rest[0])
+	if issue == nil {
+		return ghFailure("GraphQL: Could not resolve to an issue or pull request with the number of %s. (repository.issue)", rest[0])
+	}
+	flags := rest[1:]
+
+	switch sub {
+	case "view":
+		return ghJSON(g.render(issue))
+	case "comment":
+		g.addComment(issue.ID, flagValue(flags, "--body"))
+		return ghOutput(fmt.Sprintf("https://github.com/owner/repo/issues/%s#issuecomment-%d\n", issue.ID, g.nextID), "")
+	case "edit":
+		for i := 0; i+1 < len(flags); i += 2 {
+			for _, label := range strings.Split(flags[i+1], ",") {
+				switch flags[i] {
+				case "--add-label":
+					if !issue.hasLabel(label) {
+						issue.Labels = append(issue.Labels, label)
+					}
+				case "--remove-label":
+					issue.removeLabel(label)
+				}
+			}
+		}
+		return ghOutput(fmt.Sprintf("https://github.com/owner/repo/issues/%s\n", issue.ID), "")
+	case "close":
+		issue.Closed = true
+		return ghOutput("", fmt.Sprintf("✓ Closed issue #%s (%s)\n", issue.ID, issue.Title))
+	}
+	return ghFailure("unsupported gh issue command %q", sub)
+}
+
+// handles reports whether args name a command the emulator serves.
+func (g *GitHub) handles(args []string) bool {
+	switch args[0] {
+	case "issue":
+		return slices.Contains([]string{"list", "view", "comment", "edit", "close", "create"}, args[1])
+	case "api":
+		return slices.ContainsFunc(args[1:], func(a string) bool {
+			return strings.HasPrefix(a, "repos/:owner/:repo/issues/")
+		})
+	}
+	return false
+}
+
+// create serves `gh issue create`, numbering the issue after the highest
+// existing issue number. The caller holds g.mu.
+func (g *GitHub) create(flags []string) (ghResult, bool) {
+	issue := &Issue{CreatedAt: time.Now()}
+	for i := 0; i+1 < len(flags); i += 2 {
+		switch flags[i] {
+		case "--title", "-t":
+			issue.Title = flags[i+1]
+		case "--body", "-b":
+			issue.Body = flags[i+1]
+		case "--label", "-l":
+			issue.Labels = append(issue.Labels, strings.Split(flags[i+1], ",")...)
+		}
+	}
+	if issue.Title == "" {
+		return ghFailure("title can't be blank")
+	}
+
+	number := 0
+	for _, existing := range g.issues {
+		if n, err := strconv.Atoi(existing.ID); err == nil && n > number {
+			number = n
+		}
+	}
+	issue.ID = strconv.Itoa(number + 1)
+	g.issues = append(g.issues, issue)
+	return ghOutput(fmt.Sprintf("https://github.com/owner/repo/issues/%s\n", issue.ID), "")
+}
+
+// list serves `gh issue list`. The caller holds g.mu.
+func (g *GitHub) list(flags []string) (ghResult, bool) {
+	state := "open"
+	limit := githubDefaultListLimit
+	var labels []string
+	var assignee string
+	for i := 0; i+1 < len(flags); i += 2 {
+		switch flags[i] {
+		case "--state", "-s":
+			state = flags[i+1]
+		case "--label", "-l":
+			labels = append(labels, strings.Split(flags[i+1], ",")...)
+		case "--assignee", "-a":
+			assignee = flags[i+1]
+		case "--limit", "-L":
+			n, err := strconv.Atoi(flags[i+1])
+			if err != nil || n < 1 {
+				return ghFailure("invalid limit: %s", flags[i+1])
+			}
+			limit = n
+		}
+	}
+
+	out := []map[string]any{}
+	for _, issue := range g.issues {
+		if len(out) == limit {
+			break
+		}
+		if (state == "open" && issue.Closed) || (state == "closed" && !issue.Closed) {
+			continue
+		}
+		if slices.ContainsFunc(labels, func(l string) bool { return !issue.hasLabel(l) }) {
+			continue
+		}
+		if assignee != "" && !strings.EqualFold(issue.Assignee, assignee) {
+			continue
+		}
+		out = append(out, g.render(issue))
+	}
+	return ghJSON(out)
+}
+
+// api serves the `gh api` issue-comment endpoints. The caller holds g.mu.
+func (g *GitHub) api(args []string) (ghResult, bool) {
+	method := http.MethodGet
+	var path, body string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--method" || args[i] == "-X":
+			i++
+			method = args[i]
+		case args[i] == "-f" || args[i] == "--raw-field":
+			i++
+			if v, ok := strings.CutPrefix(args[i], "body="); ok {
+				body = v
+				if method == http.MethodGet {
+					method = http.MethodPost
+				}
+			}
+		case strings.HasPrefix(args[i], "repos/"):
+			path = strings.TrimPrefix(args[i], "repos/:owner/:repo/issues/")
+		}
+	}
+
+	if idStr, ok := strings.CutPrefix(path, "comments/"); ok {
+		id := "comment-" + idStr
+		for issueID, comments := range g.comments {
+			for i := range comments {
+				if comments[i].ID != id {
+					continue
+				}
+				if method == http.MethodDelete {
+					g.comments[issueID] = slices.Delete(comments, i, i+1)
+					return ghOutput("", "")
+				}
+				comments[i].Body = body
+				return ghJSON(githubComment(comments[i]))
+			}
+		}
+		return ghFailure("HTTP 404: Not Found (https://api.github.com/repos/owner/repo/issues/comments/%s)", idStr)
+	}
+
+	if issue := g.find(path); issue != nil && method == http.MethodGet {
+		state := "open"
+		if issue.Closed {
+			state = "closed"
+		}
+		number, _ := strconv.Atoi(issue.ID)
+		return ghJSON(map[string]any{
+			"number": number, "title": issue.Title, "body": issue.Body, "state": state,
+			"html_url": "https://github.com/owner/repo/issues/" + issue.ID,
+		})
+	}
+
+	number, ok := strings.CutSuffix(path, "/comments")
+	if !ok || g.find(number) == nil {
+		return ghFailure("HTTP 404: Not Found (https://api.github.com/repos/owner/repo/issues/%s)", path)
+	}
+	if method == http.MethodPost {
+		g.addComment(number, body)
+		comments := g.comments[number]
+		return ghJSON(githubComment(comments[len(comments)-1]))
+	}
+	out := []map[string]any{}
+	for _, c := range g.comments[number] {
+		out = append(out, githubComment(c))
+	}
+	return ghJSON(out)
+}
+
+// githubComment renders a comment as the REST API does.
+func githubComment(c Comment) map[string]any {
+	id, _ := strconv.ParseInt(strings.TrimPrefix(c.ID, "comment-"), 10, 64)
+	stamp := c.CreatedAt.UTC().Format(time.RFC3339)
+	return map[string]any{
+		"id": id, "body": c.Body, "created_at": stamp, "updated_at": stamp,
+		"user": map[string]string{"login": c.Author},
+	}
+}
+
+// render renders an issue with every --json field erg requests. The caller
+// holds g.mu.
+func (g *GitHub) render(issue *Issue) map[string]any {
+	number, _ := strconv.Atoi(issue.ID)
+	labels := make([]map[string]string, len(issue.Labels))
+	for i, l := range issue.Labels {
+		labels[i] = map[string]string{"name": l}
+	}
+	assignees := []map[string]string{}
+	if issue.Assignee != "" {
+		assignees = append(assignees, map[string]string{"login": issue.Assignee})
+	}
+	state := "OPEN"
+	if issue.Closed {
+		state = "CLOSED"
+	}
+	return map[string]any{
+		"number":    number,
+		"title":     issue.Title,
+		"body":      issue.Body,
+		"url":       "https://github.com/owner/repo/issues/" + issue.ID,
+		"createdAt": issue.CreatedAt.UTC().Format(time.RFC3339),
+		"labels":    labels,
+		"assignees": assignees,
+		"milestone": nil,
+		"state":     state,
+	}
+}
+
+// ghJSON is the result of a gh command printing v as JSON.
+func ghJSON(v any) (ghResult, bool) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return ghResult{err: err}, true
+	}
+	return ghOutput(string(out)+"\n", "")
+}
+
+// flagValue returns the value following flag in args, or "".
+func flagValue(args []string, flag string) string {
+	if i := slices.Index(args, flag); i >= 0 && i+1 < len(args) {
+		return args[i+1]
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+// Package issuetest provides in-memory fakes of the issue trackers erg talks
+// to, so tests can drive the real provider code and whole daemon workflows
+// without tracker accounts:
+//
+//   - AsanaServer and LinearServer are httptest servers speaking the subset
+//     of the Asana REST and Linear GraphQL APIs that erg uses. Point a
+//     provider at them with NewAsanaProviderWithClient or
+//     NewLinearProviderWithClient.
+//   - GitHub emulates the gh CLI, which is how erg reaches GitHub issues. It
+//     implements exec.CommandExecutor and hands every other command to a
+//     fallback executor.
+//
+// Every fake paginates like the real API, can be told to rate-limit or fail
+// upcoming requests, and keeps comments and label changes for assertions.
+// RunScript plays a scripted end-to-end scenario against a daemon tick.
+package issuetest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Issue is an issue (task) seeded into a fake tracker.
+type Issue struct {
+	ID        string // Asana GID, Linear identifier ("ENG-1"), or GitHub issue number
+	Title     string
+	Body      string
+	Labels    []string // labels, or tags in Asana
+	Assignee  string   // login, display name, or email
+	Section   string   // Asana section
+	Priority  int      // Linear priority: 0 = none, 1 = urgent … 4 = low
+	CreatedAt time.Time
+	DueAt     time.Time
+	Closed    bool
+	Parent    string // ID of the parent issue, for Asana subtasks and Linear sub-issues
+
+	Fields      map[string]string // Asana custom fields, name to display value
+	Attachments []string          // Asana attachment names
+	Related     []string          // IDs of Linear issues this one is related to
+}
+
+// Comment is a comment left on a fake issue.
+type Comment struct {
+	ID        string
+	Body      string
+	Author    string
+	CreatedAt time.Time
+}
+
+// hasLabel reports whether the issue carries label (case-insensitive).
+func (i *Issue) hasLabel(label string) bool {
+	return slices.ContainsFunc(i.Labels, func(l string) bool { return strings.EqualFold(l, label) })
+}
+
+// removeLabel drops label from the issue, reporting whether it was there.
+func (i *Issue) removeLabel(label string) bool {
+	n := len(i.Labels)
+	i.Labels = slices.DeleteFunc(i.Labels, func(l string) bool { return strings.EqualFold(l, label) })
+	return len(i.Labels) != n
+}
+
+// store is the issue state shared by every fake.
+type store struct {
+	mu              sync.Mutex
+	issues          []*Issue
+	comments        map[string][]Comment
+	nextID          int
+	pageSize        int // set by SetPageSize; 0 means defaultPageSize
+	defaultPageSize int
+	requests        int
+	pending         []fault
+	author          string // author of comments erg posts
+}
+
+func newStore(defaultPageSize int, author string) store {
+	return store{comments: make(map[string][]Comment), defaultPageSize: defaultPageSize, author: author}
+}
+
+// fault is an injected failure for upcoming requests.
+type fault struct {
+	status     int
+	retryAfter time.Duration
+	remaining  int
+}
+
+// AddIssue seeds an issue. A zero CreatedAt is set to now.
+func (s *store) AddIssue(issue Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue.CreatedAt.IsZero() {
+		issue.CreatedAt = time.Now()
+	}
+	issue.Labels = slices.Clone(issue.Labels)
+	s.issues = append(s.issues, &issue)
+}
+
+// Issue returns a snapshot of the issue with the given ID.
+func (s *store) Issue(id string) (Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.find(id)
+	if issue == nil {
+		return Issue{}, false
+	}
+	snapshot := *issue
+	snapshot.Labels = slices.Clone(issue.Labels)
+	return snapshot, true
+}
+
+// CloseIssue marks an issue closed (completed), as a human would.
+func (s *store) CloseIssue(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue := s.find(id); issue != nil {
+		issue.Closed = true
+	}
+}
+
+// Comments returns the comments left on an issue, oldest first.
+func (s *store) Comments(id string) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.comments[id])
+}
+
+// AddComment leaves a comment on an issue as author, as a human would.
+func (s *store) AddComment(id, author, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.comments[id] = append(s.comments[id], Comment{
+		ID: "comment-" + strconv.Itoa(s.nextID), Body: body, Author: author, CreatedAt: time.Now(),
+	})
+}
+
+// SetPageSize sets how many issues a list request returns at most, to
+// exercise pagination.
+func (s *store) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// Requests returns how many requests the fake has served, including
+// injected failures.
+func (s *store) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// FailNext makes the next n requests fail with the given HTTP status.
+func (s *store) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, fault{status: status, remaining: n})
+}
+
+// RateLimitNext makes the next n requests fail with 429 Too Many Requests,
+// advertising retryAfter.
+func (s *store) RateLimitNext(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, fault{status: 429, retryAfter: retryAfter, remaining: n})
+}
+
+// begin counts a request and returns the injected failure it should get,
+// if any.
+func (s *store) begin() (fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if len(s.pending) == 0 {
+		return fault{}, false
+	}
+	f := s.pending[0]
+	s.pending[0].remaining--
+	if s.pending[0].remaining <= 0 {
+		s.pending = s.pending[1:]
+	}
+	return f, true
+}
+
+// find returns the issue with the given ID. The caller holds s.mu.
+func (s *store) find(id string) *Issue {
+	for _, issue := range s.issues {
+		if issue.ID == id {
+			return issue
+		}
+	}
+	return nil
+}
+
+// children returns the parent's sub-tasks. The caller holds s.mu.
+func (s *store) children(parentID string) []*Issue {
+	var children []*Issue
+	for _, issue := range s.issues {
+		if issue.Parent == parentID {
+			children = append(children, issue)
+		}
+	}
+	return children
+}
+
+// addComment records a comment and returns its ID. The caller holds s.mu.
+func (s *store) addComment(issueID, body string) string {
+	s.nextID++
+	id := "comment-" + strconv.Itoa(s.nextID)
+	s.comments[issueID] = append(s.comments[issueID], Comment{ID: id, Body: body, Author: s.author, CreatedAt: time.Now()})
+	return id
+}
+
+// page returns one page of matches starting at the offset encoded in
+// cursor, and the cursor of the next page ("" on the last page). The
+// caller holds s.mu.
+func (s *store) page(match func(*Issue) bool, cursor string, limit int) ([]*Issue, string) {
+	var all []*Issue
+	for _, issue := range s.issues {
+		if match(issue) {
+			all = append(all, issue)
+		}
+	}
+	size := s.pageSize
+	if size <= 0 {
+		size = s.defaultPageSize
+	}
+	if limit > 0 && limit < size {
+		size = limit
+	}
+	start, _ := strconv.Atoi(cursor)
+	start = min(max(start, 0), len(all))
+	end := min(start+size, len(all))
+	next := ""
+	if end < len(all) {
+		next = strconv.Itoa(end)
+	}
+	return all[start:end], next
+}
+
+// Step is one beat of a scripted end-to-end run: change the tracker, let the
+// daemon tick, then check the outcome.
+type Step struct {
+	Name  string
+	Do    func()             // optional: change tracker state before ticking
+	Ticks int                // daemon ticks to run; 0 means 1
+	Check func(t testing.TB) // optional: assert on the outcome
+}
+
+// RunScript plays steps in order, running each as a subtest. Later steps
+// build on earlier ones, so the script stops at the first failing step.
+func RunScript(t *testing.T, tick func(), steps ...Step) {
+	t.Helper()
+	for i, step := range steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step-%d", i+1)
+		}
+		ok := t.Run(name, func(t *testing.T) {
+			if step.Do != nil {
+				step.Do()
+			}
+			for range max(step.Ticks, 1) {
+				tick()
+			}
+			if step.Check != nil {
+				step.Check(t)
+			}
+		})
+		if !ok {
+			t.Fatalf("script stopped at step %q", name)
+		}
+	}
+}
@@ -0,0 +1,604 @@
+package issuetest_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/exec"
+	"github.com/zhubert/erg/internal/git"
+	"github.com/zhubert/erg/internal/issues"
+	"github.com/zhubert/erg/internal/issuetest"
+	"github.com/zhubert/erg/internal/providerhttp"
+)
+
+const repo = "/test/repo"
+
+func TestAsana_PaginatesAndFiltersByTag(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	srv := issuetest.NewAsana(t)
+	for i := 1; i <= 5; i++ {
+		labels := []string{"ai"}
+		if i%2 == 0 {
+			labels = nil
+		}
+		srv.AddIssue(issuetest.Issue{ID: fmt.Sprint(1000 + i), Title: fmt.Sprintf("Task %d", i), Labels: labels})
+	}
+	srv.AddIssue(issuetest.Issue{ID: "2000", Title: "Done", Labels: []string{"ai"}, Closed: true})
+	srv.SetPageSize(2)
+
+	cfg := &config.Config{}
+	cfg.SetAsanaProject(repo, issuetest.AsanaProject)
+	p := issues.NewAsanaProviderWithClient(cfg, srv.Client(), srv.URL())
+
+	got, err := p.FetchIssues(context.Background(), repo, issues.FilterConfig{Project: issuetest.AsanaProject, Label: "AI"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d issues, want 3 open tasks tagged ai: %+v", len(got), got)
+	}
+	if srv.Requests() != 3 {
+		t.Errorf("requests = %d, want 3 pages of 2", srv.Requests())
+	}
+}
+
+func TestAsana_SectionsTagsAndStories(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	srv := issuetest.NewAsana(t)
+	srv.AddIssue(issuetest.Issue{ID: "1", Title: "In todo", Labels: []string{"queued"}, Section: "To Do"})
+	srv.AddIssue(issuetest.Issue{ID: "2", Title: "In doing", Section: "Doing"})
+
+	cfg := &config.Config{}
+	cfg.SetAsanaProject(repo, issuetest.AsanaProject)
+	p := issues.NewAsanaProviderWithClient(cfg, srv.Client(), srv.URL())
+	ctx := context.Background()
+
+	got, err := p.FetchIssues(ctx, repo, issues.FilterConfig{Project: issuetest.AsanaProject, Section: "to do"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("section fetch = %+v, want task 1", got)
+	}
+
+	if err := p.RemoveLabel(ctx, repo, "1", "queued"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if issue, _ := srv.Issue("1"); len(issue.Labels) != 0 {
+		t.Errorf("labels after RemoveLabel = %v, want none", issue.Labels)
+	}
+
+	if err := p.MoveToSection(ctx, repo, "1", "Doing"); err != nil {
+		t.Fatalf("MoveToSection: %v", err)
+	}
+	if in, err := p.IsInSection(ctx, repo, "1", "doing"); err != nil || !in {
+		t.Errorf("IsInSection(doing) = %v, %v; want true", in, err)
+	}
+
+	if err := p.Comment(ctx, repo, "1", "Working on it"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	comments, err := p.GetIssueComments(ctx, repo, "1")
+	if err != nil {
+		t.Fatalf("GetIssueComments: %v", err)
+	}
+	if len(comments) != 1 || !strings.Contains(comments[0].Body, "Working on it") {
+		t.Errorf("comments = %+v, want the posted comment", comments)
+	}
+}
+
+func TestAsana_RateLimitAndAuth(t *testing.T) {
+	srv := issuetest.NewAsana(t)
+	srv.AddIssue(issuetest.Issue{ID: "1", Title: "Task"})
+	cfg := &config.Config{}
+	cfg.SetAsanaProject(repo, issuetest.AsanaProject)
+	p := issues.NewAsanaProviderWithClient(cfg, srv.Client(), srv.URL())
+	filter := issues.FilterConfig{Project: issuetest.AsanaProject}
+
+	t.Setenv("ASANA_PAT", "test-pat")
+	srv.RateLimitNext(1, 30*time.Second)
+	if _, err := p.FetchIssues(context.Background(), repo, filter); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("rate-limited fetch err = %v, want a 429 error", err)
+	}
+	if got, err := p.FetchIssues(context.Background(), repo, filter); err != nil || len(got) != 1 {
+		t.Fatalf("fetch after rate limit = %v, %v; want 1 issue", got, err)
+	}
+
+	srv.FailNext(2, 503)
+	for range 2 {
+		if _, err := p.FetchIssues(context.Background(), repo, filter); err == nil {
+			t.Fatal("expected injected 503 to fail the fetch")
+		}
+	}
+	if _, err := p.FetchIssues(context.Background(), repo, filter); err != nil {
+		t.Fatalf("fetch after faults drained: %v", err)
+	}
+}
+
+func TestProviders_RetryTransientErrors(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	ctx := context.Background()
+
+	asana := issuetest.NewAsana(t)
+	asana.AddIssue(issuetest.Issue{ID: "1", Title: "Task"})
+	asanaCfg := &config.Config{}
+	asanaCfg.SetAsanaProject(repo, issuetest.AsanaProject)
+	asanaProvider := issues.NewAsanaProviderWithClient(asanaCfg, providerhttp.Wrap(asana.Client(), providerhttp.DefaultPolicy()), asana.URL())
+
+	linear := issuetest.NewLinear(t)
+	linear.AddIssue(issuetest.Issue{ID: "ENG-1", Title: "Issue"})
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam(repo, "team-1")
+	linearProvider := issues.NewLinearProviderWithClient(linearCfg, providerhttp.Wrap(linear.Client(), providerhttp.DefaultPolicy()), linear.URL())
+
+	asana.FailNext(2, 503)
+	if got, err := asanaProvider.FetchIssues(ctx, repo, issues.FilterConfig{Project: issuetest.AsanaProject}); err != nil || len(got) != 1 {
+		t.Errorf("asana fetch through 503s = %v, %v; want 1 issue", got, err)
+	}
+	linear.FailNext(1, 502)
+	if got, err := linearProvider.FetchIssues(ctx, repo, issues.FilterConfig{Team: "team-1"}); err != nil || len(got) != 1 {
+		t.Errorf("linear fetch through a 502 = %v, %v; want 1 issue", got, err)
+	}
+
+	// Writes are not repeated after a server error: the first attempt may
+	// have been applied.
+	asana.FailNext(1, 502)
+	if err := asanaProvider.Comment(ctx, repo, "1", "hello"); err == nil {
+		t.Error("expected the failed comment to surface")
+	}
+	if n := len(asana.Comments("1")); n != 0 {
+		t.Errorf("comments = %d, want none", n)
+	}
+
+	// A rate-limit window longer than the retry budget fails fast.
+	asana.RateLimitNext(1, time.Minute)
+	if _, err := asanaProvider.FetchIssues(ctx, repo, issues.FilterConfig{Project: issuetest.AsanaProject}); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("fetch during long rate limit err = %v, want a 429 error", err)
+	}
+}
+
+func TestLinear_PaginatesAndFilters(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	srv := issuetest.NewLinear(t)
+	for i := 1; i <= 7; i++ {
+		srv.AddIssue(issuetest.Issue{
+			ID: fmt.Sprintf("ENG-%d", i), Title: fmt.Sprintf("Issue %d", i),
+			Labels: []string{"ai"}, Priority: 2, Assignee: "erg@example.com",
+		})
+	}
+	srv.AddIssue(issuetest.Issue{ID: "ENG-8", Title: "Someone else's", Labels: []string{"ai"}, Assignee: "dana@example.com"})
+	srv.AddIssue(issuetest.Issue{ID: "ENG-9", Title: "Unlabeled"})
+	srv.SetPageSize(3)
+
+	cfg := &config.Config{}
+	cfg.SetLinearTeam(repo, "team-1")
+	p := issues.NewLinearProviderWithClient(cfg, srv.Client(), srv.URL())
+
+	got, err := p.FetchIssues(context.Background(), repo, issues.FilterConfig{Team: "team-1", Label: "AI", Assignee: "erg@example.com"})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(got) != 7 {
+		t.Fatalf("got %d issues, want 7", len(got))
+	}
+	if got[0].ID != "ENG-1" || got[0].Priority != 2 {
+		t.Errorf("first issue = %+v, want ENG-1 with high priority", got[0])
+	}
+	if srv.Requests() != 3 {
+		t.Errorf("requests = %d, want 3 pages of 3", srv.Requests())
+	}
+
+	srv.SetPageSize(0)
+	got, err = p.FetchIssues(context.Background(), repo, issues.FilterConfig{Team: "team-1", MaxItems: 4})
+	if err != nil {
+		t.Fatalf("FetchIssues with MaxItems: %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("got %d issues, want MaxItems cap of 4", len(got))
+	}
+}
+
+func TestLinear_LabelsCommentsAndClaims(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	srv := issuetest.NewLinear(t)
+	srv.AddIssue(issuetest.Issue{ID: "ENG-1", Title: "Fix it", Labels: []string{"ai", "queued"}})
+
+	cfg := &config.Config{}
+	cfg.SetLinearTeam(repo, "team-1")
+	p := issues.NewLinearProviderWithClient(cfg, srv.Client(), srv.URL())
+	ctx := context.Background()
+
+	if err := p.RemoveLabel(ctx, repo, "ENG-1", "queued"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "ENG-1", "queued"); has {
+		t.Error("queued label still present after RemoveLabel")
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "ENG-1", "ai"); !has {
+		t.Error("ai label lost by RemoveLabel")
+	}
+
+	if err := p.Comment(ctx, repo, "ENG-1", "Picked up"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if comments := srv.Comments("ENG-1"); len(comments) != 1 || comments[0].Body != "Picked up" {
+		t.Errorf("server comments = %+v, want the posted comment", comments)
+	}
+
+	claimID, err := p.PostClaim(ctx, repo, "ENG-1", issues.ClaimInfo{DaemonID: "d1", Hostname: "host", Timestamp: time.Now(), Expires: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("PostClaim: %v", err)
+	}
+	claims, err := p.GetClaims(ctx, repo, "ENG-1")
+	if err != nil || len(claims) != 1 || claims[0].DaemonID != "d1" {
+		t.Fatalf("GetClaims = %+v, %v; want the posted claim", claims, err)
+	}
+	if err := p.DeleteClaim(ctx, repo, "ENG-1", claimID); err != nil {
+		t.Fatalf("DeleteClaim: %v", err)
+	}
+	if claims, _ := p.GetClaims(ctx, repo, "ENG-1"); len(claims) != 0 {
+		t.Errorf("claims after delete = %+v, want none", claims)
+	}
+
+	if err := p.MoveToSection(ctx, repo, "ENG-1", "In Progress"); err != nil {
+		t.Fatalf("MoveToSection: %v", err)
+	}
+	if in, _ := p.IsInSection(ctx, repo, "ENG-1", "in progress"); !in {
+		t.Error("issue not in In Progress after MoveToSection")
+	}
+}
+
+func TestAsana_ClaimCreatesAndAppliesTag(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	srv := issuetest.NewAsana(t)
+	srv.AddIssue(issuetest.Issue{ID: "1", Title: "First"})
+	srv.AddIssue(issuetest.Issue{ID: "2", Title: "Second"})
+
+	cfg := &config.Config{}
+	cfg.SetAsanaProject(repo, issuetest.AsanaProject)
+	p := issues.NewAsanaProviderWithClient(cfg, srv.Client(), srv.URL())
+	ctx := context.Background()
+
+	for _, id := range []string{"1", "2"} {
+		if ok, err := p.Claim(ctx, repo, id); err != nil || !ok {
+			t.Fatalf("Claim(%s) = %v, %v; want true", id, ok, err)
+		}
+	}
+	if ok, err := p.Claim(ctx, repo, "1"); err != nil || ok {
+		t.Errorf("second Claim = %v, %v; want false for an already-claimed task", ok, err)
+	}
+	if issue, _ := srv.Issue("2"); len(issue.Labels) != 1 || issue.Labels[0] != issues.ClaimLabel {
+		t.Errorf("labels = %v, want [%s]", issue.Labels, issues.ClaimLabel)
+	}
+
+	if err := p.Unclaim(ctx, repo, "1"); err != nil {
+		t.Fatalf("Unclaim: %v", err)
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "1", issues.ClaimLabel); has {
+		t.Error("claim tag still present after Unclaim")
+	}
+}
+
+func TestLinear_ClaimCreatesAndAppliesLabel(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	srv := issuetest.NewLinear(t)
+	srv.AddIssue(issuetest.Issue{ID: "ENG-1", Title: "Fix it", Labels: []string{"ai"}})
+
+	cfg := &config.Config{}
+	cfg.SetLinearTeam(repo, "team-1")
+	p := issues.NewLinearProviderWithClient(cfg, srv.Client(), srv.URL())
+	ctx := context.Background()
+
+	if ok, err := p.Claim(ctx, repo, "ENG-1"); err != nil || !ok {
+		t.Fatalf("Claim = %v, %v; want true", ok, err)
+	}
+	if ok, err := p.Claim(ctx, repo, "ENG-1"); err != nil || ok {
+		t.Errorf("second Claim = %v, %v; want false for an already-claimed issue", ok, err)
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "ENG-1", "ai"); !has {
+		t.Error("ai label lost by Claim")
+	}
+
+	if err := p.Unclaim(ctx, repo, "ENG-1"); err != nil {
+		t.Fatalf("Unclaim: %v", err)
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "ENG-1", issues.ClaimLabel); has {
+		t.Error("claim label still present after Unclaim")
+	}
+}
+
+func TestGitHub_EmulatesIssueCommands(t *testing.T) {
+	fallback := exec.NewMockExecutor(nil)
+	fallback.AddExactMatch("gh", []string{"pr", "view", "feature", "--json", "state"}, exec.MockResponse{Stdout: []byte(`{"state":"OPEN"}`)})
+	gh := issuetest.NewGitHub(fallback)
+	gh.AddIssue(issuetest.Issue{ID: "1", Title: "First", Labels: []string{"queued", "priority: high"}})
+	gh.AddIssue(issuetest.Issue{ID: "2", Title: "Second", Labels: []string{"bug"}})
+	gh.AddIssue(issuetest.Issue{ID: "3", Title: "Closed", Labels: []string{"queued"}, Closed: true})
+
+	gitSvc := git.NewGitServiceWithExecutor(gh)
+	p := issues.NewGitHubProvider(gitSvc)
+	ctx := context.Background()
+
+	got, err := p.FetchIssues(ctx, repo, issues.FilterConfig{})
+	if err != nil {
+		t.Fatalf("FetchIssues: %v", err)
+	}
+	if len(got) != 2 || got[0].Priority != 2 {
+		t.Fatalf("FetchIssues = %+v, want 2 open issues, the first high priority", got)
+	}
+	labeled, err := gitSvc.FetchGitHubIssuesWithLabel(ctx, repo, "queued", "")
+	if err != nil || len(labeled) != 1 || labeled[0].Number != 1 {
+		t.Fatalf("FetchGitHubIssuesWithLabel = %+v, %v; want issue 1", labeled, err)
+	}
+
+	if err := p.RemoveLabel(ctx, repo, "1", "queued"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if has, _ := p.CheckIssueHasLabel(ctx, repo, "1", "queued"); has {
+		t.Error("queued label still present after RemoveLabel")
+	}
+
+	if err := p.Comment(ctx, repo, "1", "On it"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	comments, err := p.GetIssueComments(ctx, repo, "1")
+	if err != nil || len(comments) != 1 || comments[0].Body != "On it" {
+		t.Fatalf("GetIssueComments = %+v, %v; want the posted comment", comments, err)
+	}
+	if err := p.UpdateComment(ctx, repo, "1", comments[0].ID, "Done"); err != nil {
+		t.Fatalf("UpdateComment: %v", err)
+	}
+	if c := gh.Comments("1"); c[0].Body != "Done" {
+		t.Errorf("comment after update = %q, want Done", c[0].Body)
+	}
+
+	gh.CloseIssue("2")
+	if closed, err := p.IsIssueClosed(ctx, repo, "2"); err != nil || !closed {
+		t.Errorf("IsIssueClosed(2) = %v, %v; want true", closed, err)
+	}
+
+	if _, err := p.GetIssue(ctx, repo, "99"); err == nil {
+		t.Error("GetIssue(99) succeeded, want not-found error")
+	}
+
+	gh.RateLimitNext(1, time.Minute)
+	if _, err := p.FetchIssues(ctx, repo, issues.FilterConfig{}); err == nil || !strings.Contains(err.Error(), "HTTP 429") {
+		t.Errorf("rate-limited fetch err = %v, want HTTP 429", err)
+	}
+
+	out, err := gh.Output(ctx, repo, "gh", "pr", "view", "feature", "--json", "state")
+	if err != nil || string(out) != `{"state":"OPEN"}` {
+		t.Errorf("non-issue gh command = %q, %v; want the fallback's response", out, err)
+	}
+}
+
+func TestRunScript_TicksThenChecks(t *testing.T) {
+	var ticks int
+	var ran []string
+	issuetest.RunScript(t, func() { ticks++ },
+		issuetest.Step{Name: "one", Check: func(t testing.TB) { ran = append(ran, "one") }},
+		issuetest.Step{Name: "three-ticks", Ticks: 3, Check: func(t testing.TB) { ran = append(ran, "three-ticks") }},
+	)
+	if ticks != 4 {
+		t.Errorf("ticks = %d, want 4", ticks)
+	}
+	if strings.Join(ran, ",") != "one,three-ticks" {
+		t.Errorf("ran = %v", ran)
+	}
+}
+
+func TestCreateIssue_AllWriters(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	ctx := context.Background()
+
+	gh := issuetest.NewGitHub(nil)
+	gh.AddIssue(issuetest.Issue{ID: "7", Title: "Existing"})
+
+	asana := issuetest.NewAsana(t)
+	asanaCfg := &config.Config{}
+	asanaCfg.SetAsanaProject(repo, issuetest.AsanaProject)
+
+	linear := issuetest.NewLinear(t)
+	linear.AddIssue(issuetest.Issue{ID: "ENG-4", Title: "Existing", Labels: []string{"bug"}})
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam(repo, "team-1")
+
+	tests := []struct {
+		name   string
+		writer issues.ProviderWriter
+		wantID string // "" when the tracker assigns opaque IDs
+	}{
+		{"github", issues.NewGitHubProvider(git.NewGitServiceWithExecutor(gh)), "8"},
+		{"asana", issues.NewAsanaProviderWithClient(asanaCfg, asana.Client(), asana.URL()), ""},
+		{"linear", issues.NewLinearProviderWithClient(linearCfg, linear.Client(), linear.URL()), "ENG-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.writer.CreateIssue(ctx, repo, "Follow-up: flaky test", "Seen during **coding**.", []string{"bug", "ai-assisted"})
+			if err != nil {
+				t.Fatalf("CreateIssue: %v", err)
+			}
+			if got.ID == "" || (tt.wantID != "" && got.ID != tt.wantID) {
+				t.Errorf("ID = %q, want %q", got.ID, tt.wantID)
+			}
+			if got.Title != "Follow-up: flaky test" || got.URL == "" {
+				t.Errorf("created issue = %+v, want title and URL", got)
+			}
+
+			checker := tt.writer.(issues.ProviderGateChecker)
+			for _, label := range []string{"bug", "ai-assisted"} {
+				if has, err := checker.CheckIssueHasLabel(ctx, repo, got.ID, label); err != nil || !has {
+					t.Errorf("CheckIssueHasLabel(%s) = %v, %v; want true", label, has, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCloseIssue_AllProviders(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	ctx := context.Background()
+
+	gh := issuetest.NewGitHub(nil)
+	gh.AddIssue(issuetest.Issue{ID: "7", Title: "Open"})
+
+	asana := issuetest.NewAsana(t)
+	asana.AddIssue(issuetest.Issue{ID: "1", Title: "Open"})
+	asanaCfg := &config.Config{}
+	asanaCfg.SetAsanaProject(repo, issuetest.AsanaProject)
+
+	linear := issuetest.NewLinear(t)
+	linear.AddIssue(issuetest.Issue{ID: "ENG-1", Title: "Open", Section: "In Review"})
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam(repo, "team-1")
+
+	tests := []struct {
+		name    string
+		actions issues.ProviderActions
+		id      string
+		issue   func(string) (issuetest.Issue, bool)
+	}{
+		{"github", issues.NewGitHubProvider(git.NewGitServiceWithExecutor(gh)), "7", gh.Issue},
+		{"asana", issues.NewAsanaProviderWithClient(asanaCfg, asana.Client(), asana.URL()), "1", asana.Issue},
+		{"linear", issues.NewLinearProviderWithClient(linearCfg, linear.Client(), linear.URL()), "ENG-1", linear.Issue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.actions.CloseIssue(ctx, repo, tt.id); err != nil {
+				t.Fatalf("CloseIssue: %v", err)
+			}
+			if issue, _ := tt.issue(tt.id); !issue.Closed {
+				t.Errorf("issue %s still open after CloseIssue", tt.id)
+			}
+		})
+	}
+}
+
+func TestFetchSubtasks_AsanaAndLinear(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	ctx := context.Background()
+
+	asana := issuetest.NewAsana(t)
+	asana.AddIssue(issuetest.Issue{ID: "10", Title: "Parent"})
+	asana.AddIssue(issuetest.Issue{ID: "11", Title: "Open child", Parent: "10"})
+	asana.AddIssue(issuetest.Issue{ID: "12", Title: "Done child", Parent: "10", Closed: true})
+	asanaCfg := &config.Config{}
+	asanaCfg.SetAsanaProject(repo, issuetest.AsanaProject)
+
+	linear := issuetest.NewLinear(t)
+	linear.AddIssue(issuetest.Issue{ID: "10", Title: "Parent"})
+	linear.AddIssue(issuetest.Issue{ID: "11", Title: "Open child", Parent: "10"})
+	linear.AddIssue(issuetest.Issue{ID: "12", Title: "Done child", Parent: "10", Closed: true})
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam(repo, "team-1")
+
+	tests := []struct {
+		name    string
+		fetcher issues.ProviderSubtaskFetcher
+	}{
+		{"asana", issues.NewAsanaProviderWithClient(asanaCfg, asana.Client(), asana.URL())},
+		{"linear", issues.NewLinearProviderWithClient(linearCfg, linear.Client(), linear.URL())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fetcher.FetchSubtasks(ctx, repo, "10")
+			if err != nil {
+				t.Fatalf("FetchSubtasks: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != "11" || got[0].Parent != "10" || got[0].Title != "Open child" {
+				t.Errorf("subtasks = %+v, want only the open child with its parent", got)
+			}
+
+			none, err := tt.fetcher.FetchSubtasks(ctx, repo, "11")
+			if err != nil || len(none) != 0 {
+				t.Errorf("FetchSubtasks(leaf) = %v, %v; want none", none, err)
+			}
+		})
+	}
+}
+
+func TestEnrichIssue_AllProviders(t *testing.T) {
+	t.Setenv("ASANA_PAT", "test-pat")
+	t.Setenv("LINEAR_API_KEY", "lin_test")
+	ctx := context.Background()
+
+	gh := issuetest.NewGitHub(nil)
+	gh.AddIssue(issuetest.Issue{ID: "3", Title: "Login is flaky", Body: "Started after the cookie change"})
+	gh.AddIssue(issuetest.Issue{ID: "7", Title: "Fix login", Body: "Follow-up to #3; see also #99 and #7."})
+	gh.AddComment("7", "alice", "Only on Safari")
+	if err := issues.NewGitHubProvider(git.NewGitServiceWithExecutor(gh)).Comment(ctx, repo, "7", "<!-- erg:step=plan -->\nplan"); err != nil {
+		t.Fatal(err)
+	}
+
+	asana := issuetest.NewAsana(t)
+	asana.AddIssue(issuetest.Issue{
+		ID: "1", Title: "Fix login",
+		Fields:      map[string]string{"Severity": "High", "Estimate": ""},
+		Attachments: []string{"trace.har"},
+	})
+	asana.AddComment("1", "alice", "Only on Safari")
+	asanaCfg := &config.Config{}
+	asanaCfg.SetAsanaProject(repo, issuetest.AsanaProject)
+
+	linear := issuetest.NewLinear(t)
+	linear.AddIssue(issuetest.Issue{ID: "ENG-3", Title: "Login is flaky", Body: "Started after the cookie change"})
+	linear.AddIssue(issuetest.Issue{ID: "ENG-7", Title: "Fix login", Related: []string{"ENG-3"}})
+	linear.AddComment("ENG-7", "alice", "Only on Safari")
+	linearCfg := &config.Config{}
+	linearCfg.SetLinearTeam(repo, "team-1")
+
+	tests := []struct {
+		name     string
+		enricher issues.ProviderEnricher
+		issue    issues.Issue
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "github",
+			enricher: issues.NewGitHubProvider(git.NewGitServiceWithExecutor(gh)),
+			issue:    issues.Issue{ID: "7", Body: "Follow-up to #3; see also #99 and #7."},
+			want:     []string{"#3 (issue, open): Login is flaky", "> Started after the cookie change", "**alice**", "Only on Safari"},
+			notWant:  []string{"#99", "#7 (", "erg:step"},
+		},
+		{
+			name:     "asana",
+			enricher: issues.NewAsanaProviderWithClient(asanaCfg, asana.Client(), asana.URL()),
+			issue:    issues.Issue{ID: "1"},
+			want:     []string{"- Severity: High", "- trace.har: ", "Only on Safari"},
+			notWant:  []string{"Estimate"},
+		},
+		{
+			name:     "linear",
+			enricher: issues.NewLinearProviderWithClient(linearCfg, linear.Client(), linear.URL()),
+			issue:    issues.Issue{ID: "ENG-7"},
+			want:     []string{"ENG-3 (related, Todo): Login is flaky", "> Started after the cookie change", "Only on Safari"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.enricher.EnrichIssue(ctx, repo, tt.issue)
+			if err != nil {
+				t.Fatalf("EnrichIssue: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("context missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("context unexpectedly contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
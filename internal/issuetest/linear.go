@@ -0,0 +1,340 @@
+package issuetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// linearDefaultPageSize is the largest page Linear serves.
+const linearDefaultPageSize = 250
+
+// LinearServer is a fake Linear GraphQL API serving a single team. Issue IDs
+// are identifiers such as "ENG-1"; Issue.Section is the workflow state name,
+// and closed issues report the "Done" state.
+type LinearServer struct {
+	store
+	srv    *httptest.Server
+	labels []string // team labels created through the API
+}
+
+// NewLinear starts a fake Linear API, closed when the test ends. Requests
+// must carry an Authorization header, as the real API requires.
+func NewLinear(t testing.TB) *LinearServer {
+	t.Helper()
+	s := &LinearServer{store: newStore(linearDefaultPageSize, "erg")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", s.graphql)
+	s.srv = httptest.NewServer(mux)
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL returns the API base URL to hand to the provider.
+func (s *LinearServer) URL() string { return s.srv.URL }
+
+// Client returns an HTTP client for the server.
+func (s *LinearServer) Client() *http.Client { return s.srv.Client() }
+
+// linearUUID is the issue UUID the fake reports for an identifier.
+func linearUUID(identifier string) string { return "uuid-" + identifier }
+
+// linearLabelID is the label ID the fake reports for a label name.
+func linearLabelID(name string) string { return "label-" + strings.ToLower(name) }
+
+// linearStateID is the workflow state ID the fake reports for a state name.
+func linearStateID(name string) string { return "state-" + strings.ToLower(name) }
+
+// linearState returns the workflow state an issue is in.
+func linearState(issue *Issue) string {
+	switch {
+	case issue.Closed:
+		return "Done"
+	case issue.Section != "":
+		return issue.Section
+	}
+	return "Todo"
+}
+
+func (s *LinearServer) graphql(w http.ResponseWriter, r *http.Request) {
+	if f, ok := s.begin(); ok {
+		if f.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.retryAfter/time.Second)))
+		}
+		graphqlError(w, f.status, http.StatusText(f.status))
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		graphqlError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		graphqlError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	vars := func(name string) string {
+		v, _ := req.Variables[name].(string)
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := req.Query
+	var data map[string]any
+	switch {
+	case strings.Contains(q, "issueLabelCreate"):
+		name := vars("name")
+		if s.teamLabel(name) == "" {
+			s.labels = append(s.labels, name)
+		}
+		data = map[string]any{"issueLabelCreate": map[string]any{"success": true, "issueLabel": map[string]string{"id": linearLabelID(name)}}}
+	case strings.Contains(q, "labels(filter"):
+		nodes := []map[string]string{}
+		if name := s.teamLabel(vars("name")); name != "" {
+			nodes = append(nodes, map[string]string{"id": linearLabelID(name)})
+		}
+		data = map[string]any{"team": map[string]any{"labels": map[string]any{"nodes": nodes}}}
+	case strings.Contains(q, "issueCreate"):
+		issue := s.createIssue(req.Variables)
+		data = map[string]any{"issueCreate": map[string]any{"success": true, "issue": s.issue(issue)}}
+	case strings.Contains(q, "commentCreate"):
+		issue := s.findUUID(vars("issueId"))
+		if issue == nil {
+			data = map[string]any{"commentCreate": map[string]any{"success": false}}
+			break
+		}
+		id := s.addComment(issue.ID, vars("body"))
+		data = map[string]any{"commentCreate": map[string]any{"success": true, "comment": map[string]string{"id": id}}}
+	case strings.Contains(q, "commentUpdate"):
+		data = map[string]any{"commentUpdate": map[string]any{"success": s.editComment(vars("id"), vars("body"), false)}}
+	case strings.Contains(q, "commentDelete"):
+		data = map[string]any{"commentDelete": map[string]any{"success": s.editComment(vars("id"), "", true)}}
+	case strings.Contains(q, "issueUpdate"):
+		data = map[string]any{"issueUpdate": map[string]any{"success": s.updateIssue(vars("id"), req.Variables)}}
+	case strings.Contains(q, "teams"):
+		data = map[string]any{"teams": map[string]any{"nodes": []map[string]string{{"id": "team-1", "name": "Engineering"}}}}
+	case strings.Contains(q, "issues("):
+		data = map[string]any{"team": map[string]any{"issues": s.listIssues(req.Variables)}}
+	case strings.Contains(q, "states"):
+		data = map[string]any{"team": map[string]any{"states": map[string]any{"nodes": s.states()}}}
+	case strings.Contains(q, "children("):
+		var issue any
+		if i := s.find(vars("id")); i != nil {
+			children := []map[string]any{}
+			for _, child := range s.children(i.ID) {
+				if !child.Closed {
+					children = append(children, s.issue(child))
+				}
+			}
+			issue = map[string]any{"children": map[string]any{"nodes": children}}
+		}
+		data = map[string]any{"issue": issue}
+	case strings.Contains(q, "issue("):
+		var issue any
+		if i := s.find(vars("id")); i != nil {
+			issue = s.issue(i)
+		}
+		data = map[string]any{"issue": issue}
+	default:
+		graphqlError(w, http.StatusBadRequest, "issuetest: unsupported query")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func graphqlError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"errors": []map[string]string{{"message": msg}}})
+}
+
+// findUUID returns the issue with the given UUID. The caller holds s.mu.
+func (s *LinearServer) findUUID(uuid string) *Issue {
+	return s.find(strings.TrimPrefix(uuid, "uuid-"))
+}
+
+// editComment updates or deletes a comment. The caller holds s.mu.
+func (s *LinearServer) editComment(id, body string, remove bool) bool {
+	for issueID, comments := range s.comments {
+		for i := range comments {
+			if comments[i].ID != id {
+				continue
+			}
+			if remove {
+				s.comments[issueID] = slices.Delete(comments, i, i+1)
+			} else {
+				comments[i].Body = body
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// teamLabel returns the name of the team label matching name
+// (case-insensitive), or "" if the team has no such label. Labels on seeded
+// issues count as team labels. The caller holds s.mu.
+func (s *LinearServer) teamLabel(name string) string {
+	match := func(l string) bool { return strings.EqualFold(l, name) }
+	if i := slices.IndexFunc(s.labels, match); i >= 0 {
+		return s.labels[i]
+	}
+	for _, issue := range s.issues {
+		if i := slices.IndexFunc(issue.Labels, match); i >= 0 {
+			return issue.Labels[i]
+		}
+	}
+	return ""
+}
+
+// createIssue applies an issueCreate mutation, numbering the issue ENG-n
+// after the highest existing number. The caller holds s.mu.
+func (s *LinearServer) createIssue(vars map[string]any) *Issue {
+	title, _ := vars["title"].(string)
+	description, _ := vars["description"].(string)
+	number := 0
+	for _, existing := range s.issues {
+		if n, err := strconv.Atoi(strings.TrimPrefix(existing.ID, "ENG-")); err == nil && n > number {
+			number = n
+		}
+	}
+	issue := &Issue{ID: "ENG-" + strconv.Itoa(number+1), Title: title, Body: description, CreatedAt: time.Now()}
+	s.issues = append(s.issues, issue)
+	s.updateIssue(linearUUID(issue.ID), map[string]any{"labelIds": vars["labelIds"]})
+	return issue
+}
+
+// updateIssue applies an issueUpdate mutation's labelIds or stateId. The
+// caller holds s.mu.
+func (s *LinearServer) updateIssue(uuid string, vars map[string]any) bool {
+	issue := s.findUUID(uuid)
+	if issue == nil {
+		return false
+	}
+	if ids, ok := vars["labelIds"].([]any); ok {
+		var labels []string
+		for _, id := range ids {
+			idStr, _ := id.(string)
+			name := s.teamLabel(strings.TrimPrefix(idStr, "label-"))
+			if name != "" && !slices.Contains(labels, name) {
+				labels = append(labels, name)
+			}
+		}
+		issue.Labels = labels
+	}
+	if stateID, ok := vars["stateId"].(string); ok {
+		for _, name := range s.states() {
+			if name["id"] == stateID {
+				issue.Section = name["name"]
+				issue.Closed = name["name"] == "Done"
+			}
+		}
+	}
+	return true
+}
+
+// listIssues serves the team issues connection, honouring the label and
+// assignee filters and skipping closed issues. The caller holds s.mu.
+func (s *LinearServer) listIssues(vars map[string]any) map[string]any {
+	label, _ := vars["label"].(string)
+	assignee, _ := vars["assignee"].(string)
+	after, _ := vars["after"].(string)
+	first, _ := vars["first"].(float64)
+
+	nodes, next := s.page(func(i *Issue) bool {
+		if i.Closed {
+			return false
+		}
+		if label != "" && !i.hasLabel(label) {
+			return false
+		}
+		return assignee == "" || strings.EqualFold(i.Assignee, assignee)
+	}, after, int(first))
+
+	rendered := make([]map[string]any, len(nodes))
+	for i, issue := range nodes {
+		rendered[i] = s.issue(issue)
+	}
+	return map[string]any{
+		"nodes":    rendered,
+		"pageInfo": map[string]any{"hasNextPage": next != "", "endCursor": next},
+	}
+}
+
+// states lists the team's workflow states: the defaults plus any state an
+// issue was seeded in. The caller holds s.mu.
+func (s *LinearServer) states() []map[string]string {
+	names := []string{"Todo", "In Progress", "In Review", "Done"}
+	for _, issue := range s.issues {
+		if issue.Section != "" && !slices.Contains(names, issue.Section) {
+			names = append(names, issue.Section)
+		}
+	}
+	states := make([]map[string]string, len(names))
+	for i, name := range names {
+		stateType := "started"
+		switch name {
+		case "Todo":
+			stateType = "unstarted"
+		case "Done":
+			stateType = "completed"
+		}
+		states[i] = map[string]string{"id": linearStateID(name), "name": name, "type": stateType}
+	}
+	return states
+}
+
+// issue renders an issue with every field erg queries. The caller holds
+// s.mu.
+func (s *LinearServer) issue(issue *Issue) map[string]any {
+	labels := make([]map[string]string, len(issue.Labels))
+	for i, l := range issue.Labels {
+		labels[i] = map[string]string{"id": linearLabelID(l), "name": l}
+	}
+	comments := []map[string]any{}
+	for _, c := range s.comments[issue.ID] {
+		stamp := c.CreatedAt.UTC().Format(time.RFC3339)
+		comments = append(comments, map[string]any{
+			"id": c.ID, "body": c.Body, "createdAt": stamp, "updatedAt": stamp,
+			"user": map[string]string{"name": c.Author},
+		})
+	}
+	relations := []map[string]any{}
+	for _, id := range issue.Related {
+		if related := s.find(id); related != nil {
+			relations = append(relations, map[string]any{"type": "related", "relatedIssue": map[string]any{
+				"identifier": related.ID, "title": related.Title, "description": related.Body,
+				"url": s.srv.URL + "/issue/" + related.ID, "state": map[string]string{"name": linearState(related)},
+			}})
+		}
+	}
+	rendered := map[string]any{
+		"id":          linearUUID(issue.ID),
+		"identifier":  issue.ID,
+		"title":       issue.Title,
+		"description": issue.Body,
+		"url":         s.srv.URL + "/issue/" + issue.ID,
+		"priority":    issue.Priority,
+		"createdAt":   issue.CreatedAt.UTC().Format(time.RFC3339),
+		"dueDate":     nil,
+		"labels":      map[string]any{"nodes": labels},
+		"comments":    map[string]any{"nodes": comments},
+		"state":       map[string]string{"name": linearState(issue)},
+		"relations":   map[string]any{"nodes": relations},
+		// Relations are only seeded in one direction.
+		"inverseRelations": map[string]any{"nodes": []map[string]any{}},
+	}
+	if !issue.DueAt.IsZero() {
+		rendered["dueDate"] = issue.DueAt.Format(time.DateOnly)
+	}
+	return rendered
+}
@@ -0,0 +1,275 @@
+// Package labelexpr parses and evaluates label filter expressions such as
+// "ai-ready && !blocked" or "bug || chore".
+//
+// Grammar, loosest binding first:
+//
+//	expr   = and { "||" and }
+//	and    = unary { "&&" unary }
+//	unary  = "!" unary | "(" expr ")" | label
+//	label  = bare text up to the next operator or parenthesis (trimmed,
+//	         inner spaces kept) | a double-quoted string
+//
+// A plain label such as "ai-assisted" is itself a valid expression. Labels
+// are matched case-insensitively.
+package labelexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a parsed label expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses a label expression.
+func Parse(s string) (*Expr, error) {
+	p := &parser{src: s}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.toks) == 0 {
+		return nil, fmt.Errorf("empty label expression")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %s in label expression %q", p.toks[p.pos], s)
+	}
+	return &Expr{src: s, root: root}, nil
+}
+
+// String returns the expression as written.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match reports whether an issue carrying labels satisfies the expression.
+func (e *Expr) Match(labels []string) bool {
+	return e.root.eval(func(name string) bool {
+		for _, l := range labels {
+			if strings.EqualFold(l, name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Simple reports whether the expression is a single label with no operators.
+func (e *Expr) Simple() bool {
+	_, ok := e.root.(labelNode)
+	return ok
+}
+
+// Required returns a label that every matching issue carries, for trackers
+// to narrow their fetch server-side, or "" when there is none (for example
+// "bug || chore" or "!blocked").
+func (e *Expr) Required() string {
+	switch n := e.root.(type) {
+	case labelNode:
+		return string(n)
+	case andNode:
+		for _, term := range n {
+			if l, ok := term.(labelNode); ok {
+				return string(l)
+			}
+		}
+	}
+	return ""
+}
+
+type node interface {
+	eval(has func(string) bool) bool
+}
+
+type (
+	labelNode string
+	notNode   struct{ x node }
+	andNode   []node
+	orNode    []node
+)
+
+func (n labelNode) eval(has func(string) bool) bool { return has(string(n)) }
+func (n notNode) eval(has func(string) bool) bool   { return !n.x.eval(has) }
+
+func (n andNode) eval(has func(string) bool) bool {
+	for _, term := range n {
+		if !term.eval(has) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) eval(has func(string) bool) bool {
+	for _, term := range n {
+		if term.eval(has) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokLabel tokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) String() string {
+	if t.kind == tokLabel {
+		return fmt.Sprintf("label %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+// tokenize splits the source into operators and labels. Bare labels run up
+// to the next '&', '|', '(' or ')'; '!' is an operator only where a label
+// would start.
+func (p *parser) tokenize() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "&&"):
+			p.toks = append(p.toks, token{tokAnd, "&&"})
+			i += 2
+		case strings.HasPrefix(s[i:], "||"):
+			p.toks = append(p.toks, token{tokOr, "||"})
+			i += 2
+		case c == '&' || c == '|':
+			return fmt.Errorf("unexpected %q at offset %d in label expression %q (use && or ||)", c, i, s)
+		case c == '!':
+			p.toks = append(p.toks, token{tokNot, "!"})
+			i++
+		case c == '(':
+			p.toks = append(p.toks, token{tokLParen, "("})
+			i++
+		case c == ')':
+			p.toks = append(p.toks, token{tokRParen, ")"})
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return fmt.Errorf("unterminated quote at offset %d in label expression %q", i, s)
+			}
+			p.toks = append(p.toks, token{tokLabel, s[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			end := strings.IndexAny(s[i:], "&|()")
+			if end < 0 {
+				end = len(s) - i
+			}
+			p.toks = append(p.toks, token{tokLabel, strings.TrimSpace(s[i : i+end])})
+			i += end
+		}
+	}
+	return nil
+}
+
+func (p *parser) next() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, true
+}
+
+func (p *parser) accept(kind tokenKind) bool {
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := orNode{first}
+	for p.accept(tokOr) {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := andNode{first}
+	for p.accept(tokAnd) {
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("label expression %q ends where a label was expected", p.src)
+	}
+	switch t.kind {
+	case tokNot:
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(tokRParen) {
+			return nil, fmt.Errorf("missing ')' in label expression %q", p.src)
+		}
+		return x, nil
+	case tokLabel:
+		if t.text == "" {
+			return nil, fmt.Errorf("empty label in label expression %q", p.src)
+		}
+		return labelNode(t.text), nil
+	}
+	return nil, fmt.Errorf("unexpected %s in label expression %q", t, p.src)
+}
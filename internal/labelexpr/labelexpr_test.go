@@ -0,0 +1,81 @@
+package labelexpr
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr   string
+		labels []string
+		want   bool
+	}{
+		{"ai-ready", []string{"AI-Ready"}, true},
+		{"ai-ready", []string{"bug"}, false},
+		{"ai-ready && !blocked", []string{"ai-ready"}, true},
+		{"ai-ready && !blocked", []string{"ai-ready", "Blocked"}, false},
+		{"bug || chore", []string{"chore"}, true},
+		{"bug || chore", []string{"feature"}, false},
+		{"ai && bug || chore", []string{"chore"}, true}, // && binds tighter
+		{"ai && (bug || chore)", []string{"chore"}, false},
+		{"ai && (bug || chore)", []string{"ai", "chore"}, true},
+		{"!!bug", []string{"bug"}, true},
+		{"good first issue && !wontfix", []string{"good first issue"}, true},
+		{`"a&&b" || c`, []string{"a&&b"}, true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := e.Match(tt.labels); got != tt.want {
+			t.Errorf("%q.Match(%v) = %v, want %v", tt.expr, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredAndSimple(t *testing.T) {
+	tests := []struct {
+		expr     string
+		required string
+		simple   bool
+	}{
+		{"ai-ready", "ai-ready", true},
+		{"  ai-ready  ", "ai-ready", true},
+		{"ai-ready && !blocked", "ai-ready", false},
+		{"!blocked && ai-ready", "ai-ready", false},
+		{"bug || chore", "", false},
+		{"!blocked", "", false},
+		{"(bug || chore) && ai", "ai", false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := e.Required(); got != tt.required {
+			t.Errorf("%q.Required() = %q, want %q", tt.expr, got, tt.required)
+		}
+		if got := e.Simple(); got != tt.simple {
+			t.Errorf("%q.Simple() = %v, want %v", tt.expr, got, tt.simple)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"   ",
+		"bug &&",
+		"&& bug",
+		"bug & chore",
+		"bug | chore",
+		"(bug || chore",
+		"bug)",
+		"bug && ()",
+		`"unterminated`,
+		`""`,
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -483,6 +483,17 @@ func (sm *SessionManager) SetRunner(sessionID string, runner claude.RunnerInterf
 	sm.runners[sessionID] = runner
 }
 
+// RemoveRunner stops and forgets a session's runner, keeping the rest of
+// its state, so the next GetOrCreateRunner creates a fresh one.
+func (sm *SessionManager) RemoveRunner(sessionID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if r, exists := sm.runners[sessionID]; exists {
+		r.Stop()
+		delete(sm.runners, sessionID)
+	}
+}
+
 // Shutdown stops all runners gracefully. This should be called when the
 // application is exiting to ensure all Claude CLI processes are terminated
 // and resources are cleaned up.
@@ -348,6 +348,23 @@ func TestSessionManager_SetRunner(t *testing.T) {
 	}
 }
 
+func TestSessionManager_RemoveRunner(t *testing.T) {
+	cfg := createTestConfig()
+	sm := NewSessionManager(cfg, git.NewGitService())
+
+	runner := claude.NewMockRunner("session-1", false, nil)
+	sm.SetRunner("session-1", runner)
+	sm.RemoveRunner("session-1")
+
+	if sm.GetRunner("session-1") != nil {
+		t.Error("RemoveRunner should forget the runner")
+	}
+	if runner.PermissionRequestChan() != nil {
+		t.Error("RemoveRunner should stop the runner")
+	}
+	sm.RemoveRunner("session-1") // no runner: no-op
+}
+
 func TestSessionManager_AddAllowedTool(t *testing.T) {
 	cfg := createTestConfig()
 	sm := NewSessionManager(cfg, git.NewGitService())
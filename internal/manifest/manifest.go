@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 
+	"github.com/zhubert/erg/internal/workflow"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,12 +14,23 @@ import (
 type Manifest struct {
 	MaxConcurrent int         `yaml:"max_concurrent,omitempty"`
 	Repos         []RepoEntry `yaml:"repos"`
+	// Budget caps the daily and weekly session spend across all repos, on
+	// top of each repo workflow's settings.budget.
+	Budget *workflow.BudgetConfig `yaml:"budget,omitempty"`
+	// Limits caps concurrent sessions per toolchain across all repos, on
+	// top of each repo workflow's settings.limits, and concurrent calls
+	// per tracker API.
+	Limits workflow.LimitsConfig `yaml:"limits,omitempty"`
 }
 
 // RepoEntry associates a repo with its workflow config file.
 type RepoEntry struct {
 	Path     string `yaml:"path"`
 	Workflow string `yaml:"workflow,omitempty"`
+	// MaxConcurrent caps the sessions this repo may run at once, within the
+	// manifest's global limit. Zero falls back to the repo workflow's
+	// settings.max_concurrent.
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
 }
 
 // LoadFile reads and parses a manifest from the given file path.
@@ -41,6 +53,16 @@ func LoadFile(path string) (*Manifest, error) {
 		if entry.Path == "" {
 			return nil, fmt.Errorf("manifest repos[%d]: path is required", i)
 		}
+		if entry.MaxConcurrent < 0 {
+			return nil, fmt.Errorf("manifest repos[%d]: max_concurrent must not be negative", i)
+		}
+	}
+
+	if errs := workflow.ValidateBudget("budget", m.Budget); len(errs) > 0 {
+		return nil, fmt.Errorf("manifest %s: %s", errs[0].Field, errs[0].Message)
+	}
+	if errs := workflow.ValidateLimits("limits", m.Limits); len(errs) > 0 {
+		return nil, fmt.Errorf("manifest %s: %s", errs[0].Field, errs[0].Message)
 	}
 
 	return &m, nil
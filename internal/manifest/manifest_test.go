@@ -41,6 +41,75 @@ repos:
 		}
 	})
 
+	t.Run("per-repo max_concurrent", func(t *testing.T) {
+		dir := t.TempDir()
+		fp := filepath.Join(dir, "manifest.yaml")
+		content := `
+max_concurrent: 4
+repos:
+  - path: owner/repo-a
+    max_concurrent: 1
+  - path: owner/repo-b
+`
+		os.WriteFile(fp, []byte(content), 0o644)
+
+		m, err := LoadFile(fp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m.Repos[0].MaxConcurrent != 1 || m.Repos[1].MaxConcurrent != 0 {
+			t.Errorf("unexpected per-repo limits: %+v", m.Repos)
+		}
+	})
+
+	t.Run("negative per-repo max_concurrent", func(t *testing.T) {
+		dir := t.TempDir()
+		fp := filepath.Join(dir, "manifest.yaml")
+		os.WriteFile(fp, []byte("repos:\n  - path: owner/repo\n    max_concurrent: -1\n"), 0o644)
+
+		if _, err := LoadFile(fp); err == nil {
+			t.Fatal("expected error for negative max_concurrent")
+		}
+	})
+
+	t.Run("global budget", func(t *testing.T) {
+		dir := t.TempDir()
+		fp := filepath.Join(dir, "manifest.yaml")
+		os.WriteFile(fp, []byte("budget:\n  daily_usd: 25\n  on_exceeded: pause\nrepos:\n  - path: owner/repo\n"), 0o644)
+
+		m, err := LoadFile(fp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m.Budget == nil || m.Budget.DailyUSD != 25 || m.Budget.OnExceeded != "pause" {
+			t.Errorf("unexpected budget: %+v", m.Budget)
+		}
+
+		os.WriteFile(fp, []byte("budget:\n  on_exceeded: halt\nrepos:\n  - path: owner/repo\n"), 0o644)
+		if _, err := LoadFile(fp); err == nil {
+			t.Fatal("expected error for invalid on_exceeded")
+		}
+	})
+
+	t.Run("global limits", func(t *testing.T) {
+		dir := t.TempDir()
+		fp := filepath.Join(dir, "manifest.yaml")
+		os.WriteFile(fp, []byte("limits:\n  java: 1\n  default: 4\n  github_api_concurrency: 2\nrepos:\n  - path: owner/repo\n"), 0o644)
+
+		m, err := LoadFile(fp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m.Limits.Toolchain("java") != 1 || m.Limits.Toolchain("go") != 4 || m.Limits.APIConcurrency("github") != 2 {
+			t.Errorf("unexpected limits: %+v", m.Limits)
+		}
+
+		os.WriteFile(fp, []byte("limits:\n  rust: -2\nrepos:\n  - path: owner/repo\n"), 0o644)
+		if _, err := LoadFile(fp); err == nil {
+			t.Fatal("expected error for a negative limit")
+		}
+	})
+
 	t.Run("empty repos", func(t *testing.T) {
 		dir := t.TempDir()
 		fp := filepath.Join(dir, "manifest.yaml")
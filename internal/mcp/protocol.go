@@ -87,8 +87,10 @@ type InputSchema struct {
 
 // Property represents a property in the input schema
 type Property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Items       *Property `json:"items,omitempty"` // Element schema for array properties
+	Enum        []string  `json:"enum,omitempty"`  // Allowed values for string properties
 }
 
 // ToolCallParams represents parameters for tools/call
@@ -253,3 +255,25 @@ type SubmitReviewResponse struct {
 	Success bool   `json:"success"`         // Whether result was stored successfully
 	Error   string `json:"error,omitempty"` // Error message if storing failed
 }
+
+// SubmitResultRequest represents the structured result envelope an automated
+// session emits at the end of a workflow state
+type SubmitResultRequest struct {
+	ID           any      `json:"id"`                      // JSON-RPC request ID for response correlation
+	Status       string   `json:"status"`                  // success, partial, failed, or blocked
+	Summary      string   `json:"summary"`                 // Brief summary of what was done
+	FilesChanged []string `json:"files_changed,omitempty"` // Paths of files the session modified
+	FollowUps    []string `json:"follow_ups,omitempty"`    // Work left for later states or humans
+	Confidence   float64  `json:"confidence"`              // Self-assessed confidence in [0, 1]
+	Learnings    []string `json:"learnings,omitempty"`     // Notes for the repository knowledge base
+	Plan         string   `json:"plan,omitempty"`          // Approach taken or proposed
+	RiskLevel    string   `json:"risk_level,omitempty"`    // low, medium, or high
+	Risks        []string `json:"risks,omitempty"`         // What the changes could break
+}
+
+// SubmitResultResponse represents the result of submitting a result envelope
+type SubmitResultResponse struct {
+	ID      any    `json:"id"`              // Correlates with request ID
+	Success bool   `json:"success"`         // Whether result was stored successfully
+	Error   string `json:"error,omitempty"` // Error message if storing failed
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/zhubert/erg/internal/logger"
 	"github.com/zhubert/erg/internal/paths"
+	"github.com/zhubert/erg/internal/toolpolicy"
 )
 
 // MCP server timeout constants
@@ -63,6 +64,8 @@ type Server struct {
 	commentIssueResp      <-chan CommentIssueResponse      // Receive comment issue responses from host
 	submitReviewChan      chan<- SubmitReviewRequest       // Send submit review requests to host
 	submitReviewResp      <-chan SubmitReviewResponse      // Receive submit review responses from host
+	submitResultChan      chan<- SubmitResultRequest       // Send submit result requests to host
+	submitResultResp      <-chan SubmitResultResponse      // Receive submit result responses from host
 	mu                    sync.Mutex
 	log                   *slog.Logger // Logger with session context
 }
@@ -80,13 +83,14 @@ func WithDenyUnlisted() ServerOption {
 	}
 }
 
-// WithHostTools enables host operation tools (create_pr, push_branch, get_review_comments, comment_issue, submit_review)
+// WithHostTools enables host operation tools (create_pr, push_branch, get_review_comments, comment_issue, submit_review, submit_result)
 func WithHostTools(
 	createPRChan chan<- CreatePRRequest, createPRResp <-chan CreatePRResponse,
 	pushBranchChan chan<- PushBranchRequest, pushBranchResp <-chan PushBranchResponse,
 	getReviewCommentsChan chan<- GetReviewCommentsRequest, getReviewCommentsResp <-chan GetReviewCommentsResponse,
 	commentIssueChan chan<- CommentIssueRequest, commentIssueResp <-chan CommentIssueResponse,
 	submitReviewChan chan<- SubmitReviewRequest, submitReviewResp <-chan SubmitReviewResponse,
+	submitResultChan chan<- SubmitResultRequest, submitResultResp <-chan SubmitResultResponse,
 ) ServerOption {
 	return func(s *Server) {
 		s.hasHostTools = true
@@ -100,6 +104,8 @@ func WithHostTools(
 		s.commentIssueResp = commentIssueResp
 		s.submitReviewChan = submitReviewChan
 		s.submitReviewResp = submitReviewResp
+		s.submitResultChan = submitResultChan
+		s.submitResultResp = submitResultResp
 	}
 }
 
@@ -216,77 +222,136 @@ func (s *Server) handleToolsList(req *JSONRPCRequest) {
 	}
 
 	if s.hasHostTools {
-		tools = append(tools,
-			ToolDefinition{
-				Name:        "create_pr",
-				Description: "Create a pull request for the current branch. Commits any uncommitted changes, pushes the branch to the remote, and creates a PR via GitHub CLI. This runs on the host machine.",
-				InputSchema: InputSchema{
-					Type: "object",
-					Properties: map[string]Property{
-						"title": {
-							Type:        "string",
-							Description: "Optional PR title. If not provided, a title and body will be auto-generated.",
-						},
+		tools = append(tools, HostToolDefinitions()...)
+	}
+
+	s.sendResult(req.ID, ToolsListResult{Tools: tools})
+}
+
+// HostToolDefinitions returns the tools an autonomous session calls to act
+// on the host: creating PRs, pushing, reading review comments, commenting
+// on the issue and submitting review and step results.
+func HostToolDefinitions() []ToolDefinition {
+	return []ToolDefinition{
+		{
+			Name:        "create_pr",
+			Description: "Create a pull request for the current branch. Commits any uncommitted changes, pushes the branch to the remote, and creates a PR via GitHub CLI. This runs on the host machine.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"title": {
+						Type:        "string",
+						Description: "Optional PR title. If not provided, a title and body will be auto-generated.",
 					},
 				},
 			},
-			ToolDefinition{
-				Name:        "push_branch",
-				Description: "Commit any uncommitted changes and push the current branch to the remote. This runs on the host machine.",
-				InputSchema: InputSchema{
-					Type: "object",
-					Properties: map[string]Property{
-						"commit_message": {
-							Type:        "string",
-							Description: "Optional commit message. If not provided, a default message will be used.",
-						},
+		},
+		{
+			Name:        "push_branch",
+			Description: "Commit any uncommitted changes and push the current branch to the remote. This runs on the host machine.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"commit_message": {
+						Type:        "string",
+						Description: "Optional commit message. If not provided, a default message will be used.",
 					},
 				},
 			},
-			ToolDefinition{
-				Name:        "get_review_comments",
-				Description: "Fetch all review comments from the pull request for the current branch. Returns top-level PR comments, review body comments, and inline code review comments. This runs on the host machine.",
-				InputSchema: InputSchema{
-					Type:       "object",
-					Properties: map[string]Property{},
+		},
+		{
+			Name:        "get_review_comments",
+			Description: "Fetch all review comments from the pull request for the current branch. Returns top-level PR comments, review body comments, and inline code review comments. This runs on the host machine.",
+			InputSchema: InputSchema{
+				Type:       "object",
+				Properties: map[string]Property{},
+			},
+		},
+		{
+			Name:        "comment_issue",
+			Description: "Post a comment on the tracked issue (e.g. a plan or status update). This runs on the host machine via the daemon's git service.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"body": {
+						Type:        "string",
+						Description: "The comment body in markdown format.",
+					},
 				},
+				Required: []string{"body"},
 			},
-			ToolDefinition{
-				Name:        "comment_issue",
-				Description: "Post a comment on the tracked issue (e.g. a plan or status update). This runs on the host machine via the daemon's git service.",
-				InputSchema: InputSchema{
-					Type: "object",
-					Properties: map[string]Property{
-						"body": {
-							Type:        "string",
-							Description: "The comment body in markdown format.",
-						},
+		},
+		{
+			Name:        "submit_review",
+			Description: "Submit a review result (passed/failed with summary). This stores the result in the daemon's work item data for downstream workflow processing.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"passed": {
+						Type:        "boolean",
+						Description: "Whether the review passed (true) or found blocking issues (false).",
+					},
+					"summary": {
+						Type:        "string",
+						Description: "Brief one-sentence summary of the review findings.",
 					},
-					Required: []string{"body"},
 				},
+				Required: []string{"passed", "summary"},
 			},
-			ToolDefinition{
-				Name:        "submit_review",
-				Description: "Submit a review result (passed/failed with summary). This stores the result in the daemon's work item data for downstream workflow processing.",
-				InputSchema: InputSchema{
-					Type: "object",
-					Properties: map[string]Property{
-						"passed": {
-							Type:        "boolean",
-							Description: "Whether the review passed (true) or found blocking issues (false).",
-						},
-						"summary": {
-							Type:        "string",
-							Description: "Brief one-sentence summary of the review findings.",
-						},
+		},
+		{
+			Name:        "submit_result",
+			Description: "Submit the structured result of the current workflow step. Call this exactly once when you finish. The workflow engine uses the status to decide what happens next.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"status": {
+						Type:        "string",
+						Description: "Outcome of the step: \"success\", \"partial\", \"failed\", or \"blocked\".",
+						Enum:        []string{"success", "partial", "failed", "blocked"},
+					},
+					"summary": {
+						Type:        "string",
+						Description: "Brief summary of what was done.",
+					},
+					"files_changed": {
+						Type:        "array",
+						Description: "Paths of files you modified, relative to the repository root.",
+						Items:       &Property{Type: "string"},
+					},
+					"follow_ups": {
+						Type:        "array",
+						Description: "Remaining work or open questions for later steps or humans.",
+						Items:       &Property{Type: "string"},
+					},
+					"confidence": {
+						Type:        "number",
+						Description: "Your confidence that the step is complete and correct, from 0 to 1.",
+					},
+					"plan": {
+						Type:        "string",
+						Description: "The implementation plan you followed or propose, as numbered steps.",
+					},
+					"risk_level": {
+						Type:        "string",
+						Description: "How likely your changes are to break something: \"low\", \"medium\", or \"high\".",
+						Enum:        []string{"low", "medium", "high"},
+					},
+					"risks": {
+						Type:        "array",
+						Description: "Specific things your changes could break, for reviewers to check.",
+						Items:       &Property{Type: "string"},
+					},
+					"learnings": {
+						Type:        "array",
+						Description: "Durable notes about this repository worth remembering in future sessions, each prefixed with its section: \"architecture:\", \"modules:\", \"conventions:\", or \"gotchas:\". Omit anything already in the knowledge base.",
+						Items:       &Property{Type: "string"},
 					},
-					Required: []string{"passed", "summary"},
 				},
+				Required: []string{"status", "summary"},
 			},
-		)
+		},
 	}
-
-	s.sendResult(req.ID, ToolsListResult{Tools: tools})
 }
 
 func (s *Server) handleToolsCall(req *JSONRPCRequest) {
@@ -310,6 +375,8 @@ func (s *Server) handleToolsCall(req *JSONRPCRequest) {
 		s.handleCommentIssue(req, params)
 	case "submit_review":
 		s.handleSubmitReview(req, params)
+	case "submit_result":
+		s.handleSubmitResult(req, params)
 	default:
 		s.log.Warn("unknown tool", "tool", params.Name)
 		s.sendError(req.ID, -32602, "Unknown tool", nil)
@@ -372,6 +439,18 @@ func (s *Server) handlePermissionToolCall(req *JSONRPCRequest, params ToolCallPa
 		return
 	}
 
+	// In deny-unlisted mode a shell command must match the session's command
+	// rules too: an allowed "Bash(go test:*)" pre-allows the tool, not every
+	// command, and the repo's tool policy may deny commands outright.
+	if s.denyUnlisted && tool == "Bash" {
+		command, _ := arguments["command"].(string)
+		if err := toolpolicy.CheckCommand(command, s.allowedToolsSnapshot(), nil); err != nil {
+			s.log.Warn("denied command", "command", command, "reason", err)
+			s.sendPermissionResult(req.ID, false, arguments, err.Error())
+			return
+		}
+	}
+
 	// Check if tool is pre-allowed
 	if s.isToolAllowed(tool) {
 		s.log.Debug("tool is pre-allowed", "tool", tool)
@@ -683,6 +762,52 @@ func (s *Server) handleSubmitReview(req *JSONRPCRequest, params ToolCallParams)
 		func(r SubmitReviewResponse) bool { return !r.Success }, "review submission")
 }
 
+// handleSubmitResult handles the submit_result host tool
+func (s *Server) handleSubmitResult(req *JSONRPCRequest, params ToolCallParams) {
+	if !s.hasHostTools || s.submitResultChan == nil {
+		s.sendToolResult(req.ID, true, `{"error":"submit_result is only available in automated sessions"}`)
+		return
+	}
+
+	status, _ := params.Arguments["status"].(string)
+	summary, _ := params.Arguments["summary"].(string)
+	confidence, _ := params.Arguments["confidence"].(float64)
+	plan, _ := params.Arguments["plan"].(string)
+	riskLevel, _ := params.Arguments["risk_level"].(string)
+
+	s.log.Info("submit_result called", "status", status, "confidence", confidence)
+
+	handleToolChannelRequest(s, req.ID, SubmitResultRequest{
+		ID:           req.ID,
+		Status:       status,
+		Summary:      summary,
+		FilesChanged: stringSliceArg(params.Arguments["files_changed"]),
+		FollowUps:    stringSliceArg(params.Arguments["follow_ups"]),
+		Confidence:   confidence,
+		Learnings:    stringSliceArg(params.Arguments["learnings"]),
+		Plan:         plan,
+		RiskLevel:    riskLevel,
+		Risks:        stringSliceArg(params.Arguments["risks"]),
+	}, s.submitResultChan, s.submitResultResp, HostToolReceiveTimeout,
+		func(r SubmitResultResponse) bool { return !r.Success }, "result submission")
+}
+
+// stringSliceArg converts a JSON array tool argument into a []string,
+// skipping non-string elements. Returns nil for missing or non-array values.
+func stringSliceArg(v any) []string {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(arr))
+	for _, e := range arr {
+		if str, ok := e.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
 // sendToolResult sends a tool call result with text content.
 // Host tools return regular tool results (not PermissionResult format).
 func (s *Server) sendToolResult(id any, isError bool, text string) {
@@ -721,6 +846,13 @@ func (s *Server) isToolAllowed(tool string) bool {
 	return false
 }
 
+// allowedToolsSnapshot returns a copy of the session's pre-allowed tools.
+func (s *Server) allowedToolsSnapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.allowedTools)
+}
+
 // hostMCPTools are the Claude CLI tool names for host operation MCP tools.
 var hostMCPTools = []string{
 	"mcp__erg__create_pr",
@@ -728,6 +860,7 @@ var hostMCPTools = []string{
 	"mcp__erg__get_review_comments",
 	"mcp__erg__comment_issue",
 	"mcp__erg__submit_review",
+	"mcp__erg__submit_result",
 }
 
 // isOwnMCPTool returns true if the tool name is one of our own MCP tools that
@@ -1094,9 +1094,11 @@ func TestServer_handleToolsList_HostTools(t *testing.T) {
 		commentIssueResp := make(chan CommentIssueResponse, 1)
 		submitReviewChan := make(chan SubmitReviewRequest, 1)
 		submitReviewResp := make(chan SubmitReviewResponse, 1)
+		submitResultChan := make(chan SubmitResultRequest, 1)
+		submitResultResp := make(chan SubmitResultResponse, 1)
 
 		s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil, nil, "test",
-			WithHostTools(createPRChan, createPRResp, pushBranchChan, pushBranchResp, getReviewCommentsChan, getReviewCommentsResp, commentIssueChan, commentIssueResp, submitReviewChan, submitReviewResp))
+			WithHostTools(createPRChan, createPRResp, pushBranchChan, pushBranchResp, getReviewCommentsChan, getReviewCommentsResp, commentIssueChan, commentIssueResp, submitReviewChan, submitReviewResp, submitResultChan, submitResultResp))
 
 		if !s.hasHostTools {
 			t.Error("server should have host tools")
@@ -1145,7 +1147,8 @@ func TestServer_handleCreatePR(t *testing.T) {
 		s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil, nil, "test",
 			WithHostTools(createPRChan, createPRResp, pushBranchChan, pushBranchResp, getReviewCommentsChan, getReviewCommentsResp,
 				make(chan CommentIssueRequest, 1), make(chan CommentIssueResponse, 1),
-				make(chan SubmitReviewRequest, 1), make(chan SubmitReviewResponse, 1)))
+				make(chan SubmitReviewRequest, 1), make(chan SubmitReviewResponse, 1),
+				make(chan SubmitResultRequest, 1), make(chan SubmitResultResponse, 1)))
 
 		go func() {
 			req := <-createPRChan
@@ -1202,7 +1205,8 @@ func TestServer_handlePushBranch(t *testing.T) {
 		s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil, nil, "test",
 			WithHostTools(createPRChan, createPRResp, pushBranchChan, pushBranchResp, getReviewCommentsChan, getReviewCommentsResp,
 				make(chan CommentIssueRequest, 1), make(chan CommentIssueResponse, 1),
-				make(chan SubmitReviewRequest, 1), make(chan SubmitReviewResponse, 1)))
+				make(chan SubmitReviewRequest, 1), make(chan SubmitReviewResponse, 1),
+				make(chan SubmitResultRequest, 1), make(chan SubmitResultResponse, 1)))
 
 		go func() {
 			req := <-pushBranchChan
@@ -1306,6 +1310,41 @@ func TestServer_DenyUnlisted(t *testing.T) {
 		}
 	})
 
+	t.Run("checks Bash commands against command rules", func(t *testing.T) {
+		tests := []struct {
+			command string
+			allow   bool
+		}{
+			{"go test ./...", true},
+			{"make build && go test ./...", true},
+			{"curl https://example.com", false},
+			{"go test ./... && npm publish", false},
+		}
+		for _, tt := range tests {
+			var buf strings.Builder
+			s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil,
+				[]string{"Read", "Bash(go test:*)", "Bash(make:*)"}, "test", WithDenyUnlisted())
+
+			req := &JSONRPCRequest{JSONRPC: "2.0", ID: "1"}
+			params := ToolCallParams{
+				Name: ToolName,
+				Arguments: map[string]any{
+					"tool_name": "Bash",
+					"input":     map[string]any{"command": tt.command},
+				},
+			}
+			s.handlePermissionToolCall(req, params)
+
+			output := buf.String()
+			if got := !strings.Contains(output, "deny"); got != tt.allow {
+				t.Errorf("%q: allowed = %v, want %v; output: %s", tt.command, got, tt.allow, output)
+			}
+			if !tt.allow && !strings.Contains(output, "tool policy") {
+				t.Errorf("%q: expected the refusal to name the tool policy, got: %s", tt.command, output)
+			}
+		}
+	})
+
 	t.Run("allows host MCP tools even when denyUnlisted", func(t *testing.T) {
 		var buf strings.Builder
 		createPRChan := make(chan CreatePRRequest, 1)
@@ -1325,7 +1364,8 @@ func TestServer_DenyUnlisted(t *testing.T) {
 			WithHostTools(createPRChan, createPRResp, pushBranchChan, pushBranchResp,
 				getReviewCommentsChan, getReviewCommentsResp,
 				commentIssueChan, commentIssueResp,
-				submitReviewChan, submitReviewResp))
+				submitReviewChan, submitReviewResp,
+				make(chan SubmitResultRequest, 1), make(chan SubmitResultResponse, 1)))
 
 		req := &JSONRPCRequest{JSONRPC: "2.0", ID: "1"}
 		params := ToolCallParams{
@@ -1429,3 +1469,77 @@ func TestServer_sendToolResult(t *testing.T) {
 		}
 	})
 }
+
+func TestServer_handleSubmitResult(t *testing.T) {
+	logger.Init(os.DevNull)
+	defer logger.Reset()
+
+	t.Run("rejects when host tools not enabled", func(t *testing.T) {
+		var buf strings.Builder
+		s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil, nil, "test")
+
+		req := &JSONRPCRequest{JSONRPC: "2.0", ID: "1"}
+		s.handleSubmitResult(req, ToolCallParams{Name: "submit_result", Arguments: map[string]any{}})
+
+		if !strings.Contains(buf.String(), "only available in automated sessions") {
+			t.Errorf("expected error about host tools, got: %s", buf.String())
+		}
+	})
+
+	t.Run("forwards parsed envelope to channel", func(t *testing.T) {
+		var buf strings.Builder
+		submitResultChan := make(chan SubmitResultRequest, 1)
+		submitResultResp := make(chan SubmitResultResponse, 1)
+
+		s := NewServer(strings.NewReader(""), &buf, nil, nil, nil, nil, nil, nil, nil, "test",
+			WithHostTools(make(chan CreatePRRequest, 1), make(chan CreatePRResponse, 1),
+				make(chan PushBranchRequest, 1), make(chan PushBranchResponse, 1),
+				make(chan GetReviewCommentsRequest, 1), make(chan GetReviewCommentsResponse, 1),
+				make(chan CommentIssueRequest, 1), make(chan CommentIssueResponse, 1),
+				make(chan SubmitReviewRequest, 1), make(chan SubmitReviewResponse, 1),
+				submitResultChan, submitResultResp))
+
+		got := make(chan SubmitResultRequest, 1)
+		go func() {
+			req := <-submitResultChan
+			got <- req
+			submitResultResp <- SubmitResultResponse{ID: req.ID, Success: true}
+		}()
+
+		req := &JSONRPCRequest{JSONRPC: "2.0", ID: "1"}
+		s.handleSubmitResult(req, ToolCallParams{
+			Name: "submit_result",
+			Arguments: map[string]any{
+				"status":        "success",
+				"summary":       "Implemented feature",
+				"files_changed": []any{"main.go", 7, "main_test.go"},
+				"follow_ups":    []any{"update docs"},
+				"confidence":    0.8,
+				"learnings":     []any{"conventions: errors wrap with %w"},
+				"plan":          "1. Add the flag.",
+				"risk_level":    "medium",
+				"risks":         []any{"changes the default timeout"},
+			},
+		})
+
+		r := <-got
+		if r.Status != "success" || r.Summary != "Implemented feature" || r.Confidence != 0.8 {
+			t.Errorf("unexpected request: %+v", r)
+		}
+		if len(r.FilesChanged) != 2 || r.FilesChanged[1] != "main_test.go" {
+			t.Errorf("files_changed = %v, want [main.go main_test.go]", r.FilesChanged)
+		}
+		if len(r.FollowUps) != 1 {
+			t.Errorf("follow_ups = %v", r.FollowUps)
+		}
+		if len(r.Learnings) != 1 {
+			t.Errorf("learnings = %v", r.Learnings)
+		}
+		if r.Plan != "1. Add the flag." || r.RiskLevel != "medium" || len(r.Risks) != 1 {
+			t.Errorf("unexpected plan and risk assessment: %+v", r)
+		}
+		if strings.Contains(buf.String(), `"isError":true`) {
+			t.Errorf("expected success result, got: %s", buf.String())
+		}
+	})
+}
@@ -56,6 +56,7 @@ const (
 	MessageTypeGetReviewComments MessageType = "getReviewComments"
 	MessageTypeCommentIssue      MessageType = "commentIssue"
 	MessageTypeSubmitReview      MessageType = "submitReview"
+	MessageTypeSubmitResult      MessageType = "submitResult"
 )
 
 // SocketMessage wraps permission, question, plan approval, or host tool requests/responses
@@ -77,6 +78,8 @@ type SocketMessage struct {
 	CommentIssueResp      *CommentIssueResponse      `json:"commentIssueResp,omitempty"`
 	SubmitReviewReq       *SubmitReviewRequest       `json:"submitReviewReq,omitempty"`
 	SubmitReviewResp      *SubmitReviewResponse      `json:"submitReviewResp,omitempty"`
+	SubmitResultReq       *SubmitResultRequest       `json:"submitResultReq,omitempty"`
+	SubmitResultResp      *SubmitResultResponse      `json:"submitResultResp,omitempty"`
 }
 
 // SocketServer listens for permission requests from MCP server subprocesses
@@ -100,6 +103,8 @@ type SocketServer struct {
 	commentIssueResp      <-chan CommentIssueResponse
 	submitReviewReq       chan<- SubmitReviewRequest
 	submitReviewResp      <-chan SubmitReviewResponse
+	submitResultReq       chan<- SubmitResultRequest
+	submitResultResp      <-chan SubmitResultResponse
 	closed                bool           // Set to true when Close() is called
 	closedMu              sync.RWMutex   // Guards closed flag
 	wg                    sync.WaitGroup // Tracks the Run() goroutine for clean shutdown
@@ -182,6 +187,7 @@ func WithHostToolChannels(
 	getReviewCommentsReq chan<- GetReviewCommentsRequest, getReviewCommentsResp <-chan GetReviewCommentsResponse,
 	commentIssueReq chan<- CommentIssueRequest, commentIssueResp <-chan CommentIssueResponse,
 	submitReviewReq chan<- SubmitReviewRequest, submitReviewResp <-chan SubmitReviewResponse,
+	submitResultReq chan<- SubmitResultRequest, submitResultResp <-chan SubmitResultResponse,
 ) SocketServerOption {
 	return func(s *SocketServer) {
 		s.createPRReq = createPRReq
@@ -194,6 +200,8 @@ func WithHostToolChannels(
 		s.commentIssueResp = commentIssueResp
 		s.submitReviewReq = submitReviewReq
 		s.submitReviewResp = submitReviewResp
+		s.submitResultReq = submitResultReq
+		s.submitResultResp = submitResultResp
 	}
 }
 
@@ -504,6 +512,18 @@ func (s *SocketServer) handleConnection(conn net.Conn) {
 				MessageTypeSubmitReview,
 				func(m *SocketMessage, r *SubmitReviewResponse) { m.SubmitReviewResp = r },
 				"submit review")
+		case MessageTypeSubmitResult:
+			handleChannelMessage(s.log, conn, msg.SubmitResultReq,
+				s.submitResultReq, s.submitResultResp,
+				HostToolResponseTimeout,
+				SubmitResultResponse{Success: false, Error: "Host tools not available"},
+				func(id any) SubmitResultResponse {
+					return SubmitResultResponse{ID: id, Success: false, Error: "Timeout"}
+				},
+				func(r *SubmitResultRequest) any { return r.ID },
+				MessageTypeSubmitResult,
+				func(m *SocketMessage, r *SubmitResultResponse) { m.SubmitResultResp = r },
+				"submit result")
 		default:
 			s.log.Warn("unknown message type", "type", msg.Type)
 		}
@@ -667,6 +687,14 @@ func (c *SocketClient) SendSubmitReviewRequest(req SubmitReviewRequest) (SubmitR
 		HostToolResponseTimeout, "submit review")
 }
 
+// SendSubmitResultRequest sends a submit result request and waits for response
+func (c *SocketClient) SendSubmitResultRequest(req SubmitResultRequest) (SubmitResultResponse, error) {
+	return sendSocketRequest(c, req, MessageTypeSubmitResult,
+		func(m *SocketMessage, r *SubmitResultRequest) { m.SubmitResultReq = r },
+		func(m *SocketMessage) *SubmitResultResponse { return m.SubmitResultResp },
+		HostToolResponseTimeout, "submit result")
+}
+
 // Close closes the client connection
 func (c *SocketClient) Close() error {
 	return c.conn.Close()
@@ -8,10 +8,15 @@ import (
 // IssueRef represents a reference to an issue/task from any supported source.
 // This is the generic replacement for the deprecated IssueNumber field.
 type IssueRef struct {
-	Source string `json:"source"` // "github", "asana", or "linear"
-	ID     string `json:"id"`     // Issue/task ID (number for GitHub, GID for Asana)
-	Title  string `json:"title"`  // Issue/task title for display
-	URL    string `json:"url"`    // Link to the issue/task
+	Source    string    `json:"source"`              // "github", "asana", or "linear"
+	ID        string    `json:"id"`                  // Issue/task ID (number for GitHub, GID for Asana)
+	Title     string    `json:"title"`               // Issue/task title for display
+	URL       string    `json:"url"`                 // Link to the issue/task
+	Epic      string    `json:"epic,omitempty"`      // Parent epic issue ID in the same tracker, if referenced
+	Priority  int       `json:"priority,omitempty"`  // Tracker priority, 1 (urgent) to 4 (low); 0 = none
+	CreatedAt time.Time `json:"created_at,omitzero"` // When the issue was opened in the tracker
+	Labels    []string  `json:"labels,omitempty"`    // Issue labels at the time it was queued
+	DueAt     time.Time `json:"due_at,omitzero"`     // Tracker due date, if any
 }
 
 // Session represents a Claude Code conversation session with its own worktree
@@ -194,6 +194,16 @@ func SessionsDir() (string, error) {
 	return filepath.Join(dir, "sessions"), nil
 }
 
+// ConversationsDir returns the directory containerized sessions' Claude
+// CLI transcripts persist in between containers.
+func ConversationsDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "conversations"), nil
+}
+
 // LogsDir returns the directory for log files.
 func LogsDir() (string, error) {
 	dir, err := StateDir()
@@ -203,6 +213,15 @@ func LogsDir() (string, error) {
 	return filepath.Join(dir, "logs"), nil
 }
 
+// ResponseCacheDir returns the directory cached Claude responses are kept in.
+func ResponseCacheDir() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "response-cache"), nil
+}
+
 // WorktreesDir returns the directory for centralized git worktrees.
 func WorktreesDir() (string, error) {
 	dir, err := DataDir()
@@ -230,6 +249,16 @@ func ManifestPath() (string, error) {
 	return filepath.Join(dir, "daemon.yaml"), nil
 }
 
+// StandardsPath returns the path of the global coding standards document,
+// which sits beside the daemon manifest.
+func StandardsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "standards.md"), nil
+}
+
 // ClaudeConfigDir returns the Claude Code configuration directory.
 // If CLAUDE_CONFIG_DIR is set, it returns that value (converted to an absolute path).
 // A leading ~ or ~/ is expanded to the user's home directory.
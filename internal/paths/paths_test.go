@@ -265,6 +265,14 @@ func TestDerivedPaths(t *testing.T) {
 			t.Errorf("SessionsDir = %q, want %q", sessDir, want)
 		}
 
+		convDir, err := ConversationsDir()
+		if err != nil {
+			t.Fatalf("ConversationsDir: %v", err)
+		}
+		if want := filepath.Join(xdgData, "erg", "conversations"); convDir != want {
+			t.Errorf("ConversationsDir = %q, want %q", convDir, want)
+		}
+
 		logsDir, err := LogsDir()
 		if err != nil {
 			t.Fatalf("LogsDir: %v", err)
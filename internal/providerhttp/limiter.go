@@ -0,0 +1,67 @@
+package providerhttp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter paces requests to a tracker.
+type Limiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimiter is a token bucket Limiter: it allows bursts of up to burst
+// requests and refills at n requests per period.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // time to earn one token
+	burst    float64
+	tokens   float64
+	last     time.Time
+
+	now   func() time.Time                                 // injectable for testing
+	sleep func(ctx context.Context, d time.Duration) error // injectable for testing
+}
+
+// NewRateLimiter returns a limiter allowing n requests per period, in bursts
+// of up to burst. The bucket starts full.
+func NewRateLimiter(n int, per time.Duration, burst int) *RateLimiter {
+	return &RateLimiter{
+		interval: per / time.Duration(max(n, 1)),
+		burst:    float64(max(burst, 1)),
+		tokens:   float64(max(burst, 1)),
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// Wait implements Limiter. Waiters reserve their token up front, so
+// concurrent callers queue in order rather than racing for refills.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	if err := l.sleep(ctx, delay); err != nil {
+		// Give the unused token back.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package providerhttp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_BurstThenPaced(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	l := NewRateLimiter(60, time.Minute, 2) // one per second, bursts of 2
+	l.now = func() time.Time { return now }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	for range 4 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if len(slept) != 2 || slept[0] != time.Second || slept[1] != 2*time.Second {
+		t.Errorf("waits = %v, want [1s 2s] after a burst of 2", slept)
+	}
+
+	// Time passing refills the bucket, capped at the burst.
+	now = now.Add(time.Hour)
+	slept = nil
+	for range 2 {
+		l.Wait(context.Background())
+	}
+	if len(slept) != 0 {
+		t.Errorf("waits after refill = %v, want none", slept)
+	}
+}
+
+func TestRateLimiter_CanceledWaitReturnsToken(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(1, time.Minute, 1)
+	l.now = func() time.Time { return now }
+	l.sleep = func(ctx context.Context, _ time.Duration) error { return context.Canceled }
+
+	l.Wait(context.Background())
+	if err := l.Wait(context.Background()); err != context.Canceled {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if l.tokens != 0 {
+		t.Errorf("tokens = %v, want the canceled reservation returned", l.tokens)
+	}
+}
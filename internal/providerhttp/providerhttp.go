@@ -0,0 +1,201 @@
+// Package providerhttp is the HTTP layer shared by erg's issue-tracker
+// clients (Asana, Linear, GitLab, ClickUp, generic HTTP, GitHub App token
+// minting).
+//
+// Trackers throttle and hiccup: a 429 or a 502 in the middle of a paginated
+// fetch used to fail the whole poll cycle. Transport retries those responses
+// with exponential backoff, honoring Retry-After, within a per-request time
+// budget, and can pace requests through a per-provider Limiter so erg stays
+// under a tracker's published rate limit in the first place.
+package providerhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy controls how a Transport retries and paces requests.
+type Policy struct {
+	// MaxRetries is how many times a request is retried after its first
+	// attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles on each
+	// later retry.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff.
+	MaxDelay time.Duration
+	// Budget caps the total time one request may spend waiting to be
+	// retried. When the next wait (a backoff or the server's Retry-After)
+	// would exceed what is left, the last response is returned as is, so a
+	// long rate-limit window fails fast instead of stalling the caller.
+	Budget time.Duration
+	// Limiter, if set, paces every attempt, retries included.
+	Limiter Limiter
+}
+
+// DefaultPolicy retries up to three times (waiting 250ms, 500ms, 1s) within
+// a 15s budget, without pacing.
+func DefaultPolicy() Policy {
+	return Policy{MaxRetries: 3, BaseDelay: 250 * time.Millisecond, MaxDelay: 5 * time.Second, Budget: 15 * time.Second}
+}
+
+// Transport is an http.RoundTripper that retries 429 Too Many Requests and
+// 5xx responses according to its Policy. A 429 means the request was not
+// processed, so it is always retried; a 5xx may come after the server acted,
+// so it is only retried for idempotent requests (GET, PUT, DELETE, or a
+// context marked with Idempotent). Transport errors (refused
+// connections, DNS failures) are not retried: they rarely clear within a
+// request's budget, and the daemon already degrades to cached issues when a
+// tracker is unreachable.
+type Transport struct {
+	Base   http.RoundTripper // nil means http.DefaultTransport
+	Policy Policy
+
+	sleep func(ctx context.Context, d time.Duration) error // injectable for testing
+}
+
+// NewTransport returns a Transport sending requests through base.
+func NewTransport(base http.RoundTripper, policy Policy) *Transport {
+	return &Transport{Base: base, Policy: policy}
+}
+
+// NewClient returns a client with pooled connections whose requests go
+// through a Transport with the given policy. The timeout covers retries.
+func NewClient(timeout time.Duration, policy Policy) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: NewTransport(&http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		}, policy),
+	}
+}
+
+// Wrap returns a copy of client whose requests go through a Transport with
+// the given policy. A client that already retries is returned unchanged.
+func Wrap(client *http.Client, policy Policy) *http.Client {
+	if _, ok := client.Transport.(*Transport); ok {
+		return client
+	}
+	wrapped := *client
+	wrapped.Transport = NewTransport(client.Transport, policy)
+	return &wrapped
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	ctx := req.Context()
+	// A body that cannot be replayed can only be sent once.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		if t.Policy.Limiter != nil {
+			if err := t.Policy.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		if err != nil || !retryable(req, resp.StatusCode) || attempt >= t.Policy.MaxRetries || !replayable {
+			return resp, err
+		}
+		delay := t.backoff(attempt)
+		if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			delay = after
+		}
+		if waited+delay > t.Policy.Budget {
+			return resp, nil
+		}
+
+		// Drain the body so the connection can be reused.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+	}
+}
+
+// backoff returns the wait before retry number attempt+1.
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.Policy.BaseDelay << attempt
+	if t.Policy.MaxDelay > 0 && (delay > t.Policy.MaxDelay || delay <= 0) {
+		delay = t.Policy.MaxDelay
+	}
+	return delay
+}
+
+// idempotentKey marks a context whose requests are safe to repeat.
+type idempotentKey struct{}
+
+// Idempotent marks requests made with the returned context as safe to
+// repeat after a server error, for POST endpoints that only read (such as
+// GraphQL queries).
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// retryable reports whether a response to req is worth retrying.
+func retryable(req *http.Request, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if status < 500 {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Context().Value(idempotentKey{}) != nil
+}
+
+// retryAfter parses a Retry-After header, given either as seconds or as an
+// HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
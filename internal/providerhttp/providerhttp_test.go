@@ -0,0 +1,211 @@
+package providerhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first n requests with status, advertising
+// retryAfter when set, then answers "ok". It returns the request counter.
+func flakyServer(t *testing.T, n int, status int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if int(calls.Add(1)) <= n {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(append([]byte("ok"), body...))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// recordingTransport returns a Transport that records its sleeps instead of
+// waiting.
+func recordingTransport(policy Policy, slept *[]time.Duration) *Transport {
+	tr := NewTransport(nil, policy)
+	tr.sleep = func(_ context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return nil
+	}
+	return tr
+}
+
+func TestTransport_RetriesServerErrorsWithBackoff(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusBadGateway, "")
+	var slept []time.Duration
+	client := &http.Client{Transport: recordingTransport(DefaultPolicy(), &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+	if len(slept) != 2 || slept[0] != 250*time.Millisecond || slept[1] != 500*time.Millisecond {
+		t.Errorf("backoff = %v, want [250ms 500ms]", slept)
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable, "")
+	var slept []time.Duration
+	client := &http.Client{Transport: recordingTransport(DefaultPolicy(), &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 4 {
+		t.Errorf("status %d after %d calls, want 503 after 4", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_HonorsRetryAfterWithinBudget(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, "2")
+	var slept []time.Duration
+	client := &http.Client{Transport: recordingTransport(DefaultPolicy(), &slept)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(slept) != 1 || slept[0] != 2*time.Second {
+		t.Errorf("status %d, slept %v; want 200 after waiting 2s", resp.StatusCode, slept)
+	}
+
+	// A window longer than the budget fails fast with the 429.
+	srv, calls = flakyServer(t, 1, http.StatusTooManyRequests, "60")
+	slept = nil
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 || len(slept) != 0 {
+		t.Errorf("status %d after %d calls, slept %v; want an immediate 429", resp.StatusCode, calls.Load(), slept)
+	}
+}
+
+func TestTransport_ServerErrorsOnPostNeedIdempotency(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusInternalServerError, "")
+	var slept []time.Duration
+	client := &http.Client{Transport: recordingTransport(DefaultPolicy(), &slept)}
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 1 {
+		t.Errorf("mutation retried: status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+
+	req, _ := http.NewRequestWithContext(Idempotent(context.Background()), http.MethodPost, srv.URL, strings.NewReader(`{"q":1}`))
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `ok{"q":1}` {
+		t.Errorf("idempotent POST = %d %q, want the body replayed", resp.StatusCode, body)
+	}
+}
+
+func TestTransport_RateLimitedPostIsReplayed(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, "")
+	var slept []time.Duration
+	client := &http.Client{Transport: recordingTransport(DefaultPolicy(), &slept)}
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_ZeroPolicyDoesNotRetry(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusBadGateway, "")
+	client := &http.Client{Transport: NewTransport(nil, Policy{})}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+		t.Errorf("status %d after %d calls, want one 502", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_LimiterErrorStopsRequest(t *testing.T) {
+	srv, calls := flakyServer(t, 0, 0, "")
+	limiter := limiterFunc(func(context.Context) error { return context.Canceled })
+	client := &http.Client{Transport: NewTransport(nil, Policy{Limiter: limiter})}
+
+	if _, err := client.Get(srv.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("request sent despite limiter error")
+	}
+}
+
+type limiterFunc func(context.Context) error
+
+func (f limiterFunc) Wait(ctx context.Context) error { return f(ctx) }
+
+func TestWrap(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+	wrapped := Wrap(client, DefaultPolicy())
+	if _, ok := wrapped.Transport.(*Transport); !ok || wrapped.Timeout != time.Second {
+		t.Fatalf("Wrap = %+v, want a retrying client with the same timeout", wrapped)
+	}
+	if client.Transport != nil {
+		t.Error("Wrap modified the original client")
+	}
+	if again := Wrap(wrapped, DefaultPolicy()); again != wrapped {
+		t.Error("Wrap re-wrapped a retrying client")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
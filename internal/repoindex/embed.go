@@ -0,0 +1,192 @@
+package repoindex
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultDims is the vector size of the default embedder.
+const DefaultDims = 512
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are.
+type Embedder interface {
+	// Name identifies the embedder and its settings. An index can only be
+	// searched with the embedder that built it.
+	Name() string
+	// Embed returns one vector per text.
+	Embed(texts []string) ([][]float32, error)
+}
+
+// DefaultEmbedder returns the embedder indexes are built with when no
+// embedding model is configured (see NewEmbedder).
+func DefaultEmbedder() Embedder {
+	return HashEmbedder{Dims: DefaultDims}
+}
+
+// HashEmbedder is a local embedder that needs no model or network: it
+// hashes a text's words and identifiers into a fixed number of dimensions
+// (the "hashing trick"). Its similarity is lexical, not semantic: texts are
+// related only as far as they share terms. Identifiers are split into their
+// camelCase and snake_case parts and common suffixes are stripped, so an
+// issue mentioning "config parsing" still finds code calling parseConfig,
+// but one about "login failures" does not find authError. Use a
+// ModelEmbedder for that.
+type HashEmbedder struct {
+	Dims int
+}
+
+// Name implements Embedder.
+func (e HashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", e.dims())
+}
+
+func (e HashEmbedder) dims() int {
+	if e.Dims > 0 {
+		return e.Dims
+	}
+	return DefaultDims
+}
+
+// Embed implements Embedder.
+func (e HashEmbedder) Embed(texts []string) ([][]float32, error) {
+	vecs := make([][]float32, len(texts))
+	for i, text := range texts {
+		vecs[i] = e.embed(text)
+	}
+	return vecs, nil
+}
+
+func (e HashEmbedder) embed(text string) []float32 {
+	counts := make(map[string]int)
+	for _, tok := range tokenize(text) {
+		counts[tok]++
+	}
+	dims := e.dims()
+	vec := make([]float32, dims)
+	for tok, n := range counts {
+		h := fnv.New32a()
+		h.Write([]byte(tok))
+		sum := h.Sum32()
+		weight := float32(1 + math.Log(float64(n)))
+		if sum&(1<<31) != 0 {
+			weight = -weight
+		}
+		vec[int(sum%uint32(dims))] += weight
+	}
+	normalize(vec)
+	return vec
+}
+
+// tokenize returns the terms of text: each identifier or word, lowercased,
+// followed by its camelCase and snake_case parts when it has several.
+// Common words and language keywords are dropped.
+func tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			if t := normalizeTerm(strings.Join(parts, "")); t != "" {
+				terms = append(terms, t)
+			}
+		}
+		for _, p := range parts {
+			if t := normalizeTerm(p); t != "" {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return terms
+}
+
+// splitIdentifier splits an identifier at underscores and camelCase
+// boundaries: "parseHTTPConfig_v2" becomes parse, HTTP, Config, v2.
+func splitIdentifier(word string) []string {
+	var parts []string
+	for _, seg := range strings.Split(word, "_") {
+		runes := []rune(seg)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
+
+// normalizeTerm lowercases a term and strips common English suffixes, so
+// "handlers" and "handling" meet "handler" and "handle". It returns "" for
+// terms too short, numeric, or common to say anything about the text.
+func normalizeTerm(t string) string {
+	t = strings.ToLower(t)
+	if len(t) < 2 || stopWords[t] || strings.IndexFunc(t, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	switch {
+	case len(t) > 5 && strings.HasSuffix(t, "ing"):
+		t = t[:len(t)-3]
+	case len(t) > 4 && strings.HasSuffix(t, "ed"):
+		t = t[:len(t)-2]
+	case len(t) > 3 && strings.HasSuffix(t, "s") && !strings.HasSuffix(t, "ss"):
+		t = t[:len(t)-1]
+	}
+	return t
+}
+
+// stopWords are English words and language keywords common enough to say
+// nothing about what a text is about.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "do": true, "for": true,
+	"from": true, "has": true, "have": true, "if": true, "in": true, "into": true,
+	"is": true, "it": true, "its": true, "not": true, "of": true, "on": true,
+	"or": true, "should": true, "so": true, "than": true, "that": true,
+	"the": true, "then": true, "there": true, "this": true, "to": true,
+	"was": true, "we": true, "when": true, "which": true, "will": true,
+	"with": true, "would": true,
+	"break": true, "case": true, "class": true, "const": true, "continue": true,
+	"def": true, "default": true, "else": true, "err": true, "false": true,
+	"fmt": true, "func": true, "function": true, "import": true, "int": true,
+	"let": true, "nil": true, "none": true, "null": true, "package": true,
+	"return": true, "self": true, "string": true, "true": true, "type": true,
+	"var": true,
+}
+
+// normalize scales vec to unit length, leaving a zero vector alone.
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	scale := float32(1 / math.Sqrt(sum))
+	for i := range vec {
+		vec[i] *= scale
+	}
+}
+
+// cosine returns the cosine similarity of two unit vectors.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
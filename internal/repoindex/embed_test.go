@@ -0,0 +1,64 @@
+package repoindex
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("func parseHTTPConfig(path string) error { return nil } // handles 404s")
+	for _, want := range []string{"parsehttpconfig", "parse", "http", "config", "path", "error", "handle", "404"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("tokenize missing %q: %v", want, got)
+		}
+	}
+	for _, dropped := range []string{"func", "string", "return", "nil", "404s"} {
+		if slices.Contains(got, dropped) {
+			t.Errorf("tokenize kept %q: %v", dropped, got)
+		}
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := map[string][]string{
+		"parseConfig":     {"parse", "Config"},
+		"HTTPServer":      {"HTTP", "Server"},
+		"load_repo_index": {"load", "repo", "index"},
+		"URL":             {"URL"},
+		"v2":              {"v2"},
+	}
+	for in, want := range tests {
+		if got := splitIdentifier(in); !slices.Equal(got, want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHashEmbedder_Similarity(t *testing.T) {
+	e := HashEmbedder{}
+	vecs, err := e.Embed([]string{
+		"Config parsing fails on empty files",
+		"func parseConfig(data []byte) (*Config, error) { if len(data) == 0 { ... } }",
+		"func renderDashboard(w http.ResponseWriter) { w.Write(indexHTML) }",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs[0]) != DefaultDims {
+		t.Fatalf("dims = %d, want %d", len(vecs[0]), DefaultDims)
+	}
+	related, unrelated := cosine(vecs[0], vecs[1]), cosine(vecs[0], vecs[2])
+	if related <= unrelated {
+		t.Errorf("related score %.3f should beat unrelated %.3f", related, unrelated)
+	}
+	if self := cosine(vecs[1], vecs[1]); self < 0.999 || self > 1.001 {
+		t.Errorf("a vector should be unit length, got self-similarity %.3f", self)
+	}
+	if empty := cosine(vecs[0], vecs[3]); empty != 0 {
+		t.Errorf("empty text similarity = %.3f, want 0", empty)
+	}
+	if e.Name() != "hash-512" || (HashEmbedder{Dims: 64}).Name() != "hash-64" {
+		t.Errorf("names = %q, %q", e.Name(), HashEmbedder{Dims: 64}.Name())
+	}
+}
@@ -0,0 +1,291 @@
+// Package repoindex is an optional codebase index: the repo's files are
+// split into chunks and embedded as vectors, so the chunks most related to
+// an issue can be found and shown to a session up front instead of the
+// agent searching for them. Vectors come from a configured embedding model
+// (ModelEmbedder), or offline from the words chunks contain
+// (HashEmbedder). Indexes are local, stored under erg's data directory,
+// and built or refreshed with `erg index`.
+package repoindex
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+const (
+	// chunkLines is how many lines a chunk spans.
+	chunkLines = 60
+	// chunkOverlap is how many lines consecutive chunks of a file share,
+	// so code near a boundary is whole in one of them.
+	chunkOverlap = 10
+	// maxFileSize skips files too large to be source worth showing.
+	maxFileSize = 256 << 10
+	// maxFiles caps how many files one index covers.
+	maxFiles = 20000
+	// embedBatch is how many chunks are embedded per Embed call.
+	embedBatch = 64
+)
+
+// skippedFiles are generated files with no code worth retrieving.
+var skippedFiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.lock": true, "poetry.lock": true, "Gemfile.lock": true, "composer.lock": true,
+}
+
+// skippedSuffixes are suffixes of minified or non-source files.
+var skippedSuffixes = []string{".min.js", ".min.css", ".map", ".svg"}
+
+// Chunk is a span of a file and its vector.
+type Chunk struct {
+	Path      string    `json:"path"`
+	StartLine int       `json:"start_line"` // 1-based
+	EndLine   int       `json:"end_line"`   // inclusive
+	Vector    []float32 `json:"vector"`
+}
+
+// Index is a repo's embedded chunks.
+type Index struct {
+	RepoPath  string            `json:"repo_path"`
+	Embedder  string            `json:"embedder"`
+	Commit    string            `json:"commit,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Files     map[string]string `json:"files"` // path → content hash
+	Chunks    []Chunk           `json:"chunks"`
+}
+
+// Stats describes what an update did.
+type Stats struct {
+	Files    int // files in the index
+	Chunks   int // chunks in the index
+	Embedded int // files new or changed since the last update
+	Removed  int // files no longer in the repo
+}
+
+// Dir returns the directory a repo's index is kept in: index/<repo> under
+// erg's data directory, where <repo> is the checkout's name and a hash of
+// its path.
+func Dir(repoPath string) (string, error) {
+	dir, err := paths.DataDir()
+	if err != nil {
+		return "", err
+	}
+	repoPath = filepath.Clean(repoPath)
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(repoPath)))
+	return filepath.Join(dir, "index", filepath.Base(repoPath)+"-"+hash[:12]), nil
+}
+
+// indexFile returns the path of a repo's index file.
+func indexFile(repoPath string) (string, error) {
+	dir, err := Dir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "index.json"), nil
+}
+
+// Load reads a repo's index. The error satisfies errors.Is(err,
+// os.ErrNotExist) when none has been built.
+func Load(repoPath string) (*Index, error) {
+	path, err := indexFile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", path, err)
+	}
+	return &ix, nil
+}
+
+// Save writes the index, replacing the previous one atomically.
+func (ix *Index) Save() error {
+	path, err := indexFile(ix.RepoPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// Update brings prev up to date with the repo's files and returns the new
+// index: files whose content is unchanged keep their chunks, new and
+// changed files are embedded again, and deleted files are dropped. A nil
+// prev, or one built by another embedder, is rebuilt from scratch. Files
+// git ignores are left out.
+func Update(ctx context.Context, repoPath string, prev *Index, e Embedder) (*Index, Stats, error) {
+	repoPath = filepath.Clean(repoPath)
+	files, err := listFiles(ctx, repoPath)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	if prev == nil || prev.Embedder != e.Name() {
+		prev = &Index{}
+	}
+	kept := make(map[string][]Chunk)
+	for _, c := range prev.Chunks {
+		kept[c.Path] = append(kept[c.Path], c)
+	}
+
+	ix := &Index{RepoPath: repoPath, Embedder: e.Name(), Files: make(map[string]string)}
+	var stats Stats
+	var pending []Chunk
+	var texts []string
+	for _, rel := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, Stats{}, err
+		}
+		data, ok := readSource(filepath.Join(repoPath, rel))
+		if !ok {
+			continue
+		}
+		hash := fmt.Sprintf("%x", sha256.Sum256(data))
+		ix.Files[rel] = hash
+		if prev.Files[rel] == hash {
+			ix.Chunks = append(ix.Chunks, kept[rel]...)
+			continue
+		}
+		stats.Embedded++
+		for _, c := range chunkFile(rel, data) {
+			pending = append(pending, c.chunk)
+			texts = append(texts, c.text)
+		}
+	}
+	for start := 0; start < len(texts); start += embedBatch {
+		end := min(start+embedBatch, len(texts))
+		vecs, err := e.Embed(texts[start:end])
+		if err != nil {
+			return nil, Stats{}, fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		for i, v := range vecs {
+			pending[start+i].Vector = v
+		}
+	}
+	ix.Chunks = append(ix.Chunks, pending...)
+	for rel := range prev.Files {
+		if _, ok := ix.Files[rel]; !ok {
+			stats.Removed++
+		}
+	}
+
+	if out, err := gitCommand(ctx, repoPath, "rev-parse", "HEAD"); err == nil {
+		ix.Commit = strings.TrimSpace(string(out))
+	}
+	ix.UpdatedAt = time.Now()
+	stats.Files = len(ix.Files)
+	stats.Chunks = len(ix.Chunks)
+	return ix, stats, nil
+}
+
+// listFiles returns the repo's tracked and untracked files, minus those git
+// ignores and those not worth indexing.
+func listFiles(ctx context.Context, repoPath string) ([]string, error) {
+	out, err := gitCommand(ctx, repoPath, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, rel := range strings.Split(string(out), "\x00") {
+		if rel == "" || seen[rel] || skippedFiles[filepath.Base(rel)] {
+			continue
+		}
+		if hasSkippedSuffix(rel) {
+			continue
+		}
+		seen[rel] = true
+		files = append(files, rel)
+		if len(files) == maxFiles {
+			break
+		}
+	}
+	return files, nil
+}
+
+func hasSkippedSuffix(rel string) bool {
+	for _, suffix := range skippedSuffixes {
+		if strings.HasSuffix(rel, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSource reads a file worth indexing: a regular text file no larger
+// than maxFileSize.
+func readSource(path string) ([]byte, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > maxFileSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// fileChunk is a chunk to embed and the text it is embedded from.
+type fileChunk struct {
+	chunk Chunk
+	text  string
+}
+
+// chunkFile splits a file into overlapping spans of chunkLines lines. Each
+// is embedded with the file's path, whose words say a lot about the code.
+func chunkFile(rel string, data []byte) []fileChunk {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	var chunks []fileChunk
+	for start := 0; start < len(lines); start += chunkLines - chunkOverlap {
+		end := min(start+chunkLines, len(lines))
+		body := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(body) != "" {
+			chunks = append(chunks, fileChunk{
+				chunk: Chunk{Path: rel, StartLine: start + 1, EndLine: end},
+				text:  rel + "\n" + body,
+			})
+		}
+		if end == len(lines) {
+			break
+		}
+	}
+	return chunks
+}
+
+func gitCommand(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package repoindex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo creates a git repository holding files.
+func testRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	for rel, content := range files {
+		writeFile(t, dir, rel, content)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// longFile returns a file of n numbered lines.
+func longFile(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestUpdate_BuildsAndRefreshes(t *testing.T) {
+	repo := testRepo(t, map[string]string{
+		"config/parse.go":  "package config\n\nfunc parseConfig(data []byte) (*Config, error) {\n\treturn decodeYAML(data)\n}\n",
+		"web/dashboard.go": "package web\n\nfunc renderDashboard(w http.ResponseWriter) {\n\tw.Write(indexHTML)\n}\n",
+		"big.txt":          longFile(130),
+		"go.sum":           "example.com/mod v1.0.0 h1:abc\n",
+		"logo.png":         "\x89PNG\x00\x00binary",
+		".gitignore":       "build/\n",
+		"build/out.go":     "package build\n",
+	})
+	e := DefaultEmbedder()
+
+	ix, stats, err := Update(t.Context(), repo, nil, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Files["config/parse.go"]; !ok {
+		t.Errorf("expected source indexed, files = %v", ix.Files)
+	}
+	for _, skipped := range []string{"go.sum", "logo.png", "build/out.go"} {
+		if _, ok := ix.Files[skipped]; ok {
+			t.Errorf("%s should not be indexed", skipped)
+		}
+	}
+	if stats.Files != 4 || stats.Embedded != 4 || stats.Removed != 0 {
+		t.Errorf("build stats = %+v", stats)
+	}
+	var spans []string
+	for _, c := range ix.Chunks {
+		if c.Path == "big.txt" {
+			spans = append(spans, fmt.Sprintf("%d-%d", c.StartLine, c.EndLine))
+		}
+	}
+	if got := strings.Join(spans, ","); got != "1-60,51-110,101-130" {
+		t.Errorf("big.txt chunks = %s", got)
+	}
+
+	// Unchanged files keep their chunks; changed and new files are embedded
+	// again; deleted files are dropped.
+	writeFile(t, repo, "web/dashboard.go", "package web\n\nfunc renderDashboard() {}\n")
+	writeFile(t, repo, "auth/token.go", "package auth\n\nfunc refreshToken() {}\n")
+	os.Remove(filepath.Join(repo, "big.txt"))
+	ix, stats, err = Update(t.Context(), repo, ix, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Files != 4 || stats.Embedded != 2 || stats.Removed != 1 {
+		t.Errorf("update stats = %+v", stats)
+	}
+	for _, c := range ix.Chunks {
+		if c.Path == "big.txt" {
+			t.Error("chunks of a deleted file remain")
+		}
+	}
+
+	// An index from another embedder is rebuilt.
+	_, stats, err = Update(t.Context(), repo, ix, HashEmbedder{Dims: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Embedded != 4 {
+		t.Errorf("embedder change should re-embed everything, stats = %+v", stats)
+	}
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	repo := testRepo(t, map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	if _, err := Load(repo); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load before build: %v, want ErrNotExist", err)
+	}
+	ix, _, err := Update(t.Context(), repo, nil, DefaultEmbedder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := Dir(repo)
+	if !strings.HasPrefix(filepath.Base(dir), filepath.Base(repo)+"-") {
+		t.Errorf("index dir %s should be named after the repo", dir)
+	}
+	got, err := Load(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Embedder != ix.Embedder || len(got.Chunks) != len(ix.Chunks) || got.Files["main.go"] == "" {
+		t.Errorf("loaded index = %+v", got)
+	}
+}
+
+func TestSearchAndRender(t *testing.T) {
+	repo := testRepo(t, map[string]string{
+		"config/parse.go":  "package config\n\n// parseConfig decodes the YAML config file.\nfunc parseConfig(data []byte) (*Config, error) {\n\treturn decodeYAML(data)\n}\n",
+		"web/dashboard.go": "package web\n\nfunc renderDashboard(w http.ResponseWriter) {\n\tw.Write(indexHTML)\n}\n",
+		"auth/token.go":    "package auth\n\nfunc refreshToken(ctx context.Context) error {\n\treturn nil\n}\n",
+	})
+	ix, _, err := Update(t.Context(), repo, nil, DefaultEmbedder())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := ix.Search(DefaultEmbedder(), "Crash when the YAML config file is empty", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) == 0 || hits[0].Path != "config/parse.go" {
+		t.Fatalf("hits = %+v, want config/parse.go first", hits)
+	}
+	if _, err := ix.Search(HashEmbedder{Dims: 64}, "config", 2); err == nil {
+		t.Error("expected searching with another embedder to fail")
+	}
+
+	out := Render(repo, hits[:1], 1000)
+	if !strings.HasPrefix(out, "config/parse.go (lines 1-6):\n```\npackage config") || !strings.HasSuffix(out, "```") {
+		t.Errorf("rendered:\n%s", out)
+	}
+	if short := Render(repo, hits[:1], 80); len(short) > 80 || !strings.Contains(short, "...") {
+		t.Errorf("capped render (%d chars):\n%s", len(short), short)
+	}
+	if gone := Render(t.TempDir(), hits, 1000); gone != "" {
+		t.Errorf("hits whose files are gone should be skipped, got:\n%s", gone)
+	}
+}
@@ -0,0 +1,118 @@
+package repoindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zhubert/erg/internal/secrets"
+)
+
+// DefaultModelURL is the embeddings endpoint used when a model is named
+// without a URL. Any server with the same API, such as Ollama or vLLM, can
+// be given instead.
+const DefaultModelURL = "https://api.openai.com/v1/embeddings"
+
+// modelTimeout bounds one embeddings request.
+const modelTimeout = 60 * time.Second
+
+// ModelEmbedder embeds texts with an embedding model served over an
+// OpenAI-compatible embeddings API. Unlike HashEmbedder, which only scores
+// the words texts share, a model places texts about the same thing close
+// together even when they are worded differently, so an issue about
+// "login failures" finds code handling authentication errors.
+type ModelEmbedder struct {
+	URL    string
+	Model  string
+	APIKey string
+	// Client sends the requests. Defaults to one with a 60s timeout.
+	Client *http.Client
+}
+
+// Name implements Embedder.
+func (e ModelEmbedder) Name() string {
+	return "model-" + e.Model
+}
+
+// Embed implements Embedder.
+func (e ModelEmbedder) Embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": e.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: modelTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings request failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vecs) || len(d.Embedding) == 0 {
+			return nil, fmt.Errorf("embeddings response has an invalid entry at index %d", d.Index)
+		}
+		normalize(d.Embedding)
+		vecs[d.Index] = d.Embedding
+	}
+	for i, v := range vecs {
+		if v == nil {
+			return nil, fmt.Errorf("embeddings response is missing text %d of %d", i+1, len(texts))
+		}
+	}
+	return vecs, nil
+}
+
+// NewEmbedder returns the embedder a repo's index uses: a ModelEmbedder
+// when model is set, with url defaulting to DefaultModelURL and its API key
+// read from the apiKeyEnv environment variable (which may hold an exec:
+// reference), or the offline DefaultEmbedder when it is not.
+func NewEmbedder(url, model, apiKeyEnv string) (Embedder, error) {
+	if model == "" {
+		return DefaultEmbedder(), nil
+	}
+	if url == "" {
+		url = DefaultModelURL
+	}
+	var key string
+	if apiKeyEnv != "" {
+		raw := os.Getenv(apiKeyEnv)
+		if raw == "" {
+			return nil, fmt.Errorf("%s is not set; the embedding model needs its API key", apiKeyEnv)
+		}
+		var err error
+		if key, err = secrets.Expand(raw); err != nil {
+			return nil, err
+		}
+	}
+	return ModelEmbedder{URL: url, Model: model, APIKey: key}, nil
+}
@@ -0,0 +1,94 @@
+package repoindex
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestModelEmbedder_Embed(t *testing.T) {
+	var got struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		// Entries out of order, and not unit length.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,2]},{"index":0,"embedding":[3,4]}]}`))
+	}))
+	defer srv.Close()
+
+	e := ModelEmbedder{URL: srv.URL, Model: "text-embedding-3-small", APIKey: "sk-test"}
+	vecs, err := e.Embed([]string{"login failures", "auth errors"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "text-embedding-3-small" || len(got.Input) != 2 || auth != "Bearer sk-test" {
+		t.Errorf("request: model %q, input %v, auth %q", got.Model, got.Input, auth)
+	}
+	want := [][]float32{{0.6, 0.8}, {0, 1}}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(float64(vecs[i][j]-want[i][j])) > 1e-6 {
+				t.Fatalf("vecs = %v, want %v", vecs, want)
+			}
+		}
+	}
+	if e.Name() != "model-text-embedding-3-small" {
+		t.Errorf("Name = %q", e.Name())
+	}
+}
+
+func TestModelEmbedder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"http error", http.StatusUnauthorized, `{"error":"bad key"}`, "401"},
+		{"missing entry", http.StatusOK, `{"data":[{"index":0,"embedding":[1]}]}`, "missing text 2"},
+		{"bad index", http.StatusOK, `{"data":[{"index":5,"embedding":[1]},{"index":0,"embedding":[1]}]}`, "invalid entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := ModelEmbedder{URL: srv.URL, Model: "m"}.Embed([]string{"a", "b"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	e, err := NewEmbedder("", "", "")
+	if err != nil || e.Name() != DefaultEmbedder().Name() {
+		t.Errorf("expected the offline embedder without a model, got %v, %v", e, err)
+	}
+
+	t.Setenv("TEST_EMBED_KEY", "sk-env")
+	e, err = NewEmbedder("", "text-embedding-3-small", "TEST_EMBED_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := e.(ModelEmbedder)
+	if !ok || m.URL != DefaultModelURL || m.APIKey != "sk-env" {
+		t.Errorf("got %+v", e)
+	}
+
+	t.Setenv("TEST_EMBED_KEY", "")
+	if _, err := NewEmbedder("", "m", "TEST_EMBED_KEY"); err == nil || !strings.Contains(err.Error(), "TEST_EMBED_KEY") {
+		t.Errorf("expected an error naming the unset key, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package repoindex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// minScore drops chunks too unlike the query to be worth showing.
+	minScore = 0.05
+	// maxHitsPerFile keeps one large file from crowding out the rest.
+	maxHitsPerFile = 2
+)
+
+// Hit is a chunk found by Search.
+type Hit struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Score     float64
+}
+
+// Search returns up to k chunks most related to query, best first. e must
+// be the embedder the index was built with.
+func (ix *Index) Search(e Embedder, query string, k int) ([]Hit, error) {
+	if e.Name() != ix.Embedder {
+		return nil, fmt.Errorf("index was built with embedder %q, not %q; rebuild it with 'erg index build'", ix.Embedder, e.Name())
+	}
+	vecs, err := e.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	q := vecs[0]
+
+	var hits []Hit
+	for _, c := range ix.Chunks {
+		if score := cosine(q, c.Vector); score >= minScore {
+			hits = append(hits, Hit{Path: c.Path, StartLine: c.StartLine, EndLine: c.EndLine, Score: score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b Hit) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+
+	perFile := make(map[string]int)
+	top := hits[:0]
+	for _, h := range hits {
+		if len(top) == k {
+			break
+		}
+		if perFile[h.Path] == maxHitsPerFile {
+			continue
+		}
+		perFile[h.Path]++
+		top = append(top, h)
+	}
+	return top, nil
+}
+
+// Render formats hits as fenced snippets read from the files under dir,
+// usually the session's worktree, in at most maxChars characters. Hits
+// whose file is gone are skipped, and the last snippet that fits only in
+// part is cut short.
+func Render(dir string, hits []Hit, maxChars int) string {
+	var b strings.Builder
+	for _, h := range hits {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(h.Path)))
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(string(data), "\n")
+		if h.StartLine > len(lines) {
+			continue
+		}
+		end := min(h.EndLine, len(lines))
+		body := strings.Join(lines[h.StartLine-1:end], "\n")
+		header := fmt.Sprintf("%s (lines %d-%d):\n```\n", h.Path, h.StartLine, end)
+		footer := "\n```\n\n"
+
+		room := maxChars - b.Len() - len(header) - len(footer)
+		if room <= 0 {
+			break
+		}
+		if len(body) > room {
+			cut := strings.LastIndexByte(body[:max(room-len("\n..."), 0)], '\n')
+			if cut <= 0 {
+				break
+			}
+			body = body[:cut] + "\n..."
+		}
+		b.WriteString(header)
+		b.WriteString(body)
+		b.WriteString(footer)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
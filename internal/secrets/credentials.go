@@ -0,0 +1,260 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// PassphraseEnvVar, when set, derives the credentials-file key from a
+// passphrase instead of the generated key file, so the file is useless to
+// anyone who copies ~/.erg without also knowing the passphrase.
+const PassphraseEnvVar = "ERG_CREDENTIALS_PASSPHRASE"
+
+const (
+	credentialsFileName = "credentials.enc"
+	credentialsKeyName  = "credentials.key"
+
+	kdfKeyFile = "keyfile"
+	kdfPBKDF2  = "pbkdf2"
+
+	pbkdf2Iterations = 600_000
+)
+
+// credentialsEnvelope is the on-disk format of the credentials file: the
+// JSON-encoded service→value map, sealed with AES-256-GCM.
+type credentialsEnvelope struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt,omitempty"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// credentialsCache holds the decrypted file so that per-request token
+// lookups don't re-run the key derivation. It is invalidated when the
+// file's modification time or size changes.
+var credentialsCache struct {
+	sync.Mutex
+	modTime time.Time
+	size    int64
+	values  map[string]string
+}
+
+// CredentialsPath returns the path of the encrypted credentials file.
+func CredentialsPath() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, credentialsFileName), nil
+}
+
+// FileGet retrieves a secret from the encrypted credentials file.
+// Returns ("", false) if the file or entry doesn't exist or can't be decrypted.
+func FileGet(service string) (string, bool) {
+	values, err := readCredentials()
+	if err != nil {
+		return "", false
+	}
+	v, ok := values[service]
+	return v, ok && v != ""
+}
+
+// FileSet stores a secret in the encrypted credentials file.
+func FileSet(service, value string) error {
+	values, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	values[service] = value
+	return writeCredentials(values)
+}
+
+// FileDelete removes a secret from the encrypted credentials file.
+func FileDelete(service string) error {
+	values, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[service]; !ok {
+		return nil
+	}
+	delete(values, service)
+	return writeCredentials(values)
+}
+
+// readCredentials decrypts the credentials file. A missing file yields an
+// empty map.
+func readCredentials() (map[string]string, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsCache.Lock()
+	defer credentialsCache.Unlock()
+	if credentialsCache.values != nil && info.ModTime().Equal(credentialsCache.modTime) && info.Size() == credentialsCache.size {
+		return maps.Clone(credentialsCache.values), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var env credentialsEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	key, err := credentialsKey(env.KDF, env.Salt, false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials file (wrong key or passphrase?)")
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+
+	credentialsCache.modTime, credentialsCache.size, credentialsCache.values = info.ModTime(), info.Size(), values
+	return maps.Clone(values), nil
+}
+
+// writeCredentials encrypts values into the credentials file, owner-only.
+// The key source is chosen afresh on every write, so setting or clearing
+// PassphraseEnvVar takes effect the next time a credential is stored.
+func writeCredentials(values map[string]string) error {
+	path, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	env := credentialsEnvelope{Version: 1, KDF: kdfKeyFile}
+	if os.Getenv(PassphraseEnvVar) != "" {
+		env.KDF = kdfPBKDF2
+		env.Salt = make([]byte, 16)
+		if _, err := rand.Read(env.Salt); err != nil {
+			return err
+		}
+	}
+	key, err := credentialsKey(env.KDF, env.Salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return err
+	}
+	env.Data = gcm.Seal(nil, env.Nonce, plain, nil)
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	credentialsCache.Lock()
+	credentialsCache.values = nil
+	credentialsCache.Unlock()
+	return nil
+}
+
+// credentialsKey returns the AES key for the given KDF. For the key-file
+// KDF, create controls whether a missing key file is generated.
+func credentialsKey(kdf string, salt []byte, create bool) ([]byte, error) {
+	switch kdf {
+	case kdfPBKDF2:
+		pass := os.Getenv(PassphraseEnvVar)
+		if pass == "" {
+			return nil, fmt.Errorf("credentials file is passphrase-protected: set %s", PassphraseEnvVar)
+		}
+		return pbkdf2.Key(sha256.New, pass, salt, pbkdf2Iterations, 32)
+	case kdfKeyFile:
+		return keyFileKey(create)
+	default:
+		return nil, fmt.Errorf("unsupported credentials file key derivation %q", kdf)
+	}
+}
+
+// keyFileKey reads the generated 32-byte key stored next to the
+// credentials file, creating it when create is set.
+func keyFileKey(create bool) ([]byte, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, credentialsKeyName)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("credentials key %s is corrupt", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) || !create {
+		return nil, fmt.Errorf("failed to read credentials key: %w", err)
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write credentials key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// isolateConfigDir points paths.ConfigDir at a fresh temp dir.
+func isolateConfigDir(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(PassphraseEnvVar, "")
+	paths.Reset()
+	t.Cleanup(paths.Reset)
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCredentialsFile_RoundTrip(t *testing.T) {
+	dir := isolateConfigDir(t)
+
+	if _, ok := FileGet(LinearAPIKeyService); ok {
+		t.Fatal("expected no credential before anything is stored")
+	}
+	if err := FileSet(LinearAPIKeyService, "lin_secret"); err != nil {
+		t.Fatalf("FileSet: %v", err)
+	}
+	if err := FileSet(AsanaPATService, "asana_secret"); err != nil {
+		t.Fatalf("FileSet: %v", err)
+	}
+	if v, ok := FileGet(LinearAPIKeyService); !ok || v != "lin_secret" {
+		t.Errorf("FileGet = %q, %v", v, ok)
+	}
+
+	// The file is encrypted and private.
+	path := filepath.Join(dir, credentialsFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "lin_secret") {
+		t.Error("credentials file contains the plaintext secret")
+	}
+	for _, name := range []string{credentialsFileName, credentialsKeyName} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s permissions = %o, want 600", name, perm)
+		}
+	}
+
+	if err := FileDelete(LinearAPIKeyService); err != nil {
+		t.Fatalf("FileDelete: %v", err)
+	}
+	if _, ok := FileGet(LinearAPIKeyService); ok {
+		t.Error("expected credential to be gone after FileDelete")
+	}
+	if v, ok := FileGet(AsanaPATService); !ok || v != "asana_secret" {
+		t.Errorf("other credential lost: %q, %v", v, ok)
+	}
+}
+
+func TestCredentialsFile_Passphrase(t *testing.T) {
+	dir := isolateConfigDir(t)
+	t.Setenv(PassphraseEnvVar, "correct horse")
+
+	if err := FileSet(GitLabTokenService, "glpat"); err != nil {
+		t.Fatalf("FileSet: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, credentialsKeyName)); err == nil {
+		t.Error("expected no key file when a passphrase is used")
+	}
+	if v, ok := FileGet(GitLabTokenService); !ok || v != "glpat" {
+		t.Errorf("FileGet = %q, %v", v, ok)
+	}
+
+	// A different passphrase cannot decrypt the file, even with a warm cache.
+	credentialsCache.Lock()
+	credentialsCache.values = nil
+	credentialsCache.Unlock()
+	t.Setenv(PassphraseEnvVar, "wrong")
+	if _, ok := FileGet(GitLabTokenService); ok {
+		t.Error("expected decryption to fail with the wrong passphrase")
+	}
+	t.Setenv(PassphraseEnvVar, "")
+	if _, err := readCredentials(); err == nil || !strings.Contains(err.Error(), PassphraseEnvVar) {
+		t.Errorf("expected error naming %s, got %v", PassphraseEnvVar, err)
+	}
+}
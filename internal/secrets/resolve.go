@@ -0,0 +1,207 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecPrefix marks a credential value that is a shell command whose output
+// is the secret, e.g. "exec:op read op://Private/Linear/credential". It may
+// be used in an environment variable or a stored credential.
+const ExecPrefix = "exec:"
+
+// execCacheTTL bounds how long an exec: result is reused, so password
+// managers aren't invoked on every API request but rotated secrets are
+// still picked up.
+const execCacheTTL = 5 * time.Minute
+
+// execTimeout bounds an exec: command, so a password manager stuck waiting
+// for an unlock can't hang every request that needs the credential.
+// Overridden in tests.
+var execTimeout = 30 * time.Second
+
+// Credential describes an issue tracker token erg can resolve.
+type Credential struct {
+	Provider string // name used by `erg auth set`
+	EnvVar   string
+	Service  string // keychain service and credentials-file key
+}
+
+// Credentials lists the issue tracker tokens erg resolves.
+var Credentials = []Credential{
+	{Provider: "asana", EnvVar: "ASANA_PAT", Service: AsanaPATService},
+	{Provider: "linear", EnvVar: "LINEAR_API_KEY", Service: LinearAPIKeyService},
+	{Provider: "gitlab", EnvVar: "GITLAB_TOKEN", Service: GitLabTokenService},
+	{Provider: "clickup", EnvVar: "CLICKUP_API_TOKEN", Service: ClickUpTokenService},
+}
+
+// CredentialFor returns the credential for a provider name.
+func CredentialFor(provider string) (Credential, bool) {
+	for _, c := range Credentials {
+		if c.Provider == provider {
+			return c, true
+		}
+	}
+	return Credential{}, false
+}
+
+func credentialForEnv(envVar string) (Credential, bool) {
+	for _, c := range Credentials {
+		if c.EnvVar == envVar {
+			return c, true
+		}
+	}
+	return Credential{}, false
+}
+
+// Source values reported by Locate.
+const (
+	SourceEnv      = "env"
+	SourceKeychain = "keychain"
+	SourceFile     = "file"
+)
+
+// Locate returns the raw value of a credential and where it was found,
+// following the resolution order: environment variable, OS keychain, then
+// the encrypted credentials file. The value is not expanded (see Expand).
+// Returns ("", "") when the credential is not set anywhere.
+func Locate(envVar, service string) (value, source string) {
+	if v := os.Getenv(envVar); v != "" {
+		return v, SourceEnv
+	}
+	if v, ok := Get(service); ok {
+		return v, SourceKeychain
+	}
+	if v, ok := FileGet(service); ok {
+		return v, SourceFile
+	}
+	return "", ""
+}
+
+// Stored returns a credential saved with `erg auth set`, checking the OS
+// keychain and then the encrypted credentials file. The value is not
+// expanded (see Expand).
+func Stored(service string) (string, bool) {
+	if v, ok := Get(service); ok {
+		return v, true
+	}
+	return FileGet(service)
+}
+
+// runExec runs an exec: command and returns its stdout, killing it when ctx
+// ends. Overridden in tests.
+var runExec = func(ctx context.Context, command string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	// Don't wait on children that outlive the shell and keep stdout open.
+	cmd.WaitDelay = time.Second
+	return cmd.Output()
+}
+
+// expandErrs remembers why a credential's exec: command last failed, keyed
+// by env var, so TokenNotFoundError can report the real cause.
+var expandErrs sync.Map
+
+// ExpandFor expands the raw value of the credential named by envVar. On
+// failure it returns ("", false) and records the error for
+// TokenNotFoundError.
+func ExpandFor(envVar, raw string) (string, bool) {
+	v, err := Expand(raw)
+	if err != nil {
+		expandErrs.Store(envVar, err)
+		return "", false
+	}
+	expandErrs.Delete(envVar)
+	return v, true
+}
+
+// execCache holds exec: results by command, and the commands running now
+// so concurrent callers share one run instead of each starting their own.
+var execCache struct {
+	sync.Mutex
+	entries map[string]execCacheEntry
+	running map[string]*execRun
+}
+
+type execCacheEntry struct {
+	value   string
+	fetched time.Time
+}
+
+// execRun is a running exec: command; done is closed once value and err
+// are set.
+type execRun struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// Expand resolves an exec: value by running its command and returning the
+// trimmed output; other values are returned unchanged. Command results are
+// cached for a few minutes, callers asking for a command already running
+// wait for it, and a command taking longer than execTimeout fails.
+func Expand(value string) (string, error) {
+	command, ok := strings.CutPrefix(value, ExecPrefix)
+	if !ok {
+		return value, nil
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", fmt.Errorf("empty %s command", ExecPrefix)
+	}
+
+	execCache.Lock()
+	if e, ok := execCache.entries[command]; ok && time.Since(e.fetched) < execCacheTTL {
+		execCache.Unlock()
+		return e.value, nil
+	}
+	if run, ok := execCache.running[command]; ok {
+		execCache.Unlock()
+		<-run.done
+		return run.value, run.err
+	}
+	run := &execRun{done: make(chan struct{})}
+	if execCache.running == nil {
+		execCache.running = make(map[string]*execRun)
+	}
+	execCache.running[command] = run
+	execCache.Unlock()
+
+	run.value, run.err = runCredentialCommand(command)
+
+	execCache.Lock()
+	delete(execCache.running, command)
+	if run.err == nil {
+		if execCache.entries == nil {
+			execCache.entries = make(map[string]execCacheEntry)
+		}
+		execCache.entries[command] = execCacheEntry{value: run.value, fetched: time.Now()}
+	}
+	execCache.Unlock()
+	close(run.done)
+	return run.value, run.err
+}
+
+// runCredentialCommand runs an exec: command under execTimeout and returns
+// its trimmed output.
+func runCredentialCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	out, err := runExec(ctx, command)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("credential command %q timed out after %s", command, execTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("credential command %q failed: %w", command, err)
+	}
+	v := strings.TrimSpace(string(out))
+	if v == "" {
+		return "", fmt.Errorf("credential command %q produced no output", command)
+	}
+	return v, nil
+}
@@ -0,0 +1,168 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	orig := runExec
+	defer func() { runExec = orig }()
+
+	calls := 0
+	runExec = func(_ context.Context, command string) ([]byte, error) {
+		calls++
+		switch command {
+		case "op read op://vault/linear":
+			return []byte("lin_from_op\n"), nil
+		case "empty":
+			return []byte("  \n"), nil
+		default:
+			return nil, errors.New("exit status 1")
+		}
+	}
+
+	if v, err := Expand("plain-token"); err != nil || v != "plain-token" {
+		t.Errorf("plain value: %q, %v", v, err)
+	}
+	for range 2 {
+		if v, err := Expand("exec: op read op://vault/linear"); err != nil || v != "lin_from_op" {
+			t.Errorf("exec value: %q, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected exec result to be cached, command ran %d times", calls)
+	}
+	if _, err := Expand("exec:empty"); err == nil {
+		t.Error("expected error for command with no output")
+	}
+	if _, err := Expand("exec:"); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
+
+func TestExpand_TimesOut(t *testing.T) {
+	origExec, origTimeout := runExec, execTimeout
+	defer func() { runExec, execTimeout = origExec, origTimeout }()
+	execTimeout = 50 * time.Millisecond
+	runExec = func(ctx context.Context, _ string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, err := Expand("exec:op read op://vault/locked")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestExpand_SharesRunningCommand(t *testing.T) {
+	orig := runExec
+	defer func() { runExec = orig }()
+
+	execCache.Lock()
+	delete(execCache.entries, "slow")
+	delete(execCache.entries, "other")
+	execCache.Unlock()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	runExec = func(_ context.Context, command string) ([]byte, error) {
+		if command == "other" {
+			return []byte("other-token"), nil
+		}
+		calls.Add(1)
+		<-release
+		return []byte("shared-token"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = Expand("exec:slow")
+		}()
+	}
+
+	// Another command is not held up by the slow one.
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if v, err := Expand("exec:other"); err != nil || v != "other-token" {
+		t.Errorf("other command: %q, %v", v, err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected concurrent callers to share one run, command ran %d times", n)
+	}
+	for i, v := range results {
+		if v != "shared-token" {
+			t.Errorf("caller %d got %q", i, v)
+		}
+	}
+}
+
+func TestExpandFor_ReportsFailureInTokenNotFoundError(t *testing.T) {
+	orig := runExec
+	defer func() { runExec = orig }()
+	runExec = func(context.Context, string) ([]byte, error) { return nil, errors.New("vault locked") }
+
+	if _, ok := ExpandFor("ASANA_PAT", "exec:op read op://vault/asana-missing"); ok {
+		t.Fatal("expected expansion to fail")
+	}
+	if err := TokenNotFoundError("ASANA_PAT"); !strings.Contains(err.Error(), "vault locked") {
+		t.Errorf("expected command failure in error, got %q", err)
+	}
+
+	if _, ok := ExpandFor("ASANA_PAT", "plain"); !ok {
+		t.Fatal("expected plain value to expand")
+	}
+	if err := TokenNotFoundError("ASANA_PAT"); strings.Contains(err.Error(), "vault locked") {
+		t.Errorf("stale failure reported after success: %q", err)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	isolateConfigDir(t)
+	orig := lookPath
+	defer func() { lookPath = orig }()
+	lookPath = func(string) (string, error) { return "", errors.New("not found") } // no keychain on Linux
+
+	t.Setenv("CLICKUP_API_TOKEN", "")
+	if v, src := Locate("CLICKUP_API_TOKEN", ClickUpTokenService); v != "" || src != "" {
+		t.Errorf("expected nothing, got %q from %q", v, src)
+	}
+	if IsKeychainAvailable() {
+		t.Skip("file fallback is shadowed by the OS keychain on this platform")
+	}
+	if err := FileSet(ClickUpTokenService, "exec:pass clickup"); err != nil {
+		t.Fatal(err)
+	}
+	if v, src := Locate("CLICKUP_API_TOKEN", ClickUpTokenService); v != "exec:pass clickup" || src != SourceFile {
+		t.Errorf("got %q from %q, want the raw file value", v, src)
+	}
+	t.Setenv("CLICKUP_API_TOKEN", "from-env")
+	if v, src := Locate("CLICKUP_API_TOKEN", ClickUpTokenService); v != "from-env" || src != SourceEnv {
+		t.Errorf("got %q from %q, want env to take priority", v, src)
+	}
+}
+
+func TestCredentialFor(t *testing.T) {
+	c, ok := CredentialFor("linear")
+	if !ok || c.EnvVar != "LINEAR_API_KEY" || c.Service != LinearAPIKeyService {
+		t.Errorf("CredentialFor(linear) = %+v, %v", c, ok)
+	}
+	if _, ok := CredentialFor("github"); ok {
+		t.Error("github uses gh auth, not a stored token")
+	}
+}
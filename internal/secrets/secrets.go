@@ -17,6 +17,8 @@ var KnownSecretEnvVars = []string{
 	"CLAUDE_CODE_OAUTH_TOKEN",
 	"LINEAR_API_KEY",
 	"ASANA_PAT",
+	"GITLAB_TOKEN",
+	"CLICKUP_API_TOKEN",
 	"GITHUB_TOKEN",
 	"GH_TOKEN",
 }
@@ -36,26 +38,62 @@ var KnownSecretEnvVarsSet = func() map[string]struct{} {
 const (
 	AsanaPATService     = "erg/ASANA_PAT"
 	LinearAPIKeyService = "erg/LINEAR_API_KEY"
+	GitLabTokenService  = "erg/GITLAB_TOKEN"
+	ClickUpTokenService = "erg/CLICKUP_API_TOKEN"
 )
 
-// TokenNotFoundError returns a platform-appropriate error for a missing token.
-// On macOS it suggests using 'erg configure' to store in the Keychain;
-// on other platforms it only mentions the environment variable.
+// TokenNotFoundError returns an error for a missing token that points at
+// the ways to provide it.
 func TokenNotFoundError(envVar string) error {
-	if IsKeychainAvailable() {
-		return fmt.Errorf("%s not found (set env var or run 'erg configure' to store in macOS Keychain)", envVar)
+	if err, ok := expandErrs.Load(envVar); ok {
+		return fmt.Errorf("%s could not be resolved: %w", envVar, err.(error))
+	}
+	if c, ok := credentialForEnv(envVar); ok {
+		return fmt.Errorf("%s not found (set the %s environment variable or run 'erg auth set %s')", envVar, envVar, c.Provider)
 	}
 	return fmt.Errorf("%s not found (set the %s environment variable)", envVar, envVar)
 }
 
-// Get retrieves a secret from the macOS Keychain by service name.
-// Returns the value and true if found, or ("", false) on non-macOS platforms,
-// if the entry doesn't exist, or on error.
+// lookPath is used to detect secret-tool. Overridden in tests.
+var lookPath = exec.LookPath
+
+// keychainBackend returns the OS keychain erg can use: "darwin" for the
+// macOS Keychain via `security`, "secret-service" for the freedesktop
+// Secret Service (GNOME Keyring, KWallet) via `secret-tool`, or "" if none.
+func keychainBackend() string {
+	if runtime.GOOS == "darwin" {
+		return "darwin"
+	}
+	if runtime.GOOS == "linux" {
+		if _, err := lookPath("secret-tool"); err == nil {
+			return "secret-service"
+		}
+	}
+	return ""
+}
+
+// KeychainName returns a human-readable name for the OS keychain.
+func KeychainName() string {
+	if keychainBackend() == "secret-service" {
+		return "system keyring"
+	}
+	return "macOS Keychain"
+}
+
+// Get retrieves a secret from the OS keychain by service name.
+// Returns the value and true if found, or ("", false) when no keychain is
+// available, if the entry doesn't exist, or on error.
 func Get(service string) (string, bool) {
-	if runtime.GOOS != "darwin" {
+	var out []byte
+	var err error
+	switch keychainBackend() {
+	case "darwin":
+		out, err = exec.Command("security", "find-generic-password", "-s", service, "-a", keychainAccount, "-w").Output()
+	case "secret-service":
+		out, err = exec.Command("secret-tool", "lookup", "service", service, "account", keychainAccount).Output()
+	default:
 		return "", false
 	}
-	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", keychainAccount, "-w").Output()
 	if err != nil {
 		return "", false
 	}
@@ -66,25 +104,36 @@ func Get(service string) (string, bool) {
 	return val, true
 }
 
-// Set stores a secret in the macOS Keychain. Uses -U to update if it already exists.
-// Returns an error on non-macOS platforms.
+// Set stores a secret in the OS keychain, replacing any existing entry.
+// Returns an error when no keychain is available.
 func Set(service, value string) error {
-	if runtime.GOOS != "darwin" {
-		return fmt.Errorf("keychain storage is only available on macOS")
+	switch keychainBackend() {
+	case "darwin":
+		return exec.Command("security", "add-generic-password", "-s", service, "-a", keychainAccount, "-w", value, "-U").Run()
+	case "secret-service":
+		// secret-tool reads the secret from stdin, keeping it out of argv.
+		cmd := exec.Command("secret-tool", "store", "--label", service, "service", service, "account", keychainAccount)
+		cmd.Stdin = strings.NewReader(value)
+		return cmd.Run()
+	default:
+		return fmt.Errorf("keychain storage is not available on this system")
 	}
-	return exec.Command("security", "add-generic-password", "-s", service, "-a", keychainAccount, "-w", value, "-U").Run()
 }
 
-// Delete removes a secret from the macOS Keychain.
-// Returns nil on non-macOS platforms (no-op).
+// Delete removes a secret from the OS keychain.
+// Returns nil when no keychain is available (no-op).
 func Delete(service string) error {
-	if runtime.GOOS != "darwin" {
+	switch keychainBackend() {
+	case "darwin":
+		return exec.Command("security", "delete-generic-password", "-s", service, "-a", keychainAccount).Run()
+	case "secret-service":
+		return exec.Command("secret-tool", "clear", "service", service, "account", keychainAccount).Run()
+	default:
 		return nil
 	}
-	return exec.Command("security", "delete-generic-password", "-s", service, "-a", keychainAccount).Run()
 }
 
 // IsKeychainAvailable returns true if the current platform supports keychain storage.
 func IsKeychainAvailable() bool {
-	return runtime.GOOS == "darwin"
+	return keychainBackend() != ""
 }
@@ -1,18 +1,52 @@
 package secrets
 
 import (
+	"os/exec"
 	"runtime"
+	"strings"
 	"testing"
 )
 
 func TestIsKeychainAvailable(t *testing.T) {
 	got := IsKeychainAvailable()
 	want := runtime.GOOS == "darwin"
+	if runtime.GOOS == "linux" {
+		_, err := exec.LookPath("secret-tool")
+		want = err == nil
+	}
 	if got != want {
 		t.Errorf("IsKeychainAvailable() = %v, want %v", got, want)
 	}
 }
 
+func TestKeychainBackend_SecretService(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-service detection only applies on Linux")
+	}
+	orig := lookPath
+	defer func() { lookPath = orig }()
+
+	lookPath = func(string) (string, error) { return "/usr/bin/secret-tool", nil }
+	if keychainBackend() != "secret-service" || KeychainName() != "system keyring" {
+		t.Errorf("expected secret-service backend when secret-tool is installed, got %q", keychainBackend())
+	}
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	if IsKeychainAvailable() {
+		t.Error("expected no keychain without secret-tool")
+	}
+}
+
+func TestTokenNotFoundError_SuggestsAuthSet(t *testing.T) {
+	err := TokenNotFoundError("LINEAR_API_KEY")
+	if !strings.Contains(err.Error(), "erg auth set linear") {
+		t.Errorf("expected hint to run 'erg auth set linear', got %q", err)
+	}
+	err = TokenNotFoundError("SOME_OTHER_TOKEN")
+	if strings.Contains(err.Error(), "erg auth set") {
+		t.Errorf("unexpected auth hint for unknown token: %q", err)
+	}
+}
+
 func TestGetNotFound(t *testing.T) {
 	val, ok := Get("erg-test/nonexistent-service-abc123")
 	if ok {
@@ -71,9 +105,9 @@ func TestSetGetDelete(t *testing.T) {
 	}
 }
 
-func TestSetNonDarwin(t *testing.T) {
-	if runtime.GOOS == "darwin" {
-		t.Skip("this test only runs on non-macOS")
+func TestSetWithoutKeychain(t *testing.T) {
+	if IsKeychainAvailable() {
+		t.Skip("this test only runs without an OS keychain")
 	}
 	err := Set("erg-test/foo", "bar")
 	if err == nil {
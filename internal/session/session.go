@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/zhubert/erg/internal/claude"
 	"github.com/zhubert/erg/internal/config"
 	"github.com/zhubert/erg/internal/logger"
 	"github.com/zhubert/erg/internal/paths"
@@ -688,6 +689,53 @@ func detectWorktreeBranch(ctx context.Context, s *SessionService, orphan orphane
 	return branch
 }
 
+// OrphanedWorktree is a worktree in the worktrees directory that no known
+// session owns.
+type OrphanedWorktree struct {
+	Path     string // Full path to the worktree
+	RepoPath string // Repo the worktree belongs to
+	ID       string // Session ID (directory name)
+	Branch   string // Checked-out branch; empty when detached or undetectable
+}
+
+// FindOrphanedWorktrees returns the worktrees in the centralized worktrees
+// directory that belong to one of repoPaths but whose session ID is not in
+// knownSessions. Unlike PruneOrphanedWorktrees it changes nothing, so
+// callers can report orphans before deciding to remove them.
+func (s *SessionService) FindOrphanedWorktrees(ctx context.Context, repoPaths []string, knownSessions map[string]bool) ([]OrphanedWorktree, error) {
+	worktreesDir, err := paths.WorktreesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	repoPathsSet := make(map[string]bool)
+	for _, repoPath := range repoPaths {
+		repoPathsSet[repoPath] = true
+		if resolved, err := filepath.EvalSymlinks(repoPath); err == nil {
+			repoPathsSet[resolved] = true
+		}
+	}
+
+	orphans, err := findOrphansInDir(worktreesDir, knownSessions, repoPathsSet)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := make([]OrphanedWorktree, 0, len(orphans))
+	for _, orphan := range orphans {
+		result = append(result, OrphanedWorktree{
+			Path:     orphan.Path,
+			RepoPath: orphan.RepoPath,
+			ID:       orphan.ID,
+			Branch:   detectWorktreeBranch(ctx, s, orphan),
+		})
+	}
+	return result, nil
+}
+
 // PruneOrphanedWorktrees removes all orphaned worktrees and their branches.
 // Pruning operations are parallelized across repos, but serialized within each repo
 // to avoid concurrent git operations on the same repository.
@@ -757,6 +805,9 @@ func (s *SessionService) PruneOrphanedWorktrees(ctx context.Context, cfg *config
 				} else {
 					log.Info("deleted session messages", "sessionID", orphan.ID)
 				}
+				if err := claude.DeleteConversation(orphan.ID); err != nil {
+					log.Warn("failed to delete session conversation", "sessionID", orphan.ID, "error", err)
+				}
 
 				mu.Lock()
 				pruned++
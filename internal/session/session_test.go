@@ -624,6 +624,48 @@ func TestFindOrphanedWorktrees_NoWorktrees(t *testing.T) {
 	}
 }
 
+func TestSessionService_FindOrphanedWorktrees(t *testing.T) {
+	setupTestPaths(t)
+	repoPath := createTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	defer cleanupWorktrees(t, repoPath)
+
+	session, err := svc.Create(ctx, repoPath, "", "", BasePointHead)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	orphans, err := svc.FindOrphanedWorktrees(ctx, []string{repoPath}, map[string]bool{session.ID: true})
+	if err != nil {
+		t.Fatalf("FindOrphanedWorktrees failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected 0 orphans for a known session, got %d", len(orphans))
+	}
+
+	orphans, err = svc.FindOrphanedWorktrees(ctx, []string{repoPath}, nil)
+	if err != nil {
+		t.Fatalf("FindOrphanedWorktrees failed: %v", err)
+	}
+	if len(orphans) != 1 {
+		t.Fatalf("Expected 1 orphan, got %d", len(orphans))
+	}
+	if orphans[0].ID != session.ID || orphans[0].Branch != session.Branch {
+		t.Errorf("orphan = %+v, want session %s on branch %s", orphans[0], session.ID, session.Branch)
+	}
+	if _, err := os.Stat(session.WorkTree); err != nil {
+		t.Errorf("FindOrphanedWorktrees must not remove the worktree: %v", err)
+	}
+
+	orphans, err = svc.FindOrphanedWorktrees(ctx, []string{"/some/other/repo"}, nil)
+	if err != nil {
+		t.Fatalf("FindOrphanedWorktrees failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected worktrees of other repos to be ignored, got %d", len(orphans))
+	}
+}
+
 func TestPruneOrphanedWorktrees(t *testing.T) {
 	setupTestPaths(t)
 	repoPath := createTestRepo(t)
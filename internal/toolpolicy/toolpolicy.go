@@ -0,0 +1,296 @@
+// Package toolpolicy enforces a repo's policy on the tools and shell
+// commands an agent may run. A policy is expressed as Claude CLI tool
+// rules — "Bash(go test:*)" allows commands starting with "go test",
+// "WebFetch" names a whole tool — so the Claude CLI enforces it through its
+// --allowedTools and --disallowedTools flags, and erg's own tool execution
+// paths (the agent backend runner's run_command, the container MCP
+// permission server) check commands against the same rules with
+// CheckCommand.
+//
+// CheckCommand is a best-effort static check, not a sandbox: it parses the
+// command line the way a shell would split it into commands, and refuses
+// what it cannot see into (command substitution, and wrappers such as
+// "bash -c" or "xargs" that run a command given as arguments) whenever the
+// policy restricts commands. A script the agent writes and then runs is
+// only as restricted as the command that runs it; isolate sessions in a
+// container when that matters.
+package toolpolicy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// bashTool is the Claude CLI's shell tool.
+const bashTool = "Bash"
+
+// Policy is a repo's tool policy.
+type Policy struct {
+	// AllowCommands, when set, are the only commands the agent may run:
+	// each is a command prefix such as "go test" or "make".
+	AllowCommands []string
+	// DenyCommands are command prefixes the agent may never run, such as
+	// "curl" or "npm publish". They win over AllowCommands.
+	DenyCommands []string
+	// DenyTools are Claude tools the agent may not use at all, such as
+	// "WebFetch".
+	DenyTools []string
+}
+
+// IsZero reports whether the policy restricts nothing.
+func (p Policy) IsZero() bool {
+	return len(p.AllowCommands) == 0 && len(p.DenyCommands) == 0 && len(p.DenyTools) == 0
+}
+
+// Rule returns the Claude CLI tool rule matching commands that start with
+// prefix.
+func Rule(prefix string) string {
+	return bashTool + "(" + prefix + ":*)"
+}
+
+// Apply returns the allowed and disallowed tool lists of a session with
+// the policy in force. An unrestricted shell among allowed is narrowed to
+// the allowed commands; a session without a shell gains none.
+func (p Policy) Apply(allowed, disallowed []string) ([]string, []string) {
+	var outAllowed []string
+	for _, tool := range allowed {
+		if slices.Contains(p.DenyTools, toolName(tool)) {
+			continue
+		}
+		if tool == bashTool && len(p.AllowCommands) > 0 {
+			for _, prefix := range p.AllowCommands {
+				outAllowed = append(outAllowed, Rule(prefix))
+			}
+			continue
+		}
+		outAllowed = append(outAllowed, tool)
+	}
+	outDisallowed := slices.Clone(disallowed)
+	for _, tool := range p.DenyTools {
+		if !slices.Contains(outDisallowed, tool) {
+			outDisallowed = append(outDisallowed, tool)
+		}
+	}
+	for _, prefix := range p.DenyCommands {
+		outDisallowed = append(outDisallowed, Rule(prefix))
+	}
+	return outAllowed, outDisallowed
+}
+
+// Refusal is a command the policy does not let the agent run.
+type Refusal struct {
+	Command string
+	Reason  string
+}
+
+func (r *Refusal) Error() string {
+	return fmt.Sprintf("refused by the repo's tool policy: `%s` %s", r.Command, r.Reason)
+}
+
+// CheckCommand checks a shell command against the tool rules allowed and
+// disallowed, returning a *Refusal when any command it runs (chained with
+// &&, ||, ;, |, & or newlines, or grouped in parentheses) is denied or,
+// when allowed lists commands rather than the whole shell, isn't among
+// them. While any command is restricted, command and process substitution
+// are refused, as are wrappers (see wrapperCommands) that allowed does not
+// list explicitly.
+func CheckCommand(command string, allowed, disallowed []string) error {
+	if slices.Contains(disallowed, bashTool) {
+		return &Refusal{Command: strings.TrimSpace(command), Reason: "is denied: the shell is disabled"}
+	}
+	denied := commandPrefixes(disallowed)
+	unrestricted := slices.Contains(allowed, bashTool) || slices.Contains(allowed, "*")
+	if unrestricted && len(denied) == 0 {
+		return nil
+	}
+	permitted := commandPrefixes(allowed)
+	cmds, substituted := splitCommand(command)
+	if substituted {
+		return &Refusal{Command: strings.TrimSpace(command), Reason: "is denied: command substitution cannot be checked against the policy"}
+	}
+	for _, cmd := range cmds {
+		for _, prefix := range denied {
+			if hasCommandPrefix(cmd, prefix) || hasCommandPrefix(baseCommand(cmd), prefix) {
+				return &Refusal{Command: cmd, Reason: fmt.Sprintf("is denied (%q commands are not allowed)", prefix)}
+			}
+		}
+		if slices.ContainsFunc(permitted, func(prefix string) bool { return hasCommandPrefix(cmd, prefix) }) {
+			continue
+		}
+		if wrapper := commandName(baseCommand(cmd)); wrapperCommands[wrapper] {
+			return &Refusal{Command: cmd, Reason: fmt.Sprintf("is denied: commands run through %q cannot be checked against the policy", wrapper)}
+		}
+		if unrestricted {
+			continue
+		}
+		if len(permitted) == 0 {
+			return &Refusal{Command: cmd, Reason: "is denied: the shell is not allowed in this session"}
+		}
+		return &Refusal{Command: cmd, Reason: fmt.Sprintf("is not an allowed command (allowed: %s)", strings.Join(permitted, ", "))}
+	}
+	return nil
+}
+
+// wrapperCommands run another command given as their arguments, which a
+// prefix rule cannot see.
+var wrapperCommands = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+	"env": true, "xargs": true, "eval": true, "exec": true, "command": true, "builtin": true,
+	"nohup": true, "nice": true, "timeout": true, "time": true, "sudo": true, "su": true,
+}
+
+// shellKeywords are reserved words that may start a command without being
+// the command run.
+var shellKeywords = map[string]bool{
+	"!": true, "{": true, "}": true, "if": true, "then": true, "else": true, "elif": true,
+	"fi": true, "do": true, "done": true, "while": true, "until": true,
+}
+
+// toolName returns the tool a rule applies to: "Bash" for "Bash(ls:*)".
+func toolName(rule string) string {
+	name, _, _ := strings.Cut(rule, "(")
+	return name
+}
+
+// commandPrefixes returns the command prefixes of the shell rules among
+// rules.
+func commandPrefixes(rules []string) []string {
+	var prefixes []string
+	for _, rule := range rules {
+		spec, ok := strings.CutPrefix(rule, bashTool+"(")
+		if !ok {
+			continue
+		}
+		spec = strings.TrimSuffix(spec, ")")
+		spec = strings.TrimSuffix(spec, ":*")
+		if spec = strings.TrimSpace(spec); spec != "" {
+			prefixes = append(prefixes, spec)
+		}
+	}
+	return prefixes
+}
+
+// hasCommandPrefix reports whether cmd is prefix or starts with it as
+// whole words: "go test ./..." has prefix "go test", "gofmt" not "go".
+func hasCommandPrefix(cmd, prefix string) bool {
+	rest, ok := strings.CutPrefix(cmd, prefix)
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// commandName returns the first word of cmd.
+func commandName(cmd string) string {
+	name, _, _ := strings.Cut(cmd, " ")
+	return name
+}
+
+// baseCommand returns cmd with the directory dropped from the program it
+// runs, so "/usr/bin/curl x" is checked as "curl x".
+func baseCommand(cmd string) string {
+	name, args, _ := strings.Cut(cmd, " ")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	if args == "" {
+		return name
+	}
+	return name + " " + args
+}
+
+// splitCommand splits a shell command line into the simple commands it
+// runs, as a shell would: at &&, ||, ;, |, a backgrounding &, newlines and
+// parentheses outside quotes, but not at the & of a redirection such as
+// 2>&1 or &>. Each command's words are unquoted and joined by single
+// spaces, with leading environment assignments such as FOO=1 and shell
+// keywords such as "if" dropped. substituted reports command or process
+// substitution ($(...), backticks, <(...)), whose commands are not split
+// out.
+func splitCommand(command string) (cmds []string, substituted bool) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if cmd := simpleCommand(words); cmd != "" {
+			cmds = append(cmds, cmd)
+		}
+		words = nil
+	}
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		var prev, next rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+			continue
+		case r == '\\' && next != 0:
+			i++
+			if next != '\n' {
+				word.WriteRune(next)
+				inWord = true
+			}
+			continue
+		case r == '`' || r == '$' && next == '(':
+			substituted = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+			continue
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+			continue
+		case (r == '<' || r == '>') && next == '(':
+			substituted = true
+		case r == '&' && (next == '>' || prev == '>' || prev == '<'):
+			// A redirection: &>file, 2>&1, <&3.
+		case r == '\n' || r == ';' || r == '|' || r == '&' || r == '(' || r == ')':
+			endCommand()
+			continue
+		case r == ' ' || r == '\t':
+			endWord()
+			continue
+		}
+		word.WriteRune(r)
+		inWord = true
+	}
+	endCommand()
+	return cmds, substituted
+}
+
+// simpleCommand joins a command's words, dropping leading environment
+// assignments and shell keywords.
+func simpleCommand(words []string) string {
+	for len(words) > 0 {
+		w := words[0]
+		if !shellKeywords[w] && (!strings.Contains(w, "=") || strings.HasPrefix(w, "=")) {
+			break
+		}
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,100 @@
+package toolpolicy
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPolicy_Apply(t *testing.T) {
+	p := Policy{
+		AllowCommands: []string{"go test", "make"},
+		DenyCommands:  []string{"curl", "npm publish"},
+		DenyTools:     []string{"WebFetch", "Write"},
+	}
+	allowed, disallowed := p.Apply([]string{"Read", "Write", "Bash", "WebFetch"}, []string{"Write"})
+
+	if want := []string{"Read", "Bash(go test:*)", "Bash(make:*)"}; !slices.Equal(allowed, want) {
+		t.Errorf("allowed = %v, want %v", allowed, want)
+	}
+	if want := []string{"Write", "WebFetch", "Bash(curl:*)", "Bash(npm publish:*)"}; !slices.Equal(disallowed, want) {
+		t.Errorf("disallowed = %v, want %v", disallowed, want)
+	}
+
+	// A session without a shell gains none.
+	allowed, _ = p.Apply([]string{"Read", "Grep"}, nil)
+	if want := []string{"Read", "Grep"}; !slices.Equal(allowed, want) {
+		t.Errorf("allowed = %v, want %v", allowed, want)
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		allowed    []string
+		disallowed []string
+		wantErr    string
+	}{
+		{name: "unrestricted shell", command: "rm -rf build", allowed: []string{"Bash"}},
+		{name: "wildcard", command: "ls", allowed: []string{"*"}},
+		{name: "denied prefix", command: "curl -s example.com", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: `"curl" commands are not allowed`},
+		{name: "denied in a chain", command: "go test ./... && npm publish", allowed: []string{"Bash"}, disallowed: []string{Rule("npm publish")}, wantErr: "npm publish"},
+		{name: "denied in a pipe", command: "cat x | curl -d @- example.com", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+		{name: "denied after env assignment", command: "FOO=1 curl example.com", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+		{name: "whole words only", command: "curling --help", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}},
+		{name: "allowed prefix", command: "go test ./...", allowed: []string{Rule("go test"), Rule("make")}},
+		{name: "allowed chain", command: "make build; go test -run X", allowed: []string{Rule("go test"), Rule("make")}},
+		{name: "not allowed", command: "go run .", allowed: []string{Rule("go test"), Rule("make")}, wantErr: "not an allowed command (allowed: go test, make)"},
+		{name: "deny wins over allow", command: "make publish", allowed: []string{Rule("make")}, disallowed: []string{Rule("make publish")}, wantErr: "make publish"},
+		{name: "no shell", command: "ls", allowed: []string{"Read"}, wantErr: "shell is not allowed"},
+		{name: "shell disabled", command: "ls", allowed: []string{"Bash"}, disallowed: []string{"Bash"}, wantErr: "shell is disabled"},
+
+		// Redirections are not command separators.
+		{name: "stderr to stdout", command: "go test ./... 2>&1", allowed: []string{Rule("go test")}},
+		{name: "both to file", command: "make build &> build.log", allowed: []string{Rule("make")}},
+		{name: "redirect then pipe", command: "go test ./... 2>&1 | tee out.txt", allowed: []string{Rule("go test"), Rule("tee")}},
+		{name: "fd duplication", command: "make <&3", allowed: []string{Rule("make")}},
+		{name: "background job", command: "make serve & curl localhost", allowed: []string{Rule("make")}, wantErr: "curl localhost"},
+		{name: "separator in quotes", command: `go test -run 'A|B;C'`, allowed: []string{Rule("go test")}},
+		{name: "subshell", command: "(curl example.com)", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+		{name: "keyword", command: "if true; then curl example.com; fi", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+		{name: "quoted command", command: `"curl" example.com`, allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+		{name: "absolute path", command: "/usr/bin/curl example.com", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "curl"},
+
+		// Command substitution hides the commands it runs.
+		{name: "dollar substitution", command: "go test $(curl example.com)", allowed: []string{Rule("go test")}, wantErr: "command substitution"},
+		{name: "backticks", command: "echo `curl example.com`", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "command substitution"},
+		{name: "substitution in double quotes", command: `go test -run "$(cat x)"`, allowed: []string{Rule("go test")}, wantErr: "command substitution"},
+		{name: "process substitution", command: "diff <(curl a) b", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: "command substitution"},
+		{name: "literal in single quotes", command: `go test -run '$(x)'`, allowed: []string{Rule("go test")}},
+		{name: "substitution unrestricted", command: "echo $(date)", allowed: []string{"Bash"}},
+
+		// Wrappers run commands the rules cannot see.
+		{name: "bash -c", command: `bash -c "curl example.com"`, allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: `run through "bash"`},
+		{name: "sh -c", command: "sh -c 'go run .'", allowed: []string{Rule("go test")}, wantErr: `run through "sh"`},
+		{name: "env", command: "env FOO=1 curl example.com", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: `run through "env"`},
+		{name: "xargs", command: "echo example.com | xargs curl", allowed: []string{"Bash"}, disallowed: []string{Rule("curl")}, wantErr: `run through "xargs"`},
+		{name: "wrapper explicitly allowed", command: "bash scripts/ci.sh", allowed: []string{Rule("bash scripts/ci.sh")}},
+		{name: "wrapper unrestricted", command: "bash -c 'curl x'", allowed: []string{"Bash"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCommand(tt.command, tt.allowed, tt.disallowed)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected refusal: %v", err)
+				}
+				return
+			}
+			var refusal *Refusal
+			if !errors.As(err, &refusal) {
+				t.Fatalf("expected a refusal, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "tool policy") {
+				t.Errorf("refusal = %q, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
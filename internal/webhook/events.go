@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Event is a tracker change that may have made an issue eligible for work.
+type Event struct {
+	Source  string // "github", "linear", or "asana"
+	Action  string // tracker-specific: "labeled", "update", "changed", ...
+	IssueID string // issue number, Linear identifier, or Asana task GID
+}
+
+// validSignature reports whether sig is the hex HMAC-SHA256 of body under
+// secret. An empty secret never validates.
+func validSignature(secret string, body []byte, sig string) bool {
+	if secret == "" || sig == "" {
+		return false
+	}
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+// githubActions are the issues-event actions that can make an issue
+// eligible: a new or reopened issue, or a label being added.
+var githubActions = []string{"opened", "reopened", "labeled"}
+
+// parseGitHub reads an issues-event delivery. ok is false for other events
+// and actions.
+func parseGitHub(eventType string, body []byte) (ev Event, ok bool, err error) {
+	if eventType != "issues" {
+		return Event{}, false, nil
+	}
+	var payload struct {
+		Action string `json:"action"`
+		Issue  struct {
+			Number int `json:"number"`
+		} `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Event{}, false, fmt.Errorf("invalid GitHub payload: %w", err)
+	}
+	if !slices.Contains(githubActions, payload.Action) {
+		return Event{}, false, nil
+	}
+	return Event{Source: "github", Action: payload.Action, IssueID: strconv.Itoa(payload.Issue.Number)}, true, nil
+}
+
+// parseLinear reads a Linear data-change delivery. ok is false for anything
+// but a created or updated issue.
+func parseLinear(body []byte) (ev Event, ok bool, err error) {
+	var payload struct {
+		Action string `json:"action"`
+		Type   string `json:"type"`
+		Data   struct {
+			Identifier string `json:"identifier"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Event{}, false, fmt.Errorf("invalid Linear payload: %w", err)
+	}
+	if payload.Type != "Issue" || (payload.Action != "create" && payload.Action != "update") {
+		return Event{}, false, nil
+	}
+	return Event{Source: "linear", Action: payload.Action, IssueID: payload.Data.Identifier}, true, nil
+}
+
+// parseAsana reads an Asana event batch and returns its first task that was
+// added or changed. ok is false when there is none.
+func parseAsana(body []byte) (ev Event, ok bool, err error) {
+	var payload struct {
+		Events []struct {
+			Action   string `json:"action"`
+			Resource struct {
+				GID          string `json:"gid"`
+				ResourceType string `json:"resource_type"`
+			} `json:"resource"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return Event{}, false, fmt.Errorf("invalid Asana payload: %w", err)
+	}
+	for _, e := range payload.Events {
+		if e.Resource.ResourceType != "task" {
+			continue
+		}
+		if e.Action == "added" || e.Action == "changed" {
+			return Event{Source: "asana", Action: e.Action, IssueID: e.Resource.GID}, true, nil
+		}
+	}
+	return Event{}, false, nil
+}
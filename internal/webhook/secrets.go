@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/zhubert/erg/internal/paths"
+)
+
+// SecretEnvVar overrides the stored signing secret for GitHub and Linear
+// deliveries, for deployments that manage secrets outside ~/.erg.
+const SecretEnvVar = "ERG_WEBHOOK_SECRET"
+
+// Secrets holds the keys webhook deliveries are signed with.
+type Secrets struct {
+	// Secret signs GitHub and Linear deliveries. erg chooses it when the
+	// webhooks are registered.
+	Secret string `json:"secret,omitempty"`
+	// Asana maps a project GID to the secret Asana handed over in the
+	// handshake when that project's webhook was created. An empty value
+	// marks a registration awaiting its handshake.
+	Asana map[string]string `json:"asana,omitempty"`
+}
+
+// ExpectAsanaHandshake marks project as awaiting its webhook handshake and
+// clears any previous secret, so the listener accepts the new one.
+func (s *Secrets) ExpectAsanaHandshake(project string) {
+	if s.Asana == nil {
+		s.Asana = make(map[string]string)
+	}
+	s.Asana[project] = ""
+}
+
+// SecretsPath returns where webhook secrets are stored.
+func SecretsPath() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "webhooks.json"), nil
+}
+
+// LoadSecrets reads the secrets file. A missing file yields empty secrets.
+func LoadSecrets(path string) (*Secrets, error) {
+	s := &Secrets{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook secrets: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook secrets: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the secrets file, readable only by the owner.
+func (s *Secrets) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write webhook secrets: %w", err)
+	}
+	return nil
+}
+
+// SigningSecret returns the secret GitHub and Linear deliveries are signed
+// with: SecretEnvVar when set, otherwise the stored secret.
+func (s *Secrets) SigningSecret() string {
+	if env := os.Getenv(SecretEnvVar); env != "" {
+		return env
+	}
+	return s.Secret
+}
+
+// EnsureSecret returns the signing secret, generating and storing a random
+// one on first use.
+func (s *Secrets) EnsureSecret() (string, error) {
+	if secret := s.SigningSecret(); secret != "" {
+		return secret, nil
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	s.Secret = hex.EncodeToString(buf)
+	return s.Secret, nil
+}
@@ -0,0 +1,203 @@
+// Package webhook receives issue-tracker webhooks so the daemon can pick up
+// newly labeled issues as soon as they change instead of on its next poll.
+//
+// A verified delivery only wakes the daemon: the payload is never trusted to
+// describe the issue. The daemon re-queries the tracker with the same
+// filters and claims as a regular poll, so a missed delivery costs nothing
+// but latency and polling keeps running as the fallback.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zhubert/erg/internal/logger"
+)
+
+// maxBodyBytes caps a delivery body; tracker payloads are a few KB.
+const maxBodyBytes = 1 << 20
+
+// Server is the webhook HTTP listener.
+type Server struct {
+	addr        string
+	secretsPath string
+	notify      func(Event)
+	log         *slog.Logger
+
+	// mu serializes secrets-file access between deliveries and the Asana
+	// handshake, which writes the file.
+	mu sync.Mutex
+}
+
+// New creates a webhook server listening on addr. Secrets are re-read from
+// secretsPath on each delivery so `erg webhook setup` takes effect without
+// restarting the daemon. notify is called for each relevant verified event.
+func New(addr, secretsPath string, notify func(Event)) *Server {
+	return &Server{addr: addr, secretsPath: secretsPath, notify: notify, log: logger.Get()}
+}
+
+// Handler returns the webhook routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhooks/github", s.handleGitHub)
+	mux.HandleFunc("POST /webhooks/linear", s.handleLinear)
+	mux.HandleFunc("POST /webhooks/asana/{project}", s.handleAsana)
+	return mux
+}
+
+// Run serves until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info("webhook listener started", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) loadSecrets() (*Secrets, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return LoadSecrets(s.secretsPath)
+}
+
+// readBody reads a delivery body, writing an error response on failure.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "body too large or unreadable", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// dispatch answers a parsed delivery: 202 and notify when relevant, 200 when
+// verified but not relevant, 400 when the payload is malformed.
+func (s *Server) dispatch(w http.ResponseWriter, ev Event, ok bool, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	s.log.Debug("webhook event received", "source", ev.Source, "action", ev.Action, "issue", ev.IssueID)
+	s.notify(ev)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleGitHub(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	secrets, err := s.loadSecrets()
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	const prefix = "sha256="
+	sig := r.Header.Get("X-Hub-Signature-256")
+	if len(sig) <= len(prefix) || sig[:len(prefix)] != prefix ||
+		!validSignature(secrets.SigningSecret(), body, sig[len(prefix):]) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	ev, relevant, err := parseGitHub(r.Header.Get("X-GitHub-Event"), body)
+	s.dispatch(w, ev, relevant, err)
+}
+
+func (s *Server) handleLinear(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	secrets, err := s.loadSecrets()
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if !validSignature(secrets.SigningSecret(), body, r.Header.Get("Linear-Signature")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	ev, relevant, err := parseLinear(body)
+	s.dispatch(w, ev, relevant, err)
+}
+
+// handleAsana serves both the webhook handshake and event deliveries. The
+// handshake carries X-Hook-Secret, which is stored for the project and
+// echoed back to confirm the webhook.
+func (s *Server) handleAsana(w http.ResponseWriter, r *http.Request) {
+	project := r.PathValue("project")
+	if hookSecret := r.Header.Get("X-Hook-Secret"); hookSecret != "" {
+		if err := s.storeAsanaSecret(project, hookSecret); err != nil {
+			s.log.Warn("asana webhook handshake rejected", "project", project, "error", err)
+			http.Error(w, "handshake rejected", http.StatusForbidden)
+			return
+		}
+		s.log.Info("asana webhook handshake completed", "project", project)
+		w.Header().Set("X-Hook-Secret", hookSecret)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	secrets, err := s.loadSecrets()
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if !validSignature(secrets.Asana[project], body, r.Header.Get("X-Hook-Signature")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	ev, relevant, err := parseAsana(body)
+	s.dispatch(w, ev, relevant, err)
+}
+
+// storeAsanaSecret records a handshake secret. The handshake itself is
+// unauthenticated, so it is only accepted for a project that `erg webhook
+// setup` marked as pending; an established secret is never replaced.
+func (s *Server) storeAsanaSecret(project, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := LoadSecrets(s.secretsPath)
+	if err != nil {
+		return err
+	}
+	existing, pending := secrets.Asana[project]
+	if !pending {
+		return fmt.Errorf("no pending webhook registration for project %s", project)
+	}
+	if existing != "" && existing != secret {
+		return fmt.Errorf("project %s already has a webhook secret", project)
+	}
+	secrets.Asana[project] = secret
+	return secrets.Save(s.secretsPath)
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newTestServer returns a server whose secrets file holds secrets, and a
+// pointer to the events it has notified.
+func newTestServer(t *testing.T, secrets *Secrets) (*Server, *[]Event) {
+	t.Helper()
+	t.Setenv(SecretEnvVar, "")
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	if secrets != nil {
+		if err := secrets.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var events []Event
+	return New("localhost:0", path, func(ev Event) { events = append(events, ev) }), &events
+}
+
+func post(s *Server, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGitHubDelivery(t *testing.T) {
+	s, events := newTestServer(t, &Secrets{Secret: "shh"})
+	labeled := `{"action":"labeled","issue":{"number":42}}`
+
+	tests := []struct {
+		name      string
+		event     string
+		body      string
+		signature string
+		wantCode  int
+	}{
+		{"labeled issue", "issues", labeled, "sha256=" + sign("shh", labeled), http.StatusAccepted},
+		{"wrong secret", "issues", labeled, "sha256=" + sign("nope", labeled), http.StatusUnauthorized},
+		{"missing prefix", "issues", labeled, sign("shh", labeled), http.StatusUnauthorized},
+		{"ping", "ping", `{}`, "sha256=" + sign("shh", `{}`), http.StatusOK},
+		{"closed issue", "issues", `{"action":"closed"}`, "sha256=" + sign("shh", `{"action":"closed"}`), http.StatusOK},
+		{"malformed", "issues", `{`, "sha256=" + sign("shh", `{`), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(s, "/webhooks/github", tt.body, map[string]string{
+				"X-GitHub-Event":      tt.event,
+				"X-Hub-Signature-256": tt.signature,
+			})
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+
+	if len(*events) != 1 {
+		t.Fatalf("expected 1 event, got %d: %+v", len(*events), *events)
+	}
+	if got := (*events)[0]; got != (Event{Source: "github", Action: "labeled", IssueID: "42"}) {
+		t.Errorf("event = %+v", got)
+	}
+}
+
+func TestGitHubDelivery_EnvSecretOverridesStored(t *testing.T) {
+	s, events := newTestServer(t, &Secrets{Secret: "stored"})
+	t.Setenv(SecretEnvVar, "from-env")
+	body := `{"action":"opened","issue":{"number":1}}`
+
+	rec := post(s, "/webhooks/github", body, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": "sha256=" + sign("stored", body),
+	})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("stored secret: status = %d, want 401", rec.Code)
+	}
+	rec = post(s, "/webhooks/github", body, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": "sha256=" + sign("from-env", body),
+	})
+	if rec.Code != http.StatusAccepted || len(*events) != 1 {
+		t.Errorf("env secret: status = %d, events = %d", rec.Code, len(*events))
+	}
+}
+
+func TestDelivery_RejectedWithoutSecret(t *testing.T) {
+	s, events := newTestServer(t, nil)
+	body := `{"action":"opened","issue":{"number":1}}`
+	rec := post(s, "/webhooks/github", body, map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-Hub-Signature-256": "sha256=" + sign("", body),
+	})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	if len(*events) != 0 {
+		t.Errorf("expected no events, got %+v", *events)
+	}
+}
+
+func TestLinearDelivery(t *testing.T) {
+	s, events := newTestServer(t, &Secrets{Secret: "shh"})
+	update := `{"action":"update","type":"Issue","data":{"identifier":"ENG-7"}}`
+	comment := `{"action":"create","type":"Comment","data":{}}`
+
+	if rec := post(s, "/webhooks/linear", update, map[string]string{"Linear-Signature": sign("shh", update)}); rec.Code != http.StatusAccepted {
+		t.Errorf("issue update: status = %d, want 202", rec.Code)
+	}
+	if rec := post(s, "/webhooks/linear", comment, map[string]string{"Linear-Signature": sign("shh", comment)}); rec.Code != http.StatusOK {
+		t.Errorf("comment: status = %d, want 200", rec.Code)
+	}
+	if rec := post(s, "/webhooks/linear", update, map[string]string{"Linear-Signature": "deadbeef"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d, want 401", rec.Code)
+	}
+
+	if len(*events) != 1 || (*events)[0].IssueID != "ENG-7" {
+		t.Errorf("events = %+v", *events)
+	}
+}
+
+func TestAsanaHandshakeAndDelivery(t *testing.T) {
+	pending := &Secrets{}
+	pending.ExpectAsanaHandshake("123")
+	s, events := newTestServer(t, pending)
+
+	// Handshake for a project that was never registered is refused.
+	rec := post(s, "/webhooks/asana/999", "", map[string]string{"X-Hook-Secret": "rogue"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("unregistered handshake: status = %d, want 403", rec.Code)
+	}
+
+	rec = post(s, "/webhooks/asana/123", "", map[string]string{"X-Hook-Secret": "hook-secret"})
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Hook-Secret") != "hook-secret" {
+		t.Fatalf("handshake: status = %d, echoed %q", rec.Code, rec.Header().Get("X-Hook-Secret"))
+	}
+	stored, err := LoadSecrets(s.secretsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Asana["123"] != "hook-secret" {
+		t.Errorf("stored secret = %q", stored.Asana["123"])
+	}
+
+	// A second handshake cannot replace the established secret.
+	rec = post(s, "/webhooks/asana/123", "", map[string]string{"X-Hook-Secret": "other"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("replacement handshake: status = %d, want 403", rec.Code)
+	}
+
+	added := `{"events":[{"action":"changed","resource":{"gid":"555","resource_type":"story"}},{"action":"added","resource":{"gid":"777","resource_type":"task"}}]}`
+	rec = post(s, "/webhooks/asana/123", added, map[string]string{"X-Hook-Signature": sign("hook-secret", added)})
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("delivery: status = %d, want 202", rec.Code)
+	}
+	heartbeat := `{"events":[]}`
+	rec = post(s, "/webhooks/asana/123", heartbeat, map[string]string{"X-Hook-Signature": sign("hook-secret", heartbeat)})
+	if rec.Code != http.StatusOK {
+		t.Errorf("heartbeat: status = %d, want 200", rec.Code)
+	}
+	rec = post(s, "/webhooks/asana/999", added, map[string]string{"X-Hook-Signature": sign("hook-secret", added)})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("other project: status = %d, want 401", rec.Code)
+	}
+
+	if len(*events) != 1 || (*events)[0] != (Event{Source: "asana", Action: "added", IssueID: "777"}) {
+		t.Errorf("events = %+v", *events)
+	}
+}
+
+func TestSecrets_EnsureSecretAndRoundTrip(t *testing.T) {
+	t.Setenv(SecretEnvVar, "")
+	path := filepath.Join(t.TempDir(), "nested", "webhooks.json")
+
+	s, err := LoadSecrets(path)
+	if err != nil {
+		t.Fatalf("missing file should load empty: %v", err)
+	}
+	first, err := s.EnsureSecret()
+	if err != nil || len(first) != 64 {
+		t.Fatalf("EnsureSecret = %q, %v", first, err)
+	}
+	if second, _ := s.EnsureSecret(); second != first {
+		t.Errorf("EnsureSecret regenerated: %q != %q", second, first)
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSecrets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Secret != first {
+		t.Errorf("round trip secret = %q, want %q", loaded.Secret, first)
+	}
+}
@@ -100,6 +100,12 @@ func FormatInitialMessage(ref config.IssueRef, body string) string {
 		header = fmt.Sprintf("Asana Task: %s\n\n%s", safeTitle, ref.URL)
 	case issues.SourceLinear:
 		header = fmt.Sprintf("Linear Issue %s: %s\n\n%s", ref.ID, safeTitle, ref.URL)
+	case issues.SourceGitLab:
+		header = fmt.Sprintf("GitLab Issue #%s: %s\n\n%s", ref.ID, safeTitle, ref.URL)
+	case issues.SourceClickUp:
+		header = fmt.Sprintf("ClickUp Task: %s\n\n%s", safeTitle, ref.URL)
+	case issues.SourceFile:
+		header = fmt.Sprintf("Backlog Item %s: %s\n\n%s", ref.ID, safeTitle, ref.URL)
 	default:
 		header = fmt.Sprintf("Issue %s: %s\n\n%s", ref.ID, safeTitle, ref.URL)
 	}
@@ -220,6 +220,16 @@ func TestFormatInitialMessage(t *testing.T) {
 			body:     "We need unit tests for the auth module",
 			contains: []string{"Linear Issue ENG-123", "Add tests", "https://linear.app/team/issue/ENG-123", "We need unit tests for the auth module"},
 		},
+		{
+			name:     "GitLab issue",
+			ref:      config.IssueRef{Source: "gitlab", ID: "7", Title: "Fix CI", URL: "https://gitlab.com/group/project/-/issues/7"},
+			contains: []string{"GitLab Issue #7", "Fix CI", "https://gitlab.com/group/project/-/issues/7"},
+		},
+		{
+			name:     "ClickUp task",
+			ref:      config.IssueRef{Source: "clickup", ID: "86b0xyz12", Title: "Add export", URL: "https://app.clickup.com/t/86b0xyz12"},
+			contains: []string{"ClickUp Task", "Add export", "https://app.clickup.com/t/86b0xyz12"},
+		},
 		{
 			name:     "unknown provider",
 			ref:      config.IssueRef{Source: "jira", ID: "PROJ-1", Title: "Migrate DB", URL: "https://jira.example.com/1"},
@@ -48,6 +48,10 @@ type Host interface {
 	// the given session ID.
 	RecordItemSpend(sessionID string, costUSD float64, outputTokens, inputTokens int)
 
+	// RecordToolUse records a tool call the session made, given its brief
+	// input description, in its work item's audit trail.
+	RecordToolUse(sessionID, tool, input string)
+
 	// SetWorkItemData stores a key-value pair in the work item's StepData
 	// for the session identified by sessionID.
 	SetWorkItemData(sessionID, key string, value any) error
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// StepData keys holding a coding session's verification verdict.
+const (
+	VerificationPassedKey  = "verification_passed"  // bool: the agent confirmed the criteria are met
+	VerificationSummaryKey = "verification_summary" // string: the agent's criterion-by-criterion check
+	VerificationRoundsKey  = "verification_rounds"  // int: verification turns the session took
+)
+
+// DefaultVerifyRounds is how many verification turns a session gets when
+// the state doesn't say.
+const DefaultVerifyRounds = 2
+
+// verificationTag matches the verdict a verification turn ends with.
+var verificationTag = regexp.MustCompile(`(?s)<verification\s+status="(met|unmet)"\s*>(.*?)</verification>`)
+
+// Verdict is the outcome of a verification turn.
+type Verdict struct {
+	Met     bool
+	Summary string
+}
+
+// ParseVerdict returns the last verdict in an agent's reply, and false if
+// the reply has none.
+func ParseVerdict(reply string) (Verdict, bool) {
+	matches := verificationTag.FindAllStringSubmatch(reply, -1)
+	if len(matches) == 0 {
+		return Verdict{}, false
+	}
+	m := matches[len(matches)-1]
+	return Verdict{Met: m[1] == "met", Summary: strings.TrimSpace(m[2])}, true
+}
+
+// verificationPrompt asks the agent to check its work against the issue
+// before the session completes. round counts from 1.
+func verificationPrompt(baseBranch string, round int) string {
+	ref := "origin/HEAD"
+	if baseBranch != "" {
+		ref = "origin/" + baseBranch
+	}
+	intro := "Before you finish, verify your work."
+	if round > 1 {
+		intro = "Your last reply did not confirm the acceptance criteria are met. Verify again."
+	}
+	return fmt.Sprintf(`%s
+1. Re-read the issue at the start of this conversation and list its acceptance criteria. If it states none, infer them from what it asks for.
+2. Review everything you changed, committed or not: run "git diff $(git merge-base %s HEAD)".
+3. Check each criterion against the diff, citing the file or test that satisfies it.
+
+If any criterion is not met, keep working until it is, then verify again.
+End your reply with your verdict, exactly one of:
+<verification status="met">each criterion and the evidence it is met</verification>
+<verification status="unmet">each criterion not met and why you could not meet it</verification>`, intro, ref)
+}
+
+// verify reads the verdict of the last verification turn, if one was asked
+// for, and reports whether to ask for another. Once it won't, the verdict —
+// unmet if Claude never gave one — is stored in the work item's StepData.
+func (w *SessionWorker) verify(log *slog.Logger) bool {
+	if w.verifyAsked == 0 {
+		w.verifyAsked++
+		log.Info("asking session to verify its work", "round", w.verifyAsked)
+		return true
+	}
+	verdict, ok := ParseVerdict(w.lastReply())
+	if !ok {
+		verdict.Summary = "The session gave no verification verdict."
+	}
+	if !verdict.Met && w.verifyAsked < w.verifyRounds {
+		w.verifyAsked++
+		log.Info("verification not confirmed, asking again", "round", w.verifyAsked)
+		return true
+	}
+
+	w.verifyDone = true
+	log.Info("verification finished", "met", verdict.Met, "rounds", w.verifyAsked)
+	for key, value := range map[string]any{
+		VerificationPassedKey:  verdict.Met,
+		VerificationSummaryKey: verdict.Summary,
+		VerificationRoundsKey:  w.verifyAsked,
+	} {
+		if err := w.host.SetWorkItemData(w.sessionID, key, value); err != nil {
+			log.Warn("failed to store verification verdict", "key", key, "error", err)
+		}
+	}
+	return false
+}
+
+// lastReply returns Claude's last non-empty reply in the session.
+func (w *SessionWorker) lastReply() string {
+	msgs := w.runner.GetMessages()
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "assistant" && strings.TrimSpace(msgs[i].Content) != "" {
+			return msgs[i].Content
+		}
+	}
+	return ""
+}
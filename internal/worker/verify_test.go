@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhubert/erg/internal/claude"
+	"github.com/zhubert/erg/internal/config"
+	"github.com/zhubert/erg/internal/exec"
+)
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		reply  string
+		want   Verdict
+		wantOK bool
+	}{
+		{reply: "All good.", wantOK: false},
+		{reply: "Checked.\n<verification status=\"met\">\n- Retries 503: client_test.go\n</verification>", want: Verdict{Met: true, Summary: "- Retries 503: client_test.go"}, wantOK: true},
+		{reply: `<verification status="met">draft</verification> then <verification status="unmet">no docs</verification>`, want: Verdict{Summary: "no docs"}, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := ParseVerdict(tt.reply)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseVerdict(%q) = %+v, %v; want %+v, %v", tt.reply, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// verifyingWorker runs a session that answers each verification prompt with
+// the next of replies, and returns the prompts it was sent.
+func verifyingWorker(t *testing.T, rounds int, replies ...string) (*mockHost, *SessionWorker, []string) {
+	t.Helper()
+	h := newMockHost(exec.NewMockExecutor(nil))
+	sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "issue-1", BaseBranch: "main"}
+	h.cfg.AddSession(*sess)
+
+	runner := claude.NewMockRunner("s1", false, nil)
+	runner.QueueResponse(
+		claude.ResponseChunk{Type: claude.ChunkTypeText, Content: "Implemented."},
+		claude.ResponseChunk{Done: true},
+	)
+	var prompts []string
+	runner.OnSend = func(content []claude.ContentBlock) {
+		text := content[0].Text
+		if !strings.Contains(text, "<verification status=") {
+			return
+		}
+		prompts = append(prompts, text)
+		reply := replies[0]
+		replies = replies[1:]
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			runner.AddAssistantMessage(reply)
+			runner.InjectChunk(claude.ResponseChunk{Done: true})
+		}()
+	}
+
+	w := NewSessionWorker(h, sess, runner, "Fix issue #1")
+	w.SetVerification(rounds)
+	w.Start(t.Context())
+	w.Wait()
+	return h, w, prompts
+}
+
+func TestVerification_ConfirmedOnFirstRound(t *testing.T) {
+	h, w, prompts := verifyingWorker(t, 2, `<verification status="met">Both criteria met.</verification>`)
+
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "git merge-base origin/main HEAD") {
+		t.Fatalf("expected one verification prompt diffing against main, got %q", prompts)
+	}
+	data := h.workItemData["s1"]
+	if data[VerificationPassedKey] != true || data[VerificationSummaryKey] != "Both criteria met." || data[VerificationRoundsKey] != 1 {
+		t.Errorf("unexpected verdict: %+v", data)
+	}
+	if w.Turns() != 2 {
+		t.Errorf("expected 2 turns, got %d", w.Turns())
+	}
+}
+
+func TestVerification_AsksAgainUntilRoundsRunOut(t *testing.T) {
+	h, _, prompts := verifyingWorker(t, 2, "I think it's done.", `<verification status="unmet">No test for the 503 path.</verification>`)
+
+	if len(prompts) != 2 || !strings.Contains(prompts[1], "Verify again") {
+		t.Fatalf("expected a second verification prompt, got %q", prompts)
+	}
+	data := h.workItemData["s1"]
+	if data[VerificationPassedKey] != false || data[VerificationSummaryKey] != "No test for the 503 path." || data[VerificationRoundsKey] != 2 {
+		t.Errorf("unexpected verdict: %+v", data)
+	}
+}
@@ -3,14 +3,18 @@ package worker
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/zhubert/erg/internal/agentbackend"
 	"github.com/zhubert/erg/internal/claude"
 	"github.com/zhubert/erg/internal/config"
 	"github.com/zhubert/erg/internal/mcp"
+	"github.com/zhubert/erg/internal/workflow"
 )
 
 // SessionWorker manages a single autonomous session's lifecycle.
@@ -20,7 +24,7 @@ type SessionWorker struct {
 	host       Host
 	sessionID  string
 	session    *config.Session
-	runner     claude.RunnerSession
+	runner     agentbackend.Backend
 	initialMsg string
 	turns      atomic.Int32 // written from run() goroutine; read externally — use atomics
 	startTime  time.Time
@@ -41,10 +45,30 @@ type SessionWorker struct {
 	// if Claude tries to finish without calling comment_issue.
 	planningMode       bool
 	commentIssuePosted bool
+
+	// Result contract: when set, submit_result must fill in the required
+	// fields, and the worker asks again if Claude finishes without a valid
+	// result, up to maxResultCorrections times.
+	resultContract    bool
+	resultRequired    []string
+	resultSubmitted   bool
+	resultCorrections int
+
+	// Verification: when verifyRounds is set, the worker asks Claude to
+	// check its work against the issue before the session completes, and
+	// again while it doesn't confirm the criteria are met, up to
+	// verifyRounds times. The verdict is stored in the work item's StepData.
+	verifyRounds int
+	verifyAsked  int
+	verifyDone   bool
 }
 
+// maxResultCorrections is how many times a worker with a result contract
+// asks Claude for a missing or malformed result before giving up.
+const maxResultCorrections = 2
+
 // NewSessionWorker creates a new session worker.
-func NewSessionWorker(host Host, sess *config.Session, runner claude.RunnerSession, initialMsg string) *SessionWorker {
+func NewSessionWorker(host Host, sess *config.Session, runner agentbackend.Backend, initialMsg string) *SessionWorker {
 	return &SessionWorker{
 		host:       host,
 		sessionID:  sess.ID,
@@ -67,7 +91,8 @@ func NewDoneWorker() *SessionWorker {
 }
 
 // NewDoneWorkerWithError creates a SessionWorker that is already done with an error.
-// Used by tests in other packages that need a completed-with-error worker.
+// Used for sessions that fail before their runner starts, and by tests in
+// other packages that need a completed-with-error worker.
 func NewDoneWorkerWithError(err error) *SessionWorker {
 	w := &SessionWorker{
 		done: make(chan struct{}),
@@ -77,6 +102,17 @@ func NewDoneWorkerWithError(err error) *SessionWorker {
 	return w
 }
 
+// PanicError is the exit error of a worker whose goroutine panicked. The panic
+// is recovered so one misbehaving session cannot take down the daemon.
+type PanicError struct {
+	Value any    // value passed to panic
+	Stack []byte // goroutine stack at the point of the panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("worker panicked: %v", e.Value)
+}
+
 // Turns returns the number of completed turns.
 func (w *SessionWorker) Turns() int {
 	return int(w.turns.Load())
@@ -110,6 +146,22 @@ func (w *SessionWorker) SetPlanningMode(enabled bool) {
 	w.planningMode = enabled
 }
 
+// SetResultContract requires the session to end with a valid submit_result
+// call that fills in each of required (see workflow.ResultFields). A
+// submission missing a field is rejected so Claude can resubmit, and a
+// session that finishes without a valid result is asked for one.
+// Must be called before Start.
+func (w *SessionWorker) SetResultContract(required []string) {
+	w.resultContract = true
+	w.resultRequired = required
+}
+
+// SetVerification makes the session verify its work against the issue
+// before completing, taking up to rounds verification turns.
+func (w *SessionWorker) SetVerification(rounds int) {
+	w.verifyRounds = rounds
+}
+
 // SetLimits overrides the per-session turn and duration limits.
 // Must be called before Start. Zero values fall back to host defaults.
 func (w *SessionWorker) SetLimits(maxTurns int, maxDuration time.Duration) {
@@ -130,8 +182,12 @@ func (w *SessionWorker) ExitError() error {
 	return nil
 }
 
-// Start begins the worker's goroutine.
+// Start begins the worker's goroutine. It does nothing for a worker that is
+// already done.
 func (w *SessionWorker) Start(ctx context.Context) {
+	if w.Done() {
+		return
+	}
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	go w.run()
 }
@@ -170,6 +226,9 @@ func (w *SessionWorker) run() {
 	defer w.once.Do(func() { close(w.done) })
 
 	log := w.host.Logger().With("sessionID", w.sessionID, "branch", w.session.Branch)
+	// Runs before done is closed, so ExitError reports the panic to whoever
+	// observes Done.
+	defer w.recoverPanic(log)
 	log.Info("worker started")
 
 	// Send initial message
@@ -213,6 +272,30 @@ func (w *SessionWorker) run() {
 			continue
 		}
 
+		// Verification: have Claude check its work against the issue,
+		// continuing until it confirms the criteria are met.
+		if w.verifyRounds > 0 && !w.verifyDone && w.verify(log) {
+			content := []claude.ContentBlock{{Type: claude.ContentTypeText, Text: verificationPrompt(w.session.BaseBranch, w.verifyAsked)}}
+			responseChan = w.runner.SendContent(w.ctx, content)
+			continue
+		}
+
+		// Result contract guard: ask for the structured result the state
+		// needs before letting the session complete without it.
+		if w.resultContract && !w.resultSubmitted && w.resultCorrections < maxResultCorrections {
+			w.resultCorrections++
+			log.Warn("session finishing without a valid result, sending correction", "attempt", w.resultCorrections)
+			correction := "You have not yet submitted a valid result. " +
+				"You MUST call the submit_result MCP tool"
+			if len(w.resultRequired) > 0 {
+				correction += " with " + strings.Join(w.resultRequired, ", ") + " filled in"
+			}
+			correction += " before finishing. Do that now."
+			content := []claude.ContentBlock{{Type: claude.ContentTypeText, Text: correction}}
+			responseChan = w.runner.SendContent(w.ctx, content)
+			continue
+		}
+
 		// Check for pending messages (e.g., child completion notifications)
 		pendingMsg := w.host.GetPendingMessage(w.sessionID)
 		if pendingMsg != "" {
@@ -229,6 +312,20 @@ func (w *SessionWorker) run() {
 	}
 }
 
+// recoverPanic converts a panic in the worker goroutine into a PanicError exit
+// error and cancels the session so the runner stops streaming.
+func (w *SessionWorker) recoverPanic(log *slog.Logger) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	pe := &PanicError{Value: r, Stack: debug.Stack()}
+	var err error = pe
+	w.exitErr.Store(&err)
+	log.Error("worker panicked", "panic", r, "turns", w.turns.Load(), "stack", string(pe.Stack))
+	w.Cancel()
+}
+
 // processOneResponse processes a single streaming response from Claude.
 // It blocks until the response is done or an error occurs.
 // Returns nil when the response completes normally, or an error to stop the worker.
@@ -315,6 +412,12 @@ func (w *SessionWorker) processOneResponse(responseChan <-chan claude.ResponseCh
 				continue
 			}
 			w.handleSubmitReview(req)
+
+		case req, ok := <-w.safeChanSubmitResult():
+			if !ok {
+				continue
+			}
+			w.handleSubmitResult(req)
 		}
 	}
 }
@@ -360,7 +463,16 @@ func (w *SessionWorker) safeChanSubmitReview() <-chan mcp.SubmitReviewRequest {
 	return ch
 }
 
-// handleStreaming logs streaming progress and records spend from final stats chunks.
+func (w *SessionWorker) safeChanSubmitResult() <-chan mcp.SubmitResultRequest {
+	ch := w.runner.SubmitResultRequestChan()
+	if ch == nil {
+		return nil
+	}
+	return ch
+}
+
+// handleStreaming logs streaming progress and records tool calls and the
+// spend of final stats chunks.
 func (w *SessionWorker) handleStreaming(chunk claude.ResponseChunk) {
 	if chunk.Type == claude.ChunkTypeText && chunk.Content != "" {
 		// Detect API errors emitted as text content (e.g., 500 errors from
@@ -378,6 +490,10 @@ func (w *SessionWorker) handleStreaming(chunk claude.ResponseChunk) {
 		w.host.Logger().Debug("streaming", "sessionID", w.sessionID, "content", preview)
 	}
 
+	if chunk.Type == claude.ChunkTypeToolUse && chunk.ToolName != "" {
+		w.host.RecordToolUse(w.sessionID, chunk.ToolName, chunk.ToolInput)
+	}
+
 	// Record spend from the final stats chunk (identified by DurationMs > 0,
 	// which is only set on the result message, not on intermediate streaming chunks).
 	if chunk.Type == claude.ChunkTypeStreamStats && chunk.Stats != nil && chunk.Stats.DurationMs > 0 {
@@ -636,3 +752,45 @@ func (w *SessionWorker) handleSubmitReview(req mcp.SubmitReviewRequest) {
 		Success: true,
 	})
 }
+
+// handleSubmitResult handles a submit_result MCP tool call.
+// It validates the result envelope and stores it in the work item's StepData
+// so the workflow engine can branch on it when the session completes.
+func (w *SessionWorker) handleSubmitResult(req mcp.SubmitResultRequest) {
+	log := w.host.Logger().With("sessionID", w.sessionID)
+
+	result := workflow.StateResult{
+		Status:       req.Status,
+		Summary:      req.Summary,
+		FilesChanged: req.FilesChanged,
+		FollowUps:    req.FollowUps,
+		Confidence:   req.Confidence,
+		Learnings:    req.Learnings,
+		Plan:         req.Plan,
+		RiskLevel:    req.RiskLevel,
+		Risks:        req.Risks,
+	}
+	if err := result.ValidateRequired(w.resultRequired); err != nil {
+		w.runner.SendSubmitResultResponse(mcp.SubmitResultResponse{
+			ID:    req.ID,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	log.Info("storing step result via MCP tool", "status", result.Status, "confidence", result.Confidence)
+
+	if err := w.host.SetWorkItemData(w.sessionID, workflow.ResultStepDataKey, result.ToStepData()); err != nil {
+		w.runner.SendSubmitResultResponse(mcp.SubmitResultResponse{
+			ID:    req.ID,
+			Error: fmt.Sprintf("Failed to store result: %v", err),
+		})
+		return
+	}
+
+	w.resultSubmitted = true
+	w.runner.SendSubmitResultResponse(mcp.SubmitResultResponse{
+		ID:      req.ID,
+		Success: true,
+	})
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/zhubert/erg/internal/git"
 	"github.com/zhubert/erg/internal/mcp"
 	"github.com/zhubert/erg/internal/testutil"
+	"github.com/zhubert/erg/internal/workflow"
 )
 
 // mockHost implements worker.Host for unit testing.
@@ -43,6 +45,7 @@ type mockHost struct {
 	commentOnIssueCalls []commentOnIssueCall      // recorded calls
 	upsertIssueErr      error                     // error to return from UpsertIssueComment
 	upsertIssueCalls    []upsertIssueCall         // recorded calls
+	toolUses            []string                  // "tool: input" of recorded tool calls
 }
 
 type commentOnIssueCall struct {
@@ -120,6 +123,10 @@ func (h *mockHost) RecordSpend(costUSD float64, outputTokens, inputTokens int) {
 func (h *mockHost) RecordItemSpend(sessionID string, costUSD float64, outputTokens, inputTokens int) {
 }
 
+func (h *mockHost) RecordToolUse(sessionID, tool, input string) {
+	h.toolUses = append(h.toolUses, tool+": "+input)
+}
+
 func (h *mockHost) CommentOnIssue(ctx context.Context, sessionID, body string) error {
 	h.commentOnIssueCalls = append(h.commentOnIssueCalls, commentOnIssueCall{SessionID: sessionID, Body: body})
 	return h.commentOnIssueErr
@@ -374,6 +381,19 @@ func TestSessionWorker_HandleStreaming(t *testing.T) {
 		Type:    claude.ChunkTypeToolUse,
 		Content: "",
 	})
+	if len(h.toolUses) != 0 {
+		t.Errorf("tool chunk without a name recorded: %v", h.toolUses)
+	}
+
+	// Tool calls are recorded for the audit trail
+	w.handleStreaming(claude.ResponseChunk{
+		Type:      claude.ChunkTypeToolUse,
+		ToolName:  "Bash",
+		ToolInput: "go test ./...",
+	})
+	if len(h.toolUses) != 1 || h.toolUses[0] != "Bash: go test ./..." {
+		t.Errorf("tool uses = %v", h.toolUses)
+	}
 
 	// Should not panic for long content (triggers truncation)
 	longContent := make([]byte, 200)
@@ -525,6 +545,46 @@ func TestSessionWorker_ExitError_NilOnSuccess(t *testing.T) {
 	}
 }
 
+// panicHost is a Host whose GetPendingMessage panics, simulating a bug that
+// fires inside the worker goroutine.
+type panicHost struct{ *mockHost }
+
+func (panicHost) GetPendingMessage(string) string { panic("boom") }
+
+func TestSessionWorker_ExitError_RecoversPanic(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	h := newMockHost(mockExec)
+
+	sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "feat-1"}
+	h.cfg.AddSession(*sess)
+
+	runner := claude.NewMockRunner("s1", false, nil)
+	runner.QueueResponse(
+		claude.ResponseChunk{Type: claude.ChunkTypeText, Content: "All done"},
+		claude.ResponseChunk{Done: true},
+	)
+
+	w := NewSessionWorker(panicHost{h}, sess, runner, "Do something")
+	w.Start(t.Context())
+
+	select {
+	case <-w.DoneChan():
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not finish after panic")
+	}
+
+	var pe *PanicError
+	if !errors.As(w.ExitError(), &pe) {
+		t.Fatalf("expected PanicError, got %v", w.ExitError())
+	}
+	if pe.Value != "boom" {
+		t.Errorf("panic value = %v, want boom", pe.Value)
+	}
+	if !strings.Contains(string(pe.Stack), "GetPendingMessage") {
+		t.Errorf("expected stack to include the panicking frame, got:\n%s", pe.Stack)
+	}
+}
+
 func TestSessionWorker_ExitError_SetOnChunkError(t *testing.T) {
 	mockExec := exec.NewMockExecutor(nil)
 	h := newMockHost(mockExec)
@@ -1000,6 +1060,146 @@ func TestSessionWorker_HandleSubmitReview_Passed(t *testing.T) {
 	}
 }
 
+func TestSessionWorker_HandleSubmitResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       mcp.SubmitResultRequest
+		wantStore bool
+	}{
+		{
+			name:      "valid envelope stored",
+			req:       mcp.SubmitResultRequest{ID: 1, Status: "success", Summary: "done", FilesChanged: []string{"a.go"}, Confidence: 0.9},
+			wantStore: true,
+		},
+		{
+			name: "unknown status rejected",
+			req:  mcp.SubmitResultRequest{ID: 1, Status: "great", Summary: "done"},
+		},
+		{
+			name: "confidence out of range rejected",
+			req:  mcp.SubmitResultRequest{ID: 1, Status: "success", Confidence: 1.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := exec.NewMockExecutor(nil)
+			h := newMockHost(mockExec)
+
+			sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "feat-1"}
+			h.cfg.AddSession(*sess)
+
+			runner := claude.NewMockRunner("s1", false, nil)
+			runner.SetHostTools(true)
+			w := NewSessionWorker(h, sess, runner, "test")
+
+			w.handleSubmitResult(tt.req)
+
+			_, stored := h.workItemData["s1"][workflow.ResultStepDataKey]
+			if stored != tt.wantStore {
+				t.Fatalf("result stored = %v, want %v", stored, tt.wantStore)
+			}
+			if !tt.wantStore {
+				return
+			}
+			got, ok := workflow.ResultFromStepData(h.workItemData["s1"])
+			if !ok || got.Status != "success" || got.Confidence != 0.9 || len(got.FilesChanged) != 1 {
+				t.Errorf("unexpected stored result: %+v", got)
+			}
+		})
+	}
+}
+
+func TestSessionWorker_HandleSubmitResult_RequiredFields(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	h := newMockHost(mockExec)
+
+	sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "feat-1"}
+	h.cfg.AddSession(*sess)
+
+	runner := claude.NewMockRunner("s1", false, nil)
+	runner.SetHostTools(true)
+	w := NewSessionWorker(h, sess, runner, "test")
+	w.SetResultContract([]string{"plan", "risk_level"})
+
+	w.handleSubmitResult(mcp.SubmitResultRequest{ID: 1, Status: "success", Summary: "done", Plan: "1. Do it."})
+	if _, stored := h.workItemData["s1"][workflow.ResultStepDataKey]; stored || w.resultSubmitted {
+		t.Fatal("expected a result missing risk_level rejected")
+	}
+
+	w.handleSubmitResult(mcp.SubmitResultRequest{ID: 2, Status: "success", Summary: "done", Plan: "1. Do it.", RiskLevel: "low", Risks: []string{"none"}})
+	got, ok := workflow.ResultFromStepData(h.workItemData["s1"])
+	if !ok || got.Plan != "1. Do it." || got.RiskLevel != "low" || len(got.Risks) != 1 || !w.resultSubmitted {
+		t.Errorf("unexpected stored result: %+v", got)
+	}
+}
+
+func TestResultContract_CorrectionsSentUntilLimit(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	h := newMockHost(mockExec)
+
+	sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "main"}
+	h.cfg.AddSession(*sess)
+
+	runner := claude.NewMockRunner("s1", false, nil)
+	runner.SetHostTools(true)
+	runner.QueueResponse(
+		claude.ResponseChunk{Type: claude.ChunkTypeText, Content: "All done."},
+		claude.ResponseChunk{Done: true},
+	)
+
+	var corrections []string
+	runner.OnSend = func(content []claude.ContentBlock) {
+		if text := content[0].Text; strings.Contains(text, "submit_result") {
+			corrections = append(corrections, text)
+			// Claude ignores the correction and finishes again.
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				runner.InjectChunk(claude.ResponseChunk{Done: true})
+			}()
+		}
+	}
+
+	w := NewSessionWorker(h, sess, runner, "Code something")
+	w.SetResultContract([]string{"files_changed"})
+	w.Start(t.Context())
+	w.Wait()
+
+	if len(corrections) != maxResultCorrections {
+		t.Fatalf("expected %d corrections, got %d", maxResultCorrections, len(corrections))
+	}
+	if !strings.Contains(corrections[0], "files_changed") {
+		t.Errorf("expected the correction to name the required fields, got %q", corrections[0])
+	}
+	if w.Turns() != 1+maxResultCorrections {
+		t.Errorf("expected %d turns, got %d", 1+maxResultCorrections, w.Turns())
+	}
+}
+
+func TestResultContract_NoCorrectionOnceSubmitted(t *testing.T) {
+	mockExec := exec.NewMockExecutor(nil)
+	h := newMockHost(mockExec)
+
+	sess := &config.Session{ID: "s1", RepoPath: "/repo", Branch: "main"}
+	h.cfg.AddSession(*sess)
+
+	runner := claude.NewMockRunner("s1", false, nil)
+	runner.QueueResponse(
+		claude.ResponseChunk{Type: claude.ChunkTypeText, Content: "All done."},
+		claude.ResponseChunk{Done: true},
+	)
+
+	w := NewSessionWorker(h, sess, runner, "Code something")
+	w.SetResultContract(nil)
+	w.resultSubmitted = true
+	w.Start(t.Context())
+	w.Wait()
+
+	if w.Turns() != 1 {
+		t.Errorf("expected 1 turn (result already submitted), got %d", w.Turns())
+	}
+}
+
 func TestPlanningMode_CorrectionSentWhenNoCommentIssue(t *testing.T) {
 	mockExec := exec.NewMockExecutor(nil)
 	h := newMockHost(mockExec)
@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"log/slog"
+	"slices"
 )
 
 // Action defines the interface for executable workflow actions.
@@ -63,3 +64,13 @@ func (r *ActionRegistry) Has(name string) bool {
 	_, ok := r.actions[name]
 	return ok
 }
+
+// Names returns the registered action names, sorted.
+func (r *ActionRegistry) Names() []string {
+	names := make([]string, 0, len(r.actions))
+	for name := range r.actions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
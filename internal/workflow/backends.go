@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Agent backend providers a backends entry may name.
+const (
+	// BackendOpenAI is the OpenAI chat completions API, or any server that
+	// speaks it, such as Ollama, vLLM or LiteLLM, at base_url.
+	BackendOpenAI = "openai"
+	// BackendGemini is Google's Gemini API.
+	BackendGemini = "gemini"
+	// BackendBedrock is the AWS Bedrock Converse API.
+	BackendBedrock = "bedrock"
+	// BackendLocal is a local inference server speaking the OpenAI API,
+	// such as Ollama, vLLM or a llama.cpp server, for repos whose code must
+	// not leave the machine. base_url defaults to Ollama's.
+	BackendLocal = "local"
+)
+
+// ValidBackendProviders lists all accepted backend providers.
+var ValidBackendProviders = []string{BackendOpenAI, BackendGemini, BackendBedrock, BackendLocal}
+
+// BackendClaude names the default backend, the Claude CLI. States select it
+// to opt out of a settings-level backend.
+const BackendClaude = "claude"
+
+// BackendConfig is a model API, other than the Claude CLI, that AI states
+// can run their sessions on.
+type BackendConfig struct {
+	// Provider is the API spoken: openai, gemini, bedrock or local.
+	Provider string `yaml:"provider"`
+	// Model is the provider's model ID, e.g. gpt-4o-mini.
+	Model string `yaml:"model"`
+	// BaseURL overrides the provider's API endpoint, e.g. a local Ollama
+	// server's http://localhost:11434/v1.
+	BaseURL string `yaml:"base_url,omitempty"`
+	// APIKeyEnv names the environment variable holding the API key.
+	// Defaults to OPENAI_API_KEY, GEMINI_API_KEY or, for Bedrock,
+	// AWS_BEARER_TOKEN_BEDROCK, falling back to AWS credentials. Local
+	// servers need no key unless it is set.
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+	// Region is the AWS region of a Bedrock backend.
+	Region string `yaml:"region,omitempty"`
+	// InputPrice and OutputPrice are the model's prices in USD per million
+	// tokens, used to record the spend of its sessions.
+	InputPrice  float64 `yaml:"input_price,omitempty"`
+	OutputPrice float64 `yaml:"output_price,omitempty"`
+	// ContextWindow is the model's context length in tokens. Older turns
+	// of a session are summarized as its conversation nears it. Defaults
+	// to a typical window for the provider.
+	ContextWindow int `yaml:"context_window,omitempty"`
+}
+
+// IsLocal reports whether the backend is a local inference server, which
+// works without the network.
+func (b *BackendConfig) IsLocal() bool {
+	return b.Provider == BackendLocal
+}
+
+// BackendName returns the backend in effect for the named state: the
+// state's own backend field, then settings.backend. It returns "" for the
+// Claude CLI.
+func (c *Config) BackendName(stateName string) string {
+	name := ""
+	if s, ok := c.States[stateName]; ok && s.Backend != "" {
+		name = s.Backend
+	} else if c.Settings != nil {
+		name = c.Settings.Backend
+	}
+	if name == BackendClaude {
+		return ""
+	}
+	return name
+}
+
+// Backend returns the backend the named state runs on, or nil for the
+// Claude CLI.
+func (c *Config) Backend(stateName string) *BackendConfig {
+	if name := c.BackendName(stateName); name != "" {
+		return c.Backends[name]
+	}
+	return nil
+}
+
+// validateBackends checks each backend names a known provider and a model,
+// and that every backend selected by settings or a state is defined.
+func validateBackends(cfg *Config) []ValidationError {
+	var errs []ValidationError
+	for _, name := range slices.Sorted(maps.Keys(cfg.Backends)) {
+		b := cfg.Backends[name]
+		field := "backends." + name
+		if name == BackendClaude {
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("%q is reserved for the Claude CLI", BackendClaude)})
+			continue
+		}
+		if b == nil {
+			errs = append(errs, ValidationError{Field: field, Message: "backend must set provider and model"})
+			continue
+		}
+		if !slices.Contains(ValidBackendProviders, b.Provider) {
+			errs = append(errs, ValidationError{
+				Field:   field + ".provider",
+				Message: fmt.Sprintf("unknown provider %q (must be %s)", b.Provider, strings.Join(ValidBackendProviders, ", ")),
+			})
+		}
+		if b.Model == "" {
+			errs = append(errs, ValidationError{Field: field + ".model", Message: "model is required"})
+		}
+		if b.BaseURL != "" {
+			if u, err := url.Parse(b.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, ValidationError{Field: field + ".base_url", Message: fmt.Sprintf("invalid base_url %q (want an http or https URL)", b.BaseURL)})
+			}
+		}
+		if b.APIKeyEnv != "" && !envKeyRe.MatchString(b.APIKeyEnv) {
+			errs = append(errs, ValidationError{Field: field + ".api_key_env", Message: fmt.Sprintf("invalid environment variable name %q", b.APIKeyEnv)})
+		}
+		if b.Provider == BackendBedrock && b.Region == "" && b.BaseURL == "" {
+			errs = append(errs, ValidationError{Field: field + ".region", Message: "bedrock backends need a region"})
+		}
+		if b.InputPrice < 0 || b.OutputPrice < 0 {
+			errs = append(errs, ValidationError{Field: field, Message: "prices must not be negative"})
+		}
+		if b.ContextWindow < 0 {
+			errs = append(errs, ValidationError{Field: field + ".context_window", Message: "context_window must not be negative"})
+		}
+	}
+
+	check := func(field, name string) {
+		if name == "" || name == BackendClaude {
+			return
+		}
+		if _, ok := cfg.Backends[name]; !ok {
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("unknown backend %q (define it under backends)", name)})
+		}
+	}
+	if cfg.Settings != nil {
+		check("settings.backend", cfg.Settings.Backend)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		check(fmt.Sprintf("states.%s.backend", name), cfg.States[name].Backend)
+	}
+	return errs
+}
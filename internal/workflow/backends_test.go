@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBackendConfig_YAML(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+backends:
+  cheap:
+    provider: openai
+    model: gpt-4o-mini
+    base_url: http://localhost:11434/v1
+    input_price: 0.15
+    context_window: 32768
+settings:
+  backend: cheap
+states:
+  coding:
+    type: task
+    backend: claude
+  triage:
+    type: task
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := cfg.Backends["cheap"]
+	if b == nil || b.Provider != BackendOpenAI || b.BaseURL != "http://localhost:11434/v1" || b.InputPrice != 0.15 || b.ContextWindow != 32768 {
+		t.Fatalf("unexpected backend: %+v", b)
+	}
+	if got := cfg.Backend("triage"); got != b {
+		t.Errorf("triage should run on the settings backend, got %+v", got)
+	}
+	if got := cfg.Backend("coding"); got != nil {
+		t.Errorf("coding opts back into the Claude CLI, got %+v", got)
+	}
+}
+
+func TestValidate_Backends(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Backends = map[string]*BackendConfig{
+		"a":      {Provider: "anthropic", Model: "m"},
+		"b":      {Provider: BackendOpenAI},
+		"c":      {Provider: BackendOpenAI, Model: "m", BaseURL: "localhost:11434", APIKeyEnv: "BAD-KEY"},
+		"claude": {Provider: BackendOpenAI, Model: "m"},
+		"d":      {Provider: BackendBedrock, Model: "m"},
+		"e":      {Provider: BackendLocal, Model: "m", ContextWindow: -1},
+	}
+	cfg.Settings = &SettingsConfig{Backend: "missing"}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"backends.a.provider", "backends.b.model", "backends.c.base_url", "backends.c.api_key_env", "backends.claude", "backends.d.region", "backends.e.context_window", "settings.backend"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Backends = map[string]*BackendConfig{
+		"gemini":  {Provider: BackendGemini, Model: "gemini-2.5-flash"},
+		"bedrock": {Provider: BackendBedrock, Model: "amazon.nova-pro-v1:0", Region: "us-east-1"},
+		"ollama":  {Provider: BackendLocal, Model: "qwen2.5-coder:32b"},
+	}
+	if !cfg.Backends["ollama"].IsLocal() || cfg.Backends["gemini"].IsLocal() {
+		t.Error("expected only the local backend local")
+	}
+	cfg.Settings = &SettingsConfig{Backend: "gemini"}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid backends, got: %v", errs)
+	}
+}
+
+func TestMerge_Backends(t *testing.T) {
+	defaults := &Config{Backends: map[string]*BackendConfig{"cheap": {Provider: BackendGemini, Model: "m"}}}
+	if got := Merge(&Config{}, defaults).Backends; got["cheap"] == nil {
+		t.Errorf("expected the default backends kept, got %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Behaviors when a spend budget is exceeded. BudgetStop is the default.
+const (
+	// BudgetStop starts no new work items; in-flight items continue.
+	BudgetStop = "stop"
+	// BudgetPause also parks in-flight items before their next AI step,
+	// until the budget period rolls over or the budget is raised.
+	BudgetPause = "pause"
+	// BudgetWarn only logs that the budget was exceeded.
+	BudgetWarn = "warn"
+)
+
+// BudgetBehaviors lists the valid on_exceeded values.
+var BudgetBehaviors = []string{BudgetStop, BudgetPause, BudgetWarn}
+
+// ErrorBudgetExceeded is the failure reason of an AI state whose work item
+// reached its item_usd or item_tokens cap. Catch rules match it in their
+// errors; retry rules never do, since the cap stays spent.
+const ErrorBudgetExceeded = "budget_exceeded"
+
+// BudgetExceededState is the state a work item moves to when it reaches its
+// spend cap and no catch rule matches, if the workflow has a state of that
+// name. Otherwise the state's error edge is taken.
+const BudgetExceededState = "budget_exceeded"
+
+// DefaultWrapUpAt is the share of a work item's cap at which its session is
+// told to wrap up when wrap_up_at is unset.
+const DefaultWrapUpAt = 0.8
+
+// BudgetConfig caps what sessions may spend per day and per week. Days are
+// calendar days in the daemon's local time zone and weeks start on Monday.
+// A zero limit is unlimited.
+type BudgetConfig struct {
+	DailyUSD     float64 `yaml:"daily_usd,omitempty"`
+	WeeklyUSD    float64 `yaml:"weekly_usd,omitempty"`
+	DailyTokens  int     `yaml:"daily_tokens,omitempty"`
+	WeeklyTokens int     `yaml:"weekly_tokens,omitempty"`
+	// OnExceeded is what happens once a limit is reached: "stop" (default),
+	// "pause" or "warn".
+	OnExceeded string `yaml:"on_exceeded,omitempty"`
+
+	// ItemUSD and ItemTokens cap what a single work item may spend over all
+	// its sessions. Once it has spent WrapUpAt of either (0.8 by default),
+	// its session is told to wrap up, and the item then moves to the
+	// budget_exceeded state.
+	ItemUSD    float64 `yaml:"item_usd,omitempty"`
+	ItemTokens int     `yaml:"item_tokens,omitempty"`
+	WrapUpAt   float64 `yaml:"wrap_up_at,omitempty"`
+}
+
+// Enabled reports whether any limit is set.
+func (b *BudgetConfig) Enabled() bool {
+	return b != nil && (b.DailyUSD > 0 || b.WeeklyUSD > 0 || b.DailyTokens > 0 || b.WeeklyTokens > 0)
+}
+
+// ItemLimited reports whether work items have a spend cap.
+func (b *BudgetConfig) ItemLimited() bool {
+	return b != nil && (b.ItemUSD > 0 || b.ItemTokens > 0)
+}
+
+// ItemSpent returns the share of the item cap that costUSD and tokens have
+// used, by whichever cap is closer to being reached. It is 0 when items
+// have no cap.
+func (b *BudgetConfig) ItemSpent(costUSD float64, tokens int) float64 {
+	if b == nil {
+		return 0
+	}
+	var spent float64
+	if b.ItemUSD > 0 {
+		spent = costUSD / b.ItemUSD
+	}
+	if b.ItemTokens > 0 {
+		spent = max(spent, float64(tokens)/float64(b.ItemTokens))
+	}
+	return spent
+}
+
+// WrapUpAtOrDefault returns the share of the item cap at which a session is
+// told to wrap up.
+func (b *BudgetConfig) WrapUpAtOrDefault() float64 {
+	if b == nil || b.WrapUpAt <= 0 {
+		return DefaultWrapUpAt
+	}
+	return b.WrapUpAt
+}
+
+// Behavior returns what happens once the budget is exceeded.
+func (b *BudgetConfig) Behavior() string {
+	if b == nil || b.OnExceeded == "" {
+		return BudgetStop
+	}
+	return strings.ToLower(b.OnExceeded)
+}
+
+// SpendBudget returns the repo's spend budget, or nil when it has none.
+func (c *Config) SpendBudget() *BudgetConfig {
+	if c == nil || c.Settings == nil || !c.Settings.Budget.Enabled() {
+		return nil
+	}
+	return c.Settings.Budget
+}
+
+// ItemBudget returns the repo's budget when it caps work items, or nil.
+func (c *Config) ItemBudget() *BudgetConfig {
+	if c == nil || c.Settings == nil || !c.Settings.Budget.ItemLimited() {
+		return nil
+	}
+	return c.Settings.Budget
+}
+
+// ValidateBudget checks a budget's limits and behavior. field prefixes the
+// reported fields, e.g. "settings.budget".
+func ValidateBudget(field string, b *BudgetConfig) []ValidationError {
+	if b == nil {
+		return nil
+	}
+	var errs []ValidationError
+	limits := []struct {
+		name  string
+		value float64
+	}{
+		{"daily_usd", b.DailyUSD},
+		{"weekly_usd", b.WeeklyUSD},
+		{"daily_tokens", float64(b.DailyTokens)},
+		{"weekly_tokens", float64(b.WeeklyTokens)},
+		{"item_usd", b.ItemUSD},
+		{"item_tokens", float64(b.ItemTokens)},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + "." + l.name,
+				Message: "must not be negative",
+			})
+		}
+	}
+	if b.WrapUpAt < 0 || b.WrapUpAt > 1 {
+		errs = append(errs, ValidationError{
+			Field:   field + ".wrap_up_at",
+			Message: "must be between 0 and 1",
+		})
+	}
+	if b.OnExceeded != "" && !slices.Contains(BudgetBehaviors, b.Behavior()) {
+		errs = append(errs, ValidationError{
+			Field:   field + ".on_exceeded",
+			Message: fmt.Sprintf("invalid value %q (must be one of: %s)", b.OnExceeded, strings.Join(BudgetBehaviors, ", ")),
+		})
+	}
+	return errs
+}
@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/testutil"
+)
+
+func TestConfig_SpendBudget(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.SpendBudget() != nil {
+		t.Error("expected no budget by default")
+	}
+	cfg := &Config{Settings: &SettingsConfig{Budget: &BudgetConfig{OnExceeded: "pause"}}}
+	if cfg.SpendBudget() != nil {
+		t.Error("a budget without limits should be ignored")
+	}
+
+	cfg.Settings.Budget.WeeklyUSD = 50
+	budget := cfg.SpendBudget()
+	if budget == nil || budget.Behavior() != BudgetPause {
+		t.Fatalf("SpendBudget = %+v, want the pausing budget", budget)
+	}
+	if (&BudgetConfig{DailyTokens: 1}).Behavior() != BudgetStop {
+		t.Error("expected stop by default")
+	}
+}
+
+func TestValidate_Budget(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Budget = &BudgetConfig{DailyUSD: -1, WeeklyTokens: 1000, OnExceeded: "halt"}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.budget.daily_usd", "settings.budget.on_exceeded"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Settings.Budget = &BudgetConfig{DailyUSD: 10, OnExceeded: "Warn"}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid budget, got: %v", errs)
+	}
+}
+
+func TestBudgetConfig_ItemCap(t *testing.T) {
+	cfg := &Config{Settings: &SettingsConfig{Budget: &BudgetConfig{DailyUSD: 10}}}
+	if cfg.ItemBudget() != nil {
+		t.Error("expected items uncapped without item limits")
+	}
+
+	cfg.Settings.Budget = &BudgetConfig{ItemUSD: 5, ItemTokens: 1000}
+	b := cfg.ItemBudget()
+	if b == nil || cfg.SpendBudget() != nil {
+		t.Fatalf("expected only an item cap, got item %+v, period %+v", b, cfg.SpendBudget())
+	}
+	if got := b.ItemSpent(1, 500); got != 0.5 {
+		t.Errorf("ItemSpent = %v, want the tokens' 0.5", got)
+	}
+	if got := b.ItemSpent(4.5, 100); got != 0.9 {
+		t.Errorf("ItemSpent = %v, want the cost's 0.9", got)
+	}
+	if b.WrapUpAtOrDefault() != DefaultWrapUpAt {
+		t.Errorf("WrapUpAtOrDefault = %v, want %v", b.WrapUpAtOrDefault(), DefaultWrapUpAt)
+	}
+	if (*BudgetConfig)(nil).ItemSpent(100, 100) != 0 {
+		t.Error("expected nothing spent without a cap")
+	}
+}
+
+func TestValidate_ItemBudget(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Budget = &BudgetConfig{ItemUSD: -1, ItemTokens: 100, WrapUpAt: 1.5}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.budget.item_usd", "settings.budget.wrap_up_at"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+}
+
+func TestEngine_AdvanceAfterAsyncFailure_BudgetExceeded(t *testing.T) {
+	states := map[string]*State{
+		"coding": {Type: StateTypeTask, Action: "ai.code", Next: "done", Error: "failed",
+			Retry: []RetryConfig{{MaxAttempts: 3}}},
+		"done":   {Type: StateTypeSucceed},
+		"failed": {Type: StateTypeFail},
+	}
+	view := &WorkItemView{CurrentStep: "coding", Phase: "async_pending"}
+
+	// Never retried: without a budget_exceeded state the error edge is taken.
+	engine := NewEngine(&Config{Start: "coding", States: states}, NewActionRegistry(), nil, testutil.DiscardLogger())
+	result, err := engine.AdvanceAfterAsyncFailure(view, ErrorBudgetExceeded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "failed" {
+		t.Errorf("expected the error edge, got %q", result.NewStep)
+	}
+
+	states[BudgetExceededState] = &State{Type: StateTypeFail}
+	result, err = engine.AdvanceAfterAsyncFailure(view, ErrorBudgetExceeded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != BudgetExceededState || result.Data["_last_error"] != ErrorBudgetExceeded {
+		t.Errorf("expected the budget_exceeded state, got step %q data %v", result.NewStep, result.Data)
+	}
+}
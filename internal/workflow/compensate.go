@@ -0,0 +1,58 @@
+package workflow
+
+import "context"
+
+// Compensate runs the on_failure compensations of the given states for a
+// failed work item, last state first and each state's list in order. Action
+// params are expanded against the item's step data; run commands execute as
+// hooks with hookCtx. A compensation that fails is logged and the rest still
+// run, since each undoes something independent. A failed item cannot wait
+// for confirmation, so actions listed in settings.confirm_actions are
+// skipped and returned for the caller to report; run commands count as
+// exec.run.
+func (e *Engine) Compensate(ctx context.Context, item *WorkItemView, states []string, hookCtx HookContext) (skipped []string) {
+	for i := len(states) - 1; i >= 0; i-- {
+		state := e.GetState(states[i])
+		if state == nil {
+			continue
+		}
+		for _, comp := range state.OnFailure {
+			log := e.logger.With("workItem", item.ID, "state", states[i])
+			if comp.Run != "" {
+				if e.config.RequiresConfirmation("exec.run") {
+					log.Warn("skipping compensation command that requires confirmation", "run", comp.Run)
+					skipped = append(skipped, "exec.run")
+					continue
+				}
+				if output, _, err := runHook(ctx, HookConfig{Run: comp.Run}, hookCtx); err != nil {
+					log.Warn("compensation command failed", "run", comp.Run, "error", err, "output", output)
+				}
+				continue
+			}
+			if e.config.RequiresConfirmation(comp.Action) {
+				log.Warn("skipping compensation that requires confirmation", "action", comp.Action)
+				skipped = append(skipped, comp.Action)
+				continue
+			}
+			action := e.actions.Get(comp.Action)
+			if action == nil {
+				log.Warn("no action registered for compensation", "action", comp.Action)
+				continue
+			}
+			result := action.Execute(ctx, &ActionContext{
+				WorkItemID: item.ID,
+				SessionID:  item.SessionID,
+				RepoPath:   item.RepoPath,
+				Branch:     item.Branch,
+				Step:       states[i],
+				Params:     NewParamHelper(ExpandParams(comp.Params, item.StepData)),
+				Logger:     e.logger,
+				Extra:      item.Extra,
+			})
+			if !result.Success {
+				log.Warn("compensation action failed", "action", comp.Action, "error", result.Error)
+			}
+		}
+	}
+	return skipped
+}
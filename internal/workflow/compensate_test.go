@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhubert/erg/internal/testutil"
+)
+
+func TestEngine_Compensate(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "log.txt")
+
+	unlabel := &recordingAction{result: ActionResult{Error: errors.New("label not found")}}
+	comment := &recordingAction{result: ActionResult{Success: true}}
+	registry := NewActionRegistry()
+	registry.Register("github.remove_label", unlabel)
+	registry.Register("github.comment_issue", comment)
+
+	cfg := &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding": {
+				Type: StateTypeTask, Action: "ai.code", Next: "open_pr",
+				OnFailure: []CompensationConfig{
+					{Action: "github.remove_label", Params: map[string]any{"label": "erg-claimed"}},
+					{Action: "github.comment_issue", Params: map[string]any{"body": "Gave up: {{step.error}}"}},
+				},
+			},
+			"open_pr": {
+				Type: StateTypeTask, Action: "github.create_pr", Next: "done",
+				OnFailure: []CompensationConfig{{Run: "echo close {{.PRURL}} >> " + logFile}},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+	engine := NewEngine(cfg, registry, &mockEventChecker{}, testutil.DiscardLogger())
+
+	view := &WorkItemView{ID: "item-1", StepData: map[string]any{"error": "CI failed"}}
+	data := TemplateData{PRURL: "https://github.com/o/r/pull/9"}
+	engine.Compensate(context.Background(), view, []string{"coding", "open_pr", "missing"}, HookContext{RepoPath: dir, Template: &data})
+
+	got, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("compensation command did not run: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != "close https://github.com/o/r/pull/9" {
+		t.Errorf("command output: got %q", string(got))
+	}
+	// A failing compensation does not stop the ones after it.
+	if unlabel.calls != 1 || comment.calls != 1 {
+		t.Fatalf("expected each action once, got remove_label=%d comment=%d", unlabel.calls, comment.calls)
+	}
+	if body := comment.params.String("body", ""); body != "Gave up: CI failed" {
+		t.Errorf("expected expanded params, got %q", body)
+	}
+}
+
+func TestEngine_Compensate_ReverseOrder(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "order.txt")
+
+	cfg := &Config{
+		Start: "a",
+		States: map[string]*State{
+			"a":    {Type: StateTypeTask, Action: "exec.run", Next: "b", OnFailure: []CompensationConfig{{Run: "echo a >> " + logFile}}},
+			"b":    {Type: StateTypeTask, Action: "exec.run", Next: "done", OnFailure: []CompensationConfig{{Run: "echo b >> " + logFile}}},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+	engine := NewEngine(cfg, NewActionRegistry(), &mockEventChecker{}, testutil.DiscardLogger())
+	engine.Compensate(context.Background(), &WorkItemView{ID: "item-1"}, []string{"a", "b"}, HookContext{RepoPath: dir})
+
+	got, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "b\na\n" {
+		t.Errorf("expected latest state first, got %q", string(got))
+	}
+}
+
+func TestEngine_Compensate_SkipsConfirmActions(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "log.txt")
+
+	closeIssue := &recordingAction{result: ActionResult{Success: true}}
+	comment := &recordingAction{result: ActionResult{Success: true}}
+	registry := NewActionRegistry()
+	registry.Register("github.close_issue", closeIssue)
+	registry.Register("github.comment_issue", comment)
+
+	cfg := &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding": {
+				Type: StateTypeTask, Action: "ai.code", Next: "done",
+				OnFailure: []CompensationConfig{
+					{Action: "github.close_issue"},
+					{Run: "echo undo >> " + logFile},
+					{Action: "github.comment_issue", Params: map[string]any{"body": "Gave up"}},
+				},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+		Settings: &SettingsConfig{ConfirmActions: []string{"github.close_issue", "exec.run"}},
+	}
+	engine := NewEngine(cfg, registry, &mockEventChecker{}, testutil.DiscardLogger())
+	skipped := engine.Compensate(context.Background(), &WorkItemView{ID: "item-1"}, []string{"coding"}, HookContext{RepoPath: dir})
+
+	if closeIssue.calls != 0 {
+		t.Errorf("gated action ran %d times", closeIssue.calls)
+	}
+	if _, err := os.Stat(logFile); err == nil {
+		t.Error("gated compensation command ran")
+	}
+	if comment.calls != 1 {
+		t.Errorf("ungated action ran %d times, want 1", comment.calls)
+	}
+	if strings.Join(skipped, ",") != "github.close_issue,exec.run" {
+		t.Errorf("skipped = %v", skipped)
+	}
+}
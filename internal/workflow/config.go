@@ -18,16 +18,37 @@ const (
 	StateTypeSucceed  StateType = "succeed"
 	StateTypeFail     StateType = "fail"
 	StateTypeTemplate StateType = "template"
+	StateTypeParallel StateType = "parallel"
+	StateTypeJoin     StateType = "join"
 )
 
 // Config is the top-level workflow configuration.
 type Config struct {
+	// Include lists workflow fragments (paths relative to this file, or
+	// http(s) URLs) merged in before this file; see resolveIncludes.
+	Include  []string          `yaml:"include,omitempty"`
 	Workflow string            `yaml:"workflow"`
 	Start    string            `yaml:"start"`
 	Source   SourceConfig      `yaml:"source"`
 	States   map[string]*State `yaml:"states"`
 	Settings *SettingsConfig   `yaml:"settings,omitempty"`
 	Triggers []TriggerConfig   `yaml:"triggers,omitempty"`
+	// Workflows selects alternative workflow files for issues by label.
+	Workflows []NamedWorkflow `yaml:"workflows,omitempty"`
+	// Mutex names a group only one work item per repo may hold at a time.
+	// An item running this workflow holds it from start until it finishes.
+	Mutex string `yaml:"mutex,omitempty"`
+	// Container points the repo at its own session image or Dockerfile.
+	Container *ContainerConfig `yaml:"container,omitempty"`
+	// Services are sidecar containers, such as databases, started for each
+	// session.
+	Services []ServiceConfig `yaml:"services,omitempty"`
+	// Preflight checks each session container works before the agent gets
+	// it.
+	Preflight *PreflightConfig `yaml:"preflight,omitempty"`
+	// Backends are model APIs, besides the Claude CLI, that AI states can
+	// run on, by name.
+	Backends map[string]*BackendConfig `yaml:"backends,omitempty"`
 }
 
 // SettingsConfig holds agent-level settings that can be specified in the workflow YAML.
@@ -41,6 +62,90 @@ type SettingsConfig struct {
 	AutoMerge      *bool  `yaml:"auto_merge,omitempty"`
 	MergeMethod    string `yaml:"merge_method,omitempty"`
 	Model          string `yaml:"model,omitempty"` // default model for all AI states (alias or full ID)
+	// FallbackModels are the models AI states move on to, in order, when
+	// the Claude API is overloaded or failing on their model.
+	FallbackModels []string `yaml:"fallback_models,omitempty"`
+	// Network is the default container network profile (full, registries, offline)
+	// for AI states that don't declare their own.
+	Network string `yaml:"network,omitempty"`
+	// NetworkProfiles maps restricted profiles (registries, offline) to the
+	// Docker networks that implement them.
+	NetworkProfiles map[string]string `yaml:"network_profiles,omitempty"`
+	// ProgressComments enables automatic progress comments on the source issue
+	// at key milestones (started, PR opened, CI green, merged).
+	ProgressComments *bool `yaml:"progress_comments,omitempty"`
+	// ProgressInterval is the minimum gap between new progress comments, in
+	// minutes. Milestones reached sooner are folded into the previous comment.
+	ProgressInterval int `yaml:"progress_interval,omitempty"`
+	// EpicSummaries enables a periodically updated progress summary comment
+	// on each epic referenced by queued issues ("Epic: #N" / "Part of #N").
+	EpicSummaries *bool `yaml:"epic_summaries,omitempty"`
+	// EpicSummaryInterval is the minimum gap between epic summary updates, in
+	// minutes.
+	EpicSummaryInterval int `yaml:"epic_summary_interval,omitempty"`
+	// ConfirmActions lists destructive actions (see DestructiveActions) that
+	// must be confirmed by a human before erg performs them.
+	ConfirmActions []string `yaml:"confirm_actions,omitempty"`
+	// Approvers lists the tracker usernames allowed to confirm or reject
+	// those actions with a "/erg confirm" or "/erg reject" comment. When
+	// empty, GitHub users with write access may; other trackers' comments
+	// are ignored and `erg approve` must be used.
+	Approvers []string `yaml:"approvers,omitempty"`
+	// KnowledgeBase enables the per-repo knowledge base: sessions record
+	// learnings about the repo when they finish, and later sessions are
+	// shown what was recorded.
+	KnowledgeBase *bool `yaml:"knowledge_base,omitempty"`
+	// IssueContext enables issue enrichment: before a session starts, erg
+	// pulls linked issues and PRs, tracker fields, attachments and recent
+	// comments into the prompt.
+	IssueContext *bool `yaml:"issue_context,omitempty"`
+	// IssueContextMaxChars caps the enrichment added to a prompt, in
+	// characters.
+	IssueContextMaxChars int `yaml:"issue_context_max_chars,omitempty"`
+	// RepoIndex enables code retrieval: before a session starts, erg looks
+	// up the code most related to the issue in the repo's index (built with
+	// `erg index build`) and adds it to the prompt.
+	RepoIndex *bool `yaml:"repo_index,omitempty"`
+	// RepoIndexMaxChars caps the retrieved code added to a prompt, in
+	// characters.
+	RepoIndexMaxChars int `yaml:"repo_index_max_chars,omitempty"`
+	// RepoIndexModel is the embedding model the repo index is built and
+	// searched with. When unset, retrieval is lexical.
+	RepoIndexModel *RepoIndexModelConfig `yaml:"repo_index_model,omitempty"`
+	// Timezone is the IANA time zone (e.g. "America/New_York") the repo's
+	// team works in. Schedule triggers fire in it and comment timestamps are
+	// shown in it. Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Migration controls what happens to in-flight work items when the
+	// workflow config changes while the daemon is running.
+	Migration *MigrationConfig `yaml:"migration,omitempty"`
+	// Events lists sinks that receive a structured event for every state
+	// transition of the repo's work items.
+	Events []EventSinkConfig `yaml:"events,omitempty"`
+	// Priority controls which queued items start first and whether hotfixes
+	// may pause running sessions.
+	Priority *PriorityConfig `yaml:"priority,omitempty"`
+	// Budget caps the repo's daily and weekly session spend.
+	Budget *BudgetConfig `yaml:"budget,omitempty"`
+	// DeadLetterAfter is how many times an issue's work may fail before it
+	// is moved to the dead-letter queue and no longer retried.
+	DeadLetterAfter int `yaml:"dead_letter_after,omitempty"`
+	// Limits caps concurrent sessions per toolchain and concurrent calls
+	// per tracker API.
+	Limits LimitsConfig `yaml:"limits,omitempty"`
+	// Reaper flags work items stuck at a state too long and acts on them.
+	Reaper *ReaperConfig `yaml:"reaper,omitempty"`
+	// Resources limits the CPU, memory and processes of session containers.
+	Resources *ResourcesConfig `yaml:"resources,omitempty"`
+	// ImageRegistry holds prebuilt session images to pull instead of
+	// building them locally.
+	ImageRegistry *ImageRegistryConfig `yaml:"image_registry,omitempty"`
+	// Backend is the default agent backend, a name under backends, for AI
+	// states that don't declare their own. Empty means the Claude CLI.
+	Backend string `yaml:"backend,omitempty"`
+	// ToolPolicy limits the tools and shell commands the repo's sessions
+	// may run.
+	ToolPolicy *ToolPolicyConfig `yaml:"tool_policy,omitempty"`
 }
 
 // State represents a single node in the workflow graph.
@@ -57,12 +162,40 @@ type State struct {
 	Catch       []CatchConfig  `yaml:"catch,omitempty"`
 	Choices     []ChoiceRule   `yaml:"choices,omitempty"`
 	Default     string         `yaml:"default,omitempty"`
-	Data        map[string]any `yaml:"data,omitempty"`
-	Before      []HookConfig   `yaml:"before,omitempty"`
-	After       []HookConfig   `yaml:"after,omitempty"`
+	// OnTimeout is what a wait state does when its timeout elapses: ""
+	// follows timeout_next (or error), TimeoutStall parks the item until a
+	// human resumes it.
+	OnTimeout string `yaml:"on_timeout,omitempty"`
+	// Escalation lists actions a wait state runs while its event has not
+	// fired, each once, after the given time in the state has elapsed.
+	Escalation []EscalationConfig `yaml:"escalation,omitempty"`
+	// Mutex names a group only one work item per repo may hold at a time.
+	// An item holds it while at this task state; others entering wait.
+	Mutex string `yaml:"mutex,omitempty"`
+	// OnFailure lists compensations that undo what this state did, e.g.
+	// closing the PR it opened. They run, latest state first, once the work
+	// item fails anywhere after entering the state.
+	OnFailure []CompensationConfig `yaml:"on_failure,omitempty"`
+	// Branches lists the first state of each branch of a parallel state.
+	// Each branch follows next edges until it reaches the parallel state's
+	// next, which must be a join state.
+	Branches []string       `yaml:"branches,omitempty"`
+	Data     map[string]any `yaml:"data,omitempty"`
+	Before   []HookConfig   `yaml:"before,omitempty"`
+	After    []HookConfig   `yaml:"after,omitempty"`
 	// Model is the model to use for this state (alias like "haiku" or full ID like
 	// "claude-haiku-4-5-20251001"). Overrides the settings-level model for this state only.
 	Model string `yaml:"model,omitempty"`
+	// FallbackModels are tried in order when the Claude API is overloaded or
+	// failing on Model. Overrides settings.fallback_models for this state only.
+	FallbackModels []string `yaml:"fallback_models,omitempty"`
+	// Network is the container network profile (full, registries, offline) for
+	// the session at this state. Overrides settings.network for this state only.
+	Network string `yaml:"network,omitempty"`
+	// Backend is the agent backend, a name under backends or "claude", the
+	// session at this state runs on. Overrides settings.backend for this
+	// state only.
+	Backend string `yaml:"backend,omitempty"`
 	// DisplayName is a human-readable label for this state, shown in the dashboard
 	// and CLI. If empty, a label is derived from the state name at display time.
 	DisplayName string `yaml:"display_name,omitempty"`
@@ -75,6 +208,31 @@ type State struct {
 	Exits map[string]string `yaml:"exits,omitempty"` // exit-name → local state name in calling workflow
 }
 
+// EscalationConfig is one level of a wait state's escalation, e.g. pinging
+// reviewers on the PR after a day and posting to Slack after two.
+type EscalationConfig struct {
+	After  Duration       `yaml:"after"`
+	Action string         `yaml:"action"`
+	Params map[string]any `yaml:"params,omitempty"`
+}
+
+// CompensationConfig is one on_failure step: either an action, such as
+// github.remove_label, or a shell command run like a hook.
+type CompensationConfig struct {
+	Action string         `yaml:"action,omitempty"`
+	Params map[string]any `yaml:"params,omitempty"`
+	Run    string         `yaml:"run,omitempty"`
+}
+
+// TimeoutStall is the State.OnTimeout value that parks a timed-out item in
+// PhaseStalled instead of transitioning.
+const TimeoutStall = "stall"
+
+// PhaseStalled is the phase of an item parked on a wait state whose timeout
+// elapsed with on_timeout: stall. It stays there, holding no slot, until
+// `erg resume` restarts the wait.
+const PhaseStalled = "stalled"
+
 // TemplateConfig is the top-level structure of a reusable workflow template file.
 type TemplateConfig struct {
 	Template string            `yaml:"template"`
@@ -116,23 +274,90 @@ type CatchConfig struct {
 
 // SourceConfig defines where issues come from.
 type SourceConfig struct {
+	Provider     string           `yaml:"provider"`
+	Filter       FilterConfig     `yaml:"filter"`
+	Readiness    *ReadinessConfig `yaml:"readiness,omitempty"`
+	Order        string           `yaml:"order,omitempty"`         // Queue ordering: priority (default), oldest-first, newest-first, label-weighted
+	LabelWeights map[string]int   `yaml:"label_weights,omitempty"` // label-weighted: weight per label; higher totals are picked up first
+
+	DeadlineWindow *Duration `yaml:"deadline_window,omitempty"` // Issues due within this window jump the queue (default 3d; "0s" disables)
+
+	Additional []AdditionalSource `yaml:"additional,omitempty"` // Further trackers polled for this repo (see sources.go)
+	Canonical  string             `yaml:"canonical,omitempty"`  // Provider whose issue is kept when the same work is in several trackers (default: provider)
+}
+
+// AdditionalSource is another tracker polled for the same repo. It shares
+// the primary source's readiness, ordering, and deadline settings.
+type AdditionalSource struct {
 	Provider string       `yaml:"provider"`
 	Filter   FilterConfig `yaml:"filter"`
 }
 
+// ReadinessConfig defines checks an issue must pass before it is picked up.
+// Issues that fail are skipped until they are updated.
+type ReadinessConfig struct {
+	MinBodyLength    int      `yaml:"min_body_length,omitempty"`   // Minimum description length in characters
+	RequiredSections []string `yaml:"required_sections,omitempty"` // Headings the description must contain (e.g. "Acceptance Criteria")
+	RequireEstimate  bool     `yaml:"require_estimate,omitempty"`  // Description must contain an "Estimate:" line or section
+	BlockedLabels    []string `yaml:"blocked_labels,omitempty"`    // Labels that mark an issue as not ready (e.g. "needs-design")
+	Comment          bool     `yaml:"comment,omitempty"`           // Comment on unready issues with what is missing
+	Dependencies     bool     `yaml:"dependencies,omitempty"`      // Hold issues until the issues blocking them are done ("Blocked by #12", tracker relations)
+}
+
 // FilterConfig holds provider-specific filter parameters.
 type FilterConfig struct {
-	Label   string `yaml:"label"`   // Required: permanent AI-assisted marker (all providers)
+	Label   string `yaml:"label"`   // Required: permanent AI-assisted marker (all providers); may be an expression such as "ai-ready && !blocked"
 	Project string `yaml:"project"` // Asana: project GID
 	Team    string `yaml:"team"`    // Linear: team ID
 	Section string `yaml:"section"` // Asana: section name to poll (fetches tasks in that section only)
+	List    string `yaml:"list"`    // ClickUp: list ID
+
+	Assignee string `yaml:"assignee,omitempty"`  // GitHub, GitLab, Linear, Asana: only pick up issues assigned to this user
+	MaxItems int    `yaml:"max_items,omitempty"` // Asana, Linear: cap on issues fetched per poll (0 = default)
+
+	// ExpandSubtasks (Asana, Linear) works a parent issue with open sub-tasks
+	// as one work item per sub-task instead of as a single item.
+	ExpandSubtasks bool `yaml:"expand_subtasks,omitempty"`
+
+	HTTP *HTTPSourceConfig `yaml:"http,omitempty"` // http: endpoint mapping
+}
+
+// HTTPSourceConfig maps a user-supplied JSON endpoint onto issues for the
+// http provider. Field paths are dot-separated ("fields.summary"); URL
+// templates may reference {id} and {label}.
+type HTTPSourceConfig struct {
+	URL            string           `yaml:"url"`                        // GET endpoint returning the issue list
+	Items          string           `yaml:"items,omitempty"`            // Path to the issue array; empty = top level
+	Fields         HTTPFieldsConfig `yaml:"fields,omitempty"`           // Paths to issue fields within each item
+	CommentURL     string           `yaml:"comment_url,omitempty"`      // Optional POST endpoint for comments
+	RemoveLabelURL string           `yaml:"remove_label_url,omitempty"` // Optional POST endpoint for label removal
+	TokenEnv       string           `yaml:"token_env,omitempty"`        // Env var holding a bearer token
 }
 
-// HookConfig defines a hook to run after a workflow step.
+// HTTPFieldsConfig holds paths to issue fields within each item returned by
+// an http source. Empty paths use the field's own name.
+type HTTPFieldsConfig struct {
+	ID        string `yaml:"id,omitempty"`
+	Title     string `yaml:"title,omitempty"`
+	Body      string `yaml:"body,omitempty"`
+	URL       string `yaml:"url,omitempty"`
+	Labels    string `yaml:"labels,omitempty"`
+	Priority  string `yaml:"priority,omitempty"`
+	CreatedAt string `yaml:"created_at,omitempty"`
+}
+
+// HookConfig defines a hook to run before or after a workflow step.
 type HookConfig struct {
 	Run string `yaml:"run"`
+	// Output, when "json", parses the hook's stdout as a JSON object and
+	// merges it into the work item's step data.
+	Output string `yaml:"output,omitempty"`
 }
 
+// HookOutputJSON is the HookConfig.Output value for hooks that emit a JSON
+// object on stdout.
+const HookOutputJSON = "json"
+
 // Duration is a wrapper around time.Duration that implements YAML unmarshaling
 // from human-readable strings like "30m", "2h".
 type Duration struct {
@@ -210,6 +435,33 @@ func (d Duration) MarshalYAML() (any, error) {
 type TriggerConfig struct {
 	Schedule string `yaml:"schedule"` // cron expression (standard 5-field format)
 	State    string `yaml:"state"`    // workflow state name to start from
+	// Name identifies the trigger in work item titles and overlap checks.
+	// Defaults to State.
+	Name string `yaml:"name,omitempty"`
+	// Prompt is the task for each scheduled work item, given to it as the
+	// issue body. A "file:" prefix reads it from a path in the repo.
+	Prompt string `yaml:"prompt,omitempty"`
+	// Timezone is the IANA time zone the schedule is evaluated in.
+	// Defaults to settings.timezone.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Overlap is what happens when the trigger fires while its previous
+	// work item is still queued or active: TriggerOverlapSkip (default) or
+	// TriggerOverlapAllow.
+	Overlap string `yaml:"overlap,omitempty"`
+}
+
+// TriggerConfig.Overlap values.
+const (
+	TriggerOverlapSkip  = "skip"
+	TriggerOverlapAllow = "allow"
+)
+
+// Key returns the name a trigger's work items are grouped under.
+func (t TriggerConfig) Key() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.State
 }
 
 // ValidActions is the set of recognized action names for task states.
@@ -231,15 +483,19 @@ var ValidActions = map[string]bool{
 	"ai.fix_ci":             true,
 	"ai.resolve_conflicts":  true,
 	"ai.address_review":     true,
+	"ai.address_feedback":   true,
 	"git.format":            true,
 	"git.rebase":            true,
 	"git.validate_diff":     true,
+	"quality.gate":          true,
 	"asana.comment":         true,
 	"asana.move_to_section": true,
 	"linear.comment":        true,
 	"linear.move_to_state":  true,
 	"slack.notify":          true,
 	"webhook.post":          true,
+	"exec.run":              true,
+	"issue.create":          true,
 	"workflow.retry":        true,
 	"workflow.wait":         true,
 }
@@ -306,4 +562,6 @@ var ValidStateTypes = map[StateType]bool{
 	StateTypeSucceed:  true,
 	StateTypeFail:     true,
 	StateTypeTemplate: true,
+	StateTypeParallel: true,
+	StateTypeJoin:     true,
 }
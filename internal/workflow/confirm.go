@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"slices"
+	"strings"
+)
+
+// DestructiveActions maps each action that can be gated behind
+// settings.confirm_actions to a short description of what it does, used in
+// the confirmation request.
+var DestructiveActions = map[string]string{
+	"git.rebase":         "rebase and force-push the branch",
+	"github.close_issue": "close the issue",
+	"github.merge":       "merge the PR into the base branch",
+	"exec.run":           "run a shell command",
+}
+
+// RequiresConfirmation reports whether the given action must be confirmed by a
+// human before it runs.
+func (c *Config) RequiresConfirmation(action string) bool {
+	return c != nil && c.Settings != nil && slices.Contains(c.Settings.ConfirmActions, action)
+}
+
+// IsApprover reports whether user is listed in settings.approvers, ignoring
+// case. It is false for everyone when the list is empty.
+func (c *Config) IsApprover(user string) bool {
+	if c == nil || c.Settings == nil || user == "" {
+		return false
+	}
+	return slices.ContainsFunc(c.Settings.Approvers, func(a string) bool {
+		return strings.EqualFold(a, user)
+	})
+}
+
+// HasApprovers reports whether settings.approvers restricts who may confirm
+// destructive actions from the tracker.
+func (c *Config) HasApprovers() bool {
+	return c != nil && c.Settings != nil && len(c.Settings.Approvers) > 0
+}
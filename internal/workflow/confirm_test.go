@@ -0,0 +1,31 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_RequiresConfirmation(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.RequiresConfirmation("git.rebase") {
+		t.Error("nil config should not require confirmation")
+	}
+
+	cfg := &Config{Settings: &SettingsConfig{ConfirmActions: []string{"git.rebase"}}}
+	if !cfg.RequiresConfirmation("git.rebase") {
+		t.Error("expected git.rebase to require confirmation")
+	}
+	if cfg.RequiresConfirmation("github.close_issue") {
+		t.Error("unlisted action should not require confirmation")
+	}
+}
+
+func TestValidateSettings_ConfirmActions(t *testing.T) {
+	errs := validateSettings(&SettingsConfig{ConfirmActions: []string{"github.close_issue", "ai.code"}})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if errs[0].Field != "settings.confirm_actions[1]" || !strings.Contains(errs[0].Message, "not a destructive action") {
+		t.Errorf("unexpected error: %+v", errs[0])
+	}
+}
@@ -0,0 +1,265 @@
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ContainerConfig points a repo at its own session container image instead
+// of the one erg builds from the detected languages: a prebuilt image, or a
+// Dockerfile erg builds. Either bypasses language detection, for repos such
+// as monorepos with bespoke toolchains.
+type ContainerConfig struct {
+	// Image is a prebuilt image used as is, like settings.container_image.
+	Image string `yaml:"image,omitempty"`
+	// Digest pins Image to a content digest (sha256:...), so a moved tag
+	// never changes what sessions run. Sessions use image@digest.
+	Digest string `yaml:"digest,omitempty"`
+	// Dockerfile is a Dockerfile, relative to the repo root, that erg builds
+	// and layers Claude Code and the erg binary onto.
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+	// Context is the build context, relative to the repo root. Defaults to
+	// the Dockerfile's directory.
+	Context string `yaml:"context,omitempty"`
+	// BuildArgs are passed to the build as --build-arg values.
+	BuildArgs map[string]string `yaml:"build_args,omitempty"`
+
+	// Monorepo scopes each session's image to the components its issue
+	// mentions paths in: the nearest directory above each path with a
+	// language marker (go.mod, package.json, ...). Sessions of issues that
+	// mention none run in the repo's full image.
+	Monorepo bool `yaml:"monorepo,omitempty"`
+	// Components names a monorepo's components explicitly instead, for
+	// layouts where a component's files live outside its directory. Setting
+	// them implies Monorepo.
+	Components []ComponentConfig `yaml:"components,omitempty"`
+
+	// GPUs passes host GPUs into session containers, as docker run --gpus
+	// takes them: "all", a count, or "device=0,1". The host needs the NVIDIA
+	// Container Toolkit. Images erg builds for the repo are then built on
+	// NVIDIA's CUDA image.
+	GPUs string `yaml:"gpus,omitempty"`
+	// CUDA is that image's CUDA version, e.g. "12.4.1". Defaults to
+	// container.DefaultCUDAVersion.
+	CUDA string `yaml:"cuda,omitempty"`
+}
+
+// ComponentConfig is one component of a monorepo.
+type ComponentConfig struct {
+	// Path is the component's directory, relative to the repo root, whose
+	// languages its sessions' image is built for.
+	Path string `yaml:"path"`
+	// Match are globs of repo paths that belong to the component, where **
+	// matches any number of directories. Defaults to Path and everything
+	// under it.
+	Match []string `yaml:"match,omitempty"`
+}
+
+// Scoped reports whether sessions get images scoped to the components
+// their issue touches.
+func (c *ContainerConfig) Scoped() bool {
+	return c != nil && (c.Monorepo || len(c.Components) > 0)
+}
+
+// GPUDevices returns the GPUs session containers get, or "" for none.
+func (c *ContainerConfig) GPUDevices() string {
+	if c == nil {
+		return ""
+	}
+	return c.GPUs
+}
+
+// ComponentFor returns the directory of the first configured component
+// matching the repo-relative path p.
+func (c *ContainerConfig) ComponentFor(p string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	p = path.Clean(filepath.ToSlash(p))
+	for _, comp := range c.Components {
+		dir := path.Clean(filepath.ToSlash(comp.Path))
+		patterns := comp.Match
+		if len(patterns) == 0 {
+			patterns = []string{dir, dir + "/**"}
+		}
+		for _, pattern := range patterns {
+			if matchPathGlob(pattern, p) {
+				return dir, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchPathGlob matches a slash-separated path against a glob whose
+// segments follow path.Match, plus ** for any number of segments.
+func matchPathGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// digestRe matches an OCI content digest.
+var digestRe = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+var (
+	// gpusRe matches a docker run --gpus value.
+	gpusRe = regexp.MustCompile(`^(all|[1-9]\d*|device=[\w.-]+(,[\w.-]+)*)$`)
+	// cudaVersionRe matches a CUDA version as NVIDIA's image tags name it.
+	cudaVersionRe = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+)
+
+// ImageRef returns the image reference sessions run: Image pinned to Digest
+// when one is set.
+func (c *ContainerConfig) ImageRef() string {
+	if c.Digest == "" || strings.Contains(c.Image, "@") {
+		return c.Image
+	}
+	return c.Image + "@" + c.Digest
+}
+
+// validateContainer checks the container section names exactly one of an
+// image, a Dockerfile or monorepo scoping, with a well-formed digest and
+// repo-relative paths.
+func validateContainer(cfg *Config) []ValidationError {
+	c := cfg.Container
+	if c == nil {
+		return nil
+	}
+	var errs []ValidationError
+	switch {
+	case c.Scoped() && (c.Image != "" || c.Dockerfile != ""):
+		errs = append(errs, ValidationError{Field: "container", Message: "monorepo scoping builds images from detected languages; it cannot be combined with image or dockerfile"})
+	case c.Scoped():
+	case c.Image == "" && c.Dockerfile == "" && c.GPUs == "":
+		errs = append(errs, ValidationError{Field: "container", Message: "must set image, dockerfile, monorepo or gpus"})
+	case c.Image != "" && c.Dockerfile != "":
+		errs = append(errs, ValidationError{Field: "container", Message: "image and dockerfile are mutually exclusive"})
+	}
+	picksImage := c.Image != "" || c.Dockerfile != "" || c.Scoped()
+	if picksImage && cfg.Settings != nil && cfg.Settings.ContainerImage != "" {
+		errs = append(errs, ValidationError{Field: "container", Message: "cannot be combined with settings.container_image"})
+	}
+
+	if c.GPUs != "" && !gpusRe.MatchString(c.GPUs) {
+		errs = append(errs, ValidationError{Field: "container.gpus", Message: fmt.Sprintf("invalid GPUs %q (want all, a count, or device=0,1)", c.GPUs)})
+	}
+	if c.CUDA != "" {
+		switch {
+		case c.GPUs == "":
+			errs = append(errs, ValidationError{Field: "container.cuda", Message: "cuda applies only with container.gpus"})
+		case c.Image != "" || c.Dockerfile != "":
+			errs = append(errs, ValidationError{Field: "container.cuda", Message: "cuda applies only to images erg builds, not to container.image or dockerfile"})
+		}
+		if !cudaVersionRe.MatchString(c.CUDA) {
+			errs = append(errs, ValidationError{Field: "container.cuda", Message: fmt.Sprintf("invalid CUDA version %q (want e.g. 12.4.1)", c.CUDA)})
+		}
+	}
+
+	if c.Digest != "" {
+		if c.Image == "" {
+			errs = append(errs, ValidationError{Field: "container.digest", Message: "digest pins an image; set container.image"})
+		}
+		if !digestRe.MatchString(c.Digest) {
+			errs = append(errs, ValidationError{Field: "container.digest", Message: fmt.Sprintf("invalid digest %q (want sha256:<64 hex chars>)", c.Digest)})
+		}
+		if _, pinned, ok := strings.Cut(c.Image, "@"); ok && pinned != c.Digest {
+			errs = append(errs, ValidationError{Field: "container.digest", Message: "image already pins a different digest"})
+		}
+	}
+	if c.Dockerfile == "" && (c.Context != "" || len(c.BuildArgs) > 0) {
+		errs = append(errs, ValidationError{Field: "container", Message: "context and build_args apply only to a dockerfile"})
+	}
+	for _, p := range []struct{ field, path string }{{"container.dockerfile", c.Dockerfile}, {"container.context", c.Context}} {
+		if p.path != "" && !insideRepo(p.path) {
+			errs = append(errs, ValidationError{Field: p.field, Message: "must be a path inside the repo"})
+		}
+	}
+	for i, comp := range c.Components {
+		field := fmt.Sprintf("container.components[%d]", i)
+		switch {
+		case comp.Path == "":
+			errs = append(errs, ValidationError{Field: field + ".path", Message: "is required"})
+		case !insideRepo(comp.Path) || filepath.Clean(comp.Path) == ".":
+			errs = append(errs, ValidationError{Field: field + ".path", Message: "must be a directory inside the repo, below its root"})
+		}
+		for _, pattern := range comp.Match {
+			for seg := range strings.SplitSeq(pattern, "/") {
+				if _, err := path.Match(seg, ""); err != nil {
+					errs = append(errs, ValidationError{Field: field + ".match", Message: fmt.Sprintf("invalid glob %q", pattern)})
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// insideRepo reports whether p is a relative path that stays inside the
+// repo.
+func insideRepo(p string) bool {
+	return !filepath.IsAbs(p) && !strings.HasPrefix(filepath.Clean(p), "..")
+}
+
+// ImageRegistryConfig names a registry of prebuilt session images. Images
+// erg would build from the detected languages are tagged there by a
+// fingerprint of the languages, their versions and the erg version; a
+// session pulls the matching one and builds locally only on a miss.
+type ImageRegistryConfig struct {
+	// Repository is the image repository, without a tag, e.g.
+	// ghcr.io/acme/erg-base.
+	Repository string `yaml:"repository"`
+	// Push publishes images built on a miss, so other machines pull them.
+	Push bool `yaml:"push,omitempty"`
+}
+
+// ImageRegistry returns the repo's prebuilt image registry, or nil when it
+// sets none.
+func (c *Config) ImageRegistry() *ImageRegistryConfig {
+	if c == nil || c.Settings == nil {
+		return nil
+	}
+	return c.Settings.ImageRegistry
+}
+
+// imageRepositoryRe matches an image repository without a tag or digest:
+// an optional registry host and port, then lowercase path components.
+var imageRepositoryRe = regexp.MustCompile(`^[a-zA-Z0-9.-]+(:[0-9]+)?(/[a-z0-9]+(?:[._-]+[a-z0-9]+)*)+$`)
+
+// validateImageRegistry checks the prebuilt image repository is a plain
+// repository and that the repo builds images it would apply to.
+func validateImageRegistry(cfg *Config) []ValidationError {
+	if cfg.Settings == nil || cfg.Settings.ImageRegistry == nil {
+		return nil
+	}
+	var errs []ValidationError
+	if repo := cfg.Settings.ImageRegistry.Repository; !imageRepositoryRe.MatchString(repo) {
+		errs = append(errs, ValidationError{Field: "settings.image_registry.repository", Message: fmt.Sprintf("invalid image repository %q (want e.g. ghcr.io/acme/erg-base, without a tag)", repo)})
+	}
+	if cfg.Settings.ContainerImage != "" || (cfg.Container != nil && (cfg.Container.Image != "" || cfg.Container.Dockerfile != "")) {
+		errs = append(errs, ValidationError{Field: "settings.image_registry", Message: "applies only to images built from detected languages; remove it or the configured image"})
+	}
+	return errs
+}
@@ -0,0 +1,167 @@
+package workflow
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestContainerConfig_ImageRef(t *testing.T) {
+	tests := []struct {
+		c    ContainerConfig
+		want string
+	}{
+		{ContainerConfig{Image: "ghcr.io/acme/tools:1"}, "ghcr.io/acme/tools:1"},
+		{ContainerConfig{Image: "ghcr.io/acme/tools:1", Digest: testDigest}, "ghcr.io/acme/tools:1@" + testDigest},
+		{ContainerConfig{Image: "ghcr.io/acme/tools@" + testDigest, Digest: testDigest}, "ghcr.io/acme/tools@" + testDigest},
+	}
+	for _, tt := range tests {
+		if got := tt.c.ImageRef(); got != tt.want {
+			t.Errorf("ImageRef() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestValidate_Container(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *ContainerConfig
+		want []string
+	}{
+		{name: "image", c: &ContainerConfig{Image: "ghcr.io/acme/tools:1", Digest: testDigest}},
+		{name: "dockerfile", c: &ContainerConfig{Dockerfile: "tools/Dockerfile", Context: ".", BuildArgs: map[string]string{"GO": "1.23"}}},
+		{name: "neither", c: &ContainerConfig{}, want: []string{"container"}},
+		{name: "both", c: &ContainerConfig{Image: "x", Dockerfile: "Dockerfile"}, want: []string{"container"}},
+		{name: "bad digest", c: &ContainerConfig{Image: "x", Digest: "sha256:abc"}, want: []string{"container.digest"}},
+		{name: "digest without image", c: &ContainerConfig{Dockerfile: "Dockerfile", Digest: testDigest}, want: []string{"container.digest"}},
+		{name: "build args on image", c: &ContainerConfig{Image: "x", BuildArgs: map[string]string{"A": "1"}}, want: []string{"container"}},
+		{name: "path outside repo", c: &ContainerConfig{Dockerfile: "../Dockerfile", Context: "/src"}, want: []string{"container.dockerfile", "container.context"}},
+		{name: "monorepo", c: &ContainerConfig{Monorepo: true}},
+		{name: "components", c: &ContainerConfig{Components: []ComponentConfig{{Path: "web"}, {Path: "services/api", Match: []string{"proto/**"}}}}},
+		{name: "monorepo with image", c: &ContainerConfig{Image: "x", Monorepo: true}, want: []string{"container"}},
+		{name: "gpus only", c: &ContainerConfig{GPUs: "all", CUDA: "12.4.1"}},
+		{name: "gpus with image", c: &ContainerConfig{Image: "x", GPUs: "device=0,1"}},
+		{name: "bad gpus", c: &ContainerConfig{GPUs: "every"}, want: []string{"container.gpus"}},
+		{name: "cuda without gpus", c: &ContainerConfig{Monorepo: true, CUDA: "12.4"}, want: []string{"container.cuda", "container.cuda"}},
+		{name: "cuda with image", c: &ContainerConfig{Image: "x", GPUs: "1", CUDA: "12.4.1"}, want: []string{"container.cuda"}},
+		{name: "bad components", c: &ContainerConfig{Components: []ComponentConfig{{}, {Path: "."}, {Path: "../web"}, {Path: "web", Match: []string{"web/[**"}}}}, want: []string{"container.components[0].path", "container.components[1].path", "container.components[2].path", "container.components[3].match"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := migrationTestConfig(nil)
+			cfg.Container = tt.c
+			var fields []string
+			for _, e := range Validate(cfg) {
+				fields = append(fields, e.Field)
+			}
+			if !slices.Equal(fields, tt.want) {
+				t.Errorf("errors on %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ContainerConflictsWithContainerImage(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.ContainerImage = "ghcr.io/zhubert/erg"
+	cfg.Container = &ContainerConfig{Image: "ghcr.io/acme/tools:1"}
+	errs := Validate(cfg)
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "settings.container_image") {
+		t.Errorf("expected a conflict error, got %v", errs)
+	}
+}
+
+func TestValidate_GPUsWithContainerImage(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.ContainerImage = "ghcr.io/acme/ml:1"
+	cfg.Container = &ContainerConfig{GPUs: "all"}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected GPUs allowed with settings.container_image, got %v", errs)
+	}
+}
+
+func TestContainerConfig_YAMLAndMerge(t *testing.T) {
+	var partial Config
+	err := yaml.Unmarshal([]byte(`
+container:
+  dockerfile: tools/ci/Dockerfile
+  context: .
+  build_args:
+    NODE_VERSION: "20"
+`), &partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := Merge(&partial, DefaultWorkflowConfig())
+	c := merged.Container
+	if c == nil || c.Dockerfile != "tools/ci/Dockerfile" || c.Context != "." || c.BuildArgs["NODE_VERSION"] != "20" {
+		t.Errorf("expected the container section kept through merge, got %+v", c)
+	}
+}
+
+func TestContainerConfig_ComponentFor(t *testing.T) {
+	c := &ContainerConfig{Components: []ComponentConfig{
+		{Path: "web"},
+		{Path: "services/api/", Match: []string{"services/api/**", "proto/**/*.proto"}},
+	}}
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"web", "web", true},
+		{"web/src/App.tsx", "web", true},
+		{"services/api/main.go", "services/api", true},
+		{"proto/v1/api.proto", "services/api", true},
+		{"proto/README.md", "", false},
+		{"webapp/index.ts", "", false},
+		{"docs/index.md", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := c.ComponentFor(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ComponentFor(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+	if (*ContainerConfig)(nil).Scoped() || !c.Scoped() || !(&ContainerConfig{Monorepo: true}).Scoped() {
+		t.Error("unexpected Scoped()")
+	}
+}
+
+func TestValidate_ImageRegistry(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		image      string
+		wantField  string
+	}{
+		{name: "valid", repository: "ghcr.io/acme/erg-base"},
+		{name: "registry port", repository: "localhost:5000/erg-base"},
+		{name: "docker hub", repository: "acme/erg-base"},
+		{name: "tag", repository: "ghcr.io/acme/erg-base:latest", wantField: "settings.image_registry.repository"},
+		{name: "no namespace", repository: "erg-base", wantField: "settings.image_registry.repository"},
+		{name: "empty", repository: "", wantField: "settings.image_registry.repository"},
+		{name: "with configured image", repository: "ghcr.io/acme/erg-base", image: "node:20", wantField: "settings.image_registry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := migrationTestConfig(nil)
+			cfg.Settings.ImageRegistry = &ImageRegistryConfig{Repository: tt.repository}
+			cfg.Settings.ContainerImage = tt.image
+			errs := Validate(cfg)
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("expected valid, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.wantField {
+				t.Errorf("expected one error on %s, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package workflow
+
+// defaultDeadLetterAfter is how many times an issue's work may fail before
+// it is moved to the dead-letter queue when settings.dead_letter_after is
+// unset.
+const defaultDeadLetterAfter = 3
+
+// DeadLetterAfter returns how many failed attempts move an issue to the
+// dead-letter queue.
+func (c *Config) DeadLetterAfter() int {
+	if c != nil && c.Settings != nil && c.Settings.DeadLetterAfter > 0 {
+		return c.Settings.DeadLetterAfter
+	}
+	return defaultDeadLetterAfter
+}
@@ -0,0 +1,14 @@
+package workflow
+
+import "testing"
+
+func TestConfig_DeadLetterAfter(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.DeadLetterAfter(); got != defaultDeadLetterAfter {
+		t.Errorf("nil config = %d, want %d", got, defaultDeadLetterAfter)
+	}
+	cfg := &Config{Settings: &SettingsConfig{DeadLetterAfter: 5}}
+	if got := cfg.DeadLetterAfter(); got != 5 {
+		t.Errorf("DeadLetterAfter = %d, want 5", got)
+	}
+}
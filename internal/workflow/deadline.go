@@ -0,0 +1,16 @@
+package workflow
+
+import "time"
+
+// defaultDeadlineWindow is how close to its due date an issue must be to jump
+// the queue when source.deadline_window is unset.
+const defaultDeadlineWindow = 72 * time.Hour
+
+// DeadlineWindow returns how close to its due date an issue must be to be
+// picked up ahead of the queue order. Zero disables deadline escalation.
+func (c *Config) DeadlineWindow() time.Duration {
+	if c != nil && c.Source.DeadlineWindow != nil {
+		return c.Source.DeadlineWindow.Duration
+	}
+	return defaultDeadlineWindow
+}
@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_DeadlineWindow(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.DeadlineWindow(); got != defaultDeadlineWindow {
+		t.Errorf("nil config window = %v, want %v", got, defaultDeadlineWindow)
+	}
+	if got := (&Config{}).DeadlineWindow(); got != defaultDeadlineWindow {
+		t.Errorf("default window = %v, want %v", got, defaultDeadlineWindow)
+	}
+
+	cfg := &Config{Source: SourceConfig{DeadlineWindow: &Duration{5 * 24 * time.Hour}}}
+	if got := cfg.DeadlineWindow(); got != 120*time.Hour {
+		t.Errorf("window = %v, want 120h", got)
+	}
+	cfg.Source.DeadlineWindow = &Duration{}
+	if got := cfg.DeadlineWindow(); got != 0 {
+		t.Errorf("explicit zero window = %v, want disabled", got)
+	}
+}
@@ -826,14 +826,15 @@ func LinearAwaitStateTemplateConfig() *TemplateConfig {
 
 // Merge overlays partial onto defaults. States present in partial replace the
 // corresponding default state entirely. States in defaults but not in partial
-// are preserved. Top-level fields (Workflow, Start) use partial if non-empty.
-// Source fields use partial if non-empty.
+// are preserved. Top-level fields (Workflow, Start, Triggers, Workflows) use
+// partial if non-empty. Source fields use partial if non-empty.
 func Merge(partial, defaults *Config) *Config {
 	result := &Config{
 		Workflow: partial.Workflow,
 		Start:    partial.Start,
 		Source:   partial.Source,
 		States:   make(map[string]*State),
+		Triggers: partial.Triggers,
 	}
 
 	// Fill empty top-level fields from defaults
@@ -843,6 +844,33 @@ func Merge(partial, defaults *Config) *Config {
 	if result.Start == "" {
 		result.Start = defaults.Start
 	}
+	if len(result.Triggers) == 0 {
+		result.Triggers = defaults.Triggers
+	}
+	result.Workflows = partial.Workflows
+	if len(result.Workflows) == 0 {
+		result.Workflows = defaults.Workflows
+	}
+	result.Mutex = partial.Mutex
+	if result.Mutex == "" {
+		result.Mutex = defaults.Mutex
+	}
+	result.Container = partial.Container
+	if result.Container == nil {
+		result.Container = defaults.Container
+	}
+	result.Services = partial.Services
+	if len(result.Services) == 0 {
+		result.Services = defaults.Services
+	}
+	result.Preflight = partial.Preflight
+	if result.Preflight == nil {
+		result.Preflight = defaults.Preflight
+	}
+	result.Backends = partial.Backends
+	if len(result.Backends) == 0 {
+		result.Backends = defaults.Backends
+	}
 
 	// Source
 	if result.Source.Provider == "" {
@@ -860,6 +888,9 @@ func Merge(partial, defaults *Config) *Config {
 	if result.Source.Filter.Section == "" {
 		result.Source.Filter.Section = defaults.Source.Filter.Section
 	}
+	if result.Source.Filter.MaxItems == 0 {
+		result.Source.Filter.MaxItems = defaults.Source.Filter.MaxItems
+	}
 
 	// Copy defaults first
 	for name, state := range defaults.States {
@@ -430,6 +430,28 @@ func TestMerge(t *testing.T) {
 		}
 	})
 
+	t.Run("partial triggers preserved", func(t *testing.T) {
+		partial := &Config{
+			Triggers: []TriggerConfig{{Schedule: "0 9 * * 1", State: "coding"}},
+		}
+		result := Merge(partial, DefaultWorkflowConfig())
+
+		if len(result.Triggers) != 1 || result.Triggers[0].State != "coding" {
+			t.Errorf("triggers: got %+v", result.Triggers)
+		}
+	})
+
+	t.Run("default triggers used when partial has none", func(t *testing.T) {
+		defaults := &Config{
+			Triggers: []TriggerConfig{{Schedule: "@daily", State: "sweep"}},
+		}
+		result := Merge(&Config{}, defaults)
+
+		if len(result.Triggers) != 1 || result.Triggers[0].State != "sweep" {
+			t.Errorf("triggers: got %+v", result.Triggers)
+		}
+	})
+
 	t.Run("partial state replaces default entirely", func(t *testing.T) {
 		partial := &Config{
 			States: map[string]*State{
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// dotStart is the node ID of the entry point in DOT output.
+const dotStart = "__start"
+
+// GenerateDOT renders the workflow as a Graphviz digraph. Parallel and join
+// states are drawn as bars, choice states as diamonds, and terminal states as
+// double circles, with fail states filled red.
+func GenerateDOT(cfg *Config) string {
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	b.WriteString("    rankdir=TB;\n")
+	b.WriteString("    node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("    edge [fontname=\"Helvetica\", fontsize=10];\n")
+	if cfg == nil || len(cfg.States) == 0 {
+		b.WriteString("}\n")
+		return b.String()
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		state := cfg.States[name]
+		label := dotQuote(graphLabel(name, state))
+		switch state.Type {
+		case StateTypeParallel, StateTypeJoin:
+			fmt.Fprintf(&b, "    %s [shape=box, style=filled, fillcolor=black, height=0.08, width=1.5, label=\"\", xlabel=%s];\n", graphID(name), dotQuote(name))
+		case StateTypeChoice:
+			fmt.Fprintf(&b, "    %s [shape=diamond, style=\"\", label=%s];\n", graphID(name), label)
+		case StateTypeSucceed:
+			fmt.Fprintf(&b, "    %s [shape=doublecircle, style=\"\", label=%s];\n", graphID(name), label)
+		case StateTypeFail:
+			fmt.Fprintf(&b, "    %s [shape=doublecircle, style=filled, fillcolor=\"#f8d7da\", color=\"#c0392b\", label=%s];\n", graphID(name), label)
+		default:
+			fmt.Fprintf(&b, "    %s [label=%s];\n", graphID(name), label)
+		}
+	}
+
+	if cfg.Start != "" {
+		fmt.Fprintf(&b, "    %s [shape=point, width=0.2, label=\"\"];\n", dotStart)
+		fmt.Fprintf(&b, "    %s -> %s;\n", dotStart, graphID(cfg.Start))
+	}
+	for _, e := range graphEdges(cfg) {
+		if e.To == "" {
+			continue // terminal states are drawn as end points
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "    %s -> %s [label=%s];\n", graphID(e.From), graphID(e.To), dotQuote(e.Label))
+		} else {
+			fmt.Fprintf(&b, "    %s -> %s;\n", graphID(e.From), graphID(e.To))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -6,6 +6,9 @@ import (
 	"log/slog"
 	"maps"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -74,7 +77,7 @@ func (e *Engine) GetConfig() *Config {
 
 // ProcessStep processes the current step for a work item.
 // It dispatches based on state type: succeed/fail → terminal,
-// task → execute action, wait → check event.
+// task → execute action, wait → check event, parallel → run branches.
 func (e *Engine) ProcessStep(ctx context.Context, item *WorkItemView) (*StepResult, error) {
 	state, ok := e.config.States[item.CurrentStep]
 	if !ok {
@@ -112,6 +115,12 @@ func (e *Engine) ProcessStep(ctx context.Context, item *WorkItemView) (*StepResu
 	case StateTypePass:
 		return e.processPassState(item, state)
 
+	case StateTypeParallel:
+		return e.processParallelState(ctx, item, state)
+
+	case StateTypeJoin:
+		return e.processJoinState(item, state)
+
 	default:
 		return nil, fmt.Errorf("unsupported state type %q", state.Type)
 	}
@@ -124,7 +133,7 @@ func (e *Engine) processTaskState(ctx context.Context, item *WorkItemView, state
 		return nil, fmt.Errorf("no action registered for %q", state.Action)
 	}
 
-	params := NewParamHelper(state.Params)
+	params := NewParamHelper(ExpandParams(state.Params, item.StepData))
 	ac := &ActionContext{
 		WorkItemID: item.ID,
 		SessionID:  item.SessionID,
@@ -185,6 +194,14 @@ func (e *Engine) processWaitState(ctx context.Context, item *WorkItemView, state
 		return nil, fmt.Errorf("no event checker configured")
 	}
 
+	// A stalled item stays parked until it is resumed.
+	if item.Phase == PhaseStalled {
+		return &StepResult{
+			NewStep:  item.CurrentStep,
+			NewPhase: item.Phase,
+		}, nil
+	}
+
 	// Enforce timeout if configured and StepEnteredAt is set
 	if state.Timeout != nil && !item.StepEnteredAt.IsZero() {
 		elapsed := time.Since(item.StepEnteredAt)
@@ -195,6 +212,14 @@ func (e *Engine) processWaitState(ctx context.Context, item *WorkItemView, state
 				"elapsed", elapsed,
 			)
 
+			if state.OnTimeout == TimeoutStall {
+				return &StepResult{
+					NewStep:  item.CurrentStep,
+					NewPhase: PhaseStalled,
+					Data:     map[string]any{"timeout": true, "timeout_elapsed": elapsed.String()},
+				}, nil
+			}
+
 			// Use timeout_next edge if available, otherwise fall back to error edge
 			if state.TimeoutNext != "" {
 				return &StepResult{
@@ -228,10 +253,11 @@ func (e *Engine) processWaitState(ctx context.Context, item *WorkItemView, state
 	}
 
 	if !fired {
-		// Event hasn't fired — no change
+		// Event hasn't fired — no change beyond any escalation that is due
 		return &StepResult{
 			NewStep:  item.CurrentStep,
 			NewPhase: item.Phase,
+			Data:     e.escalate(ctx, item, state),
 		}, nil
 	}
 
@@ -244,6 +270,58 @@ func (e *Engine) processWaitState(ctx context.Context, item *WorkItemView, state
 	}, nil
 }
 
+// escalate runs the wait state's escalation actions that have come due since
+// the item entered the state, in order, and returns the step data recording
+// how far it got, or nil if nothing ran. Progress is tied to the time the
+// state was entered, so an item that re-enters the state starts over. A
+// failed action is logged and not retried; escalation moves on.
+func (e *Engine) escalate(ctx context.Context, item *WorkItemView, state *State) map[string]any {
+	if len(state.Escalation) == 0 || item.StepEnteredAt.IsZero() {
+		return nil
+	}
+	entered := item.StepEnteredAt.UTC().Format(time.RFC3339Nano)
+	level := 0
+	if item.StepData["_escalation_entered"] == entered {
+		level = intValue(item.StepData["_escalation_level"])
+	}
+
+	elapsed := time.Since(item.StepEnteredAt)
+	ran := level
+	for ran < len(state.Escalation) && elapsed >= state.Escalation[ran].After.Duration {
+		esc := state.Escalation[ran]
+		ran++
+		log := e.logger.With("state", item.CurrentStep, "level", ran, "action", esc.Action)
+		if e.config.RequiresConfirmation(esc.Action) {
+			log.Warn("skipping escalation that requires confirmation")
+			continue
+		}
+		action := e.actions.Get(esc.Action)
+		if action == nil {
+			log.Warn("no action registered for escalation")
+			continue
+		}
+		result := action.Execute(ctx, &ActionContext{
+			WorkItemID: item.ID,
+			SessionID:  item.SessionID,
+			RepoPath:   item.RepoPath,
+			Branch:     item.Branch,
+			Step:       item.CurrentStep,
+			Params:     NewParamHelper(ExpandParams(esc.Params, item.StepData)),
+			Logger:     e.logger,
+			Extra:      item.Extra,
+		})
+		if !result.Success {
+			log.Warn("escalation action failed", "error", result.Error)
+			continue
+		}
+		log.Info("escalated wait state", "elapsed", elapsed)
+	}
+	if ran == level {
+		return nil
+	}
+	return map[string]any{"_escalation_entered": entered, "_escalation_level": ran}
+}
+
 // processChoiceState evaluates choice rules against step data and transitions accordingly.
 func (e *Engine) processChoiceState(item *WorkItemView, state *State) (*StepResult, error) {
 	for _, rule := range state.Choices {
@@ -298,13 +376,24 @@ func evaluateChoiceRule(rule ChoiceRule, data map[string]any) bool {
 	return false
 }
 
-// lookupVariable retrieves a value from step data by key.
+// lookupVariable retrieves a value from step data by key. An exact key match
+// wins; otherwise a dotted key such as "result.status" walks nested maps.
 func lookupVariable(key string, data map[string]any) (any, bool) {
 	if data == nil {
 		return nil, false
 	}
-	val, ok := data[key]
-	return val, ok
+	if val, ok := data[key]; ok {
+		return val, true
+	}
+	head, rest, found := strings.Cut(key, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := data[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupVariable(rest, nested)
 }
 
 // valuesEqual compares two values for equality, handling numeric type coercion
@@ -360,6 +449,150 @@ func (e *Engine) processPassState(item *WorkItemView, state *State) (*StepResult
 	}, nil
 }
 
+// ParallelResultsKey is the step data key under which a parallel state records
+// each branch's outcome, as {branch: {"status": "succeeded"|"failed", "error": ...}}.
+const ParallelResultsKey = "parallel_results"
+
+// processParallelState runs every branch concurrently and moves to the join
+// state once all of them have finished. Branch failures don't stop the other
+// branches; they are recorded in ParallelResultsKey for the join to act on.
+func (e *Engine) processParallelState(ctx context.Context, item *WorkItemView, state *State) (*StepResult, error) {
+	type outcome struct {
+		data map[string]any
+		err  error
+	}
+	outcomes := make([]outcome, len(state.Branches))
+
+	var wg sync.WaitGroup
+	for i, start := range state.Branches {
+		wg.Go(func() {
+			data, err := e.runBranch(ctx, item, start, state.Next)
+			outcomes[i] = outcome{data: data, err: err}
+		})
+	}
+	wg.Wait()
+
+	// Branch data is merged in declaration order, so on a key collision the
+	// later branch wins.
+	data := make(map[string]any)
+	results := make(map[string]any, len(state.Branches))
+	for i, branch := range state.Branches {
+		maps.Copy(data, outcomes[i].data)
+		if err := outcomes[i].err; err != nil {
+			e.logger.Info("parallel branch failed", "state", item.CurrentStep, "branch", branch, "error", err)
+			results[branch] = map[string]any{"status": "failed", "error": err.Error()}
+			continue
+		}
+		results[branch] = map[string]any{"status": "succeeded"}
+	}
+	data[ParallelResultsKey] = results
+
+	return &StepResult{
+		NewStep:  state.Next,
+		NewPhase: "idle",
+		Data:     data,
+		Hooks:    state.After,
+	}, nil
+}
+
+// runBranch executes one branch of a parallel state, following next edges
+// from start through task and pass states until it reaches join. It returns
+// the data produced along the way and the first action failure, if any.
+func (e *Engine) runBranch(ctx context.Context, item *WorkItemView, start, join string) (map[string]any, error) {
+	data := make(map[string]any)
+	visited := make(map[string]bool)
+	for cur := start; cur != join; {
+		if visited[cur] {
+			return data, fmt.Errorf("branch loops back to %q", cur)
+		}
+		visited[cur] = true
+
+		state, ok := e.config.States[cur]
+		if !ok {
+			return data, fmt.Errorf("unknown state %q", cur)
+		}
+
+		switch state.Type {
+		case StateTypePass:
+			maps.Copy(data, state.Data)
+			cur = state.Next
+
+		case StateTypeTask:
+			if e.config.RequiresConfirmation(state.Action) {
+				return data, fmt.Errorf("%s: action %q requires confirmation and cannot run in a parallel branch", cur, state.Action)
+			}
+			action := e.actions.Get(state.Action)
+			if action == nil {
+				return data, fmt.Errorf("no action registered for %q", state.Action)
+			}
+			result := action.Execute(ctx, &ActionContext{
+				WorkItemID: item.ID,
+				SessionID:  item.SessionID,
+				RepoPath:   item.RepoPath,
+				Branch:     item.Branch,
+				Step:       cur,
+				Params:     NewParamHelper(ExpandParams(state.Params, item.StepData)),
+				Logger:     e.logger,
+				Extra:      item.Extra,
+			})
+			maps.Copy(data, result.Data)
+			if result.Async {
+				return data, fmt.Errorf("%s: action %q is async and cannot run in a parallel branch", cur, state.Action)
+			}
+			if !result.Success {
+				errStr := "action failed"
+				if result.Error != nil {
+					errStr = result.Error.Error()
+				}
+				return data, fmt.Errorf("%s: %s", cur, errStr)
+			}
+			cur = state.Next
+			if result.OverrideNext != "" {
+				cur = result.OverrideNext
+			}
+
+		default:
+			return data, fmt.Errorf("%s: %s states cannot run in a parallel branch", cur, state.Type)
+		}
+	}
+	return data, nil
+}
+
+// processJoinState waits for the branches of the preceding parallel state.
+// All branches have finished by the time a join is reached, so it only
+// checks their outcomes: next if every branch succeeded, error otherwise.
+func (e *Engine) processJoinState(item *WorkItemView, state *State) (*StepResult, error) {
+	results, _ := item.StepData[ParallelResultsKey].(map[string]any)
+
+	var failed []string
+	for _, branch := range slices.Sorted(maps.Keys(results)) {
+		r, _ := results[branch].(map[string]any)
+		if status, _ := r["status"].(string); status != "succeeded" {
+			msg, _ := r["error"].(string)
+			failed = append(failed, fmt.Sprintf("%s (%s)", branch, msg))
+		}
+	}
+
+	if len(failed) == 0 {
+		return &StepResult{
+			NewStep:  state.Next,
+			NewPhase: "idle",
+			Hooks:    state.After,
+		}, nil
+	}
+
+	errStr := "parallel branches failed: " + strings.Join(failed, "; ")
+	if state.Error != "" {
+		return &StepResult{
+			NewStep:  state.Error,
+			NewPhase: "idle",
+			Data:     map[string]any{"_last_error": errStr},
+			Hooks:    state.After,
+		}, nil
+	}
+	return nil, fmt.Errorf("join state %q: %s", item.CurrentStep, errStr)
+}
+
 // AdvanceAfterAsync is called when an async action (e.g., Claude worker) completes.
 // It determines the next step based on success/failure.
 func (e *Engine) AdvanceAfterAsync(item *WorkItemView, success bool) (*StepResult, error) {
@@ -386,6 +619,17 @@ func (e *Engine) AdvanceAfterAsync(item *WorkItemView, success bool) (*StepResul
 	}, nil
 }
 
+// AdvanceAfterAsyncFailure is AdvanceAfterAsync for a failed async action
+// whose reason is known, such as ErrorContainerOOM or ErrorEnvSetupFailed,
+// so the state's retry and catch rules can match it.
+func (e *Engine) AdvanceAfterAsyncFailure(item *WorkItemView, reason string) (*StepResult, error) {
+	state, ok := e.config.States[item.CurrentStep]
+	if !ok {
+		return nil, fmt.Errorf("unknown state %q", item.CurrentStep)
+	}
+	return e.handleFailure(item, state, reason, nil)
+}
+
 // handleFailure processes a failure in a task state, checking retry and catch rules
 // before falling back to the error edge.
 func (e *Engine) handleFailure(item *WorkItemView, state *State, errStr string, data map[string]any) (*StepResult, error) {
@@ -395,7 +639,10 @@ func (e *Engine) handleFailure(item *WorkItemView, state *State, errStr string,
 		retryRules = DefaultRetryForAction(state.Action)
 	}
 
-	// Check retry rules first
+	// Check retry rules first. A spent item cap would only be spent again.
+	if errStr == ErrorBudgetExceeded {
+		retryRules = nil
+	}
 	retryCount := getRetryCount(item.StepData)
 	for _, retry := range retryRules {
 		if !matchesErrors(errStr, retry.Errors) {
@@ -445,6 +692,29 @@ func (e *Engine) handleFailure(item *WorkItemView, state *State, errStr string,
 		}
 	}
 
+	// A container that failed preflight goes to the workflow's
+	// env_setup_failed state, if it has one, rather than down the error edge
+	// meant for the agent's own failures.
+	if _, ok := e.config.States[EnvSetupFailedState]; ok && errStr == ErrorEnvSetupFailed {
+		return &StepResult{
+			NewStep:  EnvSetupFailedState,
+			NewPhase: "idle",
+			Data:     mergeData(data, map[string]any{"_last_error": errStr}),
+			Hooks:    state.After,
+		}, nil
+	}
+
+	// Likewise a work item that reached its spend cap goes to the workflow's
+	// budget_exceeded state, if it has one.
+	if _, ok := e.config.States[BudgetExceededState]; ok && errStr == ErrorBudgetExceeded {
+		return &StepResult{
+			NewStep:  BudgetExceededState,
+			NewPhase: "idle",
+			Data:     mergeData(data, map[string]any{"_last_error": errStr}),
+			Hooks:    state.After,
+		}, nil
+	}
+
 	// Fall back to error edge
 	if state.Error != "" {
 		errorData := mergeData(data, map[string]any{
@@ -466,10 +736,12 @@ func getRetryCount(stepData map[string]any) int {
 	if stepData == nil {
 		return 0
 	}
-	v, ok := stepData["_retry_count"]
-	if !ok {
-		return 0
-	}
+	return intValue(stepData["_retry_count"])
+}
+
+// intValue reads a step data counter, which is an int when set in memory and
+// a float64 once it has round-tripped through the JSON state file.
+func intValue(v any) int {
 	switch n := v.(type) {
 	case int:
 		return n
@@ -660,9 +932,11 @@ func (e *Engine) findFirstWaitStateByEvent(event string) string {
 }
 
 // stateOutgoing returns all states directly reachable from state in one step,
-// following every possible transition edge (next, error, timeout_next, catch, choices, default).
+// following every possible transition edge (next, error, timeout_next, catch, choices, default,
+// branches).
 func stateOutgoing(state *State) []string {
 	var nexts []string
+	nexts = append(nexts, state.Branches...)
 	if state.Next != "" {
 		nexts = append(nexts, state.Next)
 	}
@@ -2,7 +2,9 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"testing"
 	"time"
 
@@ -614,6 +616,117 @@ func TestEngine_ProcessStep_WaitTimeout_ZeroEnteredAt(t *testing.T) {
 	}
 }
 
+func TestEngine_ProcessStep_WaitTimeout_Stall(t *testing.T) {
+	cfg := &Config{
+		Start: "wait",
+		States: map[string]*State{
+			"wait":   {Type: StateTypeWait, Event: "pr.reviewed", Timeout: &Duration{1 * time.Hour}, OnTimeout: TimeoutStall, Next: "done", Error: "failed"},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		},
+	}
+	engine := NewEngine(cfg, NewActionRegistry(), &mockEventChecker{fired: true}, testutil.DiscardLogger())
+
+	view := &WorkItemView{
+		CurrentStep:   "wait",
+		Phase:         "idle",
+		StepEnteredAt: time.Now().Add(-2 * time.Hour),
+	}
+	result, err := engine.ProcessStep(context.Background(), view)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "wait" || result.NewPhase != PhaseStalled {
+		t.Errorf("expected to stall on wait, got %q/%q", result.NewStep, result.NewPhase)
+	}
+
+	// A stalled item stays parked even when the event fires.
+	view.Phase = PhaseStalled
+	result, err = engine.ProcessStep(context.Background(), view)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "wait" || result.NewPhase != PhaseStalled || result.Data != nil {
+		t.Errorf("expected stalled item to stay parked, got %+v", result)
+	}
+}
+
+// recordingAction counts its executions and keeps the last params it saw.
+type recordingAction struct {
+	calls  int
+	params *ParamHelper
+	result ActionResult
+}
+
+func (a *recordingAction) Execute(ctx context.Context, ac *ActionContext) ActionResult {
+	a.calls++
+	a.params = ac.Params
+	return a.result
+}
+
+func TestEngine_ProcessStep_WaitEscalation(t *testing.T) {
+	ping := &recordingAction{result: ActionResult{Success: true}}
+	notify := &recordingAction{result: ActionResult{Error: errors.New("slack down")}}
+	registry := NewActionRegistry()
+	registry.Register("github.comment_pr", ping)
+	registry.Register("slack.notify", notify)
+
+	cfg := &Config{
+		Start: "wait",
+		States: map[string]*State{
+			"wait": {
+				Type: StateTypeWait, Event: "pr.reviewed", Next: "done",
+				Escalation: []EscalationConfig{
+					{After: Duration{1 * time.Hour}, Action: "github.comment_pr", Params: map[string]any{"body": "Review for {{step.pr_url}}?"}},
+					{After: Duration{2 * time.Hour}, Action: "slack.notify"},
+					{After: Duration{3 * time.Hour}, Action: "github.comment_pr"},
+				},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+	engine := NewEngine(cfg, registry, &mockEventChecker{fired: false}, testutil.DiscardLogger())
+
+	entered := time.Now().Add(-150 * time.Minute)
+	view := &WorkItemView{
+		CurrentStep:   "wait",
+		Phase:         "idle",
+		StepEnteredAt: entered,
+		StepData:      map[string]any{"pr_url": "https://github.com/o/r/pull/1"},
+	}
+	result, err := engine.ProcessStep(context.Background(), view)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "wait" || result.NewPhase != "idle" {
+		t.Fatalf("expected to keep waiting, got %q/%q", result.NewStep, result.NewPhase)
+	}
+	if ping.calls != 1 || notify.calls != 1 {
+		t.Fatalf("expected both due levels to run once, got ping=%d notify=%d", ping.calls, notify.calls)
+	}
+	if got := ping.params.String("body", ""); got != "Review for https://github.com/o/r/pull/1?" {
+		t.Errorf("expected expanded params, got %q", got)
+	}
+	if result.Data["_escalation_level"] != 2 {
+		t.Errorf("expected level 2 recorded (failed level is not retried), got %v", result.Data)
+	}
+
+	// Recorded progress (as after a JSON round trip) stops levels re-running.
+	maps.Copy(view.StepData, result.Data)
+	view.StepData["_escalation_level"] = float64(2)
+	result, _ = engine.ProcessStep(context.Background(), view)
+	if ping.calls != 1 || notify.calls != 1 || result.Data != nil {
+		t.Errorf("expected no repeat escalation, got ping=%d notify=%d data=%v", ping.calls, notify.calls, result.Data)
+	}
+
+	// Re-entering the state starts escalation over.
+	view.StepEnteredAt = time.Now().Add(-90 * time.Minute)
+	result, _ = engine.ProcessStep(context.Background(), view)
+	if ping.calls != 2 || result.Data["_escalation_level"] != 1 {
+		t.Errorf("expected first level to run again after re-entry, got ping=%d data=%v", ping.calls, result.Data)
+	}
+}
+
 func TestEngine_FullTraversal(t *testing.T) {
 	// Test a full workflow traversal with sync actions and event checks.
 	// New flow: coding → open_pr → await_ci → check_ci_result → await_review → merge → done
@@ -1745,3 +1858,186 @@ func (a *captureStepAction) Execute(_ context.Context, ac *ActionContext) Action
 	*a.capturedStep = ac.Step
 	return ActionResult{Success: true}
 }
+
+func TestEngine_ProcessStep_ExpandsStepDataInParams(t *testing.T) {
+	action := &captureParamsAction{result: ActionResult{Success: true}}
+	cfg := &Config{
+		Start: "test",
+		States: map[string]*State{
+			"test": {
+				Type:   StateTypeTask,
+				Action: "test.action",
+				Params: map[string]any{"command": "go test {{step.packages}}"},
+				Next:   "done",
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+	registry := NewActionRegistry()
+	registry.Register("test.action", action)
+	engine := NewEngine(cfg, registry, nil, testutil.DiscardLogger())
+
+	view := &WorkItemView{
+		CurrentStep: "test",
+		StepData:    map[string]any{"packages": []any{"./cmd", "./internal/daemon"}},
+	}
+	if _, err := engine.ProcessStep(context.Background(), view); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := action.lastParams.String("command", ""); got != "go test ./cmd ./internal/daemon" {
+		t.Errorf("command = %q", got)
+	}
+}
+
+func TestLookupVariable_DottedPath(t *testing.T) {
+	data := map[string]any{
+		"result":     map[string]any{"status": "partial", "nested": map[string]any{"n": 1}},
+		"flat.key":   "exact",
+		"not_a_map":  "x",
+		"confidence": 0.5,
+	}
+	tests := []struct {
+		key    string
+		want   any
+		wantOK bool
+	}{
+		{"result.status", "partial", true},
+		{"result.nested.n", 1, true},
+		{"flat.key", "exact", true},
+		{"result.missing", nil, false},
+		{"not_a_map.status", nil, false},
+		{"confidence", 0.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := lookupVariable(tt.key, data)
+			if ok != tt.wantOK || (ok && !valuesEqual(got, tt.want)) {
+				t.Errorf("lookupVariable(%q) = %v, %v; want %v, %v", tt.key, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// barrierAction blocks until n executions are in flight at once, proving
+// that parallel branches run concurrently rather than one after another.
+type barrierAction struct {
+	arrived chan struct{}
+	n       int
+}
+
+func (a *barrierAction) Execute(ctx context.Context, ac *ActionContext) ActionResult {
+	a.arrived <- struct{}{}
+	deadline := time.After(5 * time.Second)
+	for len(a.arrived) < a.n {
+		select {
+		case <-deadline:
+			return ActionResult{Error: fmt.Errorf("%s ran alone", ac.Step)}
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return ActionResult{Success: true, Data: map[string]any{ac.Step + "_ran": true}}
+}
+
+func parallelTestConfig() *Config {
+	return &Config{
+		Start: "checks",
+		States: map[string]*State{
+			"checks":    {Type: StateTypeParallel, Branches: []string{"lint", "test", "scan"}, Next: "gather"},
+			"lint":      {Type: StateTypeTask, Action: "test.barrier", Next: "gather"},
+			"test":      {Type: StateTypeTask, Action: "test.barrier", Next: "gather"},
+			"scan":      {Type: StateTypeTask, Action: "test.barrier", Next: "scan_done"},
+			"scan_done": {Type: StateTypePass, Data: map[string]any{"scanned": true}, Next: "gather"},
+			"gather":    {Type: StateTypeJoin, Next: "done", Error: "failed"},
+			"done":      {Type: StateTypeSucceed},
+			"failed":    {Type: StateTypeFail},
+		},
+	}
+}
+
+func TestEngine_ProcessStep_ParallelRunsBranchesConcurrently(t *testing.T) {
+	registry := NewActionRegistry()
+	registry.Register("test.barrier", &barrierAction{arrived: make(chan struct{}, 3), n: 3})
+	engine := NewEngine(parallelTestConfig(), registry, nil, testutil.DiscardLogger())
+
+	result, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "checks", Phase: "idle"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "gather" {
+		t.Errorf("expected to move to join state 'gather', got %q", result.NewStep)
+	}
+	for _, key := range []string{"lint_ran", "test_ran", "scan_ran", "scanned"} {
+		if result.Data[key] != true {
+			t.Errorf("expected branch data %q to be merged, got %v", key, result.Data)
+		}
+	}
+	results := result.Data[ParallelResultsKey].(map[string]any)
+	for _, branch := range []string{"lint", "test", "scan"} {
+		if r := results[branch].(map[string]any); r["status"] != "succeeded" {
+			t.Errorf("branch %s: expected succeeded, got %v", branch, r)
+		}
+	}
+
+	// The join follows next when every branch succeeded.
+	joined, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "gather", Phase: "idle", StepData: result.Data})
+	if err != nil {
+		t.Fatalf("unexpected join error: %v", err)
+	}
+	if joined.NewStep != "done" {
+		t.Errorf("expected join to move to 'done', got %q", joined.NewStep)
+	}
+}
+
+func TestEngine_ProcessStep_ParallelBranchFailure(t *testing.T) {
+	cfg := parallelTestConfig()
+	cfg.States["test"].Action = "test.fail"
+
+	registry := NewActionRegistry()
+	registry.Register("test.barrier", &mockAction{result: ActionResult{Success: true}})
+	registry.Register("test.fail", &mockAction{result: ActionResult{Error: fmt.Errorf("3 tests failed")}})
+	engine := NewEngine(cfg, registry, nil, testutil.DiscardLogger())
+
+	result, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "checks", Phase: "idle"})
+	if err != nil {
+		t.Fatalf("a failed branch should not fail the parallel state: %v", err)
+	}
+	results := result.Data[ParallelResultsKey].(map[string]any)
+	if r := results["test"].(map[string]any); r["status"] != "failed" || r["error"] != "test: 3 tests failed" {
+		t.Errorf("expected failed test branch, got %v", r)
+	}
+	if r := results["lint"].(map[string]any); r["status"] != "succeeded" {
+		t.Errorf("expected other branches to finish, got %v", r)
+	}
+
+	joined, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "gather", Phase: "idle", StepData: result.Data})
+	if err != nil {
+		t.Fatalf("unexpected join error: %v", err)
+	}
+	if joined.NewStep != "failed" {
+		t.Errorf("expected join to follow error edge, got %q", joined.NewStep)
+	}
+	if msg, _ := joined.Data["_last_error"].(string); msg != "parallel branches failed: test (test: 3 tests failed)" {
+		t.Errorf("unexpected join error message: %q", msg)
+	}
+
+	// Without an error edge the join fails the workflow.
+	cfg.States["gather"].Error = ""
+	if _, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "gather", Phase: "idle", StepData: result.Data}); err == nil {
+		t.Error("expected error from join with failed branches and no error edge")
+	}
+}
+
+func TestEngine_ProcessStep_ParallelAsyncBranchFails(t *testing.T) {
+	registry := NewActionRegistry()
+	registry.Register("test.barrier", &mockAction{result: ActionResult{Success: true, Async: true}})
+	engine := NewEngine(parallelTestConfig(), registry, nil, testutil.DiscardLogger())
+
+	result, err := engine.ProcessStep(context.Background(), &WorkItemView{CurrentStep: "checks", Phase: "idle"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := result.Data[ParallelResultsKey].(map[string]any)
+	if r := results["lint"].(map[string]any); r["status"] != "failed" {
+		t.Errorf("expected async branch action to fail the branch, got %v", r)
+	}
+}
@@ -0,0 +1,20 @@
+package workflow
+
+import "time"
+
+// defaultEpicSummaryInterval is the minimum gap between epic summary updates
+// when settings.epic_summary_interval is unset.
+const defaultEpicSummaryInterval = time.Hour
+
+// EpicSummariesEnabled reports whether epic progress summaries are on.
+func (c *Config) EpicSummariesEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.EpicSummaries != nil && *c.Settings.EpicSummaries
+}
+
+// EpicSummaryInterval returns the minimum gap between epic summary updates.
+func (c *Config) EpicSummaryInterval() time.Duration {
+	if c != nil && c.Settings != nil && c.Settings.EpicSummaryInterval > 0 {
+		return time.Duration(c.Settings.EpicSummaryInterval) * time.Minute
+	}
+	return defaultEpicSummaryInterval
+}
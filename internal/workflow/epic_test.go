@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_EpicSummarySettings(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.EpicSummariesEnabled() {
+		t.Error("nil config should not enable epic summaries")
+	}
+	if got := (&Config{}).EpicSummaryInterval(); got != defaultEpicSummaryInterval {
+		t.Errorf("default interval = %v, want %v", got, defaultEpicSummaryInterval)
+	}
+
+	enabled := true
+	cfg := &Config{Settings: &SettingsConfig{EpicSummaries: &enabled, EpicSummaryInterval: 15}}
+	if !cfg.EpicSummariesEnabled() {
+		t.Error("expected epic summaries enabled")
+	}
+	if got := cfg.EpicSummaryInterval(); got != 15*time.Minute {
+		t.Errorf("interval = %v, want 15m", got)
+	}
+}
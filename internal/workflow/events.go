@@ -0,0 +1,59 @@
+package workflow
+
+import "slices"
+
+// Event sink types for settings.events.
+const (
+	EventSinkJSONL   = "jsonl"
+	EventSinkWebhook = "webhook"
+	EventSinkStdout  = "stdout"
+)
+
+// ValidEventSinkTypes is the set of recognized event sink types.
+var ValidEventSinkTypes = map[string]bool{
+	EventSinkJSONL:   true,
+	EventSinkWebhook: true,
+	EventSinkStdout:  true,
+}
+
+// EventSinkConfig is a destination for the structured events the daemon
+// emits on every state transition.
+type EventSinkConfig struct {
+	Type string `yaml:"type"`
+	// Path is the file a jsonl sink appends to. Relative paths are resolved
+	// against the repo root.
+	Path string `yaml:"path,omitempty"`
+	// URL is the endpoint a webhook sink POSTs each event to.
+	URL string `yaml:"url,omitempty"`
+	// Headers are extra request headers for a webhook sink.
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// TransitionReason names the edge of from that leads to step: next, error,
+// catch, timeout, choice, default or branch. A nil from (the item is just
+// starting) gives "start"; a move that follows none of from's edges, such
+// as a confirmation being rejected without an error edge or a migration
+// remap, gives "jump".
+func TransitionReason(from *State, step string) string {
+	switch {
+	case from == nil:
+		return "start"
+	case step == "":
+		return "jump"
+	case from.Next == step:
+		return "next"
+	case slices.ContainsFunc(from.Catch, func(c CatchConfig) bool { return c.Next == step }):
+		return "catch"
+	case from.Error == step:
+		return "error"
+	case from.TimeoutNext == step:
+		return "timeout"
+	case slices.ContainsFunc(from.Choices, func(r ChoiceRule) bool { return r.Next == step }):
+		return "choice"
+	case from.Default == step:
+		return "default"
+	case slices.Contains(from.Branches, step):
+		return "branch"
+	}
+	return "jump"
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import "testing"
+
+func TestTransitionReason(t *testing.T) {
+	task := &State{
+		Type:  StateTypeTask,
+		Next:  "open_pr",
+		Error: "failed",
+		Catch: []CatchConfig{{Errors: []string{"*"}, Next: "fix"}},
+	}
+	wait := &State{Type: StateTypeWait, Next: "merge", TimeoutNext: "nudge"}
+	choice := &State{Type: StateTypeChoice, Choices: []ChoiceRule{{Variable: "x", Next: "a"}}, Default: "b"}
+	parallel := &State{Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "join"}
+
+	tests := []struct {
+		from *State
+		to   string
+		want string
+	}{
+		{nil, "coding", "start"},
+		{task, "open_pr", "next"},
+		{task, "fix", "catch"},
+		{task, "failed", "error"},
+		{task, "elsewhere", "jump"},
+		{wait, "nudge", "timeout"},
+		{choice, "a", "choice"},
+		{choice, "b", "default"},
+		{parallel, "test", "branch"},
+		{parallel, "join", "next"},
+	}
+	for _, tt := range tests {
+		if got := TransitionReason(tt.from, tt.to); got != tt.want {
+			t.Errorf("TransitionReason(%+v, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestValidateEventSinks(t *testing.T) {
+	errs := validateEventSinks([]EventSinkConfig{
+		{Type: EventSinkJSONL, Path: "/var/log/erg/events.jsonl"},
+		{Type: EventSinkWebhook, URL: "https://example.com/hook"},
+		{Type: EventSinkStdout},
+		{Type: EventSinkJSONL},
+		{Type: EventSinkWebhook, URL: "example.com"},
+		{Type: "kafka"},
+	})
+	want := []string{"settings.events[3].path", "settings.events[4].url", "settings.events[5].type"}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("error %d: expected field %s, got %s", i, field, errs[i].Field)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fingerprint identifies the configuration a PR was produced with, so changes
+// in output quality can be traced back to workflow or prompt edits.
+type Fingerprint struct {
+	Workflow string            // short hash of the effective workflow config
+	Model    string            // model used for coding; empty means the CLI default
+	Prompts  map[string]string // AI state name -> short hash of its system prompt
+}
+
+// fingerprintFooterRe matches a fingerprint footer previously added to a PR body.
+var fingerprintFooterRe = regexp.MustCompile(`\n*<!-- erg:fingerprint [^>]*-->\s*$`)
+
+// HashConfig returns a short, stable hash of a workflow config. Any change to
+// the states, settings, source, or triggers yields a different hash.
+func HashConfig(cfg *Config) string {
+	if cfg == nil {
+		return ""
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	return shortHash(string(data))
+}
+
+// HashPrompt returns a short hash of a resolved system prompt.
+func HashPrompt(prompt string) string {
+	return shortHash(prompt)
+}
+
+func shortHash(s string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))[:12]
+}
+
+// PromptList renders the prompt hashes as "state:hash" pairs sorted by state.
+func (f Fingerprint) PromptList() string {
+	pairs := make([]string, 0, len(f.Prompts))
+	for _, name := range slices.Sorted(maps.Keys(f.Prompts)) {
+		pairs = append(pairs, name+":"+f.Prompts[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Footer renders the fingerprint as a hidden HTML comment for a PR body.
+func (f Fingerprint) Footer() string {
+	model := f.Model
+	if model == "" {
+		model = "default"
+	}
+	footer := fmt.Sprintf("<!-- erg:fingerprint workflow=%s model=%s", f.Workflow, model)
+	if len(f.Prompts) > 0 {
+		footer += " prompts=" + f.PromptList()
+	}
+	return footer + " -->"
+}
+
+// WithFingerprintFooter returns body with the fingerprint footer appended,
+// replacing any footer already present.
+func WithFingerprintFooter(body string, f Fingerprint) string {
+	body = fingerprintFooterRe.ReplaceAllString(body, "")
+	if body == "" {
+		return f.Footer()
+	}
+	return body + "\n\n" + f.Footer()
+}
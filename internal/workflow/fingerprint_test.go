@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashConfig(t *testing.T) {
+	cfg := DefaultWorkflowConfig()
+	h1 := HashConfig(cfg)
+	if len(h1) != 12 {
+		t.Fatalf("HashConfig length = %d, want 12", len(h1))
+	}
+	if HashConfig(DefaultWorkflowConfig()) != h1 {
+		t.Error("expected identical configs to hash the same")
+	}
+
+	cfg.Settings = &SettingsConfig{Model: "haiku"}
+	if HashConfig(cfg) == h1 {
+		t.Error("expected settings change to change the hash")
+	}
+	if HashConfig(nil) != "" {
+		t.Error("expected empty hash for nil config")
+	}
+}
+
+func TestFingerprintFooter(t *testing.T) {
+	f := Fingerprint{
+		Workflow: "abc123def456",
+		Prompts:  map[string]string{"review": "222", "coding": "111"},
+	}
+	want := "<!-- erg:fingerprint workflow=abc123def456 model=default prompts=coding:111,review:222 -->"
+	if got := f.Footer(); got != want {
+		t.Errorf("Footer() = %q, want %q", got, want)
+	}
+
+	f.Model = "claude-opus-4"
+	f.Prompts = nil
+	want = "<!-- erg:fingerprint workflow=abc123def456 model=claude-opus-4 -->"
+	if got := f.Footer(); got != want {
+		t.Errorf("Footer() = %q, want %q", got, want)
+	}
+}
+
+func TestWithFingerprintFooter(t *testing.T) {
+	first := Fingerprint{Workflow: "aaa", Prompts: map[string]string{"coding": "111"}}
+	second := Fingerprint{Workflow: "bbb", Prompts: map[string]string{"coding": "222"}}
+
+	body := WithFingerprintFooter("## Summary\nFixes the bug.\n", first)
+	if !strings.HasPrefix(body, "## Summary\nFixes the bug.") || !strings.HasSuffix(body, first.Footer()) {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+
+	body = WithFingerprintFooter(body, second)
+	if strings.Contains(body, "workflow=aaa") {
+		t.Errorf("expected previous footer replaced:\n%s", body)
+	}
+	if strings.Count(body, "erg:fingerprint") != 1 || !strings.HasSuffix(body, second.Footer()) {
+		t.Errorf("expected exactly one footer at the end:\n%s", body)
+	}
+
+	if got := WithFingerprintFooter("", first); got != first.Footer() {
+		t.Errorf("empty body = %q, want footer only", got)
+	}
+}
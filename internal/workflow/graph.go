@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// Diagram formats accepted by Graph.
+const (
+	GraphFormatMermaid  = "mermaid"
+	GraphFormatDOT      = "dot"
+	GraphFormatPlantUML = "plantuml"
+)
+
+// graphUnsafe matches characters not accepted in diagram node IDs.
+var graphUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Graph renders the workflow as a diagram in the given format.
+func Graph(cfg *Config, format string) (string, error) {
+	switch format {
+	case GraphFormatMermaid:
+		return GenerateMermaid(cfg), nil
+	case GraphFormatDOT:
+		return GenerateDOT(cfg), nil
+	case GraphFormatPlantUML:
+		return GeneratePlantUML(cfg), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (must be mermaid, dot, or plantuml)", format)
+	}
+}
+
+// graphEdge is a labeled transition between two states. An empty To marks
+// a terminal state's exit from the workflow.
+type graphEdge struct {
+	From, To, Label string
+}
+
+// graphID returns name with characters diagram formats reject replaced.
+func graphID(name string) string {
+	return graphUnsafe.ReplaceAllString(name, "_")
+}
+
+// graphLabel returns the text shown for a state: its display name or name,
+// followed by the event it waits on or the action it runs.
+func graphLabel(name string, state *State) string {
+	label := name
+	if state.DisplayName != "" {
+		label = state.DisplayName
+	}
+	if state.Type == StateTypeWait && state.Event != "" {
+		label += " (" + state.Event + ")"
+	} else if state.Type == StateTypeTask && state.Action != "" {
+		label += " (" + state.Action + ")"
+	}
+	return label
+}
+
+// graphEdges lists every transition in the workflow, state by state in name
+// order. A parallel state links to its branches rather than its join, which
+// the branches lead into.
+func graphEdges(cfg *Config) []graphEdge {
+	var edges []graphEdge
+	add := func(from, to, label string) {
+		if to != "" {
+			edges = append(edges, graphEdge{From: from, To: to, Label: label})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		state := cfg.States[name]
+		switch state.Type {
+		case StateTypeSucceed, StateTypeFail:
+			edges = append(edges, graphEdge{From: name})
+			continue
+		case StateTypeParallel:
+			for _, branch := range state.Branches {
+				add(name, branch, "")
+			}
+			continue
+		case StateTypeChoice:
+			for _, rule := range state.Choices {
+				add(name, rule.Next, choiceLabel(rule))
+			}
+			add(name, state.Default, "default")
+			continue
+		}
+		add(name, state.Next, "")
+		add(name, state.Error, "error")
+		add(name, state.TimeoutNext, "timeout")
+		for _, c := range state.Catch {
+			add(name, c.Next, "catch")
+		}
+	}
+	return edges
+}
+
+// choiceLabel describes a choice rule's condition for an edge label.
+func choiceLabel(rule ChoiceRule) string {
+	switch {
+	case rule.IsPresent != nil && *rule.IsPresent:
+		return rule.Variable + " present"
+	case rule.IsPresent != nil:
+		return rule.Variable + " absent"
+	case rule.Equals != nil:
+		return fmt.Sprintf("%s == %v", rule.Variable, rule.Equals)
+	case rule.NotEquals != nil:
+		return fmt.Sprintf("%s != %v", rule.Variable, rule.NotEquals)
+	default:
+		return rule.Variable
+	}
+}
+
+// GraphNode is a state placed for drawing: Depth is its distance in steps
+// from the start state, and Links are its outgoing transitions.
+type GraphNode struct {
+	Name  string      `json:"name"`
+	Label string      `json:"label"`
+	Type  StateType   `json:"type"`
+	Depth int         `json:"depth"`
+	Links []GraphLink `json:"links,omitempty"`
+}
+
+// GraphLink is a transition from a GraphNode, labeled when it is taken on
+// error, timeout, or a choice.
+type GraphLink struct {
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+// GraphLayout returns the workflow's states ordered by depth and then name,
+// for renderers that lay out the diagram themselves. States the start state
+// cannot reach come last, one level past the deepest reachable state.
+func GraphLayout(cfg *Config) []GraphNode {
+	if cfg == nil || len(cfg.States) == 0 {
+		return nil
+	}
+	links := make(map[string][]GraphLink)
+	for _, e := range graphEdges(cfg) {
+		if e.To != "" {
+			links[e.From] = append(links[e.From], GraphLink{To: e.To, Label: e.Label})
+		}
+	}
+
+	depth := map[string]int{}
+	maxDepth := 0
+	if _, ok := cfg.States[cfg.Start]; ok {
+		depth[cfg.Start] = 0
+		queue := []string{cfg.Start}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, l := range links[name] {
+				if _, seen := depth[l.To]; seen {
+					continue
+				}
+				if _, ok := cfg.States[l.To]; !ok {
+					continue
+				}
+				depth[l.To] = depth[name] + 1
+				maxDepth = max(maxDepth, depth[l.To])
+				queue = append(queue, l.To)
+			}
+		}
+	}
+
+	nodes := make([]GraphNode, 0, len(cfg.States))
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		state := cfg.States[name]
+		d, ok := depth[name]
+		if !ok {
+			d = maxDepth + 1
+		}
+		nodes = append(nodes, GraphNode{Name: name, Label: graphLabel(name, state), Type: state.Type, Depth: d, Links: links[name]})
+	}
+	slices.SortStableFunc(nodes, func(a, b GraphNode) int { return a.Depth - b.Depth })
+	return nodes
+}
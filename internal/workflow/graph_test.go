@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func graphTestConfig() *Config {
+	return &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding": {Type: StateTypeTask, Action: "ai.code", Next: "checks", Error: "failed"},
+			"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "gather"},
+			"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather"},
+			"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", DisplayName: `Run "unit" tests`},
+			"gather": {Type: StateTypeJoin, Next: "route"},
+			"route": {Type: StateTypeChoice, Choices: []ChoiceRule{
+				{Variable: "ci_passed", Equals: true, Next: "done"},
+			}, Default: "failed"},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		},
+	}
+}
+
+func TestGenerateDOT(t *testing.T) {
+	got := GenerateDOT(graphTestConfig())
+
+	for _, want := range []string{
+		"digraph workflow {\n",
+		`    coding [label="coding (ai.code)"];` + "\n",
+		`    test [label="Run \"unit\" tests (exec.run)"];` + "\n",
+		`    checks [shape=box, style=filled, fillcolor=black`,
+		`    route [shape=diamond`,
+		`    done [shape=doublecircle, style="", label="done"];` + "\n",
+		`    failed [shape=doublecircle, style=filled, fillcolor="#f8d7da"`,
+		"    __start -> coding;\n",
+		`    coding -> failed [label="error"];` + "\n",
+		"    checks -> lint;\n",
+		"    checks -> test;\n",
+		"    gather -> route;\n",
+		`    route -> done [label="ci_passed == true"];` + "\n",
+		`    route -> failed [label="default"];` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in DOT:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "checks -> gather") {
+		t.Errorf("fork should not link straight to its join:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "}\n") {
+		t.Errorf("DOT should end with closing brace:\n%s", got)
+	}
+}
+
+func TestGeneratePlantUML(t *testing.T) {
+	got := GeneratePlantUML(graphTestConfig())
+
+	for _, want := range []string{
+		"@startuml\n",
+		"state checks <<fork>>\n",
+		"state gather <<join>>\n",
+		"state route <<choice>>\n",
+		"state \"coding (ai.code)\" as coding\n",
+		"state \"Run 'unit' tests (exec.run)\" as test\n",
+		"state \"failed\" as failed #f8d7da\n",
+		"[*] --> coding\n",
+		"coding --> failed : error\n",
+		"checks --> lint\n",
+		"route --> done : ci_passed == true\n",
+		"done --> [*]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in PlantUML:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "@enduml\n") {
+		t.Errorf("PlantUML should end with @enduml:\n%s", got)
+	}
+}
+
+func TestGraph_Formats(t *testing.T) {
+	cfg := graphTestConfig()
+	for format, prefix := range map[string]string{
+		GraphFormatMermaid:  "stateDiagram-v2",
+		GraphFormatDOT:      "digraph workflow",
+		GraphFormatPlantUML: "@startuml",
+	} {
+		got, err := Graph(cfg, format)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", format, err)
+			continue
+		}
+		if !strings.HasPrefix(got, prefix) {
+			t.Errorf("%s: expected prefix %q, got:\n%s", format, prefix, got)
+		}
+	}
+
+	if _, err := Graph(cfg, "svg"); err == nil {
+		t.Error("expected error for format Graph does not render")
+	}
+}
+
+func TestGraph_EmptyConfig(t *testing.T) {
+	if got := GenerateDOT(nil); got != "digraph workflow {\n    rankdir=TB;\n    node [shape=box, style=rounded, fontname=\"Helvetica\"];\n    edge [fontname=\"Helvetica\", fontsize=10];\n}\n" {
+		t.Errorf("unexpected DOT for nil config:\n%s", got)
+	}
+	if got := GeneratePlantUML(&Config{}); got != "@startuml\n@enduml\n" {
+		t.Errorf("unexpected PlantUML for empty config:\n%s", got)
+	}
+}
+
+func TestGraphLayout(t *testing.T) {
+	cfg := graphTestConfig()
+	cfg.States["orphan"] = &State{Type: StateTypeSucceed}
+
+	var got []string
+	byName := make(map[string]GraphNode)
+	for _, n := range GraphLayout(cfg) {
+		got = append(got, fmt.Sprintf("%s:%d", n.Name, n.Depth))
+		byName[n.Name] = n
+	}
+	want := "coding:0 checks:1 failed:1 lint:2 test:2 gather:3 route:4 done:5 orphan:6"
+	if strings.Join(got, " ") != want {
+		t.Errorf("layout = %s\nwant     %s", strings.Join(got, " "), want)
+	}
+	if l := byName["coding"].Links; len(l) != 2 || l[1] != (GraphLink{To: "failed", Label: "error"}) {
+		t.Errorf("coding links = %+v", l)
+	}
+	if byName["test"].Label != `Run "unit" tests (exec.run)` {
+		t.Errorf("test label = %q", byName["test"].Label)
+	}
+	if GraphLayout(nil) != nil {
+		t.Error("expected no nodes for a nil config")
+	}
+}
@@ -1,9 +1,12 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"strings"
@@ -36,6 +39,9 @@ type HookContext struct {
 	PRURL      string
 	WorkTree   string
 	Provider   string
+	// Template, when set, is the data hook commands are rendered with
+	// before they run; see RenderTemplate.
+	Template *TemplateData
 }
 
 // envVars returns the hook context as environment variable pairs.
@@ -53,60 +59,116 @@ func (hc HookContext) envVars() []string {
 	}
 }
 
+// Environ returns the filtered process environment with the hook context
+// variables appended, suitable for exec.Cmd.Env.
+func (hc HookContext) Environ() []string {
+	return append(filteredEnv(), hc.envVars()...)
+}
+
 // RunHooks executes hooks sequentially. Errors are logged but do not block the workflow.
-func RunHooks(ctx context.Context, hooks []HookConfig, hookCtx HookContext, logger *slog.Logger) {
+// It returns the step data emitted by hooks with output: json, or nil if
+// there is none.
+func RunHooks(ctx context.Context, hooks []HookConfig, hookCtx HookContext, logger *slog.Logger) map[string]any {
+	var data map[string]any
 	for _, hook := range hooks {
 		if hook.Run == "" {
 			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
-		cmd.Dir = hookCtx.RepoPath
-		cmd.Env = append(filteredEnv(), hookCtx.envVars()...)
-
-		output, err := cmd.CombinedOutput()
+		output, hookData, err := runHook(ctx, hook, hookCtx)
 		if err != nil {
 			logger.Warn("hook failed",
 				"command", hook.Run,
 				"error", err,
-				"output", string(output),
+				"output", output,
 			)
 			continue
 		}
+		data = mergeHookData(data, hookData)
 
 		logger.Debug("hook completed",
 			"command", hook.Run,
-			"output", string(output),
+			"output", output,
 		)
 	}
+	return data
 }
 
 // RunBeforeHooks executes before-hooks sequentially. Unlike RunHooks (after-hooks),
 // a failure stops execution and returns the error, blocking the workflow step.
-func RunBeforeHooks(ctx context.Context, hooks []HookConfig, hookCtx HookContext, logger *slog.Logger) error {
+// A hook with output: json whose stdout is not a JSON object counts as failed.
+// It returns the step data emitted by the hooks that completed.
+func RunBeforeHooks(ctx context.Context, hooks []HookConfig, hookCtx HookContext, logger *slog.Logger) (map[string]any, error) {
+	var data map[string]any
 	for _, hook := range hooks {
 		if hook.Run == "" {
 			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
-		cmd.Dir = hookCtx.RepoPath
-		cmd.Env = append(filteredEnv(), hookCtx.envVars()...)
-
-		output, err := cmd.CombinedOutput()
+		output, hookData, err := runHook(ctx, hook, hookCtx)
 		if err != nil {
 			logger.Error("before hook failed, blocking step",
 				"command", hook.Run,
 				"error", err,
-				"output", string(output),
+				"output", output,
 			)
-			return fmt.Errorf("before hook %q failed: %w", hook.Run, err)
+			return data, fmt.Errorf("before hook %q failed: %w", hook.Run, err)
 		}
+		data = mergeHookData(data, hookData)
 
 		logger.Debug("before hook completed",
 			"command", hook.Run,
-			"output", string(output),
+			"output", output,
 		)
 	}
-	return nil
+	return data, nil
+}
+
+// runHook renders a single hook's command, shell-quoting the values it
+// interpolates (see RenderCommand), and runs it with sh -c in the repo. It returns the hook's combined output for logging and, for
+// output: json hooks, the object its stdout decoded to.
+func runHook(ctx context.Context, hook HookConfig, hookCtx HookContext) (string, map[string]any, error) {
+	command := hook.Run
+	if hookCtx.Template != nil {
+		rendered, err := RenderCommand(command, *hookCtx.Template)
+		if err != nil {
+			return "", nil, err
+		}
+		command = rendered
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = hookCtx.RepoPath
+	cmd.Env = hookCtx.Environ()
+
+	if hook.Output != HookOutputJSON {
+		output, err := cmd.CombinedOutput()
+		return string(output), nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	output := stdout.String() + stderr.String()
+	if err != nil {
+		return output, nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &data); err != nil {
+		return output, nil, fmt.Errorf("hook output is not a JSON object: %w", err)
+	}
+	return output, data, nil
+}
+
+// mergeHookData copies src into dst, allocating dst if needed, so later
+// hooks override keys set by earlier ones.
+func mergeHookData(dst, src map[string]any) map[string]any {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
 }
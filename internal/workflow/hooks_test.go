@@ -163,7 +163,7 @@ func TestRunBeforeHooks_Success(t *testing.T) {
 	hookCtx := HookContext{RepoPath: dir, Branch: "test"}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
+	_, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -189,7 +189,7 @@ func TestRunBeforeHooks_FailureBlocks(t *testing.T) {
 	hookCtx := HookContext{RepoPath: dir}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
+	_, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
 	if err == nil {
 		t.Fatal("expected error from failing before hook")
 	}
@@ -205,7 +205,7 @@ func TestRunBeforeHooks_EmptyRun(t *testing.T) {
 	hookCtx := HookContext{RepoPath: t.TempDir()}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
+	_, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
 	if err != nil {
 		t.Fatalf("expected no error for empty run, got: %v", err)
 	}
@@ -224,7 +224,7 @@ func TestRunBeforeHooks_MultipleSuccess(t *testing.T) {
 	hookCtx := HookContext{RepoPath: dir}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
+	_, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -325,7 +325,7 @@ func TestRunBeforeHooks_SecretsNotPassedToHook(t *testing.T) {
 
 	hookCtx := HookContext{RepoPath: dir, Branch: "test"}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
-	if err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger); err != nil {
+	if _, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -378,3 +378,136 @@ func splitEnvVar(s string) []string {
 	}
 	return []string{s[:idx], s[idx+1:]}
 }
+
+func TestHookContext_Environ(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "linear_secret")
+	t.Setenv("MY_CUSTOM_VAR", "keep_me")
+
+	env := HookContext{Branch: "feature-1", IssueID: "42"}.Environ()
+
+	envMap := make(map[string]string, len(env))
+	for _, kv := range env {
+		key, val, _ := strings.Cut(kv, "=")
+		envMap[key] = val
+	}
+	if _, found := envMap["LINEAR_API_KEY"]; found {
+		t.Error("LINEAR_API_KEY should have been filtered out")
+	}
+	if envMap["MY_CUSTOM_VAR"] != "keep_me" {
+		t.Error("MY_CUSTOM_VAR should be kept")
+	}
+	if envMap["ERG_BRANCH"] != "feature-1" || envMap["ERG_ISSUE_ID"] != "42" {
+		t.Errorf("expected ERG_* vars to be set, got branch=%q issue=%q", envMap["ERG_BRANCH"], envMap["ERG_ISSUE_ID"])
+	}
+}
+
+func TestRunHooks_JSONOutput(t *testing.T) {
+	hooks := []HookConfig{
+		{Run: `echo '{"packages": ["./cmd", "./internal/daemon"], "test_cmd": "go test"}'; echo noise >&2`, Output: HookOutputJSON},
+		{Run: "echo not json"}, // plain hooks are ignored
+		{Run: `echo '{"test_cmd": "make test"}'`, Output: HookOutputJSON},
+		{Run: "echo broken", Output: HookOutputJSON}, // logged and skipped
+	}
+	hookCtx := HookContext{RepoPath: t.TempDir()}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	data := RunHooks(context.Background(), hooks, hookCtx, logger)
+	if data["test_cmd"] != "make test" {
+		t.Errorf("later hook should override test_cmd, got %v", data["test_cmd"])
+	}
+	if pkgs, ok := data["packages"].([]any); !ok || len(pkgs) != 2 {
+		t.Errorf("unexpected packages: %v", data["packages"])
+	}
+}
+
+func TestRunHooks_NoJSONOutput(t *testing.T) {
+	hooks := []HookConfig{{Run: `echo '{"a": 1}'`}}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if data := RunHooks(context.Background(), hooks, HookContext{RepoPath: t.TempDir()}, logger); data != nil {
+		t.Errorf("expected no data without output: json, got %v", data)
+	}
+}
+
+func TestRunBeforeHooks_JSONOutput(t *testing.T) {
+	hooks := []HookConfig{
+		{Run: `echo '{"affected": "./cmd"}'`, Output: HookOutputJSON},
+		{Run: "echo '[1, 2]'", Output: HookOutputJSON},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	data, err := RunBeforeHooks(context.Background(), hooks, HookContext{RepoPath: t.TempDir()}, logger)
+	if err == nil || !strings.Contains(err.Error(), "not a JSON object") {
+		t.Fatalf("expected invalid output to block the step, got %v", err)
+	}
+	if data["affected"] != "./cmd" {
+		t.Errorf("data from hooks that completed should be returned, got %v", data)
+	}
+}
+
+func TestRunHooks_Template(t *testing.T) {
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "out.txt")
+
+	hooks := []HookConfig{
+		{Run: `echo {{shellquote .Issue.Title}} {{step "plan"}} > ` + outFile},
+	}
+	data := TemplateData{
+		Issue: TemplateIssue{Title: "it's broken"},
+		Step:  map[string]any{"plan": "fix"},
+	}
+	hookCtx := HookContext{RepoPath: dir, Template: &data}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	RunHooks(context.Background(), hooks, hookCtx, logger)
+
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("hook output file not created: %v", err)
+	}
+	if string(got) != "it's broken fix\n" {
+		t.Errorf("hook output: got %q", string(got))
+	}
+}
+
+func TestRunBeforeHooks_TemplateErrorBlocks(t *testing.T) {
+	hooks := []HookConfig{{Run: "echo {{.NoSuchField}}"}}
+	hookCtx := HookContext{RepoPath: t.TempDir(), Template: &TemplateData{}}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	if _, err := RunBeforeHooks(context.Background(), hooks, hookCtx, logger); err == nil {
+		t.Error("expected a template error to fail the before hook")
+	}
+}
+
+func TestRunHooks_TemplateValuesCannotInjectCommands(t *testing.T) {
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "out.txt")
+	pwned := filepath.Join(dir, "pwned")
+
+	hooks := []HookConfig{
+		{Run: `echo {{.Issue.Title}} {{join "," .Issue.Labels}} {{step "plan"}} > ` + outFile},
+	}
+	data := TemplateData{
+		Issue: TemplateIssue{
+			Title:  "x; touch " + pwned + "; echo $(id) `id`",
+			Labels: []string{"a'b", "$(touch " + pwned + ")"},
+		},
+		Step: map[string]any{"plan": "ok && touch " + pwned},
+	}
+	hookCtx := HookContext{RepoPath: dir, Template: &data}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	RunHooks(context.Background(), hooks, hookCtx, logger)
+
+	if _, err := os.Stat(pwned); err == nil {
+		t.Fatal("issue text ran as a shell command")
+	}
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("hook output file not created: %v", err)
+	}
+	want := data.Issue.Title + " a'b,$(touch " + pwned + ") ok && touch " + pwned + "\n"
+	if string(got) != want {
+		t.Errorf("hook output: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// includeFetchTimeout bounds fetching one remote fragment.
+	includeFetchTimeout = 10 * time.Second
+	// includeCacheTTL is how long a fetched remote fragment is reused. The
+	// orchestrator reloads the workflow every tick, so without it each tick
+	// would refetch every URL.
+	includeCacheTTL = 5 * time.Minute
+	// maxIncludeSize caps the size of a remote fragment.
+	maxIncludeSize = 1 << 20
+)
+
+var includeHTTPClient = &http.Client{Timeout: includeFetchTimeout}
+
+// includeCache holds fetched remote fragments by URL.
+var includeCache = struct {
+	sync.Mutex
+	entries map[string]cachedInclude
+}{entries: make(map[string]cachedInclude)}
+
+type cachedInclude struct {
+	data      []byte
+	fetchedAt time.Time
+}
+
+// resolveIncludes merges the fragments listed in cfg.Include into cfg.
+// Fragments are workflow files of their own and may include further
+// fragments. They are merged in order, so a later fragment overrides an
+// earlier one, and cfg overrides them all, with Merge's rules: a state
+// replaces the state of the same name, and a top-level block replaces the
+// included one.
+//
+// Local includes are paths relative to dir, the directory of the including
+// file. http(s) URLs are fetched; includes inside a remote fragment are
+// resolved against its URL, so a remote fragment can never read local
+// files.
+func resolveIncludes(cfg *Config, dir string) (*Config, error) {
+	return resolveIncludesFrom(cfg, dir, nil)
+}
+
+// resolveIncludesFrom is the recursive implementation of resolveIncludes.
+// base is the including file's directory or URL; chain is the list of
+// fragments currently being resolved, for cycle detection.
+func resolveIncludesFrom(cfg *Config, base string, chain []string) (*Config, error) {
+	if len(cfg.Include) == 0 {
+		return cfg, nil
+	}
+
+	var included *Config
+	for _, ref := range cfg.Include {
+		key, err := includeLocation(ref, base)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(chain, key) {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(chain, " -> "), key)
+		}
+
+		data, err := readInclude(key)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+		var frag Config
+		if err := yaml.Unmarshal(data, &frag); err != nil {
+			return nil, fmt.Errorf("include %q: failed to parse: %w", ref, err)
+		}
+
+		fragBase := filepath.Dir(key)
+		if isRemoteInclude(key) {
+			fragBase = key
+		}
+		resolved, err := resolveIncludesFrom(&frag, fragBase, append(slices.Clip(chain), key))
+		if err != nil {
+			return nil, err
+		}
+		if included == nil {
+			included = resolved
+		} else {
+			included = Merge(resolved, included)
+		}
+	}
+
+	return Merge(cfg, included), nil
+}
+
+// includeLocation returns the absolute path or URL an include reference
+// names, relative to base.
+func includeLocation(ref, base string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return "", fmt.Errorf("include entries must not be empty")
+	}
+	if isRemoteInclude(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", fmt.Errorf("invalid include URL %q: %w", base, err)
+		}
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return "", fmt.Errorf("invalid include %q: %w", ref, err)
+		}
+		loc := baseURL.ResolveReference(refURL)
+		if loc.Scheme != "http" && loc.Scheme != "https" {
+			return "", fmt.Errorf("include %q: remote fragments can only include http(s) URLs", ref)
+		}
+		return loc.String(), nil
+	}
+	if isRemoteInclude(ref) {
+		return ref, nil
+	}
+	if strings.Contains(ref, "://") {
+		return "", fmt.Errorf("include %q: only http and https URLs are supported", ref)
+	}
+	if filepath.IsAbs(ref) {
+		return "", fmt.Errorf("include %q: absolute paths are not allowed, use a path relative to the including file", ref)
+	}
+	return filepath.Join(base, ref), nil
+}
+
+func isRemoteInclude(loc string) bool {
+	return strings.HasPrefix(loc, "https://") || strings.HasPrefix(loc, "http://")
+}
+
+// readInclude returns the contents of a fragment. Remote fragments are
+// cached for includeCacheTTL; when a refetch fails the cached copy is used.
+func readInclude(loc string) ([]byte, error) {
+	if !isRemoteInclude(loc) {
+		data, err := os.ReadFile(loc)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file %s not found", loc)
+		}
+		return data, err
+	}
+
+	includeCache.Lock()
+	cached, ok := includeCache.entries[loc]
+	includeCache.Unlock()
+	if ok && time.Since(cached.fetchedAt) < includeCacheTTL {
+		return cached.data, nil
+	}
+
+	data, err := fetchInclude(loc)
+	if err != nil {
+		if ok {
+			return cached.data, nil
+		}
+		return nil, err
+	}
+	includeCache.Lock()
+	includeCache.entries[loc] = cachedInclude{data: data, fetchedAt: time.Now()}
+	includeCache.Unlock()
+	return data, nil
+}
+
+func fetchInclude(loc string) ([]byte, error) {
+	resp, err := includeHTTPClient.Get(loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIncludeSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	if len(data) > maxIncludeSize {
+		return nil, fmt.Errorf("fragment is larger than %d bytes", maxIncludeSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,194 @@
+package workflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeIncludeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAndMerge_Include(t *testing.T) {
+	repo := t.TempDir()
+	writeIncludeFile(t, filepath.Join(repo, ".erg", "workflow.yaml"), `include:
+  - fragments/quality.yaml
+  - fragments/notify.yaml
+source:
+  provider: github
+  filter:
+    label: queued
+start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: lint
+  notify:
+    type: pass
+    next: done
+`)
+	writeIncludeFile(t, filepath.Join(repo, ".erg", "fragments", "quality.yaml"), `include:
+  - common/lint.yaml
+settings:
+  max_concurrent: 4
+states:
+  test:
+    type: task
+    action: exec.run
+    params:
+      command: make test
+    next: notify
+`)
+	writeIncludeFile(t, filepath.Join(repo, ".erg", "fragments", "common", "lint.yaml"), `states:
+  lint:
+    type: task
+    action: exec.run
+    params:
+      command: make lint
+    next: test
+  test:
+    type: task
+    action: exec.run
+    params:
+      command: go test ./...
+    next: notify
+`)
+	writeIncludeFile(t, filepath.Join(repo, ".erg", "fragments", "notify.yaml"), `states:
+  notify:
+    type: task
+    action: webhook.post
+    next: done
+`)
+
+	cfg, err := LoadAndMerge(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Start != "coding" || cfg.Source.Provider != "github" {
+		t.Errorf("top-level fields should come from workflow.yaml, got start %q provider %q", cfg.Start, cfg.Source.Provider)
+	}
+	if cfg.States["lint"] == nil || cfg.States["lint"].Params["command"] != "make lint" {
+		t.Errorf("nested include not merged: %+v", cfg.States["lint"])
+	}
+	// quality.yaml overrides the test state of the fragment it includes.
+	if got := cfg.States["test"].Params["command"]; got != "make test" {
+		t.Errorf("test command = %v, want the including fragment's", got)
+	}
+	// workflow.yaml overrides the included notify state.
+	if got := cfg.States["notify"].Type; got != StateTypePass {
+		t.Errorf("notify type = %q, want workflow.yaml's pass state", got)
+	}
+	if cfg.Settings == nil || cfg.Settings.MaxConcurrent != 4 {
+		t.Errorf("settings from fragment not merged: %+v", cfg.Settings)
+	}
+	if len(cfg.Include) != 0 {
+		t.Errorf("merged config should not list includes, got %v", cfg.Include)
+	}
+	if errs := Validate(cfg); len(errs) > 0 {
+		t.Errorf("merged config should validate, got %v", errs)
+	}
+}
+
+func TestResolveIncludes_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFile(t, filepath.Join(dir, "a.yaml"), "include: [b.yaml]\n")
+	writeIncludeFile(t, filepath.Join(dir, "b.yaml"), "include: [sub/../a.yaml]\n")
+	writeIncludeFile(t, filepath.Join(dir, "bad.yaml"), "states: [not, a, map]\n")
+
+	tests := []struct {
+		name    string
+		include []string
+		want    string
+	}{
+		{"cycle", []string{"a.yaml"}, "include cycle"},
+		{"missing", []string{"nope.yaml"}, "not found"},
+		{"absolute", []string{"/etc/erg.yaml"}, "absolute paths are not allowed"},
+		{"empty", []string{" "}, "must not be empty"},
+		{"scheme", []string{"file:///etc/passwd"}, "only http and https"},
+		{"parse", []string{"bad.yaml"}, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveIncludes(&Config{Include: tt.include}, dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolveIncludes_Remote(t *testing.T) {
+	var fetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		switch r.URL.Path {
+		case "/fragments/gate.yaml":
+			w.Write([]byte("include: [lint.yaml]\nstates:\n  gate:\n    type: pass\n    next: lint\n"))
+		case "/fragments/lint.yaml":
+			w.Write([]byte("states:\n  lint:\n    type: pass\n    next: done\n"))
+		case "/fragments/local.yaml":
+			w.Write([]byte("include: [\"file:///etc/passwd\"]\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cfg, err := resolveIncludes(&Config{Include: []string{srv.URL + "/fragments/gate.yaml"}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.States["gate"] == nil || cfg.States["lint"] == nil {
+		t.Errorf("remote fragments not merged: %v", cfg.States)
+	}
+
+	// Fetched fragments are cached.
+	before := fetches
+	if _, err := resolveIncludes(&Config{Include: []string{srv.URL + "/fragments/gate.yaml"}}, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != before {
+		t.Errorf("expected cached fragments to be reused, got %d more fetches", fetches-before)
+	}
+
+	if _, err := resolveIncludes(&Config{Include: []string{srv.URL + "/fragments/local.yaml"}}, t.TempDir()); err == nil || !strings.Contains(err.Error(), "can only include http(s) URLs") {
+		t.Errorf("remote fragment must not include local files, got %v", err)
+	}
+	if _, err := resolveIncludes(&Config{Include: []string{srv.URL + "/missing.yaml"}}, t.TempDir()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected fetch error, got %v", err)
+	}
+}
+
+func TestReadInclude_StaleCacheOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("states: {}\n"))
+	}))
+	loc := srv.URL + "/frag.yaml"
+	if _, err := readInclude(loc); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	includeCache.Lock()
+	entry := includeCache.entries[loc]
+	entry.fetchedAt = time.Now().Add(-2 * includeCacheTTL)
+	includeCache.entries[loc] = entry
+	includeCache.Unlock()
+
+	data, err := readInclude(loc)
+	if err != nil || string(data) != "states: {}\n" {
+		t.Errorf("expected stale copy when the server is down, got %q, %v", data, err)
+	}
+}
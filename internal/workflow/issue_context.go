@@ -0,0 +1,19 @@
+package workflow
+
+// defaultIssueContextMaxChars caps issue enrichment when
+// settings.issue_context_max_chars is unset.
+const defaultIssueContextMaxChars = 8000
+
+// IssueContextEnabled reports whether issue enrichment is on.
+func (c *Config) IssueContextEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.IssueContext != nil && *c.Settings.IssueContext
+}
+
+// IssueContextMaxChars returns the most characters of issue enrichment added
+// to a session prompt.
+func (c *Config) IssueContextMaxChars() int {
+	if c != nil && c.Settings != nil && c.Settings.IssueContextMaxChars > 0 {
+		return c.Settings.IssueContextMaxChars
+	}
+	return defaultIssueContextMaxChars
+}
@@ -0,0 +1,22 @@
+package workflow
+
+import "testing"
+
+func TestConfig_IssueContextSettings(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.IssueContextEnabled() {
+		t.Error("nil config should not enable issue context")
+	}
+	if got := (&Config{}).IssueContextMaxChars(); got != defaultIssueContextMaxChars {
+		t.Errorf("default max chars = %d, want %d", got, defaultIssueContextMaxChars)
+	}
+
+	enabled := true
+	cfg := &Config{Settings: &SettingsConfig{IssueContext: &enabled, IssueContextMaxChars: 2000}}
+	if !cfg.IssueContextEnabled() {
+		t.Error("expected issue context enabled")
+	}
+	if got := cfg.IssueContextMaxChars(); got != 2000 {
+		t.Errorf("max chars = %d, want 2000", got)
+	}
+}
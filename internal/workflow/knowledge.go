@@ -0,0 +1,6 @@
+package workflow
+
+// KnowledgeBaseEnabled reports whether the per-repo knowledge base is on.
+func (c *Config) KnowledgeBaseEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.KnowledgeBase != nil && *c.Settings.KnowledgeBase
+}
@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Special keys of a LimitsConfig. Any other key names a toolchain.
+const (
+	// LimitsDefault caps the sessions of toolchains without their own limit.
+	LimitsDefault = "default"
+	// APIConcurrencySuffix marks a provider's API concurrency limit, e.g.
+	// "github_api_concurrency".
+	APIConcurrencySuffix = "_api_concurrency"
+)
+
+// APIConcurrencyProviders lists the providers whose API calls can be
+// limited. Other trackers are paced by their HTTP client's rate limiter.
+var APIConcurrencyProviders = []string{"github"}
+
+// LimitsConfig caps concurrent work by the toolchain it needs and by the
+// tracker it calls, e.g. {java: 1, default: 4, github_api_concurrency: 2}.
+// Toolchain keys are detected languages (go, node, python, ruby, rust,
+// java, php, cpp, dotnet, elixir, swift, zig, terraform); a session counts against every toolchain of its repo. A
+// missing or zero limit is unlimited.
+type LimitsConfig map[string]int
+
+// Toolchain returns how many sessions needing toolchain may run at once,
+// or 0 when they are unlimited.
+func (l LimitsConfig) Toolchain(toolchain string) int {
+	if n, ok := l[toolchain]; ok {
+		return n
+	}
+	return l[LimitsDefault]
+}
+
+// HasToolchainLimits reports whether any toolchain is limited.
+func (l LimitsConfig) HasToolchainLimits() bool {
+	for key, n := range l {
+		if n > 0 && !strings.HasSuffix(key, APIConcurrencySuffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIConcurrency returns how many calls to provider's API may be in flight
+// at once, or 0 when they are unlimited.
+func (l LimitsConfig) APIConcurrency(provider string) int {
+	return l[provider+APIConcurrencySuffix]
+}
+
+// ConcurrencyLimits returns the repo's toolchain and provider limits, or
+// nil when it has none.
+func (c *Config) ConcurrencyLimits() LimitsConfig {
+	if c == nil || c.Settings == nil {
+		return nil
+	}
+	return c.Settings.Limits
+}
+
+// ValidateLimits checks that limits are not negative and that API
+// concurrency is only limited for providers that support it. field
+// prefixes the reported fields, e.g. "settings.limits".
+func ValidateLimits(field string, l LimitsConfig) []ValidationError {
+	var errs []ValidationError
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		if l[key] < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + "." + key,
+				Message: "must not be negative",
+			})
+		}
+		if provider, ok := strings.CutSuffix(key, APIConcurrencySuffix); ok && !slices.Contains(APIConcurrencyProviders, provider) {
+			errs = append(errs, ValidationError{
+				Field:   field + "." + key,
+				Message: fmt.Sprintf("API concurrency can only be limited for: %s", strings.Join(APIConcurrencyProviders, ", ")),
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLimitsConfig(t *testing.T) {
+	l := LimitsConfig{"java": 1, "default": 4, "github_api_concurrency": 2}
+
+	if got := l.Toolchain("java"); got != 1 {
+		t.Errorf("Toolchain(java) = %d, want 1", got)
+	}
+	if got := l.Toolchain("go"); got != 4 {
+		t.Errorf("Toolchain(go) = %d, want the default 4", got)
+	}
+	if got := l.APIConcurrency("github"); got != 2 {
+		t.Errorf("APIConcurrency(github) = %d, want 2", got)
+	}
+	if got := l.APIConcurrency("linear"); got != 0 {
+		t.Errorf("APIConcurrency(linear) = %d, want 0", got)
+	}
+	if !l.HasToolchainLimits() {
+		t.Error("expected toolchain limits")
+	}
+	if (LimitsConfig{"github_api_concurrency": 2}).HasToolchainLimits() {
+		t.Error("an API limit is not a toolchain limit")
+	}
+
+	var nilLimits LimitsConfig
+	if nilLimits.Toolchain("java") != 0 || nilLimits.HasToolchainLimits() {
+		t.Error("expected no limits by default")
+	}
+}
+
+func TestValidate_Limits(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Limits = LimitsConfig{"java": -1, "linear_api_concurrency": 2, "github_api_concurrency": 2}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.limits.java", "settings.limits.linear_api_concurrency"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Settings.Limits = LimitsConfig{"rust": 1, "default": 4}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid limits, got: %v", errs)
+	}
+}
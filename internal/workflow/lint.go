@@ -0,0 +1,323 @@
+package workflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownFieldPattern matches the strict decoder's report of an unknown key.
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type`)
+
+// decodeLinePattern matches the line prefix of other decoder errors.
+var decodeLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// fieldIndex pattern splits "choices[0]" into its key and index.
+var fieldIndexPattern = regexp.MustCompile(`^([^\[]+)\[(\d+)\]$`)
+
+// ConfigPath returns the workflow file LoadAndMergeWithFile reads for the
+// given arguments.
+func ConfigPath(repoPath, workflowFile string) string {
+	if workflowFile != "" {
+		return workflowFile
+	}
+	return filepath.Join(repoPath, workflowDir, workflowFileName)
+}
+
+// LintFile loads and validates a workflow file, reporting every problem with
+// the line it comes from where that can be determined. On top of Validate it
+// reports unknown keys, states that can never be reached, and states from
+// which no succeed or fail state is reachable. Each extra check runs on the
+// merged config; its errors are located the same way.
+//
+// It returns the merged config alongside the problems so callers can go on to
+// use it. Like LoadAndMergeWithFile it returns nil, nil, nil when the file
+// does not exist; a file that cannot be parsed at all is returned as an error.
+func LintFile(repoPath, workflowFile string, extra ...func(*Config) []ValidationError) (*Config, []ValidationError, error) {
+	data, err := os.ReadFile(ConfigPath(repoPath, workflowFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read workflow config: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse workflow config: %w", err)
+	}
+	lines := indexLines(&root)
+
+	errs := strictDecodeErrors(data, lines)
+
+	cfg, err := LoadAndMergeWithFile(repoPath, workflowFile)
+	if err != nil {
+		if len(errs) > 0 {
+			// Type errors from the strict pass already explain the failure.
+			return nil, locate(errs, lines), nil
+		}
+		return nil, nil, err
+	}
+
+	var declared Config
+	_ = yaml.Unmarshal(data, &declared)
+
+	errs = append(errs, Validate(cfg)...)
+	errs = append(errs, unreachableStates(cfg, declared.States)...)
+	errs = append(errs, trappedStates(cfg, declared.States)...)
+	for _, check := range extra {
+		errs = append(errs, check(cfg)...)
+	}
+	errs = append(errs, namedWorkflowErrors(repoPath, workflowFile, cfg, extra)...)
+	return cfg, locate(errs, lines), nil
+}
+
+// namedWorkflowErrors loads and validates the workflows cfg selects by
+// label, reporting each problem against the entry that names the file.
+func namedWorkflowErrors(repoPath, workflowFile string, cfg *Config, extra []func(*Config) []ValidationError) []ValidationError {
+	if len(cfg.Workflows) == 0 || len(validateWorkflows(cfg.Workflows)) > 0 {
+		return nil
+	}
+	named, err := LoadNamedWorkflows(repoPath, workflowFile, cfg)
+	if err != nil {
+		return []ValidationError{{Field: "workflows", Message: err.Error()}}
+	}
+	var errs []ValidationError
+	for i, nw := range cfg.Workflows {
+		ncfg := named[nw.Name]
+		nerrs := Validate(ncfg)
+		for _, check := range extra {
+			nerrs = append(nerrs, check(ncfg)...)
+		}
+		for _, e := range nerrs {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("workflows[%d]", i),
+				Message: fmt.Sprintf("%s: %s: %s", nw.File, e.Field, e.Message),
+			})
+		}
+	}
+	return errs
+}
+
+// strictDecodeErrors decodes data with unknown keys disallowed and converts
+// the decoder's complaints into validation errors.
+func strictDecodeErrors(data []byte, lines map[string]int) []ValidationError {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg Config
+	err := dec.Decode(&cfg)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	keyAt := make(map[int][]string) // line → key paths declared on it
+	for path, line := range lines {
+		keyAt[line] = append(keyAt[line], path)
+	}
+
+	var errs []ValidationError
+	for _, msg := range typeErr.Errors {
+		if m := unknownFieldPattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			field := m[2]
+			for _, path := range keyAt[line] {
+				if path == field || strings.HasSuffix(path, "."+field) {
+					field = path
+					break
+				}
+			}
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("unknown key %q", m[2]), Line: line})
+			continue
+		}
+		if m := decodeLinePattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			errs = append(errs, ValidationError{Message: m[2], Line: line})
+			continue
+		}
+		errs = append(errs, ValidationError{Message: msg})
+	}
+	return errs
+}
+
+// indexLines maps the dotted path of every mapping key and sequence item in
+// the document ("states.coding.next", "triggers[0].state") to its line.
+func indexLines(root *yaml.Node) map[string]int {
+	lines := make(map[string]int)
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, val := n.Content[i], n.Content[i+1]
+				p := key.Value
+				if path != "" {
+					p = path + "." + key.Value
+				}
+				lines[p] = key.Line
+				walk(val, p)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				lines[p] = c.Line
+				walk(c, p)
+			}
+		}
+	}
+	walk(root, "")
+	return lines
+}
+
+// locate fills in the line of each error from its field path, falling back to
+// the closest enclosing key that appears in the file, and sorts the errors by
+// line.
+func locate(errs []ValidationError, lines map[string]int) []ValidationError {
+	for i := range errs {
+		if errs[i].Line != 0 {
+			continue
+		}
+		for field := errs[i].Field; field != ""; field = parentField(field) {
+			if line, ok := lines[field]; ok {
+				errs[i].Line = line
+				break
+			}
+		}
+	}
+	slices.SortStableFunc(errs, func(a, b ValidationError) int { return a.Line - b.Line })
+	return errs
+}
+
+// parentField drops the last segment of a field path: an index
+// ("a.b[1]" → "a.b") or a key ("a.b" → "a").
+func parentField(field string) string {
+	last := field
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		last = field[i+1:]
+	}
+	if m := fieldIndexPattern.FindStringSubmatch(last); m != nil {
+		return strings.TrimSuffix(field, last) + m[1]
+	}
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		return field[:i]
+	}
+	return ""
+}
+
+// reachableStates returns the states reachable from the start state and from
+// every trigger's entry state.
+func reachableStates(cfg *Config) map[string]bool {
+	seen := make(map[string]bool)
+	var queue []string
+	roots := append([]string{cfg.Start}, triggerStates(cfg)...)
+	if cfg.Preflight != nil {
+		// Entered from any AI state whose container fails preflight.
+		roots = append(roots, EnvSetupFailedState)
+	}
+	if cfg.ItemBudget() != nil {
+		// Entered from any AI state whose work item reaches its spend cap.
+		roots = append(roots, BudgetExceededState)
+	}
+	for _, root := range roots {
+		if _, ok := cfg.States[root]; ok && !seen[root] {
+			seen[root] = true
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range stateOutgoing(cfg.States[cur]) {
+			if _, ok := cfg.States[next]; ok && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
+
+func triggerStates(cfg *Config) []string {
+	var names []string
+	for _, t := range cfg.Triggers {
+		names = append(names, t.State)
+	}
+	return names
+}
+
+// unreachableStates reports states declared in the file that no path from
+// the start state or a trigger leads to. States merged in from defaults and
+// template states, which expansion replaces, are not reported.
+func unreachableStates(cfg *Config, declared map[string]*State) []ValidationError {
+	if _, ok := cfg.States[cfg.Start]; !ok {
+		return nil // reported by Validate
+	}
+	reachable := reachableStates(cfg)
+	var errs []ValidationError
+	for _, name := range slices.Sorted(maps.Keys(declared)) {
+		if _, ok := cfg.States[name]; ok && !reachable[name] {
+			errs = append(errs, ValidationError{
+				Field:   "states." + name,
+				Message: "state is unreachable from the start state or any trigger",
+			})
+		}
+	}
+	return errs
+}
+
+// trappedStates reports reachable states declared in the file from which no
+// succeed or fail state can be reached, such as a loop with no way out.
+func trappedStates(cfg *Config, declared map[string]*State) []ValidationError {
+	// Walk edges backwards from the terminal states.
+	incoming := make(map[string][]string)
+	var queue []string
+	canFinish := make(map[string]bool)
+	for name, state := range cfg.States {
+		for _, next := range stateOutgoing(state) {
+			incoming[next] = append(incoming[next], name)
+		}
+		if state.Type == StateTypeSucceed || state.Type == StateTypeFail {
+			canFinish[name] = true
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, prev := range incoming[cur] {
+			if !canFinish[prev] {
+				canFinish[prev] = true
+				queue = append(queue, prev)
+			}
+		}
+	}
+
+	reachable := reachableStates(cfg)
+	var errs []ValidationError
+	for _, name := range slices.Sorted(maps.Keys(declared)) {
+		if reachable[name] && !canFinish[name] {
+			errs = append(errs, ValidationError{
+				Field:   "states." + name,
+				Message: "no path from this state reaches a succeed or fail state",
+			})
+		}
+	}
+	return errs
+}
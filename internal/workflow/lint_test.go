@@ -0,0 +1,264 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLintFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	ergDir := filepath.Join(dir, ".erg")
+	if err := os.MkdirAll(ergDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ergDir, "workflow.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func findLintError(errs []ValidationError, field string) *ValidationError {
+	for i := range errs {
+		if errs[i].Field == field {
+			return &errs[i]
+		}
+	}
+	return nil
+}
+
+func TestLintFile_NoFile(t *testing.T) {
+	cfg, errs, err := LintFile(t.TempDir(), "")
+	if err != nil || cfg != nil || errs != nil {
+		t.Errorf("expected nil results for missing file, got cfg=%v errs=%v err=%v", cfg, errs, err)
+	}
+}
+
+func TestLintFile_Template(t *testing.T) {
+	dir := writeLintFile(t, Template)
+	cfg, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("expected config")
+	}
+	for _, e := range errs {
+		t.Errorf("unexpected lint error at line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+}
+
+func TestLintFile_SyntaxError(t *testing.T) {
+	dir := writeLintFile(t, "start: a\nstates:\n  a: [unclosed\n")
+	_, _, err := LintFile(dir, "")
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	if !strings.Contains(err.Error(), "line") {
+		t.Errorf("expected line number in error, got: %v", err)
+	}
+}
+
+func TestLintFile_UnknownKey(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    nxt: done
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findLintError(errs, "states.coding.nxt")
+	if e == nil {
+		t.Fatalf("expected unknown key error, got %v", errs)
+	}
+	if e.Line != 6 {
+		t.Errorf("expected line 6, got %d", e.Line)
+	}
+	if !strings.Contains(e.Message, `unknown key "nxt"`) {
+		t.Errorf("unexpected message: %s", e.Message)
+	}
+}
+
+func TestLintFile_MissingTargetHasLine(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: nowhere
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findLintError(errs, "states.coding.next")
+	if e == nil {
+		t.Fatalf("expected missing target error, got %v", errs)
+	}
+	if e.Line != 6 {
+		t.Errorf("expected line 6, got %d", e.Line)
+	}
+}
+
+func TestLintFile_UnreachableState(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+  orphan:
+    type: pass
+    next: done
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findLintError(errs, "states.orphan")
+	if e == nil || !strings.Contains(e.Message, "unreachable") {
+		t.Fatalf("expected unreachable error, got %v", errs)
+	}
+	if e.Line != 7 {
+		t.Errorf("expected line 7, got %d", e.Line)
+	}
+	// Merged default terminal states are never reported.
+	if findLintError(errs, "states.failed") != nil {
+		t.Error("default failed state should not be reported")
+	}
+}
+
+func TestLintFile_TriggerStateIsReachable(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+triggers:
+  - schedule: "0 9 * * 1"
+    state: respond
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+  respond:
+    type: task
+    action: ai.code
+    next: done
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e := findLintError(errs, "states.respond"); e != nil {
+		t.Errorf("trigger entry state should be reachable: %s", e.Message)
+	}
+}
+
+func TestLintFile_CycleWithoutExit(t *testing.T) {
+	dir := writeLintFile(t, `start: a
+states:
+  a:
+    type: pass
+    next: b
+  b:
+    type: wait
+    event: pr.reviewed
+    next: a
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"states.a", "states.b"} {
+		e := findLintError(errs, name)
+		if e == nil || !strings.Contains(e.Message, "succeed or fail") {
+			t.Errorf("expected %s to be reported as trapped, got %v", name, errs)
+		}
+	}
+}
+
+func TestLintFile_ExtraChecks(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+`)
+	extra := func(cfg *Config) []ValidationError {
+		return []ValidationError{{Field: "states.coding.action", Message: "extra"}}
+	}
+	_, errs, err := LintFile(dir, "", extra)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findLintError(errs, "states.coding.action")
+	if e == nil || e.Line != 5 {
+		t.Fatalf("expected extra error on line 5, got %v", errs)
+	}
+}
+
+func TestLintFile_SortedByLine(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: nowhere
+    bogus: 1
+  orphan:
+    type: pass
+    next: done
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i < len(errs); i++ {
+		if errs[i].Line < errs[i-1].Line {
+			t.Fatalf("errors not sorted by line: %v", errs)
+		}
+	}
+}
+
+func TestParentField(t *testing.T) {
+	tests := map[string]string{
+		"states.a.next":          "states.a",
+		"states.a.choices[1]":    "states.a.choices",
+		"states.a.choices[1].eq": "states.a.choices[1]",
+		"start":                  "",
+	}
+	for in, want := range tests {
+		if got := parentField(in); got != want {
+			t.Errorf("parentField(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLintFile_TemplateStates(t *testing.T) {
+	dir := writeLintFile(t, `start: build
+source:
+  provider: github
+  filter:
+    label: queued
+states:
+  build:
+    type: template
+    use: .erg/templates/simple.yaml
+    exits:
+      success: done
+      failure: failed
+`)
+	writeTemplateFile(t, dir, ".erg/templates/simple.yaml", simpleTemplateYAML)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, e := range errs {
+		t.Errorf("unexpected lint error at line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+}
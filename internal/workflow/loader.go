@@ -122,6 +122,10 @@ func LoadAndMergeWithFile(repoPath, workflowFile string) (*Config, error) {
 	if cfg == nil {
 		return nil, nil
 	}
+	cfg, err = resolveIncludes(cfg, filepath.Dir(ConfigPath(repoPath, workflowFile)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workflow includes: %w", err)
+	}
 
 	// Provide minimal terminal states so users don't have to redeclare them.
 	base := &Config{
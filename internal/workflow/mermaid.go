@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GraphOverlay is live run-time information drawn on top of a workflow
+// diagram.
+type GraphOverlay struct {
+	// Counts is the number of work items currently at each state.
+	Counts map[string]int
+	// Current is a state to highlight, such as the one a particular work
+	// item is at.
+	Current string
+}
+
+// GenerateMermaid renders the workflow as a Mermaid state diagram. Parallel
+// states are drawn as forks and join states as joins, so branches that run
+// concurrently sit side by side; choice states are drawn as decision points.
+func GenerateMermaid(cfg *Config) string {
+	return GenerateMermaidOverlay(cfg, nil)
+}
+
+// GenerateMermaidOverlay renders the workflow like GenerateMermaid, adding
+// each state's work item count to its label and highlighting the states that
+// have items waiting at them and the overlay's current state. A nil overlay
+// draws the plain diagram.
+func GenerateMermaidOverlay(cfg *Config, overlay *GraphOverlay) string {
+	if overlay == nil {
+		overlay = &GraphOverlay{}
+	}
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	if cfg == nil || len(cfg.States) == 0 {
+		return b.String()
+	}
+
+	// Declarations first: Mermaid needs pseudo-state kinds before first use.
+	var failStates, occupied []string
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		state := cfg.States[name]
+		switch state.Type {
+		case StateTypeParallel:
+			fmt.Fprintf(&b, "    state %s <<fork>>\n", graphID(name))
+		case StateTypeJoin:
+			fmt.Fprintf(&b, "    state %s <<join>>\n", graphID(name))
+		case StateTypeChoice:
+			fmt.Fprintf(&b, "    state %s <<choice>>\n", graphID(name))
+		default:
+			label := graphLabel(name, state)
+			if n := overlay.Counts[name]; n > 0 {
+				label += fmt.Sprintf(" [%d]", n)
+				if name != overlay.Current {
+					occupied = append(occupied, graphID(name))
+				}
+			}
+			if label != graphID(name) {
+				fmt.Fprintf(&b, "    state \"%s\" as %s\n", strings.ReplaceAll(label, `"`, "'"), graphID(name))
+			}
+			if state.Type == StateTypeFail {
+				failStates = append(failStates, graphID(name))
+			}
+		}
+	}
+
+	if cfg.Start != "" {
+		fmt.Fprintf(&b, "    [*] --> %s\n", graphID(cfg.Start))
+	}
+	for _, e := range graphEdges(cfg) {
+		to := "[*]"
+		if e.To != "" {
+			to = graphID(e.To)
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "    %s --> %s: %s\n", graphID(e.From), to, e.Label)
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", graphID(e.From), to)
+		}
+	}
+
+	if len(failStates) > 0 {
+		b.WriteString("    classDef failure fill:#f8d7da,stroke:#c0392b\n")
+		fmt.Fprintf(&b, "    class %s failure\n", strings.Join(failStates, ","))
+	}
+	if len(occupied) > 0 {
+		b.WriteString("    classDef occupied fill:#d1ecf1,stroke:#0c5460\n")
+		fmt.Fprintf(&b, "    class %s occupied\n", strings.Join(occupied, ","))
+	}
+	if _, ok := cfg.States[overlay.Current]; ok {
+		b.WriteString("    classDef current fill:#fff3cd,stroke:#d39e00,stroke-width:3px\n")
+		fmt.Fprintf(&b, "    class %s current\n", graphID(overlay.Current))
+	}
+	return b.String()
+}
@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMermaid_ParallelForkAndJoin(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding": {Type: StateTypeTask, Action: "ai.code", Next: "checks", Error: "failed"},
+			"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "gather"},
+			"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather"},
+			"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", DisplayName: "Run \"unit\" tests"},
+			"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		},
+	}
+
+	got := GenerateMermaid(cfg)
+
+	for _, want := range []string{
+		"stateDiagram-v2\n",
+		"    state checks <<fork>>\n",
+		"    state gather <<join>>\n",
+		"    state \"coding (ai.code)\" as coding\n",
+		"    state \"Run 'unit' tests (exec.run)\" as test\n",
+		"    [*] --> coding\n",
+		"    coding --> checks\n",
+		"    coding --> failed: error\n",
+		"    checks --> lint\n",
+		"    checks --> test\n",
+		"    lint --> gather\n",
+		"    test --> gather\n",
+		"    gather --> done\n",
+		"    gather --> failed: error\n",
+		"    done --> [*]\n",
+		"    class failed failure\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in diagram:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "checks --> gather") {
+		t.Errorf("fork should not link straight to its join:\n%s", got)
+	}
+}
+
+func TestMermaid_ChoiceAndWaitEdges(t *testing.T) {
+	present := true
+	cfg := &Config{
+		Start: "await_ci",
+		States: map[string]*State{
+			"await_ci": {Type: StateTypeWait, Event: "ci.complete", Next: "route", TimeoutNext: "failed"},
+			"route": {Type: StateTypeChoice, Choices: []ChoiceRule{
+				{Variable: "ci_passed", Equals: true, Next: "done"},
+				{Variable: "conflicting", IsPresent: &present, Next: "failed"},
+			}, Default: "failed"},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		},
+	}
+
+	got := GenerateMermaid(cfg)
+
+	for _, want := range []string{
+		"    state route <<choice>>\n",
+		"    state \"await_ci (ci.complete)\" as await_ci\n",
+		"    await_ci --> failed: timeout\n",
+		"    route --> done: ci_passed == true\n",
+		"    route --> failed: conflicting present\n",
+		"    route --> failed: default\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in diagram:\n%s", want, got)
+		}
+	}
+}
+
+func TestMermaid_SanitizesStateIDs(t *testing.T) {
+	cfg := &Config{
+		Start: "smoke-test",
+		States: map[string]*State{
+			"smoke-test": {Type: StateTypePass, Next: "done"},
+			"done":       {Type: StateTypeSucceed},
+		},
+	}
+
+	got := GenerateMermaid(cfg)
+	if !strings.Contains(got, "state \"smoke-test\" as smoke_test\n") || !strings.Contains(got, "[*] --> smoke_test\n") || !strings.Contains(got, "smoke_test --> done\n") {
+		t.Errorf("expected hyphens replaced in state IDs:\n%s", got)
+	}
+}
+
+func TestMermaidOverlay_CountsAndCurrent(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding":       {Type: StateTypeTask, Action: "ai.code", Next: "await_review"},
+			"await_review": {Type: StateTypeWait, Event: "pr.reviewed", Next: "done"},
+			"done":         {Type: StateTypeSucceed},
+		},
+	}
+
+	got := GenerateMermaidOverlay(cfg, &GraphOverlay{
+		Counts:  map[string]int{"coding": 2, "await_review": 1},
+		Current: "await_review",
+	})
+
+	for _, want := range []string{
+		"    state \"coding (ai.code) [2]\" as coding\n",
+		"    state \"await_review (pr.reviewed) [1]\" as await_review\n",
+		"    class coding occupied\n",
+		"    class await_review current\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in diagram:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "await_review occupied") {
+		t.Errorf("current state should not also be marked occupied:\n%s", got)
+	}
+}
+
+func TestMermaidOverlay_CountOnBareState(t *testing.T) {
+	cfg := &Config{
+		Start: "idle",
+		States: map[string]*State{
+			"idle": {Type: StateTypePass, Next: "done"},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+
+	plain := GenerateMermaid(cfg)
+	if strings.Contains(plain, "state \"idle") {
+		t.Errorf("plain diagram should not declare a label for idle:\n%s", plain)
+	}
+
+	got := GenerateMermaidOverlay(cfg, &GraphOverlay{Counts: map[string]int{"idle": 1}, Current: "missing"})
+	if !strings.Contains(got, "    state \"idle [1]\" as idle\n") {
+		t.Errorf("expected count label on idle:\n%s", got)
+	}
+	if strings.Contains(got, "classDef current") {
+		t.Errorf("unknown current state should not be highlighted:\n%s", got)
+	}
+}
@@ -0,0 +1,62 @@
+package workflow
+
+// Migration policies decide what happens to in-flight work items when the
+// workflow config changes under them.
+const (
+	// MigrationFinishOnOld keeps items on the workflow version they started
+	// on until they finish or are moved with `erg workflow migrate`.
+	MigrationFinishOnOld = "finish-on-old"
+	// MigrationRemap moves items onto the new version, at the same step or
+	// the one a remap rule names. Items whose step no longer exists fail.
+	MigrationRemap = "remap"
+	// MigrationFail fails every item started on an earlier version.
+	MigrationFail = "fail"
+)
+
+// ValidMigrationPolicies is the set of recognized settings.migration.policy values.
+var ValidMigrationPolicies = map[string]bool{
+	MigrationFinishOnOld: true,
+	MigrationRemap:       true,
+	MigrationFail:        true,
+}
+
+// MigrationConfig controls how in-flight work items are carried over when
+// the workflow config changes.
+type MigrationConfig struct {
+	Policy string `yaml:"policy,omitempty"`
+	// Remap maps states of earlier versions to the state items at them
+	// should move to, for states that were renamed or removed.
+	Remap map[string]string `yaml:"remap,omitempty"`
+}
+
+// MigrationPolicy returns the configured migration policy, defaulting to
+// remap.
+func (c *Config) MigrationPolicy() string {
+	if c != nil && c.Settings != nil && c.Settings.Migration != nil && c.Settings.Migration.Policy != "" {
+		return c.Settings.Migration.Policy
+	}
+	return MigrationRemap
+}
+
+// MigrationRemap returns the configured state remapping rules.
+func (c *Config) MigrationRemap() map[string]string {
+	if c != nil && c.Settings != nil && c.Settings.Migration != nil {
+		return c.Settings.Migration.Remap
+	}
+	return nil
+}
+
+// RemapStep returns the state in c an item at step should move to: the
+// target of step's rule in rules if there is one, otherwise step itself. It
+// reports false when that state does not exist in c. An empty step (an item
+// that has not started) always maps to itself.
+func (c *Config) RemapStep(step string, rules map[string]string) (string, bool) {
+	if target, ok := rules[step]; ok {
+		step = target
+	}
+	if step == "" {
+		return "", true
+	}
+	_, ok := c.States[step]
+	return step, ok
+}
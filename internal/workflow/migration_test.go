@@ -0,0 +1,76 @@
+package workflow
+
+import "testing"
+
+func migrationTestConfig(m *MigrationConfig) *Config {
+	return &Config{
+		Start: "coding",
+		Source: SourceConfig{
+			Provider: "github",
+			Filter:   FilterConfig{Label: "ai-assisted"},
+		},
+		Settings: &SettingsConfig{Migration: m},
+		States: map[string]*State{
+			"coding": {Type: StateTypeTask, Action: "ai.code", Next: "done"},
+			"done":   {Type: StateTypeSucceed},
+		},
+	}
+}
+
+func TestMigrationPolicy(t *testing.T) {
+	if got := (&Config{}).MigrationPolicy(); got != MigrationRemap {
+		t.Errorf("default policy: got %q", got)
+	}
+	cfg := migrationTestConfig(&MigrationConfig{Policy: MigrationFinishOnOld})
+	if got := cfg.MigrationPolicy(); got != MigrationFinishOnOld {
+		t.Errorf("configured policy: got %q", got)
+	}
+}
+
+func TestRemapStep(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	rules := map[string]string{"implement": "coding", "review": "gone"}
+
+	tests := []struct {
+		step   string
+		want   string
+		wantOK bool
+	}{
+		{"coding", "coding", true},
+		{"implement", "coding", true},
+		{"review", "gone", false},
+		{"deploy", "deploy", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.RemapStep(tt.step, rules)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("RemapStep(%q) = %q, %v; want %q, %v", tt.step, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestValidate_Migration(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      *MigrationConfig
+		fields []string
+	}{
+		{"valid", &MigrationConfig{Policy: MigrationFinishOnOld, Remap: map[string]string{"implement": "coding"}}, nil},
+		{"unknown policy", &MigrationConfig{Policy: "rewind"}, []string{"settings.migration.policy"}},
+		{"missing remap target", &MigrationConfig{Remap: map[string]string{"implement": "code", "review": "done"}}, []string{"settings.migration.remap.implement"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(migrationTestConfig(tt.m))
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("expected %d errors, got: %v", len(tt.fields), errs)
+			}
+			for i, f := range tt.fields {
+				if errs[i].Field != f {
+					t.Errorf("error %d: expected field %q, got %q", i, f, errs[i].Field)
+				}
+			}
+		})
+	}
+}
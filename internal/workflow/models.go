@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ModelChain returns the models the named state's Claude sessions run on,
+// in the order they are tried: the state's model, or settings.model, then
+// its fallback_models, or settings.fallback_models. A leading "" is the
+// Claude CLI's default model.
+func (c *Config) ModelChain(stateName string) []string {
+	var model string
+	var fallbacks []string
+	if c.Settings != nil {
+		model, fallbacks = c.Settings.Model, c.Settings.FallbackModels
+	}
+	if s, ok := c.States[stateName]; ok && s != nil {
+		if s.Model != "" {
+			model = s.Model
+		}
+		if len(s.FallbackModels) > 0 {
+			fallbacks = s.FallbackModels
+		}
+	}
+	return append([]string{model}, fallbacks...)
+}
+
+// validateFallbackModels checks fallback models are named, and only given
+// to states on the Claude CLI, as other backends have a model of their own.
+func validateFallbackModels(cfg *Config) []ValidationError {
+	var errs []ValidationError
+	check := func(field string, models []string) {
+		for i, m := range models {
+			if m == "" {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s[%d]", field, i), Message: "fallback model must not be empty"})
+			}
+		}
+	}
+	if cfg.Settings != nil {
+		check("settings.fallback_models", cfg.Settings.FallbackModels)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		s := cfg.States[name]
+		if s == nil || len(s.FallbackModels) == 0 {
+			continue
+		}
+		field := fmt.Sprintf("states.%s.fallback_models", name)
+		check(field, s.FallbackModels)
+		if b := cfg.BackendName(name); b != "" {
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("fallback models apply to the Claude CLI, not agent backend %q", b)})
+		}
+	}
+	return errs
+}
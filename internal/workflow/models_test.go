@@ -0,0 +1,74 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_ModelChain(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+settings:
+  model: sonnet
+  fallback_models: [haiku]
+states:
+  triage:
+    type: task
+    model: haiku
+    fallback_models: []
+  address_review:
+    type: task
+    model: opus
+    fallback_models: [sonnet, haiku]
+  coding:
+    type: task
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		"triage":         {"haiku", "haiku"},
+		"address_review": {"opus", "sonnet", "haiku"},
+		"coding":         {"sonnet", "haiku"},
+		"missing":        {"sonnet", "haiku"},
+	}
+	for state, want := range tests {
+		if got := cfg.ModelChain(state); !slices.Equal(got, want) {
+			t.Errorf("ModelChain(%q) = %v, want %v", state, got, want)
+		}
+	}
+	if got := (&Config{}).ModelChain("coding"); !slices.Equal(got, []string{""}) {
+		t.Errorf("expected the CLI default alone, got %v", got)
+	}
+}
+
+func TestValidate_FallbackModels(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings = &SettingsConfig{FallbackModels: []string{"sonnet", ""}}
+	cfg.Backends = map[string]*BackendConfig{"cheap": {Provider: BackendOpenAI, Model: "gpt-4o-mini"}}
+	cfg.States["coding"].Backend = "cheap"
+	cfg.States["coding"].FallbackModels = []string{"haiku"}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.fallback_models[1]", "states.coding.fallback_models"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+}
+
+func TestExpandTemplates_SubstitutesFallbackModels(t *testing.T) {
+	s := &State{Model: "{{model}}", FallbackModels: []string{"{{fallback}}"}}
+	clone := cloneState(s)
+	applyParamSubstitution(clone, map[string]any{"model": "opus", "fallback": "sonnet"})
+	if clone.Model != "opus" || !slices.Equal(clone.FallbackModels, []string{"sonnet"}) {
+		t.Errorf("unexpected substitution: %+v", clone)
+	}
+	if s.FallbackModels[0] != "{{fallback}}" {
+		t.Error("expected the template's state left alone")
+	}
+}
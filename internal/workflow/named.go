@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultWorkflowName labels items running on the repo's main workflow file
+// in status output.
+const DefaultWorkflowName = "default"
+
+// NamedWorkflow maps issue labels to an alternative workflow file, such as a
+// lightweight workflow for docs changes or an expedited one for hotfixes.
+type NamedWorkflow struct {
+	Name string `yaml:"name"`
+	// File is the workflow file, relative to the file declaring it.
+	File string `yaml:"file"`
+	// Labels select the workflow for issues carrying any of them.
+	Labels []string `yaml:"labels"`
+}
+
+// WorkflowForLabels returns the name of the first workflow in c.Workflows
+// with one of the given issue labels, compared case-insensitively, or ""
+// when the main workflow applies.
+func (c *Config) WorkflowForLabels(labels []string) string {
+	for _, nw := range c.Workflows {
+		for _, want := range nw.Labels {
+			for _, have := range labels {
+				if strings.EqualFold(want, have) {
+					return nw.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// LoadNamedWorkflows loads the workflow files primary selects by label,
+// keyed by name. primary is the merged config loaded from workflowFile for
+// repoPath. Issues are always polled with primary's source, so each named
+// workflow takes primary's source, and primary's settings unless it has a
+// settings block of its own. Its triggers are ignored.
+func LoadNamedWorkflows(repoPath, workflowFile string, primary *Config) (map[string]*Config, error) {
+	if len(primary.Workflows) == 0 {
+		return nil, nil
+	}
+	dir := filepath.Dir(ConfigPath(repoPath, workflowFile))
+	named := make(map[string]*Config, len(primary.Workflows))
+	for _, nw := range primary.Workflows {
+		path := nw.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		cfg, err := LoadAndMergeWithFile(repoPath, path)
+		if err != nil {
+			return nil, fmt.Errorf("workflow %q: %w", nw.Name, err)
+		}
+		if cfg == nil {
+			return nil, fmt.Errorf("workflow %q: file %s not found", nw.Name, path)
+		}
+		if len(cfg.Workflows) > 0 {
+			return nil, fmt.Errorf("workflow %q: %s cannot declare workflows of its own", nw.Name, path)
+		}
+		cfg.Source = primary.Source
+		cfg.Triggers = nil // triggers are registered from the main file
+		if cfg.Settings == nil {
+			cfg.Settings = primary.Settings
+		}
+		named[nw.Name] = cfg
+	}
+	return named, nil
+}
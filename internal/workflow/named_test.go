@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowForLabels(t *testing.T) {
+	cfg := &Config{Workflows: []NamedWorkflow{
+		{Name: "hotfix", File: "hotfix.yaml", Labels: []string{"hotfix", "urgent"}},
+		{Name: "docs", File: "docs.yaml", Labels: []string{"docs"}},
+	}}
+
+	tests := []struct {
+		labels []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"queued"}, ""},
+		{[]string{"queued", "docs"}, "docs"},
+		{[]string{"URGENT"}, "hotfix"},
+		{[]string{"docs", "hotfix"}, "hotfix"}, // first listed workflow wins
+	}
+	for _, tt := range tests {
+		if got := cfg.WorkflowForLabels(tt.labels); got != tt.want {
+			t.Errorf("WorkflowForLabels(%v) = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func writeNamedFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ".erg", name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const namedWorkflowsBlock = `workflows:
+  - name: docs
+    file: docs.yaml
+    labels: [docs]
+`
+
+const namedMain = `source:
+  provider: github
+  filter:
+    label: queued
+settings:
+  max_concurrent: 5
+` + namedWorkflowsBlock
+
+func TestLoadNamedWorkflows(t *testing.T) {
+	dir := writeLintFile(t, namedMain)
+	writeNamedFile(t, dir, "docs.yaml", `source:
+  provider: linear
+triggers:
+  - schedule: "0 9 * * *"
+    state: coding
+start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+  done:
+    type: succeed
+`)
+
+	primary, err := LoadAndMerge(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := LoadNamedWorkflows(dir, "", primary)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	docs := named["docs"]
+	if docs == nil {
+		t.Fatalf("expected docs workflow, got %v", named)
+	}
+	if docs.Source.Provider != "github" || docs.Source.Filter.Label != "queued" {
+		t.Errorf("expected main source, got %+v", docs.Source)
+	}
+	if len(docs.Triggers) != 0 {
+		t.Errorf("expected triggers to be dropped, got %v", docs.Triggers)
+	}
+	if docs.Settings == nil || docs.Settings.MaxConcurrent != 5 {
+		t.Errorf("expected main settings to be inherited, got %+v", docs.Settings)
+	}
+	if _, ok := docs.States["coding"]; !ok {
+		t.Error("expected docs states")
+	}
+}
+
+func TestLoadNamedWorkflows_Errors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		dir := writeLintFile(t, namedMain)
+		primary, _ := LoadAndMerge(dir)
+		_, err := LoadNamedWorkflows(dir, "", primary)
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("nested workflows", func(t *testing.T) {
+		dir := writeLintFile(t, namedMain)
+		writeNamedFile(t, dir, "docs.yaml", namedMain)
+		primary, _ := LoadAndMerge(dir)
+		_, err := LoadNamedWorkflows(dir, "", primary)
+		if err == nil || !strings.Contains(err.Error(), "cannot declare workflows") {
+			t.Errorf("expected nested workflows error, got %v", err)
+		}
+	})
+}
+
+func TestLintFile_NamedWorkflowErrors(t *testing.T) {
+	dir := writeLintFile(t, Template+namedWorkflowsBlock)
+	writeNamedFile(t, dir, "docs.yaml", `start: coding
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: nowhere
+`)
+
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findLintError(errs, "workflows[0]")
+	if e == nil {
+		t.Fatalf("expected error from docs workflow, got %v", errs)
+	}
+	if !strings.Contains(e.Message, "docs.yaml: states.coding.next") {
+		t.Errorf("unexpected message: %s", e.Message)
+	}
+	if e.Line == 0 {
+		t.Error("expected the error to point at the workflows entry")
+	}
+}
@@ -0,0 +1,96 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Container network profiles a state may declare via its network field.
+const (
+	// NetworkFull leaves the session container on Docker's default network.
+	NetworkFull = "full"
+	// NetworkRegistries restricts egress to package registries (plus the
+	// Claude API), for verification steps that install dependencies.
+	NetworkRegistries = "registries"
+	// NetworkOffline allows no egress beyond the Claude API, for steps such
+	// as a final self-review that need nothing from the network.
+	NetworkOffline = "offline"
+)
+
+// ValidNetworkProfiles lists all accepted network profile names.
+var ValidNetworkProfiles = []string{NetworkFull, NetworkRegistries, NetworkOffline}
+
+// NetworkProfile returns the network profile in effect for the named state:
+// the state's own network field, then settings.network, then NetworkFull.
+func (c *Config) NetworkProfile(stateName string) string {
+	if s, ok := c.States[stateName]; ok && s.Network != "" {
+		return s.Network
+	}
+	if c.Settings != nil && c.Settings.Network != "" {
+		return c.Settings.Network
+	}
+	return NetworkFull
+}
+
+// ContainerNetwork returns the Docker network the session container should
+// join for the named state. Returns "" for the full profile, meaning Docker's
+// default network. Restricted profiles map to operator-provisioned networks
+// listed under settings.network_profiles.
+func (c *Config) ContainerNetwork(stateName string) string {
+	profile := c.NetworkProfile(stateName)
+	if profile == NetworkFull || c.Settings == nil {
+		return ""
+	}
+	return c.Settings.NetworkProfiles[profile]
+}
+
+// validateNetwork checks network profile names on states and settings, and
+// that every restricted profile in use maps to a Docker network.
+func validateNetwork(cfg *Config) []ValidationError {
+	var errs []ValidationError
+	used := map[string]bool{}
+
+	check := func(field, profile string) {
+		if profile == "" {
+			return
+		}
+		if !slices.Contains(ValidNetworkProfiles, profile) {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("unknown network profile %q (must be %s)", profile, strings.Join(ValidNetworkProfiles, ", ")),
+			})
+			return
+		}
+		used[profile] = true
+	}
+
+	if cfg.Settings != nil {
+		check("settings.network", cfg.Settings.Network)
+		for profile := range cfg.Settings.NetworkProfiles {
+			if profile == NetworkFull || !slices.Contains(ValidNetworkProfiles, profile) {
+				errs = append(errs, ValidationError{
+					Field:   "settings.network_profiles." + profile,
+					Message: fmt.Sprintf("network_profiles keys must be %s or %s", NetworkRegistries, NetworkOffline),
+				})
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		check(fmt.Sprintf("states.%s.network", name), cfg.States[name].Network)
+	}
+
+	for _, profile := range []string{NetworkRegistries, NetworkOffline} {
+		if !used[profile] {
+			continue
+		}
+		if cfg.Settings == nil || cfg.Settings.NetworkProfiles[profile] == "" {
+			errs = append(errs, ValidationError{
+				Field:   "settings.network_profiles." + profile,
+				Message: fmt.Sprintf("network profile %q is used but no Docker network is configured for it", profile),
+			})
+		}
+	}
+	return errs
+}
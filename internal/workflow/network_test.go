@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ContainerNetwork(t *testing.T) {
+	cfg := &Config{
+		States: map[string]*State{
+			"coding": {Type: StateTypeTask},
+			"tests":  {Type: StateTypeTask, Network: NetworkRegistries},
+			"review": {Type: StateTypeTask, Network: NetworkOffline},
+		},
+		Settings: &SettingsConfig{
+			NetworkProfiles: map[string]string{
+				NetworkRegistries: "erg-registries",
+				NetworkOffline:    "erg-offline",
+			},
+		},
+	}
+
+	tests := []struct {
+		state       string
+		wantProfile string
+		wantNetwork string
+	}{
+		{"coding", NetworkFull, ""},
+		{"tests", NetworkRegistries, "erg-registries"},
+		{"review", NetworkOffline, "erg-offline"},
+		{"missing", NetworkFull, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			if got := cfg.NetworkProfile(tt.state); got != tt.wantProfile {
+				t.Errorf("NetworkProfile = %q, want %q", got, tt.wantProfile)
+			}
+			if got := cfg.ContainerNetwork(tt.state); got != tt.wantNetwork {
+				t.Errorf("ContainerNetwork = %q, want %q", got, tt.wantNetwork)
+			}
+		})
+	}
+
+	cfg.Settings.Network = NetworkOffline
+	if got := cfg.ContainerNetwork("coding"); got != "erg-offline" {
+		t.Errorf("settings default: ContainerNetwork = %q, want erg-offline", got)
+	}
+}
+
+func TestValidateNetwork(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *Config
+		wantField string
+	}{
+		{
+			name: "valid",
+			cfg: &Config{
+				States:   map[string]*State{"a": {Network: NetworkOffline}},
+				Settings: &SettingsConfig{NetworkProfiles: map[string]string{NetworkOffline: "net"}},
+			},
+		},
+		{
+			name: "full needs no mapping",
+			cfg:  &Config{States: map[string]*State{"a": {Network: NetworkFull}}},
+		},
+		{
+			name:      "unknown state profile",
+			cfg:       &Config{States: map[string]*State{"a": {Network: "lan"}}},
+			wantField: "states.a.network",
+		},
+		{
+			name:      "unknown settings profile",
+			cfg:       &Config{Settings: &SettingsConfig{Network: "lan"}},
+			wantField: "settings.network",
+		},
+		{
+			name:      "missing mapping",
+			cfg:       &Config{States: map[string]*State{"a": {Network: NetworkRegistries}}},
+			wantField: "settings.network_profiles.registries",
+		},
+		{
+			name:      "bad mapping key",
+			cfg:       &Config{Settings: &SettingsConfig{NetworkProfiles: map[string]string{NetworkFull: "bridge"}}},
+			wantField: "settings.network_profiles.full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateNetwork(tt.cfg)
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			found := false
+			for _, e := range errs {
+				if strings.HasPrefix(e.Field, tt.wantField) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error on %s, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GeneratePlantUML renders the workflow as a PlantUML state diagram. Parallel
+// states are drawn as forks, join states as joins, and choice states as
+// decision points; fail states are shaded red.
+func GeneratePlantUML(cfg *Config) string {
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	if cfg == nil || len(cfg.States) == 0 {
+		b.WriteString("@enduml\n")
+		return b.String()
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.States)) {
+		state := cfg.States[name]
+		switch state.Type {
+		case StateTypeParallel:
+			fmt.Fprintf(&b, "state %s <<fork>>\n", graphID(name))
+		case StateTypeJoin:
+			fmt.Fprintf(&b, "state %s <<join>>\n", graphID(name))
+		case StateTypeChoice:
+			fmt.Fprintf(&b, "state %s <<choice>>\n", graphID(name))
+		default:
+			decl := fmt.Sprintf("state \"%s\" as %s", strings.ReplaceAll(graphLabel(name, state), `"`, "'"), graphID(name))
+			if state.Type == StateTypeFail {
+				decl += " #f8d7da"
+			}
+			b.WriteString(decl + "\n")
+		}
+	}
+
+	if cfg.Start != "" {
+		fmt.Fprintf(&b, "[*] --> %s\n", graphID(cfg.Start))
+	}
+	for _, e := range graphEdges(cfg) {
+		to := "[*]"
+		if e.To != "" {
+			to = graphID(e.To)
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "%s --> %s : %s\n", graphID(e.From), to, e.Label)
+		} else {
+			fmt.Fprintf(&b, "%s --> %s\n", graphID(e.From), to)
+		}
+	}
+
+	b.WriteString("@enduml\n")
+	return b.String()
+}
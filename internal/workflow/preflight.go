@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrorEnvSetupFailed is the failure reason of an AI state whose session
+// container failed its preflight checks, so the agent never started. Retry
+// and catch rules match it in their errors.
+const ErrorEnvSetupFailed = "env_setup_failed"
+
+// EnvSetupFailedState is the state a work item moves to when its session
+// container fails preflight and no retry or catch rule matches, if the
+// workflow has a state of that name. Otherwise the state's error edge is
+// taken.
+const EnvSetupFailedState = "env_setup_failed"
+
+// PreflightConfig checks each session container before the agent starts in
+// it: that git works in the worktree, that the detected languages'
+// toolchains run, that the state's network exists, and that the repo's own
+// commands succeed.
+type PreflightConfig struct {
+	// Commands are run with sh -c in the worktree, in order, e.g.
+	// "go build ./..." or "npm ci".
+	Commands []string `yaml:"commands,omitempty"`
+	// Timeout bounds all the checks together. Defaults to 10m.
+	Timeout *Duration `yaml:"timeout,omitempty"`
+}
+
+// DefaultPreflightTimeout bounds a session's preflight checks.
+const DefaultPreflightTimeout = 10 * time.Minute
+
+// TimeoutOrDefault returns how long the checks may take.
+func (p *PreflightConfig) TimeoutOrDefault() time.Duration {
+	if p.Timeout != nil && p.Timeout.Duration > 0 {
+		return p.Timeout.Duration
+	}
+	return DefaultPreflightTimeout
+}
+
+// validatePreflight checks the preflight commands are non-empty.
+func validatePreflight(cfg *Config) []ValidationError {
+	p := cfg.Preflight
+	if p == nil {
+		return nil
+	}
+	var errs []ValidationError
+	for i, cmd := range p.Commands {
+		if strings.TrimSpace(cmd) == "" {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("preflight.commands[%d]", i), Message: "must not be empty"})
+		}
+	}
+	if p.Timeout != nil && p.Timeout.Duration < 0 {
+		errs = append(errs, ValidationError{Field: "preflight.timeout", Message: "must be positive"})
+	}
+	return errs
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zhubert/erg/internal/testutil"
+)
+
+func TestPreflightConfig_YAML(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+preflight:
+  commands:
+    - go build ./...
+    - npm ci
+  timeout: 5m
+`), &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Preflight == nil || !slices.Equal(cfg.Preflight.Commands, []string{"go build ./...", "npm ci"}) {
+		t.Fatalf("unexpected preflight %+v", cfg.Preflight)
+	}
+	if got := cfg.Preflight.TimeoutOrDefault(); got != 5*time.Minute {
+		t.Errorf("TimeoutOrDefault() = %v, want 5m", got)
+	}
+	if got := (&PreflightConfig{}).TimeoutOrDefault(); got != DefaultPreflightTimeout {
+		t.Errorf("TimeoutOrDefault() without a timeout = %v, want %v", got, DefaultPreflightTimeout)
+	}
+}
+
+func TestValidate_Preflight(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Preflight = &PreflightConfig{
+		Commands: []string{"go build ./...", " "},
+		Timeout:  &Duration{Duration: -time.Second},
+	}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"preflight.commands[1]", "preflight.timeout"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Preflight = &PreflightConfig{Commands: []string{"npm ci"}}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid preflight, got: %v", errs)
+	}
+}
+
+func TestEngine_AdvanceAfterAsyncFailure_EnvSetupFailed(t *testing.T) {
+	states := func() map[string]*State {
+		return map[string]*State{
+			"coding": {Type: StateTypeTask, Action: "ai.code", Next: "done", Error: "failed"},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		}
+	}
+	view := &WorkItemView{CurrentStep: "coding", Phase: "async_pending"}
+
+	// Without an env_setup_failed state the error edge is taken.
+	engine := NewEngine(&Config{Start: "coding", States: states()}, NewActionRegistry(), nil, testutil.DiscardLogger())
+	result, err := engine.AdvanceAfterAsyncFailure(view, ErrorEnvSetupFailed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "failed" {
+		t.Errorf("expected the error edge, got %q", result.NewStep)
+	}
+
+	withState := states()
+	withState[EnvSetupFailedState] = &State{Type: StateTypeFail}
+	engine = NewEngine(&Config{Start: "coding", States: withState}, NewActionRegistry(), nil, testutil.DiscardLogger())
+	result, err = engine.AdvanceAfterAsyncFailure(view, ErrorEnvSetupFailed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != EnvSetupFailedState || result.Data["_last_error"] != ErrorEnvSetupFailed {
+		t.Errorf("expected the env_setup_failed state, got step %q data %v", result.NewStep, result.Data)
+	}
+
+	// A catch rule still wins.
+	withState["coding"].Catch = []CatchConfig{{Errors: []string{ErrorEnvSetupFailed}, Next: "done"}}
+	result, err = engine.AdvanceAfterAsyncFailure(view, ErrorEnvSetupFailed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "done" {
+		t.Errorf("expected the catch rule, got %q", result.NewStep)
+	}
+
+	// Other failures keep taking the error edge.
+	result, err = engine.AdvanceAfterAsync(view, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "failed" {
+		t.Errorf("expected a generic failure to take the error edge, got %q", result.NewStep)
+	}
+}
+
+func TestLintFile_EnvSetupFailedStateIsReachable(t *testing.T) {
+	dir := writeLintFile(t, `start: coding
+preflight:
+  commands:
+    - go build ./...
+states:
+  coding:
+    type: task
+    action: ai.code
+    next: done
+  env_setup_failed:
+    type: fail
+  done:
+    type: succeed
+`)
+	_, errs, err := LintFile(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e := findLintError(errs, "states.env_setup_failed"); e != nil {
+		t.Errorf("env_setup_failed should be reachable with preflight: %s", e.Message)
+	}
+}
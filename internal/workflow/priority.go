@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultHotfixLabel marks issues that jump the queue when
+// settings.priority.hotfix_labels is unset.
+const DefaultHotfixLabel = "hotfix"
+
+// PriorityConfig controls how urgent issues are scheduled.
+type PriorityConfig struct {
+	// HotfixLabels mark issues that start before every other queued item,
+	// in any repo. Defaults to ["hotfix"].
+	HotfixLabels []string `yaml:"hotfix_labels,omitempty"`
+	// Preempt lets a hotfix that finds no free slot pause the lowest
+	// priority coding session to take its place. The paused session's work
+	// is committed to its branch and it resumes once a slot frees up.
+	Preempt *bool `yaml:"preempt,omitempty"`
+}
+
+// HotfixLabels returns the labels that mark an issue as a hotfix.
+func (c *Config) HotfixLabels() []string {
+	if c != nil && c.Settings != nil && c.Settings.Priority != nil && len(c.Settings.Priority.HotfixLabels) > 0 {
+		return c.Settings.Priority.HotfixLabels
+	}
+	return []string{DefaultHotfixLabel}
+}
+
+// IsHotfix reports whether an issue with these labels is a hotfix.
+// Labels match case-insensitively.
+func (c *Config) IsHotfix(labels []string) bool {
+	hotfix := c.HotfixLabels()
+	return slices.ContainsFunc(labels, func(label string) bool {
+		return slices.ContainsFunc(hotfix, func(h string) bool { return strings.EqualFold(h, label) })
+	})
+}
+
+// PreemptionEnabled reports whether hotfixes may pause running sessions.
+func (c *Config) PreemptionEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.Priority != nil &&
+		c.Settings.Priority.Preempt != nil && *c.Settings.Priority.Preempt
+}
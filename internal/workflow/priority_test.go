@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConfig_HotfixLabels(t *testing.T) {
+	var nilCfg *Config
+	if !nilCfg.IsHotfix([]string{"bug", "Hotfix"}) {
+		t.Error("expected the default label to match case-insensitively")
+	}
+	if nilCfg.PreemptionEnabled() {
+		t.Error("preemption should be off by default")
+	}
+
+	preempt := true
+	cfg := &Config{Settings: &SettingsConfig{Priority: &PriorityConfig{
+		HotfixLabels: []string{"sev1", "incident"},
+		Preempt:      &preempt,
+	}}}
+	if got := cfg.HotfixLabels(); !slices.Equal(got, []string{"sev1", "incident"}) {
+		t.Errorf("HotfixLabels = %v", got)
+	}
+	if cfg.IsHotfix([]string{"hotfix"}) {
+		t.Error("configured labels replace the default")
+	}
+	if !cfg.IsHotfix([]string{"INCIDENT"}) {
+		t.Error("expected configured label to match")
+	}
+	if !cfg.PreemptionEnabled() {
+		t.Error("expected preemption enabled")
+	}
+}
+
+func TestValidate_Priority(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Priority = &PriorityConfig{HotfixLabels: []string{"hotfix", " "}}
+
+	errs := Validate(cfg)
+	if len(errs) != 1 || errs[0].Field != "settings.priority.hotfix_labels[1]" {
+		t.Errorf("expected blank label error, got: %v", errs)
+	}
+}
@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// Progress milestones reported on the source issue when progress comments
+// are enabled via settings.progress_comments.
+const (
+	ProgressStarted  = "started"
+	ProgressPROpened = "pr_opened"
+	ProgressCIGreen  = "ci_green"
+	ProgressMerged   = "merged"
+)
+
+// defaultProgressInterval is the minimum gap between new progress comments
+// when settings.progress_interval is unset.
+const defaultProgressInterval = 10 * time.Minute
+
+// ProgressCommentsEnabled reports whether automatic progress comments are on.
+func (c *Config) ProgressCommentsEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.ProgressComments != nil && *c.Settings.ProgressComments
+}
+
+// ProgressInterval returns the minimum gap between new progress comments.
+func (c *Config) ProgressInterval() time.Duration {
+	if c != nil && c.Settings != nil && c.Settings.ProgressInterval > 0 {
+		return time.Duration(c.Settings.ProgressInterval) * time.Minute
+	}
+	return defaultProgressInterval
+}
+
+// ProgressMessage returns the one-line progress update for a milestone.
+// prURL is linked from the PR-opened message when available. Returns empty
+// string for unknown milestones.
+func ProgressMessage(milestone, prURL string) string {
+	switch milestone {
+	case ProgressStarted:
+		return "Work has started on this issue."
+	case ProgressPROpened:
+		if prURL != "" {
+			return fmt.Sprintf("Pull request opened: %s", prURL)
+		}
+		return "Pull request opened."
+	case ProgressCIGreen:
+		return "CI checks are passing."
+	case ProgressMerged:
+		return "The pull request has been merged."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressMessage(t *testing.T) {
+	tests := []struct {
+		milestone string
+		prURL     string
+		want      string
+	}{
+		{ProgressStarted, "", "Work has started on this issue."},
+		{ProgressPROpened, "https://example.com/pr/1", "Pull request opened: https://example.com/pr/1"},
+		{ProgressPROpened, "", "Pull request opened."},
+		{ProgressCIGreen, "", "CI checks are passing."},
+		{ProgressMerged, "", "The pull request has been merged."},
+		{"unknown", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ProgressMessage(tt.milestone, tt.prURL); got != tt.want {
+			t.Errorf("ProgressMessage(%q, %q) = %q, want %q", tt.milestone, tt.prURL, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_ProgressSettings(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.ProgressCommentsEnabled() {
+		t.Error("nil config should not enable progress comments")
+	}
+	if got := (&Config{}).ProgressInterval(); got != defaultProgressInterval {
+		t.Errorf("default interval = %v, want %v", got, defaultProgressInterval)
+	}
+
+	enabled := true
+	cfg := &Config{Settings: &SettingsConfig{ProgressComments: &enabled, ProgressInterval: 3}}
+	if !cfg.ProgressCommentsEnabled() {
+		t.Error("expected progress comments enabled")
+	}
+	if got := cfg.ProgressInterval(); got != 3*time.Minute {
+		t.Errorf("interval = %v, want 3m", got)
+	}
+}
@@ -0,0 +1,26 @@
+package workflow
+
+// Check types for the quality.gate action's checks param.
+const (
+	// QualityCheckCommand passes when its command exits 0 (tests, lint).
+	QualityCheckCommand = "command"
+	// QualityCheckCoverage runs its command and passes when the last
+	// percentage in the output is at least min.
+	QualityCheckCoverage = "coverage"
+	// QualityCheckDiffSize passes when the branch changes at most max lines.
+	QualityCheckDiffSize = "diff_size"
+	// QualityCheckFileCount passes when the branch changes at most max files.
+	QualityCheckFileCount = "file_count"
+)
+
+// QualityCheckTypes lists the valid check types in documentation order.
+var QualityCheckTypes = []string{QualityCheckCommand, QualityCheckCoverage, QualityCheckDiffSize, QualityCheckFileCount}
+
+// QualityGateFailed is the error a quality.gate state fails with when checks
+// ran and at least one did not pass, so catch can route it separately from
+// checks that could not run at all.
+const QualityGateFailed = "quality gate failed"
+
+// QualityGateReportKey is the step data key holding the failing checks'
+// output, which ai.address_feedback puts in front of the session by default.
+const QualityGateReportKey = "quality_gate_report"
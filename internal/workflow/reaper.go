@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Reaper actions, taken on a work item that has been at a state too long.
+const (
+	// ReaperPing comments on the issue that the work is stuck.
+	ReaperPing = "ping"
+	// ReaperRebase rebases the item's branch onto the base branch.
+	ReaperRebase = "rebase"
+	// ReaperRequeue closes the item's PR and queues the issue again from
+	// the start.
+	ReaperRequeue = "requeue"
+	// ReaperAbandon stops work on the item for good, like `erg cancel`.
+	ReaperAbandon = "abandon"
+)
+
+// ReaperActions lists the valid reaper rule actions.
+var ReaperActions = []string{ReaperPing, ReaperRebase, ReaperRequeue, ReaperAbandon}
+
+// ReaperConfig flags work items stuck at a state, e.g. waiting on review
+// for a week, and acts on them.
+type ReaperConfig struct {
+	// Rules say what to do once an item has been at a state for a while.
+	// Each rule runs once per stay at its state, in order of After, so a
+	// state can ping after a week and abandon after two.
+	Rules []ReaperRule `yaml:"rules"`
+	// SummaryWebhook is a Slack incoming webhook (supports $ENV_VAR) that
+	// receives a daily summary of stuck items. The summary is always logged.
+	SummaryWebhook string `yaml:"summary_webhook,omitempty"`
+}
+
+// ReaperRule is one reaper threshold: after After at State, take Action.
+type ReaperRule struct {
+	State  string   `yaml:"state"`
+	After  Duration `yaml:"after"`
+	Action string   `yaml:"action"`
+}
+
+// ReaperRules returns the reaper rules for state, soonest first, or nil
+// when the state has none.
+func (c *Config) ReaperRules(state string) []ReaperRule {
+	if c == nil || c.Settings == nil || c.Settings.Reaper == nil {
+		return nil
+	}
+	var rules []ReaperRule
+	for _, r := range c.Settings.Reaper.Rules {
+		if r.State == state {
+			rules = append(rules, r)
+		}
+	}
+	slices.SortStableFunc(rules, func(a, b ReaperRule) int { return cmp.Compare(a.After.Duration, b.After.Duration) })
+	return rules
+}
+
+// validateReaper checks that reaper rules name existing states, positive
+// thresholds, and known actions.
+func validateReaper(cfg *Config) []ValidationError {
+	if cfg.Settings == nil || cfg.Settings.Reaper == nil {
+		return nil
+	}
+	var errs []ValidationError
+	for i, r := range cfg.Settings.Reaper.Rules {
+		field := fmt.Sprintf("settings.reaper.rules[%d]", i)
+		if _, ok := cfg.States[r.State]; !ok {
+			errs = append(errs, ValidationError{
+				Field:   field + ".state",
+				Message: fmt.Sprintf("references non-existent state %q", r.State),
+			})
+		}
+		if r.After.Duration <= 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".after",
+				Message: "must be positive",
+			})
+		}
+		if !slices.Contains(ReaperActions, r.Action) {
+			errs = append(errs, ValidationError{
+				Field:   field + ".action",
+				Message: fmt.Sprintf("unknown reaper action %q (must be %s)", r.Action, strings.Join(ReaperActions, ", ")),
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_ReaperRules(t *testing.T) {
+	if rules := (&Config{}).ReaperRules("coding"); rules != nil {
+		t.Errorf("expected no rules without a reaper, got %v", rules)
+	}
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Reaper = &ReaperConfig{Rules: []ReaperRule{
+		{State: "coding", After: Duration{14 * 24 * time.Hour}, Action: ReaperAbandon},
+		{State: "done", After: Duration{time.Hour}, Action: ReaperPing},
+		{State: "coding", After: Duration{7 * 24 * time.Hour}, Action: ReaperPing},
+	}}
+
+	rules := cfg.ReaperRules("coding")
+	if len(rules) != 2 || rules[0].Action != ReaperPing || rules[1].Action != ReaperAbandon {
+		t.Errorf("expected coding rules soonest first, got %+v", rules)
+	}
+}
+
+func TestValidate_Reaper(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Reaper = &ReaperConfig{Rules: []ReaperRule{
+		{State: "missing", After: Duration{time.Hour}, Action: ReaperPing},
+		{State: "coding", Action: "nudge"},
+	}}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.reaper.rules[0].state", "settings.reaper.rules[1].after", "settings.reaper.rules[1].action"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Settings.Reaper.Rules = []ReaperRule{{State: "coding", After: Duration{7 * 24 * time.Hour}, Action: ReaperRequeue}}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid reaper, got: %v", errs)
+	}
+}
+
+func TestReaperConfig_YAML(t *testing.T) {
+	var s SettingsConfig
+	err := yaml.Unmarshal([]byte(`
+reaper:
+  summary_webhook: $SLACK_WEBHOOK
+  rules:
+    - state: coding
+      after: 7d
+      action: rebase
+`), &s)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	r := s.Reaper
+	if r == nil || r.SummaryWebhook != "$SLACK_WEBHOOK" || len(r.Rules) != 1 || r.Rules[0].After.Duration != 7*24*time.Hour || r.Rules[0].Action != ReaperRebase {
+		t.Errorf("unexpected reaper config: %+v", r)
+	}
+}
@@ -0,0 +1,235 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateIssue is the issue a work item came from, as templates see it.
+type TemplateIssue struct {
+	ID     string
+	Title  string
+	URL    string
+	Source string
+	Labels []string
+}
+
+// TemplateSpend is what a work item's sessions have spent so far.
+type TemplateSpend struct {
+	CostUSD      float64
+	InputTokens  int
+	OutputTokens int
+}
+
+// TemplateCommands are the repo's canonical build, test and lint commands,
+// as detected from its task runners and manifests. A command is empty when
+// none was detected.
+type TemplateCommands struct {
+	Build string
+	Test  string
+	Lint  string
+}
+
+// TemplateData is what prompt, hook command and PR body templates can
+// reference, e.g. {{.Issue.Title}}, {{.Branch}} or {{.Spend.CostUSD}}.
+// Step holds the outputs of earlier steps; {{step "key"}} formats one the
+// way {{step.key}} placeholders do and is empty when the key is not set.
+// Result is the structured result the last session submitted, e.g.
+// {{.Result.Summary}} or {{range .Result.FollowUps}}.
+type TemplateData struct {
+	Issue       TemplateIssue
+	Repo        string
+	Branch      string
+	PRURL       string
+	WorkItemID  string
+	CurrentStep string
+	Step        map[string]any
+	Result      StateResult
+	Spend       TemplateSpend
+	Commands    TemplateCommands
+}
+
+// StepData returns the step data {{step "key"}} reads from. Types that
+// embed TemplateData to add fields of their own inherit it.
+func (d TemplateData) StepData() map[string]any { return d.Step }
+
+// RenderTemplate renders text as a Go text/template with data, usually a
+// TemplateData or a type embedding one. Only a small set of pure string
+// functions is available, so templates cannot read files or the
+// environment, or run commands. Legacy {{step.key}} placeholders keep
+// working. Text without "{{" is returned unchanged.
+func RenderTemplate(text string, data any) (string, error) {
+	return render(text, data, false)
+}
+
+// RenderCommand renders a shell command template like RenderTemplate, but
+// shell-quotes what every action prints, so issue text and step data reach
+// the shell as single words and cannot inject commands. Actions that
+// already end in shellquote or raw are left alone, as are those printing
+// a .Commands field, which are commands meant to run.
+func RenderCommand(text string, data any) (string, error) {
+	return render(text, data, true)
+}
+
+func render(text string, data any, quote bool) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	var stepData map[string]any
+	if src, ok := data.(interface{ StepData() map[string]any }); ok {
+		stepData = src.StepData()
+	}
+	tmpl, err := parseTemplate(text, stepData)
+	if err != nil {
+		return "", err
+	}
+	if quote {
+		for _, t := range tmpl.Templates() {
+			quoteActions(t.Tree.Root)
+		}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("template execution failed: %w", err)
+	}
+	return b.String(), nil
+}
+
+// quoteActions pipes every printing action under node through shellquote.
+func quoteActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			quoteActions(child)
+		}
+	case *parse.ActionNode:
+		if needsQuoting(n.Pipe) {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("shellquote").SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	case *parse.RangeNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	case *parse.WithNode:
+		quoteActions(n.List)
+		quoteActions(n.ElseList)
+	}
+}
+
+// needsQuoting reports whether an action's output should be shell-quoted:
+// it prints something, does not quote or opt out itself, and is not a
+// .Commands field.
+func needsQuoting(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+		return false
+	}
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	if id, ok := last.Args[0].(*parse.IdentifierNode); ok && (id.Ident == "shellquote" || id.Ident == "raw") {
+		return false
+	}
+	if len(pipe.Cmds) == 1 && len(last.Args) == 1 {
+		if f, ok := last.Args[0].(*parse.FieldNode); ok && f.Ident[0] == "Commands" {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckTemplate reports whether text parses as a template, so mistakes
+// surface in `erg workflow validate` rather than at run time.
+func CheckTemplate(text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+	_, err := parseTemplate(text, nil)
+	return err
+}
+
+func parseTemplate(text string, stepData map[string]any) (*template.Template, error) {
+	text = stepDataPlaceholder.ReplaceAllString(text, `{{step "$1"}}`)
+	tmpl, err := template.New("template").Funcs(templateFuncs(stepData)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateFuncs is the function set available to templates.
+func templateFuncs(stepData map[string]any) template.FuncMap {
+	return template.FuncMap{
+		"step": func(key string) string { return formatStepValue(stepData[key]) },
+		"default": func(def, v any) any {
+			if v == nil || v == "" {
+				return def
+			}
+			return v
+		},
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, repl, s string) string { return strings.ReplaceAll(s, old, repl) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"split":     func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":      templateJoin,
+		"truncate":  templateTruncate,
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"shellquote": func(v any) string { return "'" + strings.ReplaceAll(printValue(v), "'", `'\''`) + "'" },
+		"raw":        printValue,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+// printValue formats v as an action printing it would, with nothing for
+// a missing value.
+func printValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// templateJoin joins a list of strings or step data values with sep.
+func templateJoin(sep string, v any) string {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, sep)
+	case []any:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = fmt.Sprint(e)
+		}
+		return strings.Join(parts, sep)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// templateTruncate shortens s to at most n runes, marking the cut with "…".
+func templateTruncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func testTemplateData() TemplateData {
+	return TemplateData{
+		Issue: TemplateIssue{
+			ID:     "42",
+			Title:  "Fix the flaky test",
+			URL:    "https://github.com/o/r/issues/42",
+			Source: "github",
+			Labels: []string{"bug", "ci"},
+		},
+		Repo:        "/repos/r",
+		Branch:      "erg/issue-42",
+		PRURL:       "https://github.com/o/r/pull/7",
+		WorkItemID:  "item-1",
+		CurrentStep: "coding",
+		Step:        map[string]any{"plan": "do it", "files": []any{"a.go", "b.go"}},
+		Spend:       TemplateSpend{CostUSD: 1.25, InputTokens: 100, OutputTokens: 50},
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text", "no placeholders here", "no placeholders here"},
+		{"issue fields", "#{{.Issue.ID}} {{.Issue.Title}} ({{.Issue.Source}})", "#42 Fix the flaky test (github)"},
+		{"branch and repo", "{{.Branch}} in {{.Repo}}", "erg/issue-42 in /repos/r"},
+		{"spend", "${{printf \"%.2f\" .Spend.CostUSD}} / {{.Spend.InputTokens}}", "$1.25 / 100"},
+		{"step func", `plan: {{step "plan"}}`, "plan: do it"},
+		{"legacy step placeholder", "plan: {{step.plan}}", "plan: do it"},
+		{"missing step key", `[{{step "nope"}}]`, "[]"},
+		{"step list", `{{join ", " .Step.files}}`, "a.go, b.go"},
+		{"labels", `{{join "," .Issue.Labels}}`, "bug,ci"},
+		{"default", `{{default "none" .PRURL}} {{default "none" .Step.nope}}`, "https://github.com/o/r/pull/7 none"},
+		{"string funcs", `{{upper .Issue.Source}} {{lower "ABC"}} {{trim "  x  "}} {{replace "flaky" "slow" .Issue.Title}}`, "GITHUB abc x Fix the slow test"},
+		{"truncate", `{{truncate 5 .Issue.Title}}`, "Fix …"},
+		{"shellquote", `echo {{shellquote "it's"}}`, `echo 'it'\''s'`},
+		{"json", `{{json .Issue.Title}}`, `"Fix the flaky test"`},
+		{"indent", `{{indent 2 "a\nb"}}`, "  a\n  b"},
+		{"conditional", `{{if contains "bug" (join " " .Issue.Labels)}}bug{{end}}`, "bug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.text, testTemplateData())
+			if err != nil {
+				t.Fatalf("RenderTemplate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate_EmbeddedData(t *testing.T) {
+	data := struct {
+		TemplateData
+		Body string
+	}{TemplateData: testTemplateData(), Body: "generated"}
+
+	got, err := RenderTemplate(`{{.Body}} for #{{.Issue.ID}} ({{step "plan"}})`, data)
+	if err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	if got != "generated for #42 (do it)" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRenderTemplate_Errors(t *testing.T) {
+	if _, err := RenderTemplate("{{.Issue.Title", testTemplateData()); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+	if _, err := RenderTemplate("{{.NoSuchField}}", testTemplateData()); err == nil || !strings.Contains(err.Error(), "template execution failed") {
+		t.Errorf("expected execution error, got %v", err)
+	}
+}
+
+func TestRenderTemplate_NoUnsafeFuncs(t *testing.T) {
+	for _, fn := range []string{"env", "readFile", "exec", "getenv"} {
+		if err := CheckTemplate("{{" + fn + ` "x"}}`); err == nil {
+			t.Errorf("expected %s to be undefined", fn)
+		}
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	if err := CheckTemplate("plain"); err != nil {
+		t.Errorf("plain text: %v", err)
+	}
+	if err := CheckTemplate("{{.Issue.Title}} {{step.plan}}"); err != nil {
+		t.Errorf("valid template: %v", err)
+	}
+	if err := CheckTemplate("{{if .Branch}}"); err == nil {
+		t.Error("expected error for unclosed if")
+	}
+}
+
+func TestRenderCommand(t *testing.T) {
+	data := TemplateData{
+		Issue:    TemplateIssue{Title: "it's $(bad)", Labels: []string{"a", "b"}},
+		Branch:   "issue-1",
+		Step:     map[string]any{"n": 3},
+		Commands: TemplateCommands{Test: "go test ./..."},
+	}
+	tests := []struct {
+		name, tmpl, want string
+	}{
+		{"field", "echo {{.Issue.Title}}", `echo 'it'\''s $(bad)'`},
+		{"explicit shellquote", "echo {{shellquote .Issue.Title}}", `echo 'it'\''s $(bad)'`},
+		{"piped", "echo {{.Issue.Title | upper}}", `echo 'IT'\''S $(BAD)'`},
+		{"raw", "echo {{raw .Branch}}", "echo issue-1"},
+		{"commands", "{{.Commands.Test}} -run X", "go test ./... -run X"},
+		{"non-string", "echo {{step \"n\"}} {{.Step.n}}", "echo '3' '3'"},
+		{"missing", "echo {{.Step.none}}", "echo ''"},
+		{"inside if and range", "{{if .Branch}}{{range .Issue.Labels}}{{.}} {{end}}{{end}}", "'a' 'b' "},
+		{"assignment", "{{$t := .Branch}}echo {{$t}}", "echo 'issue-1'"},
+		{"no template", "echo hi", "echo hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderCommand(tt.tmpl, data)
+			if err != nil {
+				t.Fatalf("RenderCommand: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Prompts and bodies are not quoted.
+	if got, _ := RenderTemplate("{{.Branch}}", data); got != "issue-1" {
+		t.Errorf("RenderTemplate quoted its output: %q", got)
+	}
+}
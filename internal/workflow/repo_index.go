@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultRepoIndexMaxChars caps retrieved code when
+// settings.repo_index_max_chars is unset.
+const defaultRepoIndexMaxChars = 6000
+
+// RepoIndexModelConfig names the embedding model a repo's code index is
+// built and searched with, served over an OpenAI-compatible embeddings
+// API. Without one the index is lexical: chunks match an issue only by the
+// words they share with it.
+type RepoIndexModelConfig struct {
+	// Model is the embedding model, e.g. "text-embedding-3-small".
+	Model string `yaml:"model"`
+	// URL is the embeddings endpoint. Defaults to OpenAI's.
+	URL string `yaml:"url,omitempty"`
+	// APIKeyEnv names the environment variable holding the API key. Its
+	// value may be an exec: reference. Unset for servers needing no key.
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+}
+
+// RepoIndexEnabled reports whether code retrieval from the repo index is on.
+func (c *Config) RepoIndexEnabled() bool {
+	return c != nil && c.Settings != nil && c.Settings.RepoIndex != nil && *c.Settings.RepoIndex
+}
+
+// RepoIndexMaxChars returns the most characters of retrieved code added to
+// a session prompt.
+func (c *Config) RepoIndexMaxChars() int {
+	if c != nil && c.Settings != nil && c.Settings.RepoIndexMaxChars > 0 {
+		return c.Settings.RepoIndexMaxChars
+	}
+	return defaultRepoIndexMaxChars
+}
+
+// RepoIndexModel returns the repo index's embedding model; its Model is
+// empty when none is configured.
+func (c *Config) RepoIndexModel() RepoIndexModelConfig {
+	if c == nil || c.Settings == nil || c.Settings.RepoIndexModel == nil {
+		return RepoIndexModelConfig{}
+	}
+	return *c.Settings.RepoIndexModel
+}
+
+// validateRepoIndexModel checks a configured embedding model is named and
+// its endpoint is an HTTP URL.
+func validateRepoIndexModel(m *RepoIndexModelConfig) []ValidationError {
+	if m == nil {
+		return nil
+	}
+	var errs []ValidationError
+	if strings.TrimSpace(m.Model) == "" {
+		errs = append(errs, ValidationError{Field: "settings.repo_index_model.model", Message: "model is required"})
+	}
+	if m.URL != "" && !strings.HasPrefix(m.URL, "http://") && !strings.HasPrefix(m.URL, "https://") {
+		errs = append(errs, ValidationError{Field: "settings.repo_index_model.url", Message: fmt.Sprintf("url %q must start with http:// or https://", m.URL)})
+	}
+	return errs
+}
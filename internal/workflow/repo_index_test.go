@@ -0,0 +1,30 @@
+package workflow
+
+import "testing"
+
+func TestConfig_RepoIndexSettings(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.RepoIndexEnabled() {
+		t.Error("nil config should not enable the repo index")
+	}
+	if got := (&Config{}).RepoIndexMaxChars(); got != defaultRepoIndexMaxChars {
+		t.Errorf("default max chars = %d, want %d", got, defaultRepoIndexMaxChars)
+	}
+
+	enabled := true
+	cfg := &Config{Settings: &SettingsConfig{RepoIndex: &enabled, RepoIndexMaxChars: 3000}}
+	if !cfg.RepoIndexEnabled() {
+		t.Error("expected the repo index enabled")
+	}
+	if got := cfg.RepoIndexMaxChars(); got != 3000 {
+		t.Errorf("max chars = %d, want 3000", got)
+	}
+	if m := cfg.RepoIndexModel(); m.Model != "" {
+		t.Errorf("expected no embedding model by default, got %+v", m)
+	}
+
+	cfg.Settings.RepoIndexModel = &RepoIndexModelConfig{Model: "nomic-embed-text", URL: "http://localhost:11434/v1/embeddings"}
+	if m := cfg.RepoIndexModel(); m.Model != "nomic-embed-text" || m.URL != "http://localhost:11434/v1/embeddings" {
+		t.Errorf("RepoIndexModel = %+v", m)
+	}
+}
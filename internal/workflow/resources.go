@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrorContainerOOM is the failure reason of an AI state whose session
+// container was killed for running out of memory. Retry and catch rules
+// match it in their errors.
+const ErrorContainerOOM = "container_oom"
+
+// ResourcesConfig limits each of the repo's session containers.
+type ResourcesConfig struct {
+	// CPUs caps the CPU a container may use, e.g. "2" or "1.5".
+	CPUs string `yaml:"cpus,omitempty"`
+	// Memory caps its memory, e.g. "4g" or "512m". Swap does not add to it.
+	Memory string `yaml:"memory,omitempty"`
+	// Pids caps how many processes it may run at once.
+	Pids int `yaml:"pids,omitempty"`
+	// OOMRetryMemory allows a session killed for running out of memory one
+	// retry with this higher memory limit. Without it, or when the retry
+	// runs out too, the state fails with container_oom.
+	OOMRetryMemory string `yaml:"oom_retry_memory,omitempty"`
+}
+
+// ContainerResources returns the repo's session container limits, or nil
+// when it sets none.
+func (c *Config) ContainerResources() *ResourcesConfig {
+	if c == nil || c.Settings == nil {
+		return nil
+	}
+	return c.Settings.Resources
+}
+
+var memoryRe = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)([bkmg]?)$`)
+
+// ParseMemory parses a memory size as container runtimes take it, such as
+// "512m" or "4g", into bytes.
+func ParseMemory(s string) (int64, error) {
+	m := memoryRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid memory size %q (want e.g. 512m or 4g)", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q: %w", s, err)
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		n *= 1 << 10
+	case "m":
+		n *= 1 << 20
+	case "g":
+		n *= 1 << 30
+	}
+	return int64(n), nil
+}
+
+// validateResources checks the container limits parse and that an OOM
+// retry raises the memory limit.
+func validateResources(cfg *Config) []ValidationError {
+	r := cfg.ContainerResources()
+	if r == nil {
+		return nil
+	}
+	var errs []ValidationError
+	if r.CPUs != "" {
+		if n, err := strconv.ParseFloat(r.CPUs, 64); err != nil || n <= 0 {
+			errs = append(errs, ValidationError{Field: "settings.resources.cpus", Message: fmt.Sprintf("invalid CPU count %q", r.CPUs)})
+		}
+	}
+	var memory int64
+	if r.Memory != "" {
+		var err error
+		if memory, err = ParseMemory(r.Memory); err != nil {
+			errs = append(errs, ValidationError{Field: "settings.resources.memory", Message: err.Error()})
+		}
+	}
+	if r.Pids < 0 {
+		errs = append(errs, ValidationError{Field: "settings.resources.pids", Message: "must not be negative"})
+	}
+	if r.OOMRetryMemory != "" {
+		retry, err := ParseMemory(r.OOMRetryMemory)
+		switch {
+		case err != nil:
+			errs = append(errs, ValidationError{Field: "settings.resources.oom_retry_memory", Message: err.Error()})
+		case r.Memory == "":
+			errs = append(errs, ValidationError{Field: "settings.resources.oom_retry_memory", Message: "raises settings.resources.memory; set it too"})
+		case memory > 0 && retry <= memory:
+			errs = append(errs, ValidationError{Field: "settings.resources.oom_retry_memory", Message: "must be higher than settings.resources.memory"})
+		}
+	}
+	return errs
+}
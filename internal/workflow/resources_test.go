@@ -0,0 +1,96 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zhubert/erg/internal/testutil"
+)
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "512m", want: 512 << 20},
+		{in: "4G", want: 4 << 30},
+		{in: "1.5g", want: 3 << 29},
+		{in: "64k", want: 64 << 10},
+		{in: "4gb", wantErr: true},
+		{in: "lots", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMemory(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMemory(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_Resources(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.Resources = &ResourcesConfig{CPUs: "0", Memory: "4x", Pids: -1, OOMRetryMemory: "8g"}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"settings.resources.cpus", "settings.resources.memory", "settings.resources.pids"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Settings.Resources = &ResourcesConfig{Memory: "8g", OOMRetryMemory: "4g"}
+	if errs := Validate(cfg); len(errs) != 1 || errs[0].Field != "settings.resources.oom_retry_memory" {
+		t.Errorf("expected a lower retry limit to be rejected, got: %v", errs)
+	}
+	cfg.Settings.Resources = &ResourcesConfig{OOMRetryMemory: "4g"}
+	if errs := Validate(cfg); len(errs) != 1 || errs[0].Field != "settings.resources.oom_retry_memory" {
+		t.Errorf("expected a retry limit without a limit to be rejected, got: %v", errs)
+	}
+
+	cfg.Settings.Resources = &ResourcesConfig{CPUs: "1.5", Memory: "4g", Pids: 512, OOMRetryMemory: "8g"}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid resources, got: %v", errs)
+	}
+}
+
+func TestEngine_AdvanceAfterAsyncFailure_ContainerOOM(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		States: map[string]*State{
+			"coding": {
+				Type: StateTypeTask, Action: "ai.code", Next: "done", Error: "failed",
+				Catch: []CatchConfig{{Errors: []string{ErrorContainerOOM}, Next: "split"}},
+			},
+			"split":  {Type: StateTypeFail},
+			"done":   {Type: StateTypeSucceed},
+			"failed": {Type: StateTypeFail},
+		},
+	}
+	engine := NewEngine(cfg, NewActionRegistry(), nil, testutil.DiscardLogger())
+	view := &WorkItemView{CurrentStep: "coding", Phase: "async_pending"}
+
+	result, err := engine.AdvanceAfterAsyncFailure(view, ErrorContainerOOM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "split" || result.Data["_caught_error"] != ErrorContainerOOM {
+		t.Errorf("expected the container_oom catch, got step %q data %v", result.NewStep, result.Data)
+	}
+
+	result, err = engine.AdvanceAfterAsync(view, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewStep != "failed" {
+		t.Errorf("expected a generic failure to take the error edge, got %q", result.NewStep)
+	}
+}
@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ResultStepDataKey is the StepData key under which the structured result
+// envelope of the most recent AI session is stored. Choice rules can branch
+// on its fields with dotted variables such as "result.status".
+const ResultStepDataKey = "result"
+
+// Result statuses an agent may report via the submit_result tool.
+const (
+	ResultStatusSuccess = "success"
+	ResultStatusPartial = "partial"
+	ResultStatusFailed  = "failed"
+	ResultStatusBlocked = "blocked"
+)
+
+// ValidResultStatuses lists all accepted result statuses.
+var ValidResultStatuses = []string{
+	ResultStatusSuccess,
+	ResultStatusPartial,
+	ResultStatusFailed,
+	ResultStatusBlocked,
+}
+
+// ValidRiskLevels lists the risk levels an agent may assess its changes at.
+var ValidRiskLevels = []string{"low", "medium", "high"}
+
+// ResultFields lists the envelope fields a state can require with its
+// result_required param. status and summary are always required.
+var ResultFields = []string{"plan", "files_changed", "risk_level", "risks", "follow_ups"}
+
+// StateResult is the machine-readable envelope a session emits at the end of
+// a workflow state. It lets transitions key off structured data instead of
+// scraping free-form transcript text.
+type StateResult struct {
+	Status       string
+	Summary      string
+	FilesChanged []string
+	FollowUps    []string
+	Confidence   float64
+
+	// Plan is the approach the session took or proposes, and RiskLevel
+	// and Risks its assessment of what the changes could break.
+	Plan      string
+	RiskLevel string
+	Risks     []string
+
+	// Learnings are durable notes about the repository for the knowledge
+	// base, each optionally prefixed with its section ("gotchas: ...").
+	Learnings []string
+}
+
+// RequiredResultFields returns the fields a state's result_required param
+// lists, or nil when it sets none.
+func RequiredResultFields(params map[string]any) []string {
+	return toStringSlice(params["result_required"])
+}
+
+// Validate checks that the result has a known status and a confidence in [0, 1].
+func (r StateResult) Validate() error {
+	if !slices.Contains(ValidResultStatuses, r.Status) {
+		return fmt.Errorf("invalid result status %q (must be one of %v)", r.Status, ValidResultStatuses)
+	}
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return fmt.Errorf("confidence %v out of range [0, 1]", r.Confidence)
+	}
+	if r.RiskLevel != "" && !slices.Contains(ValidRiskLevels, r.RiskLevel) {
+		return fmt.Errorf("invalid risk_level %q (must be one of %v)", r.RiskLevel, ValidRiskLevels)
+	}
+	for _, f := range r.FilesChanged {
+		if f == "" || path.IsAbs(f) || f == ".." || strings.HasPrefix(f, "../") {
+			return fmt.Errorf("files_changed entry %q must be a path relative to the repository root", f)
+		}
+	}
+	return nil
+}
+
+// ValidateRequired is Validate plus a check that each of required, a
+// subset of ResultFields, is filled in. A failed or blocked result need
+// not fill them: the session reports why it could not.
+func (r StateResult) ValidateRequired(required []string) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.Failed() {
+		return nil
+	}
+	var missing []string
+	for _, field := range required {
+		var empty bool
+		switch field {
+		case "plan":
+			empty = strings.TrimSpace(r.Plan) == ""
+		case "files_changed":
+			empty = len(r.FilesChanged) == 0
+		case "risk_level":
+			empty = r.RiskLevel == ""
+		case "risks":
+			empty = len(r.Risks) == 0
+		case "follow_ups":
+			empty = len(r.FollowUps) == 0
+		}
+		if empty {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required result fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Failed reports whether the session declared it could not complete the step.
+func (r StateResult) Failed() bool {
+	return r.Status == ResultStatusFailed || r.Status == ResultStatusBlocked
+}
+
+// ToStepData converts the result into the map form stored in StepData.
+func (r StateResult) ToStepData() map[string]any {
+	files := r.FilesChanged
+	if files == nil {
+		files = []string{}
+	}
+	followUps := r.FollowUps
+	if followUps == nil {
+		followUps = []string{}
+	}
+	data := map[string]any{
+		"status":        r.Status,
+		"summary":       r.Summary,
+		"files_changed": files,
+		"follow_ups":    followUps,
+		"confidence":    r.Confidence,
+	}
+	if len(r.Learnings) > 0 {
+		data["learnings"] = r.Learnings
+	}
+	if r.Plan != "" {
+		data["plan"] = r.Plan
+	}
+	if r.RiskLevel != "" {
+		data["risk_level"] = r.RiskLevel
+	}
+	if len(r.Risks) > 0 {
+		data["risks"] = r.Risks
+	}
+	return data
+}
+
+// ResultFromStepData extracts a StateResult from step data. Returns false when
+// no result envelope is present. Handles both in-memory values and values that
+// were round-tripped through JSON state persistence.
+func ResultFromStepData(data map[string]any) (StateResult, bool) {
+	raw, ok := data[ResultStepDataKey].(map[string]any)
+	if !ok {
+		return StateResult{}, false
+	}
+	var r StateResult
+	r.Status, _ = raw["status"].(string)
+	r.Summary, _ = raw["summary"].(string)
+	r.FilesChanged = toStringSlice(raw["files_changed"])
+	r.FollowUps = toStringSlice(raw["follow_ups"])
+	r.Learnings = toStringSlice(raw["learnings"])
+	r.Plan, _ = raw["plan"].(string)
+	r.RiskLevel, _ = raw["risk_level"].(string)
+	r.Risks = toStringSlice(raw["risks"])
+	r.Confidence, _ = toFloat64(raw["confidence"])
+	return r, true
+}
+
+// toStringSlice converts []string or []any (after JSON decoding) to []string.
+func toStringSlice(v any) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case []any:
+		out := make([]string, 0, len(s))
+		for _, e := range s {
+			if str, ok := e.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStateResult_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  StateResult
+		wantErr bool
+	}{
+		{name: "success", result: StateResult{Status: ResultStatusSuccess, Confidence: 0.9}},
+		{name: "blocked zero confidence", result: StateResult{Status: ResultStatusBlocked}},
+		{name: "unknown status", result: StateResult{Status: "done"}, wantErr: true},
+		{name: "empty status", result: StateResult{}, wantErr: true},
+		{name: "confidence too high", result: StateResult{Status: ResultStatusSuccess, Confidence: 1.1}, wantErr: true},
+		{name: "negative confidence", result: StateResult{Status: ResultStatusPartial, Confidence: -0.1}, wantErr: true},
+		{name: "risk assessed", result: StateResult{Status: ResultStatusSuccess, RiskLevel: "medium", Risks: []string{"touches auth"}}},
+		{name: "unknown risk level", result: StateResult{Status: ResultStatusSuccess, RiskLevel: "severe"}, wantErr: true},
+		{name: "relative files", result: StateResult{Status: ResultStatusSuccess, FilesChanged: []string{"pkg/a.go", "..b/c.go"}}},
+		{name: "absolute file", result: StateResult{Status: ResultStatusSuccess, FilesChanged: []string{"/etc/passwd"}}, wantErr: true},
+		{name: "file outside repo", result: StateResult{Status: ResultStatusSuccess, FilesChanged: []string{"../other/a.go"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.result.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStateResult_Failed(t *testing.T) {
+	for status, want := range map[string]bool{
+		ResultStatusSuccess: false,
+		ResultStatusPartial: false,
+		ResultStatusFailed:  true,
+		ResultStatusBlocked: true,
+	} {
+		if got := (StateResult{Status: status}).Failed(); got != want {
+			t.Errorf("Failed() for %q = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestResultFromStepData_RoundTrip(t *testing.T) {
+	orig := StateResult{
+		Status:       ResultStatusPartial,
+		Summary:      "half done",
+		FilesChanged: []string{"a.go", "b.go"},
+		FollowUps:    []string{"write docs"},
+		Confidence:   0.6,
+		Learnings:    []string{"gotchas: run make generate after editing protos"},
+		Plan:         "1. Split the handler.",
+		RiskLevel:    "low",
+		Risks:        []string{"changes the log format"},
+	}
+
+	// In-memory form
+	got, ok := ResultFromStepData(map[string]any{ResultStepDataKey: orig.ToStepData()})
+	if !ok || got.Status != orig.Status || len(got.FilesChanged) != 2 || got.Confidence != 0.6 {
+		t.Errorf("in-memory round trip = %+v, %v", got, ok)
+	}
+
+	// After JSON persistence, slices decode as []any
+	raw, err := json.Marshal(map[string]any{ResultStepDataKey: orig.ToStepData()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got, ok = ResultFromStepData(decoded)
+	if !ok || got.Summary != "half done" || len(got.FollowUps) != 1 || got.FilesChanged[1] != "b.go" || len(got.Learnings) != 1 ||
+		got.Plan != orig.Plan || got.RiskLevel != "low" || len(got.Risks) != 1 {
+		t.Errorf("JSON round trip = %+v, %v", got, ok)
+	}
+
+	if _, ok := ResultFromStepData(map[string]any{}); ok {
+		t.Error("expected no result for empty step data")
+	}
+}
+
+func TestStateResult_ValidateRequired(t *testing.T) {
+	required := []string{"plan", "risk_level", "files_changed"}
+	tests := []struct {
+		name    string
+		result  StateResult
+		wantErr string
+	}{
+		{name: "all present", result: StateResult{Status: ResultStatusSuccess, Plan: "p", RiskLevel: "low", FilesChanged: []string{"a.go"}}},
+		{name: "missing fields", result: StateResult{Status: ResultStatusSuccess, Plan: " "}, wantErr: "plan, risk_level, files_changed"},
+		{name: "invalid before missing", result: StateResult{Status: "done"}, wantErr: "invalid result status"},
+		{name: "failed needs no fields", result: StateResult{Status: ResultStatusBlocked, Summary: "need access"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.ValidateRequired(required)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
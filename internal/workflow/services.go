@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ServiceConfig is a sidecar service, such as a database, started for each
+// session on a network of its own so the session's tests can reach it by
+// name.
+type ServiceConfig struct {
+	// Name is the host name the session reaches the service at.
+	Name string `yaml:"name"`
+	// Image is the service's container image, e.g. postgres:16.
+	Image string `yaml:"image"`
+	// Env sets the service container's environment.
+	Env map[string]string `yaml:"env,omitempty"`
+	// Healthcheck, when set, holds the session back until the service is
+	// ready.
+	Healthcheck *HealthcheckConfig `yaml:"healthcheck,omitempty"`
+}
+
+// HealthcheckConfig is a command run in a service's container until it
+// succeeds.
+type HealthcheckConfig struct {
+	// Command is run with sh -c, e.g. "pg_isready -U postgres".
+	Command string `yaml:"command"`
+	// Interval is the pause between attempts. Defaults to 1s.
+	Interval *Duration `yaml:"interval,omitempty"`
+	// Timeout is how long to wait for success. Defaults to 60s.
+	Timeout *Duration `yaml:"timeout,omitempty"`
+}
+
+// Default healthcheck timing.
+const (
+	DefaultHealthcheckInterval = time.Second
+	DefaultHealthcheckTimeout  = 60 * time.Second
+)
+
+// IntervalOrDefault returns the pause between attempts.
+func (h *HealthcheckConfig) IntervalOrDefault() time.Duration {
+	if h.Interval != nil && h.Interval.Duration > 0 {
+		return h.Interval.Duration
+	}
+	return DefaultHealthcheckInterval
+}
+
+// TimeoutOrDefault returns how long to wait for the service.
+func (h *HealthcheckConfig) TimeoutOrDefault() time.Duration {
+	if h.Timeout != nil && h.Timeout.Duration > 0 {
+		return h.Timeout.Duration
+	}
+	return DefaultHealthcheckTimeout
+}
+
+// serviceNameRe matches a service name usable as a host name.
+var serviceNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// envKeyRe matches an environment variable name.
+var envKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateServices checks each service has a unique host name, an image,
+// and a command to its healthcheck.
+func validateServices(cfg *Config) []ValidationError {
+	var errs []ValidationError
+	seen := map[string]bool{}
+	for i, svc := range cfg.Services {
+		field := fmt.Sprintf("services[%d]", i)
+		switch {
+		case !serviceNameRe.MatchString(svc.Name):
+			errs = append(errs, ValidationError{Field: field + ".name", Message: fmt.Sprintf("invalid service name %q (want a lowercase host name such as postgres)", svc.Name)})
+		case seen[svc.Name]:
+			errs = append(errs, ValidationError{Field: field + ".name", Message: fmt.Sprintf("duplicate service name %q", svc.Name)})
+		}
+		seen[svc.Name] = true
+		if svc.Image == "" || strings.ContainsAny(svc.Image, " \t\n") {
+			errs = append(errs, ValidationError{Field: field + ".image", Message: fmt.Sprintf("invalid image %q", svc.Image)})
+		}
+		for k := range svc.Env {
+			if !envKeyRe.MatchString(k) {
+				errs = append(errs, ValidationError{Field: field + ".env", Message: fmt.Sprintf("invalid variable name %q", k)})
+			}
+		}
+		if h := svc.Healthcheck; h != nil && strings.TrimSpace(h.Command) == "" {
+			errs = append(errs, ValidationError{Field: field + ".healthcheck.command", Message: "is required"})
+		}
+	}
+	return errs
+}
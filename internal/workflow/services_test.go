@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestServiceConfig_YAML(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+services:
+  - name: postgres
+    image: postgres:16
+    env:
+      POSTGRES_PASSWORD: test
+    healthcheck:
+      command: pg_isready -U postgres
+      interval: 2s
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Services) != 1 || cfg.Services[0].Env["POSTGRES_PASSWORD"] != "test" {
+		t.Fatalf("unexpected services: %+v", cfg.Services)
+	}
+	h := cfg.Services[0].Healthcheck
+	if h.IntervalOrDefault() != 2*time.Second || h.TimeoutOrDefault() != DefaultHealthcheckTimeout {
+		t.Errorf("unexpected healthcheck timing: %s, %s", h.IntervalOrDefault(), h.TimeoutOrDefault())
+	}
+}
+
+func TestValidate_Services(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Services = []ServiceConfig{
+		{Name: "Postgres", Image: "postgres:16"},
+		{Name: "redis", Image: ""},
+		{Name: "redis", Image: "redis:7", Env: map[string]string{"BAD-KEY": "x"}},
+		{Name: "mysql", Image: "mysql:8", Healthcheck: &HealthcheckConfig{}},
+	}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"services[0].name", "services[1].image", "services[2].name", "services[2].env", "services[3].healthcheck.command"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Services = []ServiceConfig{{Name: "postgres", Image: "postgres:16", Healthcheck: &HealthcheckConfig{Command: "pg_isready"}}}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected valid services, got: %v", errs)
+	}
+}
@@ -0,0 +1,35 @@
+package workflow
+
+// Sources returns the repo's issue sources: the primary source followed by
+// each source.additional entry. Additional entries inherit the primary's
+// readiness, ordering, and deadline settings.
+func (s SourceConfig) Sources() []SourceConfig {
+	primary := s
+	primary.Additional = nil
+	sources := []SourceConfig{primary}
+	for _, extra := range s.Additional {
+		src := primary
+		src.Provider = extra.Provider
+		src.Filter = extra.Filter
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// CanonicalProvider returns the provider whose issue is kept when the same
+// work is found in more than one source: source.canonical, or the primary
+// provider when unset.
+func (s SourceConfig) CanonicalProvider() string {
+	if s.Canonical != "" {
+		return s.Canonical
+	}
+	return s.Provider
+}
+
+// ForSource returns a copy of c that polls src, one of c.Source.Sources().
+// States and settings are shared with c.
+func (c *Config) ForSource(src SourceConfig) *Config {
+	cp := *c
+	cp.Source = src
+	return &cp
+}
@@ -0,0 +1,49 @@
+package workflow
+
+import "testing"
+
+func TestSourceConfig_Sources(t *testing.T) {
+	src := SourceConfig{
+		Provider:  "github",
+		Filter:    FilterConfig{Label: "queued"},
+		Readiness: &ReadinessConfig{MinBodyLength: 20},
+		Order:     "oldest-first",
+		Additional: []AdditionalSource{
+			{Provider: "linear", Filter: FilterConfig{Label: "ai", Team: "ENG"}},
+		},
+	}
+
+	sources := src.Sources()
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(sources))
+	}
+	if sources[0].Provider != "github" || sources[0].Filter.Label != "queued" || len(sources[0].Additional) != 0 {
+		t.Errorf("unexpected primary source: %+v", sources[0])
+	}
+	extra := sources[1]
+	if extra.Provider != "linear" || extra.Filter.Team != "ENG" || extra.Filter.Label != "ai" {
+		t.Errorf("unexpected additional source: %+v", extra)
+	}
+	if extra.Readiness != src.Readiness || extra.Order != "oldest-first" {
+		t.Errorf("additional source should inherit readiness and order: %+v", extra)
+	}
+
+	if got := src.CanonicalProvider(); got != "github" {
+		t.Errorf("CanonicalProvider() = %q, want primary provider", got)
+	}
+	src.Canonical = "linear"
+	if got := src.CanonicalProvider(); got != "linear" {
+		t.Errorf("CanonicalProvider() = %q, want linear", got)
+	}
+}
+
+func TestConfig_ForSource(t *testing.T) {
+	cfg := &Config{Start: "s", Source: SourceConfig{Provider: "github"}}
+	sub := cfg.ForSource(SourceConfig{Provider: "linear"})
+	if sub.Source.Provider != "linear" || sub.Start != "s" {
+		t.Errorf("unexpected derived config: %+v", sub)
+	}
+	if cfg.Source.Provider != "github" {
+		t.Error("ForSource must not modify the original config")
+	}
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const standardsFileName = "standards.md"
+
+// MaxStandardsSize caps each standards document injected into a session's
+// system prompt. Longer documents are truncated.
+const MaxStandardsSize = 32 * 1024
+
+// Standards holds the coding standards every session on a repo follows: the
+// organization's global document and the repo's own.
+type Standards struct {
+	Global string
+	Repo   string
+}
+
+// Empty reports whether neither document has any content.
+func (s Standards) Empty() bool {
+	return s.Global == "" && s.Repo == ""
+}
+
+// RepoStandardsPath returns the path of a repo's standards document,
+// .erg/standards.md, kept under version control beside its workflow.
+func RepoStandardsPath(repoPath string) string {
+	return filepath.Join(repoPath, workflowDir, standardsFileName)
+}
+
+// LoadStandards reads the global standards document at globalPath and the
+// repo's .erg/standards.md. Missing documents are empty; globalPath may be
+// empty to skip the global one.
+func LoadStandards(globalPath, repoPath string) (Standards, error) {
+	var s Standards
+	var err error
+	if globalPath != "" {
+		if s.Global, err = readStandards(globalPath); err != nil {
+			return Standards{}, err
+		}
+	}
+	if repoPath != "" {
+		if s.Repo, err = readStandards(RepoStandardsPath(repoPath)); err != nil {
+			return Standards{}, err
+		}
+	}
+	return s, nil
+}
+
+// standardsComment matches the HTML comments left out of standards, which
+// are notes for whoever edits the document.
+var standardsComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// readStandards reads a standards document without its HTML comments,
+// trimmed and capped at MaxStandardsSize. Returns "" if it does not exist.
+func readStandards(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read standards %s: %w", path, err)
+	}
+	text := strings.TrimSpace(standardsComment.ReplaceAllString(string(data), ""))
+	if len(text) > MaxStandardsSize {
+		text = text[:MaxStandardsSize] + "\n... (standards truncated)"
+	}
+	return text, nil
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadStandards(t *testing.T) {
+	repo := t.TempDir()
+	global := filepath.Join(t.TempDir(), "standards.md")
+
+	s, err := LoadStandards(global, repo)
+	if err != nil || !s.Empty() {
+		t.Fatalf("expected no standards, got %+v, %v", s, err)
+	}
+
+	os.WriteFile(global, []byte("<!-- notes for editors -->\n- Wrap errors.\n"), 0o644)
+	os.MkdirAll(filepath.Join(repo, ".erg"), 0o755)
+	os.WriteFile(RepoStandardsPath(repo), []byte(strings.Repeat("x", MaxStandardsSize+10)), 0o644)
+
+	s, err = LoadStandards(global, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Global != "- Wrap errors." {
+		t.Errorf("expected comments dropped from the global standards, got %q", s.Global)
+	}
+	if !strings.HasSuffix(s.Repo, "(standards truncated)") || len(s.Repo) > MaxStandardsSize+30 {
+		t.Errorf("expected the repo standards truncated, got %d bytes", len(s.Repo))
+	}
+
+	if s, _ := LoadStandards("", repo); s.Global != "" || s.Repo == "" {
+		t.Errorf("expected only the repo standards without a global path, got %+v", s)
+	}
+}
@@ -0,0 +1,110 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// stepDataPlaceholder matches {{step.key}} references to a work item's step
+// data, such as values emitted by hooks with output: json.
+var stepDataPlaceholder = regexp.MustCompile(`\{\{\s*step\.(\w+)\s*\}\}`)
+
+// ExpandStepData replaces {{step.key}} placeholders in s with the work
+// item's step data. Strings are inserted as-is, lists of strings and numbers
+// space-separated (so they can be passed to a shell command), and other
+// values as JSON. Keys that are not set expand to an empty string.
+func ExpandStepData(s string, data map[string]any) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return stepDataPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+		key := stepDataPlaceholder.FindStringSubmatch(match)[1]
+		return formatStepValue(data[key])
+	})
+}
+
+// ExpandParams returns a copy of params with {{step.key}} placeholders in
+// string values, including those nested in maps and lists, expanded from
+// data. A value that is a single placeholder takes the step data value with
+// its type, so `max_turns: "{{step.turns}}"` stays a number. Returns params
+// unchanged when it has no placeholders.
+func ExpandParams(params, data map[string]any) map[string]any {
+	if !hasStepPlaceholder(params) {
+		return params
+	}
+	return expandValue(params, data).(map[string]any)
+}
+
+func expandValue(v any, data map[string]any) any {
+	switch v := v.(type) {
+	case string:
+		if m := stepDataPlaceholder.FindStringSubmatch(v); m != nil && m[0] == v {
+			if val, ok := data[m[1]]; ok {
+				return val
+			}
+		}
+		return ExpandStepData(v, data)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = expandValue(e, data)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = expandValue(e, data)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func hasStepPlaceholder(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return stepDataPlaceholder.MatchString(v)
+	case map[string]any:
+		for _, e := range v {
+			if hasStepPlaceholder(e) {
+				return true
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if hasStepPlaceholder(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatStepValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			switch e.(type) {
+			case string, float64, int, bool:
+				parts = append(parts, fmt.Sprint(e))
+			default:
+				b, _ := json.Marshal(v)
+				return string(b)
+			}
+		}
+		return strings.Join(parts, " ")
+	case map[string]any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandStepData(t *testing.T) {
+	data := map[string]any{
+		"test_cmd": "go test",
+		"packages": []any{"./cmd", "./internal/daemon"},
+		"count":    float64(3),
+		"meta":     map[string]any{"risk": "low"},
+		"mixed":    []any{"a", map[string]any{"b": true}},
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"{{step.test_cmd}} {{ step.packages }}", "go test ./cmd ./internal/daemon"},
+		{"{{step.count}} files", "3 files"},
+		{"meta: {{step.meta}}", `meta: {"risk":"low"}`},
+		{"{{step.mixed}}", `["a",{"b":true}]`},
+		{"[{{step.missing}}]", "[]"},
+		{"{{model}} stays", "{{model}} stays"},
+		{"no placeholders", "no placeholders"},
+	}
+	for _, tt := range tests {
+		if got := ExpandStepData(tt.in, data); got != tt.want {
+			t.Errorf("ExpandStepData(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandParams(t *testing.T) {
+	data := map[string]any{"pkgs": "./cmd", "turns": float64(20)}
+	params := map[string]any{
+		"command":   "go test {{step.pkgs}}",
+		"max_turns": "{{step.turns}}",
+		"env":       map[string]any{"PKGS": "{{step.pkgs}}"},
+		"labels":    []any{"ci", "{{step.pkgs}}"},
+		"draft":     true,
+	}
+
+	got := ExpandParams(params, data)
+	want := map[string]any{
+		"command":   "go test ./cmd",
+		"max_turns": float64(20),
+		"env":       map[string]any{"PKGS": "./cmd"},
+		"labels":    []any{"ci", "./cmd"},
+		"draft":     true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandParams = %v, want %v", got, want)
+	}
+	if params["command"] != "go test {{step.pkgs}}" {
+		t.Error("ExpandParams must not modify the state's params")
+	}
+
+	plain := map[string]any{"command": "make"}
+	if got := ExpandParams(plain, data); reflect.ValueOf(got).Pointer() != reflect.ValueOf(plain).Pointer() {
+		t.Error("params without placeholders should be returned as-is")
+	}
+}
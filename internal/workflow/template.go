@@ -261,7 +261,8 @@ func resolveParams(defs []TemplateParam, overrides map[string]any) map[string]an
 var paramPlaceholder = regexp.MustCompile(`\{\{(\w+)\}\}`)
 
 // applyParamSubstitution replaces {{param_name}} placeholders in the string
-// values of state.Params and state.Model with the corresponding value from params.
+// values of state.Params, state.Model, state.FallbackModels and state.Backend
+// with the corresponding value from params.
 func applyParamSubstitution(state *State, params map[string]any) {
 	if len(params) == 0 {
 		return
@@ -270,6 +271,12 @@ func applyParamSubstitution(state *State, params map[string]any) {
 	if state.Model != "" {
 		state.Model = substituteParams(state.Model, params)
 	}
+	for i, m := range state.FallbackModels {
+		state.FallbackModels[i] = substituteParams(m, params)
+	}
+	if state.Backend != "" {
+		state.Backend = substituteParams(state.Backend, params)
+	}
 	for k, v := range state.Params {
 		if s, ok := v.(string); ok {
 			// When the entire value is a single {{name}} placeholder, replace it
@@ -301,8 +308,11 @@ func substituteParams(s string, params map[string]any) string {
 
 // rewriteStateRefs rewrites all state-name references in a state using the
 // provided rename function. Only references in transition fields are rewritten
-// (next, error, timeout_next, default, choices[*].next, catch[*].next).
+// (next, error, timeout_next, default, choices[*].next, catch[*].next, branches).
 func rewriteStateRefs(state *State, rename func(string) string) {
+	for i := range state.Branches {
+		state.Branches[i] = rename(state.Branches[i])
+	}
 	if state.Next != "" {
 		state.Next = rename(state.Next)
 	}
@@ -355,6 +365,14 @@ func cloneState(s *State) *State {
 		clone.Choices = make([]ChoiceRule, len(s.Choices))
 		copy(clone.Choices, s.Choices)
 	}
+	if s.Branches != nil {
+		clone.Branches = make([]string, len(s.Branches))
+		copy(clone.Branches, s.Branches)
+	}
+	if s.FallbackModels != nil {
+		clone.FallbackModels = make([]string, len(s.FallbackModels))
+		copy(clone.FallbackModels, s.FallbackModels)
+	}
 	if s.Catch != nil {
 		clone.Catch = make([]CatchConfig, len(s.Catch))
 		for i, c := range s.Catch {
@@ -387,6 +405,18 @@ func cloneState(s *State) *State {
 		clone.After = make([]HookConfig, len(s.After))
 		copy(clone.After, s.After)
 	}
+	if s.OnFailure != nil {
+		clone.OnFailure = make([]CompensationConfig, len(s.OnFailure))
+		for i, c := range s.OnFailure {
+			clone.OnFailure[i] = c
+			if c.Params != nil {
+				clone.OnFailure[i].Params = make(map[string]any, len(c.Params))
+				for k, v := range c.Params {
+					clone.OnFailure[i].Params[k] = v
+				}
+			}
+		}
+	}
 	if s.Timeout != nil {
 		t := *s.Timeout
 		clone.Timeout = &t
@@ -0,0 +1,37 @@
+package workflow
+
+import "time"
+
+// localTimeLayout is how times are shown to humans in tracker comments.
+const localTimeLayout = "Jan 2, 15:04 MST"
+
+// Location returns the repo's time zone from settings.timezone, used for
+// schedule triggers and for timestamps in comments. Returns UTC when unset
+// or invalid (validation rejects unknown zones).
+func (c *Config) Location() *time.Location {
+	if c == nil || c.Settings == nil || c.Settings.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Settings.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// TriggerLocation returns the time zone a trigger's schedule is evaluated
+// in: its own timezone if set and valid, otherwise the repo's.
+func (c *Config) TriggerLocation(t TriggerConfig) *time.Location {
+	if t.Timezone != "" {
+		if loc, err := time.LoadLocation(t.Timezone); err == nil {
+			return loc
+		}
+	}
+	return c.Location()
+}
+
+// FormatLocalTime renders t for a tracker comment in the repo's time zone,
+// e.g. "Mar 12, 10:00 EDT".
+func (c *Config) FormatLocalTime(t time.Time) string {
+	return t.In(c.Location()).Format(localTimeLayout)
+}
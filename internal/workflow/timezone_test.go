@@ -0,0 +1,32 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_Location(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.Location(); got != time.UTC {
+		t.Errorf("nil config location = %v, want UTC", got)
+	}
+	if got := (&Config{Settings: &SettingsConfig{Timezone: "Not/AZone"}}).Location(); got != time.UTC {
+		t.Errorf("invalid timezone location = %v, want UTC", got)
+	}
+	cfg := &Config{Settings: &SettingsConfig{Timezone: "America/New_York"}}
+	if got := cfg.Location().String(); got != "America/New_York" {
+		t.Errorf("location = %q, want America/New_York", got)
+	}
+}
+
+func TestConfig_FormatLocalTime(t *testing.T) {
+	ts := time.Date(2026, 3, 12, 14, 0, 0, 0, time.UTC)
+
+	if got := (&Config{}).FormatLocalTime(ts); got != "Mar 12, 14:00 UTC" {
+		t.Errorf("default format = %q", got)
+	}
+	cfg := &Config{Settings: &SettingsConfig{Timezone: "America/New_York"}}
+	if got := cfg.FormatLocalTime(ts); got != "Mar 12, 10:00 EDT" {
+		t.Errorf("New York format = %q, want %q", got, "Mar 12, 10:00 EDT")
+	}
+}
@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolPolicyConfig limits the tools and shell commands an agent may run in
+// the repo's sessions. Commands are matched by prefix on whole words, so
+// "go test" covers "go test ./..." and "npm publish" covers
+// "npm publish --tag next". Refused commands are logged and noted in the
+// session transcript.
+type ToolPolicyConfig struct {
+	// AllowCommands, when set, are the only commands sessions may run,
+	// e.g. ["go test", "go build", "make"].
+	AllowCommands []string `yaml:"allow_commands,omitempty"`
+	// DenyCommands are commands sessions may never run, e.g. ["curl",
+	// "npm publish"]. They win over allow_commands.
+	DenyCommands []string `yaml:"deny_commands,omitempty"`
+	// DenyTools are Claude tools sessions may not use, e.g. ["WebFetch"].
+	DenyTools []string `yaml:"deny_tools,omitempty"`
+}
+
+// ToolPolicy returns the repo's tool policy, or nil when it sets none.
+func (c *Config) ToolPolicy() *ToolPolicyConfig {
+	if c == nil || c.Settings == nil {
+		return nil
+	}
+	return c.Settings.ToolPolicy
+}
+
+// validateToolPolicy checks each policy entry is a plain command prefix or
+// tool name: erg turns them into Claude CLI tool rules, which can't hold
+// commas or parentheses.
+func validateToolPolicy(cfg *Config) []ValidationError {
+	p := cfg.ToolPolicy()
+	if p == nil {
+		return nil
+	}
+	var errs []ValidationError
+	check := func(field string, entries []string, what string) {
+		for i, e := range entries {
+			switch {
+			case strings.TrimSpace(e) == "":
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s[%d]", field, i), Message: what + " is empty"})
+			case strings.ContainsAny(e, ",()"):
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("%s %q may not contain commas or parentheses", what, e)})
+			}
+		}
+	}
+	check("settings.tool_policy.allow_commands", p.AllowCommands, "command")
+	check("settings.tool_policy.deny_commands", p.DenyCommands, "command")
+	check("settings.tool_policy.deny_tools", p.DenyTools, "tool")
+	return errs
+}
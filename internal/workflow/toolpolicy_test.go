@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_ToolPolicy(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`
+settings:
+  tool_policy:
+    allow_commands: [go test, make]
+    deny_commands: [curl, npm publish]
+    deny_tools: [WebFetch]
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.ToolPolicy()
+	if p == nil || !slices.Equal(p.AllowCommands, []string{"go test", "make"}) ||
+		!slices.Equal(p.DenyCommands, []string{"curl", "npm publish"}) || !slices.Equal(p.DenyTools, []string{"WebFetch"}) {
+		t.Errorf("unexpected tool policy: %+v", p)
+	}
+	if (&Config{}).ToolPolicy() != nil {
+		t.Error("expected no tool policy without settings")
+	}
+}
+
+func TestValidate_ToolPolicy(t *testing.T) {
+	cfg := migrationTestConfig(nil)
+	cfg.Settings.ToolPolicy = &ToolPolicyConfig{
+		AllowCommands: []string{"go test", " "},
+		DenyCommands:  []string{"curl", "npm publish,yarn publish"},
+		DenyTools:     []string{"Bash(rm:*)"},
+	}
+
+	var fields []string
+	for _, e := range Validate(cfg) {
+		fields = append(fields, e.Field)
+	}
+	want := []string{
+		"settings.tool_policy.allow_commands[1]",
+		"settings.tool_policy.deny_commands[1]",
+		"settings.tool_policy.deny_tools[0]",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("errors on %v, want %v", fields, want)
+	}
+
+	cfg.Settings.ToolPolicy = &ToolPolicyConfig{AllowCommands: []string{"go test"}, DenyCommands: []string{"curl"}, DenyTools: []string{"WebFetch"}}
+	if errs := Validate(cfg); len(errs) != 0 {
+		t.Errorf("expected a valid tool policy, got: %v", errs)
+	}
+}
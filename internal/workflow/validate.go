@@ -2,17 +2,22 @@ package workflow
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/robfig/cron/v3"
+	"github.com/zhubert/erg/internal/labelexpr"
 )
 
 // ValidationError describes a single validation problem.
 type ValidationError struct {
 	Field   string
 	Message string
+	Line    int // line in the workflow file, 0 if unknown; set by LintFile
 }
 
 // Validate checks a Config for errors and returns all problems found.
@@ -55,10 +60,30 @@ func Validate(cfg *Config) []ValidationError {
 
 	// Settings validation
 	errs = append(errs, validateSettings(cfg.Settings)...)
+	errs = append(errs, validateMigration(cfg)...)
+	errs = append(errs, validatePriority(cfg)...)
+	errs = append(errs, validateReaper(cfg)...)
+	errs = append(errs, validateContainer(cfg)...)
+	errs = append(errs, validateResources(cfg)...)
+	errs = append(errs, validateToolPolicy(cfg)...)
+	errs = append(errs, validateImageRegistry(cfg)...)
+	errs = append(errs, validateServices(cfg)...)
+	errs = append(errs, validatePreflight(cfg)...)
+	errs = append(errs, validateBackends(cfg)...)
+	errs = append(errs, validateFallbackModels(cfg)...)
+	if cfg.Settings != nil {
+		errs = append(errs, ValidateBudget("settings.budget", cfg.Settings.Budget)...)
+		errs = append(errs, ValidateLimits("settings.limits", cfg.Settings.Limits)...)
+	}
+	errs = append(errs, validateWorkflows(cfg.Workflows)...)
+	errs = append(errs, validateMutex("mutex", cfg.Mutex)...)
 
 	// Trigger validation
 	errs = append(errs, validateTriggers(cfg.Triggers, cfg.States)...)
 
+	// Network profile validation
+	errs = append(errs, validateNetwork(cfg)...)
+
 	return errs
 }
 
@@ -71,7 +96,7 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 	if !ValidStateTypes[state.Type] {
 		errs = append(errs, ValidationError{
 			Field:   prefix + ".type",
-			Message: fmt.Sprintf("unknown state type %q (must be task, wait, choice, pass, succeed, or fail)", state.Type),
+			Message: fmt.Sprintf("unknown state type %q (must be task, wait, choice, pass, parallel, join, succeed, or fail)", state.Type),
 		})
 		return errs // Can't validate further without valid type
 	}
@@ -129,6 +154,9 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			errs = append(errs, validateCodingParams(prefix, state.Params)...)
 			// simplify is only meaningful for ai.code, not ai.plan
 			errs = append(errs, optionalBoolParam(prefix, state.Params, "simplify")...)
+			errs = append(errs, validatePipelineParams(prefix, state.Params)...)
+			errs = append(errs, optionalBoolParam(prefix, state.Params, "verify")...)
+			errs = append(errs, optionalPositiveNum(prefix, state.Params, "max_verify_rounds")...)
 		}
 
 		// Validate params for ai.plan action (same param shape as ai.code)
@@ -141,6 +169,22 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			errs = append(errs, validateMergeParams(prefix, state.Params)...)
 		}
 
+		// Validate the body template of github.create_pr actions
+		if state.Action == "github.create_pr" {
+			errs = append(errs, optionalTemplateParams(prefix, state.Params, "body")...)
+		}
+
+		// Validate message and body templates of notification actions
+		switch state.Action {
+		case "slack.notify":
+			errs = append(errs, optionalTemplateParams(prefix, state.Params, "message")...)
+		case "webhook.post":
+			errs = append(errs, optionalTemplateParams(prefix, state.Params, "body")...)
+		case "issue.create":
+			errs = append(errs, optionalTemplateParams(prefix, state.Params, "title", "body")...)
+			errs = append(errs, optionalBoolParam(prefix, state.Params, "from_follow_ups")...)
+		}
+
 		// Validate params for github.comment_issue action
 		if state.Action == "github.comment_issue" {
 			errs = append(errs, validateCommentIssueParams(prefix, state.Params)...)
@@ -171,6 +215,11 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			errs = append(errs, validateFormatParams(prefix, state.Params)...)
 		}
 
+		// Validate params for exec.run action
+		if state.Action == "exec.run" {
+			errs = append(errs, validateExecParams(prefix, state.Params)...)
+		}
+
 		// Validate params for git.rebase action
 		if state.Action == "git.rebase" {
 			errs = append(errs, validateRebaseParams(prefix, state.Params)...)
@@ -181,6 +230,17 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			errs = append(errs, validateDiffParams(prefix, state.Params)...)
 		}
 
+		// Validate params for quality.gate action
+		if state.Action == "quality.gate" {
+			errs = append(errs, validateQualityGateParams(prefix, state.Params)...)
+		}
+
+		// Validate params for ai.address_feedback action
+		if state.Action == "ai.address_feedback" {
+			errs = append(errs, optionalPositiveNum(prefix, state.Params, "max_feedback_rounds")...)
+			errs = append(errs, optionalBoolParam(prefix, state.Params, "simplify")...)
+		}
+
 		// Validate params for ai.resolve_conflicts action
 		if state.Action == "ai.resolve_conflicts" {
 			errs = append(errs, validateResolveConflictsParams(prefix, state.Params)...)
@@ -231,6 +291,8 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			errs = append(errs, validateCIParams(prefix, state.Params)...)
 		}
 
+		errs = append(errs, validateEscalation(prefix, state)...)
+
 	case StateTypeChoice:
 		// Choice states require at least one choice rule
 		if len(state.Choices) == 0 {
@@ -290,6 +352,17 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 			})
 		}
 
+	case StateTypeParallel:
+		errs = append(errs, validateParallelState(prefix, state, allStates)...)
+
+	case StateTypeJoin:
+		if state.Next == "" {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".next",
+				Message: "next is required for join states",
+			})
+		}
+
 	case StateTypeSucceed, StateTypeFail:
 		// Terminal states must not have next
 		if state.Next != "" {
@@ -300,6 +373,31 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 		}
 	}
 
+	if state.Type != StateTypeWait {
+		if state.OnTimeout != "" {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".on_timeout",
+				Message: "on_timeout is only valid on wait states",
+			})
+		}
+		if len(state.Escalation) > 0 {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".escalation",
+				Message: "escalation is only valid on wait states",
+			})
+		}
+	}
+	if state.Mutex != "" && state.Type != StateTypeTask {
+		errs = append(errs, ValidationError{
+			Field:   prefix + ".mutex",
+			Message: "mutex is only valid on task states",
+		})
+	} else {
+		errs = append(errs, validateMutex(prefix+".mutex", state.Mutex)...)
+	}
+
+	errs = append(errs, validateOnFailure(prefix, state)...)
+
 	// Validate retry configs
 	for i, retry := range state.Retry {
 		retryPrefix := fmt.Sprintf("%s.retry[%d]", prefix, i)
@@ -359,6 +457,112 @@ func validateState(name string, state *State, allStates map[string]*State) []Val
 		}
 	}
 
+	errs = append(errs, validateHooks(prefix+".before", state.Before)...)
+	errs = append(errs, validateHooks(prefix+".after", state.After)...)
+
+	return errs
+}
+
+// validateHooks checks that every hook has a command to run and a known
+// output format.
+func validateHooks(field string, hooks []HookConfig) []ValidationError {
+	var errs []ValidationError
+	for i, hook := range hooks {
+		if strings.TrimSpace(hook.Run) == "" {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("%s[%d].run", field, i),
+				Message: "hook run command is required",
+			})
+		} else if err := CheckTemplate(hook.Run); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("%s[%d].run", field, i),
+				Message: err.Error(),
+			})
+		}
+		if hook.Output != "" && hook.Output != HookOutputJSON {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("%s[%d].output", field, i),
+				Message: fmt.Sprintf("unknown hook output %q (must be json)", hook.Output),
+			})
+		}
+	}
+	return errs
+}
+
+// validateParallelState checks that each branch of a parallel state is a
+// chain of synchronous task and pass states that ends at the join state named
+// by next.
+func validateParallelState(prefix string, state *State, allStates map[string]*State) []ValidationError {
+	var errs []ValidationError
+	if len(state.Branches) < 2 {
+		errs = append(errs, ValidationError{
+			Field:   prefix + ".branches",
+			Message: "at least two branches are required for parallel states",
+		})
+	}
+	if state.Next == "" {
+		return append(errs, ValidationError{
+			Field:   prefix + ".next",
+			Message: "next is required for parallel states",
+		})
+	}
+	if join, ok := allStates[state.Next]; !ok {
+		return errs // reported by the next reference check
+	} else if join.Type != StateTypeJoin {
+		return append(errs, ValidationError{
+			Field:   prefix + ".next",
+			Message: fmt.Sprintf("must reference a join state, %q is a %s state", state.Next, join.Type),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i, start := range state.Branches {
+		field := fmt.Sprintf("%s.branches[%d]", prefix, i)
+		for cur := start; cur != state.Next; {
+			if seen[cur] {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("state %q is reached more than once; branches must not share or revisit states", cur),
+				})
+				break
+			}
+			seen[cur] = true
+
+			s, ok := allStates[cur]
+			if !ok {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("references non-existent state %q", cur),
+				})
+				break
+			}
+			if s.Type != StateTypeTask && s.Type != StateTypePass {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("state %q is a %s state; branches may only contain task and pass states", cur, s.Type),
+				})
+				break
+			}
+			if strings.HasPrefix(s.Action, "ai.") {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("state %q runs %s, which is async and cannot run in a parallel branch", cur, s.Action),
+				})
+				break
+			}
+			if s.Error != "" || len(s.Catch) > 0 || len(s.Retry) > 0 {
+				errs = append(errs, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("state %q sets error, catch, or retry; branch failures are handled by the join state", cur),
+				})
+				break
+			}
+			if s.Next == "" {
+				break // reported by the state's own next check
+			}
+			cur = s.Next
+		}
+	}
 	return errs
 }
 
@@ -369,10 +573,12 @@ func validateCodingParams(prefix string, params map[string]any) []ValidationErro
 		return errs
 	}
 
+	errs = append(errs, validateResultRequired(prefix, params)...)
+
 	// Validate system_prompt path if present
 	if sp, ok := params["system_prompt"]; ok {
 		if s, ok := sp.(string); ok {
-			errs = append(errs, validatePromptPath(prefix+".params.system_prompt", s)...)
+			errs = append(errs, validateTemplate(prefix+".params.system_prompt", s)...)
 		}
 	}
 
@@ -397,6 +603,43 @@ func validateCodingParams(prefix string, params map[string]any) []ValidationErro
 	return errs
 }
 
+// validatePipelineParams validates the params of an ai.code state run as a
+// plan/implement/review pipeline.
+func validatePipelineParams(prefix string, params map[string]any) []ValidationError {
+	errs := optionalBoolParam(prefix, params, "pipeline")
+	for _, key := range []string{"plan_system_prompt", "review_system_prompt"} {
+		if s, ok := params[key].(string); ok {
+			errs = append(errs, validateTemplate(prefix+".params."+key, s)...)
+		}
+	}
+	return errs
+}
+
+// validateResultRequired validates the result_required param: a list of
+// the result envelope fields the state's session must fill in.
+func validateResultRequired(prefix string, params map[string]any) []ValidationError {
+	v, ok := params["result_required"]
+	if !ok {
+		return nil
+	}
+	field := prefix + ".params.result_required"
+	list, ok := v.([]any)
+	if !ok {
+		return []ValidationError{{Field: field, Message: "result_required must be a list of result fields"}}
+	}
+	var errs []ValidationError
+	for _, e := range list {
+		name, _ := e.(string)
+		if !slices.Contains(ResultFields, name) {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("unknown result field %v (must be one of %v)", e, ResultFields),
+			})
+		}
+	}
+	return errs
+}
+
 // validateMergeParams validates params for github.merge actions.
 func validateMergeParams(prefix string, params map[string]any) []ValidationError {
 	return optionalEnum(prefix, params, "method", []string{"rebase", "squash", "merge"})
@@ -434,6 +677,24 @@ func validateFormatParams(prefix string, params map[string]any) []ValidationErro
 	return requireString(prefix, params, "command", "git.format action")
 }
 
+// validateExecParams validates params for exec.run actions.
+func validateExecParams(prefix string, params map[string]any) []ValidationError {
+	errs := requireString(prefix, params, "command", "exec.run action")
+	errs = append(errs, optionalTemplateParams(prefix, params, "command")...)
+	if raw, ok := params["env"]; ok && raw != nil {
+		env, ok := raw.(map[string]any)
+		if !ok {
+			return append(errs, ValidationError{Field: prefix + ".params.env", Message: "env must be a map of variable names to values"})
+		}
+		for k, v := range env {
+			if _, ok := v.(string); !ok {
+				errs = append(errs, ValidationError{Field: prefix + ".params.env." + k, Message: "env values must be strings"})
+			}
+		}
+	}
+	return errs
+}
+
 // validateRebaseParams validates params for git.rebase actions.
 func validateRebaseParams(prefix string, params map[string]any) []ValidationError {
 	return optionalPositiveNum(prefix, params, "max_rebase_rounds")
@@ -456,7 +717,7 @@ func validateSource(cfg *Config) []ValidationError {
 	var errs []ValidationError
 
 	switch cfg.Source.Provider {
-	case "github", "asana", "linear":
+	case "github", "asana", "linear", "gitlab", "clickup", "file", "http":
 		// valid
 	case "":
 		errs = append(errs, ValidationError{
@@ -466,13 +727,13 @@ func validateSource(cfg *Config) []ValidationError {
 	default:
 		errs = append(errs, ValidationError{
 			Field:   "source.provider",
-			Message: fmt.Sprintf("unknown provider %q (must be github, asana, or linear)", cfg.Source.Provider),
+			Message: fmt.Sprintf("unknown provider %q (must be github, asana, linear, gitlab, clickup, file, or http)", cfg.Source.Provider),
 		})
 	}
 
 	// Filter requirements (only validate when provider is known)
 	switch cfg.Source.Provider {
-	case "github", "asana", "linear":
+	case "github", "asana", "linear", "gitlab", "clickup":
 		// Label is required for all providers — it serves as the permanent
 		// AI-assisted marker so humans can distinguish erg-managed issues.
 		if cfg.Source.Filter.Label == "" {
@@ -481,6 +742,21 @@ func validateSource(cfg *Config) []ValidationError {
 				Message: "label is required (identifies AI-assisted issues)",
 			})
 		}
+	case "file":
+		// The backlog lives in the repo, so every item is erg-managed;
+		// the label is an optional filter.
+	case "http":
+		// Generic endpoints may not expose labels at all, so the label is an
+		// optional filter.
+	}
+
+	if label := cfg.Source.Filter.Label; label != "" {
+		if _, err := labelexpr.Parse(label); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.label",
+				Message: err.Error(),
+			})
+		}
 	}
 
 	// Provider-specific filter requirements
@@ -499,8 +775,133 @@ func validateSource(cfg *Config) []ValidationError {
 				Message: "team is required for linear provider",
 			})
 		}
+	case "gitlab":
+		if cfg.Source.Filter.Project == "" {
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.project",
+				Message: "project is required for gitlab provider",
+			})
+		}
+	case "clickup":
+		if cfg.Source.Filter.List == "" {
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.list",
+				Message: "list is required for clickup provider",
+			})
+		}
+	case "http":
+		if cfg.Source.Filter.HTTP == nil || cfg.Source.Filter.HTTP.URL == "" {
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.http.url",
+				Message: "http.url is required for http provider",
+			})
+		}
+	}
+
+	if cfg.Source.Filter.Assignee != "" {
+		switch cfg.Source.Provider {
+		case "github", "gitlab", "linear", "asana":
+			// supported
+		default:
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.assignee",
+				Message: fmt.Sprintf("assignee is not supported for %s provider", cfg.Source.Provider),
+			})
+		}
+	}
+
+	if cfg.Source.Filter.ExpandSubtasks {
+		switch cfg.Source.Provider {
+		case "linear", "asana":
+			// supported
+		default:
+			errs = append(errs, ValidationError{
+				Field:   "source.filter.expand_subtasks",
+				Message: fmt.Sprintf("expand_subtasks is not supported for %s provider", cfg.Source.Provider),
+			})
+		}
+	}
+
+	if cfg.Source.Filter.MaxItems < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "source.filter.max_items",
+			Message: "max_items must not be negative",
+		})
+	}
+
+	switch cfg.Source.Order {
+	case "", "priority", "oldest-first", "newest-first":
+		// valid
+	case "label-weighted":
+		if len(cfg.Source.LabelWeights) == 0 {
+			errs = append(errs, ValidationError{
+				Field:   "source.label_weights",
+				Message: "label_weights is required for label-weighted order",
+			})
+		}
+	default:
+		errs = append(errs, ValidationError{
+			Field:   "source.order",
+			Message: fmt.Sprintf("unknown order %q (must be priority, oldest-first, newest-first, or label-weighted)", cfg.Source.Order),
+		})
+	}
+
+	if w := cfg.Source.DeadlineWindow; w != nil && w.Duration < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "source.deadline_window",
+			Message: "deadline_window must not be negative",
+		})
 	}
 
+	if r := cfg.Source.Readiness; r != nil {
+		if r.MinBodyLength < 0 {
+			errs = append(errs, ValidationError{
+				Field:   "source.readiness.min_body_length",
+				Message: "min_body_length must not be negative",
+			})
+		}
+		for i, section := range r.RequiredSections {
+			if strings.TrimSpace(section) == "" {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("source.readiness.required_sections[%d]", i),
+					Message: "section name must not be empty",
+				})
+			}
+		}
+	}
+
+	errs = append(errs, validateAdditionalSources(&cfg.Source)...)
+
+	return errs
+}
+
+// validateAdditionalSources validates source.additional and source.canonical.
+// Each additional source must meet the same provider and filter requirements
+// as the primary one, and no provider may be polled twice.
+func validateAdditionalSources(src *SourceConfig) []ValidationError {
+	var errs []ValidationError
+	providers := []string{src.Provider}
+	for i, extra := range src.Additional {
+		prefix := fmt.Sprintf("source.additional[%d]", i)
+		sub := &Config{Source: SourceConfig{Provider: extra.Provider, Filter: extra.Filter}}
+		for _, e := range validateSource(sub) {
+			e.Field = prefix + strings.TrimPrefix(e.Field, "source")
+			errs = append(errs, e)
+		}
+		if extra.Provider != "" && slices.Contains(providers, extra.Provider) {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".provider",
+				Message: fmt.Sprintf("provider %q is already a source for this repo", extra.Provider),
+			})
+		}
+		providers = append(providers, extra.Provider)
+	}
+	if src.Canonical != "" && !slices.Contains(providers, src.Canonical) {
+		errs = append(errs, ValidationError{
+			Field:   "source.canonical",
+			Message: fmt.Sprintf("canonical provider %q is not one of this repo's sources", src.Canonical),
+		})
+	}
 	return errs
 }
 
@@ -516,6 +917,154 @@ func validateSettings(s *SettingsConfig) []ValidationError {
 			Message: "max_concurrent must not be negative",
 		})
 	}
+	if s.ProgressInterval < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "settings.progress_interval",
+			Message: "progress_interval must not be negative",
+		})
+	}
+	if s.EpicSummaryInterval < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "settings.epic_summary_interval",
+			Message: "epic_summary_interval must not be negative",
+		})
+	}
+	if s.DeadLetterAfter < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "settings.dead_letter_after",
+			Message: "dead_letter_after must not be negative",
+		})
+	}
+	if s.IssueContextMaxChars < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "settings.issue_context_max_chars",
+			Message: "issue_context_max_chars must not be negative",
+		})
+	}
+	if s.RepoIndexMaxChars < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "settings.repo_index_max_chars",
+			Message: "repo_index_max_chars must not be negative",
+		})
+	}
+	errs = append(errs, validateRepoIndexModel(s.RepoIndexModel)...)
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "settings.timezone",
+				Message: fmt.Sprintf("unknown time zone %q (use an IANA name such as America/New_York)", s.Timezone),
+			})
+		}
+	}
+	for i, action := range s.ConfirmActions {
+		if _, ok := DestructiveActions[action]; !ok {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("settings.confirm_actions[%d]", i),
+				Message: fmt.Sprintf("%q is not a destructive action (must be one of %s)", action, strings.Join(slices.Sorted(maps.Keys(DestructiveActions)), ", ")),
+			})
+		}
+	}
+	errs = append(errs, validateEventSinks(s.Events)...)
+	return errs
+}
+
+// validateEventSinks checks that each event sink has a known type and the
+// destination its type needs.
+func validateEventSinks(sinks []EventSinkConfig) []ValidationError {
+	var errs []ValidationError
+	for i, sink := range sinks {
+		prefix := fmt.Sprintf("settings.events[%d]", i)
+		switch sink.Type {
+		case EventSinkJSONL:
+			if sink.Path == "" {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".path",
+					Message: "path is required for jsonl sinks",
+				})
+			}
+		case EventSinkWebhook:
+			if !strings.HasPrefix(sink.URL, "https://") && !strings.HasPrefix(sink.URL, "http://") {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".url",
+					Message: "url is required for webhook sinks and must be http(s)",
+				})
+			}
+		case EventSinkStdout:
+		default:
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("unknown event sink type %q (must be jsonl, webhook, or stdout)", sink.Type),
+			})
+		}
+	}
+	return errs
+}
+
+// validateMigration checks the migration policy and that remap rules lead to
+// existing states.
+func validateMigration(cfg *Config) []ValidationError {
+	if cfg.Settings == nil || cfg.Settings.Migration == nil {
+		return nil
+	}
+	m := cfg.Settings.Migration
+	var errs []ValidationError
+	if m.Policy != "" && !ValidMigrationPolicies[m.Policy] {
+		errs = append(errs, ValidationError{
+			Field:   "settings.migration.policy",
+			Message: fmt.Sprintf("unknown migration policy %q (must be finish-on-old, remap, or fail)", m.Policy),
+		})
+	}
+	for _, from := range slices.Sorted(maps.Keys(m.Remap)) {
+		if _, ok := cfg.States[m.Remap[from]]; !ok {
+			errs = append(errs, ValidationError{
+				Field:   "settings.migration.remap." + from,
+				Message: fmt.Sprintf("references non-existent state %q", m.Remap[from]),
+			})
+		}
+	}
+	return errs
+}
+
+// validatePriority checks that hotfix labels are not blank.
+func validatePriority(cfg *Config) []ValidationError {
+	if cfg.Settings == nil || cfg.Settings.Priority == nil {
+		return nil
+	}
+	var errs []ValidationError
+	for i, label := range cfg.Settings.Priority.HotfixLabels {
+		if strings.TrimSpace(label) == "" {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("settings.priority.hotfix_labels[%d]", i),
+				Message: "label must not be empty",
+			})
+		}
+	}
+	return errs
+}
+
+// validateWorkflows checks the label-selected workflows: each needs a unique
+// name, a file, and at least one label.
+func validateWorkflows(workflows []NamedWorkflow) []ValidationError {
+	var errs []ValidationError
+	seen := make(map[string]bool)
+	for i, nw := range workflows {
+		prefix := fmt.Sprintf("workflows[%d]", i)
+		switch {
+		case nw.Name == "":
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "workflow name is required"})
+		case nw.Name == DefaultWorkflowName:
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("%q is reserved for the main workflow", DefaultWorkflowName)})
+		case seen[nw.Name]:
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate workflow name %q", nw.Name)})
+		}
+		seen[nw.Name] = true
+		if nw.File == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".file", Message: "workflow file is required"})
+		}
+		if len(nw.Labels) == 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".labels", Message: "at least one label is required"})
+		}
+	}
 	return errs
 }
 
@@ -531,7 +1080,7 @@ func detectCycles(cfg *Config) []ValidationError {
 	// Build adjacency list from all transition edges
 	edges := make(map[string][]string)
 	for name, state := range cfg.States {
-		var targets []string
+		targets := slices.Clone(state.Branches)
 		if state.Next != "" {
 			targets = append(targets, state.Next)
 		}
@@ -635,6 +1184,66 @@ func validateDiffParams(prefix string, params map[string]any) []ValidationError
 	return errs
 }
 
+// validateQualityGateParams validates params for quality.gate actions: a
+// non-empty list of uniquely named checks, each with what its type needs.
+func validateQualityGateParams(prefix string, params map[string]any) []ValidationError {
+	field := prefix + ".params.checks"
+	raw, ok := params["checks"].([]any)
+	if !ok || len(raw) == 0 {
+		return []ValidationError{{Field: field, Message: "checks is required for quality.gate action and must be a non-empty list"}}
+	}
+	var errs []ValidationError
+	seen := make(map[string]bool)
+	for i, entry := range raw {
+		checkPrefix := fmt.Sprintf("%s[%d]", field, i)
+		check, ok := entry.(map[string]any)
+		if !ok {
+			errs = append(errs, ValidationError{Field: checkPrefix, Message: "check must be a map"})
+			continue
+		}
+		name, _ := check["name"].(string)
+		switch {
+		case name == "":
+			errs = append(errs, ValidationError{Field: checkPrefix + ".name", Message: "name is required"})
+		case seen[name]:
+			errs = append(errs, ValidationError{Field: checkPrefix + ".name", Message: fmt.Sprintf("duplicate check name %q", name)})
+		}
+		seen[name] = true
+
+		checkType, _ := check["type"].(string)
+		if checkType == "" {
+			checkType = QualityCheckCommand
+		}
+		command, _ := check["command"].(string)
+		switch checkType {
+		case QualityCheckCommand, QualityCheckCoverage:
+			if command == "" {
+				errs = append(errs, ValidationError{Field: checkPrefix + ".command", Message: fmt.Sprintf("command is required for %s checks", checkType)})
+			}
+			if checkType == QualityCheckCoverage {
+				if pct, ok := toFloat64(check["min"]); !ok || pct <= 0 || pct > 100 {
+					errs = append(errs, ValidationError{Field: checkPrefix + ".min", Message: "min must be a percentage between 0 and 100"})
+				}
+			}
+		case QualityCheckDiffSize, QualityCheckFileCount:
+			if limit, ok := toFloat64(check["max"]); !ok || limit <= 0 {
+				errs = append(errs, ValidationError{Field: checkPrefix + ".max", Message: fmt.Sprintf("max must be greater than 0 for %s checks", checkType)})
+			}
+		default:
+			errs = append(errs, ValidationError{
+				Field:   checkPrefix + ".type",
+				Message: fmt.Sprintf("unknown check type %q (must be %s)", checkType, strings.Join(QualityCheckTypes, ", ")),
+			})
+		}
+		if t, ok := check["timeout"].(string); ok {
+			if _, err := time.ParseDuration(t); err != nil {
+				errs = append(errs, ValidationError{Field: checkPrefix + ".timeout", Message: fmt.Sprintf("invalid timeout %q", t)})
+			}
+		}
+	}
+	return errs
+}
+
 // validateRetryActionParams validates params for workflow.retry actions.
 func validateRetryActionParams(prefix string, params map[string]any) []ValidationError {
 	var errs []ValidationError
@@ -768,10 +1377,135 @@ func optionalBoolParam(prefix string, params map[string]any, key string) []Valid
 // a schedule passes validation but fires differently (or vice versa).
 var CronParserSpec = cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow
 
+// validateEscalation validates a wait state's on_timeout and escalation
+// levels. Levels must come in increasing order of after, before the timeout,
+// since a level due at or past it would never run.
+func validateEscalation(prefix string, state *State) []ValidationError {
+	var errs []ValidationError
+	switch state.OnTimeout {
+	case "":
+	case TimeoutStall:
+		if state.Timeout == nil {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".on_timeout",
+				Message: "on_timeout requires timeout to be set",
+			})
+		}
+		if state.TimeoutNext != "" {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".on_timeout",
+				Message: "on_timeout: stall cannot be combined with timeout_next",
+			})
+		}
+	default:
+		errs = append(errs, ValidationError{
+			Field:   prefix + ".on_timeout",
+			Message: fmt.Sprintf("unknown on_timeout %q (must be stall)", state.OnTimeout),
+		})
+	}
+
+	var prev time.Duration
+	for i, esc := range state.Escalation {
+		escPrefix := fmt.Sprintf("%s.escalation[%d]", prefix, i)
+		switch {
+		case esc.After.Duration <= 0:
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".after",
+				Message: "after must be greater than 0",
+			})
+		case esc.After.Duration <= prev:
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".after",
+				Message: fmt.Sprintf("after must be later than the previous level's %s", prev),
+			})
+		case state.Timeout != nil && esc.After.Duration >= state.Timeout.Duration:
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".after",
+				Message: fmt.Sprintf("after must be earlier than the state's timeout of %s", state.Timeout.Duration),
+			})
+		}
+		prev = max(prev, esc.After.Duration)
+		if esc.Action == "" {
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".action",
+				Message: "action is required",
+			})
+		} else if !ValidActions[esc.Action] {
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".action",
+				Message: fmt.Sprintf("unknown action %q", esc.Action),
+			})
+		} else if strings.HasPrefix(esc.Action, "ai.") {
+			errs = append(errs, ValidationError{
+				Field:   escPrefix + ".action",
+				Message: fmt.Sprintf("%s starts a session and cannot run as an escalation", esc.Action),
+			})
+		}
+	}
+	return errs
+}
+
+// validateOnFailure checks that every compensation of a state runs exactly
+// one of an action or a command, and that actions are known and sessionless.
+func validateOnFailure(prefix string, state *State) []ValidationError {
+	var errs []ValidationError
+	if len(state.OnFailure) > 0 && (state.Type == StateTypeSucceed || state.Type == StateTypeFail) {
+		return append(errs, ValidationError{
+			Field:   prefix + ".on_failure",
+			Message: "on_failure is not valid on terminal states",
+		})
+	}
+	for i, comp := range state.OnFailure {
+		compPrefix := fmt.Sprintf("%s.on_failure[%d]", prefix, i)
+		switch {
+		case comp.Action == "" && comp.Run == "":
+			errs = append(errs, ValidationError{
+				Field:   compPrefix,
+				Message: "one of action or run is required",
+			})
+		case comp.Action != "" && comp.Run != "":
+			errs = append(errs, ValidationError{
+				Field:   compPrefix,
+				Message: "action and run are mutually exclusive",
+			})
+		case comp.Run != "":
+			if err := CheckTemplate(comp.Run); err != nil {
+				errs = append(errs, ValidationError{Field: compPrefix + ".run", Message: err.Error()})
+			}
+		case !ValidActions[comp.Action]:
+			errs = append(errs, ValidationError{
+				Field:   compPrefix + ".action",
+				Message: fmt.Sprintf("unknown action %q", comp.Action),
+			})
+		case strings.HasPrefix(comp.Action, "ai."):
+			errs = append(errs, ValidationError{
+				Field:   compPrefix + ".action",
+				Message: fmt.Sprintf("%s starts a session and cannot run as a compensation", comp.Action),
+			})
+		}
+	}
+	return errs
+}
+
+// mutexNamePattern matches valid mutex group names, e.g. "db-migrations".
+var mutexNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateMutex validates a workflow or state mutex group name.
+func validateMutex(field, name string) []ValidationError {
+	if name == "" || mutexNamePattern.MatchString(name) {
+		return nil
+	}
+	return []ValidationError{{
+		Field:   field,
+		Message: fmt.Sprintf("invalid mutex %q (use letters, digits, '.', '_' and '-')", name),
+	}}
+}
+
 // validateTriggers validates cron-based trigger configurations.
 func validateTriggers(triggers []TriggerConfig, states map[string]*State) []ValidationError {
 	var errs []ValidationError
 	p := cron.NewParser(CronParserSpec)
+	seen := make(map[string]bool)
 	for i, t := range triggers {
 		prefix := fmt.Sprintf("triggers[%d]", i)
 		if t.Schedule == "" {
@@ -796,6 +1530,61 @@ func validateTriggers(triggers []TriggerConfig, states map[string]*State) []Vali
 				Message: fmt.Sprintf("unknown state %q", t.State),
 			})
 		}
+		if t.Name != "" {
+			if seen[t.Name] {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".name",
+					Message: fmt.Sprintf("duplicate trigger name %q", t.Name),
+				})
+			}
+			seen[t.Name] = true
+		}
+		if t.Timezone != "" {
+			if _, err := time.LoadLocation(t.Timezone); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".timezone",
+					Message: fmt.Sprintf("unknown time zone %q (use an IANA name such as America/New_York)", t.Timezone),
+				})
+			} else if strings.HasPrefix(t.Schedule, "CRON_TZ=") || strings.HasPrefix(t.Schedule, "TZ=") {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".timezone",
+					Message: "timezone cannot be combined with a CRON_TZ= or TZ= schedule prefix",
+				})
+			}
+		}
+		switch t.Overlap {
+		case "", TriggerOverlapSkip, TriggerOverlapAllow:
+		default:
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".overlap",
+				Message: fmt.Sprintf("unknown overlap %q (must be skip or allow)", t.Overlap),
+			})
+		}
+		errs = append(errs, validatePromptPath(prefix+".prompt", t.Prompt)...)
+	}
+	return errs
+}
+
+// validateTemplate checks a value that is either a file: reference or a
+// template rendered with workflow data.
+func validateTemplate(field, value string) []ValidationError {
+	if strings.HasPrefix(value, "file:") {
+		return validatePromptPath(field, value)
+	}
+	if err := CheckTemplate(value); err != nil {
+		return []ValidationError{{Field: field, Message: err.Error()}}
+	}
+	return nil
+}
+
+// optionalTemplateParams checks that each of the named string params, when
+// present, is a valid template or file: reference.
+func optionalTemplateParams(prefix string, params map[string]any, keys ...string) []ValidationError {
+	var errs []ValidationError
+	for _, key := range keys {
+		if s, ok := params[key].(string); ok {
+			errs = append(errs, validateTemplate(prefix+".params."+key, s)...)
+		}
 	}
 	return errs
 }
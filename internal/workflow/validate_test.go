@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -104,6 +105,160 @@ func TestValidate(t *testing.T) {
 			},
 			wantFields: []string{"source.filter.label", "source.filter.team"},
 		},
+		{
+			name: "gitlab missing label and project",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "gitlab"},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.label", "source.filter.project"},
+		},
+		{
+			name: "clickup missing label and list",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "clickup"},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.label", "source.filter.list"},
+		},
+		{
+			name: "file provider without label",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "file"},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "http provider missing url",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "http", Filter: FilterConfig{HTTP: &HTTPSourceConfig{Items: "issues"}}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.http.url"},
+		},
+		{
+			name: "invalid readiness checks",
+			cfg: &Config{
+				Start: "s",
+				Source: SourceConfig{
+					Provider:  "github",
+					Filter:    FilterConfig{Label: "q"},
+					Readiness: &ReadinessConfig{MinBodyLength: -1, RequiredSections: []string{" "}},
+				},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.readiness.min_body_length", "source.readiness.required_sections[0]"},
+		},
+		{
+			name: "negative max_items",
+			cfg: &Config{
+				Start: "s",
+				Source: SourceConfig{
+					Provider: "asana",
+					Filter:   FilterConfig{Label: "q", Project: "123", MaxItems: -1},
+				},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.max_items"},
+		},
+		{
+			name: "assignee on unsupported provider",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "clickup", Filter: FilterConfig{Label: "q", List: "901", Assignee: "erg-bot"}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.assignee"},
+		},
+		{
+			name: "expand_subtasks on unsupported provider",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q", ExpandSubtasks: true}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.expand_subtasks"},
+		},
+		{
+			name: "expand_subtasks on linear",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "linear", Filter: FilterConfig{Label: "q", Team: "t", ExpandSubtasks: true}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "invalid label expression",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "ai-ready && (bug || chore"}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.filter.label"},
+		},
+		{
+			name: "negative deadline_window",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}, DeadlineWindow: &Duration{-time.Hour}},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.deadline_window"},
+		},
+		{
+			name: "valid additional source",
+			cfg: &Config{
+				Start: "s",
+				Source: SourceConfig{
+					Provider:   "github",
+					Filter:     FilterConfig{Label: "q"},
+					Additional: []AdditionalSource{{Provider: "linear", Filter: FilterConfig{Label: "q", Team: "ENG"}}},
+					Canonical:  "linear",
+				},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+		},
+		{
+			name: "invalid additional sources",
+			cfg: &Config{
+				Start: "s",
+				Source: SourceConfig{
+					Provider: "github",
+					Filter:   FilterConfig{Label: "q"},
+					Additional: []AdditionalSource{
+						{Provider: "linear", Filter: FilterConfig{Label: "q"}},
+						{Provider: "github", Filter: FilterConfig{Label: "q"}},
+					},
+					Canonical: "asana",
+				},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.additional[0].filter.team", "source.additional[1].provider", "source.canonical"},
+		},
+		{
+			name: "unknown order",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}, Order: "random"},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.order"},
+		},
+		{
+			name: "label-weighted order without weights",
+			cfg: &Config{
+				Start:  "s",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}, Order: "label-weighted"},
+				States: map[string]*State{"s": {Type: StateTypeSucceed}},
+			},
+			wantFields: []string{"source.label_weights"},
+		},
 		{
 			name:       "missing start",
 			cfg:        &Config{States: map[string]*State{"s": {Type: StateTypeSucceed}}, Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}}},
@@ -749,6 +904,67 @@ func TestValidate(t *testing.T) {
 			},
 			wantFields: nil,
 		},
+		{
+			name: "valid escalation with stall",
+			cfg: &Config{
+				Start:  "w",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"w": {
+						Type: StateTypeWait, Event: "pr.reviewed", Next: "done",
+						Timeout: &Duration{Duration: 3 * time.Hour}, OnTimeout: TimeoutStall,
+						Escalation: []EscalationConfig{
+							{After: Duration{Duration: time.Hour}, Action: "github.comment_pr"},
+							{After: Duration{Duration: 2 * time.Hour}, Action: "slack.notify"},
+						},
+					},
+					"done": {Type: StateTypeSucceed},
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "invalid on_timeout",
+			cfg: &Config{
+				Start:  "w",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"w":    {Type: StateTypeWait, Event: "pr.reviewed", Next: "done", OnTimeout: "park"},
+					"x":    {Type: StateTypeWait, Event: "pr.reviewed", Next: "done", OnTimeout: TimeoutStall},
+					"y":    {Type: StateTypeWait, Event: "pr.reviewed", Next: "done", Timeout: &Duration{Duration: time.Hour}, TimeoutNext: "done", OnTimeout: TimeoutStall},
+					"task": {Type: StateTypeTask, Action: "github.merge", Next: "done", OnTimeout: TimeoutStall},
+					"done": {Type: StateTypeSucceed},
+				},
+			},
+			wantFields: []string{"states.w.on_timeout", "states.x.on_timeout", "states.y.on_timeout", "states.task.on_timeout"},
+		},
+		{
+			name: "invalid escalation levels",
+			cfg: &Config{
+				Start:  "w",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"w": {
+						Type: StateTypeWait, Event: "pr.reviewed", Next: "done",
+						Timeout: &Duration{Duration: 3 * time.Hour}, Error: "done",
+						Escalation: []EscalationConfig{
+							{After: Duration{Duration: 2 * time.Hour}, Action: "github.comment_pr"},
+							{After: Duration{Duration: time.Hour}, Action: "nope.action"},
+							{After: Duration{Duration: 4 * time.Hour}, Action: "ai.code"},
+							{Action: ""},
+						},
+					},
+					"task": {Type: StateTypeTask, Action: "github.merge", Next: "done", Escalation: []EscalationConfig{{After: Duration{Duration: time.Hour}, Action: "slack.notify"}}},
+					"done": {Type: StateTypeSucceed},
+				},
+			},
+			wantFields: []string{
+				"states.w.escalation[1].after", "states.w.escalation[1].action",
+				"states.w.escalation[2].after", "states.w.escalation[2].action",
+				"states.w.escalation[3].after", "states.w.escalation[3].action",
+				"states.task.escalation",
+			},
+		},
 		{
 			name: "valid settings",
 			cfg: &Config{
@@ -774,6 +990,66 @@ func TestValidate(t *testing.T) {
 			},
 			wantFields: []string{"settings.max_concurrent"},
 		},
+		{
+			name: "negative issue_context_max_chars in settings",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{IssueContextMaxChars: -1},
+			},
+			wantFields: []string{"settings.issue_context_max_chars"},
+		},
+		{
+			name: "negative repo_index_max_chars in settings",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{RepoIndexMaxChars: -1},
+			},
+			wantFields: []string{"settings.repo_index_max_chars"},
+		},
+		{
+			name: "repo_index_model without a model or with a bad url",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{RepoIndexModel: &RepoIndexModelConfig{URL: "localhost:11434"}},
+			},
+			wantFields: []string{"settings.repo_index_model.model", "settings.repo_index_model.url"},
+		},
+		{
+			name: "negative dead_letter_after in settings",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{DeadLetterAfter: -1},
+			},
+			wantFields: []string{"settings.dead_letter_after"},
+		},
+		{
+			name: "unknown timezone in settings",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{Timezone: "Mars/Olympus_Mons"},
+			},
+			wantFields: []string{"settings.timezone"},
+		},
+		{
+			name: "IANA timezone in settings",
+			cfg: &Config{
+				Start:    "s",
+				Source:   SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States:   map[string]*State{"s": {Type: StateTypeSucceed}},
+				Settings: &SettingsConfig{Timezone: "America/New_York"},
+			},
+			wantFields: nil,
+		},
 		{
 			name: "nil settings is valid",
 			cfg: &Config{
@@ -903,6 +1179,156 @@ func TestValidate(t *testing.T) {
 			},
 			wantFields: nil,
 		},
+		{
+			name: "exec.run missing command param",
+			cfg: &Config{
+				Start:  "lint",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"lint": {Type: StateTypeTask, Action: "exec.run", Next: "done"},
+					"done": {Type: StateTypeSucceed},
+				},
+			},
+			wantFields: []string{"states.lint.params.command"},
+		},
+		{
+			name: "exec.run with non-string env value",
+			cfg: &Config{
+				Start:  "lint",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"lint": {Type: StateTypeTask, Action: "exec.run", Next: "done", Params: map[string]any{"command": "make lint", "env": map[string]any{"STRICT": true}}},
+					"done": {Type: StateTypeSucceed},
+				},
+			},
+			wantFields: []string{"states.lint.params.env.STRICT"},
+		},
+		{
+			name: "exec.run with command and env",
+			cfg: &Config{
+				Start:  "lint",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "done", Error: "failed", Params: map[string]any{"command": "./scripts/lint.sh", "env": map[string]any{"STRICT": "1"}}},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "parallel with valid branches",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "gather"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "parallel with a single branch",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint"}, Next: "gather"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: []string{"states.checks.branches"},
+		},
+		{
+			name: "parallel next is not a join",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "done"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: []string{"states.checks.next"},
+		},
+		{
+			name: "parallel branch runs an async action",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "code"}, Next: "gather"},
+					"code":   {Type: StateTypeTask, Action: "ai.code", Next: "gather"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: []string{"states.checks.branches[1]"},
+		},
+		{
+			name: "parallel branch state sets an error edge",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "scan"}, Next: "gather"},
+					"scan":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Error: "failed", Params: map[string]any{"command": "make scan"}},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: []string{"states.checks.branches[1]"},
+		},
+		{
+			name: "parallel branches share a state",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test", "pre"}, Next: "gather"},
+					"pre":    {Type: StateTypePass, Next: "lint"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin, Next: "done", Error: "failed"},
+					"done":   {Type: StateTypeSucceed},
+					"failed": {Type: StateTypeFail},
+				},
+			},
+			wantFields: []string{"states.checks.branches[2]"},
+		},
+		{
+			name: "join without next",
+			cfg: &Config{
+				Start:  "checks",
+				Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "q"}},
+				States: map[string]*State{
+					"checks": {Type: StateTypeParallel, Branches: []string{"lint", "test"}, Next: "gather"},
+					"lint":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make lint"}},
+					"test":   {Type: StateTypeTask, Action: "exec.run", Next: "gather", Params: map[string]any{"command": "make test"}},
+					"gather": {Type: StateTypeJoin},
+				},
+			},
+			wantFields: []string{"states.gather.next"},
+		},
 		{
 			name: "cycle detection: simple A→B→A",
 			cfg: &Config{
@@ -1065,6 +1491,58 @@ func TestValidateRebaseParams(t *testing.T) {
 	}
 }
 
+func TestValidatePipelineParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]any
+		wantError bool
+	}{
+		{"nil params", nil, false},
+		{"pipeline on", map[string]any{"pipeline": true}, false},
+		{"pipeline not a bool", map[string]any{"pipeline": "yes"}, true},
+		{"valid prompts", map[string]any{"plan_system_prompt": "Plan {{.Issue.Title}}", "review_system_prompt": "file:.erg/review.md"}, false},
+		{"bad plan template", map[string]any{"plan_system_prompt": "Plan {{.Issue.Title"}, true},
+		{"bad review template", map[string]any{"review_system_prompt": "{{end}}"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validatePipelineParams("states.coding", tt.params)
+			if tt.wantError && len(errs) == 0 {
+				t.Error("expected validation error")
+			}
+			if !tt.wantError && len(errs) > 0 {
+				t.Errorf("unexpected validation errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateResultRequired(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]any
+		wantError bool
+	}{
+		{"unset", map[string]any{}, false},
+		{"known fields", map[string]any{"result_required": []any{"plan", "risk_level", "follow_ups"}}, false},
+		{"unknown field", map[string]any{"result_required": []any{"plan", "tests"}}, true},
+		{"not a list", map[string]any{"result_required": "plan"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateCodingParams("states.coding", tt.params)
+			if tt.wantError && len(errs) == 0 {
+				t.Error("expected validation error")
+			}
+			if !tt.wantError && len(errs) > 0 {
+				t.Errorf("unexpected validation errors: %v", errs)
+			}
+		})
+	}
+}
+
 func TestValidate_GitRebaseAction(t *testing.T) {
 	// A workflow with git.rebase and invalid max_rebase_rounds should fail validation
 	cfg := &Config{
@@ -1207,6 +1685,54 @@ func TestValidateDiffParams(t *testing.T) {
 	}
 }
 
+func TestValidateQualityGateParams(t *testing.T) {
+	check := func(kv ...any) map[string]any {
+		m := map[string]any{}
+		for i := 0; i < len(kv); i += 2 {
+			m[kv[i].(string)] = kv[i+1]
+		}
+		return m
+	}
+	tests := []struct {
+		name      string
+		checks    any
+		wantField string // "" = valid
+	}{
+		{"missing checks", nil, "states.gate.params.checks"},
+		{"empty checks", []any{}, "states.gate.params.checks"},
+		{"valid checks", []any{
+			check("name", "tests", "command", "go test ./...", "timeout", "20m"),
+			check("name", "coverage", "type", "coverage", "command", "go test -cover ./...", "min", 80),
+			check("name", "diff", "type", "diff_size", "max", 500),
+			check("name", "files", "type", "file_count", "max", float64(20)),
+		}, ""},
+		{"check not a map", []any{"go test"}, "states.gate.params.checks[0]"},
+		{"missing name", []any{check("command", "true")}, "states.gate.params.checks[0].name"},
+		{"duplicate name", []any{check("name", "a", "command", "true"), check("name", "a", "command", "true")}, "states.gate.params.checks[1].name"},
+		{"missing command", []any{check("name", "lint")}, "states.gate.params.checks[0].command"},
+		{"coverage without min", []any{check("name", "c", "type", "coverage", "command", "x")}, "states.gate.params.checks[0].min"},
+		{"coverage over 100", []any{check("name", "c", "type", "coverage", "command", "x", "min", 120)}, "states.gate.params.checks[0].min"},
+		{"diff_size without max", []any{check("name", "d", "type", "diff_size")}, "states.gate.params.checks[0].max"},
+		{"unknown type", []any{check("name", "x", "type", "vibes")}, "states.gate.params.checks[0].type"},
+		{"bad timeout", []any{check("name", "t", "command", "true", "timeout", "soon")}, "states.gate.params.checks[0].timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateQualityGateParams("states.gate", map[string]any{"checks": tt.checks})
+			if tt.wantField == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected validation errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.wantField {
+				t.Errorf("expected one error for %s, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
+
 func TestValidate_ValidateDiffAction(t *testing.T) {
 	cfg := &Config{
 		Workflow: "test",
@@ -1399,6 +1925,42 @@ func TestValidateTriggers(t *testing.T) {
 			},
 			wantFields: []string{"triggers[1].schedule"},
 		},
+		{
+			name: "named triggers with prompt, timezone and overlap",
+			triggers: []TriggerConfig{
+				{Schedule: "0 2 * * *", State: "coding", Name: "deps", Prompt: "Update dependencies", Timezone: "Europe/Berlin"},
+				{Schedule: "0 3 * * *", State: "coding", Name: "flaky", Prompt: "file:prompts/flaky.md", Overlap: TriggerOverlapAllow},
+			},
+			wantFields: nil,
+		},
+		{
+			name: "duplicate name",
+			triggers: []TriggerConfig{
+				{Schedule: "0 2 * * *", State: "coding", Name: "deps"},
+				{Schedule: "0 3 * * *", State: "plan", Name: "deps"},
+			},
+			wantFields: []string{"triggers[1].name"},
+		},
+		{
+			name:       "unknown timezone",
+			triggers:   []TriggerConfig{{Schedule: "0 2 * * *", State: "coding", Timezone: "Mars/Olympus"}},
+			wantFields: []string{"triggers[0].timezone"},
+		},
+		{
+			name:       "timezone with CRON_TZ prefix",
+			triggers:   []TriggerConfig{{Schedule: "CRON_TZ=UTC 0 2 * * *", State: "coding", Timezone: "Europe/Berlin"}},
+			wantFields: []string{"triggers[0].timezone"},
+		},
+		{
+			name:       "unknown overlap",
+			triggers:   []TriggerConfig{{Schedule: "0 2 * * *", State: "coding", Overlap: "queue"}},
+			wantFields: []string{"triggers[0].overlap"},
+		},
+		{
+			name:       "prompt file outside repo",
+			triggers:   []TriggerConfig{{Schedule: "0 2 * * *", State: "coding", Prompt: "file:../secrets.md"}},
+			wantFields: []string{"triggers[0].prompt"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1469,3 +2031,195 @@ func TestValidate_WithInvalidTrigger(t *testing.T) {
 		t.Errorf("expected error on triggers[0].schedule, got: %v", errs)
 	}
 }
+
+func TestValidate_EmptyHookRun(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		Source: SourceConfig{
+			Provider: "github",
+			Filter:   FilterConfig{Label: "ai-assisted"},
+		},
+		States: map[string]*State{
+			"coding": {
+				Type:   StateTypeTask,
+				Action: "ai.code",
+				Next:   "done",
+				Before: []HookConfig{{Run: "make deps"}},
+				After:  []HookConfig{{Run: "make lint"}, {Run: "  "}},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+
+	errs := Validate(cfg)
+	if len(errs) != 1 || errs[0].Field != "states.coding.after[1].run" {
+		t.Errorf("expected single error for states.coding.after[1].run, got: %v", errs)
+	}
+}
+
+func TestValidate_HookOutput(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		Source: SourceConfig{
+			Provider: "github",
+			Filter:   FilterConfig{Label: "ai-assisted"},
+		},
+		States: map[string]*State{
+			"coding": {
+				Type:   StateTypeTask,
+				Action: "ai.code",
+				Next:   "done",
+				Before: []HookConfig{{Run: "scripts/affected.sh", Output: HookOutputJSON}},
+				After:  []HookConfig{{Run: "make lint", Output: "yaml"}},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+
+	errs := Validate(cfg)
+	if len(errs) != 1 || errs[0].Field != "states.coding.after[0].output" {
+		t.Errorf("expected single error for states.coding.after[0].output, got: %v", errs)
+	}
+}
+
+func TestValidate_Templates(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		Source: SourceConfig{
+			Provider: "github",
+			Filter:   FilterConfig{Label: "ai-assisted"},
+		},
+		States: map[string]*State{
+			"coding": {
+				Type:   StateTypeTask,
+				Action: "ai.code",
+				Next:   "pr",
+				Params: map[string]any{"system_prompt": "Fix {{.Issue.Title}"},
+				Before: []HookConfig{{Run: "echo {{shellquote .Issue.Title}} {{step.plan}}"}},
+				After:  []HookConfig{{Run: "echo {{if .Branch}}"}},
+			},
+			"pr": {
+				Type:   StateTypeTask,
+				Action: "github.create_pr",
+				Next:   "done",
+				Params: map[string]any{"body": "{{.Body}}\n\nCost so far: {{.Spend.CostUSD}}"},
+			},
+			"done": {Type: StateTypeSucceed},
+		},
+	}
+
+	errs := Validate(cfg)
+	want := map[string]bool{"states.coding.params.system_prompt": true, "states.coding.after[0].run": true}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got: %v", len(want), errs)
+	}
+	for _, e := range errs {
+		if !want[e.Field] {
+			t.Errorf("unexpected error: %v", e)
+		}
+	}
+}
+
+func TestValidate_OnFailure(t *testing.T) {
+	cfg := &Config{
+		Start: "coding",
+		Source: SourceConfig{
+			Provider: "github",
+			Filter:   FilterConfig{Label: "ai-assisted"},
+		},
+		States: map[string]*State{
+			"coding": {
+				Type:   StateTypeTask,
+				Action: "ai.code",
+				Next:   "done",
+				OnFailure: []CompensationConfig{
+					{Action: "github.remove_label", Params: map[string]any{"label": "wip"}},
+					{Run: "git push origin --delete {{.Branch}}"},
+					{},
+					{Action: "github.comment_issue", Run: "echo both"},
+					{Action: "github.nope"},
+					{Action: "ai.fix_ci"},
+					{Run: "echo {{.Branch"},
+				},
+			},
+			"done": {Type: StateTypeSucceed, OnFailure: []CompensationConfig{{Run: "true"}}},
+		},
+	}
+
+	errs := Validate(cfg)
+	want := []string{
+		"states.coding.on_failure[2]",
+		"states.coding.on_failure[3]",
+		"states.coding.on_failure[4].action",
+		"states.coding.on_failure[5].action",
+		"states.coding.on_failure[6].run",
+		"states.done.on_failure",
+	}
+	got := make(map[string]bool)
+	for _, e := range errs {
+		got[e.Field] = true
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got: %v", len(want), errs)
+	}
+	for _, field := range want {
+		if !got[field] {
+			t.Errorf("expected error for %s, got: %v", field, errs)
+		}
+	}
+}
+
+func TestValidate_Workflows(t *testing.T) {
+	cfg := &Config{
+		Start:  "coding",
+		Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "ai-assisted"}},
+		States: map[string]*State{
+			"coding": {Type: StateTypeTask, Action: "ai.code", Next: "done"},
+			"done":   {Type: StateTypeSucceed},
+		},
+		Workflows: []NamedWorkflow{
+			{Name: "docs", File: "docs.yaml", Labels: []string{"docs"}},
+			{Name: "docs", File: "other.yaml", Labels: []string{"other"}},
+			{Name: DefaultWorkflowName, File: "default.yaml", Labels: []string{"x"}},
+			{Name: "hotfix"},
+		},
+	}
+
+	errs := Validate(cfg)
+	want := []string{"workflows[1].name", "workflows[2].name", "workflows[3].file", "workflows[3].labels"}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got: %v", len(want), errs)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("error %d: expected field %s, got %s", i, field, errs[i].Field)
+		}
+	}
+}
+
+func TestValidate_Mutex(t *testing.T) {
+	cfg := &Config{
+		Start:  "migrate",
+		Source: SourceConfig{Provider: "github", Filter: FilterConfig{Label: "ai-assisted"}},
+		Mutex:  "db migrations",
+		States: map[string]*State{
+			"migrate": {Type: StateTypeTask, Action: "ai.code", Next: "ci", Mutex: "db-migrations"},
+			"ci":      {Type: StateTypeWait, Event: "ci.complete", Next: "done", Mutex: "ci"},
+			"bad":     {Type: StateTypeTask, Action: "ai.code", Next: "done", Mutex: "-lock"},
+			"done":    {Type: StateTypeSucceed},
+		},
+	}
+
+	got := make(map[string]bool)
+	for _, e := range Validate(cfg) {
+		got[e.Field] = true
+	}
+	for _, field := range []string{"mutex", "states.ci.mutex", "states.bad.mutex"} {
+		if !got[field] {
+			t.Errorf("expected error for %s, got %v", field, got)
+		}
+	}
+	if got["states.migrate.mutex"] {
+		t.Error("expected db-migrations to be a valid mutex on a task state")
+	}
+}